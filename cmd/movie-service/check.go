@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"thermondo/config"
+	"thermondo/internal/pkg/cache"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// selfCheck is one check run by -check-config. A check that does not apply
+// to the configuration returns a skipped error saying why.
+type selfCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type skipped string
+
+func (s skipped) Error() string { return string(s) }
+
+// runConfigCheck prints the effective configuration and the outcome of each
+// self-check to out, and reports whether all of them passed
+func runConfigCheck(ctx context.Context, cfg config.Configuration, out io.Writer) bool {
+	fmt.Fprintln(out, "Effective configuration (secrets redacted, * = default):")
+	for _, entry := range config.Report(cfg) {
+		marker := " "
+		if entry.Default {
+			marker = "*"
+		}
+		fmt.Fprintf(out, "  %s %-36s %s\n", marker, entry.EnvVar, entry.Value)
+	}
+
+	checks := []selfCheck{
+		{"jwt secret", func(ctx context.Context) error {
+			return config.CheckJWTSecret(cfg.JWT.Secret)
+		}},
+		{"jwt keys", func(ctx context.Context) error {
+			if len(cfg.JWT.Keys) == 0 {
+				return skipped("JWT_KEYS is empty")
+			}
+			_, err := newTokenKeys(cfg.JWT)
+			return err
+		}},
+		{"postgres", func(ctx context.Context) error {
+			if cfg.DataStore != "postgres" {
+				return skipped("STORAGE=" + cfg.DataStore)
+			}
+			if err := config.CheckDSN(cfg.Database.DSN); err != nil {
+				return err
+			}
+			db, err := sqlx.Open("postgres", cfg.Database.DSN)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			return db.PingContext(ctx)
+		}},
+		{"sqlite", func(ctx context.Context) error {
+			if cfg.DataStore != "sqlite" {
+				return skipped("only used when STORAGE=sqlite")
+			}
+			return checkWritableDir(filepath.Dir(cfg.Database.SQLitePath))
+		}},
+		{"redis", func(ctx context.Context) error {
+			if os.Getenv("APP_ENV") != "production" {
+				return skipped("only used when APP_ENV=production")
+			}
+			c, err := cache.NewRedisCache(cache.RedisConfig{
+				Host:     cfg.Redis.Host,
+				Port:     cfg.Redis.Port,
+				Password: cfg.Redis.Password,
+				DB:       cfg.Redis.DB,
+			}, "thermondo")
+			if err != nil {
+				return err
+			}
+			return c.Close()
+		}},
+		{"media storage", func(ctx context.Context) error {
+			if cfg.Storage.Backend != "local" {
+				if _, err := url.ParseRequestURI(cfg.Storage.S3Endpoint); err != nil {
+					return fmt.Errorf("invalid STORAGE_S3_ENDPOINT: %w", err)
+				}
+				return skipped("S3 credentials are not verified")
+			}
+			return checkWritableDir(cfg.Storage.LocalDir)
+		}},
+		{"encryption keys", func(ctx context.Context) error {
+			if !cfg.Encryption.EncryptEmails {
+				return skipped("ENCRYPTION_EMAILS is off")
+			}
+			_, err := newKeyring(cfg.Encryption)
+			return err
+		}},
+		{"captcha", func(ctx context.Context) error {
+			if cfg.Signup.CaptchaVerifyURL == "" {
+				return skipped("SIGNUP_CAPTCHA_VERIFY_URL is not set")
+			}
+			_, err := url.ParseRequestURI(cfg.Signup.CaptchaVerifyURL)
+			return err
+		}},
+	}
+
+	fmt.Fprintln(out, "\nSelf-checks:")
+	ok := true
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := check.run(checkCtx)
+		cancel()
+
+		switch err.(type) {
+		case nil:
+			fmt.Fprintf(out, "  [ok]   %s\n", check.name)
+		case skipped:
+			fmt.Fprintf(out, "  [skip] %s: %v\n", check.name, err)
+		default:
+			ok = false
+			fmt.Fprintf(out, "  [FAIL] %s: %v\n", check.name, err)
+		}
+	}
+	return ok
+}
+
+// checkWritableDir creates dir if needed and writes a scratch file to it
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
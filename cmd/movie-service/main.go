@@ -2,43 +2,172 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
 	"thermondo/config"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/money"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/usage"
+	"thermondo/internal/domain/users"
 	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/captcha"
+	"thermondo/internal/pkg/encryption"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/migrate"
+	"thermondo/internal/pkg/oidc"
 	"thermondo/internal/pkg/postgres"
+	"thermondo/internal/pkg/ratelimit"
 	"thermondo/internal/pkg/server"
+	"thermondo/internal/pkg/storage"
+	"thermondo/internal/pkg/tokens"
+	adminHandlers "thermondo/internal/platform/http/handlers/admin"
+	anonymousHandlers "thermondo/internal/platform/http/handlers/anonymous"
+	collectionHandlers "thermondo/internal/platform/http/handlers/collections"
+	feedHandlers "thermondo/internal/platform/http/handlers/feeds"
+	jwksHandlers "thermondo/internal/platform/http/handlers/jwks"
+	listHandlers "thermondo/internal/platform/http/handlers/lists"
+	mediaHandlers "thermondo/internal/platform/http/handlers/media"
 	movieHandlers "thermondo/internal/platform/http/handlers/movies"
+	partnerHandlers "thermondo/internal/platform/http/handlers/partners"
+	peopleHandlers "thermondo/internal/platform/http/handlers/people"
 	ratingHandlers "thermondo/internal/platform/http/handlers/ratings"
+	seoHandlers "thermondo/internal/platform/http/handlers/seo"
 	userHandlers "thermondo/internal/platform/http/handlers/users"
+	viewHandlers "thermondo/internal/platform/http/handlers/views"
+	"thermondo/internal/platform/http/middleware"
 	"thermondo/internal/platform/http/rest"
 	"thermondo/internal/platform/repository"
+	"thermondo/internal/platform/repository/cached"
+	"thermondo/internal/platform/repository/instrumented"
+	"thermondo/internal/platform/repository/memory"
+	adminService "thermondo/internal/platform/service/admin"
+	anonymousService "thermondo/internal/platform/service/anonymous"
+	collectionService "thermondo/internal/platform/service/collections"
+	feedService "thermondo/internal/platform/service/feeds"
+	jobsService "thermondo/internal/platform/service/jobs"
+	listService "thermondo/internal/platform/service/lists"
 	movieService "thermondo/internal/platform/service/movies"
+	oidcService "thermondo/internal/platform/service/oidc"
+	partnerService "thermondo/internal/platform/service/partners"
+	peopleService "thermondo/internal/platform/service/people"
 	ratingService "thermondo/internal/platform/service/rating"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+	retentionService "thermondo/internal/platform/service/retention"
+	seoService "thermondo/internal/platform/service/seo"
+	sessionService "thermondo/internal/platform/service/session"
+	usageService "thermondo/internal/platform/service/usage"
 	userService "thermondo/internal/platform/service/user"
+	viewService "thermondo/internal/platform/service/views"
+	"thermondo/internal/platform/service/warmup"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
+	checkConfig := flag.Bool("check-config", false, "validate the configuration and dependencies, print a report and exit")
+	flag.Parse()
+
+	cfg, configLoader, err := config.LoadWithLoader(context.Background())
 	if err != nil {
+		if *checkConfig {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		slog.Error("Failed to load config", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if *checkConfig {
+		if !runConfigCheck(context.Background(), cfg, os.Stdout) {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Database
-	db, err := postgres.NewConnection(cfg.Database.DSN, cfg.Database.HealthCheck)
-	if err != nil {
-		logger.Error("Failed to connect to database", slog.String("error", err.Error()))
-		os.Exit(1)
+	// Levels can change at runtime, from config reloads and /admin/logging,
+	// globally and per module
+	level, _ := config.ParseLogLevel(cfg.LogLevel)
+	logLevels := logging.NewLevels(level)
+	// Request and response bodies are only logged once selected through
+	// /admin/logging
+	logBodies := logging.NewBodies()
+	logger := slog.New(logging.NewHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevels}), logLevels))
+	httpLogger := logging.Module(logger, logging.ModuleHTTP)
+	cacheLogger := logging.Module(logger, logging.ModuleCache)
+	repositoryLogger := logging.Module(logger, logging.ModuleRepository)
+
+	if cfg.Server.LegacyErrorFormat {
+		response.SetDefaultErrorFormat(response.ErrorFormatLegacy)
+		logger.Warn("Serving errors in legacy {\"error\": ...} format")
+	}
+
+	// Database. With STORAGE=memory or STORAGE=sqlite movies, ratings and
+	// users are kept in process or in a SQLite file; the other features
+	// still use Postgres, so the pool is opened without connecting and only
+	// fails once one of them is used.
+	withPostgres := cfg.DataStore == "postgres"
+	var queryLog []postgres.Option
+	if cfg.Database.QueryLog {
+		queryLog = append(queryLog, postgres.WithQueryLog(postgres.QueryLogConfig{
+			Logger:        repositoryLogger,
+			SlowThreshold: cfg.Database.SlowQueryThreshold,
+		}))
+	}
+	var db, coreDB *sqlx.DB
+	if !withPostgres {
+		db, err = sqlx.Open("postgres", cfg.Database.DSN)
+		if err != nil {
+			logger.Error("Failed to open database", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+	switch cfg.DataStore {
+	case "memory":
+		logger.Warn("Keeping movies, ratings and users in memory; they are lost on restart and other features need Postgres")
+	case "sqlite":
+		coreDB, err = openSQLite(cfg.Database.SQLitePath, queryLog, repositoryLogger)
+		if err != nil {
+			logger.Error("Failed to open SQLite database", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer coreDB.Close()
+		logger.Warn("Keeping movies, ratings and users in SQLite; other features need Postgres", slog.String("path", cfg.Database.SQLitePath))
+	default:
+		dbOptions := append([]postgres.Option{postgres.WithPool(postgres.PoolConfig{
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		})}, queryLog...)
+		db, err = postgres.NewConnection(cfg.Database.DSN, cfg.Database.HealthCheck, dbOptions...)
+		if err != nil {
+			logger.Error("Failed to connect to database", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		if cfg.Database.PoolStatsInterval > 0 {
+			go postgres.MonitorPool(context.Background(), db, repositoryLogger, cfg.Database.PoolStatsInterval)
+		}
+		coreDB = db
 	}
 	defer db.Close()
 
 	// Determine environment
 	appEnv := os.Getenv("APP_ENV")
-	var c cache.Cache
+	var (
+		c             cache.Cache
+		usageCounters cache.Counters
+		viewRecents   cache.Recents
+	)
 	if appEnv == "production" {
 		redisConfig := cache.RedisConfig{
 			Host:     cfg.Redis.Host,
@@ -48,59 +177,640 @@ func main() {
 		}
 		c, err = cache.NewRedisCache(redisConfig, "thermondo")
 		if err != nil {
-			logger.Error("Failed to initialize Redis cache", slog.String("error", err.Error()))
+			cacheLogger.Error("Failed to initialize Redis cache", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		c = cache.NewCircuitBreaker(c, cacheLogger,
+			cache.WithBreakerFailures(cfg.Redis.BreakerFailures),
+			cache.WithBreakerCoolDown(cfg.Redis.BreakerCoolDown),
+		)
+		if cfg.Redis.L1Size > 0 {
+			bus, err := cache.NewRedisInvalidationBus(redisConfig, "thermondo:cache_invalidations")
+			if err != nil {
+				cacheLogger.Error("Failed to initialize cache invalidation bus", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			c = cache.NewLayeredCache(c, cacheLogger,
+				cache.WithL1Size(cfg.Redis.L1Size),
+				cache.WithL1TTL(cfg.Redis.L1TTL),
+				cache.WithInvalidationBus(bus),
+			)
+		}
+		cacheLogger.Info("Using Redis cache")
+
+		usageCounters, err = cache.NewRedisCounters(redisConfig, "thermondo")
+		if err != nil {
+			cacheLogger.Error("Failed to initialize Redis usage counters", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		viewRecents, err = cache.NewRedisRecents(redisConfig, "thermondo")
+		if err != nil {
+			cacheLogger.Error("Failed to initialize Redis recently viewed lists", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
-		logger.Info("Using Redis cache")
 	} else {
 		c = cache.NewNoOpCache()
-		logger.Info("Using NoOp (in-memory) cache for non-production environment", slog.String("env", appEnv))
+		cacheLogger.Info("Using NoOp (in-memory) cache for non-production environment", slog.String("env", appEnv))
+		usageCounters = cache.NewMemoryCounters()
+		viewRecents = cache.NewMemoryRecents()
 	}
 	defer c.Close()
+	defer usageCounters.Close()
+	defer viewRecents.Close()
 
 	// Repositories
-	userRepo := repository.NewUserRepository(db, c)
-	movieRepo := repository.NewMovieRepository(db)
-	ratingRepo := repository.NewRatingRepository(db)
+	var userRepoOptions []repository.UserRepositoryOption
+	if cfg.Encryption.EncryptEmails && coreDB != nil {
+		keyring, err := newKeyring(cfg.Encryption)
+		if err != nil {
+			logger.Error("Failed to initialize encryption keys", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		userRepoOptions = append(userRepoOptions, repository.WithEmailEncryption(keyring))
+		logger.Info("Encrypting user emails", slog.String("active_key", keyring.ActiveKeyID()))
+
+		if cfg.Encryption.BackfillOnStart {
+			go func() {
+				n, err := repository.EncryptUserEmails(context.Background(), coreDB, keyring, cfg.Encryption.BackfillBatchSize)
+				if err != nil {
+					repositoryLogger.Error("Failed to encrypt existing user emails", slog.String("error", err.Error()), slog.Int("updated", n))
+					return
+				}
+				repositoryLogger.Info("Encrypted existing user emails", slog.Int("updated", n))
+			}()
+		}
+	}
+	var (
+		userRepo   users.UserRepository
+		movieRepo  movies.Repository
+		ratingRepo rating.Repository
+	)
+	if coreDB == nil {
+		store := memory.NewStore()
+		userRepo = memory.NewUserRepository(store, c, repositoryLogger)
+		movieRepo = memory.NewMovieRepository(store)
+		ratingRepo = memory.NewRatingRepository(store)
+	} else {
+		userRepo = repository.NewUserRepository(coreDB, c, repositoryLogger, userRepoOptions...)
+		movieRepo = repository.NewMovieRepository(coreDB)
+		ratingRepo = repository.NewRatingRepository(coreDB)
+	}
+	// Decorators compose from the inside out: the measured calls are the
+	// ones that reach the repository, so lookups answered by the not-found
+	// cache are not counted as queries
+	userRepo = instrumented.NewUserRepository(userRepo, repositoryLogger)
+	movieRepo = instrumented.NewMovieRepository(movieRepo, repositoryLogger)
+	ratingRepo = instrumented.NewRatingRepository(ratingRepo, repositoryLogger)
+	if cfg.Redis.NotFoundTTL > 0 {
+		userRepo = cached.NewUserRepository(userRepo, c, cfg.Redis.NotFoundTTL, cacheLogger)
+		movieRepo = cached.NewMovieRepository(movieRepo, c, cfg.Redis.NotFoundTTL, cacheLogger)
+		ratingRepo = cached.NewRatingRepository(ratingRepo, c, cfg.Redis.NotFoundTTL, cacheLogger)
+	}
+	translationRepo := repository.NewTranslationRepository(db)
+	releaseRepo := repository.NewReleaseRepository(db)
+	providerRepo := repository.NewProviderRepository(db)
+	certificationRepo := repository.NewCertificationRepository(db)
+	peopleRepo := repository.NewPeopleRepository(db)
+	collectionRepo := repository.NewCollectionRepository(db)
+	listRepo := repository.NewListRepository(db)
+	mergeRepo := repository.NewMergeRepository(db)
+	posterRepo := repository.NewPosterRepository(db)
+	// Slugs are looked up with every movie, so they are left out rather
+	// than failing each lookup without Postgres
+	var slugRepo movies.SlugRepository
+	if withPostgres {
+		slugRepo = repository.NewSlugRepository(db)
+	}
+	summaryRepo := repository.NewSummaryRepository(db)
+	aggregateRepo := repository.NewAggregateRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	jobRepo := repository.NewJobRepository(db)
 	idGenerator := shared.NewULIDsGenerator()
 	timeProvider := shared.NewTimeProvider()
 
+	// Media storage
+	mediaStore, mediaSigner, err := newMediaStorage(cfg.Storage)
+	if err != nil {
+		logger.Error("Failed to initialize media storage", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logger.Info("Using media storage", slog.String("backend", cfg.Storage.Backend))
+
 	// Services
-	userService := userService.NewUserService(userRepo, ratingRepo, movieRepo, idGenerator, timeProvider, c)
-	movieService := movieService.NewMovieService(movieRepo, idGenerator, timeProvider, logger)
-	ratingService := ratingService.NewRatingService(ratingRepo, idGenerator, timeProvider, logger)
+	emailPolicy := users.EmailDomainPolicy{
+		Allowed: cfg.Signup.AllowedDomains,
+		Blocked: cfg.Signup.BlockedDomains,
+	}
+	if cfg.Signup.BlockDisposable {
+		emailPolicy.Blocked = append(emailPolicy.Blocked, users.DisposableEmailDomains...)
+	}
+	userService := userService.NewUserService(userRepo, ratingRepo, movieRepo, idGenerator, timeProvider, c, logger,
+		userService.WithListRepository(listRepo),
+		userService.WithAvatarStorage(mediaStore, cfg.Storage.SignedURLTTL),
+		userService.WithEmailDomainPolicy(emailPolicy),
+		userService.WithActivityRepository(repository.NewUserActivityRepository(db)),
+		userService.WithGenreHalfLife(cfg.Recommendations.GenreHalfLife),
+		userService.WithMergeRepository(repository.NewUserMergeRepository(db)),
+		userService.WithSettingsRepository(repository.NewUserSettingsRepository(db)),
+	)
+	ratingOptions := []ratingService.Option{
+		ratingService.WithBayesianConfig(ratingService.BayesianConfig{
+			MinVotes:      cfg.Ratings.BayesianMinVotes,
+			GlobalAverage: ratingService.DefaultGlobalAverage,
+			ConfidenceK:   cfg.Ratings.BayesianConfidenceK,
+			// Withholds averages in stats, comparisons and movie details
+			DisplayMinRatings: cfg.Ratings.DisplayMinRatings,
+		}),
+		ratingService.WithReviewLimits(rating.ReviewLimits{
+			MinLength: cfg.Ratings.ReviewMinLength,
+			MaxLength: cfg.Ratings.ReviewMaxLength,
+		}),
+		ratingService.WithGlobalAverageTTL(cfg.Ratings.GlobalAverageTTL),
+		ratingService.WithRestoreWindow(cfg.Ratings.RestoreWindow),
+		ratingService.WithComparisonRepository(repository.NewComparisonRepository(db)),
+		ratingService.WithCache(c),
+	}
+	if cfg.Ratings.VolatilityEnabled {
+		ratingOptions = append(ratingOptions, ratingService.WithVolatilityDetection(rating.VolatilityConfig{
+			Window:          cfg.Ratings.VolatilityWindow,
+			Baseline:        cfg.Ratings.VolatilityBaseline,
+			MinSpikeRatings: cfg.Ratings.VolatilityMinRatings,
+			SpikeFactor:     cfg.Ratings.VolatilitySpikeFactor,
+			ExcludeFlagged:  cfg.Ratings.VolatilityExcludeFlagged,
+		}, ratingService.NewLogNotifier(logger)))
+	}
+	if cfg.Ratings.TrustEnabled {
+		ratingOptions = append(ratingOptions, ratingService.WithTrustWeighting(users.TrustConfig{
+			FullTrustAge:     cfg.Ratings.TrustFullAge,
+			FullTrustRatings: cfg.Ratings.TrustFullRatings,
+			AgeWeight:        cfg.Ratings.TrustAgeWeight,
+			ActivityWeight:   cfg.Ratings.TrustActivityWeight,
+			VerifiedWeight:   cfg.Ratings.TrustVerifiedWeight,
+			ReportPenalty:    cfg.Ratings.TrustReportPenalty,
+			MinTrust:         cfg.Ratings.TrustMin,
+		}))
+	}
+	jobService := jobsService.NewJobService(jobRepo, idGenerator, timeProvider, logger,
+		jobsService.WithWorkers(cfg.Jobs.Workers),
+		jobsService.WithPollInterval(cfg.Jobs.PollInterval),
+		jobsService.WithHeartbeatInterval(cfg.Jobs.HeartbeatInterval),
+		jobsService.WithStaleAfter(cfg.Jobs.StaleAfter),
+	)
+	ratingImports := ratingService.NewImportService(repository.NewRatingImporter(db), jobService, mediaStore, idGenerator, timeProvider, logger,
+		ratingService.WithImportBatchSize(cfg.Ratings.ImportBatchSize),
+		ratingService.WithImportMaxBytes(cfg.Ratings.ImportMaxBytes),
+		ratingService.WithImportReviewLimits(rating.ReviewLimits{MaxLength: cfg.Ratings.ReviewMaxLength}),
+	)
+	jobService.Register(ratingService.ImportJobType, ratingImports.RunImport, ratingService.ImportRetryPolicy())
+	ratingHistory := ratingService.NewHistoryService(ratingRepo, repository.NewMovieMatcher(db), repository.NewRatingImporter(db), idGenerator, timeProvider, logger,
+		ratingService.WithHistoryMaxBytes(cfg.Ratings.HistoryMaxBytes),
+		ratingService.WithHistoryMaxEntries(cfg.Ratings.HistoryMaxEntries),
+		ratingService.WithHistoryReviewLimits(rating.ReviewLimits{MaxLength: cfg.Ratings.ReviewMaxLength}),
+	)
+	retentionRuns := retentionService.NewRetentionService(repository.NewRetentionRepository(db), mediaStore, jobService, timeProvider, logger,
+		retentionService.WithPolicy(retentionService.Policy{
+			Sessions:       cfg.Retention.Sessions,
+			MergeAudit:     cfg.Retention.MergeAudit,
+			Jobs:           cfg.Retention.Jobs,
+			DeletedRatings: cfg.Retention.DeletedRatings,
+			OrphanedMedia:  cfg.Retention.OrphanedMedia,
+		}),
+		retentionService.WithBatchSize(cfg.Retention.BatchSize),
+	)
+	jobService.Register(retentionService.JobType, retentionRuns.Run, jobs.DefaultRetryPolicy())
+	if cfg.Retention.Interval > 0 {
+		jobService.Schedule(retentionService.JobType, cfg.Retention.Interval, retentionService.RunOptions{DryRun: cfg.Retention.DryRun})
+	}
+	ratings := ratingService.NewRatingService(ratingRepo, idGenerator, timeProvider, logger, ratingOptions...)
+	if cfg.Ratings.GlobalAverageRefreshInterval > 0 {
+		go ratings.StartGlobalAverageUpdater(context.Background(), cfg.Ratings.GlobalAverageRefreshInterval)
+	}
+	// Validated with the rest of the config, so it cannot fail here
+	kidsPolicy, _ := movies.NewContentPolicy(cfg.Content.KidsTerritory, cfg.Content.KidsMaxCertification)
+	rankingMetric, _ := movies.ParseRankingMetric(cfg.Ratings.RankingMetric)
+	// Searches, collections and recommendations rank with the prior weight
+	// of the rating service, which follows the reloaded configuration
+	confidenceK := func() float64 { return ratings.GetBayesianConfig().ConfidenceK }
+	statsHistory := ratingService.NewStatsHistoryService(repository.NewStatsHistoryRepository(db), movieRepo, ratings, timeProvider, logger,
+		ratingService.WithStatsDownsampleAfter(cfg.Ratings.StatsDownsampleAfter),
+	)
+	jobService.Register(ratingService.StatsSnapshotJobType, statsHistory.RunSnapshot, jobs.DefaultRetryPolicy())
+	if cfg.Ratings.StatsSnapshotInterval > 0 {
+		jobService.Schedule(ratingService.StatsSnapshotJobType, cfg.Ratings.StatsSnapshotInterval, nil)
+	}
+	movieService := movieService.NewMovieService(movieRepo, idGenerator, timeProvider, logger,
+		movieService.WithRatingRepository(ratingRepo),
+		movieService.WithStatsDisplay(ratings),
+		movieService.WithTranslationRepository(translationRepo),
+		movieService.WithReleaseRepository(releaseRepo),
+		movieService.WithProviderRepository(providerRepo),
+		movieService.WithCertificationRepository(certificationRepo),
+		movieService.WithPeopleRepository(peopleRepo),
+		movieService.WithMergeRepository(mergeRepo),
+		movieService.WithAggregateRepository(aggregateRepo),
+		movieService.WithPosterStorage(posterRepo, mediaStore, cfg.Storage.SignedURLTTL),
+		movieService.WithSlugRepository(slugRepo),
+		movieService.WithCache(c),
+		movieService.WithBayesianConfidenceK(confidenceK),
+		movieService.WithRankingMetric(rankingMetric),
+		movieService.WithKidsPolicy(kidsPolicy),
+	)
+	peopleService := peopleService.NewPeopleService(peopleRepo, movieRepo, idGenerator, timeProvider, logger)
+	collectionService := collectionService.NewCollectionService(collectionRepo, idGenerator, timeProvider, logger,
+		collectionService.WithBayesianConfidenceK(confidenceK),
+	)
+
+	listService := listService.NewListService(listRepo, idGenerator, timeProvider, logger)
+	adminService := adminService.NewAdminService(summaryRepo, userRepo, c, timeProvider, logger)
+	sessionService := sessionService.NewSessionService(sessionRepo, idGenerator, timeProvider, logger)
+	anonymousService := anonymousService.NewAnonymousService(repository.NewAnonymousRepository(db), idGenerator, timeProvider, logger,
+		anonymousService.WithReviewLimits(rating.ReviewLimits{
+			MinLength: cfg.Ratings.ReviewMinLength,
+			MaxLength: cfg.Ratings.ReviewMaxLength,
+		}),
+	)
+	usageService := usageService.NewUsageService(repository.NewUsageRepository(db), usageCounters, timeProvider, logger,
+		usageService.WithMonthlyQuota(usage.KindUser, cfg.Usage.UserMonthlyQuota),
+		usageService.WithMonthlyQuota(usage.KindAPIKey, cfg.Usage.APIKeyMonthlyQuota),
+	)
+	viewService := viewService.NewViewService(repository.NewViewRepository(db), movieRepo, usageCounters, viewRecents, timeProvider, logger)
+	partnerService := partnerService.NewPartnerService(repository.NewPartnerRepository(db), ratings, movieRepo, idGenerator, timeProvider, logger)
+	homeService := recommendationService.NewRecommendationService(repository.NewRecommendationRepository(db), userRepo, timeProvider, logger,
+		recommendationService.WithCache(c),
+		recommendationService.WithBayesianConfidenceK(confidenceK),
+		recommendationService.WithShelfSize(cfg.Recommendations.ShelfSize),
+		recommendationService.WithColdStartRatings(cfg.Recommendations.ColdStartRatings),
+		recommendationService.WithGenreHalfLife(cfg.Recommendations.GenreHalfLife),
+		recommendationService.WithKidsPolicy(kidsPolicy, certificationRepo),
+		recommendationService.WithHeadToHead(ratings),
+	)
 
 	// Handlers
-	userHandler := userHandlers.NewHandler(userService, logger, cfg.JWT.Secret)
-	movieHandler := movieHandlers.NewHandler(movieService, logger)
-	ratingHandler := ratingHandlers.NewHandler(ratingService, logger)
-	userProfileHandler := userHandlers.NewProfileHandler(userService, logger)
+	userHandlerOptions := []userHandlers.Option{
+		userHandlers.WithMaxAvatarBytes(cfg.Storage.MaxAvatarBytes),
+		userHandlers.WithSessions(sessionService),
+		userHandlers.WithHome(homeService),
+		userHandlers.WithRecentAuthWindow(cfg.JWT.RecentAuthWindow),
+	}
+	// The limiter is always installed so a reload can enable it; a limit of
+	// 0 lets every request through
+	signupLimiter := ratelimit.New(cfg.Signup.RateLimit, cfg.Signup.RateWindow)
+	userHandlerOptions = append(userHandlerOptions, userHandlers.WithSignupRateLimit(signupLimiter))
+	if cfg.Signup.CaptchaVerifyURL != "" {
+		userHandlerOptions = append(userHandlerOptions, userHandlers.WithCaptchaVerifier(captcha.NewSiteVerifier(cfg.Signup.CaptchaVerifyURL, cfg.Signup.CaptchaSecret)))
+		logger.Info("Requiring CAPTCHA on signup")
+	}
+	tokenKeys, err := newTokenKeys(cfg.JWT)
+	if err != nil {
+		logger.Error("Failed to initialize token signing keys", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if id := tokenKeys.SigningKeyID(); id != "" {
+		logger.Info("Signing tokens with a named key", slog.String("key_id", id))
+	}
+	internalCallers, err := middleware.ParseInternalCallers(cfg.Internal.CallerTokens, cfg.Internal.Impersonators)
+	if err != nil {
+		logger.Error("Failed to initialize internal callers", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if cfg.OIDC.Enabled() {
+		providers, err := newOIDCProviders(cfg.OIDC)
+		if err != nil {
+			logger.Error("Failed to initialize sign-in providers", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		signIn := oidcService.NewOIDCService(providers, repository.NewIdentityRepository(db), userService,
+			cfg.OIDC.StateSecret, cfg.OIDC.CallbackURL, timeProvider, logger,
+			oidcService.WithDefaultRole(users.Role(cfg.OIDC.DefaultRole)),
+			oidcService.WithReturnURLs(cfg.OIDC.ReturnURLs...),
+		)
+		userHandlerOptions = append(userHandlerOptions, userHandlers.WithOIDC(signIn))
+		logger.Info("Enabled social sign-in", slog.Any("providers", signIn.Providers()))
+	}
+	userHandler := userHandlers.NewHandler(userService, httpLogger, tokenKeys, userHandlerOptions...)
+	// Validated with the rest of the config, so neither can fail here
+	fxBase, _ := money.ParseCurrency(cfg.FX.BaseCurrency)
+	fxRates, _ := money.ParseRates(cfg.FX.Rates)
+	movieHandlerOptions := []movieHandlers.Option{
+		movieHandlers.WithAuthentication(tokenKeys, sessionService),
+		movieHandlers.WithMaxPosterBytes(cfg.Storage.MaxUploadBytes),
+		movieHandlers.WithCurrencyConverter(money.NewConverter(money.NewStaticRates(fxBase, fxRates))),
+	}
+	ratingHandlerOptions := []ratingHandlers.Option{
+		ratingHandlers.WithAuthentication(tokenKeys, sessionService),
+		ratingHandlers.WithHistory(ratingHistory),
+		ratingHandlers.WithStatsHistory(statsHistory),
+		ratingHandlers.WithMaxHistoryBytes(cfg.Ratings.HistoryMaxBytes),
+	}
+	if cfg.ResponseCache.FreshFor > 0 {
+		responseCache := middleware.NewResponseCache(c, timeProvider, httpLogger,
+			middleware.WithResponseFreshFor(cfg.ResponseCache.FreshFor),
+			middleware.WithResponseStaleFor(cfg.ResponseCache.StaleFor),
+		)
+		movieHandlerOptions = append(movieHandlerOptions, movieHandlers.WithResponseCache(responseCache.Handler))
+		ratingHandlerOptions = append(ratingHandlerOptions, ratingHandlers.WithResponseCache(responseCache.Handler))
+	}
+	movieHandler := movieHandlers.NewHandler(movieService, httpLogger, movieHandlerOptions...)
+	ratingHandler := ratingHandlers.NewHandler(ratings, httpLogger, ratingHandlerOptions...)
+	peopleHandler := peopleHandlers.NewHandler(peopleService, httpLogger,
+		peopleHandlers.WithAuthentication(tokenKeys, sessionService),
+	)
+	collectionHandler := collectionHandlers.NewHandler(collectionService, httpLogger,
+		collectionHandlers.WithAuthentication(tokenKeys, sessionService),
+	)
+	listHandler := listHandlers.NewHandler(listService, httpLogger, tokenKeys,
+		listHandlers.WithSessionValidator(sessionService),
+	)
+	userProfileHandler := userHandlers.NewProfileHandler(userService, httpLogger,
+		userHandlers.WithProfileAuthentication(tokenKeys, sessionService),
+	)
+	// Starting a device is as cheap a way to a new identity as signing up,
+	// so both draw from the same per-IP budget
+	anonymousHandler := anonymousHandlers.NewHandler(anonymousService, httpLogger, tokenKeys,
+		anonymousHandlers.WithSessionValidator(sessionService),
+		anonymousHandlers.WithStartRateLimit(signupLimiter),
+	)
+	viewHandler := viewHandlers.NewHandler(viewService, httpLogger, tokenKeys,
+		viewHandlers.WithSessionValidator(sessionService),
+	)
+	// The warmer requests movie stats through the router, which is built
+	// from the handlers below
+	var appRouter *rest.Router
+	warmerOptions := []warmup.Option{
+		warmup.WithTopMovies(cfg.Warmup.TopMovies),
+		warmup.WithUsers(userService),
+	}
+	// Without Postgres no job worker runs, so every warmup runs inline
+	if withPostgres {
+		warmerOptions = append(warmerOptions, warmup.WithJobQueue(jobService, warmup.DefaultInlineLimit))
+	}
+	warmer := warmup.NewWarmer(ratingRepo, ratings, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		appRouter.Handler().ServeHTTP(w, r)
+	}), cacheLogger, warmerOptions...)
+	jobService.Register(warmup.JobType, warmer.RunJob, jobs.DefaultRetryPolicy())
+	adminHandler := adminHandlers.NewHandler(movieService, adminService, httpLogger, tokenKeys,
+		adminHandlers.WithSessionValidator(sessionService),
+		adminHandlers.WithLogLevels(logLevels),
+		adminHandlers.WithBodyLogging(logBodies),
+		adminHandlers.WithGlobalAverage(ratings),
+		adminHandlers.WithBayesianConfig(ratings),
+		adminHandlers.WithRatingImports(ratingImports),
+		adminHandlers.WithJobs(jobService),
+		adminHandlers.WithRetention(retentionRuns),
+		adminHandlers.WithPartners(partnerService),
+		adminHandlers.WithUsage(usageService),
+		adminHandlers.WithCacheWarmer(warmer),
+	)
+	// Each key carries its own per-minute limit
+	partnerHandler := partnerHandlers.NewHandler(partnerService, httpLogger,
+		partnerHandlers.WithKeyRateLimiter(ratelimit.New(0, time.Minute)),
+		partnerHandlers.WithUsageMeter(usageService),
+	)
+
+	// Callers without a token share a strict budget per client IP
+	anonymousLimiter := ratelimit.New(cfg.Public.AnonymousRateLimit, cfg.Public.AnonymousRateWindow)
 
 	// Router with all handlers
-	appRouter := rest.NewRouter(
-		logger,
+	routerOptions := []rest.RouterOption{
 		rest.WithCORS(rest.DefaultCORSOptions()),
 		rest.WithHandlers(
 			userHandler,
 			movieHandler,
 			ratingHandler,
+			peopleHandler,
+			collectionHandler,
+			listHandler,
 			userProfileHandler,
+			adminHandler,
+			anonymousHandler,
+			viewHandler,
 		),
-	)
+		// First, so the requests it rejects are neither logged nor metered
+		rest.WithAPIMiddleware(middleware.Localize()),
+		rest.WithAPIMiddleware(middleware.InternalCallers(internalCallers, response.NewWriter(httpLogger), httpLogger)),
+		rest.WithAPIMiddleware(middleware.PublicAccess(publicRoutes, anonymousLimiter, response.NewWriter(httpLogger), tokenKeys)),
+		rest.WithAPIMiddleware(middleware.LogBodies(logBodies, httpLogger)),
+		rest.WithAPIMiddleware(middleware.Metered(usageService, response.NewWriter(httpLogger), middleware.BearerPrincipal(tokenKeys))),
+		rest.WithAPIMiddleware(middleware.ContentMode(userRepo, response.NewWriter(httpLogger), tokenKeys)),
+		rest.WithAPIMiddleware(middleware.RecordViews(viewService, tokenKeys)),
+		rest.WithMountedHandlers("/partner/v1", partnerHandler),
+		rest.WithMountedHandlers("/.well-known", jwksHandlers.NewHandler(tokenKeys)),
+	}
+	if cfg.Server.LegacyRoutes {
+		// Validated with the rest of the config, so it cannot fail here
+		var sunset time.Time
+		if cfg.Server.LegacyRoutesSunset != "" {
+			sunset, _ = time.Parse(time.DateOnly, cfg.Server.LegacyRoutesSunset)
+		}
+		routerOptions = append(routerOptions, rest.WithLegacyRoutes(sunset))
+	}
+	// Local media is served by the API itself; S3 hands out its own URLs
+	if cfg.Storage.Backend == "local" {
+		routerOptions = append(routerOptions, rest.WithHandlers(mediaHandlers.NewHandler(mediaStore, mediaSigner, httpLogger)))
+	}
+	// The sitemap and feeds are served outside the API, where crawlers and
+	// feed readers look for them
+	if cfg.SEO.SiteURL != "" {
+		seo := seoService.NewSEOService(movieRepo, ratings, cfg.SEO.SiteURL, logger,
+			seoService.WithPageSize(cfg.SEO.SitemapPageSize),
+			seoService.WithCache(c),
+		)
+		routerOptions = append(routerOptions,
+			rest.WithHandlers(seoHandlers.NewHandler(seo, httpLogger)),
+			rest.WithMountedHandlers("/sitemap.xml", seoHandlers.NewSitemapHandler(seo, httpLogger)),
+			rest.WithMountedHandlers("/feeds", feedHandlers.NewHandler(
+				feedService.NewFeedService(movieRepo, ratingRepo, cfg.SEO.SiteURL, logger, feedService.WithCache(c)),
+				httpLogger,
+			)),
+		)
+	}
+	appRouter = rest.NewRouter(httpLogger, routerOptions...)
+
+	// Settings that can change without a restart, on SIGHUP or every
+	// CONFIG_RELOAD_INTERVAL
+	configWatcher := config.NewWatcher(configLoader, cfg, logger)
+	appliedLogLevel := cfg.LogLevel
+	configWatcher.OnReload(func(next config.Configuration) {
+		// Only a changed LOG_LEVEL replaces a level set through /admin/logging
+		if next.LogLevel != appliedLogLevel {
+			if level, err := config.ParseLogLevel(next.LogLevel); err == nil {
+				logLevels.SetBase(level)
+				appliedLogLevel = next.LogLevel
+			}
+		}
+		signupLimiter.SetLimit(next.Signup.RateLimit, next.Signup.RateWindow)
+		anonymousLimiter.SetLimit(next.Public.AnonymousRateLimit, next.Public.AnonymousRateWindow)
+
+		bayesian := ratings.GetBayesianConfig()
+		bayesian.MinVotes = next.Ratings.BayesianMinVotes
+		bayesian.ConfidenceK = next.Ratings.BayesianConfidenceK
+		bayesian.DisplayMinRatings = next.Ratings.DisplayMinRatings
+		ratings.SetBayesianConfig(bayesian)
+
+		logger.Info("Applied reloaded configuration")
+	})
+	go configWatcher.Run(context.Background())
+
+	// Job workers run until the server has shut down; a job interrupted
+	// by the shutdown is queued again for the next worker. The queue and
+	// usage counts live in Postgres, so neither runs with STORAGE=memory or
+	// STORAGE=sqlite.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		defer close(jobsDone)
+		if withPostgres {
+			jobService.Run(jobsCtx)
+		}
+	}()
 
 	// Server
-	srv, err := server.NewServer(
-		cfg,
-		logger,
-		server.WithRouter(appRouter),
-	)
+	serverOptions := []server.ServerOption{server.WithRouter(appRouter)}
+	if cfg.Server.LoadShedding {
+		serverOptions = append(serverOptions, server.WithLoadShedder(server.NewLoadShedder(response.NewWriter(httpLogger),
+			server.WithConcurrencyLimits(cfg.Server.LoadShedMinConcurrent, cfg.Server.LoadShedMaxConcurrent),
+			server.WithTargetLatency(cfg.Server.LoadShedTargetLatency),
+		)))
+		logger.Info("Shedding load over the concurrency limit")
+	}
+	if cfg.Warmup.Enabled {
+		serverOptions = append(serverOptions, server.WithWarmup(warmer.Run, cfg.Warmup.Timeout))
+	}
+	srv, err := server.NewServer(cfg, logger, serverOptions...)
 	if err != nil {
 		logger.Error("Failed to create server", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	appRouter.SetHealthProvider(srv)
 
-	if err := srv.Run(context.Background()); err != nil {
+	// Usage and views are flushed until the server has shut down, then
+	// once more so the last counts buffered in memory are kept
+	usageCtx, stopUsage := context.WithCancel(context.Background())
+	usageDone := make(chan struct{})
+	go func() {
+		defer close(usageDone)
+		if withPostgres {
+			usageService.StartFlusher(usageCtx, cfg.Usage.FlushInterval)
+		}
+	}()
+	viewsDone := make(chan struct{})
+	go func() {
+		defer close(viewsDone)
+		if withPostgres {
+			viewService.StartFlusher(usageCtx, cfg.Views.FlushInterval)
+		}
+	}()
+
+	err = srv.Run(context.Background())
+	stopJobs()
+	stopUsage()
+	<-jobsDone
+	<-usageDone
+	<-viewsDone
+	if err != nil {
 		logger.Error("Server failed", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 }
+
+// openSQLite opens the SQLite database at path, creating its directory
+// if needed, and applies the SQLite migrations
+func openSQLite(path string, opts []postgres.Option, logger *slog.Logger) (*sqlx.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := postgres.NewSQLiteConnection(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate.Up(db.DB, repository.SQLiteMigrations(), logger); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// newKeyring builds the column encryption keyring from ENCRYPTION_* settings
+func newKeyring(cfg config.EncryptionConfig) (*encryption.Keyring, error) {
+	keys, err := encryption.ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(cfg.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_INDEX_KEY is not valid base64: %w", err)
+	}
+	if len(indexKey) != encryption.KeySize {
+		return nil, fmt.Errorf("ENCRYPTION_INDEX_KEY must be %d bytes", encryption.KeySize)
+	}
+	return encryption.NewKeyring(cfg.ActiveKey, keys, encryption.WithIndexKey(indexKey))
+}
+
+// newTokenKeys builds the token signing and verification keys from JWT_*
+// settings
+func newTokenKeys(cfg config.JWTConfig) (*tokens.Keys, error) {
+	keys, err := tokens.ParseKeys(cfg.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_KEYS: %w", err)
+	}
+	return tokens.NewKeys(cfg.Secret, cfg.SigningKeyID, keys,
+		tokens.WithIssuer(cfg.Issuer),
+		tokens.WithAudience(cfg.Audience),
+		tokens.WithExpiry(cfg.Expiry),
+	)
+}
+
+// newOIDCProviders builds the social sign-in providers that have a client ID
+// configured in OIDC_*
+func newOIDCProviders(cfg config.OIDCConfig) ([]*oidc.Provider, error) {
+	var providers []*oidc.Provider
+	if cfg.GoogleClientID != "" {
+		providers = append(providers, oidc.NewProvider("google", oidc.GoogleIssuer, cfg.GoogleClientID,
+			oidc.WithClientSecret(cfg.GoogleClientSecret),
+		))
+	}
+	if cfg.AppleClientID != "" {
+		key, err := oidc.ReadAppleKey(cfg.ApplePrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDC_APPLE_PRIVATE_KEY_PATH: %w", err)
+		}
+		// Apple posts the callback and only shares the user's name there
+		providers = append(providers, oidc.NewProvider("apple", oidc.AppleIssuer, cfg.AppleClientID,
+			oidc.WithClientSecretFunc(oidc.AppleClientSecret(cfg.AppleTeamID, cfg.AppleKeyID, cfg.AppleClientID, key)),
+			oidc.WithScopes("openid", "email", "name"),
+			oidc.WithFormPost(),
+		))
+	}
+	return providers, nil
+}
+
+// newMediaStorage builds the storage backend selected by STORAGE_BACKEND. The
+// signer is only returned for the local backend with a signing key.
+func newMediaStorage(cfg config.StorageConfig) (storage.Storage, *storage.URLSigner, error) {
+	switch cfg.Backend {
+	case "local":
+		var signer *storage.URLSigner
+		if cfg.URLSigningKey != "" {
+			signer = storage.NewURLSigner(cfg.URLSigningKey)
+		}
+		store, err := storage.NewLocalStorage(storage.LocalConfig{
+			Root:    cfg.LocalDir,
+			BaseURL: cfg.MediaBaseURL,
+			Signer:  signer,
+		})
+		return store, signer, err
+	case "s3":
+		store, err := storage.NewS3Storage(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+			PublicBaseURL:   cfg.S3PublicBaseURL,
+		})
+		return store, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
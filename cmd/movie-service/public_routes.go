@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"thermondo/internal/platform/http/middleware"
+)
+
+// publicMovieFields are the fields of a movie callers without a token get:
+// enough for a listing or a landing page, without the budget, box office
+// or external IDs
+var publicMovieFields = []string{
+	"id", "slug", "title", "description", "release_year", "genre", "director",
+	"duration_mins", "rating", "language", "country", "poster_url", "locale",
+}
+
+// publicListFields are those of a page of movies
+var publicListFields = append(prefixed("movies.", publicMovieFields...),
+	"total", "limit", "offset", "has_more", "query",
+)
+
+// publicDetailFields are those of a movie's page, with its average rating
+var publicDetailFields = append(prefixed("", publicMovieFields...),
+	"stats.average_score", "stats.total_ratings", "stats.withheld", "stats.notice",
+)
+
+// publicUpcomingFields are those of a page of upcoming releases, each with
+// its movie
+var publicUpcomingFields = append(prefixed("releases.movie.", publicMovieFields...),
+	"releases.region", "releases.type", "releases.release_date",
+	"total", "limit", "offset", "has_more",
+)
+
+// publicRatingFields are those of a page of a movie's ratings, without who
+// wrote them
+var publicRatingFields = append(prefixed("ratings.",
+	"id", "movie_id", "score", "review", "review_html", "contains_spoilers",
+	"contains_adult_language", "created_at",
+), "total", "limit", "offset", "has_more")
+
+// publicRoutes is the route policy table: the read endpoints callers
+// without a token may use, under PUBLIC_ANONYMOUS_RATE_LIMIT, and the
+// fields they get. Routes left out are not affected, so every read that
+// serves movies belongs here; those without fields already answer with
+// summaries.
+var publicRoutes = []middleware.RoutePolicy{
+	{Method: http.MethodGet, Pattern: "/movies", Fields: publicListFields},
+	{Method: http.MethodGet, Pattern: "/search/movies", Fields: publicListFields},
+	{Method: http.MethodGet, Pattern: "/movies/random", Fields: publicListFields},
+	{Method: http.MethodGet, Pattern: "/movies/upcoming", Fields: publicUpcomingFields},
+	{Method: http.MethodGet, Pattern: "/movies/decades"},
+	{Method: http.MethodGet, Pattern: "/collections/{id}"},
+	{Method: http.MethodGet, Pattern: "/people/{id}/movies"},
+	{Method: http.MethodGet, Pattern: "/movies/{movieId}/ratings", Fields: publicRatingFields},
+	{Method: http.MethodGet, Pattern: "/movies/{id}", Fields: publicDetailFields},
+	{Method: http.MethodGet, Pattern: "/movies/slug/{slug}", Fields: publicDetailFields},
+	{Method: http.MethodGet, Pattern: "/search/movies/{id}", Fields: publicDetailFields},
+	{Method: http.MethodGet, Pattern: "/movies/{movieId}/stats", Fields: []string{
+		"movie_id", "average_score", "total_ratings", "audience_score", "critic_score", "withheld", "notice",
+	}},
+}
+
+func prefixed(prefix string, fields ...string) []string {
+	out := make([]string, len(fields))
+	for i, field := range fields {
+		out[i] = prefix + field
+	}
+	return out
+}
@@ -1,20 +1,45 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/joeshaw/envdecode"
 	"github.com/joho/godotenv"
 )
 
 // Configuration struct to hold all the configuration for the application
 type Configuration struct {
-	Server   ServerConfig
-	Database Postgres
-	JWT      JWTConfig
-	Redis    RedisConfig
-	AppName  string `env:"APP_NAME,default=[thermondo-backend]: "`
+	Server          ServerConfig
+	Database        Postgres
+	JWT             JWTConfig
+	Internal        InternalConfig
+	OIDC            OIDCConfig
+	Redis           RedisConfig
+	Storage         StorageConfig
+	Ratings         RatingsConfig
+	Signup          SignupConfig
+	Jobs            JobsConfig
+	Retention       RetentionConfig
+	Encryption      EncryptionConfig
+	Recommendations RecommendationsConfig
+	Usage           UsageConfig
+	Views           ViewsConfig
+	FX              FXConfig
+	Content         ContentConfig
+	ResponseCache   ResponseCacheConfig
+	Warmup          WarmupConfig
+	SEO             SEOConfig
+	Public          PublicConfig
+	AppName         string `env:"APP_NAME,default=[thermondo-backend]: "`
+	LogLevel        string `env:"LOG_LEVEL,default=info"`
+	// DataStore keeps movies, ratings and users in "postgres", in a
+	// "sqlite" file at Database.SQLitePath or, for a demo that needs no
+	// database, in process ("memory")
+	DataStore string `env:"STORAGE,default=postgres"`
+	// ReloadInterval re-reads the config providers periodically; 0 only
+	// reloads on SIGHUP
+	ReloadInterval time.Duration `env:"CONFIG_RELOAD_INTERVAL,default=0s"`
 }
 
 type ServerConfig struct {
@@ -25,6 +50,17 @@ type ServerConfig struct {
 	IdleTimeout         time.Duration `env:"SERVER_IDLE_TIMEOUT,default=10s"`
 	ShutdownTimeout     time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT,default=10s"`
 	ShutdownGracePeriod time.Duration `env:"SERVER_SHUTDOWN_GRACE_PERIOD,default=10s"`
+	LegacyErrorFormat   bool          `env:"SERVER_LEGACY_ERROR_FORMAT,default=false"` // Render errors as {"error": "..."} instead of problem+json
+	// LoadShedding answers requests over an adaptive concurrency limit with
+	// 503, lists and searches first; writes and health checks always pass
+	LoadShedding          bool          `env:"SERVER_LOAD_SHEDDING,default=false"`
+	LoadShedMinConcurrent int           `env:"SERVER_LOAD_SHED_MIN_CONCURRENT,default=10"`
+	LoadShedMaxConcurrent int           `env:"SERVER_LOAD_SHED_MAX_CONCURRENT,default=200"`
+	LoadShedTargetLatency time.Duration `env:"SERVER_LOAD_SHED_TARGET_LATENCY,default=500ms"` // The limit shrinks while requests take longer
+	// LegacyRoutes also serves the API at the root, as it was before
+	// /api/v1, with Deprecation and Sunset headers on every response
+	LegacyRoutes       bool   `env:"SERVER_LEGACY_ROUTES,default=true"`
+	LegacyRoutesSunset string `env:"SERVER_LEGACY_ROUTES_SUNSET"` // YYYY-MM-DD the root routes go away; unset while undecided
 }
 
 type Postgres struct {
@@ -32,11 +68,80 @@ type Postgres struct {
 	MaxIdleConns int    `env:"POSTGRES_MAX_IDLE_CONNECTIONS,default=20"`
 	MaxOpenConns int    `env:"POSTGRES_MAX_OPEN_CONNECTIONS,default=20"`
 	HealthCheck  bool   `env:"POSTGRES_HEALTH_CHECK,default=false"`
+	// Connections are closed after ConnMaxLifetime, or after ConnMaxIdleTime
+	// unused; 0 keeps them forever
+	ConnMaxLifetime time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME,default=30m"`
+	ConnMaxIdleTime time.Duration `env:"POSTGRES_CONN_MAX_IDLE_TIME,default=5m"`
+	// PoolStatsInterval reports pool usage to the log and /metrics; 0
+	// disables it
+	PoolStatsInterval time.Duration `env:"POSTGRES_POOL_STATS_INTERVAL,default=1m"`
+	// QueryLog times every query; those taking at least SlowQueryThreshold
+	// are logged at warn level, the rest at debug level
+	QueryLog           bool          `env:"POSTGRES_QUERY_LOG,default=false"`
+	SlowQueryThreshold time.Duration `env:"POSTGRES_SLOW_QUERY_THRESHOLD,default=200ms"`
+	// SQLitePath is the database file used with STORAGE=sqlite
+	SQLitePath string `env:"SQLITE_PATH,default=./data/thermondo.db"`
 }
 
+// JWTConfig configures the tokens issued at login. Tokens are signed with
+// the key JWT_SIGNING_KEY_ID names, or with JWT_SECRET when it is empty, and
+// verified with whichever key signed them; tokens without a key ID are
+// verified with JWT_SECRET. JWT_KEYS lists id:hs256:base64secret and
+// id:rs256:path-to-PEM-private-key entries separated by semicolons. To
+// rotate, add a key, sign with it and keep the old one until the tokens it
+// signed have expired. The public halves of the RS256 keys are served at
+// /.well-known/jwks.json.
 type JWTConfig struct {
 	Secret string        `env:"JWT_SECRET,default=secret"`
-	Expiry time.Duration `env:"JWT_EXPIRY,default=1h"`
+	Expiry time.Duration `env:"JWT_EXPIRY,default=24h"`
+	// Issuer and Audience, when set, are put in every token and required
+	// of every token presented; tokens issued before they were set stop
+	// being accepted
+	Issuer       string   `env:"JWT_ISSUER"`
+	Audience     string   `env:"JWT_AUDIENCE"`
+	Keys         []string `env:"JWT_KEYS"`
+	SigningKeyID string   `env:"JWT_SIGNING_KEY_ID"`
+	// RecentAuthWindow is how recently users must have signed in to link or
+	// unlink sign-in providers, or to get a step-up token without their
+	// password
+	RecentAuthWindow time.Duration `env:"JWT_RECENT_AUTH_WINDOW,default=10m"`
+}
+
+// InternalConfig lists the other services trusted to call the API. Each
+// sends its token in X-Internal-Token; CallerTokens holds name:token
+// entries separated by semicolons. The callers named in Impersonators may
+// also act for a user they name in X-On-Behalf-Of, without the user's
+// bearer token.
+type InternalConfig struct {
+	CallerTokens  []string `env:"INTERNAL_CALLER_TOKENS"`
+	Impersonators []string `env:"INTERNAL_IMPERSONATORS"`
+}
+
+// OIDCConfig enables signing in with Google and Apple, each when its client
+// ID is set. The providers send users back to CallbackURL, with {provider}
+// standing for the provider's name, which must be registered with them.
+// Users signing in for the first time are linked to the account with the
+// email the provider verified, or get a new account with DefaultRole.
+// ReturnURLs lists, separated by semicolons, where apps may have the token
+// delivered instead of as JSON.
+type OIDCConfig struct {
+	CallbackURL        string   `env:"OIDC_CALLBACK_URL"`
+	StateSecret        string   `env:"OIDC_STATE_SECRET"`
+	DefaultRole        string   `env:"OIDC_DEFAULT_ROLE,default=user"`
+	ReturnURLs         []string `env:"OIDC_RETURN_URLS"`
+	GoogleClientID     string   `env:"OIDC_GOOGLE_CLIENT_ID"`
+	GoogleClientSecret string   `env:"OIDC_GOOGLE_CLIENT_SECRET"`
+	// Apple's client secret is a token signed with the private key
+	// downloaded from the developer account, as a .p8 file
+	AppleClientID       string `env:"OIDC_APPLE_CLIENT_ID"`
+	AppleTeamID         string `env:"OIDC_APPLE_TEAM_ID"`
+	AppleKeyID          string `env:"OIDC_APPLE_KEY_ID"`
+	ApplePrivateKeyPath string `env:"OIDC_APPLE_PRIVATE_KEY_PATH"`
+}
+
+// Enabled reports whether any provider is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.GoogleClientID != "" || c.AppleClientID != ""
 }
 
 type RedisConfig struct {
@@ -44,18 +149,261 @@ type RedisConfig struct {
 	Port     int    `env:"REDIS_PORT,default=6379"`
 	Password string `env:"REDIS_PASSWORD,default=password"`
 	DB       int    `env:"REDIS_DB,default=0"`
+	// After BreakerFailures consecutive failures the cache is skipped for
+	// BreakerCoolDown, answering reads with misses, before Redis is tried again
+	BreakerFailures int           `env:"REDIS_BREAKER_FAILURES,default=5"`
+	BreakerCoolDown time.Duration `env:"REDIS_BREAKER_COOL_DOWN,default=30s"`
+	// The hottest entries are also kept in process, up to L1Size of them for
+	// L1TTL at most; writes are broadcast so other instances drop their copy.
+	// An L1Size of 0 reads every entry from Redis.
+	L1Size int           `env:"REDIS_L1_SIZE,default=1000"`
+	L1TTL  time.Duration `env:"REDIS_L1_TTL,default=5s"`
+	// Movie, user and rating IDs that were looked up and not found are
+	// remembered for NotFoundTTL; 0 looks every one up
+	NotFoundTTL time.Duration `env:"REDIS_NOT_FOUND_TTL,default=30s"`
+}
+
+// StorageConfig selects where uploaded media such as movie posters are kept
+type StorageConfig struct {
+	Backend        string `env:"STORAGE_BACKEND,default=local"` // "local" or "s3"
+	MaxUploadBytes int64  `env:"STORAGE_MAX_UPLOAD_BYTES,default=10485760"`
+	MaxAvatarBytes int64  `env:"STORAGE_MAX_AVATAR_BYTES,default=5242880"`
+	// SignedURLTTL is how long signed download URLs stay valid
+	SignedURLTTL time.Duration `env:"STORAGE_SIGNED_URL_TTL,default=15m"`
+
+	LocalDir string `env:"STORAGE_LOCAL_DIR,default=./data/media"`
+	// MediaBaseURL is where the API serves local objects
+	MediaBaseURL string `env:"STORAGE_MEDIA_BASE_URL,default=/api/v1/media"`
+	// URLSigningKey makes local media URLs signed and expiring; leave it
+	// empty to serve them publicly
+	URLSigningKey string `env:"STORAGE_URL_SIGNING_KEY"`
+
+	S3Endpoint        string `env:"STORAGE_S3_ENDPOINT"`
+	S3Bucket          string `env:"STORAGE_S3_BUCKET"`
+	S3Region          string `env:"STORAGE_S3_REGION,default=us-east-1"`
+	S3AccessKeyID     string `env:"STORAGE_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"STORAGE_S3_SECRET_ACCESS_KEY"`
+	S3PathStyle       bool   `env:"STORAGE_S3_PATH_STYLE,default=false"`
+	// S3PublicBaseURL serves objects from a public bucket or CDN instead of
+	// presigned URLs
+	S3PublicBaseURL string `env:"STORAGE_S3_PUBLIC_BASE_URL"`
+}
+
+// RatingsConfig tunes review bombing detection and trust weighting of movie
+// rating stats, and review validation
+type RatingsConfig struct {
+	VolatilityEnabled        bool          `env:"RATINGS_VOLATILITY_ENABLED,default=false"`
+	VolatilityWindow         time.Duration `env:"RATINGS_VOLATILITY_WINDOW,default=24h"`
+	VolatilityBaseline       time.Duration `env:"RATINGS_VOLATILITY_BASELINE,default=720h"` // Period before the window that predicts normal activity
+	VolatilityMinRatings     int64         `env:"RATINGS_VOLATILITY_MIN_RATINGS,default=10"`
+	VolatilitySpikeFactor    float64       `env:"RATINGS_VOLATILITY_SPIKE_FACTOR,default=5"`
+	VolatilityExcludeFlagged bool          `env:"RATINGS_VOLATILITY_EXCLUDE_FLAGGED,default=false"`
+
+	// Trust weighting; see users.TrustConfig for the formula
+	TrustEnabled        bool          `env:"RATINGS_TRUST_ENABLED,default=false"`
+	TrustFullAge        time.Duration `env:"RATINGS_TRUST_FULL_AGE,default=720h"`
+	TrustFullRatings    int64         `env:"RATINGS_TRUST_FULL_RATINGS,default=20"`
+	TrustAgeWeight      float64       `env:"RATINGS_TRUST_AGE_WEIGHT,default=0.4"`
+	TrustActivityWeight float64       `env:"RATINGS_TRUST_ACTIVITY_WEIGHT,default=0.3"`
+	TrustVerifiedWeight float64       `env:"RATINGS_TRUST_VERIFIED_WEIGHT,default=0.3"`
+	TrustReportPenalty  float64       `env:"RATINGS_TRUST_REPORT_PENALTY,default=0.25"`
+	TrustMin            float64       `env:"RATINGS_TRUST_MIN,default=0.1"`
+
+	// Bayesian averaging of movie scores; both can be changed by a reload
+	BayesianMinVotes    int64   `env:"RATINGS_BAYESIAN_MIN_VOTES,default=10"`
+	BayesianConfidenceK float64 `env:"RATINGS_BAYESIAN_CONFIDENCE_K,default=25"`
+	// DisplayMinRatings is how many ratings a movie needs before its
+	// averages are shown; 0 always shows them. It can be changed by a reload.
+	DisplayMinRatings int64 `env:"RATINGS_DISPLAY_MIN_RATINGS,default=0"`
+	// RankingMetric ranks the top movies of directors, genres and decades:
+	// bayesian or wilson, the lower bound of the Wilson score interval
+	RankingMetric string `env:"RATINGS_RANKING_METRIC,default=bayesian"`
+	// The global average is read from running totals at most once per TTL,
+	// and the totals are recomputed from scratch every refresh interval; 0
+	// disables the scheduled refresh
+	GlobalAverageTTL             time.Duration `env:"RATINGS_GLOBAL_AVERAGE_TTL,default=1m"`
+	GlobalAverageRefreshInterval time.Duration `env:"RATINGS_GLOBAL_AVERAGE_REFRESH_INTERVAL,default=1h"`
+
+	// Review length in characters; 0 disables a bound
+	ReviewMinLength int `env:"RATINGS_REVIEW_MIN_LENGTH,default=0"`
+	ReviewMaxLength int `env:"RATINGS_REVIEW_MAX_LENGTH,default=5000"`
+
+	// Bulk imports through POST /admin/ratings/import
+	ImportBatchSize int   `env:"RATINGS_IMPORT_BATCH_SIZE,default=500"`
+	ImportMaxBytes  int64 `env:"RATINGS_IMPORT_MAX_BYTES,default=104857600"`
+
+	// Users importing their own history through POST /users/{id}/ratings/import
+	HistoryMaxBytes   int64 `env:"RATINGS_HISTORY_MAX_BYTES,default=10485760"`
+	HistoryMaxEntries int   `env:"RATINGS_HISTORY_MAX_ENTRIES,default=20000"`
+
+	// Daily stats snapshots behind GET /movies/{id}/stats/history; 0
+	// disables them. Older snapshots are thinned out to one per week.
+	StatsSnapshotInterval time.Duration `env:"RATINGS_STATS_SNAPSHOT_INTERVAL,default=24h"`
+	StatsDownsampleAfter  time.Duration `env:"RATINGS_STATS_DOWNSAMPLE_AFTER,default=8760h"`
+
+	// How long a deleted rating can be restored through
+	// POST /ratings/{id}/restore; RETENTION_DELETED_RATINGS purges it later
+	RestoreWindow time.Duration `env:"RATINGS_RESTORE_WINDOW,default=72h"`
+}
+
+// SignupConfig protects user registration from scripted signups. List
+// values are separated by semicolons.
+type SignupConfig struct {
+	RateLimit  int           `env:"SIGNUP_RATE_LIMIT,default=5"` // Registrations per client IP and window; 0 disables the limit
+	RateWindow time.Duration `env:"SIGNUP_RATE_WINDOW,default=1h"`
+
+	BlockDisposable bool     `env:"SIGNUP_BLOCK_DISPOSABLE_DOMAINS,default=true"`
+	BlockedDomains  []string `env:"SIGNUP_BLOCKED_DOMAINS"`
+	AllowedDomains  []string `env:"SIGNUP_ALLOWED_DOMAINS"` // When set, only these domains may register
+
+	// CaptchaVerifyURL is a reCAPTCHA, hCaptcha or Turnstile siteverify
+	// endpoint; leave it empty to not require a CAPTCHA
+	CaptchaVerifyURL string `env:"SIGNUP_CAPTCHA_VERIFY_URL"`
+	CaptchaSecret    string `env:"SIGNUP_CAPTCHA_SECRET"`
+}
+
+// JobsConfig tunes the background job workers
+type JobsConfig struct {
+	Workers      int           `env:"JOBS_WORKERS,default=2"` // Jobs run at once by this process
+	PollInterval time.Duration `env:"JOBS_POLL_INTERVAL,default=5s"`
+	// Running jobs save their progress every heartbeat interval; a job
+	// without a heartbeat for StaleAfter is taken to have lost its worker
+	// and is retried
+	HeartbeatInterval time.Duration `env:"JOBS_HEARTBEAT_INTERVAL,default=5s"`
+	StaleAfter        time.Duration `env:"JOBS_STALE_AFTER,default=2m"`
 }
 
-// LoadConfig loads the configuration from the environment variables
+// RetentionConfig sets how long data is kept once it is no longer used; a
+// period of 0 keeps it forever
+type RetentionConfig struct {
+	// Interval is how often the policy runs as a background job; 0 only
+	// runs it through POST /admin/retention/run
+	Interval  time.Duration `env:"RETENTION_INTERVAL,default=24h"`
+	DryRun    bool          `env:"RETENTION_DRY_RUN,default=false"` // Scheduled runs only count what they would remove
+	BatchSize int           `env:"RETENTION_BATCH_SIZE,default=1000"`
+
+	Sessions      time.Duration `env:"RETENTION_SESSIONS,default=720h"` // After a session expired or was revoked
+	MergeAudit    time.Duration `env:"RETENTION_MERGE_AUDIT,default=0s"`
+	Jobs          time.Duration `env:"RETENTION_JOBS,default=720h"` // After a job finished
+	OrphanedMedia time.Duration `env:"RETENTION_ORPHANED_MEDIA,default=24h"`
+	// DeletedRatings counts from when a rating was deleted
+	DeletedRatings time.Duration `env:"RETENTION_DELETED_RATINGS,default=720h"`
+}
+
+// ResponseCacheConfig tunes the shared cache in front of the busiest read
+// endpoints, GET /movies and GET /movies/{id}/stats. A response is served as
+// is for FreshFor, then for StaleFor more while it is refreshed in the
+// background.
+type ResponseCacheConfig struct {
+	FreshFor time.Duration `env:"RESPONSE_CACHE_FRESH_FOR,default=30s"` // 0 disables the cache
+	StaleFor time.Duration `env:"RESPONSE_CACHE_STALE_FOR,default=5m"`
+}
+
+// WarmupConfig fills caches at startup before the server reports ready: the
+// global average rating and the stats of the TopMovies most rated movies
+type WarmupConfig struct {
+	Enabled   bool          `env:"WARMUP_ENABLED,default=false"`
+	TopMovies int           `env:"WARMUP_TOP_MOVIES,default=100"`
+	Timeout   time.Duration `env:"WARMUP_TIMEOUT,default=30s"` // The server reports ready after it at the latest; 0 waits for the warmup
+}
+
+// SEOConfig publishes the catalog for search engines: /sitemap.xml lists
+// the movie pages of the web frontend at SiteURL, {SiteURL}/movies/{id},
+// and /movies/{id}/jsonld is their schema.org data. The Atom feeds at
+// /feeds/new-movies.atom and /feeds/movie/{id}/reviews.atom link entries to
+// the same pages. All are off while SiteURL is empty.
+type SEOConfig struct {
+	SiteURL         string `env:"SEO_SITE_URL"`
+	SitemapPageSize int    `env:"SEO_SITEMAP_PAGE_SIZE,default=10000"` // Movies per sitemap page, at most 50000
+}
+
+// PublicConfig holds callers without a token, such as the marketing site,
+// to a strict rate limit on the read endpoints the service opens to them
+type PublicConfig struct {
+	AnonymousRateLimit  int           `env:"PUBLIC_ANONYMOUS_RATE_LIMIT,default=30"` // Requests per client IP and window; 0 disables the limit
+	AnonymousRateWindow time.Duration `env:"PUBLIC_ANONYMOUS_RATE_WINDOW,default=1m"`
+}
+
+// RecommendationsConfig tunes the personalized home shelves
+type RecommendationsConfig struct {
+	ShelfSize int `env:"RECOMMENDATIONS_SHELF_SIZE,default=12"` // Movies per shelf at most
+	// ColdStartRatings is how many ratings a user needs before their picks
+	// rely on ratings alone; below it they are blended with the genres and
+	// decades the user chose during onboarding
+	ColdStartRatings int `env:"RECOMMENDATIONS_COLD_START_RATINGS,default=10"`
+	// GenreHalfLife is how long it takes for a rating to count half as much
+	// towards a user's favorite genre in the top picks and the recent
+	// favorite genre of their stats; 0 counts every rating alike
+	GenreHalfLife time.Duration `env:"RECOMMENDATIONS_GENRE_HALF_LIFE,default=2160h"`
+}
+
+// UsageConfig tunes usage metering. Requests are counted per user and per
+// partner API key, buffered in Redis (in memory outside production) and
+// flushed to Postgres every FlushInterval.
+type UsageConfig struct {
+	FlushInterval time.Duration `env:"USAGE_FLUSH_INTERVAL,default=1m"`
+	// Requests allowed per calendar month (UTC); 0 is unlimited
+	UserMonthlyQuota   int64 `env:"USAGE_USER_MONTHLY_QUOTA,default=0"`
+	APIKeyMonthlyQuota int64 `env:"USAGE_API_KEY_MONTHLY_QUOTA,default=0"`
+}
+
+// ViewsConfig tunes view tracking. Views are buffered in Redis (in memory
+// outside production) and rolled up per movie and day into Postgres every
+// FlushInterval, which is also how stale the popular today ranking can be.
+type ViewsConfig struct {
+	FlushInterval time.Duration `env:"VIEWS_FLUSH_INTERVAL,default=1m"`
+}
+
+// FXConfig holds the exchange rates budgets and revenues are converted with
+// for ?currency= reads. Rates quote one unit of BaseCurrency, e.g.
+// FX_RATES=EUR=0.92,GBP=0.79 with FX_BASE_CURRENCY=USD.
+type FXConfig struct {
+	BaseCurrency string `env:"FX_BASE_CURRENCY,default=USD"`
+	Rates        string `env:"FX_RATES"`
+}
+
+// ContentConfig sets what kids mode lets through: movies certified at most
+// KidsMaxCertification in KidsTerritory
+type ContentConfig struct {
+	KidsTerritory        string `env:"CONTENT_KIDS_TERRITORY,default=US"`
+	KidsMaxCertification string `env:"CONTENT_KIDS_MAX_CERTIFICATION,default=PG"`
+}
+
+// EncryptionConfig holds the keys for application-level encryption of
+// sensitive columns. Keys are base64-encoded 32-byte values; ENCRYPTION_KEYS
+// lists id:key entries separated by semicolons. To rotate, add a new key,
+// make it active and keep the old one until every row has been rewrapped.
+type EncryptionConfig struct {
+	Keys      []string `env:"ENCRYPTION_KEYS"`
+	ActiveKey string   `env:"ENCRYPTION_ACTIVE_KEY"`
+	// IndexKey keys the blind index used to look up encrypted emails. It is
+	// not rotated with the other keys.
+	IndexKey string `env:"ENCRYPTION_INDEX_KEY"`
+
+	EncryptEmails bool `env:"ENCRYPTION_EMAILS,default=false"`
+	// BackfillOnStart encrypts leftover plaintext emails and rewraps those
+	// under retired keys in the background at startup
+	BackfillOnStart   bool `env:"ENCRYPTION_BACKFILL_ON_START,default=true"`
+	BackfillBatchSize int  `env:"ENCRYPTION_BACKFILL_BATCH_SIZE,default=500"`
+}
+
+// LoadConfig loads the configuration from the environment variables, a
+// .env file and the providers selected by ProvidersFromEnv
 func LoadConfig() (Configuration, error) {
+	conf, _, err := LoadWithLoader(context.Background())
+	return conf, err
+}
 
+// LoadWithLoader is LoadConfig that also returns the loader, for reloading
+func LoadWithLoader(ctx context.Context) (Configuration, *Loader, error) {
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("Warning: .env file not found, using environment variables")
 	}
 
-	var conf Configuration
-	if err := envdecode.Decode(&conf); err != nil {
-		return Configuration{}, err
+	loader := NewLoader(ProvidersFromEnv()...)
+	conf, err := loader.Load(ctx)
+	if err != nil {
+		return Configuration{}, nil, err
 	}
-	return conf, nil
+	return conf, loader, nil
 }
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeshaw/envdecode"
+	"github.com/joho/godotenv"
+)
+
+// Provider supplies configuration values keyed by environment variable
+// name, e.g. from a file or a secrets manager
+type Provider interface {
+	Name() string
+	Values(ctx context.Context) (map[string]string, error)
+}
+
+// Loader decodes the configuration from the process environment, filled in
+// by its providers. Variables set in the environment always win; between
+// providers, the first one to supply a key wins. Loading again picks up
+// changed provider values, so a Loader can be used for hot reloads.
+type Loader struct {
+	providers []Provider
+
+	mu sync.Mutex
+	// applied are the variables this loader set, which a reload may
+	// change or remove without overriding the real environment
+	applied map[string]bool
+}
+
+func NewLoader(providers ...Provider) *Loader {
+	return &Loader{providers: providers, applied: make(map[string]bool)}
+}
+
+// Load fetches every provider, decodes the configuration and validates it
+func (l *Loader) Load(ctx context.Context) (Configuration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	values := make(map[string]string)
+	for _, p := range l.providers {
+		provided, err := p.Values(ctx)
+		if err != nil {
+			return Configuration{}, fmt.Errorf("config provider %s: %w", p.Name(), err)
+		}
+		for key, value := range provided {
+			if _, ok := values[key]; !ok {
+				values[key] = value
+			}
+		}
+	}
+
+	for key := range l.applied {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+			delete(l.applied, key)
+		}
+	}
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); set && !l.applied[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return Configuration{}, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+		l.applied[key] = true
+	}
+
+	var conf Configuration
+	if err := envdecode.Decode(&conf); err != nil {
+		return Configuration{}, err
+	}
+	if err := conf.Validate(); err != nil {
+		return Configuration{}, err
+	}
+	return conf, nil
+}
+
+// ProvidersFromEnv returns the providers selected by CONFIG_FILE and
+// VAULT_ADDR, plus *_FILE secret references, in precedence order
+func ProvidersFromEnv() []Provider {
+	providers := []Provider{SecretFileProvider{}}
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		providers = append(providers, &VaultProvider{
+			Address: addr,
+			Token:   os.Getenv("VAULT_TOKEN"),
+			Mount:   envOr("VAULT_KV_MOUNT", "secret"),
+			Path:    os.Getenv("VAULT_SECRET_PATH"),
+		})
+	}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		providers = append(providers, FileProvider{Path: path})
+	}
+	return providers
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// FileProvider reads KEY=VALUE lines in .env format
+type FileProvider struct {
+	Path string
+}
+
+func (p FileProvider) Name() string {
+	return "file " + p.Path
+}
+
+func (p FileProvider) Values(ctx context.Context) (map[string]string, error) {
+	return godotenv.Read(p.Path)
+}
+
+// SecretFileProvider resolves NAME_FILE variables to the contents of the
+// file they point at, as NAME. This is how Docker and Kubernetes secrets
+// are usually mounted.
+type SecretFileProvider struct{}
+
+func (SecretFileProvider) Name() string {
+	return "secret files"
+}
+
+func (SecretFileProvider) Values(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, entry := range os.Environ() {
+		key, path, _ := strings.Cut(entry, "=")
+		name, ok := strings.CutSuffix(key, "_FILE")
+		if !ok || name == "" || path == "" || name == "CONFIG" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		values[name] = strings.TrimRight(string(content), "\r\n")
+	}
+	return values, nil
+}
+
+// VaultProvider reads one secret from a HashiCorp Vault KV engine. Each
+// field of the secret is a variable, e.g. JWT_SECRET. Both KV version 1
+// and 2 mounts are supported.
+type VaultProvider struct {
+	Address string
+	Token   string
+	Mount   string
+	Path    string
+	Client  *http.Client
+}
+
+func (p *VaultProvider) Name() string {
+	return "vault " + p.Mount + "/" + p.Path
+}
+
+func (p *VaultProvider) Values(ctx context.Context) (map[string]string, error) {
+	if p.Path == "" {
+		return nil, fmt.Errorf("VAULT_SECRET_PATH is required when VAULT_ADDR is set")
+	}
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	// KV v2 serves secrets under <mount>/data/<path>; fall back to v1
+	values, status, err := p.read(ctx, client, p.Mount+"/data/"+p.Path)
+	if err == nil && status == http.StatusNotFound {
+		values, status, err = p.read(ctx, client, p.Mount+"/"+p.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status %d", status)
+	}
+	return values, nil
+}
+
+func (p *VaultProvider) read(ctx context.Context, client *http.Client, path string) (map[string]string, int, error) {
+	endpoint, err := url.JoinPath(p.Address, "v1", path)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, nil
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, 0, fmt.Errorf("invalid vault response: %w", err)
+	}
+
+	fields := body.Data
+	if nested, ok := fields["data"].(map[string]interface{}); ok {
+		fields = nested
+	}
+	values := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, http.StatusOK, nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MinJWTSecretBits is the estimated entropy a JWT secret needs to pass
+// CheckJWTSecret
+const MinJWTSecretBits = 128
+
+const redacted = "[REDACTED]"
+
+// weakSecrets are defaults and placeholders that must never reach
+// production, whatever their length
+var weakSecrets = []string{"secret", "changeme", "password", "jwt-secret", "test-secret"}
+
+// ReportEntry is one effective setting
+type ReportEntry struct {
+	EnvVar string
+	Value  string
+	// Default is true when the value is the built-in default
+	Default bool
+}
+
+// Report lists every setting with its effective value, sorted as declared.
+// Secrets are redacted and passwords are stripped from DSNs.
+func Report(c Configuration) []ReportEntry {
+	var entries []ReportEntry
+	collectReport(reflect.ValueOf(c), &entries)
+	return entries
+}
+
+func collectReport(v reflect.Value, entries *[]ReportEntry) {
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		tag := field.Tag.Get("env")
+		if tag == "" {
+			if value.Kind() == reflect.Struct {
+				collectReport(value, entries)
+			}
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		rendered := formatValue(value)
+		entry := ReportEntry{EnvVar: name, Value: rendered}
+		if def, ok := strings.CutPrefix(options, "default="); ok {
+			if d, err := time.ParseDuration(def); err == nil && value.Type() == reflect.TypeOf(d) {
+				def = d.String()
+			}
+			entry.Default = def == rendered
+		} else {
+			entry.Default = rendered == ""
+		}
+
+		switch {
+		case rendered == "":
+		case name == "POSTGRESQL_DSN":
+			entry.Value = RedactDSN(rendered)
+		case IsSecret(name):
+			entry.Value = redacted
+		}
+		*entries = append(*entries, entry)
+	}
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// IsSecret reports whether the variable holds a credential or key
+func IsSecret(envVar string) bool {
+	if strings.HasSuffix(envVar, "_KEY_ID") || envVar == "ENCRYPTION_ACTIVE_KEY" {
+		return false
+	}
+	for _, marker := range []string{"SECRET", "PASSWORD", "TOKEN", "_KEY", "KEYS"} {
+		if strings.Contains(envVar, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+var dsnPassword = regexp.MustCompile(`(?i)(password=)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// RedactDSN hides the password of a URL or key=value Postgres DSN
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// CheckDSN parses a Postgres DSN without connecting
+func CheckDSN(dsn string) error {
+	if _, err := pq.NewConnector(dsn); err != nil {
+		return fmt.Errorf("invalid POSTGRESQL_DSN: %w", err)
+	}
+	return nil
+}
+
+// CheckJWTSecret rejects well-known placeholder secrets and secrets whose
+// estimated entropy is below MinJWTSecretBits
+func CheckJWTSecret(secret string) error {
+	for _, weak := range weakSecrets {
+		if strings.EqualFold(secret, weak) {
+			return errors.New("JWT_SECRET is a well-known placeholder")
+		}
+	}
+	if bits := estimateEntropy(secret); bits < MinJWTSecretBits {
+		return fmt.Errorf("JWT_SECRET has about %.0f bits of entropy, need at least %d; use e.g. openssl rand -base64 32", bits, MinJWTSecretBits)
+	}
+	return nil
+}
+
+// estimateEntropy approximates the entropy of s in bits from the Shannon
+// entropy of its characters. It overestimates structured secrets such as
+// words, but catches short and repetitive ones.
+func estimateEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	perChar := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		perChar -= p * math.Log2(p)
+	}
+	return perChar * float64(total)
+}
@@ -0,0 +1,322 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/money"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/platform/http/middleware"
+	"time"
+)
+
+// minStateSecretLength keeps the secret signing sign-in state as strong as
+// the 256-bit HMAC it keys
+const minStateSecretLength = 32
+
+// ValidationError lists every problem found in a configuration, so they
+// can all be fixed in one go
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks that required values are present and consistent. Values
+// with defaults are only checked for sensible ranges; values that are
+// required only when a feature is enabled are checked when it is.
+func (c Configuration) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch c.DataStore {
+	case "postgres":
+		if c.Database.DSN == "" {
+			addf("POSTGRESQL_DSN is required")
+		}
+	case "sqlite":
+		if c.Database.SQLitePath == "" {
+			addf("SQLITE_PATH is required when STORAGE=sqlite")
+		}
+	case "memory":
+	default:
+		addf("STORAGE must be postgres, sqlite or memory, got %q", c.DataStore)
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		addf("SERVER_PORT must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+	if c.Server.LoadShedding {
+		if c.Server.LoadShedMinConcurrent < 1 || c.Server.LoadShedMaxConcurrent < c.Server.LoadShedMinConcurrent {
+			addf("SERVER_LOAD_SHED_MIN_CONCURRENT must be at least 1 and at most SERVER_LOAD_SHED_MAX_CONCURRENT (%d), got %d", c.Server.LoadShedMaxConcurrent, c.Server.LoadShedMinConcurrent)
+		}
+		if c.Server.LoadShedTargetLatency <= 0 {
+			addf("SERVER_LOAD_SHED_TARGET_LATENCY must be positive when SERVER_LOAD_SHEDDING is enabled")
+		}
+	}
+	if c.Server.LegacyRoutesSunset != "" {
+		if _, err := time.Parse(time.DateOnly, c.Server.LegacyRoutesSunset); err != nil {
+			addf("SERVER_LEGACY_ROUTES_SUNSET must be a date as YYYY-MM-DD, got %q", c.Server.LegacyRoutesSunset)
+		}
+	}
+	if c.JWT.Secret == "" {
+		addf("JWT_SECRET is required")
+	}
+	if c.JWT.Expiry <= 0 {
+		addf("JWT_EXPIRY must be positive")
+	}
+	if c.JWT.RecentAuthWindow <= 0 {
+		addf("JWT_RECENT_AUTH_WINDOW must be positive")
+	}
+	if c.JWT.SigningKeyID != "" && !slices.ContainsFunc(c.JWT.Keys, func(entry string) bool {
+		id, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		return id == c.JWT.SigningKeyID
+	}) {
+		addf("JWT_SIGNING_KEY_ID %q is not one of JWT_KEYS", c.JWT.SigningKeyID)
+	}
+	var callers []string
+	for _, entry := range c.Internal.CallerTokens {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, token, _ := strings.Cut(entry, ":")
+		if name == "" || len(token) < middleware.MinInternalTokenLength {
+			addf("INTERNAL_CALLER_TOKENS entries must be name:token with a token of at least %d characters", middleware.MinInternalTokenLength)
+			continue
+		}
+		callers = append(callers, name)
+	}
+	for _, name := range c.Internal.Impersonators {
+		if !slices.Contains(callers, name) {
+			addf("INTERNAL_IMPERSONATORS: %q is not one of INTERNAL_CALLER_TOKENS", name)
+		}
+	}
+	if c.OIDC.DefaultRole != "user" && c.OIDC.DefaultRole != "admin" {
+		addf("OIDC_DEFAULT_ROLE must be user or admin, got %q", c.OIDC.DefaultRole)
+	}
+	if c.OIDC.Enabled() {
+		if !strings.Contains(c.OIDC.CallbackURL, "{provider}") {
+			addf("OIDC_CALLBACK_URL is required with a {provider} placeholder when a sign-in provider is configured")
+		}
+		if len(c.OIDC.StateSecret) < minStateSecretLength {
+			addf("OIDC_STATE_SECRET must be at least %d characters when a sign-in provider is configured", minStateSecretLength)
+		}
+	}
+	if c.OIDC.GoogleClientID != "" && c.OIDC.GoogleClientSecret == "" {
+		addf("OIDC_GOOGLE_CLIENT_SECRET is required when OIDC_GOOGLE_CLIENT_ID is set")
+	}
+	if c.OIDC.AppleClientID != "" {
+		for _, required := range []struct{ name, value string }{
+			{"OIDC_APPLE_TEAM_ID", c.OIDC.AppleTeamID},
+			{"OIDC_APPLE_KEY_ID", c.OIDC.AppleKeyID},
+			{"OIDC_APPLE_PRIVATE_KEY_PATH", c.OIDC.ApplePrivateKeyPath},
+		} {
+			if required.value == "" {
+				addf("%s is required when OIDC_APPLE_CLIENT_ID is set", required.name)
+			}
+		}
+	}
+	if _, err := ParseLogLevel(c.LogLevel); err != nil {
+		addf("LOG_LEVEL: %v", err)
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			addf("STORAGE_LOCAL_DIR is required when STORAGE_BACKEND=local")
+		}
+	case "s3":
+		for _, required := range []struct{ name, value string }{
+			{"STORAGE_S3_ENDPOINT", c.Storage.S3Endpoint},
+			{"STORAGE_S3_BUCKET", c.Storage.S3Bucket},
+			{"STORAGE_S3_ACCESS_KEY_ID", c.Storage.S3AccessKeyID},
+			{"STORAGE_S3_SECRET_ACCESS_KEY", c.Storage.S3SecretAccessKey},
+		} {
+			if required.value == "" {
+				addf("%s is required when STORAGE_BACKEND=s3", required.name)
+			}
+		}
+	default:
+		addf("STORAGE_BACKEND must be local or s3, got %q", c.Storage.Backend)
+	}
+
+	if c.Redis.BreakerFailures < 1 || c.Redis.BreakerCoolDown <= 0 {
+		addf("REDIS_BREAKER_FAILURES must be at least 1 and REDIS_BREAKER_COOL_DOWN positive")
+	}
+	if c.Redis.L1Size < 0 || c.Redis.L1TTL < 0 {
+		addf("REDIS_L1_SIZE and REDIS_L1_TTL must not be negative; use a size of 0 to disable")
+	}
+	if c.Redis.NotFoundTTL < 0 {
+		addf("REDIS_NOT_FOUND_TTL must not be negative; use 0 to disable")
+	}
+
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		addf("POSTGRES_MAX_OPEN_CONNECTIONS and POSTGRES_MAX_IDLE_CONNECTIONS must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		addf("POSTGRES_MAX_IDLE_CONNECTIONS (%d) must not exceed POSTGRES_MAX_OPEN_CONNECTIONS (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		addf("POSTGRES_CONN_MAX_LIFETIME and POSTGRES_CONN_MAX_IDLE_TIME must not be negative; use 0 to keep connections")
+	}
+	if c.Database.SlowQueryThreshold < 0 {
+		addf("POSTGRES_SLOW_QUERY_THRESHOLD must not be negative; use 0 to log no query as slow")
+	}
+
+	if c.Ratings.ReviewMaxLength > 0 && c.Ratings.ReviewMinLength > c.Ratings.ReviewMaxLength {
+		addf("RATINGS_REVIEW_MIN_LENGTH (%d) must not exceed RATINGS_REVIEW_MAX_LENGTH (%d)", c.Ratings.ReviewMinLength, c.Ratings.ReviewMaxLength)
+	}
+	if c.Ratings.BayesianMinVotes < 1 {
+		addf("RATINGS_BAYESIAN_MIN_VOTES must be at least 1")
+	}
+	if c.Ratings.BayesianConfidenceK < 0 {
+		addf("RATINGS_BAYESIAN_CONFIDENCE_K must not be negative")
+	}
+	if c.Ratings.DisplayMinRatings < 0 {
+		addf("RATINGS_DISPLAY_MIN_RATINGS must not be negative")
+	}
+	if _, err := movies.ParseRankingMetric(c.Ratings.RankingMetric); err != nil {
+		addf("RATINGS_RANKING_METRIC: %v, got %q", err, c.Ratings.RankingMetric)
+	}
+	if c.Ratings.GlobalAverageTTL < 0 || c.Ratings.GlobalAverageRefreshInterval < 0 {
+		addf("RATINGS_GLOBAL_AVERAGE_TTL and RATINGS_GLOBAL_AVERAGE_REFRESH_INTERVAL must not be negative")
+	}
+	if c.Ratings.ImportBatchSize < 1 {
+		addf("RATINGS_IMPORT_BATCH_SIZE must be at least 1")
+	}
+	if c.Ratings.ImportMaxBytes < 1 {
+		addf("RATINGS_IMPORT_MAX_BYTES must be positive")
+	}
+	if c.Ratings.HistoryMaxBytes < 1 || c.Ratings.HistoryMaxEntries < 1 {
+		addf("RATINGS_HISTORY_MAX_BYTES and RATINGS_HISTORY_MAX_ENTRIES must be positive")
+	}
+	if c.Ratings.StatsSnapshotInterval < 0 || c.Ratings.StatsDownsampleAfter < 0 {
+		addf("RATINGS_STATS_SNAPSHOT_INTERVAL and RATINGS_STATS_DOWNSAMPLE_AFTER must not be negative")
+	}
+	if c.Ratings.RestoreWindow < 0 {
+		addf("RATINGS_RESTORE_WINDOW must not be negative; use 0 to disable restoring")
+	}
+
+	if c.Signup.RateLimit < 0 {
+		addf("SIGNUP_RATE_LIMIT must not be negative; use 0 to disable the limit")
+	}
+	if c.Signup.RateLimit > 0 && c.Signup.RateWindow <= 0 {
+		addf("SIGNUP_RATE_WINDOW must be positive when SIGNUP_RATE_LIMIT is set")
+	}
+	if c.Signup.CaptchaVerifyURL != "" && c.Signup.CaptchaSecret == "" {
+		addf("SIGNUP_CAPTCHA_SECRET is required when SIGNUP_CAPTCHA_VERIFY_URL is set")
+	}
+
+	if c.Public.AnonymousRateLimit < 0 {
+		addf("PUBLIC_ANONYMOUS_RATE_LIMIT must not be negative; use 0 to disable the limit")
+	}
+	if c.Public.AnonymousRateLimit > 0 && c.Public.AnonymousRateWindow <= 0 {
+		addf("PUBLIC_ANONYMOUS_RATE_WINDOW must be positive when PUBLIC_ANONYMOUS_RATE_LIMIT is set")
+	}
+
+	if c.Jobs.Workers < 1 {
+		addf("JOBS_WORKERS must be at least 1")
+	}
+	if c.Jobs.PollInterval <= 0 || c.Jobs.HeartbeatInterval <= 0 {
+		addf("JOBS_POLL_INTERVAL and JOBS_HEARTBEAT_INTERVAL must be positive")
+	}
+	if c.Jobs.StaleAfter <= c.Jobs.HeartbeatInterval {
+		addf("JOBS_STALE_AFTER (%s) must exceed JOBS_HEARTBEAT_INTERVAL (%s)", c.Jobs.StaleAfter, c.Jobs.HeartbeatInterval)
+	}
+
+	if c.Retention.Interval < 0 || c.Retention.Sessions < 0 || c.Retention.MergeAudit < 0 || c.Retention.Jobs < 0 || c.Retention.OrphanedMedia < 0 || c.Retention.DeletedRatings < 0 {
+		addf("RETENTION_INTERVAL and the RETENTION_* periods must not be negative; use 0 to disable")
+	}
+	if c.Retention.BatchSize < 1 {
+		addf("RETENTION_BATCH_SIZE must be at least 1")
+	}
+	// Uploads are stored before the row pointing at them is saved
+	if c.Retention.OrphanedMedia > 0 && c.Retention.OrphanedMedia < time.Hour {
+		addf("RETENTION_ORPHANED_MEDIA must be at least 1h so uploads in progress are kept")
+	}
+	if c.Retention.DeletedRatings > 0 && c.Retention.DeletedRatings < c.Ratings.RestoreWindow {
+		addf("RETENTION_DELETED_RATINGS (%s) must not be shorter than RATINGS_RESTORE_WINDOW (%s)", c.Retention.DeletedRatings, c.Ratings.RestoreWindow)
+	}
+
+	if c.ResponseCache.FreshFor < 0 || c.ResponseCache.StaleFor < 0 {
+		addf("RESPONSE_CACHE_FRESH_FOR and RESPONSE_CACHE_STALE_FOR must not be negative; use 0 to disable")
+	}
+
+	if c.Warmup.TopMovies < 0 || c.Warmup.Timeout < 0 {
+		addf("WARMUP_TOP_MOVIES and WARMUP_TIMEOUT must not be negative")
+	}
+
+	if c.SEO.SiteURL != "" {
+		if u, err := url.Parse(c.SEO.SiteURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			addf("SEO_SITE_URL must be an absolute http(s) URL without a query, got %q", c.SEO.SiteURL)
+		}
+		if c.SEO.SitemapPageSize < 1 || c.SEO.SitemapPageSize > 50000 {
+			addf("SEO_SITEMAP_PAGE_SIZE must be between 1 and 50000, got %d", c.SEO.SitemapPageSize)
+		}
+	}
+
+	if c.Recommendations.ShelfSize < 1 {
+		addf("RECOMMENDATIONS_SHELF_SIZE must be at least 1")
+	}
+	if c.Recommendations.ColdStartRatings < 0 {
+		addf("RECOMMENDATIONS_COLD_START_RATINGS must not be negative; use 0 to ignore onboarding preferences")
+	}
+	if c.Recommendations.GenreHalfLife < 0 {
+		addf("RECOMMENDATIONS_GENRE_HALF_LIFE must not be negative; use 0 to count every rating alike")
+	}
+
+	if c.Usage.FlushInterval <= 0 {
+		addf("USAGE_FLUSH_INTERVAL must be positive")
+	}
+	if c.Usage.UserMonthlyQuota < 0 || c.Usage.APIKeyMonthlyQuota < 0 {
+		addf("USAGE_*_MONTHLY_QUOTA must not be negative; use 0 for no quota")
+	}
+	if c.Views.FlushInterval <= 0 {
+		addf("VIEWS_FLUSH_INTERVAL must be positive")
+	}
+
+	if _, err := money.ParseCurrency(c.FX.BaseCurrency); err != nil {
+		addf("FX_BASE_CURRENCY: %v, got %q", err, c.FX.BaseCurrency)
+	}
+	if _, err := money.ParseRates(c.FX.Rates); err != nil {
+		addf("FX_RATES: %v", err)
+	}
+
+	if _, err := movies.CertificationsUpTo(c.Content.KidsTerritory, c.Content.KidsMaxCertification); err != nil {
+		addf("CONTENT_KIDS_MAX_CERTIFICATION: %v, got %q in %q", err, c.Content.KidsMaxCertification, c.Content.KidsTerritory)
+	}
+
+	if c.Encryption.EncryptEmails {
+		if len(c.Encryption.Keys) == 0 {
+			addf("ENCRYPTION_KEYS is required when ENCRYPTION_EMAILS=true")
+		}
+		if c.Encryption.ActiveKey == "" {
+			addf("ENCRYPTION_ACTIVE_KEY is required when ENCRYPTION_EMAILS=true")
+		}
+		if key, err := base64.StdEncoding.DecodeString(c.Encryption.IndexKey); err != nil || len(key) != 32 {
+			addf("ENCRYPTION_INDEX_KEY must be a base64-encoded 32-byte key when ENCRYPTION_EMAILS=true")
+		}
+	}
+
+	if c.ReloadInterval < 0 {
+		addf("CONFIG_RELOAD_INTERVAL must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ParseLogLevel parses debug, info, warn or error
+func ParseLogLevel(level string) (slog.Level, error) {
+	return logging.ParseLevel(level)
+}
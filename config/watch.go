@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Watcher reloads the configuration on SIGHUP and, when an interval is
+// set, periodically so that changes in Vault or the config file are picked
+// up. Only settings that are safe to change at runtime are applied, by the
+// callbacks registered with OnReload; changes to anything else are logged
+// as needing a restart.
+type Watcher struct {
+	loader   *Loader
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	current  Configuration
+	onReload []func(Configuration)
+}
+
+func NewWatcher(loader *Loader, current Configuration, logger *slog.Logger) *Watcher {
+	return &Watcher{
+		loader:   loader,
+		interval: current.ReloadInterval,
+		logger:   logger,
+		current:  current,
+	}
+}
+
+// OnReload registers fn to receive every successfully reloaded configuration
+func (w *Watcher) OnReload(fn func(Configuration)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onReload = append(w.onReload, fn)
+}
+
+// Run reloads on SIGHUP and on every interval until ctx is done
+func (w *Watcher) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			w.logger.Info("Reloading configuration on SIGHUP")
+			w.Reload(ctx)
+		case <-tick:
+			w.Reload(ctx)
+		}
+	}
+}
+
+// Reload loads the configuration and hands it to the callbacks. An invalid
+// configuration is logged and ignored, keeping the current settings.
+func (w *Watcher) Reload(ctx context.Context) error {
+	next, err := w.loader.Load(ctx)
+	if err != nil {
+		w.logger.Error("Failed to reload configuration, keeping current settings", slog.String("error", err.Error()))
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if sections := RestartRequired(w.current, next); len(sections) > 0 {
+		w.logger.Warn("Configuration changes need a restart and were not applied", slog.Any("sections", sections))
+	}
+	w.current = next
+	for _, fn := range w.onReload {
+		fn(next)
+	}
+	return nil
+}
+
+// RestartRequired names the settings that differ between old and next but
+// are only read at startup. Log level, signup and anonymous rate limits, the
+// Bayesian rating parameters and the display minimum can change at runtime.
+func RestartRequired(old, next Configuration) []string {
+	applied := old
+	applied.LogLevel = next.LogLevel
+	applied.Signup.RateLimit = next.Signup.RateLimit
+	applied.Signup.RateWindow = next.Signup.RateWindow
+	applied.Public = next.Public
+	applied.Ratings.BayesianMinVotes = next.Ratings.BayesianMinVotes
+	applied.Ratings.BayesianConfidenceK = next.Ratings.BayesianConfidenceK
+	applied.Ratings.DisplayMinRatings = next.Ratings.DisplayMinRatings
+
+	var changed []string
+	a, b := reflect.ValueOf(applied), reflect.ValueOf(next)
+	for i := 0; i < a.NumField(); i++ {
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			changed = append(changed, a.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
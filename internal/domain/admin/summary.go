@@ -0,0 +1,43 @@
+// Package admin holds the read models behind the internal ops dashboard.
+package admin
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"time"
+)
+
+// Totals counts the main entities
+type Totals struct {
+	Users       int64 `json:"users"`
+	ActiveUsers int64 `json:"active_users"`
+	Movies      int64 `json:"movies"`
+	Ratings     int64 `json:"ratings"`
+}
+
+// DailyCount is the number of events on one UTC day
+type DailyCount struct {
+	Date  time.Time `json:"date"`
+	Count int64     `json:"count"`
+}
+
+// TopMovie is a movie ranked by how many ratings it received in a period
+type TopMovie struct {
+	MovieID      movies.MovieID `json:"movie_id"`
+	Title        string         `json:"title"`
+	ReleaseYear  int            `json:"release_year"`
+	Ratings      int64          `json:"ratings"`
+	AverageScore float64        `json:"average_score"`
+}
+
+type SummaryRepository interface {
+	Totals(ctx context.Context) (*Totals, error)
+	// RatingsPerDay and SignupsPerDay only return days with at least one
+	// event, oldest first
+	RatingsPerDay(ctx context.Context, since time.Time) ([]DailyCount, error)
+	SignupsPerDay(ctx context.Context, since time.Time) ([]DailyCount, error)
+	// TopMovies ranks movies by ratings created since, then by average score
+	TopMovies(ctx context.Context, since time.Time, limit int) ([]*TopMovie, error)
+	// Ping checks the database connection
+	Ping(ctx context.Context) error
+}
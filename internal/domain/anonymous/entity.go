@@ -0,0 +1,155 @@
+package anonymous
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+// DeviceTokenPrefix tells device tokens apart from bearer tokens in logs
+// and support requests
+const DeviceTokenPrefix = "anon_"
+
+// deviceTokenBytes of randomness make device tokens unguessable
+const deviceTokenBytes = 32
+
+var (
+	ErrPrincipalNotFound       = errors.New("anonymous principal not found")
+	ErrMovieNotFound           = errors.New("movie not found")
+	ErrAlreadyClaimed          = errors.New("anonymous ratings have already been claimed")
+	ErrInvalidConflictStrategy = fmt.Errorf("on_conflict must be one of %s, %s or %s", KeepAccount, KeepAnonymous, KeepNewest)
+)
+
+type PrincipalID string
+
+// Principal is a device that rates movies without an account. It is known
+// by the hash of its device token only, so a leaked table can't be used to
+// rate on its behalf.
+type Principal struct {
+	ID        PrincipalID   `json:"id"`
+	TokenHash string        `json:"-"`
+	CreatedAt time.Time     `json:"created_at"`
+	ClaimedBy *users.UserID `json:"claimed_by,omitempty"`
+	ClaimedAt *time.Time    `json:"claimed_at,omitempty"`
+}
+
+// Claimed reports whether the principal's ratings moved to an account
+func (p *Principal) Claimed() bool {
+	return p.ClaimedAt != nil
+}
+
+// Rating is a score a device gave before its owner signed up. A device
+// rates a movie once; rating it again replaces the score.
+type Rating struct {
+	PrincipalID PrincipalID    `json:"-"`
+	MovieID     movies.MovieID `json:"movie_id"`
+	Score       int            `json:"score"`
+	Review      string         `json:"review,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func NewRating(principalID PrincipalID, movieID movies.MovieID, score int, review string, now time.Time) (*Rating, error) {
+	if movieID == "" {
+		return nil, rating.ErrEmptyMovieID
+	}
+	if score < 1 || score > 5 {
+		return nil, rating.ErrInvalidScore
+	}
+	return &Rating{
+		PrincipalID: principalID,
+		MovieID:     movieID,
+		Score:       score,
+		Review:      strings.TrimSpace(review),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// NewDeviceToken returns a random device token and the hash it is stored by
+func NewDeviceToken() (token, hash string) {
+	b := make([]byte, deviceTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic("anonymous: failed to read random bytes: " + err.Error())
+	}
+	token = DeviceTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashDeviceToken(token)
+}
+
+// HashDeviceToken returns the hash a device token is looked up by. The token
+// is random enough that a plain SHA-256 can't be reversed.
+func HashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ConflictStrategy decides which rating wins when a claimed device and the
+// account both rated the same movie
+type ConflictStrategy string
+
+const (
+	KeepAccount   ConflictStrategy = "keep_account"
+	KeepAnonymous ConflictStrategy = "keep_anonymous"
+	KeepNewest    ConflictStrategy = "keep_newest"
+)
+
+// ParseConflictStrategy defaults to KeepAccount, so claiming never loses a
+// rating the user made while signed in unless asked to
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch strategy := ConflictStrategy(strings.ToLower(strings.TrimSpace(s))); strategy {
+	case "":
+		return KeepAccount, nil
+	case KeepAccount, KeepAnonymous, KeepNewest:
+		return strategy, nil
+	default:
+		return "", ErrInvalidConflictStrategy
+	}
+}
+
+// PrefersAnonymous reports whether the device's rating replaces the
+// account's. KeepNewest compares when each was last changed and keeps the
+// account's on a tie.
+func (s ConflictStrategy) PrefersAnonymous(account *rating.Rating, device *Rating) bool {
+	switch s {
+	case KeepAnonymous:
+		return true
+	case KeepNewest:
+		return device.UpdatedAt.After(account.UpdatedAt)
+	default:
+		return false
+	}
+}
+
+// Kept names the side of a conflict whose rating survived
+type Kept string
+
+const (
+	KeptAccount   Kept = "account"
+	KeptAnonymous Kept = "anonymous"
+)
+
+// Conflict is a movie both the device and the account had rated
+type Conflict struct {
+	MovieID        movies.MovieID `json:"movie_id"`
+	AccountScore   int            `json:"account_score"`
+	AnonymousScore int            `json:"anonymous_score"`
+	Kept           Kept           `json:"kept"`
+}
+
+// ClaimResult sums up moving a device's ratings to an account
+type ClaimResult struct {
+	PrincipalID PrincipalID  `json:"principal_id"`
+	UserID      users.UserID `json:"user_id"`
+	// Imported counts the ratings of movies the account hadn't rated
+	Imported  int        `json:"imported"`
+	Conflicts []Conflict `json:"conflicts"`
+	ClaimedAt time.Time  `json:"claimed_at"`
+}
@@ -0,0 +1,66 @@
+package anonymous
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDeviceToken(t *testing.T) {
+	token, hash := NewDeviceToken()
+	other, _ := NewDeviceToken()
+
+	assert.True(t, strings.HasPrefix(token, DeviceTokenPrefix))
+	assert.NotEqual(t, token, other)
+	assert.Equal(t, HashDeviceToken(token), hash)
+	assert.Len(t, hash, 64)
+}
+
+func TestNewRating(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	r, err := NewRating("principal-1", "movie-1", 4, "  Tense ", now)
+	require.NoError(t, err)
+	assert.Equal(t, "Tense", r.Review)
+	assert.Equal(t, now, r.UpdatedAt)
+
+	_, err = NewRating("principal-1", "movie-1", 0, "", now)
+	assert.ErrorIs(t, err, rating.ErrInvalidScore)
+	_, err = NewRating("principal-1", "", 3, "", now)
+	assert.ErrorIs(t, err, rating.ErrEmptyMovieID)
+}
+
+func TestParseConflictStrategy(t *testing.T) {
+	for input, want := range map[string]ConflictStrategy{
+		"":               KeepAccount,
+		"keep_account":   KeepAccount,
+		"Keep_Anonymous": KeepAnonymous,
+		" keep_newest ":  KeepNewest,
+	} {
+		strategy, err := ParseConflictStrategy(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, strategy, input)
+	}
+
+	_, err := ParseConflictStrategy("keep_both")
+	assert.ErrorIs(t, err, ErrInvalidConflictStrategy)
+}
+
+func TestPrefersAnonymous(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	account := &rating.Rating{Score: 2, UpdatedAt: now}
+	older := &Rating{Score: 5, UpdatedAt: now.Add(-time.Hour)}
+	newer := &Rating{Score: 5, UpdatedAt: now.Add(time.Hour)}
+	tied := &Rating{Score: 5, UpdatedAt: now}
+
+	assert.False(t, KeepAccount.PrefersAnonymous(account, newer))
+	assert.True(t, KeepAnonymous.PrefersAnonymous(account, older))
+	assert.True(t, KeepNewest.PrefersAnonymous(account, newer))
+	assert.False(t, KeepNewest.PrefersAnonymous(account, older))
+	assert.False(t, KeepNewest.PrefersAnonymous(account, tied), "ties keep the account's rating")
+}
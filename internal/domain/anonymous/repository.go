@@ -0,0 +1,29 @@
+package anonymous
+
+import (
+	"context"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+type Repository interface {
+	CreatePrincipal(ctx context.Context, principal *Principal) error
+	// FindPrincipalByTokenHash returns ErrPrincipalNotFound for unknown
+	// tokens; claimed principals are returned so callers can tell them apart
+	FindPrincipalByTokenHash(ctx context.Context, hash string) (*Principal, error)
+	// SaveRating stores the device's rating of a movie, replacing an earlier
+	// one but keeping when it was first made. It returns ErrMovieNotFound
+	// for unknown movies and ErrAlreadyClaimed once the principal was
+	// claimed.
+	SaveRating(ctx context.Context, r *Rating) (*Rating, error)
+	// ListRatings returns the device's ratings, most recently changed first
+	ListRatings(ctx context.Context, principalID PrincipalID) ([]*Rating, error)
+	// Claim moves the principal's ratings, given as candidates for the
+	// user's ratings, to the user in one transaction. Candidates for movies
+	// the user hasn't rated are inserted as they are; on a movie both rated,
+	// strategy picks the rating kept. The principal's ratings are deleted
+	// either way. It returns ErrAlreadyClaimed when the principal was
+	// claimed first and users.ErrUserNotFound for unknown users.
+	Claim(ctx context.Context, principalID PrincipalID, userID users.UserID, candidates []*rating.Rating, strategy ConflictStrategy, at time.Time) (*ClaimResult, error)
+}
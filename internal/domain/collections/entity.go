@@ -0,0 +1,72 @@
+package collections
+
+import (
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+type CollectionID string
+
+// Collection groups related movies, such as a franchise or a trilogy, in a
+// fixed viewing order
+type Collection struct {
+	ID          CollectionID `db:"id"`
+	Name        string       `db:"name"`
+	Description string       `db:"description"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+}
+
+// Entry is a movie in a collection together with its rating numbers.
+// Entries are ordered by Position, lowest first.
+type Entry struct {
+	Movie           *movies.Movie
+	Position        int
+	AverageScore    float64
+	TotalRatings    int64
+	BayesianAverage float64
+}
+
+// Stats aggregates the ratings of a collection's entries
+type Stats struct {
+	MovieCount   int
+	TotalRatings int64
+	// AverageBayesianRating is the mean of the entries' Bayesian averages, so
+	// a single heavily rated entry does not dominate the collection score
+	AverageBayesianRating float64
+}
+
+func NewCollection(name, description string, idGenerator shared.IDGenerator, timeProvider shared.TimeProvider) (*Collection, error) {
+	collection := &Collection{
+		ID:          CollectionID(idGenerator.Generate()),
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		CreatedAt:   timeProvider.Now(),
+		UpdatedAt:   timeProvider.Now(),
+	}
+
+	if collection.Name == "" {
+		return nil, ErrEmptyName
+	}
+
+	return collection, nil
+}
+
+// ComputeStats aggregates entries into collection stats
+func ComputeStats(entries []*Entry) Stats {
+	stats := Stats{MovieCount: len(entries)}
+	if len(entries) == 0 {
+		return stats
+	}
+
+	var sum float64
+	for _, e := range entries {
+		sum += e.BayesianAverage
+		stats.TotalRatings += e.TotalRatings
+	}
+	stats.AverageBayesianRating = sum / float64(len(entries))
+
+	return stats
+}
@@ -0,0 +1,38 @@
+package collections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIDGenerator struct{}
+
+func (m *mockIDGenerator) Generate() string { return "mock-id" }
+
+type mockTimeProvider struct{ now time.Time }
+
+func (m *mockTimeProvider) Now() time.Time { return m.now }
+
+func TestNewCollection(t *testing.T) {
+	tp := &mockTimeProvider{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	collection, err := NewCollection(" The Lord of the Rings Trilogy ", "", &mockIDGenerator{}, tp)
+	assert.NoError(t, err)
+	assert.Equal(t, CollectionID("mock-id"), collection.ID)
+	assert.Equal(t, "The Lord of the Rings Trilogy", collection.Name)
+
+	_, err = NewCollection("  ", "", &mockIDGenerator{}, tp)
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestComputeStats(t *testing.T) {
+	stats := ComputeStats([]*Entry{
+		{BayesianAverage: 4.5, TotalRatings: 1000},
+		{BayesianAverage: 3.5, TotalRatings: 2},
+	})
+	assert.Equal(t, Stats{MovieCount: 2, TotalRatings: 1002, AverageBayesianRating: 4.0}, stats)
+
+	assert.Equal(t, Stats{}, ComputeStats(nil))
+}
@@ -0,0 +1,9 @@
+package collections
+
+import "errors"
+
+var (
+	ErrEmptyName       = errors.New("name cannot be empty")
+	ErrEmptyMovieID    = errors.New("movie ID cannot be empty")
+	ErrInvalidPosition = errors.New("position must be positive")
+)
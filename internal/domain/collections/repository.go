@@ -0,0 +1,19 @@
+package collections
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+)
+
+type Repository interface {
+	Save(ctx context.Context, collection *Collection) (*Collection, error)
+	GetByID(ctx context.Context, id CollectionID) (*Collection, error)
+
+	// AddMovie places the movie at position, or after the last entry when
+	// position is nil. Adding a movie that is already in the collection moves it.
+	AddMovie(ctx context.Context, id CollectionID, movieID movies.MovieID, position *int) error
+	RemoveMovie(ctx context.Context, id CollectionID, movieID movies.MovieID) error
+	// GetEntries returns the collection's movies in order with their rating
+	// numbers. confidenceK is the prior weight of the Bayesian average.
+	GetEntries(ctx context.Context, id CollectionID, confidenceK float64) ([]*Entry, error)
+}
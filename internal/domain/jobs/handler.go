@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ReportFunc records the progress of a running job in percent, along with
+// the result so far. It is cheap; the worker saves the latest report on its
+// next heartbeat.
+type ReportFunc func(progress float64, result any)
+
+// Handler runs a job of one type. It should return soon after ctx is done,
+// which happens when the job is cancelled or the worker shuts down.
+type Handler func(ctx context.Context, job *Job, report ReportFunc) error
+
+// RetryPolicy decides how often a job type is attempted and how long a
+// failed attempt waits, doubling from Backoff up to MaxBackoff
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultRetryPolicy suits jobs that fail on transient errors such as a
+// lost database connection
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, Backoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}
+}
+
+// Delay is how long to wait after the given failed attempt, counted from 1
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a failure that retrying cannot fix, such as an invalid
+// payload, so the job fails right away
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Second, MaxBackoff: time.Minute}
+
+	assert.Equal(t, 10*time.Second, policy.Delay(1))
+	assert.Equal(t, 20*time.Second, policy.Delay(2))
+	assert.Equal(t, 40*time.Second, policy.Delay(3))
+	assert.Equal(t, time.Minute, policy.Delay(4))
+	assert.Equal(t, time.Minute, policy.Delay(10))
+}
+
+func TestPermanent(t *testing.T) {
+	cause := errors.New("invalid payload")
+	err := fmt.Errorf("run import: %w", Permanent(cause))
+
+	assert.True(t, IsPermanent(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, IsPermanent(cause))
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobFinished = errors.New("job has already finished")
+	ErrJobExists   = errors.New("job already exists")
+)
+
+type JobID string
+
+func (id JobID) String() string {
+	return string(id)
+}
+
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether a job in this status will not run again
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Job is a unit of background work such as an import. Payload is what the
+// job's handler needs to run it and Result what it reports back; both are
+// JSON owned by the handler of Type.
+type Job struct {
+	ID       JobID           `db:"id"`
+	Type     string          `db:"type"`
+	Status   Status          `db:"status"`
+	Payload  json.RawMessage `db:"payload"`
+	Result   json.RawMessage `db:"result"`
+	Progress float64         `db:"progress"` // Percent done, 0-100
+	// Error is the last failure; it is kept while a failed attempt waits
+	// for its retry
+	Error           string     `db:"error"`
+	Attempts        int        `db:"attempts"`
+	MaxAttempts     int        `db:"max_attempts"`
+	CancelRequested bool       `db:"cancel_requested"`
+	CreatedBy       string     `db:"created_by"`
+	RunAt           time.Time  `db:"run_at"` // When a queued job becomes due
+	CreatedAt       time.Time  `db:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at"` // Doubles as the heartbeat of running jobs
+	StartedAt       *time.Time `db:"started_at"`
+	FinishedAt      *time.Time `db:"finished_at"`
+}
+
+type Repository interface {
+	// Create returns ErrJobExists when a job with the ID exists
+	Create(ctx context.Context, job *Job) error
+	// GetByID returns ErrJobNotFound for unknown IDs
+	GetByID(ctx context.Context, id JobID) (*Job, error)
+	// Claim marks the oldest due job of one of types as running and counts
+	// the attempt. It returns nil when no job is due. Concurrent callers
+	// never claim the same job.
+	Claim(ctx context.Context, types []string, now time.Time) (*Job, error)
+	// Heartbeat records the progress of a running job and reports whether
+	// its cancellation was requested
+	Heartbeat(ctx context.Context, id JobID, progress float64, result json.RawMessage, now time.Time) (cancelRequested bool, err error)
+	// Finish moves a running job to a finished status
+	Finish(ctx context.Context, id JobID, status Status, result json.RawMessage, errMsg string, now time.Time) error
+	// Retry queues a running job again to run at runAt
+	Retry(ctx context.Context, id JobID, errMsg string, runAt, now time.Time) error
+	// Cancel cancels a queued job right away and asks the worker of a
+	// running one to stop. It returns ErrJobFinished for finished jobs.
+	Cancel(ctx context.Context, id JobID, now time.Time) (*Job, error)
+	// RequeueStale recovers running jobs whose last heartbeat is before
+	// staleBefore, as their worker has stopped. Jobs with attempts left are
+	// queued again and the others fail. It returns how many were recovered.
+	RequeueStale(ctx context.Context, staleBefore, now time.Time) (int64, error)
+}
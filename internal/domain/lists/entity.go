@@ -0,0 +1,115 @@
+package lists
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+type ListID string
+
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+)
+
+func (v Visibility) IsValid() bool {
+	return v == VisibilityPublic || v == VisibilityPrivate
+}
+
+// shareSlugBytes yields a 16 character slug, enough that slugs of private
+// lists cannot be guessed
+const shareSlugBytes = 10
+
+var slugEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// List is a user-curated, ordered list of movies such as "watch later".
+// Private lists are only visible to their owner, or to anyone holding the
+// share slug.
+type List struct {
+	ID          ListID       `db:"id"`
+	UserID      users.UserID `db:"user_id"`
+	Name        string       `db:"name"`
+	Description string       `db:"description"`
+	Visibility  Visibility   `db:"visibility"`
+	ShareSlug   string       `db:"share_slug"`
+	MovieCount  int          `db:"movie_count"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+}
+
+// Entry is a movie in a list. Entries are ordered by Position, lowest first.
+type Entry struct {
+	Movie    *movies.Movie
+	Position int
+	AddedAt  time.Time
+}
+
+// Counts summarises a user's lists by visibility
+type Counts struct {
+	Total   int64 `db:"total"`
+	Public  int64 `db:"public"`
+	Private int64 `db:"private"`
+}
+
+func NewList(userID, name, description string, visibility Visibility, idGenerator shared.IDGenerator, timeProvider shared.TimeProvider) (*List, error) {
+	if visibility == "" {
+		visibility = VisibilityPrivate
+	}
+
+	list := &List{
+		ID:          ListID(idGenerator.Generate()),
+		UserID:      users.UserID(strings.TrimSpace(userID)),
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		Visibility:  visibility,
+		ShareSlug:   NewShareSlug(),
+		CreatedAt:   timeProvider.Now(),
+		UpdatedAt:   timeProvider.Now(),
+	}
+
+	if err := list.Validate(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (l *List) Validate() error {
+	if l.UserID == "" {
+		return ErrEmptyUserID
+	}
+	if l.Name == "" {
+		return ErrEmptyName
+	}
+	if !l.Visibility.IsValid() {
+		return ErrInvalidVisibility
+	}
+	return nil
+}
+
+// IsOwnedBy reports whether userID owns the list
+func (l *List) IsOwnedBy(userID string) bool {
+	return userID != "" && string(l.UserID) == userID
+}
+
+// CanView reports whether userID may see the list. viaSlug is true when the
+// list was looked up by its share slug, which grants access to private lists.
+func (l *List) CanView(userID string, viaSlug bool) bool {
+	return l.Visibility == VisibilityPublic || viaSlug || l.IsOwnedBy(userID)
+}
+
+// NewShareSlug returns a random, URL-safe slug for sharing a list
+func NewShareSlug() string {
+	b := make([]byte, shareSlugBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic("lists: failed to read random bytes: " + err.Error())
+	}
+	return strings.ToLower(slugEncoding.EncodeToString(b))
+}
@@ -0,0 +1,53 @@
+package lists
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIDGenerator struct{}
+
+func (m *mockIDGenerator) Generate() string { return "mock-id" }
+
+type mockTimeProvider struct{ now time.Time }
+
+func (m *mockTimeProvider) Now() time.Time { return m.now }
+
+func TestNewList(t *testing.T) {
+	tp := &mockTimeProvider{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	list, err := NewList("user-1", " Watch later ", "", "", &mockIDGenerator{}, tp)
+	require.NoError(t, err)
+	assert.Equal(t, ListID("mock-id"), list.ID)
+	assert.Equal(t, "Watch later", list.Name)
+	assert.Equal(t, VisibilityPrivate, list.Visibility, "lists are private by default")
+	assert.Regexp(t, regexp.MustCompile(`^[a-z2-7]{16}$`), list.ShareSlug)
+
+	_, err = NewList("", "Watch later", "", VisibilityPublic, &mockIDGenerator{}, tp)
+	assert.ErrorIs(t, err, ErrEmptyUserID)
+
+	_, err = NewList("user-1", "  ", "", VisibilityPublic, &mockIDGenerator{}, tp)
+	assert.ErrorIs(t, err, ErrEmptyName)
+
+	_, err = NewList("user-1", "Watch later", "", "friends", &mockIDGenerator{}, tp)
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+}
+
+func TestListCanView(t *testing.T) {
+	private := &List{UserID: "owner", Visibility: VisibilityPrivate}
+	public := &List{UserID: "owner", Visibility: VisibilityPublic}
+
+	assert.True(t, private.CanView("owner", false))
+	assert.False(t, private.CanView("someone-else", false))
+	assert.False(t, private.CanView("", false))
+	assert.True(t, private.CanView("", true), "the share slug grants access")
+	assert.True(t, public.CanView("", false))
+}
+
+func TestNewShareSlugIsUnique(t *testing.T) {
+	assert.NotEqual(t, NewShareSlug(), NewShareSlug())
+}
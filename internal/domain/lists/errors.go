@@ -0,0 +1,11 @@
+package lists
+
+import "errors"
+
+var (
+	ErrEmptyUserID       = errors.New("user ID cannot be empty")
+	ErrEmptyName         = errors.New("name cannot be empty")
+	ErrInvalidVisibility = errors.New("visibility must be 'public' or 'private'")
+	ErrEmptyMovieID      = errors.New("movie ID cannot be empty")
+	ErrInvalidPosition   = errors.New("position must be positive")
+)
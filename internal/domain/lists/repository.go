@@ -0,0 +1,25 @@
+package lists
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+)
+
+type Repository interface {
+	Save(ctx context.Context, list *List) (*List, error)
+	Update(ctx context.Context, list *List) (*List, error)
+	Delete(ctx context.Context, id ListID) error
+	GetByID(ctx context.Context, id ListID) (*List, error)
+	GetBySlug(ctx context.Context, slug string) (*List, error)
+	// GetByUser returns the user's lists, newest first. Private lists are
+	// only included when includePrivate is set.
+	GetByUser(ctx context.Context, userID users.UserID, includePrivate bool) ([]*List, error)
+	CountByUser(ctx context.Context, userID users.UserID) (Counts, error)
+
+	// AddMovie places the movie at position, or after the last entry when
+	// position is nil. Adding a movie that is already on the list moves it.
+	AddMovie(ctx context.Context, id ListID, movieID movies.MovieID, position *int) error
+	RemoveMovie(ctx context.Context, id ListID, movieID movies.MovieID) error
+	GetEntries(ctx context.Context, id ListID) ([]*Entry, error)
+}
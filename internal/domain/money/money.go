@@ -0,0 +1,87 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	ErrInvalidCurrency = errors.New("currency must be a supported ISO 4217 code, e.g. USD or EUR")
+	ErrRateUnavailable = errors.New("no exchange rate between these currencies")
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+// DefaultCurrency is the currency of amounts recorded before currencies were
+// tracked
+const DefaultCurrency Currency = "USD"
+
+// minorUnits maps the supported currencies to the number of digits after the
+// decimal point of their minor unit
+var minorUnits = map[Currency]int{
+	"AUD": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0, "CNY": 2, "CZK": 2,
+	"DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "INR": 2, "ISK": 0,
+	"JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "PLN": 2,
+	"SEK": 2, "SGD": 2, "TRY": 2, "USD": 2, "ZAR": 2,
+}
+
+// ParseCurrency normalizes a currency code, rejecting unsupported ones
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := minorUnits[currency]; !ok {
+		return "", ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
+func (c Currency) IsValid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+// MinorUnits is the number of decimal digits of the currency's minor unit,
+// e.g. 2 for USD cents and 0 for JPY
+func (c Currency) MinorUnits() int {
+	return minorUnits[c]
+}
+
+// Money is an amount in the minor unit of its currency, so a budget of
+// $1,500.00 is {150000, USD}. Amounts are integers to keep arithmetic exact.
+type Money struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+func New(amount int64, currency Currency) (Money, error) {
+	if !currency.IsValid() {
+		return Money{}, ErrInvalidCurrency
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Convert returns m in the currency to, where rate is the price of one unit
+// of m's currency in to. The result is rounded to the nearest minor unit.
+func (m Money) Convert(to Currency, rate float64) Money {
+	if m.Currency == to {
+		return m
+	}
+	scale := math.Pow10(to.MinorUnits() - m.Currency.MinorUnits())
+	return Money{Amount: int64(math.Round(float64(m.Amount) * rate * scale)), Currency: to}
+}
+
+// String formats m in major units, e.g. "1500.00 USD"
+func (m Money) String() string {
+	digits := m.Currency.MinorUnits()
+	if digits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	sign, amount := "", m.Amount
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	unit := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/unit, digits, amount%unit, m.Currency)
+}
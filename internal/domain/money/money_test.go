@@ -0,0 +1,64 @@
+package money
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrency(t *testing.T) {
+	currency, err := ParseCurrency(" eur ")
+	require.NoError(t, err)
+	assert.Equal(t, Currency("EUR"), currency)
+
+	for _, code := range []string{"", "EU", "EURO", "XXX"} {
+		_, err := ParseCurrency(code)
+		assert.ErrorIs(t, err, ErrInvalidCurrency, code)
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	budget := Money{Amount: 150000, Currency: "USD"}
+
+	assert.Equal(t, Money{Amount: 138000, Currency: "EUR"}, budget.Convert("EUR", 0.92))
+	assert.Equal(t, Money{Amount: 225000, Currency: "JPY"}, budget.Convert("JPY", 150), "cents to whole yen")
+	assert.Equal(t, Money{Amount: 100000, Currency: "USD"}, Money{Amount: 150000, Currency: "JPY"}.Convert("USD", 1.0/150))
+	assert.Equal(t, Money{Amount: 451010, Currency: "KWD"}, budget.Convert("KWD", 0.3006731), "rounded to the nearest fils")
+	assert.Equal(t, budget, budget.Convert("USD", 2), "same currency is never converted")
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "1500.05 USD", Money{Amount: 150005, Currency: "USD"}.String())
+	assert.Equal(t, "-0.50 EUR", Money{Amount: -50, Currency: "EUR"}.String())
+	assert.Equal(t, "1500 JPY", Money{Amount: 1500, Currency: "JPY"}.String())
+	assert.Equal(t, "1.005 KWD", Money{Amount: 1005, Currency: "KWD"}.String())
+}
+
+func TestStaticRates(t *testing.T) {
+	rates, err := ParseRates("eur=0.8, GBP=0.5,")
+	require.NoError(t, err)
+	provider := NewStaticRates("USD", rates)
+	ctx := context.Background()
+
+	rate, err := provider.Rate(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.8, rate, 1e-9)
+
+	rate, err = provider.Rate(ctx, "EUR", "GBP")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.625, rate, 1e-9, "cross rate through the base currency")
+
+	_, err = provider.Rate(ctx, "USD", "JPY")
+	assert.ErrorIs(t, err, ErrRateUnavailable)
+
+	converted, err := NewConverter(provider).Convert(ctx, Money{Amount: 1000, Currency: "GBP"}, "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 1600, Currency: "EUR"}, converted)
+
+	for _, list := range []string{"EUR", "EUR=abc", "EUR=-1", "XXX=1"} {
+		_, err := ParseRates(list)
+		assert.Error(t, err, list)
+	}
+}
@@ -0,0 +1,92 @@
+package money
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateProvider supplies exchange rates. Implementations may be a static table
+// or a client of an FX service.
+type RateProvider interface {
+	// Rate returns the price of one unit of from in to, or
+	// ErrRateUnavailable when the pair is not quoted
+	Rate(ctx context.Context, from, to Currency) (float64, error)
+}
+
+// Converter converts amounts with the rates of a RateProvider
+type Converter struct {
+	provider RateProvider
+}
+
+func NewConverter(provider RateProvider) *Converter {
+	return &Converter{provider: provider}
+}
+
+func (c *Converter) Convert(ctx context.Context, m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	rate, err := c.provider.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Convert(to, rate), nil
+}
+
+// StaticRates quotes every currency against a base currency and derives cross
+// rates from those quotes
+type StaticRates struct {
+	base  Currency
+	rates map[Currency]float64
+}
+
+// NewStaticRates returns a provider where rates[c] is the price of one unit
+// of base in c
+func NewStaticRates(base Currency, rates map[Currency]float64) *StaticRates {
+	quotes := make(map[Currency]float64, len(rates)+1)
+	for currency, rate := range rates {
+		quotes[currency] = rate
+	}
+	quotes[base] = 1
+	return &StaticRates{base: base, rates: quotes}
+}
+
+func (s *StaticRates) Rate(_ context.Context, from, to Currency) (float64, error) {
+	fromRate, ok := s.rates[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", ErrRateUnavailable, from, to)
+	}
+	toRate, ok := s.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", ErrRateUnavailable, from, to)
+	}
+	return toRate / fromRate, nil
+}
+
+// ParseRates reads a comma-separated list of CODE=rate quotes such as
+// "EUR=0.92,GBP=0.79"
+func ParseRates(list string) (map[Currency]float64, error) {
+	rates := make(map[Currency]float64)
+	for _, quote := range strings.Split(list, ",") {
+		quote = strings.TrimSpace(quote)
+		if quote == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(quote, "=")
+		if !ok {
+			return nil, fmt.Errorf("rate %q must be CODE=rate", quote)
+		}
+		currency, err := ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("rate %q: %w", quote, err)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate %q must be a positive number", quote)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
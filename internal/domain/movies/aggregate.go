@@ -0,0 +1,99 @@
+package movies
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AggregateKind says which movie attribute the movies of an aggregate share
+type AggregateKind string
+
+const (
+	AggregateDirector AggregateKind = "director"
+	AggregateGenre    AggregateKind = "genre"
+)
+
+// RankingMetric is the score top lists rank movies by
+type RankingMetric string
+
+const (
+	// RankByBayesian ranks by the Bayesian average, which pulls movies with
+	// few ratings towards the global average
+	RankByBayesian RankingMetric = "bayesian"
+	// RankByWilson ranks by the lower bound of the Wilson score interval,
+	// which ranks movies with few ratings low until they have more
+	RankByWilson RankingMetric = "wilson"
+)
+
+// ErrInvalidRankingMetric is returned for a ranking metric other than
+// bayesian or wilson
+var ErrInvalidRankingMetric = errors.New("ranking metric must be 'bayesian' or 'wilson'")
+
+// ParseRankingMetric accepts "bayesian" or "wilson" in any case
+func ParseRankingMetric(s string) (RankingMetric, error) {
+	switch metric := RankingMetric(strings.ToLower(strings.TrimSpace(s))); metric {
+	case RankByBayesian, RankByWilson:
+		return metric, nil
+	default:
+		return "", ErrInvalidRankingMetric
+	}
+}
+
+// Ranking is how top lists score movies
+type Ranking struct {
+	Metric RankingMetric
+	// ConfidenceK weighs the global average in Bayesian averages, which are
+	// computed whatever the metric
+	ConfidenceK float64
+}
+
+// RatedTitle is a movie of an aggregate with its scores
+type RatedTitle struct {
+	MovieID          MovieID
+	Title            string
+	ReleaseYear      int
+	BayesianAverage  float64
+	WilsonLowerBound float64
+	TotalRatings     int64
+}
+
+// AggregateStats summarize the movies of one director or genre. Names are
+// matched case-insensitively; Name is the spelling most of the movies use.
+// Best and worst are only picked among rated movies and are nil when none
+// are rated.
+type AggregateStats struct {
+	Kind         AggregateKind
+	Name         string
+	MovieCount   int64
+	RatedMovies  int64
+	TotalRatings int64
+	// AverageBayesian is the mean of the movies' Bayesian averages, so
+	// movies with few ratings count towards the global average
+	AverageBayesian   float64
+	BestRated         *RatedTitle
+	WorstRated        *RatedTitle
+	ScoreDistribution map[int]int64 // Visible ratings per score
+}
+
+// NormalizeAggregateName trims name and rejects blank names
+func NormalizeAggregateName(kind AggregateKind, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%s is required", kind)
+	}
+	return name, nil
+}
+
+// AggregateRepository computes statistics over the movies sharing a
+// director, genre or decade
+type AggregateRepository interface {
+	// GetAggregateStats returns the stats of the movies of kind named name,
+	// picking the best and worst by ranking. It returns an error containing
+	// "not found" when no movie matches.
+	GetAggregateStats(ctx context.Context, kind AggregateKind, name string, ranking Ranking) (*AggregateStats, error)
+	// GetDecades returns every decade with movies, oldest first, with its
+	// top rated movies by ranking, at most top of them
+	GetDecades(ctx context.Context, ranking Ranking, top int) ([]*DecadeSummary, error)
+}
@@ -0,0 +1,160 @@
+package movies
+
+import (
+	"context"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+// CertificationSystem is the age rating board of a territory
+type CertificationSystem string
+
+const (
+	SystemMPAA CertificationSystem = "MPAA"
+	SystemBBFC CertificationSystem = "BBFC"
+	SystemFSK  CertificationSystem = "FSK"
+)
+
+// DefaultTerritory is the territory of the movie's Rating field, which is
+// kept as its MPAA certification
+const DefaultTerritory = "US"
+
+type ratingBoard struct {
+	system CertificationSystem
+	// levels lists the certifications from least to most restrictive
+	levels []string
+}
+
+var ratingBoards = map[string]ratingBoard{
+	"US": {system: SystemMPAA, levels: AllowedRatings()},
+	"GB": {system: SystemBBFC, levels: []string{"U", "PG", "12A", "12", "15", "18", "R18"}},
+	"DE": {system: SystemFSK, levels: []string{"0", "6", "12", "16", "18"}},
+}
+
+// Certification is a movie's age rating in one territory. The US
+// certification is the movie's Rating.
+type Certification struct {
+	MovieID       MovieID             `db:"movie_id"`
+	Territory     string              `db:"territory"`
+	System        CertificationSystem `db:"-"`
+	Certification string              `db:"certification"`
+	UpdatedAt     time.Time           `db:"updated_at"`
+}
+
+type CertificationRequest struct {
+	Certification string `json:"certification"`
+}
+
+func NewCertification(movieID MovieID, territory, certification string, timeProvider shared.TimeProvider) (*Certification, error) {
+	if movieID == "" {
+		return nil, ErrEmptyMovieID
+	}
+	normalizedTerritory, board, err := lookupBoard(territory)
+	if err != nil {
+		return nil, err
+	}
+	level, err := board.level(certification)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certification{
+		MovieID:       movieID,
+		Territory:     normalizedTerritory,
+		System:        board.system,
+		Certification: board.levels[level],
+		UpdatedAt:     timeProvider.Now(),
+	}, nil
+}
+
+// SystemOf returns the rating board of a territory
+func SystemOf(territory string) (CertificationSystem, error) {
+	_, board, err := lookupBoard(territory)
+	if err != nil {
+		return "", err
+	}
+	return board.system, nil
+}
+
+// NormalizeTerritory returns the canonical code of a territory with a
+// supported rating board
+func NormalizeTerritory(territory string) (string, error) {
+	normalized, _, err := lookupBoard(territory)
+	return normalized, err
+}
+
+// CertificationsUpTo returns the certifications of the territory's board
+// that are no more restrictive than max, e.g. U, PG and 12A for 12A in GB
+func CertificationsUpTo(territory, max string) ([]string, error) {
+	_, board, err := lookupBoard(territory)
+	if err != nil {
+		return nil, err
+	}
+	level, err := board.level(max)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), board.levels[:level+1]...), nil
+}
+
+// ContentPolicy lets through movies certified with one of Certifications
+// in Territory; movies without a certification there are left out
+type ContentPolicy struct {
+	Territory      string
+	Certifications []string
+}
+
+// NewContentPolicy allows movies certified at most maxCertification in
+// territory
+func NewContentPolicy(territory, maxCertification string) (*ContentPolicy, error) {
+	normalized, err := NormalizeTerritory(territory)
+	if err != nil {
+		return nil, err
+	}
+	certifications, err := CertificationsUpTo(normalized, maxCertification)
+	if err != nil {
+		return nil, err
+	}
+	return &ContentPolicy{Territory: normalized, Certifications: certifications}, nil
+}
+
+func lookupBoard(territory string) (string, ratingBoard, error) {
+	normalized, err := NormalizeRegion(territory)
+	if err != nil {
+		return "", ratingBoard{}, ErrUnsupportedTerritory
+	}
+	board, ok := ratingBoards[normalized]
+	if !ok {
+		return "", ratingBoard{}, ErrUnsupportedTerritory
+	}
+	return normalized, board, nil
+}
+
+// level finds a certification ignoring case, spaces and hyphens, so PG-13
+// matches PG13
+func (b ratingBoard) level(certification string) (int, error) {
+	compact := func(s string) string {
+		return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+	}
+	wanted := compact(certification)
+	for i, level := range b.levels {
+		if compact(level) == wanted {
+			return i, nil
+		}
+	}
+	return 0, ErrInvalidCertification
+}
+
+// CertificationRepository stores movie certifications per territory. The
+// US certification is read from and written to the movie's rating.
+type CertificationRepository interface {
+	// SaveCertification creates or replaces the movie's certification in its territory
+	SaveCertification(ctx context.Context, certification *Certification) (*Certification, error)
+	// GetCertifications returns the movie's certifications ordered by territory
+	GetCertifications(ctx context.Context, movieID MovieID) ([]*Certification, error)
+	DeleteCertification(ctx context.Context, movieID MovieID, territory string) error
+	// FilterAllowed returns the IDs among ids of movies policy lets through,
+	// in the order given
+	FilterAllowed(ctx context.Context, ids []MovieID, policy ContentPolicy) ([]MovieID, error)
+}
@@ -0,0 +1,54 @@
+package movies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCertification(t *testing.T) {
+	now := fixedTimeProvider{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	certification, err := NewCertification("movie-1", "gb", "12a", now)
+	require.NoError(t, err)
+	assert.Equal(t, "GB", certification.Territory)
+	assert.Equal(t, SystemBBFC, certification.System)
+	assert.Equal(t, "12A", certification.Certification)
+
+	certification, err = NewCertification("movie-1", "US", "PG-13", now)
+	require.NoError(t, err)
+	assert.Equal(t, "PG13", certification.Certification, "the US value is the movie's rating")
+
+	tests := []struct {
+		territory, certification string
+		want                     error
+	}{
+		{"FR", "12", ErrUnsupportedTerritory},
+		{"DEU", "12", ErrUnsupportedTerritory},
+		{"DE", "PG", ErrInvalidCertification},
+		{"GB", "R", ErrInvalidCertification},
+		{"US", "15", ErrInvalidCertification},
+	}
+	for _, tt := range tests {
+		_, err := NewCertification("movie-1", tt.territory, tt.certification, now)
+		assert.ErrorIs(t, err, tt.want, tt)
+	}
+
+	_, err = NewCertification("", "DE", "12", now)
+	assert.ErrorIs(t, err, ErrEmptyMovieID)
+}
+
+func TestCertificationsUpTo(t *testing.T) {
+	allowed, err := CertificationsUpTo("DE", "12")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "6", "12"}, allowed)
+
+	allowed, err = CertificationsUpTo("US", "pg")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"G", "PG"}, allowed)
+
+	_, err = CertificationsUpTo("GB", "PG13")
+	assert.ErrorIs(t, err, ErrInvalidCertification)
+}
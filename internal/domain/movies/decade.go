@@ -0,0 +1,39 @@
+package movies
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidDecade = errors.New("decade must be a decade's first year such as 1990 or 1990s")
+
+// DecadeSummary is the movies released in one decade
+type DecadeSummary struct {
+	Decade     int // The decade's first year, e.g. 1990
+	MovieCount int64
+	// TopRated are the decade's rated movies with the highest Bayesian
+	// averages, best first
+	TopRated []*RatedTitle
+}
+
+// ParseDecade reads a decade written as its first year, with or without a
+// trailing s: 1990 and 1990s are both the nineties
+func ParseDecade(s string) (int, error) {
+	decade, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(s), "s"))
+	if err != nil || decade%10 != 0 || decade < FirstMovieYear/10*10 {
+		return 0, ErrInvalidDecade
+	}
+	return decade, nil
+}
+
+// DecadeLabel writes decade the way ParseDecade reads it, e.g. 1990s
+func DecadeLabel(decade int) string {
+	return fmt.Sprintf("%ds", decade)
+}
+
+// DecadeYears returns the first and the last year of decade
+func DecadeYears(decade int) (first, last int) {
+	return decade, decade + 9
+}
@@ -0,0 +1,28 @@
+package movies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecade(t *testing.T) {
+	for _, s := range []string{"1990", "1990s", " 1990s "} {
+		decade, err := ParseDecade(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, 1990, decade)
+	}
+	decade, err := ParseDecade("1880s")
+	require.NoError(t, err, "the decade of the first movie")
+	assert.Equal(t, 1880, decade)
+
+	for _, s := range []string{"", "nineties", "1995", "90s", "1870s", "1990ss"} {
+		_, err := ParseDecade(s)
+		assert.ErrorIs(t, err, ErrInvalidDecade, s)
+	}
+
+	assert.Equal(t, "1990s", DecadeLabel(1990))
+	first, last := DecadeYears(1990)
+	assert.Equal(t, []int{1990, 1999}, []int{first, last})
+}
@@ -0,0 +1,48 @@
+package movies
+
+import "strings"
+
+// DuplicateReason says why an existing movie was flagged as a possible
+// duplicate of a new one
+type DuplicateReason string
+
+const (
+	DuplicateIMDbID    DuplicateReason = "imdb_id"
+	DuplicateTitleYear DuplicateReason = "title_year"
+)
+
+// DuplicateCandidate is an existing movie that may describe the same film
+type DuplicateCandidate struct {
+	Movie  *Movie
+	Reason DuplicateReason
+}
+
+// NormalizeTitle lowercases title and keeps only ASCII letters and digits, so
+// "Se7en", "SE7EN" and "Se7en!" compare equal. It must stay in sync with the
+// normalized title expression used by the movie repository.
+func NormalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// MatchDuplicates classifies existing movies against movie, dropping any
+// that match neither by IMDb ID nor by normalized title and release year
+func MatchDuplicates(movie *Movie, existing []*Movie) []*DuplicateCandidate {
+	title := NormalizeTitle(movie.Title)
+
+	var candidates []*DuplicateCandidate
+	for _, e := range existing {
+		switch {
+		case movie.IMDbID != nil && e.IMDbID != nil && *movie.IMDbID == *e.IMDbID:
+			candidates = append(candidates, &DuplicateCandidate{Movie: e, Reason: DuplicateIMDbID})
+		case title != "" && e.ReleaseYear == movie.ReleaseYear && NormalizeTitle(e.Title) == title:
+			candidates = append(candidates, &DuplicateCandidate{Movie: e, Reason: DuplicateTitleYear})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,32 @@
+package movies
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	assert.Equal(t, "se7en", NormalizeTitle("Se7en"))
+	assert.Equal(t, "se7en", NormalizeTitle(" SE7EN! "))
+	assert.Equal(t, "walle", NormalizeTitle("WALL·E"))
+	assert.Equal(t, "", NormalizeTitle("千と千尋"))
+}
+
+func TestMatchDuplicates(t *testing.T) {
+	imdb := "tt0114369"
+	movie := &Movie{Title: "Se7en", ReleaseYear: 1995, IMDbID: &imdb}
+
+	candidates := MatchDuplicates(movie, []*Movie{
+		{ID: "same-imdb", Title: "Seven", ReleaseYear: 1995, IMDbID: &imdb},
+		{ID: "same-title", Title: "SE7EN", ReleaseYear: 1995},
+		{ID: "other-year", Title: "Se7en", ReleaseYear: 2005},
+	})
+
+	require.Len(t, candidates, 2)
+	assert.Equal(t, MovieID("same-imdb"), candidates[0].Movie.ID)
+	assert.Equal(t, DuplicateIMDbID, candidates[0].Reason)
+	assert.Equal(t, MovieID("same-title"), candidates[1].Movie.ID)
+	assert.Equal(t, DuplicateTitleYear, candidates[1].Reason)
+}
@@ -2,6 +2,7 @@ package movies
 
 import (
 	"strings"
+	"thermondo/internal/domain/money"
 	"thermondo/internal/domain/shared"
 	"time"
 )
@@ -14,22 +15,28 @@ const (
 type MovieID string
 
 type Movie struct {
-	ID           MovieID   `db:"id"`
-	Title        string    `db:"title"`
-	Description  string    `db:"description"`
-	ReleaseYear  int       `db:"release_year"`
-	Genre        string    `db:"genre"`
-	Director     string    `db:"director"`
-	DurationMins int       `db:"duration_mins"`
-	Rating       Rating    `db:"rating"` // G, PG, PG13, Restricted, NC17, etc.
-	Language     string    `db:"language"`
-	Country      string    `db:"country"`
-	Budget       *int64    `db:"budget"`     // Optional, int64(avoid floating point issues)
-	Revenue      *int64    `db:"revenue"`    // Optional, int64(avoid floating point issues)
-	IMDbID       *string   `db:"imdb_id"`    // Optional external reference
-	PosterURL    *string   `db:"poster_url"` // Optional poster image
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	ID           MovieID        `db:"id"`
+	Title        string         `db:"title"`
+	Description  string         `db:"description"`
+	ReleaseYear  int            `db:"release_year"`
+	Genre        string         `db:"genre"`
+	Director     string         `db:"director"`
+	DurationMins int            `db:"duration_mins"`
+	Rating       Rating         `db:"rating"` // G, PG, PG13, Restricted, NC17, etc.
+	Language     string         `db:"language"`
+	Country      string         `db:"country"`
+	Budget       *int64         `db:"budget"`     // Optional, minor units of Currency
+	Revenue      *int64         `db:"revenue"`    // Optional, minor units of Currency
+	Currency     money.Currency `db:"currency"`   // Currency of Budget and Revenue
+	IMDbID       *string        `db:"imdb_id"`    // Optional external reference
+	PosterURL    *string        `db:"poster_url"` // Optional poster image
+	CreatedAt    time.Time      `db:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at"`
+	// Locale is set when Title and Description were replaced by a translation;
+	// it is empty for the canonical record
+	Locale string `db:"-"`
+	// Slug is the movie's current slug, set by the lookups that return it
+	Slug string `db:"-"`
 }
 
 type CreateMovieRequest struct {
@@ -44,20 +51,39 @@ type CreateMovieRequest struct {
 	Country      string  `json:"country"`
 	Budget       *int64  `json:"budget,omitempty"`
 	Revenue      *int64  `json:"revenue,omitempty"`
-	IMDbID       *string `json:"imdb_id,omitempty"`
-	PosterURL    *string `json:"poster_url,omitempty"`
+	// Currency is the ISO 4217 code of Budget and Revenue, USD by default
+	Currency  string  `json:"currency,omitempty"`
+	IMDbID    *string `json:"imdb_id,omitempty"`
+	PosterURL *string `json:"poster_url,omitempty"`
+	// AllowDuplicate skips the potential duplicate check
+	AllowDuplicate bool `json:"allow_duplicate,omitempty"`
 }
 
 type SearchMoviesRequest struct {
-	Query    string `json:"query,omitempty"`
-	Genre    string `json:"genre,omitempty"`
-	Director string `json:"director,omitempty"`
-	MinYear  *int   `json:"min_year,omitempty"`
-	MaxYear  *int   `json:"max_year,omitempty"`
-	Limit    int    `json:"limit"`
-	Offset   int    `json:"offset"`
-	SortBy   string `json:"sort_by"`
-	Order    string `json:"order"`
+	Query       string   `json:"query,omitempty"`
+	Genre       string   `json:"genre,omitempty"`
+	Director    string   `json:"director,omitempty"`
+	MinYear     *int     `json:"min_year,omitempty"`
+	MaxYear     *int     `json:"max_year,omitempty"`
+	Language    string   `json:"language,omitempty"`
+	Country     string   `json:"country,omitempty"`
+	MinDuration *int     `json:"min_duration,omitempty"`
+	MinRating   *float64 `json:"min_rating,omitempty"` // minimum Bayesian average rating
+	Featuring   string   `json:"featuring,omitempty"`  // name of a credited person
+	Limit       int      `json:"limit"`
+	Offset      int      `json:"offset"`
+	SortBy      string   `json:"sort_by"`
+	Order       string   `json:"order"`
+
+	// MaxCertification keeps movies certified at most this in Territory,
+	// US by default; uncertified movies are left out
+	MaxCertification string `json:"max_certification,omitempty"`
+	Territory        string `json:"territory,omitempty"`
+
+	// Provider keeps movies offered on this provider, in Region when set,
+	// e.g. only movies on netflix in DE
+	Provider string `json:"provider,omitempty"`
+	Region   string `json:"region,omitempty"`
 }
 
 func NewMovie(
@@ -80,6 +106,7 @@ func NewMovie(
 		DurationMins: durationMins,
 		Language:     strings.TrimSpace(language),
 		Country:      strings.TrimSpace(country),
+		Currency:     money.DefaultCurrency,
 		CreatedAt:    timeProvider.Now(),
 		UpdatedAt:    timeProvider.Now(),
 	}
@@ -133,5 +160,26 @@ func (m *Movie) Validate(timeProvider shared.TimeProvider) error {
 		return ErrInvalidRevenue
 	}
 
+	if !m.Currency.IsValid() {
+		return ErrInvalidCurrency
+	}
+
 	return nil
 }
+
+// BudgetMoney returns the budget with its currency, or nil when unknown
+func (m *Movie) BudgetMoney() *money.Money {
+	return m.amount(m.Budget)
+}
+
+// RevenueMoney returns the revenue with its currency, or nil when unknown
+func (m *Movie) RevenueMoney() *money.Money {
+	return m.amount(m.Revenue)
+}
+
+func (m *Movie) amount(minorUnits *int64) *money.Money {
+	if minorUnits == nil {
+		return nil
+	}
+	return &money.Money{Amount: *minorUnits, Currency: m.Currency}
+}
@@ -12,4 +12,24 @@ var (
 	ErrEmptyCountry    = errors.New("country cannot be empty")
 	ErrInvalidBudget   = errors.New("budget must be non-negative")
 	ErrInvalidRevenue  = errors.New("revenue must be non-negative")
+	ErrInvalidCurrency = errors.New("currency must be a supported ISO 4217 code, e.g. USD or EUR")
+	ErrEmptyMovieID    = errors.New("movie ID cannot be empty")
+	ErrInvalidLocale   = errors.New("locale must be a language code with an optional region, e.g. de or pt-BR")
+	ErrMergeIntoSelf   = errors.New("a movie cannot be merged into itself")
+	ErrInvalidRating   = errors.New("rating must be one of G, PG, PG13, Restricted, NC17")
+
+	ErrInvalidRegion      = errors.New("region must be an ISO 3166-1 alpha-2 country code, e.g. DE or US")
+	ErrInvalidReleaseType = errors.New("release type must be theatrical or streaming")
+	ErrInvalidReleaseDate = errors.New("release date must be a YYYY-MM-DD date between 1888 and 5 years from now")
+
+	ErrInvalidProviderID   = errors.New("provider must be a lowercase slug of letters, digits and dashes, e.g. netflix or prime-video")
+	ErrInvalidProviderName = errors.New("provider name must be 1 to 100 characters")
+	ErrInvalidOfferType    = errors.New("offer type must be subscription, free, rent or buy")
+	ErrInvalidOfferURL     = errors.New("url must be an absolute http or https URL")
+
+	ErrUnsupportedTerritory = errors.New("territory must be one with a supported rating board: US (MPAA), GB (BBFC) or DE (FSK)")
+	ErrInvalidCertification = errors.New("certification is not a level of the territory's rating board")
+
+	ErrInvalidPosterVariant = errors.New("poster variant must be one of small, medium, large")
+	ErrInvalidPosterImage   = errors.New("poster must be a JPEG or PNG image")
 )
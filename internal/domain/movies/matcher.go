@@ -0,0 +1,24 @@
+package movies
+
+import "context"
+
+// TitleMatch is a movie whose title resembles a searched title. Similarity
+// runs from 0 to 1, where 1 is an identical normalized title.
+type TitleMatch struct {
+	Movie      *Movie
+	Similarity float64
+}
+
+// Matcher finds the movies that entries exported from other services refer
+// to, by our ID, by IMDb ID or by title
+type Matcher interface {
+	// GetByIDs returns the movies that exist among ids, keyed by ID
+	GetByIDs(ctx context.Context, ids []MovieID) (map[MovieID]*Movie, error)
+	// GetByIMDbIDs returns the movies that exist among the IMDb IDs, keyed
+	// by IMDb ID
+	GetByIMDbIDs(ctx context.Context, imdbIDs []string) (map[string]*Movie, error)
+	// MatchTitle returns up to limit movies whose title resembles title, most
+	// similar first. A year other than 0 keeps movies released within a year
+	// of it, as services disagree on release years around the new year.
+	MatchTitle(ctx context.Context, title string, year int, limit int) ([]*TitleMatch, error)
+}
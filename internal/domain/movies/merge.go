@@ -0,0 +1,33 @@
+package movies
+
+import (
+	"context"
+	"time"
+)
+
+// MergeRecord is the audit entry written when a duplicate movie is merged
+// into another. The source movie is deleted and its ID redirects to the target.
+type MergeRecord struct {
+	ID       int64     `db:"id"`
+	SourceID MovieID   `db:"source_movie_id"`
+	TargetID MovieID   `db:"target_movie_id"`
+	MergedBy string    `db:"merged_by"`
+	MergedAt time.Time `db:"merged_at"`
+	// RatingsMoved counts source ratings reassigned to the target;
+	// RatingsDropped counts the older rating of each user who rated both
+	RatingsMoved   int64 `db:"ratings_moved"`
+	RatingsDropped int64 `db:"ratings_dropped"`
+}
+
+// MergeRepository merges duplicate movies and resolves the IDs they leave behind
+type MergeRepository interface {
+	// Merge moves everything attached to source onto target in one
+	// transaction: ratings (keeping the newer rating when a user rated both),
+	// credits, translations and collection and list entries. Metadata missing
+	// on target is filled from source, and the slugs of source become old
+	// slugs of target. source is then deleted and replaced by a redirect, and
+	// the merge is recorded in the audit table.
+	Merge(ctx context.Context, sourceID, targetID MovieID, mergedBy string, mergedAt time.Time) (*MergeRecord, error)
+	// ResolveRedirect returns the movie a merged ID now points to
+	ResolveRedirect(ctx context.Context, id MovieID) (MovieID, error)
+}
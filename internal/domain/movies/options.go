@@ -1,6 +1,9 @@
 package movies
 
-import "strings"
+import (
+	"strings"
+	"thermondo/internal/domain/money"
+)
 
 type MovieOption func(*Movie)
 
@@ -18,6 +21,14 @@ func WithRevenue(revenue int64) MovieOption {
 	}
 }
 
+// WithCurrency sets the currency budget and revenue are recorded in. Codes
+// are normalized; unsupported ones fail validation.
+func WithCurrency(currency string) MovieOption {
+	return func(m *Movie) {
+		m.Currency = money.Currency(strings.ToUpper(strings.TrimSpace(currency)))
+	}
+}
+
 func WithIMDbID(imdbID string) MovieOption {
 	return func(m *Movie) {
 		imdbID = strings.TrimSpace(imdbID)
@@ -47,11 +58,17 @@ func WithRating(rating string) MovieOption {
 
 //=================================== Search Options ===================================
 
+// SortFields are the fields movies can be sorted by; controversy, how
+// divided the ratings are, sorts the most divisive movies first with desc
+var SortFields = []string{"controversy", "created_at", "director", "duration_mins", "genre", "release_year", "title", "updated_at"}
+
 type SearchOptions struct {
 	Limit  int
 	Offset int
-	SortBy string // "title", "release_year", "created_at"
-	Order  string // "asc", "desc"
+	// SortBy is one of SortFields, or several with directions, e.g.
+	// "release_year:desc,title:asc"
+	SortBy string
+	Order  string // "asc", "desc"; the direction of fields SortBy gives none
 }
 
 func DefaultSearchOptions() SearchOptions {
@@ -0,0 +1,89 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// PosterVariant names one of the sizes an uploaded poster is resized to
+type PosterVariant string
+
+const (
+	PosterSmall  PosterVariant = "small"
+	PosterMedium PosterVariant = "medium"
+	PosterLarge  PosterVariant = "large"
+)
+
+// PosterVariants lists every variant rendered on upload, smallest first
+var PosterVariants = []PosterVariant{PosterSmall, PosterMedium, PosterLarge}
+
+var posterWidths = map[PosterVariant]int{
+	PosterSmall:  185,
+	PosterMedium: 342,
+	PosterLarge:  780,
+}
+
+// Width is the variant's target width in pixels
+func (v PosterVariant) Width() int {
+	return posterWidths[v]
+}
+
+func ParsePosterVariant(s string) (PosterVariant, error) {
+	variant := PosterVariant(s)
+	if _, ok := posterWidths[variant]; !ok {
+		return "", ErrInvalidPosterVariant
+	}
+	return variant, nil
+}
+
+// Poster is one stored variant of a movie's poster image
+type Poster struct {
+	MovieID     MovieID       `db:"movie_id"`
+	Variant     PosterVariant `db:"variant"`
+	StorageKey  string        `db:"storage_key"`
+	ContentType string        `db:"content_type"`
+	Width       int           `db:"width"`
+	Height      int           `db:"height"`
+	SizeBytes   int64         `db:"size_bytes"`
+	CreatedAt   time.Time     `db:"created_at"`
+}
+
+// PosterHash is a perceptual hash of a poster image; copies of the same
+// artwork hash a few bits apart at most
+type PosterHash uint64
+
+// Distance counts the bits two hashes differ in, 0 for identical posters
+func (h PosterHash) Distance(other PosterHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// String renders the hash as 16 hex digits
+func (h PosterHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// PosterFingerprint is the hash of a movie's current poster, with the title
+// and year to tell the movie apart by
+type PosterFingerprint struct {
+	MovieID     MovieID
+	Title       string
+	ReleaseYear int
+	Hash        PosterHash
+	CreatedAt   time.Time
+}
+
+type PosterRepository interface {
+	// ReplacePosters swaps a movie's poster variants for posters, records
+	// the hash of the uploaded image and points its poster_url at posterURL
+	// in one transaction. It returns the variants that were replaced so their
+	// stored objects can be removed.
+	ReplacePosters(ctx context.Context, movieID MovieID, posterURL string, posters []*Poster, hash PosterHash) ([]*Poster, error)
+	GetPoster(ctx context.Context, movieID MovieID, variant PosterVariant) (*Poster, error)
+	ListPosters(ctx context.Context, movieID MovieID) ([]*Poster, error)
+	// ListPosterHashes returns the poster hash of every movie that has one.
+	// Posters uploaded before hashes were recorded have none until they are
+	// uploaded again.
+	ListPosterHashes(ctx context.Context) ([]*PosterFingerprint, error)
+}
@@ -0,0 +1,143 @@
+package movies
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+// providerIDPattern accepts the slug of a streaming service, e.g. netflix
+// or prime-video
+var providerIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Provider is a streaming service or store movies can be watched on
+type Provider struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type ProviderRequest struct {
+	Name string `json:"name"`
+}
+
+func NewProvider(id, name string, timeProvider shared.TimeProvider) (*Provider, error) {
+	normalized, err := NormalizeProviderID(id)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidProviderName
+	}
+
+	return &Provider{
+		ID:        normalized,
+		Name:      name,
+		CreatedAt: timeProvider.Now(),
+		UpdatedAt: timeProvider.Now(),
+	}, nil
+}
+
+// NormalizeProviderID returns the lowercase slug of a provider
+func NormalizeProviderID(id string) (string, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if !providerIDPattern.MatchString(id) {
+		return "", ErrInvalidProviderID
+	}
+	return id, nil
+}
+
+// OfferType says how a movie can be watched on a provider
+type OfferType string
+
+const (
+	OfferSubscription OfferType = "subscription"
+	OfferFree         OfferType = "free"
+	OfferRent         OfferType = "rent"
+	OfferBuy          OfferType = "buy"
+)
+
+func ParseOfferType(value string) (OfferType, error) {
+	switch t := OfferType(strings.ToLower(strings.TrimSpace(value))); t {
+	case OfferSubscription, OfferFree, OfferRent, OfferBuy:
+		return t, nil
+	}
+	return "", ErrInvalidOfferType
+}
+
+// Offer is where to watch a movie in one region: on a provider, by
+// subscription, for free, to rent or to buy. URL links to the movie on the
+// provider when known.
+type Offer struct {
+	MovieID      MovieID   `db:"movie_id"`
+	Region       string    `db:"region"`
+	ProviderID   string    `db:"provider_id"`
+	ProviderName string    `db:"provider_name"`
+	Type         OfferType `db:"type"`
+	URL          *string   `db:"url"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+type OfferRequest struct {
+	URL *string `json:"url"`
+}
+
+func NewOffer(movieID MovieID, region, providerID, offerType string, link *string, timeProvider shared.TimeProvider) (*Offer, error) {
+	if movieID == "" {
+		return nil, ErrEmptyMovieID
+	}
+	normalizedRegion, err := NormalizeRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	normalizedProvider, err := NormalizeProviderID(providerID)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseOfferType(offerType)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		trimmed := strings.TrimSpace(*link)
+		u, err := url.ParseRequestURI(trimmed)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, ErrInvalidOfferURL
+		}
+		link = &trimmed
+	}
+
+	return &Offer{
+		MovieID:    movieID,
+		Region:     normalizedRegion,
+		ProviderID: normalizedProvider,
+		Type:       t,
+		URL:        link,
+		CreatedAt:  timeProvider.Now(),
+		UpdatedAt:  timeProvider.Now(),
+	}, nil
+}
+
+// ProviderRepository stores the providers and where movies can be watched
+// on them. Offers are entered by admins; nothing syncs them from a provider
+// catalog yet.
+type ProviderRepository interface {
+	// ListProviders returns every provider by name
+	ListProviders(ctx context.Context) ([]*Provider, error)
+	// SaveProvider creates a provider or renames it
+	SaveProvider(ctx context.Context, provider *Provider) (*Provider, error)
+	// SaveOffer creates or replaces the offer of its movie, region, provider
+	// and type. It fails with a not found error for an unknown movie or
+	// provider.
+	SaveOffer(ctx context.Context, offer *Offer) (*Offer, error)
+	// GetOffers returns the movie's offers by region and provider name, only
+	// those in region when it is set
+	GetOffers(ctx context.Context, movieID MovieID, region string) ([]*Offer, error)
+	DeleteOffer(ctx context.Context, movieID MovieID, region, providerID string, offerType OfferType) error
+}
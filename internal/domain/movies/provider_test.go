@@ -0,0 +1,60 @@
+package movies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProvider(t *testing.T) {
+	now := fixedTimeProvider{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	provider, err := NewProvider(" Prime-Video ", " Amazon Prime Video ", now)
+	require.NoError(t, err)
+	assert.Equal(t, "prime-video", provider.ID)
+	assert.Equal(t, "Amazon Prime Video", provider.Name)
+
+	_, err = NewProvider("prime video", "Amazon Prime Video", now)
+	assert.ErrorIs(t, err, ErrInvalidProviderID)
+	_, err = NewProvider("-netflix", "Netflix", now)
+	assert.ErrorIs(t, err, ErrInvalidProviderID)
+	_, err = NewProvider("netflix", " ", now)
+	assert.ErrorIs(t, err, ErrInvalidProviderName)
+}
+
+func TestNewOffer(t *testing.T) {
+	now := fixedTimeProvider{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+	link := " https://www.netflix.com/title/80100172 "
+
+	offer, err := NewOffer("movie-1", "de", "Netflix", "Subscription", &link, now)
+	require.NoError(t, err)
+	assert.Equal(t, "DE", offer.Region)
+	assert.Equal(t, "netflix", offer.ProviderID)
+	assert.Equal(t, OfferSubscription, offer.Type)
+	assert.Equal(t, "https://www.netflix.com/title/80100172", *offer.URL)
+
+	offer, err = NewOffer("movie-1", "DE", "netflix", "rent", nil, now)
+	require.NoError(t, err)
+	assert.Nil(t, offer.URL)
+
+	badLink := "netflix.com/title/80100172"
+	tests := []struct {
+		region, provider, offerType string
+		link                        *string
+		want                        error
+	}{
+		{"DEU", "netflix", "rent", nil, ErrInvalidRegion},
+		{"DE", "net flix", "rent", nil, ErrInvalidProviderID},
+		{"DE", "netflix", "stream", nil, ErrInvalidOfferType},
+		{"DE", "netflix", "rent", &badLink, ErrInvalidOfferURL},
+	}
+	for _, tt := range tests {
+		_, err := NewOffer("movie-1", tt.region, tt.provider, tt.offerType, tt.link, now)
+		assert.ErrorIs(t, err, tt.want, tt)
+	}
+
+	_, err = NewOffer("", "DE", "netflix", "rent", nil, now)
+	assert.ErrorIs(t, err, ErrEmptyMovieID)
+}
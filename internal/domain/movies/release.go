@@ -0,0 +1,122 @@
+package movies
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+// ReleaseDateLayout is the format of release dates in requests and responses
+const ReleaseDateLayout = "2006-01-02"
+
+// regionPattern accepts an ISO 3166-1 alpha-2 country code
+var regionPattern = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+
+// ReleaseType says how a movie is released in a region
+type ReleaseType string
+
+const (
+	ReleaseTheatrical ReleaseType = "theatrical"
+	ReleaseStreaming  ReleaseType = "streaming"
+)
+
+func ParseReleaseType(value string) (ReleaseType, error) {
+	switch t := ReleaseType(strings.ToLower(strings.TrimSpace(value))); t {
+	case ReleaseTheatrical, ReleaseStreaming:
+		return t, nil
+	}
+	return "", ErrInvalidReleaseType
+}
+
+// Release is when a movie comes out in one region, in theaters or on
+// streaming. A movie has at most one release of each type per region, and
+// its ReleaseYear follows its earliest release.
+type Release struct {
+	MovieID     MovieID     `db:"movie_id"`
+	Region      string      `db:"region"`
+	Type        ReleaseType `db:"type"`
+	ReleaseDate time.Time   `db:"release_date"`
+	CreatedAt   time.Time   `db:"created_at"`
+	UpdatedAt   time.Time   `db:"updated_at"`
+}
+
+type ReleaseRequest struct {
+	ReleaseDate string `json:"release_date"` // YYYY-MM-DD
+}
+
+func NewRelease(movieID MovieID, region, releaseType, releaseDate string, timeProvider shared.TimeProvider) (*Release, error) {
+	if movieID == "" {
+		return nil, ErrEmptyMovieID
+	}
+	normalized, err := NormalizeRegion(region)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseReleaseType(releaseType)
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse(ReleaseDateLayout, strings.TrimSpace(releaseDate))
+	if err != nil {
+		return nil, ErrInvalidReleaseDate
+	}
+	// The earliest release becomes the release year, so dates are held to
+	// the same bounds as the year
+	if date.Year() < FirstMovieYear || date.Year() > timeProvider.Now().Year()+MaxFutureYears {
+		return nil, ErrInvalidReleaseDate
+	}
+
+	return &Release{
+		MovieID:     movieID,
+		Region:      normalized,
+		Type:        t,
+		ReleaseDate: date,
+		CreatedAt:   timeProvider.Now(),
+		UpdatedAt:   timeProvider.Now(),
+	}, nil
+}
+
+// NormalizeRegion returns the uppercase form of an ISO 3166-1 alpha-2 code
+func NormalizeRegion(region string) (string, error) {
+	region = strings.TrimSpace(region)
+	if !regionPattern.MatchString(region) {
+		return "", ErrInvalidRegion
+	}
+	return strings.ToUpper(region), nil
+}
+
+// UpcomingFilter selects releases dated From through To, both inclusive.
+// Region and Type narrow the results when set.
+type UpcomingFilter struct {
+	Region string
+	Type   ReleaseType
+	From   time.Time
+	To     time.Time
+	Limit  int
+	Offset int
+	// Policy leaves out movies it doesn't let through, e.g. in kids mode
+	Policy *ContentPolicy
+}
+
+// UpcomingRelease is a release together with its movie, for calendars
+type UpcomingRelease struct {
+	Release *Release
+	Movie   *Movie
+}
+
+// ReleaseRepository stores the regional releases of movies
+type ReleaseRepository interface {
+	// SaveRelease creates or replaces the release for its movie, region and
+	// type, and sets the movie's release year from its earliest release
+	SaveRelease(ctx context.Context, release *Release) (*Release, error)
+	// GetReleases returns the movie's releases, earliest first
+	GetReleases(ctx context.Context, movieID MovieID) ([]*Release, error)
+	// DeleteRelease removes a release. The release year follows the earliest
+	// remaining release and is kept when none remain.
+	DeleteRelease(ctx context.Context, movieID MovieID, region string, releaseType ReleaseType) error
+	// ListUpcoming returns a page of releases matching filter, by date, and
+	// the total number of matches
+	ListUpcoming(ctx context.Context, filter UpcomingFilter) ([]*UpcomingRelease, int64, error)
+}
@@ -0,0 +1,37 @@
+package movies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRelease(t *testing.T) {
+	now := fixedTimeProvider{now: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	release, err := NewRelease("movie-1", " de ", "Streaming", "2025-03-14", now)
+	require.NoError(t, err)
+	assert.Equal(t, "DE", release.Region)
+	assert.Equal(t, ReleaseStreaming, release.Type)
+	assert.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), release.ReleaseDate)
+
+	tests := []struct {
+		region, releaseType, date string
+		want                      error
+	}{
+		{"DEU", "theatrical", "2025-03-14", ErrInvalidRegion},
+		{"DE", "dvd", "2025-03-14", ErrInvalidReleaseType},
+		{"DE", "theatrical", "14.03.2025", ErrInvalidReleaseDate},
+		{"DE", "theatrical", "1887-12-31", ErrInvalidReleaseDate},
+		{"DE", "theatrical", "2030-01-01", ErrInvalidReleaseDate},
+	}
+	for _, tt := range tests {
+		_, err := NewRelease("movie-1", tt.region, tt.releaseType, tt.date, now)
+		assert.ErrorIs(t, err, tt.want, tt)
+	}
+
+	_, err = NewRelease("", "DE", "theatrical", "2025-03-14", now)
+	assert.ErrorIs(t, err, ErrEmptyMovieID)
+}
@@ -7,9 +7,72 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// SearchFilter combines movie search criteria; every non-empty field narrows
+// the result set (criteria are ANDed). Ranges are inclusive.
+type SearchFilter struct {
+	Query       string // case-insensitive substring of the title
+	Genre       string
+	Director    string
+	MinYear     *int
+	MaxYear     *int
+	Language    string
+	Country     string
+	MinDuration *int // minutes
+	// Featuring keeps movies crediting a person with this name in any role
+	// (director, actor, writer, composer), compared case-insensitively
+	Featuring string
+	// MinBayesianRating keeps movies whose Bayesian average rating is at least
+	// this value. BayesianConfidenceK is the prior weight (m) used to compute it
+	// against the global average rating.
+	MinBayesianRating   *float64
+	BayesianConfidenceK float64
+	// Certifications keeps movies certified in CertificationTerritory with
+	// one of these values
+	CertificationTerritory string
+	Certifications         []string
+	// NotRatedBy keeps movies the user with this ID has not rated
+	NotRatedBy string
+	// Provider keeps movies with an offer on this provider, only in
+	// ProviderRegion when that is set
+	Provider       string
+	ProviderRegion string
+}
+
+// FacetBucket is the number of matching movies sharing one facet value
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SearchFacets holds per-attribute counts over a search result set, used to
+// render filter sidebars. Buckets are ordered by descending count.
+type SearchFacets struct {
+	Genres    []FacetBucket `json:"genres"`
+	Decades   []FacetBucket `json:"decades"`
+	Languages []FacetBucket `json:"languages"`
+	Ratings   []FacetBucket `json:"ratings"` // MPAA rating
+}
+
+// SuggestionKind says which movie attribute an autocomplete suggestion matched
+type SuggestionKind string
+
+const (
+	SuggestionTitle    SuggestionKind = "title"
+	SuggestionDirector SuggestionKind = "director"
+)
+
+// Suggestion is one autocomplete match. MovieID is only set for titles.
+type Suggestion struct {
+	Kind    SuggestionKind `json:"kind"`
+	Text    string         `json:"text"`
+	MovieID MovieID        `json:"movie_id,omitempty"`
+}
+
 // Repository defines the interface for movie data access
 type Repository interface {
 	Save(ctx context.Context, movie *Movie) (*Movie, error)
+	// Update overwrites the stored movie's attributes with movie's
+	Update(ctx context.Context, movie *Movie) (*Movie, error)
 	GetByID(ctx context.Context, id MovieID) (*Movie, error)
 	GetAll(ctx context.Context, options ...SearchOption) ([]*Movie, error)
 	SearchByTitle(ctx context.Context, title string, options ...SearchOption) ([]*Movie, error)
@@ -18,6 +81,20 @@ type Repository interface {
 	GetByGenre(ctx context.Context, genre string, options ...SearchOption) ([]*Movie, error)
 	GetByDirector(ctx context.Context, director string, options ...SearchOption) ([]*Movie, error)
 	GetByYearRange(ctx context.Context, startYear, endYear int, options ...SearchOption) ([]*Movie, error)
+	// Search returns a page of movies matching filter and the total number of
+	// matches. The total is 0 when the page is empty.
+	Search(ctx context.Context, filter SearchFilter, options ...SearchOption) ([]*Movie, int64, error)
+	CountSearch(ctx context.Context, filter SearchFilter) (int64, error)
+	GetSearchFacets(ctx context.Context, filter SearchFilter) (*SearchFacets, error)
+	// Suggest returns up to limit title and director matches for query,
+	// prefix matches first and then by trigram similarity
+	Suggest(ctx context.Context, query string, limit int) ([]*Suggestion, error)
+	// Random returns up to limit movies matching filter, picked and ordered
+	// at random
+	Random(ctx context.Context, filter SearchFilter, limit int) ([]*Movie, error)
+	// FindPotentialDuplicates returns existing movies with the same IMDb ID as
+	// movie, or with the same normalized title (see NormalizeTitle) and release year
+	FindPotentialDuplicates(ctx context.Context, movie *Movie) ([]*Movie, error)
 	GetDB() *sqlx.DB
 	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	ScanMovies(rows *sql.Rows) ([]*Movie, error)
@@ -0,0 +1,89 @@
+package movies
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSlugTitleLength caps the title part of a slug, so long titles still
+// make readable URLs
+const maxSlugTitleLength = 80
+
+// slugFolds spells accented Latin letters without their accents. The
+// backfill of migration 000043 folds the same letters and must stay in sync.
+var slugFolds = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a",
+	"ç", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u",
+	"ý", "y", "ÿ", "y",
+)
+
+// Slug makes the URL-friendly name of a movie from its title and release
+// year, e.g. "Amélie" from 2001 becomes amelie-2001. Runs of anything but
+// letters and digits become a single hyphen. Movies sharing a title and
+// year share the slug; SlugCandidate tells them apart.
+func Slug(title string, releaseYear int) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range slugFolds.Replace(strings.ToLower(title)) {
+		if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') {
+			hyphen = true
+			continue
+		}
+		hyphen = hyphen && b.Len() > 0
+		if hyphen && b.Len()+2 > maxSlugTitleLength || b.Len()+1 > maxSlugTitleLength {
+			break
+		}
+		if hyphen {
+			b.WriteByte('-')
+		}
+		b.WriteRune(r)
+		hyphen = false
+	}
+	if b.Len() == 0 {
+		b.WriteString("movie")
+	}
+	return b.String() + "-" + strconv.Itoa(releaseYear)
+}
+
+// SlugCandidate is the nth choice of slug for a movie whose slug is base:
+// base itself first, then base-2, base-3 and so on
+func SlugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// IsSlugCandidate reports whether slug is one of the candidates of base
+func IsSlugCandidate(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	n, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	parsed, err := strconv.Atoi(n)
+	return err == nil && parsed > 1 && strconv.Itoa(parsed) == n
+}
+
+// SlugRepository keeps the slugs of movies. A movie has one current slug;
+// the slugs it had before stay its own, so old links can be redirected.
+type SlugRepository interface {
+	// AssignSlug makes a candidate of base the movie's current slug and
+	// returns it. A current slug that already is a candidate of base is
+	// kept; otherwise the first candidate no other movie holds is taken.
+	AssignSlug(ctx context.Context, movieID MovieID, base string, assignedAt time.Time) (string, error)
+	// CurrentSlug returns the movie's current slug
+	CurrentSlug(ctx context.Context, movieID MovieID) (string, error)
+	// ResolveSlug returns the movie a current or old slug belongs to and
+	// the movie's current slug
+	ResolveSlug(ctx context.Context, slug string) (MovieID, string, error)
+}
@@ -0,0 +1,42 @@
+package movies
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		title string
+		year  int
+		want  string
+	}{
+		{"Heat", 1995, "heat-1995"},
+		{"The Lord of the Rings: The Fellowship of the Ring", 2001, "the-lord-of-the-rings-the-fellowship-of-the-ring-2001"},
+		{"Amélie", 2001, "amelie-2001"},
+		{"  Se7en!! ", 1995, "se7en-1995"},
+		{"WALL·E", 2008, "wall-e-2008"},
+		{"千と千尋の神隠し", 2001, "movie-2001"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Slug(tt.title, tt.year), tt.title)
+	}
+
+	long := Slug(strings.Repeat("word ", 40), 2020)
+	assert.LessOrEqual(t, len(long), maxSlugTitleLength+len("-2020"))
+	assert.NotContains(t, long, "--")
+}
+
+func TestSlugCandidate(t *testing.T) {
+	assert.Equal(t, "heat-1995", SlugCandidate("heat-1995", 1))
+	assert.Equal(t, "heat-1995-3", SlugCandidate("heat-1995", 3))
+
+	assert.True(t, IsSlugCandidate("heat-1995", "heat-1995"))
+	assert.True(t, IsSlugCandidate("heat-1995-12", "heat-1995"))
+	assert.False(t, IsSlugCandidate("heat-1995-1", "heat-1995"))
+	assert.False(t, IsSlugCandidate("heat-1995-02", "heat-1995"))
+	assert.False(t, IsSlugCandidate("heat-2-1995", "heat-1995"))
+	assert.False(t, IsSlugCandidate("heat-1995-extended", "heat-1995"))
+}
@@ -0,0 +1,140 @@
+package movies
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+// localePattern accepts an ISO 639 language with an optional ISO 3166 or UN M.49 region
+var localePattern = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+// Translation is a localized title and description for a movie. The movie
+// itself always keeps its canonical title and description.
+type Translation struct {
+	MovieID     MovieID   `db:"movie_id"`
+	Locale      string    `db:"locale"`
+	Title       string    `db:"title"`
+	Description string    `db:"description"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+type TranslationRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+func NewTranslation(movieID MovieID, locale, title, description string, timeProvider shared.TimeProvider) (*Translation, error) {
+	normalized, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &Translation{
+		MovieID:     movieID,
+		Locale:      normalized,
+		Title:       strings.TrimSpace(title),
+		Description: strings.TrimSpace(description),
+		CreatedAt:   timeProvider.Now(),
+		UpdatedAt:   timeProvider.Now(),
+	}
+
+	if translation.MovieID == "" {
+		return nil, ErrEmptyMovieID
+	}
+	if translation.Title == "" {
+		return nil, ErrEmptyTitle
+	}
+
+	return translation, nil
+}
+
+// NormalizeLocale returns the canonical form of a locale tag: a lowercase
+// language and an uppercase region, so "PT_br" becomes "pt-BR"
+func NormalizeLocale(locale string) (string, error) {
+	parts := localePattern.FindStringSubmatch(strings.TrimSpace(locale))
+	if parts == nil {
+		return "", ErrInvalidLocale
+	}
+	if parts[2] == "" {
+		return strings.ToLower(parts[1]), nil
+	}
+	return strings.ToLower(parts[1]) + "-" + strings.ToUpper(parts[2]), nil
+}
+
+// LocaleFallbacks expands preferred locales, most preferred first, with their
+// base language: [pt-BR, en] becomes [pt-BR, pt, en]. Invalid tags are skipped.
+func LocaleFallbacks(locales []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			result = append(result, locale)
+		}
+	}
+
+	for _, locale := range locales {
+		normalized, err := NormalizeLocale(locale)
+		if err != nil {
+			continue
+		}
+		add(normalized)
+		if base, _, found := strings.Cut(normalized, "-"); found {
+			add(base)
+		}
+	}
+
+	return result
+}
+
+// languageCodes maps the language names movies are stored with to their
+// ISO 639-1 code
+var languageCodes = map[string]string{
+	"arabic":     "ar",
+	"chinese":    "zh",
+	"danish":     "da",
+	"dutch":      "nl",
+	"english":    "en",
+	"finnish":    "fi",
+	"french":     "fr",
+	"german":     "de",
+	"greek":      "el",
+	"hindi":      "hi",
+	"italian":    "it",
+	"japanese":   "ja",
+	"korean":     "ko",
+	"mandarin":   "zh",
+	"norwegian":  "no",
+	"persian":    "fa",
+	"polish":     "pl",
+	"portuguese": "pt",
+	"russian":    "ru",
+	"spanish":    "es",
+	"swedish":    "sv",
+	"turkish":    "tr",
+}
+
+// LanguageCode returns the locale of a movie's language, which is stored
+// either as a locale tag ("en", "pt-BR") or as its English name
+// ("English"). It is empty for languages it does not know.
+func LanguageCode(language string) string {
+	if locale, err := NormalizeLocale(language); err == nil {
+		return locale
+	}
+	return languageCodes[strings.ToLower(strings.TrimSpace(language))]
+}
+
+// TranslationRepository stores localized titles and descriptions for movies
+type TranslationRepository interface {
+	// SaveTranslation creates or replaces the translation for its movie and locale
+	SaveTranslation(ctx context.Context, translation *Translation) (*Translation, error)
+	GetTranslation(ctx context.Context, movieID MovieID, locale string) (*Translation, error)
+	GetTranslations(ctx context.Context, movieID MovieID) ([]*Translation, error)
+	DeleteTranslation(ctx context.Context, movieID MovieID, locale string) error
+	// FindTranslations returns every translation of the given movies in any of the given locales
+	FindTranslations(ctx context.Context, movieIDs []MovieID, locales []string) ([]*Translation, error)
+}
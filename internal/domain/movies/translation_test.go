@@ -0,0 +1,68 @@
+package movies
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedTimeProvider struct{ now time.Time }
+
+func (f fixedTimeProvider) Now() time.Time { return f.now }
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"de", "de", false},
+		{"PT_br", "pt-BR", false},
+		{" en-us ", "en-US", false},
+		{"es-419", "es-419", false},
+		{"fil", "fil", false},
+		{"english", "", true},
+		{"en-", "", true},
+		{"*", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeLocale(tt.in)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidLocale, tt.in)
+			continue
+		}
+		assert.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	assert.Equal(t, []string{"pt-BR", "pt", "en-GB", "en"}, LocaleFallbacks([]string{"pt-BR", "en-GB", "pt", "invalid locale"}))
+	assert.Empty(t, LocaleFallbacks(nil))
+}
+
+func TestLanguageCode(t *testing.T) {
+	assert.Equal(t, "en", LanguageCode("English"))
+	assert.Equal(t, "de", LanguageCode(" german "))
+	assert.Equal(t, "pt-BR", LanguageCode("pt_br"))
+	assert.Empty(t, LanguageCode("Klingon"))
+	assert.Empty(t, LanguageCode(""))
+}
+
+func TestNewTranslation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	translation, err := NewTranslation("movie-1", "de_de", " Titel ", "", fixedTimeProvider{now})
+	assert.NoError(t, err)
+	assert.Equal(t, "de-DE", translation.Locale)
+	assert.Equal(t, "Titel", translation.Title)
+	assert.Equal(t, now, translation.CreatedAt)
+
+	_, err = NewTranslation("movie-1", "de", "  ", "", fixedTimeProvider{now})
+	assert.ErrorIs(t, err, ErrEmptyTitle)
+
+	_, err = NewTranslation("", "de", "Titel", "", fixedTimeProvider{now})
+	assert.ErrorIs(t, err, ErrEmptyMovieID)
+}
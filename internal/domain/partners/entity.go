@@ -0,0 +1,134 @@
+package partners
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KeyPrefix starts every partner API key, so leaked keys are easy to spot
+const KeyPrefix = "pk_"
+
+const (
+	keyBytes = 32
+	// displayedPrefixLength is how much of a key is kept in the clear to
+	// tell keys apart in listings: the prefix and a few random characters
+	displayedPrefixLength = len(KeyPrefix) + 6
+	maxNameLength         = 100
+)
+
+// Scope grants an API key access to one part of the partner API
+type Scope string
+
+const (
+	// ScopeScoresRead allows reading aggregate movie scores
+	ScopeScoresRead Scope = "scores:read"
+)
+
+// Scopes lists every scope a key can be granted
+var Scopes = []Scope{ScopeScoresRead}
+
+var (
+	ErrKeyNotFound  = errors.New("API key not found")
+	ErrEmptyName    = errors.New("name cannot be empty")
+	ErrNameTooLong  = fmt.Errorf("name must be at most %d characters", maxNameLength)
+	ErrNoScopes     = errors.New("grant at least one scope")
+	ErrUnknownScope = errors.New("unknown scope")
+	ErrRateLimit    = errors.New("rate_limit cannot be negative")
+)
+
+type KeyID string
+
+// APIKey lets a partner call the partner API. Only a hash of the key is
+// stored; the key itself is shown once, when it is created.
+type APIKey struct {
+	ID     KeyID   `json:"id"`
+	Name   string  `json:"name"`
+	Prefix string  `json:"prefix"`
+	Hash   string  `json:"-"`
+	Scopes []Scope `json:"scopes"`
+	// RateLimit caps requests per minute; 0 leaves the key unlimited
+	RateLimit  int        `json:"rate_limit"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKey validates the key's settings and generates it. The returned
+// secret is the key to hand to the partner.
+func NewAPIKey(id KeyID, name string, scopes []Scope, rateLimit int, now time.Time) (key *APIKey, secret string, err error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return nil, "", ErrEmptyName
+	case len(name) > maxNameLength:
+		return nil, "", ErrNameTooLong
+	case len(scopes) == 0:
+		return nil, "", ErrNoScopes
+	case rateLimit < 0:
+		return nil, "", ErrRateLimit
+	}
+
+	seen := make(map[Scope]bool, len(scopes))
+	granted := make([]Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return nil, "", fmt.Errorf("%w: %s", ErrUnknownScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			granted = append(granted, scope)
+		}
+	}
+
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic("partners: failed to read random bytes: " + err.Error())
+	}
+	secret = KeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return &APIKey{
+		ID:        id,
+		Name:      name,
+		Prefix:    secret[:displayedPrefixLength],
+		Hash:      HashKey(secret),
+		Scopes:    granted,
+		RateLimit: rateLimit,
+		CreatedAt: now,
+	}, secret, nil
+}
+
+// HashKey returns the hash a key is looked up by
+func HashKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s Scope) Valid() bool {
+	for _, scope := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the key can still be used
+func (k *APIKey) Active() bool {
+	return k.RevokedAt == nil
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope Scope) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package partners
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	key, secret, err := NewAPIKey("key-1", "  Acme Streaming ", []Scope{ScopeScoresRead, ScopeScoresRead}, 60, now)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, KeyPrefix))
+	assert.Equal(t, "Acme Streaming", key.Name)
+	assert.Equal(t, secret[:len(key.Prefix)], key.Prefix)
+	assert.Equal(t, HashKey(secret), key.Hash)
+	assert.NotContains(t, key.Hash, secret)
+	assert.Equal(t, []Scope{ScopeScoresRead}, key.Scopes, "repeated scopes are granted once")
+	assert.True(t, key.HasScope(ScopeScoresRead))
+	assert.True(t, key.Active())
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []Scope
+		rateLimit int
+		want      error
+	}{
+		{"blank name", " ", []Scope{ScopeScoresRead}, 0, ErrEmptyName},
+		{"long name", strings.Repeat("a", 101), []Scope{ScopeScoresRead}, 0, ErrNameTooLong},
+		{"no scopes", "Acme", nil, 0, ErrNoScopes},
+		{"unknown scope", "Acme", []Scope{"ratings:write"}, 0, ErrUnknownScope},
+		{"negative rate limit", "Acme", []Scope{ScopeScoresRead}, -1, ErrRateLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := NewAPIKey("key-1", tt.keyName, tt.scopes, tt.rateLimit, now)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package partners
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	CreateKey(ctx context.Context, key *APIKey) error
+	// FindKeyByHash returns ErrKeyNotFound for unknown keys; revoked keys
+	// are returned so callers can tell them apart
+	FindKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	// ListKeys returns every key, newest first
+	ListKeys(ctx context.Context) ([]*APIKey, error)
+	// RevokeKey returns ErrKeyNotFound when there is no such active key
+	RevokeKey(ctx context.Context, id KeyID, at time.Time) error
+
+	// RecordUsage counts a request of the key to endpoint on the day of at
+	// and marks the key used
+	RecordUsage(ctx context.Context, id KeyID, endpoint string, at time.Time) error
+	// GetUsage returns the counts matching filter by day, key and endpoint
+	GetUsage(ctx context.Context, filter UsageFilter) ([]*Usage, error)
+}
@@ -0,0 +1,20 @@
+package partners
+
+import "time"
+
+// Usage counts the requests a key made to one endpoint on one day (UTC).
+// It is what partners are billed by.
+type Usage struct {
+	KeyID    KeyID     `json:"key_id"`
+	Day      time.Time `json:"day"`
+	Endpoint string    `json:"endpoint"`
+	Requests int64     `json:"requests"`
+}
+
+// UsageFilter narrows a usage report to days in [From, To] and, when
+// KeyID is set, to one key
+type UsageFilter struct {
+	From  time.Time
+	To    time.Time
+	KeyID KeyID
+}
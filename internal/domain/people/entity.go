@@ -0,0 +1,99 @@
+package people
+
+import (
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/shared"
+	"time"
+)
+
+type PersonID string
+
+type Person struct {
+	ID        PersonID  `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Role is what a person did on a movie
+type Role string
+
+const (
+	RoleDirector Role = "director"
+	RoleActor    Role = "actor"
+	RoleWriter   Role = "writer"
+	RoleComposer Role = "composer"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleDirector, RoleActor, RoleWriter, RoleComposer:
+		return true
+	}
+	return false
+}
+
+// Credit links a person to a movie in one role. A person can hold several
+// roles on the same movie (e.g. director and writer).
+type Credit struct {
+	MovieID    movies.MovieID `db:"movie_id"`
+	PersonID   PersonID       `db:"person_id"`
+	PersonName string         `db:"person_name"` // populated on reads
+	Role       Role           `db:"role"`
+	Character  string         `db:"character_name"` // actors only
+	// BillingOrder ranks credits within a role, lowest first
+	BillingOrder int       `db:"billing_order"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// CreditedMovie is a movie a person worked on, with the role they held
+type CreditedMovie struct {
+	Movie     *movies.Movie
+	Role      Role
+	Character string
+}
+
+func NewPerson(name string, idGenerator shared.IDGenerator, timeProvider shared.TimeProvider) (*Person, error) {
+	person := &Person{
+		ID:        PersonID(idGenerator.Generate()),
+		Name:      strings.TrimSpace(name),
+		CreatedAt: timeProvider.Now(),
+		UpdatedAt: timeProvider.Now(),
+	}
+
+	if person.Name == "" {
+		return nil, ErrEmptyName
+	}
+
+	return person, nil
+}
+
+func NewCredit(movieID movies.MovieID, personID PersonID, role Role, character string, billingOrder int, timeProvider shared.TimeProvider) (*Credit, error) {
+	credit := &Credit{
+		MovieID:      movieID,
+		PersonID:     personID,
+		Role:         Role(strings.ToLower(strings.TrimSpace(string(role)))),
+		Character:    strings.TrimSpace(character),
+		BillingOrder: billingOrder,
+		CreatedAt:    timeProvider.Now(),
+	}
+
+	if credit.MovieID == "" {
+		return nil, ErrEmptyMovieID
+	}
+	if credit.PersonID == "" {
+		return nil, ErrEmptyPersonID
+	}
+	if !credit.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	if credit.Character != "" && credit.Role != RoleActor {
+		return nil, ErrCharacterNotActor
+	}
+	if credit.BillingOrder < 0 {
+		return nil, ErrInvalidBillingOrder
+	}
+
+	return credit, nil
+}
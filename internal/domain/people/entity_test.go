@@ -0,0 +1,61 @@
+package people
+
+import (
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIDGenerator struct{}
+
+func (m *mockIDGenerator) Generate() string { return "mock-id" }
+
+type mockTimeProvider struct{ now time.Time }
+
+func (m *mockTimeProvider) Now() time.Time { return m.now }
+
+func TestNewPerson(t *testing.T) {
+	tp := &mockTimeProvider{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	person, err := NewPerson("  Greta Gerwig ", &mockIDGenerator{}, tp)
+	assert.NoError(t, err)
+	assert.Equal(t, PersonID("mock-id"), person.ID)
+	assert.Equal(t, "Greta Gerwig", person.Name)
+
+	_, err = NewPerson(" ", &mockIDGenerator{}, tp)
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestNewCredit(t *testing.T) {
+	tp := &mockTimeProvider{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	credit, err := NewCredit("movie-1", "person-1", Role(" Actor "), " Jo March ", 1, tp)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleActor, credit.Role)
+	assert.Equal(t, "Jo March", credit.Character)
+
+	tests := []struct {
+		name      string
+		movieID   string
+		personID  string
+		role      Role
+		character string
+		billing   int
+		want      error
+	}{
+		{"missing movie", "", "person-1", RoleActor, "", 0, ErrEmptyMovieID},
+		{"missing person", "movie-1", "", RoleActor, "", 0, ErrEmptyPersonID},
+		{"unknown role", "movie-1", "person-1", "producer", "", 0, ErrInvalidRole},
+		{"character on a director", "movie-1", "person-1", RoleDirector, "Jo", 0, ErrCharacterNotActor},
+		{"negative billing", "movie-1", "person-1", RoleWriter, "", -1, ErrInvalidBillingOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCredit(movies.MovieID(tt.movieID), PersonID(tt.personID), tt.role, tt.character, tt.billing, tp)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package people
+
+import "errors"
+
+var (
+	ErrEmptyName           = errors.New("name cannot be empty")
+	ErrEmptyMovieID        = errors.New("movie ID cannot be empty")
+	ErrEmptyPersonID       = errors.New("person ID cannot be empty")
+	ErrInvalidRole         = errors.New("role must be one of director, actor, writer, composer")
+	ErrCharacterNotActor   = errors.New("character can only be set for actor credits")
+	ErrInvalidBillingOrder = errors.New("billing order must be non-negative")
+)
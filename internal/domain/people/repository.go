@@ -0,0 +1,22 @@
+package people
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+)
+
+type Repository interface {
+	Save(ctx context.Context, person *Person) (*Person, error)
+	GetByID(ctx context.Context, id PersonID) (*Person, error)
+	// GetByName returns the earliest created person with this name, compared
+	// case-insensitively
+	GetByName(ctx context.Context, name string) (*Person, error)
+
+	// SaveCredit creates the credit or updates its character and billing order
+	SaveCredit(ctx context.Context, credit *Credit) (*Credit, error)
+	DeleteCredit(ctx context.Context, movieID movies.MovieID, personID PersonID, role Role) error
+	GetMovieCredits(ctx context.Context, movieID movies.MovieID) ([]*Credit, error)
+	// GetPersonMovies returns the person's credited movies, newest first, and
+	// the total number of credits. An empty role matches every role.
+	GetPersonMovies(ctx context.Context, personID PersonID, role Role, options ...movies.SearchOption) ([]*CreditedMovie, int64, error)
+}
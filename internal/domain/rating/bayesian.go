@@ -0,0 +1,16 @@
+package rating
+
+// DefaultBayesianConfidenceK is how many votes at the global average every
+// movie starts with in its Bayesian average
+const DefaultBayesianConfidenceK = 25.0
+
+// BayesianAverage is a movie's average of votes pulled towards the global
+// average by confidenceK votes at it, (C*m + R*v) / (C + v), so that movies
+// with few ratings do not outrank well-rated ones by chance. With no votes it
+// is the global average.
+func BayesianAverage(average, votes, globalAverage, confidenceK float64) float64 {
+	if votes <= 0 {
+		return globalAverage
+	}
+	return (confidenceK*globalAverage + average*votes) / (confidenceK + votes)
+}
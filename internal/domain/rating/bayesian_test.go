@@ -0,0 +1,14 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBayesianAverage(t *testing.T) {
+	assert.Equal(t, 3.2, BayesianAverage(4, 0, 3.2, DefaultBayesianConfidenceK), "no votes is the global average")
+	assert.InDelta(t, 3.5, BayesianAverage(4, 25, 3, 25), 1e-9, "as many votes as k weigh the same as the prior")
+	assert.Equal(t, 5.0, BayesianAverage(5, 3, 3, 0), "without a prior it is the movie's average")
+	assert.Less(t, BayesianAverage(5, 2, 3, 25), BayesianAverage(4.5, 500, 3, 25), "a few perfect votes do not outrank many good ones")
+}
@@ -0,0 +1,73 @@
+package rating
+
+import (
+	"context"
+	"math"
+	"thermondo/internal/domain/movies"
+)
+
+// ComparedMovie is one side of a MovieComparison: the movie's visible
+// ratings
+type ComparedMovie struct {
+	MovieID      movies.MovieID
+	Title        string
+	AverageScore float64
+	TotalRatings int64
+	ScoreCount   map[int]int64 // Score (1-5) -> Count
+}
+
+// HeadToHead compares the scores of the users who rated both movies of a
+// pair
+type HeadToHead struct {
+	SharedRaters int64 `json:"shared_raters"`
+	PreferFirst  int64 `json:"prefer_first"` // Shared raters who scored the first movie higher
+	PreferSecond int64 `json:"prefer_second"`
+	Ties         int64 `json:"ties"`
+	// AverageDifference is the mean of the first movie's score minus the
+	// second's among shared raters
+	AverageDifference float64 `json:"average_difference"`
+}
+
+// Reversed returns the head-to-head with the two movies swapped
+func (h HeadToHead) Reversed() HeadToHead {
+	return HeadToHead{
+		SharedRaters:      h.SharedRaters,
+		PreferFirst:       h.PreferSecond,
+		PreferSecond:      h.PreferFirst,
+		Ties:              h.Ties,
+		AverageDifference: -h.AverageDifference,
+	}
+}
+
+// PreferFirstPercent is the share of shared raters who scored the first
+// movie higher, as a whole percentage; 0 without shared raters
+func (h HeadToHead) PreferFirstPercent() int {
+	return percentOf(h.PreferFirst, h.SharedRaters)
+}
+
+// PreferSecondPercent is PreferFirstPercent for the second movie
+func (h HeadToHead) PreferSecondPercent() int {
+	return percentOf(h.PreferSecond, h.SharedRaters)
+}
+
+func percentOf(part, total int64) int {
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(float64(part) / float64(total) * 100))
+}
+
+// MovieComparison puts the ratings of two movies side by side
+type MovieComparison struct {
+	First  *ComparedMovie
+	Second *ComparedMovie
+}
+
+type ComparisonRepository interface {
+	// CompareMovies returns the visible ratings of both movies. It returns
+	// an error containing "not found" when either movie does not exist.
+	CompareMovies(ctx context.Context, first, second movies.MovieID) (*MovieComparison, error)
+	// GetHeadToHead pairs up the visible ratings of the users who rated both
+	// movies. Movies that do not exist have no shared raters.
+	GetHeadToHead(ctx context.Context, first, second movies.MovieID) (*HeadToHead, error)
+}
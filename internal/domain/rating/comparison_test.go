@@ -0,0 +1,18 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadToHead(t *testing.T) {
+	h := HeadToHead{SharedRaters: 3, PreferFirst: 2, Ties: 1, AverageDifference: 1.33}
+
+	assert.Equal(t, 67, h.PreferFirstPercent())
+	assert.Equal(t, 0, h.PreferSecondPercent())
+	assert.Equal(t, HeadToHead{SharedRaters: 3, PreferSecond: 2, Ties: 1, AverageDifference: -1.33}, h.Reversed())
+	assert.Equal(t, h, h.Reversed().Reversed())
+
+	assert.Equal(t, 0, HeadToHead{}.PreferFirstPercent(), "no shared raters")
+}
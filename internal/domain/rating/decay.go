@@ -0,0 +1,35 @@
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultGenreHalfLife is how long it takes by default for a rating to count
+// half as much towards a user's recent taste
+const DefaultGenreHalfLife = 90 * 24 * time.Hour
+
+// DecayWeight is what a rating of age counts for when its weight halves
+// every halfLife: 1 for a new rating, 0.5 after one half-life, 0.25 after
+// two. A halfLife of 0 or less, or a rating from the future, weighs 1.
+func DecayWeight(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// FavoriteGenre returns the genre with the highest weight, the
+// alphabetically first on a tie, or "" when weights is empty
+func FavoriteGenre[N int64 | float64](weights map[string]N) string {
+	var (
+		favorite string
+		best     N
+	)
+	for genre, weight := range weights {
+		if favorite == "" || weight > best || (weight == best && genre < favorite) {
+			favorite, best = genre, weight
+		}
+	}
+	return favorite
+}
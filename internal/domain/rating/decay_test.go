@@ -0,0 +1,24 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecayWeight(t *testing.T) {
+	day := 24 * time.Hour
+
+	assert.Equal(t, 1.0, DecayWeight(0, 30*day))
+	assert.InDelta(t, 0.5, DecayWeight(30*day, 30*day), 0.0001)
+	assert.InDelta(t, 0.25, DecayWeight(60*day, 30*day), 0.0001)
+	assert.Equal(t, 1.0, DecayWeight(-day, 30*day), "ratings from the future count fully")
+	assert.Equal(t, 1.0, DecayWeight(365*day, 0), "no decay without a half-life")
+}
+
+func TestFavoriteGenre(t *testing.T) {
+	assert.Equal(t, "Crime", FavoriteGenre(map[string]int64{"Horror": 2, "Crime": 3}))
+	assert.Equal(t, "Crime", FavoriteGenre(map[string]float64{"Horror": 1.5, "Crime": 1.5}), "alphabetically first on a tie")
+	assert.Empty(t, FavoriteGenre(map[string]float64{}))
+}
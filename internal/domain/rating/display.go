@@ -0,0 +1,23 @@
+package rating
+
+// NotEnoughRatings is shown instead of the averages of withheld stats
+const NotEnoughRatings = "Not enough ratings"
+
+// Withhold hides the averages of stats with fewer than minRatings ratings,
+// which say too little about a movie to be shown: the average, median and
+// standard deviation overall and of audience and critics, and the
+// trust-weighted average. Counts and histograms stay. It reports whether
+// the stats were withheld; a minRatings of 0 never withholds.
+func (s *MovieRatingStats) Withhold(minRatings int64) bool {
+	if s.TotalRatings >= minRatings {
+		return false
+	}
+	s.Withheld = true
+	s.AverageScore = 0
+	s.StandardDeviation = 0
+	s.MedianScore = 0
+	s.Audience.AverageScore = 0
+	s.Critics.AverageScore = 0
+	s.Trust = nil
+	return true
+}
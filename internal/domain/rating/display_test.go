@@ -0,0 +1,38 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovieRatingStats_Withhold(t *testing.T) {
+	stats := func() *MovieRatingStats {
+		return &MovieRatingStats{
+			AverageScore:      4.5,
+			TotalRatings:      4,
+			ScoreCount:        map[int]int64{4: 2, 5: 2},
+			StandardDeviation: 0.5,
+			MedianScore:       4.5,
+			Controversy:       0.018,
+			Audience:          ScoreSummary{AverageScore: 4.5, TotalRatings: 4},
+			Trust:             &TrustWeighting{WeightedAverage: 4.4, EffectiveRatings: 3.2},
+		}
+	}
+
+	shown := stats()
+	assert.False(t, shown.Withhold(4))
+	assert.False(t, shown.Withhold(0))
+	assert.Equal(t, stats(), shown)
+
+	withheld := stats()
+	assert.True(t, withheld.Withhold(5))
+	assert.True(t, withheld.Withheld)
+	assert.Zero(t, withheld.AverageScore)
+	assert.Zero(t, withheld.MedianScore)
+	assert.Zero(t, withheld.StandardDeviation)
+	assert.Equal(t, ScoreSummary{TotalRatings: 4}, withheld.Audience)
+	assert.Nil(t, withheld.Trust)
+	assert.Equal(t, int64(4), withheld.TotalRatings, "counts stay")
+	assert.Equal(t, map[int]int64{4: 2, 5: 2}, withheld.ScoreCount)
+}
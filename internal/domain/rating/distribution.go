@@ -0,0 +1,100 @@
+package rating
+
+import "math"
+
+const (
+	// DistributionPrior is how many pseudo-ratings every score starts with
+	// in a smoothed distribution
+	DistributionPrior = 1.0
+	// ControversyPriorVotes is how many agreeing votes every movie's
+	// controversy starts with, so a couple of opposite ratings don't make a
+	// movie the most divisive
+	ControversyPriorVotes = 10.0
+	// MaxScoreVariance is the variance of ratings split evenly between 1
+	// and 5 stars, the most divided they can be
+	MaxScoreVariance = 4.0
+)
+
+// SmoothDistribution returns the share of each score from 1 to 5 with a
+// symmetric Dirichlet prior of prior pseudo-ratings per score, so a few
+// ratings don't make a spiky histogram: (count + prior) / (total + 5 *
+// prior). The shares add up to 1, and are even without ratings.
+func SmoothDistribution(counts map[int]int64, prior float64) map[int]float64 {
+	var total int64
+	for score := 1; score <= 5; score++ {
+		total += counts[score]
+	}
+	shares := make(map[int]float64, 5)
+	for score := 1; score <= 5; score++ {
+		shares[score] = (float64(counts[score]) + prior) / (float64(total) + 5*prior)
+	}
+	return shares
+}
+
+// MedianScore returns the median of the counted scores, the mean of the
+// two middle ones for an even number of ratings; 0 without ratings
+func MedianScore(counts map[int]int64) float64 {
+	var total int64
+	for score := 1; score <= 5; score++ {
+		total += counts[score]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	// The scores at the 1-based positions lower and upper, which are the
+	// same for an odd number of ratings
+	lower, upper := (total+1)/2, total/2+1
+	var seen int64
+	var low float64
+	for score := 1; score <= 5; score++ {
+		seen += counts[score]
+		if low == 0 && seen >= lower {
+			low = float64(score)
+		}
+		if seen >= upper {
+			return (low + float64(score)) / 2
+		}
+	}
+	return low
+}
+
+// ScoreVariance returns the population variance of the counted scores
+func ScoreVariance(counts map[int]int64) float64 {
+	var total, sum, squares float64
+	for score := 1; score <= 5; score++ {
+		n := float64(counts[score])
+		total += n
+		sum += n * float64(score)
+		squares += n * float64(score*score)
+	}
+	if total == 0 {
+		return 0
+	}
+	mean := sum / total
+	return squares/total - mean*mean
+}
+
+// Controversy rates how divided a movie's ratings are, from 0 when everyone
+// agrees to 1 when they split evenly between 1 and 5 stars: the variance of
+// the scores over MaxScoreVariance, weighted by ratings / (ratings +
+// ControversyPriorVotes). It is rounded to three decimals.
+func Controversy(variance float64, ratings int64) float64 {
+	if ratings <= 0 {
+		return 0
+	}
+	n := float64(ratings)
+	controversy := math.Max(variance, 0) / MaxScoreVariance * n / (n + ControversyPriorVotes)
+	return math.Round(controversy*1000) / 1000
+}
+
+// Describe sets the standard deviation of variance, rounded to two
+// decimals like the averages, the controversy, and the median and smoothed
+// distribution of ScoreCount
+func (s *MovieRatingStats) Describe(variance float64) {
+	// Rounding can leave the variance of equal scores just below zero
+	s.StandardDeviation = math.Round(math.Sqrt(math.Max(variance, 0))*100) / 100
+	s.Controversy = Controversy(variance, s.TotalRatings)
+	s.MedianScore = MedianScore(s.ScoreCount)
+	s.SmoothedDistribution = SmoothDistribution(s.ScoreCount, DistributionPrior)
+}
@@ -0,0 +1,56 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoothDistribution(t *testing.T) {
+	// Three five-star ratings no longer make a single spike
+	shares := SmoothDistribution(map[int]int64{5: 3}, DistributionPrior)
+	assert.Equal(t, map[int]float64{1: 1.0 / 8, 2: 1.0 / 8, 3: 1.0 / 8, 4: 1.0 / 8, 5: 4.0 / 8}, shares)
+
+	assert.Equal(t, 0.2, SmoothDistribution(nil, DistributionPrior)[3], "even without ratings")
+
+	// Many ratings outweigh the prior
+	assert.InDelta(t, 0.8, SmoothDistribution(map[int]int64{4: 8000, 2: 2000}, DistributionPrior)[4], 0.001)
+}
+
+func TestMedianScore(t *testing.T) {
+	assert.Equal(t, 0.0, MedianScore(nil))
+	assert.Equal(t, 4.0, MedianScore(map[int]int64{2: 1, 4: 1, 5: 1}))
+	assert.Equal(t, 3.0, MedianScore(map[int]int64{2: 1, 4: 1}), "the mean of the middle two")
+	assert.Equal(t, 1.0, MedianScore(map[int]int64{1: 3, 5: 2}))
+	assert.Equal(t, 4.5, MedianScore(map[int]int64{1: 1, 4: 2, 5: 3}))
+}
+
+func TestControversy(t *testing.T) {
+	assert.Equal(t, 0.0, Controversy(0, 0))
+	assert.Equal(t, 0.0, Controversy(0, 500), "everyone agrees")
+
+	// The same split is more controversial with more ratings
+	split := ScoreVariance(map[int]int64{1: 1, 5: 1})
+	assert.Equal(t, 4.0, split)
+	assert.Equal(t, 0.167, Controversy(split, 2))
+	assert.Equal(t, 0.99, Controversy(split, 1000))
+
+	// And a love-or-hate movie is more controversial than a lukewarm one
+	polarized := ScoreVariance(map[int]int64{1: 40, 5: 60})
+	lukewarm := ScoreVariance(map[int]int64{2: 40, 3: 30, 4: 30})
+	assert.Greater(t, Controversy(polarized, 100), Controversy(lukewarm, 100))
+}
+
+func TestMovieRatingStats_Describe(t *testing.T) {
+	stats := &MovieRatingStats{TotalRatings: 4, ScoreCount: map[int]int64{1: 2, 5: 2}}
+	stats.Describe(ScoreVariance(stats.ScoreCount))
+
+	assert.Equal(t, 2.0, stats.StandardDeviation)
+	assert.Equal(t, 3.0, stats.MedianScore)
+	assert.InDelta(t, 3.0/9, stats.SmoothedDistribution[1], 1e-9)
+	assert.Equal(t, 0.286, stats.Controversy, "4 / 4 * 4 / 14")
+
+	stats = &MovieRatingStats{ScoreCount: map[int]int64{4: 3}}
+	stats.Describe(-1e-12)
+	assert.Equal(t, 0.0, stats.StandardDeviation, "equal scores")
+}
@@ -2,31 +2,73 @@ package rating
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/shared"
 	"thermondo/internal/domain/users"
 	"time"
+	"unicode/utf8"
 )
 
 type RatingID string
 
 type Rating struct {
-	ID        RatingID       `db:"id"`
-	UserID    users.UserID   `db:"user_id"`
-	MovieID   movies.MovieID `db:"movie_id"`
-	Score     int            `db:"score"`
-	Review    string         `db:"review"`
-	CreatedAt time.Time      `db:"created_at"`
-	UpdatedAt time.Time      `db:"updated_at"`
+	ID               RatingID       `db:"id"`
+	UserID           users.UserID   `db:"user_id"`
+	MovieID          movies.MovieID `db:"movie_id"`
+	Score            int            `db:"score"`
+	Review           string         `db:"review"`            // Markdown as written; rendered on request
+	ContainsSpoilers bool           `db:"contains_spoilers"` // Review gives away the plot
+	// ContainsAdultLanguage hides the rating from review listings in kids mode
+	ContainsAdultLanguage bool      `db:"contains_adult_language"`
+	CreatedAt             time.Time `db:"created_at"`
+	UpdatedAt             time.Time `db:"updated_at"`
+	Version               int       `db:"version"` // incremented on every update; see ErrVersionConflict
 }
 
 var (
 	ErrInvalidScore = errors.New("score must be between 1 and 5")
 	ErrEmptyUserID  = errors.New("user ID cannot be empty")
 	ErrEmptyMovieID = errors.New("movie ID cannot be empty")
+	// ErrVersionConflict is returned by Repository.Update when the rating was
+	// modified after it was read
+	ErrVersionConflict = errors.New("rating was modified concurrently")
+	ErrReviewTooShort  = errors.New("review is too short")
+	ErrReviewTooLong   = errors.New("review is too long")
+	// ErrNotDeleted and ErrRestoreExpired are returned by Repository.Restore
+	// for a rating that is not deleted, and for one deleted too long ago
+	ErrNotDeleted     = errors.New("rating is not deleted")
+	ErrRestoreExpired = errors.New("rating was deleted too long ago to be restored")
 )
 
+// ReviewLimits bounds the length of a review in characters. A zero bound is
+// not enforced, and an empty review is always allowed since reviews are
+// optional.
+type ReviewLimits struct {
+	MinLength int
+	MaxLength int
+}
+
+// DefaultReviewLimits keeps reviews to what fits on a page
+func DefaultReviewLimits() ReviewLimits {
+	return ReviewLimits{MaxLength: 5000}
+}
+
+// Check validates the length of a trimmed review
+func (l ReviewLimits) Check(review string) error {
+	length := utf8.RuneCountInString(review)
+	switch {
+	case length == 0:
+		return nil
+	case l.MinLength > 0 && length < l.MinLength:
+		return fmt.Errorf("%w: at least %d characters required", ErrReviewTooShort, l.MinLength)
+	case l.MaxLength > 0 && length > l.MaxLength:
+		return fmt.Errorf("%w: at most %d characters allowed", ErrReviewTooLong, l.MaxLength)
+	}
+	return nil
+}
+
 func NewRating(
 	userID users.UserID,
 	movieID movies.MovieID,
@@ -43,6 +85,7 @@ func NewRating(
 		Review:    strings.TrimSpace(review),
 		CreatedAt: timeProvider.Now(),
 		UpdatedAt: timeProvider.Now(),
+		Version:   1,
 	}
 
 	if err := rating.Validate(); err != nil {
@@ -83,3 +126,13 @@ func (r *Rating) UpdateReview(review string, timeProvider shared.TimeProvider) e
 	r.UpdatedAt = timeProvider.Now()
 	return nil
 }
+
+func (r *Rating) MarkSpoilers(containsSpoilers bool, timeProvider shared.TimeProvider) {
+	r.ContainsSpoilers = containsSpoilers
+	r.UpdatedAt = timeProvider.Now()
+}
+
+func (r *Rating) MarkAdultLanguage(containsAdultLanguage bool, timeProvider shared.TimeProvider) {
+	r.ContainsAdultLanguage = containsAdultLanguage
+	r.UpdatedAt = timeProvider.Now()
+}
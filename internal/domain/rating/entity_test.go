@@ -32,6 +32,7 @@ func TestNewRating_Success(t *testing.T) {
 	assert.Equal(t, "Great!", r.Review)
 	assert.Equal(t, timeNow, r.CreatedAt)
 	assert.Equal(t, timeNow, r.UpdatedAt)
+	assert.Equal(t, 1, r.Version)
 }
 
 func TestNewRating_ValidationErrors(t *testing.T) {
@@ -174,3 +175,25 @@ func TestNewRating_LongReview(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, string(longReview), r.Review)
 }
+
+func TestReviewLimits_Check(t *testing.T) {
+	limits := ReviewLimits{MinLength: 5, MaxLength: 10}
+
+	assert.NoError(t, limits.Check(""))
+	assert.NoError(t, limits.Check("Great"))
+	assert.NoError(t, limits.Check("Großartig!"))
+	assert.ErrorIs(t, limits.Check("Meh"), ErrReviewTooShort)
+	assert.ErrorIs(t, limits.Check("Far too long"), ErrReviewTooLong)
+
+	assert.NoError(t, ReviewLimits{}.Check("Anything goes"))
+}
+
+func TestRating_MarkSpoilers(t *testing.T) {
+	timeNow := time.Now()
+	timeProv := &mockTimeProvider{now: timeNow}
+	r := &Rating{}
+
+	r.MarkSpoilers(true, timeProv)
+	assert.True(t, r.ContainsSpoilers)
+	assert.Equal(t, timeNow, r.UpdatedAt)
+}
@@ -0,0 +1,22 @@
+package rating
+
+import "context"
+
+// ImportStatus is what happened to one rating of an import batch
+type ImportStatus string
+
+const (
+	ImportInserted     ImportStatus = "imported"
+	ImportDuplicate    ImportStatus = "duplicate" // The user had already rated the movie
+	ImportUnknownUser  ImportStatus = "unknown_user"
+	ImportUnknownMovie ImportStatus = "unknown_movie"
+)
+
+// Importer writes historical ratings, e.g. from a legacy system, keeping
+// their original timestamps
+type Importer interface {
+	// ImportBatch inserts the ratings whose user and movie exist, skipping
+	// those the user has already rated, and returns a status per rating in
+	// the order given
+	ImportBatch(ctx context.Context, ratings []*Rating) ([]ImportStatus, error)
+}
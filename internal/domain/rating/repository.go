@@ -2,15 +2,69 @@ package rating
 
 import (
 	"context"
+	"errors"
+	"math"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/users"
+	"time"
 )
 
 type SearchOptions struct {
 	Limit  int
 	Offset int
-	SortBy string // "created_at", "score"
-	Order  string // "asc", "desc"
+	// SortBy is "created_at", "updated_at" or "score", or several with
+	// directions, e.g. "score:desc,created_at:asc"; joined queries also
+	// accept "title" and "release_year"
+	SortBy string
+	Order  string // "asc", "desc"; the direction of fields SortBy gives none
+	// Reviewer keeps only ratings by critics or by the audience; empty keeps all
+	Reviewer ReviewerType
+	// Spoilers hides or keeps only reviews flagged as spoilers; empty keeps all
+	Spoilers SpoilerFilter
+	// HideAdultLanguage leaves out reviews flagged as containing adult language
+	HideAdultLanguage bool
+	// ReviewsOnly leaves out ratings without a review
+	ReviewsOnly bool
+}
+
+// ReviewerType tells verified critics apart from the regular audience
+type ReviewerType string
+
+const (
+	ReviewerCritic   ReviewerType = "critic"
+	ReviewerAudience ReviewerType = "audience"
+)
+
+var ErrInvalidReviewerType = errors.New("reviewer must be 'critic' or 'audience'")
+
+// ParseReviewerType accepts "critic", "audience" or "" for everyone
+func ParseReviewerType(s string) (ReviewerType, error) {
+	switch t := ReviewerType(s); t {
+	case "", ReviewerCritic, ReviewerAudience:
+		return t, nil
+	default:
+		return "", ErrInvalidReviewerType
+	}
+}
+
+// SpoilerFilter selects ratings by their spoiler flag
+type SpoilerFilter string
+
+const (
+	SpoilersHide SpoilerFilter = "hide"
+	SpoilersOnly SpoilerFilter = "only"
+)
+
+var ErrInvalidSpoilerFilter = errors.New("spoilers must be 'hide' or 'only'")
+
+// ParseSpoilerFilter accepts "hide", "only" or "" for every rating
+func ParseSpoilerFilter(s string) (SpoilerFilter, error) {
+	switch f := SpoilerFilter(s); f {
+	case "", SpoilersHide, SpoilersOnly:
+		return f, nil
+	default:
+		return "", ErrInvalidSpoilerFilter
+	}
 }
 
 func DefaultSearchOptions() SearchOptions {
@@ -43,19 +97,145 @@ func WithSort(sortBy, order string) SearchOption {
 	}
 }
 
+func WithReviewer(reviewer ReviewerType) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.Reviewer = reviewer
+	}
+}
+
+func WithSpoilers(spoilers SpoilerFilter) SearchOption {
+	return func(opts *SearchOptions) {
+		opts.Spoilers = spoilers
+	}
+}
+
+// WithoutAdultLanguage leaves out reviews flagged as containing adult
+// language, for kids mode
+func WithoutAdultLanguage() SearchOption {
+	return func(opts *SearchOptions) {
+		opts.HideAdultLanguage = true
+	}
+}
+
+// WithReviewsOnly leaves out ratings without a review
+func WithReviewsOnly() SearchOption {
+	return func(opts *SearchOptions) {
+		opts.ReviewsOnly = true
+	}
+}
+
+// UserRatingFilter narrows a user's ratings by score and by attributes of the
+// rated movie. Empty/nil fields mean "no filter"; ranges are inclusive.
+type UserRatingFilter struct {
+	Genre    string
+	MinScore *int
+	MaxScore *int
+	YearFrom *int
+	YearTo   *int
+	// RatedFrom and RatedTo bound when the rating was created, [from, to)
+	RatedFrom *time.Time
+	RatedTo   *time.Time
+}
+
+// ReviewFilter narrows a review search. Empty/nil fields mean "no filter";
+// ranges are inclusive and dates apply to when the rating was created.
+type ReviewFilter struct {
+	MovieID  movies.MovieID
+	MinScore *int
+	MaxScore *int
+	From     *time.Time
+	To       *time.Time
+}
+
+// ReviewMatch is a rating whose review matched a full-text search
+type ReviewMatch struct {
+	Rating *Rating
+	// Snippet is the HTML-escaped part of the review around the matches,
+	// with every match wrapped in <mark>
+	Snippet string
+	Rank    float64
+}
+
+// RatingWithMovie is a rating joined with the rated movie and that movie's
+// aggregate rating numbers
+type RatingWithMovie struct {
+	Rating            *Rating
+	Movie             *movies.Movie
+	MovieAverage      float64
+	MovieTotalRatings int64
+}
+
 type Repository interface {
 	Save(ctx context.Context, rating *Rating) (*Rating, error)
 	GetByID(ctx context.Context, id RatingID) (*Rating, error)
+	// GetByIDIncludingDeleted reads the rating whether or not it is
+	// soft-deleted
+	GetByIDIncludingDeleted(ctx context.Context, id RatingID) (*Rating, error)
 	GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*Rating, error)
 	GetByUser(ctx context.Context, userID users.UserID, options ...SearchOption) ([]*Rating, error)
 	GetByMovie(ctx context.Context, movieID movies.MovieID, options ...SearchOption) ([]*Rating, error)
+	// GetUserRatingsWithMovies joins the user's ratings with movies so sorting
+	// (including by title) and filtering happen in SQL across all pages. It
+	// returns the page and the total number of matching ratings.
+	GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter UserRatingFilter, options ...SearchOption) ([]*RatingWithMovie, int64, error)
 	Update(ctx context.Context, rating *Rating) (*Rating, error)
-	Delete(ctx context.Context, id RatingID) error
+	// Delete soft-deletes the rating: from deletedAt it is left out of every
+	// read, listing and aggregate until it is restored or purged
+	Delete(ctx context.Context, id RatingID, deletedAt time.Time) error
+	// Restore undeletes a rating deleted at or after deletedSince
+	Restore(ctx context.Context, id RatingID, deletedSince time.Time) (*Rating, error)
 	GetMovieStats(ctx context.Context, movieID movies.MovieID) (*MovieRatingStats, error)
 	Exists(ctx context.Context, id RatingID) (bool, error)
 	Count(ctx context.Context) (int64, error)
+	// MostRatedMovies lists the movies with the most visible ratings, most
+	// rated first
+	MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error)
+	// MostActiveRaters lists the users with the most ratings, most first
+	MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error)
+
+	// GetGlobalStats reads the running totals of visible ratings, which the
+	// database keeps current on every write
+	GetGlobalStats(ctx context.Context) (*GlobalStats, error)
+	// RefreshGlobalStats recomputes the totals from the ratings, correcting
+	// any drift, and marks them refreshed at now
+	RefreshGlobalStats(ctx context.Context, now time.Time) (*GlobalStats, error)
+	// GetRatingActivity counts the movie's ratings per score created since
+	// windowStart and between baselineStart and windowStart
+	GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*RatingActivity, error)
+	// GetRaterGroups groups the movie's ratings by score and rater trust
+	// factors as of asOf. Account ages and rating counts are capped at the
+	// point where config grants full trust so the groups stay few.
+	GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*RaterGroup, error)
+	// SearchReviews runs a full-text search over review text, best matches
+	// first. Only the limit and offset of options apply. It returns the page
+	// and the total number of matches.
+	SearchReviews(ctx context.Context, query string, filter ReviewFilter, options ...SearchOption) ([]*ReviewMatch, int64, error)
+}
 
-	GetGlobalAverageRating(ctx context.Context) (float64, error)
+// ScoreSummary is the average and number of a group of ratings
+type ScoreSummary struct {
+	AverageScore float64 `json:"average_score"`
+	TotalRatings int64   `json:"total_ratings"`
+}
+
+// GlobalStats are the totals of all visible ratings that the global average
+// is computed from
+type GlobalStats struct {
+	ScoreSum     int64
+	TotalRatings int64
+	// RefreshedAt is when the totals were last recomputed from scratch
+	RefreshedAt time.Time
+	// UpdatedAt is when a rating write last changed them
+	UpdatedAt time.Time
+}
+
+// Average is the mean score rounded to two decimals; ok is false without
+// ratings
+func (s GlobalStats) Average() (average float64, ok bool) {
+	if s.TotalRatings == 0 {
+		return 0, false
+	}
+	return math.Round(float64(s.ScoreSum)/float64(s.TotalRatings)*100) / 100, true
 }
 
 type MovieRatingStats struct {
@@ -63,4 +243,23 @@ type MovieRatingStats struct {
 	AverageScore float64        `json:"average_score"`
 	TotalRatings int64          `json:"total_ratings"`
 	ScoreCount   map[int]int64  `json:"score_count"` // Score (1-5) -> Count
+	// StandardDeviation and MedianScore describe how the scores spread
+	StandardDeviation float64 `json:"standard_deviation"`
+	MedianScore       float64 `json:"median_score"`
+	// Controversy is how divided the ratings are, from 0 to 1
+	Controversy float64 `json:"controversy"`
+	// Withheld is set when the averages are hidden because the movie has
+	// too few ratings
+	Withheld bool `json:"withheld,omitempty"`
+	// SmoothedDistribution is each score's share of the ratings, smoothed
+	// with DistributionPrior pseudo-ratings per score
+	SmoothedDistribution map[int]float64 `json:"smoothed_distribution"`
+	// Audience and Critics split the totals by whether the rater is a
+	// verified critic
+	Audience ScoreSummary `json:"audience_score"`
+	Critics  ScoreSummary `json:"critic_score"`
+	// Volatility is set when review bombing detection is enabled
+	Volatility *Volatility `json:"volatility,omitempty"`
+	// Trust is set when ratings are weighted by rater trust
+	Trust *TrustWeighting `json:"trust_weighting,omitempty"`
 }
@@ -0,0 +1,38 @@
+package rating
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"time"
+)
+
+// StatsHistoryDateLayout is the format of snapshot dates
+const StatsHistoryDateLayout = "2006-01-02"
+
+// StatsSnapshot is a movie's rating numbers on the day it was taken, kept
+// to chart how its reception changes over the years
+type StatsSnapshot struct {
+	MovieID         movies.MovieID `db:"movie_id"`
+	Date            time.Time      `db:"snapshot_date"`
+	AverageScore    float64        `db:"average_score"`
+	BayesianAverage float64        `db:"bayesian_average"`
+	TotalRatings    int64          `db:"total_ratings"`
+	// Withheld is set when the averages are hidden because the movie had
+	// too few ratings that day
+	Withheld bool `db:"-"`
+}
+
+type StatsHistoryRepository interface {
+	// SnapshotStats writes the snapshot of date for every movie with visible
+	// ratings, replacing one taken earlier for the same date, and returns
+	// how many it wrote. The Bayesian average weighs globalAverage as
+	// confidenceK votes.
+	SnapshotStats(ctx context.Context, date time.Time, globalAverage, confidenceK float64) (int64, error)
+	// GetStatsHistory returns the movie's snapshots between from and to,
+	// inclusive, oldest first
+	GetStatsHistory(ctx context.Context, movieID movies.MovieID, from, to time.Time) ([]*StatsSnapshot, error)
+	// DownsampleStatsHistory keeps only the latest snapshot of every movie
+	// per week for snapshots dated before before, and returns how many it
+	// removed
+	DownsampleStatsHistory(ctx context.Context, before time.Time) (int64, error)
+}
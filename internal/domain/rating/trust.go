@@ -0,0 +1,40 @@
+package rating
+
+import "thermondo/internal/domain/users"
+
+// RaterGroup counts a movie's ratings with one score by raters sharing the
+// same trust factors
+type RaterGroup struct {
+	Score   int
+	Factors users.TrustFactors
+	Ratings int64
+}
+
+// TrustWeighting is a movie's average with every rating weighted by its
+// rater's trust score
+type TrustWeighting struct {
+	WeightedAverage float64 `json:"weighted_average"`
+	// EffectiveRatings is the sum of the weights, i.e. how many fully
+	// trusted ratings the movie's ratings are worth
+	EffectiveRatings float64 `json:"effective_ratings"`
+	// ScoreWeight is the summed weight per score
+	ScoreWeight map[int]float64 `json:"-"`
+}
+
+// WeighRatings applies config to groups
+func WeighRatings(groups []*RaterGroup, config users.TrustConfig) *TrustWeighting {
+	weighting := &TrustWeighting{ScoreWeight: make(map[int]float64)}
+
+	var sum float64
+	for _, group := range groups {
+		weight := config.Score(group.Factors) * float64(group.Ratings)
+		weighting.ScoreWeight[group.Score] += weight
+		weighting.EffectiveRatings += weight
+		sum += weight * float64(group.Score)
+	}
+	if weighting.EffectiveRatings > 0 {
+		weighting.WeightedAverage = sum / weighting.EffectiveRatings
+	}
+
+	return weighting
+}
@@ -0,0 +1,35 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeighRatings(t *testing.T) {
+	config := users.DefaultTrustConfig()
+	veteran := users.TrustFactors{AccountAge: 365 * 24 * time.Hour, EmailVerified: true, RatingCount: 100}
+	newcomer := users.TrustFactors{}
+
+	t.Run("new accounts count less", func(t *testing.T) {
+		weighting := WeighRatings([]*RaterGroup{
+			{Score: 5, Factors: veteran, Ratings: 2},
+			{Score: 1, Factors: newcomer, Ratings: 10},
+		}, config)
+
+		// 2 ratings worth 1 and 10 worth 0.1: (2*5 + 1*1) / 3
+		assert.InDelta(t, 3.0, weighting.EffectiveRatings, 0.0001)
+		assert.InDelta(t, 11.0/3.0, weighting.WeightedAverage, 0.0001)
+		assert.InDelta(t, 1.0, weighting.ScoreWeight[1], 0.0001)
+	})
+
+	t.Run("no ratings", func(t *testing.T) {
+		weighting := WeighRatings(nil, config)
+
+		assert.Equal(t, 0.0, weighting.EffectiveRatings)
+		assert.Equal(t, 0.0, weighting.WeightedAverage)
+	})
+}
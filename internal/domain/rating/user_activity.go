@@ -0,0 +1,50 @@
+package rating
+
+import (
+	"context"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+// MonthlyCount is how many ratings a user gave in a calendar month
+type MonthlyCount struct {
+	Month string `json:"month" db:"month"` // YYYY-MM, in UTC
+	Count int64  `json:"count" db:"count"`
+}
+
+// Streak is a run of consecutive UTC days on each of which the user rated
+// at least one movie
+type Streak struct {
+	Days  int       `json:"days"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// DecadeCount is how many movies of a release decade a user rated
+type DecadeCount struct {
+	Decade int   `json:"decade" db:"decade"` // e.g. 1990
+	Count  int64 `json:"count" db:"count"`
+}
+
+// UserActivity aggregates the ratings a user gave in a period
+type UserActivity struct {
+	TotalRatings int64   `json:"total_ratings"`
+	AverageScore float64 `json:"average_score"`
+	// PerMonth leaves out months without ratings, oldest first
+	PerMonth []MonthlyCount `json:"per_month"`
+	// LongestStreak is the latest of the longest streaks; nil without
+	// ratings
+	LongestStreak *Streak `json:"longest_streak,omitempty"`
+	// Decades are ordered by count, most rated first
+	Decades     []DecadeCount `json:"decades"`
+	TopGenre    string        `json:"top_genre"`
+	TopDirector string        `json:"top_director"`
+}
+
+// UserActivityRepository runs the aggregate queries behind user statistics
+// and the year in review
+type UserActivityRepository interface {
+	// GetUserActivity aggregates the user's ratings created in [from, to); a
+	// zero from or to leaves that end of the period open
+	GetUserActivity(ctx context.Context, userID users.UserID, from, to time.Time) (*UserActivity, error)
+}
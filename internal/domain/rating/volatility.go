@@ -0,0 +1,127 @@
+package rating
+
+import "time"
+
+const (
+	VolatilityNegative = "negative" // spike of 1-star ratings
+	VolatilityPositive = "positive" // spike of 5-star ratings
+)
+
+// VolatilityConfig tunes review bombing detection. A movie is flagged when
+// the number of 1-star (or 5-star) ratings created within Window is at least
+// MinSpikeRatings and at least SpikeFactor times what the preceding Baseline
+// period predicts for a window of that length.
+type VolatilityConfig struct {
+	Window          time.Duration
+	Baseline        time.Duration
+	MinSpikeRatings int64
+	SpikeFactor     float64
+	// ExcludeFlagged drops the spiking ratings of a flagged window from the
+	// Bayesian average
+	ExcludeFlagged bool
+}
+
+func DefaultVolatilityConfig() VolatilityConfig {
+	return VolatilityConfig{
+		Window:          24 * time.Hour,
+		Baseline:        30 * 24 * time.Hour,
+		MinSpikeRatings: 10,
+		SpikeFactor:     5,
+	}
+}
+
+// RatingActivity counts a movie's ratings per score in the detection window
+// and in the baseline period right before it
+type RatingActivity struct {
+	Window   map[int]int64
+	Baseline map[int]int64
+}
+
+// Volatility is the outcome of review bombing detection for a movie. It is
+// informational only: ratings are never rejected because of it.
+type Volatility struct {
+	Flagged     bool      `json:"flagged"`
+	Direction   string    `json:"direction,omitempty"`
+	Score       int       `json:"score,omitempty"`
+	WindowStart time.Time `json:"window_start"`
+	// SpikeRatings is how many ratings of Score were created in the window,
+	// ExpectedRatings how many the baseline predicts
+	SpikeRatings    int64   `json:"spike_ratings,omitempty"`
+	ExpectedRatings float64 `json:"expected_ratings,omitempty"`
+	// Excluded reports whether the spike was left out of the Bayesian average
+	Excluded bool `json:"excluded"`
+}
+
+// DetectVolatility checks activity for a spike of extreme ratings. When both
+// 1-star and 5-star ratings spike, the stronger one wins.
+func DetectVolatility(activity RatingActivity, windowStart time.Time, config VolatilityConfig) *Volatility {
+	result := &Volatility{WindowStart: windowStart}
+	if config.Window <= 0 || config.Baseline <= 0 {
+		return result
+	}
+
+	scale := float64(config.Window) / float64(config.Baseline)
+	bestRatio := 0.0
+	for _, candidate := range []struct {
+		score     int
+		direction string
+	}{{1, VolatilityNegative}, {5, VolatilityPositive}} {
+		observed := activity.Window[candidate.score]
+		expected := float64(activity.Baseline[candidate.score]) * scale
+		if observed < config.MinSpikeRatings {
+			continue
+		}
+
+		// A quiet baseline would make any activity look like a spike, so
+		// expect at least one rating per window
+		ratio := float64(observed) / max(expected, 1)
+		if ratio < config.SpikeFactor || ratio <= bestRatio {
+			continue
+		}
+
+		bestRatio = ratio
+		result.Flagged = true
+		result.Direction = candidate.direction
+		result.Score = candidate.score
+		result.SpikeRatings = observed
+		result.ExpectedRatings = expected
+	}
+
+	return result
+}
+
+// Votes returns the average and number of votes the Bayesian average is
+// computed from: the trust-weighted ones when Trust is set, the plain ones
+// otherwise
+func (stats *MovieRatingStats) Votes() (float64, float64) {
+	if stats.Trust != nil {
+		return stats.Trust.WeightedAverage, stats.Trust.EffectiveRatings
+	}
+	return stats.AverageScore, float64(stats.TotalRatings)
+}
+
+// WithoutSpike returns Votes with the spiking ratings of v removed. Every
+// spiking rating has the same score, so this is exact without reloading the
+// ratings. With trust weighting each spiking rating is removed at the mean
+// weight of its score.
+func (stats *MovieRatingStats) WithoutSpike(v *Volatility) (float64, float64) {
+	average, votes := stats.Votes()
+	if v == nil || !v.Flagged {
+		return average, votes
+	}
+
+	spike := float64(v.SpikeRatings)
+	if stats.Trust != nil {
+		if count := stats.ScoreCount[v.Score]; count > 0 {
+			spike *= stats.Trust.ScoreWeight[v.Score] / float64(count)
+		}
+	}
+
+	remaining := votes - spike
+	if remaining <= 0 {
+		return 0, 0
+	}
+
+	sum := average*votes - float64(v.Score)*spike
+	return sum / remaining, remaining
+}
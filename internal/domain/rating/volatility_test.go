@@ -0,0 +1,105 @@
+package rating
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectVolatility(t *testing.T) {
+	windowStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := DefaultVolatilityConfig()
+
+	tests := []struct {
+		name      string
+		activity  RatingActivity
+		flagged   bool
+		direction string
+	}{
+		{
+			name:     "quiet movie",
+			activity: RatingActivity{Window: map[int]int64{1: 2, 4: 3}, Baseline: map[int]int64{1: 30, 4: 90}},
+		},
+		{
+			name:      "one-star spike",
+			activity:  RatingActivity{Window: map[int]int64{1: 40, 4: 2}, Baseline: map[int]int64{1: 30, 4: 90}},
+			flagged:   true,
+			direction: VolatilityNegative,
+		},
+		{
+			name:      "five-star spike on a movie without history",
+			activity:  RatingActivity{Window: map[int]int64{5: 12}, Baseline: map[int]int64{}},
+			flagged:   true,
+			direction: VolatilityPositive,
+		},
+		{
+			name:     "below the minimum number of ratings",
+			activity: RatingActivity{Window: map[int]int64{1: 9}, Baseline: map[int]int64{}},
+		},
+		{
+			name:     "steady high volume is not a spike",
+			activity: RatingActivity{Window: map[int]int64{1: 50}, Baseline: map[int]int64{1: 1500}},
+		},
+		{
+			name:      "stronger spike wins",
+			activity:  RatingActivity{Window: map[int]int64{1: 20, 5: 60}, Baseline: map[int]int64{}},
+			flagged:   true,
+			direction: VolatilityPositive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := DetectVolatility(tt.activity, windowStart, config)
+
+			assert.Equal(t, tt.flagged, v.Flagged)
+			assert.Equal(t, tt.direction, v.Direction)
+			assert.Equal(t, windowStart, v.WindowStart)
+		})
+	}
+}
+
+func TestMovieRatingStatsWithoutSpike(t *testing.T) {
+	stats := &MovieRatingStats{AverageScore: 3.0, TotalRatings: 30}
+
+	t.Run("removes the spiking ratings", func(t *testing.T) {
+		average, total := stats.WithoutSpike(&Volatility{Flagged: true, Score: 1, SpikeRatings: 10})
+
+		assert.Equal(t, 20.0, total)
+		assert.InDelta(t, 4.0, average, 0.0001)
+	})
+
+	t.Run("keeps stats that are not flagged", func(t *testing.T) {
+		average, total := stats.WithoutSpike(&Volatility{})
+
+		assert.Equal(t, 30.0, total)
+		assert.Equal(t, 3.0, average)
+	})
+
+	t.Run("handles a movie made only of the spike", func(t *testing.T) {
+		average, total := stats.WithoutSpike(&Volatility{Flagged: true, Score: 1, SpikeRatings: 30})
+
+		assert.Equal(t, 0.0, total)
+		assert.Equal(t, 0.0, average)
+	})
+}
+
+func TestMovieRatingStatsWithoutSpikeWeighted(t *testing.T) {
+	// 10 one-star ratings worth 0.1 each, 20 four-star ratings worth 1 each
+	stats := &MovieRatingStats{
+		AverageScore: 3.0,
+		TotalRatings: 30,
+		ScoreCount:   map[int]int64{1: 10, 4: 20},
+		Trust: &TrustWeighting{
+			WeightedAverage:  81.0 / 21.0,
+			EffectiveRatings: 21,
+			ScoreWeight:      map[int]float64{1: 1, 4: 20},
+		},
+	}
+
+	average, votes := stats.WithoutSpike(&Volatility{Flagged: true, Score: 1, SpikeRatings: 10})
+
+	assert.InDelta(t, 20.0, votes, 0.0001)
+	assert.InDelta(t, 4.0, average, 0.0001)
+}
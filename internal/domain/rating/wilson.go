@@ -0,0 +1,21 @@
+package rating
+
+import "math"
+
+// WilsonZ is the z-score of the Wilson interval, for 95% confidence
+const WilsonZ = 1.96
+
+// WilsonLowerBound is the lower bound of the Wilson score interval of a
+// movie's ratings: the score the movie has at least, with 95% confidence.
+// Ratings are taken as the share of the 1-5 scale they reach, (score-1)/4,
+// and the bound is put back on the scale, so it ranges from 1 to 5 and
+// grows towards the average as votes add up. With no votes it is 1.
+func WilsonLowerBound(average, votes float64) float64 {
+	if votes <= 0 {
+		return 1
+	}
+	p := math.Min(math.Max((average-1)/4, 0), 1)
+	z2 := WilsonZ * WilsonZ
+	bound := (p + z2/(2*votes) - WilsonZ*math.Sqrt(p*(1-p)/votes+z2/(4*votes*votes))) / (1 + z2/votes)
+	return 1 + 4*bound
+}
@@ -0,0 +1,24 @@
+package rating
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilsonLowerBound(t *testing.T) {
+	assert.Equal(t, 1.0, WilsonLowerBound(0, 0), "no votes")
+	assert.InDelta(t, 1.0, WilsonLowerBound(1, 50), 1e-9, "all one-star")
+
+	// Four of five stars from 100 votes: p = 0.75, bound 0.657
+	assert.InDelta(t, 1+4*0.6569, WilsonLowerBound(4, 100), 0.001)
+
+	// More votes at the same average mean a tighter bound
+	few, many := WilsonLowerBound(4.5, 5), WilsonLowerBound(4.5, 500)
+	assert.Less(t, few, many)
+	assert.Less(t, many, 4.5)
+
+	// A near-perfect average from a handful of votes ranks below a slightly
+	// lower one from many, unlike the plain average
+	assert.Less(t, WilsonLowerBound(5, 3), WilsonLowerBound(4.6, 200))
+}
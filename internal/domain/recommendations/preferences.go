@@ -0,0 +1,75 @@
+package recommendations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+const (
+	MaxPreferredGenres   = 10
+	maxGenreLength       = 100
+	firstPreferredDecade = movies.FirstMovieYear / 10 * 10
+)
+
+var (
+	ErrNoPreferences = errors.New("pick at least one genre or decade")
+	ErrEmptyGenre    = errors.New("genres cannot be empty")
+	ErrTooManyGenres = fmt.Errorf("pick at most %d genres", MaxPreferredGenres)
+	ErrGenreTooLong  = fmt.Errorf("genres must be at most %d characters", maxGenreLength)
+)
+
+// Preferences are the genres and decades a user picked during onboarding.
+// They stand in for ratings until the user has rated enough movies.
+type Preferences struct {
+	UserID    users.UserID `json:"user_id"`
+	Genres    []string     `json:"genres"`
+	Decades   []int        `json:"decades"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// NewPreferences validates the picks, dropping repeated genres (compared
+// ignoring case) and decades. Decades are named by their first year, such
+// as 1990, and can't be later than the one now falls in.
+func NewPreferences(userID users.UserID, genres []string, decades []int, now time.Time) (*Preferences, error) {
+	preferences := &Preferences{UserID: userID, Genres: []string{}, Decades: []int{}, UpdatedAt: now}
+
+	seenGenres := make(map[string]bool)
+	for _, genre := range genres {
+		genre = strings.TrimSpace(genre)
+		if genre == "" {
+			return nil, ErrEmptyGenre
+		}
+		if len(genre) > maxGenreLength {
+			return nil, ErrGenreTooLong
+		}
+		if key := strings.ToLower(genre); !seenGenres[key] {
+			seenGenres[key] = true
+			preferences.Genres = append(preferences.Genres, genre)
+		}
+	}
+	if len(preferences.Genres) > MaxPreferredGenres {
+		return nil, ErrTooManyGenres
+	}
+
+	seenDecades := make(map[int]bool)
+	for _, decade := range decades {
+		if decade%10 != 0 || decade < firstPreferredDecade || decade > now.Year() {
+			return nil, fmt.Errorf("decade %d must be the first year of a decade between %d and %d", decade, firstPreferredDecade, now.Year()/10*10)
+		}
+		if !seenDecades[decade] {
+			seenDecades[decade] = true
+			preferences.Decades = append(preferences.Decades, decade)
+		}
+	}
+	sort.Ints(preferences.Decades)
+
+	if len(preferences.Genres) == 0 && len(preferences.Decades) == 0 {
+		return nil, ErrNoPreferences
+	}
+	return preferences, nil
+}
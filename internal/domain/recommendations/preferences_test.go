@@ -0,0 +1,43 @@
+package recommendations
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPreferences(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	preferences, err := NewPreferences("user-1", []string{" Sci-Fi ", "Horror", "sci-fi"}, []int{2020, 1970, 2020}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sci-Fi", "Horror"}, preferences.Genres)
+	assert.Equal(t, []int{1970, 2020}, preferences.Decades)
+	assert.Equal(t, now, preferences.UpdatedAt)
+
+	preferences, err = NewPreferences("user-1", nil, []int{1880}, now)
+	require.NoError(t, err)
+	assert.Empty(t, preferences.Genres)
+	assert.NotNil(t, preferences.Genres)
+
+	_, err = NewPreferences("user-1", nil, nil, now)
+	assert.ErrorIs(t, err, ErrNoPreferences)
+
+	_, err = NewPreferences("user-1", []string{" "}, nil, now)
+	assert.ErrorIs(t, err, ErrEmptyGenre)
+
+	_, err = NewPreferences("user-1", []string{strings.Repeat("a", 101)}, nil, now)
+	assert.ErrorIs(t, err, ErrGenreTooLong)
+
+	_, err = NewPreferences("user-1", strings.Split("a,b,c,d,e,f,g,h,i,j,k", ","), nil, now)
+	assert.ErrorIs(t, err, ErrTooManyGenres)
+
+	for _, decade := range []int{1995, 1870, 2030} {
+		_, err = NewPreferences("user-1", nil, []int{decade}, now)
+		assert.EqualError(t, err, fmt.Sprintf("decade %d must be the first year of a decade between 1880 and 2020", decade))
+	}
+}
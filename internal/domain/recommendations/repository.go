@@ -0,0 +1,48 @@
+package recommendations
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+// Repository finds the movies a user's home shelves are made of and keeps
+// the onboarding preferences they start from. Every query leaves out movies
+// the user has already rated, and ratings by shadow-banned users count for
+// nothing.
+type Repository interface {
+	// GetWatchlist returns the movies on the user's lists they haven't rated
+	// yet, most recently added first
+	GetWatchlist(ctx context.Context, userID users.UserID, limit int) ([]*movies.Movie, error)
+	// GetFavoriteGenre returns the genre the user rated highest on average
+	// among those they rated at least minRatings times, or "" when none has
+	// enough ratings. Each score weighs less the older it is at asOf,
+	// halving every halfLife; a halfLife of 0 weighs them all alike.
+	GetFavoriteGenre(ctx context.Context, userID users.UserID, minRatings int, asOf time.Time, halfLife time.Duration) (string, error)
+	// GetTopInGenre returns the movies of genre with the highest Bayesian
+	// average, weighting the global average by confidenceK
+	GetTopInGenre(ctx context.Context, userID users.UserID, genre string, confidenceK float64, limit int) ([]*movies.Movie, error)
+	// GetRecentFavorite returns the movie the user gave their highest score,
+	// of at least minScore, among the ratings since, and the newest of those
+	// on a tie. It returns nil when there is none.
+	GetRecentFavorite(ctx context.Context, userID users.UserID, since time.Time, minScore int) (*movies.Movie, error)
+	// GetSimilar returns movies sharing the genre or director of movie,
+	// those sharing both first and then the best rated
+	GetSimilar(ctx context.Context, userID users.UserID, movie *movies.Movie, limit int) ([]*movies.Movie, error)
+	// GetTrendingAmongSimilarUsers returns the movies most often rated
+	// minScore or more since by the user's neighbors: the up to neighbors
+	// users who gave scores within a point of the user's on the most movies
+	// they both rated
+	GetTrendingAmongSimilarUsers(ctx context.Context, userID users.UserID, since time.Time, neighbors, minScore, limit int) ([]*movies.Movie, error)
+	// GetPreferred returns the movies of the preferred genres or decades,
+	// those matching both first and then by Bayesian average
+	GetPreferred(ctx context.Context, userID users.UserID, preferences *Preferences, confidenceK float64, limit int) ([]*movies.Movie, error)
+	// CountRatings counts the user's ratings
+	CountRatings(ctx context.Context, userID users.UserID) (int, error)
+
+	// SavePreferences replaces the user's onboarding preferences
+	SavePreferences(ctx context.Context, preferences *Preferences) error
+	// GetPreferences returns nil when the user never picked any
+	GetPreferences(ctx context.Context, userID users.UserID) (*Preferences, error)
+}
@@ -0,0 +1,45 @@
+package retention
+
+import (
+	"context"
+	"path"
+	"time"
+)
+
+// Target is a kind of row that is deleted once its retention period is over
+type Target string
+
+const (
+	// TargetSessions are login sessions, and with them their refresh
+	// tokens, that expired or were revoked
+	TargetSessions Target = "sessions"
+	// TargetMergeAudit are the audit records of movie merges
+	TargetMergeAudit Target = "merge_audit"
+	// TargetJobs are finished background jobs
+	TargetJobs Target = "jobs"
+	// TargetDeletedRatings are soft-deleted ratings
+	TargetDeletedRatings Target = "deleted_ratings"
+)
+
+type Repository interface {
+	// Purge deletes the rows of target that became eligible for deletion
+	// before before, in batches of at most batchSize rows, and returns how
+	// many it deleted. With dryRun it only counts them.
+	Purge(ctx context.Context, target Target, before time.Time, batchSize int, dryRun bool) (int64, error)
+	// MediaReferences returns the stored objects rows still point at
+	MediaReferences(ctx context.Context) (*MediaReferences, error)
+}
+
+// MediaReferences are the storage keys the database points at. Some rows
+// point at a prefix whose objects all belong to them, such as the sizes of
+// an avatar under the user's avatar key.
+type MediaReferences struct {
+	Keys     map[string]bool
+	Prefixes map[string]bool
+}
+
+// Referenced reports whether a row points at key or at the prefix directly
+// above it
+func (r *MediaReferences) Referenced(key string) bool {
+	return r.Keys[key] || r.Prefixes[path.Dir(key)]
+}
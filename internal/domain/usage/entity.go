@@ -0,0 +1,84 @@
+package usage
+
+import (
+	"errors"
+	"time"
+)
+
+// PrincipalKind tells what a metered principal is
+type PrincipalKind string
+
+const (
+	KindUser   PrincipalKind = "user"
+	KindAPIKey PrincipalKind = "api_key"
+)
+
+var ErrUnknownKind = errors.New("kind must be user or api_key")
+
+// ParseKind parses a principal kind; an empty string means any kind
+func ParseKind(value string) (PrincipalKind, error) {
+	switch kind := PrincipalKind(value); kind {
+	case "", KindUser, KindAPIKey:
+		return kind, nil
+	}
+	return "", ErrUnknownKind
+}
+
+// Principal is who a request is metered against: a signed-in user or a
+// partner API key
+type Principal struct {
+	Kind PrincipalKind `json:"kind"`
+	ID   string        `json:"id"`
+}
+
+// Counter holds the requests a principal made to one endpoint on one day
+// (UTC) and the response bytes they were sent
+type Counter struct {
+	Kind        PrincipalKind `json:"kind"`
+	PrincipalID string        `json:"principal_id"`
+	Day         time.Time     `json:"day"`
+	Endpoint    string        `json:"endpoint"`
+	Requests    int64         `json:"requests"`
+	Bytes       int64         `json:"bytes"`
+}
+
+func (c *Counter) Principal() Principal {
+	return Principal{Kind: c.Kind, ID: c.PrincipalID}
+}
+
+// Filter narrows counters to days in [From, To] and, when set, to a kind
+// of principal or a single one
+type Filter struct {
+	From        time.Time
+	To          time.Time
+	Kind        PrincipalKind
+	PrincipalID string
+}
+
+// Quota is a principal's allowance of requests per calendar month (UTC)
+type Quota struct {
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+// Exceeded reports whether the request that brought usage to Used is over
+// the allowance
+func (q *Quota) Exceeded() bool {
+	return q.Used > q.Limit
+}
+
+func (q *Quota) Remaining() int64 {
+	return max(q.Limit-q.Used, 0)
+}
+
+// MonthStart returns the start of t's month in UTC, when quotas reset
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NextMonth returns the start of the month after t's
+func NextMonth(t time.Time) time.Time {
+	return MonthStart(t).AddDate(0, 1, 0)
+}
@@ -0,0 +1,40 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKind(t *testing.T) {
+	for _, value := range []string{"", "user", "api_key"} {
+		kind, err := ParseKind(value)
+		assert.NoError(t, err)
+		assert.Equal(t, PrincipalKind(value), kind)
+	}
+	_, err := ParseKind("device")
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestQuota(t *testing.T) {
+	quota := &Quota{Limit: 100, Used: 100}
+	assert.False(t, quota.Exceeded(), "the last allowed request")
+	assert.Zero(t, quota.Remaining())
+
+	quota.Used = 101
+	assert.True(t, quota.Exceeded())
+	assert.Zero(t, quota.Remaining())
+
+	quota.Used = 40
+	assert.Equal(t, int64(60), quota.Remaining())
+}
+
+func TestMonthBounds(t *testing.T) {
+	berlin := time.FixedZone("CET", 3600)
+	at := time.Date(2024, 12, 1, 0, 30, 0, 0, berlin)
+
+	assert.Equal(t, time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), MonthStart(at), "still November in UTC")
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), NextMonth(at))
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), NextMonth(time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,17 @@
+package usage
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Add adds the counts to those stored for the same principal, day and
+	// endpoint
+	Add(ctx context.Context, counters []*Counter) error
+	// List returns the counters matching filter by day, principal and
+	// endpoint
+	List(ctx context.Context, filter Filter) ([]*Counter, error)
+	// CountRequests sums the requests of the principal on days in [from, to)
+	CountRequests(ctx context.Context, principal Principal, from, to time.Time) (int64, error)
+}
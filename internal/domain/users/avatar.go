@@ -0,0 +1,36 @@
+package users
+
+// AvatarSize names one of the square renditions stored for an avatar
+type AvatarSize string
+
+const (
+	AvatarStandard  AvatarSize = "standard"
+	AvatarThumbnail AvatarSize = "thumbnail"
+)
+
+// AvatarSizes lists every size rendered on upload
+var AvatarSizes = []AvatarSize{AvatarStandard, AvatarThumbnail}
+
+var avatarWidths = map[AvatarSize]int{
+	AvatarStandard:  256,
+	AvatarThumbnail: 64,
+}
+
+// Width is the size's edge length in pixels
+func (s AvatarSize) Width() int {
+	return avatarWidths[s]
+}
+
+func ParseAvatarSize(s string) (AvatarSize, error) {
+	size := AvatarSize(s)
+	if _, ok := avatarWidths[size]; !ok {
+		return "", ErrInvalidAvatarSize
+	}
+	return size, nil
+}
+
+// AvatarObjectKey is the storage key of one size of the avatar uploaded under
+// prefix, the value kept in User.AvatarKey
+func AvatarObjectKey(prefix string, size AvatarSize) string {
+	return prefix + "/" + string(size) + ".jpg"
+}
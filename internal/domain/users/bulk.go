@@ -0,0 +1,59 @@
+package users
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxBulkUsers caps how many users one bulk operation may touch
+const MaxBulkUsers = 500
+
+// BulkAction is an admin operation applied to many users at once
+type BulkAction string
+
+const (
+	BulkDeactivate BulkAction = "deactivate"
+	BulkActivate   BulkAction = "activate"
+	BulkChangeRole BulkAction = "change_role"
+)
+
+var ErrInvalidBulkAction = errors.New("action must be one of: deactivate, activate, change_role")
+
+// ParseBulkAction validates a bulk action name
+func ParseBulkAction(value string) (BulkAction, error) {
+	switch action := BulkAction(value); action {
+	case BulkDeactivate, BulkActivate, BulkChangeRole:
+		return action, nil
+	default:
+		return "", ErrInvalidBulkAction
+	}
+}
+
+// BulkStatus is the outcome of a bulk action for one user
+type BulkStatus string
+
+const (
+	// BulkUpdated means the user was changed
+	BulkUpdated BulkStatus = "updated"
+	// BulkUnchanged means the user was already in the requested state
+	BulkUnchanged BulkStatus = "unchanged"
+	BulkNotFound  BulkStatus = "not_found"
+	// BulkSkipped means the user was deliberately left alone, see Reason
+	BulkSkipped BulkStatus = "skipped"
+)
+
+// BulkUpdate describes one bulk operation. Role is only used by
+// BulkChangeRole.
+type BulkUpdate struct {
+	Action    BulkAction
+	Role      Role
+	UserIDs   []UserID
+	UpdatedAt time.Time
+}
+
+// BulkResult is the per-user outcome of a bulk operation
+type BulkResult struct {
+	UserID UserID     `json:"user_id"`
+	Status BulkStatus `json:"status"`
+	Reason string     `json:"reason,omitempty"`
+}
@@ -0,0 +1,50 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ContentMode decides which movies and reviews a user is shown
+type ContentMode string
+
+const (
+	ContentModeStandard ContentMode = "standard"
+	// ContentModeKids leaves out movies above the configured certification
+	// and reviews flagged as containing adult language
+	ContentModeKids ContentMode = "kids"
+)
+
+var ErrInvalidContentMode = errors.New("content mode must be 'standard' or 'kids'")
+
+// ParseContentMode accepts "standard" or "kids" in any case
+func ParseContentMode(s string) (ContentMode, error) {
+	switch mode := ContentMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ContentModeStandard, ContentModeKids:
+		return mode, nil
+	default:
+		return "", ErrInvalidContentMode
+	}
+}
+
+type contentModeKey struct{}
+
+// WithContentMode returns a context carrying the content mode of a request
+func WithContentMode(ctx context.Context, mode ContentMode) context.Context {
+	return context.WithValue(ctx, contentModeKey{}, mode)
+}
+
+// ContentModeFrom returns the content mode of a request, standard when none
+// was set
+func ContentModeFrom(ctx context.Context) ContentMode {
+	if mode, ok := ctx.Value(contentModeKey{}).(ContentMode); ok {
+		return mode
+	}
+	return ContentModeStandard
+}
+
+// IsKidsMode reports whether the request is restricted to kids content
+func IsKidsMode(ctx context.Context) bool {
+	return ContentModeFrom(ctx) == ContentModeKids
+}
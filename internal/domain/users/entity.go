@@ -28,15 +28,19 @@ type CreateUserRequest struct {
 
 // User represents a user entity
 type User struct {
-	ID        UserID    `json:"id" db:"id"`
-	FirstName string    `json:"first_name" db:"first_name"`
-	LastName  string    `json:"last_name" db:"last_name"`
-	Email     string    `json:"email" db:"email"`
-	Password  string    `json:"password" db:"password"`
-	Role      Role      `json:"role" db:"role"`
-	IsActive  bool      `json:"is_active" db:"is_active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID           UserID      `json:"id" db:"id"`
+	FirstName    string      `json:"first_name" db:"first_name"`
+	LastName     string      `json:"last_name" db:"last_name"`
+	Email        string      `json:"email" db:"email"`
+	Password     string      `json:"password" db:"password"`
+	Role         Role        `json:"role" db:"role"`
+	IsActive     bool        `json:"is_active" db:"is_active"`
+	AvatarKey    *string     `json:"avatar_key,omitempty" db:"avatar_key"` // Storage prefix of the current avatar
+	ShadowBanned bool        `json:"shadow_banned" db:"shadow_banned"`     // Ratings are hidden from everyone but the user
+	IsCritic     bool        `json:"is_critic" db:"is_critic"`             // Verified critic; ratings count towards the critic score
+	ContentMode  ContentMode `json:"content_mode" db:"content_mode"`       // Kids mode holds on every device the user signs in on
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
 }
 
 // NewUser creates a new user entity
@@ -51,15 +55,16 @@ func NewUser(
 ) (*User, error) {
 
 	user := User{
-		ID:        UserID(idGenerator.Generate()),
-		FirstName: strings.TrimSpace(firstName),
-		LastName:  strings.TrimSpace(lastName),
-		Email:     strings.ToLower(strings.TrimSpace(email)),
-		Password:  password,
-		Role:      RoleUser,
-		IsActive:  true,
-		CreatedAt: timeProvider.Now(),
-		UpdatedAt: timeProvider.Now(),
+		ID:          UserID(idGenerator.Generate()),
+		FirstName:   strings.TrimSpace(firstName),
+		LastName:    strings.TrimSpace(lastName),
+		Email:       strings.ToLower(strings.TrimSpace(email)),
+		Password:    password,
+		Role:        RoleUser,
+		IsActive:    true,
+		ContentMode: ContentModeStandard,
+		CreatedAt:   timeProvider.Now(),
+		UpdatedAt:   timeProvider.Now(),
 	}
 
 	// Validate the user before applying options
@@ -80,6 +85,20 @@ func NewUser(
 }
 
 func (u *User) Validate() error {
+	if err := u.ValidateProfile(); err != nil {
+		return err
+	}
+
+	if u.Password == "" {
+		return ErrEmptyPassword
+	}
+
+	return nil
+}
+
+// ValidateProfile checks the user's name and email without requiring the
+// password, for updates of users loaded without their password hash
+func (u *User) ValidateProfile() error {
 	if u.FirstName == "" {
 		return ErrEmptyFirstName
 	}
@@ -92,10 +111,6 @@ func (u *User) Validate() error {
 		return ErrEmptyEmail
 	}
 
-	if u.Password == "" {
-		return ErrEmptyPassword
-	}
-
 	if _, err := mail.ParseAddress(u.Email); err != nil {
 		return ErrInvalidEmail
 	}
@@ -9,4 +9,12 @@ var (
 	ErrEmptyLastName     = errors.New("last name cannot be empty")
 	ErrEmptyPassword     = errors.New("password cannot be empty")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidAvatar     = errors.New("avatar must be a JPEG or PNG image")
+	ErrInvalidAvatarSize = errors.New("avatar size must be standard or thumbnail")
+	ErrNoAvatar          = errors.New("user has no avatar")
+
+	// ErrEmailNotSearchable is returned for email prefix filters and email
+	// sorting while emails are stored encrypted
+	ErrEmailNotSearchable = errors.New("email_prefix and sort_by=email are unavailable while emails are encrypted")
 )
@@ -0,0 +1,37 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityExists   = errors.New("identity is already linked")
+)
+
+// Identity links a user to their account with an OpenID Connect provider,
+// such as Google or Apple, so they can sign in with it instead of a
+// password. The provider knows the user by Subject, which unlike the email
+// never changes. The email is not kept here, so it stays encrypted when
+// user emails are.
+type Identity struct {
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"-" db:"subject"`
+	UserID    UserID    `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type IdentityRepository interface {
+	// Create links the identity; it returns ErrIdentityExists when the
+	// provider's subject is already linked, to this user or another
+	Create(ctx context.Context, identity *Identity) error
+	// Find returns ErrIdentityNotFound for unknown subjects
+	Find(ctx context.Context, provider, subject string) (*Identity, error)
+	// ListByUser returns the user's identities, oldest first
+	ListByUser(ctx context.Context, userID UserID) ([]*Identity, error)
+	// Delete unlinks the user's identities with provider; it returns
+	// ErrIdentityNotFound when the user has none
+	Delete(ctx context.Context, userID UserID, provider string) error
+}
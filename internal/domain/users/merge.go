@@ -0,0 +1,36 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrMergeIntoSelf = errors.New("an account cannot be merged into itself")
+
+// MergeRecord is the audit entry written when a user's second account is
+// merged into the one they keep. The source account is deleted.
+type MergeRecord struct {
+	ID       int64     `json:"id" db:"id"`
+	SourceID UserID    `json:"source_user_id" db:"source_user_id"`
+	TargetID UserID    `json:"target_user_id" db:"target_user_id"`
+	MergedAt time.Time `json:"merged_at" db:"merged_at"`
+	// RatingsMoved counts source ratings reassigned to the target;
+	// RatingsDropped counts the older rating of each movie both rated
+	RatingsMoved   int64 `json:"ratings_moved" db:"ratings_moved"`
+	RatingsDropped int64 `json:"ratings_dropped" db:"ratings_dropped"`
+	// IdentitiesMoved counts the sign-in providers now linked to the target
+	IdentitiesMoved int64 `json:"identities_moved" db:"identities_moved"`
+}
+
+// MergeRepository merges two accounts of the same person
+type MergeRepository interface {
+	// Merge moves everything of source onto target in one transaction:
+	// ratings (keeping the newer rating when both rated a movie), lists,
+	// linked identities and claimed anonymous ratings. The avatar and
+	// onboarding preferences are taken from source when target has none;
+	// target keeps its own name, email, password and role. source is then
+	// deleted, which signs it out everywhere, and the merge is recorded in
+	// the audit table. It returns ErrUserNotFound when either is missing.
+	Merge(ctx context.Context, sourceID, targetID UserID, mergedAt time.Time) (*MergeRecord, error)
+}
@@ -2,12 +2,43 @@ package users
 
 import (
 	"context"
+	"time"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, user *User) (*User, error)
+	// Update stores the user's name, email and active flag; the password and
+	// role are not changed
+	Update(ctx context.Context, user *User) (*User, error)
+	// UpdateAvatar sets or, with a nil key, clears the user's avatar and
+	// returns the key it replaced
+	UpdateAvatar(ctx context.Context, id UserID, avatarKey *string, updatedAt time.Time) (*string, error)
+	// SetShadowBanned flags or unflags the user as shadow-banned
+	SetShadowBanned(ctx context.Context, id UserID, banned bool, updatedAt time.Time) error
+	// SetCritic grants or revokes verified critic status
+	SetCritic(ctx context.Context, id UserID, critic bool, updatedAt time.Time) error
+	// BulkUpdate applies one action to every listed user in a single
+	// transaction and reports the outcome per user, in input order. Unknown
+	// IDs are reported as not found rather than failing the batch.
+	BulkUpdate(ctx context.Context, update BulkUpdate) ([]BulkResult, error)
 	FindByID(ctx context.Context, id UserID) (*User, error)
 	FindByEmail(ctx context.Context, email string) (*User, error)
-	List(ctx context.Context, page, limit int) ([]*User, error)
-	Count(ctx context.Context) (int, error)
+	// List returns a page of users matching filter along with the total
+	// number of matches, computed in the same statement with a window count.
+	// The total is 0 when the page is empty; use Count for pages past the end.
+	List(ctx context.Context, filter ListFilter, page, limit int) ([]*User, int, error)
+	Count(ctx context.Context, filter ListFilter) (int, error)
+}
+
+// ListFilter narrows and orders a user listing. Empty fields mean "no
+// filter"; without SortBy users are listed by ID.
+type ListFilter struct {
+	Role         Role
+	IsActive     *bool
+	CreatedAfter *time.Time
+	EmailPrefix  string
+	// SortBy is "id", "email", "last_name" or "created_at", or several
+	// with directions, e.g. "last_name:asc,created_at:desc"
+	SortBy string
+	Order  string // "asc", "desc"; the direction of fields SortBy gives none
 }
@@ -0,0 +1,53 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+type SessionID string
+
+func (id SessionID) String() string {
+	return string(id)
+}
+
+// Session is one signed-in device. Every access token carries the ID of the
+// session it was issued for, so revoking the session logs the device out.
+type Session struct {
+	ID         SessionID  `json:"id" db:"id"`
+	UserID     UserID     `json:"user_id" db:"user_id"`
+	UserAgent  string     `json:"user_agent" db:"user_agent"`
+	IP         string     `json:"ip" db:"ip"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Active reports whether the session can still authenticate requests
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	// FindByID returns ErrSessionNotFound for unknown IDs; revoked and
+	// expired sessions are returned so callers can tell them apart
+	FindByID(ctx context.Context, id SessionID) (*Session, error)
+	// ListActive returns the user's unrevoked, unexpired sessions, most
+	// recently used first
+	ListActive(ctx context.Context, userID UserID, now time.Time) ([]*Session, error)
+	Touch(ctx context.Context, id SessionID, at time.Time) error
+	// Revoke ends one of the user's active sessions; it returns
+	// ErrSessionNotFound when the user has no such active session
+	Revoke(ctx context.Context, userID UserID, id SessionID, at time.Time) error
+	// RevokeAll ends every active session of the user and returns how many
+	// were ended
+	RevokeAll(ctx context.Context, userID UserID, at time.Time) (int64, error)
+}
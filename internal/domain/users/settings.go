@@ -0,0 +1,113 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"time"
+)
+
+// DefaultLocale is the locale of users who haven't picked one
+const DefaultLocale = "en"
+
+// ErrInvalidSortOrder is returned for a default sort order on a field its
+// listing can't be sorted by, or in a direction other than asc or desc
+var ErrInvalidSortOrder = errors.New("sort order must be a sortable field of its listing, asc or desc")
+
+// The fields each listing with a default sort order can be sorted by
+var (
+	RatingSortFields  = []string{"created_at", "updated_at", "score"}
+	ProfileSortFields = []string{"created_at", "updated_at", "score", "title", "release_year"}
+)
+
+// Settings are the preferences a user sets for how the app behaves for
+// them. The content mode is kept on the user, since requests are filtered
+// by it; the rest is stored as one JSON document, so a setting can be added
+// without a migration and users who never saved it get its default.
+type Settings struct {
+	UserID        UserID               `json:"user_id"`
+	Locale        string               `json:"locale"`
+	ContentMode   ContentMode          `json:"content_mode"`
+	Notifications NotificationSettings `json:"notifications"`
+	SortOrders    SortOrders           `json:"sort_orders"`
+	// UpdatedAt is zero until the user first saves their settings
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationSettings are what the user opted in to be notified about;
+// nothing until they do
+type NotificationSettings struct {
+	// NewReleases is for movies on the user's lists being released
+	NewReleases bool `json:"new_releases"`
+	// Recommendations is for picks based on the user's ratings
+	Recommendations bool `json:"recommendations"`
+	// ProductNews is for announcements of new features
+	ProductNews bool `json:"product_news"`
+}
+
+// SortOrder is how a listing is sorted when the request doesn't say
+type SortOrder struct {
+	SortBy string `json:"sort_by"`
+	Order  string `json:"order"`
+}
+
+// SortOrders are the user's default sort order of each listing: the
+// movie catalog, the ratings of a movie and their own rating history
+type SortOrders struct {
+	Movies  SortOrder `json:"movies"`
+	Ratings SortOrder `json:"ratings"`
+	Profile SortOrder `json:"profile"`
+}
+
+// DefaultSettings are the settings of a user who never saved any
+func DefaultSettings(userID UserID) *Settings {
+	newest := SortOrder{SortBy: "created_at", Order: "desc"}
+	return &Settings{
+		UserID:      userID,
+		Locale:      DefaultLocale,
+		ContentMode: ContentModeStandard,
+		SortOrders:  SortOrders{Movies: newest, Ratings: newest, Profile: newest},
+	}
+}
+
+// Normalize validates the settings, putting the locale, content mode and
+// sort orders in their canonical form. It returns movies.ErrInvalidLocale,
+// ErrInvalidContentMode or ErrInvalidSortOrder.
+func (s *Settings) Normalize() error {
+	locale, err := movies.NormalizeLocale(s.Locale)
+	if err != nil {
+		return err
+	}
+	s.Locale = locale
+
+	if s.ContentMode, err = ParseContentMode(string(s.ContentMode)); err != nil {
+		return err
+	}
+
+	for _, order := range []struct {
+		sort   *SortOrder
+		fields []string
+	}{
+		{&s.SortOrders.Movies, movies.SortFields},
+		{&s.SortOrders.Ratings, RatingSortFields},
+		{&s.SortOrders.Profile, ProfileSortFields},
+	} {
+		order.sort.Order = strings.ToLower(strings.TrimSpace(order.sort.Order))
+		if !slices.Contains(order.fields, order.sort.SortBy) || (order.sort.Order != "asc" && order.sort.Order != "desc") {
+			return ErrInvalidSortOrder
+		}
+	}
+	return nil
+}
+
+// SettingsRepository stores the settings other than the content mode
+type SettingsRepository interface {
+	// Get returns the user's stored settings over the defaults, or nil when
+	// they never saved any. The content mode is left at its default.
+	Get(ctx context.Context, userID UserID) (*Settings, error)
+	// Save stores the settings, returning ErrUserNotFound when the user
+	// doesn't exist
+	Save(ctx context.Context, settings *Settings) error
+}
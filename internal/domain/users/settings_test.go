@@ -0,0 +1,38 @@
+package users
+
+import (
+	"testing"
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsNormalize(t *testing.T) {
+	settings := DefaultSettings("user-1")
+	require.NoError(t, settings.Normalize(), "the defaults are valid")
+
+	settings.Locale = "PT_br"
+	settings.ContentMode = "Kids"
+	settings.SortOrders.Movies = SortOrder{SortBy: "release_year", Order: "ASC"}
+	require.NoError(t, settings.Normalize())
+	assert.Equal(t, "pt-BR", settings.Locale)
+	assert.Equal(t, ContentModeKids, settings.ContentMode)
+	assert.Equal(t, SortOrder{SortBy: "release_year", Order: "asc"}, settings.SortOrders.Movies)
+
+	for name, tc := range map[string]struct {
+		change func(*Settings)
+		want   error
+	}{
+		"locale":                {func(s *Settings) { s.Locale = "english" }, movies.ErrInvalidLocale},
+		"content mode":          {func(s *Settings) { s.ContentMode = "adult" }, ErrInvalidContentMode},
+		"field of another list": {func(s *Settings) { s.SortOrders.Ratings.SortBy = "title" }, ErrInvalidSortOrder},
+		"direction":             {func(s *Settings) { s.SortOrders.Profile.Order = "up" }, ErrInvalidSortOrder},
+	} {
+		t.Run(name, func(t *testing.T) {
+			settings := DefaultSettings("user-1")
+			tc.change(settings)
+			assert.ErrorIs(t, settings.Normalize(), tc.want)
+		})
+	}
+}
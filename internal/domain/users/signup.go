@@ -0,0 +1,61 @@
+package users
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrEmailDomainNotAllowed = errors.New("email domain is not allowed")
+
+// DisposableEmailDomains are throwaway mailbox providers commonly used for
+// scripted signups. It is a starting point, not an exhaustive list; extend
+// it through EmailDomainPolicy.Blocked.
+var DisposableEmailDomains = []string{
+	"10minutemail.com",
+	"dispostable.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"maildrop.cc",
+	"mailinator.com",
+	"sharklasers.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// EmailDomainPolicy decides which email domains may register. A domain also
+// covers its subdomains. When Allowed is non-empty only those domains may
+// register; Blocked always wins.
+type EmailDomainPolicy struct {
+	Allowed []string
+	Blocked []string
+}
+
+// Permits reports whether email may be used to register
+func (p EmailDomainPolicy) Permits(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	if matchesDomain(domain, p.Blocked) {
+		return false
+	}
+	return len(p.Allowed) == 0 || matchesDomain(domain, p.Allowed)
+}
+
+func matchesDomain(domain string, list []string) bool {
+	for _, entry := range list {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if domain == entry || strings.HasSuffix(domain, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
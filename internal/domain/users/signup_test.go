@@ -0,0 +1,32 @@
+package users
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailDomainPolicyPermits(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy EmailDomainPolicy
+		email  string
+		want   bool
+	}{
+		{"empty policy allows everything", EmailDomainPolicy{}, "jane@example.com", true},
+		{"blocked domain", EmailDomainPolicy{Blocked: []string{"mailinator.com"}}, "bot@mailinator.com", false},
+		{"blocked domain is case-insensitive", EmailDomainPolicy{Blocked: []string{"Mailinator.com"}}, "bot@MAILINATOR.COM", false},
+		{"blocked domain covers subdomains", EmailDomainPolicy{Blocked: []string{"mailinator.com"}}, "bot@eu.mailinator.com", false},
+		{"suffix is not a subdomain", EmailDomainPolicy{Blocked: []string{"mailinator.com"}}, "jane@notmailinator.com", true},
+		{"allowlist admits listed domains", EmailDomainPolicy{Allowed: []string{"thermondo.de"}}, "jane@thermondo.de", true},
+		{"allowlist rejects the rest", EmailDomainPolicy{Allowed: []string{"thermondo.de"}}, "jane@example.com", false},
+		{"blocklist wins over allowlist", EmailDomainPolicy{Allowed: []string{"thermondo.de"}, Blocked: []string{"test.thermondo.de"}}, "bot@test.thermondo.de", false},
+		{"no domain", EmailDomainPolicy{}, "not-an-email", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Permits(tt.email))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package users
+
+import "time"
+
+// TrustConfig is the formula that turns TrustFactors into a rating weight.
+// Each component grows linearly to its full weight: account age up to
+// FullTrustAge, rating count up to FullTrustRatings, plus VerifiedWeight for
+// a verified email. Every upheld report subtracts ReportPenalty and the
+// result is clamped to [MinTrust, 1].
+type TrustConfig struct {
+	FullTrustAge     time.Duration
+	FullTrustRatings int64
+	AgeWeight        float64
+	ActivityWeight   float64
+	VerifiedWeight   float64
+	ReportPenalty    float64
+	MinTrust         float64
+}
+
+func DefaultTrustConfig() TrustConfig {
+	return TrustConfig{
+		FullTrustAge:     30 * 24 * time.Hour,
+		FullTrustRatings: 20,
+		AgeWeight:        0.4,
+		ActivityWeight:   0.3,
+		VerifiedWeight:   0.3,
+		ReportPenalty:    0.25,
+		MinTrust:         0.1,
+	}
+}
+
+// TrustFactors are what a user's trust score is computed from
+type TrustFactors struct {
+	AccountAge    time.Duration
+	EmailVerified bool
+	RatingCount   int64
+	ReportCount   int64
+}
+
+// Score returns the weight of a rating by a user with factors f
+func (c TrustConfig) Score(f TrustFactors) float64 {
+	score := c.AgeWeight * fraction(float64(f.AccountAge), float64(c.FullTrustAge))
+	score += c.ActivityWeight * fraction(float64(f.RatingCount), float64(c.FullTrustRatings))
+	if f.EmailVerified {
+		score += c.VerifiedWeight
+	}
+	score -= c.ReportPenalty * float64(f.ReportCount)
+
+	return min(max(score, c.MinTrust), 1)
+}
+
+// fraction is value/full capped to [0, 1]; a zero full means no ramp-up
+func fraction(value, full float64) float64 {
+	if full <= 0 {
+		return 1
+	}
+	return min(max(value/full, 0), 1)
+}
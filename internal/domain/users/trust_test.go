@@ -0,0 +1,32 @@
+package users
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrustConfigScore(t *testing.T) {
+	config := DefaultTrustConfig()
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		factors TrustFactors
+		want    float64
+	}{
+		{"brand-new account", TrustFactors{}, 0.1},
+		{"established verified account", TrustFactors{AccountAge: 90 * day, EmailVerified: true, RatingCount: 50}, 1},
+		{"half-way account", TrustFactors{AccountAge: 15 * day, RatingCount: 10}, 0.35},
+		{"verified newcomer", TrustFactors{EmailVerified: true}, 0.3},
+		{"reported veteran", TrustFactors{AccountAge: 90 * day, EmailVerified: true, RatingCount: 50, ReportCount: 2}, 0.5},
+		{"reports never push below the floor", TrustFactors{ReportCount: 10}, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, config.Score(tt.factors), 0.0001)
+		})
+	}
+}
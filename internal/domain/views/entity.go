@@ -0,0 +1,20 @@
+package views
+
+import (
+	"thermondo/internal/domain/movies"
+	"time"
+)
+
+// DailyViews counts the users who viewed a movie on one day (UTC). A user
+// viewing the movie again the same day is not counted again.
+type DailyViews struct {
+	MovieID movies.MovieID `json:"movie_id"`
+	Day     time.Time      `json:"day"`
+	Views   int64          `json:"views"`
+}
+
+// RecentView is a movie a user viewed and when they last did
+type RecentView struct {
+	MovieID  movies.MovieID
+	ViewedAt time.Time
+}
@@ -0,0 +1,14 @@
+package views
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Add adds the counts to those stored for the same movie and day
+	Add(ctx context.Context, counts []*DailyViews) error
+	// Top returns up to limit of the movies most viewed on day, most
+	// viewed first
+	Top(ctx context.Context, day time.Time, limit int) ([]*DailyViews, error)
+}
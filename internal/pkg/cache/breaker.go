@@ -0,0 +1,293 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"thermondo/internal/pkg/metrics"
+	"time"
+)
+
+var (
+	breakerState         = metrics.NewGauge("cache_circuit_state", "State of the cache circuit breaker: 0 closed, 1 open, 2 half-open")
+	breakerOpened        = metrics.NewCounter("cache_circuit_opened_total", "Times the cache circuit breaker opened")
+	breakerShortCircuits = metrics.NewCounter("cache_circuit_short_circuits_total", "Cache calls skipped while the circuit breaker was open")
+)
+
+// ErrCircuitOpen is returned by Ping while the circuit breaker skips calls
+var ErrCircuitOpen = errors.New("cache circuit breaker is open")
+
+const (
+	DefaultBreakerFailures = 5
+	DefaultBreakerCoolDown = 30 * time.Second
+)
+
+// BreakerState is the state of a CircuitBreaker
+type BreakerState int
+
+const (
+	// BreakerClosed passes every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen skips every call until the cool-down has passed
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial call through to decide whether
+	// to close again
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker keeps a cache outage from slowing down every request. After
+// consecutive failures it opens and, for a cool-down, answers reads with a
+// miss and writes with a no-op without calling the cache. Then one trial call
+// goes through: it closes the breaker when it succeeds and opens it for
+// another cool-down when it fails.
+//
+// Deletes skipped while the breaker is open are lost, so entries written
+// before the outage can be served until their TTL expires.
+type CircuitBreaker struct {
+	next     Cache
+	logger   *slog.Logger
+	failures int
+	coolDown time.Duration
+	now      func() time.Time
+
+	mu                  sync.Mutex
+	state               BreakerState
+	consecutiveFailures int
+	openedAt            time.Time
+}
+
+// BreakerOption configures optional settings of the circuit breaker
+type BreakerOption func(*CircuitBreaker)
+
+// WithBreakerFailures sets how many consecutive failures open the breaker
+func WithBreakerFailures(n int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if n > 0 {
+			b.failures = n
+		}
+	}
+}
+
+// WithBreakerCoolDown sets how long the breaker stays open
+func WithBreakerCoolDown(d time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if d > 0 {
+			b.coolDown = d
+		}
+	}
+}
+
+// WithBreakerClock replaces time.Now, for tests
+func WithBreakerClock(now func() time.Time) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.now = now
+	}
+}
+
+func NewCircuitBreaker(next Cache, logger *slog.Logger, opts ...BreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		next:     next,
+		logger:   logger,
+		failures: DefaultBreakerFailures,
+		coolDown: DefaultBreakerCoolDown,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	breakerState.Set(float64(BreakerClosed))
+	return b
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow tells whether a call may go through, moving an open breaker whose
+// cool-down has passed to half-open for one trial call. Until the trial
+// reports back, openedAt is when it started.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen, BreakerHalfOpen:
+		// A trial that never reported back is replaced after a cool-down
+		if now := b.now(); now.Sub(b.openedAt) >= b.coolDown {
+			b.openedAt = now
+			if b.state == BreakerOpen {
+				b.setState(BreakerHalfOpen)
+			}
+			return true
+		}
+	}
+	breakerShortCircuits.Inc()
+	return false
+}
+
+// record counts the outcome of a call that went through. Misses are
+// answers, and a call canceled by its caller says nothing about the cache.
+func (b *CircuitBreaker) record(err error) {
+	failed := err != nil && !errors.Is(err, ErrCacheMiss) && !errors.Is(err, context.Canceled)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.consecutiveFailures = 0
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.consecutiveFailures >= b.failures) {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+		breakerOpened.Inc()
+		b.logger.Warn("Cache circuit breaker opened, skipping the cache",
+			slog.String("error", err.Error()),
+			slog.Int("consecutive_failures", b.consecutiveFailures),
+			slog.Duration("cool_down", b.coolDown))
+	}
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	b.state = state
+	breakerState.Set(float64(state))
+	if state != BreakerOpen {
+		b.logger.Info("Cache circuit breaker " + state.String())
+	}
+}
+
+func (b *CircuitBreaker) Get(ctx context.Context, key string, dest interface{}) error {
+	if !b.allow() {
+		return ErrCacheMiss
+	}
+	err := b.next.Get(ctx, key, dest)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.Set(ctx, key, value, ttl)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) Delete(ctx context.Context, keys ...string) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.Delete(ctx, keys...)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) DeletePattern(ctx context.Context, pattern string) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.DeletePattern(ctx, pattern)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.SetWithTags(ctx, key, value, ttl, tags...)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	if !b.allow() {
+		return nil, nil
+	}
+	keys, err := b.next.TaggedKeys(ctx, tags...)
+	b.record(err)
+	return keys, err
+}
+
+func (b *CircuitBreaker) InvalidateTags(ctx context.Context, tags ...string) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.InvalidateTags(ctx, tags...)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) Exists(ctx context.Context, key string) (bool, error) {
+	if !b.allow() {
+		return false, nil
+	}
+	exists, err := b.next.Exists(ctx, key)
+	b.record(err)
+	return exists, err
+}
+
+func (b *CircuitBreaker) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if !b.allow() {
+		return 0, nil
+	}
+	ttl, err := b.next.TTL(ctx, key)
+	b.record(err)
+	return ttl, err
+}
+
+func (b *CircuitBreaker) MGet(ctx context.Context, keys []string, dest interface{}) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.MGet(ctx, keys, dest)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if !b.allow() {
+		return nil
+	}
+	err := b.next.MSet(ctx, items, ttl)
+	b.record(err)
+	return err
+}
+
+// Ping reports ErrCircuitOpen while calls are skipped, so health checks
+// still show the outage
+func (b *CircuitBreaker) Ping(ctx context.Context) error {
+	if !b.allow() {
+		return ErrCircuitOpen
+	}
+	err := b.next.Ping(ctx)
+	b.record(err)
+	return err
+}
+
+func (b *CircuitBreaker) Close() error {
+	return b.next.Close()
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	dialErr := errors.New("dial tcp: i/o timeout")
+	setup := func() (*MockCache, *CircuitBreaker, *time.Time) {
+		next := new(MockCache)
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		breaker := NewCircuitBreaker(next, slog.New(slog.NewTextHandler(io.Discard, nil)),
+			WithBreakerFailures(3), WithBreakerCoolDown(30*time.Second), WithBreakerClock(func() time.Time { return now }))
+		return next, breaker, &now
+	}
+
+	t.Run("opens after consecutive failures and skips the cache", func(t *testing.T) {
+		next, breaker, _ := setup()
+		next.On("Get", ctx, "movie:1", mock.Anything).Return(dialErr).Times(3)
+
+		var dest string
+		for range 3 {
+			assert.ErrorIs(t, breaker.Get(ctx, "movie:1", &dest), dialErr)
+		}
+		require.Equal(t, BreakerOpen, breaker.State())
+
+		assert.ErrorIs(t, breaker.Get(ctx, "movie:1", &dest), ErrCacheMiss)
+		assert.NoError(t, breaker.Set(ctx, "movie:1", "Heat", time.Minute))
+		assert.NoError(t, breaker.DeletePattern(ctx, "movie:*"))
+		assert.ErrorIs(t, breaker.Ping(ctx), ErrCircuitOpen)
+		next.AssertExpectations(t)
+	})
+
+	t.Run("misses and successes reset the failure count", func(t *testing.T) {
+		next, breaker, _ := setup()
+		next.On("Get", ctx, "down", mock.Anything).Return(dialErr)
+		next.On("Get", ctx, "missing", mock.Anything).Return(ErrCacheMiss)
+
+		var dest string
+		for range 2 {
+			breaker.Get(ctx, "down", &dest)
+			breaker.Get(ctx, "down", &dest)
+			breaker.Get(ctx, "missing", &dest)
+		}
+		assert.Equal(t, BreakerClosed, breaker.State())
+	})
+
+	t.Run("closes when the trial after the cool-down succeeds", func(t *testing.T) {
+		next, breaker, now := setup()
+		next.On("Set", ctx, "movie:1", "Heat", time.Minute).Return(dialErr).Times(3)
+		for range 3 {
+			breaker.Set(ctx, "movie:1", "Heat", time.Minute)
+		}
+		require.Equal(t, BreakerOpen, breaker.State())
+
+		*now = now.Add(30 * time.Second)
+		next.On("Ping", ctx).Return(nil).Once()
+		assert.NoError(t, breaker.Ping(ctx))
+		assert.Equal(t, BreakerClosed, breaker.State())
+		next.AssertExpectations(t)
+	})
+
+	t.Run("opens again when the trial fails", func(t *testing.T) {
+		next, breaker, now := setup()
+		next.On("Ping", ctx).Return(dialErr)
+		for range 3 {
+			breaker.Ping(ctx)
+		}
+
+		*now = now.Add(30 * time.Second)
+		assert.ErrorIs(t, breaker.Ping(ctx), dialErr)
+		assert.Equal(t, BreakerOpen, breaker.State())
+
+		*now = now.Add(10 * time.Second)
+		assert.ErrorIs(t, breaker.Ping(ctx), ErrCircuitOpen, "for a whole new cool-down")
+		next.AssertNumberOfCalls(t, "Ping", 4)
+	})
+
+	t.Run("lets one trial through at a time", func(t *testing.T) {
+		next, breaker, now := setup()
+		next.On("Ping", ctx).Return(dialErr).Times(3)
+		for range 3 {
+			breaker.Ping(ctx)
+		}
+		*now = now.Add(30 * time.Second)
+
+		require.True(t, breaker.allow())
+		assert.Equal(t, BreakerHalfOpen, breaker.State())
+		assert.False(t, breaker.allow())
+	})
+}
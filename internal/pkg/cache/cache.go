@@ -33,6 +33,11 @@ type Cache interface {
 	TTL(ctx context.Context, key string) (time.Duration, error)
 	Close() error
 
+	// Tagged entries
+	SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error
+	TaggedKeys(ctx context.Context, tags ...string) ([]string, error)
+	InvalidateTags(ctx context.Context, tags ...string) error
+
 	// Batch operations
 	MGet(ctx context.Context, keys []string, dest interface{}) error
 	MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error
@@ -47,6 +52,19 @@ type redisCache struct {
 }
 
 func NewRedisCache(config RedisConfig, prefix string) (Cache, error) {
+	rdb, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisCache{
+		client: rdb,
+		prefix: prefix,
+	}, nil
+}
+
+// newRedisClient connects to Redis and checks the connection
+func newRedisClient(config RedisConfig) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:         fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Password:     config.Password,
@@ -68,10 +86,7 @@ func NewRedisCache(config RedisConfig, prefix string) (Cache, error) {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	return &redisCache{
-		client: rdb,
-		prefix: prefix,
-	}, nil
+	return rdb, nil
 }
 
 func (r *redisCache) getKey(key string) string {
@@ -155,6 +170,87 @@ func (r *redisCache) DeletePattern(ctx context.Context, pattern string) error {
 	return iter.Err()
 }
 
+// tagKey is the set holding the keys registered under a tag
+func (r *redisCache) tagKey(tag string) string {
+	return r.getKey(Key("tag", tag))
+}
+
+// SetWithTags stores the value and registers the key under every tag. A tag
+// set lives as long as the longest-lived entry registered under it.
+func (r *redisCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("json marshal error: %w", err)
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, r.getKey(key), data, ttl)
+	for _, tag := range tags {
+		tagKey := r.tagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		if ttl > 0 {
+			pipe.ExpireNX(ctx, tagKey, ttl)
+			pipe.ExpireGT(ctx, tagKey, ttl)
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis set with tags error: %w", err)
+	}
+
+	return nil
+}
+
+// TaggedKeys returns the keys registered under any of the tags. Keys that
+// have expired since may still be listed.
+func (r *redisCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = r.tagKey(tag)
+	}
+
+	keys, err := r.client.SUnion(ctx, tagKeys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis sunion error: %w", err)
+	}
+	return keys, nil
+}
+
+// InvalidateTags deletes every key registered under the tags. Only the keys
+// deleted are dropped from the tag sets, so a key registered meanwhile stays
+// tagged.
+func (r *redisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := r.tagKey(tag)
+		keys, err := r.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return fmt.Errorf("redis smembers error: %w", err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		prefixedKeys := make([]string, len(keys))
+		members := make([]interface{}, len(keys))
+		for i, key := range keys {
+			prefixedKeys[i] = r.getKey(key)
+			members[i] = key
+		}
+
+		pipe := r.client.TxPipeline()
+		pipe.Del(ctx, prefixedKeys...)
+		pipe.SRem(ctx, tagKey, members...)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("redis invalidate tag error: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (r *redisCache) Exists(ctx context.Context, key string) (bool, error) {
 	count, err := r.client.Exists(ctx, r.getKey(key)).Result()
 	if err != nil {
@@ -266,6 +362,18 @@ func (n *NoOpCache) DeletePattern(ctx context.Context, pattern string) error {
 	return nil
 }
 
+func (n *NoOpCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	return nil
+}
+
+func (n *NoOpCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	return nil, nil
+}
+
+func (n *NoOpCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return nil
+}
+
 func (n *NoOpCache) Exists(ctx context.Context, key string) (bool, error) {
 	return false, nil
 }
@@ -2,12 +2,20 @@ package cache
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
+	"thermondo/internal/pkg/testenv"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
 func TestNoOpCache_Behavior(t *testing.T) {
 	ctx := context.Background()
 	c := NewNoOpCache()
@@ -29,6 +37,15 @@ func TestNoOpCache_Behavior(t *testing.T) {
 	err = c.DeletePattern(ctx, "pattern*")
 	assert.NoError(t, err)
 
+	// Tagged entries are neither stored nor listed
+	err = c.SetWithTags(ctx, "key", "value", time.Minute, UserTag("1"))
+	assert.NoError(t, err)
+	keys, err := c.TaggedKeys(ctx, UserTag("1"))
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	err = c.InvalidateTags(ctx, UserTag("1"))
+	assert.NoError(t, err)
+
 	// Exists should return false, nil
 	exists, err := c.Exists(ctx, "key")
 	assert.NoError(t, err)
@@ -54,3 +71,30 @@ func TestNoOpCache_Behavior(t *testing.T) {
 	err = c.Ping(ctx)
 	assert.NoError(t, err)
 }
+
+func TestRedisCache_Tags(t *testing.T) {
+	ctx := context.Background()
+	host, port := testenv.Redis(t)
+	c, err := NewRedisCache(RedisConfig{Host: host, Port: port}, t.Name())
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.SetWithTags(ctx, UserStatsKeyFunc("u1"), 1, time.Minute, UserTag("u1")))
+	require.NoError(t, c.SetWithTags(ctx, UserWrappedKeyFunc("u1", 2024), 2, time.Minute, UserTag("u1")))
+	require.NoError(t, c.SetWithTags(ctx, UserStatsKeyFunc("u2"), 3, time.Minute, UserTag("u2")))
+
+	keys, err := c.TaggedKeys(ctx, UserTag("u1"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user_stats:u1", "user_wrapped:u1:2024"}, keys)
+
+	require.NoError(t, c.InvalidateTags(ctx, UserTag("u1")))
+	var value int
+	assert.ErrorIs(t, c.Get(ctx, UserStatsKeyFunc("u1"), &value), ErrCacheMiss)
+	assert.ErrorIs(t, c.Get(ctx, UserWrappedKeyFunc("u1", 2024), &value), ErrCacheMiss)
+	require.NoError(t, c.Get(ctx, UserStatsKeyFunc("u2"), &value))
+	assert.Equal(t, 3, value)
+
+	keys, err = c.TaggedKeys(ctx, UserTag("u1"))
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
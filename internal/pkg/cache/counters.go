@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Counters are integer counters shared by every instance of the service
+// when kept in Redis. The in-memory implementation only counts for the
+// instance it runs in.
+type Counters interface {
+	// IncrBy adds n to the counter at key and returns its new value. A
+	// counter created by the call expires after ttl.
+	IncrBy(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error)
+	// HIncrBy adds each value to its field of the hash at key
+	HIncrBy(ctx context.Context, key string, fields map[string]int64) error
+	// HTake returns the hash at key and deletes it in one step, so counts
+	// added meanwhile land in a new hash
+	HTake(ctx context.Context, key string) (map[string]int64, error)
+	Close() error
+}
+
+type redisCounters struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisCounters(config RedisConfig, prefix string) (Counters, error) {
+	rdb, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &redisCounters{client: rdb, prefix: prefix}, nil
+}
+
+func (r *redisCounters) getKey(key string) string {
+	if r.prefix == "" {
+		return key
+	}
+	return fmt.Sprintf("%s:%s", r.prefix, key)
+}
+
+func (r *redisCounters) IncrBy(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, r.getKey(key), n)
+	pipe.ExpireNX(ctx, r.getKey(key), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("redis incrby error: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+func (r *redisCounters) HIncrBy(ctx context.Context, key string, fields map[string]int64) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	pipe := r.client.Pipeline()
+	for field, n := range fields {
+		pipe.HIncrBy(ctx, r.getKey(key), field, n)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis hincrby error: %w", err)
+	}
+	return nil
+}
+
+func (r *redisCounters) HTake(ctx context.Context, key string) (map[string]int64, error) {
+	pipe := r.client.TxPipeline()
+	all := pipe.HGetAll(ctx, r.getKey(key))
+	pipe.Del(ctx, r.getKey(key))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("redis htake error: %w", err)
+	}
+
+	values := make(map[string]int64, len(all.Val()))
+	for field, value := range all.Val() {
+		var n int64
+		if _, err := fmt.Sscan(value, &n); err != nil {
+			return nil, fmt.Errorf("redis htake: field %s holds %q: %w", field, value, err)
+		}
+		values[field] = n
+	}
+	return values, nil
+}
+
+func (r *redisCounters) Close() error {
+	return r.client.Close()
+}
+
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// MemoryCounters keeps counters in the process, for a single instance or
+// environments without Redis
+type MemoryCounters struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	counters map[string]*memoryCounter
+	hashes   map[string]map[string]int64
+}
+
+func NewMemoryCounters() *MemoryCounters {
+	return &MemoryCounters{
+		now:      time.Now,
+		counters: make(map[string]*memoryCounter),
+		hashes:   make(map[string]map[string]int64),
+	}
+}
+
+func (m *MemoryCounters) IncrBy(ctx context.Context, key string, n int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	c, ok := m.counters[key]
+	if !ok || !now.Before(c.expiresAt) {
+		c = &memoryCounter{expiresAt: now.Add(ttl)}
+		m.counters[key] = c
+	}
+	c.value += n
+	return c.value, nil
+}
+
+func (m *MemoryCounters) HIncrBy(ctx context.Context, key string, fields map[string]int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash, ok := m.hashes[key]
+	if !ok {
+		hash = make(map[string]int64, len(fields))
+		m.hashes[key] = hash
+	}
+	for field, n := range fields {
+		hash[field] += n
+	}
+	return nil
+}
+
+func (m *MemoryCounters) HTake(ctx context.Context, key string) (map[string]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hash := m.hashes[key]
+	delete(m.hashes, key)
+	if hash == nil {
+		hash = map[string]int64{}
+	}
+	return hash, nil
+}
+
+func (m *MemoryCounters) Close() error {
+	return nil
+}
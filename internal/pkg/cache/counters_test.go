@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCounters_IncrBy(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	counters := NewMemoryCounters()
+	counters.now = func() time.Time { return now }
+
+	n, err := counters.IncrBy(ctx, "month", 1, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, _ = counters.IncrBy(ctx, "month", 41, time.Hour)
+	assert.Equal(t, int64(42), n)
+
+	// The ttl is only set when the counter is created
+	now = now.Add(59 * time.Minute)
+	n, _ = counters.IncrBy(ctx, "month", 1, 24*time.Hour)
+	assert.Equal(t, int64(43), n)
+	now = now.Add(time.Minute)
+	n, _ = counters.IncrBy(ctx, "month", 1, time.Hour)
+	assert.Equal(t, int64(1), n, "an expired counter starts over")
+}
+
+func TestMemoryCounters_HTake(t *testing.T) {
+	ctx := context.Background()
+	counters := NewMemoryCounters()
+
+	require.NoError(t, counters.HIncrBy(ctx, "pending", map[string]int64{"a": 1, "b": 10}))
+	require.NoError(t, counters.HIncrBy(ctx, "pending", map[string]int64{"a": 2}))
+
+	taken, err := counters.HTake(ctx, "pending")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"a": 3, "b": 10}, taken)
+
+	taken, err = counters.HTake(ctx, "pending")
+	require.NoError(t, err)
+	assert.Empty(t, taken, "taking empties the hash")
+}
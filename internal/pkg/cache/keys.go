@@ -2,50 +2,173 @@ package cache
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
-// Cache key patterns for different domains
+// Cache keys and TTLs for different domains
 const (
-	// Movie-related cache keys
-	MovieStatsKey   = "movie_stats:%s"        // movie_stats:{movie_id}
-	MovieSearchKey  = "movie_search:%s:%d:%d" // movie_search:{query}:{limit}:{offset}
-	MovieDetailsKey = "movie_details:%s"      // movie_details:{movie_id}
-
-	// User-related cache keys
-	UserProfileKey = "user_profile:%s:%d:%d:%s" // user_profile:{user_id}:{limit}:{offset}:{sort}
-	UserStatsKey   = "user_stats:%s"            // user_stats:{user_id}
-	UserRatingKey  = "user_rating:%s:%s"        // user_rating:{user_id}:{movie_id}
-
 	// Global cache keys
 	GlobalAverageKey = "global_average"
 	TopMoviesKey     = "top_movies:%d" // top_movies:{limit}
 
+	// Admin cache keys
+	AdminSummaryKey = "admin_summary"
+
 	// Cache TTL constants
 	MovieStatsTTL    = 15 * time.Minute
 	UserProfileTTL   = 10 * time.Minute
 	UserStatsTTL     = 5 * time.Minute
 	GlobalAverageTTL = 1 * time.Hour
 	MovieSearchTTL   = 20 * time.Minute
+	MovieFacetsTTL   = 10 * time.Minute
+	MovieSuggestTTL  = 5 * time.Minute
+	AdminSummaryTTL  = 5 * time.Minute
+	UserWrappedTTL   = 15 * time.Minute
+	// Director and genre stats span many movies and change slowly
+	AggregateStatsTTL = 30 * time.Minute
+	DecadesTTL        = 30 * time.Minute
+	// A pair's shared raters barely move once it has enough of them
+	HeadToHeadTTL = 1 * time.Hour
+	// Search engines crawl the sitemap at most a few times a day
+	SitemapTTL = 1 * time.Hour
+	// Structured data is also cleared with the movie's tag when it is rated
+	StructuredDataTTL = 1 * time.Hour
+	// Feed readers poll often; review feeds are also cleared with the movie's tag
+	FeedTTL = 5 * time.Minute
+
+	// Home shelves are cached one by one, as they go stale at different rates
+	HomeWatchlistTTL       = 2 * time.Minute
+	HomeTopPicksTTL        = 1 * time.Hour
+	HomeBecauseYouRatedTTL = 15 * time.Minute
+	HomeTrendingTTL        = 10 * time.Minute
+)
+
+// Key builds a cache key from a namespace and the parts that tell its
+// entries apart, joined by colons. Every key is built by Key, through the
+// helpers below, so the format cannot drift between the code that writes
+// an entry and the code that clears it.
+func Key(namespace string, parts ...interface{}) string {
+	var b strings.Builder
+	b.WriteString(namespace)
+	for _, part := range parts {
+		b.WriteByte(':')
+		fmt.Fprint(&b, part)
+	}
+	return b.String()
+}
+
+// Tags group entries for InvalidateTags. Entries about a user are tagged
+// with UserTag and cached responses about a movie with MovieTag, so clearing
+// the tag clears every one of them, whatever its key looks like.
+const (
+	// ProfilesTag groups the profile pages of every user
+	ProfilesTag = "user_profiles"
 )
 
-// Cache key builders
+// UserTag groups the entries about one user
+func UserTag(userID string) string {
+	return Key("user", userID)
+}
+
+// MovieTag groups the cached responses about one movie
+func MovieTag(movieID string) string {
+	return Key("movie", movieID)
+}
+
+// Cache key builders. Entries about a user are tagged with UserTag, profile
+// pages with ProfilesTag as well; home shelves are not, as they go stale at
+// their own rates.
+//
+//	movie_stats:{movie_id}
+//	movie_search:{query}:{limit}:{offset}
+//	movie_facets:{filters}
+//	movie_suggest:{query}:{limit}
+//	user_profile:{user_id}:{limit}:{offset}:{sort}:{order}:{filters}
+//	user_stats:{user_id}
+//	user_wrapped:{user_id}:{year}
+//	user_rating:{user_id}:{movie_id}
+//	home_shelf:{user_id}:{shelf}
+//	aggregate_stats:{kind}:{name}
+//	decades:{top}
+//	head_to_head:{movie_id}:{movie_id}
+//	sitemap:{page}
+//	structured_data:{movie_id}
+//	feed:{kind}:{id}
+//	not_found:{kind}:{id}
 func MovieStatsKeyFunc(movieID string) string {
-	return fmt.Sprintf(MovieStatsKey, movieID)
+	return Key("movie_stats", movieID)
 }
 
-func UserProfileKeyFunc(userID string, limit, offset int, sortBy string) string {
-	return fmt.Sprintf(UserProfileKey, userID, limit, offset, sortBy)
+func UserProfileKeyFunc(userID string, limit, offset int, sortBy, order, filters string) string {
+	return Key("user_profile", userID, limit, offset, sortBy, order, filters)
 }
 
 func UserStatsKeyFunc(userID string) string {
-	return fmt.Sprintf(UserStatsKey, userID)
+	return Key("user_stats", userID)
+}
+
+func UserWrappedKeyFunc(userID string, year int) string {
+	return Key("user_wrapped", userID, year)
 }
 
 func UserRatingKeyFunc(userID, movieID string) string {
-	return fmt.Sprintf(UserRatingKey, userID, movieID)
+	return Key("user_rating", userID, movieID)
 }
 
 func MovieSearchKeyFunc(query string, limit, offset int) string {
-	return fmt.Sprintf(MovieSearchKey, query, limit, offset)
+	return Key("movie_search", query, limit, offset)
+}
+
+func MovieFacetsKeyFunc(filters string) string {
+	return Key("movie_facets", filters)
+}
+
+func MovieSuggestKeyFunc(query string, limit int) string {
+	return Key("movie_suggest", query, limit)
+}
+
+func HomeShelfKeyFunc(userID, shelf string) string {
+	return Key("home_shelf", userID, shelf)
+}
+
+// AggregateStatsKeyFunc keys director and genre stats ranked by metric;
+// names are matched case-insensitively, so name should be lower-cased
+func AggregateStatsKeyFunc(kind, name, metric string) string {
+	return Key("aggregate_stats", kind, name, metric)
+}
+
+func DecadesKeyFunc(top int, metric string) string {
+	return Key("decades", top, metric)
+}
+
+// SitemapKeyFunc keys a sitemap page; page 0 is the sitemap index
+func SitemapKeyFunc(page int) string {
+	return Key("sitemap", page)
+}
+
+func StructuredDataKeyFunc(movieID string) string {
+	return Key("structured_data", movieID)
+}
+
+// FeedKeyFunc keys an Atom feed; id is empty for feeds of the whole catalog
+func FeedKeyFunc(kind, id string) string {
+	return Key("feed", kind, id)
+}
+
+// NotFoundKeyFunc keys the record that the kind of entity with id, e.g. a
+// movie, does not exist
+func NotFoundKeyFunc(kind, id string) string {
+	return Key("not_found", kind, id)
+}
+
+// HeadToHeadKeyFunc keys the head-to-head of a movie pair. The IDs are put
+// in order so both orders of a pair share an entry; it reports whether they
+// were swapped, in which case the cached head-to-head is of second against
+// first.
+func HeadToHeadKeyFunc(first, second string) (key string, swapped bool) {
+	if second < first {
+		return Key("head_to_head", second, first), true
+	}
+	return Key("head_to_head", first, second), false
 }
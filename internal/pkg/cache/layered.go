@@ -0,0 +1,456 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"thermondo/internal/pkg/metrics"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	l1Hits   = metrics.NewCounter("cache_l1_hits_total", "Cache reads answered by the in-process cache")
+	l1Misses = metrics.NewCounter("cache_l1_misses_total", "Cache reads passed on to the shared cache")
+)
+
+const (
+	DefaultL1Size = 1000
+	DefaultL1TTL  = 5 * time.Second
+)
+
+// Invalidation tells other instances which keys to drop from their
+// in-process cache
+type Invalidation struct {
+	Origin  string   `json:"origin"`
+	Keys    []string `json:"keys,omitempty"`
+	Pattern string   `json:"pattern,omitempty"`
+}
+
+// InvalidationBus broadcasts invalidations between instances
+type InvalidationBus interface {
+	Publish(ctx context.Context, inv Invalidation) error
+	// Subscribe calls handle for every invalidation published, by any
+	// instance, until ctx is done
+	Subscribe(ctx context.Context, handle func(Invalidation)) error
+	Close() error
+}
+
+// LayeredCache keeps the hottest entries of a shared cache in process for a
+// short while, so repeated reads of the same key skip the round trip to
+// Redis. Every write goes through to the shared cache and is broadcast on
+// the invalidation bus, so other instances drop their copy right away
+// instead of serving it until the L1 TTL runs out.
+type LayeredCache struct {
+	l2     Cache
+	l1     *lru
+	l1TTL  time.Duration
+	bus    InvalidationBus
+	origin string
+	logger *slog.Logger
+	now    func() time.Time
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// LayeredOption configures optional settings of the layered cache
+type LayeredOption func(*LayeredCache)
+
+// WithL1Size sets how many entries the in-process cache holds at most
+func WithL1Size(n int) LayeredOption {
+	return func(c *LayeredCache) {
+		if n > 0 {
+			c.l1 = newLRU(n)
+		}
+	}
+}
+
+// WithL1TTL sets how long an entry is served from the in-process cache at
+// most; entries set with a shorter TTL keep theirs
+func WithL1TTL(d time.Duration) LayeredOption {
+	return func(c *LayeredCache) {
+		if d > 0 {
+			c.l1TTL = d
+		}
+	}
+}
+
+// WithInvalidationBus broadcasts writes to other instances and applies
+// theirs
+func WithInvalidationBus(bus InvalidationBus) LayeredOption {
+	return func(c *LayeredCache) {
+		c.bus = bus
+	}
+}
+
+func NewLayeredCache(l2 Cache, logger *slog.Logger, opts ...LayeredOption) *LayeredCache {
+	c := &LayeredCache{
+		l2:     l2,
+		l1:     newLRU(DefaultL1Size),
+		l1TTL:  DefaultL1TTL,
+		origin: newOrigin(),
+		logger: logger,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.bus != nil {
+		ctx, stop := context.WithCancel(context.Background())
+		c.stop = stop
+		c.done = make(chan struct{})
+		go func() {
+			defer close(c.done)
+			if err := c.bus.Subscribe(ctx, c.apply); err != nil && ctx.Err() == nil {
+				c.logger.Error("Cache invalidation subscription ended", slog.String("error", err.Error()))
+			}
+		}()
+	}
+	return c
+}
+
+func newOrigin() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// apply drops the keys another instance invalidated
+func (c *LayeredCache) apply(inv Invalidation) {
+	if inv.Origin == c.origin {
+		return
+	}
+	c.l1.remove(inv.Keys...)
+	if inv.Pattern != "" {
+		c.l1.removeMatching(inv.Pattern)
+	}
+}
+
+func (c *LayeredCache) broadcast(ctx context.Context, inv Invalidation) {
+	if c.bus == nil {
+		return
+	}
+	inv.Origin = c.origin
+	if err := c.bus.Publish(ctx, inv); err != nil {
+		c.logger.Warn("Failed to broadcast cache invalidation", slog.String("error", err.Error()))
+	}
+}
+
+func (c *LayeredCache) Get(ctx context.Context, key string, dest interface{}) error {
+	if data, ok := c.l1.get(key, c.now()); ok {
+		l1Hits.Inc()
+		return json.Unmarshal(data, dest)
+	}
+	l1Misses.Inc()
+
+	// An invalidation landing during the read must win over what was read
+	generation := c.l1.currentGeneration()
+	var data json.RawMessage
+	if err := c.l2.Get(ctx, key, &data); err != nil {
+		return err
+	}
+	c.l1.putIfUnchanged(key, data, c.now().Add(c.l1TTL), generation)
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("json unmarshal error: %w", err)
+	}
+	return nil
+}
+
+func (c *LayeredCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.set(ctx, key, value, ttl, nil)
+}
+
+func (c *LayeredCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	return c.set(ctx, key, value, ttl, tags)
+}
+
+func (c *LayeredCache) set(ctx context.Context, key string, value interface{}, ttl time.Duration, tags []string) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("json marshal error: %w", err)
+	}
+	c.l1.remove(key)
+	if len(tags) > 0 {
+		err = c.l2.SetWithTags(ctx, key, json.RawMessage(data), ttl, tags...)
+	} else {
+		err = c.l2.Set(ctx, key, json.RawMessage(data), ttl)
+	}
+	if err != nil {
+		return err
+	}
+
+	l1TTL := c.l1TTL
+	if ttl > 0 && ttl < l1TTL {
+		l1TTL = ttl
+	}
+	c.l1.put(key, data, c.now().Add(l1TTL))
+	c.broadcast(ctx, Invalidation{Keys: []string{key}})
+	return nil
+}
+
+func (c *LayeredCache) Delete(ctx context.Context, keys ...string) error {
+	c.l1.remove(keys...)
+	err := c.l2.Delete(ctx, keys...)
+	c.broadcast(ctx, Invalidation{Keys: keys})
+	return err
+}
+
+func (c *LayeredCache) DeletePattern(ctx context.Context, pattern string) error {
+	c.l1.removeMatching(pattern)
+	err := c.l2.DeletePattern(ctx, pattern)
+	c.broadcast(ctx, Invalidation{Pattern: pattern})
+	return err
+}
+
+func (c *LayeredCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	return c.l2.TaggedKeys(ctx, tags...)
+}
+
+// InvalidateTags looks the tagged keys up in the shared cache first, as the
+// in-process cache does not know the tags of the entries it read through
+func (c *LayeredCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	keys, err := c.l2.TaggedKeys(ctx, tags...)
+	if err != nil {
+		return err
+	}
+	c.l1.remove(keys...)
+	err = c.l2.InvalidateTags(ctx, tags...)
+	c.broadcast(ctx, Invalidation{Keys: keys})
+	return err
+}
+
+func (c *LayeredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if _, ok := c.l1.get(key, c.now()); ok {
+		return true, nil
+	}
+	return c.l2.Exists(ctx, key)
+}
+
+func (c *LayeredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.l2.TTL(ctx, key)
+}
+
+// MGet reads from the shared cache only
+func (c *LayeredCache) MGet(ctx context.Context, keys []string, dest interface{}) error {
+	return c.l2.MGet(ctx, keys, dest)
+}
+
+func (c *LayeredCache) MSet(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	c.l1.remove(keys...)
+	err := c.l2.MSet(ctx, items, ttl)
+	c.broadcast(ctx, Invalidation{Keys: keys})
+	return err
+}
+
+func (c *LayeredCache) Ping(ctx context.Context) error {
+	return c.l2.Ping(ctx)
+}
+
+// Close stops listening for invalidations and closes both the bus and the
+// shared cache
+func (c *LayeredCache) Close() error {
+	if c.bus != nil {
+		c.stop()
+		<-c.done
+		if err := c.bus.Close(); err != nil {
+			return err
+		}
+	}
+	return c.l2.Close()
+}
+
+// lru is a size-bounded map of JSON values that evicts the least recently
+// used entry first. generation counts removals, so a read from the shared
+// cache can tell whether its key was invalidated while it ran.
+type lru struct {
+	size int
+
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List // Most recently used first
+	generation uint64
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (l *lru) get(key string, now time.Time) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		l.order.Remove(el)
+		delete(l.items, key)
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (l *lru) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.generation
+}
+
+func (l *lru) put(key string, value []byte, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.putLocked(key, value, expiresAt)
+}
+
+// putIfUnchanged stores the value unless anything was removed since
+// generation was read
+func (l *lru) putIfUnchanged(key string, value []byte, expiresAt time.Time, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.generation == generation {
+		l.putLocked(key, value, expiresAt)
+	}
+}
+
+func (l *lru) putLocked(key string, value []byte, expiresAt time.Time) {
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		l.order.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (l *lru) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.order.Remove(el)
+			delete(l.items, key)
+		}
+	}
+}
+
+// removeMatching removes the keys matching a Redis glob pattern
+func (l *lru) removeMatching(pattern string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+	for key, el := range l.items {
+		if globMatch(pattern, key) {
+			l.order.Remove(el)
+			delete(l.items, key)
+		}
+	}
+}
+
+// globMatch reports whether key matches pattern, where * matches any run of
+// characters and ? any one character. Redis patterns may also hold
+// character classes; keys under such a pattern are all treated as matching,
+// which only drops more from the in-process cache than needed.
+func globMatch(pattern, key string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '[', '\\':
+			return true
+		case '*':
+			for j := len(key); j >= 0; j-- {
+				if globMatch(pattern[i+1:], key[j:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+			key = key[1:]
+		default:
+			if len(key) == 0 || key[0] != pattern[i] {
+				return false
+			}
+			key = key[1:]
+		}
+	}
+	return len(key) == 0
+}
+
+// redisInvalidationBus broadcasts invalidations over a Redis pub/sub channel
+type redisInvalidationBus struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewRedisInvalidationBus(config RedisConfig, channel string) (InvalidationBus, error) {
+	rdb, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &redisInvalidationBus{client: rdb, channel: channel}, nil
+}
+
+func (b *redisInvalidationBus) Publish(ctx context.Context, inv Invalidation) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return fmt.Errorf("json marshal error: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		return fmt.Errorf("redis publish error: %w", err)
+	}
+	return nil
+}
+
+func (b *redisInvalidationBus) Subscribe(ctx context.Context, handle func(Invalidation)) error {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("redis subscription to %s closed", b.channel)
+			}
+			var inv Invalidation
+			if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+				continue
+			}
+			handle(inv)
+		}
+	}
+}
+
+func (b *redisInvalidationBus) Close() error {
+	return b.client.Close()
+}
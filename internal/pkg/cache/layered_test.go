@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedCache stands in for Redis, keeping values as JSON and counting reads
+type sharedCache struct {
+	NoOpCache
+	mu     sync.Mutex
+	values map[string][]byte
+	tags   map[string][]string
+	reads  int
+}
+
+func (s *sharedCache) Get(ctx context.Context, key string, dest interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	data, ok := s.values[key]
+	if !ok {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *sharedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = data
+	return nil
+}
+
+func (s *sharedCache) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	return nil
+}
+
+func (s *sharedCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := s.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tags == nil {
+		s.tags = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		s.tags[tag] = append(s.tags[tag], key)
+	}
+	return nil
+}
+
+func (s *sharedCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for _, tag := range tags {
+		keys = append(keys, s.tags[tag]...)
+	}
+	return keys, nil
+}
+
+func (s *sharedCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	keys, _ := s.TaggedKeys(ctx, tags...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	for _, tag := range tags {
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+func (s *sharedCache) readCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads
+}
+
+// memoryBus delivers invalidations to every subscriber synchronously
+type memoryBus struct {
+	mu       sync.Mutex
+	handlers []func(Invalidation)
+}
+
+func (b *memoryBus) Publish(ctx context.Context, inv Invalidation) error {
+	b.mu.Lock()
+	handlers := append([]func(Invalidation){}, b.handlers...)
+	b.mu.Unlock()
+	for _, handle := range handlers {
+		handle(inv)
+	}
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context, handle func(Invalidation)) error {
+	b.mu.Lock()
+	b.handlers = append(b.handlers, handle)
+	b.mu.Unlock()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *memoryBus) Close() error { return nil }
+
+func (b *memoryBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers)
+}
+
+func TestLayeredCache(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("serves repeated reads from the process", func(t *testing.T) {
+		shared := &sharedCache{values: map[string][]byte{"stats:1": []byte(`{"average":4.5}`)}}
+		c := NewLayeredCache(shared, logger, WithL1TTL(5*time.Second))
+		now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		c.now = func() time.Time { return now }
+
+		var stats struct{ Average float64 }
+		for range 3 {
+			require.NoError(t, c.Get(ctx, "stats:1", &stats))
+		}
+		assert.Equal(t, 4.5, stats.Average)
+		assert.Equal(t, 1, shared.readCount())
+
+		now = now.Add(5 * time.Second)
+		require.NoError(t, c.Get(ctx, "stats:1", &stats))
+		assert.Equal(t, 2, shared.readCount(), "expired from the process")
+
+		assert.ErrorIs(t, c.Get(ctx, "stats:2", &stats), ErrCacheMiss)
+	})
+
+	t.Run("drops copies other instances overwrote", func(t *testing.T) {
+		shared := &sharedCache{values: make(map[string][]byte)}
+		bus := &memoryBus{}
+		a := NewLayeredCache(shared, logger, WithInvalidationBus(bus))
+		b := NewLayeredCache(shared, logger, WithInvalidationBus(bus))
+		require.Eventually(t, func() bool { return bus.subscribers() == 2 }, time.Second, time.Millisecond)
+
+		require.NoError(t, a.Set(ctx, "movie:1", "Heat", time.Minute))
+		var title string
+		require.NoError(t, b.Get(ctx, "movie:1", &title))
+		require.NoError(t, a.Get(ctx, "movie:1", &title))
+		assert.Equal(t, 1, shared.readCount(), "a kept its own write")
+
+		require.NoError(t, b.Set(ctx, "movie:1", "Heat (1995)", time.Minute))
+		require.NoError(t, a.Get(ctx, "movie:1", &title))
+		assert.Equal(t, "Heat (1995)", title)
+
+		require.NoError(t, b.Delete(ctx, "movie:1"))
+		assert.ErrorIs(t, a.Get(ctx, "movie:1", &title), ErrCacheMiss)
+
+		require.NoError(t, a.Close())
+		require.NoError(t, b.Close())
+	})
+
+	t.Run("drops tagged entries other instances read", func(t *testing.T) {
+		shared := &sharedCache{values: make(map[string][]byte)}
+		bus := &memoryBus{}
+		a := NewLayeredCache(shared, logger, WithInvalidationBus(bus))
+		b := NewLayeredCache(shared, logger, WithInvalidationBus(bus))
+		require.Eventually(t, func() bool { return bus.subscribers() == 2 }, time.Second, time.Millisecond)
+
+		require.NoError(t, a.SetWithTags(ctx, UserStatsKeyFunc("u1"), 1, time.Minute, UserTag("u1")))
+		require.NoError(t, a.SetWithTags(ctx, UserStatsKeyFunc("u2"), 2, time.Minute, UserTag("u2")))
+		var count int
+		require.NoError(t, b.Get(ctx, UserStatsKeyFunc("u1"), &count))
+		require.NoError(t, b.Get(ctx, UserStatsKeyFunc("u2"), &count))
+
+		require.NoError(t, a.InvalidateTags(ctx, UserTag("u1")))
+		assert.ErrorIs(t, b.Get(ctx, UserStatsKeyFunc("u1"), &count), ErrCacheMiss)
+		assert.ErrorIs(t, a.Get(ctx, UserStatsKeyFunc("u1"), &count), ErrCacheMiss)
+		require.NoError(t, b.Get(ctx, UserStatsKeyFunc("u2"), &count))
+		assert.Equal(t, 2, count)
+
+		require.NoError(t, a.Close())
+		require.NoError(t, b.Close())
+	})
+
+	t.Run("evicts the least recently used entries", func(t *testing.T) {
+		shared := &sharedCache{values: make(map[string][]byte)}
+		c := NewLayeredCache(shared, logger, WithL1Size(2))
+		for _, key := range []string{"a", "b"} {
+			require.NoError(t, c.Set(ctx, key, key, time.Minute))
+		}
+		var value string
+		require.NoError(t, c.Get(ctx, "a", &value))
+		require.NoError(t, c.Set(ctx, "c", "c", time.Minute))
+
+		require.NoError(t, c.Get(ctx, "a", &value))
+		assert.Equal(t, 0, shared.readCount())
+		require.NoError(t, c.Get(ctx, "b", &value))
+		assert.Equal(t, 1, shared.readCount(), "b was evicted")
+	})
+
+	t.Run("drops keys matching a deleted pattern", func(t *testing.T) {
+		shared := &sharedCache{values: make(map[string][]byte)}
+		c := NewLayeredCache(shared, logger)
+		require.NoError(t, c.Set(ctx, "user_stats:1", 1, time.Minute))
+		require.NoError(t, c.Set(ctx, "movie:1", 1, time.Minute))
+
+		require.NoError(t, c.DeletePattern(ctx, "user_*"))
+		_, ok := c.l1.get("user_stats:1", time.Now())
+		assert.False(t, ok)
+		_, ok = c.l1.get("movie:1", time.Now())
+		assert.True(t, ok)
+	})
+}
+
+func TestGlobMatch(t *testing.T) {
+	assert.True(t, globMatch("user_wrapped:u1:*", "user_wrapped:u1:2024"))
+	assert.True(t, globMatch("*:stats", "movie:1:stats"))
+	assert.True(t, globMatch("movie:?", "movie:1"))
+	assert.False(t, globMatch("movie:?", "movie:12"))
+	assert.False(t, globMatch("user_*", "movie:1"))
+	assert.True(t, globMatch("movie:[12]", "movie:3"), "character classes match broadly")
+}
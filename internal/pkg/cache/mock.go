@@ -32,6 +32,22 @@ func (m *MockCache) DeletePattern(ctx context.Context, pattern string) error {
 	return args.Error(0)
 }
 
+func (m *MockCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	args := m.Called(ctx, key, value, ttl, tags)
+	return args.Error(0)
+}
+
+func (m *MockCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	args := m.Called(ctx, tags)
+	keys, _ := args.Get(0).([]string)
+	return keys, args.Error(1)
+}
+
+func (m *MockCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	args := m.Called(ctx, tags)
+	return args.Error(0)
+}
+
 func (m *MockCache) Exists(ctx context.Context, key string) (bool, error) {
 	args := m.Called(ctx, key)
 	return args.Bool(0), args.Error(1)
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Recent is a member of a recents list and when it was last added
+type Recent struct {
+	Member string
+	At     time.Time
+}
+
+// Recents are short lists of the members most recently added to them,
+// e.g. the movies a user viewed, shared by every instance of the service
+// when kept in Redis
+type Recents interface {
+	// Add puts member at the front of the list at key, or moves it there,
+	// and drops all but the keep most recent members. The list expires ttl
+	// after the last add.
+	Add(ctx context.Context, key, member string, at time.Time, keep int, ttl time.Duration) error
+	// Latest returns up to n members of the list at key, most recent first
+	Latest(ctx context.Context, key string, n int) ([]Recent, error)
+	Close() error
+}
+
+// redisRecents keeps each list in a sorted set scored by the time in
+// milliseconds
+type redisRecents struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisRecents(config RedisConfig, prefix string) (Recents, error) {
+	rdb, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &redisRecents{client: rdb, prefix: prefix}, nil
+}
+
+func (r *redisRecents) getKey(key string) string {
+	if r.prefix == "" {
+		return key
+	}
+	return fmt.Sprintf("%s:%s", r.prefix, key)
+}
+
+func (r *redisRecents) Add(ctx context.Context, key, member string, at time.Time, keep int, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.ZAdd(ctx, r.getKey(key), redis.Z{Score: float64(at.UnixMilli()), Member: member})
+	pipe.ZRemRangeByRank(ctx, r.getKey(key), 0, int64(-keep-1))
+	pipe.Expire(ctx, r.getKey(key), ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis zadd error: %w", err)
+	}
+	return nil
+}
+
+func (r *redisRecents) Latest(ctx context.Context, key string, n int) ([]Recent, error) {
+	if n <= 0 {
+		return []Recent{}, nil
+	}
+	members, err := r.client.ZRevRangeWithScores(ctx, r.getKey(key), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis zrevrange error: %w", err)
+	}
+
+	recents := make([]Recent, 0, len(members))
+	for _, z := range members {
+		member, ok := z.Member.(string)
+		if !ok {
+			return nil, fmt.Errorf("redis zrevrange: unexpected member %v", z.Member)
+		}
+		recents = append(recents, Recent{Member: member, At: time.UnixMilli(int64(z.Score)).UTC()})
+	}
+	return recents, nil
+}
+
+func (r *redisRecents) Close() error {
+	return r.client.Close()
+}
+
+type memoryRecentList struct {
+	members   map[string]time.Time
+	expiresAt time.Time
+}
+
+// MemoryRecents keeps recents lists in the process, for a single instance
+// or environments without Redis
+type MemoryRecents struct {
+	now func() time.Time
+
+	mu    sync.Mutex
+	lists map[string]*memoryRecentList
+}
+
+func NewMemoryRecents() *MemoryRecents {
+	return &MemoryRecents{
+		now:   time.Now,
+		lists: make(map[string]*memoryRecentList),
+	}
+}
+
+func (m *MemoryRecents) Add(ctx context.Context, key, member string, at time.Time, keep int, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := m.list(key)
+	if list == nil {
+		list = &memoryRecentList{members: make(map[string]time.Time)}
+		m.lists[key] = list
+	}
+	list.members[member] = at
+	list.expiresAt = m.now().Add(ttl)
+
+	for _, dropped := range sortRecents(list.members)[min(keep, len(list.members)):] {
+		delete(list.members, dropped.Member)
+	}
+	return nil
+}
+
+func (m *MemoryRecents) Latest(ctx context.Context, key string, n int) ([]Recent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := m.list(key)
+	if list == nil || n <= 0 {
+		return []Recent{}, nil
+	}
+	recents := sortRecents(list.members)
+	return recents[:min(n, len(recents))], nil
+}
+
+// list returns the list at key unless it is missing or has expired. The
+// caller must hold m.mu.
+func (m *MemoryRecents) list(key string) *memoryRecentList {
+	list, ok := m.lists[key]
+	if !ok {
+		return nil
+	}
+	if !m.now().Before(list.expiresAt) {
+		delete(m.lists, key)
+		return nil
+	}
+	return list
+}
+
+func (m *MemoryRecents) Close() error {
+	return nil
+}
+
+// sortRecents orders members most recent first, like a sorted set read in
+// reverse
+func sortRecents(members map[string]time.Time) []Recent {
+	recents := make([]Recent, 0, len(members))
+	for member, at := range members {
+		recents = append(recents, Recent{Member: member, At: at})
+	}
+	sort.Slice(recents, func(i, j int) bool {
+		if !recents[i].At.Equal(recents[j].At) {
+			return recents[i].At.After(recents[j].At)
+		}
+		return recents[i].Member > recents[j].Member
+	})
+	return recents
+}
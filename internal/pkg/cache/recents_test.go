@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/testenv"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRecents(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	recents := NewMemoryRecents()
+	recents.now = func() time.Time { return now }
+
+	require.NoError(t, recents.Add(ctx, "viewed", "a", now, 2, time.Hour))
+	require.NoError(t, recents.Add(ctx, "viewed", "b", now.Add(time.Second), 2, time.Hour))
+	require.NoError(t, recents.Add(ctx, "viewed", "a", now.Add(2*time.Second), 2, time.Hour))
+
+	latest, err := recents.Latest(ctx, "viewed", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Recent{{"a", now.Add(2 * time.Second)}, {"b", now.Add(time.Second)}}, latest, "adding again moves to the front")
+
+	require.NoError(t, recents.Add(ctx, "viewed", "c", now.Add(3*time.Second), 2, time.Hour))
+	latest, _ = recents.Latest(ctx, "viewed", 10)
+	assert.Equal(t, []Recent{{"c", now.Add(3 * time.Second)}, {"a", now.Add(2 * time.Second)}}, latest, "only the most recent are kept")
+
+	latest, _ = recents.Latest(ctx, "viewed", 1)
+	assert.Len(t, latest, 1)
+
+	now = now.Add(time.Hour)
+	latest, err = recents.Latest(ctx, "viewed", 10)
+	require.NoError(t, err)
+	assert.Empty(t, latest, "the list expires ttl after the last add")
+}
+
+func TestRedisRecents(t *testing.T) {
+	ctx := context.Background()
+	host, port := testenv.Redis(t)
+	recents, err := NewRedisRecents(RedisConfig{Host: host, Port: port}, t.Name())
+	require.NoError(t, err)
+	defer recents.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, recents.Add(ctx, "viewed", "a", now, 2, time.Minute))
+	require.NoError(t, recents.Add(ctx, "viewed", "b", now.Add(time.Second), 2, time.Minute))
+	require.NoError(t, recents.Add(ctx, "viewed", "c", now.Add(2*time.Second), 2, time.Minute))
+
+	latest, err := recents.Latest(ctx, "viewed", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Recent{{"c", now.Add(2 * time.Second)}, {"b", now.Add(time.Second)}}, latest)
+}
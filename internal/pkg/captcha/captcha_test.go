@@ -0,0 +1,41 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PostForm.Get("response") {
+		case "good":
+			w.Write([]byte(`{"success": true}`))
+		case "broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}
+	}))
+	defer server.Close()
+
+	verifier := NewSiteVerifier(server.URL, "secret")
+	ctx := context.Background()
+
+	assert.NoError(t, verifier.Verify(ctx, "good", "203.0.113.7"))
+	assert.ErrorIs(t, verifier.Verify(ctx, "forged", "203.0.113.7"), ErrInvalidToken)
+	assert.ErrorIs(t, verifier.Verify(ctx, " ", "203.0.113.7"), ErrMissingToken)
+
+	err := verifier.Verify(ctx, "broken", "203.0.113.7")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,279 @@
+// Package encryption provides application-level envelope encryption for
+// sensitive column values.
+//
+// Every value is sealed with its own random data key, and the data key is
+// wrapped by a named key-encryption key from a Keyring. Rotating the active
+// key therefore only rewraps the small data key; the value itself is never
+// re-encrypted. Encrypted values are self-describing strings of the form
+//
+//	enc:v1:<key id>:<wrapped data key>:<ciphertext>
+//
+// so rows written under older keys, or not yet encrypted at all, can live
+// side by side during a rotation or backfill.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length of key-encryption, data and index keys (AES-256)
+const KeySize = 32
+
+const prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey    = errors.New("encryption key not found")
+	ErrMalformed     = errors.New("malformed encrypted value")
+	ErrDecryptFailed = errors.New("unable to decrypt value")
+	ErrNoIndexKey    = errors.New("no blind index key configured")
+)
+
+var encoding = base64.RawURLEncoding
+
+// Keyring holds the key-encryption keys by ID. New values are always
+// wrapped with the active key; the others are kept to read older values.
+type Keyring struct {
+	active   string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// Option configures a Keyring
+type Option func(*Keyring)
+
+// WithIndexKey sets the HMAC key used by BlindIndex. It must stay the same
+// across key rotations, or existing indexes stop matching.
+func WithIndexKey(key []byte) Option {
+	return func(k *Keyring) {
+		k.indexKey = key
+	}
+}
+
+// NewKeyring creates a keyring that encrypts with keys[active]. Every key
+// must be KeySize bytes long.
+func NewKeyring(active string, keys map[string][]byte, opts ...Option) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active key %q: %w", active, ErrUnknownKey)
+	}
+
+	k := &Keyring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k, nil
+}
+
+// ParseKeys decodes "id:base64key" entries, as read from configuration
+func ParseKeys(entries []string) (map[string][]byte, error) {
+	keys := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key entry must be id:base64key")
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("key %q is configured twice", id)
+		}
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are wrapped with
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped by the active key
+func (k *Keyring) Encrypt(plaintext []byte) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(data, plaintext, nil)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+	return format(k.active, wrapped, ciphertext), nil
+}
+
+// EncryptString is Encrypt for string values
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	return k.Encrypt([]byte(plaintext))
+}
+
+// Decrypt opens a value produced by Encrypt with whichever key wrapped it
+func (k *Keyring) Decrypt(value string) ([]byte, error) {
+	id, wrapped, ciphertext, err := parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(data, ciphertext, nil)
+}
+
+// DecryptString is Decrypt for string values
+func (k *Keyring) DecryptString(value string) (string, error) {
+	plaintext, err := k.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value is not yet encrypted or was wrapped
+// by a key other than the active one
+func (k *Keyring) NeedsRotation(value string) bool {
+	id, _, _, err := parse(value)
+	return err != nil || id != k.active
+}
+
+// Rewrap moves value onto the active key by rewrapping its data key; the
+// ciphertext itself is kept as is
+func (k *Keyring) Rewrap(value string) (string, error) {
+	id, wrapped, ciphertext, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	if id == k.active {
+		return value, nil
+	}
+
+	dataKey, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+	return format(k.active, rewrapped, ciphertext), nil
+}
+
+// BlindIndex returns a deterministic keyed hash of value, so encrypted
+// columns can still be looked up by equality
+func (k *Keyring) BlindIndex(value string) (string, error) {
+	if len(k.indexKey) == 0 {
+		return "", ErrNoIndexKey
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// IsEncrypted reports whether value looks like the output of Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyPrefix returns the prefix shared by every value wrapped with key id,
+// for finding rows that still need rotating
+func KeyPrefix(id string) string {
+	return prefix + id + ":"
+}
+
+func (k *Keyring) unwrap(id string, wrapped []byte) ([]byte, error) {
+	kek, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %q: %w", id, ErrUnknownKey)
+	}
+	return open(kek, wrapped, []byte(id))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext with a random nonce, which is prepended to the
+// result
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrDecryptFailed
+	}
+	return plaintext, nil
+}
+
+func format(id string, wrapped, ciphertext []byte) string {
+	return prefix + id + ":" + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(ciphertext)
+}
+
+func parse(value string) (id string, wrapped, ciphertext []byte, err error) {
+	if !IsEncrypted(value) {
+		return "", nil, nil, ErrMalformed
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, ErrMalformed
+	}
+	if wrapped, err = encoding.DecodeString(parts[1]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	if ciphertext, err = encoding.DecodeString(parts[2]); err != nil {
+		return "", nil, nil, ErrMalformed
+	}
+	return parts[0], wrapped, ciphertext, nil
+}
@@ -0,0 +1,140 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	first, err := keyring.EncryptString("jane@example.com")
+	require.NoError(t, err)
+	second, err := keyring.EncryptString("jane@example.com")
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(first))
+	assert.True(t, strings.HasPrefix(first, KeyPrefix("k1")))
+	assert.NotEqual(t, first, second, "each value gets its own data key and nonce")
+	assert.NotContains(t, first, "jane")
+
+	plaintext, err := keyring.DecryptString(first)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", plaintext)
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	value, err := old.EncryptString("secret")
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	require.NoError(t, err)
+
+	// Values under the retired key stay readable until rewrapped
+	assert.True(t, rotated.NeedsRotation(value))
+	plaintext, err := rotated.DecryptString(value)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	rewrapped, err := rotated.Rewrap(value)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsRotation(rewrapped))
+	assert.Equal(t, value[strings.LastIndex(value, ":"):], rewrapped[strings.LastIndex(rewrapped, ":"):], "the ciphertext is not re-encrypted")
+
+	// Once the old key is dropped only the rewrapped value can be read
+	current, err := NewKeyring("k2", map[string][]byte{"k2": testKey(2)})
+	require.NoError(t, err)
+	_, err = current.Decrypt(value)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	plaintext, err = current.DecryptString(rewrapped)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	// Plaintext left over from before encryption also needs rotating
+	assert.True(t, current.NeedsRotation("jane@example.com"))
+}
+
+func TestKeyringRejectsTampering(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	value, err := keyring.EncryptString("secret")
+	require.NoError(t, err)
+
+	flipped := byte('A')
+	if value[len(value)-10] == 'A' {
+		flipped = 'B'
+	}
+	tampered := value[:len(value)-10] + string(flipped) + value[len(value)-9:]
+	_, err = keyring.Decrypt(tampered)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+
+	_, err = keyring.Decrypt("enc:v1:k1:not-enough-parts")
+	assert.ErrorIs(t, err, ErrMalformed)
+	_, err = keyring.Decrypt("plain")
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	// A wrapped data key cannot be replayed under another key ID
+	other, err := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(1)})
+	require.NoError(t, err)
+	_, err = other.Decrypt(strings.Replace(value, KeyPrefix("k1"), KeyPrefix("k2"), 1))
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+}
+
+func TestBlindIndex(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+	_, err = keyring.BlindIndex("jane@example.com")
+	assert.ErrorIs(t, err, ErrNoIndexKey)
+
+	keyring, err = NewKeyring("k1", map[string][]byte{"k1": testKey(1)}, WithIndexKey(testKey(9)))
+	require.NoError(t, err)
+	first, err := keyring.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	second, err := keyring.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	other, err := keyring.BlindIndex("john@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+	assert.Len(t, first, 64)
+}
+
+func TestNewKeyringValidation(t *testing.T) {
+	_, err := NewKeyring("missing", map[string][]byte{"k1": testKey(1)})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = NewKeyring("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+
+	_, err = NewKeyring("a:b", map[string][]byte{"a:b": testKey(1)})
+	assert.Error(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey(1))
+
+	keys, err := ParseKeys([]string{"k1:" + encoded, " ", "k2:" + encoded})
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, testKey(1), keys["k1"])
+
+	_, err = ParseKeys([]string{"no-separator"})
+	assert.Error(t, err)
+	_, err = ParseKeys([]string{"k1:!!!"})
+	assert.Error(t, err)
+	_, err = ParseKeys([]string{"k1:" + encoded, "k1:" + encoded})
+	assert.Error(t, err)
+}
@@ -2,6 +2,58 @@ package errors
 
 import "net/http"
 
+// ErrorCode is a machine-readable identifier for an error condition.
+// Clients should branch on the code rather than on the human-readable message.
+type ErrorCode string
+
+const (
+	CodeBadRequest         ErrorCode = "BAD_REQUEST"
+	CodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	CodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	CodeForbidden          ErrorCode = "FORBIDDEN"
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeConflict           ErrorCode = "CONFLICT"
+	CodeGone               ErrorCode = "GONE"
+	CodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+	CodePayloadTooLarge    ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeTooManyRequests    ErrorCode = "TOO_MANY_REQUESTS"
+	CodeInternal           ErrorCode = "INTERNAL_ERROR"
+	CodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+	// CodeReauthenticationRequired asks the client to sign the user in again
+	// before a sensitive action
+	CodeReauthenticationRequired ErrorCode = "REAUTHENTICATION_REQUIRED"
+)
+
+// CodeForStatus maps an HTTP status code to its default ErrorCode
+func CodeForStatus(statusCode int) ErrorCode {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeServiceUnavailable
+	default:
+		return CodeInternal
+	}
+}
+
 type AppError struct {
 	Message    string `json:"message"`
 	StatusCode int    `json:"-"`
@@ -16,7 +68,7 @@ func NewBadRequestError(message string) *AppError {
 	return &AppError{
 		Message:    message,
 		StatusCode: http.StatusBadRequest,
-		Code:       "BAD_REQUEST",
+		Code:       string(CodeBadRequest),
 	}
 }
 
@@ -24,7 +76,7 @@ func NewConflictError(message string) *AppError {
 	return &AppError{
 		Message:    message,
 		StatusCode: http.StatusConflict,
-		Code:       "CONFLICT",
+		Code:       string(CodeConflict),
 	}
 }
 
@@ -32,7 +84,7 @@ func NewInternalError(message string) *AppError {
 	return &AppError{
 		Message:    message,
 		StatusCode: http.StatusInternalServerError,
-		Code:       "INTERNAL_ERROR",
+		Code:       string(CodeInternal),
 	}
 }
 
@@ -40,6 +92,46 @@ func NewNotFoundError(message string) *AppError {
 	return &AppError{
 		Message:    message,
 		StatusCode: http.StatusNotFound,
-		Code:       "NOT_FOUND",
+		Code:       string(CodeNotFound),
+	}
+}
+
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusUnauthorized,
+		Code:       string(CodeUnauthorized),
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusForbidden,
+		Code:       string(CodeForbidden),
+	}
+}
+
+func NewGoneError(message string) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusGone,
+		Code:       string(CodeGone),
+	}
+}
+
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusPreconditionFailed,
+		Code:       string(CodePreconditionFailed),
+	}
+}
+
+func NewPayloadTooLargeError(message string) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Code:       string(CodePayloadTooLarge),
 	}
 }
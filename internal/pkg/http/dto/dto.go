@@ -0,0 +1,97 @@
+// Package dto checks the JSON policy of the transport DTOs that handlers
+// decode requests into and encode responses from. The DTOs are the public
+// API: every member is named explicitly, and none of them hold a domain or
+// service struct, whose fields would otherwise reach clients, or be set by
+// them, as soon as they were added. Handlers map between their DTOs and the
+// service types, and each handler package tests its DTOs with Check.
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// internalPackages are the layers whose structs must not appear in a DTO
+var internalPackages = []string{
+	"thermondo/internal/domain/",
+	"thermondo/internal/platform/service/",
+}
+
+var (
+	marshalerType   = reflect.TypeFor[json.Marshaler]()
+	unmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+)
+
+// Check returns the policy violations of the types of values, one per field
+// and rule broken, e.g. "ratings.CreateRatingRequest.UserID: no json tag".
+// A DTO breaks the policy when an exported field has no json tag, when a
+// field holds an interface, or when it holds a struct from the domain or
+// service layers, directly or through pointers, slices and maps. Types that
+// encode themselves, such as time.Time, are not looked into.
+func Check(values ...any) []string {
+	var violations []string
+	seen := make(map[reflect.Type]bool)
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		violations = append(violations, checkType(t, t.String(), seen)...)
+	}
+	return violations
+}
+
+func checkType(t reflect.Type, path string, seen map[reflect.Type]bool) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if t.Kind() == reflect.Map {
+			if violations := checkType(t.Key(), path, seen); len(violations) > 0 {
+				return violations
+			}
+		}
+		t = t.Elem()
+	}
+
+	switch {
+	case t.Kind() == reflect.Interface:
+		return []string{fmt.Sprintf("%s: holds %s, which can encode anything", path, t)}
+	case t.Kind() != reflect.Struct:
+		return nil
+	case isInternal(t):
+		return []string{fmt.Sprintf("%s: holds %s, map it to a DTO", path, t)}
+	case encodesItself(t) || seen[t]:
+		return nil
+	}
+	seen[t] = true
+
+	var violations []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := path + "." + field.Name
+		tag, tagged := field.Tag.Lookup("json")
+		if tag == "-" {
+			continue
+		}
+		if !tagged && !field.Anonymous {
+			violations = append(violations, fieldPath+": no json tag")
+		}
+		violations = append(violations, checkType(field.Type, fieldPath, seen)...)
+	}
+	return violations
+}
+
+func isInternal(t reflect.Type) bool {
+	for _, prefix := range internalPackages {
+		if strings.HasPrefix(t.PkgPath()+"/", prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodesItself(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	return t.Implements(marshalerType) || ptr.Implements(marshalerType) ||
+		t.Implements(unmarshalerType) || ptr.Implements(unmarshalerType)
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/mergepatch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMovie struct {
+	ID        movies.MovieID                   `json:"id"`
+	Title     string                           `json:"title"`
+	Tags      []string                         `json:"tags,omitempty"`
+	Ratings   map[string]int64                 `json:"ratings"`
+	Released  *time.Time                       `json:"released,omitempty"`
+	Poster    mergepatch.Field[string]         `json:"poster"`
+	Secret    string                           `json:"-"`
+	Nested    []testCredit                     `json:"credits"`
+	ByCountry map[string][]*testCredit         `json:"by_country"`
+	Patches   map[string]mergepatch.Field[int] `json:"patches"`
+	internal  string
+}
+
+type testCredit struct {
+	Name string `json:"name"`
+}
+
+type testEmbedding struct {
+	testCredit
+	Role string `json:"role"`
+}
+
+type testLeaky struct {
+	Title   string
+	Movie   *movies.Movie           `json:"movie"`
+	Movies  []movies.Movie          `json:"movies"`
+	ByID    map[string]movies.Movie `json:"by_id"`
+	Extra   any                     `json:"extra"`
+	Extras  map[string]any          `json:"extras"`
+	Credits []testLeakyCredit       `json:"credits"`
+}
+
+type testLeakyCredit struct {
+	Name string
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("accepts explicit DTOs", func(t *testing.T) {
+		assert.Empty(t, Check(testMovie{internal: "x"}, &testEmbedding{}, []testCredit{}))
+	})
+
+	t.Run("reports every violation", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"dto.testLeaky.Title: no json tag",
+			"dto.testLeaky.Movie: holds movies.Movie, map it to a DTO",
+			"dto.testLeaky.Movies: holds movies.Movie, map it to a DTO",
+			"dto.testLeaky.ByID: holds movies.Movie, map it to a DTO",
+			"dto.testLeaky.Extra: holds interface {}, which can encode anything",
+			"dto.testLeaky.Extras: holds interface {}, which can encode anything",
+			"dto.testLeaky.Credits.Name: no json tag",
+		}, Check(testLeaky{}))
+	})
+
+	t.Run("rejects domain types passed directly", func(t *testing.T) {
+		assert.Equal(t, []string{"*movies.Movie: holds movies.Movie, map it to a DTO"}, Check(&movies.Movie{}))
+	})
+}
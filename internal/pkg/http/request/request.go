@@ -0,0 +1,167 @@
+// Package request decodes JSON request bodies strictly. A body must be a
+// single JSON object within a size limit, no deeper than MaxDepth, without
+// duplicate members, and with only the members the target struct declares,
+// so oversized or crafted payloads are rejected before a handler sees them.
+package request
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+const (
+	// DefaultMaxBytes caps a JSON body unless the route sets its own limit
+	DefaultMaxBytes int64 = 64 << 10
+	// MaxDepth caps how deeply objects and arrays may be nested
+	MaxDepth = 32
+)
+
+var (
+	ErrEmptyBody      = errors.New("request body is empty")
+	ErrNotAnObject    = errors.New("request body must be a JSON object")
+	ErrTrailingData   = errors.New("request body must hold a single JSON object")
+	ErrTooDeep        = fmt.Errorf("JSON must not be nested deeper than %d levels", MaxDepth)
+	ErrUnknownField   = errors.New("unknown field")
+	ErrDuplicateField = errors.New("duplicate field")
+)
+
+type options struct {
+	maxBytes int64
+}
+
+type Option func(*options)
+
+// WithMaxBytes overrides DefaultMaxBytes for a route
+func WithMaxBytes(n int64) Option {
+	return func(o *options) {
+		o.maxBytes = n
+	}
+}
+
+// DecodeJSON decodes the body of r into dst with Decode, reading no more
+// than the route's limit. Bodies over the limit are a 413, anything else
+// Decode rejects is a 400 whose message says what was wrong.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any, opts ...Option) *appErrors.AppError {
+	o := options{maxBytes: DefaultMaxBytes}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := Decode(http.MaxBytesReader(w, r.Body, o.maxBytes), dst)
+	if err == nil {
+		return nil
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return appErrors.NewPayloadTooLargeError(fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit))
+	}
+	return appErrors.NewBadRequestError("Invalid JSON payload: " + err.Error())
+}
+
+// Decode reads one JSON object from r into dst, rejecting members dst does
+// not declare so typos do not silently become no-ops
+func Decode(r io.Reader, dst any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if err := checkStructure(body); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		// encoding/json has no error type for unknown fields
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("%w %s", ErrUnknownField, field)
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return fmt.Errorf("field %q must not be a %s", typeErr.Field, typeErr.Value)
+		}
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// frame is an object or array being scanned by checkStructure
+type frame struct {
+	object bool
+	// wantKey is set while the next token of an object is a member name
+	wantKey bool
+	keys    map[string]struct{}
+}
+
+// checkStructure walks the tokens of body, which must be exactly one
+// object, before anything is allocated for its values. encoding/json
+// would otherwise keep the last of duplicate members and recurse as deep
+// as the document goes.
+func checkStructure(body []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	first, err := decoder.Token()
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrEmptyBody
+	case err != nil:
+		return fmt.Errorf("invalid JSON: %w", err)
+	case first != json.Delim('{'):
+		return ErrNotAnObject
+	}
+
+	stack := []frame{{object: true, wantKey: true, keys: map[string]struct{}{}}}
+	for len(stack) > 0 {
+		token, err := decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		top := &stack[len(stack)-1]
+		if key, ok := token.(string); ok && top.wantKey {
+			if _, seen := top.keys[key]; seen {
+				return fmt.Errorf("%w %q", ErrDuplicateField, key)
+			}
+			top.keys[key] = struct{}{}
+			top.wantKey = false
+			continue
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			if len(stack) >= MaxDepth {
+				return ErrTooDeep
+			}
+			object := token == json.Delim('{')
+			next := frame{object: object, wantKey: object}
+			if object {
+				next.keys = map[string]struct{}{}
+			}
+			stack = append(stack, next)
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				parent := &stack[len(stack)-1]
+				parent.wantKey = parent.object
+			}
+		default:
+			top.wantKey = top.object
+		}
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return ErrTrailingData
+	}
+	return nil
+}
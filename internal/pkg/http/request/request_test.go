@@ -0,0 +1,77 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	Title  string         `json:"title"`
+	Year   int            `json:"year"`
+	Tags   []string       `json:"tags"`
+	Extras map[string]any `json:"extras"`
+}
+
+func TestDecode(t *testing.T) {
+	var req testRequest
+	require.NoError(t, Decode(strings.NewReader(`{"title": "Alien", "year": 1979, "tags": ["horror"], "extras": {"a": {"title": 1}}}`), &req))
+
+	assert.Equal(t, "Alien", req.Title)
+	assert.Equal(t, 1979, req.Year)
+	assert.Equal(t, []string{"horror"}, req.Tags)
+}
+
+func TestDecodeRejectsInvalidDocuments(t *testing.T) {
+	deep := strings.Repeat(`{"a":`, MaxDepth) + `1` + strings.Repeat(`}`, MaxDepth)
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"empty body":          {``, "request body is empty"},
+		"array":               {`[{"title": "Alien"}]`, "request body must be a JSON object"},
+		"null document":       {`null`, "request body must be a JSON object"},
+		"malformed":           {`{"title": "Alien"`, "invalid JSON: unexpected EOF"},
+		"trailing document":   {`{"title": "Alien"} {"title": "Aliens"}`, "request body must hold a single JSON object"},
+		"unknown field":       {`{"titel": "Alien"}`, `unknown field "titel"`},
+		"duplicate field":     {`{"title": "Alien", "year": 1979, "title": "Aliens"}`, `duplicate field "title"`},
+		"nested duplicate":    {`{"extras": {"a": [{"b": 1, "b": 2}]}}`, `duplicate field "b"`},
+		"too deep":            {`{"extras": ` + deep + `}`, "JSON must not be nested deeper than 32 levels"},
+		"wrong type":          {`{"year": "1979"}`, `field "year" must not be a string`},
+		"wrong type in array": {`{"tags": [1]}`, `field "tags.0" must not be a number`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var req testRequest
+			err := Decode(strings.NewReader(tt.body), &req)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	decode := func(body string, opts ...Option) error {
+		r := httptest.NewRequest(http.MethodPost, "/movies", strings.NewReader(body))
+		var req testRequest
+		if appErr := DecodeJSON(httptest.NewRecorder(), r, &req, opts...); appErr != nil {
+			return appErr
+		}
+		return nil
+	}
+
+	err := decode(`{"title": "Alien"}`)
+	assert.NoError(t, err)
+
+	err = decode(`{"title": "Alien", "director": "Ridley Scott"}`)
+	require.Error(t, err)
+	assert.Equal(t, `Invalid JSON payload: unknown field "director"`, err.Error())
+
+	err = decode(`{"title": "`+strings.Repeat("a", 100)+`"}`, WithMaxBytes(64))
+	require.Error(t, err)
+	assert.Equal(t, "Request body must not exceed 64 bytes", err.Error())
+}
@@ -0,0 +1,32 @@
+package response
+
+import "net/http"
+
+// instanceWriter carries the path of a request to the writers that are
+// only given its http.ResponseWriter
+type instanceWriter struct {
+	http.ResponseWriter
+	instance string
+}
+
+func (w *instanceWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithInstance returns resp carrying the instance, the request path, of
+// the problems written to it
+func WithInstance(resp http.ResponseWriter, instance string) http.ResponseWriter {
+	return &instanceWriter{ResponseWriter: resp, instance: instance}
+}
+
+// instanceOf returns the path of req, or the instance resp carries when req
+// is nil, looking through the writers wrapping it
+func instanceOf(resp http.ResponseWriter, req *http.Request) string {
+	if req != nil {
+		return req.URL.Path
+	}
+	if w, ok := wrapped[*instanceWriter](resp); ok {
+		return w.instance
+	}
+	return ""
+}
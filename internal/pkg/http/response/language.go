@@ -0,0 +1,51 @@
+package response
+
+import (
+	"net/http"
+	"thermondo/internal/pkg/i18n"
+)
+
+// languageWriter carries the language of a request to the writers that
+// are only given its http.ResponseWriter
+type languageWriter struct {
+	http.ResponseWriter
+	language string
+}
+
+func (w *languageWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithLanguage returns resp carrying the language errors written to it are
+// translated into
+func WithLanguage(resp http.ResponseWriter, language string) http.ResponseWriter {
+	return &languageWriter{ResponseWriter: resp, language: language}
+}
+
+// languageOf returns the language of req, or the one resp carries when req
+// is nil, looking through the writers wrapping it
+func languageOf(resp http.ResponseWriter, req *http.Request) string {
+	if req != nil {
+		return i18n.LanguageFrom(req.Context())
+	}
+	if w, ok := wrapped[*languageWriter](resp); ok {
+		return w.language
+	}
+	return i18n.DefaultLanguage
+}
+
+// wrapped returns the writer of type W among resp and the writers it wraps
+func wrapped[W http.ResponseWriter](resp http.ResponseWriter) (W, bool) {
+	for resp != nil {
+		if w, ok := resp.(W); ok {
+			return w, true
+		}
+		unwrapper, ok := resp.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		resp = unwrapper.Unwrap()
+	}
+	var none W
+	return none, false
+}
@@ -0,0 +1,65 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+// ProblemTypeBase is the URI prefix used to build problem type identifiers
+const ProblemTypeBase = "https://problems.thermondo.de/"
+
+// ErrorFormat selects the wire shape used for error responses
+type ErrorFormat int32
+
+const (
+	// ErrorFormatProblem renders errors as RFC 7807 application/problem+json
+	ErrorFormatProblem ErrorFormat = iota
+	// ErrorFormatLegacy renders errors as {"error": "..."}
+	ErrorFormatLegacy
+)
+
+var defaultErrorFormat atomic.Int32
+
+// SetDefaultErrorFormat sets the error format used by writers created afterwards
+func SetDefaultErrorFormat(format ErrorFormat) {
+	defaultErrorFormat.Store(int32(format))
+}
+
+// DefaultErrorFormat returns the process-wide default error format
+func DefaultErrorFormat() ErrorFormat {
+	return ErrorFormat(defaultErrorFormat.Load())
+}
+
+// Problem is an RFC 7807 problem details document extended with a
+// machine-readable error code
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     appErrors.ErrorCode `json:"code"`
+	Extra    map[string]any      `json:"extra,omitempty"`
+}
+
+// NewProblem builds a problem for the given status and code
+func NewProblem(status int, code appErrors.ErrorCode, detail string) *Problem {
+	return &Problem{
+		Type:   ProblemTypeBase + strings.ToLower(strings.ReplaceAll(string(code), "_", "-")),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// WithExtra attaches additional members to the problem document
+func (p *Problem) WithExtra(key string, value any) *Problem {
+	if p.Extra == nil {
+		p.Extra = make(map[string]any)
+	}
+	p.Extra[key] = value
+	return p
+}
@@ -4,18 +4,39 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/i18n"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
 )
 
 type Writer struct {
-	logger *slog.Logger
+	logger      *slog.Logger
+	errorFormat ErrorFormat
 }
 
-func NewWriter(logger *slog.Logger) *Writer {
-	return &Writer{logger: logger}
+type WriterOption func(*Writer)
+
+// WithErrorFormat overrides the process-wide default error format for this writer
+func WithErrorFormat(format ErrorFormat) WriterOption {
+	return func(w *Writer) {
+		w.errorFormat = format
+	}
+}
+
+func NewWriter(logger *slog.Logger, opts ...WriterOption) *Writer {
+	w := &Writer{logger: logger, errorFormat: DefaultErrorFormat()}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
 }
 
 func (w *Writer) WriteSuccess(resp http.ResponseWriter, data any, statusCode int) {
-	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Type", ContentTypeJSON)
 	resp.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(resp).Encode(data); err != nil {
@@ -23,19 +44,62 @@ func (w *Writer) WriteSuccess(resp http.ResponseWriter, data any, statusCode int
 	}
 }
 
+// WriteError writes an error using the status code's default error code
 func (w *Writer) WriteError(resp http.ResponseWriter, message string, statusCode int) {
+	w.WriteProblem(resp, nil, NewProblem(statusCode, appErrors.CodeForStatus(statusCode), message))
+}
+
+// WriteAppError writes an AppError, preserving its machine-readable code
+func (w *Writer) WriteAppError(resp http.ResponseWriter, appErr *appErrors.AppError) {
+	code := appErrors.ErrorCode(appErr.Code)
+	if code == "" {
+		code = appErrors.CodeForStatus(appErr.StatusCode)
+	}
+	w.WriteProblem(resp, nil, NewProblem(appErr.StatusCode, code, appErr.Message))
+}
+
+// WriteProblem writes an RFC 7807 problem document. Unless one is already
+// set, the problem instance is the path of req, or the one resp carries
+// (see WithInstance) when req is nil. The title and detail are translated into the request's language; the
+// code stays the same in every language.
+func (w *Writer) WriteProblem(resp http.ResponseWriter, req *http.Request, problem *Problem) {
+	if problem.Instance == "" {
+		problem.Instance = instanceOf(resp, req)
+	}
+
+	language := languageOf(resp, req)
+	problem.Title = i18n.Translate(language, problem.Title)
+	problem.Detail = i18n.Translate(language, problem.Detail)
+	resp.Header().Set("Content-Language", language)
+
+	if w.errorFormat == ErrorFormatLegacy {
+		w.writeLegacyError(resp, problem)
+		return
+	}
+
+	resp.Header().Set("Content-Type", ContentTypeProblem)
+	resp.WriteHeader(problem.Status)
+
+	if err := json.NewEncoder(resp).Encode(problem); err != nil {
+		w.logger.Error("Failed to encode problem response", "error", err)
+	}
+}
+
+func (w *Writer) writeLegacyError(resp http.ResponseWriter, problem *Problem) {
 	errorResponse := ErrorResponse{
-		Error: message,
+		Error: problem.Detail,
 	}
 
-	resp.Header().Set("Content-Type", "application/json")
-	resp.WriteHeader(statusCode)
+	resp.Header().Set("Content-Type", ContentTypeJSON)
+	resp.WriteHeader(problem.Status)
 
 	if err := json.NewEncoder(resp).Encode(errorResponse); err != nil {
 		w.logger.Error("Failed to encode error response", "error", err)
 	}
 }
 
+// ErrorResponse is the legacy error shape, kept for clients that have not
+// migrated to application/problem+json yet
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
@@ -60,7 +124,7 @@ func (w *Writer) WriteAPIResponse(resp http.ResponseWriter, data interface{}, er
 		Message: message,
 	}
 
-	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Type", ContentTypeJSON)
 	resp.WriteHeader(statusCode)
 
 	if encodeErr := json.NewEncoder(resp).Encode(response); encodeErr != nil {
@@ -0,0 +1,100 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteError_Problem(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatProblem))
+	rec := httptest.NewRecorder()
+
+	w.WriteError(rec, "movie not found", http.StatusNotFound)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, ContentTypeProblem, rec.Header().Get("Content-Type"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, ProblemTypeBase+"not-found", problem.Type)
+	assert.Equal(t, "Not Found", problem.Title)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "movie not found", problem.Detail)
+	assert.Equal(t, appErrors.CodeNotFound, problem.Code)
+}
+
+func TestWriteProblem_Instance(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatProblem))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/movies/abc", nil)
+
+	w.WriteProblem(rec, req, NewProblem(http.StatusConflict, appErrors.CodeConflict, "duplicate"))
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "/api/v1/movies/abc", problem.Instance)
+	assert.Equal(t, appErrors.CodeConflict, problem.Code)
+}
+
+func TestWriteError_InstanceFromWriter(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatProblem))
+	rec := httptest.NewRecorder()
+
+	w.WriteError(WithLanguage(WithInstance(rec, "/api/v1/movies/abc"), "en"), "movie not found", http.StatusNotFound)
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "/api/v1/movies/abc", problem.Instance)
+}
+
+func TestWriteAppError_PreservesCode(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatProblem))
+	rec := httptest.NewRecorder()
+
+	w.WriteAppError(rec, &appErrors.AppError{Message: "bad", StatusCode: http.StatusBadRequest, Code: string(appErrors.CodeValidationFailed)})
+
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, appErrors.CodeValidationFailed, problem.Code)
+}
+
+func TestWriteProblem_Translated(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatProblem))
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/abc", nil)
+	req = req.WithContext(i18n.WithLanguage(req.Context(), "fr"))
+
+	w.WriteProblem(rec, req, NewProblem(http.StatusNotFound, appErrors.CodeNotFound, "user not found"))
+
+	assert.Equal(t, "fr", rec.Header().Get("Content-Language"))
+	var problem Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "Introuvable", problem.Title)
+	assert.Equal(t, "utilisateur introuvable", problem.Detail)
+	assert.Equal(t, appErrors.CodeNotFound, problem.Code)
+}
+
+func TestWriteError_Legacy(t *testing.T) {
+	w := NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), WithErrorFormat(ErrorFormatLegacy))
+	rec := httptest.NewRecorder()
+
+	w.WriteError(rec, "invalid token", http.StatusUnauthorized)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, ContentTypeJSON, rec.Header().Get("Content-Type"))
+
+	var legacy ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &legacy))
+	assert.Equal(t, "invalid token", legacy.Error)
+}
@@ -0,0 +1,146 @@
+// Package i18n translates the messages the API shows users. Messages are
+// written in English in the code and looked up by that text in the catalog
+// of the request's language, so anything without a translation is shown in
+// English. The catalogs in catalogs/ are embedded in the binary, one JSON
+// object of English message to translation per language.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language messages are written in, used when a
+// request asks for none we have a catalog for
+const DefaultLanguage = "en"
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	files, err := catalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read message catalogs: %v", err))
+	}
+
+	loaded := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := catalogFiles.ReadFile(path.Join("catalogs", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to read message catalog %s: %v", file.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("failed to parse message catalog %s: %v", file.Name(), err))
+		}
+		loaded[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = messages
+	}
+	return loaded
+}
+
+// Languages returns the languages messages can be shown in, sorted
+func Languages() []string {
+	languages := []string{DefaultLanguage}
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header
+// ordered by preference, as sent. Wildcards and q=0 entries are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+// Match returns the language of an Accept-Language header most preferred
+// that messages can be shown in, going by the primary language, so de-CH
+// is shown in German. It returns DefaultLanguage when there is none.
+func Match(acceptLanguage string) string {
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		primary, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+		primary = strings.ToLower(primary)
+		if _, ok := catalogs[primary]; ok || primary == DefaultLanguage {
+			return primary
+		}
+	}
+	return DefaultLanguage
+}
+
+// Translate returns message in language, or message itself when it has no
+// translation
+func Translate(language, message string) string {
+	if translated, ok := catalogs[language][message]; ok {
+		return translated
+	}
+	return message
+}
+
+type languageKey struct{}
+
+// WithLanguage returns a context carrying the language of a request
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageFrom returns the language of a request, DefaultLanguage when none
+// was set
+func LanguageFrom(ctx context.Context) string {
+	if language, ok := ctx.Value(languageKey{}).(string); ok {
+		return language
+	}
+	return DefaultLanguage
+}
+
+// T translates message into the language of ctx
+func T(ctx context.Context, message string) string {
+	return Translate(LanguageFrom(ctx), message)
+}
+
+// Sprintf translates format into the language of ctx and formats it.
+// Translations keep the verbs of format, in the same order.
+func Sprintf(ctx context.Context, format string, args ...any) string {
+	return fmt.Sprintf(T(ctx, format), args...)
+}
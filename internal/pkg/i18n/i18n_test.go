@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "de"}, ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"en", "pt_br"}, ParseAcceptLanguage("pt_br;q=0.5, en, es;q=0"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+	assert.Empty(t, ParseAcceptLanguage("de;q=abc"))
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"de-CH, en;q=0.5", "de"},
+		{"ja, fr;q=0.8", "fr"},
+		{"en-GB, de;q=0.9", "en"},
+		{"ja", DefaultLanguage},
+		{"", DefaultLanguage},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.header), tt.header)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Nutzer nicht gefunden", Translate("de", "user not found"))
+	assert.Equal(t, "user not found", Translate("en", "user not found"))
+	assert.Equal(t, "no translation", Translate("de", "no translation"), "falls back to English")
+	assert.Equal(t, "user not found", Translate("ja", "user not found"))
+}
+
+func TestSprintf(t *testing.T) {
+	ctx := WithLanguage(context.Background(), "fr")
+	assert.Equal(t, "Note fiable basée sur 12 notes d'utilisateurs.", Sprintf(ctx, "Reliable rating based on %d user ratings.", 12))
+	assert.Equal(t, "Reliable rating based on 12 user ratings.", Sprintf(context.Background(), "Reliable rating based on %d user ratings.", 12))
+}
+
+func TestCatalogs(t *testing.T) {
+	assert.Equal(t, []string{"de", "en", "fr"}, Languages())
+
+	verbs := regexp.MustCompile(`%[-+# 0]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+	for language, messages := range catalogs {
+		for message, translated := range messages {
+			assert.NotEmpty(t, translated, "%s: %q", language, message)
+			assert.Equal(t, verbs.FindAllString(message, -1), verbs.FindAllString(translated, -1),
+				"%s: %q must keep the verbs of the message", language, message)
+		}
+	}
+}
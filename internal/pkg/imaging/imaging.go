@@ -0,0 +1,214 @@
+// Package imaging decodes uploaded images, renders resized JPEG variants and
+// computes perceptual hashes using only the standard library.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"math/bits"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image dimensions are too large")
+)
+
+// MaxPixels bounds decoded images so a small, highly compressed upload cannot
+// exhaust memory
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+// Variant is a named output width; the height follows the aspect ratio
+// unless Square crops the image to its centered square first
+type Variant struct {
+	Name   string
+	Width  int
+	Square bool
+}
+
+// Rendition is an encoded variant
+type Rendition struct {
+	Name   string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Decode reads a JPEG or PNG image, checking its dimensions before decoding
+// the pixel data
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, "", ErrUnsupportedFormat
+		}
+		return nil, "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+	return img, format, nil
+}
+
+// Renditions resizes img to each variant and encodes it as JPEG. Images are
+// never upscaled: a variant wider than the source keeps the source size.
+func Renditions(img image.Image, variants []Variant) ([]Rendition, error) {
+	src := flatten(img)
+
+	renditions := make([]Rendition, 0, len(variants))
+	for _, variant := range variants {
+		img := src
+		if variant.Square {
+			img = CropSquare(src)
+		}
+		resized := Resize(img, variant.Width)
+
+		var buf bytes.Buffer
+		if err := Encode(&buf, resized); err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", variant.Name, err)
+		}
+		bounds := resized.Bounds()
+		renditions = append(renditions, Rendition{
+			Name:   variant.Name,
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+			Data:   buf.Bytes(),
+		})
+	}
+	return renditions, nil
+}
+
+func Encode(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
+
+// Resize scales img down to width, preserving the aspect ratio, by averaging
+// each destination pixel's source area (a box filter). Narrower images are
+// returned unchanged.
+func Resize(img *image.RGBA, width int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if width <= 0 || width >= srcW {
+		return img
+	}
+
+	height := max(1, (srcH*width+srcW/2)/srcW)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcH / height
+		y1 := max(y0+1, (y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcW / width
+			x1 := max(x0+1, (x+1)*srcW/width)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := img.PixOffset(bounds.Min.X+x0, bounds.Min.Y+sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(img.Pix[offset])
+					g += uint32(img.Pix[offset+1])
+					b += uint32(img.Pix[offset+2])
+					a += uint32(img.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// hashWidth and hashHeight are the grayscale thumbnail DifferenceHash
+// compares; each row yields hashWidth-1 bits
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// DifferenceHash computes a 64-bit perceptual hash of img (dHash): the image
+// is shrunk to a 9x8 grayscale thumbnail and each bit tells whether a pixel
+// is brighter than its right neighbour. Resized, recompressed or slightly
+// retouched copies of an image hash a few bits apart at most; see
+// HammingDistance.
+func DifferenceHash(img image.Image) uint64 {
+	src := flatten(img)
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Average the luminance of the source area of each thumbnail pixel
+	var sums, counts [hashWidth * hashHeight]uint64
+	for y := 0; y < h; y++ {
+		row := y * hashHeight / h * hashWidth
+		offset := src.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		for x := 0; x < w; x++ {
+			r, g, b := uint64(src.Pix[offset]), uint64(src.Pix[offset+1]), uint64(src.Pix[offset+2])
+			cell := row + x*hashWidth/w
+			sums[cell] += 299*r + 587*g + 114*b
+			counts[cell]++
+			offset += 4
+		}
+	}
+
+	var gray [hashWidth * hashHeight]uint64
+	for i := range gray {
+		if counts[i] > 0 {
+			gray[i] = sums[i] / counts[i]
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if gray[y*hashWidth+x] > gray[y*hashWidth+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HammingDistance counts the bits two hashes differ in: 0 for the same
+// image, up to about 10 for copies of it
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// CropSquare returns the largest centered square of img
+func CropSquare(img *image.RGBA) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	return img.SubImage(image.Rect(x0, y0, x0+side, y0+side)).(*image.RGBA)
+}
+
+// flatten converts img to opaque RGBA, compositing any transparency onto
+// white since JPEG has no alpha channel
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+	return dst
+}
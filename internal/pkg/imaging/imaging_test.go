@@ -0,0 +1,135 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 60))
+
+	decoded, format, err := Decode(encodePNG(t, img))
+	require.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, image.Rect(0, 0, 40, 60), decoded.Bounds())
+
+	_, _, err = Decode([]byte("definitely not an image"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestRenditions(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 600))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	renditions, err := Renditions(img, []Variant{{Name: "small", Width: 100}, {Name: "large", Width: 800}})
+	require.NoError(t, err)
+	require.Len(t, renditions, 2)
+
+	assert.Equal(t, "small", renditions[0].Name)
+	assert.Equal(t, 100, renditions[0].Width)
+	assert.Equal(t, 150, renditions[0].Height)
+
+	// No upscaling
+	assert.Equal(t, 400, renditions[1].Width)
+	assert.Equal(t, 600, renditions[1].Height)
+
+	for _, rendition := range renditions {
+		decoded, format, err := image.Decode(bytes.NewReader(rendition.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, rendition.Width, decoded.Bounds().Dx())
+	}
+}
+
+func TestResizeAveragesPixels(t *testing.T) {
+	// Alternating black and white columns average to grey
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				img.Set(x, y, color.White)
+			} else {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	resized := Resize(img, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), resized.Bounds())
+	assert.Equal(t, color.RGBA{127, 127, 127, 255}, resized.RGBAAt(0, 0))
+}
+
+func TestFlattenCompositesOnWhite(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.NRGBA{0, 0, 0, 0})
+
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, flatten(img).RGBAAt(0, 0))
+}
+
+func TestRenditionsSquare(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 300, 200))
+
+	renditions, err := Renditions(img, []Variant{{Name: "thumbnail", Width: 64, Square: true}})
+	require.NoError(t, err)
+	assert.Equal(t, 64, renditions[0].Width)
+	assert.Equal(t, 64, renditions[0].Height)
+}
+
+func TestCropSquare(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	img.Set(15, 5, color.White)
+
+	cropped := CropSquare(img)
+	assert.Equal(t, image.Rect(10, 0, 20, 10), cropped.Bounds())
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, cropped.RGBAAt(15, 5))
+
+	// Resize handles the offset bounds of a cropped image
+	assert.Equal(t, image.Rect(0, 0, 5, 5), Resize(cropped, 5).Bounds())
+}
+
+func TestDifferenceHash(t *testing.T) {
+	// A poster-like image: soft blobs of light and shade
+	poster := image.NewRGBA(image.Rect(0, 0, 300, 450))
+	for y := 0; y < 450; y++ {
+		for x := 0; x < 300; x++ {
+			v := uint8(128 + 120*math.Sin(float64(x)/23)*math.Cos(float64(y)/41))
+			poster.Set(x, y, color.RGBA{R: v, G: v / 2, B: 255 - v/3, A: 0xff})
+		}
+	}
+	hash := DifferenceHash(poster)
+
+	// A smaller, recompressed copy is near-identical
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, Resize(poster, 120)))
+	copied, _, err := Decode(buf.Bytes())
+	require.NoError(t, err)
+	assert.LessOrEqual(t, HammingDistance(hash, DifferenceHash(copied)), 4)
+
+	// The mirrored image is a different poster
+	mirrored := image.NewRGBA(poster.Bounds())
+	for y := 0; y < 450; y++ {
+		for x := 0; x < 300; x++ {
+			mirrored.Set(299-x, y, poster.At(x, y))
+		}
+	}
+	assert.Greater(t, HammingDistance(hash, DifferenceHash(mirrored)), 20)
+
+	// Without any detail no pixel is brighter than its neighbour
+	assert.Zero(t, DifferenceHash(image.NewGray(image.Rect(0, 0, 10, 10))))
+	assert.Equal(t, 64, HammingDistance(0, ^uint64(0)))
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidSamplePercent = errors.New("sample percent must be between 0 and 100")
+
+// Bodies selects the requests whose bodies are logged: a percentage of all
+// traffic, and every request to the listed routes. A route is a pattern as
+// the router matched it, e.g. /api/v1/users/login, optionally preceded by a
+// method, e.g. "POST /api/v1/users/". It is safe for concurrent use; nothing
+// is selected until Set is called.
+type Bodies struct {
+	mu      sync.RWMutex
+	percent float64
+	routes  []string
+}
+
+func NewBodies() *Bodies {
+	return &Bodies{}
+}
+
+// Set replaces the sample percentage and routes
+func (b *Bodies) Set(percent float64, routes []string) error {
+	if percent < 0 || percent > 100 {
+		return ErrInvalidSamplePercent
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.percent = percent
+	b.routes = append([]string(nil), routes...)
+	return nil
+}
+
+// Percent returns the share of all requests that is sampled
+func (b *Bodies) Percent() float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.percent
+}
+
+// Routes returns a copy of the routes whose requests are all logged
+func (b *Bodies) Routes() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]string(nil), b.routes...)
+}
+
+// Active reports whether any request can be selected
+func (b *Bodies) Active() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.percent > 0 || len(b.routes) > 0
+}
+
+// Selects decides whether to log the bodies of a request to the route
+// pattern with method, sampling the percentage afresh on every call
+func (b *Bodies) Selects(method, pattern string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, route := range b.routes {
+		if routeMethod, routePattern, ok := strings.Cut(route, " "); ok {
+			if strings.EqualFold(routeMethod, method) && routePattern == pattern {
+				return true
+			}
+		} else if route == pattern {
+			return true
+		}
+	}
+	return b.percent > 0 && rand.Float64()*100 < b.percent
+}
@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodiesSelection(t *testing.T) {
+	bodies := NewBodies()
+	assert.False(t, bodies.Active())
+	assert.False(t, bodies.Selects("POST", "/api/v1/users/"))
+
+	assert.ErrorIs(t, bodies.Set(101, nil), ErrInvalidSamplePercent)
+	require.NoError(t, bodies.Set(0, []string{"post /api/v1/users/", "/api/v1/ratings/{id}"}))
+	assert.True(t, bodies.Active())
+	assert.True(t, bodies.Selects("POST", "/api/v1/users/"), "methods match whatever their case")
+	assert.False(t, bodies.Selects("GET", "/api/v1/users/"))
+	assert.True(t, bodies.Selects("PUT", "/api/v1/ratings/{id}"), "a route without a method matches every method")
+
+	require.NoError(t, bodies.Set(100, nil))
+	assert.Empty(t, bodies.Routes())
+	assert.True(t, bodies.Selects("GET", "/health"))
+}
@@ -0,0 +1,19 @@
+package logging
+
+import "context"
+
+type attrsKey struct{}
+
+// WithAttrs returns a context whose operations are logged with attrs too,
+// such as the internal service a request came from and the user it acts
+// for. attrs are key-value pairs as slog.Logger.Info takes them.
+func WithAttrs(ctx context.Context, attrs ...any) context.Context {
+	existing := AttrsFrom(ctx)
+	return context.WithValue(ctx, attrsKey{}, append(existing[:len(existing):len(existing)], attrs...))
+}
+
+// AttrsFrom returns the attributes WithAttrs added to ctx
+func AttrsFrom(ctx context.Context) []any {
+	attrs, _ := ctx.Value(attrsKey{}).([]any)
+	return attrs
+}
@@ -0,0 +1,21 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAttrs(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, AttrsFrom(ctx))
+
+	parent := WithAttrs(ctx, "caller", "billing")
+	child := WithAttrs(parent, "on_behalf_of", "user-1")
+	sibling := WithAttrs(parent, "on_behalf_of", "user-2")
+
+	assert.Equal(t, []any{"caller", "billing"}, AttrsFrom(parent))
+	assert.Equal(t, []any{"caller", "billing", "on_behalf_of", "user-1"}, AttrsFrom(child))
+	assert.Equal(t, []any{"caller", "billing", "on_behalf_of", "user-2"}, AttrsFrom(sibling))
+}
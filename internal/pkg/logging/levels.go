@@ -0,0 +1,173 @@
+// Package logging adds runtime-adjustable log levels to slog, globally and
+// per module. A module is a part of the service, such as the repository
+// layer, whose loggers carry a "module" attribute; see Module.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// ModuleKey is the attribute that names a logger's module
+const ModuleKey = "module"
+
+// Modules that can have their own level
+const (
+	ModuleRepository = "repository"
+	ModuleCache      = "cache"
+	ModuleHTTP       = "http"
+)
+
+var Modules = []string{ModuleRepository, ModuleCache, ModuleHTTP}
+
+var ErrUnknownModule = errors.New("module must be one of: repository, cache, http")
+
+// Levels holds the base level and per-module overrides. It is safe for
+// concurrent use, and is itself a slog.Leveler reporting the most verbose
+// level in use, for the handler it wraps.
+type Levels struct {
+	mu        sync.RWMutex
+	base      slog.Level
+	overrides map[string]slog.Level
+}
+
+func NewLevels(base slog.Level) *Levels {
+	return &Levels{base: base, overrides: make(map[string]slog.Level)}
+}
+
+// Base returns the level of loggers without an override
+func (l *Levels) Base() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.base
+}
+
+func (l *Levels) SetBase(level slog.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.base = level
+}
+
+// For returns the effective level of module
+func (l *Levels) For(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.overrides[module]; ok {
+		return level
+	}
+	return l.base
+}
+
+// SetModule overrides the level of one module
+func (l *Levels) SetModule(module string, level slog.Level) error {
+	if !IsModule(module) {
+		return ErrUnknownModule
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overrides[module] = level
+	return nil
+}
+
+// ClearModule makes module follow the base level again
+func (l *Levels) ClearModule(module string) error {
+	if !IsModule(module) {
+		return ErrUnknownModule
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.overrides, module)
+	return nil
+}
+
+// Overrides returns a copy of the per-module levels
+func (l *Levels) Overrides() map[string]slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make(map[string]slog.Level, len(l.overrides))
+	for module, level := range l.overrides {
+		out[module] = level
+	}
+	return out
+}
+
+// Level implements slog.Leveler with the most verbose level in use
+func (l *Levels) Level() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	min := l.base
+	for _, level := range l.overrides {
+		if level < min {
+			min = level
+		}
+	}
+	return min
+}
+
+// IsModule reports whether module is one of Modules
+func IsModule(module string) bool {
+	for _, m := range Modules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLevel parses debug, info, warn or error, case-insensitively
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("level must be one of debug, info, warn, error; got %q", level)
+	}
+	return l, nil
+}
+
+// FormatLevel is the inverse of ParseLevel
+func FormatLevel(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// Module returns a logger whose records are filtered by module's level
+func Module(logger *slog.Logger, module string) *slog.Logger {
+	return logger.With(ModuleKey, module)
+}
+
+// Handler filters records by the level of the module they belong to. The
+// wrapped handler should use the Levels as its own level, so it never
+// drops a record this handler lets through.
+type Handler struct {
+	inner  slog.Handler
+	levels *Levels
+	module string
+}
+
+func NewHandler(inner slog.Handler, levels *Levels) *Handler {
+	return &Handler{inner: inner, levels: levels}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.levels.For(h.module)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	return h.inner.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	module := h.module
+	for _, attr := range attrs {
+		if attr.Key == ModuleKey {
+			module = attr.Value.String()
+		}
+	}
+	return &Handler{inner: h.inner.WithAttrs(attrs), levels: h.levels, module: module}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name), levels: h.levels, module: h.module}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(levels *Levels) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	inner := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: levels})
+	return slog.New(NewHandler(inner, levels)), &buf
+}
+
+func TestModuleLevels(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo)
+	logger, buf := newTestLogger(levels)
+	repo := Module(logger, ModuleRepository)
+	http := Module(logger, ModuleHTTP)
+
+	repo.Debug("repo debug")
+	http.Debug("http debug")
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, levels.SetModule(ModuleRepository, slog.LevelDebug))
+	repo.Debug("repo debug")
+	http.Debug("http debug")
+	logger.Debug("base debug")
+	assert.Contains(t, buf.String(), "repo debug")
+	assert.NotContains(t, buf.String(), "http debug")
+	assert.NotContains(t, buf.String(), "base debug")
+
+	// Raising the base level still leaves the override in place
+	buf.Reset()
+	levels.SetBase(slog.LevelError)
+	http.Warn("http warn")
+	repo.Warn("repo warn")
+	assert.NotContains(t, buf.String(), "http warn")
+	assert.Contains(t, buf.String(), "repo warn")
+
+	require.NoError(t, levels.ClearModule(ModuleRepository))
+	buf.Reset()
+	repo.Warn("repo warn")
+	assert.Empty(t, buf.String())
+}
+
+func TestModuleSurvivesGroupsAndAttrs(t *testing.T) {
+	levels := NewLevels(slog.LevelInfo)
+	logger, buf := newTestLogger(levels)
+	require.NoError(t, levels.SetModule(ModuleCache, slog.LevelDebug))
+
+	Module(logger, ModuleCache).With("key", "v").WithGroup("g").Debug("cache debug")
+	assert.True(t, strings.Contains(buf.String(), "cache debug"))
+}
+
+func TestLevelsValidation(t *testing.T) {
+	levels := NewLevels(slog.LevelWarn)
+	assert.ErrorIs(t, levels.SetModule("templates", slog.LevelDebug), ErrUnknownModule)
+	assert.ErrorIs(t, levels.ClearModule("templates"), ErrUnknownModule)
+
+	assert.Equal(t, slog.LevelWarn, levels.Level())
+	require.NoError(t, levels.SetModule(ModuleHTTP, slog.LevelDebug))
+	assert.Equal(t, slog.LevelDebug, levels.Level(), "the wrapped handler must let the most verbose module through")
+	assert.Equal(t, map[string]slog.Level{ModuleHTTP: slog.LevelDebug}, levels.Overrides())
+}
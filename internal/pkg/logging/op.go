@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// The attributes every service operation is logged with, next to the IDs
+// of the entities it acts on, such as movie_id or user_id
+const (
+	OperationKey = "operation"
+	DurationKey  = "duration"
+	ErrorKey     = "error"
+	RequestIDKey = "request_id"
+)
+
+// Op logs one service operation when it ends, so every operation logs the
+// same fields whichever service runs it. Services start one at the top of a
+// method and defer End with the address of the method's error result:
+//
+//	op := logging.StartOp(ctx, s.logger, "rating.update", "rating_id", id)
+//	defer op.End(&err)
+//
+// Operations that succeed are logged at debug level, those the client got
+// wrong (an AppError with a 4xx status, or an error given to Expect) at info
+// level and any other failure at error level.
+type Op struct {
+	ctx      context.Context
+	logger   *slog.Logger
+	start    time.Time
+	attrs    []any
+	expected []error
+}
+
+// StartOp starts the named operation; attrs are key-value pairs as
+// slog.Logger.Info takes them
+func StartOp(ctx context.Context, logger *slog.Logger, operation string, attrs ...any) *Op {
+	return &Op{
+		ctx:    ctx,
+		logger: logger,
+		start:  time.Now(),
+		attrs:  append([]any{OperationKey, operation}, attrs...),
+	}
+}
+
+// With adds attributes known only once the operation is under way, such as
+// the ID of the entity it created
+func (o *Op) With(attrs ...any) {
+	o.attrs = append(o.attrs, attrs...)
+}
+
+// Expect marks errors the client causes, such as the validation errors of
+// a domain package, so they are logged like a 4xx AppError
+func (o *Op) Expect(targets ...error) *Op {
+	o.expected = append(o.expected, targets...)
+	return o
+}
+
+// End logs the operation with *errp as its outcome
+func (o *Op) End(errp *error) {
+	var err error
+	if errp != nil {
+		err = *errp
+	}
+
+	level, msg := slog.LevelDebug, "Operation completed"
+	if err != nil {
+		level, msg = slog.LevelError, "Operation failed"
+		if o.isExpected(err) {
+			level = slog.LevelInfo
+		}
+	}
+	if !o.logger.Enabled(o.ctx, level) {
+		return
+	}
+
+	attrs := append(o.attrs[:len(o.attrs):len(o.attrs)], DurationKey, time.Since(o.start))
+	attrs = append(attrs, AttrsFrom(o.ctx)...)
+	if requestID := middleware.GetReqID(o.ctx); requestID != "" {
+		attrs = append(attrs, RequestIDKey, requestID)
+	}
+	if err != nil {
+		attrs = append(attrs, ErrorKey, err)
+	}
+	o.logger.Log(o.ctx, level, msg, attrs...)
+}
+
+func (o *Op) isExpected(err error) bool {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.StatusCode < http.StatusInternalServerError
+	}
+	for _, target := range o.expected {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
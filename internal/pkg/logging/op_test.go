@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOp(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	ctx = WithAttrs(ctx, "caller", "billing")
+	errInvalid := errors.New("invalid")
+
+	run := func(err error) map[string]any {
+		t.Helper()
+		buf.Reset()
+		op := StartOp(ctx, logger, "movie.create", "title", "Alien").Expect(errInvalid)
+		op.With("movie_id", "movie-1")
+		op.End(&err)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		return record
+	}
+
+	t.Run("logs a success at debug level", func(t *testing.T) {
+		record := run(nil)
+		assert.Equal(t, "DEBUG", record["level"])
+		assert.Equal(t, "Operation completed", record["msg"])
+		assert.Equal(t, "movie.create", record[OperationKey])
+		assert.Equal(t, "Alien", record["title"])
+		assert.Equal(t, "movie-1", record["movie_id"])
+		assert.Equal(t, "req-1", record[RequestIDKey])
+		assert.Equal(t, "billing", record["caller"])
+		assert.Contains(t, record, DurationKey)
+		assert.NotContains(t, record, ErrorKey)
+	})
+
+	t.Run("logs client errors at info level", func(t *testing.T) {
+		record := run(appErrors.NewNotFoundError("Movie not found"))
+		assert.Equal(t, "INFO", record["level"])
+		assert.Equal(t, "Operation failed", record["msg"])
+		assert.Equal(t, "Movie not found", record[ErrorKey])
+
+		record = run(errInvalid)
+		assert.Equal(t, "INFO", record["level"], "expected errors are the client's")
+	})
+
+	t.Run("logs other errors at error level", func(t *testing.T) {
+		record := run(appErrors.NewInternalError("Failed to create movie"))
+		assert.Equal(t, "ERROR", record["level"])
+
+		record = run(errors.New("connection refused"))
+		assert.Equal(t, "ERROR", record["level"])
+		assert.Equal(t, "connection refused", record[ErrorKey])
+	})
+
+	t.Run("skips disabled levels", func(t *testing.T) {
+		buf.Reset()
+		quiet := slog.New(slog.NewJSONHandler(&buf, nil))
+		var err error
+		StartOp(ctx, quiet, "movie.get").End(&err)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,176 @@
+// Package markdown renders the small markdown subset allowed in reviews to
+// HTML that is safe to embed. Raw HTML in the source is always escaped and
+// links keep only http, https and mailto targets.
+//
+// Supported syntax: paragraphs, line breaks, "- " lists, "> " quotes,
+// **bold**, *italic*, `code`, [text](url) and ||spoiler||.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	italicPattern  = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	spoilerPattern = regexp.MustCompile(`\|\|(\S(?:.*?\S)?)\|\|`)
+	// placeholderPattern matches rendered links parked while the text
+	// around them is formatted
+	placeholderPattern = regexp.MustCompile("\x00([0-9]+)\x00")
+)
+
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+type blockKind int
+
+const (
+	paragraph blockKind = iota
+	list
+	quote
+)
+
+// Render converts markdown to HTML
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\x00", "")
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+
+	var b strings.Builder
+	var kind blockKind
+	var lines []string
+
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		switch kind {
+		case list:
+			b.WriteString("<ul>")
+			for _, line := range lines {
+				b.WriteString("<li>" + renderInline(line) + "</li>")
+			}
+			b.WriteString("</ul>\n")
+		case quote:
+			b.WriteString("<blockquote><p>" + renderLines(lines) + "</p></blockquote>\n")
+		default:
+			b.WriteString("<p>" + renderLines(lines) + "</p>\n")
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+
+		var k blockKind
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			k, line = list, line[2:]
+		case strings.HasPrefix(line, ">"):
+			k, line = quote, strings.TrimSpace(line[1:])
+		default:
+			k = paragraph
+		}
+
+		if k != kind {
+			flush()
+			kind = k
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// HasSpoilers reports whether the source marks any text as a spoiler.
+// Spoiler markers inside code spans do not count.
+func HasSpoilers(src string) bool {
+	ticks := strings.Count(src, "`")
+	for i, part := range strings.Split(src, "`") {
+		if !inCodeSpan(i, ticks) && spoilerPattern.MatchString(part) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderLines(lines []string) string {
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = renderInline(line)
+	}
+	return strings.Join(rendered, "<br>\n")
+}
+
+// renderInline formats one line. Backticks split it into code spans, which
+// are escaped but otherwise kept verbatim; an unmatched backtick is literal.
+func renderInline(text string) string {
+	ticks := strings.Count(text, "`")
+
+	var b strings.Builder
+	for i, part := range strings.Split(text, "`") {
+		if inCodeSpan(i, ticks) {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString("`")
+		}
+		b.WriteString(formatText(html.EscapeString(part)))
+	}
+	return b.String()
+}
+
+// inCodeSpan tells whether the i-th backtick separated part of a text with
+// the given number of backticks lies between a pair of them
+func inCodeSpan(i, ticks int) bool {
+	return i%2 == 1 && i < ticks-ticks%2
+}
+
+// formatText applies the inline syntax to escaped text. Links are rendered
+// first and parked behind placeholders so their URLs are not formatted.
+func formatText(text string) string {
+	var links []string
+	text = linkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := linkPattern.FindStringSubmatch(match)
+		label := formatEmphasis(m[1])
+		if !safeURL(html.UnescapeString(m[2])) {
+			links = append(links, label)
+		} else {
+			links = append(links, `<a href="`+m[2]+`" rel="nofollow ugc">`+label+`</a>`)
+		}
+		return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+	})
+
+	text = formatEmphasis(text)
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		i, _ := strconv.Atoi(strings.Trim(match, "\x00"))
+		return links[i]
+	})
+}
+
+func formatEmphasis(text string) string {
+	text = boldPattern.ReplaceAllString(text, "<strong>$1</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1</em>")
+	return spoilerPattern.ReplaceAllString(text, `<span class="spoiler">$1</span>`)
+}
+
+func safeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return allowedSchemes[strings.ToLower(u.Scheme)]
+}
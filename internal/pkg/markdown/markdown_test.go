@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "plain paragraph",
+			src:  "Great movie",
+			want: "<p>Great movie</p>",
+		},
+		{
+			name: "paragraphs and line breaks",
+			src:  "First line\nsecond line\n\nNew paragraph",
+			want: "<p>First line<br>\nsecond line</p>\n<p>New paragraph</p>",
+		},
+		{
+			name: "emphasis",
+			src:  "**bold** and *italic* and `co*de*`",
+			want: "<p><strong>bold</strong> and <em>italic</em> and <code>co*de*</code></p>",
+		},
+		{
+			name: "spoiler",
+			src:  "The butler ||did it||",
+			want: `<p>The butler <span class="spoiler">did it</span></p>`,
+		},
+		{
+			name: "list and quote",
+			src:  "- one\n- two\n> quoted",
+			want: "<ul><li>one</li><li>two</li></ul>\n<blockquote><p>quoted</p></blockquote>",
+		},
+		{
+			name: "link",
+			src:  "[trailer](https://example.com/a_*b*?x=1&y=2)",
+			want: `<p><a href="https://example.com/a_*b*?x=1&amp;y=2" rel="nofollow ugc">trailer</a></p>`,
+		},
+		{
+			name: "unsafe link keeps only the text",
+			src:  "[click](javascript:alert%281%29)",
+			want: "<p>click</p>",
+		},
+		{
+			name: "raw html is escaped",
+			src:  `<script>alert("x")</script> <img src=x onerror=alert(1)>`,
+			want: "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &lt;img src=x onerror=alert(1)&gt;</p>",
+		},
+		{
+			name: "quotes cannot break out of href",
+			src:  `[x](https://example.com/"onmouseover="alert(1))`,
+			want: `<p><a href="https://example.com/&#34;onmouseover=&#34;alert(1" rel="nofollow ugc">x</a>)</p>`,
+		},
+		{
+			name: "unmatched backtick is literal",
+			src:  "it`s *fine*",
+			want: "<p>it`s <em>fine</em></p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.src))
+		})
+	}
+}
+
+func TestHasSpoilers(t *testing.T) {
+	assert.True(t, HasSpoilers("The ending ||is a dream||"))
+	assert.False(t, HasSpoilers("No spoilers here"))
+	assert.False(t, HasSpoilers("Use `||x||` to hide text"))
+	assert.False(t, HasSpoilers("empty |||| markers"))
+}
@@ -0,0 +1,82 @@
+// Package mergepatch describes JSON Merge Patch documents (RFC 7396) as typed
+// request structs. Each patchable member is declared as a Field so services
+// can tell a member that was left out from one that was explicitly nulled.
+// Handlers decode patches with request.DecodeJSON like any other body.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+)
+
+// ContentType is the media type registered for JSON Merge Patch documents
+const ContentType = "application/merge-patch+json"
+
+var ErrUnsupportedMediaType = errors.New("PATCH requests must be sent as " + ContentType + " or application/json")
+
+// Field is one member of a merge patch. Set is false when the member was
+// absent, so the target keeps its value; Null is true when the member was
+// explicitly null, which clears the target.
+type Field[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// Value returns a Field set to v, mainly for tests
+func Value[T any](v T) Field[T] {
+	return Field[T]{Set: true, Value: v}
+}
+
+// Null returns a Field explicitly set to null
+func Null[T any]() Field[T] {
+	return Field[T]{Set: true, Null: true}
+}
+
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// ApplyTo copies a non-null value onto dst. It reports whether the member was
+// null so callers can reject nulls for required attributes.
+func (f Field[T]) ApplyTo(dst *T) (null bool) {
+	if !f.Set {
+		return false
+	}
+	if f.Null {
+		return true
+	}
+	*dst = f.Value
+	return false
+}
+
+// ApplyToOptional copies the value onto an optional attribute, clearing it
+// when the member was null
+func (f Field[T]) ApplyToOptional(dst **T) {
+	if !f.Set {
+		return
+	}
+	if f.Null {
+		*dst = nil
+		return
+	}
+	v := f.Value
+	*dst = &v
+}
+
+// CheckContentType accepts the merge patch media type and, for clients that
+// cannot set it, plain JSON
+func CheckContentType(contentType string) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != ContentType && mediaType != "application/json") {
+		return ErrUnsupportedMediaType
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPatch struct {
+	Title  Field[string] `json:"title"`
+	Budget Field[int64]  `json:"budget"`
+	Poster Field[string] `json:"poster"`
+}
+
+func TestField(t *testing.T) {
+	var patch testPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"title": "Alien", "budget": null}`), &patch))
+
+	assert.Equal(t, Value("Alien"), patch.Title)
+	assert.Equal(t, Null[int64](), patch.Budget)
+	assert.False(t, patch.Poster.Set)
+}
+
+func TestApply(t *testing.T) {
+	title := "Alien"
+	assert.False(t, Field[string]{}.ApplyTo(&title))
+	assert.Equal(t, "Alien", title)
+
+	assert.False(t, Value("Aliens").ApplyTo(&title))
+	assert.Equal(t, "Aliens", title)
+
+	assert.True(t, Null[string]().ApplyTo(&title))
+	assert.Equal(t, "Aliens", title)
+
+	budget := int64(10)
+	optional := &budget
+	Field[int64]{}.ApplyToOptional(&optional)
+	assert.Equal(t, int64(10), *optional)
+
+	Value(int64(20)).ApplyToOptional(&optional)
+	assert.Equal(t, int64(20), *optional)
+
+	Null[int64]().ApplyToOptional(&optional)
+	assert.Nil(t, optional)
+}
+
+func TestCheckContentType(t *testing.T) {
+	assert.NoError(t, CheckContentType("application/merge-patch+json"))
+	assert.NoError(t, CheckContentType("application/json; charset=utf-8"))
+	assert.ErrorIs(t, CheckContentType("text/plain"), ErrUnsupportedMediaType)
+	assert.ErrorIs(t, CheckContentType(""), ErrUnsupportedMediaType)
+}
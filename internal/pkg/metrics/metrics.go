@@ -0,0 +1,300 @@
+// Package metrics keeps process-wide counters and gauges and serves them in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a value that only goes up
+type Counter struct {
+	v atomic.Int64
+}
+
+func (c *Counter) Inc() { c.v.Add(1) }
+
+func (c *Counter) Add(n int64) { c.v.Add(n) }
+
+func (c *Counter) Value() int64 { return c.v.Load() }
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	bits atomic.Uint64
+}
+
+func (g *Gauge) Set(v float64) { g.bits.Store(math.Float64bits(v)) }
+
+func (g *Gauge) Value() float64 { return math.Float64frombits(g.bits.Load()) }
+
+// CounterVec is a family of counters told apart by label values
+type CounterVec struct {
+	labels []string
+
+	mu       sync.RWMutex
+	children map[string]*Counter
+	values   map[string][]string
+}
+
+// With returns the counter for the label values, in the order the labels
+// were declared
+func (v *CounterVec) With(values ...string) *Counter {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	c, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c, ok := v.children[key]; ok {
+		return c
+	}
+	c = &Counter{}
+	v.children[key] = c
+	v.values[key] = append([]string(nil), values...)
+	return c
+}
+
+// DefaultDurationBuckets are the upper bounds, in seconds, of histograms of
+// how long database and cache calls take
+var DefaultDurationBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Histogram counts observations into buckets by upper bound and keeps their
+// sum
+type Histogram struct {
+	upperBounds []float64
+
+	mu     sync.Mutex
+	counts []uint64 // Per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(upperBounds []float64) *Histogram {
+	return &Histogram{upperBounds: upperBounds, counts: make([]uint64, len(upperBounds)+1)}
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upperBounds, v)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// write writes the cumulative buckets, sum and count under labels, which
+// are formatted without braces
+func (h *Histogram) write(w io.Writer, name, labels string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, bound := range h.upperBounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	braced := ""
+	if labels != "" {
+		braced = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, braced, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, braced, h.count)
+}
+
+// HistogramVec is a family of histograms told apart by label values
+type HistogramVec struct {
+	labels      []string
+	upperBounds []float64
+
+	mu       sync.RWMutex
+	children map[string]*Histogram
+	values   map[string][]string
+}
+
+// With returns the histogram for the label values, in the order the labels
+// were declared
+func (v *HistogramVec) With(values ...string) *Histogram {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: got %d label values for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	h, ok := v.children[key]
+	v.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if h, ok := v.children[key]; ok {
+		return h
+	}
+	h = newHistogram(v.upperBounds)
+	v.children[key] = h
+	v.values[key] = append([]string(nil), values...)
+	return h
+}
+
+type metric struct {
+	name, help, kind string
+	write            func(w io.Writer, name string)
+}
+
+// Registry holds named metrics
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// Default is the registry the package-level constructors use and Handler
+// serves
+var Default = NewRegistry()
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[m.name]; ok {
+		panic("metrics: duplicate metric " + m.name)
+	}
+	r.metrics[m.name] = m
+}
+
+// NewCounter registers a counter. Names follow the Prometheus conventions,
+// e.g. postgres_slow_queries_total.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(metric{name: name, help: help, kind: "counter", write: func(w io.Writer, name string) {
+		fmt.Fprintf(w, "%s %d\n", name, c.Value())
+	}})
+	return c
+}
+
+// NewCounterVec registers a counter family with the given label names
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{labels: labels, children: make(map[string]*Counter), values: make(map[string][]string)}
+	r.register(metric{name: name, help: help, kind: "counter", write: func(w io.Writer, name string) {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		keys := make([]string, 0, len(v.children))
+		for key := range v.children {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s%s %d\n", name, formatLabels(v.labels, v.values[key]), v.children[key].Value())
+		}
+	}})
+	return v
+}
+
+// NewHistogramVec registers a histogram family with the given bucket upper
+// bounds, in increasing order, and label names
+func (r *Registry) NewHistogramVec(name, help string, upperBounds []float64, labels ...string) *HistogramVec {
+	v := &HistogramVec{labels: labels, upperBounds: upperBounds, children: make(map[string]*Histogram), values: make(map[string][]string)}
+	r.register(metric{name: name, help: help, kind: "histogram", write: func(w io.Writer, name string) {
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		keys := make([]string, 0, len(v.children))
+		for key := range v.children {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			labels := formatLabels(v.labels, v.values[key])
+			v.children[key].write(w, name, labels[1:len(labels)-1])
+		}
+	}})
+	return v
+}
+
+// NewGauge registers a gauge
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{}
+	r.register(metric{name: name, help: help, kind: "gauge", write: func(w io.Writer, name string) {
+		fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(g.Value(), 'g', -1, 64))
+	}})
+	return g
+}
+
+// WriteText writes every metric in the text exposition format, sorted by name
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.RLock()
+		m := r.metrics[name]
+		r.mu.RUnlock()
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, m.help, name, m.kind)
+		m.write(w, name)
+	}
+}
+
+// Handler serves the registry for scraping
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+func NewCounter(name, help string) *Counter {
+	return Default.NewCounter(name, help)
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+func NewGauge(name, help string) *Gauge {
+	return Default.NewGauge(name, help)
+}
+
+func NewHistogramVec(name, help string, upperBounds []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, upperBounds, labels...)
+}
+
+// Handler serves the Default registry
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests served")
+	shed := r.NewCounterVec("shed_total", "Requests shed", "class")
+	inUse := r.NewGauge("connections_in_use", "Open connections in use")
+
+	requests.Inc()
+	requests.Add(2)
+	shed.With("search").Inc()
+	shed.With(`a"b`).Add(3)
+	inUse.Set(1.5)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, `# HELP connections_in_use Open connections in use
+# TYPE connections_in_use gauge
+connections_in_use 1.5
+# HELP requests_total Requests served
+# TYPE requests_total counter
+requests_total 3
+# HELP shed_total Requests shed
+# TYPE shed_total counter
+shed_total{class="a\"b"} 3
+shed_total{class="search"} 1
+`, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+
+	assert.Panics(t, func() { r.NewCounter("requests_total", "again") })
+	assert.Panics(t, func() { shed.With("a", "b") })
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	durations := r.NewHistogramVec("call_duration_seconds", "Call durations", []float64{0.1, 1}, "method")
+
+	durations.With("get").Observe(0.05)
+	durations.With("get").Observe(0.1)
+	durations.With("get").Observe(3)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, `# HELP call_duration_seconds Call durations
+# TYPE call_duration_seconds histogram
+call_duration_seconds_bucket{method="get",le="0.1"} 2
+call_duration_seconds_bucket{method="get",le="1"} 2
+call_duration_seconds_bucket{method="get",le="+Inf"} 3
+call_duration_seconds_sum{method="get"} 3.15
+call_duration_seconds_count{method="get"} 3
+`, rec.Body.String())
+
+	assert.Panics(t, func() { durations.With() })
+}
@@ -3,7 +3,11 @@ package migrate
 import (
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
 
 	_ "github.com/lib/pq"
 	"github.com/rubenv/sql-migrate"
@@ -38,3 +42,90 @@ func RollbackMigrations(db *sql.DB, migrationsDir string, logger *slog.Logger) e
 	logger.Info("Rolled back migrations", "count", n)
 	return nil
 }
+
+// migration is one {version}_{title}.up.sql file
+type migration struct {
+	version int64
+	name    string
+}
+
+// Up applies the migrations in fsys newer than the version recorded in
+// schema_migrations, oldest first, each in its own transaction. Files are
+// named {version}_{title}.up.sql and the version table is laid out as
+// golang-migrate lays it out, so a database can move between the two.
+func Up(db *sql.DB, fsys fs.FS, logger *slog.Logger) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT NOT NULL PRIMARY KEY,
+		dirty BOOLEAN NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int64
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	pending, err := pendingMigrations(fsys, current)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		if err := apply(db, fsys, m); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("Applied migrations", "count", len(pending))
+	return nil
+}
+
+// pendingMigrations lists the up migrations in fsys newer than current,
+// oldest first
+func pendingMigrations(fsys fs.FS, current int64) ([]migration, error) {
+	names, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var pending []migration
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", name, err)
+		}
+		if version > current {
+			pending = append(pending, migration{version: version, name: name})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].version < pending[j].version })
+	return pending, nil
+}
+
+func apply(db *sql.DB, fsys fs.FS, m migration) error {
+	script, err := fs.ReadFile(fsys, m.name)
+	if err != nil {
+		return fmt.Errorf("failed to read migration %s: %w", m.name, err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(string(script)); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.name, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, m.version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,33 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_add_index.up.sql":          {Data: []byte("CREATE INDEX")},
+		"000002_create_users.up.sql":   {Data: []byte("CREATE TABLE users")},
+		"000002_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+		"000001_create_movies.up.sql":  {Data: []byte("CREATE TABLE movies")},
+	}
+
+	pending, err := pendingMigrations(fsys, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []migration{
+		{version: 1, name: "000001_create_movies.up.sql"},
+		{version: 2, name: "000002_create_users.up.sql"},
+		{version: 10, name: "10_add_index.up.sql"},
+	}, pending, "ordered by version, not by name")
+
+	pending, err = pendingMigrations(fsys, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []migration{{version: 10, name: "10_add_index.up.sql"}}, pending)
+
+	_, err = pendingMigrations(fstest.MapFS{"init.up.sql": {}}, 0)
+	assert.Error(t, err)
+}
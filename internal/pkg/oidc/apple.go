@@ -0,0 +1,57 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// appleSecretLifetime is how long a client secret made for Apple is valid;
+// a new one is made for every exchange
+const appleSecretLifetime = 5 * time.Minute
+
+// AppleClientSecret makes the client secret Apple expects for the code
+// exchange: a JWT signed with the team's Sign in with Apple key
+func AppleClientSecret(teamID, keyID, clientID string, key *ecdsa.PrivateKey) func(now time.Time) (string, error) {
+	return func(now time.Time) (string, error) {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+			Issuer:    teamID,
+			Subject:   clientID,
+			Audience:  jwt.ClaimStrings{AppleIssuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(appleSecretLifetime)),
+		})
+		token.Header["kid"] = keyID
+		secret, err := token.SignedString(key)
+		if err != nil {
+			return "", fmt.Errorf("failed to sign Apple client secret: %w", err)
+		}
+		return secret, nil
+	}
+}
+
+// ReadAppleKey reads the .p8 file Apple issues for a Sign in with Apple key
+func ReadAppleKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Apple key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an EC private key", path)
+	}
+	return key, nil
+}
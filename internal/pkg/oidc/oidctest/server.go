@@ -0,0 +1,160 @@
+// Package oidctest runs a fake OpenID Connect provider for tests
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "test-key"
+
+// Grant is what the provider answers for an authorization code
+type Grant struct {
+	// Claims go into the ID token next to iss, aud, iat and exp
+	Claims jwt.MapClaims
+	// CodeChallenge, when set, is the S256 challenge the code verifier
+	// must match
+	CodeChallenge string
+}
+
+// Server is a provider that issues ID tokens for the codes given to Grant
+type Server struct {
+	*httptest.Server
+	ClientID string
+	key      *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]Grant
+	codes  int
+	// Exchanges holds the forms the token endpoint received
+	Exchanges []map[string][]string
+}
+
+// NewServer starts a provider for clientID, closed when the test ends
+func NewServer(t *testing.T, clientID string) *Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{ClientID: clientID, key: key, grants: make(map[string]Grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"jwks_uri":               s.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Grant makes the token endpoint answer code with an ID token
+func (s *Server) Grant(code string, grant Grant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants[code] = grant
+}
+
+// Authorize plays the user signing in at authURL, which AuthCodeURL
+// returned: it grants a code for an ID token with claims and the request's
+// nonce, and returns the code and the state to call back with
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) (code, state string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		panic(err)
+	}
+	query := u.Query()
+	full := jwt.MapClaims{"nonce": query.Get("nonce")}
+	for k, v := range claims {
+		full[k] = v
+	}
+
+	s.mu.Lock()
+	s.codes++
+	code = fmt.Sprintf("code-%d", s.codes)
+	s.mu.Unlock()
+	s.Grant(code, Grant{Claims: full, CodeChallenge: query.Get("code_challenge")})
+	return code, query.Get("state")
+}
+
+// IDToken signs claims as the provider, adding iss, aud, iat and exp unless
+// claims sets them
+func (s *Server) IDToken(claims jwt.MapClaims) string {
+	full := jwt.MapClaims{
+		"iss": s.URL,
+		"aud": s.ClientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		full[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, full)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.Exchanges = append(s.Exchanges, r.PostForm)
+	grant, ok := s.grants[r.PostForm.Get("code")]
+	// Codes are single use
+	delete(s.grants, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok || r.PostForm.Get("client_id") != s.ClientID {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if grant.CodeChallenge != "" && CodeChallenge(r.PostForm.Get("code_verifier")) != grant.CodeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code verifier mismatch"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": s.IDToken(grant.Claims), "token_type": "Bearer"})
+}
+
+// CodeChallenge returns the S256 challenge of verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
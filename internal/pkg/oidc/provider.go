@@ -0,0 +1,436 @@
+// Package oidc signs users in with an OpenID Connect provider, using the
+// authorization code flow with PKCE. The provider's endpoints and signing
+// keys are discovered from its issuer URL; ID tokens are verified against
+// them, the client ID and the nonce of the request that started the flow.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuers of the providers the service supports
+const (
+	GoogleIssuer = "https://accounts.google.com"
+	AppleIssuer  = "https://appleid.apple.com"
+)
+
+const (
+	// maxResponseBytes caps what is read from the provider
+	maxResponseBytes = 1 << 20
+	// keyRefreshInterval is how often at most the signing keys are fetched
+	// again for a token signed with a key not seen yet
+	keyRefreshInterval = time.Minute
+	// clockSkew is tolerated when checking when an ID token was issued and
+	// expires
+	clockSkew = time.Minute
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid ID token")
+	ErrExchangeFailed = errors.New("authorization code exchange failed")
+)
+
+// IDToken holds the claims of a verified ID token the service uses
+type IDToken struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+	Name          string
+}
+
+// Provider is one OpenID Connect provider the service is registered with
+type Provider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret func(now time.Time) (string, error)
+	scopes       []string
+	formPost     bool
+	client       *http.Client
+	now          func() time.Time
+
+	mu            sync.Mutex
+	endpoints     *endpoints
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// endpoints are the parts of the discovery document the flow uses
+type endpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// ProviderOption configures a Provider
+type ProviderOption func(*Provider)
+
+// WithClientSecret authenticates the code exchange with a fixed secret
+func WithClientSecret(secret string) ProviderOption {
+	return func(p *Provider) {
+		p.clientSecret = func(time.Time) (string, error) { return secret, nil }
+	}
+}
+
+// WithClientSecretFunc authenticates the code exchange with a secret made
+// for each exchange, such as the signed JWT Apple expects
+func WithClientSecretFunc(secret func(now time.Time) (string, error)) ProviderOption {
+	return func(p *Provider) {
+		p.clientSecret = secret
+	}
+}
+
+// WithScopes replaces the default openid, email and profile scopes
+func WithScopes(scopes ...string) ProviderOption {
+	return func(p *Provider) {
+		p.scopes = scopes
+	}
+}
+
+// WithFormPost asks the provider to POST the authorization response to the
+// redirect URI, as Apple requires when the email or name is requested
+func WithFormPost() ProviderOption {
+	return func(p *Provider) {
+		p.formPost = true
+	}
+}
+
+// WithHTTPClient replaces the client the provider is called with
+func WithHTTPClient(client *http.Client) ProviderOption {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) ProviderOption {
+	return func(p *Provider) {
+		p.now = now
+	}
+}
+
+func NewProvider(name, issuer, clientID string, opts ...ProviderOption) *Provider {
+	p := &Provider{
+		name:     name,
+		issuer:   strings.TrimSuffix(issuer, "/"),
+		clientID: clientID,
+		scopes:   []string{"openid", "email", "profile"},
+		client:   &http.Client{Timeout: 10 * time.Second},
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name is how the provider is named in URLs and stored identities
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// FormPost reports whether the provider POSTs its authorization response
+func (p *Provider) FormPost() bool {
+	return p.formPost
+}
+
+// AuthRequest holds what the authorization request binds the flow to
+type AuthRequest struct {
+	RedirectURI string
+	State       string
+	Nonce       string
+	// CodeVerifier is kept by the caller and given to Exchange; only its
+	// S256 challenge is sent to the provider
+	CodeVerifier string
+}
+
+// AuthCodeURL returns the URL to send the user to
+func (p *Provider) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	e, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(req.CodeVerifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {req.RedirectURI},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {req.State},
+		"nonce":                 {req.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if p.formPost {
+		query.Set("response_mode", "form_post")
+	}
+
+	separator := "?"
+	if strings.Contains(e.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return e.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code for an ID token and verifies it
+func (p *Provider) Exchange(ctx context.Context, code, redirectURI, codeVerifier, nonce string) (*IDToken, error) {
+	e, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.clientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.clientSecret != nil {
+		secret, err := p.clientSecret(p.now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to make client secret: %w", err)
+		}
+		form.Set("client_secret", secret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &body)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || body.IDToken == "" {
+		// invalid_grant and the like mean the code was bad, replayed or
+		// expired: the user's fault or an attacker's, not ours
+		if body.Error != "" {
+			return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, body.Error, body.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token endpoint answered %d without an ID token", status)
+	}
+	return p.Verify(ctx, body.IDToken, nonce)
+}
+
+// idTokenClaims are the claims of an ID token as providers send them
+type idTokenClaims struct {
+	Nonce string `json:"nonce"`
+	Email string `json:"email"`
+	// EmailVerified is a bool, or the string "true" or "false" from Apple
+	EmailVerified any    `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// Verify checks that rawIDToken was signed by the provider for this client
+// and for the request that used nonce, and returns its claims
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*IDToken, error) {
+	e, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithIssuer(e.Issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (any, error) {
+		return p.keyFor(ctx, token)
+	}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	verified := false
+	switch v := claims.EmailVerified.(type) {
+	case bool:
+		verified = v
+	case string:
+		verified = v == "true"
+	}
+	return &IDToken{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: verified,
+		GivenName:     claims.GivenName,
+		FamilyName:    claims.FamilyName,
+		Name:          claims.Name,
+	}, nil
+}
+
+// keyFor returns the provider key that signed token, fetching the keys
+// again when it names one not seen yet
+func (p *Provider) keyFor(ctx context.Context, token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key, ok := p.keys[kid]
+	if !ok && p.now().Sub(p.keysFetchedAt) >= keyRefreshInterval {
+		keys, err := p.fetchKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.keys, p.keysFetchedAt = keys, p.now()
+		key, ok = p.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+	case *ecdsa.PublicKey:
+		if token.Method.Alg() != jwt.SigningMethodES256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+	}
+	return key, nil
+}
+
+// fetchKeys reads the provider's JWK set; p.mu must be held. Keys of types
+// other than RSA and P-256 are skipped.
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoints.JWKSURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	status, err := p.do(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint answered %d", status)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch {
+		case k.KeyType == "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(e) > 4 {
+				continue
+			}
+			keys[k.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case k.KeyType == "EC" && k.Curve == "P-256":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+			if !key.Curve.IsOnCurve(key.X, key.Y) {
+				continue
+			}
+			keys[k.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+// discover reads the provider's discovery document once
+func (p *Provider) discover(ctx context.Context) (*endpoints, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	var e endpoints
+	status, err := p.do(req, &e)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint of %s answered %d", p.name, status)
+	}
+	if strings.TrimSuffix(e.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("discovery document of %s names issuer %q, want %q", p.name, e.Issuer, p.issuer)
+	}
+	if e.AuthorizationEndpoint == "" || e.TokenEndpoint == "" || e.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s lacks an endpoint", p.name)
+	}
+	p.endpoints = &e
+	return p.endpoints, nil
+}
+
+// do sends req and decodes the JSON response into dest, whatever its status
+func (p *Provider) do(req *http.Request, dest any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call %s: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(dest); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("failed to decode response of %s: %w", p.name, err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,144 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	server := oidctest.NewServer(t, "client-1")
+	provider := NewProvider("google", server.URL, "client-1", WithFormPost())
+
+	raw, err := provider.AuthCodeURL(context.Background(), AuthRequest{
+		RedirectURI:  "https://api.example.com/callback",
+		State:        "state-1",
+		Nonce:        "nonce-1",
+		CodeVerifier: "verifier-1",
+	})
+	require.NoError(t, err)
+
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	query := u.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "client-1", query.Get("client_id"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, oidctest.CodeChallenge("verifier-1"), query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "form_post", query.Get("response_mode"))
+	assert.Empty(t, query.Get("code_verifier"), "the verifier never leaves the service")
+}
+
+func TestExchange(t *testing.T) {
+	server := oidctest.NewServer(t, "client-1")
+	provider := NewProvider("google", server.URL, "client-1", WithClientSecret("s3cret"))
+	ctx := context.Background()
+
+	server.Grant("code-1", oidctest.Grant{
+		Claims: jwt.MapClaims{
+			"sub": "subject-1", "nonce": "nonce-1", "email": "ada@example.com", "email_verified": true,
+			"given_name": "Ada", "family_name": "Lovelace",
+		},
+		CodeChallenge: oidctest.CodeChallenge("verifier-1"),
+	})
+	token, err := provider.Exchange(ctx, "code-1", "https://api.example.com/callback", "verifier-1", "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &IDToken{Subject: "subject-1", Email: "ada@example.com", EmailVerified: true, GivenName: "Ada", FamilyName: "Lovelace"}, token)
+	require.Len(t, server.Exchanges, 1)
+	assert.Equal(t, "s3cret", server.Exchanges[0]["client_secret"][0])
+
+	_, err = provider.Exchange(ctx, "code-1", "https://api.example.com/callback", "verifier-1", "nonce-1")
+	assert.ErrorIs(t, err, ErrExchangeFailed, "codes are single use")
+
+	server.Grant("code-2", oidctest.Grant{Claims: jwt.MapClaims{"sub": "subject-1", "nonce": "nonce-1"}, CodeChallenge: oidctest.CodeChallenge("verifier-1")})
+	_, err = provider.Exchange(ctx, "code-2", "https://api.example.com/callback", "stolen", "nonce-1")
+	assert.ErrorIs(t, err, ErrExchangeFailed)
+
+	server.Grant("code-3", oidctest.Grant{Claims: jwt.MapClaims{"sub": "subject-1", "nonce": "nonce-1"}})
+	_, err = provider.Exchange(ctx, "code-3", "https://api.example.com/callback", "verifier-1", "nonce-2")
+	assert.ErrorIs(t, err, ErrInvalidIDToken, "the nonce binds the token to the request")
+}
+
+func TestVerify(t *testing.T) {
+	server := oidctest.NewServer(t, "client-1")
+	provider := NewProvider("apple", server.URL, "client-1")
+	ctx := context.Background()
+
+	token, err := provider.Verify(ctx, server.IDToken(jwt.MapClaims{"sub": "subject-1", "nonce": "n", "email_verified": "true"}), "n")
+	require.NoError(t, err)
+	assert.True(t, token.EmailVerified, "Apple sends the flag as a string")
+
+	for name, claims := range map[string]jwt.MapClaims{
+		"other audience": {"sub": "subject-1", "nonce": "n", "aud": "client-2"},
+		"other issuer":   {"sub": "subject-1", "nonce": "n", "iss": "https://evil.example.com"},
+		"expired":        {"sub": "subject-1", "nonce": "n", "exp": time.Now().Add(-time.Hour).Unix()},
+		"no subject":     {"nonce": "n"},
+		"no nonce":       {"sub": "subject-1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := provider.Verify(ctx, server.IDToken(claims), "n")
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("signed by someone else", func(t *testing.T) {
+		other := oidctest.NewServer(t, "client-1")
+		forged := other.IDToken(jwt.MapClaims{"sub": "subject-1", "nonce": "n", "iss": server.URL})
+		_, err := provider.Verify(ctx, forged, "n")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer(t, "client-1")
+	provider := NewProvider("google", server.URL+"/tenant", "client-1")
+
+	_, err := provider.AuthCodeURL(context.Background(), AuthRequest{})
+	assert.Error(t, err)
+}
+
+func TestAppleClientSecret(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "AuthKey.p8")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	read, err := ReadAppleKey(path)
+	require.NoError(t, err)
+	now := time.Now().Truncate(time.Second)
+	secret, err := AppleClientSecret("TEAM123", "KEY123", "com.example.app", read)(now)
+	require.NoError(t, err)
+
+	claims := &jwt.RegisteredClaims{}
+	parsed, err := jwt.ParseWithClaims(secret, claims, func(*jwt.Token) (any, error) { return &key.PublicKey, nil })
+	require.NoError(t, err)
+	assert.Equal(t, "KEY123", parsed.Header["kid"])
+	assert.Equal(t, "ES256", parsed.Method.Alg())
+	assert.Equal(t, "TEAM123", claims.Issuer)
+	assert.Equal(t, "com.example.app", claims.Subject)
+	assert.Equal(t, jwt.ClaimStrings{AppleIssuer}, claims.Audience)
+	assert.Equal(t, now.Add(5*time.Minute), claims.ExpiresAt.Time)
+
+	_, err = ReadAppleKey(filepath.Join(t.TempDir(), "missing.p8"))
+	assert.ErrorContains(t, err, "failed to read Apple key")
+}
@@ -1,15 +1,59 @@
 package postgres
 
 import (
+	"database/sql"
+	"database/sql/driver"
+
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
-func NewConnection(dsn string, hasHealthCheck bool) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", dsn)
+// Option configures a connection
+type Option func(*options)
+
+type options struct {
+	queryLog *QueryLogConfig
+	pool     *PoolConfig
+}
+
+// WithQueryLog times every query and logs it as described on
+// QueryLogConfig
+func WithQueryLog(cfg QueryLogConfig) Option {
+	return func(o *options) {
+		o.queryLog = &cfg
+	}
+}
+
+func NewConnection(dsn string, hasHealthCheck bool, opts ...Option) (*sqlx.DB, error) {
+	connector, err := pq.NewConnector(dsn)
 	if err != nil {
 		return nil, err
 	}
+	return open(connector, "postgres", hasHealthCheck, opts...)
+}
+
+// open wraps connector in the configured options; driverName is what
+// DialectOf tells connections apart by
+func open(connector driver.Connector, driverName string, hasHealthCheck bool, opts ...Option) (*sqlx.DB, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.queryLog != nil && o.queryLog.Logger != nil {
+		connector = &loggingConnector{
+			Connector: connector,
+			log:       &queryLogger{logger: o.queryLog.Logger, threshold: o.queryLog.SlowThreshold},
+		}
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(connector), driverName)
+	if o.pool != nil {
+		o.pool.apply(db)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
 	// Ping the database to check if the connection is healthy
 	if hasHealthCheck {
 		if err := db.Ping(); err != nil {
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect is the SQL flavour of a connection. Queries are written for
+// Postgres, including $n placeholders, which SQLite connections opened by
+// NewSQLiteConnection accept; Dialect covers the constructs that differ.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// DialectOf tells which database db talks to
+func DialectOf(db *sqlx.DB) Dialect {
+	if db != nil && db.DriverName() == sqliteDriverName {
+		return DialectSQLite
+	}
+	return DialectPostgres
+}
+
+// ILike is the case-insensitive LIKE operator. SQLite's LIKE ignores case
+// for ASCII letters, which is as close as it gets.
+func (d Dialect) ILike() string {
+	if d == DialectSQLite {
+		return "LIKE"
+	}
+	return "ILIKE"
+}
+
+// LikeEscape is the clause that makes a backslash escape LIKE wildcards, as
+// it does by default in Postgres
+func (d Dialect) LikeEscape() string {
+	if d == DialectSQLite {
+		return ` ESCAPE '\'`
+	}
+	return ""
+}
+
+// Decimal casts a numeric expression so averages of integers keep their
+// fractions
+func (d Dialect) Decimal(expr string) string {
+	if d == DialectSQLite {
+		return "CAST(" + expr + " AS REAL)"
+	}
+	return expr + "::decimal"
+}
+
+// InList renders "expr IN (...)" with one placeholder per value, appended
+// to args. Postgres passes a list as a single array with = ANY($n), which
+// SQLite has no type for.
+func (d Dialect) InList(expr string, values []string, args []interface{}) (string, []interface{}) {
+	if d == DialectPostgres {
+		args = append(args, pq.Array(values))
+		return fmt.Sprintf("%s = ANY($%d)", expr, len(args)), args
+	}
+	if len(values) == 0 {
+		return "FALSE", args
+	}
+	placeholders := make([]byte, 0, len(values)*4)
+	for i, value := range values {
+		if i > 0 {
+			placeholders = append(placeholders, ", "...)
+		}
+		args = append(args, value)
+		placeholders = fmt.Appendf(placeholders, "$%d", len(args))
+	}
+	return fmt.Sprintf("%s IN (%s)", expr, placeholders), args
+}
+
+// IsUniqueViolation reports whether err is a unique or primary key
+// constraint violation
+func (d Dialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"thermondo/internal/pkg/metrics"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PoolConfig sizes the connection pool. Zero values keep the database/sql
+// defaults: unlimited open connections, 2 idle connections and no maximum
+// lifetime or idle time.
+type PoolConfig struct {
+	MaxOpenConns int
+	MaxIdleConns int
+	// ConnMaxLifetime recycles connections, so they are spread over
+	// database replicas and poolers again after a failover
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections left over from a burst
+	ConnMaxIdleTime time.Duration
+}
+
+// WithPool applies cfg to the connection pool
+func WithPool(cfg PoolConfig) Option {
+	return func(o *options) {
+		o.pool = &cfg
+	}
+}
+
+func (cfg PoolConfig) apply(db *sqlx.DB) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
+
+var (
+	poolOpen           = metrics.NewGauge("postgres_pool_open_connections", "Open connections, in use or idle")
+	poolInUse          = metrics.NewGauge("postgres_pool_in_use_connections", "Connections in use")
+	poolIdle           = metrics.NewGauge("postgres_pool_idle_connections", "Idle connections")
+	poolMaxOpen        = metrics.NewGauge("postgres_pool_max_open_connections", "Maximum open connections; 0 is unlimited")
+	poolWaits          = metrics.NewCounter("postgres_pool_waits_total", "Times a query waited for a free connection")
+	poolWaitSeconds    = metrics.NewGauge("postgres_pool_wait_seconds_total", "Time spent waiting for a free connection")
+	poolClosedIdle     = metrics.NewCounter("postgres_pool_closed_idle_total", "Connections closed by MaxIdleConns or ConnMaxIdleTime")
+	poolClosedLifetime = metrics.NewCounter("postgres_pool_closed_lifetime_total", "Connections closed by ConnMaxLifetime")
+)
+
+// MonitorPool records the pool statistics every interval until ctx is done.
+// It logs them at debug level, or at warn level when queries had to wait
+// for a connection during the interval, which means the pool is too small
+// for the load.
+func MonitorPool(ctx context.Context, db *sqlx.DB, logger *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := db.Stats()
+	recordPoolStats(last, last)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := db.Stats()
+			recordPoolStats(last, stats)
+			logPoolStats(ctx, logger, last, stats)
+			last = stats
+		}
+	}
+}
+
+func logPoolStats(ctx context.Context, logger *slog.Logger, last, stats sql.DBStats) {
+	waits := stats.WaitCount - last.WaitCount
+	level := slog.LevelDebug
+	if waits > 0 {
+		level = slog.LevelWarn
+	}
+	logger.LogAttrs(ctx, level, "Connection pool stats",
+		slog.Int("open", stats.OpenConnections),
+		slog.Int("in_use", stats.InUse),
+		slog.Int("idle", stats.Idle),
+		slog.Int("max_open", stats.MaxOpenConnections),
+		slog.Int64("waits", waits),
+		slog.Duration("wait_duration", stats.WaitDuration-last.WaitDuration),
+	)
+}
+
+func recordPoolStats(last, stats sql.DBStats) {
+	poolOpen.Set(float64(stats.OpenConnections))
+	poolInUse.Set(float64(stats.InUse))
+	poolIdle.Set(float64(stats.Idle))
+	poolMaxOpen.Set(float64(stats.MaxOpenConnections))
+	poolWaitSeconds.Set(stats.WaitDuration.Seconds())
+	poolWaits.Add(stats.WaitCount - last.WaitCount)
+	poolClosedIdle.Add((stats.MaxIdleClosed + stats.MaxIdleTimeClosed) - (last.MaxIdleClosed + last.MaxIdleTimeClosed))
+	poolClosedLifetime.Add(stats.MaxLifetimeClosed - last.MaxLifetimeClosed)
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolConfig(t *testing.T) {
+	db, err := open(fakeConnector{}, "postgres", false, WithPool(PoolConfig{
+		MaxOpenConns:    1,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+		ConnMaxIdleTime: time.Second,
+	}))
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, 1, db.Stats().MaxOpenConnections)
+}
+
+func TestPoolStats(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	last := sql.DBStats{MaxOpenConnections: 10, OpenConnections: 4, WaitCount: 2, MaxLifetimeClosed: 1}
+
+	calm := last
+	logPoolStats(context.Background(), logger, last, calm)
+	assert.Empty(t, buf.String(), "without waits the stats are logged at debug level")
+
+	busy := last
+	busy.OpenConnections, busy.InUse = 10, 10
+	busy.WaitCount, busy.WaitDuration = 5, 300*time.Millisecond
+	busy.MaxLifetimeClosed = 3
+	waitsBefore, closedBefore := poolWaits.Value(), poolClosedLifetime.Value()
+
+	recordPoolStats(last, busy)
+	logPoolStats(context.Background(), logger, last, busy)
+
+	assert.Contains(t, buf.String(), `"waits":3`)
+	assert.Contains(t, buf.String(), `"in_use":10`)
+	assert.Equal(t, waitsBefore+3, poolWaits.Value())
+	assert.Equal(t, closedBefore+2, poolClosedLifetime.Value())
+	assert.Equal(t, float64(10), poolInUse.Value())
+	assert.Equal(t, 0.3, poolWaitSeconds.Value())
+}
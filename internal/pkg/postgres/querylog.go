@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"thermondo/internal/pkg/metrics"
+)
+
+var slowQueries = metrics.NewCounter("postgres_slow_queries_total", "Queries that took at least the slow query threshold")
+
+// QueryLogConfig configures query logging. Queries taking at least
+// SlowThreshold are logged at warn level and counted in
+// postgres_slow_queries_total; all others are logged at debug level, so
+// they show up when the logger's level is lowered.
+type QueryLogConfig struct {
+	Logger *slog.Logger
+	// SlowThreshold of 0 treats no query as slow
+	SlowThreshold time.Duration
+}
+
+// queryLogger wraps a connector so every statement run through it is timed.
+// Parameters are logged by type and size only, never by value, since they
+// hold emails, password hashes and tokens.
+type queryLogger struct {
+	logger    *slog.Logger
+	threshold time.Duration
+}
+
+func (l *queryLogger) observe(ctx context.Context, query string, args []driver.NamedValue, start time.Time, err error) {
+	if err == driver.ErrSkip {
+		// database/sql retries through a prepared statement, which is logged
+		return
+	}
+	elapsed := time.Since(start)
+
+	level, msg := slog.LevelDebug, "Query"
+	if l.threshold > 0 && elapsed >= l.threshold {
+		slowQueries.Inc()
+		level, msg = slog.LevelWarn, "Slow query"
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", strings.Join(strings.Fields(query), " ")),
+		slog.Any("args", sanitizeArgs(args)),
+		slog.Duration("duration", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// sanitizeArgs describes query parameters without revealing text or binary
+// values. Numbers, booleans, times and NULL are kept as they matter for
+// reproducing a query plan and are not sensitive.
+func sanitizeArgs(args []driver.NamedValue) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		switch v := arg.Value.(type) {
+		case nil:
+			out[i] = "NULL"
+		case string:
+			out[i] = fmt.Sprintf("<string len=%d>", len(v))
+		case []byte:
+			out[i] = fmt.Sprintf("<bytes len=%d>", len(v))
+		case time.Time:
+			out[i] = v.UTC().Format(time.RFC3339Nano)
+		default:
+			out[i] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+type loggingConnector struct {
+	driver.Connector
+	log *queryLogger
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &loggingConn{Conn: conn, log: c.log}, nil
+}
+
+// loggingConn forwards the optional driver interfaces database/sql looks
+// for, so wrapping keeps the driver's fast paths
+type loggingConn struct {
+	driver.Conn
+	log *queryLogger
+}
+
+func (c *loggingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.log.observe(ctx, query, args, start, err)
+	return rows, err
+}
+
+func (c *loggingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.log.observe(ctx, query, args, start, err)
+	return result, err
+}
+
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &loggingStmt{Stmt: stmt, query: query, log: c.log}, nil
+}
+
+func (c *loggingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *loggingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *loggingConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *loggingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type loggingStmt struct {
+	driver.Stmt
+	query string
+	log   *queryLogger
+}
+
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedToValues(args))
+	}
+	s.log.observe(ctx, s.query, args, start, err)
+	return rows, err
+}
+
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedToValues(args))
+	}
+	s.log.observe(ctx, s.query, args, start, err)
+	return result, err
+}
+
+func namedToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
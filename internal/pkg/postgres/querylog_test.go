@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConnector executes nothing; queries mentioning pg_sleep take 20ms and
+// queries mentioning missing_table fail
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "pg_sleep") {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if strings.Contains(query, "missing_table") {
+		return nil, errors.New(`relation "missing_table" does not exist`)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	db, err := open(fakeConnector{}, "postgres", false, WithQueryLog(QueryLogConfig{Logger: logger, SlowThreshold: 10 * time.Millisecond}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := slowQueries.Value()
+	ctx := context.Background()
+
+	_, err = db.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", "Jane", 7)
+	require.NoError(t, err)
+	assert.Empty(t, buf.String(), "fast queries are only logged at debug level")
+
+	_, err = db.ExecContext(ctx, `SELECT pg_sleep(1)
+		FROM users WHERE email = $1 AND created_at > $2 AND deleted IS $3`,
+		"jane@example.com", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	records := decodeLogs(t, &buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "Slow query", records[0]["msg"])
+	assert.Equal(t, "SELECT pg_sleep(1) FROM users WHERE email = $1 AND created_at > $2 AND deleted IS $3", records[0]["query"])
+	assert.Equal(t, []any{"<string len=16>", "2024-01-02T03:04:05Z", "NULL"}, records[0]["args"])
+	assert.NotContains(t, buf.String(), "jane@example.com")
+	assert.Equal(t, before+1, slowQueries.Value())
+}
+
+func TestQueryLogDebug(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db, err := open(fakeConnector{}, "postgres", false, WithQueryLog(QueryLogConfig{Logger: logger}))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(context.Background(), "DELETE FROM missing_table WHERE id = $1", int64(3))
+	require.Error(t, err)
+
+	records := decodeLogs(t, &buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "Query", records[0]["msg"])
+	assert.Equal(t, "DEBUG", records[0]["level"])
+	assert.Equal(t, []any{"3"}, records[0]["args"])
+	assert.Contains(t, records[0]["error"], "does not exist")
+}
@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the driver name SQLite connections report, the same
+// mattn/go-sqlite3 registers itself under
+const sqliteDriverName = "sqlite3"
+
+// minSQLiteVersion is 3.35, the first release with RETURNING
+const minSQLiteVersion = 3035000
+
+// sqliteTimeFormat stores timestamps in UTC with a fixed width, so they
+// compare as text in the same order as in time
+const sqliteTimeFormat = "2006-01-02 15:04:05.000000000+00:00"
+
+// NewSQLiteConnection opens the SQLite database file at path, creating it
+// if needed. The connection accepts the Postgres-style $n placeholders the
+// repositories are written with, and adds the Postgres functions they use
+// that SQLite lacks: now(), regexp_replace and pg_trgm's similarity.
+func NewSQLiteConnection(path string, opts ...Option) (*sqlx.DB, error) {
+	if _, version, _ := sqlite3.Version(); version < minSQLiteVersion {
+		return nil, fmt.Errorf("SQLite 3.35 or later is required for RETURNING, have %d", version)
+	}
+
+	// Write transactions take the lock when they begin rather than failing
+	// when a read lock cannot be upgraded; writers wait up to the busy
+	// timeout for each other
+	dsn := "file:" + path + "?_foreign_keys=1&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+	return open(&sqliteConnector{dsn: dsn}, sqliteDriverName, false, opts...)
+}
+
+var (
+	sqliteDriverOnce sync.Once
+	sqliteDriver     *sqlite3.SQLiteDriver
+)
+
+func registeredSQLiteDriver() *sqlite3.SQLiteDriver {
+	sqliteDriverOnce.Do(func() {
+		sqliteDriver = &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				if err := conn.RegisterFunc("now", sqliteNow, false); err != nil {
+					return err
+				}
+				if err := conn.RegisterFunc("regexp_replace", regexpReplace, true); err != nil {
+					return err
+				}
+				return conn.RegisterFunc("similarity", Similarity, true)
+			},
+		}
+	})
+	return sqliteDriver
+}
+
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c *sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{Conn: conn}, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return registeredSQLiteDriver()
+}
+
+// sqliteConn rewrites statements and their arguments for SQLite on the way
+// to the driver
+type sqliteConn struct {
+	driver.Conn
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, rebindSQLite(query), sqliteArgs(args))
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, rebindSQLite(query), sqliteArgs(args))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, rebindSQLite(query))
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteStmt{Stmt: stmt}, nil
+}
+
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *sqliteConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+type sqliteStmt struct {
+	driver.Stmt
+}
+
+func (s *sqliteStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, sqliteArgs(args))
+}
+
+func (s *sqliteStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, sqliteArgs(args))
+}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// rebindSQLite turns $n placeholders into ?n. SQLite reads $n as a named
+// parameter and numbers those by first appearance, so "$2 ... $1" would
+// bind the arguments the wrong way round; ?n binds argument n.
+func rebindSQLite(query string) string {
+	return placeholder.ReplaceAllString(query, "?$1")
+}
+
+// sqliteArgs stores times as sqliteTimeFormat text
+func sqliteArgs(args []driver.NamedValue) []driver.NamedValue {
+	out := args
+	for i, arg := range args {
+		if t, ok := arg.Value.(time.Time); ok {
+			if &out[0] == &args[0] {
+				out = append([]driver.NamedValue(nil), args...)
+			}
+			out[i].Value = t.UTC().Format(sqliteTimeFormat)
+		}
+	}
+	return out
+}
+
+// sqliteNow is now() as stored in sqliteTimeFormat
+func sqliteNow() string {
+	return time.Now().UTC().Format(sqliteTimeFormat)
+}
+
+// regexpReplace is Postgres' regexp_replace(source, pattern, replacement,
+// flags) for the flags the repositories use: g replaces every match rather
+// than the first
+func regexpReplace(source, pattern, replacement, flags string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(flags, "g") {
+		return re.ReplaceAllString(source, replacement), nil
+	}
+	if loc := re.FindStringIndex(source); loc != nil {
+		return source[:loc[0]] + re.ReplaceAllString(source[loc[0]:loc[1]], replacement) + source[loc[1]:], nil
+	}
+	return source, nil
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteConnection(t *testing.T) {
+	db, err := NewSQLiteConnection(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, DialectSQLite, DialectOf(db))
+
+	var difference int
+	require.NoError(t, db.QueryRow(`SELECT $2 - $1`, 1, 5).Scan(&difference))
+	assert.Equal(t, 4, difference, "$n binds the nth argument wherever it appears")
+
+	_, err = db.Exec(`CREATE TABLE events (id TEXT PRIMARY KEY, at TIMESTAMP NOT NULL)`)
+	require.NoError(t, err)
+	berlin := time.FixedZone("CET", 3600)
+	earlier := time.Date(2024, 5, 1, 12, 0, 0, 500, berlin)
+	later := time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC)
+	_, err = db.Exec(`INSERT INTO events (id, at) VALUES ($1, $2), ($3, $4)`, "earlier", earlier, "later", later)
+	require.NoError(t, err)
+
+	var (
+		id string
+		at time.Time
+	)
+	require.NoError(t, db.QueryRow(`SELECT id, at FROM events WHERE at > $1`, earlier).Scan(&id, &at))
+	assert.Equal(t, "later", id, "times compare in time order whatever their zone")
+	assert.True(t, later.Equal(at))
+
+	_, err = db.Exec(`INSERT INTO events (id, at) VALUES ($1, now())`, "later")
+	assert.True(t, DialectOf(db).IsUniqueViolation(err))
+
+	var normalized string
+	var score float64
+	require.NoError(t, db.QueryRow(`SELECT regexp_replace(lower($1), '[^a-z0-9]+', '', 'g'), similarity('The Godfather', 'godfather')`,
+		"Se7en: The Movie").Scan(&normalized, &score))
+	assert.Equal(t, "se7enthemovie", normalized)
+	assert.InDelta(t, 10.0/13, score, 0.001)
+}
+
+func TestDialectInList(t *testing.T) {
+	condition, args := DialectSQLite.InList("id", []string{"a", "b"}, []interface{}{true})
+	assert.Equal(t, "id IN ($2, $3)", condition)
+	assert.Equal(t, []interface{}{true, "a", "b"}, args)
+
+	condition, args = DialectSQLite.InList("id", nil, nil)
+	assert.Equal(t, "FALSE", condition)
+	assert.Empty(t, args)
+
+	condition, args = DialectPostgres.InList("id", []string{"a"}, nil)
+	assert.Equal(t, "id = ANY($1)", condition)
+	assert.Len(t, args, 1)
+}
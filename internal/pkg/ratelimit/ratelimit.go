@@ -0,0 +1,110 @@
+// Package ratelimit provides a small in-process fixed-window rate limiter.
+// Counters live in memory, so each instance of the service enforces its own
+// limit.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows up to limit events per key in each window
+type Limiter struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*counter
+	lastSweep time.Time
+}
+
+type counter struct {
+	start time.Time
+	count int
+}
+
+// Option configures a Limiter
+type Option func(*Limiter)
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+func New(limit int, window time.Duration, opts ...Option) *Limiter {
+	l := &Limiter{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		windows: make(map[string]*counter),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.lastSweep = l.now()
+	return l
+}
+
+// Allow records an event for key and reports whether it is within the
+// limit. When it is not, retryAfter says when the current window ends. A
+// limit of 0 or less allows everything.
+func (l *Limiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.allow(key, l.limit)
+}
+
+// AllowUpTo is Allow with a limit of its own for key, such as one set per
+// API key. The limiter's window still applies.
+func (l *Limiter) AllowUpTo(key string, limit int) (allowed bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.allow(key, limit)
+}
+
+func (l *Limiter) allow(key string, limit int) (bool, time.Duration) {
+	if limit <= 0 {
+		return true, 0
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	c, ok := l.windows[key]
+	if !ok || now.Sub(c.start) >= l.window {
+		c = &counter{start: now}
+		l.windows[key] = c
+	}
+
+	if c.count >= limit {
+		return false, c.start.Add(l.window).Sub(now)
+	}
+	c.count++
+	return true, 0
+}
+
+// SetLimit changes the limit and window at runtime. Counts in open windows
+// are kept, so lowering the limit takes effect immediately.
+func (l *Limiter) SetLimit(limit int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = limit
+	l.window = window
+}
+
+// sweep drops expired windows once per window so idle keys don't pile up
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, c := range l.windows {
+		if now.Sub(c.start) >= l.window {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
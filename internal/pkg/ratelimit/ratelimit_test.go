@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(2, time.Minute, WithClock(func() time.Time { return now }))
+
+	allowed, _ := limiter.Allow("1.2.3.4")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("1.2.3.4")
+	assert.True(t, allowed)
+
+	now = now.Add(20 * time.Second)
+	allowed, retryAfter := limiter.Allow("1.2.3.4")
+	assert.False(t, allowed)
+	assert.Equal(t, 40*time.Second, retryAfter)
+
+	// Keys are counted separately
+	allowed, _ = limiter.Allow("5.6.7.8")
+	assert.True(t, allowed)
+
+	// A new window starts once the old one has passed
+	now = now.Add(40 * time.Second)
+	allowed, _ = limiter.Allow("1.2.3.4")
+	assert.True(t, allowed)
+}
+
+func TestLimiterSweepsExpiredWindows(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(1, time.Minute, WithClock(func() time.Time { return now }))
+
+	limiter.Allow("a")
+	limiter.Allow("b")
+	assert.Len(t, limiter.windows, 2)
+
+	now = now.Add(2 * time.Minute)
+	limiter.Allow("c")
+	assert.Len(t, limiter.windows, 1)
+}
+
+func TestLimiterSetLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(0, time.Minute, WithClock(func() time.Time { return now }))
+
+	// A zero limit is disabled
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.Allow("1.2.3.4")
+		assert.True(t, allowed)
+	}
+
+	limiter.SetLimit(1, time.Minute)
+	allowed, _ := limiter.Allow("1.2.3.4")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("1.2.3.4")
+	assert.False(t, allowed)
+
+	limiter.SetLimit(2, time.Minute)
+	allowed, _ = limiter.Allow("1.2.3.4")
+	assert.True(t, allowed)
+}
+
+func TestLimiterAllowUpTo(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(0, time.Minute, WithClock(func() time.Time { return now }))
+
+	allowed, _ := limiter.AllowUpTo("key-small", 1)
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.AllowUpTo("key-small", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Minute, retryAfter)
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := limiter.AllowUpTo("key-large", 3)
+		assert.True(t, allowed)
+	}
+	allowed, _ = limiter.AllowUpTo("key-unlimited", 0)
+	assert.True(t, allowed)
+}
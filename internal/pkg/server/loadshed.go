@@ -0,0 +1,224 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/metrics"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var (
+	shedRequests     = metrics.NewCounterVec("http_requests_shed_total", "Requests rejected with 503 by the load shedder, by priority", "priority")
+	requestsInFlight = metrics.NewGauge("http_requests_in_flight", "Requests being served")
+	concurrencyLimit = metrics.NewGauge("http_concurrency_limit", "Requests the load shedder currently lets run at once")
+)
+
+// Priority orders requests for load shedding: the lower the priority, the
+// earlier a request is shed as the server nears its concurrency limit
+type Priority int
+
+const (
+	// PriorityLow requests, lists and searches, are shed first, once the
+	// requests in flight reach lowPriorityShare of the limit
+	PriorityLow Priority = iota
+	// PriorityNormal requests are shed once the limit is reached
+	PriorityNormal
+	// PriorityCritical requests, writes and health checks, are never shed
+	PriorityCritical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	default:
+		return "critical"
+	}
+}
+
+const (
+	DefaultMinConcurrency = 10
+	DefaultMaxConcurrency = 200
+	DefaultTargetLatency  = 500 * time.Millisecond
+
+	// lowPriorityShare is the part of the limit low priority requests may
+	// fill, which leaves headroom for the rest before the limit is reached
+	lowPriorityShare = 0.75
+	// backoffFactor shrinks the limit when requests get slow
+	backoffFactor = 0.9
+)
+
+// Classifier tells the priority of a request
+type Classifier func(r *http.Request) Priority
+
+// DefaultClassifier protects health checks, metrics and every write, and
+// gives lists and searches the lowest priority: searches and suggestions,
+// and bare collections such as GET /api/v1/movies
+func DefaultClassifier(r *http.Request) Priority {
+	switch r.URL.Path {
+	case "/health", "/ready", "/metrics":
+		return PriorityCritical
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return PriorityCritical
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, segment := range segments {
+		if segment == "search" || segment == "suggest" {
+			return PriorityLow
+		}
+	}
+	if len(segments) == 3 && segments[0] == "api" && segments[1] == "v1" {
+		return PriorityLow
+	}
+	return PriorityNormal
+}
+
+// LoadShedder limits the requests served at once with an adaptive limit:
+// the limit grows by about one for every limit requests that finish within
+// the target latency, and shrinks by a tenth, at most once per target
+// latency, when requests are slower or time out. Requests over the limit
+// are answered with 503 and Retry-After right away instead of queueing up
+// behind the slow ones.
+type LoadShedder struct {
+	writer        *response.Writer
+	classify      Classifier
+	now           func() time.Time
+	minLimit      float64
+	maxLimit      float64
+	targetLatency time.Duration
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+}
+
+// LoadShedOption configures optional settings of the load shedder
+type LoadShedOption func(*LoadShedder)
+
+// WithConcurrencyLimits bounds the adaptive limit. It starts at max.
+func WithConcurrencyLimits(min, max int) LoadShedOption {
+	return func(s *LoadShedder) {
+		if min > 0 && max >= min {
+			s.minLimit, s.maxLimit = float64(min), float64(max)
+		}
+	}
+}
+
+// WithTargetLatency sets the latency above which the limit shrinks
+func WithTargetLatency(d time.Duration) LoadShedOption {
+	return func(s *LoadShedder) {
+		if d > 0 {
+			s.targetLatency = d
+		}
+	}
+}
+
+// WithClassifier replaces DefaultClassifier
+func WithClassifier(classify Classifier) LoadShedOption {
+	return func(s *LoadShedder) {
+		s.classify = classify
+	}
+}
+
+// WithShedClock replaces time.Now, for tests
+func WithShedClock(now func() time.Time) LoadShedOption {
+	return func(s *LoadShedder) {
+		s.now = now
+	}
+}
+
+func NewLoadShedder(writer *response.Writer, opts ...LoadShedOption) *LoadShedder {
+	s := &LoadShedder{
+		writer:        writer,
+		classify:      DefaultClassifier,
+		now:           time.Now,
+		minLimit:      DefaultMinConcurrency,
+		maxLimit:      DefaultMaxConcurrency,
+		targetLatency: DefaultTargetLatency,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.limit = s.maxLimit
+	concurrencyLimit.Set(s.limit)
+	return s
+}
+
+// Handler sheds the requests to next that do not fit in the current limit
+func (s *LoadShedder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		priority := s.classify(r)
+		if !s.acquire(priority) {
+			shedRequests.With(priority.String()).Inc()
+			w.Header().Set("Retry-After", "1")
+			s.writer.WriteProblem(w, r, response.NewProblem(http.StatusServiceUnavailable, appErrors.CodeServiceUnavailable, "The server is busy, try again shortly"))
+			return
+		}
+
+		start := s.now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			s.release(s.now().Sub(start), ww.Status())
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// Limit returns the current concurrency limit
+func (s *LoadShedder) Limit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int(s.limit)
+}
+
+func (s *LoadShedder) acquire(priority Priority) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if priority != PriorityCritical {
+		threshold := s.limit
+		if priority == PriorityLow {
+			threshold *= lowPriorityShare
+		}
+		if float64(s.inFlight) >= threshold {
+			return false
+		}
+	}
+	s.inFlight++
+	requestsInFlight.Set(float64(s.inFlight))
+	return true
+}
+
+// release adapts the limit to how the finished request went. Only slowness
+// and timeouts count as overload; other server errors are bugs, not load.
+func (s *LoadShedder) release(latency time.Duration, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inFlight--
+	requestsInFlight.Set(float64(s.inFlight))
+
+	overloaded := latency > s.targetLatency ||
+		status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+	if overloaded {
+		now := s.now()
+		if now.Sub(s.lastDecrease) < s.targetLatency {
+			return
+		}
+		s.lastDecrease = now
+		s.limit = max(s.minLimit, s.limit*backoffFactor)
+	} else {
+		s.limit = min(s.maxLimit, s.limit+1/s.limit)
+	}
+	concurrencyLimit.Set(s.limit)
+}
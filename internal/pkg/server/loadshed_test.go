@@ -0,0 +1,127 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/http/response"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         Priority
+	}{
+		{http.MethodGet, "/health", PriorityCritical},
+		{http.MethodGet, "/metrics", PriorityCritical},
+		{http.MethodPost, "/api/v1/ratings", PriorityCritical},
+		{http.MethodDelete, "/api/v1/movies/movie-1", PriorityCritical},
+		{http.MethodGet, "/api/v1/movies", PriorityLow},
+		{http.MethodGet, "/api/v1/movies/", PriorityLow},
+		{http.MethodGet, "/api/v1/search/movies", PriorityLow},
+		{http.MethodGet, "/api/v1/movies/suggest", PriorityLow},
+		{http.MethodGet, "/api/v1/reviews/search", PriorityLow},
+		{http.MethodGet, "/api/v1/movies/movie-1", PriorityNormal},
+		{http.MethodGet, "/api/v1/movies/movie-1/stats", PriorityNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, DefaultClassifier(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestLoadShedder(t *testing.T) {
+	writer := response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	t.Run("sheds low priority requests first and never critical ones", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		shedder := NewLoadShedder(writer, WithConcurrencyLimits(4, 4))
+		h := shedder.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+		}))
+		serve := func(method, path string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+			return w
+		}
+
+		var wg sync.WaitGroup
+		block := func(method, path string) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, http.StatusOK, serve(method, path).Code)
+			}()
+			<-started
+		}
+
+		// Low priority requests may fill three quarters of the limit
+		for range 3 {
+			block(http.MethodGet, "/api/v1/movies")
+		}
+		shed := serve(http.MethodGet, "/api/v1/search/movies")
+		assert.Equal(t, http.StatusServiceUnavailable, shed.Code)
+		assert.Equal(t, "1", shed.Header().Get("Retry-After"))
+
+		block(http.MethodGet, "/api/v1/movies/movie-1")
+		assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodGet, "/api/v1/movies/movie-2").Code)
+
+		block(http.MethodPost, "/api/v1/ratings")
+		block(http.MethodGet, "/health")
+
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("adapts the limit to latency", func(t *testing.T) {
+		var (
+			mu      sync.Mutex
+			now     = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			latency time.Duration
+		)
+		clock := func() time.Time {
+			mu.Lock()
+			defer mu.Unlock()
+			return now
+		}
+		shedder := NewLoadShedder(writer, WithConcurrencyLimits(5, 20), WithTargetLatency(100*time.Millisecond), WithShedClock(clock))
+		h := shedder.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			now = now.Add(latency)
+			mu.Unlock()
+		}))
+		serve := func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/movies/movie-1", nil))
+		}
+		require.Equal(t, 20, shedder.Limit())
+
+		latency = time.Second
+		serve()
+		assert.Equal(t, 18, shedder.Limit())
+		for range 50 {
+			serve()
+		}
+		assert.Equal(t, 5, shedder.Limit(), "never below the minimum")
+
+		latency = 10 * time.Millisecond
+		for range 30 {
+			serve()
+		}
+		assert.Greater(t, shedder.Limit(), 5)
+		for range 1000 {
+			serve()
+		}
+		assert.Equal(t, 20, shedder.Limit(), "never above the maximum")
+	})
+}
@@ -1,5 +1,10 @@
 package server
 
+import (
+	"context"
+	"time"
+)
+
 type ServerOption func(*Server)
 
 func WithHealthChecker(hc HealthChecker) ServerOption {
@@ -13,3 +18,20 @@ func WithRouter(router RouterProvider) ServerOption {
 		s.router = router
 	}
 }
+
+// WithLoadShedder sheds requests in front of the router when the server is
+// overloaded
+func WithLoadShedder(shedder *LoadShedder) ServerOption {
+	return func(s *Server) {
+		s.loadShedder = shedder
+	}
+}
+
+// WithWarmup runs warmup once the server listens and holds off readiness
+// until it returns or timeout (0 for none) has passed
+func WithWarmup(warmup func(ctx context.Context) error, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.warmup = warmup
+		s.warmupTimeout = timeout
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"thermondo/config"
 	"time"
@@ -49,6 +50,13 @@ type Server struct {
 	state         ServerState
 	healthChecker HealthChecker
 	router        RouterProvider
+	loadShedder   *LoadShedder
+
+	// warmup runs once the server listens; until it is done the server is
+	// not ready
+	warmup        func(ctx context.Context) error
+	warmupTimeout time.Duration
+	warmed        atomic.Bool
 
 	// Channels for coordinating server lifecycle
 	shutdownCh chan struct{}
@@ -85,10 +93,15 @@ func NewServer(conf config.Configuration, logger *slog.Logger, opts ...ServerOpt
 		return nil, fmt.Errorf("%w: router provider is required", ErrInvalidConfiguration)
 	}
 
+	handler := server.router.Handler()
+	if server.loadShedder != nil {
+		handler = server.loadShedder.Handler(handler)
+	}
+
 	// Setup HTTP server with timeouts and secure defaults
 	server.httpServer = &http.Server{
 		Addr:         net.JoinHostPort(conf.Server.Host, conf.Server.Port),
-		Handler:      server.router.Handler(),
+		Handler:      handler,
 		ReadTimeout:  time.Duration(conf.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(conf.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(conf.Server.IdleTimeout) * time.Second,
@@ -129,6 +142,12 @@ func (s *Server) Start(ctx context.Context) error {
 		s.doneCh <- nil
 	}()
 
+	if s.warmup != nil {
+		go s.runWarmup(ctx)
+	} else {
+		s.warmed.Store(true)
+	}
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -219,7 +238,26 @@ func (s *Server) HealthStatus(ctx context.Context) error {
 	return nil
 }
 
-// IsReady returns whether the server is ready to handle requests
+// IsReady returns whether the server is ready to handle requests, that is
+// running with its warmup done
 func (s *Server) IsReady() bool {
-	return s.state == StateRunning
+	return s.state == StateRunning && s.warmed.Load()
+}
+
+// runWarmup runs the warmup within its timeout. The server turns ready
+// afterwards even if the warmup failed, as it only saves later requests
+// work.
+func (s *Server) runWarmup(ctx context.Context) {
+	defer s.warmed.Store(true)
+
+	if s.warmupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.warmupTimeout)
+		defer cancel()
+	}
+
+	s.logger.Info("Warming up before reporting ready")
+	if err := s.warmup(ctx); err != nil {
+		s.logger.Warn("Warmup incomplete", slog.String("error", err.Error()))
+	}
 }
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"thermondo/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type handlerProvider struct{}
+
+func (handlerProvider) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func newTestServer(t *testing.T, opts ...ServerOption) *Server {
+	t.Helper()
+	conf := config.Configuration{Server: config.ServerConfig{Port: "0", ShutdownTimeout: time.Second}}
+	srv, err := NewServer(conf, slog.New(slog.NewTextHandler(io.Discard, nil)), append(opts, WithRouter(handlerProvider{}))...)
+	require.NoError(t, err)
+	return srv
+}
+
+func TestServer_Warmup(t *testing.T) {
+	t.Run("is not ready until the warmup is done", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := newTestServer(t, WithWarmup(func(ctx context.Context) error {
+			<-release
+			return errors.New("cache unavailable")
+		}, 0))
+		srv.state = StateRunning
+
+		done := make(chan struct{})
+		go func() {
+			srv.runWarmup(context.Background())
+			close(done)
+		}()
+
+		assert.False(t, srv.IsReady())
+		close(release)
+		<-done
+		assert.True(t, srv.IsReady(), "a failed warmup does not keep the server from being ready")
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		srv := newTestServer(t, WithWarmup(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, 10*time.Millisecond))
+		srv.state = StateRunning
+
+		srv.runWarmup(context.Background())
+
+		assert.True(t, srv.IsReady())
+	})
+}
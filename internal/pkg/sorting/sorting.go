@@ -0,0 +1,172 @@
+// Package sorting parses multi-column sorts such as
+// "release_year:desc,title:asc" against a whitelist of fields, and applies
+// them as an ORDER BY list or to a slice in memory. Handlers parse a sort
+// to reject bad input; repositories resolve it again against the columns
+// or comparators they know, so nothing unchecked reaches a query.
+package sorting
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MaxKeys caps how many fields one sort may use
+const MaxKeys = 4
+
+var (
+	ErrUnknownField   = errors.New("unknown sort field")
+	ErrDuplicateField = errors.New("sort field given twice")
+	ErrInvalidOrder   = errors.New("sort order must be asc or desc")
+	ErrTooManyFields  = fmt.Errorf("at most %d sort fields can be combined", MaxKeys)
+	ErrAmbiguous      = errors.New("use either sort or sort_by, not both")
+)
+
+// Key is one field of a sort
+type Key struct {
+	Field string
+	Desc  bool
+}
+
+// Parse reads a comma-separated sort whose fields may carry a direction,
+// e.g. "release_year:desc,title". Fields without one are sorted in order,
+// which is "asc", "desc" or empty for ascending. Every field must be in
+// allowed.
+func Parse(spec, order string, allowed []string) ([]Key, error) {
+	defaultDesc, err := isDesc(order)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(spec, ",")
+	if len(parts) > MaxKeys {
+		return nil, ErrTooManyFields
+	}
+	keys := make([]Key, 0, len(parts))
+	for _, part := range parts {
+		field, direction, hasDirection := strings.Cut(strings.TrimSpace(part), ":")
+		if !slices.Contains(allowed, field) {
+			return nil, fmt.Errorf("%w %q, use one of: %s", ErrUnknownField, field, strings.Join(allowed, ", "))
+		}
+		if slices.ContainsFunc(keys, func(k Key) bool { return k.Field == field }) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateField, field)
+		}
+
+		desc := defaultDesc
+		if hasDirection {
+			if desc, err = isDesc(direction); err != nil || direction == "" {
+				return nil, ErrInvalidOrder
+			}
+		}
+		keys = append(keys, Key{Field: field, Desc: desc})
+	}
+	return keys, nil
+}
+
+// Format writes keys back as a sort Parse reads, with every direction
+// spelled out
+func Format(keys []Key) string {
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		direction := "asc"
+		if key.Desc {
+			direction = "desc"
+		}
+		parts[i] = key.Field + ":" + direction
+	}
+	return strings.Join(parts, ",")
+}
+
+// FromQuery reads the sort of a listing from the sort query parameter, or
+// from sort_by, its older name, and returns it formatted. Fields without a
+// direction take order. It returns an empty string when neither is set.
+func FromQuery(query url.Values, order string, allowed []string) (string, error) {
+	spec := query.Get("sort")
+	if sortBy := query.Get("sort_by"); sortBy != "" {
+		if spec != "" {
+			return "", ErrAmbiguous
+		}
+		spec = sortBy
+	}
+	if spec == "" {
+		return "", nil
+	}
+
+	keys, err := Parse(spec, order, allowed)
+	if err != nil {
+		return "", err
+	}
+	return Format(keys), nil
+}
+
+// Fields lists the keys of a whitelist in a stable order
+func Fields[T any](whitelist map[string]T) []string {
+	fields := make([]string, 0, len(whitelist))
+	for field := range whitelist {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// Resolve parses spec against the fields of whitelist. A sort that does
+// not parse falls back to fallback in order, as repositories have always
+// done for sorts they do not know.
+func Resolve[T any](spec, order string, whitelist map[string]T, fallback string) []Key {
+	if spec != "" {
+		if keys, err := Parse(spec, order, Fields(whitelist)); err == nil {
+			return keys
+		}
+	}
+	desc, _ := isDesc(order)
+	return []Key{{Field: fallback, Desc: desc}}
+}
+
+// OrderBy renders keys as the list of an ORDER BY clause. columns maps
+// each field to the expressions it orders by; a comma-separated list such
+// as "last_name, first_name" orders by each in the key's direction.
+func OrderBy(keys []Key, columns map[string]string) string {
+	var terms []string
+	for _, key := range keys {
+		direction := " ASC"
+		if key.Desc {
+			direction = " DESC"
+		}
+		for _, column := range strings.Split(columns[key.Field], ",") {
+			terms = append(terms, strings.TrimSpace(column)+direction)
+		}
+	}
+	return strings.Join(terms, ", ")
+}
+
+// Sort orders items stably by keys, comparing each field with its
+// comparator in compare, which returns a negative number when a sorts
+// before b ascending. Items equal on every key keep their order.
+func Sort[T any](items []T, keys []Key, compare map[string]func(a, b T) int) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			c := compare[key.Field](items[i], items[j])
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+func isDesc(order string) (bool, error) {
+	switch strings.ToLower(order) {
+	case "", "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	default:
+		return false, ErrInvalidOrder
+	}
+}
@@ -0,0 +1,86 @@
+package sorting
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fields = []string{"created_at", "release_year", "title"}
+
+func TestParse(t *testing.T) {
+	keys, err := Parse("release_year:desc, title", "asc", fields)
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Field: "release_year", Desc: true}, {Field: "title"}}, keys)
+
+	keys, err = Parse("title", "DESC", fields)
+	require.NoError(t, err)
+	assert.Equal(t, []Key{{Field: "title", Desc: true}}, keys)
+	assert.Equal(t, "title:desc", Format(keys))
+
+	tests := map[string]struct {
+		spec string
+		want error
+	}{
+		"unknown field":     {"password", ErrUnknownField},
+		"empty field":       {"title,", ErrUnknownField},
+		"duplicate field":   {"title:asc,title:desc", ErrDuplicateField},
+		"bad direction":     {"title:up", ErrInvalidOrder},
+		"missing direction": {"title:", ErrInvalidOrder},
+		"too many fields":   {strings.Repeat("title,", MaxKeys) + "title", ErrTooManyFields},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tt.spec, "", fields)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	_, err = Parse("password", "", fields)
+	assert.EqualError(t, err, `unknown sort field "password", use one of: created_at, release_year, title`)
+}
+
+func TestFromQuery(t *testing.T) {
+	sort, err := FromQuery(url.Values{"sort": {"release_year:desc,title"}}, "asc", fields)
+	require.NoError(t, err)
+	assert.Equal(t, "release_year:desc,title:asc", sort)
+
+	sort, err = FromQuery(url.Values{"sort_by": {"title"}}, "desc", fields)
+	require.NoError(t, err)
+	assert.Equal(t, "title:desc", sort)
+
+	sort, err = FromQuery(url.Values{}, "desc", fields)
+	require.NoError(t, err)
+	assert.Empty(t, sort)
+
+	_, err = FromQuery(url.Values{"sort": {"title"}, "sort_by": {"title"}}, "", fields)
+	assert.ErrorIs(t, err, ErrAmbiguous)
+}
+
+func TestOrderBy(t *testing.T) {
+	columns := map[string]string{"name": "last_name, first_name", "year": "m.release_year"}
+
+	keys := Resolve("year:desc,name", "asc", columns, "year")
+	assert.Equal(t, "m.release_year DESC, last_name ASC, first_name ASC", OrderBy(keys, columns))
+
+	keys = Resolve("password", "desc", columns, "year")
+	assert.Equal(t, "m.release_year DESC", OrderBy(keys, columns), "unknown sorts fall back")
+}
+
+func TestSort(t *testing.T) {
+	type movie struct {
+		title string
+		year  int
+	}
+	compare := map[string]func(a, b movie) int{
+		"title": func(a, b movie) int { return strings.Compare(a.title, b.title) },
+		"year":  func(a, b movie) int { return a.year - b.year },
+	}
+	list := []movie{{"Heat", 1995}, {"Alien", 1979}, {"Casino", 1995}, {"Aliens", 1986}}
+
+	Sort(list, Resolve("year:desc,title:asc", "", compare, "title"), compare)
+	assert.Equal(t, []movie{{"Casino", 1995}, {"Heat", 1995}, {"Aliens", 1986}, {"Alien", 1979}}, list)
+}
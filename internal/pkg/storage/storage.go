@@ -0,0 +1,62 @@
+// Package storage stores binary objects such as uploaded images behind a
+// backend-neutral interface. NewLocalStorage keeps objects on disk and serves
+// them through the API's /media route; NewS3Storage talks to any
+// S3-compatible object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object describes a stored object
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the object's content; callers must close it. It returns
+	// ErrNotFound for unknown keys.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// URL returns an address clients can download key from. Signed URLs stay
+	// valid for ttl.
+	URL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// CleanKey validates an object key: slash-separated, relative and free of
+// "." and ".." segments, so a key can never escape the storage root
+func CleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	if cleaned := path.Clean(key); cleaned != key {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return key, nil
+}
+
+// PrivatePrefix starts the keys of objects that are only read by the
+// service itself, such as import uploads. The /media route never serves
+// them; S3 buckets with public reads must exclude the prefix.
+const PrivatePrefix = "private/"
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanKey(t *testing.T) {
+	valid := []string{"posters/1/large.jpg", "a", "a/b/c"}
+	for _, key := range valid {
+		cleaned, err := CleanKey(key)
+		assert.NoError(t, err, key)
+		assert.Equal(t, key, cleaned)
+	}
+
+	invalid := []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b", "a/./b", "a/", `a\b`, "."}
+	for _, key := range invalid {
+		_, err := CleanKey(key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStorage(LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media/"})
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(ctx, "posters/1/large.jpg", []byte("jpeg bytes"), "image/jpeg"))
+
+	body, object, err := store.Get(ctx, "posters/1/large.jpg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, body.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg bytes", string(data))
+	assert.Equal(t, "image/jpeg", object.ContentType)
+	assert.EqualValues(t, len("jpeg bytes"), object.Size)
+
+	u, err := store.URL(ctx, "posters/1/large.jpg", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/media/posters/1/large.jpg", u)
+
+	require.NoError(t, store.Delete(ctx, "posters/1/large.jpg"))
+	_, _, err = store.Get(ctx, "posters/1/large.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete(ctx, "posters/1/large.jpg"))
+
+	// Directories are not objects
+	_, _, err = store.Get(ctx, "posters")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, store.Put(ctx, "../escape.jpg", nil, "image/jpeg"), ErrInvalidKey)
+}
+
+func TestLocalStorageList(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStorage(LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+	require.NoError(t, err)
+
+	for _, key := range []string{"posters/1/large.jpg", "posters/1/small.jpg", "posters/2/large.jpg", "avatars/1/a/small.jpg"} {
+		require.NoError(t, store.Put(ctx, key, []byte(key), "image/jpeg"))
+	}
+
+	keys := func(objects []Object) []string {
+		var keys []string
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+		return keys
+	}
+
+	objects, err := store.List(ctx, "posters/1/")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"posters/1/large.jpg", "posters/1/small.jpg"}, keys(objects))
+	assert.EqualValues(t, len("posters/1/large.jpg"), objects[0].Size)
+	assert.False(t, objects[0].ModTime.IsZero())
+
+	objects, err = store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, objects, 4)
+
+	objects, err = store.List(ctx, "private/")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	signer := NewURLSigner("secret")
+	store, err := NewLocalStorage(LocalConfig{Root: t.TempDir(), BaseURL: "/media", Signer: signer})
+	require.NoError(t, err)
+
+	u, err := store.URL(context.Background(), "posters/1/small.jpg", time.Minute)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(u)
+	require.NoError(t, err)
+	assert.Equal(t, "/media/posters/1/small.jpg", parsed.Path)
+	assert.NoError(t, signer.Verify("posters/1/small.jpg", parsed.Query()))
+}
+
+func TestURLSigner(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	signer := NewURLSigner("secret")
+	signer.now = func() time.Time { return now }
+
+	query := signer.Sign("posters/1/large.jpg", time.Minute)
+	assert.NoError(t, signer.Verify("posters/1/large.jpg", query))
+
+	t.Run("other key", func(t *testing.T) {
+		assert.ErrorIs(t, signer.Verify("posters/2/large.jpg", query), ErrInvalidSignature)
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		other := NewURLSigner("other")
+		other.now = signer.now
+		assert.ErrorIs(t, other.Verify("posters/1/large.jpg", query), ErrInvalidSignature)
+	})
+
+	t.Run("tampered expiry", func(t *testing.T) {
+		tampered := url.Values{"expires": {"9999999999"}, "signature": query["signature"]}
+		assert.ErrorIs(t, signer.Verify("posters/1/large.jpg", tampered), ErrInvalidSignature)
+	})
+
+	t.Run("missing parameters", func(t *testing.T) {
+		assert.ErrorIs(t, signer.Verify("posters/1/large.jpg", url.Values{}), ErrInvalidSignature)
+		assert.ErrorIs(t, signer.Verify("posters/1/large.jpg", url.Values{"expires": query["expires"], "signature": {"zz"}}), ErrInvalidSignature)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		assert.ErrorIs(t, signer.Verify("posters/1/large.jpg", query), ErrExpiredSignature)
+	})
+
+	assert.False(t, strings.Contains(query.Encode(), "secret"))
+}
@@ -0,0 +1,233 @@
+package testenv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/migrate"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresURLEnv names a database to test against instead of starting a
+// container. It must be empty or migrated by this package, as migrations
+// are applied to it once per test binary.
+const PostgresURLEnv = "TEST_DATABASE_URL"
+
+const postgresImage = "postgres:15-alpine"
+
+var postgres server
+
+// Postgres returns a connection to a migrated database whose changes are
+// all rolled back when the test ends. Everything the test runs shares one
+// transaction on one connection: transactions the code under test begins
+// become savepoints, and a failing statement is rolled back on its own
+// rather than aborting the rest of the test.
+//
+// With a single connection, rows must be closed before the next query runs.
+func Postgres(t testing.TB, migrations fs.FS) *sqlx.DB {
+	t.Helper()
+	dsn := postgres.get(t, func() (string, bool, error) {
+		return startPostgres(migrations)
+	})
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", PostgresURLEnv, err)
+	}
+	ctx := context.Background()
+	raw, err := connector.Connect(ctx)
+	if err != nil {
+		t.Fatalf("failed to connect to Postgres: %v", err)
+	}
+	conn := &txConn{Conn: raw}
+	if err := conn.exec(ctx, "BEGIN"); err != nil {
+		raw.Close()
+		t.Fatalf("failed to begin the test transaction: %v", err)
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(&txConnector{conn: conn}), "postgres")
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		db.Close()
+		conn.exec(ctx, "ROLLBACK")
+		raw.Close()
+	})
+	return db
+}
+
+// startPostgres finds or starts the server and migrates it, returning its
+// DSN
+func startPostgres(migrations fs.FS) (string, bool, error) {
+	dsn := os.Getenv(PostgresURLEnv)
+	if dsn == "" {
+		if !dockerAvailable() {
+			return "", true, fmt.Errorf("no database to test against: set %s or install Docker", PostgresURLEnv)
+		}
+		addr, err := startContainer(postgresImage, 5432,
+			"POSTGRES_USER=postgres", "POSTGRES_PASSWORD=postgres", "POSTGRES_DB=test_db")
+		if err != nil {
+			return "", false, err
+		}
+		dsn = fmt.Sprintf("postgres://postgres:postgres@%s/test_db?sslmode=disable", addr)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return "", false, err
+	}
+	defer db.Close()
+
+	// The image restarts the server once its init scripts are done, and
+	// only listens on TCP after that
+	deadline := time.Now().Add(time.Minute)
+	for {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return "", false, fmt.Errorf("postgres did not come up: %w", err)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	if err := migrate.Up(db, migrations, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		return "", false, err
+	}
+	return dsn, false, nil
+}
+
+// txConnector hands database/sql the same connection every time
+type txConnector struct {
+	conn *txConn
+}
+
+func (c *txConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c *txConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// txConn runs every statement inside the transaction its owner began, each
+// guarded by a savepoint so a failure does not abort the transaction.
+// Closing it is left to the owner.
+type txConn struct {
+	driver.Conn
+	savepoints atomic.Int64
+}
+
+func (c *txConn) exec(ctx context.Context, query string) error {
+	_, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, nil)
+	return err
+}
+
+func (c *txConn) savepoint(ctx context.Context) (string, error) {
+	name := fmt.Sprintf("testenv_%d", c.savepoints.Add(1))
+	return name, c.exec(ctx, "SAVEPOINT "+name)
+}
+
+// settle keeps what ran since the savepoint if err is nil and undoes it
+// otherwise
+func (c *txConn) settle(ctx context.Context, name string, err error) error {
+	if err != nil {
+		return c.exec(ctx, "ROLLBACK TO SAVEPOINT "+name)
+	}
+	return c.exec(ctx, "RELEASE SAVEPOINT "+name)
+}
+
+func (c *txConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	name, err := c.savepoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+	if settleErr := c.settle(ctx, name, err); settleErr != nil && err == nil {
+		err = settleErr
+	}
+	return result, err
+}
+
+// QueryContext settles the savepoint once the rows are closed, as the
+// connection cannot run anything else while they are read
+func (c *txConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	name, err := c.savepoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+	if err != nil {
+		c.settle(ctx, name, err)
+		return nil, err
+	}
+	return &txRows{Rows: rows, conn: c, savepoint: name}, nil
+}
+
+func (c *txConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	name, err := c.savepoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &txSavepoint{conn: c, name: name}, nil
+}
+
+func (c *txConn) Ping(ctx context.Context) error {
+	return c.exec(ctx, "SELECT 1")
+}
+
+// Close is a no-op; the owner closes the connection when the test ends
+func (c *txConn) Close() error {
+	return nil
+}
+
+// txSavepoint stands in for a transaction begun inside the test's own
+type txSavepoint struct {
+	conn *txConn
+	name string
+}
+
+func (tx *txSavepoint) Commit() error {
+	return tx.conn.settle(context.Background(), tx.name, nil)
+}
+
+func (tx *txSavepoint) Rollback() error {
+	return tx.conn.settle(context.Background(), tx.name, errRolledBack)
+}
+
+var errRolledBack = errors.New("rolled back")
+
+type txRows struct {
+	driver.Rows
+	conn      *txConn
+	savepoint string
+	err       error
+}
+
+func (r *txRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return err
+}
+
+func (r *txRows) Close() error {
+	err := r.Rows.Close()
+	if settleErr := r.conn.settle(context.Background(), r.savepoint, r.err); err == nil {
+		err = settleErr
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package testenv
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(Main(m))
+}
+
+var migrations = fstest.MapFS{
+	"000001_create_notes.up.sql": {Data: []byte(`CREATE TABLE notes (id INTEGER PRIMARY KEY)`)},
+}
+
+func TestPostgres(t *testing.T) {
+	count := func(t *testing.T) int {
+		var n int
+		require.NoError(t, Postgres(t, migrations).Get(&n, `SELECT COUNT(*) FROM notes`))
+		return n
+	}
+
+	t.Run("rolls back what a test wrote", func(t *testing.T) {
+		db := Postgres(t, migrations)
+		_, err := db.Exec(`INSERT INTO notes (id) VALUES (1)`)
+		require.NoError(t, err)
+
+		var n int
+		require.NoError(t, db.Get(&n, `SELECT COUNT(*) FROM notes`))
+		assert.Equal(t, 1, n)
+	})
+	assert.Equal(t, 0, count(t))
+
+	t.Run("keeps going after a failed statement", func(t *testing.T) {
+		db := Postgres(t, migrations)
+		_, err := db.Exec(`INSERT INTO notes (id) VALUES (1)`)
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO notes (id) VALUES (1)`)
+		assert.Error(t, err)
+		var id int
+		assert.Error(t, db.QueryRow(`INSERT INTO notes (id) VALUES (1) RETURNING id`).Scan(&id))
+
+		var n int
+		require.NoError(t, db.Get(&n, `SELECT COUNT(*) FROM notes`))
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("turns transactions into savepoints", func(t *testing.T) {
+		db := Postgres(t, migrations)
+		tx, err := db.Beginx()
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO notes (id) VALUES (1)`)
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		tx, err = db.Beginx()
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO notes (id) VALUES (2)`)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		var ids []int
+		require.NoError(t, db.Select(&ids, `SELECT id FROM notes`))
+		assert.Equal(t, []int{2}, ids)
+	})
+}
@@ -0,0 +1,84 @@
+package testenv
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// RedisAddrEnv names a Redis server, as host:port, to test against instead
+// of starting a container
+const RedisAddrEnv = "TEST_REDIS_ADDR"
+
+const redisImage = "redis:7-alpine"
+
+var redis server
+
+// Redis returns the host and port of a Redis server. Tests share the
+// server, so each should keep to keys of its own, e.g. under a prefix named
+// after the test.
+func Redis(t testing.TB) (string, int) {
+	t.Helper()
+	addr := redis.get(t, startRedis)
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", RedisAddrEnv, err)
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", RedisAddrEnv, err)
+	}
+	return host, portNum
+}
+
+func startRedis() (string, bool, error) {
+	addr := os.Getenv(RedisAddrEnv)
+	if addr == "" {
+		if !dockerAvailable() {
+			return "", true, fmt.Errorf("no Redis to test against: set %s or install Docker", RedisAddrEnv)
+		}
+		var err error
+		addr, err = startContainer(redisImage, 6379)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		err := pingRedis(addr)
+		if err == nil {
+			return addr, false, nil
+		}
+		if time.Now().After(deadline) {
+			return "", false, fmt.Errorf("redis did not come up: %w", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func pingRedis(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply != "+PONG\r\n" {
+		return fmt.Errorf("unexpected reply to PING: %q", reply)
+	}
+	return nil
+}
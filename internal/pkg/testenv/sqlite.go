@@ -0,0 +1,35 @@
+package testenv
+
+import (
+	"io"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"thermondo/internal/pkg/migrate"
+	dbconn "thermondo/internal/pkg/postgres"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageEnv set to sqlite asks tests of code that runs on either database
+// to use SQLite rather than Postgres
+const StorageEnv = "TEST_STORAGE"
+
+// SQLite returns a connection to a migrated SQLite database of the test's
+// own, removed when the test ends. It needs neither Docker nor a server, so
+// it never skips.
+func SQLite(t testing.TB, migrations fs.FS) *sqlx.DB {
+	t.Helper()
+	db, err := dbconn.NewSQLiteConnection(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migrate.Up(db.DB, migrations, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("failed to migrate SQLite: %v", err)
+	}
+	return db
+}
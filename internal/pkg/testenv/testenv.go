@@ -0,0 +1,112 @@
+// Package testenv provides the Postgres and Redis servers integration tests
+// run against. A server named by an environment variable is used as is;
+// otherwise a throwaway container is started with Docker the first time a
+// test asks for one, and removed once the package's tests have run. Tests
+// skip when neither is available, so go test ./... passes on any machine.
+// SQLite databases are files in the test's temporary directory.
+package testenv
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// containerLabel marks every container started here, so ones left behind by
+// a killed test run can be found with docker ps --filter label=...
+const containerLabel = "thermondo.testenv"
+
+var (
+	mu         sync.Mutex
+	containers []string
+)
+
+// Main runs the package's tests and removes the containers they started.
+// Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(testenv.Main(m)) }
+func Main(m *testing.M) int {
+	code := m.Run()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range containers {
+		exec.Command("docker", "rm", "-f", id).Run()
+	}
+	containers = nil
+	return code
+}
+
+// dockerAvailable reports whether the docker CLI is installed and can reach
+// a daemon
+func dockerAvailable() bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	return exec.Command("docker", "info").Run() == nil
+}
+
+// startContainer runs image in the background with port published on a
+// random local port, and returns the address it is reachable on
+func startContainer(image string, port int, env ...string) (string, error) {
+	args := []string{"run", "--detach", "--rm", "--label", containerLabel,
+		"--publish", fmt.Sprintf("127.0.0.1::%d", port)}
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+	args = append(args, image)
+
+	id, err := docker(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to start %s: %w", image, err)
+	}
+	mu.Lock()
+	containers = append(containers, id)
+	mu.Unlock()
+
+	// docker port lists one line per address family
+	addrs, err := docker("port", id, fmt.Sprintf("%d/tcp", port))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the port of %s: %w", image, err)
+	}
+	addr, _, _ := strings.Cut(addrs, "\n")
+	return addr, nil
+}
+
+func docker(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// server starts at most once per test binary and remembers how that went
+type server struct {
+	once sync.Once
+	addr string
+	err  error
+	skip bool
+}
+
+// get returns the server's address, starting it on first use, and ends the
+// test when there is none to be had
+func (s *server) get(t testing.TB, start func() (addr string, skip bool, err error)) string {
+	t.Helper()
+	s.once.Do(func() {
+		s.addr, s.skip, s.err = start()
+	})
+	if s.skip {
+		t.Skip(s.err)
+	}
+	if s.err != nil {
+		t.Fatal(s.err)
+	}
+	return s.addr
+}
@@ -0,0 +1,337 @@
+// Package tokens signs and verifies the JWTs the service issues at login.
+//
+// Tokens are signed with one active key and carry its ID in the kid
+// header; every other configured key still verifies the tokens it signed.
+// Rotating the signing key therefore does not end existing sessions: add a
+// new key, sign with it, and drop the old one once its tokens have expired.
+// Tokens without a kid, as issued before keys were named, are verified with
+// the legacy secret.
+//
+// Keys are HS256 secrets or RS256 private keys. The public half of the
+// RS256 keys is published as a JWK set, so other services can verify
+// tokens without sharing a secret.
+package tokens
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// The algorithms a key can sign with, as named in configuration
+const (
+	HS256 = "hs256"
+	RS256 = "rs256"
+)
+
+// DefaultExpiry is how long issued tokens are valid unless WithExpiry says
+// otherwise
+const DefaultExpiry = 24 * time.Hour
+
+// minRSABits is the smallest RSA key accepted for signing
+const minRSABits = 2048
+
+var (
+	ErrUnknownKey   = errors.New("token signing key not found")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Key is one named signing key
+type Key struct {
+	id     string
+	method jwt.SigningMethod
+	// private signs and public verifies; both are the secret for HS256
+	private any
+	public  any
+}
+
+// NewHMACKey creates an HS256 key
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key %q: secret is empty", id)
+	}
+	return &Key{id: id, method: jwt.SigningMethodHS256, private: secret, public: secret}, nil
+}
+
+// NewRSAKey creates an RS256 key
+func NewRSAKey(id string, private *rsa.PrivateKey) (*Key, error) {
+	if bits := private.N.BitLen(); bits < minRSABits {
+		return nil, fmt.Errorf("key %q: RSA key has %d bits, need at least %d", id, bits, minRSABits)
+	}
+	return &Key{id: id, method: jwt.SigningMethodRS256, private: private, public: &private.PublicKey}, nil
+}
+
+// ID returns the key ID tokens signed with the key carry in their kid
+// header
+func (k *Key) ID() string {
+	return k.id
+}
+
+// ParseKeys decodes "id:hs256:base64secret" and "id:rs256:path" entries, as
+// read from configuration; path names a PEM-encoded PKCS#1 or PKCS#8 RSA
+// private key
+func ParseKeys(entries []string) ([]*Key, error) {
+	var keys []*Key
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("key entry must be id:hs256:base64secret or id:rs256:path")
+		}
+		id, alg, value := parts[0], strings.ToLower(parts[1]), parts[2]
+		if seen[id] {
+			return nil, fmt.Errorf("key %q is configured twice", id)
+		}
+		seen[id] = true
+
+		var key *Key
+		var err error
+		switch alg {
+		case HS256:
+			var secret []byte
+			if secret, err = base64.StdEncoding.DecodeString(value); err != nil {
+				return nil, fmt.Errorf("key %q is not valid base64: %w", id, err)
+			}
+			key, err = NewHMACKey(id, secret)
+		case RS256:
+			var private *rsa.PrivateKey
+			if private, err = readRSAKey(value); err != nil {
+				return nil, fmt.Errorf("key %q: %w", id, err)
+			}
+			key, err = NewRSAKey(id, private)
+		default:
+			return nil, fmt.Errorf("key %q: algorithm must be hs256 or rs256, got %q", id, parts[1])
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func readRSAKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RSA key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an RSA private key", path)
+	}
+	return key, nil
+}
+
+// Keys signs tokens with the active key and verifies them with whichever
+// key signed them
+type Keys struct {
+	legacy   *Key
+	signing  *Key
+	byID     map[string]*Key
+	issuer   string
+	audience string
+	expiry   time.Duration
+}
+
+// Option configures Keys
+type Option func(*Keys)
+
+// WithIssuer sets the iss claim of issued tokens and requires it of the
+// tokens verified
+func WithIssuer(issuer string) Option {
+	return func(k *Keys) {
+		k.issuer = issuer
+	}
+}
+
+// WithAudience sets the aud claim of issued tokens and requires it of the
+// tokens verified
+func WithAudience(audience string) Option {
+	return func(k *Keys) {
+		k.audience = audience
+	}
+}
+
+// WithExpiry sets how long issued tokens are valid
+func WithExpiry(d time.Duration) Option {
+	return func(k *Keys) {
+		if d > 0 {
+			k.expiry = d
+		}
+	}
+}
+
+// FromSecret creates keys that sign and verify with secret alone, as HS256
+// without a key ID
+func FromSecret(secret string, opts ...Option) *Keys {
+	k := &Keys{
+		legacy: &Key{method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)},
+		byID:   make(map[string]*Key),
+		expiry: DefaultExpiry,
+	}
+	k.signing = k.legacy
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k
+}
+
+// NewKeys creates keys that sign with keys[signingID], or with the legacy
+// secret when signingID is empty, and verify with any of them
+func NewKeys(secret string, signingID string, keys []*Key, opts ...Option) (*Keys, error) {
+	k := FromSecret(secret, opts...)
+	for _, key := range keys {
+		if key.id == "" || strings.Contains(key.id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", key.id)
+		}
+		k.byID[key.id] = key
+	}
+	if signingID != "" {
+		signing, ok := k.byID[signingID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q: %w", signingID, ErrUnknownKey)
+		}
+		k.signing = signing
+	}
+	return k, nil
+}
+
+// SigningKeyID returns the ID of the key new tokens are signed with, empty
+// for the legacy secret
+func (k *Keys) SigningKeyID() string {
+	return k.signing.id
+}
+
+// Expiry returns how long issued tokens are valid
+func (k *Keys) Expiry() time.Duration {
+	return k.expiry
+}
+
+// Issue signs claims with the signing key, adding expiresAt, the issue time,
+// the issuer and the audience
+func (k *Keys) Issue(claims jwt.MapClaims, expiresAt time.Time) (string, error) {
+	claims["iat"] = time.Now().Unix()
+	claims["exp"] = expiresAt.Unix()
+	if k.issuer != "" {
+		claims["iss"] = k.issuer
+	}
+	if k.audience != "" {
+		claims["aud"] = k.audience
+	}
+
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	if k.signing.id != "" {
+		token.Header["kid"] = k.signing.id
+	}
+	signed, err := token.SignedString(k.signing.private)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Parse verifies tokenString and decodes its claims into claims. It returns
+// ErrInvalidToken for a token that is malformed, expired, signed with an
+// unknown key or algorithm, or issued for another issuer or audience.
+func (k *Keys) Parse(tokenString string, claims jwt.Claims) error {
+	var options []jwt.ParserOption
+	if k.issuer != "" {
+		options = append(options, jwt.WithIssuer(k.issuer))
+	}
+	if k.audience != "" {
+		options = append(options, jwt.WithAudience(k.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, k.keyFor, options...)
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// keyFor picks the key named by the kid header, or the legacy secret for
+// tokens without one, and checks the token is signed with its algorithm
+func (k *Keys) keyFor(token *jwt.Token) (any, error) {
+	key := k.legacy
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = k.byID[kid]; !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWK is the public half of an RS256 key (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKSet is the document other services fetch to verify tokens
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the RS256 keys, the signing key first.
+// HS256 secrets are never published.
+func (k *Keys) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	add := func(key *Key) {
+		public, ok := key.public.(*rsa.PublicKey)
+		if !ok {
+			return
+		}
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "RSA",
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+			Modulus:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		})
+	}
+	add(k.signing)
+	ids := make([]string, 0, len(k.byID))
+	for id := range k.byID {
+		if id != k.signing.id {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		add(k.byID[id])
+	}
+	return set
+}
@@ -0,0 +1,152 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func issue(t *testing.T, keys *Keys) string {
+	t.Helper()
+	token, err := keys.Issue(jwt.MapClaims{"user_id": "user-1"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	return token
+}
+
+func TestKeysRoundTrip(t *testing.T) {
+	keys := FromSecret("secret")
+	assert.Equal(t, DefaultExpiry, keys.Expiry())
+
+	claims := jwt.MapClaims{}
+	require.NoError(t, keys.Parse(issue(t, keys), claims))
+	assert.Equal(t, "user-1", claims["user_id"])
+	assert.Contains(t, claims, "iat")
+
+	expired, err := keys.Issue(jwt.MapClaims{}, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.ErrorIs(t, keys.Parse(expired, jwt.MapClaims{}), ErrInvalidToken)
+	assert.ErrorIs(t, FromSecret("other").Parse(issue(t, keys), jwt.MapClaims{}), ErrInvalidToken)
+}
+
+func TestKeysRotation(t *testing.T) {
+	first, err := NewHMACKey("k1", []byte("first-secret"))
+	require.NoError(t, err)
+	second, err := NewHMACKey("k2", []byte("second-secret"))
+	require.NoError(t, err)
+
+	legacy := issue(t, FromSecret("secret"))
+	before, err := NewKeys("secret", "k1", []*Key{first})
+	require.NoError(t, err)
+	signedWithFirst := issue(t, before)
+
+	after, err := NewKeys("secret", "k2", []*Key{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", after.SigningKeyID())
+
+	token, _, err := jwt.NewParser().ParseUnverified(issue(t, after), jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", token.Header["kid"])
+
+	assert.NoError(t, after.Parse(signedWithFirst, jwt.MapClaims{}), "tokens of the previous key stay valid")
+	assert.NoError(t, after.Parse(legacy, jwt.MapClaims{}), "tokens without a kid are verified with the secret")
+
+	retired, err := NewKeys("secret", "k2", []*Key{second})
+	require.NoError(t, err)
+	assert.ErrorIs(t, retired.Parse(signedWithFirst, jwt.MapClaims{}), ErrInvalidToken)
+
+	_, err = NewKeys("secret", "k3", []*Key{first})
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeysIssuerAndAudience(t *testing.T) {
+	keys := FromSecret("secret", WithIssuer("thermondo"), WithAudience("movie-api"))
+
+	claims := jwt.MapClaims{}
+	require.NoError(t, keys.Parse(issue(t, keys), claims))
+	assert.Equal(t, "thermondo", claims["iss"])
+	assert.Equal(t, "movie-api", claims["aud"])
+
+	assert.ErrorIs(t, keys.Parse(issue(t, FromSecret("secret")), jwt.MapClaims{}), ErrInvalidToken)
+	other := FromSecret("secret", WithIssuer("thermondo"), WithAudience("partner-api"))
+	assert.ErrorIs(t, keys.Parse(issue(t, other), jwt.MapClaims{}), ErrInvalidToken)
+}
+
+func TestKeysRS256(t *testing.T) {
+	private := testRSAKey(t)
+	key, err := NewRSAKey("rsa-1", private)
+	require.NoError(t, err)
+	hmac, err := NewHMACKey("hmac-1", []byte("secret"))
+	require.NoError(t, err)
+	keys, err := NewKeys("secret", "rsa-1", []*Key{hmac, key})
+	require.NoError(t, err)
+
+	token := issue(t, keys)
+	assert.NoError(t, keys.Parse(token, jwt.MapClaims{}))
+
+	// A token naming the RSA key but signed with HMAC over its public key
+	// must not verify
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"user_id": "admin"})
+	forged.Header["kid"] = "rsa-1"
+	forgedString, err := forged.SignedString(x509.MarshalPKCS1PublicKey(&private.PublicKey))
+	require.NoError(t, err)
+	assert.ErrorIs(t, keys.Parse(forgedString, jwt.MapClaims{}), ErrInvalidToken)
+
+	set := keys.JWKS()
+	require.Len(t, set.Keys, 1, "HMAC secrets are never published")
+	jwk := set.Keys[0]
+	assert.Equal(t, "RSA", jwk.KeyType)
+	assert.Equal(t, "rsa-1", jwk.KeyID)
+	assert.Equal(t, "RS256", jwk.Algorithm)
+	assert.Equal(t, "AQAB", jwk.Exponent)
+	modulus, err := base64.RawURLEncoding.DecodeString(jwk.Modulus)
+	require.NoError(t, err)
+	assert.Equal(t, private.N.Bytes(), modulus)
+
+	_, err = NewRSAKey("small", &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: private.Primes[0], E: 65537}})
+	assert.ErrorContains(t, err, "need at least 2048")
+}
+
+func TestParseKeys(t *testing.T) {
+	private := testRSAKey(t)
+	dir := t.TempDir()
+	pkcs1 := filepath.Join(dir, "pkcs1.pem")
+	require.NoError(t, os.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)}), 0o600))
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	pkcs8 := filepath.Join(dir, "pkcs8.pem")
+	require.NoError(t, os.WriteFile(pkcs8, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	keys, err := ParseKeys([]string{"h1:HS256:c2VjcmV0", " ", "r1:rs256:" + pkcs1, "r2:rs256:" + pkcs8})
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.Equal(t, []string{"h1", "r1", "r2"}, []string{keys[0].ID(), keys[1].ID(), keys[2].ID()})
+
+	for entry, message := range map[string]string{
+		"h1":                           "must be id:hs256",
+		"h1:hs256:not base64!":         "not valid base64",
+		"h1:es256:c2VjcmV0":            "must be hs256 or rs256",
+		"r1:rs256:" + dir + "/missing": "failed to read RSA key",
+	} {
+		_, err := ParseKeys([]string{entry})
+		assert.ErrorContains(t, err, message, entry)
+	}
+	_, err = ParseKeys([]string{"h1:hs256:c2VjcmV0", "h1:hs256:b3RoZXI="})
+	assert.ErrorContains(t, err, "configured twice")
+}
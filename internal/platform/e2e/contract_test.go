@@ -0,0 +1,151 @@
+package e2e
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"thermondo/internal/platform/http/rest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the v1 response shapes in testdata/v1")
+
+// TestV1Contract pins the shape of v1 responses: the fields, their JSON
+// types and which are present. A field may be added to v1 by updating the
+// shapes with -update; renaming, retyping or dropping one is a breaking
+// change that belongs in the next version.
+func TestV1Contract(t *testing.T) {
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+	for _, store := range stores {
+		t.Run(store, func(t *testing.T) {
+			server := newServer(t, store, rest.WithLegacyRoutes(sunset))
+			api := newClient(t, server)
+
+			status, user := api.do(http.MethodPost, "/users", map[string]any{
+				"first_name": "Grace",
+				"last_name":  "Hopper",
+				"email":      "grace@example.com",
+				"password":   "correct-horse-battery",
+				"role":       "user",
+			})
+			require.Equal(t, http.StatusCreated, status, user)
+			assertShape(t, "create_user", user)
+			assert.Equal(t, "1", api.header.Get(rest.APIVersionHeader))
+			assert.Empty(t, api.header.Get("Deprecation"), "v1 is current")
+			userID := user["id"].(string)
+
+			status, login := api.do(http.MethodPost, "/users/login", map[string]any{
+				"email":    "grace@example.com",
+				"password": "correct-horse-battery",
+			})
+			require.Equal(t, http.StatusOK, status, login)
+			assertShape(t, "login", login)
+			api.token = login["token"].(string)
+
+			status, movie := api.do(http.MethodPost, "/movies", map[string]any{
+				"title":         "Alien",
+				"description":   "In space no one can hear you scream.",
+				"release_year":  1979,
+				"genre":         "Horror",
+				"director":      "Ridley Scott",
+				"duration_mins": 117,
+				"language":      "English",
+				"country":       "UK",
+			})
+			require.Equal(t, http.StatusCreated, status, movie)
+			assertShape(t, "create_movie", movie)
+			movieID := movie["id"].(string)
+
+			status, fetched := api.do(http.MethodGet, "/movies/"+movieID, nil)
+			require.Equal(t, http.StatusOK, status, fetched)
+			assertShape(t, "get_movie", fetched)
+
+			status, rating := api.do(http.MethodPost, "/ratings", map[string]any{
+				"movie_id": movieID,
+				"score":    5,
+				"review":   "A perfect haunted house in space.",
+			})
+			require.Equal(t, http.StatusCreated, status, rating)
+			assertShape(t, "create_rating", rating)
+
+			status, fetchedRating := api.do(http.MethodGet, "/ratings/"+rating["id"].(string), nil)
+			require.Equal(t, http.StatusOK, status, fetchedRating)
+			assertShape(t, "get_rating", fetchedRating)
+
+			status, stats := api.do(http.MethodGet, "/movies/"+movieID+"/stats", nil)
+			require.Equal(t, http.StatusOK, status, stats)
+			assertShape(t, "movie_stats", stats)
+
+			status, profile := api.do(http.MethodGet, "/user/"+userID+"/profile", nil)
+			require.Equal(t, http.StatusOK, status, profile)
+			assertShape(t, "user_profile", profile)
+
+			status, problem := api.do(http.MethodGet, "/ratings/missing", nil)
+			require.Equal(t, http.StatusNotFound, status, problem)
+			assertShape(t, "problem", problem)
+			assert.Equal(t, "/api/v1/ratings/missing", problem["instance"])
+
+			// The routes from before the prefix answer the same, deprecated
+			api.prefix = ""
+			status, legacy := api.do(http.MethodGet, "/movies/"+movieID, nil)
+			require.Equal(t, http.StatusOK, status, legacy)
+			assert.Equal(t, fetched, legacy)
+			assert.Equal(t, "1", api.header.Get(rest.APIVersionHeader))
+			assert.Regexp(t, `^@\d+$`, api.header.Get("Deprecation"))
+			assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", api.header.Get("Sunset"))
+			assert.Equal(t, `</api/v1/movies/`+movieID+`>; rel="successor-version"`, api.header.Get("Link"))
+		})
+	}
+}
+
+// assertShape compares the shape of a decoded response with the one pinned
+// in testdata/v1/name.json
+func assertShape(t *testing.T, name string, decoded map[string]any) {
+	t.Helper()
+	path := filepath.Join("testdata", "v1", name+".json")
+	got, err := json.MarshalIndent(shape(decoded), "", "  ")
+	require.NoError(t, err)
+	got = append(got, '\n')
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+		return
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run go test -run TestV1Contract -update to record it")
+	assert.JSONEq(t, string(want), string(got), "the v1 %s response changed shape", name)
+}
+
+// shape replaces every value of a decoded JSON document by the name of its
+// type. Arrays keep the shape of their first element.
+func shape(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		fields := make(map[string]any, len(value))
+		for key, field := range value {
+			fields[key] = shape(field)
+		}
+		return fields
+	case []any:
+		if len(value) == 0 {
+			return []any{}
+		}
+		return []any{shape(value[0])}
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
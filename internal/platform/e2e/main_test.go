@@ -0,0 +1,146 @@
+// Package e2e drives the API through the real router, wired to real
+// services and repositories the way cmd/movie-service wires them, and
+// checks complete flows against the JSON clients see. Each flow runs once
+// per store: in memory, in SQLite, and in Postgres when testenv has one.
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/testenv"
+	"thermondo/internal/pkg/tokens"
+	movieHandlers "thermondo/internal/platform/http/handlers/movies"
+	ratingHandlers "thermondo/internal/platform/http/handlers/ratings"
+	userHandlers "thermondo/internal/platform/http/handlers/users"
+	"thermondo/internal/platform/http/middleware"
+	"thermondo/internal/platform/http/rest"
+	"thermondo/internal/platform/repository"
+	"thermondo/internal/platform/repository/memory"
+	movieService "thermondo/internal/platform/service/movies"
+	ratingService "thermondo/internal/platform/service/rating"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+const jwtSecret = "e2e-secret"
+
+// stores are the backends movies, ratings and users can be kept in, as
+// picked by STORAGE
+var stores = []string{"memory", "sqlite", "postgres"}
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+// newServer serves the API with movies, ratings and users kept in store.
+// Only features every store supports are wired; the rest need tables that
+// are only in Postgres.
+func newServer(t *testing.T, store string, opts ...rest.RouterOption) *httptest.Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	c := cache.NewNoOpCache()
+
+	var (
+		userRepo   users.UserRepository
+		movieRepo  movies.Repository
+		ratingRepo rating.Repository
+	)
+	switch store {
+	case "memory":
+		s := memory.NewStore()
+		userRepo = memory.NewUserRepository(s, c, logger)
+		movieRepo = memory.NewMovieRepository(s)
+		ratingRepo = memory.NewRatingRepository(s)
+	default:
+		var db *sqlx.DB
+		if store == "sqlite" {
+			db = testenv.SQLite(t, repository.SQLiteMigrations())
+		} else {
+			db = testenv.Postgres(t, repository.Migrations())
+		}
+		userRepo = repository.NewUserRepository(db, c, logger)
+		movieRepo = repository.NewMovieRepository(db)
+		ratingRepo = repository.NewRatingRepository(db)
+	}
+	idGenerator := shared.NewULIDsGenerator()
+	timeProvider := shared.NewTimeProvider()
+
+	users := userService.NewUserService(userRepo, ratingRepo, movieRepo, idGenerator, timeProvider, c, logger)
+	ratings := ratingService.NewRatingService(ratingRepo, idGenerator, timeProvider, logger)
+	movies := movieService.NewMovieService(movieRepo, idGenerator, timeProvider, logger,
+		movieService.WithRatingRepository(ratingRepo),
+		movieService.WithCache(c),
+		movieService.WithBayesianConfidenceK(func() float64 { return ratings.GetBayesianConfig().ConfidenceK }),
+	)
+
+	router := rest.NewRouter(logger, append([]rest.RouterOption{
+		rest.WithHandlers(
+			userHandlers.NewHandler(users, logger, tokens.FromSecret(jwtSecret)),
+			movieHandlers.NewHandler(movies, logger),
+			ratingHandlers.NewHandler(ratings, logger, ratingHandlers.WithAuthentication(tokens.FromSecret(jwtSecret), nil)),
+			userHandlers.NewProfileHandler(users, logger, userHandlers.WithProfileAuthentication(tokens.FromSecret(jwtSecret), nil)),
+		),
+		rest.WithAPIMiddleware(middleware.ContentMode(userRepo, response.NewWriter(logger), tokens.FromSecret(jwtSecret))),
+	}, opts...)...)
+	server := httptest.NewServer(router.Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// client calls the API on behalf of one caller
+type client struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+	// prefix is put in front of every path
+	prefix string
+	// header is the header of the last response
+	header http.Header
+}
+
+// newClient calls the /api/v1 routes of server
+func newClient(t *testing.T, server *httptest.Server) *client {
+	return &client{t: t, server: server, prefix: "/api/v1"}
+}
+
+// do sends body as JSON and returns the status and the decoded response,
+// failing the test if the response is not JSON
+func (c *client) do(method, path string, body any) (int, map[string]any) {
+	c.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		require.NoError(c.t, err)
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.server.URL+c.prefix+path, reader)
+	require.NoError(c.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.server.Client().Do(req)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	c.header = resp.Header
+
+	var decoded map[string]any
+	require.NoError(c.t, json.NewDecoder(resp.Body).Decode(&decoded), "%s %s", method, path)
+	return resp.StatusCode, decoded
+}
@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRatingFlow signs a user up, logs them in, adds a movie, rates it and
+// reads the rating back from the user's profile and the movie's stats
+func TestRatingFlow(t *testing.T) {
+	for _, store := range stores {
+		t.Run(store, func(t *testing.T) {
+			api := newClient(t, newServer(t, store))
+
+			status, user := api.do(http.MethodPost, "/users", map[string]any{
+				"first_name": "Ada",
+				"last_name":  "Lovelace",
+				"email":      "ada@example.com",
+				"password":   "correct-horse-battery",
+				"role":       "user",
+			})
+			require.Equal(t, http.StatusCreated, status, user)
+			userID, _ := user["id"].(string)
+			require.NotEmpty(t, userID)
+			assert.Equal(t, "ada@example.com", user["email"])
+			assert.Equal(t, "user", user["role"])
+			assert.Equal(t, true, user["is_active"])
+			assert.NotContains(t, user, "password")
+
+			status, problem := api.do(http.MethodPost, "/users", map[string]any{
+				"first_name": "Ada",
+				"last_name":  "Again",
+				"email":      "ada@example.com",
+				"password":   "correct-horse-battery",
+				"role":       "user",
+			})
+			assert.Equal(t, http.StatusConflict, status, problem)
+
+			status, problem = api.do(http.MethodPost, "/users/login", map[string]any{
+				"email":    "ada@example.com",
+				"password": "wrong",
+			})
+			assert.Equal(t, http.StatusUnauthorized, status, problem)
+			assert.Equal(t, "Invalid credentials", problem["detail"])
+
+			status, login := api.do(http.MethodPost, "/users/login", map[string]any{
+				"email":    "ada@example.com",
+				"password": "correct-horse-battery",
+			})
+			require.Equal(t, http.StatusOK, status, login)
+			api.token, _ = login["token"].(string)
+			require.NotEmpty(t, api.token)
+			assert.NotZero(t, login["expires_at"])
+
+			status, movie := api.do(http.MethodPost, "/movies", map[string]any{
+				"title":         "The Matrix",
+				"description":   "A hacker learns what the world really is.",
+				"release_year":  1999,
+				"genre":         "Science Fiction",
+				"director":      "Lana Wachowski",
+				"duration_mins": 136,
+				"language":      "English",
+				"country":       "USA",
+			})
+			require.Equal(t, http.StatusCreated, status, movie)
+			movieID, _ := movie["id"].(string)
+			require.NotEmpty(t, movieID)
+			assert.Equal(t, "The Matrix", movie["title"])
+			assert.EqualValues(t, 1999, movie["release_year"])
+
+			status, fetched := api.do(http.MethodGet, "/movies/"+movieID, nil)
+			require.Equal(t, http.StatusOK, status, fetched)
+			assert.Equal(t, movieID, fetched["id"])
+			assert.Equal(t, "Lana Wachowski", fetched["director"])
+
+			status, problem = api.do(http.MethodPost, "/ratings", map[string]any{
+				"movie_id": movieID,
+				"score":    11,
+			})
+			assert.Equal(t, http.StatusBadRequest, status, problem)
+
+			status, created := api.do(http.MethodPost, "/ratings", map[string]any{
+				"movie_id": movieID,
+				"score":    4,
+				"review":   "Still holds up.",
+			})
+			require.Equal(t, http.StatusCreated, status, created)
+			assert.Equal(t, userID, created["user_id"])
+			assert.Equal(t, movieID, created["movie_id"])
+			assert.EqualValues(t, 4, created["score"])
+
+			status, problem = api.do(http.MethodPost, "/ratings", map[string]any{
+				"movie_id": movieID,
+				"score":    5,
+			})
+			assert.Equal(t, http.StatusConflict, status, problem)
+
+			status, profile := api.do(http.MethodGet, "/user/"+userID+"/profile", nil)
+			require.Equal(t, http.StatusOK, status, profile)
+			assert.Equal(t, userID, profile["user"].(map[string]any)["id"])
+			stats := profile["stats"].(map[string]any)
+			assert.EqualValues(t, 1, stats["total_ratings"])
+			assert.EqualValues(t, 4, stats["average_score"])
+			assert.Equal(t, map[string]any{"4": float64(1)}, stats["score_distribution"])
+			assert.Equal(t, "Science Fiction", stats["favorite_genre"])
+			ratings := profile["ratings"].([]any)
+			require.Len(t, ratings, 1)
+			rated := ratings[0].(map[string]any)
+			assert.Equal(t, movieID, rated["movie_id"])
+			assert.Equal(t, "The Matrix", rated["title"])
+			assert.EqualValues(t, 4, rated["score"])
+			assert.Equal(t, "Still holds up.", rated["review"])
+			assert.EqualValues(t, 4, rated["movie_average"])
+			assert.EqualValues(t, 1, rated["total_ratings"])
+
+			status, movieStats := api.do(http.MethodGet, "/movies/"+movieID+"/stats", nil)
+			require.Equal(t, http.StatusOK, status, movieStats)
+			assert.Equal(t, movieID, movieStats["movie_id"])
+			assert.EqualValues(t, 4, movieStats["average_score"])
+			assert.EqualValues(t, 1, movieStats["total_ratings"])
+			assert.Equal(t, map[string]any{"4": float64(1)}, movieStats["score_count"])
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestBodies sends bodies the API must refuse before they reach a
+// service. Decoding does not depend on the store, so one is enough.
+func TestRequestBodies(t *testing.T) {
+	api := newClient(t, newServer(t, "memory"))
+
+	tests := map[string]struct {
+		body   string
+		status int
+		detail string
+	}{
+		"unknown field": {
+			body:   `{"email": "ada@example.com", "password": "correct-horse-battery", "remember_me": true}`,
+			status: http.StatusBadRequest,
+			detail: `Invalid JSON payload: unknown field "remember_me"`,
+		},
+		"duplicate field": {
+			body:   `{"email": "ada@example.com", "password": "wrong", "password": "correct-horse-battery"}`,
+			status: http.StatusBadRequest,
+			detail: `Invalid JSON payload: duplicate field "password"`,
+		},
+		"too deep": {
+			body:   `{"email": ` + strings.Repeat("[", 64) + strings.Repeat("]", 64) + `}`,
+			status: http.StatusBadRequest,
+			detail: "Invalid JSON payload: JSON must not be nested deeper than 32 levels",
+		},
+		"too large": {
+			body:   `{"email": "` + strings.Repeat("a", 8<<10) + `@example.com"}`,
+			status: http.StatusRequestEntityTooLarge,
+			detail: "Request body must not exceed 4096 bytes",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, problem := api.do(http.MethodPost, "/users/login", json.RawMessage(tt.body))
+			assert.Equal(t, tt.status, status, problem)
+			assert.Equal(t, tt.detail, problem["detail"])
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"net/http"
+	"thermondo/internal/pkg/http/request"
+	ratingService "thermondo/internal/platform/service/rating"
+)
+
+// BayesianConfigService reads and replaces the parameters of movie scores
+type BayesianConfigService interface {
+	GetBayesianConfig() ratingService.BayesianConfig
+	SetBayesianConfig(config ratingService.BayesianConfig)
+}
+
+// BayesianConfigRequest changes the parameters of movie scores. Omitted
+// fields are left as they are.
+type BayesianConfigRequest struct {
+	MinVotes          *int64   `json:"min_votes"`
+	ConfidenceK       *float64 `json:"confidence_k"`
+	DisplayMinRatings *int64   `json:"display_min_ratings"`
+}
+
+// GetBayesianConfig handles GET /admin/ratings/bayesian
+func (h *Handler) GetBayesianConfig(w http.ResponseWriter, r *http.Request) {
+	h.responseWriter.WriteSuccess(w, bayesianConfigResponse(h.bayesian.GetBayesianConfig()), http.StatusOK)
+}
+
+// UpdateBayesianConfig handles PUT /admin/ratings/bayesian, changing the
+// parameters until the next restart or config reload of RATINGS_BAYESIAN_*
+// and RATINGS_DISPLAY_MIN_RATINGS
+func (h *Handler) UpdateBayesianConfig(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var req BayesianConfigRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	// The same bounds as the configuration
+	switch {
+	case req.MinVotes != nil && *req.MinVotes < 1:
+		h.responseWriter.WriteError(w, "min_votes must be at least 1", http.StatusBadRequest)
+		return
+	case req.ConfidenceK != nil && *req.ConfidenceK < 0:
+		h.responseWriter.WriteError(w, "confidence_k must not be negative", http.StatusBadRequest)
+		return
+	case req.DisplayMinRatings != nil && *req.DisplayMinRatings < 0:
+		h.responseWriter.WriteError(w, "display_min_ratings must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	config := h.bayesian.GetBayesianConfig()
+	if req.MinVotes != nil {
+		config.MinVotes = *req.MinVotes
+	}
+	if req.ConfidenceK != nil {
+		config.ConfidenceK = *req.ConfidenceK
+	}
+	if req.DisplayMinRatings != nil {
+		config.DisplayMinRatings = *req.DisplayMinRatings
+	}
+	h.bayesian.SetBayesianConfig(config)
+
+	response := bayesianConfigResponse(config)
+	// Warn so the change is recorded whatever the log level is
+	h.logger.Warn("[update_bayesian_config_handler] Bayesian configuration changed", "admin_id", adminID,
+		"min_votes", response.MinVotes, "confidence_k", response.ConfidenceK, "display_min_ratings", response.DisplayMinRatings)
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func bayesianConfigResponse(config ratingService.BayesianConfig) BayesianConfigResponse {
+	return BayesianConfigResponse{
+		MinVotes:          config.MinVotes,
+		ConfidenceK:       config.ConfidenceK,
+		DisplayMinRatings: config.DisplayMinRatings,
+	}
+}
@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/pkg/tokens"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupBayesianRouter(service *MockBayesianConfigService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+		WithBayesianConfig(service),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestBayesianConfig(t *testing.T) {
+	current := ratingService.BayesianConfig{MinVotes: 10, GlobalAverage: 3.5, ConfidenceK: 25}
+	request := func(t *testing.T, router http.Handler, method, body, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/ratings/bayesian", strings.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("shows the current parameters", func(t *testing.T) {
+		service := new(MockBayesianConfigService)
+		service.On("GetBayesianConfig").Return(current)
+
+		rr := request(t, setupBayesianRouter(service), http.MethodGet, "", "admin")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp BayesianConfigResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, BayesianConfigResponse{MinVotes: 10, ConfidenceK: 25}, resp)
+	})
+
+	t.Run("changes only the given fields", func(t *testing.T) {
+		service := new(MockBayesianConfigService)
+		service.On("GetBayesianConfig").Return(current)
+		service.On("SetBayesianConfig", ratingService.BayesianConfig{MinVotes: 10, GlobalAverage: 3.5, ConfidenceK: 25, DisplayMinRatings: 5}).Once()
+
+		rr := request(t, setupBayesianRouter(service), http.MethodPut, `{"display_min_ratings": 5}`, "admin")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp BayesianConfigResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(5), resp.DisplayMinRatings)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("rejects out of range values", func(t *testing.T) {
+		for _, body := range []string{`{"min_votes": 0}`, `{"confidence_k": -1}`, `{"display_min_ratings": -1}`} {
+			service := new(MockBayesianConfigService)
+
+			rr := request(t, setupBayesianRouter(service), http.MethodPut, body, "admin")
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+			service.AssertNotCalled(t, "SetBayesianConfig", mock.Anything)
+		}
+	})
+
+	t.Run("non-admins are rejected", func(t *testing.T) {
+		service := new(MockBayesianConfigService)
+
+		rr := request(t, setupBayesianRouter(service), http.MethodPut, `{"display_min_ratings": 5}`, "user")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "SetBayesianConfig", mock.Anything)
+	})
+
+	t.Run("not registered without a service", func(t *testing.T) {
+		rr := request(t, setupRouter(new(MockMovieService), new(MockAdminService)), http.MethodGet, "", "admin")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
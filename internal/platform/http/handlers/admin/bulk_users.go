@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"net/http"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/request"
+	adminService "thermondo/internal/platform/service/admin"
+)
+
+// BulkUsersRequest is the body of POST /admin/users:batch. Role is only
+// read by the change_role action.
+type BulkUsersRequest struct {
+	Action  string   `json:"action"`
+	Role    string   `json:"role,omitempty"`
+	UserIDs []string `json:"user_ids"`
+}
+
+func (r BulkUsersRequest) toService() adminService.BulkUsersRequest {
+	return adminService.BulkUsersRequest{Action: r.Action, Role: r.Role, UserIDs: r.UserIDs}
+}
+
+// BulkUpdateUsers handles POST /admin/users:batch. The whole batch is applied
+// in one transaction; the response reports what happened to each user.
+func (h *Handler) BulkUpdateUsers(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var req BulkUsersRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	results, err := h.adminService.BulkUpdateUsers(r.Context(), req.toService(), adminID)
+	if err != nil {
+		h.logger.Error("[bulk_update_users_handler] Failed to bulk update users", "error", err, "action", req.Action)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := BulkUsersResponse{
+		Action:  req.Action,
+		Results: make([]BulkUserResult, 0, len(results)),
+	}
+	for _, result := range results {
+		response.Results = append(response.Results, BulkUserResult{
+			UserID: string(result.UserID),
+			Status: string(result.Status),
+			Reason: result.Reason,
+		})
+		switch result.Status {
+		case users.BulkUpdated:
+			response.Summary.Updated++
+		case users.BulkUnchanged:
+			response.Summary.Unchanged++
+		case users.BulkNotFound:
+			response.Summary.NotFound++
+		case users.BulkSkipped:
+			response.Summary.Skipped++
+		}
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
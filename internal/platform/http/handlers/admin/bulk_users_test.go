@@ -0,0 +1,83 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	adminService "thermondo/internal/platform/service/admin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkUpdateUsers(t *testing.T) {
+	t.Run("reports per-user results", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("BulkUpdateUsers", mock.Anything, adminService.BulkUsersRequest{
+			Action:  "deactivate",
+			UserIDs: []string{"admin-1", "bot-1", "bot-2", "ghost"},
+		}, "admin-1").Return([]users.BulkResult{
+			{UserID: "admin-1", Status: users.BulkSkipped, Reason: "cannot change your own account"},
+			{UserID: "bot-1", Status: users.BulkUpdated},
+			{UserID: "bot-2", Status: users.BulkUnchanged},
+			{UserID: "ghost", Status: users.BulkNotFound},
+		}, nil)
+
+		body := `{"action":"deactivate","user_ids":["admin-1","bot-1","bot-2","ghost"]}`
+		req := httptest.NewRequest(http.MethodPost, "/admin/users:batch", strings.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp BulkUsersResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "deactivate", resp.Action)
+		assert.Equal(t, BulkUsersSummary{Updated: 1, Unchanged: 1, NotFound: 1, Skipped: 1}, resp.Summary)
+		require.Len(t, resp.Results, 4)
+		assert.Equal(t, BulkUserResult{UserID: "admin-1", Status: "skipped", Reason: "cannot change your own account"}, resp.Results[0])
+		assert.Equal(t, BulkUserResult{UserID: "bot-1", Status: "updated"}, resp.Results[1])
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		service := new(MockAdminService)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users:batch", strings.NewReader("{"))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "BulkUpdateUsers", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("BulkUpdateUsers", mock.Anything, mock.Anything, "admin-1").Return(nil, appErrors.NewBadRequestError("user_ids must not be empty"))
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users:batch", strings.NewReader(`{"action":"activate","user_ids":[]}`))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		service := new(MockAdminService)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users:batch", strings.NewReader(`{"action":"activate","user_ids":["u"]}`))
+		req.Header.Set("Authorization", bearerToken(t, "user-1", "user"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "BulkUpdateUsers", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/platform/service/warmup"
+)
+
+// CacheWarmer warms caches on demand, queueing the warmups too large to run
+// during the request
+type CacheWarmer interface {
+	Start(ctx context.Context, req warmup.Request, startedBy string) (*warmup.Result, *jobs.Job, error)
+}
+
+// CacheWarmRequest is the body of POST /admin/cache/warm: movies and users
+// by ID, and the top_movies most rated movies and top_users most active
+// raters
+type CacheWarmRequest struct {
+	MovieIDs  []string `json:"movie_ids,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	TopMovies int      `json:"top_movies,omitempty"`
+	TopUsers  int      `json:"top_users,omitempty"`
+}
+
+func (r CacheWarmRequest) toService() warmup.Request {
+	return warmup.Request{MovieIDs: r.MovieIDs, UserIDs: r.UserIDs, TopMovies: r.TopMovies, TopUsers: r.TopUsers}
+}
+
+// WarmCache handles POST /admin/cache/warm. Small warmups answer 200 with
+// what was warmed; larger ones are queued and answer 202 with the job.
+func (h *Handler) WarmCache(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var req CacheWarmRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	result, job, err := h.cacheWarmer.Start(r.Context(), req.toService(), adminID)
+	if err != nil {
+		h.logger.Error("[warm_cache_handler] Failed to warm caches", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	if job != nil {
+		h.logger.Info("[warm_cache_handler] Cache warmup queued", "admin_id", adminID, "job_id", job.ID)
+		w.Header().Set("Location", "/api/v1/admin/jobs/"+job.ID.String())
+		h.responseWriter.WriteSuccess(w, jobResponse(job), http.StatusAccepted)
+		return
+	}
+
+	h.logger.Info("[warm_cache_handler] Caches warmed", "admin_id", adminID, "movies", result.Movies, "users", result.Users, "failed", result.Failed)
+	h.responseWriter.WriteSuccess(w, CacheWarmResponse{
+		Movies: result.Movies,
+		Users:  result.Users,
+		Failed: result.Failed,
+	}, http.StatusOK)
+}
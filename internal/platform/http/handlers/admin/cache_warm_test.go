@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/domain/jobs"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/service/warmup"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWarmCache(t *testing.T) {
+	request := func(t *testing.T, warmer *MockCacheWarmer, body, role string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+			WithCacheWarmer(warmer),
+		).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/cache/warm", strings.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("returns what a small warmup warmed", func(t *testing.T) {
+		warmer := new(MockCacheWarmer)
+		warmer.On("Start", mock.Anything, warmup.Request{MovieIDs: []string{"movie-1"}, TopUsers: 5}, "admin-1").
+			Return(&warmup.Result{Movies: 1, Users: 4, Failed: 1}, nil, nil)
+
+		rr := request(t, warmer, `{"movie_ids":["movie-1"],"top_users":5}`, "admin")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"movies":1,"users":4,"failed":1}`, rr.Body.String())
+		warmer.AssertExpectations(t)
+	})
+
+	t.Run("queues a large warmup", func(t *testing.T) {
+		warmer := new(MockCacheWarmer)
+		warmer.On("Start", mock.Anything, warmup.Request{TopMovies: 500}, "admin-1").
+			Return(nil, &jobs.Job{ID: "job-1", Type: warmup.JobType, Status: jobs.StatusQueued}, nil)
+
+		rr := request(t, warmer, `{"top_movies":500}`, "admin")
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "/api/v1/admin/jobs/job-1", rr.Header().Get("Location"))
+		assert.Contains(t, rr.Body.String(), `"type":"cache.warm"`)
+	})
+
+	t.Run("passes validation errors through", func(t *testing.T) {
+		warmer := new(MockCacheWarmer)
+		warmer.On("Start", mock.Anything, warmup.Request{}, "admin-1").
+			Return(nil, nil, appErrors.NewBadRequestError("Nothing to warm"))
+
+		rr := request(t, warmer, `{}`, "admin")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("rejects an invalid body", func(t *testing.T) {
+		warmer := new(MockCacheWarmer)
+		rr := request(t, warmer, `{"top_movies":"all"}`, "admin")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		warmer.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		rr := request(t, new(MockCacheWarmer), `{"top_movies":5}`, "user")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GrantCritic handles PUT /admin/users/{id}/critic
+func (h *Handler) GrantCritic(w http.ResponseWriter, r *http.Request) {
+	h.setCritic(w, r, true)
+}
+
+// RevokeCritic handles DELETE /admin/users/{id}/critic
+func (h *Handler) RevokeCritic(w http.ResponseWriter, r *http.Request) {
+	h.setCritic(w, r, false)
+}
+
+func (h *Handler) setCritic(w http.ResponseWriter, r *http.Request, critic bool) {
+	adminID, _ := r.Context().Value("user_id").(string)
+	userID := chi.URLParam(r, "id")
+
+	if err := h.adminService.SetCritic(r.Context(), userID, critic, adminID); err != nil {
+		h.logger.Error("[critic_handler] Failed to update critic status", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, CriticResponse{UserID: userID, IsCritic: critic}, http.StatusOK)
+}
@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type MergeResponse struct {
+	ID             int64  `json:"id"`
+	SourceID       string `json:"source_id"`
+	TargetID       string `json:"target_id"`
+	MergedBy       string `json:"merged_by"`
+	MergedAt       string `json:"merged_at"`
+	RatingsMoved   int64  `json:"ratings_moved"`
+	RatingsDropped int64  `json:"ratings_dropped"`
+}
+
+type ShadowBanResponse struct {
+	UserID       string `json:"user_id"`
+	ShadowBanned bool   `json:"shadow_banned"`
+}
+
+type CriticResponse struct {
+	UserID   string `json:"user_id"`
+	IsCritic bool   `json:"is_critic"`
+}
+
+type BulkUsersResponse struct {
+	Action  string           `json:"action"`
+	Summary BulkUsersSummary `json:"summary"`
+	Results []BulkUserResult `json:"results"`
+}
+
+// BulkUsersSummary counts the per-user outcomes of a bulk operation
+type BulkUsersSummary struct {
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	NotFound  int `json:"not_found"`
+	Skipped   int `json:"skipped"`
+}
+
+type BulkUserResult struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type SummaryResponse struct {
+	GeneratedAt       string             `json:"generated_at"`
+	Totals            TotalsResponse     `json:"totals"`
+	RatingsPerDay     []DailyCount       `json:"ratings_per_day"`
+	TopMoviesThisWeek []TopMovieResponse `json:"top_movies_this_week"`
+	NewSignups        SignupsResponse    `json:"new_signups"`
+	Health            HealthResponse     `json:"health"`
+}
+
+type TotalsResponse struct {
+	Users       int64 `json:"users"`
+	ActiveUsers int64 `json:"active_users"`
+	Movies      int64 `json:"movies"`
+	Ratings     int64 `json:"ratings"`
+}
+
+type DailyCount struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+type TopMovieResponse struct {
+	MovieID      string  `json:"movie_id"`
+	Title        string  `json:"title"`
+	ReleaseYear  int     `json:"release_year"`
+	Ratings      int64   `json:"ratings"`
+	AverageScore float64 `json:"average_score"`
+}
+
+type SignupsResponse struct {
+	Last7Days  int64        `json:"last_7_days"`
+	Last30Days int64        `json:"last_30_days"`
+	PerDay     []DailyCount `json:"per_day"`
+}
+
+type HealthResponse struct {
+	Database ComponentHealth `json:"database"`
+	Cache    ComponentHealth `json:"cache"`
+}
+
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// LoggingResponse shows the base log level and per-module overrides
+type LoggingResponse struct {
+	Level   string               `json:"level"`
+	Modules map[string]string    `json:"modules"`
+	Bodies  *BodyLoggingResponse `json:"bodies,omitempty"`
+}
+
+// BodyLoggingResponse shows which requests have their bodies logged
+type BodyLoggingResponse struct {
+	SamplePercent float64  `json:"sample_percent"`
+	Routes        []string `json:"routes"`
+}
+
+// BayesianConfigResponse is how movie scores are computed and shown: the
+// votes for full confidence, the prior weight of the global average, and
+// the ratings a movie needs before its averages are shown
+type BayesianConfigResponse struct {
+	MinVotes          int64   `json:"min_votes"`
+	ConfidenceK       float64 `json:"confidence_k"`
+	DisplayMinRatings int64   `json:"display_min_ratings"`
+}
+
+// GlobalAverageResponse shows the cached global average rating and the
+// running totals behind it
+type GlobalAverageResponse struct {
+	Average      float64 `json:"average"`
+	TotalRatings int64   `json:"total_ratings"`
+	ScoreSum     int64   `json:"score_sum"`
+	// RefreshedAt is when the totals were last recomputed from scratch
+	RefreshedAt string `json:"refreshed_at,omitempty"`
+	// UpdatedAt is when a rating write last changed the totals
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// CachedAt is when the service last read the totals
+	CachedAt string `json:"cached_at,omitempty"`
+}
+
+// JobResponse shows a background job. Result is whatever the job type
+// reports, e.g. the counts of a rating import.
+type JobResponse struct {
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Progress        float64         `json:"progress"` // Percent done
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	CancelRequested bool            `json:"cancel_requested"`
+	CreatedBy       string          `json:"created_by,omitempty"`
+	CreatedAt       string          `json:"created_at"`
+	RunAt           string          `json:"run_at"`
+	StartedAt       string          `json:"started_at,omitempty"`
+	FinishedAt      string          `json:"finished_at,omitempty"`
+}
+
+// PartnerKeyResponse is an API key of the partner API, without its secret
+type PartnerKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedPartnerKeyResponse is a new key with its secret, which is only
+// ever returned here
+type CreatedPartnerKeyResponse struct {
+	PartnerKeyResponse
+	Secret string `json:"secret"`
+}
+
+type PartnerKeysResponse struct {
+	Keys []PartnerKeyResponse `json:"keys"`
+}
+
+type PartnerUsageResponse struct {
+	From          string                      `json:"from"`
+	To            string                      `json:"to"`
+	KeyID         string                      `json:"key_id,omitempty"`
+	TotalRequests int64                       `json:"total_requests"`
+	Usage         []PartnerDailyUsageResponse `json:"usage"`
+}
+
+// PartnerDailyUsageResponse is the requests of a key to one endpoint on
+// one day
+type PartnerDailyUsageResponse struct {
+	KeyID    string    `json:"key_id"`
+	Day      time.Time `json:"day"`
+	Endpoint string    `json:"endpoint"`
+	Requests int64     `json:"requests"`
+}
+
+type UsageReportResponse struct {
+	From          string                   `json:"from"`
+	To            string                   `json:"to"`
+	TotalRequests int64                    `json:"total_requests"`
+	TotalBytes    int64                    `json:"total_bytes"`
+	Principals    []PrincipalUsageResponse `json:"principals"`
+	Counters      []UsageCounterResponse   `json:"counters"`
+}
+
+// PrincipalUsageResponse sums a principal's usage over the report's range
+type PrincipalUsageResponse struct {
+	Kind         string                  `json:"kind"`
+	ID           string                  `json:"id"`
+	Requests     int64                   `json:"requests"`
+	Bytes        int64                   `json:"bytes"`
+	TopEndpoints []EndpointUsageResponse `json:"top_endpoints"`
+}
+
+type EndpointUsageResponse struct {
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// UsageCounterResponse is the requests a principal made to one endpoint on
+// one day
+type UsageCounterResponse struct {
+	Kind        string    `json:"kind"`
+	PrincipalID string    `json:"principal_id"`
+	Day         time.Time `json:"day"`
+	Endpoint    string    `json:"endpoint"`
+	Requests    int64     `json:"requests"`
+	Bytes       int64     `json:"bytes"`
+}
+
+// PosterMatchResponse is a movie whose poster resembles another, Distance
+// bits of their perceptual hashes apart
+type PosterMatchResponse struct {
+	MovieID     string    `json:"movie_id"`
+	Title       string    `json:"title"`
+	ReleaseYear int       `json:"release_year"`
+	Hash        string    `json:"hash"`
+	Distance    int       `json:"distance"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+type PosterDuplicatesResponse struct {
+	MaxDistance int                   `json:"max_distance"`
+	Groups      []PosterGroupResponse `json:"groups"`
+}
+
+// PosterGroupResponse is movies sharing a poster, the first uploaded first;
+// distances are to its poster
+type PosterGroupResponse struct {
+	Movies []PosterMatchResponse `json:"movies"`
+}
+
+type PosterMatchesResponse struct {
+	MaxDistance int                   `json:"max_distance"`
+	Movies      []PosterMatchResponse `json:"movies"`
+}
+
+// CacheWarmResponse counts the movies and users a warmup warmed and those it
+// failed to
+type CacheWarmResponse struct {
+	Movies int `json:"movies"`
+	Users  int `json:"users"`
+	Failed int `json:"failed"`
+}
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		BulkUsersRequest{},
+		CacheWarmRequest{},
+		CreatePartnerKeyRequest{},
+		LoggingRequest{},
+		BayesianConfigRequest{},
+		MergeResponse{},
+		ShadowBanResponse{},
+		CriticResponse{},
+		BulkUsersResponse{},
+		SummaryResponse{},
+		HealthResponse{},
+		LoggingResponse{},
+		GlobalAverageResponse{},
+		BayesianConfigResponse{},
+		JobResponse{},
+		CreatedPartnerKeyResponse{},
+		PartnerKeysResponse{},
+		PartnerUsageResponse{},
+		UsageReportResponse{},
+		CacheWarmResponse{},
+		PosterDuplicatesResponse{},
+		PosterMatchesResponse{},
+	))
+}
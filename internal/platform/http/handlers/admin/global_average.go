@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	ratingService "thermondo/internal/platform/service/rating"
+	"time"
+)
+
+// GlobalAverageService reports and recomputes the global average rating
+type GlobalAverageService interface {
+	GlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error)
+	RefreshGlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error)
+}
+
+// GetGlobalAverage handles GET /admin/global-average
+func (h *Handler) GetGlobalAverage(w http.ResponseWriter, r *http.Request) {
+	global, err := h.globalAverage.GlobalAverage(r.Context())
+	if err != nil {
+		h.logger.Error("[get_global_average_handler] Failed to get global average", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, globalAverageResponse(global), http.StatusOK)
+}
+
+// RefreshGlobalAverage handles POST /admin/global-average/refresh,
+// recomputing the running totals from every rating now instead of waiting
+// for the scheduled refresh
+func (h *Handler) RefreshGlobalAverage(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	global, err := h.globalAverage.RefreshGlobalAverage(r.Context())
+	if err != nil {
+		h.logger.Error("[refresh_global_average_handler] Failed to refresh global average", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[refresh_global_average_handler] Global average refreshed", "admin_id", adminID, "average", global.Average)
+	h.responseWriter.WriteSuccess(w, globalAverageResponse(global), http.StatusOK)
+}
+
+func globalAverageResponse(global *ratingService.GlobalAverage) GlobalAverageResponse {
+	return GlobalAverageResponse{
+		Average:      global.Average,
+		TotalRatings: global.TotalRatings,
+		ScoreSum:     global.ScoreSum,
+		RefreshedAt:  formatOptionalTime(global.RefreshedAt),
+		UpdatedAt:    formatOptionalTime(global.UpdatedAt),
+		CachedAt:     formatOptionalTime(global.LoadedAt),
+	}
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
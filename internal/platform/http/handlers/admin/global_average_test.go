@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGlobalAverageRouter(service *MockGlobalAverageService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+		WithGlobalAverage(service),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestGlobalAverage(t *testing.T) {
+	refreshed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	global := &ratingService.GlobalAverage{
+		Average:     3.82,
+		GlobalStats: rating.GlobalStats{ScoreSum: 382, TotalRatings: 100, RefreshedAt: refreshed, UpdatedAt: refreshed.Add(time.Hour)},
+		LoadedAt:    refreshed.Add(90 * time.Minute),
+	}
+	request := func(t *testing.T, router http.Handler, method, path, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("shows the cached value and refresh times", func(t *testing.T) {
+		service := new(MockGlobalAverageService)
+		service.On("GlobalAverage", mock.Anything).Return(global, nil)
+
+		rr := request(t, setupGlobalAverageRouter(service), http.MethodGet, "/admin/global-average", "admin")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp GlobalAverageResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, GlobalAverageResponse{
+			Average:      3.82,
+			TotalRatings: 100,
+			ScoreSum:     382,
+			RefreshedAt:  "2024-03-01T12:00:00Z",
+			UpdatedAt:    "2024-03-01T13:00:00Z",
+			CachedAt:     "2024-03-01T13:30:00Z",
+		}, resp)
+	})
+
+	t.Run("refresh recomputes the totals", func(t *testing.T) {
+		service := new(MockGlobalAverageService)
+		service.On("RefreshGlobalAverage", mock.Anything).Return(global, nil).Once()
+
+		rr := request(t, setupGlobalAverageRouter(service), http.MethodPost, "/admin/global-average/refresh", "admin")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("refresh failure", func(t *testing.T) {
+		service := new(MockGlobalAverageService)
+		service.On("RefreshGlobalAverage", mock.Anything).Return(nil, appErrors.NewInternalError("Failed to refresh global average"))
+
+		rr := request(t, setupGlobalAverageRouter(service), http.MethodPost, "/admin/global-average/refresh", "admin")
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("non-admins are rejected", func(t *testing.T) {
+		service := new(MockGlobalAverageService)
+
+		rr := request(t, setupGlobalAverageRouter(service), http.MethodGet, "/admin/global-average", "user")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "GlobalAverage", mock.Anything)
+	})
+
+	t.Run("not registered without a service", func(t *testing.T) {
+		rr := request(t, setupRouter(new(MockMovieService), new(MockAdminService)), http.MethodGet, "/admin/global-average", "admin")
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	adminService "thermondo/internal/platform/service/admin"
+	movieService "thermondo/internal/platform/service/movies"
+	ratingService "thermondo/internal/platform/service/rating"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler serves the /admin endpoints. Every route requires a bearer token
+// with the admin role.
+type Handler struct {
+	movieService   movieService.Service
+	adminService   adminService.Service
+	responseWriter *response.Writer
+	auth           *middleware.AuthMiddleware
+	sessions       middleware.SessionValidator
+	logLevels      *logging.Levels
+	bodies         *logging.Bodies
+	globalAverage  GlobalAverageService
+	bayesian       BayesianConfigService
+	ratingImports  ratingService.ImportService
+	jobs           JobService
+	retention      RetentionService
+	cacheWarmer    CacheWarmer
+	partners       PartnerService
+	usage          UsageService
+	logger         *slog.Logger
+}
+
+// Option configures optional behaviour of the admin handler
+type Option func(*Handler)
+
+// WithSessionValidator rejects admin tokens whose session has been revoked
+func WithSessionValidator(validator middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.sessions = validator
+	}
+}
+
+// WithLogLevels enables GET and PUT /admin/logging to change levels at runtime
+func WithLogLevels(levels *logging.Levels) Option {
+	return func(h *Handler) {
+		h.logLevels = levels
+	}
+}
+
+// WithBodyLogging lets PUT /admin/logging choose the requests whose bodies
+// are logged. It needs WithLogLevels.
+func WithBodyLogging(bodies *logging.Bodies) Option {
+	return func(h *Handler) {
+		h.bodies = bodies
+	}
+}
+
+// WithGlobalAverage enables GET /admin/global-average and
+// POST /admin/global-average/refresh
+func WithGlobalAverage(service GlobalAverageService) Option {
+	return func(h *Handler) {
+		h.globalAverage = service
+	}
+}
+
+// WithBayesianConfig enables GET and PUT /admin/ratings/bayesian to change
+// how movie scores are computed and shown at runtime
+func WithBayesianConfig(service BayesianConfigService) Option {
+	return func(h *Handler) {
+		h.bayesian = service
+	}
+}
+
+// WithRatingImports enables POST /admin/ratings/import
+func WithRatingImports(service ratingService.ImportService) Option {
+	return func(h *Handler) {
+		h.ratingImports = service
+	}
+}
+
+// WithJobs enables GET /admin/jobs/{id} and POST /admin/jobs/{id}/cancel
+func WithJobs(service JobService) Option {
+	return func(h *Handler) {
+		h.jobs = service
+	}
+}
+
+// WithRetention enables POST /admin/retention/run
+func WithRetention(service RetentionService) Option {
+	return func(h *Handler) {
+		h.retention = service
+	}
+}
+
+// WithCacheWarmer enables POST /admin/cache/warm
+func WithCacheWarmer(warmer CacheWarmer) Option {
+	return func(h *Handler) {
+		h.cacheWarmer = warmer
+	}
+}
+
+// WithPartners enables the /admin/partners endpoints that manage partner
+// API keys and report their usage
+func WithPartners(service PartnerService) Option {
+	return func(h *Handler) {
+		h.partners = service
+	}
+}
+
+// WithUsage enables GET /admin/usage
+func WithUsage(service UsageService) Option {
+	return func(h *Handler) {
+		h.usage = service
+	}
+}
+
+func NewHandler(movieService movieService.Service, adminService adminService.Service, logger *slog.Logger, keys *tokens.Keys, opts ...Option) *Handler {
+	responseWriter := response.NewWriter(logger)
+	h := &Handler{
+		movieService:   movieService,
+		adminService:   adminService,
+		responseWriter: responseWriter,
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	var authOptions []middleware.AuthOption
+	if h.sessions != nil {
+		authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+	}
+	h.auth = middleware.NewAuthMiddleware(keys, responseWriter, authOptions...)
+	return h
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin))
+		r.Get("/summary", h.GetSummary)
+		r.Post("/movies/{id}/merge-into/{targetId}", h.MergeMovie)
+		r.Put("/users/{id}/shadow-ban", h.ShadowBanUser)
+		r.Delete("/users/{id}/shadow-ban", h.LiftShadowBan)
+		r.Put("/users/{id}/critic", h.GrantCritic)
+		r.Delete("/users/{id}/critic", h.RevokeCritic)
+		r.Post("/users:batch", h.BulkUpdateUsers)
+		r.Get("/posters/duplicates", h.FindDuplicatePosters)
+		r.Post("/posters/search", h.SearchByPoster)
+		if h.logLevels != nil {
+			r.Get("/logging", h.GetLogging)
+			r.Put("/logging", h.UpdateLogging)
+		}
+		if h.globalAverage != nil {
+			r.Get("/global-average", h.GetGlobalAverage)
+			r.Post("/global-average/refresh", h.RefreshGlobalAverage)
+		}
+		if h.bayesian != nil {
+			r.Get("/ratings/bayesian", h.GetBayesianConfig)
+			r.Put("/ratings/bayesian", h.UpdateBayesianConfig)
+		}
+		if h.ratingImports != nil {
+			r.Post("/ratings/import", h.ImportRatings)
+		}
+		if h.jobs != nil {
+			r.Get("/jobs/{id}", h.GetJob)
+			r.Post("/jobs/{id}/cancel", h.CancelJob)
+		}
+		if h.retention != nil {
+			r.Post("/retention/run", h.RunRetention)
+		}
+		if h.cacheWarmer != nil {
+			r.Post("/cache/warm", h.WarmCache)
+		}
+		if h.partners != nil {
+			r.Post("/partners/keys", h.CreatePartnerKey)
+			r.Get("/partners/keys", h.ListPartnerKeys)
+			r.Delete("/partners/keys/{id}", h.RevokePartnerKey)
+			r.Get("/partners/usage", h.GetPartnerUsage)
+		}
+		if h.usage != nil {
+			r.Get("/usage", h.GetUsage)
+		}
+	})
+}
+
+// MergeMovie handles POST /admin/movies/{id}/merge-into/{targetId}, folding the
+// duplicate movie {id} into {targetId}
+func (h *Handler) MergeMovie(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	record, err := h.movieService.MergeMovies(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "targetId"), adminID)
+	if err != nil {
+		h.logger.Error("[merge_movie_handler] Failed to merge movies", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, MergeResponse{
+		ID:             record.ID,
+		SourceID:       string(record.SourceID),
+		TargetID:       string(record.TargetID),
+		MergedBy:       record.MergedBy,
+		MergedAt:       record.MergedAt.Format(time.RFC3339),
+		RatingsMoved:   record.RatingsMoved,
+		RatingsDropped: record.RatingsDropped,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
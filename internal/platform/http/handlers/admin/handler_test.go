@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+func setupRouter(service *MockMovieService, admin *MockAdminService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, admin, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret)).RegisterRoutes(router)
+	return router
+}
+
+func bearerToken(t *testing.T, userID, role string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return "Bearer " + signed
+}
+
+func TestMergeMovie(t *testing.T) {
+	t.Run("admin merges a duplicate", func(t *testing.T) {
+		service := new(MockMovieService)
+		service.On("MergeMovies", mock.Anything, "source", "target", "admin-1").Return(&movies.MergeRecord{
+			ID: 7, SourceID: "source", TargetID: "target", MergedBy: "admin-1", MergedAt: time.Now(), RatingsMoved: 3, RatingsDropped: 1,
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/movies/source/merge-into/target", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(service, new(MockAdminService)).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp MergeResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(3), resp.RatingsMoved)
+		assert.Equal(t, int64(1), resp.RatingsDropped)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), new(MockAdminService)).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/admin/movies/source/merge-into/target", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		service := new(MockMovieService)
+		req := httptest.NewRequest(http.MethodPost, "/admin/movies/source/merge-into/target", nil)
+		req.Header.Set("Authorization", bearerToken(t, "user-1", "user"))
+		rr := httptest.NewRecorder()
+		setupRouter(service, new(MockAdminService)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "MergeMovies", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown movie is 404", func(t *testing.T) {
+		service := new(MockMovieService)
+		service.On("MergeMovies", mock.Anything, "source", "missing", "admin-1").Return(nil, appErrors.NewNotFoundError("Movie not found"))
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/movies/source/merge-into/missing", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(service, new(MockAdminService)).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
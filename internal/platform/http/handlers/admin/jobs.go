@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"thermondo/internal/domain/jobs"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// JobService reports on and cancels background jobs
+type JobService interface {
+	GetJob(ctx context.Context, id string) (*jobs.Job, error)
+	CancelJob(ctx context.Context, id string) (*jobs.Job, error)
+}
+
+// GetJob handles GET /admin/jobs/{id}
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.jobs.GetJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, jobResponse(job), http.StatusOK)
+}
+
+// CancelJob handles POST /admin/jobs/{id}/cancel. A queued job is cancelled
+// right away; a running one stops at its next heartbeat.
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	job, err := h.jobs.CancelJob(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[cancel_job_handler] Job cancellation requested", "admin_id", adminID, "job_id", job.ID, "status", job.Status)
+	h.responseWriter.WriteSuccess(w, jobResponse(job), http.StatusOK)
+}
+
+func jobResponse(job *jobs.Job) JobResponse {
+	response := JobResponse{
+		ID:              job.ID.String(),
+		Type:            job.Type,
+		Status:          string(job.Status),
+		Progress:        math.Round(job.Progress*10) / 10,
+		Result:          job.Result,
+		Error:           job.Error,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		CancelRequested: job.CancelRequested,
+		CreatedBy:       job.CreatedBy,
+		CreatedAt:       formatOptionalTime(job.CreatedAt),
+		RunAt:           formatOptionalTime(job.RunAt),
+	}
+	if job.StartedAt != nil {
+		response.StartedAt = formatOptionalTime(*job.StartedAt)
+	}
+	if job.FinishedAt != nil {
+		response.FinishedAt = formatOptionalTime(*job.FinishedAt)
+	}
+	return response
+}
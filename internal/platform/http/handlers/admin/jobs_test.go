@@ -0,0 +1,104 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/jobs"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobsRouter(service *MockJobService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+		WithJobs(service),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestJobs(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	started := created.Add(time.Minute)
+	running := &jobs.Job{
+		ID:          "job-1",
+		Type:        "ratings.import",
+		Status:      jobs.StatusRunning,
+		Progress:    33.333,
+		Result:      json.RawMessage(`{"imported":10}`),
+		Error:       "connection reset",
+		Attempts:    2,
+		MaxAttempts: 3,
+		CreatedBy:   "admin-1",
+		CreatedAt:   created,
+		RunAt:       created,
+		StartedAt:   &started,
+	}
+	request := func(t *testing.T, router http.Handler, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("shows progress, result and the last error", func(t *testing.T) {
+		service := new(MockJobService)
+		service.On("GetJob", mock.Anything, "job-1").Return(running, nil)
+
+		rr := request(t, setupJobsRouter(service), http.MethodGet, "/admin/jobs/job-1")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp JobResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, 33.3, resp.Progress)
+		assert.Equal(t, "running", resp.Status)
+		assert.JSONEq(t, `{"imported":10}`, string(resp.Result))
+		assert.Equal(t, "connection reset", resp.Error)
+		assert.Equal(t, 2, resp.Attempts)
+		assert.Equal(t, "2024-03-01T12:01:00Z", resp.StartedAt)
+		assert.Empty(t, resp.FinishedAt)
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		service := new(MockJobService)
+		service.On("GetJob", mock.Anything, "missing").Return(nil, appErrors.NewNotFoundError("Job not found"))
+
+		rr := request(t, setupJobsRouter(service), http.MethodGet, "/admin/jobs/missing")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("cancels a job", func(t *testing.T) {
+		cancelling := *running
+		cancelling.CancelRequested = true
+		service := new(MockJobService)
+		service.On("CancelJob", mock.Anything, "job-1").Return(&cancelling, nil)
+
+		rr := request(t, setupJobsRouter(service), http.MethodPost, "/admin/jobs/job-1/cancel")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp JobResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.True(t, resp.CancelRequested)
+	})
+
+	t.Run("finished jobs cannot be cancelled", func(t *testing.T) {
+		service := new(MockJobService)
+		service.On("CancelJob", mock.Anything, "job-1").Return(nil, appErrors.NewConflictError("Job has already finished"))
+
+		rr := request(t, setupJobsRouter(service), http.MethodPost, "/admin/jobs/job-1/cancel")
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
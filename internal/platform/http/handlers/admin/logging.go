@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/logging"
+)
+
+// LoggingRequest changes log levels. Omitted fields are left as they are;
+// an empty module level removes that module's override.
+type LoggingRequest struct {
+	Level   *string             `json:"level"`
+	Modules map[string]string   `json:"modules"`
+	Bodies  *BodyLoggingRequest `json:"bodies"`
+}
+
+// BodyLoggingRequest replaces the selection of requests whose bodies are
+// logged, at debug level of the http module: a percentage of all requests
+// and every request to the given routes. Zero and no routes turn it off.
+type BodyLoggingRequest struct {
+	SamplePercent float64  `json:"sample_percent"`
+	Routes        []string `json:"routes"`
+}
+
+// GetLogging handles GET /admin/logging
+func (h *Handler) GetLogging(w http.ResponseWriter, r *http.Request) {
+	h.responseWriter.WriteSuccess(w, h.loggingResponse(), http.StatusOK)
+}
+
+// UpdateLogging handles PUT /admin/logging, adjusting log levels until the
+// next restart or config reload of LOG_LEVEL
+func (h *Handler) UpdateLogging(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var req LoggingRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	// Validate everything first so a bad entry changes nothing
+	var base *slog.Level
+	if req.Level != nil {
+		level, err := logging.ParseLevel(*req.Level)
+		if err != nil {
+			h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		base = &level
+	}
+	modules := make(map[string]*slog.Level, len(req.Modules))
+	for module, value := range req.Modules {
+		if !logging.IsModule(module) {
+			h.responseWriter.WriteError(w, logging.ErrUnknownModule.Error(), http.StatusBadRequest)
+			return
+		}
+		if value == "" {
+			modules[module] = nil
+			continue
+		}
+		level, err := logging.ParseLevel(value)
+		if err != nil {
+			h.responseWriter.WriteError(w, module+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		modules[module] = &level
+	}
+	if req.Bodies != nil {
+		if h.bodies == nil {
+			h.responseWriter.WriteError(w, "Body logging is not available", http.StatusBadRequest)
+			return
+		}
+		if req.Bodies.SamplePercent < 0 || req.Bodies.SamplePercent > 100 {
+			h.responseWriter.WriteError(w, logging.ErrInvalidSamplePercent.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if base != nil {
+		h.logLevels.SetBase(*base)
+	}
+	for module, level := range modules {
+		if level == nil {
+			h.logLevels.ClearModule(module)
+		} else {
+			h.logLevels.SetModule(module, *level)
+		}
+	}
+	if req.Bodies != nil {
+		h.bodies.Set(req.Bodies.SamplePercent, req.Bodies.Routes)
+	}
+
+	response := h.loggingResponse()
+	// Warn so the change is recorded whatever the new level is
+	h.logger.Warn("[update_logging_handler] Log levels changed", "admin_id", adminID, "level", response.Level, "modules", response.Modules, "bodies", response.Bodies)
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func (h *Handler) loggingResponse() LoggingResponse {
+	response := LoggingResponse{
+		Level:   logging.FormatLevel(h.logLevels.Base()),
+		Modules: make(map[string]string),
+	}
+	for module, level := range h.logLevels.Overrides() {
+		response.Modules[module] = logging.FormatLevel(level)
+	}
+	if h.bodies != nil {
+		response.Bodies = &BodyLoggingResponse{
+			SamplePercent: h.bodies.Percent(),
+			Routes:        h.bodies.Routes(),
+		}
+		if response.Bodies.Routes == nil {
+			response.Bodies.Routes = []string{}
+		}
+	}
+	return response
+}
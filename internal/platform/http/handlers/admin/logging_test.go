@@ -0,0 +1,129 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLoggingRouter(levels *logging.Levels) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+		WithLogLevels(levels),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestUpdateLogging(t *testing.T) {
+	put := func(t *testing.T, router http.Handler, role, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/admin/logging", strings.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("changes the base level and module overrides", func(t *testing.T) {
+		levels := logging.NewLevels(slog.LevelInfo)
+		require.NoError(t, levels.SetModule(logging.ModuleCache, slog.LevelWarn))
+		router := setupLoggingRouter(levels)
+
+		rr := put(t, router, "admin", `{"level":"warn","modules":{"repository":"debug","cache":""}}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp LoggingResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, LoggingResponse{Level: "warn", Modules: map[string]string{"repository": "debug"}}, resp)
+		assert.Equal(t, slog.LevelWarn, levels.Base())
+		assert.Equal(t, slog.LevelDebug, levels.For(logging.ModuleRepository))
+		assert.Equal(t, slog.LevelWarn, levels.For(logging.ModuleCache))
+	})
+
+	t.Run("omitted level is kept", func(t *testing.T) {
+		levels := logging.NewLevels(slog.LevelError)
+		rr := put(t, setupLoggingRouter(levels), "admin", `{"modules":{"http":"debug"}}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, slog.LevelError, levels.Base())
+		assert.Equal(t, slog.LevelDebug, levels.For(logging.ModuleHTTP))
+	})
+
+	t.Run("invalid entries change nothing", func(t *testing.T) {
+		for _, body := range []string{
+			`{"level":"verbose"}`,
+			`{"level":"debug","modules":{"templates":"debug"}}`,
+			`{"level":"debug","modules":{"http":"loud"}}`,
+			`not json`,
+		} {
+			levels := logging.NewLevels(slog.LevelInfo)
+			rr := put(t, setupLoggingRouter(levels), "admin", body)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+			assert.Equal(t, slog.LevelInfo, levels.Base(), body)
+			assert.Empty(t, levels.Overrides(), body)
+		}
+	})
+
+	t.Run("selects the requests whose bodies are logged", func(t *testing.T) {
+		levels := logging.NewLevels(slog.LevelInfo)
+		bodies := logging.NewBodies()
+		router := chi.NewRouter()
+		NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+			WithLogLevels(levels),
+			WithBodyLogging(bodies),
+		).RegisterRoutes(router)
+
+		rr := put(t, router, "admin", `{"modules":{"http":"debug"},"bodies":{"sample_percent":5,"routes":["POST /api/v1/users/"]}}`)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp LoggingResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, &BodyLoggingResponse{SamplePercent: 5, Routes: []string{"POST /api/v1/users/"}}, resp.Bodies)
+		assert.Equal(t, 5.0, bodies.Percent())
+
+		rr = put(t, router, "admin", `{"level":"debug","bodies":{"sample_percent":101}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, slog.LevelInfo, levels.Base(), "a bad selection changes nothing")
+		assert.Equal(t, 5.0, bodies.Percent())
+
+		rr = put(t, setupLoggingRouter(levels), "admin", `{"bodies":{"sample_percent":1}}`)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "body logging is not configured")
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		levels := logging.NewLevels(slog.LevelInfo)
+		rr := put(t, setupLoggingRouter(levels), "user", `{"level":"debug"}`)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Equal(t, slog.LevelInfo, levels.Base())
+	})
+}
+
+func TestGetLogging(t *testing.T) {
+	levels := logging.NewLevels(slog.LevelDebug)
+	req := httptest.NewRequest(http.MethodGet, "/admin/logging", nil)
+	req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+	rr := httptest.NewRecorder()
+	setupLoggingRouter(levels).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp LoggingResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, LoggingResponse{Level: "debug", Modules: map[string]string{}}, resp)
+
+	// Without levels configured the routes do not exist
+	rr = httptest.NewRecorder()
+	setupRouter(new(MockMovieService), new(MockAdminService)).ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/partners"
+	"thermondo/internal/domain/users"
+
+	adminService "thermondo/internal/platform/service/admin"
+	movieService "thermondo/internal/platform/service/movies"
+	partnerService "thermondo/internal/platform/service/partners"
+	ratingService "thermondo/internal/platform/service/rating"
+	usageService "thermondo/internal/platform/service/usage"
+	"thermondo/internal/platform/service/warmup"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMovieService mocks the admin operations of the movie service; the
+// embedded interface panics for anything else
+type MockMovieService struct {
+	movieService.Service
+	mock.Mock
+}
+
+func (m *MockMovieService) MergeMovies(ctx context.Context, sourceID, targetID, mergedBy string) (*movies.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID, mergedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.MergeRecord), args.Error(1)
+}
+
+func (m *MockMovieService) FindDuplicatePosters(ctx context.Context, maxDistance int) ([]*movieService.PosterDuplicates, error) {
+	args := m.Called(ctx, maxDistance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterDuplicates), args.Error(1)
+}
+
+func (m *MockMovieService) SearchByPoster(ctx context.Context, data []byte, maxDistance int) ([]*movieService.PosterMatch, error) {
+	args := m.Called(ctx, data, maxDistance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterMatch), args.Error(1)
+}
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) SetShadowBan(ctx context.Context, userID string, banned bool, adminID string) error {
+	args := m.Called(ctx, userID, banned, adminID)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) SetCritic(ctx context.Context, userID string, critic bool, adminID string) error {
+	args := m.Called(ctx, userID, critic, adminID)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) BulkUpdateUsers(ctx context.Context, req adminService.BulkUsersRequest, adminID string) ([]users.BulkResult, error) {
+	args := m.Called(ctx, req, adminID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.BulkResult), args.Error(1)
+}
+
+func (m *MockAdminService) GetSummary(ctx context.Context) (*adminService.Summary, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*adminService.Summary), args.Error(1)
+}
+
+// MockGlobalAverageService is a mock implementation of GlobalAverageService
+type MockGlobalAverageService struct {
+	mock.Mock
+}
+
+func (m *MockGlobalAverageService) GlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.GlobalAverage), args.Error(1)
+}
+
+func (m *MockGlobalAverageService) RefreshGlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.GlobalAverage), args.Error(1)
+}
+
+// MockBayesianConfigService is a mock implementation of BayesianConfigService
+type MockBayesianConfigService struct {
+	mock.Mock
+}
+
+func (m *MockBayesianConfigService) GetBayesianConfig() ratingService.BayesianConfig {
+	args := m.Called()
+	return args.Get(0).(ratingService.BayesianConfig)
+}
+
+func (m *MockBayesianConfigService) SetBayesianConfig(config ratingService.BayesianConfig) {
+	m.Called(config)
+}
+
+// MockImportService is a mock implementation of ratingService.ImportService
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) StartImport(ctx context.Context, body io.Reader, startedBy string) (*jobs.Job, error) {
+	args := m.Called(ctx, body, startedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jobs.Job), args.Error(1)
+}
+
+func (m *MockImportService) RunImport(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	args := m.Called(ctx, job, report)
+	return args.Error(0)
+}
+
+// MockJobService is a mock implementation of JobService
+type MockJobService struct {
+	mock.Mock
+}
+
+func (m *MockJobService) GetJob(ctx context.Context, id string) (*jobs.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jobs.Job), args.Error(1)
+}
+
+func (m *MockJobService) CancelJob(ctx context.Context, id string) (*jobs.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jobs.Job), args.Error(1)
+}
+
+// MockRetentionService is a mock implementation of RetentionService
+type MockRetentionService struct {
+	mock.Mock
+}
+
+func (m *MockRetentionService) StartRun(ctx context.Context, dryRun bool, startedBy string) (*jobs.Job, error) {
+	args := m.Called(ctx, dryRun, startedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jobs.Job), args.Error(1)
+}
+
+// MockCacheWarmer is a mock implementation of CacheWarmer
+type MockCacheWarmer struct {
+	mock.Mock
+}
+
+func (m *MockCacheWarmer) Start(ctx context.Context, req warmup.Request, startedBy string) (*warmup.Result, *jobs.Job, error) {
+	args := m.Called(ctx, req, startedBy)
+	var result *warmup.Result
+	if args.Get(0) != nil {
+		result = args.Get(0).(*warmup.Result)
+	}
+	var job *jobs.Job
+	if args.Get(1) != nil {
+		job = args.Get(1).(*jobs.Job)
+	}
+	return result, job, args.Error(2)
+}
+
+// MockPartnerService is a mock implementation of PartnerService
+type MockPartnerService struct {
+	mock.Mock
+}
+
+func (m *MockPartnerService) CreateKey(ctx context.Context, req partnerService.CreateKeyRequest) (*partnerService.CreatedKey, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partnerService.CreatedKey), args.Error(1)
+}
+
+func (m *MockPartnerService) ListKeys(ctx context.Context) ([]*partners.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*partners.APIKey), args.Error(1)
+}
+
+func (m *MockPartnerService) RevokeKey(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPartnerService) GetUsage(ctx context.Context, req partnerService.UsageRequest) (*partnerService.UsageReport, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partnerService.UsageReport), args.Error(1)
+}
+
+// MockUsageService is a mock implementation of UsageService
+type MockUsageService struct {
+	mock.Mock
+}
+
+func (m *MockUsageService) GetUsage(ctx context.Context, req usageService.ReportRequest) (*usageService.Report, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usageService.Report), args.Error(1)
+}
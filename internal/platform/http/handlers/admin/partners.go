@@ -0,0 +1,140 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"thermondo/internal/domain/partners"
+	"thermondo/internal/pkg/http/request"
+	partnerService "thermondo/internal/platform/service/partners"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PartnerService manages the API keys of the partner API and reports their
+// usage
+type PartnerService interface {
+	CreateKey(ctx context.Context, req partnerService.CreateKeyRequest) (*partnerService.CreatedKey, error)
+	ListKeys(ctx context.Context) ([]*partners.APIKey, error)
+	RevokeKey(ctx context.Context, id string) error
+	GetUsage(ctx context.Context, req partnerService.UsageRequest) (*partnerService.UsageReport, error)
+}
+
+// CreatePartnerKeyRequest is the body of POST /admin/partners/keys
+type CreatePartnerKeyRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	RateLimit int      `json:"rate_limit"` // Requests per minute; 0 leaves the key unlimited
+}
+
+func (r CreatePartnerKeyRequest) toService() partnerService.CreateKeyRequest {
+	req := partnerService.CreateKeyRequest{Name: r.Name, RateLimit: r.RateLimit}
+	for _, scope := range r.Scopes {
+		req.Scopes = append(req.Scopes, partners.Scope(scope))
+	}
+	return req
+}
+
+// CreatePartnerKey handles POST /admin/partners/keys. The response holds the
+// key's secret, which can't be retrieved again.
+func (h *Handler) CreatePartnerKey(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var req CreatePartnerKeyRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	created, err := h.partners.CreateKey(r.Context(), req.toService())
+	if err != nil {
+		h.logger.Error("[create_partner_key_handler] Failed to create API key", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[create_partner_key_handler] API key created", "admin_id", adminID, "key_id", created.ID, "name", created.Name)
+	h.responseWriter.WriteSuccess(w, CreatedPartnerKeyResponse{
+		PartnerKeyResponse: partnerKeyToResponse(created.APIKey),
+		Secret:             created.Secret,
+	}, http.StatusCreated)
+}
+
+// ListPartnerKeys handles GET /admin/partners/keys
+func (h *Handler) ListPartnerKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.partners.ListKeys(r.Context())
+	if err != nil {
+		h.logger.Error("[list_partner_keys_handler] Failed to list API keys", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+	response := PartnerKeysResponse{Keys: make([]PartnerKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, partnerKeyToResponse(key))
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// RevokePartnerKey handles DELETE /admin/partners/keys/{id}. Revoked keys
+// are kept so their usage can still be reported.
+func (h *Handler) RevokePartnerKey(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+	keyID := chi.URLParam(r, "id")
+
+	if err := h.partners.RevokeKey(r.Context(), keyID); err != nil {
+		h.logger.Error("[revoke_partner_key_handler] Failed to revoke API key", "error", err, "key_id", keyID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[revoke_partner_key_handler] API key revoked", "admin_id", adminID, "key_id", keyID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPartnerUsage handles GET /admin/partners/usage?from=&to=&key_id=, the
+// requests per key, endpoint and day for billing
+func (h *Handler) GetPartnerUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	report, err := h.partners.GetUsage(r.Context(), partnerService.UsageRequest{
+		From:  query.Get("from"),
+		To:    query.Get("to"),
+		KeyID: query.Get("key_id"),
+	})
+	if err != nil {
+		h.logger.Error("[get_partner_usage_handler] Failed to get usage", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+	response := PartnerUsageResponse{
+		From:          report.From,
+		To:            report.To,
+		KeyID:         report.KeyID,
+		TotalRequests: report.TotalRequests,
+		Usage:         make([]PartnerDailyUsageResponse, 0, len(report.Usage)),
+	}
+	for _, usage := range report.Usage {
+		response.Usage = append(response.Usage, PartnerDailyUsageResponse{
+			KeyID:    string(usage.KeyID),
+			Day:      usage.Day,
+			Endpoint: usage.Endpoint,
+			Requests: usage.Requests,
+		})
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func partnerKeyToResponse(key *partners.APIKey) PartnerKeyResponse {
+	response := PartnerKeyResponse{
+		ID:         string(key.ID),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     make([]string, 0, len(key.Scopes)),
+		RateLimit:  key.RateLimit,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+	for _, scope := range key.Scopes {
+		response.Scopes = append(response.Scopes, string(scope))
+	}
+	return response
+}
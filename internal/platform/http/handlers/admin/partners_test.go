@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/domain/partners"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	partnerService "thermondo/internal/platform/service/partners"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPartnerKeys(t *testing.T) {
+	request := func(t *testing.T, service *MockPartnerService, method, path, body, role string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+			WithPartners(service),
+		).RegisterRoutes(router)
+
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("creates a key", func(t *testing.T) {
+		service := new(MockPartnerService)
+		req := partnerService.CreateKeyRequest{Name: "Acme", Scopes: []partners.Scope{partners.ScopeScoresRead}, RateLimit: 60}
+		service.On("CreateKey", mock.Anything, req).Return(&partnerService.CreatedKey{
+			APIKey: &partners.APIKey{ID: "key-1", Name: "Acme", Prefix: "pk_abcdef", Hash: "stored-hash", Scopes: req.Scopes, RateLimit: 60},
+			Secret: "pk_abcdef-secret",
+		}, nil)
+
+		rr := request(t, service, http.MethodPost, "/admin/partners/keys", `{"name":"Acme","scopes":["scores:read"],"rate_limit":60}`, "admin")
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"secret":"pk_abcdef-secret"`)
+		assert.NotContains(t, rr.Body.String(), "stored-hash")
+		service.AssertExpectations(t)
+	})
+
+	t.Run("revokes a key", func(t *testing.T) {
+		service := new(MockPartnerService)
+		service.On("RevokeKey", mock.Anything, "key-1").Return(nil)
+		service.On("RevokeKey", mock.Anything, "key-2").Return(appErrors.NewNotFoundError("API key not found"))
+
+		assert.Equal(t, http.StatusNoContent, request(t, service, http.MethodDelete, "/admin/partners/keys/key-1", "", "admin").Code)
+		assert.Equal(t, http.StatusNotFound, request(t, service, http.MethodDelete, "/admin/partners/keys/key-2", "", "admin").Code)
+	})
+
+	t.Run("reports usage", func(t *testing.T) {
+		service := new(MockPartnerService)
+		service.On("GetUsage", mock.Anything, partnerService.UsageRequest{From: "2024-03-01", To: "2024-03-31", KeyID: "key-1"}).
+			Return(&partnerService.UsageReport{From: "2024-03-01", To: "2024-03-31", KeyID: "key-1", TotalRequests: 42, Usage: []*partners.Usage{}}, nil)
+
+		rr := request(t, service, http.MethodGet, "/admin/partners/usage?from=2024-03-01&to=2024-03-31&key_id=key-1", "", "admin")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"total_requests":42`)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		service := new(MockPartnerService)
+		rr := request(t, service, http.MethodGet, "/admin/partners/keys", "", "user")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "ListKeys", mock.Anything)
+	})
+}
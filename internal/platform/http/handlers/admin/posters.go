@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	appErrors "thermondo/internal/pkg/errors"
+	movieService "thermondo/internal/platform/service/movies"
+)
+
+// maxPosterSearchBytes caps the image of a poster search, as uploads are
+const maxPosterSearchBytes = 10 << 20
+
+// FindDuplicatePosters handles GET /admin/posters/duplicates?max_distance=,
+// listing the groups of movies whose posters are the same or nearly so:
+// duplicate catalog entries, or artwork uploaded to the wrong movie
+func (h *Handler) FindDuplicatePosters(w http.ResponseWriter, r *http.Request) {
+	maxDistance, ok := h.posterMatchDistance(w, r)
+	if !ok {
+		return
+	}
+
+	groups, err := h.movieService.FindDuplicatePosters(r.Context(), maxDistance)
+	if err != nil {
+		h.logger.Error("[find_duplicate_posters_handler] Failed to find duplicate posters", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := PosterDuplicatesResponse{MaxDistance: maxDistance, Groups: make([]PosterGroupResponse, len(groups))}
+	for i, group := range groups {
+		response.Groups[i] = PosterGroupResponse{Movies: posterMatchesToResponse(group.Movies)}
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// SearchByPoster handles POST /admin/posters/search?max_distance= with a
+// JPEG or PNG image as the body, listing the movies whose poster resembles
+// it, closest first
+func (h *Handler) SearchByPoster(w http.ResponseWriter, r *http.Request) {
+	maxDistance, ok := h.posterMatchDistance(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPosterSearchBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.handleServiceError(w, appErrors.NewPayloadTooLargeError("Image must be at most 10 MB"))
+			return
+		}
+		h.handleServiceError(w, appErrors.NewBadRequestError("Failed to read image"))
+		return
+	}
+	if len(data) == 0 {
+		h.handleServiceError(w, appErrors.NewBadRequestError("Request body must be a JPEG or PNG image"))
+		return
+	}
+
+	matches, err := h.movieService.SearchByPoster(r.Context(), data, maxDistance)
+	if err != nil {
+		h.logger.Error("[search_by_poster_handler] Failed to search by poster", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, PosterMatchesResponse{MaxDistance: maxDistance, Movies: posterMatchesToResponse(matches)}, http.StatusOK)
+}
+
+// posterMatchDistance reads ?max_distance=, writing a 400 for anything but
+// an integer; the service checks its range
+func (h *Handler) posterMatchDistance(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("max_distance")
+	if value == "" {
+		return movieService.DefaultPosterMatchDistance, true
+	}
+	maxDistance, err := strconv.Atoi(value)
+	if err != nil {
+		h.handleServiceError(w, appErrors.NewBadRequestError("max_distance must be an integer"))
+		return 0, false
+	}
+	return maxDistance, true
+}
+
+func posterMatchesToResponse(matches []*movieService.PosterMatch) []PosterMatchResponse {
+	response := make([]PosterMatchResponse, len(matches))
+	for i, match := range matches {
+		response[i] = PosterMatchResponse{
+			MovieID:     string(match.MovieID),
+			Title:       match.Title,
+			ReleaseYear: match.ReleaseYear,
+			Hash:        match.Hash.String(),
+			Distance:    match.Distance,
+			UploadedAt:  match.CreatedAt,
+		}
+	}
+	return response
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/tokens"
+	movieService "thermondo/internal/platform/service/movies"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPosterDuplicates(t *testing.T) {
+	request := func(t *testing.T, service *MockMovieService, method, path string, body []byte) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(service, new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret)).
+			RegisterRoutes(router)
+
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	uploadedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	match := func(id string, distance int) *movieService.PosterMatch {
+		return &movieService.PosterMatch{
+			PosterFingerprint: movies.PosterFingerprint{MovieID: movies.MovieID(id), Title: "Dune", ReleaseYear: 2021, Hash: 0xff00, CreatedAt: uploadedAt},
+			Distance:          distance,
+		}
+	}
+
+	t.Run("lists groups of duplicates at the default distance", func(t *testing.T) {
+		service := new(MockMovieService)
+		service.On("FindDuplicatePosters", mock.Anything, movieService.DefaultPosterMatchDistance).
+			Return([]*movieService.PosterDuplicates{{Movies: []*movieService.PosterMatch{match("dune", 0), match("dune-copy", 1)}}}, nil)
+
+		rr := request(t, service, http.MethodGet, "/admin/posters/duplicates", nil)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"max_distance":4`)
+		assert.Contains(t, rr.Body.String(), `{"movie_id":"dune-copy","title":"Dune","release_year":2021,"hash":"000000000000ff00","distance":1,"uploaded_at":"2024-06-01T00:00:00Z"}`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("rejects a distance that is not a number", func(t *testing.T) {
+		service := new(MockMovieService)
+		rr := request(t, service, http.MethodGet, "/admin/posters/duplicates?max_distance=close", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "FindDuplicatePosters", mock.Anything, mock.Anything)
+	})
+
+	t.Run("searches by an uploaded image", func(t *testing.T) {
+		service := new(MockMovieService)
+		service.On("SearchByPoster", mock.Anything, []byte("png data"), 8).
+			Return([]*movieService.PosterMatch{match("dune", 3)}, nil)
+
+		rr := request(t, service, http.MethodPost, "/admin/posters/search?max_distance=8", []byte("png data"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"movies":[{"movie_id":"dune"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("requires an image to search by", func(t *testing.T) {
+		service := new(MockMovieService)
+		rr := request(t, service, http.MethodPost, "/admin/posters/search", nil)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "SearchByPoster", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an image over the size limit", func(t *testing.T) {
+		rr := request(t, new(MockMovieService), http.MethodPost, "/admin/posters/search", make([]byte, maxPosterSearchBytes+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+}
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"net/http"
+)
+
+// ImportRatings handles POST /admin/ratings/import. The body is an NDJSON
+// stream of historical ratings; it is stored and imported by a background
+// job, and the response points at the job to poll.
+func (h *Handler) ImportRatings(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	job, err := h.ratingImports.StartImport(r.Context(), r.Body, adminID)
+	if err != nil {
+		h.logger.Error("[import_ratings_handler] Failed to start rating import", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[import_ratings_handler] Rating import queued", "admin_id", adminID, "job_id", job.ID)
+	w.Header().Set("Location", "/api/v1/admin/jobs/"+job.ID.String())
+	h.responseWriter.WriteSuccess(w, jobResponse(job), http.StatusAccepted)
+}
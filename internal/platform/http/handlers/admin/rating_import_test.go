@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/jobs"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRatingImportRouter(service *MockImportService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+		WithRatingImports(service),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestImportRatings(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	request := func(t *testing.T, router http.Handler, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/ratings/import", strings.NewReader(`{"user_id":"u1"}`+"\n"))
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("queues an import and points at its job", func(t *testing.T) {
+		service := new(MockImportService)
+		service.On("StartImport", mock.Anything, mock.Anything, "admin-1").Return(&jobs.Job{
+			ID:          "job-1",
+			Type:        "ratings.import",
+			Status:      jobs.StatusQueued,
+			MaxAttempts: 3,
+			CreatedBy:   "admin-1",
+			CreatedAt:   created,
+			RunAt:       created,
+		}, nil)
+
+		rr := request(t, setupRatingImportRouter(service), "admin")
+
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "/api/v1/admin/jobs/job-1", rr.Header().Get("Location"))
+		var resp JobResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "queued", resp.Status)
+		assert.Equal(t, "ratings.import", resp.Type)
+		assert.Equal(t, "2024-03-01T12:00:00Z", resp.CreatedAt)
+	})
+
+	t.Run("oversized upload", func(t *testing.T) {
+		service := new(MockImportService)
+		service.On("StartImport", mock.Anything, mock.Anything, "admin-1").
+			Return(nil, appErrors.NewPayloadTooLargeError("Import must not exceed 10 bytes"))
+
+		rr := request(t, setupRatingImportRouter(service), "admin")
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+
+	t.Run("requires an admin", func(t *testing.T) {
+		service := new(MockImportService)
+
+		rr := request(t, setupRatingImportRouter(service), "user")
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "StartImport", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/jobs"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+// RetentionService queues runs of the retention policy
+type RetentionService interface {
+	StartRun(ctx context.Context, dryRun bool, startedBy string) (*jobs.Job, error)
+}
+
+// RunRetention handles POST /admin/retention/run. With ?dry_run=true the
+// job only counts what it would remove.
+func (h *Handler) RunRetention(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := r.Context().Value("user_id").(string)
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			h.handleServiceError(w, appErrors.NewBadRequestError("dry_run must be true or false"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	job, err := h.retention.StartRun(r.Context(), dryRun, adminID)
+	if err != nil {
+		h.logger.Error("[run_retention_handler] Failed to start retention run", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("[run_retention_handler] Retention run queued", "admin_id", adminID, "job_id", job.ID, "dry_run", dryRun)
+	w.Header().Set("Location", "/api/v1/admin/jobs/"+job.ID.String())
+	h.responseWriter.WriteSuccess(w, jobResponse(job), http.StatusAccepted)
+}
@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunRetention(t *testing.T) {
+	request := func(t *testing.T, service *MockRetentionService, path, role string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+			WithRetention(service),
+		).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("queues a dry run", func(t *testing.T) {
+		service := new(MockRetentionService)
+		service.On("StartRun", mock.Anything, true, "admin-1").
+			Return(&jobs.Job{ID: "job-1", Type: "retention.purge", Status: jobs.StatusQueued}, nil)
+
+		rr := request(t, service, "/admin/retention/run?dry_run=true", "admin")
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "/api/v1/admin/jobs/job-1", rr.Header().Get("Location"))
+		assert.Contains(t, rr.Body.String(), `"type":"retention.purge"`)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid dry_run", func(t *testing.T) {
+		service := new(MockRetentionService)
+		rr := request(t, service, "/admin/retention/run?dry_run=maybe", "admin")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "StartRun", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		rr := request(t, new(MockRetentionService), "/admin/retention/run", "user")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
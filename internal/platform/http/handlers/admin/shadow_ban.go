@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ShadowBanUser handles PUT /admin/users/{id}/shadow-ban
+func (h *Handler) ShadowBanUser(w http.ResponseWriter, r *http.Request) {
+	h.setShadowBan(w, r, true)
+}
+
+// LiftShadowBan handles DELETE /admin/users/{id}/shadow-ban
+func (h *Handler) LiftShadowBan(w http.ResponseWriter, r *http.Request) {
+	h.setShadowBan(w, r, false)
+}
+
+func (h *Handler) setShadowBan(w http.ResponseWriter, r *http.Request, banned bool) {
+	adminID, _ := r.Context().Value("user_id").(string)
+	userID := chi.URLParam(r, "id")
+
+	if err := h.adminService.SetShadowBan(r.Context(), userID, banned, adminID); err != nil {
+		h.logger.Error("[shadow_ban_handler] Failed to update shadow ban", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, ShadowBanResponse{UserID: userID, ShadowBanned: banned}, http.StatusOK)
+}
@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowBan(t *testing.T) {
+	t.Run("admin shadow-bans a user", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("SetShadowBan", mock.Anything, "user-1", true, "admin-1").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/users/user-1/shadow-ban", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body ShadowBanResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, ShadowBanResponse{UserID: "user-1", ShadowBanned: true}, body)
+	})
+
+	t.Run("admin lifts a shadow ban", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("SetShadowBan", mock.Anything, "user-1", false, "admin-1").Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/admin/users/user-1/shadow-ban", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("SetShadowBan", mock.Anything, "missing", true, "admin-1").Return(appErrors.NewNotFoundError("User not found"))
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/users/missing/shadow-ban", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		service := new(MockAdminService)
+
+		req := httptest.NewRequest(http.MethodPut, "/admin/users/user-1/shadow-ban", nil)
+		req.Header.Set("Authorization", bearerToken(t, "user-1", "user"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "SetShadowBan", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"math"
+	"net/http"
+	"thermondo/internal/domain/admin"
+	adminService "thermondo/internal/platform/service/admin"
+	"time"
+)
+
+// GetSummary handles GET /admin/summary, the ops dashboard overview
+func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.adminService.GetSummary(r.Context())
+	if err != nil {
+		h.logger.Error("[get_summary_handler] Failed to get admin summary", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, summaryToResponse(summary), http.StatusOK)
+}
+
+func summaryToResponse(summary *adminService.Summary) SummaryResponse {
+	top := make([]TopMovieResponse, len(summary.TopMoviesThisWeek))
+	for i, movie := range summary.TopMoviesThisWeek {
+		top[i] = TopMovieResponse{
+			MovieID:      string(movie.MovieID),
+			Title:        movie.Title,
+			ReleaseYear:  movie.ReleaseYear,
+			Ratings:      movie.Ratings,
+			AverageScore: math.Round(movie.AverageScore*100) / 100,
+		}
+	}
+
+	return SummaryResponse{
+		GeneratedAt: summary.GeneratedAt.Format(time.RFC3339),
+		Totals: TotalsResponse{
+			Users:       summary.Totals.Users,
+			ActiveUsers: summary.Totals.ActiveUsers,
+			Movies:      summary.Totals.Movies,
+			Ratings:     summary.Totals.Ratings,
+		},
+		RatingsPerDay:     dailyCountsToResponse(summary.RatingsPerDay),
+		TopMoviesThisWeek: top,
+		NewSignups: SignupsResponse{
+			Last7Days:  summary.NewSignups.Last7Days,
+			Last30Days: summary.NewSignups.Last30Days,
+			PerDay:     dailyCountsToResponse(summary.NewSignups.PerDay),
+		},
+		Health: HealthResponse{
+			Database: ComponentHealth(summary.Health.Database),
+			Cache:    ComponentHealth(summary.Health.Cache),
+		},
+	}
+}
+
+func dailyCountsToResponse(counts []admin.DailyCount) []DailyCount {
+	response := make([]DailyCount, len(counts))
+	for i, c := range counts {
+		response[i] = DailyCount{Date: c.Date.Format("2006-01-02"), Count: c.Count}
+	}
+	return response
+}
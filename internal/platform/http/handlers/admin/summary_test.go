@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/admin"
+	adminService "thermondo/internal/platform/service/admin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSummary(t *testing.T) {
+	t.Run("admin gets the summary", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("GetSummary", mock.Anything).Return(&adminService.Summary{
+			GeneratedAt:       time.Date(2024, 3, 30, 15, 30, 0, 0, time.UTC),
+			Totals:            admin.Totals{Users: 10, ActiveUsers: 8, Movies: 50, Ratings: 200},
+			RatingsPerDay:     []admin.DailyCount{{Date: time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC), Count: 6}},
+			TopMoviesThisWeek: []*admin.TopMovie{{MovieID: "m1", Title: "Alien", ReleaseYear: 1979, Ratings: 9, AverageScore: 4.4444}},
+			NewSignups:        adminService.Signups{Last7Days: 3, Last30Days: 5},
+			Health: adminService.Health{
+				Database: adminService.ComponentHealth{Status: adminService.HealthUp, LatencyMs: 1},
+				Cache:    adminService.ComponentHealth{Status: adminService.HealthDown, Error: "connection refused"},
+			},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/summary", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var body SummaryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, "2024-03-30T15:30:00Z", body.GeneratedAt)
+		assert.Equal(t, int64(200), body.Totals.Ratings)
+		assert.Equal(t, []DailyCount{{Date: "2024-03-30", Count: 6}}, body.RatingsPerDay)
+		require.Len(t, body.TopMoviesThisWeek, 1)
+		assert.Equal(t, 4.44, body.TopMoviesThisWeek[0].AverageScore)
+		assert.Equal(t, int64(3), body.NewSignups.Last7Days)
+		assert.Equal(t, "down", body.Health.Cache.Status)
+		assert.Equal(t, "connection refused", body.Health.Cache.Error)
+	})
+
+	t.Run("non-admin is forbidden", func(t *testing.T) {
+		service := new(MockAdminService)
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/summary", nil)
+		req.Header.Set("Authorization", bearerToken(t, "user-1", "user"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "GetSummary", mock.Anything)
+	})
+
+	t.Run("service failure is a server error", func(t *testing.T) {
+		service := new(MockAdminService)
+		service.On("GetSummary", mock.Anything).Return(nil, errors.New("boom"))
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/summary", nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", "admin"))
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockMovieService), service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
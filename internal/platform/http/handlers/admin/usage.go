@@ -0,0 +1,102 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	appErrors "thermondo/internal/pkg/errors"
+	usageService "thermondo/internal/platform/service/usage"
+)
+
+// UsageService reports metered usage per principal
+type UsageService interface {
+	GetUsage(ctx context.Context, req usageService.ReportRequest) (*usageService.Report, error)
+}
+
+// GetUsage handles GET /admin/usage?from=&to=&kind=&principal_id=&top=. With
+// ?format=csv the counters are sent as a CSV attachment instead, one row
+// per principal, endpoint and day.
+func (h *Handler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" {
+		h.handleServiceError(w, appErrors.NewBadRequestError("format must be 'csv' or 'json'"))
+		return
+	}
+
+	req := usageService.ReportRequest{
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		Kind:        query.Get("kind"),
+		PrincipalID: query.Get("principal_id"),
+	}
+	if value := query.Get("top"); value != "" {
+		top, err := strconv.Atoi(value)
+		if err != nil || top < 1 {
+			h.handleServiceError(w, appErrors.NewBadRequestError("top must be a positive integer"))
+			return
+		}
+		req.Top = top
+	}
+
+	report, err := h.usage.GetUsage(r.Context(), req)
+	if err != nil {
+		h.logger.Error("[get_usage_handler] Failed to get usage", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	if format == "json" {
+		h.responseWriter.WriteSuccess(w, usageReportToResponse(report), http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s-%s.csv"`, report.From, report.To))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := usageService.WriteCSV(w, report.Counters); err != nil {
+		h.logger.Error("[get_usage_handler] Failed to write usage", "error", err)
+	}
+}
+
+func usageReportToResponse(report *usageService.Report) UsageReportResponse {
+	response := UsageReportResponse{
+		From:          report.From,
+		To:            report.To,
+		TotalRequests: report.TotalRequests,
+		TotalBytes:    report.TotalBytes,
+		Principals:    make([]PrincipalUsageResponse, 0, len(report.Principals)),
+		Counters:      make([]UsageCounterResponse, 0, len(report.Counters)),
+	}
+	for _, principal := range report.Principals {
+		principalResponse := PrincipalUsageResponse{
+			Kind:         string(principal.Kind),
+			ID:           principal.ID,
+			Requests:     principal.Requests,
+			Bytes:        principal.Bytes,
+			TopEndpoints: make([]EndpointUsageResponse, 0, len(principal.TopEndpoints)),
+		}
+		for _, endpoint := range principal.TopEndpoints {
+			principalResponse.TopEndpoints = append(principalResponse.TopEndpoints, EndpointUsageResponse{
+				Endpoint: endpoint.Endpoint,
+				Requests: endpoint.Requests,
+				Bytes:    endpoint.Bytes,
+			})
+		}
+		response.Principals = append(response.Principals, principalResponse)
+	}
+	for _, counter := range report.Counters {
+		response.Counters = append(response.Counters, UsageCounterResponse{
+			Kind:        string(counter.Kind),
+			PrincipalID: counter.PrincipalID,
+			Day:         counter.Day,
+			Endpoint:    counter.Endpoint,
+			Requests:    counter.Requests,
+			Bytes:       counter.Bytes,
+		})
+	}
+	return response
+}
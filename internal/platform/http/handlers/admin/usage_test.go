@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/usage"
+	"thermondo/internal/pkg/tokens"
+	usageService "thermondo/internal/platform/service/usage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUsage(t *testing.T) {
+	request := func(t *testing.T, service *MockUsageService, path, role string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(new(MockMovieService), new(MockAdminService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret),
+			WithUsage(service),
+		).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", bearerToken(t, "admin-1", role))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	report := &usageService.Report{
+		From: "2024-03-01", To: "2024-03-31", TotalRequests: 2, TotalBytes: 20,
+		Principals: []*usageService.PrincipalUsage{},
+		Counters: []*usage.Counter{
+			{Kind: usage.KindUser, PrincipalID: "user-1", Day: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), Endpoint: "GET /api/v1/movies", Requests: 2, Bytes: 20},
+		},
+	}
+	req := usageService.ReportRequest{From: "2024-03-01", To: "2024-03-31", Kind: "user", Top: 3}
+
+	t.Run("as JSON", func(t *testing.T) {
+		service := new(MockUsageService)
+		service.On("GetUsage", mock.Anything, req).Return(report, nil)
+
+		rr := request(t, service, "/admin/usage?from=2024-03-01&to=2024-03-31&kind=user&top=3", "admin")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"total_requests":2`)
+	})
+
+	t.Run("as CSV", func(t *testing.T) {
+		service := new(MockUsageService)
+		service.On("GetUsage", mock.Anything, req).Return(report, nil)
+
+		rr := request(t, service, "/admin/usage?from=2024-03-01&to=2024-03-31&kind=user&top=3&format=csv", "admin")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="usage-2024-03-01-2024-03-31.csv"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "day,kind,principal_id,endpoint,requests,bytes\n2024-03-02,user,user-1,GET /api/v1/movies,2,20\n", rr.Body.String())
+	})
+
+	t.Run("rejects bad parameters", func(t *testing.T) {
+		service := new(MockUsageService)
+		assert.Equal(t, http.StatusBadRequest, request(t, service, "/admin/usage?format=xml", "admin").Code)
+		assert.Equal(t, http.StatusBadRequest, request(t, service, "/admin/usage?top=0", "admin").Code)
+		service.AssertNotCalled(t, "GetUsage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires the admin role", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(t, new(MockUsageService), "/admin/usage", "user").Code)
+	})
+}
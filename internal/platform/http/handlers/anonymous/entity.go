@@ -0,0 +1,54 @@
+package anonymous
+
+import "time"
+
+// RateRequest is the body of PUT /anonymous/ratings/{movieId}
+type RateRequest struct {
+	Score  int    `json:"score"`
+	Review string `json:"review"`
+}
+
+// ClaimRequest is the body of POST /auth/claim. OnConflict defaults to
+// keep_account.
+type ClaimRequest struct {
+	DeviceToken string `json:"device_token"`
+	OnConflict  string `json:"on_conflict"`
+}
+
+// StartResponse holds the token the device authenticates with from now on,
+// which is only ever returned here
+type StartResponse struct {
+	PrincipalID string    `json:"principal_id"`
+	DeviceToken string    `json:"device_token"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RatingResponse struct {
+	MovieID   string    `json:"movie_id"`
+	Score     int       `json:"score"`
+	Review    string    `json:"review,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RatingsResponse struct {
+	Ratings []RatingResponse `json:"ratings"`
+}
+
+// ClaimResponse sums up moving the device's ratings to the account
+type ClaimResponse struct {
+	PrincipalID string             `json:"principal_id"`
+	UserID      string             `json:"user_id"`
+	Imported    int                `json:"imported"`
+	Conflicts   []ConflictResponse `json:"conflicts"`
+	ClaimedAt   time.Time          `json:"claimed_at"`
+}
+
+// ConflictResponse is a movie rated both on the device and on the account,
+// and which score was kept
+type ConflictResponse struct {
+	MovieID        string `json:"movie_id"`
+	AccountScore   int    `json:"account_score"`
+	AnonymousScore int    `json:"anonymous_score"`
+	Kept           string `json:"kept"`
+}
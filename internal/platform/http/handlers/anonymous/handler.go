@@ -0,0 +1,223 @@
+package anonymous
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"thermondo/internal/domain/anonymous"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	anonymousService "thermondo/internal/platform/service/anonymous"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeviceTokenHeader carries the token of an anonymous device
+const DeviceTokenHeader = "X-Device-Token"
+
+type principalKey struct{}
+
+// Handler serves anonymous ratings: devices start a session under
+// /auth/anonymous, rate under /anonymous/ratings with their device token,
+// and the ratings move to an account through /auth/claim
+type Handler struct {
+	service        anonymousService.Service
+	responseWriter *response.Writer
+	auth           *middleware.AuthMiddleware
+	sessions       middleware.SessionValidator
+	startLimiter   *ratelimit.Limiter
+	logger         *slog.Logger
+}
+
+// Option configures optional behaviour of the anonymous handler
+type Option func(*Handler)
+
+// WithSessionValidator rejects claims made with tokens whose session has
+// been revoked
+func WithSessionValidator(validator middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.sessions = validator
+	}
+}
+
+// WithStartRateLimit caps the devices started per client IP
+func WithStartRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.startLimiter = limiter
+	}
+}
+
+func NewHandler(service anonymousService.Service, logger *slog.Logger, keys *tokens.Keys, opts ...Option) *Handler {
+	responseWriter := response.NewWriter(logger)
+	h := &Handler{
+		service:        service,
+		responseWriter: responseWriter,
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	var authOptions []middleware.AuthOption
+	if h.sessions != nil {
+		authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+	}
+	h.auth = middleware.NewAuthMiddleware(keys, responseWriter, authOptions...)
+	return h
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	var startMiddleware []func(http.Handler) http.Handler
+	if h.startLimiter != nil {
+		startMiddleware = append(startMiddleware, middleware.RateLimitByIP(h.startLimiter, h.responseWriter))
+	}
+
+	router.With(startMiddleware...).Post("/auth/anonymous", h.Start)
+	router.With(h.auth.Authenticate).Post("/auth/claim", h.Claim)
+
+	router.Route("/anonymous/ratings", func(r chi.Router) {
+		r.Use(h.authenticateDevice)
+		r.Get("/", h.ListRatings)
+		r.Put("/{movieId}", h.Rate)
+	})
+}
+
+// authenticateDevice resolves the device token header to its principal
+func (h *Handler) authenticateDevice(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := h.service.Authenticate(r.Context(), r.Header.Get(DeviceTokenHeader))
+		if err != nil {
+			h.writeServiceError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
+
+func principalFrom(r *http.Request) *anonymous.Principal {
+	principal, _ := r.Context().Value(principalKey{}).(*anonymous.Principal)
+	return principal
+}
+
+// Start handles POST /auth/anonymous, registering a device that can rate
+// without an account
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	started, err := h.service.Start(r.Context())
+	if err != nil {
+		h.logger.Error("[start_anonymous_handler] Failed to start anonymous session", "error", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, StartResponse{
+		PrincipalID: string(started.PrincipalID),
+		DeviceToken: started.DeviceToken,
+		CreatedAt:   started.CreatedAt,
+	}, http.StatusCreated)
+}
+
+// Rate handles PUT /anonymous/ratings/{movieId}
+func (h *Handler) Rate(w http.ResponseWriter, r *http.Request) {
+	var req RateRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[rate_anonymous_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	principal := principalFrom(r)
+	saved, err := h.service.Rate(r.Context(), principal.ID, req.toService(chi.URLParam(r, "movieId")))
+	if err != nil {
+		h.logger.Error("[rate_anonymous_handler] Failed to save rating", "error", err, "principal_id", principal.ID)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, ratingToResponse(saved), http.StatusOK)
+}
+
+// ListRatings handles GET /anonymous/ratings
+func (h *Handler) ListRatings(w http.ResponseWriter, r *http.Request) {
+	principal := principalFrom(r)
+	ratings, err := h.service.ListRatings(r.Context(), principal.ID)
+	if err != nil {
+		h.logger.Error("[list_anonymous_ratings_handler] Failed to list ratings", "error", err, "principal_id", principal.ID)
+		h.writeServiceError(w, err)
+		return
+	}
+	response := RatingsResponse{Ratings: make([]RatingResponse, 0, len(ratings))}
+	for _, rating := range ratings {
+		response.Ratings = append(response.Ratings, ratingToResponse(rating))
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// Claim handles POST /auth/claim, moving a device's ratings to the account
+// of the bearer token. It is meant to be called right after signing up on
+// the device.
+func (h *Handler) Claim(w http.ResponseWriter, r *http.Request) {
+	var req ClaimRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[claim_anonymous_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	userID, _ := r.Context().Value("user_id").(string)
+	result, err := h.service.Claim(r.Context(), userID, req.toService())
+	if err != nil {
+		h.logger.Error("[claim_anonymous_handler] Failed to claim ratings", "error", err, "user_id", userID)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, claimToResponse(result), http.StatusOK)
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func (r RateRequest) toService(movieID string) anonymousService.RateRequest {
+	return anonymousService.RateRequest{MovieID: movieID, Score: r.Score, Review: r.Review}
+}
+
+func (r ClaimRequest) toService() anonymousService.ClaimRequest {
+	return anonymousService.ClaimRequest{DeviceToken: r.DeviceToken, OnConflict: r.OnConflict}
+}
+
+func ratingToResponse(rating *anonymous.Rating) RatingResponse {
+	return RatingResponse{
+		MovieID:   string(rating.MovieID),
+		Score:     rating.Score,
+		Review:    rating.Review,
+		CreatedAt: rating.CreatedAt,
+		UpdatedAt: rating.UpdatedAt,
+	}
+}
+
+func claimToResponse(result *anonymous.ClaimResult) ClaimResponse {
+	response := ClaimResponse{
+		PrincipalID: string(result.PrincipalID),
+		UserID:      string(result.UserID),
+		Imported:    result.Imported,
+		Conflicts:   make([]ConflictResponse, 0, len(result.Conflicts)),
+		ClaimedAt:   result.ClaimedAt,
+	}
+	for _, conflict := range result.Conflicts {
+		response.Conflicts = append(response.Conflicts, ConflictResponse{
+			MovieID:        string(conflict.MovieID),
+			AccountScore:   conflict.AccountScore,
+			AnonymousScore: conflict.AnonymousScore,
+			Kept:           string(conflict.Kept),
+		})
+	}
+	return response
+}
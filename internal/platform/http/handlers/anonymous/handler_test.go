@@ -0,0 +1,139 @@
+package anonymous
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/anonymous"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	anonymousService "thermondo/internal/platform/service/anonymous"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupRouter(service *MockAnonymousService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret)).RegisterRoutes(router)
+	return router
+}
+
+func bearerRequest(t *testing.T, method, target, userID, body string) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestStart(t *testing.T) {
+	service := new(MockAnonymousService)
+	service.On("Start", mock.Anything).Return(&anonymousService.StartResponse{
+		PrincipalID: "principal-1", DeviceToken: "anon_phone", CreatedAt: testNow,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/anonymous", nil))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"principal_id":"principal-1","device_token":"anon_phone","created_at":"2024-01-01T12:00:00Z"}`, w.Body.String())
+}
+
+func TestRate(t *testing.T) {
+	t.Run("with a device token", func(t *testing.T) {
+		service := new(MockAnonymousService)
+		service.On("Authenticate", mock.Anything, "anon_phone").Return(&anonymous.Principal{ID: "principal-1"}, nil)
+		service.On("Rate", mock.Anything, anonymous.PrincipalID("principal-1"), anonymousService.RateRequest{MovieID: "movie-1", Score: 4}).
+			Return(&anonymous.Rating{MovieID: "movie-1", Score: 4, CreatedAt: testNow, UpdatedAt: testNow}, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/anonymous/ratings/movie-1", strings.NewReader(`{"score":4}`))
+		req.Header.Set(DeviceTokenHeader, "anon_phone")
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"movie_id":"movie-1","score":4,"created_at":"2024-01-01T12:00:00Z","updated_at":"2024-01-01T12:00:00Z"}`, w.Body.String())
+	})
+
+	t.Run("without a device token", func(t *testing.T) {
+		service := new(MockAnonymousService)
+		service.On("Authenticate", mock.Anything, "").Return(nil, appErrors.NewUnauthorizedError("Missing device token"))
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/anonymous/ratings/movie-1", strings.NewReader(`{"score":4}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		service.AssertNotCalled(t, "Rate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestListRatings(t *testing.T) {
+	service := new(MockAnonymousService)
+	service.On("Authenticate", mock.Anything, "anon_phone").Return(&anonymous.Principal{ID: "principal-1"}, nil)
+	service.On("ListRatings", mock.Anything, anonymous.PrincipalID("principal-1")).Return([]*anonymous.Rating{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/anonymous/ratings", nil)
+	req.Header.Set(DeviceTokenHeader, "anon_phone")
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ratings":[]}`, w.Body.String())
+}
+
+func TestClaim(t *testing.T) {
+	t.Run("claims for the caller", func(t *testing.T) {
+		service := new(MockAnonymousService)
+		service.On("Claim", mock.Anything, "user-1", anonymousService.ClaimRequest{DeviceToken: "anon_phone", OnConflict: "keep_newest"}).
+			Return(&anonymous.ClaimResult{
+				PrincipalID: "principal-1", UserID: "user-1", Imported: 2, ClaimedAt: testNow,
+				Conflicts: []anonymous.Conflict{{MovieID: "movie-1", AccountScore: 2, AnonymousScore: 5, Kept: anonymous.KeptAnonymous}},
+			}, nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodPost, "/auth/claim", "user-1", `{"device_token":"anon_phone","on_conflict":"keep_newest"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"principal_id":"principal-1","user_id":"user-1","imported":2,"claimed_at":"2024-01-01T12:00:00Z",
+			"conflicts":[{"movie_id":"movie-1","account_score":2,"anonymous_score":5,"kept":"anonymous"}]}`, w.Body.String())
+	})
+
+	t.Run("already claimed", func(t *testing.T) {
+		service := new(MockAnonymousService)
+		service.On("Claim", mock.Anything, "user-1", mock.Anything).Return(nil, appErrors.NewConflictError(anonymous.ErrAlreadyClaimed.Error()))
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodPost, "/auth/claim", "user-1", `{"device_token":"anon_phone"}`))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("without a bearer token", func(t *testing.T) {
+		service := new(MockAnonymousService)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/claim", strings.NewReader(`{"device_token":"anon_phone"}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		service.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
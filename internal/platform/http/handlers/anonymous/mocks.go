@@ -0,0 +1,54 @@
+package anonymous
+
+import (
+	"context"
+	"thermondo/internal/domain/anonymous"
+	anonymousService "thermondo/internal/platform/service/anonymous"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAnonymousService is a mock implementation of the anonymous service
+type MockAnonymousService struct {
+	mock.Mock
+}
+
+func (m *MockAnonymousService) Start(ctx context.Context) (*anonymousService.StartResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymousService.StartResponse), args.Error(1)
+}
+
+func (m *MockAnonymousService) Authenticate(ctx context.Context, deviceToken string) (*anonymous.Principal, error) {
+	args := m.Called(ctx, deviceToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.Principal), args.Error(1)
+}
+
+func (m *MockAnonymousService) Rate(ctx context.Context, principalID anonymous.PrincipalID, req anonymousService.RateRequest) (*anonymous.Rating, error) {
+	args := m.Called(ctx, principalID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.Rating), args.Error(1)
+}
+
+func (m *MockAnonymousService) ListRatings(ctx context.Context, principalID anonymous.PrincipalID) ([]*anonymous.Rating, error) {
+	args := m.Called(ctx, principalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*anonymous.Rating), args.Error(1)
+}
+
+func (m *MockAnonymousService) Claim(ctx context.Context, userID string, req anonymousService.ClaimRequest) (*anonymous.ClaimResult, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.ClaimResult), args.Error(1)
+}
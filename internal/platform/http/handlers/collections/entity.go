@@ -0,0 +1,39 @@
+package collections
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type AddMovieRequest struct {
+	MovieID  string `json:"movie_id"`
+	Position *int   `json:"position,omitempty"` // 1-based; the movie is appended when omitted
+}
+
+type CollectionResponse struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	CreatedAt   string                   `json:"created_at"`
+	UpdatedAt   string                   `json:"updated_at"`
+	Stats       *CollectionStatsResponse `json:"stats,omitempty"`
+	Movies      []CollectionMovieSummary `json:"movies,omitempty"`
+}
+
+type CollectionStatsResponse struct {
+	MovieCount            int     `json:"movie_count"`
+	TotalRatings          int64   `json:"total_ratings"`
+	AverageBayesianRating float64 `json:"average_bayesian_rating"`
+}
+
+type CollectionMovieSummary struct {
+	Position        int     `json:"position"`
+	ID              string  `json:"id"`
+	Title           string  `json:"title"`
+	ReleaseYear     int     `json:"release_year"`
+	Genre           string  `json:"genre"`
+	PosterURL       *string `json:"poster_url,omitempty"`
+	AverageScore    float64 `json:"average_score"`
+	TotalRatings    int64   `json:"total_ratings"`
+	BayesianAverage float64 `json:"bayesian_average"`
+}
@@ -0,0 +1,17 @@
+package collections
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		CreateCollectionRequest{},
+		AddMovieRequest{},
+		CollectionResponse{},
+	))
+}
@@ -0,0 +1,205 @@
+package collections
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	collectionService "thermondo/internal/platform/service/collections"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	collectionService collectionService.Service
+	responseWriter    *response.Writer
+	logger            *slog.Logger
+	auth              *middleware.AuthMiddleware
+	keys              *tokens.Keys
+	sessions          middleware.SessionValidator
+}
+
+// Option configures optional behaviour of the collection handler
+type Option func(*Handler)
+
+// WithAuthentication verifies bearer tokens on the routes that curate
+// collections, rejecting tokens whose session has been revoked
+func WithAuthentication(keys *tokens.Keys, sessions middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.keys = keys
+		h.sessions = sessions
+	}
+}
+
+func NewHandler(collectionService collectionService.Service, logger *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{
+		collectionService: collectionService,
+		responseWriter:    response.NewWriter(logger),
+		logger:            logger,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.keys != nil {
+		var authOptions []middleware.AuthOption
+		if h.sessions != nil {
+			authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+		}
+		h.auth = middleware.NewAuthMiddleware(h.keys, h.responseWriter, authOptions...)
+	}
+
+	return h
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Route("/collections", func(r chi.Router) {
+		r.Get("/{id}", h.GetCollection)
+
+		// Curating collections is for admins; without authentication
+		// configured these routes are not registered
+		if h.auth != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin))
+				r.Post("/", h.CreateCollection)
+				r.Post("/{id}/movies", h.AddMovie)
+				r.Delete("/{id}/movies/{movieId}", h.RemoveMovie)
+			})
+		}
+	})
+}
+
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req CreateCollectionRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	collection, err := h.collectionService.CreateCollection(r.Context(), req.toService())
+	if err != nil {
+		h.logger.Error("[create_collection_handler] Failed to create collection", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, collectionToResponse(collection), http.StatusCreated)
+}
+
+// GetCollection handles GET /collections/{id}, embedding the ordered movie
+// summaries and aggregated rating stats
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	details, err := h.collectionService.GetCollection(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.Error("[get_collection_handler] Failed to get collection", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, detailsToResponse(details), http.StatusOK)
+}
+
+// AddMovie attaches a movie and responds with the updated collection
+func (h *Handler) AddMovie(w http.ResponseWriter, r *http.Request) {
+	var req AddMovieRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	collectionID := chi.URLParam(r, "id")
+	if err := h.collectionService.AddMovie(r.Context(), collectionID, req.toService()); err != nil {
+		h.logger.Error("[add_collection_movie_handler] Failed to add movie", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	details, err := h.collectionService.GetCollection(r.Context(), collectionID)
+	if err != nil {
+		h.logger.Error("[add_collection_movie_handler] Failed to get collection", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, detailsToResponse(details), http.StatusOK)
+}
+
+func (h *Handler) RemoveMovie(w http.ResponseWriter, r *http.Request) {
+	err := h.collectionService.RemoveMovie(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "movieId"))
+	if err != nil {
+		h.logger.Error("[remove_collection_movie_handler] Failed to remove movie", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Movie removed from collection"}, http.StatusOK)
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func (r CreateCollectionRequest) toService() collectionService.CreateCollectionRequest {
+	return collectionService.CreateCollectionRequest{Name: r.Name, Description: r.Description}
+}
+
+func (r AddMovieRequest) toService() collectionService.AddMovieRequest {
+	return collectionService.AddMovieRequest{MovieID: r.MovieID, Position: r.Position}
+}
+
+func collectionToResponse(c *collections.Collection) CollectionResponse {
+	return CollectionResponse{
+		ID:          string(c.ID),
+		Name:        c.Name,
+		Description: c.Description,
+		CreatedAt:   c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func detailsToResponse(details *collectionService.CollectionDetails) CollectionResponse {
+	resp := collectionToResponse(details.Collection)
+	resp.Stats = &CollectionStatsResponse{
+		MovieCount:            details.Stats.MovieCount,
+		TotalRatings:          details.Stats.TotalRatings,
+		AverageBayesianRating: round2(details.Stats.AverageBayesianRating),
+	}
+	resp.Movies = make([]CollectionMovieSummary, len(details.Entries))
+	for i, e := range details.Entries {
+		resp.Movies[i] = CollectionMovieSummary{
+			Position:        e.Position,
+			ID:              string(e.Movie.ID),
+			Title:           e.Movie.Title,
+			ReleaseYear:     e.Movie.ReleaseYear,
+			Genre:           e.Movie.Genre,
+			PosterURL:       e.Movie.PosterURL,
+			AverageScore:    round2(e.AverageScore),
+			TotalRatings:    e.TotalRatings,
+			BayesianAverage: round2(e.BayesianAverage),
+		}
+	}
+	return resp
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
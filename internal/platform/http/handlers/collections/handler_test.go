@@ -0,0 +1,148 @@
+package collections
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	collectionService "thermondo/internal/platform/service/collections"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const collectionTestSecret = "collection-test-secret"
+
+func setupRouter(service *MockCollectionService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithAuthentication(tokens.FromSecret(collectionTestSecret), nil),
+	).RegisterRoutes(router)
+	return router
+}
+
+// roleRequest is a request carrying the bearer token of a caller with role
+func roleRequest(t *testing.T, method, target, role string, body io.Reader) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": role + "-1",
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(collectionTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func createRequestBody(v interface{}) io.Reader {
+	jsonData, _ := json.Marshal(v)
+	return bytes.NewReader(jsonData)
+}
+
+func createTestDetails() *collectionService.CollectionDetails {
+	return &collectionService.CollectionDetails{
+		Collection: &collections.Collection{ID: "collection-1", Name: "The Lord of the Rings Trilogy", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+		Entries: []*collections.Entry{
+			{Movie: &movies.Movie{ID: "movie-1", Title: "The Fellowship of the Ring", ReleaseYear: 2001}, Position: 1, AverageScore: 4.6, TotalRatings: 50, BayesianAverage: 4.1234},
+		},
+		Stats: collections.Stats{MovieCount: 1, TotalRatings: 50, AverageBayesianRating: 4.1234},
+	}
+}
+
+func TestGetCollection(t *testing.T) {
+	t.Run("returns the collection with movies and stats", func(t *testing.T) {
+		service := new(MockCollectionService)
+		service.On("GetCollection", mock.Anything, "collection-1").Return(createTestDetails(), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/collections/collection-1", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp CollectionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Stats)
+		assert.Equal(t, 4.12, resp.Stats.AverageBayesianRating)
+		require.Len(t, resp.Movies, 1)
+		assert.Equal(t, 1, resp.Movies[0].Position)
+		assert.Equal(t, "The Fellowship of the Ring", resp.Movies[0].Title)
+	})
+
+	t.Run("unknown collection is 404", func(t *testing.T) {
+		service := new(MockCollectionService)
+		service.On("GetCollection", mock.Anything, "missing").Return(nil, appErrors.NewNotFoundError("Collection not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/collections/missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestCreateCollection(t *testing.T) {
+	service := new(MockCollectionService)
+	req := CreateCollectionRequest{Name: "Alien"}
+	service.On("CreateCollection", mock.Anything, collectionService.CreateCollectionRequest{Name: "Alien"}).Return(&collections.Collection{ID: "collection-2", Name: "Alien"}, nil)
+
+	rr := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(rr, roleRequest(t, http.MethodPost, "/collections", "admin", createRequestBody(req)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var resp CollectionResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "collection-2", resp.ID)
+	assert.Nil(t, resp.Stats)
+}
+
+func TestAddMovie(t *testing.T) {
+	service := new(MockCollectionService)
+	position := 1
+	req := AddMovieRequest{MovieID: "movie-1", Position: &position}
+	service.On("AddMovie", mock.Anything, "collection-1", collectionService.AddMovieRequest{MovieID: "movie-1", Position: &position}).Return(nil)
+	service.On("GetCollection", mock.Anything, "collection-1").Return(createTestDetails(), nil)
+
+	rr := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(rr, roleRequest(t, http.MethodPost, "/collections/collection-1/movies", "admin", createRequestBody(req)))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	service.AssertExpectations(t)
+}
+
+// TestCurationRequiresAdmin checks that collections are curated by admins
+// only
+func TestCurationRequiresAdmin(t *testing.T) {
+	routes := []struct{ method, target string }{
+		{http.MethodPost, "/collections"},
+		{http.MethodPost, "/collections/collection-1/movies"},
+		{http.MethodDelete, "/collections/collection-1/movies/movie-1"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.target, func(t *testing.T) {
+			service := new(MockCollectionService)
+
+			rr := httptest.NewRecorder()
+			setupRouter(service).ServeHTTP(rr, httptest.NewRequest(route.method, route.target, nil))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+			rr = httptest.NewRecorder()
+			setupRouter(service).ServeHTTP(rr, roleRequest(t, route.method, route.target, "user", nil))
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+
+			assert.Empty(t, service.Calls)
+		})
+	}
+}
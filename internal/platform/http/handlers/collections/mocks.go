@@ -0,0 +1,41 @@
+package collections
+
+import (
+	"context"
+	"thermondo/internal/domain/collections"
+
+	collectionService "thermondo/internal/platform/service/collections"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCollectionService is a mock implementation of the collections.Service interface
+type MockCollectionService struct {
+	mock.Mock
+}
+
+func (m *MockCollectionService) CreateCollection(ctx context.Context, req collectionService.CreateCollectionRequest) (*collections.Collection, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*collections.Collection), args.Error(1)
+}
+
+func (m *MockCollectionService) GetCollection(ctx context.Context, id string) (*collectionService.CollectionDetails, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*collectionService.CollectionDetails), args.Error(1)
+}
+
+func (m *MockCollectionService) AddMovie(ctx context.Context, collectionID string, req collectionService.AddMovieRequest) error {
+	args := m.Called(ctx, collectionID, req)
+	return args.Error(0)
+}
+
+func (m *MockCollectionService) RemoveMovie(ctx context.Context, collectionID, movieID string) error {
+	args := m.Called(ctx, collectionID, movieID)
+	return args.Error(0)
+}
@@ -0,0 +1,124 @@
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	feedService "thermondo/internal/platform/service/feeds"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// cacheControl lets readers and CDNs keep a feed as long as the service
+// caches it
+const cacheControl = "public, max-age=300"
+
+// Handler serves the Atom feeds. It is mounted at /feeds, outside the API,
+// so the feed URLs stay stable across API versions.
+type Handler struct {
+	service        feedService.Service
+	responseWriter *response.Writer
+	logger         *slog.Logger
+}
+
+func NewHandler(service feedService.Service, logger *slog.Logger) *Handler {
+	return &Handler{
+		service:        service,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Get("/new-movies.atom", h.GetNewMovies)
+	router.Get("/movie/{movieId}/reviews.atom", h.GetMovieReviews)
+}
+
+// GetNewMovies handles GET /feeds/new-movies.atom
+func (h *Handler) GetNewMovies(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.service.NewMovies(r.Context())
+	if err != nil {
+		h.logger.Error("[new_movies_feed_handler] Failed to build feed", "error", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeFeed(w, r, feed)
+}
+
+// GetMovieReviews handles GET /feeds/movie/{movieId}/reviews.atom
+func (h *Handler) GetMovieReviews(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.service.MovieReviews(r.Context(), chi.URLParam(r, "movieId"))
+	if err != nil {
+		h.logger.Error("[movie_reviews_feed_handler] Failed to build feed", "error", err)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeFeed(w, r, feed)
+}
+
+// writeFeed renders the feed with an ETag of its content and its Updated
+// time as Last-Modified, so readers polling with If-None-Match or
+// If-Modified-Since get 304 Not Modified until it changes
+func (h *Handler) writeFeed(w http.ResponseWriter, r *http.Request, feed *feedService.Feed) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(toAtomFeed(feed)); err != nil {
+		h.logger.Error("[feed_handler] Failed to render feed", "error", err, "feed", feed.ID)
+		h.responseWriter.WriteError(w, "Failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControl)
+	http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(buf.Bytes()))
+}
+
+func toAtomFeed(feed *feedService.Feed) *AtomFeed {
+	doc := &AtomFeed{
+		Xmlns:   atomNamespace,
+		ID:      feed.ID,
+		Title:   feed.Title,
+		Updated: atomTime(feed.Updated),
+		Author:  AtomAuthor{Name: feed.Author},
+		Links: []AtomLink{
+			{Href: feed.ID, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]AtomEntry, len(feed.Entries)),
+	}
+	for i, entry := range feed.Entries {
+		doc.Entries[i] = AtomEntry{
+			ID:      entry.ID,
+			Title:   entry.Title,
+			Link:    AtomLink{Href: entry.Link, Rel: "alternate", Type: "text/html"},
+			Updated: atomTime(entry.Updated),
+			Content: AtomContent{Type: "html", Body: entry.Content},
+		}
+		if !entry.Published.IsZero() {
+			doc.Entries[i].Published = atomTime(entry.Published)
+		}
+	}
+	return doc
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
@@ -0,0 +1,157 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/errors"
+	feedService "thermondo/internal/platform/service/feeds"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouter(service feedService.Service) *chi.Mux {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := chi.NewRouter()
+	router.Route("/feeds", NewHandler(service, logger).RegisterRoutes)
+	return router
+}
+
+var updated = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+func reviewsFeed() *feedService.Feed {
+	return &feedService.Feed{
+		ID:      "https://movies.example.com/feeds/movie/movie-1/reviews.atom",
+		Title:   "Reviews of Heat (1995)",
+		Link:    "https://movies.example.com/movies/movie-1",
+		Author:  "movies.example.com",
+		Updated: updated,
+		Entries: []*feedService.Entry{{
+			ID:        "https://movies.example.com/movies/movie-1#review-rating-1",
+			Title:     "5/5 for Heat",
+			Link:      "https://movies.example.com/movies/movie-1#review-rating-1",
+			Content:   "<p><strong>Tense</strong></p>",
+			Published: updated.Add(-time.Hour),
+			Updated:   updated,
+		}},
+	}
+}
+
+func TestGetMovieReviews(t *testing.T) {
+	t.Run("should serve the feed as Atom", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("MovieReviews", mock.Anything, "movie-1").Return(reviewsFeed(), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/movie/movie-1/reviews.atom", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/atom+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, updated.Format(http.TimeFormat), rr.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, rr.Header().Get("ETag"))
+		assert.Contains(t, rr.Body.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`)
+		assert.Contains(t, rr.Body.String(), `&lt;p&gt;&lt;strong&gt;Tense`, "the HTML content is escaped")
+
+		var feed AtomFeed
+		require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+		assert.Equal(t, "2024-05-01T12:30:00Z", feed.Updated)
+		require.Len(t, feed.Entries, 1)
+		assert.Equal(t, "2024-05-01T11:30:00Z", feed.Entries[0].Published)
+		assert.Equal(t, "html", feed.Entries[0].Content.Type)
+		assert.Equal(t, "<p><strong>Tense</strong></p>", feed.Entries[0].Content.Body)
+	})
+
+	t.Run("should answer not modified to a matching ETag", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("MovieReviews", mock.Anything, "movie-1").Return(reviewsFeed(), nil)
+		router := setupRouter(service)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/movie/movie-1/reviews.atom", nil))
+		etag := rr.Header().Get("ETag")
+
+		req := httptest.NewRequest(http.MethodGet, "/feeds/movie/movie-1/reviews.atom", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	t.Run("should answer not modified since the last update", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("MovieReviews", mock.Anything, "movie-1").Return(reviewsFeed(), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/feeds/movie/movie-1/reviews.atom", nil)
+		req.Header.Set("If-Modified-Since", updated.Add(time.Minute).Format(http.TimeFormat))
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("should serve a changed feed", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("MovieReviews", mock.Anything, "movie-1").Return(reviewsFeed(), nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/feeds/movie/movie-1/reviews.atom", nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		req.Header.Set("If-Modified-Since", updated.Add(-time.Minute).Format(http.TimeFormat))
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("should pass service errors through", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("MovieReviews", mock.Anything, "missing").Return(nil, errors.NewNotFoundError("Movie not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/movie/missing/reviews.atom", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetNewMovies(t *testing.T) {
+	t.Run("should serve an empty catalog", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("NewMovies", mock.Anything).Return(&feedService.Feed{
+			ID:    "https://movies.example.com/feeds/new-movies.atom",
+			Title: "New movies",
+			Link:  "https://movies.example.com/movies",
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/new-movies.atom", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Empty(t, rr.Header().Get("Last-Modified"), "there is no update to date it by")
+		var feed AtomFeed
+		require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &feed))
+		assert.Equal(t, "New movies", feed.Title)
+		assert.Empty(t, feed.Entries)
+		require.Len(t, feed.Links, 2)
+		assert.Equal(t, "self", feed.Links[0].Rel)
+	})
+
+	t.Run("should fail on service errors", func(t *testing.T) {
+		service := new(MockFeedService)
+		service.On("NewMovies", mock.Anything).Return(nil, errors.NewInternalError("Failed to build feed"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/new-movies.atom", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
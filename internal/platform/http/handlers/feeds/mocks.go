@@ -0,0 +1,29 @@
+package feeds
+
+import (
+	"context"
+	feedService "thermondo/internal/platform/service/feeds"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFeedService is a mock implementation of the feed service
+type MockFeedService struct {
+	mock.Mock
+}
+
+func (m *MockFeedService) NewMovies(ctx context.Context) (*feedService.Feed, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*feedService.Feed), args.Error(1)
+}
+
+func (m *MockFeedService) MovieReviews(ctx context.Context, movieID string) (*feedService.Feed, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*feedService.Feed), args.Error(1)
+}
@@ -0,0 +1,32 @@
+package jwks
+
+import (
+	"encoding/json"
+	"net/http"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler publishes the public keys of the RS256 token signing keys, so
+// other services can verify the tokens the service issues. It is mounted
+// under /.well-known.
+type Handler struct {
+	keys *tokens.Keys
+}
+
+func NewHandler(keys *tokens.Keys) *Handler {
+	return &Handler{keys: keys}
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Get("/jwks.json", h.ServeJWKS)
+}
+
+// ServeJWKS handles GET /.well-known/jwks.json. Verifiers may cache the set
+// for a few minutes, so a new key is published a little before it signs.
+func (h *Handler) ServeJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
@@ -0,0 +1,50 @@
+package jwks
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeJWKS(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := tokens.NewRSAKey("2024-06", private)
+	require.NoError(t, err)
+	keys, err := tokens.NewKeys("secret", "2024-06", []*tokens.Key{key})
+	require.NoError(t, err)
+
+	router := chi.NewRouter()
+	NewHandler(keys).RegisterRoutes(router)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+	var set tokens.JWKSet
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "2024-06", set.Keys[0].KeyID)
+	assert.Equal(t, "RS256", set.Keys[0].Algorithm)
+}
+
+func TestServeJWKS_SecretOnly(t *testing.T) {
+	router := chi.NewRouter()
+	NewHandler(tokens.FromSecret("secret")).RegisterRoutes(router)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"keys":[]}`, w.Body.String())
+}
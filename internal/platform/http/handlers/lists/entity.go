@@ -0,0 +1,50 @@
+package lists
+
+// CreateListRequest is the body of POST /lists. The list belongs to the
+// caller.
+type CreateListRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"` // "public" or "private"; lists are private when omitted
+}
+
+// UpdateListRequest changes only the members that are set
+type UpdateListRequest struct {
+	Name                *string `json:"name,omitempty"`
+	Description         *string `json:"description,omitempty"`
+	Visibility          *string `json:"visibility,omitempty"`
+	RegenerateShareSlug bool    `json:"regenerate_share_slug,omitempty"`
+}
+
+type AddMovieRequest struct {
+	MovieID  string `json:"movie_id"`
+	Position *int   `json:"position,omitempty"` // 1-based; the movie is appended when omitted
+}
+
+type ListResponse struct {
+	ID          string              `json:"id"`
+	UserID      string              `json:"user_id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Visibility  string              `json:"visibility"`
+	MovieCount  int                 `json:"movie_count"`
+	ShareSlug   string              `json:"share_slug,omitempty"` // Only shown to the owner
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+	Movies      []ListMovieResponse `json:"movies,omitempty"`
+}
+
+type ListMovieResponse struct {
+	Position    int     `json:"position"`
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseYear int     `json:"release_year"`
+	Genre       string  `json:"genre"`
+	PosterURL   *string `json:"poster_url,omitempty"`
+	AddedAt     string  `json:"added_at"`
+}
+
+type ListsResponse struct {
+	Lists []ListResponse `json:"lists"`
+	Total int            `json:"total"`
+}
@@ -0,0 +1,20 @@
+package lists
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		CreateListRequest{},
+		UpdateListRequest{},
+		AddMovieRequest{},
+		ListResponse{},
+		ListsResponse{},
+		successResponse{},
+	))
+}
@@ -0,0 +1,267 @@
+package lists
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"thermondo/internal/domain/lists"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	listService "thermondo/internal/platform/service/lists"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	listService    listService.Service
+	responseWriter *response.Writer
+	auth           *middleware.AuthMiddleware
+	sessions       middleware.SessionValidator
+	logger         *slog.Logger
+}
+
+// Option configures optional behaviour of the lists handler
+type Option func(*Handler)
+
+// WithSessionValidator rejects tokens whose session has been revoked
+func WithSessionValidator(validator middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.sessions = validator
+	}
+}
+
+func NewHandler(listService listService.Service, logger *slog.Logger, keys *tokens.Keys, opts ...Option) *Handler {
+	responseWriter := response.NewWriter(logger)
+	h := &Handler{
+		listService:    listService,
+		responseWriter: responseWriter,
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	var authOptions []middleware.AuthOption
+	if h.sessions != nil {
+		authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+	}
+	h.auth = middleware.NewAuthMiddleware(keys, responseWriter, authOptions...)
+	return h
+}
+
+// RegisterRoutes authenticates the changes to lists. Reads are open to
+// everyone, and authenticated when a token is sent so that owners see
+// their private lists.
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Route("/lists", func(r chi.Router) {
+		r.With(h.auth.Authenticate).Post("/", h.CreateList)
+		r.With(h.auth.AuthenticateOptional).Get("/{id}", h.GetList)
+		r.With(h.auth.Authenticate).Patch("/{id}", h.UpdateList)
+		r.With(h.auth.Authenticate).Delete("/{id}", h.DeleteList)
+		r.With(h.auth.Authenticate).Post("/{id}/movies", h.AddMovie)
+		r.With(h.auth.Authenticate).Delete("/{id}/movies/{movieId}", h.RemoveMovie)
+	})
+
+	router.With(h.auth.AuthenticateOptional).Get("/user/{userId}/lists", h.GetUserLists)
+}
+
+func (h *Handler) CreateList(w http.ResponseWriter, r *http.Request) {
+	var req CreateListRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	ownerID := requestingUserID(r)
+
+	list, err := h.listService.CreateList(r.Context(), req.toService(ownerID))
+	if err != nil {
+		h.logger.Error("[create_list_handler] Failed to create list", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, listToResponse(list, ownerID), http.StatusCreated)
+}
+
+// GetList handles GET /lists/{id}, where id is either the list ID or its
+// share slug
+func (h *Handler) GetList(w http.ResponseWriter, r *http.Request) {
+	requesterID := requestingUserID(r)
+	details, err := h.listService.GetList(r.Context(), chi.URLParam(r, "id"), requesterID)
+	if err != nil {
+		h.logger.Error("[get_list_handler] Failed to get list", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, detailsToResponse(details, requesterID), http.StatusOK)
+}
+
+// GetUserLists handles GET /user/{userId}/lists. Private lists are only
+// included for the owner.
+func (h *Handler) GetUserLists(w http.ResponseWriter, r *http.Request) {
+	requesterID := requestingUserID(r)
+	result, err := h.listService.GetUserLists(r.Context(), chi.URLParam(r, "userId"), requesterID)
+	if err != nil {
+		h.logger.Error("[get_user_lists_handler] Failed to get user lists", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := ListsResponse{Lists: make([]ListResponse, len(result)), Total: len(result)}
+	for i, list := range result {
+		resp.Lists[i] = listToResponse(list, requesterID)
+	}
+
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
+
+func (h *Handler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	var req UpdateListRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	requesterID := requestingUserID(r)
+	list, err := h.listService.UpdateList(r.Context(), chi.URLParam(r, "id"), requesterID, req.toService())
+	if err != nil {
+		h.logger.Error("[update_list_handler] Failed to update list", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, listToResponse(list, requesterID), http.StatusOK)
+}
+
+func (h *Handler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	err := h.listService.DeleteList(r.Context(), chi.URLParam(r, "id"), requestingUserID(r))
+	if err != nil {
+		h.logger.Error("[delete_list_handler] Failed to delete list", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "List deleted successfully"}, http.StatusOK)
+}
+
+// AddMovie attaches a movie and responds with the updated list
+func (h *Handler) AddMovie(w http.ResponseWriter, r *http.Request) {
+	var req AddMovieRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	listID := chi.URLParam(r, "id")
+	requesterID := requestingUserID(r)
+	if err := h.listService.AddMovie(r.Context(), listID, requesterID, req.toService()); err != nil {
+		h.logger.Error("[add_list_movie_handler] Failed to add movie", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	details, err := h.listService.GetList(r.Context(), listID, requesterID)
+	if err != nil {
+		h.logger.Error("[add_list_movie_handler] Failed to get list", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, detailsToResponse(details, requesterID), http.StatusOK)
+}
+
+func (h *Handler) RemoveMovie(w http.ResponseWriter, r *http.Request) {
+	err := h.listService.RemoveMovie(r.Context(), chi.URLParam(r, "id"), requestingUserID(r), chi.URLParam(r, "movieId"))
+	if err != nil {
+		h.logger.Error("[remove_list_movie_handler] Failed to remove movie", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Movie removed from list"}, http.StatusOK)
+}
+
+type successResponse struct {
+	Message string `json:"message"`
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// requestingUserID is the authenticated caller, or empty for anonymous reads
+func requestingUserID(r *http.Request) string {
+	if principal, ok := middleware.PrincipalFrom(r.Context()); ok {
+		return principal.UserID
+	}
+	return ""
+}
+
+func (r CreateListRequest) toService(ownerID string) listService.CreateListRequest {
+	return listService.CreateListRequest{
+		UserID:      ownerID,
+		Name:        r.Name,
+		Description: r.Description,
+		Visibility:  r.Visibility,
+	}
+}
+
+func (r UpdateListRequest) toService() listService.UpdateListRequest {
+	return listService.UpdateListRequest{
+		Name:                r.Name,
+		Description:         r.Description,
+		Visibility:          r.Visibility,
+		RegenerateShareSlug: r.RegenerateShareSlug,
+	}
+}
+
+func (r AddMovieRequest) toService() listService.AddMovieRequest {
+	return listService.AddMovieRequest{MovieID: r.MovieID, Position: r.Position}
+}
+
+// listToResponse maps a list, revealing the share slug to its owner only
+func listToResponse(l *lists.List, requesterID string) ListResponse {
+	resp := ListResponse{
+		ID:          string(l.ID),
+		UserID:      string(l.UserID),
+		Name:        l.Name,
+		Description: l.Description,
+		Visibility:  string(l.Visibility),
+		MovieCount:  l.MovieCount,
+		CreatedAt:   l.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   l.UpdatedAt.Format(time.RFC3339),
+	}
+	if l.IsOwnedBy(requesterID) {
+		resp.ShareSlug = l.ShareSlug
+	}
+	return resp
+}
+
+func detailsToResponse(details *listService.ListDetails, requesterID string) ListResponse {
+	resp := listToResponse(details.List, requesterID)
+	resp.MovieCount = len(details.Entries)
+	resp.Movies = make([]ListMovieResponse, len(details.Entries))
+	for i, e := range details.Entries {
+		resp.Movies[i] = ListMovieResponse{
+			Position:    e.Position,
+			ID:          string(e.Movie.ID),
+			Title:       e.Movie.Title,
+			ReleaseYear: e.Movie.ReleaseYear,
+			Genre:       e.Movie.Genre,
+			PosterURL:   e.Movie.PosterURL,
+			AddedAt:     e.AddedAt.Format(time.RFC3339),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,201 @@
+package lists
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	listService "thermondo/internal/platform/service/lists"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const listTestSecret = "list-test-secret"
+
+func setupRouter(service *MockListService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(listTestSecret)).RegisterRoutes(router)
+	return router
+}
+
+// authRequest is a request carrying the bearer token of callerID
+func authRequest(t *testing.T, method, target, callerID string, body io.Reader) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": callerID,
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(listTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func createRequestBody(v interface{}) io.Reader {
+	jsonData, _ := json.Marshal(v)
+	return bytes.NewReader(jsonData)
+}
+
+func createTestList() *lists.List {
+	return &lists.List{
+		ID: "list-1", UserID: "owner", Name: "Best 90s thrillers", Visibility: lists.VisibilityPrivate,
+		ShareSlug: "abcdefghijklmnop", MovieCount: 1, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+}
+
+func createTestDetails() *listService.ListDetails {
+	return &listService.ListDetails{
+		List: createTestList(),
+		Entries: []*lists.Entry{
+			{Movie: &movies.Movie{ID: "movie-1", Title: "Se7en", ReleaseYear: 1995}, Position: 1, AddedAt: time.Now()},
+		},
+	}
+}
+
+func TestGetList(t *testing.T) {
+	t.Run("share slug shows the movies but not the slug", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("GetList", mock.Anything, "abcdefghijklmnop", "").Return(createTestDetails(), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/lists/abcdefghijklmnop", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp ListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Movies, 1)
+		assert.Equal(t, "Se7en", resp.Movies[0].Title)
+		assert.Empty(t, resp.ShareSlug)
+	})
+
+	t.Run("owner sees the share slug", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("GetList", mock.Anything, "list-1", "owner").Return(createTestDetails(), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, authRequest(t, http.MethodGet, "/lists/list-1", "owner", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp ListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "abcdefghijklmnop", resp.ShareSlug)
+	})
+
+	t.Run("user_id query does not identify the caller", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("GetList", mock.Anything, "list-1", "").Return(nil, appErrors.NewNotFoundError("List not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/lists/list-1?user_id=owner", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("hidden list is 404", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("GetList", mock.Anything, "list-1", "").Return(nil, appErrors.NewNotFoundError("List not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/lists/list-1", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestCreateList(t *testing.T) {
+	t.Run("uses the authenticated user as owner", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("CreateList", mock.Anything, listService.CreateListRequest{UserID: "owner", Name: "Watch later"}).Return(createTestList(), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, authRequest(t, http.MethodPost, "/lists", "owner", createRequestBody(CreateListRequest{Name: "Watch later"})))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("owner cannot be set in the body", func(t *testing.T) {
+		service := new(MockListService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, authRequest(t, http.MethodPost, "/lists", "owner", createRequestBody(map[string]string{"user_id": "someone-else", "name": "Watch later"})))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "CreateList", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		service := new(MockListService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/lists", createRequestBody(CreateListRequest{Name: "Watch later"})))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		service.AssertNotCalled(t, "CreateList", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateList(t *testing.T) {
+	t.Run("forbidden for other users", func(t *testing.T) {
+		service := new(MockListService)
+		service.On("UpdateList", mock.Anything, "list-1", "someone-else", mock.Anything).Return(nil, appErrors.NewForbiddenError("Only the owner can modify this list"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, authRequest(t, http.MethodPatch, "/lists/list-1", "someone-else", createRequestBody(map[string]string{"name": "Mine"})))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		service := new(MockListService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodPatch, "/lists/list-1?user_id=owner", createRequestBody(map[string]string{"name": "Mine"})))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		service.AssertNotCalled(t, "UpdateList", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetUserLists(t *testing.T) {
+	service := new(MockListService)
+	service.On("GetUserLists", mock.Anything, "owner", "").Return([]*lists.List{createTestList()}, nil)
+
+	rr := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/user/owner/lists", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp ListsResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Total)
+	assert.Equal(t, 1, resp.Lists[0].MovieCount)
+}
+
+func TestAddMovie(t *testing.T) {
+	service := new(MockListService)
+	req := AddMovieRequest{MovieID: "movie-1"}
+	service.On("AddMovie", mock.Anything, "list-1", "owner", listService.AddMovieRequest{MovieID: "movie-1"}).Return(nil)
+	service.On("GetList", mock.Anything, "list-1", "owner").Return(createTestDetails(), nil)
+
+	rr := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(rr, authRequest(t, http.MethodPost, "/lists/list-1/movies", "owner", createRequestBody(req)))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	service.AssertExpectations(t)
+}
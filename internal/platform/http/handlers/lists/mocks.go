@@ -0,0 +1,62 @@
+package lists
+
+import (
+	"context"
+	"thermondo/internal/domain/lists"
+
+	listService "thermondo/internal/platform/service/lists"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockListService is a mock implementation of the lists.Service interface
+type MockListService struct {
+	mock.Mock
+}
+
+func (m *MockListService) CreateList(ctx context.Context, req listService.CreateListRequest) (*lists.List, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *MockListService) GetList(ctx context.Context, idOrSlug, requesterID string) (*listService.ListDetails, error) {
+	args := m.Called(ctx, idOrSlug, requesterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*listService.ListDetails), args.Error(1)
+}
+
+func (m *MockListService) GetUserLists(ctx context.Context, userID, requesterID string) ([]*lists.List, error) {
+	args := m.Called(ctx, userID, requesterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*lists.List), args.Error(1)
+}
+
+func (m *MockListService) UpdateList(ctx context.Context, id, requesterID string, req listService.UpdateListRequest) (*lists.List, error) {
+	args := m.Called(ctx, id, requesterID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *MockListService) DeleteList(ctx context.Context, id, requesterID string) error {
+	args := m.Called(ctx, id, requesterID)
+	return args.Error(0)
+}
+
+func (m *MockListService) AddMovie(ctx context.Context, id, requesterID string, req listService.AddMovieRequest) error {
+	args := m.Called(ctx, id, requesterID, req)
+	return args.Error(0)
+}
+
+func (m *MockListService) RemoveMovie(ctx context.Context, id, requesterID, movieID string) error {
+	args := m.Called(ctx, id, requesterID, movieID)
+	return args.Error(0)
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler serves objects kept by the local storage backend. S3-compatible
+// backends hand out their own URLs and do not need it.
+type Handler struct {
+	store          storage.Storage
+	signer         *storage.URLSigner
+	responseWriter *response.Writer
+	logger         *slog.Logger
+}
+
+// NewHandler serves objects from store. With a signer, every request must
+// carry a valid, unexpired signature from storage.URLSigner.Sign.
+func NewHandler(store storage.Storage, signer *storage.URLSigner, logger *slog.Logger) *Handler {
+	return &Handler{
+		store:          store,
+		signer:         signer,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Get("/media/*", h.ServeMedia)
+}
+
+// ServeMedia handles GET /media/{key}
+func (h *Handler) ServeMedia(w http.ResponseWriter, r *http.Request) {
+	key, err := storage.CleanKey(chi.URLParam(r, "*"))
+	if err != nil || strings.HasPrefix(key, storage.PrivatePrefix) {
+		h.responseWriter.WriteError(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if h.signer != nil {
+		if err := h.signer.Verify(key, r.URL.Query()); err != nil {
+			h.responseWriter.WriteError(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+
+	body, object, err := h.store.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.responseWriter.WriteError(w, "Not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("[serve_media_handler] Failed to read object", "error", err, "key", key)
+		h.responseWriter.WriteError(w, "Failed to read media", http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	// Object keys are never reused, so their content is immutable; signed
+	// URLs stay out of shared caches
+	if h.signer != nil {
+		w.Header().Set("Cache-Control", "private, max-age=300")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, key, object.ModTime, seeker)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	if _, err := io.Copy(w, body); err != nil {
+		h.logger.Error("[serve_media_handler] Failed to write object", "error", err, "key", key)
+	}
+}
@@ -0,0 +1,73 @@
+package media
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMedia(t *testing.T, signer *storage.URLSigner) (storage.Storage, chi.Router) {
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/media", Signer: signer})
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "posters/1/large.jpg", []byte("jpeg bytes"), "image/jpeg"))
+
+	router := chi.NewRouter()
+	NewHandler(store, signer, slog.New(slog.NewTextHandler(io.Discard, nil))).RegisterRoutes(router)
+	return store, router
+}
+
+func TestServeMedia(t *testing.T) {
+	_, router := setupMedia(t, nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/media/posters/1/large.jpg", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "jpeg bytes", rr.Body.String())
+	assert.Equal(t, "image/jpeg", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Get("Cache-Control"), "immutable")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/media/posters/2/large.jpg", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/media/posters/../../etc/passwd", nil))
+	assert.NotEqual(t, http.StatusOK, rr.Code)
+}
+
+func TestServeMediaHidesPrivateObjects(t *testing.T) {
+	store, router := setupMedia(t, nil)
+	require.NoError(t, store.Put(context.Background(), storage.PrivatePrefix+"imports/ratings/1.ndjson", []byte("{}"), "application/x-ndjson"))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/media/"+storage.PrivatePrefix+"imports/ratings/1.ndjson", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestServeMediaSigned(t *testing.T) {
+	store, router := setupMedia(t, storage.NewURLSigner("secret"))
+
+	url, err := store.URL(context.Background(), "posters/1/large.jpg", time.Minute)
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "jpeg bytes", rr.Body.String())
+	assert.Contains(t, rr.Header().Get("Cache-Control"), "private")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/media/posters/1/large.jpg", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
@@ -0,0 +1,76 @@
+package movies
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"thermondo/internal/domain/movies"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetDirectorStats handles GET /stats/directors/{name}, the numbers behind
+// a director spotlight page
+func (h *Handler) GetDirectorStats(w http.ResponseWriter, r *http.Request) {
+	h.getAggregateStats(w, r, movies.AggregateDirector, "name")
+}
+
+// GetGenreStats handles GET /stats/genres/{genre}
+func (h *Handler) GetGenreStats(w http.ResponseWriter, r *http.Request) {
+	h.getAggregateStats(w, r, movies.AggregateGenre, "genre")
+}
+
+func (h *Handler) getAggregateStats(w http.ResponseWriter, r *http.Request, kind movies.AggregateKind, param string) {
+	// chi routes on the escaped path when it differs from the decoded one,
+	// e.g. for names with slashes, and leaves the parameter escaped
+	name := chi.URLParam(r, param)
+	if r.URL.RawPath != "" {
+		unescaped, err := url.PathUnescape(name)
+		if err != nil {
+			h.responseWriter.WriteError(w, "Invalid "+string(kind)+" name", http.StatusBadRequest)
+			return
+		}
+		name = unescaped
+	}
+
+	stats, err := h.movieService.GetAggregateStats(r.Context(), kind, name)
+	if err != nil {
+		h.logger.Error("[aggregate_stats_handler] Failed to get stats", "error", err, "kind", kind)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, aggregateStatsToResponse(stats), http.StatusOK)
+}
+
+func aggregateStatsToResponse(stats *movies.AggregateStats) AggregateStatsResponse {
+	distribution := make(map[string]int64, 5)
+	for score := 1; score <= 5; score++ {
+		distribution[strconv.Itoa(score)] = stats.ScoreDistribution[score]
+	}
+	return AggregateStatsResponse{
+		Kind:              string(stats.Kind),
+		Name:              stats.Name,
+		MovieCount:        stats.MovieCount,
+		RatedMovies:       stats.RatedMovies,
+		TotalRatings:      stats.TotalRatings,
+		AverageBayesian:   stats.AverageBayesian,
+		BestRated:         ratedTitleToResponse(stats.BestRated),
+		WorstRated:        ratedTitleToResponse(stats.WorstRated),
+		ScoreDistribution: distribution,
+	}
+}
+
+func ratedTitleToResponse(title *movies.RatedTitle) *RatedTitleResponse {
+	if title == nil {
+		return nil
+	}
+	return &RatedTitleResponse{
+		MovieID:          string(title.MovieID),
+		Title:            title.Title,
+		ReleaseYear:      title.ReleaseYear,
+		BayesianAverage:  title.BayesianAverage,
+		WilsonLowerBound: title.WilsonLowerBound,
+		TotalRatings:     title.TotalRatings,
+	}
+}
@@ -0,0 +1,69 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/http/request"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) ListCertifications(w http.ResponseWriter, r *http.Request) {
+	certifications, err := h.movieService.ListCertifications(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.Error("[list_certifications_handler] Failed to list certifications", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &CertificationsListResponse{Certifications: make([]CertificationResponse, len(certifications))}
+	for i, certification := range certifications {
+		response.Certifications[i] = certificationToResponse(certification)
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// PutCertification handles PUT /movies/{id}/certifications/{territory},
+// creating or replacing the movie's age rating in a territory
+func (h *Handler) PutCertification(w http.ResponseWriter, r *http.Request) {
+	var req CertificationRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	certification, err := h.movieService.PutCertification(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "territory"), movies.CertificationRequest{Certification: req.Certification})
+	if err != nil {
+		h.logger.Error("[put_certification_handler] Failed to save certification", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, certificationToResponse(certification), http.StatusOK)
+}
+
+func (h *Handler) DeleteCertification(w http.ResponseWriter, r *http.Request) {
+	if err := h.movieService.DeleteCertification(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "territory")); err != nil {
+		h.logger.Error("[delete_certification_handler] Failed to delete certification", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Certification deleted successfully"}, http.StatusOK)
+}
+
+func certificationToResponse(certification *movies.Certification) CertificationResponse {
+	return CertificationResponse{
+		MovieID:       string(certification.MovieID),
+		Territory:     certification.Territory,
+		System:        string(certification.System),
+		Certification: certification.Certification,
+		UpdatedAt:     certification.UpdatedAt.Format(time.RFC3339),
+	}
+}
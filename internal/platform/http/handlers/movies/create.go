@@ -1,22 +1,37 @@
 package movies
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"thermondo/internal/domain/movies"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	movieService "thermondo/internal/platform/service/movies"
 	"time"
 )
 
 func (h *Handler) CreateMovie(w http.ResponseWriter, r *http.Request) {
-	var req movies.CreateMovieRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.responseWriter.WriteError(w, "Invalid JSON", http.StatusBadRequest)
+	var req CreateMovieRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
+	// The override may also be given as a query parameter so a client can
+	// resubmit the same body after reviewing the candidates
+	if allow, _ := strconv.ParseBool(r.URL.Query().Get("allow_duplicate")); allow {
+		req.AllowDuplicate = true
+	}
 
-	movie, err := h.movieService.CreateMovie(r.Context(), req)
+	movie, err := h.movieService.CreateMovie(r.Context(), req.toDomain())
 	if err != nil {
 		h.logger.Error("[create_movie_handler] Failed to create movie", "error", err)
+		var dupErr *movieService.DuplicateMovieError
+		if errors.As(err, &dupErr) {
+			h.writeDuplicateProblem(w, r, dupErr)
+			return
+		}
 		h.handleServiceError(w, err)
 		return
 	}
@@ -32,8 +47,8 @@ func (h *Handler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 		Rating:       string(movie.Rating),
 		Language:     movie.Language,
 		Country:      movie.Country,
-		Budget:       movie.Budget,
-		Revenue:      movie.Revenue,
+		Budget:       moneyToResponse(movie.BudgetMoney()),
+		Revenue:      moneyToResponse(movie.RevenueMoney()),
 		IMDbID:       movie.IMDbID,
 		PosterURL:    movie.PosterURL,
 		CreatedAt:    movie.CreatedAt.Format(time.RFC3339),
@@ -41,5 +56,44 @@ func (h *Handler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.responseWriter.WriteSuccess(w, response, http.StatusCreated)
+}
+
+// writeDuplicateProblem responds 409 with the matching movies under
+// extra.candidates
+func (h *Handler) writeDuplicateProblem(w http.ResponseWriter, r *http.Request, dupErr *movieService.DuplicateMovieError) {
+	candidates := make([]DuplicateCandidateResponse, len(dupErr.Candidates))
+	for i, c := range dupErr.Candidates {
+		candidates[i] = DuplicateCandidateResponse{
+			ID:          string(c.Movie.ID),
+			Title:       c.Movie.Title,
+			ReleaseYear: c.Movie.ReleaseYear,
+			Director:    c.Movie.Director,
+			IMDbID:      c.Movie.IMDbID,
+			Reason:      string(c.Reason),
+		}
+	}
 
+	problem := response.NewProblem(http.StatusConflict, appErrors.CodeConflict, dupErr.Message).
+		WithExtra("candidates", candidates)
+	h.responseWriter.WriteProblem(w, r, problem)
+}
+
+func (r CreateMovieRequest) toDomain() movies.CreateMovieRequest {
+	return movies.CreateMovieRequest{
+		Title:          r.Title,
+		Description:    r.Description,
+		ReleaseYear:    r.ReleaseYear,
+		Genre:          r.Genre,
+		Director:       r.Director,
+		DurationMins:   r.DurationMins,
+		Rating:         r.Rating,
+		Language:       r.Language,
+		Country:        r.Country,
+		Budget:         r.Budget,
+		Revenue:        r.Revenue,
+		Currency:       r.Currency,
+		IMDbID:         r.IMDbID,
+		PosterURL:      r.PosterURL,
+		AllowDuplicate: r.AllowDuplicate,
+	}
 }
@@ -0,0 +1,82 @@
+package movies
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"thermondo/internal/domain/money"
+)
+
+// CurrencyConverter converts budgets and revenues for the currency query
+// parameter, so amounts recorded in different currencies can be compared
+type CurrencyConverter interface {
+	Convert(ctx context.Context, m money.Money, to money.Currency) (money.Money, error)
+}
+
+// WithCurrencyConverter enables ?currency=XXX on movie reads
+func WithCurrencyConverter(converter CurrencyConverter) Option {
+	return func(h *Handler) {
+		h.currencyConverter = converter
+	}
+}
+
+// convertAmounts adds budget_converted and revenue_converted in the currency
+// requested by the currency query parameter. It reports whether the responses
+// can be written; when it returns false an error response was written.
+func (h *Handler) convertAmounts(w http.ResponseWriter, r *http.Request, responses ...*MovieResponse) bool {
+	code := strings.TrimSpace(r.URL.Query().Get("currency"))
+	if code == "" {
+		return true
+	}
+	target, err := money.ParseCurrency(code)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if h.currencyConverter == nil {
+		h.responseWriter.WriteError(w, "Currency conversion is not available", http.StatusBadRequest)
+		return false
+	}
+
+	for _, response := range responses {
+		if response.BudgetConverted, err = h.convert(r.Context(), response.Budget, target); err != nil {
+			break
+		}
+		if response.RevenueConverted, err = h.convert(r.Context(), response.Revenue, target); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		h.logger.Warn("[convert_amounts] Failed to convert amounts", "error", err, "currency", target)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) convert(ctx context.Context, amount *MoneyResponse, to money.Currency) (*MoneyResponse, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	converted, err := h.currencyConverter.Convert(ctx, money.Money{Amount: amount.Amount, Currency: money.Currency(amount.Currency)}, to)
+	if err != nil {
+		return nil, err
+	}
+	return moneyToResponse(&converted), nil
+}
+
+func moneyToResponse(amount *money.Money) *MoneyResponse {
+	if amount == nil {
+		return nil
+	}
+	return &MoneyResponse{Amount: amount.Amount, Currency: string(amount.Currency)}
+}
+
+// responsePointers lets convertAmounts update the movies of a list in place
+func responsePointers(responses []MovieResponse) []*MovieResponse {
+	pointers := make([]*MovieResponse, len(responses))
+	for i := range responses {
+		pointers[i] = &responses[i]
+	}
+	return pointers
+}
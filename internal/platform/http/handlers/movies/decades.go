@@ -0,0 +1,79 @@
+package movies
+
+import (
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/movies"
+	movieService "thermondo/internal/platform/service/movies"
+)
+
+// ListDecades handles GET /movies/decades?top=5, the movie count and the top
+// rated movies of every decade, oldest first
+func (h *Handler) ListDecades(w http.ResponseWriter, r *http.Request) {
+	top := movieService.DefaultDecadeTop
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		var err error
+		top, err = strconv.Atoi(topStr)
+		if err != nil || top < 0 || top > movieService.MaxDecadeTop {
+			h.responseWriter.WriteError(w, "top must be between 0 and 10", http.StatusBadRequest)
+			return
+		}
+	}
+
+	decades, err := h.movieService.GetDecades(r.Context(), top)
+	if err != nil {
+		h.logger.Error("[list_decades_handler] Failed to list decades", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &DecadesResponse{Decades: make([]DecadeResponse, len(decades))}
+	for i, decade := range decades {
+		first, last := movies.DecadeYears(decade.Decade)
+		topRated := make([]RatedTitleResponse, len(decade.TopRated))
+		for j, title := range decade.TopRated {
+			topRated[j] = *ratedTitleToResponse(title)
+		}
+		response.Decades[i] = DecadeResponse{
+			Decade:     movies.DecadeLabel(decade.Decade),
+			FirstYear:  first,
+			LastYear:   last,
+			MovieCount: decade.MovieCount,
+			TopRated:   topRated,
+		}
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// getMoviesOfDecade serves GET /movies?decade=1990s as a search over the
+// decade's years, in the usual movie list shape
+func (h *Handler) getMoviesOfDecade(w http.ResponseWriter, r *http.Request) {
+	params, err := h.parseSearchParams(r)
+	if err != nil {
+		h.logger.Error("[get_all_movies_handler] Failed to parse decade params", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	moviesList, total, err := h.movieService.SearchMovies(r.Context(), *params)
+	if err != nil {
+		h.logger.Error("[get_all_movies_handler] Failed to get movies of decade", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.localize(w, r, moviesList...)
+
+	response := &MoviesListResponse{
+		Movies:  h.moviesToResponse(moviesList),
+		Total:   total,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		HasMore: params.Offset+params.Limit < int(total),
+	}
+	if !h.convertAmounts(w, r, responsePointers(response.Movies)...) {
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
@@ -1,41 +1,127 @@
 package movies
 
+import "thermondo/internal/pkg/mergepatch"
+
+// MoneyResponse is an amount in the minor unit of its currency, so a budget
+// of $1,500.00 is {150000, USD}
+type MoneyResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// CreateMovieRequest is the body of POST /movies. Budget and Revenue are in
+// the minor unit of Currency, USD by default.
+type CreateMovieRequest struct {
+	Title          string  `json:"title"`
+	Description    string  `json:"description"`
+	ReleaseYear    int     `json:"release_year"`
+	Genre          string  `json:"genre"`
+	Director       string  `json:"director"`
+	DurationMins   int     `json:"duration_mins"`
+	Rating         *string `json:"rating,omitempty"`
+	Language       string  `json:"language"`
+	Country        string  `json:"country"`
+	Budget         *int64  `json:"budget,omitempty"`
+	Revenue        *int64  `json:"revenue,omitempty"`
+	Currency       string  `json:"currency,omitempty"`
+	IMDbID         *string `json:"imdb_id,omitempty"`
+	PosterURL      *string `json:"poster_url,omitempty"`
+	AllowDuplicate bool    `json:"allow_duplicate,omitempty"` // Skips the potential duplicate check
+}
+
+// PatchMovieRequest is a JSON Merge Patch of a movie
+type PatchMovieRequest struct {
+	Title        mergepatch.Field[string] `json:"title"`
+	Description  mergepatch.Field[string] `json:"description"`
+	ReleaseYear  mergepatch.Field[int]    `json:"release_year"`
+	Genre        mergepatch.Field[string] `json:"genre"`
+	Director     mergepatch.Field[string] `json:"director"`
+	DurationMins mergepatch.Field[int]    `json:"duration_mins"`
+	Rating       mergepatch.Field[string] `json:"rating"`
+	Language     mergepatch.Field[string] `json:"language"`
+	Country      mergepatch.Field[string] `json:"country"`
+	Budget       mergepatch.Field[int64]  `json:"budget"`
+	Revenue      mergepatch.Field[int64]  `json:"revenue"`
+	Currency     mergepatch.Field[string] `json:"currency"` // Relabels budget and revenue, without converting them
+	IMDbID       mergepatch.Field[string] `json:"imdb_id"`
+	PosterURL    mergepatch.Field[string] `json:"poster_url"`
+}
+
+type CertificationRequest struct {
+	Certification string `json:"certification"`
+}
+
+type ReleaseRequest struct {
+	ReleaseDate string `json:"release_date"` // YYYY-MM-DD
+}
+
+type ProviderRequest struct {
+	Name string `json:"name"`
+}
+
+// OfferRequest links to the movie on the provider; leave url out when the
+// link is not known
+type OfferRequest struct {
+	URL *string `json:"url,omitempty"`
+}
+
+type TranslationRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
 type CreateMovieResponse struct {
-	ID           string  `json:"id"`
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	ReleaseYear  int     `json:"release_year"`
-	Genre        string  `json:"genre"`
-	Director     string  `json:"director"`
-	DurationMins int     `json:"duration_mins"`
-	Rating       string  `json:"rating"`
-	Language     string  `json:"language"`
-	Country      string  `json:"country"`
-	Budget       *int64  `json:"budget,omitempty"`
-	Revenue      *int64  `json:"revenue,omitempty"`
-	IMDbID       *string `json:"imdb_id,omitempty"`
-	PosterURL    *string `json:"poster_url,omitempty"`
-	CreatedAt    string  `json:"created_at"`
-	UpdatedAt    string  `json:"updated_at"`
+	ID           string         `json:"id"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	ReleaseYear  int            `json:"release_year"`
+	Genre        string         `json:"genre"`
+	Director     string         `json:"director"`
+	DurationMins int            `json:"duration_mins"`
+	Rating       string         `json:"rating"`
+	Language     string         `json:"language"`
+	Country      string         `json:"country"`
+	Budget       *MoneyResponse `json:"budget,omitempty"`
+	Revenue      *MoneyResponse `json:"revenue,omitempty"`
+	IMDbID       *string        `json:"imdb_id,omitempty"`
+	PosterURL    *string        `json:"poster_url,omitempty"`
+	CreatedAt    string         `json:"created_at"`
+	UpdatedAt    string         `json:"updated_at"`
+}
+
+// DuplicateCandidateResponse is an existing movie returned when a create is
+// rejected as a potential duplicate
+type DuplicateCandidateResponse struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseYear int     `json:"release_year"`
+	Director    string  `json:"director"`
+	IMDbID      *string `json:"imdb_id,omitempty"`
+	Reason      string  `json:"reason"` // "imdb_id" or "title_year"
 }
 
 type MovieResponse struct {
-	ID           string  `json:"id"`
-	Title        string  `json:"title"`
-	Description  string  `json:"description"`
-	ReleaseYear  int     `json:"release_year"`
-	Genre        string  `json:"genre"`
-	Director     string  `json:"director"`
-	DurationMins int     `json:"duration_mins"`
-	Rating       string  `json:"rating"`
-	Language     string  `json:"language"`
-	Country      string  `json:"country"`
-	Budget       *int64  `json:"budget,omitempty"`
-	Revenue      *int64  `json:"revenue,omitempty"`
-	IMDbID       *string `json:"imdb_id,omitempty"`
-	PosterURL    *string `json:"poster_url,omitempty"`
-	CreatedAt    string  `json:"created_at"`
-	UpdatedAt    string  `json:"updated_at"`
+	ID           string         `json:"id"`
+	Slug         string         `json:"slug,omitempty"` // Set by the single movie lookups, create and update
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	ReleaseYear  int            `json:"release_year"`
+	Genre        string         `json:"genre"`
+	Director     string         `json:"director"`
+	DurationMins int            `json:"duration_mins"`
+	Rating       string         `json:"rating"`
+	Language     string         `json:"language"`
+	Country      string         `json:"country"`
+	Budget       *MoneyResponse `json:"budget,omitempty"`
+	Revenue      *MoneyResponse `json:"revenue,omitempty"`
+	// Set when converted with the currency query parameter
+	BudgetConverted  *MoneyResponse `json:"budget_converted,omitempty"`
+	RevenueConverted *MoneyResponse `json:"revenue_converted,omitempty"`
+	IMDbID           *string        `json:"imdb_id,omitempty"`
+	PosterURL        *string        `json:"poster_url,omitempty"`
+	Locale           string         `json:"locale,omitempty"` // Set when localized via Accept-Language
+	CreatedAt        string         `json:"created_at"`
+	UpdatedAt        string         `json:"updated_at"`
 }
 
 type MoviesListResponse struct {
@@ -46,6 +132,12 @@ type MoviesListResponse struct {
 	HasMore bool            `json:"has_more"`
 }
 
+// RandomMoviesResponse holds the movies picked by GET /movies/random, in
+// no particular order
+type RandomMoviesResponse struct {
+	Movies []MovieResponse `json:"movies"`
+}
+
 type SearchMoviesResponse struct {
 	Movies  []MovieResponse `json:"movies"`
 	Total   int64           `json:"total"`
@@ -53,4 +145,189 @@ type SearchMoviesResponse struct {
 	Offset  int             `json:"offset"`
 	HasMore bool            `json:"has_more"`
 	Query   string          `json:"query,omitempty"`
+	// Facets is only present when requested with facets=true
+	Facets *SearchFacetsResponse `json:"facets,omitempty"`
+}
+
+type FacetBucketResponse struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type SearchFacetsResponse struct {
+	Genres    []FacetBucketResponse `json:"genres"`
+	Decades   []FacetBucketResponse `json:"decades"`
+	Languages []FacetBucketResponse `json:"languages"`
+	Ratings   []FacetBucketResponse `json:"ratings"`
+}
+
+// MovieDetailsResponse is a movie with the related data requested via ?include=
+type MovieDetailsResponse struct {
+	MovieResponse
+	Stats      *MovieStatsResponse      `json:"stats,omitempty"`
+	UserRating *MovieUserRatingResponse `json:"user_rating,omitempty"`
+	// Providers is where to watch the movie, requested with
+	// include=providers and left out when it is offered nowhere
+	Providers []OfferResponse `json:"providers,omitempty"`
+}
+
+type MovieStatsResponse struct {
+	AverageScore float64          `json:"average_score"`
+	TotalRatings int64            `json:"total_ratings"`
+	ScoreCount   map[string]int64 `json:"score_count"` // String keys for JSON
+	// Withheld is set when average_score is left out because the movie has
+	// too few ratings, with a Notice to show in its place
+	Withheld bool   `json:"withheld,omitempty"`
+	Notice   string `json:"notice,omitempty"`
+}
+
+// AggregateStatsResponse summarizes the movies of a director or genre.
+// best_rated and worst_rated are null when none of the movies is rated.
+type AggregateStatsResponse struct {
+	Kind              string              `json:"kind"`
+	Name              string              `json:"name"`
+	MovieCount        int64               `json:"movie_count"`
+	RatedMovies       int64               `json:"rated_movies"`
+	TotalRatings      int64               `json:"total_ratings"`
+	AverageBayesian   float64             `json:"average_bayesian_score"`
+	BestRated         *RatedTitleResponse `json:"best_rated"`
+	WorstRated        *RatedTitleResponse `json:"worst_rated"`
+	ScoreDistribution map[string]int64    `json:"score_distribution"` // String keys for JSON
+}
+
+type RatedTitleResponse struct {
+	MovieID         string  `json:"movie_id"`
+	Title           string  `json:"title"`
+	ReleaseYear     int     `json:"release_year"`
+	BayesianAverage float64 `json:"bayesian_average"`
+	// WilsonLowerBound is the other metric movies can be ranked by
+	WilsonLowerBound float64 `json:"wilson_lower_bound"`
+	TotalRatings     int64   `json:"total_ratings"`
+}
+
+type DecadesResponse struct {
+	Decades []DecadeResponse `json:"decades"`
+}
+
+// DecadeResponse is one decade, labelled like the decade filter, e.g. 1990s
+type DecadeResponse struct {
+	Decade     string               `json:"decade"`
+	FirstYear  int                  `json:"first_year"`
+	LastYear   int                  `json:"last_year"`
+	MovieCount int64                `json:"movie_count"`
+	TopRated   []RatedTitleResponse `json:"top_rated"`
+}
+
+type MovieUserRatingResponse struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Score     int    `json:"score"`
+	Review    string `json:"review"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type SuggestionResponse struct {
+	Kind    string `json:"kind"`
+	Text    string `json:"text"`
+	MovieID string `json:"movie_id,omitempty"`
+}
+
+type SuggestionsResponse struct {
+	Suggestions []SuggestionResponse `json:"suggestions"`
+}
+
+type TranslationResponse struct {
+	MovieID     string `json:"movie_id"`
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type TranslationsListResponse struct {
+	Translations []TranslationResponse `json:"translations"`
+}
+
+type ReleaseResponse struct {
+	MovieID     string `json:"movie_id"`
+	Region      string `json:"region"`
+	Type        string `json:"type"`
+	ReleaseDate string `json:"release_date"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type ReleasesListResponse struct {
+	Releases []ReleaseResponse `json:"releases"`
+}
+
+type UpcomingReleaseResponse struct {
+	Region      string        `json:"region"`
+	Type        string        `json:"type"`
+	ReleaseDate string        `json:"release_date"`
+	Movie       MovieResponse `json:"movie"`
+}
+
+type UpcomingReleasesResponse struct {
+	Releases []UpcomingReleaseResponse `json:"releases"`
+	Total    int64                     `json:"total"`
+	Limit    int                       `json:"limit"`
+	Offset   int                       `json:"offset"`
+	HasMore  bool                      `json:"has_more"`
+}
+
+type ProviderResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ProvidersListResponse struct {
+	Providers []ProviderResponse `json:"providers"`
+}
+
+// OfferResponse is where to watch a movie in a region: on which provider,
+// and whether by subscription, for free, to rent or to buy
+type OfferResponse struct {
+	Region       string  `json:"region"`
+	ProviderID   string  `json:"provider_id"`
+	ProviderName string  `json:"provider_name"`
+	Type         string  `json:"type"`
+	URL          *string `json:"url,omitempty"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+type OffersListResponse struct {
+	MovieID string          `json:"movie_id"`
+	Offers  []OfferResponse `json:"offers"`
+}
+
+type CertificationResponse struct {
+	MovieID       string `json:"movie_id"`
+	Territory     string `json:"territory"`
+	System        string `json:"system"`
+	Certification string `json:"certification"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type CertificationsListResponse struct {
+	Certifications []CertificationResponse `json:"certifications"`
+}
+
+type PosterVariantResponse struct {
+	Variant     string `json:"variant"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	SizeBytes   int64  `json:"size_bytes"`
+	ContentType string `json:"content_type"`
+	URL         string `json:"url"`
+}
+
+type PosterResponse struct {
+	MovieID string `json:"movie_id"`
+	// PosterURL is the stable address stored on the movie; it redirects to
+	// the large variant
+	PosterURL string                  `json:"poster_url"`
+	Variants  []PosterVariantResponse `json:"variants"`
 }
@@ -0,0 +1,38 @@
+package movies
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		CreateMovieRequest{},
+		PatchMovieRequest{},
+		CertificationRequest{},
+		ReleaseRequest{},
+		ProviderRequest{},
+		OfferRequest{},
+		TranslationRequest{},
+		CreateMovieResponse{},
+		DuplicateCandidateResponse{},
+		MovieResponse{},
+		MoviesListResponse{},
+		MovieDetailsResponse{},
+		SearchMoviesResponse{},
+		SuggestionsResponse{},
+		RandomMoviesResponse{},
+		PosterResponse{},
+		CertificationsListResponse{},
+		ReleasesListResponse{},
+		ProvidersListResponse{},
+		OffersListResponse{},
+		UpcomingReleasesResponse{},
+		TranslationsListResponse{},
+		AggregateStatsResponse{},
+		DecadesResponse{},
+	))
+}
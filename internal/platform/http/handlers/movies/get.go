@@ -1,12 +1,24 @@
 package movies
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/i18n"
+	"thermondo/internal/platform/http/middleware"
+	movieService "thermondo/internal/platform/service/movies"
 
 	"github.com/go-chi/chi/v5"
 )
 
 func (h *Handler) GetAllMovies(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("decade") != "" {
+		h.getMoviesOfDecade(w, r)
+		return
+	}
+
 	params, err := h.parseListParams(r)
 	if err != nil {
 		h.logger.Error("[get_all_movies_handler] Failed to parse list params", "error", err)
@@ -27,6 +39,8 @@ func (h *Handler) GetAllMovies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.localize(w, r, moviesList...)
+
 	response := &MoviesListResponse{
 		Movies:  h.moviesToResponse(moviesList),
 		Total:   total,
@@ -34,6 +48,9 @@ func (h *Handler) GetAllMovies(w http.ResponseWriter, r *http.Request) {
 		Offset:  params.Offset,
 		HasMore: params.Offset+params.Limit < int(total),
 	}
+	if !h.convertAmounts(w, r, responsePointers(response.Movies)...) {
+		return
+	}
 
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
@@ -46,13 +63,142 @@ func (h *Handler) GetMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.getMovie(w, r, movieID)
+}
+
+// GetMovieBySlug handles GET /movies/slug/{slug}, answering like GET
+// /movies/{id}. An old slug of a movie is redirected with a 301 to its
+// current one.
+func (h *Handler) GetMovieBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := chi.URLParam(r, "slug")
+
+	movieID, current, err := h.movieService.ResolveMovieSlug(r.Context(), slug)
+	if err != nil {
+		h.logger.Error("[get_movie_by_slug_handler] Failed to resolve slug", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	if current != slug {
+		location := *r.URL
+		location.Path = strings.TrimSuffix(r.URL.Path, slug) + current
+		location.RawPath = ""
+		http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+		return
+	}
+
+	h.getMovie(w, r, movieID)
+}
+
+// getMovie serves the movie, with the data of ?include= when given
+func (h *Handler) getMovie(w http.ResponseWriter, r *http.Request, movieID string) {
+	if r.URL.Query().Get("include") != "" {
+		h.getMovieDetails(w, r, movieID)
+		return
+	}
+
 	movie, err := h.movieService.GetMovieByID(r.Context(), movieID)
 	if err != nil {
+		if h.redirectMerged(w, r, movieID, err) {
+			return
+		}
 		h.logger.Error("[get_movie_handler] Failed to get movie", "error", err)
 		h.handleServiceError(w, err)
 		return
 	}
 
+	h.localize(w, r, movie)
+
 	response := h.movieToResponse(movie)
+	if !h.convertAmounts(w, r, &response) {
+		return
+	}
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
+
+// getMovieDetails serves GET /movies/{id}?include=stats,user_rating,providers,
+// embedding the rating stats, the requesting user's rating and where to
+// watch the movie, in ?region= when given, in a single response
+func (h *Handler) getMovieDetails(w http.ResponseWriter, r *http.Request, movieID string) {
+	req, err := h.parseMovieDetailsParams(r, movieID)
+	if err != nil {
+		h.logger.Error("[get_movie_handler] Failed to parse include params", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := h.movieService.GetMovieDetails(r.Context(), *req)
+	if err != nil {
+		if h.redirectMerged(w, r, movieID, err) {
+			return
+		}
+		h.logger.Error("[get_movie_handler] Failed to get movie details", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.localize(w, r, details.Movie)
+
+	response := h.movieDetailsToResponse(details)
+	if response.Stats != nil && response.Stats.Withheld {
+		response.Stats.Notice = i18n.T(r.Context(), rating.NotEnoughRatings)
+	}
+	if !h.convertAmounts(w, r, &response.MovieResponse) {
+		return
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// redirectMerged answers a lookup of a merged movie ID with a 301 to the movie
+// it was merged into. It reports whether a redirect was written; err is the
+// lookup error and only not found errors are redirected.
+func (h *Handler) redirectMerged(w http.ResponseWriter, r *http.Request, movieID string, err error) bool {
+	var appErr *appErrors.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusNotFound {
+		return false
+	}
+
+	newID, err := h.movieService.ResolveMovieRedirect(r.Context(), movieID)
+	if err != nil {
+		return false
+	}
+
+	location := *r.URL
+	location.Path = strings.TrimSuffix(r.URL.Path, movieID) + newID
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+	return true
+}
+
+func (h *Handler) parseMovieDetailsParams(r *http.Request, movieID string) (*movieService.MovieDetailsRequest, error) {
+	req := &movieService.MovieDetailsRequest{MovieID: movieID}
+
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "stats":
+			req.IncludeStats = true
+		case "user_rating":
+			userID := requestingUserID(r)
+			if userID == "" {
+				return nil, errors.New("include=user_rating requires an authenticated user")
+			}
+			req.UserID = userID
+		case "providers":
+			req.IncludeProviders = true
+			req.Region = r.URL.Query().Get("region")
+		case "":
+		default:
+			return nil, errors.New("include must be a comma-separated list of 'stats', 'user_rating' and 'providers'")
+		}
+	}
+
+	return req, nil
+}
+
+// requestingUserID is the authenticated caller, empty for anonymous requests
+func requestingUserID(r *http.Request) string {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		return ""
+	}
+	return principal.UserID
+}
@@ -8,8 +8,12 @@ import (
 	"strconv"
 	"strings"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
 	appErrors "thermondo/internal/pkg/errors"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/sorting"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
 	movieService "thermondo/internal/platform/service/movies"
 	"time"
 
@@ -25,41 +29,134 @@ type Handler struct {
 	movieService   movieService.Service
 	logger         *slog.Logger
 	responseWriter *response.Writer
+	maxPosterBytes int64
+	auth           *middleware.AuthMiddleware
+	keys           *tokens.Keys
+	sessions       middleware.SessionValidator
+	// cached wraps the hot movie listing
+	cached []func(http.Handler) http.Handler
+
+	currencyConverter CurrencyConverter
+}
+
+// Option configures optional behaviour of the movie handler
+type Option func(*Handler)
+
+// WithAuthentication verifies bearer tokens on the routes that need a
+// caller, rejecting tokens whose session has been revoked
+func WithAuthentication(keys *tokens.Keys, sessions middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.keys = keys
+		h.sessions = sessions
+	}
 }
 
-func NewHandler(movieService movieService.Service, logger *slog.Logger) *Handler {
+// WithMaxPosterBytes sets the largest poster upload accepted
+func WithMaxPosterBytes(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxPosterBytes = n
+		}
+	}
+}
+
+// WithResponseCache serves GET /movies through the given response cache
+// middleware
+func WithResponseCache(middleware func(http.Handler) http.Handler) Option {
+	return func(h *Handler) {
+		h.cached = append(h.cached, middleware)
+	}
+}
+
+func NewHandler(movieService movieService.Service, logger *slog.Logger, opts ...Option) *Handler {
 	if logger == nil {
 		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelInfo,
 		}))
 	}
 
-	return &Handler{
+	handler := &Handler{
 		movieService:   movieService,
 		logger:         logger,
 		responseWriter: response.NewWriter(logger),
+		maxPosterBytes: DefaultMaxPosterBytes,
 	}
+
+	for _, opt := range opts {
+		opt(handler)
+	}
+
+	if handler.keys != nil {
+		var authOptions []middleware.AuthOption
+		if handler.sessions != nil {
+			authOptions = append(authOptions, middleware.WithSessionValidator(handler.sessions))
+		}
+		handler.auth = middleware.NewAuthMiddleware(handler.keys, handler.responseWriter, authOptions...)
+	}
+
+	return handler
 }
 
 func (h *Handler) RegisterRoutes(router chi.Router) {
+	// identified lets the routes that tailor their answer to the caller
+	// know who is asking, and serves anonymous requests all the same
+	var identified []func(http.Handler) http.Handler
+	if h.auth != nil {
+		identified = append(identified, h.auth.AuthenticateOptional)
+	}
+
 	router.Route("/movies", func(r chi.Router) {
 		r.Post("/", h.CreateMovie)
-		r.Get("/", h.GetAllMovies)
+		r.With(h.cached...).Get("/", h.GetAllMovies)
+		r.Get("/suggest", h.SuggestMovies)
+		r.With(identified...).Get("/random", h.RandomMovies)
+		r.Get("/upcoming", h.ListUpcoming)
+		r.Get("/decades", h.ListDecades)
+		r.Get("/slug/{slug}", h.GetMovieBySlug)
+		r.With(identified...).Get("/{id}", h.GetMovie)
+		r.Get("/{id}/translations", h.ListTranslations)
+		r.Get("/{id}/translations/{locale}", h.GetTranslation)
+		r.Get("/{id}/releases", h.ListReleases)
+		r.Get("/{id}/providers", h.ListOffers)
+		r.Get("/{id}/certifications", h.ListCertifications)
+		r.Get("/{id}/poster", h.ListPosters)
+		r.Get("/{id}/poster/{variant}", h.GetPoster)
 
 		// Weird Chi router bug, so removing this and replacing
 		// with the routes below
 		// r.Get("/search", h.SearchMovies)
 
-		// r.Route("/{id}", func(r chi.Router) {
-		// 	r.Get("/", h.GetMovie)
-		// })
+		// Writes to the catalogue are for admins; without authentication
+		// configured they are not routed
+		if h.auth != nil {
+			r.Group(func(r chi.Router) {
+				r.Use(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin))
+				r.Patch("/{id}", h.PatchMovie)
+				r.Post("/{id}/poster", h.UploadPoster)
+				r.Put("/{id}/translations/{locale}", h.PutTranslation)
+				r.Delete("/{id}/translations/{locale}", h.DeleteTranslation)
+				r.Put("/{id}/providers/{region}/{provider}/{type}", h.PutOffer)
+				r.Delete("/{id}/providers/{region}/{provider}/{type}", h.DeleteOffer)
+				r.Put("/{id}/releases/{region}/{type}", h.PutRelease)
+				r.Delete("/{id}/releases/{region}/{type}", h.DeleteRelease)
+				r.Put("/{id}/certifications/{territory}", h.PutCertification)
+				r.Delete("/{id}/certifications/{territory}", h.DeleteCertification)
+			})
+		}
 	})
 
+	router.Get("/providers", h.ListProviders)
+	if h.auth != nil {
+		router.With(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin)).Put("/providers/{provider}", h.PutProvider)
+	}
+	router.Get("/stats/directors/{name}", h.GetDirectorStats)
+	router.Get("/stats/genres/{genre}", h.GetGenreStats)
+
 	router.Route("/search/movies", func(r chi.Router) {
 		r.Get("/", h.SearchMovies)
 
 		r.Route("/{id}", func(r chi.Router) {
-			r.Get("/", h.GetMovie)
+			r.With(identified...).Get("/", h.GetMovie)
 		})
 	})
 }
@@ -68,7 +165,7 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 	var appErr *appErrors.AppError
 	if errors.As(err, &appErr) {
 
-		h.responseWriter.WriteError(w, appErr.Message, appErr.StatusCode)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
@@ -78,6 +175,7 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 type listParams struct {
 	Limit  int
 	Offset int
+	// SortBy is a sort such as "release_year:desc,title:asc"
 	SortBy string
 	Order  string
 }
@@ -108,14 +206,6 @@ func (h *Handler) parseListParams(r *http.Request) (*listParams, error) {
 		params.Offset = offset
 	}
 
-	if sortBy := r.URL.Query().Get("sort_by"); sortBy != "" {
-		if !h.isValidSortField(sortBy) {
-			h.logger.Error("[parse_list_params] Invalid sort_by field", "sort_by", sortBy)
-			return nil, errors.New("invalid sort_by field")
-		}
-		params.SortBy = sortBy
-	}
-
 	if order := r.URL.Query().Get("order"); order != "" {
 		order = strings.ToLower(order)
 		if order != "asc" && order != "desc" {
@@ -125,6 +215,15 @@ func (h *Handler) parseListParams(r *http.Request) (*listParams, error) {
 		params.Order = order
 	}
 
+	sort, err := sorting.FromQuery(r.URL.Query(), params.Order, movies.SortFields)
+	if err != nil {
+		h.logger.Error("[parse_list_params] Invalid sort", "error", err)
+		return nil, err
+	}
+	if sort != "" {
+		params.SortBy = sort
+	}
+
 	return params, nil
 }
 
@@ -163,6 +262,46 @@ func (h *Handler) parseSearchParams(r *http.Request) (*movies.SearchMoviesReques
 		searchParams.MaxYear = &maxYear
 	}
 
+	searchParams.Language = strings.TrimSpace(r.URL.Query().Get("language"))
+	searchParams.Country = strings.TrimSpace(r.URL.Query().Get("country"))
+	searchParams.Featuring = strings.TrimSpace(r.URL.Query().Get("featuring"))
+	searchParams.MaxCertification = strings.TrimSpace(r.URL.Query().Get("max_certification"))
+	searchParams.Territory = strings.TrimSpace(r.URL.Query().Get("territory"))
+	searchParams.Provider = strings.TrimSpace(r.URL.Query().Get("provider"))
+	searchParams.Region = strings.TrimSpace(r.URL.Query().Get("region"))
+
+	if minDurationStr := r.URL.Query().Get("min_duration"); minDurationStr != "" {
+		minDuration, err := strconv.Atoi(minDurationStr)
+		if err != nil || minDuration < 1 {
+			h.logger.Error("[parse_search_params] Invalid min_duration", "error", err)
+			return nil, errors.New("min_duration must be a positive number of minutes")
+		}
+		searchParams.MinDuration = &minDuration
+	}
+
+	if minRatingStr := r.URL.Query().Get("min_rating"); minRatingStr != "" {
+		minRating, err := strconv.ParseFloat(minRatingStr, 64)
+		if err != nil || minRating < 1 || minRating > 5 {
+			h.logger.Error("[parse_search_params] Invalid min_rating", "error", err)
+			return nil, errors.New("min_rating must be a number between 1 and 5")
+		}
+		searchParams.MinRating = &minRating
+	}
+
+	// decade is shorthand for the decade's year range
+	if decadeStr := r.URL.Query().Get("decade"); decadeStr != "" {
+		if searchParams.MinYear != nil || searchParams.MaxYear != nil {
+			return nil, errors.New("decade cannot be combined with min_year or max_year")
+		}
+		decade, err := movies.ParseDecade(decadeStr)
+		if err != nil {
+			h.logger.Error("[parse_search_params] Invalid decade", "error", err)
+			return nil, err
+		}
+		first, last := movies.DecadeYears(decade)
+		searchParams.MinYear, searchParams.MaxYear = &first, &last
+	}
+
 	if searchParams.MinYear != nil && searchParams.MaxYear != nil {
 		if *searchParams.MinYear > *searchParams.MaxYear {
 			h.logger.Error("[parse_search_params] Min year cannot be greater than max year")
@@ -173,18 +312,6 @@ func (h *Handler) parseSearchParams(r *http.Request) (*movies.SearchMoviesReques
 	return searchParams, nil
 }
 
-func (h *Handler) isValidSortField(field string) bool {
-	validFields := map[string]bool{
-		"title":        true,
-		"release_year": true,
-		"created_at":   true,
-		"updated_at":   true,
-		"genre":        true,
-		"director":     true,
-	}
-	return validFields[field]
-}
-
 // Response transformation methods
 func (h *Handler) moviesToResponse(moviesList []*movies.Movie) []MovieResponse {
 	responses := make([]MovieResponse, len(moviesList))
@@ -194,9 +321,46 @@ func (h *Handler) moviesToResponse(moviesList []*movies.Movie) []MovieResponse {
 	return responses
 }
 
+func (h *Handler) movieDetailsToResponse(details *movieService.MovieDetails) MovieDetailsResponse {
+	response := MovieDetailsResponse{
+		MovieResponse: h.movieToResponse(details.Movie),
+	}
+
+	if details.Stats != nil {
+		scoreCount := make(map[string]int64, len(details.Stats.ScoreCount))
+		for score, count := range details.Stats.ScoreCount {
+			scoreCount[strconv.Itoa(score)] = count
+		}
+		response.Stats = &MovieStatsResponse{
+			AverageScore: details.Stats.AverageScore,
+			TotalRatings: details.Stats.TotalRatings,
+			ScoreCount:   scoreCount,
+			Withheld:     details.Stats.Withheld,
+		}
+	}
+
+	for _, offer := range details.Offers {
+		response.Providers = append(response.Providers, offerToResponse(offer))
+	}
+
+	if details.UserRating != nil {
+		response.UserRating = &MovieUserRatingResponse{
+			ID:        string(details.UserRating.ID),
+			UserID:    string(details.UserRating.UserID),
+			Score:     details.UserRating.Score,
+			Review:    details.UserRating.Review,
+			CreatedAt: details.UserRating.CreatedAt.Format(time.RFC3339),
+			UpdatedAt: details.UserRating.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+
+	return response
+}
+
 func (h *Handler) movieToResponse(movie *movies.Movie) MovieResponse {
 	return MovieResponse{
 		ID:           string(movie.ID),
+		Slug:         movie.Slug,
 		Title:        movie.Title,
 		Description:  movie.Description,
 		ReleaseYear:  movie.ReleaseYear,
@@ -206,10 +370,11 @@ func (h *Handler) movieToResponse(movie *movies.Movie) MovieResponse {
 		Rating:       string(movie.Rating),
 		Language:     movie.Language,
 		Country:      movie.Country,
-		Budget:       movie.Budget,
-		Revenue:      movie.Revenue,
+		Budget:       moneyToResponse(movie.BudgetMoney()),
+		Revenue:      moneyToResponse(movie.RevenueMoney()),
 		IMDbID:       movie.IMDbID,
 		PosterURL:    movie.PosterURL,
+		Locale:       movie.Locale,
 		CreatedAt:    movie.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    movie.UpdatedAt.Format(time.RFC3339),
 	}
@@ -5,20 +5,99 @@ import (
 	"encoding/json"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"thermondo/internal/domain/money"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
 	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	movieService "thermondo/internal/platform/service/movies"
 )
 
+const movieTestSecret = "movie-test-secret"
+
+// authRequest is a request carrying the bearer token of callerID
+func authRequest(t *testing.T, method, target, callerID, body string) *http.Request {
+	t.Helper()
+	return tokenRequest(t, method, target, callerID, "user", strings.NewReader(body))
+}
+
+// adminRequest is a request carrying the bearer token of an admin
+func adminRequest(t *testing.T, method, target string, body io.Reader) *http.Request {
+	t.Helper()
+	return tokenRequest(t, method, target, "admin-1", "admin", body)
+}
+
+func tokenRequest(t *testing.T, method, target, callerID, role string, body io.Reader) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": callerID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(movieTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+// newAuthRouter routes the handler with bearer tokens verified, as the API
+// does
+func newAuthRouter(mockService *mockMovieService, opts ...Option) *chi.Mux {
+	router := chi.NewRouter()
+	opts = append(opts, WithAuthentication(tokens.FromSecret(movieTestSecret), nil))
+	NewHandler(mockService, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...).RegisterRoutes(router)
+	return router
+}
+
+// TestAdminWrites checks that the writes to the catalogue are refused to
+// anonymous callers and to users who are not admins
+func TestAdminWrites(t *testing.T) {
+	routes := []struct{ method, target string }{
+		{http.MethodPatch, "/movies/test-movie-123"},
+		{http.MethodPost, "/movies/test-movie-123/poster"},
+		{http.MethodPut, "/movies/test-movie-123/translations/fr"},
+		{http.MethodDelete, "/movies/test-movie-123/translations/fr"},
+		{http.MethodPut, "/movies/test-movie-123/providers/de/netflix/subscription"},
+		{http.MethodDelete, "/movies/test-movie-123/providers/de/netflix/subscription"},
+		{http.MethodPut, "/providers/netflix"},
+		{http.MethodPut, "/movies/test-movie-123/releases/de/theatrical"},
+		{http.MethodDelete, "/movies/test-movie-123/releases/de/theatrical"},
+		{http.MethodPut, "/movies/test-movie-123/certifications/de"},
+		{http.MethodDelete, "/movies/test-movie-123/certifications/de"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.target, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			router := newAuthRouter(mockService)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(route.method, route.target, strings.NewReader(`{}`)))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, authRequest(t, route.method, route.target, "user-1", `{}`))
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+
+			assert.Empty(t, mockService.Calls, "the service is not reached")
+		})
+	}
+}
+
 // Test helper to create a test movie
 func createTestMovie() *movies.Movie {
 	budget := int64(10000000000)  // $100M (in cents)
@@ -39,6 +118,7 @@ func createTestMovie() *movies.Movie {
 		Country:      "USA",
 		Budget:       &budget,
 		Revenue:      &revenue,
+		Currency:     "USD",
 		IMDbID:       &imdbID,
 		PosterURL:    &posterURL,
 		CreatedAt:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
@@ -105,9 +185,9 @@ func TestCreateMovieHandler(t *testing.T) {
 				assert.Equal(t, "English", response.Language)
 				assert.Equal(t, "USA", response.Country)
 				assert.NotNil(t, response.Budget)
-				assert.Equal(t, int64(10000000000), *response.Budget)
+				assert.Equal(t, MoneyResponse{Amount: 10000000000, Currency: "USD"}, *response.Budget)
 				assert.NotNil(t, response.Revenue)
-				assert.Equal(t, int64(25000000000), *response.Revenue)
+				assert.Equal(t, MoneyResponse{Amount: 25000000000, Currency: "USD"}, *response.Revenue)
 				assert.NotNil(t, response.IMDbID)
 				assert.Equal(t, "tt1234567", *response.IMDbID)
 				assert.NotNil(t, response.PosterURL)
@@ -325,6 +405,775 @@ func TestCreateMovieHandler(t *testing.T) {
 	}
 }
 
+func TestGetMovieHandler_Include(t *testing.T) {
+	movie := createTestMovie()
+
+	tests := []struct {
+		name           string
+		url            string
+		caller         string
+		setupMock      func(*mockMovieService)
+		expectedStatus int
+		expectedBody   func(*testing.T, string)
+	}{
+		{
+			name: "without include returns the plain movie",
+			url:  "/movies/test-movie-123",
+			setupMock: func(m *mockMovieService) {
+				m.On("GetMovieByID", mock.Anything, "test-movie-123").Return(movie, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, `"id":"test-movie-123"`)
+				assert.NotContains(t, body, `"stats"`)
+			},
+		},
+		{
+			name:   "embeds stats and the caller's rating",
+			url:    "/movies/test-movie-123?include=stats,user_rating",
+			caller: "user-1",
+			setupMock: func(m *mockMovieService) {
+				m.On("GetMovieDetails", mock.Anything, movieService.MovieDetailsRequest{
+					MovieID:      "test-movie-123",
+					IncludeStats: true,
+					UserID:       "user-1",
+				}).Return(&movieService.MovieDetails{
+					Movie: movie,
+					Stats: &rating.MovieRatingStats{
+						MovieID:      movie.ID,
+						AverageScore: 4.5,
+						TotalRatings: 2,
+						ScoreCount:   map[int]int64{4: 1, 5: 1},
+					},
+					UserRating: &rating.Rating{ID: "rating-1", UserID: "user-1", MovieID: movie.ID, Score: 5},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var resp MovieDetailsResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &resp))
+				assert.Equal(t, "test-movie-123", resp.ID)
+				require.NotNil(t, resp.Stats)
+				assert.Equal(t, 4.5, resp.Stats.AverageScore)
+				assert.Equal(t, map[string]int64{"4": 1, "5": 1}, resp.Stats.ScoreCount)
+				require.NotNil(t, resp.UserRating)
+				assert.Equal(t, 5, resp.UserRating.Score)
+			},
+		},
+		{
+			name: "embeds where to watch in the region",
+			url:  "/movies/test-movie-123?include=providers&region=DE",
+			setupMock: func(m *mockMovieService) {
+				m.On("GetMovieDetails", mock.Anything, movieService.MovieDetailsRequest{
+					MovieID:          "test-movie-123",
+					IncludeProviders: true,
+					Region:           "DE",
+				}).Return(&movieService.MovieDetails{
+					Movie:  movie,
+					Offers: []*movies.Offer{{MovieID: movie.ID, Region: "DE", ProviderID: "netflix", ProviderName: "Netflix", Type: movies.OfferSubscription}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var resp MovieDetailsResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &resp))
+				require.Len(t, resp.Providers, 1)
+				assert.Equal(t, "Netflix", resp.Providers[0].ProviderName)
+				assert.Equal(t, "subscription", resp.Providers[0].Type)
+			},
+		},
+		{
+			name:           "user_rating without a user is rejected",
+			url:            "/movies/test-movie-123?include=user_rating",
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "user_rating requires")
+			},
+		},
+		{
+			name:           "user_id does not stand in for the caller",
+			url:            "/movies/test-movie-123?include=user_rating&user_id=user-1",
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "user_rating requires")
+			},
+		},
+		{
+			name:           "unknown include is rejected",
+			url:            "/movies/test-movie-123?include=cast",
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "include must be")
+			},
+		},
+		{
+			name: "movie not found",
+			url:  "/movies/missing?include=stats",
+			setupMock: func(m *mockMovieService) {
+				m.On("GetMovieDetails", mock.Anything, movieService.MovieDetailsRequest{MovieID: "missing", IncludeStats: true}).
+					Return(nil, errors.NewNotFoundError("Movie not found"))
+				m.On("ResolveMovieRedirect", mock.Anything, "missing").Return("", errors.NewNotFoundError("Movie not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "Movie not found")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			tt.setupMock(mockService)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			router := chi.NewRouter()
+			NewHandler(mockService, logger, WithAuthentication(tokens.FromSecret(movieTestSecret), nil)).RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.caller != "" {
+				req = authRequest(t, http.MethodGet, tt.url, tt.caller, "")
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.expectedBody(t, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchMoviesHandler_PaginationUsesFilteredTotal(t *testing.T) {
+	mockService := new(mockMovieService)
+	mockService.On("SearchMovies", mock.Anything, mock.MatchedBy(func(req movies.SearchMoviesRequest) bool {
+		return req.Genre == "Action" && req.Limit == 1 && req.Offset == 0
+	})).Return([]*movies.Movie{createTestMovie()}, int64(3), nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := chi.NewRouter()
+	NewHandler(mockService, logger).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search/movies?genre=Action&limit=1", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp SearchMoviesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, int64(3), resp.Total)
+	assert.True(t, resp.HasMore)
+	assert.Len(t, resp.Movies, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetAllMoviesHandler_Sort(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tests := []struct {
+		name           string
+		query          string
+		expectedSort   string
+		expectedStatus int
+		expectedDetail string
+	}{
+		{name: "defaults to newest first", query: "", expectedSort: "created_at", expectedStatus: http.StatusOK},
+		{name: "several columns", query: "sort=release_year:desc,title:asc", expectedSort: "release_year:desc,title:asc", expectedStatus: http.StatusOK},
+		{name: "columns without a direction take order", query: "sort=genre,title:desc&order=asc", expectedSort: "genre:asc,title:desc", expectedStatus: http.StatusOK},
+		{name: "sort_by still works", query: "sort_by=title&order=asc", expectedSort: "title:asc", expectedStatus: http.StatusOK},
+		{name: "unknown column", query: "sort=budget:desc", expectedStatus: http.StatusBadRequest, expectedDetail: "use one of: controversy, created_at, director, duration_mins"},
+		{name: "sort and sort_by", query: "sort=title&sort_by=title", expectedStatus: http.StatusBadRequest, expectedDetail: "use either sort or sort_by"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			if tt.expectedSort != "" {
+				mockService.On("GetAllMovies", mock.Anything, DefaultLimit, InitialOffset, tt.expectedSort, mock.Anything).
+					Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+			}
+			router := chi.NewRouter()
+			NewHandler(mockService, logger).RegisterRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies?"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code, rr.Body.String())
+			assert.Contains(t, rr.Body.String(), tt.expectedDetail)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSearchMoviesHandler_Facets(t *testing.T) {
+	mockService := new(mockMovieService)
+	mockService.On("SearchMovies", mock.Anything, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+	mockService.On("GetSearchFacets", mock.Anything, mock.MatchedBy(func(req movies.SearchMoviesRequest) bool {
+		return req.Genre == "Action"
+	})).Return(&movies.SearchFacets{
+		Genres:    []movies.FacetBucket{{Value: "Action", Count: 1}},
+		Decades:   []movies.FacetBucket{{Value: "2020s", Count: 1}},
+		Languages: []movies.FacetBucket{{Value: "English", Count: 1}},
+		Ratings:   []movies.FacetBucket{{Value: "PG-13", Count: 1}},
+	}, nil)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := chi.NewRouter()
+	NewHandler(mockService, logger).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search/movies?genre=Action&facets=true", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp SearchMoviesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Facets)
+	assert.Equal(t, []FacetBucketResponse{{Value: "2020s", Count: 1}}, resp.Facets.Decades)
+	assert.Equal(t, []FacetBucketResponse{{Value: "PG-13", Count: 1}}, resp.Facets.Ratings)
+	mockService.AssertExpectations(t)
+}
+
+func TestSuggestMoviesHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("returns suggestions", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("Suggest", mock.Anything, "godf", DefaultSuggestLimit).Return([]*movies.Suggestion{
+			{Kind: movies.SuggestionTitle, Text: "The Godfather", MovieID: "movie-1"},
+			{Kind: movies.SuggestionDirector, Text: "Francis Ford Coppola"},
+		}, nil)
+
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/suggest?q=godf", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp SuggestionsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, []SuggestionResponse{
+			{Kind: "title", Text: "The Godfather", MovieID: "movie-1"},
+			{Kind: "director", Text: "Francis Ford Coppola"},
+		}, resp.Suggestions)
+		mockService.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing q", "/movies/suggest"},
+		{"blank q", "/movies/suggest?q=%20"},
+		{"limit too large", "/movies/suggest?q=godf&limit=21"},
+		{"invalid limit", "/movies/suggest?q=godf&limit=abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			router := chi.NewRouter()
+			NewHandler(mockService, logger).RegisterRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "Suggest", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRandomMoviesHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("returns picks for the filters", func(t *testing.T) {
+		decade, minRating := 1990, 4.0
+		mockService := new(mockMovieService)
+		mockService.On("RandomMovies", mock.Anything, movieService.RandomRequest{
+			Genre:      "Horror",
+			Decade:     &decade,
+			MinRating:  &minRating,
+			NotRatedBy: "user-1",
+			Limit:      3,
+		}).Return([]*movies.Movie{createTestMovie()}, nil)
+
+		router := chi.NewRouter()
+		NewHandler(mockService, logger, WithAuthentication(tokens.FromSecret(movieTestSecret), nil)).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, authRequest(t, http.MethodGet, "/movies/random?genre=Horror&decade=1990s&min_rating=4&unrated=true&limit=3", "user-1", ""))
+
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var resp RandomMoviesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Movies, 1)
+		assert.Equal(t, "Test Movie", resp.Movies[0].Title)
+		mockService.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unrated without a user", "/movies/random?unrated=true"},
+		{"unrated for a user_id", "/movies/random?unrated=true&user_id=user-1"},
+		{"limit too large", "/movies/random?limit=11"},
+		{"invalid decade", "/movies/random?decade=nineties"},
+		{"min_rating out of range", "/movies/random?min_rating=6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			router := chi.NewRouter()
+			NewHandler(mockService, logger).RegisterRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.query, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "RandomMovies", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "de"}, parseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"en", "pt-BR"}, parseAcceptLanguage("pt_br;q=0.5, en, es;q=0"))
+	assert.Empty(t, parseAcceptLanguage(""))
+	assert.Empty(t, parseAcceptLanguage("de;q=abc"))
+}
+
+func TestGetMovieHandler_Localized(t *testing.T) {
+	movie := createTestMovie()
+	mockService := new(mockMovieService)
+	mockService.On("GetMovieByID", mock.Anything, "test-id-123").Return(movie, nil)
+	mockService.On("LocalizeMovies", mock.Anything, []*movies.Movie{movie}, []string{"de-DE", "en"}).Run(func(args mock.Arguments) {
+		m := args.Get(1).([]*movies.Movie)[0]
+		m.Title = "Testfilm"
+		m.Locale = "de"
+	})
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := chi.NewRouter()
+	NewHandler(mockService, logger).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/movies/test-id-123", nil)
+	req.Header.Set("Accept-Language", "de-DE,en;q=0.5")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "de", rr.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+
+	var resp MovieResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, "Testfilm", resp.Title)
+	assert.Equal(t, "de", resp.Locale)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetMovieHandler_Currency(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rates := money.NewStaticRates("USD", map[money.Currency]float64{"EUR": 0.9})
+
+	get := func(t *testing.T, query string, opts ...Option) *httptest.ResponseRecorder {
+		mockService := new(mockMovieService)
+		mockService.On("GetMovieByID", mock.Anything, "test-movie-123").Return(createTestMovie(), nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger, opts...).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/test-movie-123"+query, nil))
+		return rr
+	}
+
+	t.Run("should add converted amounts", func(t *testing.T) {
+		rr := get(t, "?currency=eur", WithCurrencyConverter(money.NewConverter(rates)))
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		var resp MovieResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, MoneyResponse{Amount: 10000000000, Currency: "USD"}, *resp.Budget)
+		assert.Equal(t, MoneyResponse{Amount: 9000000000, Currency: "EUR"}, *resp.BudgetConverted)
+		assert.Equal(t, MoneyResponse{Amount: 22500000000, Currency: "EUR"}, *resp.RevenueConverted)
+	})
+
+	t.Run("should omit converted amounts without the parameter", func(t *testing.T) {
+		rr := get(t, "", WithCurrencyConverter(money.NewConverter(rates)))
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "budget_converted")
+	})
+
+	t.Run("should reject currencies it cannot convert to", func(t *testing.T) {
+		for query, opts := range map[string][]Option{
+			"?currency=EURO": {WithCurrencyConverter(money.NewConverter(rates))},
+			"?currency=JPY":  {WithCurrencyConverter(money.NewConverter(rates))},
+			"?currency=EUR":  nil,
+		} {
+			assert.Equal(t, http.StatusBadRequest, get(t, query, opts...).Code, query)
+		}
+	})
+}
+
+func TestReleaseHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	release := &movies.Release{
+		MovieID: "test-movie-123", Region: "DE", Type: movies.ReleaseTheatrical,
+		ReleaseDate: time.Date(2024, 9, 12, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should put a release", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("PutRelease", mock.Anything, "test-movie-123", "de", "theatrical", movies.ReleaseRequest{ReleaseDate: "2024-09-12"}).
+			Return(release, nil)
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodPut, "/movies/test-movie-123/releases/de/theatrical", strings.NewReader(`{"release_date":"2024-09-12"}`)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp ReleaseResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "2024-09-12", resp.ReleaseDate)
+		assert.Equal(t, "DE", resp.Region)
+	})
+
+	t.Run("should list upcoming releases with their movies", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ListUpcoming", mock.Anything, movieService.UpcomingRequest{Region: "DE", From: "2024-09-01", Limit: 1}).
+			Return([]*movies.UpcomingRelease{{Release: release, Movie: createTestMovie()}}, int64(3), nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/upcoming?region=DE&from=2024-09-01&limit=1", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp UpcomingReleasesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Releases, 1)
+		assert.Equal(t, "2024-09-12", resp.Releases[0].ReleaseDate)
+		assert.Equal(t, "Test Movie", resp.Releases[0].Movie.Title)
+		assert.Equal(t, int64(3), resp.Total)
+		assert.True(t, resp.HasMore)
+	})
+
+	t.Run("should pass service errors through", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("DeleteRelease", mock.Anything, "test-movie-123", "US", "streaming").
+			Return(errors.NewNotFoundError("Release not found"))
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodDelete, "/movies/test-movie-123/releases/US/streaming", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestCertificationHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	certification := &movies.Certification{
+		MovieID: "test-movie-123", Territory: "DE", System: movies.SystemFSK, Certification: "12",
+		UpdatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should put a certification", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("PutCertification", mock.Anything, "test-movie-123", "de", movies.CertificationRequest{Certification: "12"}).
+			Return(certification, nil)
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodPut, "/movies/test-movie-123/certifications/de", strings.NewReader(`{"certification":"12"}`)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp CertificationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "FSK", resp.System)
+		assert.Equal(t, "12", resp.Certification)
+	})
+
+	t.Run("should pass the certification filter to search", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("SearchMovies", mock.Anything, mock.MatchedBy(func(req movies.SearchMoviesRequest) bool {
+			return req.MaxCertification == "12" && req.Territory == "DE"
+		})).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search/movies/?max_certification=12&territory=DE", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should pass service errors through", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("DeleteCertification", mock.Anything, "test-movie-123", "GB").
+			Return(errors.NewNotFoundError("Certification not found"))
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodDelete, "/movies/test-movie-123/certifications/GB", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestProviderHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	link := "https://www.netflix.com/title/81265477"
+	offer := &movies.Offer{
+		MovieID: "test-movie-123", Region: "DE", ProviderID: "netflix", ProviderName: "Netflix",
+		Type: movies.OfferSubscription, URL: &link,
+		UpdatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("should put an offer", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("PutOffer", mock.Anything, "test-movie-123", "de", "netflix", "subscription", movies.OfferRequest{URL: &link}).
+			Return(offer, nil)
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodPut, "/movies/test-movie-123/providers/de/netflix/subscription", strings.NewReader(`{"url":"`+link+`"}`)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp OfferResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "Netflix", resp.ProviderName)
+		assert.Equal(t, link, *resp.URL)
+	})
+
+	t.Run("should list the offers in a region", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ListOffers", mock.Anything, "test-movie-123", "DE").Return([]*movies.Offer{offer}, nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/test-movie-123/providers?region=DE", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp OffersListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Offers, 1)
+		assert.Equal(t, "DE", resp.Offers[0].Region)
+	})
+
+	t.Run("should save a provider", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("PutProvider", mock.Anything, "netflix", movies.ProviderRequest{Name: "Netflix"}).Return(&movies.Provider{ID: "netflix", Name: "Netflix"}, nil)
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodPut, "/providers/netflix", strings.NewReader(`{"name":"Netflix"}`)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"name":"Netflix"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should list the providers", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ListProviders", mock.Anything).Return([]*movies.Provider{{ID: "netflix", Name: "Netflix"}}, nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/providers", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"id":"netflix"`)
+	})
+
+	t.Run("should pass the provider filter to search", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("SearchMovies", mock.Anything, mock.MatchedBy(func(req movies.SearchMoviesRequest) bool {
+			return req.Provider == "netflix" && req.Region == "DE"
+		})).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/search/movies/?provider=netflix&region=DE", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("should pass service errors through", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("DeleteOffer", mock.Anything, "test-movie-123", "US", "max", "buy").
+			Return(errors.NewNotFoundError("Offer not found"))
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodDelete, "/movies/test-movie-123/providers/US/max/buy", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestAggregateStatsHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("should return director stats", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("GetAggregateStats", mock.Anything, movies.AggregateDirector, "Greta Gerwig").Return(&movies.AggregateStats{
+			Kind: movies.AggregateDirector, Name: "Greta Gerwig", MovieCount: 2, RatedMovies: 1, TotalRatings: 3, AverageBayesian: 3.9,
+			BestRated:         &movies.RatedTitle{MovieID: "m1", Title: "Lady Bird", ReleaseYear: 2017, BayesianAverage: 4.1, TotalRatings: 3},
+			WorstRated:        &movies.RatedTitle{MovieID: "m1", Title: "Lady Bird", ReleaseYear: 2017, BayesianAverage: 4.1, TotalRatings: 3},
+			ScoreDistribution: map[int]int64{4: 1, 5: 2},
+		}, nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stats/directors/Greta%20Gerwig", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp AggregateStatsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "director", resp.Kind)
+		assert.Equal(t, int64(2), resp.MovieCount)
+		require.NotNil(t, resp.BestRated)
+		assert.Equal(t, "Lady Bird", resp.BestRated.Title)
+		assert.Equal(t, map[string]int64{"1": 0, "2": 0, "3": 0, "4": 1, "5": 2}, resp.ScoreDistribution)
+	})
+
+	t.Run("should unescape slashes in names", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("GetAggregateStats", mock.Anything, movies.AggregateGenre, "Sci-Fi/Fantasy").
+			Return(nil, errors.NewNotFoundError("No movies found for genre Sci-Fi/Fantasy"))
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/stats/genres/Sci-Fi%2FFantasy", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDecadeHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := func(mockService *mockMovieService) *chi.Mux {
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+		return router
+	}
+
+	t.Run("should list decades with their top movies", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("GetDecades", mock.Anything, 2).Return([]*movies.DecadeSummary{
+			{Decade: 1980, MovieCount: 1, TopRated: []*movies.RatedTitle{}},
+			{Decade: 1990, MovieCount: 3, TopRated: []*movies.RatedTitle{
+				{MovieID: "m1", Title: "Heat", ReleaseYear: 1995, BayesianAverage: 4.2, TotalRatings: 12},
+			}},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setup(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/decades?top=2", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp DecadesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Decades, 2)
+		assert.Equal(t, DecadeResponse{Decade: "1980s", FirstYear: 1980, LastYear: 1989, MovieCount: 1, TopRated: []RatedTitleResponse{}}, resp.Decades[0])
+		require.Len(t, resp.Decades[1].TopRated, 1)
+		assert.Equal(t, "Heat", resp.Decades[1].TopRated[0].Title)
+	})
+
+	t.Run("should reject an invalid top", func(t *testing.T) {
+		mockService := new(mockMovieService)
+
+		rr := httptest.NewRecorder()
+		setup(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/decades?top=11", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "GetDecades", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should filter the movie list by decade", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("SearchMovies", mock.Anything, mock.MatchedBy(func(req movies.SearchMoviesRequest) bool {
+			return req.MinYear != nil && *req.MinYear == 1990 && req.MaxYear != nil && *req.MaxYear == 1999 && req.Limit == 5
+		})).Return([]*movies.Movie{{ID: "m1", Title: "Heat", ReleaseYear: 1995}}, int64(1), nil)
+
+		rr := httptest.NewRecorder()
+		setup(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies?decade=1990s&limit=5", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp MoviesListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(1), resp.Total)
+		require.Len(t, resp.Movies, 1)
+		mockService.AssertNotCalled(t, "GetAllMovies", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	for _, url := range []string{"/movies?decade=1995", "/movies?decade=1990s&min_year=1992"} {
+		t.Run("should reject "+url, func(t *testing.T) {
+			mockService := new(mockMovieService)
+
+			rr := httptest.NewRecorder()
+			setup(mockService).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestTranslationHandlers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	translation := &movies.Translation{
+		MovieID:   "test-id-123",
+		Locale:    "fr",
+		Title:     "Le Film",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	t.Run("PUT saves the translation", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("PutTranslation", mock.Anything, "test-id-123", "fr", movies.TranslationRequest{Title: "Le Film"}).Return(translation, nil)
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodPut, "/movies/test-id-123/translations/fr", createRequestBody(map[string]string{"title": "Le Film"})))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp TranslationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "fr", resp.Locale)
+		assert.Equal(t, "Le Film", resp.Title)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("GET lists translations", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ListTranslations", mock.Anything, "test-id-123").Return([]*movies.Translation{translation}, nil)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/test-id-123/translations", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp TranslationsListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Len(t, resp.Translations, 1)
+	})
+
+	t.Run("DELETE of a missing translation is 404", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("DeleteTranslation", mock.Anything, "test-id-123", "fr").Return(errors.NewNotFoundError("Translation not found"))
+
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, adminRequest(t, http.MethodDelete, "/movies/test-id-123/translations/fr", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
 // Test for response structure validation
 func TestCreateMovieResponse_Structure(t *testing.T) {
 	movie := createTestMovie()
@@ -340,8 +1189,8 @@ func TestCreateMovieResponse_Structure(t *testing.T) {
 		Rating:       movie.Rating.String(),
 		Language:     movie.Language,
 		Country:      movie.Country,
-		Budget:       movie.Budget,
-		Revenue:      movie.Revenue,
+		Budget:       moneyToResponse(movie.BudgetMoney()),
+		Revenue:      moneyToResponse(movie.RevenueMoney()),
 		IMDbID:       movie.IMDbID,
 		PosterURL:    movie.PosterURL,
 		CreatedAt:    movie.CreatedAt.Format(time.RFC3339),
@@ -419,3 +1268,317 @@ func BenchmarkCreateMovieHandler(b *testing.B) {
 		handler.CreateMovie(rr, req)
 	}
 }
+
+func TestCreateMovieHandler_Duplicates(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	req := movies.CreateMovieRequest{Title: "Se7en", ReleaseYear: 1995}
+
+	t.Run("conflict lists the candidates", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		existing := createTestMovie()
+		mockService.On("CreateMovie", mock.Anything, req).Return(nil, &movieService.DuplicateMovieError{
+			AppError:   errors.NewConflictError("Movie may already exist"),
+			Candidates: []*movies.DuplicateCandidate{{Movie: existing, Reason: movies.DuplicateIMDbID}},
+		})
+
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/movies", createRequestBody(req)))
+
+		require.Equal(t, http.StatusConflict, rr.Code)
+		var problem struct {
+			Code  string `json:"code"`
+			Extra struct {
+				Candidates []DuplicateCandidateResponse `json:"candidates"`
+			} `json:"extra"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+		assert.Equal(t, string(errors.CodeConflict), problem.Code)
+		require.Len(t, problem.Extra.Candidates, 1)
+		assert.Equal(t, "test-movie-123", problem.Extra.Candidates[0].ID)
+		assert.Equal(t, "imdb_id", problem.Extra.Candidates[0].Reason)
+	})
+
+	t.Run("query parameter overrides the check", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		override := req
+		override.AllowDuplicate = true
+		mockService.On("CreateMovie", mock.Anything, override).Return(createTestMovie(), nil)
+
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/movies?allow_duplicate=true", createRequestBody(req)))
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestGetMovieHandler_MergedRedirect(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mockService := new(mockMovieService)
+	mockService.On("GetMovieDetails", mock.Anything, movieService.MovieDetailsRequest{MovieID: "old-id", IncludeStats: true}).
+		Return(nil, errors.NewNotFoundError("Movie not found"))
+	mockService.On("ResolveMovieRedirect", mock.Anything, "old-id").Return("new-id", nil)
+
+	router := chi.NewRouter()
+	NewHandler(mockService, logger).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/old-id?include=stats", nil))
+
+	assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+	assert.Equal(t, "/movies/new-id?include=stats", rr.Header().Get("Location"))
+}
+
+func TestGetMovieBySlugHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	request := func(mockService *mockMovieService, url string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		NewHandler(mockService, logger).RegisterRoutes(router)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+		return rr
+	}
+
+	t.Run("serves the movie of its current slug", func(t *testing.T) {
+		movie := createTestMovie()
+		movie.Slug = "test-movie-2023"
+		mockService := new(mockMovieService)
+		mockService.On("ResolveMovieSlug", mock.Anything, "test-movie-2023").Return("test-movie-123", "test-movie-2023", nil)
+		mockService.On("GetMovieByID", mock.Anything, "test-movie-123").Return(movie, nil)
+
+		rr := request(mockService, "/movies/slug/test-movie-2023")
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"slug":"test-movie-2023"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("redirects an old slug to the current one", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ResolveMovieSlug", mock.Anything, "working-title-2023").Return("test-movie-123", "test-movie-2023", nil)
+
+		rr := request(mockService, "/movies/slug/working-title-2023?include=stats")
+
+		assert.Equal(t, http.StatusMovedPermanently, rr.Code)
+		assert.Equal(t, "/movies/slug/test-movie-2023?include=stats", rr.Header().Get("Location"))
+		mockService.AssertNotCalled(t, "GetMovieDetails", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown slug", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("ResolveMovieSlug", mock.Anything, "alien-1979").Return("", "", errors.NewNotFoundError("Movie not found"))
+
+		rr := request(mockService, "/movies/slug/alien-1979")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestPatchMovieHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		setupMock      func(*mockMovieService)
+		expectedStatus int
+	}{
+		{
+			name:        "merge patch with explicit null",
+			contentType: "application/merge-patch+json",
+			body:        `{"title": "Renamed", "poster_url": null}`,
+			setupMock: func(m *mockMovieService) {
+				patched := createTestMovie()
+				patched.Title = "Renamed"
+				patched.PosterURL = nil
+				m.On("PatchMovie", mock.Anything, "test-movie-123", mock.MatchedBy(func(req movieService.PatchMovieRequest) bool {
+					return req.Title.Value == "Renamed" && req.PosterURL.Null && !req.Budget.Set
+				})).Return(patched, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "unknown member",
+			contentType:    "application/merge-patch+json",
+			body:           `{"titel": "Renamed"}`,
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non-object document",
+			contentType:    "application/merge-patch+json",
+			body:           `["title"]`,
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unsupported content type",
+			contentType:    "text/plain",
+			body:           `{"title": "Renamed"}`,
+			setupMock:      func(m *mockMovieService) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "service validation error",
+			contentType: "application/json",
+			body:        `{"title": null}`,
+			setupMock: func(m *mockMovieService) {
+				m.On("PatchMovie", mock.Anything, "test-movie-123", mock.Anything).
+					Return(nil, errors.NewBadRequestError("title cannot be null"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mockMovieService)
+			tt.setupMock(mockService)
+
+			req := adminRequest(t, http.MethodPatch, "/movies/test-movie-123", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			newAuthRouter(mockService).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response MovieResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				assert.Equal(t, "Renamed", response.Title)
+				assert.Nil(t, response.PosterURL)
+			}
+		})
+	}
+
+	t.Run("requires a token", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		router := chi.NewRouter()
+		NewHandler(mockService, logger, WithAuthentication(tokens.FromSecret(movieTestSecret), nil)).RegisterRoutes(router)
+
+		req := httptest.NewRequest(http.MethodPatch, "/movies/test-movie-123", strings.NewReader(`{"title": "Renamed"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		mockService.AssertNotCalled(t, "PatchMovie", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires an admin", func(t *testing.T) {
+		mockService := new(mockMovieService)
+
+		req := authRequest(t, http.MethodPatch, "/movies/test-movie-123", "user-1", `{"title": "Renamed"}`)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rr := httptest.NewRecorder()
+		newAuthRouter(mockService).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertNotCalled(t, "PatchMovie", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func posterUploadBody(t *testing.T, field string, data []byte) (io.Reader, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, "poster.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+func TestUploadPosterHandler(t *testing.T) {
+	t.Run("stores the poster and lists its variants", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		mockService.On("UploadPoster", mock.Anything, "test-movie-123", []byte("png bytes")).Return([]*movieService.PosterImage{
+			{Poster: movies.Poster{Variant: movies.PosterSmall, Width: 185, Height: 278, ContentType: "image/jpeg"}, URL: "/api/v1/media/posters/small.jpg"},
+		}, nil)
+
+		router := newAuthRouter(mockService)
+
+		body, contentType := posterUploadBody(t, "poster", []byte("png bytes"))
+		req := adminRequest(t, http.MethodPost, "/movies/test-movie-123/poster", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var response PosterResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "/api/v1/movies/test-movie-123/poster/large", response.PosterURL)
+		require.Len(t, response.Variants, 1)
+		assert.Equal(t, "small", response.Variants[0].Variant)
+		assert.Equal(t, "/api/v1/media/posters/small.jpg", response.Variants[0].URL)
+	})
+
+	t.Run("rejects a body without a poster field", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		router := newAuthRouter(mockService)
+
+		body, contentType := posterUploadBody(t, "image", []byte("png bytes"))
+		req := adminRequest(t, http.MethodPost, "/movies/test-movie-123/poster", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "UploadPoster", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a poster over the size limit", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		router := newAuthRouter(mockService, WithMaxPosterBytes(1024))
+
+		body, contentType := posterUploadBody(t, "poster", bytes.Repeat([]byte("x"), 2048))
+		req := adminRequest(t, http.MethodPost, "/movies/test-movie-123/poster", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		mockService.AssertNotCalled(t, "UploadPoster", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a body far over the size limit", func(t *testing.T) {
+		mockService := new(mockMovieService)
+		router := newAuthRouter(mockService, WithMaxPosterBytes(1024))
+
+		body, contentType := posterUploadBody(t, "poster", bytes.Repeat([]byte("x"), 256<<10))
+		req := adminRequest(t, http.MethodPost, "/movies/test-movie-123/poster", body)
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	})
+}
+
+func TestGetPosterHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mockService := new(mockMovieService)
+	mockService.On("PosterURL", mock.Anything, "test-movie-123", "large").Return("https://cdn.example.com/large.jpg?sig=abc", nil)
+	mockService.On("PosterURL", mock.Anything, "test-movie-123", "huge").Return("", errors.NewBadRequestError("invalid variant"))
+
+	router := chi.NewRouter()
+	NewHandler(mockService, logger).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/test-movie-123/poster/large", nil))
+	assert.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "https://cdn.example.com/large.jpg?sig=abc", rr.Header().Get("Location"))
+	assert.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/test-movie-123/poster/huge", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,38 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/i18n"
+)
+
+// parseAcceptLanguage returns the locales of an Accept-Language header ordered
+// by preference. Wildcards, q=0 entries and malformed tags are dropped.
+func parseAcceptLanguage(header string) []string {
+	var locales []string
+	for _, tag := range i18n.ParseAcceptLanguage(header) {
+		locale, err := movies.NormalizeLocale(tag)
+		if err != nil {
+			continue
+		}
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// localize applies the request's Accept-Language preferences to moviesList.
+// Responses vary by the header whether or not a translation was found.
+func (h *Handler) localize(w http.ResponseWriter, r *http.Request, moviesList ...*movies.Movie) {
+	w.Header().Add("Vary", "Accept-Language")
+
+	locales := parseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if len(locales) == 0 {
+		return
+	}
+
+	h.movieService.LocalizeMovies(r.Context(), moviesList, locales)
+
+	if len(moviesList) == 1 && moviesList[0] != nil && moviesList[0].Locale != "" {
+		w.Header().Set("Content-Language", moviesList[0].Locale)
+	}
+}
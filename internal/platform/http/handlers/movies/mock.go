@@ -3,6 +3,7 @@ package movies
 import (
 	"context"
 	"thermondo/internal/domain/movies"
+	movieService "thermondo/internal/platform/service/movies"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -20,6 +21,14 @@ func (m *mockMovieService) CreateMovie(ctx context.Context, req movies.CreateMov
 	return args.Get(0).(*movies.Movie), args.Error(1)
 }
 
+func (m *mockMovieService) PatchMovie(ctx context.Context, id string, req movieService.PatchMovieRequest) (*movies.Movie, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
 func (m *mockMovieService) GetMovieByID(ctx context.Context, id string) (*movies.Movie, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -43,3 +52,226 @@ func (m *mockMovieService) GetAllMovies(ctx context.Context, limit, offset int,
 	}
 	return args.Get(0).([]*movies.Movie), args.Get(1).(int64), args.Error(2)
 }
+
+func (m *mockMovieService) GetMovieDetails(ctx context.Context, req movieService.MovieDetailsRequest) (*movieService.MovieDetails, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movieService.MovieDetails), args.Error(1)
+}
+
+func (m *mockMovieService) GetSearchFacets(ctx context.Context, req movies.SearchMoviesRequest) (*movies.SearchFacets, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.SearchFacets), args.Error(1)
+}
+
+func (m *mockMovieService) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Suggestion), args.Error(1)
+}
+
+func (m *mockMovieService) RandomMovies(ctx context.Context, req movieService.RandomRequest) ([]*movies.Movie, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockMovieService) LocalizeMovies(ctx context.Context, moviesList []*movies.Movie, locales []string) {
+	m.Called(ctx, moviesList, locales)
+}
+
+func (m *mockMovieService) ListTranslations(ctx context.Context, movieID string) ([]*movies.Translation, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Translation), args.Error(1)
+}
+
+func (m *mockMovieService) GetTranslation(ctx context.Context, movieID, locale string) (*movies.Translation, error) {
+	args := m.Called(ctx, movieID, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Translation), args.Error(1)
+}
+
+func (m *mockMovieService) PutTranslation(ctx context.Context, movieID, locale string, req movies.TranslationRequest) (*movies.Translation, error) {
+	args := m.Called(ctx, movieID, locale, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Translation), args.Error(1)
+}
+
+func (m *mockMovieService) DeleteTranslation(ctx context.Context, movieID, locale string) error {
+	args := m.Called(ctx, movieID, locale)
+	return args.Error(0)
+}
+
+func (m *mockMovieService) MergeMovies(ctx context.Context, sourceID, targetID, mergedBy string) (*movies.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID, mergedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.MergeRecord), args.Error(1)
+}
+
+func (m *mockMovieService) ResolveMovieRedirect(ctx context.Context, id string) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockMovieService) ResolveMovieSlug(ctx context.Context, slug string) (string, string, error) {
+	args := m.Called(ctx, slug)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *mockMovieService) UploadPoster(ctx context.Context, id string, data []byte) ([]*movieService.PosterImage, error) {
+	args := m.Called(ctx, id, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterImage), args.Error(1)
+}
+
+func (m *mockMovieService) ListPosters(ctx context.Context, id string) ([]*movieService.PosterImage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterImage), args.Error(1)
+}
+
+func (m *mockMovieService) PosterURL(ctx context.Context, id, variant string) (string, error) {
+	args := m.Called(ctx, id, variant)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockMovieService) FindDuplicatePosters(ctx context.Context, maxDistance int) ([]*movieService.PosterDuplicates, error) {
+	args := m.Called(ctx, maxDistance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterDuplicates), args.Error(1)
+}
+
+func (m *mockMovieService) SearchByPoster(ctx context.Context, data []byte, maxDistance int) ([]*movieService.PosterMatch, error) {
+	args := m.Called(ctx, data, maxDistance)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movieService.PosterMatch), args.Error(1)
+}
+
+func (m *mockMovieService) ListReleases(ctx context.Context, movieID string) ([]*movies.Release, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Release), args.Error(1)
+}
+
+func (m *mockMovieService) PutRelease(ctx context.Context, movieID, region, releaseType string, req movies.ReleaseRequest) (*movies.Release, error) {
+	args := m.Called(ctx, movieID, region, releaseType, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Release), args.Error(1)
+}
+
+func (m *mockMovieService) DeleteRelease(ctx context.Context, movieID, region, releaseType string) error {
+	args := m.Called(ctx, movieID, region, releaseType)
+	return args.Error(0)
+}
+
+func (m *mockMovieService) ListCertifications(ctx context.Context, movieID string) ([]*movies.Certification, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Certification), args.Error(1)
+}
+
+func (m *mockMovieService) PutCertification(ctx context.Context, movieID, territory string, req movies.CertificationRequest) (*movies.Certification, error) {
+	args := m.Called(ctx, movieID, territory, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Certification), args.Error(1)
+}
+
+func (m *mockMovieService) DeleteCertification(ctx context.Context, movieID, territory string) error {
+	args := m.Called(ctx, movieID, territory)
+	return args.Error(0)
+}
+
+func (m *mockMovieService) ListUpcoming(ctx context.Context, req movieService.UpcomingRequest) ([]*movies.UpcomingRelease, int64, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*movies.UpcomingRelease), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *mockMovieService) GetAggregateStats(ctx context.Context, kind movies.AggregateKind, name string) (*movies.AggregateStats, error) {
+	args := m.Called(ctx, kind, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.AggregateStats), args.Error(1)
+}
+
+func (m *mockMovieService) GetDecades(ctx context.Context, top int) ([]*movies.DecadeSummary, error) {
+	args := m.Called(ctx, top)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.DecadeSummary), args.Error(1)
+}
+
+func (m *mockMovieService) ListProviders(ctx context.Context) ([]*movies.Provider, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Provider), args.Error(1)
+}
+
+func (m *mockMovieService) PutProvider(ctx context.Context, providerID string, req movies.ProviderRequest) (*movies.Provider, error) {
+	args := m.Called(ctx, providerID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Provider), args.Error(1)
+}
+
+func (m *mockMovieService) ListOffers(ctx context.Context, movieID, region string) ([]*movies.Offer, error) {
+	args := m.Called(ctx, movieID, region)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Offer), args.Error(1)
+}
+
+func (m *mockMovieService) PutOffer(ctx context.Context, movieID, region, providerID, offerType string, req movies.OfferRequest) (*movies.Offer, error) {
+	args := m.Called(ctx, movieID, region, providerID, offerType, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Offer), args.Error(1)
+}
+
+func (m *mockMovieService) DeleteOffer(ctx context.Context, movieID, region, providerID, offerType string) error {
+	args := m.Called(ctx, movieID, region, providerID, offerType)
+	return args.Error(0)
+}
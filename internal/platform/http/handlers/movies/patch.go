@@ -0,0 +1,61 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/mergepatch"
+	"thermondo/internal/platform/http/middleware"
+	movieService "thermondo/internal/platform/service/movies"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PatchMovie handles PATCH /movies/{id} with a JSON Merge Patch body; members
+// left out keep their value and null clears optional fields
+func (h *Handler) PatchMovie(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.PrincipalFrom(r.Context()); !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	movieID := chi.URLParam(r, "id")
+
+	if err := mergepatch.CheckContentType(r.Header.Get("Content-Type")); err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req PatchMovieRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[patch_movie_handler] Invalid merge patch", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	movie, err := h.movieService.PatchMovie(r.Context(), movieID, req.toService())
+	if err != nil {
+		h.logger.Error("[patch_movie_handler] Failed to patch movie", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, h.movieToResponse(movie), http.StatusOK)
+}
+
+func (r PatchMovieRequest) toService() movieService.PatchMovieRequest {
+	return movieService.PatchMovieRequest{
+		Title:        r.Title,
+		Description:  r.Description,
+		ReleaseYear:  r.ReleaseYear,
+		Genre:        r.Genre,
+		Director:     r.Director,
+		DurationMins: r.DurationMins,
+		Rating:       r.Rating,
+		Language:     r.Language,
+		Country:      r.Country,
+		Budget:       r.Budget,
+		Revenue:      r.Revenue,
+		Currency:     r.Currency,
+		IMDbID:       r.IMDbID,
+		PosterURL:    r.PosterURL,
+	}
+}
@@ -0,0 +1,120 @@
+package movies
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"thermondo/internal/domain/movies"
+	movieService "thermondo/internal/platform/service/movies"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultMaxPosterBytes caps the size of an uploaded poster image
+const DefaultMaxPosterBytes = 10 << 20
+
+// posterFormOverhead leaves room for the multipart boundaries and headers
+// around the image itself
+const posterFormOverhead = 64 << 10
+
+// UploadPoster handles POST /movies/{id}/poster with a multipart/form-data
+// body carrying a JPEG or PNG image in the "poster" field
+func (h *Handler) UploadPoster(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxPosterBytes+posterFormOverhead)
+	if err := r.ParseMultipartForm(h.maxPosterBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.writePosterTooLarge(w)
+			return
+		}
+		h.logger.Error("[upload_poster_handler] Invalid multipart body", "error", err)
+		h.responseWriter.WriteError(w, "Request body must be multipart/form-data with a poster file", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("poster")
+	if err != nil {
+		h.responseWriter.WriteError(w, "Missing poster file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxPosterBytes {
+		h.writePosterTooLarge(w)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error("[upload_poster_handler] Failed to read poster", "error", err)
+		h.responseWriter.WriteError(w, "Failed to read poster file", http.StatusBadRequest)
+		return
+	}
+
+	images, err := h.movieService.UploadPoster(r.Context(), movieID, data)
+	if err != nil {
+		h.logger.Error("[upload_poster_handler] Failed to upload poster", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, posterToResponse(movieID, images), http.StatusCreated)
+}
+
+// ListPosters handles GET /movies/{id}/poster
+func (h *Handler) ListPosters(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+
+	images, err := h.movieService.ListPosters(r.Context(), movieID)
+	if err != nil {
+		h.logger.Error("[list_posters_handler] Failed to list posters", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, posterToResponse(movieID, images), http.StatusOK)
+}
+
+// GetPoster handles GET /movies/{id}/poster/{variant} by redirecting to the
+// variant's current, possibly signed, download URL
+func (h *Handler) GetPoster(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+	variant := chi.URLParam(r, "variant")
+
+	url, err := h.movieService.PosterURL(r.Context(), movieID, variant)
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	// Signed URLs expire, so clients must come back here rather than cache
+	// the redirect
+	w.Header().Set("Cache-Control", "no-cache")
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+func (h *Handler) writePosterTooLarge(w http.ResponseWriter) {
+	h.responseWriter.WriteError(w, fmt.Sprintf("Poster must not exceed %d bytes", h.maxPosterBytes), http.StatusRequestEntityTooLarge)
+}
+
+func posterToResponse(movieID string, images []*movieService.PosterImage) PosterResponse {
+	response := PosterResponse{
+		MovieID:   movieID,
+		PosterURL: movieService.PosterPath(movies.MovieID(movieID), movies.PosterLarge),
+		Variants:  make([]PosterVariantResponse, len(images)),
+	}
+	for i, image := range images {
+		response.Variants[i] = PosterVariantResponse{
+			Variant:     string(image.Variant),
+			Width:       image.Width,
+			Height:      image.Height,
+			SizeBytes:   image.SizeBytes,
+			ContentType: image.ContentType,
+			URL:         image.URL,
+		}
+	}
+	return response
+}
@@ -0,0 +1,110 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/http/request"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ListProviders handles GET /providers, the streaming services and stores
+// movies can be offered on, e.g. to fill a provider filter
+func (h *Handler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	providers, err := h.movieService.ListProviders(r.Context())
+	if err != nil {
+		h.logger.Error("[list_providers_handler] Failed to list providers", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &ProvidersListResponse{Providers: make([]ProviderResponse, len(providers))}
+	for i, provider := range providers {
+		response.Providers[i] = ProviderResponse{ID: provider.ID, Name: provider.Name}
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// PutProvider handles PUT /providers/{provider}, adding a provider or
+// renaming it
+func (h *Handler) PutProvider(w http.ResponseWriter, r *http.Request) {
+	var req ProviderRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	provider, err := h.movieService.PutProvider(r.Context(), chi.URLParam(r, "provider"), movies.ProviderRequest{Name: req.Name})
+	if err != nil {
+		h.logger.Error("[put_provider_handler] Failed to save provider", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, ProviderResponse{ID: provider.ID, Name: provider.Name}, http.StatusOK)
+}
+
+// ListOffers handles GET /movies/{id}/providers?region=DE, where to watch
+// the movie, in one region when given
+func (h *Handler) ListOffers(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+	offers, err := h.movieService.ListOffers(r.Context(), movieID, r.URL.Query().Get("region"))
+	if err != nil {
+		h.logger.Error("[list_offers_handler] Failed to list offers", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &OffersListResponse{MovieID: movieID, Offers: make([]OfferResponse, len(offers))}
+	for i, offer := range offers {
+		response.Offers[i] = offerToResponse(offer)
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// PutOffer handles PUT /movies/{id}/providers/{region}/{provider}/{type},
+// creating or replacing an offer of the movie
+func (h *Handler) PutOffer(w http.ResponseWriter, r *http.Request) {
+	var req OfferRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	offer, err := h.movieService.PutOffer(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "region"), chi.URLParam(r, "provider"), chi.URLParam(r, "type"), movies.OfferRequest{URL: req.URL})
+	if err != nil {
+		h.logger.Error("[put_offer_handler] Failed to save offer", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, offerToResponse(offer), http.StatusOK)
+}
+
+func (h *Handler) DeleteOffer(w http.ResponseWriter, r *http.Request) {
+	if err := h.movieService.DeleteOffer(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "region"), chi.URLParam(r, "provider"), chi.URLParam(r, "type")); err != nil {
+		h.logger.Error("[delete_offer_handler] Failed to delete offer", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Offer deleted successfully"}, http.StatusOK)
+}
+
+func offerToResponse(offer *movies.Offer) OfferResponse {
+	return OfferResponse{
+		Region:       offer.Region,
+		ProviderID:   offer.ProviderID,
+		ProviderName: offer.ProviderName,
+		Type:         string(offer.Type),
+		URL:          offer.URL,
+		UpdatedAt:    offer.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,86 @@
+package movies
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/movies"
+	movieService "thermondo/internal/platform/service/movies"
+)
+
+// RandomMovies handles GET /movies/random, the "surprise me" pick of the
+// discovery feature. Picks can be narrowed by genre, decade (1990 or
+// 1990s), min_rating and, with unrated=true, to movies the requesting user
+// has not rated yet.
+func (h *Handler) RandomMovies(w http.ResponseWriter, r *http.Request) {
+	req, err := h.parseRandomParams(r)
+	if err != nil {
+		h.logger.Error("[random_movies_handler] Invalid params", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	picked, err := h.movieService.RandomMovies(r.Context(), req)
+	if err != nil {
+		h.logger.Error("[random_movies_handler] Failed to pick random movies", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.localize(w, r, picked...)
+
+	response := &RandomMoviesResponse{Movies: h.moviesToResponse(picked)}
+	if !h.convertAmounts(w, r, responsePointers(response.Movies)...) {
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func (h *Handler) parseRandomParams(r *http.Request) (movieService.RandomRequest, error) {
+	query := r.URL.Query()
+	req := movieService.RandomRequest{
+		Genre: strings.TrimSpace(query.Get("genre")),
+		Limit: movieService.DefaultRandomLimit,
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > movieService.MaxRandomLimit {
+			return req, errors.New("limit must be between 1 and 10")
+		}
+		req.Limit = limit
+	}
+
+	if decadeStr := query.Get("decade"); decadeStr != "" {
+		decade, err := movies.ParseDecade(decadeStr)
+		if err != nil {
+			return req, err
+		}
+		req.Decade = &decade
+	}
+
+	if minRatingStr := query.Get("min_rating"); minRatingStr != "" {
+		minRating, err := strconv.ParseFloat(minRatingStr, 64)
+		if err != nil || minRating < 1 || minRating > 5 {
+			return req, errors.New("min_rating must be a number between 1 and 5")
+		}
+		req.MinRating = &minRating
+	}
+
+	if unratedStr := query.Get("unrated"); unratedStr != "" {
+		unrated, err := strconv.ParseBool(unratedStr)
+		if err != nil {
+			return req, errors.New("unrated must be true or false")
+		}
+		if unrated {
+			req.NotRatedBy = requestingUserID(r)
+			if req.NotRatedBy == "" {
+				return req, errors.New("unrated=true requires an authenticated user")
+			}
+		}
+	}
+
+	return req, nil
+}
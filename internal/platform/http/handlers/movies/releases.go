@@ -0,0 +1,125 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/http/request"
+	movieService "thermondo/internal/platform/service/movies"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) ListReleases(w http.ResponseWriter, r *http.Request) {
+	releases, err := h.movieService.ListReleases(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.Error("[list_releases_handler] Failed to list releases", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &ReleasesListResponse{Releases: make([]ReleaseResponse, len(releases))}
+	for i, release := range releases {
+		response.Releases[i] = releaseToResponse(release)
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// PutRelease handles PUT /movies/{id}/releases/{region}/{type}, creating or
+// replacing the release date of the movie in a region
+func (h *Handler) PutRelease(w http.ResponseWriter, r *http.Request) {
+	var req ReleaseRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	release, err := h.movieService.PutRelease(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "region"), chi.URLParam(r, "type"), movies.ReleaseRequest{ReleaseDate: req.ReleaseDate})
+	if err != nil {
+		h.logger.Error("[put_release_handler] Failed to save release", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, releaseToResponse(release), http.StatusOK)
+}
+
+func (h *Handler) DeleteRelease(w http.ResponseWriter, r *http.Request) {
+	if err := h.movieService.DeleteRelease(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "region"), chi.URLParam(r, "type")); err != nil {
+		h.logger.Error("[delete_release_handler] Failed to delete release", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Release deleted successfully"}, http.StatusOK)
+}
+
+// ListUpcoming handles GET /movies/upcoming?region=DE&type=theatrical&from=&to=,
+// the releases of the product calendar ordered by date
+func (h *Handler) ListUpcoming(w http.ResponseWriter, r *http.Request) {
+	params, err := h.parseListParams(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	upcoming, total, err := h.movieService.ListUpcoming(r.Context(), movieService.UpcomingRequest{
+		Region: query.Get("region"),
+		Type:   query.Get("type"),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+		Limit:  params.Limit,
+		Offset: params.Offset,
+	})
+	if err != nil {
+		h.logger.Error("[list_upcoming_handler] Failed to list upcoming releases", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	moviesList := make([]*movies.Movie, len(upcoming))
+	for i, u := range upcoming {
+		moviesList[i] = u.Movie
+	}
+	h.localize(w, r, moviesList...)
+
+	response := &UpcomingReleasesResponse{
+		Releases: make([]UpcomingReleaseResponse, len(upcoming)),
+		Total:    total,
+		Limit:    params.Limit,
+		Offset:   params.Offset,
+		HasMore:  params.Offset+params.Limit < int(total),
+	}
+	converted := make([]*MovieResponse, len(upcoming))
+	for i, u := range upcoming {
+		response.Releases[i] = UpcomingReleaseResponse{
+			Region:      u.Release.Region,
+			Type:        string(u.Release.Type),
+			ReleaseDate: u.Release.ReleaseDate.Format(movies.ReleaseDateLayout),
+			Movie:       h.movieToResponse(u.Movie),
+		}
+		converted[i] = &response.Releases[i].Movie
+	}
+	if !h.convertAmounts(w, r, converted...) {
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func releaseToResponse(release *movies.Release) ReleaseResponse {
+	return ReleaseResponse{
+		MovieID:     string(release.MovieID),
+		Region:      release.Region,
+		Type:        string(release.Type),
+		ReleaseDate: release.ReleaseDate.Format(movies.ReleaseDateLayout),
+		CreatedAt:   release.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   release.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -1,6 +1,10 @@
 package movies
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/movies"
+)
 
 func (h *Handler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 	searchParams, err := h.parseSearchParams(r)
@@ -10,6 +14,16 @@ func (h *Handler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	withFacets := false
+	if facetsStr := r.URL.Query().Get("facets"); facetsStr != "" {
+		withFacets, err = strconv.ParseBool(facetsStr)
+		if err != nil {
+			h.logger.Error("[search_movies_handler] Invalid facets flag", "error", err)
+			h.responseWriter.WriteError(w, "facets must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
 	moviesList, total, err := h.movieService.SearchMovies(r.Context(), *searchParams)
 	if err != nil {
 		h.logger.Error("[search_movies_handler] Failed to search movies", "error", err)
@@ -17,6 +31,8 @@ func (h *Handler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.localize(w, r, moviesList...)
+
 	response := &SearchMoviesResponse{
 		Movies:  h.moviesToResponse(moviesList),
 		Total:   total,
@@ -25,7 +41,37 @@ func (h *Handler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 		HasMore: searchParams.Offset+searchParams.Limit < int(total),
 		Query:   searchParams.Query,
 	}
+	if !h.convertAmounts(w, r, responsePointers(response.Movies)...) {
+		return
+	}
+
+	if withFacets {
+		facets, err := h.movieService.GetSearchFacets(r.Context(), *searchParams)
+		if err != nil {
+			h.logger.Error("[search_movies_handler] Failed to get search facets", "error", err)
+			h.handleServiceError(w, err)
+			return
+		}
+		response.Facets = h.facetsToResponse(facets)
+	}
 
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 
 }
+
+func (h *Handler) facetsToResponse(facets *movies.SearchFacets) *SearchFacetsResponse {
+	buckets := func(in []movies.FacetBucket) []FacetBucketResponse {
+		out := make([]FacetBucketResponse, len(in))
+		for i, b := range in {
+			out[i] = FacetBucketResponse{Value: b.Value, Count: b.Count}
+		}
+		return out
+	}
+
+	return &SearchFacetsResponse{
+		Genres:    buckets(facets.Genres),
+		Decades:   buckets(facets.Decades),
+		Languages: buckets(facets.Languages),
+		Ratings:   buckets(facets.Ratings),
+	}
+}
@@ -0,0 +1,49 @@
+package movies
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 20
+)
+
+func (h *Handler) SuggestMovies(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		h.responseWriter.WriteError(w, "q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := DefaultSuggestLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxSuggestLimit {
+			h.logger.Error("[suggest_movies_handler] Invalid limit", "limit", limitStr)
+			h.responseWriter.WriteError(w, "limit must be between 1 and 20", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.movieService.Suggest(r.Context(), query, limit)
+	if err != nil {
+		h.logger.Error("[suggest_movies_handler] Failed to get suggestions", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := &SuggestionsResponse{Suggestions: make([]SuggestionResponse, len(suggestions))}
+	for i, s := range suggestions {
+		resp.Suggestions[i] = SuggestionResponse{
+			Kind:    string(s.Kind),
+			Text:    s.Text,
+			MovieID: string(s.MovieID),
+		}
+	}
+
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
@@ -0,0 +1,81 @@
+package movies
+
+import (
+	"net/http"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/http/request"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+
+	translations, err := h.movieService.ListTranslations(r.Context(), movieID)
+	if err != nil {
+		h.logger.Error("[list_translations_handler] Failed to list translations", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &TranslationsListResponse{Translations: make([]TranslationResponse, len(translations))}
+	for i, t := range translations {
+		response.Translations[i] = translationToResponse(t)
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func (h *Handler) GetTranslation(w http.ResponseWriter, r *http.Request) {
+	translation, err := h.movieService.GetTranslation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "locale"))
+	if err != nil {
+		h.logger.Error("[get_translation_handler] Failed to get translation", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, translationToResponse(translation), http.StatusOK)
+}
+
+func (h *Handler) PutTranslation(w http.ResponseWriter, r *http.Request) {
+	var req TranslationRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	translation, err := h.movieService.PutTranslation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "locale"), movies.TranslationRequest{Title: req.Title, Description: req.Description})
+	if err != nil {
+		h.logger.Error("[put_translation_handler] Failed to save translation", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, translationToResponse(translation), http.StatusOK)
+}
+
+func (h *Handler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	if err := h.movieService.DeleteTranslation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "locale")); err != nil {
+		h.logger.Error("[delete_translation_handler] Failed to delete translation", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Translation deleted successfully"}, http.StatusOK)
+}
+
+func translationToResponse(t *movies.Translation) TranslationResponse {
+	return TranslationResponse{
+		MovieID:     string(t.MovieID),
+		Locale:      t.Locale,
+		Title:       t.Title,
+		Description: t.Description,
+		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   t.UpdatedAt.Format(time.RFC3339),
+	}
+}
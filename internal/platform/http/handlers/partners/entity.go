@@ -0,0 +1,9 @@
+package partners
+
+// ScoreResponse is all the partner API reveals about a movie's ratings
+type ScoreResponse struct {
+	MovieID    string  `json:"movie_id"`
+	Score      float64 `json:"score"`
+	Count      int64   `json:"count"`
+	Confidence float64 `json:"confidence"` // From 0 to 1 as the movie gathers enough ratings
+}
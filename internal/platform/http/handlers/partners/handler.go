@@ -0,0 +1,172 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/partners"
+	"thermondo/internal/domain/usage"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/platform/http/middleware"
+	partnerService "thermondo/internal/platform/service/partners"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// APIKeyHeader carries a partner's API key
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyKey struct{}
+
+// Handler serves the partner API. It is mounted apart from /api/v1 so it
+// can be versioned on its own; every route needs an API key with the
+// route's scope, is held to the key's rate limit and is metered for
+// billing.
+type Handler struct {
+	service        partnerService.Service
+	responseWriter *response.Writer
+	limiter        *ratelimit.Limiter
+	usage          middleware.UsageMeter
+	logger         *slog.Logger
+}
+
+// Option configures optional behaviour of the partner handler
+type Option func(*Handler)
+
+// WithKeyRateLimiter enforces each key's rate limit. Only the limiter's
+// window is used; the limit comes from the key.
+func WithKeyRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.limiter = limiter
+	}
+}
+
+// WithUsageMeter holds keys to their monthly quota and adds their requests
+// to the usage reports
+func WithUsageMeter(meter middleware.UsageMeter) Option {
+	return func(h *Handler) {
+		h.usage = meter
+	}
+}
+
+func NewHandler(service partnerService.Service, logger *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{
+		service:        service,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Group(func(r chi.Router) {
+		r.Use(h.authenticateKey, h.limitKey)
+		if h.usage != nil {
+			r.Use(middleware.Metered(h.usage, h.responseWriter, keyPrincipal))
+		}
+		r.With(h.requireScope(partners.ScopeScoresRead), h.meter).Get("/movies/{id}/score", h.GetScore)
+	})
+}
+
+// authenticateKey resolves the API key header to its key
+func (h *Handler) authenticateKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := h.service.Authenticate(r.Context(), r.Header.Get(APIKeyHeader))
+		if err != nil {
+			h.writeServiceError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyKey{}, key)))
+	})
+}
+
+// limitKey rejects requests beyond the key's rate limit with 429 and a
+// Retry-After header
+func (h *Handler) limitKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.limiter != nil {
+			key := keyFrom(r)
+			if allowed, retryAfter := h.limiter.AllowUpTo(string(key.ID), key.RateLimit); !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				h.responseWriter.WriteProblem(w, r, response.NewProblem(http.StatusTooManyRequests, appErrors.CodeTooManyRequests, "API key rate limit exceeded, try again later"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) requireScope(scope partners.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !keyFrom(r).HasScope(scope) {
+				h.responseWriter.WriteProblem(w, r, response.NewProblem(http.StatusForbidden, appErrors.CodeForbidden, "API key lacks the "+string(scope)+" scope"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// meter counts the request against the key once it is answered. Failures
+// on our side are not billed.
+func (h *Handler) meter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		key := keyFrom(r)
+		endpoint := r.Method + " " + chi.RouteContext(r.Context()).RoutePattern()
+		// A partner hanging up after being answered is still billed
+		if err := h.service.RecordUsage(context.WithoutCancel(r.Context()), key.ID, endpoint); err != nil {
+			h.logger.Error("[partner_meter] Failed to record usage", "error", err, "key_id", key.ID, "endpoint", endpoint)
+		}
+	})
+}
+
+func keyPrincipal(r *http.Request) (usage.Principal, bool) {
+	return usage.Principal{Kind: usage.KindAPIKey, ID: string(keyFrom(r).ID)}, true
+}
+
+func keyFrom(r *http.Request) *partners.APIKey {
+	key, _ := r.Context().Value(apiKeyKey{}).(*partners.APIKey)
+	return key
+}
+
+// GetScore handles GET /movies/{id}/score
+func (h *Handler) GetScore(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "id")
+	score, err := h.service.GetScore(r.Context(), movieID)
+	if err != nil {
+		h.logger.Error("[get_partner_score_handler] Failed to get score", "error", err, "movie_id", movieID, "key_id", keyFrom(r).ID)
+		h.writeServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, ScoreResponse{
+		MovieID:    score.MovieID,
+		Score:      score.Score,
+		Count:      score.Count,
+		Confidence: score.Confidence,
+	}, http.StatusOK)
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
@@ -0,0 +1,107 @@
+package partners
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/partners"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/ratelimit"
+	partnerService "thermondo/internal/platform/service/partners"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const scoreEndpoint = "GET /movies/{id}/score"
+
+func setupRouter(service *MockPartnerService, opts ...Option) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), opts...).RegisterRoutes(router)
+	return router
+}
+
+func scoreRequest(key string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/movies/movie-1/score", nil)
+	if key != "" {
+		req.Header.Set(APIKeyHeader, key)
+	}
+	return req
+}
+
+func TestGetScore(t *testing.T) {
+	service := new(MockPartnerService)
+	service.On("Authenticate", mock.Anything, "pk_acme").Return(&partners.APIKey{ID: "key-1", Scopes: []partners.Scope{partners.ScopeScoresRead}}, nil)
+	service.On("GetScore", mock.Anything, "movie-1").Return(&partnerService.Score{MovieID: "movie-1", Score: 3.88, Count: 12, Confidence: 0.4}, nil)
+	service.On("RecordUsage", mock.Anything, partners.KeyID("key-1"), scoreEndpoint).Return(nil)
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, scoreRequest("pk_acme"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"movie_id":"movie-1","score":3.88,"count":12,"confidence":0.4}`, w.Body.String())
+	service.AssertCalled(t, "RecordUsage", mock.Anything, partners.KeyID("key-1"), scoreEndpoint)
+}
+
+func TestGetScore_MissingKey(t *testing.T) {
+	service := new(MockPartnerService)
+	service.On("Authenticate", mock.Anything, "").Return(nil, appErrors.NewUnauthorizedError("Missing API key"))
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, scoreRequest(""))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	service.AssertNotCalled(t, "GetScore", mock.Anything, mock.Anything)
+	service.AssertNotCalled(t, "RecordUsage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetScore_MissingScope(t *testing.T) {
+	service := new(MockPartnerService)
+	service.On("Authenticate", mock.Anything, "pk_acme").Return(&partners.APIKey{ID: "key-1"}, nil)
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, scoreRequest("pk_acme"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	service.AssertNotCalled(t, "GetScore", mock.Anything, mock.Anything)
+}
+
+func TestGetScore_RateLimit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	limiter := ratelimit.New(0, time.Minute, ratelimit.WithClock(func() time.Time { return now }))
+	service := new(MockPartnerService)
+	service.On("Authenticate", mock.Anything, "pk_acme").Return(&partners.APIKey{ID: "key-1", Scopes: []partners.Scope{partners.ScopeScoresRead}, RateLimit: 2}, nil)
+	service.On("GetScore", mock.Anything, "movie-1").Return(&partnerService.Score{MovieID: "movie-1"}, nil)
+	service.On("RecordUsage", mock.Anything, partners.KeyID("key-1"), scoreEndpoint).Return(nil)
+	router := setupRouter(service, WithKeyRateLimiter(limiter))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, scoreRequest("pk_acme"))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, scoreRequest("pk_acme"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	service.AssertNumberOfCalls(t, "RecordUsage", 2)
+}
+
+func TestGetScore_NotFoundIsBilled(t *testing.T) {
+	service := new(MockPartnerService)
+	service.On("Authenticate", mock.Anything, "pk_acme").Return(&partners.APIKey{ID: "key-1", Scopes: []partners.Scope{partners.ScopeScoresRead}}, nil)
+	service.On("GetScore", mock.Anything, "movie-1").Return(nil, appErrors.NewNotFoundError("Movie not found"))
+	service.On("RecordUsage", mock.Anything, partners.KeyID("key-1"), scoreEndpoint).Return(nil)
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, scoreRequest("pk_acme"))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	service.AssertCalled(t, "RecordUsage", mock.Anything, partners.KeyID("key-1"), scoreEndpoint)
+}
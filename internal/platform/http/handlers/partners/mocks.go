@@ -0,0 +1,64 @@
+package partners
+
+import (
+	"context"
+	"thermondo/internal/domain/partners"
+	partnerService "thermondo/internal/platform/service/partners"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPartnerService is a mock implementation of the partner service
+type MockPartnerService struct {
+	mock.Mock
+}
+
+func (m *MockPartnerService) CreateKey(ctx context.Context, req partnerService.CreateKeyRequest) (*partnerService.CreatedKey, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partnerService.CreatedKey), args.Error(1)
+}
+
+func (m *MockPartnerService) ListKeys(ctx context.Context) ([]*partners.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*partners.APIKey), args.Error(1)
+}
+
+func (m *MockPartnerService) RevokeKey(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPartnerService) Authenticate(ctx context.Context, secret string) (*partners.APIKey, error) {
+	args := m.Called(ctx, secret)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partners.APIKey), args.Error(1)
+}
+
+func (m *MockPartnerService) RecordUsage(ctx context.Context, keyID partners.KeyID, endpoint string) error {
+	args := m.Called(ctx, keyID, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockPartnerService) GetUsage(ctx context.Context, req partnerService.UsageRequest) (*partnerService.UsageReport, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partnerService.UsageReport), args.Error(1)
+}
+
+func (m *MockPartnerService) GetScore(ctx context.Context, movieID string) (*partnerService.Score, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partnerService.Score), args.Error(1)
+}
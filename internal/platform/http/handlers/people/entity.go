@@ -0,0 +1,50 @@
+package people
+
+type CreatePersonRequest struct {
+	Name string `json:"name"`
+}
+
+// AddCreditRequest is the body of POST /movies/{movieId}/credits
+type AddCreditRequest struct {
+	PersonID     string `json:"person_id"`
+	Role         string `json:"role"`
+	Character    string `json:"character,omitempty"`
+	BillingOrder int    `json:"billing_order,omitempty"`
+}
+
+type PersonResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CreditedMovieResponse struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	ReleaseYear int    `json:"release_year"`
+	Genre       string `json:"genre"`
+	Role        string `json:"role"`
+	Character   string `json:"character,omitempty"`
+}
+
+type PersonMoviesResponse struct {
+	Movies  []CreditedMovieResponse `json:"movies"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+	HasMore bool                    `json:"has_more"`
+}
+
+type CreditResponse struct {
+	MovieID      string `json:"movie_id"`
+	PersonID     string `json:"person_id"`
+	PersonName   string `json:"person_name"`
+	Role         string `json:"role"`
+	Character    string `json:"character,omitempty"`
+	BillingOrder int    `json:"billing_order"`
+}
+
+type MovieCreditsResponse struct {
+	Credits []CreditResponse `json:"credits"`
+}
@@ -0,0 +1,20 @@
+package people
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		CreatePersonRequest{},
+		AddCreditRequest{},
+		PersonResponse{},
+		PersonMoviesResponse{},
+		MovieCreditsResponse{},
+		CreditResponse{},
+	))
+}
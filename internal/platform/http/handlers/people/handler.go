@@ -0,0 +1,263 @@
+package people
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/people"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	peopleService "thermondo/internal/platform/service/people"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+type Handler struct {
+	peopleService  peopleService.Service
+	responseWriter *response.Writer
+	logger         *slog.Logger
+	auth           *middleware.AuthMiddleware
+	keys           *tokens.Keys
+	sessions       middleware.SessionValidator
+}
+
+// Option configures optional behaviour of the people handler
+type Option func(*Handler)
+
+// WithAuthentication verifies bearer tokens on the routes that add people,
+// rejecting tokens whose session has been revoked
+func WithAuthentication(keys *tokens.Keys, sessions middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.keys = keys
+		h.sessions = sessions
+	}
+}
+
+func NewHandler(peopleService peopleService.Service, logger *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{
+		peopleService:  peopleService,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.keys != nil {
+		var authOptions []middleware.AuthOption
+		if h.sessions != nil {
+			authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+		}
+		h.auth = middleware.NewAuthMiddleware(h.keys, h.responseWriter, authOptions...)
+	}
+
+	return h
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Route("/people", func(r chi.Router) {
+		// Adding people is for admins; without authentication configured
+		// the route is not registered
+		if h.auth != nil {
+			r.With(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin)).Post("/", h.CreatePerson)
+		}
+		r.Get("/{id}", h.GetPerson)
+		r.Get("/{id}/movies", h.GetPersonMovies)
+	})
+
+	// Plain routes rather than a /movies/{movieId} sub-router so that
+	// GET /movies/{id} still reaches the movies handler
+	router.Get("/movies/{movieId}/credits", h.GetMovieCredits)
+	router.Post("/movies/{movieId}/credits", h.AddCredit)
+	router.Delete("/movies/{movieId}/credits/{personId}/{role}", h.RemoveCredit)
+}
+
+func (h *Handler) CreatePerson(w http.ResponseWriter, r *http.Request) {
+	var req CreatePersonRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	person, err := h.peopleService.CreatePerson(r.Context(), req.toService())
+	if err != nil {
+		h.logger.Error("[create_person_handler] Failed to create person", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, personToResponse(person), http.StatusCreated)
+}
+
+func (h *Handler) GetPerson(w http.ResponseWriter, r *http.Request) {
+	person, err := h.peopleService.GetPerson(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.logger.Error("[get_person_handler] Failed to get person", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, personToResponse(person), http.StatusOK)
+}
+
+func (h *Handler) GetPersonMovies(w http.ResponseWriter, r *http.Request) {
+	personID := chi.URLParam(r, "id")
+
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	credited, total, err := h.peopleService.GetPersonMovies(r.Context(), personID, r.URL.Query().Get("role"), limit, offset)
+	if err != nil {
+		h.logger.Error("[get_person_movies_handler] Failed to get person movies", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := &PersonMoviesResponse{
+		Movies:  make([]CreditedMovieResponse, len(credited)),
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: offset+limit < int(total),
+	}
+	for i, c := range credited {
+		resp.Movies[i] = CreditedMovieResponse{
+			ID:          string(c.Movie.ID),
+			Title:       c.Movie.Title,
+			ReleaseYear: c.Movie.ReleaseYear,
+			Genre:       c.Movie.Genre,
+			Role:        string(c.Role),
+			Character:   c.Character,
+		}
+	}
+
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
+
+func (h *Handler) GetMovieCredits(w http.ResponseWriter, r *http.Request) {
+	credits, err := h.peopleService.GetMovieCredits(r.Context(), chi.URLParam(r, "movieId"))
+	if err != nil {
+		h.logger.Error("[get_movie_credits_handler] Failed to get credits", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := &MovieCreditsResponse{Credits: make([]CreditResponse, len(credits))}
+	for i, c := range credits {
+		resp.Credits[i] = creditToResponse(c)
+	}
+
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
+
+func (h *Handler) AddCredit(w http.ResponseWriter, r *http.Request) {
+	var req AddCreditRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	credit, err := h.peopleService.AddCredit(r.Context(), chi.URLParam(r, "movieId"), req.toService())
+	if err != nil {
+		h.logger.Error("[add_credit_handler] Failed to add credit", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, creditToResponse(credit), http.StatusCreated)
+}
+
+func (h *Handler) RemoveCredit(w http.ResponseWriter, r *http.Request) {
+	err := h.peopleService.RemoveCredit(r.Context(), chi.URLParam(r, "movieId"), chi.URLParam(r, "personId"), chi.URLParam(r, "role"))
+	if err != nil {
+		h.logger.Error("[remove_credit_handler] Failed to remove credit", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	type successResponse struct {
+		Message string `json:"message"`
+	}
+
+	h.responseWriter.WriteSuccess(w, successResponse{Message: "Credit deleted successfully"}, http.StatusOK)
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func parsePagination(r *http.Request) (int, int, error) {
+	limit, offset := DefaultLimit, 0
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > MaxLimit {
+			return 0, 0, errors.New("limit must be between 1 and 100")
+		}
+		limit = parsed
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			return 0, 0, errors.New("offset must be non-negative")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+func (r CreatePersonRequest) toService() peopleService.CreatePersonRequest {
+	return peopleService.CreatePersonRequest{Name: r.Name}
+}
+
+func (r AddCreditRequest) toService() peopleService.AddCreditRequest {
+	return peopleService.AddCreditRequest{
+		PersonID:     r.PersonID,
+		Role:         r.Role,
+		Character:    r.Character,
+		BillingOrder: r.BillingOrder,
+	}
+}
+
+func personToResponse(p *people.Person) PersonResponse {
+	return PersonResponse{
+		ID:        string(p.ID),
+		Name:      p.Name,
+		CreatedAt: p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: p.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func creditToResponse(c *people.Credit) CreditResponse {
+	return CreditResponse{
+		MovieID:      string(c.MovieID),
+		PersonID:     string(c.PersonID),
+		PersonName:   c.PersonName,
+		Role:         string(c.Role),
+		Character:    c.Character,
+		BillingOrder: c.BillingOrder,
+	}
+}
@@ -0,0 +1,176 @@
+package people
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	peopleService "thermondo/internal/platform/service/people"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const peopleTestSecret = "people-test-secret"
+
+func setupRouter(service *MockPeopleService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithAuthentication(tokens.FromSecret(peopleTestSecret), nil),
+	).RegisterRoutes(router)
+	return router
+}
+
+// roleRequest is a request carrying the bearer token of a caller with role
+func roleRequest(t *testing.T, method, target, role string, body io.Reader) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": role + "-1",
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(peopleTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func createRequestBody(v interface{}) io.Reader {
+	jsonData, _ := json.Marshal(v)
+	return bytes.NewReader(jsonData)
+}
+
+func TestCreatePerson(t *testing.T) {
+	t.Run("admin creates a person", func(t *testing.T) {
+		service := new(MockPeopleService)
+		service.On("CreatePerson", mock.Anything, peopleService.CreatePersonRequest{Name: "Hans Zimmer"}).
+			Return(&people.Person{ID: "person-1", Name: "Hans Zimmer", CreatedAt: time.Now(), UpdatedAt: time.Now()}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, roleRequest(t, http.MethodPost, "/people", "admin", createRequestBody(map[string]string{"name": "Hans Zimmer"})))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var resp PersonResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "person-1", resp.ID)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("anonymous caller is 401", func(t *testing.T) {
+		service := new(MockPeopleService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/people", createRequestBody(map[string]string{"name": "Hans Zimmer"})))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Empty(t, service.Calls)
+	})
+
+	t.Run("non-admin is 403", func(t *testing.T) {
+		service := new(MockPeopleService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, roleRequest(t, http.MethodPost, "/people", "user", createRequestBody(map[string]string{"name": "Hans Zimmer"})))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		assert.Empty(t, service.Calls)
+	})
+}
+
+func TestGetPersonMovies(t *testing.T) {
+	t.Run("returns a page of credited movies", func(t *testing.T) {
+		service := new(MockPeopleService)
+		service.On("GetPersonMovies", mock.Anything, "person-1", "composer", 1, 0).Return([]*people.CreditedMovie{
+			{Movie: &movies.Movie{ID: "movie-1", Title: "Dune", ReleaseYear: 2021, Genre: "Sci-Fi"}, Role: people.RoleComposer},
+		}, int64(2), nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/people/person-1/movies?role=composer&limit=1", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp PersonMoviesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(2), resp.Total)
+		assert.True(t, resp.HasMore)
+		assert.Equal(t, CreditedMovieResponse{ID: "movie-1", Title: "Dune", ReleaseYear: 2021, Genre: "Sci-Fi", Role: "composer"}, resp.Movies[0])
+	})
+
+	t.Run("rejects an invalid limit", func(t *testing.T) {
+		service := new(MockPeopleService)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/people/person-1/movies?limit=0", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "GetPersonMovies", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown person is 404", func(t *testing.T) {
+		service := new(MockPeopleService)
+		service.On("GetPersonMovies", mock.Anything, "missing", "", DefaultLimit, 0).Return(nil, int64(0), appErrors.NewNotFoundError("Person not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/people/missing/movies", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestMovieCredits(t *testing.T) {
+	t.Run("POST adds a credit", func(t *testing.T) {
+		service := new(MockPeopleService)
+		req := AddCreditRequest{PersonID: "person-1", Role: "actor", Character: "Paul Atreides"}
+		service.On("AddCredit", mock.Anything, "movie-1", peopleService.AddCreditRequest{PersonID: "person-1", Role: "actor", Character: "Paul Atreides"}).Return(&people.Credit{
+			MovieID: "movie-1", PersonID: "person-1", PersonName: "Timothée Chalamet", Role: people.RoleActor, Character: "Paul Atreides",
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/movies/movie-1/credits", createRequestBody(req)))
+
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var resp CreditResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "Timothée Chalamet", resp.PersonName)
+		assert.Equal(t, "Paul Atreides", resp.Character)
+	})
+
+	t.Run("GET lists credits", func(t *testing.T) {
+		service := new(MockPeopleService)
+		service.On("GetMovieCredits", mock.Anything, "movie-1").Return([]*people.Credit{
+			{MovieID: "movie-1", PersonID: "person-2", PersonName: "Denis Villeneuve", Role: people.RoleDirector},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/credits", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp MovieCreditsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Credits, 1)
+		assert.Equal(t, "director", resp.Credits[0].Role)
+	})
+
+	t.Run("DELETE removes a credit", func(t *testing.T) {
+		service := new(MockPeopleService)
+		service.On("RemoveCredit", mock.Anything, "movie-1", "person-1", "actor").Return(nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/movies/movie-1/credits/person-1/actor", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		service.AssertExpectations(t)
+	})
+}
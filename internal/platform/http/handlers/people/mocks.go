@@ -0,0 +1,60 @@
+package people
+
+import (
+	"context"
+	"thermondo/internal/domain/people"
+
+	peopleService "thermondo/internal/platform/service/people"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPeopleService is a mock implementation of the people.Service interface
+type MockPeopleService struct {
+	mock.Mock
+}
+
+func (m *MockPeopleService) CreatePerson(ctx context.Context, req peopleService.CreatePersonRequest) (*people.Person, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *MockPeopleService) GetPerson(ctx context.Context, id string) (*people.Person, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *MockPeopleService) GetPersonMovies(ctx context.Context, personID, role string, limit, offset int) ([]*people.CreditedMovie, int64, error) {
+	args := m.Called(ctx, personID, role, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*people.CreditedMovie), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockPeopleService) GetMovieCredits(ctx context.Context, movieID string) ([]*people.Credit, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*people.Credit), args.Error(1)
+}
+
+func (m *MockPeopleService) AddCredit(ctx context.Context, movieID string, req peopleService.AddCreditRequest) (*people.Credit, error) {
+	args := m.Called(ctx, movieID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Credit), args.Error(1)
+}
+
+func (m *MockPeopleService) RemoveCredit(ctx context.Context, movieID, personID, role string) error {
+	args := m.Called(ctx, movieID, personID, role)
+	return args.Error(0)
+}
@@ -0,0 +1,69 @@
+package ratings
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/i18n"
+	ratingService "thermondo/internal/platform/service/rating"
+)
+
+// CompareMovies handles GET /movies:compare?ids=a,b, the side-by-side stats
+// of two movies for the versus view
+func (h *Handler) CompareMovies(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.URL.Query().Get("ids"), ",")
+	if len(ids) != 2 {
+		h.responseWriter.WriteError(w, "ids must be two comma-separated movie IDs", http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.ratingService.CompareMovies(r.Context(), ids[0], ids[1])
+	if err != nil {
+		h.logger.Error("[compare_movies_handler] Failed to compare movies", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &MovieComparisonResponse{
+		Movies: []ComparedMovieResponse{
+			comparedMovieToResponse(comparison.First),
+			comparedMovieToResponse(comparison.Second),
+		},
+		HeadToHead: HeadToHeadResponse{
+			SharedRaters:        comparison.HeadToHead.SharedRaters,
+			PreferFirst:         comparison.HeadToHead.PreferFirst,
+			PreferSecond:        comparison.HeadToHead.PreferSecond,
+			Ties:                comparison.HeadToHead.Ties,
+			PreferFirstPercent:  comparison.HeadToHead.PreferFirstPercent(),
+			PreferSecondPercent: comparison.HeadToHead.PreferSecondPercent(),
+			AverageDifference:   comparison.HeadToHead.AverageDifference,
+		},
+	}
+	for i := range response.Movies {
+		if response.Movies[i].Withheld {
+			response.Movies[i].Notice = i18n.T(r.Context(), rating.NotEnoughRatings)
+		}
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func comparedMovieToResponse(movie ratingService.ComparedMovieStats) ComparedMovieResponse {
+	// Every score is listed so the two histograms line up
+	scoreCount := make(map[string]int64, 5)
+	for score := 1; score <= 5; score++ {
+		scoreCount[strconv.Itoa(score)] = movie.ScoreCount[score]
+	}
+	return ComparedMovieResponse{
+		MovieID:         string(movie.MovieID),
+		Title:           movie.Title,
+		AverageScore:    movie.AverageScore,
+		BayesianAverage: math.Round(movie.BayesianAverage*100) / 100,
+		Confidence:      math.Round(movie.Confidence*100) / 100,
+		Controversy:     movie.Controversy,
+		TotalRatings:    movie.TotalRatings,
+		ScoreCount:      scoreCount,
+		Withheld:        movie.Withheld,
+	}
+}
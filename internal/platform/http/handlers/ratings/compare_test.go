@@ -0,0 +1,81 @@
+package ratings
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareMovies(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := func(service *MockRatingService) *chi.Mux {
+		router := chi.NewRouter()
+		NewHandler(service, logger).RegisterRoutes(router)
+		return router
+	}
+
+	t.Run("returns both movies side by side", func(t *testing.T) {
+		service := new(MockRatingService)
+		service.On("CompareMovies", mock.Anything, "movie-1", "movie-2").Return(&ratingService.MovieComparison{
+			First: ratingService.ComparedMovieStats{
+				ComparedMovie:   &rating.ComparedMovie{MovieID: "movie-1", Title: "Heat", AverageScore: 4.5, TotalRatings: 2, ScoreCount: map[int]int64{4: 1, 5: 1}},
+				BayesianAverage: 3.1111,
+				Confidence:      0.2,
+				Controversy:     0.008,
+			},
+			Second: ratingService.ComparedMovieStats{
+				ComparedMovie:   &rating.ComparedMovie{MovieID: "movie-2", Title: "Collateral", ScoreCount: map[int]int64{}},
+				BayesianAverage: 3.0,
+			},
+			HeadToHead: rating.HeadToHead{SharedRaters: 3, PreferFirst: 1, Ties: 2, AverageDifference: 0.67},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setup(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies:compare?ids=movie-1,movie-2", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response MovieComparisonResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response.Movies, 2)
+		assert.Equal(t, "Heat", response.Movies[0].Title)
+		assert.Equal(t, 3.11, response.Movies[0].BayesianAverage)
+		assert.Equal(t, 0.008, response.Movies[0].Controversy)
+		assert.Equal(t, map[string]int64{"1": 0, "2": 0, "3": 0, "4": 1, "5": 1}, response.Movies[0].ScoreCount)
+		assert.Equal(t, int64(0), response.Movies[1].ScoreCount["5"])
+		assert.Equal(t, HeadToHeadResponse{
+			SharedRaters: 3, PreferFirst: 1, Ties: 2, PreferFirstPercent: 33, PreferSecondPercent: 0, AverageDifference: 0.67,
+		}, response.HeadToHead)
+	})
+
+	t.Run("requires two ids", func(t *testing.T) {
+		service := new(MockRatingService)
+
+		rr := httptest.NewRecorder()
+		setup(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies:compare?ids=movie-1", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		service.AssertNotCalled(t, "CompareMovies", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("passes service errors through", func(t *testing.T) {
+		service := new(MockRatingService)
+		service.On("CompareMovies", mock.Anything, "movie-1", "missing").Return(nil, appErrors.NewNotFoundError("Movie not found"))
+
+		rr := httptest.NewRecorder()
+		setup(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies:compare?ids=movie-1,missing", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
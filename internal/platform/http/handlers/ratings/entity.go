@@ -1,23 +1,66 @@
 package ratings
 
+import "time"
+
+// CreateRatingRequest is the body of POST /ratings. The rating is by the
+// caller, or by the user in the path of the admin route.
+type CreateRatingRequest struct {
+	MovieID               string `json:"movie_id"`
+	Score                 int    `json:"score"`
+	Review                string `json:"review,omitempty"`            // Markdown
+	ContainsSpoilers      bool   `json:"contains_spoilers,omitempty"` // Implied by ||spoiler|| tags in the review
+	ContainsAdultLanguage bool   `json:"contains_adult_language,omitempty"`
+}
+
+// UpdateRatingRequest is the body of PUT /ratings/{id}; members left out
+// keep their value
+type UpdateRatingRequest struct {
+	Score                 *int    `json:"score,omitempty"`
+	Review                *string `json:"review,omitempty"`
+	ContainsSpoilers      *bool   `json:"contains_spoilers,omitempty"`
+	ContainsAdultLanguage *bool   `json:"contains_adult_language,omitempty"`
+}
+
 type CreateRatingResponse struct {
-	ID        string `json:"id"`
-	UserID    string `json:"user_id"`
-	MovieID   string `json:"movie_id"`
-	Score     int    `json:"score"`
-	Review    string `json:"review"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID                    string `json:"id"`
+	UserID                string `json:"user_id"`
+	MovieID               string `json:"movie_id"`
+	Score                 int    `json:"score"`
+	Review                string `json:"review"`
+	ContainsSpoilers      bool   `json:"contains_spoilers"`
+	ContainsAdultLanguage bool   `json:"contains_adult_language"`
+	CreatedAt             string `json:"created_at"`
+	UpdatedAt             string `json:"updated_at"`
 }
 
 type RatingResponse struct {
-	ID        string `json:"id"`
-	UserID    string `json:"user_id"`
-	MovieID   string `json:"movie_id"`
-	Score     int    `json:"score"`
-	Review    string `json:"review"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID                    string  `json:"id"`
+	UserID                string  `json:"user_id"`
+	MovieID               string  `json:"movie_id"`
+	Score                 int     `json:"score"`
+	Review                string  `json:"review"`
+	ReviewHTML            *string `json:"review_html,omitempty"` // Only with ?render=html
+	ContainsSpoilers      bool    `json:"contains_spoilers"`
+	ContainsAdultLanguage bool    `json:"contains_adult_language"`
+	CreatedAt             string  `json:"created_at"`
+	UpdatedAt             string  `json:"updated_at"`
+	Version               int     `json:"version"`
+}
+
+// ReviewSearchResult is a matching rating with the highlighted part of its
+// review
+type ReviewSearchResult struct {
+	RatingResponse
+	Snippet string  `json:"snippet"` // HTML-escaped, matches wrapped in <mark>
+	Rank    float64 `json:"rank"`
+}
+
+type ReviewSearchResponse struct {
+	Results []ReviewSearchResult `json:"results"`
+	Total   int64                `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	HasMore bool                 `json:"has_more"`
 }
 
 type RatingsListResponse struct {
@@ -33,4 +76,204 @@ type MovieStatsResponse struct {
 	AverageScore float64          `json:"average_score"`
 	TotalRatings int64            `json:"total_ratings"`
 	ScoreCount   map[string]int64 `json:"score_count"` // String keys for JSON
+	// SmoothedDistribution is each score's share with a prior of one
+	// rating per score, for histograms of movies with few ratings
+	SmoothedDistribution map[string]float64  `json:"smoothed_distribution"`
+	StandardDeviation    float64             `json:"standard_deviation"`
+	MedianScore          float64             `json:"median_score"`
+	Controversy          float64             `json:"controversy"`
+	Audience             ScoreResponse       `json:"audience_score"`
+	Critics              ScoreResponse       `json:"critic_score"`
+	Volatility           *VolatilityResponse `json:"volatility,omitempty"`
+	Trust                *TrustResponse      `json:"trust_weighting,omitempty"`
+	// Withheld is set when the averages are left out because the movie has
+	// too few ratings, with a Notice to show in their place
+	Withheld bool   `json:"withheld,omitempty"`
+	Notice   string `json:"notice,omitempty"`
+}
+
+// ScoreResponse is the average of one group of raters
+type ScoreResponse struct {
+	AverageScore float64 `json:"average_score"`
+	TotalRatings int64   `json:"total_ratings"`
+}
+
+// TrustResponse is the average with ratings weighted by rater trust
+type TrustResponse struct {
+	WeightedAverage  float64 `json:"weighted_average"`
+	EffectiveRatings float64 `json:"effective_ratings"`
+}
+
+// VolatilityResponse flags a possible review bombing in progress
+type VolatilityResponse struct {
+	Flagged         bool    `json:"flagged"`
+	Direction       string  `json:"direction,omitempty"`
+	Score           int     `json:"score,omitempty"`
+	WindowStart     string  `json:"window_start"`
+	SpikeRatings    int64   `json:"spike_ratings,omitempty"`
+	ExpectedRatings float64 `json:"expected_ratings,omitempty"`
+	Excluded        bool    `json:"excluded"`
+}
+
+// MovieComparisonResponse is two movies side by side; head_to_head's first
+// and second refer to the order of movies
+type MovieComparisonResponse struct {
+	Movies     []ComparedMovieResponse `json:"movies"`
+	HeadToHead HeadToHeadResponse      `json:"head_to_head"`
+}
+
+type ComparedMovieResponse struct {
+	MovieID         string           `json:"movie_id"`
+	Title           string           `json:"title"`
+	AverageScore    float64          `json:"average_score"`
+	BayesianAverage float64          `json:"bayesian_average"`
+	Confidence      float64          `json:"confidence"`
+	Controversy     float64          `json:"controversy"` // 0 when raters agree, 1 when split between 1 and 5
+	TotalRatings    int64            `json:"total_ratings"`
+	ScoreCount      map[string]int64 `json:"score_count"` // Every score from 1 to 5
+	// Withheld and Notice are as in MovieStatsResponse
+	Withheld bool   `json:"withheld,omitempty"`
+	Notice   string `json:"notice,omitempty"`
+}
+
+// HeadToHeadResponse is how the users who rated both movies scored them;
+// the percentages are of shared raters, as in "67% of people who rated
+// both prefer the first"
+type HeadToHeadResponse struct {
+	SharedRaters        int64   `json:"shared_raters"`
+	PreferFirst         int64   `json:"prefer_first"`
+	PreferSecond        int64   `json:"prefer_second"`
+	Ties                int64   `json:"ties"`
+	PreferFirstPercent  int     `json:"prefer_first_percent"`
+	PreferSecondPercent int     `json:"prefer_second_percent"`
+	AverageDifference   float64 `json:"average_difference"` // First minus second
+}
+
+// StatsHistoryResponse is a movie's stats snapshots, oldest first
+type StatsHistoryResponse struct {
+	MovieID   string                  `json:"movie_id"`
+	Snapshots []StatsSnapshotResponse `json:"snapshots"`
+}
+
+type StatsSnapshotResponse struct {
+	Date            string  `json:"date"`
+	AverageScore    float64 `json:"average_score"`
+	BayesianAverage float64 `json:"bayesian_average"`
+	TotalRatings    int64   `json:"total_ratings"`
+	Withheld        bool    `json:"withheld,omitempty"` // Too few ratings that day to show the averages
+}
+
+// HistoryEntryResponse is one rating of an export, in the layout an import
+// reads back
+type HistoryEntryResponse struct {
+	MovieID          string     `json:"movie_id,omitempty"`
+	IMDbID           string     `json:"imdb_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Year             int        `json:"year,omitempty"`
+	Score            int        `json:"score"`
+	Review           string     `json:"review,omitempty"`
+	ContainsSpoilers bool       `json:"contains_spoilers,omitempty"`
+	RatedAt          time.Time  `json:"rated_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+}
+
+// TitleResolutionRequest is an entry of the resolutions form field of an
+// import
+type TitleResolutionRequest struct {
+	Title   string `json:"title"`
+	Year    int    `json:"year,omitempty"`
+	MovieID string `json:"movie_id"`
+}
+
+type MovieCandidateResponse struct {
+	MovieID    string  `json:"movie_id"`
+	Title      string  `json:"title"`
+	Year       int     `json:"year"`
+	IMDbID     *string `json:"imdb_id,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// HistoryMatchResponse is how an entry of an import was matched
+type HistoryMatchResponse struct {
+	Line       int                      `json:"line"`
+	Entry      *HistoryEntryResponse    `json:"entry"`
+	Status     string                   `json:"status"`
+	Movie      *MovieCandidateResponse  `json:"movie,omitempty"`
+	Candidates []MovieCandidateResponse `json:"candidates,omitempty"`
+	Reason     string                   `json:"reason,omitempty"`
+}
+
+type UnresolvedTitleResponse struct {
+	Title      string                   `json:"title"`
+	Year       int                      `json:"year,omitempty"`
+	Lines      []int                    `json:"lines"`
+	Candidates []MovieCandidateResponse `json:"candidates"`
+}
+
+// HistoryPreviewResponse is what an import would do
+type HistoryPreviewResponse struct {
+	Entries    int                        `json:"entries"`
+	Matched    int                        `json:"matched"`
+	Fuzzy      int                        `json:"fuzzy"`
+	Unmatched  int                        `json:"unmatched"`
+	Invalid    int                        `json:"invalid"`
+	Matches    []*HistoryMatchResponse    `json:"matches"`
+	Unresolved []*UnresolvedTitleResponse `json:"unresolved"`
+}
+
+// HistoryImportResponse is what an import did
+type HistoryImportResponse struct {
+	Imported    int                     `json:"imported"`
+	Duplicates  int                     `json:"duplicates"`
+	Skipped     int                     `json:"skipped"`
+	NotImported []*HistoryMatchResponse `json:"not_imported"`
+}
+
+// ScoreExplanationResponse is why a movie has its score: the Bayesian
+// average with the numbers it is computed from, exact rather than rounded
+// so clients can redo the computation
+type ScoreExplanationResponse struct {
+	MovieID         string  `json:"movie_id"`
+	BayesianAverage float64 `json:"bayesian_average"`
+	Confidence      float64 `json:"confidence"`
+	Percentile      float64 `json:"percentile"`
+	Explanation     string  `json:"explanation"`
+	Formula         string  `json:"formula"`
+	// WilsonLowerBound is the Wilson score interval's lower bound of the
+	// same votes, which top lists may rank by instead
+	WilsonLowerBound float64                  `json:"wilson_lower_bound"`
+	Inputs           ScoreInputsResponse      `json:"inputs"`
+	Weights          ScoreWeightsResponse     `json:"weights"`
+	Adjustments      ScoreAdjustmentsResponse `json:"adjustments"`
+	Volatility       *VolatilityResponse      `json:"volatility,omitempty"`
+	// Withheld is set when the movie has too few ratings for a score; the
+	// explanation then says so
+	Withheld bool `json:"withheld,omitempty"`
+}
+
+// ScoreInputsResponse are the numbers in the formula, and the number of
+// ratings for full confidence
+type ScoreInputsResponse struct {
+	ConfidenceK           float64 `json:"confidence_k"`
+	GlobalAverage         float64 `json:"global_average"`
+	GlobalAverageLoadedAt string  `json:"global_average_loaded_at,omitempty"`
+	MovieAverage          float64 `json:"movie_average"`
+	Votes                 float64 `json:"votes"`
+	MinVotes              int64   `json:"min_votes"`
+}
+
+// ScoreWeightsResponse are the shares of the global and the movie's
+// average in the score, summing to 1
+type ScoreWeightsResponse struct {
+	GlobalAverage float64 `json:"global_average"`
+	MovieAverage  float64 `json:"movie_average"`
+}
+
+// ScoreAdjustmentsResponse is how the movie's ratings became the average
+// and votes of the inputs
+type ScoreAdjustmentsResponse struct {
+	RawAverage    float64 `json:"raw_average"`
+	RawRatings    int64   `json:"raw_ratings"`
+	TrustWeighted bool    `json:"trust_weighted"`
+	ExcludedVotes float64 `json:"excluded_votes"`
 }
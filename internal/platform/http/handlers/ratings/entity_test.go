@@ -0,0 +1,27 @@
+package ratings
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		CreateRatingRequest{},
+		UpdateRatingRequest{},
+		TitleResolutionRequest{},
+		CreateRatingResponse{},
+		RatingResponse{},
+		RatingsListResponse{},
+		ReviewSearchResponse{},
+		MovieStatsResponse{},
+		StatsHistoryResponse{},
+		MovieComparisonResponse{},
+		[]*HistoryEntryResponse{},
+		HistoryPreviewResponse{},
+		HistoryImportResponse{},
+	))
+}
@@ -0,0 +1,37 @@
+package ratings
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errInvalidIfMatch = errors.New(`If-Match must be "*" or a single ETag returned by this API`)
+
+// ratingETag renders a rating version as a strong entity tag
+func ratingETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch returns the version the client expects from the If-Match
+// header, or nil when the header is absent or "*". Weak tags are accepted
+// because the version fully identifies the representation.
+func parseIfMatch(r *http.Request) (*int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return nil, errInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return nil, errInvalidIfMatch
+	}
+
+	return &version, nil
+}
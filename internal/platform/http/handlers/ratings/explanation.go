@@ -0,0 +1,63 @@
+package ratings
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ScoreFormula is how the Bayesian average is computed from the inputs of
+// a score explanation
+const ScoreFormula = "(confidence_k * global_average + movie_average * votes) / (confidence_k + votes)"
+
+// GetScoreExplanation handles GET /movies/{movieId}/stats/explanation, the
+// movie's Bayesian average with every input and intermediate number, for
+// showing why a movie has its score
+func (h *Handler) GetScoreExplanation(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "movieId")
+
+	stats, err := h.ratingService.GetEnhancedMovieStats(r.Context(), movieID)
+	if err != nil {
+		h.logger.Error("[get_score_explanation_handler] Failed to get enhanced stats", "error", err, "movie_id", movieID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	b := stats.Breakdown
+	response := ScoreExplanationResponse{
+		MovieID:          movieID,
+		BayesianAverage:  stats.BayesianAverage,
+		Confidence:       stats.Confidence,
+		Percentile:       stats.Percentile,
+		Explanation:      stats.Explanation,
+		Formula:          ScoreFormula,
+		WilsonLowerBound: stats.WilsonLowerBound,
+		Withheld:         stats.Withheld,
+		Inputs: ScoreInputsResponse{
+			ConfidenceK:   b.ConfidenceK,
+			GlobalAverage: b.GlobalAverage,
+			MovieAverage:  b.MovieAverage,
+			Votes:         b.Votes,
+			MinVotes:      b.MinVotes,
+		},
+		Weights: ScoreWeightsResponse{
+			GlobalAverage: b.PriorWeight,
+			MovieAverage:  b.MovieWeight,
+		},
+		Adjustments: ScoreAdjustmentsResponse{
+			RawAverage:    b.RawAverage,
+			RawRatings:    b.RawRatings,
+			TrustWeighted: b.TrustWeighted,
+			ExcludedVotes: b.ExcludedVotes,
+		},
+	}
+	if !b.GlobalAverageLoadedAt.IsZero() {
+		response.Inputs.GlobalAverageLoadedAt = b.GlobalAverageLoadedAt.Format(time.RFC3339)
+	}
+	if stats.Volatility != nil {
+		response.Volatility = volatilityToResponse(stats.Volatility)
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
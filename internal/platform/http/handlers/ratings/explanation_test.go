@@ -0,0 +1,77 @@
+package ratings
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetScoreExplanation(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := func(service *MockRatingService) *chi.Mux {
+		router := chi.NewRouter()
+		NewHandler(service, logger).RegisterRoutes(router)
+		return router
+	}
+
+	t.Run("breaks the score down", func(t *testing.T) {
+		service := new(MockRatingService)
+		service.On("GetEnhancedMovieStats", mock.Anything, "movie-1").Return(&ratingService.EnhancedMovieStats{
+			MovieRatingStats: &rating.MovieRatingStats{
+				MovieID: "movie-1", AverageScore: 2.5, TotalRatings: 40,
+				Volatility: &rating.Volatility{Flagged: true, Direction: rating.VolatilityNegative, Score: 1, SpikeRatings: 20, Excluded: true},
+			},
+			BayesianAverage:  155.0 / 45.0,
+			Confidence:       1,
+			Percentile:       50,
+			Explanation:      "Reliable rating based on 40 user ratings.",
+			WilsonLowerBound: 3.31,
+			Breakdown: ratingService.ScoreBreakdown{
+				ConfidenceK: 25, GlobalAverage: 3, GlobalAverageLoadedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+				MovieAverage: 4, Votes: 20, ExcludedVotes: 20, RawAverage: 2.5, RawRatings: 40,
+				PriorWeight: 25.0 / 45.0, MovieWeight: 20.0 / 45.0, BayesianAverage: 155.0 / 45.0, MinVotes: 10,
+			},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setup(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/stats/explanation", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response ScoreExplanationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "movie-1", response.MovieID)
+		assert.Equal(t, ScoreFormula, response.Formula)
+		assert.Equal(t, 3.31, response.WilsonLowerBound)
+		assert.Equal(t, ScoreInputsResponse{
+			ConfidenceK: 25, GlobalAverage: 3, GlobalAverageLoadedAt: "2024-06-01T12:00:00Z", MovieAverage: 4, Votes: 20, MinVotes: 10,
+		}, response.Inputs)
+		assert.Equal(t, ScoreWeightsResponse{GlobalAverage: 25.0 / 45.0, MovieAverage: 20.0 / 45.0}, response.Weights)
+		assert.Equal(t, ScoreAdjustmentsResponse{RawAverage: 2.5, RawRatings: 40, ExcludedVotes: 20}, response.Adjustments)
+		require.NotNil(t, response.Volatility)
+		assert.True(t, response.Volatility.Excluded)
+		assert.Equal(t, "Reliable rating based on 40 user ratings.", response.Explanation)
+	})
+
+	t.Run("passes service errors through", func(t *testing.T) {
+		service := new(MockRatingService)
+		service.On("GetEnhancedMovieStats", mock.Anything, "movie-1").Return(nil, appErrors.NewInternalError("Failed to get movie stats"))
+
+		rr := httptest.NewRecorder()
+		setup(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/stats/explanation", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
@@ -1,13 +1,20 @@
 package ratings
 
 import (
-	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/http/request"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/i18n"
+	"thermondo/internal/pkg/markdown"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
 	ratingService "thermondo/internal/platform/service/rating"
 	"time"
 
@@ -17,28 +24,112 @@ import (
 )
 
 type Handler struct {
-	ratingService  ratingService.Service
-	responseWriter *response.Writer
-	logger         *slog.Logger
+	ratingService   ratingService.Service
+	history         ratingService.HistoryService
+	statsHistory    ratingService.StatsHistoryService
+	maxHistoryBytes int64
+	responseWriter  *response.Writer
+	auth            *middleware.AuthMiddleware
+	keys            *tokens.Keys
+	sessions        middleware.SessionValidator
+	logger          *slog.Logger
+	// cached wraps the hot movie stats
+	cached []func(http.Handler) http.Handler
 }
 
-func NewHandler(ratingService ratingService.Service, logger *slog.Logger) *Handler {
-	return &Handler{
-		ratingService:  ratingService,
-		responseWriter: response.NewWriter(logger),
-		logger:         logger,
+// Option configures optional behaviour of the rating handler
+type Option func(*Handler)
+
+// WithAuthentication verifies bearer tokens on the routes that need a
+// caller, rejecting tokens whose session has been revoked
+func WithAuthentication(keys *tokens.Keys, sessions middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.keys = keys
+		h.sessions = sessions
+	}
+}
+
+// WithHistory enables exporting and importing a user's ratings under
+// /users/{userId}/ratings. It needs WithAuthentication.
+func WithHistory(service ratingService.HistoryService) Option {
+	return func(h *Handler) {
+		h.history = service
+	}
+}
+
+// WithStatsHistory serves the daily stats snapshots of movies under
+// /movies/{movieId}/stats/history
+func WithStatsHistory(service ratingService.StatsHistoryService) Option {
+	return func(h *Handler) {
+		h.statsHistory = service
 	}
 }
 
+// WithResponseCache serves GET /movies/{movieId}/stats through the given
+// response cache middleware
+func WithResponseCache(middleware func(http.Handler) http.Handler) Option {
+	return func(h *Handler) {
+		h.cached = append(h.cached, middleware)
+	}
+}
+
+// WithMaxHistoryBytes sets the largest import file accepted in a
+// multipart/form-data body
+func WithMaxHistoryBytes(n int64) Option {
+	return func(h *Handler) {
+		h.maxHistoryBytes = n
+	}
+}
+
+func NewHandler(ratingService ratingService.Service, logger *slog.Logger, opts ...Option) *Handler {
+	h := &Handler{
+		ratingService:   ratingService,
+		maxHistoryBytes: DefaultMaxHistoryBytes,
+		responseWriter:  response.NewWriter(logger),
+		logger:          logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.keys != nil {
+		var authOptions []middleware.AuthOption
+		if h.sessions != nil {
+			authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+		}
+		h.auth = middleware.NewAuthMiddleware(h.keys, h.responseWriter, authOptions...)
+	}
+	return h
+}
+
+// CreateRating handles POST /ratings, rating a movie as the caller
 func (h *Handler) CreateRating(w http.ResponseWriter, r *http.Request) {
-	var req ratingService.CreateRatingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to decode request", "error", err)
-		h.responseWriter.WriteError(w, "Invalid JSON payload", http.StatusBadRequest)
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	h.createRating(w, r, principal.UserID)
+}
+
+// CreateRatingForUser handles POST /admin/users/{userId}/ratings, letting
+// support tooling rate on behalf of a user
+func (h *Handler) CreateRatingForUser(w http.ResponseWriter, r *http.Request) {
+	principal, _ := middleware.PrincipalFrom(r.Context())
+	userID := chi.URLParam(r, "userId")
+	h.logger.Info("[create_rating_for_user_handler] Rating on behalf of user", "admin_id", principal.UserID, "user_id", userID)
+	h.createRating(w, r, userID)
+}
+
+func (h *Handler) createRating(w http.ResponseWriter, r *http.Request, userID string) {
+	var req CreateRatingRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("Failed to decode request", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
-	rating, err := h.ratingService.CreateRating(r.Context(), req)
+	rating, err := h.ratingService.CreateRating(r.Context(), req.toService(userID))
 	if err != nil {
 		h.logger.Error("Failed to create rating", "error", err)
 		h.handleServiceError(w, err)
@@ -58,6 +149,12 @@ func (h *Handler) GetRatingByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	renderHTML, err := parseRender(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	rating, err := h.ratingService.GetRatingByID(r.Context(), ratingID)
 	if err != nil {
 		h.logger.Error("Failed to get rating by ID", "error", err)
@@ -66,11 +163,22 @@ func (h *Handler) GetRatingByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := h.ratingToResponse(rating)
+	if renderHTML {
+		response.ReviewHTML = reviewHTML(rating.Review)
+	}
+	w.Header().Set("ETag", ratingETag(rating.Version))
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
 
-// UpdateRating handles PUT /ratings/{id}
+// UpdateRating handles PUT /ratings/{id}. An If-Match header carrying the
+// ETag from a previous read makes the update conditional: it fails with 412
+// and the current rating when someone else updated it in between.
 func (h *Handler) UpdateRating(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
 	ratingID := chi.URLParam(r, "id")
 	if ratingID == "" {
 		h.logger.Error("Rating ID is required", "error", errors.New("rating ID is required"))
@@ -78,26 +186,52 @@ func (h *Handler) UpdateRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req ratingService.UpdateRatingRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to decode request", "error", err)
-		h.responseWriter.WriteError(w, "Invalid JSON payload", http.StatusBadRequest)
+	var req UpdateRatingRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("Failed to decode request", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
-	rating, err := h.ratingService.UpdateRating(r.Context(), ratingID, req)
+	expectedVersion, err := parseIfMatch(r)
 	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rating, err := h.ratingService.UpdateRating(r.Context(), ratingID, principal, req.toService(expectedVersion))
+	if err != nil {
+		var conflictErr *ratingService.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			h.writeVersionConflict(w, r, conflictErr)
+			return
+		}
 		h.logger.Error("Failed to update rating", "error", err)
 		h.handleServiceError(w, err)
 		return
 	}
 
 	response := h.ratingToResponse(rating)
+	w.Header().Set("ETag", ratingETag(rating.Version))
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
 
+// writeVersionConflict responds 412 (or 409 when no If-Match was sent) with
+// the stored rating under extra.current and its ETag
+func (h *Handler) writeVersionConflict(w http.ResponseWriter, r *http.Request, conflictErr *ratingService.VersionConflictError) {
+	w.Header().Set("ETag", ratingETag(conflictErr.Current.Version))
+	problem := response.NewProblem(conflictErr.StatusCode, appErrors.ErrorCode(conflictErr.Code), conflictErr.Message).
+		WithExtra("current", h.ratingToResponse(conflictErr.Current))
+	h.responseWriter.WriteProblem(w, r, problem)
+}
+
 // DeleteRating handles DELETE /ratings/{id}
 func (h *Handler) DeleteRating(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
 	ratingID := chi.URLParam(r, "id")
 	if ratingID == "" {
 		h.logger.Error("Rating ID is required", "error", errors.New("rating ID is required"))
@@ -105,7 +239,7 @@ func (h *Handler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.ratingService.DeleteRating(r.Context(), ratingID)
+	err := h.ratingService.DeleteRating(r.Context(), ratingID, principal)
 	if err != nil {
 		h.logger.Error("Failed to delete rating", "error", err)
 		h.handleServiceError(w, err)
@@ -119,6 +253,33 @@ func (h *Handler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 	h.responseWriter.WriteSuccess(w, successResponse{Message: "Rating deleted successfully"}, http.StatusOK)
 }
 
+// RestoreRating handles POST /ratings/{id}/restore. A deleted rating can be
+// restored until the restore window has passed, and only while its author
+// has not rated the movie again.
+func (h *Handler) RestoreRating(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	ratingID := chi.URLParam(r, "id")
+	if ratingID == "" {
+		h.logger.Error("Rating ID is required", "error", errors.New("rating ID is required"))
+		h.responseWriter.WriteError(w, "Rating ID is required", http.StatusBadRequest)
+		return
+	}
+
+	rating, err := h.ratingService.RestoreRating(r.Context(), ratingID, principal)
+	if err != nil {
+		h.logger.Error("Failed to restore rating", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", ratingETag(rating.Version))
+	h.responseWriter.WriteSuccess(w, h.ratingToResponse(rating), http.StatusOK)
+}
+
 // GetMovieRatings handles GET /movies/{movieId}/ratings
 func (h *Handler) GetMovieRatings(w http.ResponseWriter, r *http.Request) {
 	movieID := chi.URLParam(r, "movieId")
@@ -136,8 +297,29 @@ func (h *Handler) GetMovieRatings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	reviewer, err := rating.ParseReviewerType(r.URL.Query().Get("reviewer"))
+	if err != nil {
+		h.logger.Error("Invalid reviewer", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spoilers, err := rating.ParseSpoilerFilter(r.URL.Query().Get("spoilers"))
+	if err != nil {
+		h.logger.Error("Invalid spoiler filter", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	renderHTML, err := parseRender(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter := ratingService.MovieRatingsFilter{Reviewer: reviewer, Spoilers: spoilers}
 	ratingsList, total, err := h.ratingService.GetMovieRatings(
-		r.Context(), movieID, params.Limit, params.Offset, params.SortBy, params.Order,
+		r.Context(), movieID, params.Limit, params.Offset, params.SortBy, params.Order, filter,
 	)
 	if err != nil {
 		h.logger.Error("Failed to get movie ratings", "error", err)
@@ -146,7 +328,7 @@ func (h *Handler) GetMovieRatings(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := &RatingsListResponse{
-		Ratings: h.ratingsToResponse(ratingsList),
+		Ratings: h.ratingsToResponse(ratingsList, renderHTML),
 		Total:   total,
 		Limit:   params.Limit,
 		Offset:  params.Offset,
@@ -172,7 +354,12 @@ func (h *Handler) GetMovieStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Rating changes clear the cached stats of their movie by this tag
+	middleware.TagResponse(r.Context(), cache.MovieTag(movieID))
 	response := h.statsToResponse(stats)
+	if response.Withheld {
+		response.Notice = i18n.T(r.Context(), rating.NotEnoughRatings)
+	}
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
 
@@ -192,6 +379,12 @@ func (h *Handler) GetUserRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	renderHTML, err := parseRender(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	rating, err := h.ratingService.GetUserRating(r.Context(), userID, movieID)
 	if err != nil {
 		h.logger.Error("Failed to get user rating", "error", err)
@@ -200,6 +393,9 @@ func (h *Handler) GetUserRating(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := h.ratingToResponse(rating)
+	if renderHTML {
+		response.ReviewHTML = reviewHTML(rating.Review)
+	}
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
 
@@ -260,6 +456,27 @@ func (h *Handler) parseListParams(r *http.Request) (*listParams, error) {
 	return params, nil
 }
 
+// parseRender reads the render query parameter. Reviews are markdown; with
+// render=html responses also carry the sanitized HTML.
+func parseRender(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("render") {
+	case "":
+		return false, nil
+	case "html":
+		return true, nil
+	default:
+		return false, errors.New("render must be 'html'")
+	}
+}
+
+func reviewHTML(review string) *string {
+	if review == "" {
+		return nil
+	}
+	rendered := markdown.Render(review)
+	return &rendered
+}
+
 func (h *Handler) isValidSortField(field string) bool {
 	validFields := map[string]bool{
 		"created_at": true,
@@ -269,36 +486,66 @@ func (h *Handler) isValidSortField(field string) bool {
 	return validFields[field]
 }
 
+func (r CreateRatingRequest) toService(userID string) ratingService.CreateRatingRequest {
+	return ratingService.CreateRatingRequest{
+		UserID:                userID,
+		MovieID:               r.MovieID,
+		Score:                 r.Score,
+		Review:                r.Review,
+		ContainsSpoilers:      r.ContainsSpoilers,
+		ContainsAdultLanguage: r.ContainsAdultLanguage,
+	}
+}
+
+// toService maps the body, with the version from If-Match, if any
+func (r UpdateRatingRequest) toService(expectedVersion *int) ratingService.UpdateRatingRequest {
+	return ratingService.UpdateRatingRequest{
+		Score:                 r.Score,
+		Review:                r.Review,
+		ContainsSpoilers:      r.ContainsSpoilers,
+		ContainsAdultLanguage: r.ContainsAdultLanguage,
+		ExpectedVersion:       expectedVersion,
+	}
+}
+
 // Response transformation methods
-func (h *Handler) ratingsToResponse(ratingsList []*rating.Rating) []RatingResponse {
+func (h *Handler) ratingsToResponse(ratingsList []*rating.Rating, renderHTML bool) []RatingResponse {
 	responses := make([]RatingResponse, len(ratingsList))
 	for i, rating := range ratingsList {
 		responses[i] = h.ratingToResponse(rating)
+		if renderHTML {
+			responses[i].ReviewHTML = reviewHTML(rating.Review)
+		}
 	}
 	return responses
 }
 
 func (h *Handler) ratingToResponse(rating *rating.Rating) RatingResponse {
 	return RatingResponse{
-		ID:        string(rating.ID),
-		UserID:    string(rating.UserID),
-		MovieID:   string(rating.MovieID),
-		Score:     rating.Score,
-		Review:    rating.Review,
-		CreatedAt: rating.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: rating.UpdatedAt.Format(time.RFC3339),
+		ID:                    string(rating.ID),
+		UserID:                string(rating.UserID),
+		MovieID:               string(rating.MovieID),
+		Score:                 rating.Score,
+		Review:                rating.Review,
+		ContainsSpoilers:      rating.ContainsSpoilers,
+		ContainsAdultLanguage: rating.ContainsAdultLanguage,
+		CreatedAt:             rating.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             rating.UpdatedAt.Format(time.RFC3339),
+		Version:               rating.Version,
 	}
 }
 
 func (h *Handler) ratingToCreateResponse(rating *rating.Rating) CreateRatingResponse {
 	return CreateRatingResponse{
-		ID:        string(rating.ID),
-		UserID:    string(rating.UserID),
-		MovieID:   string(rating.MovieID),
-		Score:     rating.Score,
-		Review:    rating.Review,
-		CreatedAt: rating.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: rating.UpdatedAt.Format(time.RFC3339),
+		ID:                    string(rating.ID),
+		UserID:                string(rating.UserID),
+		MovieID:               string(rating.MovieID),
+		Score:                 rating.Score,
+		Review:                rating.Review,
+		ContainsSpoilers:      rating.ContainsSpoilers,
+		ContainsAdultLanguage: rating.ContainsAdultLanguage,
+		CreatedAt:             rating.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             rating.UpdatedAt.Format(time.RFC3339),
 	}
 }
 
@@ -308,12 +555,51 @@ func (h *Handler) statsToResponse(stats *rating.MovieRatingStats) MovieStatsResp
 	for score, count := range stats.ScoreCount {
 		scoreCount[strconv.Itoa(score)] = count
 	}
+	smoothed := make(map[string]float64)
+	for score, share := range stats.SmoothedDistribution {
+		smoothed[strconv.Itoa(score)] = math.Round(share*10000) / 10000
+	}
+
+	response := MovieStatsResponse{
+		MovieID:              string(stats.MovieID),
+		AverageScore:         stats.AverageScore,
+		TotalRatings:         stats.TotalRatings,
+		ScoreCount:           scoreCount,
+		SmoothedDistribution: smoothed,
+		StandardDeviation:    stats.StandardDeviation,
+		MedianScore:          stats.MedianScore,
+		Controversy:          stats.Controversy,
+		Withheld:             stats.Withheld,
+		Audience: ScoreResponse{
+			AverageScore: stats.Audience.AverageScore,
+			TotalRatings: stats.Audience.TotalRatings,
+		},
+		Critics: ScoreResponse{
+			AverageScore: stats.Critics.AverageScore,
+			TotalRatings: stats.Critics.TotalRatings,
+		},
+	}
+	if stats.Volatility != nil {
+		response.Volatility = volatilityToResponse(stats.Volatility)
+	}
+	if t := stats.Trust; t != nil {
+		response.Trust = &TrustResponse{
+			WeightedAverage:  math.Round(t.WeightedAverage*100) / 100,
+			EffectiveRatings: math.Round(t.EffectiveRatings*100) / 100,
+		}
+	}
+	return response
+}
 
-	return MovieStatsResponse{
-		MovieID:      string(stats.MovieID),
-		AverageScore: stats.AverageScore,
-		TotalRatings: stats.TotalRatings,
-		ScoreCount:   scoreCount,
+func volatilityToResponse(v *rating.Volatility) *VolatilityResponse {
+	return &VolatilityResponse{
+		Flagged:         v.Flagged,
+		Direction:       v.Direction,
+		Score:           v.Score,
+		WindowStart:     v.WindowStart.Format(time.RFC3339),
+		SpikeRatings:    v.SpikeRatings,
+		ExpectedRatings: math.Round(v.ExpectedRatings*100) / 100,
+		Excluded:        v.Excluded,
 	}
 }
 
@@ -321,7 +607,7 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 	var appErr *appErrors.AppError
 	if errors.As(err, &appErr) {
 		h.logger.Error("Service error", "error", appErr)
-		h.responseWriter.WriteError(w, appErr.Message, appErr.StatusCode)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
@@ -342,24 +628,52 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 func (h *Handler) RegisterRoutes(router chi.Router) {
 
 	router.Route("/ratings", func(r chi.Router) {
-		r.Post("/", h.CreateRating)
+		if h.auth != nil {
+			r.With(h.auth.Authenticate).Post("/", h.CreateRating)
+		} else {
+			r.Post("/", h.CreateRating)
+		}
 
 		r.Route("/{id}", func(r chi.Router) {
 			r.Get("/", h.GetRatingByID)
-			r.Put("/", h.UpdateRating)
-			r.Delete("/", h.DeleteRating)
+			if h.auth != nil {
+				r.With(h.auth.Authenticate).Put("/", h.UpdateRating)
+				r.With(h.auth.Authenticate).Delete("/", h.DeleteRating)
+				r.With(h.auth.Authenticate).Post("/restore", h.RestoreRating)
+			} else {
+				r.Put("/", h.UpdateRating)
+				r.Delete("/", h.DeleteRating)
+				r.Post("/restore", h.RestoreRating)
+			}
 		})
 	})
 
 	// User-centric rating routes
 	router.Route("/users/{userId}/ratings", func(r chi.Router) {
 		r.Get("/", h.GetUserRating)
+		// Static segments take precedence over {movieId}
+		if h.history != nil && h.auth != nil {
+			r.With(h.auth.Authenticate).Get("/export", h.ExportHistory)
+			r.With(h.auth.Authenticate).Post("/import/preview", h.PreviewHistoryImport)
+			r.With(h.auth.Authenticate).Post("/import", h.ImportHistory)
+		}
 		r.Get("/{movieId}", h.GetUserRating)
 	})
 
 	// Movie-centric rating routes
-	router.Route("/movies/{movieId}", func(r chi.Router) {
-		r.Get("/ratings", h.GetMovieRatings)
-		r.Get("/stats", h.GetMovieStats)
-	})
+	// Registered as plain routes rather than a /movies/{movieId} sub-router so
+	// that GET /movies/{id} still reaches the movies handler
+	router.Get("/movies/{movieId}/ratings", h.GetMovieRatings)
+	router.Get("/movies:compare", h.CompareMovies)
+	router.With(h.cached...).Get("/movies/{movieId}/stats", h.GetMovieStats)
+	router.Get("/movies/{movieId}/stats/explanation", h.GetScoreExplanation)
+	if h.statsHistory != nil {
+		router.Get("/movies/{movieId}/stats/history", h.GetStatsHistory)
+	}
+
+	router.Get("/reviews/search", h.SearchReviews)
+
+	if h.auth != nil {
+		router.With(h.auth.Authenticate, h.auth.RequireRole(users.RoleAdmin)).Post("/admin/users/{userId}/ratings", h.CreateRatingForUser)
+	}
 }
@@ -9,10 +9,15 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
 	ratingService "thermondo/internal/platform/service/rating"
 
 	"github.com/go-chi/chi/v5"
@@ -30,6 +35,7 @@ func createTestRating() *rating.Rating {
 		Review:    "Great movie!",
 		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
 		UpdatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Version:   1,
 	}
 }
 
@@ -42,6 +48,7 @@ func TestCreateRating(t *testing.T) {
 	tests := []struct {
 		name           string
 		requestBody    interface{}
+		anonymous      bool
 		setupMock      func(*MockRatingService)
 		expectedStatus int
 		expectedBody   func(*testing.T, string)
@@ -49,8 +56,7 @@ func TestCreateRating(t *testing.T) {
 	}{
 		{
 			name: "successful rating creation",
-			requestBody: ratingService.CreateRatingRequest{
-				UserID:  "test-user-123",
+			requestBody: CreateRatingRequest{
 				MovieID: "test-movie-123",
 				Score:   5,
 				Review:  "Great movie!",
@@ -83,8 +89,7 @@ func TestCreateRating(t *testing.T) {
 		},
 		{
 			name: "invalid score",
-			requestBody: ratingService.CreateRatingRequest{
-				UserID:  "test-user-123",
+			requestBody: CreateRatingRequest{
 				MovieID: "test-movie-123",
 				Score:   6, // Invalid score (should be 1-5)
 				Review:  "Great movie!",
@@ -101,7 +106,7 @@ func TestCreateRating(t *testing.T) {
 		},
 		{
 			name:        "malformed JSON",
-			requestBody: `{"user_id": "test-user-123", "score": "not_a_number"}`,
+			requestBody: `{"movie_id": "test-movie-123", "score": "not_a_number"}`,
 			setupMock: func(m *MockRatingService) {
 				m.On("GetBayesianConfig").Return(ratingService.DefaultBayesianConfig()).Maybe()
 			},
@@ -111,6 +116,27 @@ func TestCreateRating(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name:           "rating on behalf of another user",
+			requestBody:    `{"user_id": "other-user", "movie_id": "test-movie-123", "score": 5}`,
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "user_id")
+			},
+			expectError: true,
+		},
+		{
+			name:           "unauthenticated",
+			requestBody:    CreateRatingRequest{MovieID: "test-movie-123", Score: 5},
+			anonymous:      true,
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "Authentication required")
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -130,6 +156,9 @@ func TestCreateRating(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPost, "/ratings", body)
 			req.Header.Set("Content-Type", "application/json")
+			if !tt.anonymous {
+				req = req.WithContext(middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: "test-user-123", Role: users.RoleUser}))
+			}
 			rr := httptest.NewRecorder()
 			handler.CreateRating(rr, req)
 
@@ -140,6 +169,54 @@ func TestCreateRating(t *testing.T) {
 	}
 }
 
+func TestCreateRatingForUser(t *testing.T) {
+	setupRouter := func(m *MockRatingService) *chi.Mux {
+		router := chi.NewRouter()
+		NewHandler(m, slog.New(slog.NewTextHandler(io.Discard, nil)), WithAuthentication(tokens.FromSecret(historyTestSecret), nil)).RegisterRoutes(router)
+		return router
+	}
+	body := `{"movie_id": "test-movie-123", "score": 5}`
+
+	t.Run("admin rates on behalf of a user", func(t *testing.T) {
+		m := new(MockRatingService)
+		m.On("CreateRating", mock.Anything, ratingService.CreateRatingRequest{UserID: "test-user-123", MovieID: "test-movie-123", Score: 5}).Return(createTestRating(), nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(m).ServeHTTP(w, historyRequest(t, http.MethodPost, "/admin/users/test-user-123/ratings", "admin-1", "admin", body))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("forbidden for users", func(t *testing.T) {
+		m := new(MockRatingService)
+
+		w := httptest.NewRecorder()
+		setupRouter(m).ServeHTTP(w, historyRequest(t, http.MethodPost, "/admin/users/test-user-123/ratings", "user-2", "user", body))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		m.AssertNotCalled(t, "CreateRating", mock.Anything, mock.Anything)
+	})
+
+	t.Run("the token decides the author", func(t *testing.T) {
+		m := new(MockRatingService)
+		m.On("CreateRating", mock.Anything, ratingService.CreateRatingRequest{UserID: "user-2", MovieID: "test-movie-123", Score: 5}).Return(createTestRating(), nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(m).ServeHTTP(w, historyRequest(t, http.MethodPost, "/ratings", "user-2", "user", body))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("a token is required", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupRouter(new(MockRatingService)).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ratings", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
 func TestGetRatingByID(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -169,6 +246,7 @@ func TestGetRatingByID(t *testing.T) {
 				assert.Equal(t, "Great movie!", response.Review)
 				assert.Equal(t, "2024-01-01T12:00:00Z", response.CreatedAt)
 				assert.Equal(t, "2024-01-01T12:00:00Z", response.UpdatedAt)
+				assert.Equal(t, 1, response.Version)
 			},
 			expectError: false,
 		},
@@ -222,6 +300,193 @@ func TestGetRatingByID(t *testing.T) {
 	}
 }
 
+func TestRestoreRating(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*MockRatingService)
+		expectedStatus int
+		expectedBody   func(*testing.T, string)
+	}{
+		{
+			name: "restored",
+			setupMock: func(m *MockRatingService) {
+				m.On("RestoreRating", mock.Anything, "test-rating-123", mock.Anything).Return(createTestRating(), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response RatingResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+				assert.Equal(t, "test-rating-123", response.ID)
+				assert.Equal(t, 5, response.Score)
+			},
+		},
+		{
+			name: "restore window passed",
+			setupMock: func(m *MockRatingService) {
+				m.On("RestoreRating", mock.Anything, "test-rating-123", mock.Anything).
+					Return(nil, appErrors.NewGoneError("Rating was deleted too long ago to be restored"))
+			},
+			expectedStatus: http.StatusGone,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "too long ago")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRatingService)
+			tt.setupMock(mockService)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := NewHandler(mockService, logger)
+
+			req := httptest.NewRequest(http.MethodPost, "/ratings/test-rating-123/restore", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "test-rating-123")
+			ctx := middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: "test-user-123", Role: users.RoleUser})
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.RestoreRating(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.expectedBody(t, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRatingChangesRequireAuthentication(t *testing.T) {
+	m := new(MockRatingService)
+	router := chi.NewRouter()
+	NewHandler(m, slog.New(slog.NewTextHandler(io.Discard, nil)), WithAuthentication(tokens.FromSecret(historyTestSecret), nil)).RegisterRoutes(router)
+
+	for _, tc := range []struct{ method, target string }{
+		{http.MethodPut, "/ratings/test-rating-123"},
+		{http.MethodDelete, "/ratings/test-rating-123"},
+		{http.MethodPost, "/ratings/test-rating-123/restore"},
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.target, bytes.NewBufferString(`{"score": 3}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", tc.method, tc.target)
+	}
+	m.AssertNotCalled(t, "UpdateRating", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	m.AssertNotCalled(t, "DeleteRating", mock.Anything, mock.Anything, mock.Anything)
+	m.AssertNotCalled(t, "RestoreRating", mock.Anything, mock.Anything, mock.Anything)
+
+	t.Run("the caller is passed on as the requester", func(t *testing.T) {
+		m.On("DeleteRating", mock.Anything, "test-rating-123", mock.MatchedBy(func(requester ratingService.Requester) bool {
+			return requester.Is("user-2") && !requester.IsAdmin()
+		})).Return(appErrors.NewForbiddenError("You can only change your own ratings"))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, historyRequest(t, http.MethodDelete, "/ratings/test-rating-123", "user-2", "user", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestUpdateRating(t *testing.T) {
+	score := 3
+	tests := []struct {
+		name           string
+		ifMatch        string
+		setupMock      func(*MockRatingService)
+		expectedStatus int
+		expectedBody   func(*testing.T, *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "matching If-Match updates and returns the new ETag",
+			ifMatch: `"1"`,
+			setupMock: func(m *MockRatingService) {
+				updated := createTestRating()
+				updated.Score = score
+				updated.Version = 2
+				m.On("UpdateRating", mock.Anything, "test-rating-123", mock.Anything, mock.MatchedBy(func(req ratingService.UpdateRatingRequest) bool {
+					return req.ExpectedVersion != nil && *req.ExpectedVersion == 1
+				})).Return(updated, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+			},
+		},
+		{
+			name: "no If-Match updates unconditionally",
+			setupMock: func(m *MockRatingService) {
+				m.On("UpdateRating", mock.Anything, "test-rating-123", mock.Anything, mock.MatchedBy(func(req ratingService.UpdateRatingRequest) bool {
+					return req.ExpectedVersion == nil
+				})).Return(createTestRating(), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   func(t *testing.T, rr *httptest.ResponseRecorder) {},
+		},
+		{
+			name:    "stale If-Match returns 412 with the current rating",
+			ifMatch: `W/"1"`,
+			setupMock: func(m *MockRatingService) {
+				current := createTestRating()
+				current.Score = 1
+				current.Version = 4
+				m.On("UpdateRating", mock.Anything, "test-rating-123", mock.Anything, mock.Anything).Return(nil, &ratingService.VersionConflictError{
+					AppError: appErrors.NewPreconditionFailedError("Rating has been modified since it was read"),
+					Current:  current,
+				})
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody: func(t *testing.T, rr *httptest.ResponseRecorder) {
+				assert.Equal(t, `"4"`, rr.Header().Get("ETag"))
+
+				var problem struct {
+					Code  string `json:"code"`
+					Extra struct {
+						Current RatingResponse `json:"current"`
+					} `json:"extra"`
+				}
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+				assert.Equal(t, "PRECONDITION_FAILED", problem.Code)
+				assert.Equal(t, 1, problem.Extra.Current.Score)
+				assert.Equal(t, 4, problem.Extra.Current.Version)
+			},
+		},
+		{
+			name:           "malformed If-Match",
+			ifMatch:        "1",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   func(t *testing.T, rr *httptest.ResponseRecorder) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRatingService)
+			tt.setupMock(mockService)
+
+			handler := NewHandler(mockService, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			req := httptest.NewRequest(http.MethodPut, "/ratings/test-rating-123", createRequestBody(map[string]int{"score": score}))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "test-rating-123")
+			ctx := middleware.WithPrincipal(req.Context(), &middleware.Principal{UserID: "test-user-123", Role: users.RoleUser})
+			req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+
+			rr := httptest.NewRecorder()
+			handler.UpdateRating(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.expectedBody(t, rr)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestGetMovieRatings(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -238,7 +503,7 @@ func TestGetMovieRatings(t *testing.T) {
 			queryParams: "limit=10&offset=0&sort_by=created_at&order=desc",
 			setupMock: func(m *MockRatingService) {
 				ratings := []*rating.Rating{createTestRating()}
-				m.On("GetMovieRatings", mock.Anything, "test-movie-123", 10, 0, "created_at", "desc").Return(ratings, int64(1), nil)
+				m.On("GetMovieRatings", mock.Anything, "test-movie-123", 10, 0, "created_at", "desc", ratingService.MovieRatingsFilter{}).Return(ratings, int64(1), nil)
 				m.On("GetBayesianConfig").Return(ratingService.DefaultBayesianConfig()).Maybe()
 			},
 			expectedStatus: http.StatusOK,
@@ -262,6 +527,65 @@ func TestGetMovieRatings(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:        "critic ratings only",
+			movieID:     "test-movie-123",
+			queryParams: "reviewer=critic",
+			setupMock: func(m *MockRatingService) {
+				ratings := []*rating.Rating{createTestRating()}
+				m.On("GetMovieRatings", mock.Anything, "test-movie-123", 20, 0, "created_at", "desc", ratingService.MovieRatingsFilter{Reviewer: rating.ReviewerCritic}).Return(ratings, int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response RatingsListResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+				assert.Len(t, response.Ratings, 1)
+			},
+			expectError: false,
+		},
+		{
+			name:        "spoilers hidden and reviews rendered",
+			movieID:     "test-movie-123",
+			queryParams: "spoilers=hide&render=html",
+			setupMock: func(m *MockRatingService) {
+				r := createTestRating()
+				r.Review = "**Great** movie!"
+				filter := ratingService.MovieRatingsFilter{Spoilers: rating.SpoilersHide}
+				m.On("GetMovieRatings", mock.Anything, "test-movie-123", 20, 0, "created_at", "desc", filter).Return([]*rating.Rating{r}, int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response RatingsListResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+				require.Len(t, response.Ratings, 1)
+				assert.Equal(t, "**Great** movie!", response.Ratings[0].Review)
+				require.NotNil(t, response.Ratings[0].ReviewHTML)
+				assert.Equal(t, "<p><strong>Great</strong> movie!</p>", *response.Ratings[0].ReviewHTML)
+			},
+			expectError: false,
+		},
+		{
+			name:           "invalid spoiler filter",
+			movieID:        "test-movie-123",
+			queryParams:    "spoilers=maybe",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "spoilers must be")
+			},
+			expectError: true,
+		},
+		{
+			name:           "invalid reviewer",
+			movieID:        "test-movie-123",
+			queryParams:    "reviewer=robots",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "reviewer must be")
+			},
+			expectError: true,
+		},
 		{
 			name:        "invalid limit",
 			movieID:     "test-movie-123",
@@ -348,6 +672,12 @@ func TestGetMovieStats(t *testing.T) {
 						4: 30,
 						3: 20,
 					},
+					StandardDeviation:    0.78,
+					MedianScore:          4.5,
+					Controversy:          0.138,
+					SmoothedDistribution: map[int]float64{1: 1.0 / 105, 2: 1.0 / 105, 3: 21.0 / 105, 4: 31.0 / 105, 5: 51.0 / 105},
+					Audience:             rating.ScoreSummary{AverageScore: 4.4, TotalRatings: 90},
+					Critics:              rating.ScoreSummary{AverageScore: 4.9, TotalRatings: 10},
 				}
 				m.On("GetMovieStats", mock.Anything, "test-movie-123").Return(stats, nil)
 				m.On("GetBayesianConfig").Return(ratingService.DefaultBayesianConfig()).Maybe()
@@ -364,6 +694,72 @@ func TestGetMovieStats(t *testing.T) {
 				assert.Equal(t, int64(50), response.ScoreCount["5"])
 				assert.Equal(t, int64(30), response.ScoreCount["4"])
 				assert.Equal(t, int64(20), response.ScoreCount["3"])
+				assert.Equal(t, ScoreResponse{AverageScore: 4.4, TotalRatings: 90}, response.Audience)
+				assert.Equal(t, ScoreResponse{AverageScore: 4.9, TotalRatings: 10}, response.Critics)
+				assert.Equal(t, 0.78, response.StandardDeviation)
+				assert.Equal(t, 4.5, response.MedianScore)
+				assert.Equal(t, 0.138, response.Controversy)
+				assert.Equal(t, 0.4857, response.SmoothedDistribution["5"])
+				assert.Len(t, response.SmoothedDistribution, 5)
+			},
+			expectError: false,
+		},
+		{
+			name:    "averages withheld for too few ratings",
+			movieID: "test-movie-123",
+			setupMock: func(m *MockRatingService) {
+				stats := &rating.MovieRatingStats{
+					MovieID:      "test-movie-123",
+					TotalRatings: 2,
+					ScoreCount:   map[int]int64{5: 2},
+					Withheld:     true,
+				}
+				m.On("GetMovieStats", mock.Anything, "test-movie-123").Return(stats, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response MovieStatsResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+				assert.True(t, response.Withheld)
+				assert.Equal(t, "Not enough ratings", response.Notice)
+				assert.Equal(t, int64(2), response.ScoreCount["5"])
+			},
+		},
+		{
+			name:    "stats flagged for review bombing",
+			movieID: "test-movie-123",
+			setupMock: func(m *MockRatingService) {
+				stats := &rating.MovieRatingStats{
+					MovieID:      "test-movie-123",
+					AverageScore: 2.1,
+					TotalRatings: 60,
+					ScoreCount:   map[int]int64{1: 40, 5: 20},
+					Volatility: &rating.Volatility{
+						Flagged:         true,
+						Direction:       rating.VolatilityNegative,
+						Score:           1,
+						WindowStart:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+						SpikeRatings:    38,
+						ExpectedRatings: 0.333,
+					},
+					Trust: &rating.TrustWeighting{WeightedAverage: 3.456, EffectiveRatings: 23.8},
+				}
+				m.On("GetMovieStats", mock.Anything, "test-movie-123").Return(stats, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response MovieStatsResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+
+				require.NotNil(t, response.Volatility)
+				assert.True(t, response.Volatility.Flagged)
+				assert.Equal(t, "negative", response.Volatility.Direction)
+				assert.Equal(t, "2024-01-01T00:00:00Z", response.Volatility.WindowStart)
+				assert.Equal(t, int64(38), response.Volatility.SpikeRatings)
+				assert.Equal(t, 0.33, response.Volatility.ExpectedRatings)
+				require.NotNil(t, response.Trust)
+				assert.Equal(t, 3.46, response.Trust.WeightedAverage)
+				assert.Equal(t, 23.8, response.Trust.EffectiveRatings)
 			},
 			expectError: false,
 		},
@@ -0,0 +1,291 @@
+package ratings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/platform/http/middleware"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultMaxHistoryBytes caps the size of an import file sent as a form
+const DefaultMaxHistoryBytes = ratingService.DefaultHistoryMaxBytes
+
+// historyFormOverhead leaves room for the multipart boundaries and the
+// resolutions around the file itself
+const historyFormOverhead = 1 << 20
+
+// ExportHistory handles GET /users/{userId}/ratings/export. The ratings are
+// sent as a CSV or JSON attachment, oldest first, in the layout the import
+// reads back.
+func (h *Handler) ExportHistory(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userId")
+	if !canManageRatings(r, userID) {
+		h.responseWriter.WriteError(w, "You can only export your own ratings", http.StatusForbidden)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		h.responseWriter.WriteError(w, "format must be 'csv' or 'json'", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.history.ExportHistory(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("[export_history_handler] Failed to export ratings", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="ratings.%s"`, format))
+	if format == "json" {
+		h.responseWriter.WriteSuccess(w, historyEntriesToResponse(entries), http.StatusOK)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := ratingService.WriteHistoryCSV(w, entries); err != nil {
+		h.logger.Error("[export_history_handler] Failed to write export", "error", err, "user_id", userID)
+	}
+}
+
+// PreviewHistoryImport handles POST /users/{userId}/ratings/import/preview.
+// It matches the uploaded file to movies without rating anything, so the
+// user can resolve the entries that matched no movie or several.
+func (h *Handler) PreviewHistoryImport(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userId")
+	if !canManageRatings(r, userID) {
+		h.responseWriter.WriteError(w, "You can only import your own ratings", http.StatusForbidden)
+		return
+	}
+
+	body, options, cleanup, appErr := h.readHistoryImport(w, r)
+	if appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	defer cleanup()
+
+	preview, err := h.history.PreviewHistoryImport(r.Context(), userID, body, options)
+	if err != nil {
+		h.logger.Error("[preview_history_import_handler] Failed to preview import", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, HistoryPreviewResponse{
+		Entries:    preview.Entries,
+		Matched:    preview.Matched,
+		Fuzzy:      preview.Fuzzy,
+		Unmatched:  preview.Unmatched,
+		Invalid:    preview.Invalid,
+		Matches:    historyMatchesToResponse(preview.Matches),
+		Unresolved: unresolvedTitlesToResponse(preview.Unresolved),
+	}, http.StatusOK)
+}
+
+// ImportHistory handles POST /users/{userId}/ratings/import. Entries that
+// matched no movie, or only fuzzily without accept_fuzzy=true, are reported
+// back rather than imported.
+func (h *Handler) ImportHistory(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userId")
+	if !canManageRatings(r, userID) {
+		h.responseWriter.WriteError(w, "You can only import your own ratings", http.StatusForbidden)
+		return
+	}
+
+	body, options, cleanup, appErr := h.readHistoryImport(w, r)
+	if appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	defer cleanup()
+
+	result, err := h.history.ImportHistory(r.Context(), userID, body, options)
+	if err != nil {
+		h.logger.Error("[import_history_handler] Failed to import ratings", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, HistoryImportResponse{
+		Imported:    result.Imported,
+		Duplicates:  result.Duplicates,
+		Skipped:     result.Skipped,
+		NotImported: historyMatchesToResponse(result.NotImported),
+	}, http.StatusOK)
+}
+
+// readHistoryImport returns the file of an import and how to read it. The
+// file is either the whole body or, with multipart/form-data, the "file"
+// field next to an optional "resolutions" field holding a JSON array of
+// title resolutions. cleanup releases the form once the file has been read.
+func (h *Handler) readHistoryImport(w http.ResponseWriter, r *http.Request) (io.Reader, ratingService.HistoryImportOptions, func(), *appErrors.AppError) {
+	options, err := parseHistoryImportOptions(r)
+	if err != nil {
+		return nil, options, nil, appErrors.NewBadRequestError(err.Error())
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, options, func() {}, nil
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxHistoryBytes+historyFormOverhead)
+	if err := r.ParseMultipartForm(h.maxHistoryBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, options, nil, appErrors.NewPayloadTooLargeError(fmt.Sprintf("Import must not exceed %d bytes", h.maxHistoryBytes))
+		}
+		h.logger.Error("[import_history_handler] Invalid multipart body", "error", err)
+		return nil, options, nil, appErrors.NewBadRequestError("Request body must be multipart/form-data with a file")
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		r.MultipartForm.RemoveAll()
+		return nil, options, nil, appErrors.NewBadRequestError("Missing import file")
+	}
+	cleanup := func() {
+		file.Close()
+		r.MultipartForm.RemoveAll()
+	}
+	if raw := r.FormValue("resolutions"); raw != "" {
+		var resolutions []TitleResolutionRequest
+		if err := json.Unmarshal([]byte(raw), &resolutions); err != nil {
+			cleanup()
+			return nil, options, nil, appErrors.NewBadRequestError("resolutions must be a JSON array of {title, year, movie_id}")
+		}
+		for _, resolution := range resolutions {
+			options.Resolutions = append(options.Resolutions, ratingService.TitleResolution{
+				Title:   resolution.Title,
+				Year:    resolution.Year,
+				MovieID: resolution.MovieID,
+			})
+		}
+	}
+	return file, options, cleanup, nil
+}
+
+// parseHistoryImportOptions reads format, which defaults to csv, and
+// accept_fuzzy
+func parseHistoryImportOptions(r *http.Request) (ratingService.HistoryImportOptions, error) {
+	options := ratingService.HistoryImportOptions{Format: r.URL.Query().Get("format")}
+	if options.Format == "" {
+		options.Format = "csv"
+	}
+	if raw := r.URL.Query().Get("accept_fuzzy"); raw != "" {
+		acceptFuzzy, err := strconv.ParseBool(raw)
+		if err != nil {
+			return options, errors.New("accept_fuzzy must be true or false")
+		}
+		options.AcceptFuzzy = acceptFuzzy
+	}
+	return options, nil
+}
+
+// historyEntriesToResponse keeps a nil list nil, as the service returned it
+func historyEntriesToResponse(entries []*ratingService.HistoryEntry) []*HistoryEntryResponse {
+	if entries == nil {
+		return nil
+	}
+	responses := make([]*HistoryEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = historyEntryToResponse(entry)
+	}
+	return responses
+}
+
+func historyEntryToResponse(entry *ratingService.HistoryEntry) *HistoryEntryResponse {
+	if entry == nil {
+		return nil
+	}
+	return &HistoryEntryResponse{
+		MovieID:          entry.MovieID,
+		IMDbID:           entry.IMDbID,
+		Title:            entry.Title,
+		Year:             entry.Year,
+		Score:            entry.Score,
+		Review:           entry.Review,
+		ContainsSpoilers: entry.ContainsSpoilers,
+		RatedAt:          entry.RatedAt,
+		UpdatedAt:        entry.UpdatedAt,
+	}
+}
+
+func historyMatchesToResponse(matches []*ratingService.HistoryMatch) []*HistoryMatchResponse {
+	if matches == nil {
+		return nil
+	}
+	responses := make([]*HistoryMatchResponse, len(matches))
+	for i, match := range matches {
+		response := &HistoryMatchResponse{
+			Line:       match.Line,
+			Entry:      historyEntryToResponse(match.Entry),
+			Status:     string(match.Status),
+			Candidates: candidatesToResponse(match.Candidates),
+			Reason:     match.Reason,
+		}
+		if match.Movie != nil {
+			movie := candidateToResponse(*match.Movie)
+			response.Movie = &movie
+		}
+		responses[i] = response
+	}
+	return responses
+}
+
+func unresolvedTitlesToResponse(titles []*ratingService.UnresolvedTitle) []*UnresolvedTitleResponse {
+	if titles == nil {
+		return nil
+	}
+	responses := make([]*UnresolvedTitleResponse, len(titles))
+	for i, title := range titles {
+		responses[i] = &UnresolvedTitleResponse{
+			Title:      title.Title,
+			Year:       title.Year,
+			Lines:      title.Lines,
+			Candidates: candidatesToResponse(title.Candidates),
+		}
+	}
+	return responses
+}
+
+func candidatesToResponse(candidates []ratingService.MovieCandidate) []MovieCandidateResponse {
+	if candidates == nil {
+		return nil
+	}
+	responses := make([]MovieCandidateResponse, len(candidates))
+	for i, candidate := range candidates {
+		responses[i] = candidateToResponse(candidate)
+	}
+	return responses
+}
+
+func candidateToResponse(candidate ratingService.MovieCandidate) MovieCandidateResponse {
+	return MovieCandidateResponse{
+		MovieID:    candidate.MovieID,
+		Title:      candidate.Title,
+		Year:       candidate.Year,
+		IMDbID:     candidate.IMDbID,
+		Similarity: candidate.Similarity,
+	}
+}
+
+// canManageRatings reports whether the authenticated caller may export or
+// import the ratings of user id: their own, or anyone's as an admin
+func canManageRatings(r *http.Request, id string) bool {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	return ok && (principal.Is(id) || principal.IsAdmin())
+}
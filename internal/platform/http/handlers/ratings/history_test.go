@@ -0,0 +1,205 @@
+package ratings
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const historyTestSecret = "history-test-secret"
+
+func setupHistoryRouter(history *MockHistoryService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockRatingService), slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithAuthentication(tokens.FromSecret(historyTestSecret), nil),
+		WithHistory(history),
+	).RegisterRoutes(router)
+	return router
+}
+
+func historyRequest(t *testing.T, method, target, callerID, role, body string) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": callerID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(historyTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestExportHistory(t *testing.T) {
+	entries := []*ratingService.HistoryEntry{
+		{MovieID: "movie-1", Title: "Heat", Year: 1995, Score: 4, RatedAt: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("csv by default", func(t *testing.T) {
+		history := new(MockHistoryService)
+		history.On("ExportHistory", mock.Anything, "user-1").Return(entries, nil)
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodGet, "/users/user-1/ratings/export", "user-1", "user", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="ratings.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "movie-1,,Heat,1995,4,,false,2015-06-01T00:00:00Z,")
+	})
+
+	t.Run("json for an admin", func(t *testing.T) {
+		history := new(MockHistoryService)
+		history.On("ExportHistory", mock.Anything, "user-1").Return(entries, nil)
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodGet, "/users/user-1/ratings/export?format=json", "admin-1", "admin", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `[{"movie_id":"movie-1","title":"Heat","year":1995,"score":4,"rated_at":"2015-06-01T00:00:00Z"}]`, w.Body.String())
+	})
+
+	t.Run("someone else's ratings", func(t *testing.T) {
+		history := new(MockHistoryService)
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodGet, "/users/user-1/ratings/export", "user-2", "user", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		history.AssertNotCalled(t, "ExportHistory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupHistoryRouter(new(MockHistoryService)).ServeHTTP(w, historyRequest(t, http.MethodGet, "/users/user-1/ratings/export?format=xml", "user-1", "user", ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("without a token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupHistoryRouter(new(MockHistoryService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/ratings/export", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestImportHistory(t *testing.T) {
+	t.Run("preview", func(t *testing.T) {
+		history := new(MockHistoryService)
+		history.On("PreviewHistoryImport", mock.Anything, "user-1", mock.Anything, ratingService.HistoryImportOptions{Format: "csv"}).
+			Return(&ratingService.HistoryPreview{Entries: 1, Unmatched: 1, Matches: []*ratingService.HistoryMatch{
+				{Line: 2, Entry: &ratingService.HistoryEntry{Title: "Zardoz", Score: 2}, Status: ratingService.MatchNone, Reason: "no movie with a similar title"},
+			}}, nil)
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodPost, "/users/user-1/ratings/import/preview", "user-1", "user", "title,score\nZardoz,2"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"unmatched":1`)
+		assert.Contains(t, w.Body.String(), `"status":"unmatched"`)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("import accepting fuzzy matches", func(t *testing.T) {
+		history := new(MockHistoryService)
+		history.On("ImportHistory", mock.Anything, "user-1", mock.Anything, ratingService.HistoryImportOptions{Format: "json", AcceptFuzzy: true}).
+			Return(&ratingService.HistoryImportResult{Imported: 3, NotImported: []*ratingService.HistoryMatch{}}, nil)
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodPost, "/users/user-1/ratings/import?format=json&accept_fuzzy=true", "user-1", "user", "[]"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"imported":3,"duplicates":0,"skipped":0,"not_imported":[]}`, w.Body.String())
+	})
+
+	t.Run("form with resolutions", func(t *testing.T) {
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		require.NoError(t, writer.WriteField("resolutions", `[{"title":"The Thing","movie_id":"movie-thing"}]`))
+		part, err := writer.CreateFormFile("file", "ratings.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("Date,Name,Year,Letterboxd URI,Rating\n2021-01-01,The Thing,,https://boxd.it/a,5"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		history := new(MockHistoryService)
+		history.On("ImportHistory", mock.Anything, "user-1", mock.MatchedBy(func(body io.Reader) bool {
+			data, err := io.ReadAll(body)
+			return err == nil && strings.Contains(string(data), "The Thing")
+		}), ratingService.HistoryImportOptions{
+			Format:      "letterboxd",
+			Resolutions: []ratingService.TitleResolution{{Title: "The Thing", MovieID: "movie-thing"}},
+		}).Return(&ratingService.HistoryImportResult{Imported: 1, NotImported: []*ratingService.HistoryMatch{}}, nil)
+
+		req := historyRequest(t, http.MethodPost, "/users/user-1/ratings/import?format=letterboxd", "user-1", "user", "")
+		req.Body = io.NopCloser(&form)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		history.AssertExpectations(t)
+	})
+
+	t.Run("form with invalid resolutions", func(t *testing.T) {
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		require.NoError(t, writer.WriteField("resolutions", `{"The Thing":"movie-thing"}`))
+		part, err := writer.CreateFormFile("file", "ratings.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("title,score"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req := historyRequest(t, http.MethodPost, "/users/user-1/ratings/import/preview", "user-1", "user", "")
+		req.Body = io.NopCloser(&form)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		setupHistoryRouter(new(MockHistoryService)).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		history := new(MockHistoryService)
+		history.On("ImportHistory", mock.Anything, "user-1", mock.Anything, mock.Anything).
+			Return(nil, appErrors.NewPayloadTooLargeError("Import must not exceed 10 bytes"))
+
+		w := httptest.NewRecorder()
+		setupHistoryRouter(history).ServeHTTP(w, historyRequest(t, http.MethodPost, "/users/user-1/ratings/import", "user-1", "user", "title,score"))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("invalid accept_fuzzy", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupHistoryRouter(new(MockHistoryService)).ServeHTTP(w, historyRequest(t, http.MethodPost, "/users/user-1/ratings/import?accept_fuzzy=maybe", "user-1", "user", ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("someone else's ratings", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupHistoryRouter(new(MockHistoryService)).ServeHTTP(w, historyRequest(t, http.MethodPost, "/users/user-1/ratings/import", "user-2", "user", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
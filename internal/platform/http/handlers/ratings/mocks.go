@@ -2,9 +2,12 @@ package ratings
 
 import (
 	"context"
+	"io"
+	"thermondo/internal/domain/jobs"
 	"thermondo/internal/domain/rating"
 
 	ratingService "thermondo/internal/platform/service/rating"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -30,24 +33,40 @@ func (m *MockRatingService) GetRatingByID(ctx context.Context, id string) (*rati
 	return args.Get(0).(*rating.Rating), args.Error(1)
 }
 
-func (m *MockRatingService) UpdateRating(ctx context.Context, id string, req ratingService.UpdateRatingRequest) (*rating.Rating, error) {
-	args := m.Called(ctx, id, req)
+func (m *MockRatingService) UpdateRating(ctx context.Context, id string, requester ratingService.Requester, req ratingService.UpdateRatingRequest) (*rating.Rating, error) {
+	args := m.Called(ctx, id, requester, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*rating.Rating), args.Error(1)
 }
 
-func (m *MockRatingService) DeleteRating(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
+func (m *MockRatingService) DeleteRating(ctx context.Context, id string, requester ratingService.Requester) error {
+	args := m.Called(ctx, id, requester)
 	return args.Error(0)
 }
 
-func (m *MockRatingService) GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error) {
-	args := m.Called(ctx, movieID, limit, offset, sortBy, order)
+func (m *MockRatingService) RestoreRating(ctx context.Context, id string, requester ratingService.Requester) (*rating.Rating, error) {
+	args := m.Called(ctx, id, requester)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingService) GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string, filter ratingService.MovieRatingsFilter) ([]*rating.Rating, int64, error) {
+	args := m.Called(ctx, movieID, limit, offset, sortBy, order, filter)
 	return args.Get(0).([]*rating.Rating), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockRatingService) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, limit, offset int) ([]*rating.ReviewMatch, int64, error) {
+	args := m.Called(ctx, query, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.ReviewMatch), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockRatingService) GetMovieStats(ctx context.Context, movieID string) (*rating.MovieRatingStats, error) {
 	args := m.Called(ctx, movieID)
 	if args.Get(0) == nil {
@@ -73,6 +92,11 @@ func (m *MockRatingService) SetBayesianConfig(config ratingService.BayesianConfi
 	m.Called(config)
 }
 
+func (m *MockRatingService) WithholdStats(stats *rating.MovieRatingStats) bool {
+	args := m.Called(stats)
+	return args.Bool(0)
+}
+
 func (m *MockRatingService) GetEnhancedMovieStats(ctx context.Context, movieID string) (*ratingService.EnhancedMovieStats, error) {
 	args := m.Called(ctx, movieID)
 	if args.Get(0) == nil {
@@ -81,12 +105,96 @@ func (m *MockRatingService) GetEnhancedMovieStats(ctx context.Context, movieID s
 	return args.Get(0).(*ratingService.EnhancedMovieStats), args.Error(1)
 }
 
+func (m *MockRatingService) CompareMovies(ctx context.Context, firstID, secondID string) (*ratingService.MovieComparison, error) {
+	args := m.Called(ctx, firstID, secondID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.MovieComparison), args.Error(1)
+}
+
+func (m *MockRatingService) GetHeadToHead(ctx context.Context, firstID, secondID string) (*rating.HeadToHead, error) {
+	args := m.Called(ctx, firstID, secondID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.HeadToHead), args.Error(1)
+}
+
 func (m *MockRatingService) UpdateGlobalAverage(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
 
+func (m *MockRatingService) GlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.GlobalAverage), args.Error(1)
+}
+
+func (m *MockRatingService) RefreshGlobalAverage(ctx context.Context) (*ratingService.GlobalAverage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.GlobalAverage), args.Error(1)
+}
+
+func (m *MockRatingService) StartGlobalAverageUpdater(ctx context.Context, interval time.Duration) {
+	m.Called(ctx, interval)
+}
+
 func (m *MockRatingService) GetUserRatings(ctx context.Context, userID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error) {
 	args := m.Called(ctx, userID, limit, offset, sortBy, order)
 	return args.Get(0).([]*rating.Rating), args.Get(1).(int64), args.Error(2)
 }
+
+// MockHistoryService is a mock implementation of the rating.HistoryService
+// interface
+type MockHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockHistoryService) ExportHistory(ctx context.Context, userID string) ([]*ratingService.HistoryEntry, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*ratingService.HistoryEntry), args.Error(1)
+}
+
+func (m *MockHistoryService) PreviewHistoryImport(ctx context.Context, userID string, body io.Reader, options ratingService.HistoryImportOptions) (*ratingService.HistoryPreview, error) {
+	args := m.Called(ctx, userID, body, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.HistoryPreview), args.Error(1)
+}
+
+func (m *MockHistoryService) ImportHistory(ctx context.Context, userID string, body io.Reader, options ratingService.HistoryImportOptions) (*ratingService.HistoryImportResult, error) {
+	args := m.Called(ctx, userID, body, options)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.HistoryImportResult), args.Error(1)
+}
+
+// MockStatsHistoryService is a mock implementation of the
+// rating.StatsHistoryService interface
+type MockStatsHistoryService struct {
+	mock.Mock
+}
+
+func (m *MockStatsHistoryService) GetStatsHistory(ctx context.Context, movieID, from, to string) ([]*rating.StatsSnapshot, error) {
+	args := m.Called(ctx, movieID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.StatsSnapshot), args.Error(1)
+}
+
+func (m *MockStatsHistoryService) RunSnapshot(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	return m.Called(ctx, job, report).Error(0)
+}
@@ -0,0 +1,125 @@
+package ratings
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// SearchReviews handles GET /reviews/search
+func (h *Handler) SearchReviews(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		h.responseWriter.WriteError(w, "Query parameter 'q' is required", http.StatusBadRequest)
+		return
+	}
+
+	params, err := h.parseListParams(r)
+	if err != nil {
+		h.logger.Error("[search_reviews_handler] Failed to parse list params", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseReviewFilter(r)
+	if err != nil {
+		h.logger.Error("[search_reviews_handler] Invalid filter", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matches, total, err := h.ratingService.SearchReviews(r.Context(), query, filter, params.Limit, params.Offset)
+	if err != nil {
+		h.logger.Error("[search_reviews_handler] Failed to search reviews", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	results := make([]ReviewSearchResult, len(matches))
+	for i, match := range matches {
+		results[i] = ReviewSearchResult{
+			RatingResponse: h.ratingToResponse(match.Rating),
+			Snippet:        match.Snippet,
+			Rank:           match.Rank,
+		}
+	}
+
+	h.responseWriter.WriteSuccess(w, ReviewSearchResponse{
+		Results: results,
+		Total:   total,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		HasMore: params.Offset+params.Limit < int(total),
+	}, http.StatusOK)
+}
+
+// parseReviewFilter reads the movie, score range and date range filters
+func parseReviewFilter(r *http.Request) (rating.ReviewFilter, error) {
+	filter := rating.ReviewFilter{MovieID: movies.MovieID(r.URL.Query().Get("movie_id"))}
+
+	var err error
+	if filter.MinScore, err = optionalIntParam(r, "min_score"); err != nil {
+		return filter, err
+	}
+	if filter.MaxScore, err = optionalIntParam(r, "max_score"); err != nil {
+		return filter, err
+	}
+	for _, score := range []*int{filter.MinScore, filter.MaxScore} {
+		if score != nil && (*score < 1 || *score > 5) {
+			return filter, errors.New("min_score and max_score must be between 1 and 5")
+		}
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return filter, errors.New("min_score cannot be greater than max_score")
+	}
+
+	if filter.From, err = dateParam(r, "from", false); err != nil {
+		return filter, err
+	}
+	if filter.To, err = dateParam(r, "to", true); err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return filter, errors.New("from cannot be after to")
+	}
+
+	return filter, nil
+}
+
+func optionalIntParam(r *http.Request, key string) (*int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &intValue, nil
+}
+
+// dateParam accepts a date (YYYY-MM-DD, UTC) or an RFC 3339 timestamp. A
+// plain date used as an upper bound covers that whole day.
+func dateParam(r *http.Request, key string, endOfDay bool) (*time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD) or an RFC 3339 timestamp", key)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
@@ -0,0 +1,100 @@
+package ratings
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchReviews(t *testing.T) {
+	minScore := 4
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 23, 59, 59, 999999999, time.UTC)
+
+	tests := []struct {
+		name           string
+		queryParams    string
+		setupMock      func(*MockRatingService)
+		expectedStatus int
+		expectedBody   func(*testing.T, string)
+	}{
+		{
+			name:        "matches with filters",
+			queryParams: "q=plot+twist&movie_id=test-movie-123&min_score=4&from=2024-01-01&to=2024-01-31&limit=5",
+			setupMock: func(m *MockRatingService) {
+				filter := rating.ReviewFilter{MovieID: "test-movie-123", MinScore: &minScore, From: &from, To: &to}
+				matches := []*rating.ReviewMatch{{
+					Rating:  createTestRating(),
+					Snippet: "What a <mark>plot</mark> <mark>twist</mark>",
+					Rank:    0.5,
+				}}
+				m.On("SearchReviews", mock.Anything, "plot twist", filter, 5, 0).Return(matches, int64(1), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: func(t *testing.T, body string) {
+				var response ReviewSearchResponse
+				require.NoError(t, json.Unmarshal([]byte(body), &response))
+				require.Len(t, response.Results, 1)
+				assert.Equal(t, "test-rating-123", response.Results[0].ID)
+				assert.Equal(t, "What a <mark>plot</mark> <mark>twist</mark>", response.Results[0].Snippet)
+				assert.Equal(t, int64(1), response.Total)
+				assert.False(t, response.HasMore)
+			},
+		},
+		{
+			name:           "missing query",
+			queryParams:    "movie_id=test-movie-123",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "'q' is required")
+			},
+		},
+		{
+			name:           "inverted score range",
+			queryParams:    "q=twist&min_score=5&max_score=2",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "min_score cannot be greater than max_score")
+			},
+		},
+		{
+			name:           "invalid date",
+			queryParams:    "q=twist&from=last-week",
+			setupMock:      func(m *MockRatingService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "from must be a date")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRatingService)
+			tt.setupMock(mockService)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			handler := NewHandler(mockService, logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/reviews/search?"+tt.queryParams, nil)
+			rr := httptest.NewRecorder()
+			handler.SearchReviews(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			tt.expectedBody(t, rr.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
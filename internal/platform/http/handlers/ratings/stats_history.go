@@ -0,0 +1,35 @@
+package ratings
+
+import (
+	"net/http"
+	"thermondo/internal/domain/rating"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetStatsHistory handles GET /movies/{movieId}/stats/history?from=&to=,
+// the movie's daily stats snapshots for charting
+func (h *Handler) GetStatsHistory(w http.ResponseWriter, r *http.Request) {
+	movieID := chi.URLParam(r, "movieId")
+	query := r.URL.Query()
+
+	snapshots, err := h.statsHistory.GetStatsHistory(r.Context(), movieID, query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.logger.Error("[get_stats_history_handler] Failed to get stats history", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	response := &StatsHistoryResponse{MovieID: movieID, Snapshots: make([]StatsSnapshotResponse, len(snapshots))}
+	for i, s := range snapshots {
+		response.Snapshots[i] = StatsSnapshotResponse{
+			Date:            s.Date.Format(rating.StatsHistoryDateLayout),
+			AverageScore:    s.AverageScore,
+			BayesianAverage: s.BayesianAverage,
+			TotalRatings:    s.TotalRatings,
+			Withheld:        s.Withheld,
+		}
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
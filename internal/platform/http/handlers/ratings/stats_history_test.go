@@ -0,0 +1,56 @@
+package ratings
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatsHistory(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := func(history *MockStatsHistoryService) *chi.Mux {
+		router := chi.NewRouter()
+		NewHandler(new(MockRatingService), logger, WithStatsHistory(history)).RegisterRoutes(router)
+		return router
+	}
+
+	t.Run("returns the snapshots oldest first", func(t *testing.T) {
+		history := new(MockStatsHistoryService)
+		history.On("GetStatsHistory", mock.Anything, "movie-1", "2024-01-01", "").Return([]*rating.StatsSnapshot{
+			{MovieID: "movie-1", Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), AverageScore: 4.2, BayesianAverage: 3.9, TotalRatings: 120},
+			{MovieID: "movie-1", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), AverageScore: 4.1, BayesianAverage: 3.85, TotalRatings: 126},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setup(history).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/stats/history?from=2024-01-01", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response StatsHistoryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "movie-1", response.MovieID)
+		require.Len(t, response.Snapshots, 2)
+		assert.Equal(t, StatsSnapshotResponse{Date: "2024-01-01", AverageScore: 4.2, BayesianAverage: 3.9, TotalRatings: 120}, response.Snapshots[0])
+	})
+
+	t.Run("passes service errors through", func(t *testing.T) {
+		history := new(MockStatsHistoryService)
+		history.On("GetStatsHistory", mock.Anything, "missing", "", "").Return(nil, appErrors.NewNotFoundError("Movie not found"))
+
+		rr := httptest.NewRecorder()
+		setup(history).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/missing/stats/history", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
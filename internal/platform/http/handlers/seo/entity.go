@@ -0,0 +1,64 @@
+package seo
+
+import "encoding/xml"
+
+// sitemapNamespace is the XML namespace of the sitemap protocol
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapIndex links the sitemap pages, see https://www.sitemaps.org
+type SitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []SitemapURL `xml:"sitemap"`
+}
+
+// URLSet is a sitemap page
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"` // YYYY-MM-DD
+}
+
+// MovieJSONLD is a schema.org Movie, to embed in the movie's page as
+// <script type="application/ld+json">
+type MovieJSONLD struct {
+	Context         string                 `json:"@context"`
+	Type            string                 `json:"@type"`
+	URL             string                 `json:"url"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description,omitempty"`
+	Image           string                 `json:"image,omitempty"`
+	DateCreated     string                 `json:"dateCreated,omitempty"` // release year
+	Genre           string                 `json:"genre,omitempty"`
+	Director        *PersonJSONLD          `json:"director,omitempty"`
+	Duration        string                 `json:"duration,omitempty"` // ISO 8601, e.g. PT155M
+	InLanguage      string                 `json:"inLanguage,omitempty"`
+	CountryOfOrigin *CountryJSONLD         `json:"countryOfOrigin,omitempty"`
+	ContentRating   string                 `json:"contentRating,omitempty"`
+	SameAs          []string               `json:"sameAs,omitempty"`
+	AggregateRating *AggregateRatingJSONLD `json:"aggregateRating,omitempty"`
+}
+
+type PersonJSONLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type CountryJSONLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// AggregateRatingJSONLD is the movie's Bayesian average on the 1-5 scale
+type AggregateRatingJSONLD struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	BestRating  int     `json:"bestRating"`
+	WorstRating int     `json:"worstRating"`
+	RatingCount int64   `json:"ratingCount"`
+}
@@ -0,0 +1,177 @@
+package seo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	seoService "thermondo/internal/platform/service/seo"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// cacheControl lets crawlers and CDNs keep the sitemap and structured data
+// as long as the service caches them
+const cacheControl = "public, max-age=3600"
+
+// Handler serves the schema.org data of the movies for the web frontend to
+// embed in its pages
+type Handler struct {
+	service        seoService.Service
+	responseWriter *response.Writer
+	logger         *slog.Logger
+}
+
+func NewHandler(service seoService.Service, logger *slog.Logger) *Handler {
+	return &Handler{
+		service:        service,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+}
+
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Get("/movies/{movieId}/jsonld", h.GetMovieJSONLD)
+}
+
+// SitemapHandler serves the sitemap of the movie pages. It is mounted at
+// /sitemap.xml, outside the API, where crawlers look for it.
+type SitemapHandler struct {
+	service        seoService.Service
+	responseWriter *response.Writer
+	logger         *slog.Logger
+}
+
+func NewSitemapHandler(service seoService.Service, logger *slog.Logger) *SitemapHandler {
+	return &SitemapHandler{
+		service:        service,
+		responseWriter: response.NewWriter(logger),
+		logger:         logger,
+	}
+}
+
+func (h *SitemapHandler) RegisterRoutes(router chi.Router) {
+	router.Get("/", h.ServeSitemap)
+}
+
+// ServeSitemap handles GET /sitemap.xml, the sitemap index, and
+// GET /sitemap.xml?page=N, the movie pages of its Nth sitemap
+func (h *SitemapHandler) ServeSitemap(w http.ResponseWriter, r *http.Request) {
+	pageParam := r.URL.Query().Get("page")
+	if pageParam == "" {
+		entries, err := h.service.SitemapIndex(r.Context())
+		if err != nil {
+			h.logger.Error("[sitemap_handler] Failed to build sitemap index", "error", err)
+			writeServiceError(h.responseWriter, w, err)
+			return
+		}
+		writeXML(w, &SitemapIndex{Xmlns: sitemapNamespace, Sitemaps: toSitemapURLs(entries)})
+		return
+	}
+
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		h.responseWriter.WriteError(w, "page must be a positive number", http.StatusBadRequest)
+		return
+	}
+	entries, err := h.service.SitemapPage(r.Context(), page)
+	if err != nil {
+		h.logger.Error("[sitemap_handler] Failed to build sitemap page", "error", err, "page", page)
+		writeServiceError(h.responseWriter, w, err)
+		return
+	}
+	writeXML(w, &URLSet{Xmlns: sitemapNamespace, URLs: toSitemapURLs(entries)})
+}
+
+// GetMovieJSONLD handles GET /movies/{movieId}/jsonld, the schema.org Movie
+// with its AggregateRating
+func (h *Handler) GetMovieJSONLD(w http.ResponseWriter, r *http.Request) {
+	data, err := h.service.MovieData(r.Context(), chi.URLParam(r, "movieId"))
+	if err != nil {
+		h.logger.Error("[movie_jsonld_handler] Failed to get structured data", "error", err)
+		writeServiceError(h.responseWriter, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ld+json")
+	w.Header().Set("Cache-Control", cacheControl)
+	json.NewEncoder(w).Encode(movieToJSONLD(data))
+}
+
+func movieToJSONLD(data *seoService.MovieData) *MovieJSONLD {
+	movie := data.Movie
+	doc := &MovieJSONLD{
+		Context:       "https://schema.org",
+		Type:          "Movie",
+		URL:           data.URL,
+		Name:          movie.Title,
+		Description:   movie.Description,
+		Genre:         movie.Genre,
+		InLanguage:    movie.Language,
+		ContentRating: string(movie.Rating),
+	}
+	if movie.ReleaseYear > 0 {
+		doc.DateCreated = strconv.Itoa(movie.ReleaseYear)
+	}
+	if movie.DurationMins > 0 {
+		doc.Duration = fmt.Sprintf("PT%dM", movie.DurationMins)
+	}
+	if movie.Director != "" {
+		doc.Director = &PersonJSONLD{Type: "Person", Name: movie.Director}
+	}
+	if movie.Country != "" {
+		doc.CountryOfOrigin = &CountryJSONLD{Type: "Country", Name: movie.Country}
+	}
+	// Local posters are served relative to the API, which search engines
+	// cannot resolve
+	if movie.PosterURL != nil && strings.HasPrefix(*movie.PosterURL, "http") {
+		doc.Image = *movie.PosterURL
+	}
+	if movie.IMDbID != nil {
+		doc.SameAs = []string{"https://www.imdb.com/title/" + *movie.IMDbID + "/"}
+	}
+	if data.RatingCount > 0 {
+		doc.AggregateRating = &AggregateRatingJSONLD{
+			Type:        "AggregateRating",
+			RatingValue: data.RatingValue,
+			BestRating:  5,
+			WorstRating: 1,
+			RatingCount: data.RatingCount,
+		}
+	}
+	return doc
+}
+
+func toSitemapURLs(entries []*seoService.SitemapEntry) []SitemapURL {
+	urls := make([]SitemapURL, len(entries))
+	for i, entry := range entries {
+		urls[i] = SitemapURL{Loc: entry.URL}
+		if !entry.LastModified.IsZero() {
+			urls[i].LastMod = entry.LastModified.UTC().Format(time.DateOnly)
+		}
+	}
+	return urls
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Write([]byte(xml.Header))
+	xml.NewEncoder(w).Encode(v)
+}
+
+func writeServiceError(writer *response.Writer, w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		writer.WriteAppError(w, appErr)
+		return
+	}
+	writer.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
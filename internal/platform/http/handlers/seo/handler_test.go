@@ -0,0 +1,129 @@
+package seo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+	seoService "thermondo/internal/platform/service/seo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouter(service seoService.Service) *chi.Mux {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	router := chi.NewRouter()
+	router.Route("/sitemap.xml", NewSitemapHandler(service, logger).RegisterRoutes)
+	NewHandler(service, logger).RegisterRoutes(router)
+	return router
+}
+
+func TestServeSitemap(t *testing.T) {
+	t.Run("should serve the sitemap index", func(t *testing.T) {
+		service := new(MockSEOService)
+		service.On("SitemapIndex", mock.Anything).Return([]*seoService.SitemapEntry{
+			{URL: "https://movies.example.com/sitemap.xml?page=1"},
+			{URL: "https://movies.example.com/sitemap.xml?page=2"},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/xml; charset=utf-8", rr.Header().Get("Content-Type"))
+		var index SitemapIndex
+		require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &index))
+		require.Len(t, index.Sitemaps, 2)
+		assert.Equal(t, "https://movies.example.com/sitemap.xml?page=2", index.Sitemaps[1].Loc)
+		assert.Contains(t, rr.Body.String(), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	})
+
+	t.Run("should serve a page with last modified dates", func(t *testing.T) {
+		service := new(MockSEOService)
+		service.On("SitemapPage", mock.Anything, 2).Return([]*seoService.SitemapEntry{
+			{URL: "https://movies.example.com/movies/movie-1", LastModified: time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml?page=2", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var set URLSet
+		require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &set))
+		require.Len(t, set.URLs, 1)
+		assert.Equal(t, "2024-05-01", set.URLs[0].LastMod)
+	})
+
+	t.Run("should reject an invalid page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		setupRouter(new(MockSEOService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml?page=last", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("should pass service errors through", func(t *testing.T) {
+		service := new(MockSEOService)
+		service.On("SitemapPage", mock.Anything, 9).Return(nil, errors.NewNotFoundError("Sitemap page not found"))
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml?page=9", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetMovieJSONLD(t *testing.T) {
+	imdbID := "tt1160419"
+	relativePoster := "/api/v1/media/posters/movie-1.jpg"
+	movie := &movies.Movie{
+		ID: "movie-1", Title: "Dune", ReleaseYear: 2021, Genre: "Sci-Fi", Director: "Denis Villeneuve",
+		DurationMins: 155, Rating: "PG13", Language: "English", Country: "USA",
+		IMDbID: &imdbID, PosterURL: &relativePoster,
+	}
+
+	t.Run("should describe the movie and its rating", func(t *testing.T) {
+		service := new(MockSEOService)
+		service.On("MovieData", mock.Anything, "movie-1").Return(&seoService.MovieData{
+			URL: "https://movies.example.com/movies/movie-1", Movie: movie, RatingValue: 3.6, RatingCount: 12,
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/jsonld", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/ld+json", rr.Header().Get("Content-Type"))
+		var doc MovieJSONLD
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		assert.Equal(t, "https://schema.org", doc.Context)
+		assert.Equal(t, "Movie", doc.Type)
+		assert.Equal(t, "PT155M", doc.Duration)
+		assert.Equal(t, "2021", doc.DateCreated)
+		assert.Equal(t, "Denis Villeneuve", doc.Director.Name)
+		assert.Equal(t, []string{"https://www.imdb.com/title/tt1160419/"}, doc.SameAs)
+		assert.Empty(t, doc.Image, "relative posters are left out")
+		require.NotNil(t, doc.AggregateRating)
+		assert.Equal(t, 3.6, doc.AggregateRating.RatingValue)
+		assert.Equal(t, int64(12), doc.AggregateRating.RatingCount)
+	})
+
+	t.Run("should leave out the rating of an unrated movie", func(t *testing.T) {
+		service := new(MockSEOService)
+		service.On("MovieData", mock.Anything, "movie-1").Return(&seoService.MovieData{URL: "https://movies.example.com/movies/movie-1", Movie: movie}, nil)
+
+		rr := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/movies/movie-1/jsonld", nil))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "aggregateRating")
+	})
+}
@@ -0,0 +1,37 @@
+package seo
+
+import (
+	"context"
+	seoService "thermondo/internal/platform/service/seo"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSEOService is a mock implementation of the SEO service
+type MockSEOService struct {
+	mock.Mock
+}
+
+func (m *MockSEOService) SitemapIndex(ctx context.Context) ([]*seoService.SitemapEntry, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*seoService.SitemapEntry), args.Error(1)
+}
+
+func (m *MockSEOService) SitemapPage(ctx context.Context, page int) ([]*seoService.SitemapEntry, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*seoService.SitemapEntry), args.Error(1)
+}
+
+func (m *MockSEOService) MovieData(ctx context.Context, movieID string) (*seoService.MovieData, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*seoService.MovieData), args.Error(1)
+}
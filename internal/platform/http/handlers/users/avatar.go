@@ -0,0 +1,135 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/imaging"
+	"thermondo/internal/platform/http/middleware"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultMaxAvatarBytes caps the size of an uploaded avatar image
+const DefaultMaxAvatarBytes = 5 << 20
+
+// avatarFormOverhead leaves room for the multipart boundaries and headers
+// around the image itself
+const avatarFormOverhead = 64 << 10
+
+// UploadAvatar handles PUT /users/{id}/avatar with a multipart/form-data body
+// carrying a JPEG or PNG image in the "avatar" field. Users may only change
+// their own avatar unless they are admins.
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only change your own avatar", http.StatusForbidden)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxAvatarBytes+avatarFormOverhead)
+	if err := r.ParseMultipartForm(h.maxAvatarBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.writeAvatarTooLarge(w)
+			return
+		}
+		h.logger.Error("[upload_avatar_handler] Invalid multipart body", "error", err)
+		h.responseWriter.WriteError(w, "Request body must be multipart/form-data with an avatar file", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("avatar")
+	if err != nil {
+		h.responseWriter.WriteError(w, "Missing avatar file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.maxAvatarBytes {
+		h.writeAvatarTooLarge(w)
+		return
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error("[upload_avatar_handler] Failed to read avatar", "error", err)
+		h.responseWriter.WriteError(w, "Failed to read avatar file", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.UploadAvatar(r.Context(), id, data)
+	if err != nil {
+		h.handleAvatarServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, userToResponse(user), http.StatusOK)
+}
+
+// DeleteAvatar handles DELETE /users/{id}/avatar
+func (h *Handler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only change your own avatar", http.StatusForbidden)
+		return
+	}
+
+	if err := h.userService.DeleteAvatar(r.Context(), id); err != nil {
+		h.handleAvatarServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAvatar handles GET /users/{id}/avatar and /users/{id}/avatar/{size} by
+// redirecting to the image's current, possibly signed, download URL
+func (h *Handler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	size := chi.URLParam(r, "size")
+	if size == "" {
+		size = string(domainUser.AvatarStandard)
+	}
+
+	url, err := h.userService.AvatarURL(r.Context(), id, size)
+	if err != nil {
+		h.handleAvatarServiceError(w, err)
+		return
+	}
+
+	// Signed URLs expire, so clients must come back here rather than cache
+	// the redirect
+	w.Header().Set("Cache-Control", "no-cache")
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// canManageUser reports whether the authenticated caller may change the
+// avatar or sessions of user id: their own, or anyone's as an admin
+func (h *Handler) canManageUser(r *http.Request, id string) bool {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	return ok && (principal.Is(id) || principal.IsAdmin())
+}
+
+func (h *Handler) writeAvatarTooLarge(w http.ResponseWriter) {
+	h.responseWriter.WriteError(w, fmt.Sprintf("Avatar must not exceed %d bytes", h.maxAvatarBytes), http.StatusRequestEntityTooLarge)
+}
+
+func (h *Handler) handleAvatarServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrUserNotFound), errors.Is(err, domainUser.ErrNoAvatar):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domainUser.ErrInvalidAvatar),
+		errors.Is(err, domainUser.ErrInvalidAvatarSize),
+		errors.Is(err, imaging.ErrImageTooLarge):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, userService.ErrAvatarsDisabled):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusNotImplemented)
+	default:
+		h.logger.Error("[avatar_handler] Avatar request failed", "error", err)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,193 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const avatarTestSecret = "avatar-test-secret"
+
+func avatarRequest(t *testing.T, method, target, callerID, role string, data []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := writer.CreateFormFile("avatar", "avatar.png")
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(method, target, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if callerID != "" {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": callerID,
+			"role":    role,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte(avatarTestSecret))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestUploadAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	avatarKey := "avatars/user-1/upload-1"
+
+	tests := []struct {
+		name           string
+		callerID       string
+		role           string
+		data           []byte
+		setupMock      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:     "owner uploads an avatar",
+			callerID: "user-1",
+			role:     "user",
+			data:     []byte("png bytes"),
+			setupMock: func(m *MockUserService) {
+				m.On("UploadAvatar", mock.Anything, "user-1", []byte("png bytes")).
+					Return(&users.User{ID: "user-1", FirstName: "John", AvatarKey: &avatarKey}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:     "admin uploads for another user",
+			callerID: "admin-1",
+			role:     "admin",
+			data:     []byte("png bytes"),
+			setupMock: func(m *MockUserService) {
+				m.On("UploadAvatar", mock.Anything, "user-1", mock.Anything).
+					Return(&users.User{ID: "user-1", AvatarKey: &avatarKey}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "anonymous",
+			data:           []byte("png bytes"),
+			setupMock:      func(m *MockUserService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "other user",
+			callerID:       "user-2",
+			role:           "user",
+			data:           []byte("png bytes"),
+			setupMock:      func(m *MockUserService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing file",
+			callerID:       "user-1",
+			role:           "user",
+			setupMock:      func(m *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "file too large",
+			callerID:       "user-1",
+			role:           "user",
+			data:           bytes.Repeat([]byte("x"), 2048),
+			setupMock:      func(m *MockUserService) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:     "not an image",
+			callerID: "user-1",
+			role:     "user",
+			data:     []byte("<svg/>"),
+			setupMock: func(m *MockUserService) {
+				m.On("UploadAvatar", mock.Anything, "user-1", mock.Anything).Return(nil, users.ErrInvalidAvatar)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockUserService)
+			tt.setupMock(service)
+
+			router := chi.NewRouter()
+			NewHandler(service, logger, tokens.FromSecret(avatarTestSecret), WithMaxAvatarBytes(1024)).RegisterRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, avatarRequest(t, http.MethodPut, "/users/user-1/avatar", tt.callerID, tt.role, tt.data))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			service.AssertExpectations(t)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response UserResponse
+				require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+				require.NotNil(t, response.AvatarURL)
+				assert.Equal(t, "/api/v1/users/user-1/avatar", *response.AvatarURL)
+				assert.Equal(t, "/api/v1/users/user-1/avatar/thumbnail", *response.AvatarThumbnailURL)
+			}
+		})
+	}
+}
+
+func TestDeleteAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := new(MockUserService)
+	service.On("DeleteAvatar", mock.Anything, "user-1").Return(nil).Once()
+	service.On("DeleteAvatar", mock.Anything, "user-1").Return(users.ErrNoAvatar)
+
+	router := chi.NewRouter()
+	NewHandler(service, logger, tokens.FromSecret(avatarTestSecret)).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, avatarRequest(t, http.MethodDelete, "/users/user-1/avatar", "user-1", "user", nil))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, avatarRequest(t, http.MethodDelete, "/users/user-1/avatar", "user-1", "user", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestGetAvatar(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := new(MockUserService)
+	service.On("AvatarURL", mock.Anything, "user-1", "standard").Return("/api/v1/media/avatars/user-1/u/standard.jpg", nil)
+	service.On("AvatarURL", mock.Anything, "user-1", "thumbnail").Return("/api/v1/media/avatars/user-1/u/thumbnail.jpg", nil)
+	service.On("AvatarURL", mock.Anything, "user-2", "standard").Return("", users.ErrNoAvatar)
+
+	router := chi.NewRouter()
+	NewHandler(service, logger, tokens.FromSecret(avatarTestSecret)).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/user-1/avatar", nil))
+	assert.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "/api/v1/media/avatars/user-1/u/standard.jpg", rr.Header().Get("Location"))
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/user-1/avatar/thumbnail", nil))
+	assert.Equal(t, "/api/v1/media/avatars/user-1/u/thumbnail.jpg", rr.Header().Get("Location"))
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users/user-2/avatar", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -1,11 +1,13 @@
 package users
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/captcha"
 	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/platform/http/middleware"
 	"time"
 )
 
@@ -18,6 +20,26 @@ var (
 	ErrRoleRequired      = errors.New("role is required")
 )
 
+type createUserRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	Role      string `json:"role"`
+	IsActive  *bool  `json:"is_active"` // Optional
+}
+
+func (r createUserRequest) toDomain() domainUser.CreateUserRequest {
+	return domainUser.CreateUserRequest{
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Email:     r.Email,
+		Password:  r.Password,
+		Role:      r.Role,
+		IsActive:  r.IsActive,
+	}
+}
+
 type createUserResponse struct {
 	ID        string    `json:"id"`
 	FirstName string    `json:"first_name"`
@@ -29,14 +51,30 @@ type createUserResponse struct {
 }
 
 func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
-	var req domainUser.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("[create_user_handler] Invalid JSON", "error", err)
-		h.responseWriter.WriteError(w, "Invalid request body", http.StatusBadRequest)
+	if h.captcha != nil {
+		err := h.captcha.Verify(r.Context(), r.Header.Get("X-Captcha-Token"), middleware.ClientIP(r))
+		switch {
+		case errors.Is(err, captcha.ErrMissingToken):
+			h.responseWriter.WriteError(w, captcha.ErrMissingToken.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, captcha.ErrInvalidToken):
+			h.responseWriter.WriteError(w, captcha.ErrInvalidToken.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			h.logger.Error("[create_user_handler] CAPTCHA verification failed", "error", err)
+			h.responseWriter.WriteError(w, "CAPTCHA verification is unavailable", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	var req createUserRequest
+	if appErr := request.DecodeJSON(w, r, &req, request.WithMaxBytes(maxCredentialsBytes)); appErr != nil {
+		h.logger.Error("[create_user_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
-	savedUser, err := h.userService.CreateUser(r.Context(), req)
+	savedUser, err := h.userService.CreateUser(r.Context(), req.toDomain())
 	if err != nil {
 		// Validation errors
 		switch err.Error() {
@@ -79,7 +117,7 @@ func (h *Handler) handleCreateUserServiceError(w http.ResponseWriter, err error)
 
 	var appErr *appErrors.AppError
 	if errors.As(err, &appErr) {
-		h.responseWriter.WriteError(w, appErr.Message, appErr.StatusCode)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
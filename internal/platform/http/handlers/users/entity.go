@@ -1,5 +1,7 @@
 package users
 
+import "time"
+
 type UserProfileResponse struct {
 	User    UserResponse                  `json:"user"`
 	Stats   UserProfileStatsResponse      `json:"stats"`
@@ -9,11 +11,46 @@ type UserProfileResponse struct {
 }
 
 type UserProfileStatsResponse struct {
-	TotalRatings      int64            `json:"total_ratings"`
-	AverageScore      float64          `json:"average_score"`
-	ScoreDistribution map[string]int64 `json:"score_distribution"` // String keys for JSON
-	FavoriteGenre     string           `json:"favorite_genre"`
-	GenreBreakdown    map[string]int64 `json:"genre_breakdown"`
+	TotalRatings        int64                  `json:"total_ratings"`
+	AverageScore        float64                `json:"average_score"`
+	ScoreDistribution   map[string]int64       `json:"score_distribution"` // String keys for JSON
+	FavoriteGenre       string                 `json:"favorite_genre"`
+	RecentFavoriteGenre string                 `json:"recent_favorite_genre"`
+	GenreBreakdown      map[string]int64       `json:"genre_breakdown"`
+	RatingsPerMonth     []MonthlyCountResponse `json:"ratings_per_month,omitempty"`
+	LongestStreak       *StreakResponse        `json:"longest_streak,omitempty"`
+	FavoriteDecade      int                    `json:"favorite_decade,omitempty"`
+	Lists               *ListCountsResponse    `json:"lists,omitempty"`
+}
+
+type MonthlyCountResponse struct {
+	Month string `json:"month"` // YYYY-MM
+	Count int64  `json:"count"`
+}
+
+type StreakResponse struct {
+	Days  int    `json:"days"`
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`
+}
+
+type YearInReviewResponse struct {
+	UserID          string                        `json:"user_id"`
+	Year            int                           `json:"year"`
+	TotalRatings    int64                         `json:"total_ratings"`
+	AverageScore    float64                       `json:"average_score"`
+	RatingsPerMonth []MonthlyCountResponse        `json:"ratings_per_month"`
+	LongestStreak   *StreakResponse               `json:"longest_streak,omitempty"`
+	TopDecade       int                           `json:"top_decade,omitempty"`
+	TopGenre        string                        `json:"top_genre,omitempty"`
+	TopDirector     string                        `json:"top_director,omitempty"`
+	TopRated        []UserRatingWithMovieResponse `json:"top_rated"`
+}
+
+type ListCountsResponse struct {
+	Total   int64 `json:"total"`
+	Public  int64 `json:"public"`
+	Private int64 `json:"private"`
 }
 
 type UserRatingWithMovieResponse struct {
@@ -35,3 +72,45 @@ type UserRatingWithMovieResponse struct {
 	TotalRatings  int64   `json:"total_ratings"`
 	UserVsAverage string  `json:"user_vs_average"`
 }
+
+// PreferencesResponse is what the user picked during onboarding
+type PreferencesResponse struct {
+	UserID    string    `json:"user_id"`
+	Genres    []string  `json:"genres"`
+	Decades   []int     `json:"decades"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HomeResponse is a user's personalized homepage
+type HomeResponse struct {
+	UserID  string          `json:"user_id"`
+	Shelves []ShelfResponse `json:"shelves"`
+}
+
+// ShelfResponse is a row of movies picked for one reason: Genre is the
+// favorite genre of the top picks, BecauseOf the rated movie that similar
+// movies were picked by
+type ShelfResponse struct {
+	ID        string               `json:"id"`
+	Title     string               `json:"title"`
+	Genre     string               `json:"genre,omitempty"`
+	BecauseOf *ShelfMovieResponse  `json:"because_of,omitempty"`
+	Movies    []ShelfMovieResponse `json:"movies"`
+}
+
+type ShelfMovieResponse struct {
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	ReleaseYear int                 `json:"release_year"`
+	Genre       string              `json:"genre"`
+	Director    string              `json:"director"`
+	PosterURL   *string             `json:"poster_url,omitempty"`
+	Preference  *PreferenceResponse `json:"preference,omitempty"`
+}
+
+// PreferenceResponse is how the users who rated both a card's movie and
+// the shelf's because_of scored them
+type PreferenceResponse struct {
+	SharedRaters  int64 `json:"shared_raters"`
+	PreferPercent int   `json:"prefer_percent"` // Who scored the card's movie higher
+}
@@ -0,0 +1,28 @@
+package users
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		createUserRequest{},
+		loginRequest{},
+		patchUserRequest{},
+		savePreferencesRequest{},
+		createUserResponse{},
+		loginResponse{},
+		UserResponse{},
+		ListUsersResponse{},
+		UserProfileResponse{},
+		YearInReviewResponse{},
+		ListSessionsResponse{},
+		RevokeSessionsResponse{},
+		PreferencesResponse{},
+		HomeResponse{},
+	))
+}
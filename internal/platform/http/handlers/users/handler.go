@@ -2,9 +2,18 @@ package users
 
 import (
 	"log/slog"
+	"net/http"
 	"os"
+	"thermondo/internal/pkg/captcha"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	oidcService "thermondo/internal/platform/service/oidc"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+	sessionService "thermondo/internal/platform/service/session"
 	userService "thermondo/internal/platform/service/user"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -13,29 +22,156 @@ type Handler struct {
 	userService    userService.UserService
 	logger         *slog.Logger
 	responseWriter *response.Writer
-	jwtSecret      string
+	keys           *tokens.Keys
+	auth           *middleware.AuthMiddleware
+	maxAvatarBytes int64
+	signupLimiter  *ratelimit.Limiter
+	captcha        captcha.Verifier
+	sessions       sessionService.Service
+	home           recommendationService.Service
+	oidc           oidcService.Service
+	// recentAuthWindow is how recently users must have signed in to
+	// change how they sign in, or to step up without their password
+	recentAuthWindow time.Duration
 }
 
-func NewHandler(userService userService.UserService, logger *slog.Logger, jwtSecret string) *Handler {
+// Option configures optional behaviour of the user handler
+type Option func(*Handler)
+
+// WithMaxAvatarBytes sets the largest avatar upload accepted
+func WithMaxAvatarBytes(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxAvatarBytes = n
+		}
+	}
+}
+
+// WithSignupRateLimit caps registrations per client IP
+func WithSignupRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(h *Handler) {
+		h.signupLimiter = limiter
+	}
+}
+
+// WithCaptchaVerifier requires a solved CAPTCHA, sent in the X-Captcha-Token
+// header, to register
+func WithCaptchaVerifier(verifier captcha.Verifier) Option {
+	return func(h *Handler) {
+		h.captcha = verifier
+	}
+}
+
+// WithSessions ties every login to a revocable session and enables the
+// session management endpoints
+func WithSessions(sessions sessionService.Service) Option {
+	return func(h *Handler) {
+		h.sessions = sessions
+	}
+}
+
+// WithHome enables the personalized homepage and onboarding preferences
+// endpoints
+func WithHome(home recommendationService.Service) Option {
+	return func(h *Handler) {
+		h.home = home
+	}
+}
+
+// WithOIDC enables signing in with the OpenID Connect providers the service
+// is configured with
+func WithOIDC(oidc oidcService.Service) Option {
+	return func(h *Handler) {
+		h.oidc = oidc
+	}
+}
+
+// WithRecentAuthWindow sets how recently users must have signed in to link
+// or unlink sign-in providers, or to get a step-up token without their
+// password. It defaults to DefaultRecentAuthWindow.
+func WithRecentAuthWindow(d time.Duration) Option {
+	return func(h *Handler) {
+		h.recentAuthWindow = d
+	}
+}
+
+func NewHandler(userService userService.UserService, logger *slog.Logger, keys *tokens.Keys, opts ...Option) *Handler {
 	if logger == nil {
 		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelInfo,
 		}))
 	}
 
-	return &Handler{
-		userService:    userService,
-		logger:         logger,
-		responseWriter: response.NewWriter(logger),
-		jwtSecret:      jwtSecret,
+	responseWriter := response.NewWriter(logger)
+	handler := &Handler{
+		userService:      userService,
+		logger:           logger,
+		responseWriter:   responseWriter,
+		keys:             keys,
+		maxAvatarBytes:   DefaultMaxAvatarBytes,
+		recentAuthWindow: DefaultRecentAuthWindow,
 	}
+
+	for _, opt := range opts {
+		opt(handler)
+	}
+
+	var authOptions []middleware.AuthOption
+	if handler.sessions != nil {
+		authOptions = append(authOptions, middleware.WithSessionValidator(handler.sessions))
+	}
+	handler.auth = middleware.NewAuthMiddleware(keys, responseWriter, authOptions...)
+
+	return handler
 }
 
 func (h *Handler) RegisterRoutes(router chi.Router) {
+	var signupMiddleware []func(http.Handler) http.Handler
+	if h.signupLimiter != nil {
+		signupMiddleware = append(signupMiddleware, middleware.RateLimitByIP(h.signupLimiter, h.responseWriter))
+	}
+
 	router.Route("/users", func(r chi.Router) {
-		r.Post("/", h.CreateUser)
+		r.With(signupMiddleware...).Post("/", h.CreateUser)
 		r.Post("/login", h.Login)
 		r.Get("/", h.ListUsers)
 		r.Get("/{id}", h.GetUser)
+		r.With(h.auth.Authenticate).Patch("/{id}", h.PatchUser)
+		r.With(h.auth.AuthenticateStepUp(ScopeEmailChange)).Put("/{id}/email", h.ChangeEmail)
+		r.Get("/{id}/wrapped", h.GetWrapped)
+
+		r.Get("/{id}/avatar", h.GetAvatar)
+		r.Get("/{id}/avatar/{size}", h.GetAvatar)
+		r.With(h.auth.Authenticate).Put("/{id}/avatar", h.UploadAvatar)
+		r.With(h.auth.Authenticate).Delete("/{id}/avatar", h.DeleteAvatar)
+
+		r.With(h.auth.Authenticate).Get("/{id}/settings", h.GetSettings)
+		r.With(h.auth.Authenticate).Put("/{id}/settings", h.SaveSettings)
+
+		if h.sessions != nil {
+			r.With(h.auth.Authenticate).Get("/{id}/sessions", h.ListSessions)
+			r.With(h.auth.Authenticate).Delete("/{id}/sessions", h.RevokeAllSessions)
+			r.With(h.auth.Authenticate).Delete("/{id}/sessions/{sessionId}", h.RevokeSession)
+		}
+		if h.home != nil {
+			r.With(h.auth.Authenticate).Get("/{id}/home", h.GetHome)
+			r.With(h.auth.Authenticate).Post("/{id}/preferences", h.SavePreferences)
+		}
+		if h.oidc != nil {
+			recent := h.auth.RequireRecentAuth(h.recentAuthWindow)
+			r.With(h.auth.Authenticate).Get("/{id}/identities", h.ListIdentities)
+			r.With(h.auth.Authenticate, recent).Post("/{id}/identities/{provider}", h.LinkIdentity)
+			r.With(h.auth.Authenticate, recent).Delete("/{id}/identities/{provider}", h.UnlinkIdentity)
+		}
 	})
+
+	router.With(h.auth.Authenticate).Post("/auth/token/exchange", h.ExchangeToken)
+
+	if h.oidc != nil {
+		router.Route("/auth/oidc/{provider}", func(r chi.Router) {
+			r.Get("/start", h.StartOIDC)
+			r.Get("/callback", h.OIDCCallback)
+			r.Post("/callback", h.OIDCCallback)
+		})
+	}
 }
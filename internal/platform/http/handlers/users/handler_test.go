@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +16,8 @@ import (
 	"time"
 
 	"thermondo/internal/pkg/password"
+	"thermondo/internal/pkg/tokens"
+	userService "thermondo/internal/platform/service/user"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -62,13 +65,13 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "invalid request body",
 			requestBody: map[string]interface{}{
-				"firstName": 123, // Invalid type for firstName
+				"firstName": 123, // Not a member of the request
 			},
 			mockSetup: func(service *MockUserService) {
 				service.On("CreateUser", mock.Anything, mock.AnythingOfType("users.CreateUserRequest")).Return(nil, errors.New("invalid input"))
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   response.ErrorResponse{Error: "Invalid request body"},
+			expectedBody:   response.ErrorResponse{Error: `Invalid JSON payload: unknown field "firstName"`},
 		},
 		{
 			name: "missing required fields",
@@ -134,7 +137,7 @@ func TestCreateUser(t *testing.T) {
 			mockService := new(MockUserService)
 			tt.mockSetup(mockService)
 			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-			handler := NewHandler(mockService, logger, "test-secret")
+			handler := NewHandler(mockService, logger, tokens.FromSecret("test-secret"))
 
 			// Create request
 			var reqBody []byte
@@ -164,10 +167,11 @@ func TestCreateUser(t *testing.T) {
 				assert.Equal(t, resp.Role, actual.Role)
 				assert.Equal(t, resp.IsActive, actual.IsActive)
 			} else if resp, ok := tt.expectedBody.(response.ErrorResponse); ok {
-				var actual response.ErrorResponse
+				var actual response.Problem
 				err := json.Unmarshal(w.Body.Bytes(), &actual)
 				assert.NoError(t, err)
-				assert.Equal(t, resp, actual)
+				assert.Equal(t, resp.Error, actual.Detail)
+				assert.Equal(t, tt.expectedStatus, actual.Status)
 			} else {
 				t.Fatalf("unexpected expectedBody type: %T", tt.expectedBody)
 			}
@@ -178,7 +182,7 @@ func TestCreateUser(t *testing.T) {
 func TestLogin(t *testing.T) {
 	mockService := new(MockUserService)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-	handler := NewHandler(mockService, logger, "test-secret")
+	handler := NewHandler(mockService, logger, tokens.FromSecret("test-secret"))
 
 	// Create a properly hashed password for testing
 	hashedPassword, _ := password.HashPassword("password123")
@@ -244,7 +248,7 @@ func TestLogin(t *testing.T) {
 				// No mock setup needed for invalid JSON
 			},
 			expectedStatus: http.StatusBadRequest,
-			expectedBody:   "Invalid JSON",
+			expectedBody:   "Invalid JSON payload: request body must be a JSON object",
 		},
 	}
 
@@ -275,10 +279,10 @@ func TestLogin(t *testing.T) {
 				assert.NotEmpty(t, response.Token)
 				assert.Greater(t, response.ExpiresAt, time.Now().Unix())
 			} else {
-				var response map[string]string
-				err := json.NewDecoder(w.Body).Decode(&response)
+				var problem response.Problem
+				err := json.NewDecoder(w.Body).Decode(&problem)
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedBody.(string), response["error"])
+				assert.Equal(t, tt.expectedBody.(string), problem.Detail)
 			}
 
 			mockService.AssertExpectations(t)
@@ -347,7 +351,7 @@ func TestGetUser(t *testing.T) {
 			mockService := new(MockUserService)
 			tt.mockSetup(mockService)
 			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-			handler := NewHandler(mockService, logger, "test-secret")
+			handler := NewHandler(mockService, logger, tokens.FromSecret("test-secret"))
 
 			// Create request
 			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.userID, nil)
@@ -370,10 +374,11 @@ func TestGetUser(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, resp, actual)
 			} else if resp, ok := tt.expectedBody.(response.ErrorResponse); ok {
-				var actual response.ErrorResponse
+				var actual response.Problem
 				err := json.Unmarshal(w.Body.Bytes(), &actual)
 				assert.NoError(t, err)
-				assert.Equal(t, resp, actual)
+				assert.Equal(t, resp.Error, actual.Detail)
+				assert.Equal(t, tt.expectedStatus, actual.Status)
 			} else {
 				t.Fatalf("unexpected expectedBody type: %T", tt.expectedBody)
 			}
@@ -393,7 +398,7 @@ func TestListUsers(t *testing.T) {
 			name:        "successful user list retrieval",
 			queryParams: "page=1&limit=10",
 			mockSetup: func(service *MockUserService) {
-				users := []*users.User{
+				list := []*users.User{
 					{
 						ID:        "test-id-1",
 						FirstName: "John",
@@ -415,7 +420,7 @@ func TestListUsers(t *testing.T) {
 						UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 					},
 				}
-				service.On("ListUsers", mock.Anything, 1, 10).Return(users, 2, nil)
+				service.On("ListUsers", mock.Anything, users.ListFilter{}, 1, 10).Return(list, 2, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: ListUsersResponse{
@@ -485,11 +490,71 @@ func TestListUsers(t *testing.T) {
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   response.ErrorResponse{Error: "User not found"},
 		},
+		{
+			name:        "filters and sorting",
+			queryParams: "role=admin&is_active=false&created_after=2023-01-01&email_prefix=jane&sort_by=last_name&order=DESC",
+			mockSetup: func(service *MockUserService) {
+				service.On("ListUsers", mock.Anything, mock.MatchedBy(func(filter users.ListFilter) bool {
+					return filter.Role == users.RoleAdmin &&
+						filter.IsActive != nil && !*filter.IsActive &&
+						filter.CreatedAfter != nil && filter.CreatedAfter.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+						filter.EmailPrefix == "jane" && filter.SortBy == "last_name" && filter.Order == "desc"
+				}), 1, 20).Return([]*users.User{}, 0, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: ListUsersResponse{
+				Pagination: &Pagination{Page: 1, Limit: 20},
+			},
+		},
+		{
+			name:           "invalid role",
+			queryParams:    "role=owner",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "role must be one of: admin, user"},
+		},
+		{
+			name:           "invalid is_active",
+			queryParams:    "is_active=maybe",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "is_active must be true or false"},
+		},
+		{
+			name:           "invalid created_after",
+			queryParams:    "created_after=yesterday",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "created_after must be a date (YYYY-MM-DD) or RFC3339 timestamp"},
+		},
+		{
+			name:           "invalid sort_by",
+			queryParams:    "sort_by=password",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "sort_by must be one of: id, email, last_name, created_at"},
+		},
+		{
+			name:           "invalid order",
+			queryParams:    "order=sideways",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "order must be asc or desc"},
+		},
+		{
+			name:        "email filter while emails are encrypted",
+			queryParams: "email_prefix=jo",
+			mockSetup: func(service *MockUserService) {
+				service.On("ListUsers", mock.Anything, users.ListFilter{EmailPrefix: "jo"}, 1, 20).Return([]*users.User(nil), 0, users.ErrEmailNotSearchable)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: users.ErrEmailNotSearchable.Error()},
+		},
 		{
 			name:        "internal server error",
 			queryParams: "page=1&limit=10",
 			mockSetup: func(service *MockUserService) {
-				service.On("ListUsers", mock.Anything, 1, 10).Return([]*users.User{}, 0, errors.New("database error"))
+				service.On("ListUsers", mock.Anything, users.ListFilter{}, 1, 10).Return([]*users.User{}, 0, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   response.ErrorResponse{Error: "Failed to get users"},
@@ -501,7 +566,7 @@ func TestListUsers(t *testing.T) {
 			mockService := new(MockUserService)
 			tt.mockSetup(mockService)
 			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
-			handler := NewHandler(mockService, logger, "test-secret")
+			handler := NewHandler(mockService, logger, tokens.FromSecret("test-secret"))
 			req := httptest.NewRequest(http.MethodGet, "/users?"+tt.queryParams, nil)
 			w := httptest.NewRecorder()
 			handler.ListUsers(w, req)
@@ -518,10 +583,11 @@ func TestListUsers(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, resp, actual)
 			} else if resp, ok := tt.expectedBody.(response.ErrorResponse); ok {
-				var actual response.ErrorResponse
+				var actual response.Problem
 				err := json.Unmarshal(w.Body.Bytes(), &actual)
 				assert.NoError(t, err)
-				assert.Equal(t, resp, actual)
+				assert.Equal(t, resp.Error, actual.Detail)
+				assert.Equal(t, tt.expectedStatus, actual.Status)
 			} else {
 				t.Fatalf("unexpected expectedBody type: %T", tt.expectedBody)
 			}
@@ -533,3 +599,114 @@ func TestListUsers(t *testing.T) {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestPatchUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		callerID       string
+		contentType    string
+		body           string
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:        "successful patch",
+			contentType: "application/merge-patch+json",
+			body:        `{"last_name": "Smith"}`,
+			mockSetup: func(service *MockUserService) {
+				service.On("PatchUser", mock.Anything, "test-id", mock.MatchedBy(func(req userService.PatchUserRequest) bool {
+					return req.LastName.Value == "Smith" && !req.FirstName.Set
+				})).Return(&users.User{ID: "test-id", FirstName: "John", LastName: "Smith", Email: "john@example.com"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "role cannot be patched",
+			contentType:    "application/merge-patch+json",
+			body:           `{"role": "admin"}`,
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "null for a required field",
+			contentType: "application/merge-patch+json",
+			body:        `{"first_name": null}`,
+			mockSetup: func(service *MockUserService) {
+				service.On("PatchUser", mock.Anything, "test-id", mock.Anything).Return(nil, fmt.Errorf("first_name %w", userService.ErrNullField))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "email takes a step-up token",
+			contentType:    "application/json",
+			body:           `{"email": "jane@example.com"}`,
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "active flag is for admins",
+			contentType:    "application/merge-patch+json",
+			body:           `{"is_active": false}`,
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "someone else's profile",
+			callerID:       "other-id",
+			contentType:    "application/merge-patch+json",
+			body:           `{"last_name": "Smith"}`,
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:        "user not found",
+			contentType: "application/merge-patch+json",
+			body:        `{"last_name": "Smith"}`,
+			mockSetup: func(service *MockUserService) {
+				service.On("PatchUser", mock.Anything, "test-id", mock.Anything).Return(nil, users.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "unsupported content type",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           `last_name=Smith`,
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserService)
+			tt.mockSetup(mockService)
+			logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+			router := chi.NewRouter()
+			NewHandler(mockService, logger, tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+
+			callerID := tt.callerID
+			if callerID == "" {
+				callerID = "test-id"
+			}
+			req := settingsRequest(t, http.MethodPatch, "/users/test-id", callerID, "user", tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchUserRequiresAuthentication(t *testing.T) {
+	router := chi.NewRouter()
+	NewHandler(new(MockUserService), slog.New(slog.NewTextHandler(os.Stdout, nil)), tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/test-id", bytes.NewBufferString(`{"last_name": "Smith"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
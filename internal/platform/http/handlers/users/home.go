@@ -0,0 +1,70 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetHome handles GET /users/{id}/home, the shelves of movies picked for
+// the user's homepage. As the shelves reveal the user's lists and taste,
+// only the user and admins may see them.
+func (h *Handler) GetHome(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only see your own home", http.StatusForbidden)
+		return
+	}
+
+	home, err := h.home.GetHome(r.Context(), id)
+	if err != nil {
+		h.logger.Error("[get_home_handler] Failed to load home", "error", err, "user_id", id)
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) {
+			h.responseWriter.WriteAppError(w, appErr)
+			return
+		}
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := HomeResponse{UserID: home.UserID, Shelves: make([]ShelfResponse, 0, len(home.Shelves))}
+	for _, shelf := range home.Shelves {
+		shelfResponse := ShelfResponse{
+			ID:     string(shelf.ID),
+			Title:  shelf.Title,
+			Genre:  shelf.Genre,
+			Movies: make([]ShelfMovieResponse, 0, len(shelf.Movies)),
+		}
+		if shelf.BecauseOf != nil {
+			becauseOf := shelfMovieToResponse(shelf.BecauseOf)
+			shelfResponse.BecauseOf = &becauseOf
+		}
+		for _, movie := range shelf.Movies {
+			shelfResponse.Movies = append(shelfResponse.Movies, shelfMovieToResponse(movie))
+		}
+		response.Shelves = append(response.Shelves, shelfResponse)
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+func shelfMovieToResponse(movie *recommendationService.ShelfMovie) ShelfMovieResponse {
+	response := ShelfMovieResponse{
+		ID:          movie.ID,
+		Title:       movie.Title,
+		ReleaseYear: movie.ReleaseYear,
+		Genre:       movie.Genre,
+		Director:    movie.Director,
+		PosterURL:   movie.PosterURL,
+	}
+	if movie.Preference != nil {
+		response.Preference = &PreferenceResponse{
+			SharedRaters:  movie.Preference.SharedRaters,
+			PreferPercent: movie.Preference.PreferPercent,
+		}
+	}
+	return response
+}
@@ -0,0 +1,81 @@
+package users
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupHomeRouter(home *MockHomeService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockUserService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret),
+		WithHome(home),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestGetHome(t *testing.T) {
+	t.Run("own home", func(t *testing.T) {
+		home := new(MockHomeService)
+		home.On("GetHome", mock.Anything, "user-1").Return(&recommendationService.Home{
+			UserID: "user-1",
+			Shelves: []*recommendationService.Shelf{{
+				ID:        recommendationService.ShelfBecauseYouRated,
+				Title:     "Because you rated Heat",
+				BecauseOf: &recommendationService.ShelfMovie{ID: "movie-heat", Title: "Heat"},
+				Movies: []*recommendationService.ShelfMovie{{
+					ID: "movie-thief", Title: "Thief", ReleaseYear: 1981,
+					Preference: &recommendationService.Preference{SharedRaters: 4, PreferPercent: 75},
+				}},
+			}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, sessionRequest(t, http.MethodGet, "/users/user-1/home", "user-1", "user", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":"user-1","shelves":[{
+			"id":"because_you_rated","title":"Because you rated Heat",
+			"because_of":{"id":"movie-heat","title":"Heat","release_year":0,"genre":"","director":""},
+			"movies":[{"id":"movie-thief","title":"Thief","release_year":1981,"genre":"","director":"",
+				"preference":{"shared_raters":4,"prefer_percent":75}}]
+		}]}`, w.Body.String())
+	})
+
+	t.Run("someone else's home", func(t *testing.T) {
+		home := new(MockHomeService)
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, sessionRequest(t, http.MethodGet, "/users/user-1/home", "user-2", "user", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		home.AssertNotCalled(t, "GetHome", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		home := new(MockHomeService)
+		home.On("GetHome", mock.Anything, "user-9").Return(nil, appErrors.NewNotFoundError("User not found"))
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, sessionRequest(t, http.MethodGet, "/users/user-9/home", "admin-1", "admin", ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("without a token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupHomeRouter(new(MockHomeService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/home", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
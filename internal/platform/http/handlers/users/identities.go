@@ -0,0 +1,120 @@
+package users
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/platform/http/middleware"
+	oidcService "thermondo/internal/platform/service/oidc"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DefaultRecentAuthWindow is how long after signing in a user may change
+// how they sign in without signing in again
+const DefaultRecentAuthWindow = 10 * time.Minute
+
+type IdentityResponse struct {
+	Provider string `json:"provider"`
+	LinkedAt string `json:"linked_at"`
+}
+
+type ListIdentitiesResponse struct {
+	Identities []IdentityResponse `json:"identities"`
+}
+
+type linkIdentityResponse struct {
+	AuthURL string `json:"auth_url"`
+}
+
+type unlinkIdentityRequest struct {
+	Password string `json:"password"`
+}
+
+// ListIdentities handles GET /users/{id}/identities. Users may only see
+// their own providers unless they are admins.
+func (h *Handler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only manage your own sign-in providers", http.StatusForbidden)
+		return
+	}
+
+	identities, err := h.oidc.Identities(r.Context(), id)
+	if err != nil {
+		h.logger.Error("[list_identities_handler] Failed to list identities", "error", err, "user_id", id)
+		h.handleOIDCServiceError(w, err)
+		return
+	}
+
+	response := ListIdentitiesResponse{Identities: make([]IdentityResponse, 0, len(identities))}
+	for _, identity := range identities {
+		response.Identities = append(response.Identities, IdentityResponse{
+			Provider: identity.Provider,
+			LinkedAt: identity.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// LinkIdentity handles POST /users/{id}/identities/{provider}. It returns
+// where to send the user to sign in with the provider; the callback then
+// links it to the account, merging in the account it signed in to before,
+// if any. Only users themselves, signed in recently, may link.
+func (h *Handler) LinkIdentity(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	provider := chi.URLParam(r, "provider")
+	if principal, ok := middleware.PrincipalFrom(r.Context()); !ok || !principal.Is(id) {
+		h.responseWriter.WriteError(w, "You can only link sign-in providers to your own account", http.StatusForbidden)
+		return
+	}
+
+	started, err := h.oidc.StartLink(r.Context(), provider, id)
+	if err != nil {
+		h.logger.Error("[link_identity_handler] Failed to start linking", "error", err, "user_id", id, "provider", provider)
+		h.handleOIDCServiceError(w, err)
+		return
+	}
+
+	// The browser follows auth_url and comes back to the provider's
+	// callback, so the binding is scoped to it like at sign-in
+	base := strings.TrimSuffix(r.URL.Path, "/users/"+id+"/identities/"+provider)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    started.Binding,
+		Path:     base + "/auth/oidc/" + url.PathEscape(provider),
+		MaxAge:   int(oidcService.StateLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	h.responseWriter.WriteSuccess(w, linkIdentityResponse{AuthURL: started.AuthURL}, http.StatusOK)
+}
+
+// UnlinkIdentity handles DELETE /users/{id}/identities/{provider}. Removing
+// the last provider takes the account's password in the body.
+func (h *Handler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	provider := chi.URLParam(r, "provider")
+	if principal, ok := middleware.PrincipalFrom(r.Context()); !ok || !principal.Is(id) {
+		h.responseWriter.WriteError(w, "You can only unlink sign-in providers from your own account", http.StatusForbidden)
+		return
+	}
+
+	var req unlinkIdentityRequest
+	if r.ContentLength != 0 {
+		if appErr := request.DecodeJSON(w, r, &req, request.WithMaxBytes(maxCredentialsBytes)); appErr != nil {
+			h.responseWriter.WriteAppError(w, appErr)
+			return
+		}
+	}
+
+	if err := h.oidc.Unlink(r.Context(), id, provider, req.Password); err != nil {
+		h.logger.Error("[unlink_identity_handler] Failed to unlink", "error", err, "user_id", id, "provider", provider)
+		h.handleOIDCServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,131 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	oidcService "thermondo/internal/platform/service/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// identityRequest is made by callerID, who signed in at signedInAt
+func identityRequest(t *testing.T, method, target, body, callerID, role string, signedInAt time.Time) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": callerID,
+		"role":    role,
+		"iat":     signedInAt.Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(sessionTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestListIdentities(t *testing.T) {
+	oidc := new(MockOIDCService)
+	linkedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	oidc.On("Identities", mock.Anything, "user-1").Return([]*users.Identity{
+		{Provider: "google", Subject: "google-1", UserID: "user-1", CreatedAt: linkedAt},
+	}, nil)
+	router := setupOIDCRouter(oidc, new(MockSessionService))
+
+	// Listing doesn't need a recent sign-in, and admins may look
+	for _, caller := range []struct{ id, role string }{{"user-1", "user"}, {"admin-1", "admin"}} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, identityRequest(t, http.MethodGet, "/users/user-1/identities", "", caller.id, caller.role, time.Now().Add(-24*time.Hour)))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp ListIdentitiesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, []IdentityResponse{{Provider: "google", LinkedAt: "2024-01-01T12:00:00Z"}}, resp.Identities)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, identityRequest(t, http.MethodGet, "/users/user-1/identities", "", "user-2", "user", time.Now()))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestLinkIdentity(t *testing.T) {
+	t.Run("recently signed in", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("StartLink", mock.Anything, "apple", "user-1").
+			Return(&oidcService.StartResponse{AuthURL: "https://appleid.apple.com/auth/authorize?state=s", Binding: "binding-1"}, nil)
+
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodPost, "/users/user-1/identities/apple", "", "user-1", "user", time.Now()))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp linkIdentityResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "https://appleid.apple.com/auth/authorize?state=s", resp.AuthURL)
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "binding-1", cookies[0].Value)
+		assert.Equal(t, "/auth/oidc/apple", cookies[0].Path, "sent to the provider's callback")
+	})
+
+	t.Run("signed in too long ago", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodPost, "/users/user-1/identities/apple", "", "user-1", "user", time.Now().Add(-time.Hour)))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), string(appErrors.CodeReauthenticationRequired))
+		oidc.AssertNotCalled(t, "StartLink", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("another user's account", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodPost, "/users/user-1/identities/apple", "", "admin-1", "admin", time.Now()))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code, "not even admins link for others")
+		oidc.AssertNotCalled(t, "StartLink", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	t.Run("with a password", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("Unlink", mock.Anything, "user-1", "google", "correct horse").Return(nil)
+
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodDelete, "/users/user-1/identities/google", `{"password":"correct horse"}`, "user-1", "user", time.Now()))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		oidc.AssertExpectations(t)
+	})
+
+	t.Run("without a body", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("Unlink", mock.Anything, "user-1", "google", "").
+			Return(appErrors.NewForbiddenError("Confirm your password to unlink your last sign-in provider"))
+
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodDelete, "/users/user-1/identities/google", "", "user-1", "user", time.Now()))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("signed in too long ago", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, identityRequest(t, http.MethodDelete, "/users/user-1/identities/google", "", "user-1", "user", time.Now().Add(-time.Hour)))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		oidc.AssertNotCalled(t, "Unlink", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
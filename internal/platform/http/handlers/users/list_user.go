@@ -1,9 +1,12 @@
 package users
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"thermondo/internal/domain/users"
+	userService "thermondo/internal/platform/service/user"
 	"time"
 )
 
@@ -26,8 +29,36 @@ type UserResponse struct {
 	Email     string `json:"email"`
 	Role      string `json:"role"`
 	IsActive  bool   `json:"is_active"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	IsCritic  bool   `json:"is_critic"`
+	// ContentMode is kids when the user only sees kids content
+	ContentMode string `json:"content_mode"`
+	// Avatar URLs are stable addresses that redirect to the current image
+	AvatarURL          *string `json:"avatar_url,omitempty"`
+	AvatarThumbnailURL *string `json:"avatar_thumbnail_url,omitempty"`
+	CreatedAt          string  `json:"created_at"`
+	UpdatedAt          string  `json:"updated_at"`
+}
+
+func userToResponse(user *users.User) UserResponse {
+	response := UserResponse{
+		ID:        strings.TrimSpace(user.ID.String()),
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Role:      string(user.Role),
+		IsActive:  user.IsActive,
+		IsCritic:  user.IsCritic,
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+	}
+	response.ContentMode = string(user.ContentMode)
+	if user.AvatarKey != nil {
+		avatarURL := userService.AvatarPath(users.UserID(response.ID), users.AvatarStandard)
+		thumbnailURL := userService.AvatarPath(users.UserID(response.ID), users.AvatarThumbnail)
+		response.AvatarURL = &avatarURL
+		response.AvatarThumbnailURL = &thumbnailURL
+	}
+	return response
 }
 
 func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
@@ -39,18 +70,7 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 			h.responseWriter.WriteError(w, "User not found", http.StatusNotFound)
 			return
 		}
-		userResponse := UserResponse{
-			ID:        strings.TrimSpace(user.ID.String()),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			Role:      string(user.Role),
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
-		}
-
-		h.responseWriter.WriteSuccess(w, userResponse, http.StatusOK)
+		h.responseWriter.WriteSuccess(w, userToResponse(user), http.StatusOK)
 		return
 	}
 
@@ -72,25 +92,26 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	filter, err := parseListFilter(r)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Get paginated users
-	users, total, err := h.userService.ListUsers(r.Context(), page, limit)
+	list, total, err := h.userService.ListUsers(r.Context(), filter, page, limit)
 	if err != nil {
+		if errors.Is(err, users.ErrEmailNotSearchable) {
+			h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		h.responseWriter.WriteError(w, "Failed to get users", http.StatusInternalServerError)
 		return
 	}
 
 	var userResponses []UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, UserResponse{
-			ID:        strings.TrimSpace(user.ID.String()),
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Email:     user.Email,
-			Role:      string(user.Role),
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
-		})
+	for _, user := range list {
+		userResponses = append(userResponses, userToResponse(user))
 	}
 
 	response := ListUsersResponse{
@@ -105,3 +126,57 @@ func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
 
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
 }
+
+// parseListFilter reads the listing filters and sort options. Unlike page
+// and limit, which fall back to defaults, an invalid value here is rejected
+// so a typo cannot silently widen the result set.
+func parseListFilter(r *http.Request) (users.ListFilter, error) {
+	query := r.URL.Query()
+	filter := users.ListFilter{
+		EmailPrefix: strings.TrimSpace(query.Get("email_prefix")),
+	}
+
+	if role := query.Get("role"); role != "" {
+		switch users.Role(role) {
+		case users.RoleAdmin, users.RoleUser:
+			filter.Role = users.Role(role)
+		default:
+			return filter, errors.New("role must be one of: admin, user")
+		}
+	}
+
+	if active := query.Get("is_active"); active != "" {
+		parsed, err := strconv.ParseBool(active)
+		if err != nil {
+			return filter, errors.New("is_active must be true or false")
+		}
+		filter.IsActive = &parsed
+	}
+
+	if after := query.Get("created_after"); after != "" {
+		parsed, err := time.Parse(time.RFC3339, after)
+		if err != nil {
+			parsed, err = time.Parse("2006-01-02", after)
+		}
+		if err != nil {
+			return filter, errors.New("created_after must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+		}
+		filter.CreatedAfter = &parsed
+	}
+
+	switch sortBy := query.Get("sort_by"); sortBy {
+	case "", "id", "email", "last_name", "created_at":
+		filter.SortBy = sortBy
+	default:
+		return filter, errors.New("sort_by must be one of: id, email, last_name, created_at")
+	}
+
+	switch order := strings.ToLower(query.Get("order")); order {
+	case "", "asc", "desc":
+		filter.Order = order
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	return filter, nil
+}
@@ -1,14 +1,21 @@
 package users
 
 import (
-	"encoding/json"
+	"fmt"
 	"net/http"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/request"
 	"thermondo/internal/pkg/password"
+	"thermondo/internal/platform/http/middleware"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// maxCredentialsBytes caps login and sign-up bodies, which only carry a few
+// short strings
+const maxCredentialsBytes = 4 << 10
+
 type loginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -21,9 +28,9 @@ type loginResponse struct {
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req loginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("[login_handler] Invalid JSON", "error", err)
-		h.responseWriter.WriteError(w, "Invalid JSON", http.StatusBadRequest)
+	if appErr := request.DecodeJSON(w, r, &req, request.WithMaxBytes(maxCredentialsBytes)); appErr != nil {
+		h.logger.Error("[login_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
@@ -42,26 +49,40 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate JWT token
-	expiresAt := time.Now().Add(24 * time.Hour)
+	response, err := h.issueToken(r, user)
+	if err != nil {
+		h.logger.Error("[login_handler] Failed to issue token", "error", err)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// issueToken signs user in on the requesting device, starting a session
+// when sessions are enabled
+func (h *Handler) issueToken(r *http.Request, user *domainUser.User) (*loginResponse, error) {
+	expiresAt := time.Now().Add(h.keys.Expiry())
 	claims := jwt.MapClaims{
 		"user_id": user.ID.String(),
 		"role":    string(user.Role),
-		"exp":     expiresAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(h.jwtSecret))
+	if h.sessions != nil {
+		session, err := h.sessions.Start(r.Context(), user.ID.String(), r.UserAgent(), middleware.ClientIP(r), expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start session: %w", err)
+		}
+		claims["sid"] = session.ID.String()
+	}
+
+	tokenString, err := h.keys.Issue(claims, expiresAt)
 	if err != nil {
-		h.logger.Error("[login_handler] Failed to generate token", "error", err)
-		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	response := loginResponse{
+	return &loginResponse{
 		Token:     tokenString,
 		ExpiresAt: expiresAt.Unix(),
-	}
-
-	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+	}, nil
 }
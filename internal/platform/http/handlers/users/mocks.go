@@ -4,7 +4,10 @@ import (
 	"context"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/recommendations"
 	"thermondo/internal/domain/users"
+	oidcService "thermondo/internal/platform/service/oidc"
+	recommendationService "thermondo/internal/platform/service/recommendation"
 	userService "thermondo/internal/platform/service/user"
 	"time"
 
@@ -64,17 +67,25 @@ func (m *MockUserService) FindUserByEmail(ctx context.Context, email string) (*u
 	return args.Get(0).(*users.User), args.Error(1)
 }
 
-func (m *MockUserService) ListUsers(ctx context.Context, page, limit int) ([]*users.User, int, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockUserService) ListUsers(ctx context.Context, filter users.ListFilter, page, limit int) ([]*users.User, int, error) {
+	args := m.Called(ctx, filter, page, limit)
 	return args.Get(0).([]*users.User), args.Int(1), args.Error(2)
 }
 
-func (m *MockUserService) GetUserProfile(ctx context.Context, req userService.UserProfileRequest) ([]*userService.UserRatingWithMovie, *userService.UserProfileStats, error) {
+func (m *MockUserService) PatchUser(ctx context.Context, id string, req userService.PatchUserRequest) (*users.User, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUserProfile(ctx context.Context, req userService.UserProfileRequest) ([]*userService.UserRatingWithMovie, *userService.UserProfileStats, int64, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
-		return nil, nil, args.Error(2)
+		return nil, nil, 0, args.Error(3)
 	}
-	return args.Get(0).([]*userService.UserRatingWithMovie), args.Get(1).(*userService.UserProfileStats), args.Error(2)
+	return args.Get(0).([]*userService.UserRatingWithMovie), args.Get(1).(*userService.UserProfileStats), args.Get(2).(int64), args.Error(3)
 }
 
 func (m *MockUserService) GetUserStats(ctx context.Context, userID string) (*userService.UserProfileStats, error) {
@@ -85,7 +96,162 @@ func (m *MockUserService) GetUserStats(ctx context.Context, userID string) (*use
 	return args.Get(0).(*userService.UserProfileStats), args.Error(1)
 }
 
+func (m *MockUserService) GetYearInReview(ctx context.Context, userID string, year int) (*userService.YearInReview, error) {
+	args := m.Called(ctx, userID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*userService.YearInReview), args.Error(1)
+}
+
 func (m *MockUserService) InvalidateUserCache(ctx context.Context, userID string) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
+
+func (m *MockUserService) UploadAvatar(ctx context.Context, id string, data []byte) (*users.User, error) {
+	args := m.Called(ctx, id, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *MockUserService) DeleteAvatar(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserService) AvatarURL(ctx context.Context, id, size string) (string, error) {
+	args := m.Called(ctx, id, size)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserService) MergeUsers(ctx context.Context, sourceID, targetID string) (*users.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.MergeRecord), args.Error(1)
+}
+
+func (m *MockUserService) GetSettings(ctx context.Context, id string) (*users.Settings, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Settings), args.Error(1)
+}
+
+func (m *MockUserService) SaveSettings(ctx context.Context, id string, settings users.Settings) (*users.Settings, error) {
+	args := m.Called(ctx, id, settings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Settings), args.Error(1)
+}
+
+// MockSessionService is a mock implementation of the session service
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) Start(ctx context.Context, userID, userAgent, ip string, expiresAt time.Time) (*users.Session, error) {
+	args := m.Called(ctx, userID, userAgent, ip, expiresAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Session), args.Error(1)
+}
+
+func (m *MockSessionService) List(ctx context.Context, userID string) ([]*users.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*users.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockSessionService) Validate(ctx context.Context, sessionID string) error {
+	args := m.Called(ctx, sessionID)
+	return args.Error(0)
+}
+
+// MockHomeService is a mock implementation of the recommendation service
+type MockHomeService struct {
+	mock.Mock
+}
+
+func (m *MockHomeService) GetHome(ctx context.Context, userID string) (*recommendationService.Home, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*recommendationService.Home), args.Error(1)
+}
+
+func (m *MockHomeService) SavePreferences(ctx context.Context, userID string, req recommendationService.PreferencesRequest) (*recommendations.Preferences, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*recommendations.Preferences), args.Error(1)
+}
+
+// MockOIDCService is a mock implementation of the OpenID Connect sign-in
+// service
+type MockOIDCService struct {
+	mock.Mock
+}
+
+func (m *MockOIDCService) Providers() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockOIDCService) Start(ctx context.Context, provider string, req oidcService.StartRequest) (*oidcService.StartResponse, error) {
+	args := m.Called(ctx, provider, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidcService.StartResponse), args.Error(1)
+}
+
+func (m *MockOIDCService) Callback(ctx context.Context, provider string, req oidcService.CallbackRequest) (*oidcService.CallbackResult, error) {
+	args := m.Called(ctx, provider, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidcService.CallbackResult), args.Error(1)
+}
+
+func (m *MockOIDCService) StartLink(ctx context.Context, provider, userID string) (*oidcService.StartResponse, error) {
+	args := m.Called(ctx, provider, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidcService.StartResponse), args.Error(1)
+}
+
+func (m *MockOIDCService) Identities(ctx context.Context, userID string) ([]*users.Identity, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*users.Identity), args.Error(1)
+}
+
+func (m *MockOIDCService) Unlink(ctx context.Context, userID, provider, password string) error {
+	args := m.Called(ctx, userID, provider, password)
+	return args.Error(0)
+}
@@ -0,0 +1,128 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	appErrors "thermondo/internal/pkg/errors"
+	oidcService "thermondo/internal/platform/service/oidc"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// oidcBindingCookie ties a sign-in to the browser that started it
+const oidcBindingCookie = "oidc_binding"
+
+type oidcLoginResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+	UserID    string `json:"user_id"`
+	// Provisioned is true when the account was made for this sign-in, and
+	// Linked when the provider was added to an existing account. Merged is
+	// true when linking merged the account the provider signed in to.
+	Provisioned bool `json:"provisioned"`
+	Linked      bool `json:"linked"`
+	Merged      bool `json:"merged"`
+}
+
+// StartOIDC handles GET /auth/oidc/{provider}/start, sending the user to
+// the provider to sign in. Apps may ask for the token to be delivered to an
+// allowed return_to URL instead of as JSON.
+func (h *Handler) StartOIDC(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	started, err := h.oidc.Start(r.Context(), provider, oidcService.StartRequest{ReturnTo: r.URL.Query().Get("return_to")})
+	if err != nil {
+		h.logger.Error("[start_oidc_handler] Failed to start sign-in", "error", err, "provider", provider)
+		h.handleOIDCServiceError(w, err)
+		return
+	}
+
+	// Scoped to this provider's start and callback. Apple posts the callback
+	// from its own site, so the cookie must be sent cross-site.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Value:    started.Binding,
+		Path:     path.Dir(r.URL.Path),
+		MaxAge:   int(oidcService.StateLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+	http.Redirect(w, r, started.AuthURL, http.StatusFound)
+}
+
+// OIDCCallback handles GET and POST /auth/oidc/{provider}/callback, where
+// the provider sends the user back. Providers using form_post, like Apple,
+// post the parameters as a form.
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	r.Body = http.MaxBytesReader(w, r.Body, maxCredentialsBytes)
+	if err := r.ParseForm(); err != nil {
+		h.responseWriter.WriteError(w, "Invalid callback parameters", http.StatusBadRequest)
+		return
+	}
+
+	var binding string
+	if cookie, err := r.Cookie(oidcBindingCookie); err == nil {
+		binding = cookie.Value
+	}
+	// The state can only be used once the cookie is gone, whatever happens
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcBindingCookie,
+		Path:     path.Dir(r.URL.Path),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	result, err := h.oidc.Callback(r.Context(), provider, oidcService.CallbackRequest{
+		State:   r.Form.Get("state"),
+		Code:    r.Form.Get("code"),
+		Binding: binding,
+		Error:   r.Form.Get("error"),
+		User:    r.Form.Get("user"),
+	})
+	if err != nil {
+		h.logger.Error("[oidc_callback_handler] Failed to sign in", "error", err, "provider", provider)
+		h.handleOIDCServiceError(w, err)
+		return
+	}
+
+	token, err := h.issueToken(r, result.User)
+	if err != nil {
+		h.logger.Error("[oidc_callback_handler] Failed to issue token", "error", err, "user_id", result.User.ID)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if result.ReturnTo != "" {
+		// In the fragment, so the token isn't sent on to any server
+		fragment := url.Values{
+			"token":      {token.Token},
+			"expires_at": {strconv.FormatInt(token.ExpiresAt, 10)},
+		}
+		http.Redirect(w, r, result.ReturnTo+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, oidcLoginResponse{
+		Token:       token.Token,
+		ExpiresAt:   token.ExpiresAt,
+		UserID:      result.User.ID.String(),
+		Provisioned: result.Provisioned,
+		Linked:      result.Linked,
+		Merged:      result.Merged,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleOIDCServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
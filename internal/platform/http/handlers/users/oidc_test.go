@@ -0,0 +1,146 @@
+package users
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	oidcService "thermondo/internal/platform/service/oidc"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupOIDCRouter(oidc *MockOIDCService, sessions *MockSessionService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(new(MockUserService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret),
+		WithSessions(sessions),
+		WithOIDC(oidc),
+	).RegisterRoutes(router)
+	return router
+}
+
+func TestStartOIDC(t *testing.T) {
+	oidc := new(MockOIDCService)
+	oidc.On("Start", mock.Anything, "apple", oidcService.StartRequest{ReturnTo: "thermondo://auth"}).
+		Return(&oidcService.StartResponse{AuthURL: "https://appleid.apple.com/auth/authorize?state=s", Binding: "binding-1"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/oidc/apple/start?return_to=thermondo%3A%2F%2Fauth", nil)
+	rr := httptest.NewRecorder()
+	setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusFound, rr.Code)
+	assert.Equal(t, "https://appleid.apple.com/auth/authorize?state=s", rr.Header().Get("Location"))
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, oidcBindingCookie, cookies[0].Name)
+	assert.Equal(t, "binding-1", cookies[0].Value)
+	assert.Equal(t, "/auth/oidc/apple", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+}
+
+func TestStartOIDC_UnknownProvider(t *testing.T) {
+	oidc := new(MockOIDCService)
+	oidc.On("Start", mock.Anything, "myspace", mock.Anything).Return(nil, appErrors.NewNotFoundError("Sign-in provider not found"))
+
+	rr := httptest.NewRecorder()
+	setupOIDCRouter(oidc, new(MockSessionService)).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/myspace/start", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Empty(t, rr.Result().Cookies())
+}
+
+func TestOIDCCallback(t *testing.T) {
+	user := &users.User{ID: "user-1", Role: users.RoleUser, IsActive: true}
+
+	t.Run("form post", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("Callback", mock.Anything, "apple", oidcService.CallbackRequest{
+			State: "state-1", Code: "code-1", Binding: "binding-1", User: `{"name":{"firstName":"Ada"}}`,
+		}).Return(&oidcService.CallbackResult{User: user, Provisioned: true}, nil)
+		sessions := new(MockSessionService)
+		sessions.On("Start", mock.Anything, "user-1", mock.Anything, mock.Anything, mock.Anything).Return(&users.Session{ID: "session-1"}, nil)
+
+		form := url.Values{"state": {"state-1"}, "code": {"code-1"}, "user": {`{"name":{"firstName":"Ada"}}`}}
+		req := httptest.NewRequest(http.MethodPost, "/auth/oidc/apple/callback", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: oidcBindingCookie, Value: "binding-1"})
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, sessions).ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp oidcLoginResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "user-1", resp.UserID)
+		assert.True(t, resp.Provisioned)
+		assert.False(t, resp.Linked)
+		assert.False(t, resp.Merged)
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(resp.Token, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte(sessionTestSecret), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims["user_id"])
+		assert.Equal(t, "session-1", claims["sid"])
+
+		cookies := rr.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, -1, cookies[0].MaxAge, "the binding is cleared")
+	})
+
+	t.Run("return to the app", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("Callback", mock.Anything, "google", mock.Anything).
+			Return(&oidcService.CallbackResult{User: user, ReturnTo: "thermondo://auth"}, nil)
+		sessions := new(MockSessionService)
+		sessions.On("Start", mock.Anything, "user-1", mock.Anything, mock.Anything, mock.Anything).Return(&users.Session{ID: "session-1"}, nil)
+
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, sessions).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/google/callback?state=s&code=c", nil))
+
+		require.Equal(t, http.StatusFound, rr.Code)
+		location, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "thermondo://auth", location.Scheme+"://"+location.Host)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		assert.NotEmpty(t, fragment.Get("token"))
+		assert.NotEmpty(t, fragment.Get("expires_at"))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		oidc := new(MockOIDCService)
+		oidc.On("Callback", mock.Anything, "google", oidcService.CallbackRequest{Error: "access_denied"}).
+			Return(nil, appErrors.NewUnauthorizedError("Sign-in was not completed: access_denied"))
+		sessions := new(MockSessionService)
+
+		rr := httptest.NewRecorder()
+		setupOIDCRouter(oidc, sessions).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/google/callback?error=access_denied", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		sessions.AssertNotCalled(t, "Start", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestOIDCRoutesDisabled(t *testing.T) {
+	router := chi.NewRouter()
+	NewHandler(new(MockUserService), slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/oidc/google/start", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
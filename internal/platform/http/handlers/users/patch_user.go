@@ -0,0 +1,83 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/mergepatch"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// patchUserRequest is a JSON Merge Patch of a user's profile
+type patchUserRequest struct {
+	FirstName   mergepatch.Field[string] `json:"first_name"`
+	LastName    mergepatch.Field[string] `json:"last_name"`
+	Email       mergepatch.Field[string] `json:"email"`
+	ContentMode mergepatch.Field[string] `json:"content_mode"` // "standard" or "kids"
+}
+
+func (r patchUserRequest) toService() userService.PatchUserRequest {
+	return userService.PatchUserRequest{
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		Email:       r.Email,
+		ContentMode: r.ContentMode,
+	}
+}
+
+// PatchUser handles PATCH /users/{id} with a JSON Merge Patch body. The
+// email is changed with ChangeEmail, which takes a step-up token, and admins
+// activate and deactivate users with POST /admin/users:batch.
+func (h *Handler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only update your own profile", http.StatusForbidden)
+		return
+	}
+
+	if err := mergepatch.CheckContentType(r.Header.Get("Content-Type")); err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req patchUserRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[patch_user_handler] Invalid merge patch", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	if req.Email.Set {
+		h.responseWriter.WriteError(w, "Change the email with PUT /users/{id}/email", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.PatchUser(r.Context(), id, req.toService())
+	if err != nil {
+		h.handlePatchUserServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, userToResponse(user), http.StatusOK)
+}
+
+func (h *Handler) handlePatchUserServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrUserNotFound):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domainUser.ErrUserAlreadyExists):
+		h.responseWriter.WriteError(w, "email is already in use", http.StatusConflict)
+	case errors.Is(err, userService.ErrNullField),
+		errors.Is(err, domainUser.ErrEmptyFirstName),
+		errors.Is(err, domainUser.ErrEmptyLastName),
+		errors.Is(err, domainUser.ErrEmptyEmail),
+		errors.Is(err, domainUser.ErrInvalidEmail),
+		errors.Is(err, domainUser.ErrInvalidContentMode):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error("[patch_user_handler] Failed to patch user", "error", err)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,59 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// savePreferencesRequest holds the genres and decades picked, decades named by
+// their first year such as 1990
+type savePreferencesRequest struct {
+	Genres  []string `json:"genres"`
+	Decades []int    `json:"decades"`
+}
+
+func (r savePreferencesRequest) toService() recommendationService.PreferencesRequest {
+	return recommendationService.PreferencesRequest{Genres: r.Genres, Decades: r.Decades}
+}
+
+// SavePreferences handles POST /users/{id}/preferences, the genres and
+// decades a new user picks during onboarding. They seed the picks on the
+// home until the user has rated enough movies to go by.
+func (h *Handler) SavePreferences(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only set your own preferences", http.StatusForbidden)
+		return
+	}
+
+	var req savePreferencesRequest
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[save_preferences_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	preferences, err := h.home.SavePreferences(r.Context(), id, req.toService())
+	if err != nil {
+		h.logger.Error("[save_preferences_handler] Failed to save preferences", "error", err, "user_id", id)
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) {
+			h.responseWriter.WriteAppError(w, appErr)
+			return
+		}
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, PreferencesResponse{
+		UserID:    string(preferences.UserID),
+		Genres:    preferences.Genres,
+		Decades:   preferences.Decades,
+		UpdatedAt: preferences.UpdatedAt,
+	}, http.StatusOK)
+}
@@ -0,0 +1,70 @@
+package users
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/recommendations"
+	appErrors "thermondo/internal/pkg/errors"
+	recommendationService "thermondo/internal/platform/service/recommendation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func preferencesRequest(t *testing.T, target, callerID, body string) *http.Request {
+	req := sessionRequest(t, http.MethodPost, target, callerID, "user", "")
+	req.Body = io.NopCloser(strings.NewReader(body))
+	return req
+}
+
+func TestSavePreferences(t *testing.T) {
+	t.Run("own preferences", func(t *testing.T) {
+		home := new(MockHomeService)
+		home.On("SavePreferences", mock.Anything, "user-1", recommendationService.PreferencesRequest{Genres: []string{"Horror"}, Decades: []int{1970}}).
+			Return(&recommendations.Preferences{
+				UserID: "user-1", Genres: []string{"Horror"}, Decades: []int{1970},
+				UpdatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+			}, nil)
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, preferencesRequest(t, "/users/user-1/preferences", "user-1", `{"genres":["Horror"],"decades":[1970]}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":"user-1","genres":["Horror"],"decades":[1970],"updated_at":"2024-06-01T12:00:00Z"}`, w.Body.String())
+	})
+
+	t.Run("invalid preferences", func(t *testing.T) {
+		home := new(MockHomeService)
+		home.On("SavePreferences", mock.Anything, "user-1", mock.Anything).Return(nil, appErrors.NewBadRequestError("pick at least one genre or decade"))
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, preferencesRequest(t, "/users/user-1/preferences", "user-1", `{"genres":[]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		home := new(MockHomeService)
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, preferencesRequest(t, "/users/user-1/preferences", "user-1", `{"genres":`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		home.AssertNotCalled(t, "SavePreferences", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("someone else's preferences", func(t *testing.T) {
+		home := new(MockHomeService)
+
+		w := httptest.NewRecorder()
+		setupHomeRouter(home).ServeHTTP(w, preferencesRequest(t, "/users/user-1/preferences", "user-2", `{"genres":["Horror"]}`))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		home.AssertNotCalled(t, "SavePreferences", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
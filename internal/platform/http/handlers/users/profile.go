@@ -2,12 +2,16 @@ package users
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"thermondo/internal/domain/users"
 	appErrors "thermondo/internal/pkg/errors"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
 	userService "thermondo/internal/platform/service/user"
 	"time"
 
@@ -18,17 +22,37 @@ type ProfileHandler struct {
 	userService    userService.UserService
 	responseWriter *response.Writer
 	logger         *slog.Logger
+	auth           *middleware.AuthMiddleware
 }
 
-func NewProfileHandler(userService userService.UserService, logger *slog.Logger) *ProfileHandler {
-	return &ProfileHandler{
+// ProfileOption configures optional behaviour of the profile handler
+type ProfileOption func(*ProfileHandler)
+
+// WithProfileAuthentication verifies bearer tokens and enables
+// GET /user/me/profile
+func WithProfileAuthentication(keys *tokens.Keys, sessions middleware.SessionValidator) ProfileOption {
+	return func(h *ProfileHandler) {
+		var authOptions []middleware.AuthOption
+		if sessions != nil {
+			authOptions = append(authOptions, middleware.WithSessionValidator(sessions))
+		}
+		h.auth = middleware.NewAuthMiddleware(keys, h.responseWriter, authOptions...)
+	}
+}
+
+func NewProfileHandler(userService userService.UserService, logger *slog.Logger, opts ...ProfileOption) *ProfileHandler {
+	h := &ProfileHandler{
 		userService:    userService,
 		responseWriter: response.NewWriter(logger),
 		logger:         logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// GetUserProfile handles GET /users/{userId}/profile
+// GetUserProfile handles GET /user/{userId}/profile
 func (h *ProfileHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "userId")
 	if userID == "" {
@@ -36,17 +60,30 @@ func (h *ProfileHandler) GetUserProfile(w http.ResponseWriter, r *http.Request)
 		h.responseWriter.WriteError(w, "User ID is required", http.StatusBadRequest)
 		return
 	}
+	h.writeProfile(w, r, userID)
+}
+
+// GetMyProfile handles GET /user/me/profile, the profile of the caller
+func (h *ProfileHandler) GetMyProfile(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok {
+		h.responseWriter.WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	h.writeProfile(w, r, principal.UserID)
+}
 
+func (h *ProfileHandler) writeProfile(w http.ResponseWriter, r *http.Request, userID string) {
 	// Parse query parameters
-	limit := h.getIntParam(r, "limit", 20)
+	limit := h.getIntParam(r, "limit", userService.DefaultProfileLimit)
 	offset := h.getIntParam(r, "offset", 0)
 	sortBy := r.URL.Query().Get("sort_by")
 	if sortBy == "" {
-		sortBy = "created_at"
+		sortBy = userService.DefaultProfileSortBy
 	}
 	order := r.URL.Query().Get("order")
 	if order == "" {
-		order = "desc"
+		order = userService.DefaultProfileOrder
 	}
 
 	// Validate parameters
@@ -62,12 +99,26 @@ func (h *ProfileHandler) GetUserProfile(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	order = strings.ToLower(order)
+	if order != "asc" && order != "desc" {
+		h.logger.Error("Invalid order", "order", order)
+		h.responseWriter.WriteError(w, "Order must be 'asc' or 'desc'", http.StatusBadRequest)
+		return
+	}
+
 	req := userService.UserProfileRequest{
 		UserID: userID,
 		Limit:  limit,
 		Offset: offset,
 		SortBy: sortBy,
 		Order:  order,
+		Genre:  strings.TrimSpace(r.URL.Query().Get("genre")),
+	}
+
+	if err := h.parseProfileFilters(r, &req); err != nil {
+		h.logger.Error("Invalid profile filter", "error", err)
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Get user basic info
@@ -85,7 +136,7 @@ func (h *ProfileHandler) GetUserProfile(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get user profile with ratings
-	ratingsWithMovies, stats, err := h.userService.GetUserProfile(r.Context(), req)
+	ratingsWithMovies, stats, total, err := h.userService.GetUserProfile(r.Context(), req)
 	if err != nil {
 		h.logger.Error("Failed to get user profile", "error", err)
 		h.handleServiceError(w, err)
@@ -94,11 +145,11 @@ func (h *ProfileHandler) GetUserProfile(w http.ResponseWriter, r *http.Request)
 
 	// Build response
 	response := &UserProfileResponse{
-		User:    h.userToResponse(user),
+		User:    userToResponse(user),
 		Stats:   h.statsToResponse(stats),
-		Ratings: h.ratingsWithMoviesToResponse(ratingsWithMovies),
-		HasMore: offset+limit < int(stats.TotalRatings),
-		Total:   stats.TotalRatings,
+		Ratings: ratingsWithMoviesToResponse(ratingsWithMovies),
+		HasMore: offset+limit < int(total),
+		Total:   total,
 	}
 
 	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
@@ -114,26 +165,59 @@ func (h *ProfileHandler) getIntParam(r *http.Request, key string, defaultValue i
 	return defaultValue
 }
 
-func (h *ProfileHandler) isValidSortField(field string) bool {
-	validFields := map[string]bool{
-		"created_at": true,
-		"score":      true,
-		"title":      true,
+// getOptionalIntParam returns nil when the parameter is absent and an error
+// when it is present but not an integer
+func (h *ProfileHandler) getOptionalIntParam(r *http.Request, key string) (*int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
 	}
-	return validFields[field]
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &intValue, nil
+}
+
+// parseProfileFilters reads the score and release year range filters into req
+func (h *ProfileHandler) parseProfileFilters(r *http.Request, req *userService.UserProfileRequest) error {
+	var err error
+	if req.MinScore, err = h.getOptionalIntParam(r, "min_score"); err != nil {
+		return err
+	}
+	if req.MaxScore, err = h.getOptionalIntParam(r, "max_score"); err != nil {
+		return err
+	}
+	if req.YearFrom, err = h.getOptionalIntParam(r, "year_from"); err != nil {
+		return err
+	}
+	if req.YearTo, err = h.getOptionalIntParam(r, "year_to"); err != nil {
+		return err
+	}
+
+	for _, score := range []*int{req.MinScore, req.MaxScore} {
+		if score != nil && (*score < 1 || *score > 5) {
+			return errors.New("min_score and max_score must be between 1 and 5")
+		}
+	}
+	if req.MinScore != nil && req.MaxScore != nil && *req.MinScore > *req.MaxScore {
+		return errors.New("min_score cannot be greater than max_score")
+	}
+	if req.YearFrom != nil && req.YearTo != nil && *req.YearFrom > *req.YearTo {
+		return errors.New("year_from cannot be greater than year_to")
+	}
+	return nil
 }
 
-func (h *ProfileHandler) userToResponse(user *users.User) UserResponse {
-	return UserResponse{
-		ID:        string(user.ID),
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Email:     user.Email,
-		Role:      string(user.Role),
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+func (h *ProfileHandler) isValidSortField(field string) bool {
+	validFields := map[string]bool{
+		"created_at":   true,
+		"updated_at":   true,
+		"score":        true,
+		"title":        true,
+		"release_year": true,
 	}
+	return validFields[field]
 }
 
 func (h *ProfileHandler) statsToResponse(stats *userService.UserProfileStats) UserProfileStatsResponse {
@@ -143,16 +227,28 @@ func (h *ProfileHandler) statsToResponse(stats *userService.UserProfileStats) Us
 		scoreDistribution[strconv.Itoa(score)] = count
 	}
 
-	return UserProfileStatsResponse{
-		TotalRatings:      stats.TotalRatings,
-		AverageScore:      stats.AverageScore,
-		ScoreDistribution: scoreDistribution,
-		FavoriteGenre:     stats.FavoriteGenre,
-		GenreBreakdown:    stats.GenreBreakdown,
+	resp := UserProfileStatsResponse{
+		TotalRatings:        stats.TotalRatings,
+		AverageScore:        stats.AverageScore,
+		ScoreDistribution:   scoreDistribution,
+		FavoriteGenre:       stats.FavoriteGenre,
+		RecentFavoriteGenre: stats.RecentFavoriteGenre,
+		GenreBreakdown:      stats.GenreBreakdown,
+		RatingsPerMonth:     monthlyCountsToResponse(stats.RatingsPerMonth),
+		LongestStreak:       streakToResponse(stats.LongestStreak),
+		FavoriteDecade:      stats.FavoriteDecade,
 	}
+	if stats.Lists != nil {
+		resp.Lists = &ListCountsResponse{
+			Total:   stats.Lists.Total,
+			Public:  stats.Lists.Public,
+			Private: stats.Lists.Private,
+		}
+	}
+	return resp
 }
 
-func (h *ProfileHandler) ratingsWithMoviesToResponse(ratingsWithMovies []*userService.UserRatingWithMovie) []UserRatingWithMovieResponse {
+func ratingsWithMoviesToResponse(ratingsWithMovies []*userService.UserRatingWithMovie) []UserRatingWithMovieResponse {
 	responses := make([]UserRatingWithMovieResponse, len(ratingsWithMovies))
 	for i, rwm := range ratingsWithMovies {
 		responses[i] = UserRatingWithMovieResponse{
@@ -178,7 +274,7 @@ func (h *ProfileHandler) handleServiceError(w http.ResponseWriter, err error) {
 	var appErr *appErrors.AppError
 	if errors.As(err, &appErr) {
 		h.logger.Error("Service error", "error", appErr)
-		h.responseWriter.WriteError(w, appErr.Message, appErr.StatusCode)
+		h.responseWriter.WriteAppError(w, appErr)
 		return
 	}
 
@@ -197,5 +293,8 @@ func (h *ProfileHandler) handleServiceError(w http.ResponseWriter, err error) {
 }
 
 func (h *ProfileHandler) RegisterRoutes(r chi.Router) {
+	if h.auth != nil {
+		r.With(h.auth.Authenticate).Get("/user/me/profile", h.GetMyProfile)
+	}
 	r.Get("/user/{userId}/profile", h.GetUserProfile)
 }
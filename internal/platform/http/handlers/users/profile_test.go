@@ -15,6 +15,7 @@ import (
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
 	userService "thermondo/internal/platform/service/user"
 
 	"github.com/go-chi/chi/v5"
@@ -87,7 +88,7 @@ func TestGetUserProfile(t *testing.T) {
 						req.Offset == 0 &&
 						req.SortBy == "created_at" &&
 						req.Order == "desc"
-				})).Return(ratings, stats, nil)
+				})).Return(ratings, stats, int64(1), nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: UserProfileResponse{
@@ -157,6 +158,22 @@ func TestGetUserProfile(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   response.ErrorResponse{Error: "Invalid sort field"},
 		},
+		{
+			name:           "non-numeric score filter",
+			userID:         "test-user-id",
+			queryParams:    "min_score=high",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "min_score must be an integer"},
+		},
+		{
+			name:           "inverted score range",
+			userID:         "test-user-id",
+			queryParams:    "min_score=4&max_score=2",
+			mockSetup:      func(service *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   response.ErrorResponse{Error: "min_score cannot be greater than max_score"},
+		},
 		{
 			name:        "user not found",
 			userID:      "non-existent-id",
@@ -175,7 +192,7 @@ func TestGetUserProfile(t *testing.T) {
 				service.On("FindUserByID", mock.Anything, "test-user-id").Return(&users.User{
 					ID: "test-user-id",
 				}, nil)
-				service.On("GetUserProfile", mock.Anything, mock.Anything).Return(nil, nil, errors.New("failed to get profile"))
+				service.On("GetUserProfile", mock.Anything, mock.Anything).Return(nil, nil, int64(0), errors.New("failed to get profile"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   response.ErrorResponse{Error: "Internal server error"},
@@ -227,10 +244,10 @@ func TestGetUserProfile(t *testing.T) {
 				assert.NoError(t, err)
 				responseBody = successResponse
 			} else {
-				var errorResponse response.ErrorResponse
-				err := json.Unmarshal(recorder.Body.Bytes(), &errorResponse)
+				var problem response.Problem
+				err := json.Unmarshal(recorder.Body.Bytes(), &problem)
 				assert.NoError(t, err)
-				responseBody = errorResponse
+				responseBody = response.ErrorResponse{Error: problem.Detail}
 			}
 
 			// Compare response body
@@ -242,6 +259,40 @@ func TestGetUserProfile(t *testing.T) {
 	}
 }
 
+func TestGetMyProfile(t *testing.T) {
+	setupRouter := func(service *MockUserService) *chi.Mux {
+		router := chi.NewRouter()
+		NewProfileHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)),
+			WithProfileAuthentication(tokens.FromSecret(avatarTestSecret), nil),
+		).RegisterRoutes(router)
+		return router
+	}
+
+	t.Run("profile of the caller", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("FindUserByID", mock.Anything, "user-1").Return(&users.User{ID: "user-1", Role: users.RoleUser}, nil)
+		service.On("GetUserProfile", mock.Anything, mock.MatchedBy(func(req userService.UserProfileRequest) bool {
+			return req.UserID == "user-1"
+		})).Return([]*userService.UserRatingWithMovie{}, &userService.UserProfileStats{}, int64(0), nil)
+
+		recorder := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(recorder, avatarRequest(t, http.MethodGet, "/user/me/profile", "user-1", "user", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("a token is required", func(t *testing.T) {
+		service := new(MockUserService)
+
+		recorder := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(recorder, avatarRequest(t, http.MethodGet, "/user/me/profile", "", "", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		service.AssertNotCalled(t, "FindUserByID", mock.Anything, mock.Anything)
+	})
+}
+
 // Helper function to create a pointer to a string
 func stringPtr(s string) *string {
 	return &s
@@ -0,0 +1,114 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	domainUser "thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	ExpiresAt  string `json:"expires_at"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// ListSessions handles GET /users/{id}/sessions. Users may only see their
+// own sessions unless they are admins.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only manage your own sessions", http.StatusForbidden)
+		return
+	}
+
+	sessions, err := h.sessions.List(r.Context(), id)
+	if err != nil {
+		h.logger.Error("[list_sessions_handler] Failed to list sessions", "error", err, "user_id", id)
+		h.handleSessionServiceError(w, err)
+		return
+	}
+
+	currentID, _ := r.Context().Value("session_id").(string)
+	response := ListSessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, sessionToResponse(session, currentID))
+	}
+
+	h.responseWriter.WriteSuccess(w, response, http.StatusOK)
+}
+
+// RevokeSession handles DELETE /users/{id}/sessions/{sessionId}, signing a
+// single device out
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only manage your own sessions", http.StatusForbidden)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionId")
+	if err := h.sessions.Revoke(r.Context(), id, sessionID); err != nil {
+		h.logger.Error("[revoke_session_handler] Failed to revoke session", "error", err, "user_id", id, "session_id", sessionID)
+		h.handleSessionServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions handles DELETE /users/{id}/sessions, logging the user
+// out everywhere including the device making the request
+func (h *Handler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only manage your own sessions", http.StatusForbidden)
+		return
+	}
+
+	revoked, err := h.sessions.RevokeAll(r.Context(), id)
+	if err != nil {
+		h.logger.Error("[revoke_all_sessions_handler] Failed to revoke sessions", "error", err, "user_id", id)
+		h.handleSessionServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, RevokeSessionsResponse{Revoked: revoked}, http.StatusOK)
+}
+
+func (h *Handler) handleSessionServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
+
+func sessionToResponse(session *domainUser.Session, currentID string) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID.String(),
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+		LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+		Current:    currentID != "" && session.ID.String() == currentID,
+	}
+}
@@ -0,0 +1,177 @@
+package users
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/password"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionTestSecret = "session-test-secret"
+
+func setupSessionRouter(userService *MockUserService, sessions *MockSessionService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(userService, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret),
+		WithSessions(sessions),
+	).RegisterRoutes(router)
+	return router
+}
+
+func sessionRequest(t *testing.T, method, target, callerID, role, sessionID string) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": callerID,
+		"role":    role,
+		"sid":     sessionID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(sessionTestSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestLoginStartsSession(t *testing.T) {
+	hashedPassword, err := password.HashPassword("password123")
+	require.NoError(t, err)
+
+	userService := new(MockUserService)
+	userService.On("FindUserByEmail", mock.Anything, "john@example.com").Return(&users.User{
+		ID: "user-1", Email: "john@example.com", Password: hashedPassword, Role: users.RoleUser,
+	}, nil)
+	sessions := new(MockSessionService)
+	sessions.On("Start", mock.Anything, "user-1", "Firefox", "203.0.113.7", mock.AnythingOfType("time.Time")).
+		Return(&users.Session{ID: "session-1"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/users/login", strings.NewReader(`{"email":"john@example.com","password":"password123"}`))
+	req.Header.Set("User-Agent", "Firefox")
+	req.RemoteAddr = "203.0.113.7:1234"
+	rr := httptest.NewRecorder()
+	setupSessionRouter(userService, sessions).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp loginResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(resp.Token, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(sessionTestSecret), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", claims["sid"])
+}
+
+func TestListSessions(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("marks the current session", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-1").Return(nil)
+		sessions.On("List", mock.Anything, "user-1").Return([]*users.Session{
+			{ID: "session-1", UserAgent: "Firefox", IP: "203.0.113.7", CreatedAt: created, LastUsedAt: created, ExpiresAt: created.Add(24 * time.Hour)},
+			{ID: "session-2", UserAgent: "curl/8.0", IP: "198.51.100.1", CreatedAt: created, LastUsedAt: created, ExpiresAt: created.Add(24 * time.Hour)},
+		}, nil)
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodGet, "/users/user-1/sessions", "user-1", "user", "session-1"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp ListSessionsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		require.Len(t, resp.Sessions, 2)
+		assert.Equal(t, SessionResponse{
+			ID: "session-1", UserAgent: "Firefox", IP: "203.0.113.7",
+			CreatedAt: "2024-01-01T12:00:00Z", LastUsedAt: "2024-01-01T12:00:00Z", ExpiresAt: "2024-01-02T12:00:00Z",
+			Current: true,
+		}, resp.Sessions[0])
+		assert.False(t, resp.Sessions[1].Current)
+	})
+
+	t.Run("other users' sessions are forbidden", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-9").Return(nil)
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodGet, "/users/user-1/sessions", "user-9", "user", "session-9"))
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		sessions.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked token is rejected", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-1").Return(users.ErrSessionRevoked)
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodGet, "/users/user-1/sessions", "user-1", "user", "session-1"))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		sessions.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("session store outage", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-1").Return(errors.New("connection reset"))
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodGet, "/users/user-1/sessions", "user-1", "user", "session-1"))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+}
+
+func TestRevokeSessions(t *testing.T) {
+	t.Run("revokes one device", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-1").Return(nil)
+		sessions.On("Revoke", mock.Anything, "user-1", "session-2").Return(nil)
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodDelete, "/users/user-1/sessions/session-2", "user-1", "user", "session-1"))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "session-1").Return(nil)
+		sessions.On("Revoke", mock.Anything, "user-1", "missing").Return(appErrors.NewNotFoundError("Session not found"))
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodDelete, "/users/user-1/sessions/missing", "user-1", "user", "session-1"))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("admins log a user out everywhere", func(t *testing.T) {
+		sessions := new(MockSessionService)
+		sessions.On("Validate", mock.Anything, "admin-session").Return(nil)
+		sessions.On("RevokeAll", mock.Anything, "user-1").Return(int64(3), nil)
+
+		rr := httptest.NewRecorder()
+		setupSessionRouter(new(MockUserService), sessions).ServeHTTP(rr, sessionRequest(t, http.MethodDelete, "/users/user-1/sessions", "admin-1", "admin", "admin-session"))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp RevokeSessionsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, int64(3), resp.Revoked)
+	})
+}
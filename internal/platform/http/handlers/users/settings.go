@@ -0,0 +1,113 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"thermondo/internal/domain/movies"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/request"
+	userService "thermondo/internal/platform/service/user"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SettingsResponse is how the app behaves for a user
+type SettingsResponse struct {
+	UserID        string                          `json:"user_id"`
+	Locale        string                          `json:"locale"`
+	ContentMode   string                          `json:"content_mode"`
+	Notifications domainUser.NotificationSettings `json:"notifications"`
+	SortOrders    domainUser.SortOrders           `json:"sort_orders"`
+	// UpdatedAt is null until the user first saves their settings
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func toSettingsResponse(settings *domainUser.Settings) SettingsResponse {
+	resp := SettingsResponse{
+		UserID:        string(settings.UserID),
+		Locale:        settings.Locale,
+		ContentMode:   string(settings.ContentMode),
+		Notifications: settings.Notifications,
+		SortOrders:    settings.SortOrders,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &settings.UpdatedAt
+	}
+	return resp
+}
+
+// saveSettingsRequest replaces all of the user's settings; those left out
+// go back to their defaults
+type saveSettingsRequest struct {
+	Locale        string                          `json:"locale"`
+	ContentMode   string                          `json:"content_mode"`
+	Notifications domainUser.NotificationSettings `json:"notifications"`
+	SortOrders    domainUser.SortOrders           `json:"sort_orders"`
+}
+
+// GetSettings handles GET /users/{id}/settings
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only see your own settings", http.StatusForbidden)
+		return
+	}
+
+	settings, err := h.userService.GetSettings(r.Context(), id)
+	if err != nil {
+		h.handleSettingsServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, toSettingsResponse(settings), http.StatusOK)
+}
+
+// SaveSettings handles PUT /users/{id}/settings
+func (h *Handler) SaveSettings(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if !h.canManageUser(r, id) {
+		h.responseWriter.WriteError(w, "You can only change your own settings", http.StatusForbidden)
+		return
+	}
+
+	defaults := domainUser.DefaultSettings(domainUser.UserID(id))
+	req := saveSettingsRequest{
+		Locale:        defaults.Locale,
+		ContentMode:   string(defaults.ContentMode),
+		Notifications: defaults.Notifications,
+		SortOrders:    defaults.SortOrders,
+	}
+	if appErr := request.DecodeJSON(w, r, &req); appErr != nil {
+		h.logger.Error("[save_settings_handler] Invalid JSON", "error", appErr)
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	settings, err := h.userService.SaveSettings(r.Context(), id, domainUser.Settings{
+		Locale:        req.Locale,
+		ContentMode:   domainUser.ContentMode(req.ContentMode),
+		Notifications: req.Notifications,
+		SortOrders:    req.SortOrders,
+	})
+	if err != nil {
+		h.handleSettingsServiceError(w, err)
+		return
+	}
+	h.responseWriter.WriteSuccess(w, toSettingsResponse(settings), http.StatusOK)
+}
+
+func (h *Handler) handleSettingsServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domainUser.ErrUserNotFound):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, movies.ErrInvalidLocale),
+		errors.Is(err, domainUser.ErrInvalidContentMode),
+		errors.Is(err, domainUser.ErrInvalidSortOrder):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, userService.ErrSettingsDisabled):
+		h.responseWriter.WriteError(w, err.Error(), http.StatusNotImplemented)
+	default:
+		h.logger.Error("[settings_handler] Settings request failed", "error", err)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,139 @@
+package users
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupSettingsRouter(userService *MockUserService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(userService, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+	return router
+}
+
+func settingsRequest(t *testing.T, method, target, callerID, role, body string) *http.Request {
+	req := sessionRequest(t, method, target, callerID, role, "")
+	if body != "" {
+		req.Body = io.NopCloser(strings.NewReader(body))
+	}
+	return req
+}
+
+func TestGetSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		userService := new(MockUserService)
+		userService.On("GetSettings", mock.Anything, "user-1").Return(users.DefaultSettings("user-1"), nil)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodGet, "/users/user-1/settings", "user-1", "user", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"user_id": "user-1",
+			"locale": "en",
+			"content_mode": "standard",
+			"notifications": {"new_releases": false, "recommendations": false, "product_news": false},
+			"sort_orders": {
+				"movies": {"sort_by": "created_at", "order": "desc"},
+				"ratings": {"sort_by": "created_at", "order": "desc"},
+				"profile": {"sort_by": "created_at", "order": "desc"}
+			},
+			"updated_at": null
+		}`, w.Body.String())
+	})
+
+	t.Run("admins see anyone's", func(t *testing.T) {
+		userService := new(MockUserService)
+		userService.On("GetSettings", mock.Anything, "user-1").Return(users.DefaultSettings("user-1"), nil)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodGet, "/users/user-1/settings", "admin-1", "admin", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("someone else's", func(t *testing.T) {
+		userService := new(MockUserService)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodGet, "/users/user-1/settings", "user-2", "user", ""))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		userService.AssertNotCalled(t, "GetSettings", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupSettingsRouter(new(MockUserService)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/settings", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestSaveSettings(t *testing.T) {
+	t.Run("left out settings get their defaults", func(t *testing.T) {
+		userService := new(MockUserService)
+		want := *users.DefaultSettings("")
+		want.Locale = "de"
+		want.Notifications.NewReleases = true
+		userService.On("SaveSettings", mock.Anything, "user-1", want).Return(func() *users.Settings {
+			saved := want
+			saved.UserID = "user-1"
+			saved.UpdatedAt = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			return &saved
+		}(), nil)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodPut, "/users/user-1/settings", "user-1", "user",
+			`{"locale":"de","notifications":{"new_releases":true}}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"updated_at":"2024-06-01T12:00:00Z"`)
+		userService.AssertExpectations(t)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, err := range []error{movies.ErrInvalidLocale, users.ErrInvalidContentMode, users.ErrInvalidSortOrder} {
+			userService := new(MockUserService)
+			userService.On("SaveSettings", mock.Anything, "user-1", mock.Anything).Return(nil, err)
+
+			w := httptest.NewRecorder()
+			setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodPut, "/users/user-1/settings", "user-1", "user", `{}`))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, err.Error())
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		userService := new(MockUserService)
+		userService.On("SaveSettings", mock.Anything, "user-1", mock.Anything).Return(nil, users.ErrUserNotFound)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodPut, "/users/user-1/settings", "admin-1", "admin", `{}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("someone else's", func(t *testing.T) {
+		userService := new(MockUserService)
+
+		w := httptest.NewRecorder()
+		setupSettingsRouter(userService).ServeHTTP(w, settingsRequest(t, http.MethodPut, "/users/user-1/settings", "user-2", "user", `{}`))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		userService.AssertNotCalled(t, "SaveSettings", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/captcha"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubCaptcha struct {
+	err error
+}
+
+func (s stubCaptcha) Verify(ctx context.Context, token, remoteIP string) error {
+	return s.err
+}
+
+const signupBody = `{"first_name":"Jane","last_name":"Doe","email":"jane@example.com","password":"password123","role":"user"}`
+
+func signupRequest(remoteAddr string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/users/", strings.NewReader(signupBody))
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestSignupRateLimit(t *testing.T) {
+	service := new(MockUserService)
+	service.On("CreateUser", mock.Anything, mock.Anything).Return(&users.User{ID: "test-id"}, nil)
+
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret("test-secret"),
+		WithSignupRateLimit(ratelimit.New(2, time.Hour)),
+	).RegisterRoutes(router)
+
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, signupRequest("203.0.113.7:1234"))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, signupRequest("203.0.113.7:5678"))
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+	// Other clients and other endpoints are unaffected
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, signupRequest("198.51.100.1:1234"))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	service.AssertNumberOfCalls(t, "CreateUser", 3)
+}
+
+func TestSignupCaptcha(t *testing.T) {
+	tests := []struct {
+		name           string
+		verifyErr      error
+		expectedStatus int
+	}{
+		{"solved", nil, http.StatusCreated},
+		{"missing token", captcha.ErrMissingToken, http.StatusBadRequest},
+		{"invalid token", captcha.ErrInvalidToken, http.StatusBadRequest},
+		{"provider down", io.ErrUnexpectedEOF, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockUserService)
+			service.On("CreateUser", mock.Anything, mock.Anything).Return(&users.User{ID: "test-id"}, nil)
+
+			router := chi.NewRouter()
+			NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret("test-secret"),
+				WithCaptchaVerifier(stubCaptcha{err: tt.verifyErr}),
+			).RegisterRoutes(router)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, signupRequest("203.0.113.7:1234"))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			if tt.verifyErr != nil {
+				service.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package users
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/request"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/mergepatch"
+	"thermondo/internal/pkg/password"
+	"thermondo/internal/platform/http/middleware"
+	userService "thermondo/internal/platform/service/user"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ScopeEmailChange is the step-up scope for changing a user's email
+const ScopeEmailChange = "email:change"
+
+// StepUpTokenLifetime is how long a step-up token is valid; long enough to
+// finish the one operation it was issued for
+const StepUpTokenLifetime = 5 * time.Minute
+
+// stepUpScopes are the operations step-up tokens can be issued for
+var stepUpScopes = map[string]bool{
+	ScopeEmailChange: true,
+}
+
+type exchangeTokenRequest struct {
+	Scope string `json:"scope"`
+	// Password proves who the user is again; it may be left out within the
+	// recent sign-in window
+	Password string `json:"password"`
+}
+
+type exchangeTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+	Scope     string `json:"scope"`
+}
+
+type changeEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// ExchangeToken handles POST /auth/token/exchange, trading the caller's
+// long-lived token for a step-up token that is only valid for one
+// high-risk operation, for a few minutes. Users prove who they are again
+// with their password, unless they signed in within the recent sign-in
+// window. The step-up token belongs to the same session, so signing the
+// device out revokes it too.
+func (h *Handler) ExchangeToken(w http.ResponseWriter, r *http.Request) {
+	principal, ok := middleware.PrincipalFrom(r.Context())
+	if !ok || principal.Caller != "" {
+		h.responseWriter.WriteError(w, "Only users can exchange their own token", http.StatusForbidden)
+		return
+	}
+
+	var req exchangeTokenRequest
+	if appErr := request.DecodeJSON(w, r, &req, request.WithMaxBytes(maxCredentialsBytes)); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	if !stepUpScopes[req.Scope] {
+		h.responseWriter.WriteError(w, "Unknown step-up scope", http.StatusBadRequest)
+		return
+	}
+
+	if req.Password != "" {
+		user, err := h.userService.FindUserByID(r.Context(), principal.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			h.logger.Error("[exchange_token_handler] Failed to find user", "error", err, "user_id", principal.UserID)
+			h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if user == nil || password.VerifyPassword(req.Password, user.Password) != nil {
+			h.responseWriter.WriteError(w, "Invalid credentials", http.StatusUnauthorized)
+			return
+		}
+	} else if time.Since(principal.AuthenticatedAt) > h.recentAuthWindow {
+		h.responseWriter.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeReauthenticationRequired, "Confirm your password or sign in again to continue"))
+		return
+	}
+
+	expiresAt := time.Now().Add(StepUpTokenLifetime)
+	claims := jwt.MapClaims{
+		"user_id": principal.UserID,
+		"role":    string(principal.Role),
+		"scope":   req.Scope,
+	}
+	if principal.SessionID != "" {
+		claims["sid"] = principal.SessionID
+	}
+	token, err := h.keys.Issue(claims, expiresAt)
+	if err != nil {
+		h.logger.Error("[exchange_token_handler] Failed to issue token", "error", err, "user_id", principal.UserID)
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.logger.Info("Issued step-up token", "user_id", principal.UserID, "scope", req.Scope, "session_id", principal.SessionID)
+
+	h.responseWriter.WriteSuccess(w, exchangeTokenResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Unix(),
+		Scope:     req.Scope,
+	}, http.StatusOK)
+}
+
+// ChangeEmail handles PUT /users/{id}/email. It takes a step-up token for
+// ScopeEmailChange, issued to the user themselves.
+func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if principal, ok := middleware.PrincipalFrom(r.Context()); !ok || !principal.Is(id) {
+		h.responseWriter.WriteError(w, "You can only change your own email", http.StatusForbidden)
+		return
+	}
+
+	var req changeEmailRequest
+	if appErr := request.DecodeJSON(w, r, &req, request.WithMaxBytes(maxCredentialsBytes)); appErr != nil {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+
+	user, err := h.userService.PatchUser(r.Context(), id, userService.PatchUserRequest{Email: mergepatch.Value(req.Email)})
+	if err != nil {
+		h.handlePatchUserServiceError(w, err)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, userToResponse(user), http.StatusOK)
+}
@@ -0,0 +1,140 @@
+package users
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/mergepatch"
+	"thermondo/internal/pkg/password"
+	"thermondo/internal/pkg/tokens"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStepUpRouter(service *MockUserService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+	return router
+}
+
+// exchange trades a token of user-1 issued at signedInAt for a step-up
+// token
+func exchange(t *testing.T, router http.Handler, body string, signedInAt time.Time) *httptest.ResponseRecorder {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, identityRequest(t, http.MethodPost, "/auth/token/exchange", body, "user-1", "user", signedInAt))
+	return rr
+}
+
+func TestExchangeToken(t *testing.T) {
+	hash, err := password.HashPassword("correct horse")
+	require.NoError(t, err)
+	service := new(MockUserService)
+	service.On("FindUserByID", mock.Anything, "user-1").Return(&users.User{ID: "user-1", Password: hash, Role: users.RoleUser}, nil)
+	router := setupStepUpRouter(service)
+
+	t.Run("with the password", func(t *testing.T) {
+		rr := exchange(t, router, `{"scope":"email:change","password":"correct horse"}`, time.Now().Add(-24*time.Hour))
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var resp exchangeTokenResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, ScopeEmailChange, resp.Scope)
+		assert.WithinDuration(t, time.Now().Add(StepUpTokenLifetime), time.Unix(resp.ExpiresAt, 0), 2*time.Second)
+
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(resp.Token, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte(sessionTestSecret), nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims["user_id"])
+		assert.Equal(t, "email:change", claims["scope"])
+	})
+
+	t.Run("recently signed in", func(t *testing.T) {
+		rr := exchange(t, router, `{"scope":"email:change"}`, time.Now())
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("signed in too long ago", func(t *testing.T) {
+		rr := exchange(t, router, `{"scope":"email:change"}`, time.Now().Add(-time.Hour))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), string(appErrors.CodeReauthenticationRequired))
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		rr := exchange(t, router, `{"scope":"email:change","password":"wrong"}`, time.Now())
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		rr := exchange(t, router, `{"scope":"admin"}`, time.Now())
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestChangeEmail(t *testing.T) {
+	stepUp := func(t *testing.T, userID string) string {
+		t.Helper()
+		token, err := tokens.FromSecret(sessionTestSecret).Issue(jwt.MapClaims{
+			"user_id": userID, "role": "user", "scope": ScopeEmailChange,
+		}, time.Now().Add(StepUpTokenLifetime))
+		require.NoError(t, err)
+		return token
+	}
+	changeEmail := func(router http.Handler, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/users/user-1/email", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("with a step-up token", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("PatchUser", mock.Anything, "user-1", userService.PatchUserRequest{Email: mergepatch.Value("jane@example.com")}).
+			Return(&users.User{ID: "user-1", Email: "jane@example.com"}, nil)
+
+		rr := changeEmail(setupStepUpRouter(service), stepUp(t, "user-1"), `{"email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("PatchUser", mock.Anything, "user-1", mock.Anything).Return(nil, users.ErrUserAlreadyExists)
+
+		rr := changeEmail(setupStepUpRouter(service), stepUp(t, "user-1"), `{"email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("another user's step-up token", func(t *testing.T) {
+		service := new(MockUserService)
+		rr := changeEmail(setupStepUpRouter(service), stepUp(t, "user-2"), `{"email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		service.AssertNotCalled(t, "PatchUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("regular token", func(t *testing.T) {
+		service := new(MockUserService)
+		token, err := tokens.FromSecret(sessionTestSecret).Issue(jwt.MapClaims{"user_id": "user-1", "role": "user"}, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		rr := changeEmail(setupStepUpRouter(service), token, `{"email":"jane@example.com"}`)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), string(appErrors.CodeReauthenticationRequired))
+	})
+}
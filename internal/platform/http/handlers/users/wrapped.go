@@ -0,0 +1,83 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetWrapped handles GET /users/{id}/wrapped, the user's year in review.
+// The year query parameter defaults to the current year.
+func (h *Handler) GetWrapped(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var year int
+	if value := r.URL.Query().Get("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			h.responseWriter.WriteError(w, "year must be an integer", http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+
+	review, err := h.userService.GetYearInReview(r.Context(), id, year)
+	if err != nil {
+		h.logger.Error("[get_wrapped_handler] Failed to get year in review", "error", err, "user_id", id, "year", year)
+		var appErr *appErrors.AppError
+		if errors.As(err, &appErr) {
+			h.responseWriter.WriteAppError(w, appErr)
+			return
+		}
+		h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.responseWriter.WriteSuccess(w, yearInReviewToResponse(review), http.StatusOK)
+}
+
+func yearInReviewToResponse(review *userService.YearInReview) YearInReviewResponse {
+	resp := YearInReviewResponse{
+		UserID:          review.UserID,
+		Year:            review.Year,
+		TotalRatings:    review.TotalRatings,
+		AverageScore:    review.AverageScore,
+		RatingsPerMonth: monthlyCountsToResponse(review.RatingsPerMonth),
+		LongestStreak:   streakToResponse(review.LongestStreak),
+		TopDecade:       review.TopDecade,
+		TopGenre:        review.TopGenre,
+		TopDirector:     review.TopDirector,
+		TopRated:        ratingsWithMoviesToResponse(review.TopRated),
+	}
+	if resp.RatingsPerMonth == nil {
+		resp.RatingsPerMonth = []MonthlyCountResponse{}
+	}
+	return resp
+}
+
+func monthlyCountsToResponse(counts []rating.MonthlyCount) []MonthlyCountResponse {
+	if len(counts) == 0 {
+		return nil
+	}
+	resp := make([]MonthlyCountResponse, len(counts))
+	for i, c := range counts {
+		resp[i] = MonthlyCountResponse{Month: c.Month, Count: c.Count}
+	}
+	return resp
+}
+
+func streakToResponse(streak *rating.Streak) *StreakResponse {
+	if streak == nil {
+		return nil
+	}
+	return &StreakResponse{
+		Days:  streak.Days,
+		Start: streak.Start.Format(rating.StatsHistoryDateLayout),
+		End:   streak.End.Format(rating.StatsHistoryDateLayout),
+	}
+}
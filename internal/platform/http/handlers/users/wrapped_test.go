@@ -0,0 +1,98 @@
+package users
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	userService "thermondo/internal/platform/service/user"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupWrappedRouter(service *MockUserService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(sessionTestSecret)).RegisterRoutes(router)
+	return router
+}
+
+func TestGetWrapped(t *testing.T) {
+	t.Run("year in review", func(t *testing.T) {
+		service := new(MockUserService)
+		ratedAt := time.Date(2023, 3, 1, 20, 0, 0, 0, time.UTC)
+		service.On("GetYearInReview", mock.Anything, "user-1", 2023).Return(&userService.YearInReview{
+			UserID:          "user-1",
+			Year:            2023,
+			TotalRatings:    1,
+			AverageScore:    5,
+			RatingsPerMonth: []rating.MonthlyCount{{Month: "2023-03", Count: 1}},
+			LongestStreak:   &rating.Streak{Days: 1, Start: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)},
+			TopDecade:       1990,
+			TopGenre:        "Crime",
+			TopDirector:     "Michael Mann",
+			TopRated: []*userService.UserRatingWithMovie{{
+				Rating:       &rating.Rating{ID: "rating-1", Score: 5, CreatedAt: ratedAt},
+				Movie:        &movies.Movie{ID: "movie-heat", Title: "Heat", ReleaseYear: 1995, Genre: "Crime", Director: "Michael Mann"},
+				MovieAverage: 4.5,
+				TotalRatings: 2,
+				UserVsAvg:    "above",
+			}},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		setupWrappedRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/wrapped?year=2023", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"user_id":"user-1","year":2023,"total_ratings":1,"average_score":5,
+			"ratings_per_month":[{"month":"2023-03","count":1}],
+			"longest_streak":{"days":1,"start":"2023-03-01","end":"2023-03-01"},
+			"top_decade":1990,"top_genre":"Crime","top_director":"Michael Mann",
+			"top_rated":[{
+				"rating_id":"rating-1","score":5,"review":"","rated_at":"2023-03-01T20:00:00Z",
+				"movie_id":"movie-heat","title":"Heat","release_year":1995,"genre":"Crime","director":"Michael Mann",
+				"movie_average":4.5,"total_ratings":2,"user_vs_average":"above"
+			}]
+		}`, w.Body.String())
+	})
+
+	t.Run("defaults to the current year", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("GetYearInReview", mock.Anything, "user-1", 0).Return(&userService.YearInReview{UserID: "user-1", Year: 2024}, nil)
+
+		w := httptest.NewRecorder()
+		setupWrappedRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/wrapped", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"ratings_per_month":[]`)
+	})
+
+	t.Run("invalid year", func(t *testing.T) {
+		service := new(MockUserService)
+
+		w := httptest.NewRecorder()
+		setupWrappedRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/user-1/wrapped?year=last", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		service.AssertNotCalled(t, "GetYearInReview", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("GetYearInReview", mock.Anything, "missing", 2023).Return(nil, appErrors.NewNotFoundError("User not found"))
+
+		w := httptest.NewRecorder()
+		setupWrappedRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/missing/wrapped?year=2023", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,29 @@
+package views
+
+// ViewedMovieResponse is a movie of a user's recently viewed
+type ViewedMovieResponse struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseYear int     `json:"release_year"`
+	Genre       string  `json:"genre"`
+	PosterURL   *string `json:"poster_url,omitempty"`
+	ViewedAt    string  `json:"viewed_at"`
+}
+
+type RecentlyViewedResponse struct {
+	Movies []ViewedMovieResponse `json:"movies"`
+}
+
+// PopularMovieResponse is a movie and how many users viewed it today
+type PopularMovieResponse struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseYear int     `json:"release_year"`
+	Genre       string  `json:"genre"`
+	PosterURL   *string `json:"poster_url,omitempty"`
+	Views       int64   `json:"views"`
+}
+
+type PopularTodayResponse struct {
+	Movies []PopularMovieResponse `json:"movies"`
+}
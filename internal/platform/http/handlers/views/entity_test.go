@@ -0,0 +1,16 @@
+package views
+
+import (
+	"testing"
+
+	"thermondo/internal/pkg/http/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTOs(t *testing.T) {
+	assert.Empty(t, dto.Check(
+		RecentlyViewedResponse{},
+		PopularTodayResponse{},
+	))
+}
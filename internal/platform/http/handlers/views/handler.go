@@ -0,0 +1,168 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"thermondo/internal/platform/http/middleware"
+	viewService "thermondo/internal/platform/service/views"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler serves view tracking: signed-in users record the movies they
+// view, read back what they viewed recently, and everyone can see the
+// movies viewed most today
+type Handler struct {
+	service        viewService.Service
+	responseWriter *response.Writer
+	auth           *middleware.AuthMiddleware
+	sessions       middleware.SessionValidator
+	logger         *slog.Logger
+}
+
+// Option configures optional behaviour of the views handler
+type Option func(*Handler)
+
+// WithSessionValidator rejects tokens whose session has been revoked
+func WithSessionValidator(validator middleware.SessionValidator) Option {
+	return func(h *Handler) {
+		h.sessions = validator
+	}
+}
+
+func NewHandler(service viewService.Service, logger *slog.Logger, keys *tokens.Keys, opts ...Option) *Handler {
+	responseWriter := response.NewWriter(logger)
+	h := &Handler{
+		service:        service,
+		responseWriter: responseWriter,
+		logger:         logger,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	var authOptions []middleware.AuthOption
+	if h.sessions != nil {
+		authOptions = append(authOptions, middleware.WithSessionValidator(h.sessions))
+	}
+	h.auth = middleware.NewAuthMiddleware(keys, responseWriter, authOptions...)
+	return h
+}
+
+// RegisterRoutes registers plain routes rather than /movies and /users
+// sub-routers, which the movies and users handlers own
+func (h *Handler) RegisterRoutes(router chi.Router) {
+	router.Get("/movies/popular/today", h.PopularToday)
+	router.With(h.auth.Authenticate).Post("/movies/{movieId}/view", h.RecordView)
+	router.With(h.auth.Authenticate).Get("/users/{userId}/recently-viewed", h.RecentlyViewed)
+}
+
+// RecordView handles POST /movies/{movieId}/view for the signed-in user
+func (h *Handler) RecordView(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value("user_id").(string)
+	movieID := chi.URLParam(r, "movieId")
+
+	if err := h.service.Record(r.Context(), users.UserID(userID), movies.MovieID(movieID)); err != nil {
+		h.logger.Error("[record_view_handler] Failed to record view", "error", err, "movie_id", movieID)
+		h.handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecentlyViewed handles GET /users/{userId}/recently-viewed. What a user
+// viewed is private, so only the user and admins may see it.
+func (h *Handler) RecentlyViewed(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "userId")
+	callerID, _ := r.Context().Value("user_id").(string)
+	role, _ := r.Context().Value("user_role").(string)
+	if callerID != userID && role != string(users.RoleAdmin) {
+		h.responseWriter.WriteError(w, "You can only see the movies you viewed", http.StatusForbidden)
+		return
+	}
+	limit, err := parseLimit(r, viewService.DefaultRecentLimit, viewService.MaxRecentLimit)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	viewed, err := h.service.RecentlyViewed(r.Context(), users.UserID(userID), limit)
+	if err != nil {
+		h.logger.Error("[recently_viewed_handler] Failed to get recently viewed movies", "error", err, "user_id", userID)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := RecentlyViewedResponse{Movies: make([]ViewedMovieResponse, len(viewed))}
+	for i, v := range viewed {
+		resp.Movies[i] = ViewedMovieResponse{
+			ID:          string(v.Movie.ID),
+			Title:       v.Movie.Title,
+			ReleaseYear: v.Movie.ReleaseYear,
+			Genre:       v.Movie.Genre,
+			PosterURL:   v.Movie.PosterURL,
+			ViewedAt:    v.ViewedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
+
+// PopularToday handles GET /movies/popular/today, the movies viewed by the
+// most users today (UTC)
+func (h *Handler) PopularToday(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r, viewService.DefaultPopularLimit, viewService.MaxPopularLimit)
+	if err != nil {
+		h.responseWriter.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	popular, err := h.service.PopularToday(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("[popular_today_handler] Failed to get popular movies", "error", err)
+		h.handleServiceError(w, err)
+		return
+	}
+
+	resp := PopularTodayResponse{Movies: make([]PopularMovieResponse, len(popular))}
+	for i, p := range popular {
+		resp.Movies[i] = PopularMovieResponse{
+			ID:          string(p.Movie.ID),
+			Title:       p.Movie.Title,
+			ReleaseYear: p.Movie.ReleaseYear,
+			Genre:       p.Movie.Genre,
+			PosterURL:   p.Movie.PosterURL,
+			Views:       p.Views,
+		}
+	}
+	h.responseWriter.WriteSuccess(w, resp, http.StatusOK)
+}
+
+func parseLimit(r *http.Request, fallback, maximum int) (int, error) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return fallback, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > maximum {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maximum)
+	}
+	return limit, nil
+}
+
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		h.responseWriter.WriteAppError(w, appErr)
+		return
+	}
+	h.responseWriter.WriteError(w, "Internal server error", http.StatusInternalServerError)
+}
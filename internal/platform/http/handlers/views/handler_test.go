@@ -0,0 +1,138 @@
+package views
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/tokens"
+	viewService "thermondo/internal/platform/service/views"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "test-secret"
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupRouter(service *MockViewService) *chi.Mux {
+	router := chi.NewRouter()
+	NewHandler(service, slog.New(slog.NewTextHandler(io.Discard, nil)), tokens.FromSecret(testSecret)).RegisterRoutes(router)
+	return router
+}
+
+func bearerRequest(t *testing.T, method, target, userID, role string) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func TestRecordView(t *testing.T) {
+	t.Run("records the signed-in user's view", func(t *testing.T) {
+		service := new(MockViewService)
+		service.On("Record", mock.Anything, users.UserID("user-1"), movies.MovieID("movie-1")).Return(nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodPost, "/movies/movie-1/view", "user-1", "user"))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("unknown movie", func(t *testing.T) {
+		service := new(MockViewService)
+		service.On("Record", mock.Anything, mock.Anything, mock.Anything).Return(appErrors.NewNotFoundError("Movie not found"))
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodPost, "/movies/missing/view", "user-1", "user"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("requires a token", func(t *testing.T) {
+		service := new(MockViewService)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/movies/movie-1/view", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		service.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRecentlyViewed(t *testing.T) {
+	viewed := []*viewService.ViewedMovie{
+		{Movie: &movies.Movie{ID: "movie-1", Title: "Alien", ReleaseYear: 1979, Genre: "Horror"}, ViewedAt: testNow},
+	}
+
+	t.Run("own views", func(t *testing.T) {
+		service := new(MockViewService)
+		service.On("RecentlyViewed", mock.Anything, users.UserID("user-1"), 5).Return(viewed, nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodGet, "/users/user-1/recently-viewed?limit=5", "user-1", "user"))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"movies":[{"id":"movie-1","title":"Alien","release_year":1979,"genre":"Horror","viewed_at":"2024-01-01T12:00:00Z"}]}`, w.Body.String())
+	})
+
+	t.Run("admins see anyone's", func(t *testing.T) {
+		service := new(MockViewService)
+		service.On("RecentlyViewed", mock.Anything, users.UserID("user-1"), viewService.DefaultRecentLimit).Return(viewed, nil)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodGet, "/users/user-1/recently-viewed", "admin-1", "admin"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("other users are forbidden", func(t *testing.T) {
+		service := new(MockViewService)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodGet, "/users/user-1/recently-viewed", "user-2", "user"))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		service.AssertNotCalled(t, "RecentlyViewed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		service := new(MockViewService)
+
+		w := httptest.NewRecorder()
+		setupRouter(service).ServeHTTP(w, bearerRequest(t, http.MethodGet, "/users/user-1/recently-viewed?limit=51", "user-1", "user"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestPopularToday(t *testing.T) {
+	service := new(MockViewService)
+	service.On("PopularToday", mock.Anything, 3).Return([]*viewService.PopularMovie{
+		{Movie: &movies.Movie{ID: "movie-1", Title: "Alien", ReleaseYear: 1979, Genre: "Horror"}, Views: 42},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	setupRouter(service).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/movies/popular/today?limit=3", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"movies":[{"id":"movie-1","title":"Alien","release_year":1979,"genre":"Horror","views":42}]}`, w.Body.String())
+}
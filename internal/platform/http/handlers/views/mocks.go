@@ -0,0 +1,46 @@
+package views
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	viewService "thermondo/internal/platform/service/views"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockViewService is a mock implementation of the view service
+type MockViewService struct {
+	mock.Mock
+}
+
+func (m *MockViewService) Record(ctx context.Context, userID users.UserID, movieID movies.MovieID) error {
+	args := m.Called(ctx, userID, movieID)
+	return args.Error(0)
+}
+
+func (m *MockViewService) RecentlyViewed(ctx context.Context, userID users.UserID, limit int) ([]*viewService.ViewedMovie, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*viewService.ViewedMovie), args.Error(1)
+}
+
+func (m *MockViewService) PopularToday(ctx context.Context, limit int) ([]*viewService.PopularMovie, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*viewService.PopularMovie), args.Error(1)
+}
+
+func (m *MockViewService) Flush(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockViewService) StartFlusher(ctx context.Context, interval time.Duration) {
+	m.Called(ctx, interval)
+}
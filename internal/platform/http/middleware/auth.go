@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"strings"
 	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
 	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -14,59 +17,169 @@ import (
 var (
 	ErrNoAuthHeader      = errors.New("no authorization header")
 	ErrInvalidAuthHeader = errors.New("invalid authorization header format")
-	ErrInvalidToken      = errors.New("invalid token")
+	ErrInvalidToken      = tokens.ErrInvalidToken
 )
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Role   string `json:"role"`
+	// SessionID ties the token to a revocable session
+	SessionID string `json:"sid,omitempty"`
+	// Scope limits a step-up token to the one operation it was issued for
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// SessionValidator checks that the session a token was issued for is still
+// active; it returns users.ErrSessionRevoked when it is not
+type SessionValidator interface {
+	Validate(ctx context.Context, sessionID string) error
+}
+
 type AuthMiddleware struct {
-	jwtSecret []byte
-	writer    *response.Writer
+	keys     *tokens.Keys
+	writer   *response.Writer
+	sessions SessionValidator
 }
 
-func NewAuthMiddleware(jwtSecret string, writer *response.Writer) *AuthMiddleware {
-	return &AuthMiddleware{
-		jwtSecret: []byte(jwtSecret),
-		writer:    writer,
+// AuthOption configures an AuthMiddleware
+type AuthOption func(*AuthMiddleware)
+
+// WithSessionValidator rejects tokens whose session has been revoked.
+// Tokens issued without a session are still accepted until they expire.
+func WithSessionValidator(validator SessionValidator) AuthOption {
+	return func(m *AuthMiddleware) {
+		m.sessions = validator
+	}
+}
+
+func NewAuthMiddleware(keys *tokens.Keys, writer *response.Writer, opts ...AuthOption) *AuthMiddleware {
+	m := &AuthMiddleware{
+		keys:   keys,
+		writer: writer,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
+// Authenticate verifies the bearer token of the request and adds its
+// principal to the context. A request InternalCallers authenticated on
+// behalf of a user needs no token. Step-up tokens are only accepted by
+// AuthenticateStepUp.
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			m.writer.WriteError(w, ErrNoAuthHeader.Error(), http.StatusUnauthorized)
+		if principal, ok := PrincipalFrom(r.Context()); ok && principal.Caller != "" {
+			next.ServeHTTP(w, r.WithContext(withUserValues(r.Context(), principal)))
 			return
 		}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			m.writer.WriteError(w, ErrInvalidAuthHeader.Error(), http.StatusUnauthorized)
+		principal, ok := m.authenticate(w, r)
+		if !ok {
+			return
+		}
+		if principal.Scope != "" {
+			m.writer.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeUnauthorized, "token is only valid for "+principal.Scope))
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(withUserValues(WithPrincipal(r.Context(), principal), principal)))
+	})
+}
+
+// AuthenticateOptional authenticates requests that carry a bearer token
+// like Authenticate and lets those without one through anonymously, for
+// reads whose response depends on who asks. A token that is not valid is
+// still rejected.
+func (m *AuthMiddleware) AuthenticateOptional(next http.Handler) http.Handler {
+	authenticated := m.Authenticate(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := PrincipalFrom(r.Context()); !ok && r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
 
-		tokenStr := parts[1]
-		claims := &Claims{}
+// AuthenticateStepUp only accepts step-up tokens issued for scope, which
+// users exchange their token for by proving who they are again. Internal
+// callers cannot present one.
+func (m *AuthMiddleware) AuthenticateStepUp(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := m.authenticate(w, r)
+			if !ok {
+				return
+			}
+			if principal.Scope != scope {
+				m.writer.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeReauthenticationRequired, "a step-up token for "+scope+" is required"))
+				return
+			}
 
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return m.jwtSecret, nil
+			next.ServeHTTP(w, r.WithContext(withUserValues(WithPrincipal(r.Context(), principal), principal)))
 		})
+	}
+}
 
-		if err != nil || !token.Valid {
-			m.writer.WriteError(w, ErrInvalidToken.Error(), http.StatusUnauthorized)
-			return
+// authenticate verifies the bearer token and its session, writing the
+// problem and returning false when either is not valid
+func (m *AuthMiddleware) authenticate(w http.ResponseWriter, r *http.Request) (*Principal, bool) {
+	claims, err := bearerClaims(r, m.keys)
+	if err != nil {
+		m.writer.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeUnauthorized, err.Error()))
+		return nil, false
+	}
+
+	if m.sessions != nil && claims.SessionID != "" {
+		if err := m.sessions.Validate(r.Context(), claims.SessionID); err != nil {
+			if errors.Is(err, users.ErrSessionRevoked) {
+				m.writer.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeUnauthorized, err.Error()))
+				return nil, false
+			}
+			m.writer.WriteProblem(w, r, response.NewProblem(http.StatusServiceUnavailable, appErrors.CodeServiceUnavailable, "unable to verify session"))
+			return nil, false
 		}
+	}
 
-		// Add user info to context
-		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		ctx = context.WithValue(ctx, "user_role", claims.Role)
+	principal := &Principal{
+		UserID:    claims.UserID,
+		Role:      users.Role(claims.Role),
+		SessionID: claims.SessionID,
+		Scope:     claims.Scope,
+	}
+	if claims.IssuedAt != nil {
+		principal.AuthenticatedAt = claims.IssuedAt.Time
+	}
+	return principal, true
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// withUserValues adds the principal under the keys handlers read before
+// there was a Principal
+func withUserValues(ctx context.Context, principal *Principal) context.Context {
+	ctx = context.WithValue(ctx, "user_id", principal.UserID)
+	ctx = context.WithValue(ctx, "user_role", string(principal.Role))
+	return context.WithValue(ctx, "session_id", principal.SessionID)
+}
+
+// bearerClaims parses and verifies the bearer token of the request
+func bearerClaims(r *http.Request, keys *tokens.Keys) (*Claims, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, ErrNoAuthHeader
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, ErrInvalidAuthHeader
+	}
+
+	claims := &Claims{}
+	if err := keys.Parse(parts[1], claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
 // RequireRole middleware ensures the user has the required role
@@ -75,7 +188,24 @@ func (m *AuthMiddleware) RequireRole(role users.Role) func(http.Handler) http.Ha
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userRole := r.Context().Value("user_role").(string)
 			if userRole != string(role) {
-				m.writer.WriteError(w, "insufficient permissions", http.StatusForbidden)
+				m.writer.WriteProblem(w, r, response.NewProblem(http.StatusForbidden, appErrors.CodeForbidden, "insufficient permissions"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRecentAuth rejects callers who signed in more than maxAge ago, so
+// a stolen long-lived token cannot change how the account is signed in
+// to. Callers acting on behalf of a user never signed in themselves and
+// are always rejected. It must run after Authenticate.
+func (m *AuthMiddleware) RequireRecentAuth(maxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFrom(r.Context())
+			if !ok || principal.AuthenticatedAt.IsZero() || time.Since(principal.AuthenticatedAt) > maxAge {
+				m.writer.WriteProblem(w, r, response.NewProblem(http.StatusUnauthorized, appErrors.CodeReauthenticationRequired, "sign in again to continue"))
 				return
 			}
 			next.ServeHTTP(w, r)
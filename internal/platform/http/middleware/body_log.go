@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"thermondo/internal/pkg/logging"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// maxLoggedBody is how much of each body is logged
+const maxLoggedBody = 16 << 10
+
+// secretField matches a JSON member whose name mentions a secret, and its
+// value up to where the value ends or the logged body was cut off. It works
+// on malformed JSON too, which is what the log is there to show.
+var secretField = regexp.MustCompile(`(?i)("[^"]*(?:password|token|secret|authorization|api_key|captcha)[^"]*"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+
+// LogBodies logs the request and response bodies of the requests bodies
+// selects, at debug level, to diagnose clients that send malformed
+// requests. The values of JSON members such as passwords and tokens are
+// redacted, and bodies of other types are only described. Nothing is
+// captured unless bodies is active and logger is enabled for debug.
+func LogBodies(bodies *logging.Bodies, logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !bodies.Active() || !logger.Enabled(r.Context(), slog.LevelDebug) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			request := &bodyCapture{}
+			if r.Body != nil {
+				r.Body = &teeBody{ReadCloser: r.Body, capture: request}
+			}
+			response := &bodyCapture{}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(response)
+			next.ServeHTTP(ww, r)
+
+			var pattern string
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				pattern = rctx.RoutePattern()
+			}
+			if !bodies.Selects(r.Method, pattern) {
+				return
+			}
+			logger.DebugContext(r.Context(), "HTTP bodies",
+				slog.String("method", r.Method),
+				slog.String("route", pattern),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", ww.Status()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("request_body", request.describe(r.Header.Get("Content-Type"))),
+				slog.String("response_body", response.describe(ww.Header().Get("Content-Type"))),
+			)
+		})
+	}
+}
+
+// bodyCapture keeps the first maxLoggedBody bytes written to it and counts
+// the rest
+type bodyCapture struct {
+	buf  bytes.Buffer
+	size int
+}
+
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	c.size += len(p)
+	if room := maxLoggedBody - c.buf.Len(); room > 0 {
+		c.buf.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+// describe renders the body for the log: JSON with secrets redacted, and
+// anything else by its type and size. Bodies without a type are taken for
+// JSON, as the handlers decode them.
+func (c *bodyCapture) describe(contentType string) string {
+	if c.size == 0 {
+		return ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return fmt.Sprintf("[%d bytes of %s]", c.size, mediaType)
+	}
+	body := secretField.ReplaceAllString(c.buf.String(), `$1"[REDACTED]"`)
+	if c.size > c.buf.Len() {
+		body += fmt.Sprintf("...[%d bytes in total]", c.size)
+	}
+	return body
+}
+
+// teeBody copies what the handler reads from a request body to a capture
+type teeBody struct {
+	io.ReadCloser
+	capture *bodyCapture
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture.Write(p[:n])
+	return n, err
+}
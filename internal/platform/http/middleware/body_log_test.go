@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/pkg/logging"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBodies(t *testing.T) {
+	setup := func(level slog.Level) (*logging.Bodies, *bytes.Buffer, http.Handler) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+		bodies := logging.NewBodies()
+
+		router := chi.NewRouter()
+		router.Use(LogBodies(bodies, logger))
+		router.Post("/users/login", func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token":"eyJhbGciOi","expires_at":1700000000}`))
+		})
+		router.Put("/users/{id}/avatar", func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		})
+		return bodies, &buf, router
+	}
+	send := func(router http.Handler, method, path, contentType, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	logged := func(t *testing.T, buf *bytes.Buffer) map[string]any {
+		t.Helper()
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+		return entry
+	}
+
+	t.Run("logs selected routes with secrets redacted", func(t *testing.T) {
+		bodies, buf, router := setup(slog.LevelDebug)
+		require.NoError(t, bodies.Set(0, []string{"POST /users/login"}))
+
+		send(router, http.MethodPost, "/users/login", "application/json", `{"email":"a@example.com","password":"hunter2","Captcha_Token": "abc"}`)
+
+		entry := logged(t, buf)
+		assert.Equal(t, "/users/login", entry["route"])
+		assert.EqualValues(t, 200, entry["status"])
+		assert.Equal(t, `{"email":"a@example.com","password":"[REDACTED]","Captcha_Token": "[REDACTED]"}`, entry["request_body"])
+		assert.Equal(t, `{"token":"[REDACTED]","expires_at":1700000000}`, entry["response_body"])
+	})
+
+	t.Run("redacts malformed JSON as sent", func(t *testing.T) {
+		bodies, buf, router := setup(slog.LevelDebug)
+		require.NoError(t, bodies.Set(100, nil))
+
+		send(router, http.MethodPost, "/users/login", "", `{"email":"a@example.com", "password": "hunter2`)
+
+		assert.Equal(t, `{"email":"a@example.com", "password": "[REDACTED]"`, logged(t, buf)["request_body"])
+	})
+
+	t.Run("describes other content", func(t *testing.T) {
+		bodies, buf, router := setup(slog.LevelDebug)
+		require.NoError(t, bodies.Set(0, []string{"/users/{id}/avatar"}))
+
+		send(router, http.MethodPut, "/users/u1/avatar", "image/png", "\x89PNG....")
+
+		entry := logged(t, buf)
+		assert.Equal(t, "[8 bytes of image/png]", entry["request_body"])
+		assert.Equal(t, "", entry["response_body"])
+	})
+
+	t.Run("logs nothing unless selected and enabled", func(t *testing.T) {
+		bodies, buf, router := setup(slog.LevelDebug)
+		send(router, http.MethodPost, "/users/login", "application/json", `{}`)
+		require.NoError(t, bodies.Set(0, []string{"GET /users/login"}))
+		send(router, http.MethodPost, "/users/login", "application/json", `{}`)
+		assert.Empty(t, buf.String())
+
+		bodies, buf, router = setup(slog.LevelInfo)
+		require.NoError(t, bodies.Set(100, nil))
+		send(router, http.MethodPost, "/users/login", "application/json", `{}`)
+		assert.Empty(t, buf.String(), "bodies are logged at debug level")
+	})
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+)
+
+// ContentModeHeader asks for a content mode, e.g. kids on a shared family
+// device. Responses carry the mode that applied.
+const ContentModeHeader = "X-Content-Mode"
+
+// ContentModeFinder looks up the user whose profile may set kids mode
+type ContentModeFinder interface {
+	FindByID(ctx context.Context, id users.UserID) (*users.User, error)
+}
+
+// ContentMode puts the content mode of each request in its context: kids
+// when the X-Content-Mode header asks for it or when the bearer token's user
+// has kids mode set on their profile, so a header can't lift a profile's
+// kids mode. An unknown mode in the header is rejected.
+func ContentMode(finder ContentModeFinder, writer *response.Writer, keys *tokens.Keys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mode := users.ContentModeStandard
+			if header := r.Header.Get(ContentModeHeader); header != "" {
+				parsed, err := users.ParseContentMode(header)
+				if err != nil {
+					writer.WriteProblem(w, r, response.NewProblem(http.StatusBadRequest, appErrors.CodeBadRequest, err.Error()))
+					return
+				}
+				mode = parsed
+			}
+
+			if mode != users.ContentModeKids {
+				if claims, err := bearerClaims(r, keys); err == nil && claims.UserID != "" {
+					user, err := finder.FindByID(r.Context(), users.UserID(claims.UserID))
+					if err != nil && !errors.Is(err, sql.ErrNoRows) {
+						writer.WriteProblem(w, r, response.NewProblem(http.StatusServiceUnavailable, appErrors.CodeServiceUnavailable, "unable to verify content mode"))
+						return
+					}
+					if user != nil && user.ContentMode == users.ContentModeKids {
+						mode = users.ContentModeKids
+					}
+				}
+			}
+
+			w.Header().Add("Vary", ContentModeHeader)
+			w.Header().Set(ContentModeHeader, string(mode))
+			next.ServeHTTP(w, r.WithContext(users.WithContentMode(r.Context(), mode)))
+		})
+	}
+}
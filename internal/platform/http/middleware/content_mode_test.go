@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContentModeFinder map[users.UserID]*users.User
+
+func (f fakeContentModeFinder) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	if id == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	user, ok := f[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func TestContentMode(t *testing.T) {
+	const secret = "test-secret"
+	tokenFor := func(userID string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"user_id": userID,
+			"role":    "user",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return "Bearer " + token
+	}
+
+	finder := fakeContentModeFinder{
+		"parent": {ID: "parent", ContentMode: users.ContentModeStandard},
+		"child":  {ID: "child", ContentMode: users.ContentModeKids},
+	}
+	var seen users.ContentMode
+	handler := ContentMode(finder, response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))), tokens.FromSecret(secret))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = users.ContentModeFrom(r.Context())
+		}),
+	)
+
+	tests := []struct {
+		name          string
+		header        string
+		authorization string
+		wantStatus    int
+		wantMode      users.ContentMode
+	}{
+		{name: "anonymous defaults to standard", wantStatus: http.StatusOK, wantMode: users.ContentModeStandard},
+		{name: "header asks for kids", header: "Kids", wantStatus: http.StatusOK, wantMode: users.ContentModeKids},
+		{name: "unknown mode", header: "adult", wantStatus: http.StatusBadRequest},
+		{name: "standard profile", authorization: tokenFor("parent"), wantStatus: http.StatusOK, wantMode: users.ContentModeStandard},
+		{name: "kids profile", authorization: tokenFor("child"), wantStatus: http.StatusOK, wantMode: users.ContentModeKids},
+		{name: "header cannot lift kids profile", header: "standard", authorization: tokenFor("child"), wantStatus: http.StatusOK, wantMode: users.ContentModeKids},
+		{name: "deleted user", authorization: tokenFor("gone"), wantStatus: http.StatusOK, wantMode: users.ContentModeStandard},
+		{name: "profile lookup fails", authorization: tokenFor("broken"), wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen = ""
+			req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+			if tt.header != "" {
+				req.Header.Set(ContentModeHeader, tt.header)
+			}
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantMode, seen)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, string(tt.wantMode), w.Header().Get(ContentModeHeader))
+				assert.Equal(t, ContentModeHeader, w.Header().Get("Vary"))
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/metrics"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Headers of the requests other services of ours send
+const (
+	// InternalTokenHeader carries the token identifying the calling service
+	InternalTokenHeader = "X-Internal-Token"
+	// OnBehalfOfHeader names the user an impersonating service acts for
+	OnBehalfOfHeader = "X-On-Behalf-Of"
+)
+
+// MinInternalTokenLength is the shortest token accepted for a caller
+const MinInternalTokenLength = 32
+
+// The attributes requests of internal callers are logged with
+const (
+	CallerKey     = "caller"
+	OnBehalfOfKey = "on_behalf_of"
+)
+
+var internalRejections = metrics.NewCounterVec("internal_auth_rejections_total", "Requests rejected for their internal caller headers, by reason", "reason")
+
+// InternalCaller is another service trusted to call the API
+type InternalCaller struct {
+	Name  string
+	Token string
+	// Impersonate lets the caller act for the user it names in
+	// X-On-Behalf-Of
+	Impersonate bool
+}
+
+// ParseInternalCallers decodes "name:token" entries, as read from
+// configuration, letting the callers in impersonators act for users
+func ParseInternalCallers(entries []string, impersonators []string) ([]InternalCaller, error) {
+	var callers []InternalCaller
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("internal caller entry must be name:token")
+		}
+		if len(token) < MinInternalTokenLength {
+			return nil, fmt.Errorf("internal caller %q: token must be at least %d characters", name, MinInternalTokenLength)
+		}
+		if slices.ContainsFunc(callers, func(c InternalCaller) bool { return c.Name == name }) {
+			return nil, fmt.Errorf("internal caller %q is configured twice", name)
+		}
+		callers = append(callers, InternalCaller{Name: name, Token: token, Impersonate: slices.Contains(impersonators, name)})
+	}
+	for _, name := range impersonators {
+		if !slices.ContainsFunc(callers, func(c InternalCaller) bool { return c.Name == name }) {
+			return nil, fmt.Errorf("impersonator %q is not an internal caller", name)
+		}
+	}
+	return callers, nil
+}
+
+// InternalCallers authenticates requests that carry an X-Internal-Token
+// and logs them for audit, naming the caller and the user it acts for.
+// Callers allowed to impersonate may name a user in X-On-Behalf-Of; the
+// request then authenticates as that user, with the user role whatever
+// the user's own, instead of with a bearer token. The headers are trusted
+// from configured callers only: an unknown token, or X-On-Behalf-Of from
+// anyone else, is rejected rather than ignored. Requests without either
+// header pass through untouched.
+func InternalCallers(callers []InternalCaller, writer *response.Writer, logger *slog.Logger) func(http.Handler) http.Handler {
+	digests := make([][sha256.Size]byte, len(callers))
+	for i, caller := range callers {
+		digests[i] = sha256.Sum256([]byte(caller.Token))
+	}
+	identify := func(token string) (InternalCaller, bool) {
+		// Compare every digest in constant time, so timing does not tell
+		// how close a guess came or which caller it matched
+		digest := sha256.Sum256([]byte(token))
+		found := -1
+		for i := range digests {
+			if subtle.ConstantTimeCompare(digest[:], digests[i][:]) == 1 {
+				found = i
+			}
+		}
+		if found < 0 {
+			return InternalCaller{}, false
+		}
+		return callers[found], true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := r.Header.Get(InternalTokenHeader)
+			onBehalfOf := strings.TrimSpace(r.Header.Get(OnBehalfOfHeader))
+			_, sentOnBehalfOf := r.Header[http.CanonicalHeaderKey(OnBehalfOfHeader)]
+			if token == "" && !sentOnBehalfOf {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reject := func(status int, code appErrors.ErrorCode, reason, detail string, attrs ...any) {
+				internalRejections.With(reason).Inc()
+				logger.Warn("Internal request rejected", append([]any{
+					slog.String("reason", reason),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("remote_addr", r.RemoteAddr),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+				}, attrs...)...)
+				writer.WriteProblem(w, r, response.NewProblem(status, code, detail))
+			}
+
+			if token == "" {
+				reject(http.StatusUnauthorized, appErrors.CodeUnauthorized, "missing_token", OnBehalfOfHeader+" is only accepted from internal callers")
+				return
+			}
+			caller, ok := identify(token)
+			if !ok {
+				reject(http.StatusUnauthorized, appErrors.CodeUnauthorized, "unknown_token", "invalid internal token")
+				return
+			}
+
+			ctx := logging.WithAttrs(r.Context(), CallerKey, caller.Name)
+			if sentOnBehalfOf {
+				switch {
+				case !caller.Impersonate:
+					reject(http.StatusForbidden, appErrors.CodeForbidden, "not_impersonator", "caller may not act on behalf of users", slog.String(CallerKey, caller.Name))
+					return
+				case onBehalfOf == "":
+					reject(http.StatusBadRequest, appErrors.CodeBadRequest, "empty_user", OnBehalfOfHeader+" must name a user", slog.String(CallerKey, caller.Name))
+					return
+				case r.Header.Get("Authorization") != "":
+					reject(http.StatusBadRequest, appErrors.CodeBadRequest, "ambiguous_user", "send either a bearer token or "+OnBehalfOfHeader+", not both", slog.String(CallerKey, caller.Name))
+					return
+				}
+				ctx = logging.WithAttrs(ctx, OnBehalfOfKey, onBehalfOf)
+				ctx = WithPrincipal(ctx, &Principal{UserID: onBehalfOf, Role: users.RoleUser, Caller: caller.Name})
+			}
+
+			// Nothing downstream needs the token, and it must not end up
+			// in logged or forwarded headers
+			r.Header.Del(InternalTokenHeader)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			attrs := []any{
+				slog.String(CallerKey, caller.Name),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", ww.Status()),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			}
+			if onBehalfOf != "" {
+				attrs = append(attrs, slog.String(OnBehalfOfKey, onBehalfOf))
+			}
+			logger.Info("Internal request", attrs...)
+		})
+	}
+}
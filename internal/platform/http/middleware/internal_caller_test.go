@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	billingToken = strings.Repeat("b", MinInternalTokenLength)
+	searchToken  = strings.Repeat("s", MinInternalTokenLength)
+)
+
+func TestParseInternalCallers(t *testing.T) {
+	callers, err := ParseInternalCallers([]string{"billing:" + billingToken, " ", "search:" + searchToken}, []string{"billing"})
+	require.NoError(t, err)
+	assert.Equal(t, []InternalCaller{
+		{Name: "billing", Token: billingToken, Impersonate: true},
+		{Name: "search", Token: searchToken},
+	}, callers)
+
+	for name, tc := range map[string]struct {
+		entries       []string
+		impersonators []string
+		message       string
+	}{
+		"no token":             {entries: []string{"billing"}, message: "must be name:token"},
+		"short token":          {entries: []string{"billing:short"}, message: "at least 32 characters"},
+		"duplicate":            {entries: []string{"billing:" + billingToken, "billing:" + searchToken}, message: "configured twice"},
+		"unknown impersonator": {entries: []string{"billing:" + billingToken}, impersonators: []string{"search"}, message: `"search" is not an internal caller`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseInternalCallers(tc.entries, tc.impersonators)
+			assert.ErrorContains(t, err, tc.message)
+		})
+	}
+}
+
+func TestInternalCallers(t *testing.T) {
+	callers := []InternalCaller{
+		{Name: "billing", Token: billingToken, Impersonate: true},
+		{Name: "search", Token: searchToken},
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	writer := response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	auth := NewAuthMiddleware(tokens.FromSecret("test-secret"), writer)
+
+	var principal *Principal
+	var attrs []any
+	var forwardedToken string
+	handler := InternalCallers(callers, writer, logger)(auth.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFrom(r.Context())
+		attrs = logging.AttrsFrom(r.Context())
+		forwardedToken = r.Header.Get(InternalTokenHeader)
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		principal, attrs, forwardedToken = nil, nil, ""
+		logs.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/ratings", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("impersonates the user for a whitelisted caller", func(t *testing.T) {
+		w := serve(map[string]string{InternalTokenHeader: billingToken, OnBehalfOfHeader: "user-1"})
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, &Principal{UserID: "user-1", Role: users.RoleUser, Caller: "billing"}, principal)
+		assert.Equal(t, []any{CallerKey, "billing", OnBehalfOfKey, "user-1"}, attrs)
+		assert.Empty(t, forwardedToken, "the token is not passed on")
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+		assert.Equal(t, "Internal request", record["msg"])
+		assert.Equal(t, "billing", record[CallerKey])
+		assert.Equal(t, "user-1", record[OnBehalfOfKey])
+		assert.EqualValues(t, http.StatusNoContent, record["status"])
+	})
+
+	t.Run("a caller without a user still needs a bearer token", func(t *testing.T) {
+		w := serve(map[string]string{InternalTokenHeader: searchToken})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, logs.String(), `"caller":"search"`)
+	})
+
+	t.Run("requests without the headers pass through", func(t *testing.T) {
+		w := serve(nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "rejected by Authenticate, not by InternalCallers")
+		assert.Empty(t, logs.String())
+	})
+
+	for name, tc := range map[string]struct {
+		headers map[string]string
+		status  int
+		reason  string
+	}{
+		"on behalf of without a token": {
+			headers: map[string]string{OnBehalfOfHeader: "user-1"},
+			status:  http.StatusUnauthorized, reason: "missing_token",
+		},
+		"unknown token": {
+			headers: map[string]string{InternalTokenHeader: strings.Repeat("x", MinInternalTokenLength), OnBehalfOfHeader: "user-1"},
+			status:  http.StatusUnauthorized, reason: "unknown_token",
+		},
+		"caller not allowed to impersonate": {
+			headers: map[string]string{InternalTokenHeader: searchToken, OnBehalfOfHeader: "user-1"},
+			status:  http.StatusForbidden, reason: "not_impersonator",
+		},
+		"empty user": {
+			headers: map[string]string{InternalTokenHeader: billingToken, OnBehalfOfHeader: " "},
+			status:  http.StatusBadRequest, reason: "empty_user",
+		},
+		"bearer token and user": {
+			headers: map[string]string{InternalTokenHeader: billingToken, OnBehalfOfHeader: "user-1", "Authorization": "Bearer token"},
+			status:  http.StatusBadRequest, reason: "ambiguous_user",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			before := internalRejections.With(tc.reason).Value()
+			w := serve(tc.headers)
+
+			assert.Equal(t, tc.status, w.Code)
+			assert.Nil(t, principal, "the request is not served")
+			assert.Equal(t, before+1, internalRejections.With(tc.reason).Value())
+			assert.Contains(t, logs.String(), "Internal request rejected")
+		})
+	}
+}
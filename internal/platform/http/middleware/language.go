@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/i18n"
+)
+
+// Localize picks the language of each request from its Accept-Language
+// header, English when we have no catalog for any it asks for. Errors and
+// other messages shown to the user are translated into it.
+func Localize() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			language := i18n.Match(r.Header.Get("Accept-Language"))
+			next.ServeHTTP(response.WithLanguage(w, language), r.WithContext(i18n.WithLanguage(r.Context(), language)))
+		})
+	}
+}
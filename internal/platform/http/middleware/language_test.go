@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/i18n"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	writer := response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil)), response.WithErrorFormat(response.ErrorFormatProblem))
+	handler := Localize()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("language") {
+			w.Write([]byte(i18n.LanguageFrom(r.Context())))
+			return
+		}
+		// Writers wrapping the request's, as logging middleware does, keep
+		// its language
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		writer.WriteError(ww, "user not found", http.StatusNotFound)
+	}))
+
+	t.Run("translates errors", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/missing", nil)
+		req.Header.Set("Accept-Language", "de-DE, en;q=0.5")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "de", rec.Header().Get("Content-Language"))
+		var problem response.Problem
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.Equal(t, "Nicht gefunden", problem.Title)
+		assert.Equal(t, "Nutzer nicht gefunden", problem.Detail)
+		assert.Equal(t, "NOT_FOUND", string(problem.Code), "codes aren't translated")
+	})
+
+	t.Run("sets the request's language", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?language", nil)
+		req.Header.Set("Accept-Language", "ja, fr;q=0.3")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "fr", rec.Body.String())
+	})
+
+	t.Run("English without a catalog", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/missing", nil)
+		req.Header.Set("Accept-Language", "ja")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+		assert.Contains(t, rec.Body.String(), "user not found")
+	})
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+// Principal is the caller a bearer token was verified for. Handlers take
+// the acting user from it rather than from the request, so a caller can
+// only act as themselves unless they are an admin.
+type Principal struct {
+	UserID    string
+	Role      users.Role
+	SessionID string
+	// Caller is the internal service acting for the user, empty when the
+	// user called
+	Caller string
+	// AuthenticatedAt is when the token was issued, zero when unknown
+	AuthenticatedAt time.Time
+	// Scope is the operation a step-up token was issued for, empty for
+	// regular tokens
+	Scope string
+}
+
+// IsAdmin reports whether the caller may act on behalf of other users
+func (p *Principal) IsAdmin() bool {
+	return p.Role == users.RoleAdmin
+}
+
+// Is reports whether the caller is user id
+func (p *Principal) Is(id string) bool {
+	return p.UserID != "" && p.UserID == id
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated caller
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the caller that Authenticate verified, and false on
+// routes that are not authenticated
+func PrincipalFrom(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticatePrincipal(t *testing.T) {
+	const secret = "test-secret"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "admin",
+		"sid":     "session-1",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	var principal *Principal
+	var found bool
+	auth := NewAuthMiddleware(tokens.FromSecret(secret), response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	handler := auth.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, found = PrincipalFrom(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, found)
+	assert.Equal(t, &Principal{UserID: "user-1", Role: users.RoleAdmin, SessionID: "session-1"}, principal)
+	assert.True(t, principal.IsAdmin())
+	assert.True(t, principal.Is("user-1"))
+	assert.False(t, principal.Is("user-2"))
+
+	_, found = PrincipalFrom(req.Context())
+	assert.False(t, found, "unauthenticated requests carry no principal")
+}
+
+func TestRequireRecentAuth(t *testing.T) {
+	const secret = "test-secret"
+	auth := NewAuthMiddleware(tokens.FromSecret(secret), response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	handler := auth.Authenticate(auth.RequireRecentAuth(10 * time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	for name, tc := range map[string]struct {
+		claims jwt.MapClaims
+		want   int
+	}{
+		"recent": {claims: jwt.MapClaims{"iat": time.Now().Add(-time.Minute).Unix()}, want: http.StatusNoContent},
+		"stale":  {claims: jwt.MapClaims{"iat": time.Now().Add(-time.Hour).Unix()}, want: http.StatusUnauthorized},
+		"no iat": {claims: jwt.MapClaims{}, want: http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tc.claims["user_id"] = "user-1"
+			tc.claims["role"] = "user"
+			tc.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, tc.claims).SignedString([]byte(secret))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want, rr.Code)
+			if tc.want == http.StatusUnauthorized {
+				assert.Contains(t, rr.Body.String(), "REAUTHENTICATION_REQUIRED")
+			}
+		})
+	}
+}
+
+func TestAuthenticateStepUp(t *testing.T) {
+	const secret = "test-secret"
+	keys := tokens.FromSecret(secret)
+	auth := NewAuthMiddleware(keys, response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(handler http.Handler, claims jwt.MapClaims) int {
+		token, err := keys.Issue(claims, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	scoped := jwt.MapClaims{"user_id": "user-1", "role": "user", "scope": "email:change"}
+	regular := jwt.MapClaims{"user_id": "user-1", "role": "user"}
+
+	assert.Equal(t, http.StatusNoContent, serve(auth.AuthenticateStepUp("email:change")(ok), scoped))
+	assert.Equal(t, http.StatusUnauthorized, serve(auth.AuthenticateStepUp("email:change")(ok), regular))
+	assert.Equal(t, http.StatusUnauthorized, serve(auth.AuthenticateStepUp("account:delete")(ok), scoped))
+	assert.Equal(t, http.StatusUnauthorized, serve(auth.Authenticate(ok), scoped), "step-up tokens are good for nothing else")
+}
+
+func TestAuthenticateOptional(t *testing.T) {
+	keys := tokens.FromSecret("test-secret")
+	auth := NewAuthMiddleware(keys, response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	var userID string
+	handler := auth.AuthenticateOptional(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID = ""
+		if principal, ok := PrincipalFrom(r.Context()); ok {
+			userID = principal.UserID
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(""))
+	assert.Empty(t, userID, "anonymous requests carry no principal")
+
+	token, err := keys.Issue(jwt.MapClaims{"user_id": "user-1", "role": "user"}, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, serve("Bearer "+token))
+	assert.Equal(t, "user-1", userID)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("Bearer not-a-token"), "invalid tokens are not treated as anonymous")
+}
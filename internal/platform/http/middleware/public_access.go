@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RoutePolicy opens a read endpoint to anonymous callers, those without a
+// valid bearer token, such as the scripts of the marketing site
+type RoutePolicy struct {
+	Method string
+	// Pattern is the route below /api/v1 as it is registered, e.g.
+	// /movies/{id}
+	Pattern string
+	// Fields are the JSON fields anonymous callers get, dotted for the
+	// fields of nested objects and of the items of lists, e.g. movies.title.
+	// Empty keeps every field.
+	Fields []string
+}
+
+// publicRoute is a RoutePolicy ready to prune with
+type publicRoute struct {
+	fields fieldTree
+}
+
+// fieldTree holds the fields kept at one level of a JSON document; a nil
+// subtree keeps the whole value
+type fieldTree map[string]fieldTree
+
+// PublicAccess serves anonymous requests to the routes of policies under
+// the limiter's per client IP budget, answering 429 with a Retry-After
+// header once it is used up, and strips their JSON responses down to the
+// policy's fields. Callers with a valid bearer token, and internal callers,
+// are not affected, nor are anonymous requests to other routes, which the
+// handlers authenticate as before.
+func PublicAccess(policies []RoutePolicy, limiter *ratelimit.Limiter, writer *response.Writer, keys *tokens.Keys) func(http.Handler) http.Handler {
+	routes := make(map[string]*publicRoute, len(policies))
+	for _, policy := range policies {
+		routes[policy.Method+" "+strings.TrimSuffix(policy.Pattern, "/")] = &publicRoute{
+			fields: newFieldTree(policy.Fields),
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, ok := matchPublicRoute(routes, r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			// The response depends on who asks
+			w.Header().Add("Vary", "Authorization")
+			if !isAnonymous(r, keys) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := limiter.Allow(ClientIP(r))
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				writer.WriteProblem(w, r, response.NewProblem(http.StatusTooManyRequests, appErrors.CodeTooManyRequests, "Too many requests, sign in or try again later"))
+				return
+			}
+			if route.fields == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := newResponseRecorder()
+			rec.header = w.Header().Clone()
+			next.ServeHTTP(rec, r)
+			body := rec.body.Bytes()
+			if rec.status < http.StatusMultipleChoices && isJSON(rec.header.Get("Content-Type")) {
+				var doc interface{}
+				decoder := json.NewDecoder(bytes.NewReader(body))
+				decoder.UseNumber()
+				if err := decoder.Decode(&doc); err == nil {
+					if pruned, err := json.Marshal(route.fields.prune(doc)); err == nil {
+						body = append(pruned, '\n')
+						// Validators of the full response do not describe this one
+						rec.header.Del("ETag")
+						rec.header.Del("Content-Length")
+					}
+				}
+			}
+
+			for key, values := range rec.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(rec.status)
+			w.Write(body)
+		})
+	}
+}
+
+// isAnonymous reports whether the request has neither a valid bearer token
+// nor an internal caller acting for a user
+func isAnonymous(r *http.Request, keys *tokens.Keys) bool {
+	if principal, ok := PrincipalFrom(r.Context()); ok && principal.Caller != "" {
+		return false
+	}
+	claims, err := bearerClaims(r, keys)
+	return err != nil || claims.UserID == ""
+}
+
+// matchPublicRoute finds the policy of the route the request is about to
+// be routed to. The middleware runs before routing, so it asks the router,
+// which tells static routes such as /movies/random apart from /movies/{id}.
+func matchPublicRoute(routes map[string]*publicRoute, r *http.Request) (*publicRoute, bool) {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil, false
+	}
+	pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	if pattern == "" {
+		return nil, false
+	}
+	// Legacy routes are served at the root
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "/api/v1"), "/")
+
+	route, ok := routes[r.Method+" "+pattern]
+	return route, ok
+}
+
+func newFieldTree(fields []string) fieldTree {
+	if len(fields) == 0 {
+		return nil
+	}
+	tree := fieldTree{}
+	for _, field := range fields {
+		level := tree
+		parts := strings.Split(field, ".")
+		for i, part := range parts {
+			if i == len(parts)-1 {
+				// A field kept whole wins over some of its fields
+				level[part] = nil
+				break
+			}
+			sub, ok := level[part]
+			if ok && sub == nil {
+				break
+			}
+			if !ok {
+				sub = fieldTree{}
+				level[part] = sub
+			}
+			level = sub
+		}
+	}
+	return tree
+}
+
+// prune drops the fields of v the tree does not keep, in v itself and in
+// the items of lists
+func (t fieldTree) prune(v interface{}) interface{} {
+	if t == nil {
+		return v
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		kept := make(map[string]interface{}, len(t))
+		for key, sub := range t {
+			if field, ok := value[key]; ok {
+				kept[key] = sub.prune(field)
+			}
+		}
+		return kept
+	case []interface{}:
+		for i, item := range value {
+			value[i] = t.prune(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAccess(t *testing.T) {
+	const secret = "test-secret"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	writeJSON := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", response.ContentTypeJSON)
+			w.Header().Set("ETag", `"1"`)
+			w.Write([]byte(body))
+		}
+	}
+	movie := `{"id":"m1","title":"Heat","budget":{"amount":60000000},"stats":{"average_score":4.5,"total_ratings":9007199254740993,"score_count":{"5":1}}}`
+
+	setup := func(limit int) *chi.Mux {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		policies := []RoutePolicy{
+			{Method: http.MethodGet, Pattern: "/movies/{id}", Fields: []string{"id", "title", "stats.average_score", "stats.total_ratings"}},
+			{Method: http.MethodGet, Pattern: "/search/movies", Fields: []string{"movies.id", "total"}},
+			{Method: http.MethodGet, Pattern: "/movies/{movieId}/stats"},
+		}
+		router := chi.NewRouter()
+		router.Route("/api/v1", func(v1 chi.Router) {
+			v1.Use(PublicAccess(policies, ratelimit.New(limit, time.Minute), response.NewWriter(logger), tokens.FromSecret(secret)))
+			v1.Route("/movies", func(r chi.Router) {
+				r.Get("/random", writeJSON(`{"movies":[{"id":"m2","title":"Alien"}]}`))
+				r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+					if chi.URLParam(r, "id") == "missing" {
+						response.NewWriter(logger).WriteError(w, "Movie not found", http.StatusNotFound)
+						return
+					}
+					writeJSON(movie)(w, r)
+				})
+			})
+			v1.Get("/movies/{movieId}/stats", writeJSON(`{"average_score":4.5,"score_count":{"5":1}}`))
+			v1.Route("/search/movies", func(r chi.Router) {
+				r.Get("/", writeJSON(`{"movies":[{"id":"m1","title":"Heat"},{"id":"m2","title":"Alien"}],"total":2,"facets":{}}`))
+			})
+		})
+		return router
+	}
+	get := func(router http.Handler, path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("anonymous callers get the policy's fields", func(t *testing.T) {
+		rr := get(setup(10), "/api/v1/movies/m1", "")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"id":"m1","title":"Heat","stats":{"average_score":4.5,"total_ratings":9007199254740993}}`, rr.Body.String())
+		assert.Empty(t, rr.Header().Get("ETag"), "the full response's ETag does not describe this one")
+		assert.Equal(t, "Authorization", rr.Header().Get("Vary"))
+	})
+
+	t.Run("of every item of a list", func(t *testing.T) {
+		rr := get(setup(10), "/api/v1/search/movies", "")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"movies":[{"id":"m1"},{"id":"m2"}],"total":2}`, rr.Body.String())
+	})
+
+	t.Run("a policy without fields keeps them all", func(t *testing.T) {
+		rr := get(setup(10), "/api/v1/movies/m1/stats", "")
+
+		assert.JSONEq(t, `{"average_score":4.5,"score_count":{"5":1}}`, rr.Body.String())
+	})
+
+	t.Run("errors pass through", func(t *testing.T) {
+		rr := get(setup(10), "/api/v1/movies/missing", "")
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Movie not found")
+	})
+
+	t.Run("callers with a token get everything without a limit", func(t *testing.T) {
+		router := setup(1)
+		for range 3 {
+			rr := get(router, "/api/v1/movies/m1", "Bearer "+token)
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.JSONEq(t, movie, rr.Body.String())
+		}
+	})
+
+	t.Run("an invalid token counts as none", func(t *testing.T) {
+		rr := get(setup(10), "/api/v1/movies/m1", "Bearer forged")
+
+		assert.NotContains(t, rr.Body.String(), "budget")
+	})
+
+	t.Run("routes outside the table are not affected", func(t *testing.T) {
+		router := setup(1)
+		for range 3 {
+			rr := get(router, "/api/v1/movies/random", "")
+			require.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), "Alien")
+		}
+	})
+
+	t.Run("anonymous callers are rate limited per IP", func(t *testing.T) {
+		router := setup(2)
+		get(router, "/api/v1/movies/m1", "")
+		get(router, "/api/v1/search/movies", "")
+
+		rr := get(router, "/api/v1/movies/m1", "")
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		var problem map[string]interface{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+		assert.Equal(t, float64(http.StatusTooManyRequests), problem["status"])
+	})
+}
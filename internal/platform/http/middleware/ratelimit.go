@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/ratelimit"
+)
+
+// RateLimitByIP rejects requests from a client IP once it exceeds the
+// limiter's budget with 429 and a Retry-After header. The IP is taken from
+// RemoteAddr, so mount it behind chi's RealIP middleware when the service
+// runs behind a proxy.
+func RateLimitByIP(limiter *ratelimit.Limiter, writer *response.Writer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, retryAfter := limiter.Allow(ClientIP(r))
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				writer.WriteProblem(w, r, response.NewProblem(http.StatusTooManyRequests, appErrors.CodeTooManyRequests, "Too many requests, try again later"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP returns the request's remote IP without the port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
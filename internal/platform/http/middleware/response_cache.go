@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// CacheStatusHeader tells whether a response came from the response
+	// cache: HIT, STALE or MISS
+	CacheStatusHeader = "X-Cache"
+
+	DefaultResponseFreshFor = 30 * time.Second
+	DefaultResponseStaleFor = 5 * time.Minute
+
+	// responseRefreshTimeout bounds a background refresh, which outlives
+	// the request that triggered it
+	responseRefreshTimeout = 30 * time.Second
+)
+
+// cachedResponse is a response as stored in the cache
+type cachedResponse struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// ResponseCache serves GET responses from a shared cache with
+// stale-while-revalidate semantics: a response is served as is while fresh,
+// then for a while longer as stale while a single background request per
+// key refreshes it. Concurrent misses for the same key share one request to
+// the handler, so a sudden spike on one URL reaches the database once.
+type ResponseCache struct {
+	store        cache.Cache
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	freshFor     time.Duration
+	staleFor     time.Duration
+	group        singleflight.Group
+}
+
+// ResponseCacheOption configures optional settings of the response cache
+type ResponseCacheOption func(*ResponseCache)
+
+// WithResponseFreshFor sets how long a response is served without being
+// refreshed
+func WithResponseFreshFor(d time.Duration) ResponseCacheOption {
+	return func(c *ResponseCache) {
+		if d > 0 {
+			c.freshFor = d
+		}
+	}
+}
+
+// WithResponseStaleFor sets how long a response is still served once it is
+// no longer fresh, while it is refreshed in the background; 0 refreshes it
+// before answering
+func WithResponseStaleFor(d time.Duration) ResponseCacheOption {
+	return func(c *ResponseCache) {
+		if d >= 0 {
+			c.staleFor = d
+		}
+	}
+}
+
+func NewResponseCache(store cache.Cache, timeProvider shared.TimeProvider, logger *slog.Logger, opts ...ResponseCacheOption) *ResponseCache {
+	c := &ResponseCache{
+		store:        store,
+		timeProvider: timeProvider,
+		logger:       logger,
+		freshFor:     DefaultResponseFreshFor,
+		staleFor:     DefaultResponseStaleFor,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Handler caches the successful GET responses of next. Responses are kept
+// apart by URL, Accept-Language and content mode, the inputs the cached
+// endpoints vary by.
+func (c *ResponseCache) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := c.key(r)
+		var entry cachedResponse
+		if err := c.store.Get(r.Context(), key, &entry); err == nil {
+			age := c.timeProvider.Now().Sub(entry.StoredAt)
+			if age < c.freshFor {
+				c.write(w, r, &entry, "HIT", age)
+				return
+			}
+			if age < c.freshFor+c.staleFor {
+				c.write(w, r, &entry, "STALE", age)
+				c.refreshInBackground(r, key, next)
+				return
+			}
+		}
+
+		fetched, _, _ := c.group.Do(key, func() (interface{}, error) {
+			return c.fetch(r, key, next), nil
+		})
+		c.write(w, r, fetched.(*cachedResponse), "MISS", 0)
+	})
+}
+
+func (c *ResponseCache) key(r *http.Request) string {
+	return fmt.Sprintf("http_response:%s:%s:%s",
+		users.ContentModeFrom(r.Context()), r.Header.Get("Accept-Language"), r.URL.RequestURI())
+}
+
+// responseTagsKey holds the tags collected for the response being cached
+type responseTagsKey struct{}
+
+type responseTags struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+// TagResponse tags the response being served with cache tags, so
+// InvalidateTags on any of them drops it from the response cache. It does
+// nothing when the response is not being cached.
+func TagResponse(ctx context.Context, tags ...string) {
+	if collected, ok := ctx.Value(responseTagsKey{}).(*responseTags); ok {
+		collected.mu.Lock()
+		collected.tags = append(collected.tags, tags...)
+		collected.mu.Unlock()
+	}
+}
+
+// fetch runs next for r and stores its response when it succeeded, under
+// the tags next gave it with TagResponse
+func (c *ResponseCache) fetch(r *http.Request, key string, next http.Handler) *cachedResponse {
+	rec := newResponseRecorder()
+	collected := &responseTags{}
+	next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), responseTagsKey{}, collected)))
+
+	entry := &cachedResponse{
+		Status:   rec.status,
+		Header:   rec.header,
+		Body:     rec.body.Bytes(),
+		StoredAt: c.timeProvider.Now(),
+	}
+	if entry.Status == http.StatusOK {
+		var err error
+		if len(collected.tags) > 0 {
+			err = c.store.SetWithTags(r.Context(), key, entry, c.freshFor+c.staleFor, collected.tags...)
+		} else {
+			err = c.store.Set(r.Context(), key, entry, c.freshFor+c.staleFor)
+		}
+		if err != nil {
+			c.logger.Warn("Failed to cache response", "error", err, "key", key)
+		}
+	}
+	return entry
+}
+
+// refreshInBackground refetches a stale response unless a refresh of key is
+// already running
+func (c *ResponseCache) refreshInBackground(r *http.Request, key string, next http.Handler) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), responseRefreshTimeout)
+	refresh := r.Clone(ctx)
+	go func() {
+		defer cancel()
+		c.group.Do(key, func() (interface{}, error) {
+			return c.fetch(refresh, key, next), nil
+		})
+	}()
+}
+
+func (c *ResponseCache) write(w http.ResponseWriter, r *http.Request, entry *cachedResponse, status string, age time.Duration) {
+	for name, values := range entry.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+
+	if entry.Status == http.StatusOK {
+		// Responses that depend on the bearer token's profile must not be
+		// shared by proxies
+		visibility := "public"
+		if r.Header.Get("Authorization") != "" {
+			visibility = "private"
+		}
+		maxAge := max(c.freshFor-age, 0)
+		staleFor := min(c.staleFor, c.freshFor+c.staleFor-age)
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d, stale-while-revalidate=%d",
+			visibility, int(maxAge.Seconds()), int(staleFor.Seconds())))
+		w.Header().Set("Age", strconv.Itoa(int(age.Seconds())))
+		w.Header().Set(CacheStatusHeader, status)
+	}
+
+	w.WriteHeader(entry.Status)
+	w.Write(entry.Body)
+}
+
+// responseRecorder buffers a response so it can be stored and replayed
+type responseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
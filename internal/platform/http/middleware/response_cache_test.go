@@ -0,0 +1,260 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapCache keeps values as JSON like the Redis cache does; the embedded
+// interface panics for anything else
+type mapCache struct {
+	cache.Cache
+	mu     sync.Mutex
+	values map[string][]byte
+	tagged map[string][]string
+}
+
+func (c *mapCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.values[key]
+	if !ok {
+		return errors.New("cache miss")
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = data
+	return nil
+}
+
+func (c *mapCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tagged == nil {
+		c.tagged = make(map[string][]string)
+	}
+	for _, tag := range tags {
+		c.tagged[tag] = append(c.tagged[tag], key)
+	}
+	return nil
+}
+
+func (c *mapCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		for _, key := range c.tagged[tag] {
+			delete(c.values, key)
+		}
+		delete(c.tagged, tag)
+	}
+	return nil
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestResponseCache(t *testing.T) {
+	setup := func(handler http.HandlerFunc) (http.Handler, *clock) {
+		now := &clock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		rc := NewResponseCache(&mapCache{values: make(map[string][]byte)}, now, slog.New(slog.NewTextHandler(io.Discard, nil)),
+			WithResponseFreshFor(30*time.Second), WithResponseStaleFor(5*time.Minute))
+		return rc.Handler(handler), now
+	}
+	get := func(h http.Handler, target string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("serves fresh responses from the cache", func(t *testing.T) {
+		var calls atomic.Int32
+		h, now := setup(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"movies":[]}`))
+		})
+
+		first := get(h, "/movies?limit=5")
+		assert.Equal(t, "MISS", first.Header().Get(CacheStatusHeader))
+		assert.Equal(t, "public, max-age=30, stale-while-revalidate=300", first.Header().Get("Cache-Control"))
+
+		now.advance(10 * time.Second)
+		second := get(h, "/movies?limit=5")
+		assert.Equal(t, "HIT", second.Header().Get(CacheStatusHeader))
+		assert.Equal(t, "public, max-age=20, stale-while-revalidate=300", second.Header().Get("Cache-Control"))
+		assert.Equal(t, "10", second.Header().Get("Age"))
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"movies":[]}`, second.Body.String())
+		assert.Equal(t, int32(1), calls.Load())
+
+		get(h, "/movies?limit=10")
+		assert.Equal(t, int32(2), calls.Load(), "other query strings are cached apart")
+	})
+
+	t.Run("drops tagged responses when their tag is invalidated", func(t *testing.T) {
+		store := &mapCache{values: make(map[string][]byte)}
+		now := &clock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+		var calls atomic.Int32
+		h := NewResponseCache(store, now, slog.New(slog.NewTextHandler(io.Discard, nil))).Handler(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				TagResponse(r.Context(), cache.MovieTag("movie-1"))
+				w.Write([]byte(`{}`))
+			}))
+
+		get(h, "/movies/movie-1/stats")
+		assert.Equal(t, "HIT", get(h, "/movies/movie-1/stats").Header().Get(CacheStatusHeader))
+
+		require.NoError(t, store.InvalidateTags(context.Background(), cache.MovieTag("movie-1")))
+		assert.Equal(t, "MISS", get(h, "/movies/movie-1/stats").Header().Get(CacheStatusHeader))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("serves stale responses while refreshing them in the background", func(t *testing.T) {
+		var calls atomic.Int32
+		refreshed := make(chan struct{}, 1)
+		h, now := setup(func(w http.ResponseWriter, r *http.Request) {
+			version := calls.Add(1)
+			if version > 1 {
+				defer func() { refreshed <- struct{}{} }()
+			}
+			fmt.Fprintf(w, `{"version":%d}`, version)
+		})
+
+		get(h, "/movies/movie-1/stats")
+		now.advance(time.Minute)
+		stale := get(h, "/movies/movie-1/stats")
+		assert.Equal(t, "STALE", stale.Header().Get(CacheStatusHeader))
+		assert.Equal(t, "public, max-age=0, stale-while-revalidate=270", stale.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"version":1}`, stale.Body.String())
+
+		select {
+		case <-refreshed:
+		case <-time.After(time.Second):
+			t.Fatal("the stale response was not refreshed")
+		}
+		assert.Eventually(t, func() bool {
+			return get(h, "/movies/movie-1/stats").Header().Get(CacheStatusHeader) == "HIT"
+		}, time.Second, 10*time.Millisecond)
+		assert.JSONEq(t, `{"version":2}`, get(h, "/movies/movie-1/stats").Body.String())
+	})
+
+	t.Run("shares one request between concurrent misses", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		h, _ := setup(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+			w.Write([]byte(`{}`))
+		})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Equal(t, http.StatusOK, get(h, "/movies").Code)
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("does not cache failures", func(t *testing.T) {
+		var calls atomic.Int32
+		h, _ := setup(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		assert.Equal(t, http.StatusServiceUnavailable, get(h, "/movies").Code)
+		w := get(h, "/movies")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Empty(t, w.Header().Get("Cache-Control"))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("keeps languages and content modes apart", func(t *testing.T) {
+		var calls atomic.Int32
+		h, _ := setup(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Write([]byte(`{}`))
+		})
+
+		get(h, "/movies", "Accept-Language", "de")
+		get(h, "/movies", "Accept-Language", "fr")
+		req := httptest.NewRequest(http.MethodGet, "/movies", nil)
+		req.Header.Set("Accept-Language", "de")
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req.WithContext(users.WithContentMode(req.Context(), users.ContentModeKids)))
+
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, "private, max-age=30, stale-while-revalidate=300", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("passes other methods through", func(t *testing.T) {
+		var calls atomic.Int32
+		h, _ := setup(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/movies", nil))
+			require.Equal(t, http.StatusCreated, w.Code)
+			assert.Empty(t, w.Header().Get(CacheStatusHeader))
+		}
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
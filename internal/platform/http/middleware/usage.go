@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"thermondo/internal/domain/usage"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Headers telling a principal where it stands against its monthly quota
+const (
+	QuotaLimitHeader     = "X-Quota-Limit"
+	QuotaRemainingHeader = "X-Quota-Remaining"
+	// QuotaResetHeader is when the quota resets, in Unix seconds
+	QuotaResetHeader = "X-Quota-Reset"
+)
+
+// UsageMeter counts requests per principal and holds them to quotas
+type UsageMeter interface {
+	Allow(ctx context.Context, principal usage.Principal) (*usage.Quota, error)
+	Record(ctx context.Context, principal usage.Principal, endpoint string, bytes int64) error
+}
+
+// PrincipalFunc tells who a request is metered against; ok is false for
+// requests that aren't metered
+type PrincipalFunc func(r *http.Request) (principal usage.Principal, ok bool)
+
+// Metered holds requests to the monthly quota of their principal, answering
+// 429 with a Retry-After header once it is used up, and records the usage
+// of those it serves. Responses carry the X-Quota-* headers when the
+// principal has a quota. Metering errors let requests through, and failures
+// on our side are not recorded.
+func Metered(meter UsageMeter, writer *response.Writer, identify PrincipalFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := identify(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if quota, err := meter.Allow(r.Context(), principal); err == nil && quota != nil {
+				w.Header().Set(QuotaLimitHeader, strconv.FormatInt(quota.Limit, 10))
+				w.Header().Set(QuotaRemainingHeader, strconv.FormatInt(quota.Remaining(), 10))
+				w.Header().Set(QuotaResetHeader, strconv.FormatInt(quota.ResetAt.Unix(), 10))
+				if quota.Exceeded() {
+					retryAfter := time.Until(quota.ResetAt).Seconds()
+					w.Header().Set("Retry-After", strconv.FormatInt(max(int64(retryAfter), 1), 10))
+					writer.WriteProblem(w, r, response.NewProblem(http.StatusTooManyRequests, appErrors.CodeTooManyRequests, "Monthly request quota exceeded"))
+					return
+				}
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			pattern := chi.RouteContext(r.Context()).RoutePattern()
+			if ww.Status() >= http.StatusInternalServerError || pattern == "" {
+				return
+			}
+			_ = meter.Record(context.WithoutCancel(r.Context()), principal, r.Method+" "+pattern, int64(ww.BytesWritten()))
+		})
+	}
+}
+
+// BearerPrincipal meters users by the bearer token they send, or by the
+// user an internal caller acts for. Requests without a valid token are not
+// metered; the routes that need one reject them.
+func BearerPrincipal(keys *tokens.Keys) PrincipalFunc {
+	return func(r *http.Request) (usage.Principal, bool) {
+		if principal, ok := PrincipalFrom(r.Context()); ok && principal.Caller != "" {
+			return usage.Principal{Kind: usage.KindUser, ID: principal.UserID}, true
+		}
+		claims, err := bearerClaims(r, keys)
+		if err != nil || claims.UserID == "" {
+			return usage.Principal{}, false
+		}
+		return usage.Principal{Kind: usage.KindUser, ID: claims.UserID}, true
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/usage"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedUsage struct {
+	principal usage.Principal
+	endpoint  string
+	bytes     int64
+}
+
+// fakeMeter allows up to limit requests per principal
+type fakeMeter struct {
+	limit    int64
+	used     map[usage.Principal]int64
+	recorded []recordedUsage
+}
+
+func (m *fakeMeter) Allow(ctx context.Context, principal usage.Principal) (*usage.Quota, error) {
+	if m.limit == 0 {
+		return nil, nil
+	}
+	m.used[principal]++
+	quota := &usage.Quota{Limit: m.limit, Used: m.used[principal], ResetAt: time.Now().Add(time.Hour)}
+	if quota.Exceeded() {
+		m.used[principal]--
+	}
+	return quota, nil
+}
+
+func (m *fakeMeter) Record(ctx context.Context, principal usage.Principal, endpoint string, bytes int64) error {
+	m.recorded = append(m.recorded, recordedUsage{principal, endpoint, bytes})
+	return nil
+}
+
+func TestMetered(t *testing.T) {
+	const secret = "test-secret"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	setup := func(meter *fakeMeter) *chi.Mux {
+		router := chi.NewRouter()
+		router.Use(Metered(meter, response.NewWriter(slog.New(slog.NewTextHandler(io.Discard, nil))), BearerPrincipal(tokens.FromSecret(secret))))
+		router.Get("/movies/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		})
+		router.Get("/broken", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		return router
+	}
+	request := func(router http.Handler, path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("records what it serves", func(t *testing.T) {
+		meter := &fakeMeter{used: map[usage.Principal]int64{}}
+		router := setup(meter)
+
+		w := request(router, "/movies/movie-1", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(QuotaLimitHeader), "no quota, no headers")
+
+		request(router, "/broken", "Bearer "+token)
+		request(router, "/movies/movie-1", "")
+		request(router, "/movies/movie-1", "Bearer forged")
+
+		req := httptest.NewRequest(http.MethodGet, "/movies/movie-2", nil)
+		req = req.WithContext(WithPrincipal(req.Context(), &Principal{UserID: "user-2", Role: users.RoleUser, Caller: "billing"}))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, []recordedUsage{
+			{usage.Principal{Kind: usage.KindUser, ID: "user-1"}, "GET /movies/{id}", 5},
+			{usage.Principal{Kind: usage.KindUser, ID: "user-2"}, "GET /movies/{id}", 5},
+		}, meter.recorded, "only the signed-in requests that succeeded, and those on behalf of a user")
+	})
+
+	t.Run("enforces the quota", func(t *testing.T) {
+		meter := &fakeMeter{limit: 2, used: map[usage.Principal]int64{}}
+		router := setup(meter)
+
+		w := request(router, "/movies/movie-1", "Bearer "+token)
+		assert.Equal(t, "2", w.Header().Get(QuotaLimitHeader))
+		assert.Equal(t, "1", w.Header().Get(QuotaRemainingHeader))
+		request(router, "/movies/movie-1", "Bearer "+token)
+
+		w = request(router, "/movies/movie-1", "Bearer "+token)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get(QuotaRemainingHeader))
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 3600, retryAfter, 5)
+		assert.Len(t, meter.recorded, 2)
+	})
+}
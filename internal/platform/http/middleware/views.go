@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RecordViewHeader opts a GET /movies/{id} in to counting as a view of the
+// movie by the bearer token's user, saving clients a POST /movies/{id}/view
+const RecordViewHeader = "X-Record-View"
+
+// ViewRecorder notes that a user viewed a movie
+type ViewRecorder interface {
+	Record(ctx context.Context, userID users.UserID, movieID movies.MovieID) error
+}
+
+// RecordViews records the movies served by GET /movies/{id} as viewed when
+// the request opts in with the X-Record-View header and carries a valid
+// bearer token. Recording errors don't affect the response.
+func RecordViews(recorder ViewRecorder, keys *tokens.Keys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			optIn, _ := strconv.ParseBool(r.Header.Get(RecordViewHeader))
+			if r.Method != http.MethodGet || !optIn {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			rctx := chi.RouteContext(r.Context())
+			if ww.Status() != http.StatusOK || rctx == nil || !strings.HasSuffix(rctx.RoutePattern(), "/movies/{id}") {
+				return
+			}
+			claims, err := bearerClaims(r, keys)
+			if err != nil || claims.UserID == "" {
+				return
+			}
+			_ = recorder.Record(context.WithoutCancel(r.Context()), users.UserID(claims.UserID), movies.MovieID(rctx.URLParam("id")))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/tokens"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedView struct {
+	userID  users.UserID
+	movieID movies.MovieID
+}
+
+type fakeViewRecorder struct {
+	recorded []recordedView
+}
+
+func (f *fakeViewRecorder) Record(ctx context.Context, userID users.UserID, movieID movies.MovieID) error {
+	f.recorded = append(f.recorded, recordedView{userID, movieID})
+	return nil
+}
+
+func TestRecordViews(t *testing.T) {
+	const secret = "test-secret"
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	recorder := &fakeViewRecorder{}
+	router := chi.NewRouter()
+	router.Use(RecordViews(recorder, tokens.FromSecret(secret)))
+	router.Route("/movies", func(r chi.Router) {
+		r.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if chi.URLParam(r, "id") == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		r.Get("/{id}/releases", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	send := func(path, optIn string, withToken bool) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if optIn != "" {
+			req.Header.Set(RecordViewHeader, optIn)
+		}
+		if withToken {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send("/movies/movie-1", "true", true)
+	send("/movies/movie-2", "", true)
+	send("/movies/movie-3", "true", false)
+	send("/movies/missing", "true", true)
+	send("/movies/movie-4/releases", "1", true)
+
+	assert.Equal(t, []recordedView{{"user-1", "movie-1"}}, recorder.recorded)
+}
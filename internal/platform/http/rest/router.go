@@ -6,6 +6,8 @@ import (
 	"log/slog"
 
 	"net/http"
+	"thermondo/internal/pkg/http/response"
+	"thermondo/internal/pkg/metrics"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -32,6 +34,16 @@ type Router struct {
 	corsOptions   *cors.Options
 	healthChecker HealthStatusProvider
 	handlers      []HandlerProvider
+	apiMiddleware []func(http.Handler) http.Handler
+	mounts        []mount
+	// legacy serves the /api/v1 handlers at the root too, deprecated
+	legacy *Deprecation
+}
+
+// mount is a group of handlers served under a prefix of their own
+type mount struct {
+	prefix   string
+	handlers []HandlerProvider
 }
 
 // RouterOption defines functional options for router configuration
@@ -51,6 +63,13 @@ func WithHealthProvider(provider HealthStatusProvider) RouterOption {
 	}
 }
 
+// SetHealthProvider sets the health status provider after the router was
+// created, for a provider such as the server that needs the router first.
+// It must be called before the router serves requests.
+func (r *Router) SetHealthProvider(provider HealthStatusProvider) {
+	r.healthChecker = provider
+}
+
 // WithHandlers registers multiple handler providers
 func WithHandlers(handlers ...HandlerProvider) RouterOption {
 	return func(r *Router) {
@@ -58,6 +77,37 @@ func WithHandlers(handlers ...HandlerProvider) RouterOption {
 	}
 }
 
+// WithAPIMiddleware runs middlewares on every /api/v1 route, after the
+// standard stack
+func WithAPIMiddleware(middlewares ...func(http.Handler) http.Handler) RouterOption {
+	return func(r *Router) {
+		r.apiMiddleware = append(r.apiMiddleware, middlewares...)
+	}
+}
+
+// WithMountedHandlers registers handler providers under prefix instead of
+// /api/v1, for APIs versioned apart from the main one
+func WithMountedHandlers(prefix string, handlers ...HandlerProvider) RouterOption {
+	return func(r *Router) {
+		r.mounts = append(r.mounts, mount{prefix: prefix, handlers: handlers})
+	}
+}
+
+// WithLegacyRoutes also serves the /api/v1 handlers without the prefix, as
+// they were before the API was versioned, announcing their deprecation and
+// sunset (zero while undecided) and pointing at their /api/v1 successors
+func WithLegacyRoutes(sunset time.Time) RouterOption {
+	return func(r *Router) {
+		r.legacy = &Deprecation{
+			Since:  legacyDeprecatedAt,
+			Sunset: sunset,
+			Successor: func(path string) string {
+				return "/api/v1" + path
+			},
+		}
+	}
+}
+
 // NewRouter creates a new router with middleware and routes
 func NewRouter(logger *slog.Logger, opts ...RouterOption) *Router {
 	if logger == nil {
@@ -94,6 +144,15 @@ func (r *Router) setupMiddleware() {
 	}
 
 	r.mux.Use(r.loggingMiddleware)
+	r.mux.Use(problemInstance)
+}
+
+// problemInstance lets the errors written without the request still name
+// its path as their problem instance
+func problemInstance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(response.WithInstance(w, req.URL.Path), req)
+	})
 }
 
 // loggingMiddleware provides structured request logging
@@ -123,6 +182,7 @@ func (r *Router) setupRoutes() {
 	// Health endpoints
 	r.mux.Get("/health", r.handleHealth)
 	r.mux.Get("/ready", r.handleReadiness)
+	r.mux.Handle("/metrics", metrics.Handler())
 
 	r.mux.Handle("/swagger/*", http.StripPrefix("/swagger/", http.FileServer(http.Dir("./docs/swagger-ui"))))
 
@@ -138,11 +198,31 @@ func (r *Router) setupRoutes() {
 
 	// API versioning
 	r.mux.Route("/api/v1", func(v1 chi.Router) {
+		v1.Use(apiVersion(1))
+		v1.Use(r.apiMiddleware...)
 		// Register all handler providers
 		for _, handler := range r.handlers {
 			handler.RegisterRoutes(v1)
 		}
 	})
+
+	if r.legacy != nil {
+		r.mux.Group(func(legacy chi.Router) {
+			legacy.Use(apiVersion(1), Deprecate(*r.legacy))
+			legacy.Use(r.apiMiddleware...)
+			for _, handler := range r.handlers {
+				handler.RegisterRoutes(legacy)
+			}
+		})
+	}
+
+	for _, m := range r.mounts {
+		r.mux.Route(m.prefix, func(sub chi.Router) {
+			for _, handler := range m.handlers {
+				handler.RegisterRoutes(sub)
+			}
+		})
+	}
 }
 
 // handleHealth provides a health check endpoint
@@ -179,9 +259,9 @@ func (r *Router) handleReadiness(w http.ResponseWriter, req *http.Request) {
 func DefaultCORSOptions() *cors.Options {
 	return &cors.Options{
 		AllowedOrigins:   []string{"*"}, // Configure based on environment
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-CSRF-Token", "X-Content-Mode", "X-Record-View"},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset", "X-Content-Mode", "API-Version", "Deprecation", "Sunset"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}
@@ -191,9 +271,9 @@ func DefaultCORSOptions() *cors.Options {
 func ProductionCORSOptions(allowedOrigins []string) *cors.Options {
 	return &cors.Options{
 		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "X-CSRF-Token", "X-Content-Mode", "X-Record-View"},
+		ExposedHeaders:   []string{"Link", "ETag", "Retry-After", "X-Quota-Limit", "X-Quota-Remaining", "X-Quota-Reset", "X-Content-Mode", "API-Version", "Deprecation", "Sunset"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The API is versioned in the path. A version only ever gains endpoints and
+// response fields; a breaking change ships under the next /api/vN, served
+// alongside the old one, which is then deprecated with Deprecate until its
+// sunset. Every versioned response names the version that served it in
+// APIVersionHeader, so clients can tell which contract they are getting.
+
+// APIVersionHeader carries the major version of the API that served the
+// response
+const APIVersionHeader = "API-Version"
+
+// legacyDeprecatedAt is when the routes outside /api/v1 were deprecated
+var legacyDeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// Deprecation describes routes that are on their way out
+type Deprecation struct {
+	// Since is when the routes were deprecated
+	Since time.Time
+	// Sunset is when they stop being served; zero while undecided
+	Sunset time.Time
+	// Successor returns the path that replaces a deprecated one, if any
+	Successor func(path string) string
+}
+
+// Deprecate announces d on every response, with the Deprecation (RFC 9745)
+// and Sunset (RFC 8594) headers and a successor-version link
+func Deprecate(d Deprecation) func(http.Handler) http.Handler {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if sunset != "" {
+				w.Header().Set("Sunset", sunset)
+			}
+			if d.Successor != nil {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, d.Successor(r.URL.Path)))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiVersion names version in the APIVersionHeader of every response
+func apiVersion(version int) func(http.Handler) http.Handler {
+	value := strconv.Itoa(version)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
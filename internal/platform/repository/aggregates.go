@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type aggregateRepository struct {
+	db *sqlx.DB
+}
+
+func NewAggregateRepository(db *sqlx.DB) movies.AggregateRepository {
+	return &aggregateRepository{db: db}
+}
+
+// aggregateColumns are the movie columns each aggregate groups by; they are
+// never user input
+var aggregateColumns = map[movies.AggregateKind]string{
+	movies.AggregateDirector: "director",
+	movies.AggregateGenre:    "genre",
+}
+
+// wilsonLowerBound is rating.WilsonLowerBound in SQL, of the average avg of
+// cnt ratings
+func wilsonLowerBound(avg, cnt string) string {
+	p := fmt.Sprintf("LEAST(GREATEST((%s - 1) / 4.0, 0), 1)", avg)
+	n := fmt.Sprintf("(%s)::decimal", cnt)
+	z := strconv.FormatFloat(rating.WilsonZ, 'f', -1, 64)
+	return fmt.Sprintf(`CASE WHEN %[2]s > 0 THEN
+		1 + 4 * (%[1]s + %[3]s^2 / (2 * %[2]s) - %[3]s * SQRT(%[1]s * (1 - %[1]s) / %[2]s + %[3]s^2 / (4 * %[2]s^2)))
+			  / (1 + %[3]s^2 / %[2]s)
+		ELSE 1 END`, p, n, z)
+}
+
+// bayesianAverage is rating.BayesianAverage in SQL, of the average avg of
+// cnt ratings, with the global average global and the decimal prior weight k.
+// With no ratings it is the global average, as long as k is not zero.
+func bayesianAverage(avg, cnt, global, k string) string {
+	return fmt.Sprintf("(%[2]s / (%[2]s + %[4]s)) * %[1]s + (%[4]s / (%[2]s + %[4]s)) * %[3]s", avg, cnt, global, k)
+}
+
+// rankColumn is the column of scoredMovies and of the decades' ranked
+// movies that metric ranks by
+func rankColumn(metric movies.RankingMetric) string {
+	if metric == movies.RankByWilson {
+		return "wilson"
+	}
+	return "bayesian"
+}
+
+// scoredMovies is a CTE of the aggregate's movies with their Bayesian
+// averages, against the global average kept in rating_totals, and their
+// Wilson lower bounds. $1 is the name and $2 the confidence parameter k.
+var scoredMovies = `
+	WITH global AS (
+		SELECT CASE WHEN rating_count > 0 THEN score_sum::decimal / rating_count ELSE 0 END AS avg
+		FROM rating_totals
+	), scored AS (
+		SELECT m.id, m.title, m.release_year, m.%[1]s AS name,
+			   COALESCE(s.cnt, 0) AS cnt,
+			   ` + bayesianAverage("COALESCE(s.avg, 0)", "COALESCE(s.cnt, 0)", "COALESCE(global.avg, 0)", "$2::decimal") + ` AS bayesian,
+			   ` + wilsonLowerBound("COALESCE(s.avg, 0)", "COALESCE(s.cnt, 0)") + ` AS wilson
+		FROM movies m
+		LEFT JOIN global ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT AVG(r.score) AS avg, COUNT(*) AS cnt
+			FROM ratings r WHERE r.movie_id = m.id AND %[2]s
+		) s ON TRUE
+		WHERE LOWER(m.%[1]s) = LOWER($1)
+	)`
+
+func (a *aggregateRepository) GetAggregateStats(ctx context.Context, kind movies.AggregateKind, name string, ranking movies.Ranking) (*movies.AggregateStats, error) {
+	column, ok := aggregateColumns[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregate kind %q", kind)
+	}
+	scored := fmt.Sprintf(scoredMovies, column, visibleRating("r"))
+
+	stats := &movies.AggregateStats{Kind: kind, ScoreDistribution: make(map[int]int64)}
+	err := a.db.QueryRowContext(ctx, scored+`
+		SELECT COALESCE(MODE() WITHIN GROUP (ORDER BY name), ''), COUNT(*),
+			   COUNT(*) FILTER (WHERE cnt > 0), COALESCE(SUM(cnt), 0),
+			   COALESCE(ROUND(AVG(bayesian), 2), 0)
+		FROM scored`, name, ranking.ConfidenceK,
+	).Scan(&stats.Name, &stats.MovieCount, &stats.RatedMovies, &stats.TotalRatings, &stats.AverageBayesian)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s stats: %w", kind, err)
+	}
+	if stats.MovieCount == 0 {
+		return nil, fmt.Errorf("%s %s not found", kind, name)
+	}
+
+	if stats.RatedMovies > 0 {
+		if stats.BestRated, stats.WorstRated, err = a.extremes(ctx, scored, kind, name, ranking); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT r.score, COUNT(*)
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE LOWER(m.%s) = LOWER($1) AND %s
+		GROUP BY r.score`, column, visibleRating("r")), name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s score distribution: %w", kind, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var score int
+		var count int64
+		if err := rows.Scan(&score, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan score count: %w", err)
+		}
+		stats.ScoreDistribution[score] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating score counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// extremes returns the rated movies ranked highest and lowest; ties go to
+// the movie with more ratings
+func (a *aggregateRepository) extremes(ctx context.Context, scored string, kind movies.AggregateKind, name string, ranking movies.Ranking) (*movies.RatedTitle, *movies.RatedTitle, error) {
+	pick := func(order string) (*movies.RatedTitle, error) {
+		title := &movies.RatedTitle{}
+		var id string
+		err := a.db.QueryRowContext(ctx, scored+`
+			SELECT id, title, release_year, ROUND(bayesian, 2), ROUND(wilson, 2), cnt
+			FROM scored
+			WHERE cnt > 0
+			ORDER BY `+rankColumn(ranking.Metric)+` `+order+`, cnt DESC, id
+			LIMIT 1`, name, ranking.ConfidenceK,
+		).Scan(&id, &title.Title, &title.ReleaseYear, &title.BayesianAverage, &title.WilsonLowerBound, &title.TotalRatings)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get %s extremes: %w", kind, err)
+		}
+		title.MovieID = movies.MovieID(strings.TrimSpace(id))
+		return title, nil
+	}
+
+	best, err := pick("DESC")
+	if err != nil {
+		return nil, nil, err
+	}
+	worst, err := pick("ASC")
+	if err != nil {
+		return nil, nil, err
+	}
+	return best, worst, nil
+}
+
+// decadeOf is the decade of a movie; it must match idx_movies_decade
+const decadeOf = "(m.release_year / 10) * 10"
+
+func (a *aggregateRepository) GetDecades(ctx context.Context, ranking movies.Ranking, top int) ([]*movies.DecadeSummary, error) {
+	var counts []struct {
+		Decade int   `db:"decade"`
+		Count  int64 `db:"count"`
+	}
+	err := a.db.SelectContext(ctx, &counts, `
+		SELECT `+decadeOf+` AS decade, COUNT(*) AS count
+		FROM movies m
+		GROUP BY `+decadeOf+`
+		ORDER BY decade`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count movies per decade: %w", err)
+	}
+
+	decades := make([]*movies.DecadeSummary, len(counts))
+	byDecade := make(map[int]*movies.DecadeSummary, len(counts))
+	for i, c := range counts {
+		decades[i] = &movies.DecadeSummary{Decade: c.Decade, MovieCount: c.Count, TopRated: []*movies.RatedTitle{}}
+		byDecade[c.Decade] = decades[i]
+	}
+	if top <= 0 || len(decades) == 0 {
+		return decades, nil
+	}
+
+	// Only rated movies are ranked, so the ratings are totalled first
+	rows, err := a.db.QueryContext(ctx, fmt.Sprintf(`
+		WITH global AS (
+			SELECT CASE WHEN rating_count > 0 THEN score_sum::decimal / rating_count ELSE 0 END AS avg
+			FROM rating_totals
+		), totals AS (
+			SELECT r.movie_id, AVG(r.score) AS avg, COUNT(*) AS cnt
+			FROM ratings r
+			WHERE %s
+			GROUP BY r.movie_id
+		), ranked AS (
+			SELECT m.id, m.title, m.release_year, %s AS decade, t.cnt,
+				   %s AS bayesian,
+				   %s AS wilson
+			FROM totals t
+			JOIN movies m ON m.id = t.movie_id
+			LEFT JOIN global ON TRUE
+		), numbered AS (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY decade ORDER BY %s DESC, cnt DESC, id) AS rank
+			FROM ranked
+		)
+		SELECT decade, id, title, release_year, ROUND(bayesian, 2), ROUND(wilson, 2), cnt
+		FROM numbered
+		WHERE rank <= $2
+		ORDER BY decade, rank`, visibleRating("r"), decadeOf,
+		bayesianAverage("t.avg", "t.cnt", "COALESCE(global.avg, 0)", "$1::decimal"), wilsonLowerBound("t.avg", "t.cnt"), rankColumn(ranking.Metric)), ranking.ConfidenceK, top)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top movies per decade: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var decade int
+		var id string
+		title := &movies.RatedTitle{}
+		if err := rows.Scan(&decade, &id, &title.Title, &title.ReleaseYear, &title.BayesianAverage, &title.WilsonLowerBound, &title.TotalRatings); err != nil {
+			return nil, fmt.Errorf("failed to scan top movie: %w", err)
+		}
+		title.MovieID = movies.MovieID(strings.TrimSpace(id))
+		if summary, ok := byDecade[decade]; ok {
+			summary.TopRated = append(summary.TopRated, title)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top movies: %w", err)
+	}
+	return decades, nil
+}
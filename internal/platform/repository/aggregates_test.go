@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateRepository(t *testing.T) {
+	db := setupTestDB(t, "ratings", "movies", "users")
+	defer db.Close()
+
+	for _, m := range []struct{ id, title, genre, director string }{
+		{"test-id-aggregate-1", "Lady Bird", "Drama", "Greta Gerwig"},
+		{"test-id-aggregate-2", "Little Women", "Drama", "Greta Gerwig"},
+		{"test-id-aggregate-3", "Barbie", "Comedy", "greta gerwig"},
+		{"test-id-aggregate-4", "Heat", "Crime", "Michael Mann"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 2019, $3, $4, 120, 'PG-13', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title, m.genre, m.director)
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at) VALUES
+			('user-id-aggregate-1', 'aggregate1@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW()),
+			('user-id-aggregate-2', 'aggregate2@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	// The global average is (5 + 5 + 2 + 3) / 4 = 3.75
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-id-aggregate-1', 'user-id-aggregate-1', 'test-id-aggregate-1', 5, NOW(), NOW()),
+			('rating-id-aggregate-2', 'user-id-aggregate-2', 'test-id-aggregate-1', 5, NOW(), NOW()),
+			('rating-id-aggregate-3', 'user-id-aggregate-1', 'test-id-aggregate-2', 2, NOW(), NOW()),
+			('rating-id-aggregate-4', 'user-id-aggregate-1', 'test-id-aggregate-4', 3, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	repo := NewAggregateRepository(db)
+	ctx := context.Background()
+	ranking := movies.Ranking{Metric: movies.RankByBayesian, ConfidenceK: 2}
+
+	t.Run("director", func(t *testing.T) {
+		stats, err := repo.GetAggregateStats(ctx, movies.AggregateDirector, "GRETA GERWIG", ranking)
+		require.NoError(t, err)
+		assert.Equal(t, "Greta Gerwig", stats.Name)
+		assert.Equal(t, int64(3), stats.MovieCount)
+		assert.Equal(t, int64(2), stats.RatedMovies)
+		assert.Equal(t, int64(3), stats.TotalRatings)
+		// (4.375 + 3.1667 + 3.75) / 3, the unrated movie counting as the
+		// global average
+		assert.InDelta(t, 3.76, stats.AverageBayesian, 0.001)
+		require.NotNil(t, stats.BestRated)
+		assert.Equal(t, movies.MovieID("test-id-aggregate-1"), stats.BestRated.MovieID)
+		assert.InDelta(t, 4.38, stats.BestRated.BayesianAverage, 0.001)
+		require.NotNil(t, stats.WorstRated)
+		assert.Equal(t, movies.MovieID("test-id-aggregate-2"), stats.WorstRated.MovieID)
+		assert.Equal(t, map[int]int64{5: 2, 2: 1}, stats.ScoreDistribution)
+	})
+
+	t.Run("ranked by Wilson lower bound", func(t *testing.T) {
+		stats, err := repo.GetAggregateStats(ctx, movies.AggregateDirector, "Greta Gerwig", movies.Ranking{Metric: movies.RankByWilson, ConfidenceK: 2})
+		require.NoError(t, err)
+		require.NotNil(t, stats.BestRated)
+		assert.Equal(t, movies.MovieID("test-id-aggregate-1"), stats.BestRated.MovieID)
+		assert.InDelta(t, rating.WilsonLowerBound(5, 2), stats.BestRated.WilsonLowerBound, 0.005)
+		require.NotNil(t, stats.WorstRated)
+		assert.InDelta(t, rating.WilsonLowerBound(2, 1), stats.WorstRated.WilsonLowerBound, 0.005)
+	})
+
+	t.Run("genre without ratings", func(t *testing.T) {
+		stats, err := repo.GetAggregateStats(ctx, movies.AggregateGenre, "comedy", ranking)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.MovieCount)
+		assert.Nil(t, stats.BestRated)
+		assert.Nil(t, stats.WorstRated)
+		assert.Empty(t, stats.ScoreDistribution)
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		_, err := repo.GetAggregateStats(ctx, movies.AggregateGenre, "Western", ranking)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+func TestAggregateRepository_Decades(t *testing.T) {
+	db := setupTestDB(t, "ratings", "movies", "users")
+	defer db.Close()
+
+	for _, m := range []struct {
+		id, title string
+		year      int
+	}{
+		{"test-id-decade-1", "Heat", 1995},
+		{"test-id-decade-2", "Ronin", 1998},
+		{"test-id-decade-3", "Se7en", 1995},
+		{"test-id-decade-4", "Collateral", 2004},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', $3, 'Crime', 'Director', 120, 'R', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title, m.year)
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at) VALUES
+			('user-id-decade-1', 'decade1@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-id-decade-1', 'user-id-decade-1', 'test-id-decade-1', 5, NOW(), NOW()),
+			('rating-id-decade-2', 'user-id-decade-1', 'test-id-decade-2', 2, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	decades, err := NewAggregateRepository(db).GetDecades(context.Background(), movies.Ranking{Metric: movies.RankByBayesian, ConfidenceK: 2}, 5)
+	require.NoError(t, err)
+	require.Len(t, decades, 2)
+
+	assert.Equal(t, 1990, decades[0].Decade)
+	assert.Equal(t, int64(3), decades[0].MovieCount)
+	// Unrated movies are not ranked
+	require.Len(t, decades[0].TopRated, 2)
+	assert.Equal(t, movies.MovieID("test-id-decade-1"), decades[0].TopRated[0].MovieID)
+	assert.Equal(t, movies.MovieID("test-id-decade-2"), decades[0].TopRated[1].MovieID)
+
+	assert.Equal(t, 2000, decades[1].Decade)
+	assert.Equal(t, int64(1), decades[1].MovieCount)
+	assert.Empty(t, decades[1].TopRated)
+}
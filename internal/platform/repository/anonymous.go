@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/anonymous"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type anonymousRepository struct {
+	db *sqlx.DB
+}
+
+func NewAnonymousRepository(db *sqlx.DB) anonymous.Repository {
+	return &anonymousRepository{db: db}
+}
+
+func (a *anonymousRepository) CreatePrincipal(ctx context.Context, principal *anonymous.Principal) error {
+	query := `INSERT INTO anonymous_principals (id, token_hash, created_at) VALUES ($1, $2, $3)`
+
+	if _, err := a.db.ExecContext(ctx, query, principal.ID, principal.TokenHash, principal.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create anonymous principal: %w", err)
+	}
+	return nil
+}
+
+func (a *anonymousRepository) FindPrincipalByTokenHash(ctx context.Context, hash string) (*anonymous.Principal, error) {
+	query := `SELECT id, token_hash, created_at, claimed_by, claimed_at FROM anonymous_principals WHERE token_hash = $1`
+
+	var (
+		principal anonymous.Principal
+		claimedBy sql.NullString
+	)
+	err := a.db.QueryRowContext(ctx, query, hash).Scan(&principal.ID, &principal.TokenHash, &principal.CreatedAt, &claimedBy, &principal.ClaimedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, anonymous.ErrPrincipalNotFound
+		}
+		return nil, fmt.Errorf("failed to get anonymous principal: %w", err)
+	}
+	if claimedBy.Valid {
+		userID := users.UserID(strings.TrimSpace(claimedBy.String))
+		principal.ClaimedBy = &userID
+	}
+	return &principal, nil
+}
+
+func (a *anonymousRepository) SaveRating(ctx context.Context, r *anonymous.Rating) (*anonymous.Rating, error) {
+	// The principal is checked in the same statement so a device can't keep
+	// rating once its ratings moved to an account
+	query := `
+		INSERT INTO anonymous_ratings (principal_id, movie_id, score, review, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE EXISTS (SELECT 1 FROM anonymous_principals WHERE id = $1 AND claimed_at IS NULL)
+		ON CONFLICT (principal_id, movie_id) DO UPDATE
+		SET score = EXCLUDED.score, review = EXCLUDED.review, updated_at = EXCLUDED.updated_at
+		RETURNING created_at`
+
+	saved := *r
+	err := a.db.QueryRowContext(ctx, query, r.PrincipalID, r.MovieID, r.Score, r.Review, r.CreatedAt, r.UpdatedAt).Scan(&saved.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, anonymous.ErrAlreadyClaimed
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, anonymous.ErrMovieNotFound
+		}
+		return nil, fmt.Errorf("failed to save anonymous rating: %w", err)
+	}
+	return &saved, nil
+}
+
+func (a *anonymousRepository) ListRatings(ctx context.Context, principalID anonymous.PrincipalID) ([]*anonymous.Rating, error) {
+	query := `
+		SELECT principal_id, movie_id, score, review, created_at, updated_at
+		FROM anonymous_ratings
+		WHERE principal_id = $1
+		ORDER BY updated_at DESC, movie_id`
+
+	rows, err := a.db.QueryContext(ctx, query, principalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list anonymous ratings: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := []*anonymous.Rating{}
+	for rows.Next() {
+		var r anonymous.Rating
+		if err := rows.Scan(&r.PrincipalID, &r.MovieID, &r.Score, &r.Review, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan anonymous rating: %w", err)
+		}
+		r.MovieID = movies.MovieID(strings.TrimSpace(string(r.MovieID)))
+		ratings = append(ratings, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anonymous ratings: %w", err)
+	}
+	return ratings, nil
+}
+
+func (a *anonymousRepository) Claim(ctx context.Context, principalID anonymous.PrincipalID, userID users.UserID, candidates []*domainRating.Rating, strategy anonymous.ConflictStrategy, at time.Time) (*anonymous.ClaimResult, error) {
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin claim transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Marking the principal first makes a concurrent claim of the same
+	// device wait, then find it claimed
+	result, err := tx.ExecContext(ctx, `
+		UPDATE anonymous_principals SET claimed_by = $2, claimed_at = $3
+		WHERE id = $1 AND claimed_at IS NULL`, principalID, userID, at)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, users.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to claim anonymous principal: %w", err)
+	}
+	if claimed, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	} else if claimed == 0 {
+		return nil, anonymous.ErrAlreadyClaimed
+	}
+
+	movieIDs := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		movieIDs[i] = string(candidate.MovieID)
+	}
+	var existing []*domainRating.Rating
+	err = tx.SelectContext(ctx, &existing, `
+		SELECT id, user_id, movie_id, score, review, contains_spoilers, created_at, updated_at, version
+		FROM ratings
+		WHERE user_id = $1 AND movie_id = ANY($2) AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE`, userID, pq.Array(movieIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock account ratings: %w", err)
+	}
+	accountRatings := make(map[movies.MovieID]*domainRating.Rating, len(existing))
+	for _, r := range existing {
+		accountRatings[movies.MovieID(strings.TrimSpace(string(r.MovieID)))] = r
+	}
+
+	claim := &anonymous.ClaimResult{PrincipalID: principalID, UserID: userID, Conflicts: []anonymous.Conflict{}, ClaimedAt: at}
+	for _, candidate := range candidates {
+		account, ok := accountRatings[candidate.MovieID]
+		if !ok {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO ratings (id, user_id, movie_id, score, review, contains_spoilers, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				ON CONFLICT (user_id, movie_id) WHERE deleted_at IS NULL DO NOTHING`,
+				candidate.ID, userID, candidate.MovieID, candidate.Score, candidate.Review,
+				candidate.ContainsSpoilers, candidate.CreatedAt, candidate.UpdatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to insert claimed rating: %w", err)
+			}
+			// A rating the user made since the lock keeps its place
+			if inserted, _ := result.RowsAffected(); inserted > 0 {
+				claim.Imported++
+			}
+			continue
+		}
+
+		device := &anonymous.Rating{MovieID: candidate.MovieID, Score: candidate.Score, UpdatedAt: candidate.UpdatedAt}
+		conflict := anonymous.Conflict{MovieID: candidate.MovieID, AccountScore: account.Score, AnonymousScore: candidate.Score, Kept: anonymous.KeptAccount}
+		if strategy.PrefersAnonymous(account, device) {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE ratings
+				SET score = $2, review = $3, contains_spoilers = $4, updated_at = $5, version = version + 1
+				WHERE id = $1`,
+				account.ID, candidate.Score, candidate.Review, candidate.ContainsSpoilers, at)
+			if err != nil {
+				return nil, fmt.Errorf("failed to replace account rating: %w", err)
+			}
+			conflict.Kept = anonymous.KeptAnonymous
+		}
+		claim.Conflicts = append(claim.Conflicts, conflict)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM anonymous_ratings WHERE principal_id = $1`, principalID); err != nil {
+		return nil, fmt.Errorf("failed to delete anonymous ratings: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit claim: %w", err)
+	}
+	return claim, nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/anonymous"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnonymousRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewAnonymousRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-anon', 'anon@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW());
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-anon-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-anon-alien', 'Alien', '', 1979, 'Horror', 'Ridley Scott', 117, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-anon-ronin', 'Ronin', '', 1998, 'Crime', 'John Frankenheimer', 122, 'R', 'English', 'USA', NOW(), NOW());
+		INSERT INTO ratings (id, user_id, movie_id, score, review, created_at, updated_at)
+		VALUES ('test-id-anon-r1', 'user-id-anon', 'test-id-anon-heat', 2, '', NOW(), NOW()),
+			   ('test-id-anon-r2', 'user-id-anon', 'test-id-anon-ronin', 3, '', NOW(), NOW());
+	`)
+	require.NoError(t, err)
+
+	_, hash := anonymous.NewDeviceToken()
+	require.NoError(t, repo.CreatePrincipal(ctx, &anonymous.Principal{ID: "principal-anon", TokenHash: hash, CreatedAt: now}))
+
+	principal, err := repo.FindPrincipalByTokenHash(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, anonymous.PrincipalID("principal-anon"), principal.ID)
+	assert.False(t, principal.Claimed())
+	_, err = repo.FindPrincipalByTokenHash(ctx, anonymous.HashDeviceToken("anon_unknown"))
+	assert.ErrorIs(t, err, anonymous.ErrPrincipalNotFound)
+
+	for _, r := range []*anonymous.Rating{
+		{PrincipalID: "principal-anon", MovieID: "test-id-anon-heat", Score: 3, CreatedAt: now, UpdatedAt: now},
+		{PrincipalID: "principal-anon", MovieID: "test-id-anon-heat", Score: 5, CreatedAt: now.Add(time.Minute), UpdatedAt: now.Add(time.Minute)},
+		{PrincipalID: "principal-anon", MovieID: "test-id-anon-alien", Score: 4, Review: "Still scary", CreatedAt: now, UpdatedAt: now},
+		{PrincipalID: "principal-anon", MovieID: "test-id-anon-ronin", Score: 1, CreatedAt: now, UpdatedAt: now},
+	} {
+		_, err := repo.SaveRating(ctx, r)
+		require.NoError(t, err)
+	}
+	_, err = repo.SaveRating(ctx, &anonymous.Rating{PrincipalID: "principal-anon", MovieID: "test-id-anon-none", Score: 3, CreatedAt: now, UpdatedAt: now})
+	assert.ErrorIs(t, err, anonymous.ErrMovieNotFound)
+
+	ratings, err := repo.ListRatings(ctx, "principal-anon")
+	require.NoError(t, err)
+	require.Len(t, ratings, 3)
+	assert.Equal(t, movies.MovieID("test-id-anon-heat"), ratings[0].MovieID, "rating again replaces the score")
+	assert.Equal(t, 5, ratings[0].Score)
+	assert.True(t, now.Equal(ratings[0].CreatedAt), "and keeps when it was first made")
+
+	candidates := make([]*domainRating.Rating, len(ratings))
+	for i, r := range ratings {
+		candidates[i] = &domainRating.Rating{
+			ID: domainRating.RatingID(fmt.Sprintf("test-id-anon-c%d", i)), UserID: "user-id-anon", MovieID: r.MovieID,
+			Score: r.Score, Review: r.Review, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt, Version: 1,
+		}
+	}
+	_, err = repo.Claim(ctx, "principal-anon", "user-id-missing", candidates, anonymous.KeepNewest, now)
+	assert.ErrorIs(t, err, users.ErrUserNotFound)
+
+	result, err := repo.Claim(ctx, "principal-anon", "user-id-anon", candidates, anonymous.KeepNewest, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	require.Len(t, result.Conflicts, 2)
+
+	kept := make(map[movies.MovieID]anonymous.Kept)
+	for _, conflict := range result.Conflicts {
+		kept[conflict.MovieID] = conflict.Kept
+	}
+	// The account rated both after the device last changed its ratings
+	// except for Heat, which the device rated again a minute later
+	assert.Equal(t, map[movies.MovieID]anonymous.Kept{
+		"test-id-anon-heat":  anonymous.KeptAnonymous,
+		"test-id-anon-ronin": anonymous.KeptAccount,
+	}, kept)
+
+	var scores []int
+	require.NoError(t, db.Select(&scores, `SELECT score FROM ratings WHERE user_id = 'user-id-anon' ORDER BY movie_id`))
+	assert.Equal(t, []int{4, 5, 3}, scores, "alien imported, heat replaced, ronin kept")
+
+	ratings, err = repo.ListRatings(ctx, "principal-anon")
+	require.NoError(t, err)
+	assert.Empty(t, ratings)
+
+	_, err = repo.Claim(ctx, "principal-anon", "user-id-anon", candidates, anonymous.KeepNewest, now.Add(time.Hour))
+	assert.ErrorIs(t, err, anonymous.ErrAlreadyClaimed)
+	_, err = repo.SaveRating(ctx, &anonymous.Rating{PrincipalID: "principal-anon", MovieID: "test-id-anon-heat", Score: 3, CreatedAt: now, UpdatedAt: now})
+	assert.ErrorIs(t, err, anonymous.ErrAlreadyClaimed)
+
+	principal, err = repo.FindPrincipalByTokenHash(ctx, hash)
+	require.NoError(t, err)
+	require.True(t, principal.Claimed())
+	assert.Equal(t, users.UserID("user-id-anon"), *principal.ClaimedBy)
+}
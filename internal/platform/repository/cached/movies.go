@@ -0,0 +1,53 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/cache"
+	"time"
+)
+
+type movieRepository struct {
+	movies.Repository
+	notFound *notFound
+}
+
+// NewMovieRepository remembers the movie IDs that GetByID did not find for
+// ttl. Saving a movie clears what was remembered about its ID.
+func NewMovieRepository(next movies.Repository, c cache.Cache, ttl time.Duration, logger *slog.Logger) movies.Repository {
+	return &movieRepository{Repository: next, notFound: newNotFound(c, ttl, logger, "movie")}
+}
+
+func (r *movieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	if r.notFound.known(ctx, string(id)) {
+		return nil, fmt.Errorf("movie with ID %s not found", id)
+	}
+	movie, err := r.Repository.GetByID(ctx, id)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		r.notFound.remember(ctx, string(id))
+	}
+	return movie, err
+}
+
+func (r *movieRepository) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	if r.notFound.known(ctx, string(id)) {
+		return false, nil
+	}
+	exists, err := r.Repository.Exists(ctx, id)
+	if err == nil && !exists {
+		r.notFound.remember(ctx, string(id))
+	}
+	return exists, err
+}
+
+func (r *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	saved, err := r.Repository.Save(ctx, movie)
+	if err != nil {
+		return nil, err
+	}
+	r.notFound.forget(ctx, string(saved.ID))
+	return saved, nil
+}
@@ -0,0 +1,78 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingMovies counts the lookups that reach the wrapped repository
+type countingMovies struct {
+	movies.Repository
+	lookups int
+}
+
+func (r *countingMovies) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	r.lookups++
+	return r.Repository.GetByID(ctx, id)
+}
+
+func (r *countingMovies) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	r.lookups++
+	return r.Repository.Exists(ctx, id)
+}
+
+func TestMovieRepository(t *testing.T) {
+	ctx := context.Background()
+	setup := func() (movies.Repository, *countingMovies) {
+		next := &countingMovies{Repository: memory.NewMovieRepository(memory.NewStore())}
+		return NewMovieRepository(next, newMapCache(), time.Minute, testLogger), next
+	}
+
+	t.Run("looks a missing movie up once", func(t *testing.T) {
+		repo, next := setup()
+
+		for range 3 {
+			_, err := repo.GetByID(ctx, "missing")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "not found")
+		}
+		exists, err := repo.Exists(ctx, "missing")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		assert.Equal(t, 1, next.lookups)
+	})
+
+	t.Run("finds a movie saved after it was missed", func(t *testing.T) {
+		repo, _ := setup()
+		exists, err := repo.Exists(ctx, "movie-1")
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		_, err = repo.Save(ctx, &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995})
+		require.NoError(t, err)
+
+		movie, err := repo.GetByID(ctx, "movie-1")
+		require.NoError(t, err)
+		assert.Equal(t, "Heat", movie.Title)
+	})
+
+	t.Run("does not remember movies that exist", func(t *testing.T) {
+		repo, next := setup()
+		_, err := repo.Save(ctx, &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995})
+		require.NoError(t, err)
+
+		for range 2 {
+			_, err := repo.GetByID(ctx, "movie-1")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, next.lookups)
+	})
+}
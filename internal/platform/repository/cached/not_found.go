@@ -0,0 +1,47 @@
+// Package cached decorates repositories with caching. Lookups of IDs that do
+// not exist are remembered for a short while, so clients probing unknown IDs
+// over and over reach the database once per TTL instead of on every request.
+package cached
+
+import (
+	"context"
+	"log/slog"
+	"thermondo/internal/pkg/cache"
+	"time"
+)
+
+// notFound remembers the IDs whose lookup found nothing
+type notFound struct {
+	cache  cache.Cache
+	ttl    time.Duration
+	logger *slog.Logger
+	kind   string
+}
+
+func newNotFound(c cache.Cache, ttl time.Duration, logger *slog.Logger, kind string) *notFound {
+	return &notFound{cache: c, ttl: ttl, logger: logger, kind: kind}
+}
+
+// known reports whether a lookup of id recently found nothing. A cache
+// failure counts as not known, so the lookup goes to the repository.
+func (n *notFound) known(ctx context.Context, id string) bool {
+	var missing bool
+	if err := n.cache.Get(ctx, cache.NotFoundKeyFunc(n.kind, id), &missing); err != nil {
+		return false
+	}
+	return missing
+}
+
+// remember records that a lookup of id found nothing
+func (n *notFound) remember(ctx context.Context, id string) {
+	if err := n.cache.Set(ctx, cache.NotFoundKeyFunc(n.kind, id), true, n.ttl); err != nil {
+		n.logger.Warn("Failed to cache missing entity", "error", err, "kind", n.kind, "id", id)
+	}
+}
+
+// forget drops what was recorded about id once it exists
+func (n *notFound) forget(ctx context.Context, id string) {
+	if err := n.cache.Delete(ctx, cache.NotFoundKeyFunc(n.kind, id)); err != nil {
+		n.logger.Warn("Failed to clear cached missing entity", "error", err, "kind", n.kind, "id", id)
+	}
+}
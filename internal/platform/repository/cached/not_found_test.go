@@ -0,0 +1,92 @@
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"thermondo/internal/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapCache keeps values as JSON like the Redis cache does; the embedded
+// interface panics for anything else
+type mapCache struct {
+	cache.Cache
+	mu     sync.Mutex
+	values map[string][]byte
+	failed bool
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string][]byte)}
+}
+
+func (c *mapCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.values[key]
+	if c.failed || !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if c.failed {
+		return errors.New("cache unavailable")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = data
+	return nil
+}
+
+func (c *mapCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("remembers and forgets ids by kind", func(t *testing.T) {
+		c := newMapCache()
+		movies := newNotFound(c, time.Minute, testLogger, "movie")
+		users := newNotFound(c, time.Minute, testLogger, "user")
+
+		assert.False(t, movies.known(ctx, "id-1"))
+		movies.remember(ctx, "id-1")
+		assert.True(t, movies.known(ctx, "id-1"))
+		assert.False(t, users.known(ctx, "id-1"))
+		assert.Contains(t, c.values, "not_found:movie:id-1")
+
+		movies.forget(ctx, "id-1")
+		assert.False(t, movies.known(ctx, "id-1"))
+	})
+
+	t.Run("treats a failing cache as not known", func(t *testing.T) {
+		c := newMapCache()
+		c.failed = true
+		movies := newNotFound(c, time.Minute, testLogger, "movie")
+
+		movies.remember(ctx, "id-1")
+		assert.False(t, movies.known(ctx, "id-1"))
+	})
+}
@@ -0,0 +1,53 @@
+package cached
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	"time"
+)
+
+type ratingRepository struct {
+	rating.Repository
+	notFound *notFound
+}
+
+// NewRatingRepository remembers the rating IDs that GetByID did not find
+// for ttl. Saving or restoring a rating clears what was remembered about
+// its ID.
+func NewRatingRepository(next rating.Repository, c cache.Cache, ttl time.Duration, logger *slog.Logger) rating.Repository {
+	return &ratingRepository{Repository: next, notFound: newNotFound(c, ttl, logger, "rating")}
+}
+
+func (r *ratingRepository) GetByID(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	if r.notFound.known(ctx, string(id)) {
+		return nil, fmt.Errorf("rating with ID %s not found", id)
+	}
+	found, err := r.Repository.GetByID(ctx, id)
+	if err != nil && strings.Contains(err.Error(), "not found") {
+		r.notFound.remember(ctx, string(id))
+	}
+	return found, err
+}
+
+func (r *ratingRepository) Save(ctx context.Context, newRating *rating.Rating) (*rating.Rating, error) {
+	saved, err := r.Repository.Save(ctx, newRating)
+	if err != nil {
+		return nil, err
+	}
+	r.notFound.forget(ctx, string(saved.ID))
+	return saved, nil
+}
+
+// Restore clears the ID as well, as GetByID does not find deleted ratings
+func (r *ratingRepository) Restore(ctx context.Context, id rating.RatingID, deletedSince time.Time) (*rating.Rating, error) {
+	restored, err := r.Repository.Restore(ctx, id, deletedSince)
+	if err != nil {
+		return nil, err
+	}
+	r.notFound.forget(ctx, string(id))
+	return restored, nil
+}
@@ -0,0 +1,74 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRatings counts the lookups that reach the wrapped repository
+type countingRatings struct {
+	rating.Repository
+	lookups int
+}
+
+func (r *countingRatings) GetByID(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	r.lookups++
+	return r.Repository.GetByID(ctx, id)
+}
+
+func TestRatingRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	setup := func() (rating.Repository, *countingRatings) {
+		store := memory.NewStore()
+		_, err := memory.NewUserRepository(store, cache.NewNoOpCache(), testLogger).Create(ctx, &users.User{ID: "user-1", Email: "ada@example.com"})
+		require.NoError(t, err)
+		_, err = memory.NewMovieRepository(store).Save(ctx, &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995})
+		require.NoError(t, err)
+		next := &countingRatings{Repository: memory.NewRatingRepository(store)}
+		return NewRatingRepository(next, newMapCache(), time.Minute, testLogger), next
+	}
+	newRating := &rating.Rating{ID: "rating-1", UserID: "user-1", MovieID: "movie-1", Score: 4, CreatedAt: now, UpdatedAt: now}
+
+	t.Run("finds a rating saved after it was missed", func(t *testing.T) {
+		repo, next := setup()
+		for range 2 {
+			_, err := repo.GetByID(ctx, "rating-1")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "not found")
+		}
+		assert.Equal(t, 1, next.lookups)
+
+		_, err := repo.Save(ctx, newRating)
+		require.NoError(t, err)
+
+		found, err := repo.GetByID(ctx, "rating-1")
+		require.NoError(t, err)
+		assert.Equal(t, 4, found.Score)
+	})
+
+	t.Run("finds a rating restored after it was missed", func(t *testing.T) {
+		repo, _ := setup()
+		_, err := repo.Save(ctx, newRating)
+		require.NoError(t, err)
+		require.NoError(t, repo.Delete(ctx, "rating-1", now))
+		_, err = repo.GetByID(ctx, "rating-1")
+		require.Error(t, err)
+
+		_, err = repo.Restore(ctx, "rating-1", now.Add(-time.Hour))
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, "rating-1")
+		assert.NoError(t, err)
+	})
+}
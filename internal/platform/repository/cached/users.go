@@ -0,0 +1,42 @@
+package cached
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"time"
+)
+
+type userRepository struct {
+	users.UserRepository
+	notFound *notFound
+}
+
+// NewUserRepository remembers the user IDs that FindByID did not find for
+// ttl. Creating a user clears what was remembered about its ID.
+func NewUserRepository(next users.UserRepository, c cache.Cache, ttl time.Duration, logger *slog.Logger) users.UserRepository {
+	return &userRepository{UserRepository: next, notFound: newNotFound(c, ttl, logger, "user")}
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	if r.notFound.known(ctx, string(id)) {
+		return nil, sql.ErrNoRows
+	}
+	user, err := r.UserRepository.FindByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.notFound.remember(ctx, string(id))
+	}
+	return user, err
+}
+
+func (r *userRepository) Create(ctx context.Context, user *users.User) (*users.User, error) {
+	created, err := r.UserRepository.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	r.notFound.forget(ctx, string(created.ID))
+	return created, nil
+}
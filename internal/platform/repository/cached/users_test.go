@@ -0,0 +1,45 @@
+package cached
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUsers counts the lookups that reach the wrapped repository
+type countingUsers struct {
+	users.UserRepository
+	lookups int
+}
+
+func (r *countingUsers) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	r.lookups++
+	return r.UserRepository.FindByID(ctx, id)
+}
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	next := &countingUsers{UserRepository: memory.NewUserRepository(memory.NewStore(), cache.NewNoOpCache(), testLogger)}
+	repo := NewUserRepository(next, newMapCache(), time.Minute, testLogger)
+
+	for range 2 {
+		_, err := repo.FindByID(ctx, "user-1")
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	}
+	assert.Equal(t, 1, next.lookups)
+
+	_, err := repo.Create(ctx, &users.User{ID: "user-1", FirstName: "Ada", Email: "ada@example.com"})
+	require.NoError(t, err)
+
+	user, err := repo.FindByID(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", user.FirstName)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type certificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewCertificationRepository stores certifications in movie_certifications,
+// except the US one which is the movie's rating column
+func NewCertificationRepository(db *sqlx.DB) movies.CertificationRepository {
+	return &certificationRepository{db: db}
+}
+
+func (r *certificationRepository) SaveCertification(ctx context.Context, certification *movies.Certification) (*movies.Certification, error) {
+	saved := *certification
+
+	if certification.Territory == movies.DefaultTerritory {
+		err := r.db.QueryRowContext(ctx,
+			`UPDATE movies SET rating = $2, updated_at = $3 WHERE id = $1 RETURNING updated_at`,
+			certification.MovieID, certification.Certification, certification.UpdatedAt,
+		).Scan(&saved.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("movie with ID %s not found", certification.MovieID)
+			}
+			return nil, fmt.Errorf("failed to save certification: %w", err)
+		}
+		return &saved, nil
+	}
+
+	query := `
+		INSERT INTO movie_certifications (movie_id, territory, certification, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (movie_id, territory) DO UPDATE SET
+			certification = EXCLUDED.certification,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		certification.MovieID, certification.Territory, certification.Certification, certification.UpdatedAt,
+	).Scan(&saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, fmt.Errorf("movie with ID %s not found", certification.MovieID)
+		}
+		return nil, fmt.Errorf("failed to save certification: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (r *certificationRepository) GetCertifications(ctx context.Context, movieID movies.MovieID) ([]*movies.Certification, error) {
+	query := `
+		SELECT movie_id, territory, certification, updated_at FROM (
+			SELECT id AS movie_id, 'US' AS territory, rating AS certification, updated_at
+			FROM movies WHERE id = $1 AND rating IS NOT NULL AND rating <> ''
+			UNION ALL
+			SELECT movie_id, territory, certification, updated_at
+			FROM movie_certifications WHERE movie_id = $1
+		) c
+		ORDER BY territory`
+
+	rows, err := r.db.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query certifications: %w", err)
+	}
+	defer rows.Close()
+
+	certifications := []*movies.Certification{}
+	for rows.Next() {
+		c := &movies.Certification{}
+		if err := rows.Scan(&c.MovieID, &c.Territory, &c.Certification, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan certification: %w", err)
+		}
+		c.MovieID = movies.MovieID(strings.TrimSpace(string(c.MovieID)))
+		c.System, _ = movies.SystemOf(c.Territory)
+		certifications = append(certifications, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating certifications: %w", err)
+	}
+
+	return certifications, nil
+}
+
+func (r *certificationRepository) DeleteCertification(ctx context.Context, movieID movies.MovieID, territory string) error {
+	query := `DELETE FROM movie_certifications WHERE movie_id = $1 AND territory = $2`
+	args := []interface{}{movieID, territory}
+	if territory == movies.DefaultTerritory {
+		query = `UPDATE movies SET rating = '' WHERE id = $1 AND rating <> ''`
+		args = args[:1]
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete certification: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("certification in %s for movie %s not found", territory, movieID)
+	}
+
+	return nil
+}
+
+func (r *certificationRepository) FilterAllowed(ctx context.Context, ids []movies.MovieID, policy movies.ContentPolicy) ([]movies.MovieID, error) {
+	if len(ids) == 0 {
+		return []movies.MovieID{}, nil
+	}
+	plain := make([]string, len(ids))
+	for i, id := range ids {
+		plain[i] = string(id)
+	}
+
+	condition, args := certifiedCondition("m", policy, []interface{}{pq.Array(plain)})
+	query := fmt.Sprintf(`SELECT m.id FROM movies m WHERE m.id = ANY($1) AND %s`, condition)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter movies by certification: %w", err)
+	}
+	defer rows.Close()
+
+	allowed := make(map[movies.MovieID]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan movie id: %w", err)
+		}
+		allowed[movies.MovieID(strings.TrimSpace(id))] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating movie ids: %w", err)
+	}
+
+	filtered := make([]movies.MovieID, 0, len(allowed))
+	for _, id := range ids {
+		if allowed[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
+
+// certifiedCondition renders a condition keeping the movies of alias that
+// policy lets through, appending its arguments to args. The US
+// certification is the movie's rating.
+func certifiedCondition(alias string, policy movies.ContentPolicy, args []interface{}) (string, []interface{}) {
+	if policy.Territory == movies.DefaultTerritory {
+		args = append(args, pq.Array(policy.Certifications))
+		return fmt.Sprintf("%s.rating = ANY($%d)", alias, len(args)), args
+	}
+
+	args = append(args, policy.Territory, pq.Array(policy.Certifications))
+	return fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM movie_certifications c
+			WHERE c.movie_id = %s.id AND c.territory = $%d AND c.certification = ANY($%d)
+		)`, alias, len(args)-1, len(args)), args
+}
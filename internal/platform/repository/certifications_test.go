@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificationRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewCertificationRepository(db)
+	movieRepo := NewMovieRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-cert-paddington', 'Paddington', '', 2014, 'Family', 'Paul King', 95, 'PG', 'English', 'UK', NOW(), NOW()),
+			   ('test-id-cert-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'Restricted', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, c := range []*movies.Certification{
+		{MovieID: "test-id-cert-paddington", Territory: "GB", Certification: "PG"},
+		{MovieID: "test-id-cert-paddington", Territory: "DE", Certification: "0"},
+		{MovieID: "test-id-cert-heat", Territory: "DE", Certification: "16"},
+	} {
+		c.UpdatedAt = now
+		_, err := repo.SaveCertification(ctx, c)
+		require.NoError(t, err)
+	}
+	_, err = repo.SaveCertification(ctx, &movies.Certification{MovieID: "missing", Territory: "DE", Certification: "12", UpdatedAt: now})
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.SaveCertification(ctx, &movies.Certification{MovieID: "missing", Territory: "US", Certification: "PG", UpdatedAt: now})
+	assert.ErrorContains(t, err, "not found")
+
+	certifications, err := repo.GetCertifications(ctx, "test-id-cert-paddington")
+	require.NoError(t, err)
+	require.Len(t, certifications, 3)
+	assert.Equal(t, "US", certifications[2].Territory, "the rating is the US certification")
+	assert.Equal(t, "PG", certifications[2].Certification)
+	assert.Equal(t, movies.SystemMPAA, certifications[2].System)
+
+	// The US certification is written to the rating
+	_, err = repo.SaveCertification(ctx, &movies.Certification{MovieID: "test-id-cert-heat", Territory: "US", Certification: "NC17", UpdatedAt: now})
+	require.NoError(t, err)
+	heat, err := movieRepo.GetByID(ctx, "test-id-cert-heat")
+	require.NoError(t, err)
+	assert.Equal(t, movies.Rating("NC17"), heat.Rating)
+
+	found, total, err := movieRepo.Search(ctx, movies.SearchFilter{CertificationTerritory: "DE", Certifications: []string{"0", "6", "12"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, found, 1)
+	assert.Equal(t, "Paddington", found[0].Title)
+
+	_, total, err = movieRepo.Search(ctx, movies.SearchFilter{CertificationTerritory: "US", Certifications: []string{"G", "PG", "PG13"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	allowed, err := repo.FilterAllowed(ctx, []movies.MovieID{"test-id-cert-heat", "test-id-cert-paddington", "missing"},
+		movies.ContentPolicy{Territory: "DE", Certifications: []string{"0", "6", "12", "16"}})
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-cert-heat", "test-id-cert-paddington"}, allowed, "in the order given")
+	allowed, err = repo.FilterAllowed(ctx, []movies.MovieID{"test-id-cert-heat", "test-id-cert-paddington"},
+		movies.ContentPolicy{Territory: "US", Certifications: []string{"G", "PG"}})
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-cert-paddington"}, allowed)
+
+	require.NoError(t, repo.DeleteCertification(ctx, "test-id-cert-heat", "US"))
+	assert.ErrorContains(t, repo.DeleteCertification(ctx, "test-id-cert-heat", "US"), "not found")
+	require.NoError(t, repo.DeleteCertification(ctx, "test-id-cert-heat", "DE"))
+	certifications, err = repo.GetCertifications(ctx, "test-id-cert-heat")
+	require.NoError(t, err)
+	assert.Empty(t, certifications)
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type collectionRepository struct {
+	db *sqlx.DB
+}
+
+func NewCollectionRepository(db *sqlx.DB) collections.Repository {
+	return &collectionRepository{db: db}
+}
+
+func (c *collectionRepository) Save(ctx context.Context, collection *collections.Collection) (*collections.Collection, error) {
+	query := `
+		INSERT INTO collections (id, name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at`
+
+	saved := *collection
+	err := c.db.QueryRowContext(
+		ctx, query,
+		collection.ID, collection.Name, collection.Description, collection.CreatedAt, collection.UpdatedAt,
+	).Scan(&saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("collection with ID %s already exists", collection.ID)
+		}
+		return nil, fmt.Errorf("failed to save collection: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (c *collectionRepository) GetByID(ctx context.Context, id collections.CollectionID) (*collections.Collection, error) {
+	query := `
+		SELECT id, name, COALESCE(description, ''), created_at, updated_at
+		FROM collections WHERE id = $1`
+
+	collection := &collections.Collection{}
+	var cid string
+	err := c.db.QueryRowContext(ctx, query, id).Scan(
+		&cid, &collection.Name, &collection.Description, &collection.CreatedAt, &collection.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("collection with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	collection.ID = collections.CollectionID(strings.TrimSpace(cid))
+	return collection, nil
+}
+
+func (c *collectionRepository) AddMovie(ctx context.Context, id collections.CollectionID, movieID movies.MovieID, position *int) error {
+	query := `
+		INSERT INTO collection_movies (collection_id, movie_id, position)
+		VALUES ($1, $2, COALESCE($3, (
+			SELECT COALESCE(MAX(position), 0) + 1 FROM collection_movies WHERE collection_id = $1
+		)))
+		ON CONFLICT (collection_id, movie_id) DO UPDATE SET position = EXCLUDED.position`
+
+	_, err := c.db.ExecContext(ctx, query, id, movieID, position)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("collection %s or movie %s not found", id, movieID)
+		}
+		return fmt.Errorf("failed to add movie to collection: %w", err)
+	}
+
+	return nil
+}
+
+func (c *collectionRepository) RemoveMovie(ctx context.Context, id collections.CollectionID, movieID movies.MovieID) error {
+	query := `DELETE FROM collection_movies WHERE collection_id = $1 AND movie_id = $2`
+
+	result, err := c.db.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to remove movie from collection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("movie %s not found in collection %s", movieID, id)
+	}
+
+	return nil
+}
+
+func (c *collectionRepository) GetEntries(ctx context.Context, id collections.CollectionID, confidenceK float64) ([]*collections.Entry, error) {
+	query := `
+		WITH global AS (SELECT COALESCE(AVG(score), 0) AS avg FROM ratings WHERE ` + visibleRating("ratings") + `)
+		SELECT m.id, m.title, m.description, m.release_year, m.genre, m.director,
+			   m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+			   m.imdb_id, m.poster_url, m.created_at, m.updated_at,
+			   cm.position,
+			   COALESCE(s.avg, 0), COALESCE(s.cnt, 0),
+			   ` + bayesianAverage("COALESCE(s.avg, 0)", "COALESCE(s.cnt, 0)", "global.avg", "$2::decimal") + `
+		FROM collection_movies cm
+		JOIN movies m ON m.id = cm.movie_id
+		CROSS JOIN global
+		LEFT JOIN LATERAL (
+			SELECT AVG(r.score) AS avg, COUNT(*) AS cnt
+			FROM ratings r WHERE r.movie_id = m.id AND ` + visibleRating("r") + `
+		) s ON true
+		WHERE cm.collection_id = $1
+		ORDER BY cm.position, cm.added_at`
+
+	rows, err := c.db.QueryContext(ctx, query, id, confidenceK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*collections.Entry
+	for rows.Next() {
+		movie := &movies.Movie{}
+		entry := &collections.Entry{Movie: movie}
+		var movieID string
+		if err := rows.Scan(
+			&movieID, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&entry.Position, &entry.AverageScore, &entry.TotalRatings, &entry.BayesianAverage,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan collection entry: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(movieID))
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collection entries: %w", err)
+	}
+
+	return entries, nil
+}
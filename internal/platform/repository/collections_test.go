@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewCollectionRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`TRUNCATE TABLE collections CASCADE`)
+	require.NoError(t, err)
+
+	for _, m := range []struct{ id, title string }{
+		{"test-id-collection-1", "The Fellowship of the Ring"},
+		{"test-id-collection-2", "The Two Towers"},
+		{"test-id-collection-3", "The Return of the King"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 2001, 'Fantasy', 'Peter Jackson', 178, 'PG-13', 'English', 'New Zealand', NOW(), NOW())
+		`, m.id, m.title)
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	collection, err := repo.Save(ctx, &collections.Collection{ID: "test-id-lotr", Name: "The Lord of the Rings Trilogy", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+
+	// Appended entries take the next position; an explicit position is kept
+	require.NoError(t, repo.AddMovie(ctx, collection.ID, "test-id-collection-2", nil))
+	require.NoError(t, repo.AddMovie(ctx, collection.ID, "test-id-collection-3", nil))
+	first := 1
+	require.NoError(t, repo.AddMovie(ctx, collection.ID, "test-id-collection-1", &first))
+	second := 2
+	require.NoError(t, repo.AddMovie(ctx, collection.ID, "test-id-collection-2", &second))
+
+	entries, err := repo.GetEntries(ctx, collection.ID, 25)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, movies.MovieID("test-id-collection-1"), entries[0].Movie.ID)
+	assert.Equal(t, movies.MovieID("test-id-collection-2"), entries[1].Movie.ID)
+	assert.Equal(t, movies.MovieID("test-id-collection-3"), entries[2].Movie.ID)
+	assert.Equal(t, int64(0), entries[0].TotalRatings)
+
+	require.NoError(t, repo.RemoveMovie(ctx, collection.ID, "test-id-collection-3"))
+	err = repo.RemoveMovie(ctx, collection.ID, "test-id-collection-3")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	err = repo.AddMovie(ctx, collection.ID, "missing", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.GetByID(ctx, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type comparisonRepository struct {
+	db *sqlx.DB
+}
+
+func NewComparisonRepository(db *sqlx.DB) rating.ComparisonRepository {
+	return &comparisonRepository{db: db}
+}
+
+func (c *comparisonRepository) CompareMovies(ctx context.Context, first, second movies.MovieID) (*rating.MovieComparison, error) {
+	histograms, err := c.histograms(ctx, first, second)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range []movies.MovieID{first, second} {
+		if histograms[id] == nil {
+			return nil, fmt.Errorf("movie with ID %s not found", id)
+		}
+	}
+	return &rating.MovieComparison{First: histograms[first], Second: histograms[second]}, nil
+}
+
+func (c *comparisonRepository) GetHeadToHead(ctx context.Context, first, second movies.MovieID) (*rating.HeadToHead, error) {
+	// Pairs up the two ratings of every user who rated both movies
+	query := `
+		SELECT COUNT(*),
+			   COUNT(*) FILTER (WHERE a.score > b.score),
+			   COUNT(*) FILTER (WHERE a.score < b.score),
+			   COUNT(*) FILTER (WHERE a.score = b.score),
+			   COALESCE(ROUND(AVG(a.score - b.score)::decimal, 2), 0)
+		FROM ratings a
+		JOIN ratings b ON b.user_id = a.user_id AND b.movie_id = $2 AND ` + visibleRating("b") + `
+		WHERE a.movie_id = $1 AND ` + visibleRating("a")
+
+	h := &rating.HeadToHead{}
+	err := c.db.QueryRowContext(ctx, query, first, second).
+		Scan(&h.SharedRaters, &h.PreferFirst, &h.PreferSecond, &h.Ties, &h.AverageDifference)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare shared raters: %w", err)
+	}
+	return h, nil
+}
+
+// histograms counts the visible ratings of the movies per score. Movies
+// that do not exist are missing from the result.
+func (c *comparisonRepository) histograms(ctx context.Context, ids ...movies.MovieID) (map[movies.MovieID]*rating.ComparedMovie, error) {
+	query := `
+		SELECT m.id, m.title, r.score, COUNT(r.id)
+		FROM movies m
+		LEFT JOIN ratings r ON r.movie_id = m.id AND ` + visibleRating("r") + `
+		WHERE m.id = ANY($1)
+		GROUP BY m.id, m.title, r.score`
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = string(id)
+	}
+	rows, err := c.db.QueryContext(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating histograms: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[movies.MovieID]*rating.ComparedMovie, len(ids))
+	sums := make(map[movies.MovieID]int64, len(ids))
+	for rows.Next() {
+		var id, title string
+		var score sql.NullInt64
+		var count int64
+		if err := rows.Scan(&id, &title, &score, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan rating histogram: %w", err)
+		}
+		movieID := movies.MovieID(strings.TrimSpace(id))
+		movie, ok := result[movieID]
+		if !ok {
+			movie = &rating.ComparedMovie{MovieID: movieID, Title: title, ScoreCount: make(map[int]int64)}
+			result[movieID] = movie
+		}
+		// A movie without ratings comes back as a single row without score
+		if score.Valid {
+			movie.ScoreCount[int(score.Int64)] = count
+			movie.TotalRatings += count
+			sums[movieID] += score.Int64 * count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating histograms: %w", err)
+	}
+
+	for id, movie := range result {
+		if movie.TotalRatings > 0 {
+			movie.AverageScore = math.Round(float64(sums[id])/float64(movie.TotalRatings)*100) / 100
+		}
+	}
+	return result, nil
+}
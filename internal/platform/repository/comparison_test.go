@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparisonRepository(t *testing.T) {
+	db := setupTestDB(t, "ratings", "movies", "users")
+	defer db.Close()
+
+	for _, m := range []struct{ id, title string }{
+		{"test-id-compare-1", "Heat"},
+		{"test-id-compare-2", "Collateral"},
+		{"test-id-compare-3", "Thief"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 1995, 'Crime', 'Michael Mann', 120, 'R', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title)
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, shadow_banned, created_at, updated_at) VALUES
+			('user-id-compare-1', 'compare1@example.com', 'password123', 'Test', 'User', 'user', true, false, NOW(), NOW()),
+			('user-id-compare-2', 'compare2@example.com', 'password123', 'Test', 'User', 'user', true, false, NOW(), NOW()),
+			('user-id-compare-3', 'compare3@example.com', 'password123', 'Test', 'User', 'user', true, false, NOW(), NOW()),
+			('user-id-compare-4', 'compare4@example.com', 'password123', 'Test', 'User', 'user', true, true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-id-compare-1', 'user-id-compare-1', 'test-id-compare-1', 5, NOW(), NOW()),
+			('rating-id-compare-2', 'user-id-compare-1', 'test-id-compare-2', 3, NOW(), NOW()),
+			('rating-id-compare-3', 'user-id-compare-2', 'test-id-compare-1', 4, NOW(), NOW()),
+			('rating-id-compare-4', 'user-id-compare-2', 'test-id-compare-2', 4, NOW(), NOW()),
+			('rating-id-compare-5', 'user-id-compare-3', 'test-id-compare-1', 2, NOW(), NOW()),
+			('rating-id-compare-6', 'user-id-compare-4', 'test-id-compare-1', 1, NOW(), NOW()),
+			('rating-id-compare-7', 'user-id-compare-4', 'test-id-compare-2', 5, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	repo := NewComparisonRepository(db)
+	ctx := context.Background()
+
+	t.Run("compares visible ratings", func(t *testing.T) {
+		comparison, err := repo.CompareMovies(ctx, "test-id-compare-1", "test-id-compare-2")
+		require.NoError(t, err)
+
+		assert.Equal(t, movies.MovieID("test-id-compare-1"), comparison.First.MovieID)
+		assert.Equal(t, "Heat", comparison.First.Title)
+		assert.Equal(t, int64(3), comparison.First.TotalRatings)
+		assert.Equal(t, 3.67, comparison.First.AverageScore)
+		assert.Equal(t, map[int]int64{5: 1, 4: 1, 2: 1}, comparison.First.ScoreCount)
+		assert.Equal(t, int64(2), comparison.Second.TotalRatings)
+		assert.Equal(t, 3.5, comparison.Second.AverageScore)
+	})
+
+	t.Run("head to head", func(t *testing.T) {
+		h, err := repo.GetHeadToHead(ctx, "test-id-compare-1", "test-id-compare-2")
+		require.NoError(t, err)
+		// The shadow-banned user's pair is left out
+		assert.Equal(t, &rating.HeadToHead{SharedRaters: 2, PreferFirst: 1, Ties: 1, AverageDifference: 1.0}, h)
+
+		h, err = repo.GetHeadToHead(ctx, "test-id-compare-1", "test-id-compare-3")
+		require.NoError(t, err)
+		assert.Equal(t, &rating.HeadToHead{}, h)
+	})
+
+	t.Run("movie without ratings", func(t *testing.T) {
+		comparison, err := repo.CompareMovies(ctx, "test-id-compare-1", "test-id-compare-3")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), comparison.Second.TotalRatings)
+		assert.Empty(t, comparison.Second.ScoreCount)
+	})
+
+	t.Run("unknown movie", func(t *testing.T) {
+		_, err := repo.CompareMovies(ctx, "test-id-compare-1", "test-id-compare-missing")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
@@ -5,19 +5,62 @@ import (
 	"fmt"
 	"strings"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/sorting"
 )
 
-func (r *movieRepository) getSortColumn(sortBy string) string {
-	switch sortBy {
-	case "title":
-		return "title"
-	case "release_year":
-		return "release_year"
-	case "created_at":
-		return "created_at"
-	default:
-		return "created_at"
-	}
+// movieSortColumns maps movies.SortFields onto the columns they order by.
+// Columns are split on commas, so expressions must not contain any.
+var movieSortColumns = map[string]string{
+	"controversy":   movieControversy,
+	"created_at":    "created_at",
+	"director":      "director",
+	"duration_mins": "duration_mins",
+	"genre":         "genre",
+	"release_year":  "release_year",
+	"title":         "title",
+	"updated_at":    "updated_at",
+}
+
+// movieControversy is rating.Controversy of a movie's visible ratings, 0
+// without ratings
+var movieControversy = fmt.Sprintf(`(
+	SELECT CASE WHEN COUNT(*) = 0 THEN 0 ELSE
+		(AVG(cr.score * cr.score * 1.0) - AVG(cr.score * 1.0) * AVG(cr.score * 1.0)) / %.1[1]f
+		* COUNT(*) / (COUNT(*) + %.1[2]f) END
+	FROM ratings cr
+	WHERE cr.movie_id = movies.id AND %[3]s)`,
+	rating.MaxScoreVariance, rating.ControversyPriorVotes, visibleRating("cr"))
+
+// orderBy resolves the sort in opts to an ORDER BY list, created_at by
+// default
+func (r *movieRepository) orderBy(opts movies.SearchOptions) string {
+	return sorting.OrderBy(sorting.Resolve(opts.SortBy, opts.Order, movieSortColumns, "created_at"), movieSortColumns)
+}
+
+// liveRating is a condition that drops soft-deleted ratings. Every query
+// over ratings must include it, directly or through visibleRating; alias
+// names the ratings table in the query.
+func liveRating(alias string) string {
+	return alias + ".deleted_at IS NULL"
+}
+
+// visibleRating is a condition that drops deleted ratings and ratings by
+// shadow-banned users. Every public listing and aggregate over ratings must
+// include it; alias names the ratings table in the query.
+func visibleRating(alias string) string {
+	return fmt.Sprintf("%s AND NOT EXISTS (SELECT 1 FROM users sb WHERE sb.id = %s.user_id AND sb.shadow_banned)", liveRating(alias), alias)
+}
+
+// criticRating is a condition that keeps ratings by verified critics; alias
+// names the ratings table in the query
+func criticRating(alias string) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM users cu WHERE cu.id = %s.user_id AND cu.is_critic)", alias)
+}
+
+// escapeLike escapes LIKE/ILIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 // Helper method for querying multiple movies
@@ -35,7 +78,7 @@ func (r *movieRepository) queryMovies(ctx context.Context, query string, args ..
 		err := rows.Scan(
 			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
 			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
-			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
 			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
 		)
 		if err != nil {
@@ -52,3 +95,40 @@ func (r *movieRepository) queryMovies(ctx context.Context, query string, args ..
 
 	return moviesList, nil
 }
+
+// queryMoviesWithTotal scans movies from a query whose last column is a
+// COUNT(*) OVER() window total
+func (r *movieRepository) queryMoviesWithTotal(ctx context.Context, query string, args ...interface{}) ([]*movies.Movie, int64, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query movies: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		moviesList []*movies.Movie
+		total      int64
+	)
+	for rows.Next() {
+		movie := &movies.Movie{}
+		var id string
+		err := rows.Scan(
+			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan movie: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(id))
+		moviesList = append(moviesList, movie)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating movies: %w", err)
+	}
+
+	return moviesList, total, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"thermondo/internal/domain/users"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type identityRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepository(db *sqlx.DB) users.IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+const identityColumns = `provider, subject, user_id, created_at`
+
+func (i *identityRepository) Create(ctx context.Context, identity *users.Identity) error {
+	query := `INSERT INTO user_identities (provider, subject, user_id, created_at) VALUES ($1, $2, $3, $4)`
+
+	if _, err := i.db.ExecContext(ctx, query, identity.Provider, identity.Subject, identity.UserID, identity.CreatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return users.ErrIdentityExists
+			case "23503":
+				return users.ErrUserNotFound
+			}
+		}
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+	return nil
+}
+
+func (i *identityRepository) Find(ctx context.Context, provider, subject string) (*users.Identity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE provider = $1 AND subject = $2`
+
+	var identity users.Identity
+	if err := i.db.GetContext(ctx, &identity, query, provider, subject); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+	return &identity, nil
+}
+
+func (i *identityRepository) ListByUser(ctx context.Context, userID users.UserID) ([]*users.Identity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE user_id = $1 ORDER BY created_at, provider`
+
+	var identities []*users.Identity
+	if err := i.db.SelectContext(ctx, &identities, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	return identities, nil
+}
+
+func (i *identityRepository) Delete(ctx context.Context, userID users.UserID, provider string) error {
+	result, err := i.db.ExecContext(ctx, `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if n == 0 {
+		return users.ErrIdentityNotFound
+	}
+	return nil
+}
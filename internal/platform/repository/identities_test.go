@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityRepository(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewIdentityRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-identities', 'identities@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	google := &users.Identity{Provider: "google", Subject: "1234567890", UserID: "user-id-identities", CreatedAt: now}
+	apple := &users.Identity{Provider: "apple", Subject: "001234.abcd", UserID: "user-id-identities", CreatedAt: now.Add(time.Minute)}
+	require.NoError(t, repo.Create(ctx, google))
+	require.NoError(t, repo.Create(ctx, apple))
+
+	assert.ErrorIs(t, repo.Create(ctx, &users.Identity{Provider: "google", Subject: "1234567890", UserID: "user-id-identities", CreatedAt: now}), users.ErrIdentityExists)
+	assert.ErrorIs(t, repo.Create(ctx, &users.Identity{Provider: "google", Subject: "other", UserID: "user-id-missing", CreatedAt: now}), users.ErrUserNotFound)
+
+	found, err := repo.Find(ctx, "google", "1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, users.UserID("user-id-identities"), found.UserID)
+	assert.True(t, now.Equal(found.CreatedAt))
+
+	_, err = repo.Find(ctx, "apple", "1234567890")
+	assert.ErrorIs(t, err, users.ErrIdentityNotFound, "subjects are per provider")
+
+	identities, err := repo.ListByUser(ctx, "user-id-identities")
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	assert.Equal(t, "google", identities[0].Provider)
+	assert.Equal(t, "apple", identities[1].Provider)
+
+	require.NoError(t, repo.Delete(ctx, "user-id-identities", "google"))
+	_, err = repo.Find(ctx, "google", "1234567890")
+	assert.ErrorIs(t, err, users.ErrIdentityNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "user-id-identities", "google"), users.ErrIdentityNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "user-id-other", "apple"), users.ErrIdentityNotFound, "only the user's own identities")
+}
@@ -0,0 +1,111 @@
+// Package instrumented decorates repositories with metrics and logging, so
+// every call is measured the same way without the callers or the
+// repositories doing anything. Each decorator implements its whole
+// interface, so a method added to the interface does not compile until it
+// is measured too.
+package instrumented
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"thermondo/internal/pkg/metrics"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var (
+	calls = metrics.NewCounterVec("repository_calls_total",
+		"Repository calls, by repository, method and outcome: ok, not_found or error", "repository", "method", "status")
+	callDuration = metrics.NewHistogramVec("repository_call_duration_seconds",
+		"How long repository calls take, by repository and method", metrics.DefaultDurationBuckets, "repository", "method")
+	rows = metrics.NewCounterVec("repository_rows_total",
+		"Entities returned by repository calls, by repository and method", "repository", "method")
+)
+
+// observer records the calls to one repository
+type observer struct {
+	repository string
+	logger     *slog.Logger
+}
+
+// record measures a call that started at start. Calls are logged at debug
+// level with the ID of the request that made them, if any, so a slow
+// request can be followed through its queries.
+func (o *observer) record(ctx context.Context, method string, start time.Time, n int, err error) {
+	elapsed := time.Since(start)
+	status := statusOf(err)
+	calls.With(o.repository, method, status).Inc()
+	callDuration.With(o.repository, method).Observe(elapsed.Seconds())
+	if n > 0 {
+		rows.With(o.repository, method).Add(int64(n))
+	}
+
+	if !o.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{"repository", o.repository, "method", method, "status", status, "duration", elapsed, "rows", n}
+	if requestID := middleware.GetReqID(ctx); requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	o.logger.DebugContext(ctx, "Repository call", attrs...)
+}
+
+// statusOf tells a missing entity, which the repositories report as
+// sql.ErrNoRows or an error saying "not found", from a failure
+func statusOf(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, sql.ErrNoRows) || strings.Contains(err.Error(), "not found"):
+		return "not_found"
+	default:
+		return "error"
+	}
+}
+
+// count is the number of entities in a result: the length of a list, 1 for
+// a single entity and 0 for anything else
+func count(result any) int {
+	v := reflect.ValueOf(result)
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len()
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return 1
+		}
+	}
+	return 0
+}
+
+// call runs and records a method returning a result
+func call[T any](ctx context.Context, o *observer, method string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	o.record(ctx, method, start, count(result), err)
+	return result, err
+}
+
+// page runs and records a method returning a page and a total
+func page[T any, N any](ctx context.Context, o *observer, method string, fn func() (T, N, error)) (T, N, error) {
+	start := time.Now()
+	result, total, err := fn()
+	o.record(ctx, method, start, count(result), err)
+	return result, total, err
+}
+
+// exec runs and records a method returning only an error
+func exec(ctx context.Context, o *observer, method string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	o.record(ctx, method, start, 0, err)
+	return err
+}
@@ -0,0 +1,73 @@
+package instrumented
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusOf(t *testing.T) {
+	assert.Equal(t, "ok", statusOf(nil))
+	assert.Equal(t, "not_found", statusOf(sql.ErrNoRows))
+	assert.Equal(t, "not_found", statusOf(fmt.Errorf("movie with ID %s not found", "m1")))
+	assert.Equal(t, "error", statusOf(errors.New("connection refused")))
+}
+
+func TestCount(t *testing.T) {
+	assert.Equal(t, 2, count([]*movies.Movie{{}, {}}))
+	assert.Equal(t, 0, count([]movies.MovieID(nil)))
+	assert.Equal(t, 1, count(&movies.Movie{}))
+	assert.Equal(t, 0, count((*movies.Movie)(nil)))
+	assert.Equal(t, 0, count(int64(7)))
+	assert.Equal(t, 0, count(true))
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("counts calls, outcomes and rows", func(t *testing.T) {
+		o := &observer{repository: "test_counts", logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		ctx := context.Background()
+
+		_, _ = call(ctx, o, "List", func() ([]int, error) { return []int{1, 2, 3}, nil })
+		_, _ = call(ctx, o, "List", func() ([]int, error) { return nil, errors.New("timeout") })
+		_ = exec(ctx, o, "Delete", func() error { return sql.ErrNoRows })
+
+		assert.Equal(t, int64(1), calls.With("test_counts", "List", "ok").Value())
+		assert.Equal(t, int64(1), calls.With("test_counts", "List", "error").Value())
+		assert.Equal(t, int64(1), calls.With("test_counts", "Delete", "not_found").Value())
+		assert.Equal(t, int64(3), rows.With("test_counts", "List").Value())
+	})
+
+	t.Run("logs calls at debug level with the request ID", func(t *testing.T) {
+		var logs bytes.Buffer
+		o := &observer{repository: "test_logs", logger: slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+		ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+
+		o.record(ctx, "GetByID", time.Now(), 1, errors.New("timeout"))
+
+		assert.Contains(t, logs.String(), "repository=test_logs")
+		assert.Contains(t, logs.String(), "method=GetByID")
+		assert.Contains(t, logs.String(), "status=error")
+		assert.Contains(t, logs.String(), "request_id=req-1")
+	})
+
+	t.Run("does not log above debug level", func(t *testing.T) {
+		var logs bytes.Buffer
+		o := &observer{repository: "test_quiet", logger: slog.New(slog.NewTextHandler(&logs, nil))}
+
+		o.record(context.Background(), "GetByID", time.Now(), 1, nil)
+
+		assert.Empty(t, logs.String())
+		assert.Equal(t, int64(1), calls.With("test_quiet", "GetByID", "ok").Value())
+	})
+}
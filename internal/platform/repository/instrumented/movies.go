@@ -0,0 +1,106 @@
+package instrumented
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"thermondo/internal/domain/movies"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type movieRepository struct {
+	next movies.Repository
+	o    *observer
+}
+
+var _ movies.Repository = (*movieRepository)(nil)
+
+// NewMovieRepository measures every call to next. GetDB and ScanMovies are
+// passed through unmeasured, as they do not query.
+func NewMovieRepository(next movies.Repository, logger *slog.Logger) movies.Repository {
+	return &movieRepository{next: next, o: &observer{repository: "movies", logger: logger}}
+}
+
+func (r *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	return call(ctx, r.o, "Save", func() (*movies.Movie, error) { return r.next.Save(ctx, movie) })
+}
+
+func (r *movieRepository) Update(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	return call(ctx, r.o, "Update", func() (*movies.Movie, error) { return r.next.Update(ctx, movie) })
+}
+
+func (r *movieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	return call(ctx, r.o, "GetByID", func() (*movies.Movie, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *movieRepository) GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "GetAll", func() ([]*movies.Movie, error) { return r.next.GetAll(ctx, options...) })
+}
+
+func (r *movieRepository) SearchByTitle(ctx context.Context, title string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "SearchByTitle", func() ([]*movies.Movie, error) { return r.next.SearchByTitle(ctx, title, options...) })
+}
+
+func (r *movieRepository) Count(ctx context.Context) (int64, error) {
+	return call(ctx, r.o, "Count", func() (int64, error) { return r.next.Count(ctx) })
+}
+
+func (r *movieRepository) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	return call(ctx, r.o, "Exists", func() (bool, error) { return r.next.Exists(ctx, id) })
+}
+
+func (r *movieRepository) GetByGenre(ctx context.Context, genre string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "GetByGenre", func() ([]*movies.Movie, error) { return r.next.GetByGenre(ctx, genre, options...) })
+}
+
+func (r *movieRepository) GetByDirector(ctx context.Context, director string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "GetByDirector", func() ([]*movies.Movie, error) { return r.next.GetByDirector(ctx, director, options...) })
+}
+
+func (r *movieRepository) GetByYearRange(ctx context.Context, startYear, endYear int, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "GetByYearRange", func() ([]*movies.Movie, error) {
+		return r.next.GetByYearRange(ctx, startYear, endYear, options...)
+	})
+}
+
+func (r *movieRepository) Search(ctx context.Context, filter movies.SearchFilter, options ...movies.SearchOption) ([]*movies.Movie, int64, error) {
+	return page(ctx, r.o, "Search", func() ([]*movies.Movie, int64, error) { return r.next.Search(ctx, filter, options...) })
+}
+
+func (r *movieRepository) CountSearch(ctx context.Context, filter movies.SearchFilter) (int64, error) {
+	return call(ctx, r.o, "CountSearch", func() (int64, error) { return r.next.CountSearch(ctx, filter) })
+}
+
+func (r *movieRepository) GetSearchFacets(ctx context.Context, filter movies.SearchFilter) (*movies.SearchFacets, error) {
+	return call(ctx, r.o, "GetSearchFacets", func() (*movies.SearchFacets, error) { return r.next.GetSearchFacets(ctx, filter) })
+}
+
+func (r *movieRepository) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	return call(ctx, r.o, "Suggest", func() ([]*movies.Suggestion, error) { return r.next.Suggest(ctx, query, limit) })
+}
+
+func (r *movieRepository) Random(ctx context.Context, filter movies.SearchFilter, limit int) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "Random", func() ([]*movies.Movie, error) { return r.next.Random(ctx, filter, limit) })
+}
+
+func (r *movieRepository) FindPotentialDuplicates(ctx context.Context, movie *movies.Movie) ([]*movies.Movie, error) {
+	return call(ctx, r.o, "FindPotentialDuplicates", func() ([]*movies.Movie, error) { return r.next.FindPotentialDuplicates(ctx, movie) })
+}
+
+func (r *movieRepository) GetDB() *sqlx.DB {
+	return r.next.GetDB()
+}
+
+func (r *movieRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	// The rows are read by the caller, so only the query itself is measured
+	start := time.Now()
+	result, err := r.next.QueryContext(ctx, query, args...)
+	r.o.record(ctx, "QueryContext", start, 0, err)
+	return result, err
+}
+
+func (r *movieRepository) ScanMovies(rows *sql.Rows) ([]*movies.Movie, error) {
+	return r.next.ScanMovies(rows)
+}
@@ -0,0 +1,31 @@
+package instrumented
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovieRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMovieRepository(memory.NewMovieRepository(memory.NewStore()), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	before := calls.With("movies", "GetByID", "not_found").Value()
+
+	_, err := repo.Save(ctx, &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995})
+	require.NoError(t, err)
+	movie, err := repo.GetByID(ctx, "movie-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Heat", movie.Title)
+	_, err = repo.GetByID(ctx, "missing")
+	require.Error(t, err)
+
+	assert.Equal(t, before+1, calls.With("movies", "GetByID", "not_found").Value())
+	assert.Positive(t, calls.With("movies", "Save", "ok").Value())
+}
@@ -0,0 +1,108 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+type ratingRepository struct {
+	next rating.Repository
+	o    *observer
+}
+
+var _ rating.Repository = (*ratingRepository)(nil)
+
+// NewRatingRepository measures every call to next
+func NewRatingRepository(next rating.Repository, logger *slog.Logger) rating.Repository {
+	return &ratingRepository{next: next, o: &observer{repository: "ratings", logger: logger}}
+}
+
+func (r *ratingRepository) Save(ctx context.Context, newRating *rating.Rating) (*rating.Rating, error) {
+	return call(ctx, r.o, "Save", func() (*rating.Rating, error) { return r.next.Save(ctx, newRating) })
+}
+
+func (r *ratingRepository) GetByID(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	return call(ctx, r.o, "GetByID", func() (*rating.Rating, error) { return r.next.GetByID(ctx, id) })
+}
+
+func (r *ratingRepository) GetByIDIncludingDeleted(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	return call(ctx, r.o, "GetByIDIncludingDeleted", func() (*rating.Rating, error) { return r.next.GetByIDIncludingDeleted(ctx, id) })
+}
+
+func (r *ratingRepository) GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*rating.Rating, error) {
+	return call(ctx, r.o, "GetByUserAndMovie", func() (*rating.Rating, error) { return r.next.GetByUserAndMovie(ctx, userID, movieID) })
+}
+
+func (r *ratingRepository) GetByUser(ctx context.Context, userID users.UserID, options ...rating.SearchOption) ([]*rating.Rating, error) {
+	return call(ctx, r.o, "GetByUser", func() ([]*rating.Rating, error) { return r.next.GetByUser(ctx, userID, options...) })
+}
+
+func (r *ratingRepository) GetByMovie(ctx context.Context, movieID movies.MovieID, options ...rating.SearchOption) ([]*rating.Rating, error) {
+	return call(ctx, r.o, "GetByMovie", func() ([]*rating.Rating, error) { return r.next.GetByMovie(ctx, movieID, options...) })
+}
+
+func (r *ratingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter rating.UserRatingFilter, options ...rating.SearchOption) ([]*rating.RatingWithMovie, int64, error) {
+	return page(ctx, r.o, "GetUserRatingsWithMovies", func() ([]*rating.RatingWithMovie, int64, error) {
+		return r.next.GetUserRatingsWithMovies(ctx, userID, filter, options...)
+	})
+}
+
+func (r *ratingRepository) Update(ctx context.Context, updated *rating.Rating) (*rating.Rating, error) {
+	return call(ctx, r.o, "Update", func() (*rating.Rating, error) { return r.next.Update(ctx, updated) })
+}
+
+func (r *ratingRepository) Delete(ctx context.Context, id rating.RatingID, deletedAt time.Time) error {
+	return exec(ctx, r.o, "Delete", func() error { return r.next.Delete(ctx, id, deletedAt) })
+}
+
+func (r *ratingRepository) Restore(ctx context.Context, id rating.RatingID, deletedSince time.Time) (*rating.Rating, error) {
+	return call(ctx, r.o, "Restore", func() (*rating.Rating, error) { return r.next.Restore(ctx, id, deletedSince) })
+}
+
+func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*rating.MovieRatingStats, error) {
+	return call(ctx, r.o, "GetMovieStats", func() (*rating.MovieRatingStats, error) { return r.next.GetMovieStats(ctx, movieID) })
+}
+
+func (r *ratingRepository) Exists(ctx context.Context, id rating.RatingID) (bool, error) {
+	return call(ctx, r.o, "Exists", func() (bool, error) { return r.next.Exists(ctx, id) })
+}
+
+func (r *ratingRepository) Count(ctx context.Context) (int64, error) {
+	return call(ctx, r.o, "Count", func() (int64, error) { return r.next.Count(ctx) })
+}
+
+func (r *ratingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	return call(ctx, r.o, "MostRatedMovies", func() ([]movies.MovieID, error) { return r.next.MostRatedMovies(ctx, limit) })
+}
+
+func (r *ratingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	return call(ctx, r.o, "MostActiveRaters", func() ([]users.UserID, error) { return r.next.MostActiveRaters(ctx, limit) })
+}
+
+func (r *ratingRepository) GetGlobalStats(ctx context.Context) (*rating.GlobalStats, error) {
+	return call(ctx, r.o, "GetGlobalStats", func() (*rating.GlobalStats, error) { return r.next.GetGlobalStats(ctx) })
+}
+
+func (r *ratingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*rating.GlobalStats, error) {
+	return call(ctx, r.o, "RefreshGlobalStats", func() (*rating.GlobalStats, error) { return r.next.RefreshGlobalStats(ctx, now) })
+}
+
+func (r *ratingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*rating.RatingActivity, error) {
+	return call(ctx, r.o, "GetRatingActivity", func() (*rating.RatingActivity, error) {
+		return r.next.GetRatingActivity(ctx, movieID, baselineStart, windowStart)
+	})
+}
+
+func (r *ratingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*rating.RaterGroup, error) {
+	return call(ctx, r.o, "GetRaterGroups", func() ([]*rating.RaterGroup, error) { return r.next.GetRaterGroups(ctx, movieID, asOf, config) })
+}
+
+func (r *ratingRepository) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, options ...rating.SearchOption) ([]*rating.ReviewMatch, int64, error) {
+	return page(ctx, r.o, "SearchReviews", func() ([]*rating.ReviewMatch, int64, error) {
+		return r.next.SearchReviews(ctx, query, filter, options...)
+	})
+}
@@ -0,0 +1,39 @@
+package instrumented
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatingRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := memory.NewStore()
+	_, err := memory.NewUserRepository(store, cache.NewNoOpCache(), slog.New(slog.NewTextHandler(io.Discard, nil))).Create(ctx, &users.User{ID: "user-1", Email: "ada@example.com"})
+	require.NoError(t, err)
+	_, err = memory.NewMovieRepository(store).Save(ctx, &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995})
+	require.NoError(t, err)
+	repo := NewRatingRepository(memory.NewRatingRepository(store), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	before := calls.With("ratings", "Delete", "ok").Value()
+
+	_, err = repo.Save(ctx, &rating.Rating{ID: "rating-1", UserID: "user-1", MovieID: "movie-1", Score: 4, CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	top, err := repo.MostRatedMovies(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"movie-1"}, top)
+	require.NoError(t, repo.Delete(ctx, "rating-1", now))
+
+	assert.Equal(t, before+1, calls.With("ratings", "Delete", "ok").Value())
+}
@@ -0,0 +1,60 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+type userRepository struct {
+	next users.UserRepository
+	o    *observer
+}
+
+var _ users.UserRepository = (*userRepository)(nil)
+
+// NewUserRepository measures every call to next
+func NewUserRepository(next users.UserRepository, logger *slog.Logger) users.UserRepository {
+	return &userRepository{next: next, o: &observer{repository: "users", logger: logger}}
+}
+
+func (r *userRepository) Create(ctx context.Context, user *users.User) (*users.User, error) {
+	return call(ctx, r.o, "Create", func() (*users.User, error) { return r.next.Create(ctx, user) })
+}
+
+func (r *userRepository) Update(ctx context.Context, user *users.User) (*users.User, error) {
+	return call(ctx, r.o, "Update", func() (*users.User, error) { return r.next.Update(ctx, user) })
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, id users.UserID, avatarKey *string, updatedAt time.Time) (*string, error) {
+	return call(ctx, r.o, "UpdateAvatar", func() (*string, error) { return r.next.UpdateAvatar(ctx, id, avatarKey, updatedAt) })
+}
+
+func (r *userRepository) SetShadowBanned(ctx context.Context, id users.UserID, banned bool, updatedAt time.Time) error {
+	return exec(ctx, r.o, "SetShadowBanned", func() error { return r.next.SetShadowBanned(ctx, id, banned, updatedAt) })
+}
+
+func (r *userRepository) SetCritic(ctx context.Context, id users.UserID, critic bool, updatedAt time.Time) error {
+	return exec(ctx, r.o, "SetCritic", func() error { return r.next.SetCritic(ctx, id, critic, updatedAt) })
+}
+
+func (r *userRepository) BulkUpdate(ctx context.Context, update users.BulkUpdate) ([]users.BulkResult, error) {
+	return call(ctx, r.o, "BulkUpdate", func() ([]users.BulkResult, error) { return r.next.BulkUpdate(ctx, update) })
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	return call(ctx, r.o, "FindByID", func() (*users.User, error) { return r.next.FindByID(ctx, id) })
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*users.User, error) {
+	return call(ctx, r.o, "FindByEmail", func() (*users.User, error) { return r.next.FindByEmail(ctx, email) })
+}
+
+func (r *userRepository) List(ctx context.Context, filter users.ListFilter, pageNumber, limit int) ([]*users.User, int, error) {
+	return page(ctx, r.o, "List", func() ([]*users.User, int, error) { return r.next.List(ctx, filter, pageNumber, limit) })
+}
+
+func (r *userRepository) Count(ctx context.Context, filter users.ListFilter) (int, error) {
+	return call(ctx, r.o, "Count", func() (int, error) { return r.next.Count(ctx, filter) })
+}
@@ -0,0 +1,33 @@
+package instrumented
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"testing"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/platform/repository/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(memory.NewUserRepository(memory.NewStore(), cache.NewNoOpCache(), slog.New(slog.NewTextHandler(io.Discard, nil))), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	before := rows.With("users", "List").Value()
+
+	_, err := repo.Create(ctx, &users.User{ID: "user-1", Email: "ada@example.com"})
+	require.NoError(t, err)
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows, "errors are passed through unchanged")
+	list, total, err := repo.List(ctx, users.ListFilter{}, 1, 10)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, 1, total)
+
+	assert.Equal(t, before+1, rows.With("users", "List").Value())
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"thermondo/internal/domain/jobs"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type jobRepository struct {
+	db *sqlx.DB
+}
+
+func NewJobRepository(db *sqlx.DB) jobs.Repository {
+	return &jobRepository{db: db}
+}
+
+const jobColumns = `id, type, status, payload, result, progress, error, attempts, max_attempts,
+	cancel_requested, created_by, run_at, created_at, updated_at, started_at, finished_at`
+
+// jsonArg passes JSON as text; lib/pq would send a []byte as bytea
+func jsonArg(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *jobs.Job) error {
+	payload := job.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, status, payload, max_attempts, created_by, run_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8, $9)`,
+		job.ID, job.Type, job.Status, jsonArg(payload), job.MaxAttempts, job.CreatedBy,
+		job.RunAt, job.CreatedAt, job.UpdatedAt,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return jobs.ErrJobExists
+		}
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id jobs.JobID) (*jobs.Job, error) {
+	var job jobs.Job
+	if err := r.db.GetContext(ctx, &job, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, jobs.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Claim(ctx context.Context, types []string, now time.Time) (*jobs.Job, error) {
+	query := `
+		UPDATE jobs SET
+			status = 'running', attempts = attempts + 1,
+			started_at = COALESCE(started_at, $3), updated_at = $3
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'queued' AND run_at <= $2 AND type = ANY($1)
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+
+	var job jobs.Job
+	if err := r.db.GetContext(ctx, &job, query, pq.Array(types), now, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Heartbeat(ctx context.Context, id jobs.JobID, progress float64, result json.RawMessage, now time.Time) (bool, error) {
+	var cancelRequested bool
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE jobs SET progress = $2, result = COALESCE($3::jsonb, result), updated_at = $4
+		WHERE id = $1 AND status = 'running'
+		RETURNING cancel_requested`,
+		id, progress, jsonArg(result), now,
+	).Scan(&cancelRequested)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, jobs.ErrJobNotFound
+		}
+		return false, fmt.Errorf("failed to record job progress: %w", err)
+	}
+	return cancelRequested, nil
+}
+
+func (r *jobRepository) Finish(ctx context.Context, id jobs.JobID, status jobs.Status, result json.RawMessage, errMsg string, now time.Time) error {
+	if !status.Finished() {
+		return fmt.Errorf("cannot finish job with status %q", status)
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET
+			status = $2, result = COALESCE($3::jsonb, result), error = $4,
+			progress = CASE WHEN $2 = 'succeeded' THEN 100 ELSE progress END,
+			finished_at = $5, updated_at = $5
+		WHERE id = $1 AND status = 'running'`,
+		id, status, jsonArg(result), errMsg, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return requireRow(res)
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id jobs.JobID, errMsg string, runAt, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET status = 'queued', error = $2, run_at = $3, updated_at = $4
+		WHERE id = $1 AND status = 'running'`,
+		id, errMsg, runAt, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+	return requireRow(res)
+}
+
+func (r *jobRepository) Cancel(ctx context.Context, id jobs.JobID, now time.Time) (*jobs.Job, error) {
+	query := `
+		UPDATE jobs SET
+			status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN $2 ELSE finished_at END,
+			cancel_requested = true, updated_at = $2
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns
+
+	var job jobs.Job
+	if err := r.db.GetContext(ctx, &job, query, id, now); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to cancel job: %w", err)
+		}
+		// Tell unknown jobs apart from finished ones
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, jobs.ErrJobFinished
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) RequeueStale(ctx context.Context, staleBefore, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET
+			status = CASE
+				WHEN cancel_requested THEN 'cancelled'
+				WHEN attempts < max_attempts THEN 'queued'
+				ELSE 'failed'
+			END,
+			finished_at = CASE WHEN cancel_requested OR attempts >= max_attempts THEN $2 ELSE NULL END,
+			error = 'worker stopped responding',
+			run_at = $2, updated_at = $2
+		WHERE status = 'running' AND updated_at < $1`,
+		staleBefore, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale jobs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// requireRow maps an update that matched no running job to ErrJobNotFound
+func requireRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check updated jobs: %w", err)
+	}
+	if n == 0 {
+		return jobs.ErrJobNotFound
+	}
+	return nil
+}
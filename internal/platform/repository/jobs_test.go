@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/jobs"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJobTestDB(t *testing.T) *sqlx.DB {
+	return setupTestDB(t, "jobs")
+}
+
+func TestJobRepository(t *testing.T) {
+	db := setupJobTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for i, id := range []jobs.JobID{"job-import", "job-later", "job-export"} {
+		job := &jobs.Job{
+			ID: id, Type: "ratings.import", Status: jobs.StatusQueued,
+			Payload: json.RawMessage(`{"key":"imports/a.ndjson"}`), MaxAttempts: 2, CreatedBy: "admin-1",
+			RunAt: now.Add(time.Duration(-3+i) * time.Second), CreatedAt: now, UpdatedAt: now,
+		}
+		switch id {
+		case "job-later":
+			job.RunAt = now.Add(time.Hour)
+		case "job-export":
+			job.Type = "ratings.export"
+		}
+		require.NoError(t, repo.Create(ctx, job))
+	}
+	assert.ErrorIs(t, repo.Create(ctx, &jobs.Job{ID: "job-import", Type: "ratings.import", Status: jobs.StatusQueued}), jobs.ErrJobExists)
+
+	// Only due jobs of the requested types are claimed
+	claimed, err := repo.Claim(ctx, []string{"ratings.import"}, now)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, jobs.JobID("job-import"), claimed.ID)
+	assert.Equal(t, jobs.StatusRunning, claimed.Status)
+	assert.Equal(t, 1, claimed.Attempts)
+	assert.JSONEq(t, `{"key":"imports/a.ndjson"}`, string(claimed.Payload))
+
+	none, err := repo.Claim(ctx, []string{"ratings.import"}, now)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	cancelRequested, err := repo.Heartbeat(ctx, "job-import", 40, json.RawMessage(`{"imported":4}`), now.Add(time.Second))
+	require.NoError(t, err)
+	assert.False(t, cancelRequested)
+
+	// A failed attempt is queued again and claimed once due
+	require.NoError(t, repo.Retry(ctx, "job-import", "connection reset", now.Add(time.Minute), now.Add(time.Second)))
+	job, err := repo.GetByID(ctx, "job-import")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, job.Status)
+	assert.Equal(t, "connection reset", job.Error)
+	assert.JSONEq(t, `{"imported":4}`, string(job.Result))
+
+	claimed, err = repo.Claim(ctx, []string{"ratings.import"}, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 2, claimed.Attempts)
+
+	// Cancelling a running job only asks its worker to stop
+	cancelled, err := repo.Cancel(ctx, "job-import", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusRunning, cancelled.Status)
+	cancelRequested, err = repo.Heartbeat(ctx, "job-import", 50, nil, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, cancelRequested)
+	require.NoError(t, repo.Finish(ctx, "job-import", jobs.StatusCancelled, nil, "", now.Add(time.Minute)))
+
+	_, err = repo.Cancel(ctx, "job-import", now.Add(time.Minute))
+	assert.ErrorIs(t, err, jobs.ErrJobFinished)
+	_, err = repo.Cancel(ctx, "job-missing", now)
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound)
+
+	// Cancelling a queued job takes effect right away
+	cancelled, err = repo.Cancel(ctx, "job-later", now)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.FinishedAt)
+
+	// A job whose worker stopped heartbeating fails once out of attempts
+	claimed, err = repo.Claim(ctx, []string{"ratings.export"}, now)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	_, err = db.Exec(`UPDATE jobs SET max_attempts = 1 WHERE id = 'job-export'`)
+	require.NoError(t, err)
+	n, err := repo.RequeueStale(ctx, now.Add(time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	job, err = repo.GetByID(ctx, "job-export")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Equal(t, "worker stopped responding", job.Error)
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type listRepository struct {
+	db *sqlx.DB
+}
+
+func NewListRepository(db *sqlx.DB) lists.Repository {
+	return &listRepository{db: db}
+}
+
+const listColumns = `
+	l.id, l.user_id, l.name, COALESCE(l.description, ''), l.visibility, l.share_slug,
+	(SELECT COUNT(*) FROM list_movies lm WHERE lm.list_id = l.id),
+	l.created_at, l.updated_at`
+
+func scanList(row interface{ Scan(...any) error }) (*lists.List, error) {
+	list := &lists.List{}
+	var id, userID, visibility string
+	err := row.Scan(
+		&id, &userID, &list.Name, &list.Description, &visibility, &list.ShareSlug,
+		&list.MovieCount, &list.CreatedAt, &list.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	list.ID = lists.ListID(strings.TrimSpace(id))
+	list.UserID = users.UserID(strings.TrimSpace(userID))
+	list.Visibility = lists.Visibility(visibility)
+	return list, nil
+}
+
+func (l *listRepository) Save(ctx context.Context, list *lists.List) (*lists.List, error) {
+	query := `
+		INSERT INTO lists (id, user_id, name, description, visibility, share_slug, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at, updated_at`
+
+	saved := *list
+	err := l.db.QueryRowContext(
+		ctx, query,
+		list.ID, list.UserID, list.Name, list.Description, list.Visibility, list.ShareSlug, list.CreatedAt, list.UpdatedAt,
+	).Scan(&saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code {
+			case "23505":
+				return nil, fmt.Errorf("list with ID %s already exists", list.ID)
+			case "23503":
+				return nil, fmt.Errorf("user %s not found", list.UserID)
+			}
+		}
+		return nil, fmt.Errorf("failed to save list: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (l *listRepository) Update(ctx context.Context, list *lists.List) (*lists.List, error) {
+	query := `
+		UPDATE lists
+		SET name = $2, description = $3, visibility = $4, share_slug = $5, updated_at = $6
+		WHERE id = $1`
+
+	result, err := l.db.ExecContext(ctx, query, list.ID, list.Name, list.Description, list.Visibility, list.ShareSlug, list.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update list: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("list with ID %s not found", list.ID)
+	}
+
+	return l.GetByID(ctx, list.ID)
+}
+
+func (l *listRepository) Delete(ctx context.Context, id lists.ListID) error {
+	result, err := l.db.ExecContext(ctx, `DELETE FROM lists WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete list: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("list with ID %s not found", id)
+	}
+
+	return nil
+}
+
+func (l *listRepository) GetByID(ctx context.Context, id lists.ListID) (*lists.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists l WHERE l.id = $1`
+
+	list, err := scanList(l.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("list with ID %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (l *listRepository) GetBySlug(ctx context.Context, slug string) (*lists.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists l WHERE l.share_slug = $1`
+
+	list, err := scanList(l.db.QueryRowContext(ctx, query, slug))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("list with slug %s not found", slug)
+		}
+		return nil, fmt.Errorf("failed to get list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (l *listRepository) GetByUser(ctx context.Context, userID users.UserID, includePrivate bool) ([]*lists.List, error) {
+	query := `
+		SELECT ` + listColumns + `
+		FROM lists l
+		WHERE l.user_id = $1 AND ($2::boolean OR l.visibility = 'public')
+		ORDER BY l.created_at DESC, l.id`
+
+	rows, err := l.db.QueryContext(ctx, query, userID, includePrivate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user lists: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*lists.List
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan list: %w", err)
+		}
+		result = append(result, list)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lists: %w", err)
+	}
+
+	return result, nil
+}
+
+func (l *listRepository) CountByUser(ctx context.Context, userID users.UserID) (lists.Counts, error) {
+	query := `
+		SELECT COUNT(*) AS total,
+			   COUNT(*) FILTER (WHERE visibility = 'public') AS public,
+			   COUNT(*) FILTER (WHERE visibility = 'private') AS private
+		FROM lists WHERE user_id = $1`
+
+	var counts lists.Counts
+	if err := l.db.GetContext(ctx, &counts, query, userID); err != nil {
+		return lists.Counts{}, fmt.Errorf("failed to count user lists: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (l *listRepository) AddMovie(ctx context.Context, id lists.ListID, movieID movies.MovieID, position *int) error {
+	query := `
+		INSERT INTO list_movies (list_id, movie_id, position)
+		VALUES ($1, $2, COALESCE($3, (
+			SELECT COALESCE(MAX(position), 0) + 1 FROM list_movies WHERE list_id = $1
+		)))
+		ON CONFLICT (list_id, movie_id) DO UPDATE SET position = EXCLUDED.position`
+
+	_, err := l.db.ExecContext(ctx, query, id, movieID, position)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return fmt.Errorf("list %s or movie %s not found", id, movieID)
+		}
+		return fmt.Errorf("failed to add movie to list: %w", err)
+	}
+
+	return nil
+}
+
+func (l *listRepository) RemoveMovie(ctx context.Context, id lists.ListID, movieID movies.MovieID) error {
+	query := `DELETE FROM list_movies WHERE list_id = $1 AND movie_id = $2`
+
+	result, err := l.db.ExecContext(ctx, query, id, movieID)
+	if err != nil {
+		return fmt.Errorf("failed to remove movie from list: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("movie %s not found in list %s", movieID, id)
+	}
+
+	return nil
+}
+
+func (l *listRepository) GetEntries(ctx context.Context, id lists.ListID) ([]*lists.Entry, error) {
+	query := `
+		SELECT m.id, m.title, m.description, m.release_year, m.genre, m.director,
+			   m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+			   m.imdb_id, m.poster_url, m.created_at, m.updated_at,
+			   lm.position, lm.added_at
+		FROM list_movies lm
+		JOIN movies m ON m.id = lm.movie_id
+		WHERE lm.list_id = $1
+		ORDER BY lm.position, lm.added_at`
+
+	rows, err := l.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*lists.Entry
+	for rows.Next() {
+		movie := &movies.Movie{}
+		entry := &lists.Entry{Movie: movie}
+		var movieID string
+		if err := rows.Scan(
+			&movieID, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&entry.Position, &entry.AddedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan list entry: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(movieID))
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating list entries: %w", err)
+	}
+
+	return entries, nil
+}
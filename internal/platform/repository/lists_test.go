@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewListRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-lists', 'lists@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	for _, m := range []struct{ id, title string }{
+		{"test-id-list-1", "Se7en"},
+		{"test-id-list-2", "Zodiac"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 1995, 'Thriller', 'David Fincher', 127, 'R', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title)
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	private, err := repo.Save(ctx, &lists.List{
+		ID: "test-id-list-private", UserID: "user-id-lists", Name: "Watch later",
+		Visibility: lists.VisibilityPrivate, ShareSlug: "privateslug00001", CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, &lists.List{
+		ID: "test-id-list-public", UserID: "user-id-lists", Name: "Best 90s thrillers",
+		Visibility: lists.VisibilityPublic, ShareSlug: "publicslug000001", CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.AddMovie(ctx, private.ID, "test-id-list-2", nil))
+	first := 1
+	require.NoError(t, repo.AddMovie(ctx, private.ID, "test-id-list-1", &first))
+	second := 2
+	require.NoError(t, repo.AddMovie(ctx, private.ID, "test-id-list-2", &second))
+
+	entries, err := repo.GetEntries(ctx, private.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, movies.MovieID("test-id-list-1"), entries[0].Movie.ID)
+	assert.Equal(t, movies.MovieID("test-id-list-2"), entries[1].Movie.ID)
+
+	bySlug, err := repo.GetBySlug(ctx, "privateslug00001")
+	require.NoError(t, err)
+	assert.Equal(t, private.ID, bySlug.ID)
+	assert.Equal(t, 2, bySlug.MovieCount)
+
+	all, err := repo.GetByUser(ctx, "user-id-lists", true)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	public, err := repo.GetByUser(ctx, "user-id-lists", false)
+	require.NoError(t, err)
+	require.Len(t, public, 1)
+	assert.Equal(t, lists.ListID("test-id-list-public"), public[0].ID)
+
+	counts, err := repo.CountByUser(ctx, "user-id-lists")
+	require.NoError(t, err)
+	assert.Equal(t, lists.Counts{Total: 2, Public: 1, Private: 1}, counts)
+
+	private.Name = "Watch this weekend"
+	updated, err := repo.Update(ctx, private)
+	require.NoError(t, err)
+	assert.Equal(t, "Watch this weekend", updated.Name)
+
+	require.NoError(t, repo.RemoveMovie(ctx, private.ID, "test-id-list-1"))
+	err = repo.RemoveMovie(ctx, private.ID, "test-id-list-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	require.NoError(t, repo.Delete(ctx, private.ID))
+	_, err = repo.GetByID(ctx, private.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
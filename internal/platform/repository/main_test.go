@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"thermondo/internal/pkg/testenv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testenv.Main(m))
+}
+
+// setupTestDB returns a connection to a migrated database with the tables
+// given emptied. Whatever the test writes is rolled back when it ends.
+//
+// With TEST_STORAGE=sqlite the database is a fresh SQLite file instead. It
+// only holds movies, ratings and users, so run just their tests that way:
+//
+//	TEST_STORAGE=sqlite go test -run 'Test(Movie|Rating|User)Repository_' .
+func setupTestDB(t *testing.T, tables ...string) *sqlx.DB {
+	if os.Getenv(testenv.StorageEnv) == "sqlite" {
+		return testenv.SQLite(t, SQLiteMigrations())
+	}
+	db := testenv.Postgres(t, Migrations())
+	if len(tables) > 0 {
+		_, err := db.Exec("TRUNCATE TABLE " + strings.Join(tables, ", ") + " CASCADE")
+		require.NoError(t, err)
+	}
+	return db
+}
@@ -0,0 +1,451 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/postgres"
+	"thermondo/internal/pkg/sorting"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type movieRepository struct {
+	store *Store
+}
+
+func NewMovieRepository(store *Store) movies.Repository {
+	return &movieRepository{store: store}
+}
+
+func (m *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.movies[movie.ID]; ok {
+		return nil, fmt.Errorf("movie with ID %s already exists", movie.ID)
+	}
+	m.store.movies[movie.ID] = *movie
+
+	savedMovie := *movie
+	return &savedMovie, nil
+}
+
+func (m *movieRepository) Update(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	stored, ok := m.store.movies[movie.ID]
+	if !ok {
+		return nil, fmt.Errorf("movie with ID %s not found", movie.ID)
+	}
+	updated := *movie
+	updated.CreatedAt = stored.CreatedAt
+	updated.Locale = ""
+	m.store.movies[movie.ID] = updated
+
+	updatedMovie := *movie
+	return &updatedMovie, nil
+}
+
+func (m *movieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	movie, ok := m.store.movies[id]
+	if !ok {
+		return nil, fmt.Errorf("movie with ID %s not found", id)
+	}
+	return &movie, nil
+}
+
+func (m *movieRepository) GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return m.list(func(*movies.Movie) bool { return true }, options), nil
+}
+
+func (m *movieRepository) SearchByTitle(ctx context.Context, title string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return m.list(func(movie *movies.Movie) bool {
+		return containsFold(movie.Title, title)
+	}, options), nil
+}
+
+func (m *movieRepository) GetByGenre(ctx context.Context, genre string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return m.list(func(movie *movies.Movie) bool {
+		return strings.EqualFold(movie.Genre, genre)
+	}, options), nil
+}
+
+func (m *movieRepository) GetByDirector(ctx context.Context, director string, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return m.list(func(movie *movies.Movie) bool {
+		return strings.EqualFold(movie.Director, director)
+	}, options), nil
+}
+
+func (m *movieRepository) GetByYearRange(ctx context.Context, startYear, endYear int, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	return m.list(func(movie *movies.Movie) bool {
+		return movie.ReleaseYear >= startYear && movie.ReleaseYear <= endYear
+	}, options), nil
+}
+
+// Search returns the page of movies matching every criterion in filter along
+// with the total number of matches. The total is 0 when the page is empty,
+// as with the window count of the Postgres repository.
+func (m *movieRepository) Search(ctx context.Context, filter movies.SearchFilter, options ...movies.SearchOption) ([]*movies.Movie, int64, error) {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	matches := m.filter(m.store.matcher(filter))
+	page := paginate(m.store.sortMovies(matches, opts), opts.Limit, opts.Offset)
+	if len(page) == 0 {
+		return nil, 0, nil
+	}
+	return page, int64(len(matches)), nil
+}
+
+// Random shuffles all matches and keeps the first limit
+func (m *movieRepository) Random(ctx context.Context, filter movies.SearchFilter, limit int) ([]*movies.Movie, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	matches := m.filter(m.store.matcher(filter))
+	rand.Shuffle(len(matches), func(i, j int) { matches[i], matches[j] = matches[j], matches[i] })
+	return matches[:min(limit, len(matches))], nil
+}
+
+func (m *movieRepository) CountSearch(ctx context.Context, filter movies.SearchFilter) (int64, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	return int64(len(m.filter(m.store.matcher(filter)))), nil
+}
+
+// GetSearchFacets counts the movies matching filter per genre, decade,
+// language and MPAA rating, ordered by count and then value
+func (m *movieRepository) GetSearchFacets(ctx context.Context, filter movies.SearchFilter) (*movies.SearchFacets, error) {
+	m.store.mu.RLock()
+	matches := m.filter(m.store.matcher(filter))
+	m.store.mu.RUnlock()
+
+	genres := make(map[string]int64)
+	decades := make(map[int]int64)
+	languages := make(map[string]int64)
+	ratings := make(map[string]int64)
+	for _, movie := range matches {
+		genres[movie.Genre]++
+		decades[(movie.ReleaseYear/10)*10]++
+		languages[movie.Language]++
+		ratings[string(movie.Rating)]++
+	}
+
+	decadeLabels := make(map[string]int64, len(decades))
+	for decade, count := range decades {
+		decadeLabels[fmt.Sprintf("%ds", decade)] = count
+	}
+
+	return &movies.SearchFacets{
+		Genres:    facetBuckets(genres),
+		Decades:   facetBuckets(decadeLabels),
+		Languages: facetBuckets(languages),
+		Ratings:   facetBuckets(ratings),
+	}, nil
+}
+
+// facetBuckets orders counts by descending count, then by value
+func facetBuckets(counts map[string]int64) []movies.FacetBucket {
+	buckets := make([]movies.FacetBucket, 0, len(counts))
+	for value, count := range counts {
+		buckets = append(buckets, movies.FacetBucket{Value: value, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Count != buckets[j].Count {
+			return buckets[i].Count > buckets[j].Count
+		}
+		return buckets[i].Value < buckets[j].Value
+	})
+	return buckets
+}
+
+// suggestionThreshold is the default pg_trgm similarity threshold of the %
+// operator
+const suggestionThreshold = 0.3
+
+// Suggest matches titles and distinct directors by prefix or trigram
+// similarity, computed the way pg_trgm does
+func (m *movieRepository) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	type candidate struct {
+		suggestion *movies.Suggestion
+		isPrefix   bool
+		score      float64
+	}
+
+	m.store.mu.RLock()
+	var candidates []candidate
+	directors := make(map[string]*candidate)
+	for _, movie := range m.store.movies {
+		if prefix, score := hasPrefixFold(movie.Title, query), postgres.Similarity(movie.Title, query); prefix || score >= suggestionThreshold {
+			candidates = append(candidates, candidate{
+				suggestion: &movies.Suggestion{Kind: movies.SuggestionTitle, Text: movie.Title, MovieID: movie.ID},
+				isPrefix:   prefix,
+				score:      score,
+			})
+		}
+
+		prefix, score := hasPrefixFold(movie.Director, query), postgres.Similarity(movie.Director, query)
+		if !prefix && score < suggestionThreshold {
+			continue
+		}
+		// Directors are grouped case-insensitively, like GROUP BY LOWER(director)
+		key := strings.ToLower(movie.Director)
+		if d, ok := directors[key]; ok {
+			d.suggestion.Text = min(d.suggestion.Text, movie.Director)
+			d.isPrefix = d.isPrefix || prefix
+			d.score = max(d.score, score)
+			continue
+		}
+		directors[key] = &candidate{
+			suggestion: &movies.Suggestion{Kind: movies.SuggestionDirector, Text: movie.Director},
+			isPrefix:   prefix,
+			score:      score,
+		}
+	}
+	m.store.mu.RUnlock()
+
+	for _, d := range directors {
+		candidates = append(candidates, *d)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.isPrefix != b.isPrefix {
+			return a.isPrefix
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return a.suggestion.Text < b.suggestion.Text
+	})
+
+	var suggestions []*movies.Suggestion
+	for _, c := range paginate(candidates, limit, 0) {
+		suggestions = append(suggestions, c.suggestion)
+	}
+	return suggestions, nil
+}
+
+// maxDuplicateCandidates bounds the matches returned by FindPotentialDuplicates
+const maxDuplicateCandidates = 10
+
+// FindPotentialDuplicates matches by IMDb ID or by normalized title and
+// release year, oldest first
+func (m *movieRepository) FindPotentialDuplicates(ctx context.Context, movie *movies.Movie) ([]*movies.Movie, error) {
+	title := movies.NormalizeTitle(movie.Title)
+
+	m.store.mu.RLock()
+	matches := m.filter(func(existing *movies.Movie) bool {
+		if movie.IMDbID != nil && existing.IMDbID != nil && *existing.IMDbID == *movie.IMDbID {
+			return true
+		}
+		return title != "" && existing.ReleaseYear == movie.ReleaseYear && movies.NormalizeTitle(existing.Title) == title
+	})
+	m.store.mu.RUnlock()
+
+	sortBy(matches, false, func(a, b *movies.Movie) bool { return a.CreatedAt.Before(b.CreatedAt) })
+	return paginate(matches, maxDuplicateCandidates, 0), nil
+}
+
+func (m *movieRepository) Count(ctx context.Context) (int64, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	return int64(len(m.store.movies)), nil
+}
+
+func (m *movieRepository) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	_, ok := m.store.movies[id]
+	return ok, nil
+}
+
+// GetDB returns nil: there is no database behind the store
+func (m *movieRepository) GetDB() *sqlx.DB {
+	return nil
+}
+
+func (m *movieRepository) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, ErrNoSQL
+}
+
+func (m *movieRepository) ScanMovies(rows *sql.Rows) ([]*movies.Movie, error) {
+	return nil, ErrNoSQL
+}
+
+// list returns the page of movies accepted by keep
+func (m *movieRepository) list(keep func(*movies.Movie) bool, options []movies.SearchOption) []*movies.Movie {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	return paginate(m.store.sortMovies(m.filter(keep), opts), opts.Limit, opts.Offset)
+}
+
+// filter returns copies of the movies accepted by keep, ordered by ID. The
+// caller holds the lock.
+func (m *movieRepository) filter(keep func(*movies.Movie) bool) []*movies.Movie {
+	var matches []*movies.Movie
+	for _, movie := range m.store.movies {
+		movie := movie
+		if keep(&movie) {
+			matches = append(matches, &movie)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// movieComparators compare movies on each of movies.SortFields but
+// controversy, which takes the ratings
+var movieComparators = map[string]func(a, b *movies.Movie) int{
+	"created_at":    func(a, b *movies.Movie) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"director":      func(a, b *movies.Movie) int { return strings.Compare(a.Director, b.Director) },
+	"duration_mins": func(a, b *movies.Movie) int { return cmp.Compare(a.DurationMins, b.DurationMins) },
+	"genre":         func(a, b *movies.Movie) int { return strings.Compare(a.Genre, b.Genre) },
+	"release_year":  func(a, b *movies.Movie) int { return cmp.Compare(a.ReleaseYear, b.ReleaseYear) },
+	"title":         func(a, b *movies.Movie) int { return strings.Compare(a.Title, b.Title) },
+	"updated_at":    func(a, b *movies.Movie) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// sortMovies orders movies by the whitelisted sort, created_at by default.
+// The caller holds the lock.
+func (s *Store) sortMovies(list []*movies.Movie, opts movies.SearchOptions) []*movies.Movie {
+	comparators := maps.Clone(movieComparators)
+	var controversy map[movies.MovieID]float64
+	comparators["controversy"] = func(a, b *movies.Movie) int {
+		if controversy == nil {
+			controversy = s.controversy()
+		}
+		return cmp.Compare(controversy[a.ID], controversy[b.ID])
+	}
+	sorting.Sort(list, sorting.Resolve(opts.SortBy, opts.Order, comparators, "created_at"), comparators)
+	return list
+}
+
+// controversy returns rating.Controversy of the visible ratings of every
+// rated movie
+func (s *Store) controversy() map[movies.MovieID]float64 {
+	counts := make(map[movies.MovieID]map[int]int64)
+	for _, rating := range s.ratings {
+		if !s.visible(rating) {
+			continue
+		}
+		if counts[rating.MovieID] == nil {
+			counts[rating.MovieID] = make(map[int]int64)
+		}
+		counts[rating.MovieID][rating.Score]++
+	}
+
+	controversy := make(map[movies.MovieID]float64, len(counts))
+	for id, scores := range counts {
+		var total int64
+		for _, n := range scores {
+			total += n
+		}
+		controversy[id] = domainRating.Controversy(domainRating.ScoreVariance(scores), total)
+	}
+	return controversy
+}
+
+// matcher turns a movies.SearchFilter into a predicate. The caller holds
+// the lock while it runs.
+func (s *Store) matcher(filter movies.SearchFilter) func(*movies.Movie) bool {
+	var minBayesian func(*movies.Movie) bool
+	if filter.MinBayesianRating != nil {
+		minBayesian = s.bayesianAtLeast(*filter.MinBayesianRating, filter.BayesianConfidenceK)
+	}
+
+	var rated map[movies.MovieID]bool
+	if filter.NotRatedBy != "" {
+		rated = make(map[movies.MovieID]bool)
+		for _, rating := range s.ratings {
+			if rating.UserID == users.UserID(filter.NotRatedBy) {
+				rated[rating.MovieID] = true
+			}
+		}
+	}
+
+	return func(movie *movies.Movie) bool {
+		switch {
+		case rated[movie.ID],
+			filter.Query != "" && !containsFold(movie.Title, filter.Query),
+			filter.Genre != "" && !strings.EqualFold(movie.Genre, filter.Genre),
+			filter.Director != "" && !strings.EqualFold(movie.Director, filter.Director),
+			filter.MinYear != nil && movie.ReleaseYear < *filter.MinYear,
+			filter.MaxYear != nil && movie.ReleaseYear > *filter.MaxYear,
+			filter.Language != "" && !strings.EqualFold(movie.Language, filter.Language),
+			filter.Country != "" && !strings.EqualFold(movie.Country, filter.Country),
+			filter.MinDuration != nil && movie.DurationMins < *filter.MinDuration:
+			return false
+		// Credits, certifications and provider offers are not kept in memory
+		case filter.Featuring != "", filter.CertificationTerritory != "", filter.Provider != "":
+			return false
+		case minBayesian != nil:
+			return minBayesian(movie)
+		}
+		return true
+	}
+}
+
+// bayesianAtLeast keeps movies whose Bayesian average of visible ratings is
+// at least minRating
+func (s *Store) bayesianAtLeast(minRating, confidenceK float64) func(*movies.Movie) bool {
+	var globalSum, globalCount int64
+	sums := make(map[movies.MovieID]int64)
+	counts := make(map[movies.MovieID]int64)
+	for _, rating := range s.ratings {
+		if !s.visible(rating) {
+			continue
+		}
+		globalSum += int64(rating.Score)
+		globalCount++
+		sums[rating.MovieID] += int64(rating.Score)
+		counts[rating.MovieID]++
+	}
+	var globalAverage float64
+	if globalCount > 0 {
+		globalAverage = float64(globalSum) / float64(globalCount)
+	}
+
+	return func(movie *movies.Movie) bool {
+		v := float64(counts[movie.ID])
+		var average float64
+		if v > 0 {
+			average = float64(sums[movie.ID]) / v
+		}
+		return domainRating.BayesianAverage(average, v, globalAverage, confidenceK) >= minRating
+	}
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+)
+
+func saveMovie(t *testing.T, repo movies.Repository, id, title string, year int, genre, director string, createdAt time.Time) *movies.Movie {
+	t.Helper()
+	movie, err := repo.Save(context.Background(), &movies.Movie{
+		ID:          movies.MovieID(id),
+		Title:       title,
+		ReleaseYear: year,
+		Genre:       genre,
+		Director:    director,
+		Language:    "English",
+		Rating:      "PG",
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	})
+	require.NoError(t, err)
+	return movie
+}
+
+func TestMovieRepository_SaveAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMovieRepository(NewStore())
+	saved := saveMovie(t, repo, "m1", "Heat", 1995, "Crime", "Michael Mann", time.Now())
+
+	// Changing the returned movie does not change the stored one
+	saved.Title = "Changed"
+	movie, err := repo.GetByID(ctx, "m1")
+	require.NoError(t, err)
+	assert.Equal(t, "Heat", movie.Title)
+
+	_, err = repo.Save(ctx, &movies.Movie{ID: "m1"})
+	assert.EqualError(t, err, "movie with ID m1 already exists")
+
+	_, err = repo.GetByID(ctx, "missing")
+	assert.EqualError(t, err, "movie with ID missing not found")
+
+	_, err = repo.Update(ctx, &movies.Movie{ID: "missing"})
+	assert.EqualError(t, err, "movie with ID missing not found")
+}
+
+func TestMovieRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMovieRepository(NewStore())
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	saveMovie(t, repo, "m1", "The Matrix", 1999, "Sci-Fi", "Lana Wachowski", base)
+	saveMovie(t, repo, "m2", "The Matrix Reloaded", 2003, "Sci-Fi", "Lana Wachowski", base.Add(time.Hour))
+	saveMovie(t, repo, "m3", "Heat", 1995, "Crime", "Michael Mann", base.Add(2*time.Hour))
+
+	t.Run("filters and sorts", func(t *testing.T) {
+		page, total, err := repo.Search(ctx, movies.SearchFilter{Query: "matrix", Genre: "sci-fi"},
+			movies.WithSort("release_year", "desc"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, page, 2)
+		assert.Equal(t, movies.MovieID("m2"), page[0].ID)
+		assert.Equal(t, movies.MovieID("m1"), page[1].ID)
+	})
+
+	t.Run("sorts on several columns", func(t *testing.T) {
+		all, err := repo.GetAll(ctx, movies.WithSort("director:desc,release_year", "desc"))
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, movies.MovieID("m3"), all[0].ID)
+		assert.Equal(t, movies.MovieID("m2"), all[1].ID)
+		assert.Equal(t, movies.MovieID("m1"), all[2].ID)
+	})
+
+	t.Run("sorts by controversy", func(t *testing.T) {
+		store := NewStore()
+		movieRepo, ratingRepo := NewMovieRepository(store), NewRatingRepository(store)
+		saveMovie(t, movieRepo, "agreed", "Agreed", 2000, "Drama", "Director", base)
+		saveMovie(t, movieRepo, "divided", "Divided", 2000, "Drama", "Director", base)
+		saveMovie(t, movieRepo, "unrated", "Unrated", 2000, "Drama", "Director", base)
+		userRepo := NewUserRepository(store, cache.NewNoOpCache(), testLogger)
+		for _, id := range []users.UserID{"u1", "u2"} {
+			_, err := userRepo.Create(ctx, &users.User{ID: id, Email: string(id) + "@example.com", Role: users.RoleUser, CreatedAt: base})
+			require.NoError(t, err)
+		}
+		saveRating(t, ratingRepo, "r1", "u1", "agreed", 4, base)
+		saveRating(t, ratingRepo, "r2", "u2", "agreed", 4, base)
+		saveRating(t, ratingRepo, "r3", "u1", "divided", 1, base)
+		saveRating(t, ratingRepo, "r4", "u2", "divided", 5, base)
+
+		all, err := movieRepo.GetAll(ctx, movies.WithSort("controversy:desc,title", "asc"))
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, movies.MovieID("divided"), all[0].ID)
+		assert.Equal(t, movies.MovieID("agreed"), all[1].ID, "ties go by title")
+		assert.Equal(t, movies.MovieID("unrated"), all[2].ID)
+	})
+
+	t.Run("total is 0 past the last page", func(t *testing.T) {
+		page, total, err := repo.Search(ctx, movies.SearchFilter{}, movies.WithOffset(10))
+		require.NoError(t, err)
+		assert.Empty(t, page)
+		assert.Zero(t, total)
+
+		count, err := repo.CountSearch(ctx, movies.SearchFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("defaults to newest first", func(t *testing.T) {
+		all, err := repo.GetAll(ctx, movies.WithLimit(2))
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, movies.MovieID("m3"), all[0].ID)
+		assert.Equal(t, movies.MovieID("m2"), all[1].ID)
+	})
+
+	t.Run("facets", func(t *testing.T) {
+		facets, err := repo.GetSearchFacets(ctx, movies.SearchFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, []movies.FacetBucket{{Value: "Sci-Fi", Count: 2}, {Value: "Crime", Count: 1}}, facets.Genres)
+		assert.Equal(t, []movies.FacetBucket{{Value: "1990s", Count: 2}, {Value: "2000s", Count: 1}}, facets.Decades)
+	})
+
+	t.Run("credits are not kept", func(t *testing.T) {
+		count, err := repo.CountSearch(ctx, movies.SearchFilter{Featuring: "Keanu Reeves"})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
+func TestMovieRepository_Random(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	repo := NewMovieRepository(store)
+	now := time.Now()
+	saveMovie(t, repo, "m1", "The Matrix", 1999, "Sci-Fi", "Lana Wachowski", now)
+	saveMovie(t, repo, "m2", "The Matrix Reloaded", 2003, "Sci-Fi", "Lana Wachowski", now)
+	saveMovie(t, repo, "m3", "Heat", 1995, "Crime", "Michael Mann", now)
+	_, err := NewUserRepository(store, cache.NewNoOpCache(), testLogger).Create(ctx, &users.User{ID: "u1", Email: "u1@example.com", Role: users.RoleUser, CreatedAt: now})
+	require.NoError(t, err)
+	saveRating(t, NewRatingRepository(store), "r1", "u1", "m1", 5, now)
+
+	picked, err := repo.Random(ctx, movies.SearchFilter{Genre: "sci-fi"}, 1)
+	require.NoError(t, err)
+	require.Len(t, picked, 1)
+	assert.Equal(t, "Sci-Fi", picked[0].Genre)
+
+	picked, err = repo.Random(ctx, movies.SearchFilter{NotRatedBy: "u1"}, 10)
+	require.NoError(t, err)
+	require.Len(t, picked, 2)
+	ids := []movies.MovieID{picked[0].ID, picked[1].ID}
+	assert.ElementsMatch(t, []movies.MovieID{"m2", "m3"}, ids)
+}
+
+func TestMovieRepository_Suggest(t *testing.T) {
+	repo := NewMovieRepository(NewStore())
+	now := time.Now()
+	saveMovie(t, repo, "m1", "The Matrix", 1999, "Sci-Fi", "Lana Wachowski", now)
+	saveMovie(t, repo, "m2", "Matrix Revolutions", 2003, "Sci-Fi", "lana wachowski", now)
+	saveMovie(t, repo, "m3", "Heat", 1995, "Crime", "Michael Mann", now)
+
+	suggestions, err := repo.Suggest(context.Background(), "matrix", 10)
+	require.NoError(t, err)
+	var texts []string
+	for _, s := range suggestions {
+		texts = append(texts, fmt.Sprintf("%s:%s", s.Kind, s.Text))
+	}
+	// Prefix matches come first, then titles similar enough by trigrams
+	assert.Equal(t, []string{"title:Matrix Revolutions", "title:The Matrix"}, texts)
+
+	suggestions, err = repo.Suggest(context.Background(), "lana", 10)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, movies.SuggestionDirector, suggestions[0].Kind)
+	assert.Equal(t, "Lana Wachowski", suggestions[0].Text)
+}
+
+func TestMovieRepository_FindPotentialDuplicates(t *testing.T) {
+	repo := NewMovieRepository(NewStore())
+	now := time.Now()
+	saveMovie(t, repo, "m1", "The Matrix", 1999, "Sci-Fi", "Lana Wachowski", now)
+	saveMovie(t, repo, "m2", "The Matrix", 2021, "Sci-Fi", "Lana Wachowski", now)
+
+	duplicates, err := repo.FindPotentialDuplicates(context.Background(), &movies.Movie{Title: "the matrix!", ReleaseYear: 1999})
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, movies.MovieID("m1"), duplicates[0].ID)
+}
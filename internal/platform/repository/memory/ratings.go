@@ -0,0 +1,626 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/sorting"
+	"time"
+)
+
+type ratingRepository struct {
+	store *Store
+}
+
+func NewRatingRepository(store *Store) domainRating.Repository {
+	return &ratingRepository{store: store}
+}
+
+func (r *ratingRepository) Save(ctx context.Context, rating *domainRating.Rating) (*domainRating.Rating, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.ratings[rating.ID]; ok {
+		return nil, fmt.Errorf("user has already rated this movie")
+	}
+	for _, existing := range r.store.ratings {
+		if existing.UserID == rating.UserID && existing.MovieID == rating.MovieID {
+			return nil, fmt.Errorf("user has already rated this movie")
+		}
+	}
+	if _, ok := r.store.movies[rating.MovieID]; !ok {
+		return nil, fmt.Errorf("failed to save rating: movie %s does not exist", rating.MovieID)
+	}
+	if _, ok := r.store.users[rating.UserID]; !ok {
+		return nil, fmt.Errorf("failed to save rating: user %s does not exist", rating.UserID)
+	}
+
+	rating.Version = 1
+	r.store.ratings[rating.ID] = *rating
+	r.store.totalsUpdatedAt = time.Now()
+
+	return rating, nil
+}
+
+func (r *ratingRepository) GetByID(ctx context.Context, id domainRating.RatingID) (*domainRating.Rating, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rating, ok := r.store.ratings[id]
+	if !ok {
+		return nil, fmt.Errorf("rating with ID %s not found", id)
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) GetByIDIncludingDeleted(ctx context.Context, id domainRating.RatingID) (*domainRating.Rating, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if deleted, ok := r.store.deleted[id]; ok {
+		rating := deleted.rating
+		return &rating, nil
+	}
+	rating, ok := r.store.ratings[id]
+	if !ok {
+		return nil, fmt.Errorf("rating with ID %s not found", id)
+	}
+	return &rating, nil
+}
+
+func (r *ratingRepository) GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*domainRating.Rating, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, rating := range r.store.ratings {
+		if rating.UserID == userID && rating.MovieID == movieID {
+			return &rating, nil
+		}
+	}
+	return nil, fmt.Errorf("rating not found for user %s and movie %s", userID, movieID)
+}
+
+// GetByUser lists all of the user's ratings, including hidden ones: users
+// always see their own
+func (r *ratingRepository) GetByUser(ctx context.Context, userID users.UserID, options ...domainRating.SearchOption) ([]*domainRating.Rating, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	list := r.filter(func(rating domainRating.Rating) bool {
+		return rating.UserID == userID
+	})
+	return paginate(sortRatings(list, opts), opts.Limit, opts.Offset), nil
+}
+
+func (r *ratingRepository) GetByMovie(ctx context.Context, movieID movies.MovieID, options ...domainRating.SearchOption) ([]*domainRating.Rating, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	list := r.filter(func(rating domainRating.Rating) bool {
+		if rating.MovieID != movieID || !r.store.visible(rating) {
+			return false
+		}
+		switch opts.Reviewer {
+		case domainRating.ReviewerCritic:
+			if !r.store.byCritic(rating) {
+				return false
+			}
+		case domainRating.ReviewerAudience:
+			if r.store.byCritic(rating) {
+				return false
+			}
+		}
+		switch opts.Spoilers {
+		case domainRating.SpoilersHide:
+			if rating.ContainsSpoilers {
+				return false
+			}
+		case domainRating.SpoilersOnly:
+			if !rating.ContainsSpoilers {
+				return false
+			}
+		}
+		if opts.ReviewsOnly && rating.Review == "" {
+			return false
+		}
+		return !opts.HideAdultLanguage || !rating.ContainsAdultLanguage
+	})
+	return paginate(sortRatings(list, opts), opts.Limit, opts.Offset), nil
+}
+
+func (r *ratingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter domainRating.UserRatingFilter, options ...domainRating.SearchOption) ([]*domainRating.RatingWithMovie, int64, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var results []*domainRating.RatingWithMovie
+	for _, rating := range r.filter(func(rating domainRating.Rating) bool {
+		return rating.UserID == userID
+	}) {
+		movie, ok := r.store.movies[rating.MovieID]
+		if !ok {
+			continue
+		}
+		switch {
+		case filter.Genre != "" && !strings.EqualFold(movie.Genre, filter.Genre),
+			filter.MinScore != nil && rating.Score < *filter.MinScore,
+			filter.MaxScore != nil && rating.Score > *filter.MaxScore,
+			filter.YearFrom != nil && movie.ReleaseYear < *filter.YearFrom,
+			filter.YearTo != nil && movie.ReleaseYear > *filter.YearTo,
+			filter.RatedFrom != nil && rating.CreatedAt.Before(*filter.RatedFrom),
+			filter.RatedTo != nil && !rating.CreatedAt.Before(*filter.RatedTo):
+			continue
+		}
+		results = append(results, &domainRating.RatingWithMovie{Rating: rating, Movie: &movie})
+	}
+
+	sorting.Sort(results, sorting.Resolve(opts.SortBy, opts.Order, joinedRatingComparators, "created_at"), joinedRatingComparators)
+
+	page := paginate(results, opts.Limit, opts.Offset)
+	if len(page) == 0 {
+		return nil, 0, nil
+	}
+	for _, item := range page {
+		summary := r.summarize(func(rating domainRating.Rating) bool {
+			return rating.MovieID == item.Movie.ID
+		})
+		item.MovieAverage, item.MovieTotalRatings = summary.AverageScore, summary.TotalRatings
+	}
+	return page, int64(len(results)), nil
+}
+
+// Update applies the rating only if its stored version still equals
+// rating.Version, and bumps the version
+func (r *ratingRepository) Update(ctx context.Context, rating *domainRating.Rating) (*domainRating.Rating, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.ratings[rating.ID]
+	if !ok {
+		return nil, fmt.Errorf("rating with ID %s not found", rating.ID)
+	}
+	if stored.Version != rating.Version {
+		return nil, domainRating.ErrVersionConflict
+	}
+
+	rating.UpdatedAt = time.Now()
+	stored.Score = rating.Score
+	stored.Review = rating.Review
+	stored.ContainsSpoilers = rating.ContainsSpoilers
+	stored.ContainsAdultLanguage = rating.ContainsAdultLanguage
+	stored.UpdatedAt = rating.UpdatedAt
+	stored.Version++
+	r.store.ratings[rating.ID] = stored
+	r.store.totalsUpdatedAt = rating.UpdatedAt
+
+	rating.CreatedAt = stored.CreatedAt
+	rating.Version = stored.Version
+	return rating, nil
+}
+
+func (r *ratingRepository) Delete(ctx context.Context, id domainRating.RatingID, deletedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.ratings[id]
+	if !ok {
+		return fmt.Errorf("rating with ID %s not found", id)
+	}
+	delete(r.store.ratings, id)
+	r.store.deleted[id] = deletedRating{rating: stored, deletedAt: deletedAt}
+	r.store.totalsUpdatedAt = time.Now()
+	return nil
+}
+
+func (r *ratingRepository) Restore(ctx context.Context, id domainRating.RatingID, deletedSince time.Time) (*domainRating.Rating, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	deleted, ok := r.store.deleted[id]
+	if !ok {
+		if _, live := r.store.ratings[id]; live {
+			return nil, domainRating.ErrNotDeleted
+		}
+		return nil, fmt.Errorf("rating with ID %s not found", id)
+	}
+	if deleted.deletedAt.Before(deletedSince) {
+		return nil, domainRating.ErrRestoreExpired
+	}
+	for _, existing := range r.store.ratings {
+		if existing.UserID == deleted.rating.UserID && existing.MovieID == deleted.rating.MovieID {
+			return nil, fmt.Errorf("user has already rated this movie")
+		}
+	}
+
+	delete(r.store.deleted, id)
+	r.store.ratings[id] = deleted.rating
+	r.store.totalsUpdatedAt = time.Now()
+
+	restored := deleted.rating
+	return &restored, nil
+}
+
+func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*domainRating.MovieRatingStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ofMovie := func(rating domainRating.Rating) bool { return rating.MovieID == movieID }
+	overall := r.summarize(ofMovie)
+	stats := &domainRating.MovieRatingStats{
+		MovieID:      movieID,
+		AverageScore: overall.AverageScore,
+		TotalRatings: overall.TotalRatings,
+		ScoreCount:   make(map[int]int64),
+		Critics: r.summarize(func(rating domainRating.Rating) bool {
+			return ofMovie(rating) && r.store.byCritic(rating)
+		}),
+		Audience: r.summarize(func(rating domainRating.Rating) bool {
+			return ofMovie(rating) && !r.store.byCritic(rating)
+		}),
+	}
+	for _, rating := range r.store.ratings {
+		if ofMovie(rating) && r.store.visible(rating) {
+			stats.ScoreCount[rating.Score]++
+		}
+	}
+	stats.Describe(domainRating.ScoreVariance(stats.ScoreCount))
+	return stats, nil
+}
+
+func (r *ratingRepository) Exists(ctx context.Context, id domainRating.RatingID) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	_, ok := r.store.ratings[id]
+	return ok, nil
+}
+
+func (r *ratingRepository) Count(ctx context.Context) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return int64(len(r.store.ratings)), nil
+}
+
+func (r *ratingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[movies.MovieID]int)
+	for _, rating := range r.store.ratings {
+		if r.store.visible(rating) {
+			counts[rating.MovieID]++
+		}
+	}
+
+	movieIDs := make([]movies.MovieID, 0, len(counts))
+	for id := range counts {
+		movieIDs = append(movieIDs, id)
+	}
+	sort.Slice(movieIDs, func(i, j int) bool {
+		if counts[movieIDs[i]] != counts[movieIDs[j]] {
+			return counts[movieIDs[i]] > counts[movieIDs[j]]
+		}
+		return movieIDs[i] < movieIDs[j]
+	})
+	return movieIDs[:min(limit, len(movieIDs))], nil
+}
+
+func (r *ratingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[users.UserID]int)
+	for _, rating := range r.store.ratings {
+		counts[rating.UserID]++
+	}
+
+	userIDs := make([]users.UserID, 0, len(counts))
+	for id := range counts {
+		userIDs = append(userIDs, id)
+	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		if counts[userIDs[i]] != counts[userIDs[j]] {
+			return counts[userIDs[i]] > counts[userIDs[j]]
+		}
+		return userIDs[i] < userIDs[j]
+	})
+	return userIDs[:min(limit, len(userIDs))], nil
+}
+
+// GetGlobalStats sums the visible ratings on every call, so the totals
+// never drift
+func (r *ratingRepository) GetGlobalStats(ctx context.Context) (*domainRating.GlobalStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.globalStats(), nil
+}
+
+func (r *ratingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*domainRating.GlobalStats, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.totalsRefreshedAt = now
+	r.store.totalsUpdatedAt = now
+	return r.globalStats(), nil
+}
+
+// globalStats totals the visible ratings. The caller holds the lock.
+func (r *ratingRepository) globalStats() *domainRating.GlobalStats {
+	stats := &domainRating.GlobalStats{
+		RefreshedAt: r.store.totalsRefreshedAt,
+		UpdatedAt:   r.store.totalsUpdatedAt,
+	}
+	for _, rating := range r.store.ratings {
+		if r.store.visible(rating) {
+			stats.ScoreSum += int64(rating.Score)
+			stats.TotalRatings++
+		}
+	}
+	return stats
+}
+
+func (r *ratingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*domainRating.RatingActivity, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	activity := &domainRating.RatingActivity{
+		Window:   make(map[int]int64),
+		Baseline: make(map[int]int64),
+	}
+	for _, rating := range r.store.ratings {
+		if rating.MovieID != movieID || rating.CreatedAt.Before(baselineStart) || !r.store.visible(rating) {
+			continue
+		}
+		// Every score seen gets an entry in both maps, as the grouped query
+		// returns both counts per score
+		activity.Window[rating.Score] += 0
+		activity.Baseline[rating.Score] += 0
+		if rating.CreatedAt.Before(windowStart) {
+			activity.Baseline[rating.Score]++
+		} else {
+			activity.Window[rating.Score]++
+		}
+	}
+	return activity, nil
+}
+
+// GetRaterGroups groups the movie's visible ratings by score and rater
+// trust factors. Email verification and reports are not kept in memory, so
+// every rater counts as unverified and unreported.
+func (r *ratingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*domainRating.RaterGroup, error) {
+	maxAgeDays := int64(config.FullTrustAge.Hours() / 24)
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	ratingCounts := make(map[users.UserID]int64)
+	for _, rating := range r.store.ratings {
+		ratingCounts[rating.UserID]++
+	}
+
+	type groupKey struct {
+		score       int
+		ageDays     int64
+		ratingCount int64
+	}
+	groups := make(map[groupKey]*domainRating.RaterGroup)
+	var ordered []*domainRating.RaterGroup
+	for _, rating := range r.filter(func(rating domainRating.Rating) bool {
+		return rating.MovieID == movieID && r.store.visible(rating)
+	}) {
+		user := r.store.users[rating.UserID]
+		key := groupKey{
+			score:       rating.Score,
+			ageDays:     max(min(int64(asOf.Sub(user.CreatedAt).Hours()/24), maxAgeDays), 0),
+			ratingCount: min(ratingCounts[rating.UserID], config.FullTrustRatings),
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &domainRating.RaterGroup{
+				Score: key.score,
+				Factors: users.TrustFactors{
+					AccountAge:  time.Duration(key.ageDays) * 24 * time.Hour,
+					RatingCount: key.ratingCount,
+				},
+			}
+			groups[key] = group
+			ordered = append(ordered, group)
+		}
+		group.Ratings++
+	}
+	return ordered, nil
+}
+
+// SearchReviews matches every word of query against the review text, case
+// insensitively; words prefixed with - must not appear. It stands in for
+// full-text search, ranking reviews by how often the words occur.
+func (r *ratingRepository) SearchReviews(ctx context.Context, query string, filter domainRating.ReviewFilter, options ...domainRating.SearchOption) ([]*domainRating.ReviewMatch, int64, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	var include, exclude []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, `"`)
+		if excluded, ok := strings.CutPrefix(word, "-"); ok {
+			if excluded != "" {
+				exclude = append(exclude, excluded)
+			}
+		} else if word != "" && word != "or" {
+			include = append(include, word)
+		}
+	}
+	if len(include) == 0 {
+		return nil, 0, nil
+	}
+
+	r.store.mu.RLock()
+	candidates := r.filter(func(rating domainRating.Rating) bool {
+		switch {
+		case !r.store.visible(rating),
+			opts.HideAdultLanguage && rating.ContainsAdultLanguage,
+			filter.MovieID != "" && rating.MovieID != filter.MovieID,
+			filter.MinScore != nil && rating.Score < *filter.MinScore,
+			filter.MaxScore != nil && rating.Score > *filter.MaxScore,
+			filter.From != nil && rating.CreatedAt.Before(*filter.From),
+			filter.To != nil && rating.CreatedAt.After(*filter.To):
+			return false
+		}
+		return true
+	})
+	r.store.mu.RUnlock()
+
+	var matches []*domainRating.ReviewMatch
+	for _, rating := range candidates {
+		review := strings.ToLower(rating.Review)
+		rank := 0
+		for _, word := range include {
+			n := strings.Count(review, word)
+			if n == 0 {
+				rank = 0
+				break
+			}
+			rank += n
+		}
+		for _, word := range exclude {
+			if strings.Contains(review, word) {
+				rank = 0
+			}
+		}
+		if rank == 0 {
+			continue
+		}
+		matches = append(matches, &domainRating.ReviewMatch{
+			Rating:  rating,
+			Snippet: highlight(rating.Review, include),
+			Rank:    float64(rank),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Rank != b.Rank {
+			return a.Rank > b.Rank
+		}
+		return a.Rating.CreatedAt.After(b.Rating.CreatedAt)
+	})
+
+	page := paginate(matches, opts.Limit, opts.Offset)
+	if len(page) == 0 {
+		return nil, 0, nil
+	}
+	return page, int64(len(matches)), nil
+}
+
+// highlight escapes review and wraps every occurrence of words in <mark>
+func highlight(review string, words []string) string {
+	lower := strings.ToLower(review)
+	if len(lower) != len(review) {
+		// Lower-casing changed byte offsets; leave the matches unmarked
+		return html.EscapeString(review)
+	}
+	marked := make([]bool, len(review))
+	for _, word := range words {
+		for i := 0; ; {
+			j := strings.Index(lower[i:], word)
+			if j < 0 {
+				break
+			}
+			for k := i + j; k < i+j+len(word); k++ {
+				marked[k] = true
+			}
+			i += j + len(word)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(review); {
+		j := i
+		for j < len(review) && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString("<mark>" + html.EscapeString(review[i:j]) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(review[i:j]))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// filter returns copies of the ratings accepted by keep, ordered by ID. The
+// caller holds the lock.
+func (r *ratingRepository) filter(keep func(domainRating.Rating) bool) []*domainRating.Rating {
+	var matches []*domainRating.Rating
+	for _, rating := range r.store.ratings {
+		if keep(rating) {
+			rating := rating
+			matches = append(matches, &rating)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// summarize averages the visible ratings accepted by keep. The caller
+// holds the lock.
+func (r *ratingRepository) summarize(keep func(domainRating.Rating) bool) domainRating.ScoreSummary {
+	var sum, count int64
+	for _, rating := range r.store.ratings {
+		if keep(rating) && r.store.visible(rating) {
+			sum += int64(rating.Score)
+			count++
+		}
+	}
+	return domainRating.ScoreSummary{AverageScore: roundScore(sum, count), TotalRatings: count}
+}
+
+// ratingComparators compare ratings on each field they can be sorted by
+var ratingComparators = map[string]func(a, b *domainRating.Rating) int{
+	"created_at": func(a, b *domainRating.Rating) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"score":      func(a, b *domainRating.Rating) int { return cmp.Compare(a.Score, b.Score) },
+	"updated_at": func(a, b *domainRating.Rating) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// joinedRatingComparators compare ratings listed with their movies
+var joinedRatingComparators = map[string]func(a, b *domainRating.RatingWithMovie) int{
+	"created_at": func(a, b *domainRating.RatingWithMovie) int { return a.Rating.CreatedAt.Compare(b.Rating.CreatedAt) },
+	"release_year": func(a, b *domainRating.RatingWithMovie) int {
+		return cmp.Compare(a.Movie.ReleaseYear, b.Movie.ReleaseYear)
+	},
+	"score": func(a, b *domainRating.RatingWithMovie) int { return cmp.Compare(a.Rating.Score, b.Rating.Score) },
+	"title": func(a, b *domainRating.RatingWithMovie) int {
+		return strings.Compare(strings.ToLower(a.Movie.Title), strings.ToLower(b.Movie.Title))
+	},
+	"updated_at": func(a, b *domainRating.RatingWithMovie) int { return a.Rating.UpdatedAt.Compare(b.Rating.UpdatedAt) },
+}
+
+// sortRatings orders ratings by the whitelisted sort, created_at by default
+func sortRatings(list []*domainRating.Rating, opts domainRating.SearchOptions) []*domainRating.Rating {
+	sorting.Sort(list, sorting.Resolve(opts.SortBy, opts.Order, ratingComparators, "created_at"), ratingComparators)
+	return list
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+)
+
+// newRatingFixture stores two movies, a regular user, a critic and a
+// shadow-banned user
+func newRatingFixture(t *testing.T) (rating.Repository, users.UserRepository) {
+	t.Helper()
+	ctx := context.Background()
+	store := NewStore()
+	movieRepo := NewMovieRepository(store)
+	userRepo := NewUserRepository(store, cache.NewNoOpCache(), testLogger)
+
+	now := time.Now()
+	saveMovie(t, movieRepo, "m1", "Heat", 1995, "Crime", "Michael Mann", now)
+	saveMovie(t, movieRepo, "m2", "Alien", 1979, "Horror", "Ridley Scott", now)
+	for _, id := range []users.UserID{"u1", "critic", "banned"} {
+		_, err := userRepo.Create(ctx, &users.User{ID: id, Email: string(id) + "@example.com", Role: users.RoleUser, CreatedAt: now})
+		require.NoError(t, err)
+	}
+	require.NoError(t, userRepo.SetCritic(ctx, "critic", true, now))
+	require.NoError(t, userRepo.SetShadowBanned(ctx, "banned", true, now))
+
+	return NewRatingRepository(store), userRepo
+}
+
+func saveRating(t *testing.T, repo rating.Repository, id string, userID users.UserID, movieID movies.MovieID, score int, createdAt time.Time) *rating.Rating {
+	t.Helper()
+	saved, err := repo.Save(context.Background(), &rating.Rating{
+		ID:        rating.RatingID(id),
+		UserID:    userID,
+		MovieID:   movieID,
+		Score:     score,
+		Review:    "A tense heist movie",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	})
+	require.NoError(t, err)
+	return saved
+}
+
+func TestRatingRepository_SaveAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRatingFixture(t)
+	saved := saveRating(t, repo, "r1", "u1", "m1", 4, time.Now())
+	assert.Equal(t, 1, saved.Version)
+
+	_, err := repo.Save(ctx, &rating.Rating{ID: "r2", UserID: "u1", MovieID: "m1", Score: 3})
+	assert.EqualError(t, err, "user has already rated this movie")
+
+	_, err = repo.Save(ctx, &rating.Rating{ID: "r3", UserID: "u1", MovieID: "missing", Score: 3})
+	assert.Error(t, err)
+
+	updated, err := repo.Update(ctx, &rating.Rating{ID: "r1", Score: 5, Version: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+
+	_, err = repo.Update(ctx, &rating.Rating{ID: "r1", Score: 2, Version: 1})
+	assert.ErrorIs(t, err, rating.ErrVersionConflict)
+
+	_, err = repo.Update(ctx, &rating.Rating{ID: "missing", Version: 1})
+	assert.EqualError(t, err, "rating with ID missing not found")
+
+	deletedAt := time.Now()
+	require.NoError(t, repo.Delete(ctx, "r1", deletedAt))
+	assert.EqualError(t, repo.Delete(ctx, "r1", deletedAt), "rating with ID r1 not found")
+	_, err = repo.GetByID(ctx, "r1")
+	assert.EqualError(t, err, "rating with ID r1 not found")
+
+	_, err = repo.Restore(ctx, "r1", deletedAt.Add(time.Second))
+	assert.ErrorIs(t, err, rating.ErrRestoreExpired)
+
+	restored, err := repo.Restore(ctx, "r1", deletedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 5, restored.Score)
+	_, err = repo.Restore(ctx, "r1", deletedAt.Add(-time.Hour))
+	assert.ErrorIs(t, err, rating.ErrNotDeleted)
+	_, err = repo.Restore(ctx, "missing", deletedAt)
+	assert.EqualError(t, err, "rating with ID missing not found")
+}
+
+func TestRatingRepository_Visibility(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRatingFixture(t)
+	now := time.Now()
+	saveRating(t, repo, "r1", "u1", "m1", 4, now)
+	saveRating(t, repo, "r2", "critic", "m1", 2, now.Add(time.Minute))
+	saveRating(t, repo, "r3", "banned", "m1", 1, now.Add(2*time.Minute))
+
+	t.Run("shadow-banned ratings are hidden from the movie", func(t *testing.T) {
+		list, err := repo.GetByMovie(ctx, "m1")
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.Equal(t, rating.RatingID("r2"), list[0].ID)
+
+		critics, err := repo.GetByMovie(ctx, "m1", rating.WithReviewer(rating.ReviewerCritic))
+		require.NoError(t, err)
+		require.Len(t, critics, 1)
+		assert.Equal(t, rating.RatingID("r2"), critics[0].ID)
+	})
+
+	t.Run("but not from their author", func(t *testing.T) {
+		list, err := repo.GetByUser(ctx, "banned")
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats, err := repo.GetMovieStats(ctx, "m1")
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, stats.AverageScore)
+		assert.Equal(t, int64(2), stats.TotalRatings)
+		assert.Equal(t, map[int]int64{2: 1, 4: 1}, stats.ScoreCount)
+		assert.Equal(t, rating.ScoreSummary{AverageScore: 2, TotalRatings: 1}, stats.Critics)
+		assert.Equal(t, rating.ScoreSummary{AverageScore: 4, TotalRatings: 1}, stats.Audience)
+		assert.Equal(t, 1.0, stats.StandardDeviation)
+		assert.Equal(t, 3.0, stats.MedianScore)
+		assert.InDelta(t, 1.0/7, stats.SmoothedDistribution[5], 1e-9)
+
+		global, err := repo.GetGlobalStats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), global.ScoreSum)
+		assert.Equal(t, int64(2), global.TotalRatings)
+	})
+
+	t.Run("most rated movies", func(t *testing.T) {
+		saveRating(t, repo, "r4", "u1", "m2", 5, now)
+		saveRating(t, repo, "r5", "banned", "m2", 5, now)
+
+		ids, err := repo.MostRatedMovies(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []movies.MovieID{"m1", "m2"}, ids)
+
+		ids, err = repo.MostRatedMovies(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []movies.MovieID{"m1"}, ids)
+	})
+
+	t.Run("most active raters", func(t *testing.T) {
+		// A shadow-banned user's ratings are still their own
+		raters, err := repo.MostActiveRaters(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []users.UserID{"banned", "u1"}, raters)
+	})
+}
+
+func TestRatingRepository_GetUserRatingsWithMovies(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRatingFixture(t)
+	now := time.Now()
+	saveRating(t, repo, "r1", "u1", "m1", 4, now)
+	saveRating(t, repo, "r2", "u1", "m2", 5, now.Add(time.Minute))
+	saveRating(t, repo, "r3", "critic", "m1", 3, now)
+
+	page, total, err := repo.GetUserRatingsWithMovies(ctx, "u1", rating.UserRatingFilter{}, rating.WithSort("title", "asc"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "Alien", page[0].Movie.Title)
+	assert.Equal(t, "Heat", page[1].Movie.Title)
+	assert.Equal(t, 3.5, page[1].MovieAverage)
+	assert.Equal(t, int64(2), page[1].MovieTotalRatings)
+
+	minScore := 5
+	page, total, err = repo.GetUserRatingsWithMovies(ctx, "u1", rating.UserRatingFilter{MinScore: &minScore})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, page, 1)
+	assert.Equal(t, rating.RatingID("r2"), page[0].Rating.ID)
+}
+
+func TestRatingRepository_SearchReviews(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRatingFixture(t)
+	saveRating(t, repo, "r1", "u1", "m1", 4, time.Now())
+	saveRating(t, repo, "r2", "banned", "m1", 4, time.Now())
+
+	matches, total, err := repo.SearchReviews(ctx, "heist", rating.ReviewFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "A tense <mark>heist</mark> movie", matches[0].Snippet)
+
+	matches, _, err = repo.SearchReviews(ctx, "heist -tense", rating.ReviewFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestRatingRepository_ReviewsOnly(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRatingFixture(t)
+	now := time.Now()
+	saveRating(t, repo, "r1", "u1", "m1", 4, now)
+	_, err := repo.Save(ctx, &rating.Rating{ID: "r2", UserID: "critic", MovieID: "m1", Score: 3, CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+
+	list, err := repo.GetByMovie(ctx, "m1")
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	reviews, err := repo.GetByMovie(ctx, "m1", rating.WithReviewsOnly())
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, rating.RatingID("r1"), reviews[0].ID)
+}
@@ -0,0 +1,105 @@
+// Package memory implements the movie, rating and user repositories on maps
+// held in process. It backs the zero-dependency demo mode (STORAGE=memory)
+// and service tests that want a working repository rather than a mock.
+//
+// The repositories mirror the Postgres ones: the same filters, sorting,
+// pagination, error messages and shadow-ban visibility rules. Data that
+// lives in tables of other features (credits, certifications, email
+// verification, reports) is not kept, so filters on it match nothing and
+// trust factors built from it are zero.
+package memory
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+)
+
+// ErrNoSQL is returned by the raw query methods of the movie repository,
+// which have no database to run against
+var ErrNoSQL = errors.New("raw SQL is not supported by the in-memory store")
+
+// Store holds the data shared by the repositories built on it, so that
+// ratings can be joined with their movies and raters as the database does
+type Store struct {
+	mu      sync.RWMutex
+	movies  map[movies.MovieID]movies.Movie
+	ratings map[domainRating.RatingID]domainRating.Rating
+	users   map[users.UserID]users.User
+
+	// deleted holds soft-deleted ratings apart from the live ones, so they
+	// are left out of every listing and stat
+	deleted map[domainRating.RatingID]deletedRating
+
+	// totalsRefreshedAt and totalsUpdatedAt stand in for the timestamps of
+	// the rating_totals row
+	totalsRefreshedAt time.Time
+	totalsUpdatedAt   time.Time
+}
+
+type deletedRating struct {
+	rating    domainRating.Rating
+	deletedAt time.Time
+}
+
+func NewStore() *Store {
+	now := time.Now()
+	return &Store{
+		movies:            make(map[movies.MovieID]movies.Movie),
+		ratings:           make(map[domainRating.RatingID]domainRating.Rating),
+		users:             make(map[users.UserID]users.User),
+		deleted:           make(map[domainRating.RatingID]deletedRating),
+		totalsRefreshedAt: now,
+		totalsUpdatedAt:   now,
+	}
+}
+
+// visible reports whether a rating is shown to everyone, i.e. its rater is
+// not shadow-banned. The caller holds the lock.
+func (s *Store) visible(rating domainRating.Rating) bool {
+	return !s.users[rating.UserID].ShadowBanned
+}
+
+// byCritic reports whether a rating is by a verified critic. The caller
+// holds the lock.
+func (s *Store) byCritic(rating domainRating.Rating) bool {
+	return s.users[rating.UserID].IsCritic
+}
+
+// paginate returns the items of the page starting at offset, as LIMIT and
+// OFFSET do
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) || limit <= 0 {
+		return nil
+	}
+	end := min(offset+limit, len(items))
+	return items[offset:end]
+}
+
+// sortBy orders items stably by less, reversed when desc is set. Ties keep
+// the order items came in, which callers make deterministic by sorting on
+// ID first.
+func sortBy[T any](items []T, desc bool, less func(a, b T) bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+}
+
+// roundScore rounds an average to two decimals as ROUND(x, 2) does
+func roundScore(sum, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(sum)/float64(count)*100) / 100
+}
@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/sorting"
+	"time"
+)
+
+type userRepository struct {
+	store  *Store
+	cache  cache.Cache
+	logger *slog.Logger
+}
+
+func NewUserRepository(store *Store, cache cache.Cache, logger *slog.Logger) domainUser.UserRepository {
+	return &userRepository{
+		store:  store,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// FindByID returns sql.ErrNoRows for unknown users, as the Postgres
+// repository does
+func (r *userRepository) FindByID(ctx context.Context, id domainUser.UserID) (*domainUser.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &user, nil
+}
+
+// FindByEmail returns nil without an error for unknown emails
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domainUser.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user *domainUser.User) (*domainUser.User, error) {
+	r.store.mu.Lock()
+	if _, ok := r.store.users[user.ID]; ok || r.emailTaken(user.Email, "") {
+		r.store.mu.Unlock()
+		return nil, domainUser.ErrUserAlreadyExists
+	}
+	stored := *user
+	if stored.ContentMode == "" {
+		stored.ContentMode = domainUser.ContentModeStandard
+	}
+	r.store.users[user.ID] = stored
+	r.store.mu.Unlock()
+
+	r.invalidateUserCache(ctx, user.ID)
+	return user, nil
+}
+
+func (r *userRepository) Update(ctx context.Context, user *domainUser.User) (*domainUser.User, error) {
+	err := r.update(user.ID, func(stored *domainUser.User) error {
+		if r.emailTaken(user.Email, user.ID) {
+			return domainUser.ErrUserAlreadyExists
+		}
+		stored.FirstName = user.FirstName
+		stored.LastName = user.LastName
+		stored.Email = user.Email
+		stored.IsActive = user.IsActive
+		stored.ContentMode = user.ContentMode
+		if stored.ContentMode == "" {
+			stored.ContentMode = domainUser.ContentModeStandard
+		}
+		stored.UpdatedAt = user.UpdatedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateUserCache(ctx, user.ID)
+	return user, nil
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, id domainUser.UserID, avatarKey *string, updatedAt time.Time) (*string, error) {
+	var previous *string
+	err := r.update(id, func(stored *domainUser.User) error {
+		previous = stored.AvatarKey
+		stored.AvatarKey = avatarKey
+		stored.UpdatedAt = updatedAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateUserCache(ctx, id)
+	return previous, nil
+}
+
+func (r *userRepository) SetShadowBanned(ctx context.Context, id domainUser.UserID, banned bool, updatedAt time.Time) error {
+	err := r.update(id, func(stored *domainUser.User) error {
+		if stored.ShadowBanned != banned {
+			// Hiding or showing the user's ratings changes the global totals
+			r.store.totalsUpdatedAt = updatedAt
+		}
+		stored.ShadowBanned = banned
+		stored.UpdatedAt = updatedAt
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateUserCache(ctx, id)
+	return nil
+}
+
+func (r *userRepository) SetCritic(ctx context.Context, id domainUser.UserID, critic bool, updatedAt time.Time) error {
+	err := r.update(id, func(stored *domainUser.User) error {
+		stored.IsCritic = critic
+		stored.UpdatedAt = updatedAt
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.invalidateUserCache(ctx, id)
+	return nil
+}
+
+func (r *userRepository) BulkUpdate(ctx context.Context, update domainUser.BulkUpdate) ([]domainUser.BulkResult, error) {
+	r.store.mu.Lock()
+	results := make([]domainUser.BulkResult, len(update.UserIDs))
+	var changed []domainUser.UserID
+	for i, id := range update.UserIDs {
+		results[i].UserID = id
+		user, ok := r.store.users[id]
+		if !ok {
+			results[i].Status = domainUser.BulkNotFound
+			continue
+		}
+
+		var needsChange bool
+		switch update.Action {
+		case domainUser.BulkDeactivate:
+			needsChange = user.IsActive
+		case domainUser.BulkActivate:
+			needsChange = !user.IsActive
+		case domainUser.BulkChangeRole:
+			needsChange = user.Role != update.Role
+		default:
+			r.store.mu.Unlock()
+			return nil, fmt.Errorf("unknown bulk action %q", update.Action)
+		}
+
+		if needsChange {
+			results[i].Status = domainUser.BulkUpdated
+			changed = append(changed, id)
+		} else {
+			results[i].Status = domainUser.BulkUnchanged
+		}
+	}
+
+	// Applied only once every user was checked, so an unknown action leaves
+	// all of them untouched
+	for _, id := range changed {
+		user := r.store.users[id]
+		switch update.Action {
+		case domainUser.BulkDeactivate, domainUser.BulkActivate:
+			user.IsActive = update.Action == domainUser.BulkActivate
+		case domainUser.BulkChangeRole:
+			user.Role = update.Role
+		}
+		user.UpdatedAt = update.UpdatedAt
+		r.store.users[id] = user
+	}
+	r.store.mu.Unlock()
+
+	for _, id := range changed {
+		r.invalidateUserCache(ctx, id)
+	}
+	return results, nil
+}
+
+func (r *userRepository) List(ctx context.Context, filter domainUser.ListFilter, page, limit int) ([]*domainUser.User, int, error) {
+	offset := 0
+	if page > 0 {
+		offset = (page - 1) * limit
+	}
+
+	r.store.mu.RLock()
+	matches := r.filter(filter)
+	r.store.mu.RUnlock()
+
+	sortUsers(matches, filter)
+	users := paginate(matches, limit, offset)
+	if len(users) == 0 {
+		return nil, 0, nil
+	}
+	return users, len(matches), nil
+}
+
+func (r *userRepository) Count(ctx context.Context, filter domainUser.ListFilter) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.filter(filter)), nil
+}
+
+// update applies change to the stored user under the write lock
+func (r *userRepository) update(id domainUser.UserID, change func(*domainUser.User) error) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[id]
+	if !ok {
+		return domainUser.ErrUserNotFound
+	}
+	if err := change(&user); err != nil {
+		return err
+	}
+	r.store.users[id] = user
+	return nil
+}
+
+// emailTaken reports whether a user other than except has email. The
+// caller holds the lock.
+func (r *userRepository) emailTaken(email string, except domainUser.UserID) bool {
+	for id, user := range r.store.users {
+		if id != except && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns copies of the users matching filter, ordered by ID. The
+// caller holds the lock.
+func (r *userRepository) filter(filter domainUser.ListFilter) []*domainUser.User {
+	prefix := strings.ToLower(filter.EmailPrefix)
+
+	var matches []*domainUser.User
+	for _, user := range r.store.users {
+		switch {
+		case filter.Role != "" && user.Role != filter.Role,
+			filter.IsActive != nil && user.IsActive != *filter.IsActive,
+			filter.CreatedAfter != nil && !user.CreatedAt.After(*filter.CreatedAfter),
+			prefix != "" && !strings.HasPrefix(strings.ToLower(user.Email), prefix):
+			continue
+		}
+		user := user
+		matches = append(matches, &user)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// userComparators compare users on each field they can be sorted by
+var userComparators = map[string]func(a, b *domainUser.User) int{
+	"created_at": func(a, b *domainUser.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"email":      func(a, b *domainUser.User) int { return strings.Compare(a.Email, b.Email) },
+	"id":         func(a, b *domainUser.User) int { return strings.Compare(string(a.ID), string(b.ID)) },
+	"last_name": func(a, b *domainUser.User) int {
+		return cmp.Or(strings.Compare(a.LastName, b.LastName), strings.Compare(a.FirstName, b.FirstName))
+	},
+}
+
+// sortUsers applies the filter's sort options; list comes sorted by id, so
+// ties stay in id order and pages are stable
+func sortUsers(list []*domainUser.User, filter domainUser.ListFilter) {
+	sorting.Sort(list, sorting.Resolve(filter.SortBy, filter.Order, userComparators, "id"), userComparators)
+}
+
+// invalidateUserCache deletes all cached data for a user
+func (r *userRepository) invalidateUserCache(ctx context.Context, userID domainUser.UserID) {
+	if err := r.cache.InvalidateTags(ctx, cache.UserTag(string(userID))); err != nil {
+		// The write stands; the entries expire with their TTL
+		r.logger.Warn("Failed to invalidate user cache", slog.String("error", err.Error()), slog.String("user_id", string(userID)))
+	}
+}
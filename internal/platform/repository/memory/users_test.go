@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestUserRepository_CreateAndFind(t *testing.T) {
+	ctx := context.Background()
+	mockCache := new(cache.MockCache)
+	mockCache.On("InvalidateTags", mock.Anything, []string{cache.UserTag("u1")}).Return(nil)
+	repo := NewUserRepository(NewStore(), mockCache, testLogger)
+
+	_, err := repo.Create(ctx, &users.User{ID: "u1", Email: "jane@example.com", Role: users.RoleUser})
+	require.NoError(t, err)
+	mockCache.AssertExpectations(t)
+
+	user, err := repo.FindByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, users.ContentModeStandard, user.ContentMode)
+
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	user, err = repo.FindByEmail(ctx, "nobody@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+
+	_, err = repo.Create(ctx, &users.User{ID: "u2", Email: "jane@example.com"})
+	assert.ErrorIs(t, err, users.ErrUserAlreadyExists)
+
+	_, err = repo.Update(ctx, &users.User{ID: "missing", Email: "x@example.com"})
+	assert.ErrorIs(t, err, users.ErrUserNotFound)
+}
+
+func TestUserRepository_UpdateAvatar(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(NewStore(), cache.NewNoOpCache(), testLogger)
+	_, err := repo.Create(ctx, &users.User{ID: "u1", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	first := "avatars/u1/1"
+	previous, err := repo.UpdateAvatar(ctx, "u1", &first, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, previous)
+
+	previous, err = repo.UpdateAvatar(ctx, "u1", nil, time.Now())
+	require.NoError(t, err)
+	require.NotNil(t, previous)
+	assert.Equal(t, first, *previous)
+}
+
+func TestUserRepository_ListAndBulkUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(NewStore(), cache.NewNoOpCache(), testLogger)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []users.UserID{"u1", "u2", "u3"} {
+		_, err := repo.Create(ctx, &users.User{
+			ID:        id,
+			Email:     string(id) + "@example.com",
+			LastName:  []string{"Smith", "Adams", "Smith"}[i],
+			Role:      users.RoleUser,
+			IsActive:  true,
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	results, err := repo.BulkUpdate(ctx, users.BulkUpdate{
+		Action:  users.BulkDeactivate,
+		UserIDs: []users.UserID{"u2", "missing"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []users.BulkResult{
+		{UserID: "u2", Status: users.BulkUpdated},
+		{UserID: "missing", Status: users.BulkNotFound},
+	}, results)
+
+	active := true
+	list, total, err := repo.List(ctx, users.ListFilter{IsActive: &active, SortBy: "last_name", Order: "desc"}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, list, 2)
+	assert.Equal(t, users.UserID("u1"), list[0].ID)
+	assert.Equal(t, users.UserID("u3"), list[1].ID)
+
+	list, total, err = repo.List(ctx, users.ListFilter{}, 3, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	assert.Zero(t, total)
+
+	count, err := repo.Count(ctx, users.ListFilter{CreatedAfter: &base})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestUserRepository_CacheFailureIsLogged(t *testing.T) {
+	var logs bytes.Buffer
+	mockCache := new(cache.MockCache)
+	mockCache.On("InvalidateTags", mock.Anything, []string{cache.UserTag("u1")}).Return(errors.New("cache unavailable"))
+	repo := NewUserRepository(NewStore(), mockCache, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	_, err := repo.Create(context.Background(), &users.User{ID: "u1", Email: "jane@example.com", Role: users.RoleUser})
+
+	require.NoError(t, err, "a failed invalidation does not fail the write")
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), `msg="Failed to invalidate user cache"`)
+	assert.Contains(t, logs.String(), "user_id=u1")
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type mergeRepository struct {
+	db *sqlx.DB
+}
+
+func NewMergeRepository(db *sqlx.DB) movies.MergeRepository {
+	return &mergeRepository{db: db}
+}
+
+func (m *mergeRepository) Merge(ctx context.Context, sourceID, targetID movies.MovieID, mergedBy string, mergedAt time.Time) (*movies.MergeRecord, error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin merge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both movies so concurrent merges of the same pair serialize
+	var locked int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM (SELECT id FROM movies WHERE id IN ($1, $2) ORDER BY id FOR UPDATE) l`,
+		sourceID, targetID,
+	).Scan(&locked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock movies: %w", err)
+	}
+	if locked != 2 {
+		return nil, fmt.Errorf("movie %s or %s not found", sourceID, targetID)
+	}
+
+	record := &movies.MergeRecord{SourceID: sourceID, TargetID: targetID, MergedBy: mergedBy, MergedAt: mergedAt}
+
+	if record.RatingsDropped, err = resolveRatingConflicts(ctx, tx, "movie_id", string(sourceID), string(targetID)); err != nil {
+		return nil, err
+	}
+	if record.RatingsMoved, err = moveRatings(ctx, tx, "movie_id", string(sourceID), string(targetID)); err != nil {
+		return nil, err
+	}
+
+	// Fill metadata the target is missing from the source
+	_, err = tx.ExecContext(ctx, `
+		UPDATE movies t SET
+			description = CASE WHEN COALESCE(t.description, '') = '' THEN s.description ELSE t.description END,
+			rating = COALESCE(t.rating, s.rating),
+			-- Amounts are only taken from a source in another currency when the
+			-- target has none, so budget and revenue keep sharing one currency
+			budget = CASE
+				WHEN t.currency = s.currency THEN COALESCE(t.budget, s.budget)
+				WHEN t.budget IS NULL AND t.revenue IS NULL THEN s.budget
+				ELSE t.budget END,
+			revenue = CASE
+				WHEN t.currency = s.currency THEN COALESCE(t.revenue, s.revenue)
+				WHEN t.budget IS NULL AND t.revenue IS NULL THEN s.revenue
+				ELSE t.revenue END,
+			currency = CASE WHEN t.budget IS NULL AND t.revenue IS NULL THEN s.currency ELSE t.currency END,
+			imdb_id = COALESCE(t.imdb_id, s.imdb_id),
+			poster_url = COALESCE(t.poster_url, s.poster_url),
+			updated_at = $3
+		FROM movies s
+		WHERE t.id = $2 AND s.id = $1`,
+		sourceID, targetID, mergedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge movie metadata: %w", err)
+	}
+
+	// Copy the remaining references; rows the target already has win, and the
+	// source's rows go with it when it is deleted below
+	copies := []struct{ name, query string }{
+		{"credits", `
+			INSERT INTO movie_credits (movie_id, person_id, role, character_name, billing_order, created_at)
+			SELECT $2, person_id, role, character_name, billing_order, created_at
+			FROM movie_credits WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"translations", `
+			INSERT INTO movie_translations (movie_id, locale, title, description, created_at, updated_at)
+			SELECT $2, locale, title, description, created_at, updated_at
+			FROM movie_translations WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"releases", `
+			INSERT INTO movie_releases (movie_id, region, type, release_date, created_at, updated_at)
+			SELECT $2, region, type, release_date, created_at, updated_at
+			FROM movie_releases WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"certifications", `
+			INSERT INTO movie_certifications (movie_id, territory, certification, updated_at)
+			SELECT $2, territory, certification, updated_at
+			FROM movie_certifications WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"provider offers", `
+			INSERT INTO movie_providers (movie_id, region, provider_id, type, url, created_at, updated_at)
+			SELECT $2, region, provider_id, type, url, created_at, updated_at
+			FROM movie_providers WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"collection entries", `
+			INSERT INTO collection_movies (collection_id, movie_id, position, added_at)
+			SELECT collection_id, $2, position, added_at
+			FROM collection_movies WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"list entries", `
+			INSERT INTO list_movies (list_id, movie_id, position, added_at)
+			SELECT list_id, $2, position, added_at
+			FROM list_movies WHERE movie_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"slugs", `
+			UPDATE movie_slugs SET movie_id = $2, is_current = FALSE WHERE movie_id = $1`},
+		{"redirects", `
+			UPDATE movie_redirects SET new_movie_id = $2 WHERE new_movie_id = $1`},
+	}
+	for _, c := range copies {
+		if _, err := tx.ExecContext(ctx, c.query, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", c.name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, syncReleaseYear, targetID); err != nil {
+		return nil, fmt.Errorf("failed to update release year: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO movie_merges (source_movie_id, target_movie_id, merged_by, ratings_moved, ratings_dropped, source_snapshot, merged_at)
+		SELECT $1, $2, $3, $4, $5, to_jsonb(m), $6 FROM movies m WHERE m.id = $1
+		RETURNING id`,
+		sourceID, targetID, mergedBy, record.RatingsMoved, record.RatingsDropped, mergedAt,
+	).Scan(&record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record merge: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM movies WHERE id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged movie: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO movie_redirects (old_movie_id, new_movie_id, created_at) VALUES ($1, $2, $3)`,
+		sourceID, targetID, mergedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record redirect: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	return record, nil
+}
+
+// resolveRatingConflicts keeps only the newer of two ratings that would
+// clash once column, movie_id or user_id, is moved from sourceID to
+// targetID: those of a user who rated both movies, or of a movie both users
+// rated. It returns how many ratings were dropped. Deleted ratings never
+// conflict, so they are moved along with the rest.
+func resolveRatingConflicts(ctx context.Context, tx *sqlx.Tx, column, sourceID, targetID string) (int64, error) {
+	shared := "user_id"
+	if column == "user_id" {
+		shared = "movie_id"
+	}
+	var dropped int64
+	queries := []string{
+		`DELETE FROM ratings t USING ratings s
+		 WHERE t.` + column + ` = $2 AND s.` + column + ` = $1 AND s.` + shared + ` = t.` + shared + ` AND s.updated_at > t.updated_at
+		   AND s.deleted_at IS NULL AND t.deleted_at IS NULL`,
+		`DELETE FROM ratings s USING ratings t
+		 WHERE s.` + column + ` = $1 AND t.` + column + ` = $2 AND s.` + shared + ` = t.` + shared + `
+		   AND s.deleted_at IS NULL AND t.deleted_at IS NULL`,
+	}
+	for _, query := range queries {
+		result, err := tx.ExecContext(ctx, query, sourceID, targetID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve rating conflicts: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		dropped += n
+	}
+	return dropped, nil
+}
+
+// moveRatings reassigns the ratings whose column, movie_id or user_id, is
+// sourceID to targetID. Rows are deleted and re-inserted rather than
+// updated so the updated_at trigger does not rewrite their timestamps;
+// versions are bumped so stale If-Match headers on moved ratings no longer
+// apply.
+func moveRatings(ctx context.Context, tx *sqlx.Tx, column, sourceID, targetID string) (int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM ratings WHERE `+column+` = $1
+		RETURNING id, user_id, movie_id, score, COALESCE(review, ''), contains_spoilers, contains_adult_language, created_at, updated_at, version, deleted_at`, sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to detach ratings: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		ids, userIDs, movieIDs []string
+		reviews                []string
+		scores, versions       []int64
+		spoilers, adult        []bool
+		createdAts, updatedAts []string
+		deletedAts             []sql.NullString
+	)
+	for rows.Next() {
+		var (
+			id, userID, movieID  string
+			review               string
+			score, version       int64
+			containsSpoilers     bool
+			adultLanguage        bool
+			createdAt, updatedAt time.Time
+			deletedAt            sql.NullTime
+		)
+		if err := rows.Scan(&id, &userID, &movieID, &score, &review, &containsSpoilers, &adultLanguage, &createdAt, &updatedAt, &version, &deletedAt); err != nil {
+			return 0, fmt.Errorf("failed to scan rating: %w", err)
+		}
+		if column == "user_id" {
+			userID = targetID
+		} else {
+			movieID = targetID
+		}
+		ids = append(ids, strings.TrimSpace(id))
+		userIDs = append(userIDs, strings.TrimSpace(userID))
+		movieIDs = append(movieIDs, strings.TrimSpace(movieID))
+		scores = append(scores, score)
+		versions = append(versions, version+1)
+		reviews = append(reviews, review)
+		spoilers = append(spoilers, containsSpoilers)
+		adult = append(adult, adultLanguage)
+		createdAts = append(createdAts, createdAt.Format(time.RFC3339Nano))
+		updatedAts = append(updatedAts, updatedAt.Format(time.RFC3339Nano))
+		deletedAts = append(deletedAts, sql.NullString{String: deletedAt.Time.Format(time.RFC3339Nano), Valid: deletedAt.Valid})
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating ratings: %w", err)
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO ratings (id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version, deleted_at)
+		SELECT r.id, r.user_id, r.movie_id, r.score, NULLIF(r.review, ''), r.contains_spoilers, r.contains_adult_language, r.created_at, r.updated_at, r.version, r.deleted_at
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::int[], $5::text[], $6::bool[], $7::bool[], $8::timestamptz[], $9::timestamptz[], $10::int[], $11::timestamptz[])
+			AS r(id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version, deleted_at)`,
+		pq.Array(ids), pq.Array(userIDs), pq.Array(movieIDs), pq.Array(scores), pq.Array(reviews), pq.Array(spoilers), pq.Array(adult),
+		pq.Array(createdAts), pq.Array(updatedAts), pq.Array(versions), pq.Array(deletedAts),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reattach ratings: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (m *mergeRepository) ResolveRedirect(ctx context.Context, id movies.MovieID) (movies.MovieID, error) {
+	var newID string
+	err := m.db.QueryRowContext(ctx, `SELECT new_movie_id FROM movie_redirects WHERE old_movie_id = $1`, id).Scan(&newID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("redirect for movie %s not found", id)
+		}
+		return "", fmt.Errorf("failed to resolve movie redirect: %w", err)
+	}
+
+	return movies.MovieID(strings.TrimSpace(newID)), nil
+}
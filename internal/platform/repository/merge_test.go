@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewMergeRepository(db)
+	movieRepo := NewMovieRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`TRUNCATE TABLE movie_merges, movie_redirects`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, imdb_id, created_at, updated_at)
+		VALUES ('test-id-merge-source', 'Se7en', 'A detective thriller', 1995, 'Thriller', 'David Fincher', 127, 'R', 'English', 'USA', 'tt0114369', NOW(), NOW()),
+			   ('test-id-merge-target', 'Seven', '', 1995, 'Thriller', 'David Fincher', 127, 'R', 'English', 'USA', NULL, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	for _, u := range []string{"user-id-merge-1", "user-id-merge-2", "user-id-merge-3"} {
+		_, err := db.Exec(`
+			INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+			VALUES ($1, $1 || '@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+		`, u)
+		require.NoError(t, err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	recent := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-merge-1', 'user-id-merge-1', 'test-id-merge-source', 5, $1, $2),
+			('rating-merge-2', 'user-id-merge-1', 'test-id-merge-target', 2, $1, $1),
+			('rating-merge-3', 'user-id-merge-2', 'test-id-merge-source', 3, $1, $1),
+			('rating-merge-4', 'user-id-merge-2', 'test-id-merge-target', 4, $1, $2),
+			('rating-merge-5', 'user-id-merge-3', 'test-id-merge-source', 1, $1, $1)
+	`, old, recent)
+	require.NoError(t, err)
+
+	record, err := repo.Merge(ctx, "test-id-merge-source", "test-id-merge-target", "admin-1", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), record.RatingsMoved)
+	assert.Equal(t, int64(2), record.RatingsDropped)
+
+	// The newer rating of each user wins and moved ratings keep their timestamps
+	var scores []int
+	require.NoError(t, db.Select(&scores, `SELECT score FROM ratings WHERE movie_id = 'test-id-merge-target' ORDER BY user_id`))
+	assert.Equal(t, []int{5, 4, 1}, scores)
+	var updatedAt time.Time
+	require.NoError(t, db.Get(&updatedAt, `SELECT updated_at FROM ratings WHERE id = 'rating-merge-5'`))
+	assert.WithinDuration(t, old, updatedAt, time.Second)
+
+	target, err := movieRepo.GetByID(ctx, "test-id-merge-target")
+	require.NoError(t, err)
+	assert.Equal(t, "A detective thriller", target.Description)
+	require.NotNil(t, target.IMDbID)
+	assert.Equal(t, "tt0114369", *target.IMDbID)
+
+	exists, err := movieRepo.Exists(ctx, "test-id-merge-source")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	resolved, err := repo.ResolveRedirect(ctx, "test-id-merge-source")
+	require.NoError(t, err)
+	assert.Equal(t, movies.MovieID("test-id-merge-target"), resolved)
+
+	_, err = repo.Merge(ctx, "test-id-merge-source", "test-id-merge-target", "admin-1", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
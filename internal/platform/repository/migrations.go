@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations built into the binary, so they
+// can be applied without the source tree at hand
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+//go:embed sqlite_migrations/*.sql
+var sqliteMigrationFiles embed.FS
+
+// SQLiteMigrations returns the schema for STORAGE=sqlite, which covers the
+// movies, users and ratings tables
+func SQLiteMigrations() fs.FS {
+	sub, err := fs.Sub(sqliteMigrationFiles, "sqlite_migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
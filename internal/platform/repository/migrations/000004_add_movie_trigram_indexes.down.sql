@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_movies_director_trgm;
+DROP INDEX IF EXISTS idx_movies_title_trgm;
@@ -0,0 +1,6 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Back the /movies/suggest autocomplete: trigram GIN indexes serve both
+-- ILIKE 'prefix%' and similarity (%) lookups on title and director
+CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING gin (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_movies_director_trgm ON movies USING gin (director gin_trgm_ops);
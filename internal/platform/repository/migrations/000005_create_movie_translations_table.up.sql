@@ -0,0 +1,15 @@
+CREATE TABLE movie_translations (
+    movie_id CHAR(26) NOT NULL,
+    locale VARCHAR(16) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, locale),
+
+    CONSTRAINT fk_movie_translations_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_translation_title_not_empty CHECK (TRIM(title) != '')
+);
+
+CREATE INDEX idx_movie_translations_locale ON movie_translations (locale);
@@ -0,0 +1,43 @@
+CREATE TABLE people (
+    id CHAR(26) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+
+    CONSTRAINT chk_person_name_not_empty CHECK (TRIM(name) != '')
+);
+
+CREATE INDEX idx_people_name_lower ON people (LOWER(name));
+
+CREATE TABLE movie_credits (
+    movie_id CHAR(26) NOT NULL,
+    person_id CHAR(26) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    character_name VARCHAR(255),
+    billing_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, person_id, role),
+
+    CONSTRAINT fk_movie_credits_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_movie_credits_person FOREIGN KEY (person_id) REFERENCES people(id) ON DELETE CASCADE,
+    CONSTRAINT chk_credit_role_valid CHECK (role IN ('director', 'actor', 'writer', 'composer')),
+    CONSTRAINT chk_billing_order_non_negative CHECK (billing_order >= 0)
+);
+
+CREATE INDEX idx_movie_credits_person ON movie_credits (person_id, role);
+
+-- Migrate the existing director strings: one person per distinct name
+-- (case-insensitive), with a deterministic ID so the migration is repeatable
+INSERT INTO people (id, name)
+SELECT UPPER(SUBSTRING(md5('person:' || LOWER(TRIM(director))) FROM 1 FOR 26)), MIN(TRIM(director))
+FROM movies
+GROUP BY LOWER(TRIM(director))
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO movie_credits (movie_id, person_id, role)
+SELECT id, UPPER(SUBSTRING(md5('person:' || LOWER(TRIM(director))) FROM 1 FOR 26)), 'director'
+FROM movies
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,27 @@
+CREATE TABLE collections (
+    id CHAR(26) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+
+    CONSTRAINT chk_collection_name_not_empty CHECK (TRIM(name) != '')
+);
+
+CREATE TABLE collection_movies (
+    collection_id CHAR(26) NOT NULL,
+    movie_id CHAR(26) NOT NULL,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (collection_id, movie_id),
+
+    CONSTRAINT fk_collection_movies_collection FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+    CONSTRAINT fk_collection_movies_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_collection_position_positive CHECK (position > 0)
+);
+
+CREATE INDEX idx_collection_movies_order ON collection_movies (collection_id, position);
+CREATE INDEX idx_collection_movies_movie ON collection_movies (movie_id);
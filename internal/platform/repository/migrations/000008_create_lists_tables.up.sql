@@ -0,0 +1,35 @@
+CREATE TABLE lists (
+    id CHAR(26) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'private',
+    share_slug VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (id),
+
+    CONSTRAINT fk_lists_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_lists_share_slug UNIQUE (share_slug),
+    CONSTRAINT chk_list_name_not_empty CHECK (TRIM(name) != ''),
+    CONSTRAINT chk_list_visibility CHECK (visibility IN ('public', 'private'))
+);
+
+CREATE INDEX idx_lists_user ON lists (user_id, created_at DESC);
+
+CREATE TABLE list_movies (
+    list_id CHAR(26) NOT NULL,
+    movie_id CHAR(26) NOT NULL,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (list_id, movie_id),
+
+    CONSTRAINT fk_list_movies_list FOREIGN KEY (list_id) REFERENCES lists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_list_movies_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_list_position_positive CHECK (position > 0)
+);
+
+CREATE INDEX idx_list_movies_order ON list_movies (list_id, position);
+CREATE INDEX idx_list_movies_movie ON list_movies (movie_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_movies_imdb_id;
+DROP INDEX IF EXISTS idx_movies_normalized_title;
@@ -0,0 +1,6 @@
+-- Supports duplicate detection on create; the expression must match
+-- movies.NormalizeTitle and the query in FindPotentialDuplicates
+CREATE INDEX IF NOT EXISTS idx_movies_normalized_title
+    ON movies (regexp_replace(lower(title), '[^a-z0-9]+', '', 'g'), release_year);
+
+CREATE INDEX IF NOT EXISTS idx_movies_imdb_id ON movies (imdb_id) WHERE imdb_id IS NOT NULL;
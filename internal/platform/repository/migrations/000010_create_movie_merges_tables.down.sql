@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS movie_redirects;
+DROP TABLE IF EXISTS movie_merges;
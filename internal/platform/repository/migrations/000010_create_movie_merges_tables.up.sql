@@ -0,0 +1,29 @@
+-- Audit trail of merged duplicates. No foreign keys: the source movie is
+-- deleted by the merge and the record must outlive the target too.
+CREATE TABLE movie_merges (
+    id BIGSERIAL PRIMARY KEY,
+    source_movie_id CHAR(26) NOT NULL,
+    target_movie_id CHAR(26) NOT NULL,
+    merged_by VARCHAR(36) NOT NULL,
+    ratings_moved INTEGER NOT NULL DEFAULT 0,
+    ratings_dropped INTEGER NOT NULL DEFAULT 0,
+    source_snapshot JSONB NOT NULL,
+    merged_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_movie_merges_source ON movie_merges (source_movie_id);
+CREATE INDEX idx_movie_merges_target ON movie_merges (target_movie_id);
+
+-- Old IDs keep resolving after a merge. Redirects always point at a live
+-- movie: chains are collapsed when a target is itself merged.
+CREATE TABLE movie_redirects (
+    old_movie_id CHAR(26) NOT NULL,
+    new_movie_id CHAR(26) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (old_movie_id),
+
+    CONSTRAINT fk_movie_redirects_new_movie FOREIGN KEY (new_movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_movie_redirects_new_movie ON movie_redirects (new_movie_id);
@@ -0,0 +1,2 @@
+-- Optimistic concurrency for rating updates: UPDATE ... WHERE version = $n
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,17 @@
+-- One row per resized variant of a movie's current poster. The image bytes
+-- live in object storage under storage_key.
+CREATE TABLE movie_posters (
+    movie_id CHAR(26) NOT NULL,
+    variant VARCHAR(16) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, variant),
+
+    CONSTRAINT fk_movie_posters_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_movie_posters_variant CHECK (variant IN ('small', 'medium', 'large'))
+);
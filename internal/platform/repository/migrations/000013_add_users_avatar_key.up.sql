@@ -0,0 +1,2 @@
+-- Storage prefix of the user's current avatar; its sizes live under it
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key VARCHAR(255);
@@ -0,0 +1,5 @@
+-- Shadow-banned users keep seeing their own ratings, but those ratings are
+-- left out of public listings and every rating aggregate
+ALTER TABLE users ADD COLUMN IF NOT EXISTS shadow_banned BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_shadow_banned ON users (id) WHERE shadow_banned;
@@ -0,0 +1,5 @@
+-- Inputs to the rating trust score besides account age and rating count.
+-- email_verified is set by email verification, report_count counts upheld
+-- abuse reports against the user.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS report_count INTEGER NOT NULL DEFAULT 0;
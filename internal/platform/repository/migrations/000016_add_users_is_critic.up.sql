@@ -0,0 +1,4 @@
+-- Verified critics; their ratings form the separate critic score
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_critic BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_users_is_critic ON users (id) WHERE is_critic;
@@ -0,0 +1,2 @@
+-- Reviews that give away the plot; listings can hide them
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS contains_spoilers BOOLEAN NOT NULL DEFAULT FALSE;
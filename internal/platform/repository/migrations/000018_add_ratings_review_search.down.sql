@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_ratings_review_tsv;
+ALTER TABLE ratings DROP COLUMN IF EXISTS review_tsv;
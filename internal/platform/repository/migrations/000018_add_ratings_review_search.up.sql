@@ -0,0 +1,5 @@
+-- Full-text search over review text
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS review_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', COALESCE(review, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_ratings_review_tsv ON ratings USING GIN (review_tsv);
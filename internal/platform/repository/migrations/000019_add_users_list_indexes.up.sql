@@ -0,0 +1,3 @@
+-- Email prefix search and newest-first listing of users
+CREATE INDEX IF NOT EXISTS idx_users_email_prefix ON users (LOWER(email) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
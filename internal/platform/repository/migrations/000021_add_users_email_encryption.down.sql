@@ -0,0 +1,9 @@
+-- Encrypted emails must be decrypted before rolling back, or restoring the
+-- format check and column length fails
+DROP INDEX IF EXISTS idx_users_email_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS email_hash;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_email_format;
+ALTER TABLE users ADD CONSTRAINT chk_email_format CHECK (
+    email ~* '^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$'
+);
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
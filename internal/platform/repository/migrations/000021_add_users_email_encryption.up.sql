@@ -0,0 +1,11 @@
+-- Emails may be stored encrypted by the application (enc:v1:...), which is
+-- longer than the old limit and no longer looks like an address. email_hash
+-- is a keyed blind index of the lowercased address used for lookups and to
+-- keep encrypted emails unique.
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_email_format;
+ALTER TABLE users ADD CONSTRAINT chk_email_format CHECK (
+    email LIKE 'enc:v1:%' OR email ~* '^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$'
+);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_hash VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_hash ON users (email_hash);
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS rating_totals_banned_user_delete ON users;
+DROP TRIGGER IF EXISTS rating_totals_shadow_bans ON users;
+DROP TRIGGER IF EXISTS rating_totals_truncate ON ratings;
+DROP TRIGGER IF EXISTS rating_totals_update ON ratings;
+DROP TRIGGER IF EXISTS rating_totals_delete ON ratings;
+DROP TRIGGER IF EXISTS rating_totals_insert ON ratings;
+DROP FUNCTION IF EXISTS rating_totals_before_banned_user_delete();
+DROP FUNCTION IF EXISTS rating_totals_apply_shadow_bans();
+DROP FUNCTION IF EXISTS rating_totals_reset();
+DROP FUNCTION IF EXISTS rating_totals_apply_update();
+DROP FUNCTION IF EXISTS rating_totals_remove_old();
+DROP FUNCTION IF EXISTS rating_totals_add_new();
+DROP TABLE IF EXISTS rating_totals;
@@ -0,0 +1,161 @@
+-- Running totals of visible ratings behind the global average, so it is
+-- read from one row instead of averaging every rating. Triggers keep the
+-- totals current on rating writes and shadow bans; the rating service also
+-- recomputes them from scratch on a schedule to correct any drift. Every
+-- rating write updates this row, so concurrent writes queue on its lock
+-- until they commit.
+CREATE TABLE IF NOT EXISTS rating_totals (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    score_sum BIGINT NOT NULL DEFAULT 0,
+    rating_count BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO rating_totals (score_sum, rating_count)
+SELECT COALESCE(SUM(r.score), 0), COUNT(*)
+FROM ratings r
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = r.user_id AND u.shadow_banned)
+ON CONFLICT (id) DO NOTHING;
+
+-- Statement-level triggers see all rows a statement changed at once, so
+-- bulk writes such as movie merges update the totals once
+CREATE OR REPLACE FUNCTION rating_totals_add_new()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(n.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM new_ratings n
+        WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_remove_old()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum - d.score_sum,
+        rating_count = t.rating_count - d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(o.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM old_ratings o
+        WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_apply_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(n.score - o.score), 0) AS score_sum, 0 AS rating_count
+        FROM new_ratings n
+        JOIN old_ratings o ON o.id = n.id
+        WHERE n.score <> o.score
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.score_sum <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_reset()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals SET score_sum = 0, rating_count = 0, refreshed_at = NOW(), updated_at = NOW();
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Banning or unbanning a user hides or shows all of their ratings
+CREATE OR REPLACE FUNCTION rating_totals_apply_shadow_bans()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(CASE WHEN n.shadow_banned THEN -r.score ELSE r.score END), 0) AS score_sum,
+               COALESCE(SUM(CASE WHEN n.shadow_banned THEN -1 ELSE 1 END), 0) AS rating_count
+        FROM new_users n
+        JOIN old_users o ON o.id = n.id AND o.shadow_banned <> n.shadow_banned
+        JOIN ratings r ON r.user_id = n.id
+    ) d
+    WHERE d.rating_count <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- When a shadow-banned user is deleted, their ratings are deleted by the
+-- cascade after the user row is gone and would be subtracted as visible.
+-- Count them back in first so the cascade evens out.
+CREATE OR REPLACE FUNCTION rating_totals_before_banned_user_delete()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count
+    FROM (
+        SELECT COALESCE(SUM(score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM ratings WHERE user_id = OLD.id
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS rating_totals_insert ON ratings;
+CREATE TRIGGER rating_totals_insert
+    AFTER INSERT ON ratings
+    REFERENCING NEW TABLE AS new_ratings
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION rating_totals_add_new();
+
+DROP TRIGGER IF EXISTS rating_totals_delete ON ratings;
+CREATE TRIGGER rating_totals_delete
+    AFTER DELETE ON ratings
+    REFERENCING OLD TABLE AS old_ratings
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION rating_totals_remove_old();
+
+DROP TRIGGER IF EXISTS rating_totals_update ON ratings;
+CREATE TRIGGER rating_totals_update
+    AFTER UPDATE ON ratings
+    REFERENCING OLD TABLE AS old_ratings NEW TABLE AS new_ratings
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION rating_totals_apply_update();
+
+DROP TRIGGER IF EXISTS rating_totals_truncate ON ratings;
+CREATE TRIGGER rating_totals_truncate
+    AFTER TRUNCATE ON ratings
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION rating_totals_reset();
+
+DROP TRIGGER IF EXISTS rating_totals_shadow_bans ON users;
+CREATE TRIGGER rating_totals_shadow_bans
+    AFTER UPDATE ON users
+    REFERENCING OLD TABLE AS old_users NEW TABLE AS new_users
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION rating_totals_apply_shadow_bans();
+
+DROP TRIGGER IF EXISTS rating_totals_banned_user_delete ON users;
+CREATE TRIGGER rating_totals_banned_user_delete
+    BEFORE DELETE ON users
+    FOR EACH ROW
+    WHEN (OLD.shadow_banned)
+    EXECUTE FUNCTION rating_totals_before_banned_user_delete();
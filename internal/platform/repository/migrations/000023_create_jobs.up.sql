@@ -0,0 +1,29 @@
+-- Background jobs: imports, exports, recomputations. Workers claim queued
+-- jobs with FOR UPDATE SKIP LOCKED and heartbeat through updated_at.
+CREATE TABLE jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    payload JSONB NOT NULL DEFAULT '{}',
+    result JSONB,
+    progress DOUBLE PRECISION NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    cancel_requested BOOLEAN NOT NULL DEFAULT false,
+    created_by VARCHAR(36) NOT NULL DEFAULT '',
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT chk_jobs_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    CONSTRAINT chk_jobs_progress CHECK (progress >= 0 AND progress <= 100)
+);
+
+-- Claiming the next due job
+CREATE INDEX idx_jobs_queued ON jobs (run_at) WHERE status = 'queued';
+
+-- Finding running jobs whose worker stopped
+CREATE INDEX idx_jobs_running ON jobs (updated_at) WHERE status = 'running';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_finished_at;
+DROP INDEX IF EXISTS idx_movie_merges_merged_at;
+DROP INDEX IF EXISTS idx_user_sessions_revoked_at;
+DROP INDEX IF EXISTS idx_user_sessions_expires_at;
@@ -0,0 +1,8 @@
+-- Genres and decades a user picked during onboarding, so they get
+-- recommendations before they have rated much
+CREATE TABLE user_preferences (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    genres TEXT[] NOT NULL DEFAULT '{}',
+    decades INTEGER[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
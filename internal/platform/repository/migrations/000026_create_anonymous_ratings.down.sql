@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS anonymous_ratings;
+DROP TABLE IF EXISTS anonymous_principals;
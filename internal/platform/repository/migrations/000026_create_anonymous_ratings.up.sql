@@ -0,0 +1,20 @@
+-- Devices that rate without an account. Only a hash of the device token is
+-- kept; claimed_by is set once the ratings moved to a registered account.
+CREATE TABLE anonymous_principals (
+    id VARCHAR(36) PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    claimed_by VARCHAR(36) REFERENCES users(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Kept apart from ratings so throwaway devices don't sway public scores
+CREATE TABLE anonymous_ratings (
+    principal_id VARCHAR(36) NOT NULL REFERENCES anonymous_principals(id) ON DELETE CASCADE,
+    movie_id CHAR(26) NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score >= 1 AND score <= 5),
+    review TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (principal_id, movie_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS partner_usage;
+DROP TABLE IF EXISTS partner_api_keys;
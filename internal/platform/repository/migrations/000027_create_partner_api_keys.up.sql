@@ -0,0 +1,24 @@
+-- API keys of the partner API. Only a SHA-256 of each key is stored; prefix
+-- keeps its first characters to tell keys apart.
+CREATE TABLE partner_api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    rate_limit INTEGER NOT NULL DEFAULT 0 CHECK (rate_limit >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Requests per key, endpoint and UTC day, for billing
+CREATE TABLE partner_usage (
+    key_id VARCHAR(36) NOT NULL REFERENCES partner_api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    endpoint TEXT NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day, endpoint)
+);
+
+CREATE INDEX idx_partner_usage_day ON partner_usage (day);
@@ -0,0 +1,14 @@
+-- Requests and response bytes per principal, endpoint and UTC day. Counts
+-- are buffered in Redis and added here periodically. A principal is a user
+-- or a partner API key, so principal_id references neither table.
+CREATE TABLE usage_counters (
+    principal_kind VARCHAR(16) NOT NULL CHECK (principal_kind IN ('user', 'api_key')),
+    principal_id VARCHAR(64) NOT NULL,
+    day DATE NOT NULL,
+    endpoint TEXT NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (principal_kind, principal_id, day, endpoint)
+);
+
+CREATE INDEX idx_usage_counters_day ON usage_counters (day);
@@ -0,0 +1,3 @@
+-- Budget and revenue are minor units of this ISO 4217 currency; existing
+-- amounts were always recorded in US cents
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
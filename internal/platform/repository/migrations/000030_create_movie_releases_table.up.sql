@@ -0,0 +1,17 @@
+CREATE TABLE movie_releases (
+    movie_id CHAR(26) NOT NULL,
+    region CHAR(2) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    release_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, region, type),
+
+    CONSTRAINT fk_movie_releases_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_movie_releases_type CHECK (type IN ('theatrical', 'streaming'))
+);
+
+-- Release calendars list a region's releases by date
+CREATE INDEX idx_movie_releases_region_date ON movie_releases (region, release_date);
+CREATE INDEX idx_movie_releases_date ON movie_releases (release_date);
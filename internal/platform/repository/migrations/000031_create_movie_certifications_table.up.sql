@@ -0,0 +1,16 @@
+-- Age certifications per territory. The US (MPAA) certification stays in
+-- movies.rating so existing clients keep reading it there.
+CREATE TABLE movie_certifications (
+    movie_id CHAR(26) NOT NULL,
+    territory CHAR(2) NOT NULL,
+    certification VARCHAR(10) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, territory),
+
+    CONSTRAINT fk_movie_certifications_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT chk_movie_certifications_territory CHECK (territory <> 'US')
+);
+
+-- Searches filter a territory's movies by certification
+CREATE INDEX idx_movie_certifications_territory ON movie_certifications (territory, certification);
@@ -0,0 +1,4 @@
+-- Kids mode set on the profile applies on every device, whatever the
+-- X-Content-Mode header of a request says
+ALTER TABLE users ADD COLUMN IF NOT EXISTS content_mode VARCHAR(16) NOT NULL DEFAULT 'standard'
+    CONSTRAINT chk_users_content_mode CHECK (content_mode IN ('standard', 'kids'));
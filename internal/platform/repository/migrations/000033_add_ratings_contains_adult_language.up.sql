@@ -0,0 +1,2 @@
+-- Reviews with adult language; kids mode hides them
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS contains_adult_language BOOLEAN NOT NULL DEFAULT FALSE;
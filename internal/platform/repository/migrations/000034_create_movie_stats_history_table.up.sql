@@ -0,0 +1,14 @@
+CREATE TABLE movie_stats_history (
+    movie_id CHAR(26) NOT NULL,
+    snapshot_date DATE NOT NULL,
+    average_score DECIMAL(4,2) NOT NULL,
+    bayesian_average DECIMAL(4,2) NOT NULL,
+    total_ratings BIGINT NOT NULL,
+
+    PRIMARY KEY (movie_id, snapshot_date),
+
+    CONSTRAINT fk_movie_stats_history_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+-- Downsampling walks the old snapshots of every movie by date
+CREATE INDEX idx_movie_stats_history_date ON movie_stats_history (snapshot_date);
@@ -0,0 +1,14 @@
+-- Users who viewed each movie per UTC day. Views are buffered in Redis and
+-- added here periodically; each user counts once per movie and day.
+CREATE TABLE movie_daily_views (
+    movie_id CHAR(26) NOT NULL,
+    day DATE NOT NULL,
+    views BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (movie_id, day),
+
+    CONSTRAINT fk_movie_daily_views_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+-- The most viewed movies of a day
+CREATE INDEX idx_movie_daily_views_day ON movie_daily_views (day, views DESC);
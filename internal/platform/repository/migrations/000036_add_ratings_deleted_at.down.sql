@@ -0,0 +1,94 @@
+-- Deleted ratings would come back once the column is gone
+DELETE FROM ratings WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION rating_totals_add_new()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(n.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM new_ratings n
+        WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_remove_old()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum - d.score_sum,
+        rating_count = t.rating_count - d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(o.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM old_ratings o
+        WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_apply_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(n.score - o.score), 0) AS score_sum, 0 AS rating_count
+        FROM new_ratings n
+        JOIN old_ratings o ON o.id = n.id
+        WHERE n.score <> o.score
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.score_sum <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_apply_shadow_bans()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(CASE WHEN n.shadow_banned THEN -r.score ELSE r.score END), 0) AS score_sum,
+               COALESCE(SUM(CASE WHEN n.shadow_banned THEN -1 ELSE 1 END), 0) AS rating_count
+        FROM new_users n
+        JOIN old_users o ON o.id = n.id AND o.shadow_banned <> n.shadow_banned
+        JOIN ratings r ON r.user_id = n.id
+    ) d
+    WHERE d.rating_count <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_before_banned_user_delete()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count
+    FROM (
+        SELECT COALESCE(SUM(score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM ratings WHERE user_id = OLD.id
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_ratings_deleted_at;
+DROP INDEX IF EXISTS idx_ratings_user_movie_live;
+ALTER TABLE ratings ADD CONSTRAINT ratings_user_id_movie_id_key UNIQUE (user_id, movie_id);
+ALTER TABLE ratings DROP COLUMN IF EXISTS deleted_at;
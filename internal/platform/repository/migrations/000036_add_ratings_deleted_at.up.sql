@@ -0,0 +1,104 @@
+-- Deleting a rating hides it until the retention job purges it, so it can
+-- be restored in the meantime. Deleted ratings are left out of every
+-- listing and stat, and only live ratings are unique per user and movie.
+ALTER TABLE ratings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE ratings DROP CONSTRAINT IF EXISTS ratings_user_id_movie_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ratings_user_movie_live ON ratings (user_id, movie_id) WHERE deleted_at IS NULL;
+
+-- The deleted ratings the retention job purges
+CREATE INDEX IF NOT EXISTS idx_ratings_deleted_at ON ratings (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- The totals count live ratings only; deleting and restoring a rating is
+-- an update that takes it out of or puts it back into the totals
+CREATE OR REPLACE FUNCTION rating_totals_add_new()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(n.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM new_ratings n
+        WHERE n.deleted_at IS NULL
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_remove_old()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum - d.score_sum,
+        rating_count = t.rating_count - d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(o.score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM old_ratings o
+        WHERE o.deleted_at IS NULL
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_apply_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(CASE WHEN n.deleted_at IS NULL THEN n.score ELSE 0 END
+                          - CASE WHEN o.deleted_at IS NULL THEN o.score ELSE 0 END), 0) AS score_sum,
+               COALESCE(SUM(CASE WHEN n.deleted_at IS NULL THEN 1 ELSE 0 END
+                          - CASE WHEN o.deleted_at IS NULL THEN 1 ELSE 0 END), 0) AS rating_count
+        FROM new_ratings n
+        JOIN old_ratings o ON o.id = n.id
+        WHERE (n.score <> o.score OR (n.deleted_at IS NULL) <> (o.deleted_at IS NULL))
+          AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = n.user_id AND u.shadow_banned)
+    ) d
+    WHERE d.score_sum <> 0 OR d.rating_count <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_apply_shadow_bans()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count,
+        updated_at = NOW()
+    FROM (
+        SELECT COALESCE(SUM(CASE WHEN n.shadow_banned THEN -r.score ELSE r.score END), 0) AS score_sum,
+               COALESCE(SUM(CASE WHEN n.shadow_banned THEN -1 ELSE 1 END), 0) AS rating_count
+        FROM new_users n
+        JOIN old_users o ON o.id = n.id AND o.shadow_banned <> n.shadow_banned
+        JOIN ratings r ON r.user_id = n.id AND r.deleted_at IS NULL
+    ) d
+    WHERE d.rating_count <> 0;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION rating_totals_before_banned_user_delete()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE rating_totals t SET
+        score_sum = t.score_sum + d.score_sum,
+        rating_count = t.rating_count + d.rating_count
+    FROM (
+        SELECT COALESCE(SUM(score), 0) AS score_sum, COUNT(*) AS rating_count
+        FROM ratings WHERE user_id = OLD.id AND deleted_at IS NULL
+    ) d
+    WHERE d.rating_count > 0;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
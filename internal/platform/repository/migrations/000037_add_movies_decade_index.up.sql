@@ -0,0 +1,3 @@
+-- Movies are counted and ranked per decade for era browsing; the
+-- expression must match decadeOf in the aggregate repository
+CREATE INDEX IF NOT EXISTS idx_movies_decade ON movies (((release_year / 10) * 10));
@@ -0,0 +1,15 @@
+-- Audit trail of accounts merged into another by their owner. No foreign
+-- keys: the source user is deleted by the merge and the record must outlive
+-- the target too.
+CREATE TABLE user_merges (
+    id BIGSERIAL PRIMARY KEY,
+    source_user_id VARCHAR(36) NOT NULL,
+    target_user_id VARCHAR(36) NOT NULL,
+    ratings_moved INTEGER NOT NULL DEFAULT 0,
+    ratings_dropped INTEGER NOT NULL DEFAULT 0,
+    identities_moved INTEGER NOT NULL DEFAULT 0,
+    merged_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_merges_source ON user_merges (source_user_id);
+CREATE INDEX idx_user_merges_target ON user_merges (target_user_id);
@@ -0,0 +1,8 @@
+-- Settings users set for how the app behaves for them, as one JSON document
+-- so a setting can be added without a migration. The content mode is kept
+-- on users, where requests are filtered by it.
+CREATE TABLE user_settings (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    settings JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,37 @@
+-- Streaming services and stores movies can be watched on, and where each
+-- movie is offered on them per region
+CREATE TABLE providers (
+    id VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO providers (id, name) VALUES
+    ('netflix', 'Netflix'),
+    ('prime-video', 'Amazon Prime Video'),
+    ('disney-plus', 'Disney+'),
+    ('apple-tv', 'Apple TV'),
+    ('max', 'Max'),
+    ('hulu', 'Hulu'),
+    ('paramount-plus', 'Paramount+'),
+    ('mubi', 'MUBI');
+
+CREATE TABLE movie_providers (
+    movie_id CHAR(26) NOT NULL,
+    region CHAR(2) NOT NULL,
+    provider_id VARCHAR(32) NOT NULL,
+    type VARCHAR(16) NOT NULL,
+    url TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (movie_id, region, provider_id, type),
+
+    CONSTRAINT fk_movie_providers_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE,
+    CONSTRAINT fk_movie_providers_provider FOREIGN KEY (provider_id) REFERENCES providers(id) ON DELETE CASCADE,
+    CONSTRAINT chk_movie_providers_type CHECK (type IN ('subscription', 'free', 'rent', 'buy'))
+);
+
+-- Searches keep the movies on a provider, optionally in one region
+CREATE INDEX idx_movie_providers_provider_region ON movie_providers (provider_id, region, movie_id);
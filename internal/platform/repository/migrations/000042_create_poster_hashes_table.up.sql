@@ -0,0 +1,11 @@
+-- The perceptual hash of each movie's current poster, to find movies that
+-- share their artwork: duplicate catalog entries or miscredited posters
+CREATE TABLE poster_hashes (
+    movie_id CHAR(26) PRIMARY KEY,
+    hash BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_poster_hashes_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_poster_hashes_hash ON poster_hashes (hash);
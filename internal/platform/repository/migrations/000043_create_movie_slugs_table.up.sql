@@ -0,0 +1,27 @@
+-- The URL-friendly names of movies. A movie has one current slug; the ones
+-- it had before keep pointing at it, so old links can be redirected.
+CREATE TABLE movie_slugs (
+    slug VARCHAR(100) PRIMARY KEY,
+    movie_id CHAR(26) NOT NULL,
+    is_current BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_movie_slugs_movie FOREIGN KEY (movie_id) REFERENCES movies(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_movie_slugs_current ON movie_slugs (movie_id) WHERE is_current;
+
+-- Slugs for the movies already in the catalog, like movies.Slug makes them:
+-- title and year, numbered from the second movie sharing them on
+INSERT INTO movie_slugs (slug, movie_id, is_current, created_at)
+SELECT CASE WHEN n = 1 THEN base ELSE base || '-' || n END, id, TRUE, NOW()
+FROM (
+    SELECT id, base, ROW_NUMBER() OVER (PARTITION BY base ORDER BY created_at, id) AS n
+    FROM (
+        SELECT id, created_at,
+               COALESCE(NULLIF(TRIM(BOTH '-' FROM LEFT(TRIM(BOTH '-' FROM REGEXP_REPLACE(
+                   TRANSLATE(LOWER(title), 'àáâãäåçèéêëìíîïñòóôõöøùúûüýÿ', 'aaaaaaceeeeiiiinoooooouuuuyy'),
+                   '[^a-z0-9]+', '-', 'g')), 80)), ''), 'movie') || '-' || release_year AS base
+        FROM movies
+    ) bases
+) numbered;
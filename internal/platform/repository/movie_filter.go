@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/postgres"
+)
+
+// movieFilterBuilder turns a movies.SearchFilter into a parameterised WHERE
+// clause. The same builder output is used for the page query and the count
+// query so that both always agree on the result set.
+//
+// SQLite databases hold no credits, certifications or provider offers, so
+// filtering by cast, by a provider or by a territory's certifications other
+// than the default matches nothing there.
+type movieFilterBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func newMovieFilterBuilder(dialect postgres.Dialect, filter movies.SearchFilter) *movieFilterBuilder {
+	b := &movieFilterBuilder{}
+
+	if filter.Query != "" {
+		b.add("title "+dialect.ILike()+" $%d"+dialect.LikeEscape(), "%"+strings.ToLower(escapeLike(filter.Query))+"%")
+	}
+	if filter.Genre != "" {
+		b.add("LOWER(genre) = LOWER($%d)", filter.Genre)
+	}
+	if filter.Director != "" {
+		b.add("LOWER(director) = LOWER($%d)", filter.Director)
+	}
+	if filter.MinYear != nil {
+		b.add("release_year >= $%d", *filter.MinYear)
+	}
+	if filter.MaxYear != nil {
+		b.add("release_year <= $%d", *filter.MaxYear)
+	}
+	if filter.Language != "" {
+		b.add("LOWER(language) = LOWER($%d)", filter.Language)
+	}
+	if filter.Country != "" {
+		b.add("LOWER(country) = LOWER($%d)", filter.Country)
+	}
+	if filter.MinDuration != nil {
+		b.add("duration_mins >= $%d", *filter.MinDuration)
+	}
+	if filter.Featuring != "" && dialect == postgres.DialectSQLite {
+		b.conditions = append(b.conditions, "FALSE")
+	} else if filter.Featuring != "" {
+		b.add(`EXISTS (
+			SELECT 1 FROM movie_credits mc
+			JOIN people p ON p.id = mc.person_id
+			WHERE mc.movie_id = movies.id AND LOWER(p.name) = LOWER($%d)
+		)`, filter.Featuring)
+	}
+	if filter.CertificationTerritory != "" && dialect == postgres.DialectSQLite {
+		condition := "FALSE"
+		if filter.CertificationTerritory == movies.DefaultTerritory {
+			condition, b.args = dialect.InList("movies.rating", filter.Certifications, b.args)
+		}
+		b.conditions = append(b.conditions, condition)
+	} else if filter.CertificationTerritory != "" {
+		var condition string
+		condition, b.args = certifiedCondition("movies", movies.ContentPolicy{
+			Territory:      filter.CertificationTerritory,
+			Certifications: filter.Certifications,
+		}, b.args)
+		b.conditions = append(b.conditions, condition)
+	}
+	if filter.Provider != "" && dialect == postgres.DialectSQLite {
+		b.conditions = append(b.conditions, "FALSE")
+	} else if filter.Provider != "" {
+		b.args = append(b.args, filter.Provider)
+		condition := fmt.Sprintf("mp.provider_id = $%d", len(b.args))
+		if filter.ProviderRegion != "" {
+			b.args = append(b.args, filter.ProviderRegion)
+			condition += fmt.Sprintf(" AND mp.region = $%d", len(b.args))
+		}
+		b.conditions = append(b.conditions, "EXISTS (SELECT 1 FROM movie_providers mp WHERE mp.movie_id = movies.id AND "+condition+")")
+	}
+	if filter.NotRatedBy != "" {
+		b.add("NOT EXISTS (SELECT 1 FROM ratings nr WHERE nr.movie_id = movies.id AND nr.user_id = $%d AND nr.deleted_at IS NULL)", filter.NotRatedBy)
+	}
+	if filter.MinBayesianRating != nil {
+		b.args = append(b.args, filter.BayesianConfidenceK)
+		k := dialect.Decimal(fmt.Sprintf("$%d", len(b.args)))
+		global := "(SELECT COALESCE(AVG(score), 0) FROM ratings WHERE " + visibleRating("ratings") + ")"
+		b.add(fmt.Sprintf(`(
+			SELECT %s
+			FROM ratings r
+			WHERE r.movie_id = movies.id AND %s
+		) >= $%%d`, bayesianAverage("COALESCE(AVG(r.score), 0)", "COUNT(*)", global, k), visibleRating("r")), *filter.MinBayesianRating)
+	}
+
+	return b
+}
+
+// add appends a condition whose single placeholder is rendered with the
+// position of value in the argument list
+func (b *movieFilterBuilder) add(condition string, value interface{}) {
+	b.args = append(b.args, value)
+	b.conditions = append(b.conditions, fmt.Sprintf(condition, len(b.args)))
+}
+
+// where returns the WHERE clause, or an empty string when nothing is filtered
+func (b *movieFilterBuilder) where() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// nextPlaceholder is the index the next appended argument will take
+func (b *movieFilterBuilder) nextPlaceholder() int {
+	return len(b.args) + 1
+}
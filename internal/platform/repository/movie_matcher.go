@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func NewMovieMatcher(db *sqlx.DB) movies.Matcher {
+	return &movieRepository{db: db}
+}
+
+func (m *movieRepository) GetByIDs(ctx context.Context, ids []movies.MovieID) (map[movies.MovieID]*movies.Movie, error) {
+	found := make(map[movies.MovieID]*movies.Movie)
+	if len(ids) == 0 {
+		return found, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = string(id)
+	}
+
+	query := `
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at
+		FROM movies
+		WHERE id = ANY($1)`
+
+	moviesList, err := m.queryMovies(ctx, query, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	for _, movie := range moviesList {
+		found[movie.ID] = movie
+	}
+	return found, nil
+}
+
+func (m *movieRepository) GetByIMDbIDs(ctx context.Context, imdbIDs []string) (map[string]*movies.Movie, error) {
+	found := make(map[string]*movies.Movie)
+	if len(imdbIDs) == 0 {
+		return found, nil
+	}
+
+	query := `
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at
+		FROM movies
+		WHERE imdb_id = ANY($1)
+		ORDER BY created_at`
+
+	moviesList, err := m.queryMovies(ctx, query, pq.Array(imdbIDs))
+	if err != nil {
+		return nil, err
+	}
+	// Should a merge have left two movies with an IMDb ID, the older wins
+	for _, movie := range moviesList {
+		if _, ok := found[*movie.IMDbID]; !ok {
+			found[*movie.IMDbID] = movie
+		}
+	}
+	return found, nil
+}
+
+// MatchTitle finds titles that are equal once normalized, served by
+// idx_movies_normalized_title, or similar by trigrams, served by
+// idx_movies_title_trgm. Equal titles rank first with a similarity of 1.
+func (m *movieRepository) MatchTitle(ctx context.Context, title string, year int, limit int) ([]*movies.TitleMatch, error) {
+	normalized := movies.NormalizeTitle(title)
+	if normalized == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at,
+			   CASE WHEN regexp_replace(lower(title), '[^a-z0-9]+', '', 'g') = $2 THEN 1
+					ELSE similarity(title, $1) END AS similarity
+		FROM movies
+		WHERE (title % $1 OR regexp_replace(lower(title), '[^a-z0-9]+', '', 'g') = $2)
+		  AND ($3 = 0 OR release_year BETWEEN $3 - 1 AND $3 + 1)
+		ORDER BY similarity DESC, abs(release_year - $3), created_at
+		LIMIT $4`
+
+	rows, err := m.db.QueryContext(ctx, query, title, normalized, year, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match title: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*movies.TitleMatch
+	for rows.Next() {
+		movie := &movies.Movie{}
+		match := &movies.TitleMatch{Movie: movie}
+		var id string
+		err := rows.Scan(
+			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&match.Similarity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan title match: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(id))
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating title matches: %w", err)
+	}
+
+	return matches, nil
+}
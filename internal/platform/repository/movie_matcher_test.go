@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovieMatcher(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	matcher := NewMovieMatcher(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, imdb_id, created_at, updated_at)
+		VALUES ('test-id-match-1', 'The Thing', '', 1982, 'Horror', 'John Carpenter', 109, 'R', 'English', 'USA', 'tt0084787', NOW(), NOW()),
+			   ('test-id-match-2', 'The Thing', '', 2011, 'Horror', 'Matthijs van Heijningen Jr.', 103, 'R', 'English', 'USA', NULL, NOW(), NOW()),
+			   ('test-id-match-3', 'Things to Come', '', 1936, 'Sci-Fi', 'William Cameron Menzies', 100, 'G', 'English', 'UK', NULL, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	byID, err := matcher.GetByIDs(ctx, []movies.MovieID{"test-id-match-1", "test-id-missing"})
+	require.NoError(t, err)
+	require.Len(t, byID, 1)
+	assert.Equal(t, "The Thing", byID["test-id-match-1"].Title)
+
+	byIMDbID, err := matcher.GetByIMDbIDs(ctx, []string{"tt0084787", "tt0000000"})
+	require.NoError(t, err)
+	require.Len(t, byIMDbID, 1)
+	assert.Equal(t, movies.MovieID("test-id-match-1"), byIMDbID["tt0084787"].ID)
+
+	matches, err := matcher.MatchTitle(ctx, "the thing!", 1983, 5)
+	require.NoError(t, err)
+	require.Len(t, matches, 1, "the year keeps the remake out")
+	assert.Equal(t, movies.MovieID("test-id-match-1"), matches[0].Movie.ID)
+	assert.Equal(t, 1.0, matches[0].Similarity)
+
+	matches, err = matcher.MatchTitle(ctx, "The Thng", 0, 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "The Thing", matches[0].Movie.Title)
+	assert.Less(t, matches[0].Similarity, 1.0)
+}
@@ -4,19 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math/rand/v2"
 	"strings"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/postgres"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
 type movieRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	dialect postgres.Dialect
 }
 
 func NewMovieRepository(db *sqlx.DB) movies.Repository {
-	return &movieRepository{db: db}
+	return &movieRepository{db: db, dialect: postgres.DialectOf(db)}
 }
 
 func (m *movieRepository) GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error) {
@@ -27,11 +29,11 @@ func (m *movieRepository) GetAll(ctx context.Context, options ...movies.SearchOp
 
 	query := fmt.Sprintf(`
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies 
-		ORDER BY %s %s
-		LIMIT $1 OFFSET $2`, m.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		ORDER BY %s
+		LIMIT $1 OFFSET $2`, m.orderBy(opts))
 
 	return m.queryMovies(ctx, query, opts.Limit, opts.Offset)
 }
@@ -39,7 +41,7 @@ func (m *movieRepository) GetAll(ctx context.Context, options ...movies.SearchOp
 func (m *movieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
 	query := `
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies WHERE id = $1`
 
@@ -48,7 +50,7 @@ func (m *movieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movi
 	err := m.db.QueryRowContext(ctx, query, id).Scan(
 		&movieID, &movie.Title, &movie.Description, &movie.ReleaseYear,
 		&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
-		&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue,
+		&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
 		&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
 	)
 
@@ -67,10 +69,10 @@ func (m *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movie
 	query := `
 		INSERT INTO movies (
 			id, title, description, release_year, genre, director,
-			duration_mins, rating, language, country, budget, revenue,
+			duration_mins, rating, language, country, budget, revenue, currency,
 			imdb_id, poster_url, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17
 		) RETURNING id, created_at, updated_at`
 
 	var savedMovie movies.Movie = *movie
@@ -79,13 +81,13 @@ func (m *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movie
 		ctx, query,
 		movie.ID, movie.Title, movie.Description, movie.ReleaseYear,
 		movie.Genre, movie.Director, movie.DurationMins, movie.Rating,
-		movie.Language, movie.Country, movie.Budget, movie.Revenue,
+		movie.Language, movie.Country, movie.Budget, movie.Revenue, movie.Currency,
 		movie.IMDbID, movie.PosterURL, movie.CreatedAt, movie.UpdatedAt,
 	).Scan(&savedID, &savedMovie.CreatedAt, &savedMovie.UpdatedAt)
 
 	if err != nil {
 		// Check if the error is a unique constraint violation
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		if m.dialect.IsUniqueViolation(err) {
 			return nil, fmt.Errorf("movie with ID %s already exists", movie.ID)
 		}
 		return nil, fmt.Errorf("failed to save movie: %w", err)
@@ -95,6 +97,34 @@ func (m *movieRepository) Save(ctx context.Context, movie *movies.Movie) (*movie
 	return &savedMovie, nil
 }
 
+func (m *movieRepository) Update(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	query := `
+		UPDATE movies SET
+			title = $2, description = $3, release_year = $4, genre = $5, director = $6,
+			duration_mins = $7, rating = $8, language = $9, country = $10, budget = $11,
+			revenue = $12, currency = $13, imdb_id = $14, poster_url = $15, updated_at = $16
+		WHERE id = $1
+		RETURNING updated_at`
+
+	updatedMovie := *movie
+	err := m.db.QueryRowContext(
+		ctx, query,
+		movie.ID, movie.Title, movie.Description, movie.ReleaseYear,
+		movie.Genre, movie.Director, movie.DurationMins, movie.Rating,
+		movie.Language, movie.Country, movie.Budget, movie.Revenue, movie.Currency,
+		movie.IMDbID, movie.PosterURL, movie.UpdatedAt,
+	).Scan(&updatedMovie.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("movie with ID %s not found", movie.ID)
+		}
+		return nil, fmt.Errorf("failed to update movie: %w", err)
+	}
+
+	return &updatedMovie, nil
+}
+
 func (m *movieRepository) SearchByTitle(ctx context.Context, title string, options ...movies.SearchOption) ([]*movies.Movie, error) {
 	opts := movies.DefaultSearchOptions()
 	for _, option := range options {
@@ -103,12 +133,12 @@ func (m *movieRepository) SearchByTitle(ctx context.Context, title string, optio
 
 	query := fmt.Sprintf(`
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies 
-		WHERE title ILIKE $1
-		ORDER BY %s %s
-		LIMIT $2 OFFSET $3`, m.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		WHERE title %s $1
+		ORDER BY %s
+		LIMIT $2 OFFSET $3`, m.dialect.ILike(), m.orderBy(opts))
 
 	searchPattern := "%" + strings.ToLower(title) + "%"
 	return m.queryMovies(ctx, query, searchPattern, opts.Limit, opts.Offset)
@@ -122,12 +152,12 @@ func (m *movieRepository) GetByGenre(ctx context.Context, genre string, options
 
 	query := fmt.Sprintf(`
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies 
 		WHERE LOWER(genre) = LOWER($1)
-		ORDER BY %s %s
-		LIMIT $2 OFFSET $3`, m.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		ORDER BY %s
+		LIMIT $2 OFFSET $3`, m.orderBy(opts))
 
 	return m.queryMovies(ctx, query, genre, opts.Limit, opts.Offset)
 }
@@ -140,12 +170,12 @@ func (m *movieRepository) GetByDirector(ctx context.Context, director string, op
 
 	query := fmt.Sprintf(`
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies 
 		WHERE LOWER(director) = LOWER($1)
-		ORDER BY %s %s
-		LIMIT $2 OFFSET $3`, m.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		ORDER BY %s
+		LIMIT $2 OFFSET $3`, m.orderBy(opts))
 
 	return m.queryMovies(ctx, query, director, opts.Limit, opts.Offset)
 }
@@ -158,16 +188,241 @@ func (m *movieRepository) GetByYearRange(ctx context.Context, startYear, endYear
 
 	query := fmt.Sprintf(`
 		SELECT id, title, description, release_year, genre, director,
-			   duration_mins, rating, language, country, budget, revenue,
+			   duration_mins, rating, language, country, budget, revenue, currency,
 			   imdb_id, poster_url, created_at, updated_at
 		FROM movies 
 		WHERE release_year BETWEEN $1 AND $2
-		ORDER BY %s %s
-		LIMIT $3 OFFSET $4`, m.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		ORDER BY %s
+		LIMIT $3 OFFSET $4`, m.orderBy(opts))
 
 	return m.queryMovies(ctx, query, startYear, endYear, opts.Limit, opts.Offset)
 }
 
+// Search returns the page of movies matching every criterion in filter along
+// with the total number of matches, computed in the same statement with a
+// window count so the page and the total always agree. The total is 0 when
+// the page is empty; use CountSearch for pages past the end.
+func (m *movieRepository) Search(ctx context.Context, filter movies.SearchFilter, options ...movies.SearchOption) ([]*movies.Movie, int64, error) {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	builder := newMovieFilterBuilder(m.dialect, filter)
+	limitPos := builder.nextPlaceholder()
+
+	query := fmt.Sprintf(`
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at,
+			   COUNT(*) OVER() AS total_count
+		FROM movies
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`,
+		builder.where(), m.orderBy(opts),
+		limitPos, limitPos+1)
+
+	args := append(builder.args, opts.Limit, opts.Offset)
+	return m.queryMoviesWithTotal(ctx, query, args...)
+}
+
+// Random picks movies matching filter with ORDER BY random(), but only over
+// the IDs of the matches, which the primary key index serves, before the
+// picked rows are read. TABLESAMPLE would be cheaper still on a large table
+// but samples before filtering, so a narrow filter would mostly come back
+// empty. The picks are shuffled as IN does not keep the sampled order.
+func (m *movieRepository) Random(ctx context.Context, filter movies.SearchFilter, limit int) ([]*movies.Movie, error) {
+	builder := newMovieFilterBuilder(m.dialect, filter)
+
+	query := fmt.Sprintf(`
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at
+		FROM movies
+		WHERE id IN (
+			SELECT id FROM movies
+			%s
+			ORDER BY random()
+			LIMIT $%d
+		)`, builder.where(), builder.nextPlaceholder())
+
+	picked, err := m.queryMovies(ctx, query, append(builder.args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pick random movies: %w", err)
+	}
+	rand.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+	return picked, nil
+}
+
+// CountSearch counts all movies matching filter, ignoring pagination
+func (m *movieRepository) CountSearch(ctx context.Context, filter movies.SearchFilter) (int64, error) {
+	builder := newMovieFilterBuilder(m.dialect, filter)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM movies %s`, builder.where())
+
+	var count int64
+	err := m.db.QueryRowContext(ctx, query, builder.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count movies: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetSearchFacets counts the movies matching filter per genre, decade,
+// language and MPAA rating in a single GROUPING SETS aggregation. SQLite
+// has no grouping sets, so there each facet is grouped on its own and the
+// rows are flagged as GROUPING() would.
+func (m *movieRepository) GetSearchFacets(ctx context.Context, filter movies.SearchFilter) (*movies.SearchFacets, error) {
+	builder := newMovieFilterBuilder(m.dialect, filter)
+	query := fmt.Sprintf(`
+		SELECT genre, (release_year / 10) * 10 AS decade, language, rating,
+			   GROUPING(genre), GROUPING((release_year / 10) * 10), GROUPING(language), GROUPING(rating),
+			   COUNT(*)
+		FROM movies
+		%s
+		GROUP BY GROUPING SETS ((genre), ((release_year / 10) * 10), (language), (rating))
+		ORDER BY COUNT(*) DESC, 1, 2, 3, 4`, builder.where())
+	if m.dialect == postgres.DialectSQLite {
+		query = fmt.Sprintf(`
+			WITH matched AS (
+				SELECT genre, (release_year / 10) * 10 AS decade, language, rating
+				FROM movies
+				%s
+			)
+			SELECT genre, NULL, NULL, NULL, 0, 1, 1, 1, COUNT(*) FROM matched GROUP BY genre
+			UNION ALL
+			SELECT NULL, decade, NULL, NULL, 1, 0, 1, 1, COUNT(*) FROM matched GROUP BY decade
+			UNION ALL
+			SELECT NULL, NULL, language, NULL, 1, 1, 0, 1, COUNT(*) FROM matched GROUP BY language
+			UNION ALL
+			SELECT NULL, NULL, NULL, rating, 1, 1, 1, 0, COUNT(*) FROM matched GROUP BY rating
+			ORDER BY 9 DESC, 1, 2, 3, 4`, builder.where())
+	}
+
+	rows, err := m.db.QueryContext(ctx, query, builder.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query search facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := &movies.SearchFacets{
+		Genres:    []movies.FacetBucket{},
+		Decades:   []movies.FacetBucket{},
+		Languages: []movies.FacetBucket{},
+		Ratings:   []movies.FacetBucket{},
+	}
+	for rows.Next() {
+		var (
+			genre, language, rating                        sql.NullString
+			decade                                         sql.NullInt64
+			byGenre, byDecade, byLanguage, byRating, count int64
+		)
+		if err := rows.Scan(&genre, &decade, &language, &rating, &byGenre, &byDecade, &byLanguage, &byRating, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan search facet: %w", err)
+		}
+
+		// GROUPING(x) is 0 for the column the row is aggregated by
+		switch {
+		case byGenre == 0:
+			facets.Genres = append(facets.Genres, movies.FacetBucket{Value: genre.String, Count: count})
+		case byDecade == 0:
+			facets.Decades = append(facets.Decades, movies.FacetBucket{Value: fmt.Sprintf("%ds", decade.Int64), Count: count})
+		case byLanguage == 0:
+			facets.Languages = append(facets.Languages, movies.FacetBucket{Value: language.String, Count: count})
+		case byRating == 0:
+			facets.Ratings = append(facets.Ratings, movies.FacetBucket{Value: rating.String, Count: count})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search facets: %w", err)
+	}
+
+	return facets, nil
+}
+
+// Suggest matches titles and distinct directors by prefix or trigram
+// similarity (pg_trgm); both predicates are served by the trigram GIN indexes.
+// SQLite has no trigram index, so there every movie's similarity is computed.
+func (m *movieRepository) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	prefix := "%s " + m.dialect.ILike() + " $1" + m.dialect.LikeEscape()
+	similar, anyPrefix := "%s %% $2", "BOOL_OR(%s)"
+	if m.dialect == postgres.DialectSQLite {
+		// 0.3 is pg_trgm's default similarity threshold
+		similar, anyPrefix = "similarity(%s, $2) >= 0.3", "MAX(%s)"
+	}
+	titlePrefix, directorPrefix := fmt.Sprintf(prefix, "title"), fmt.Sprintf(prefix, "director")
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT kind, value, movie_id
+		FROM (
+			SELECT 'title' AS kind, title AS value, id AS movie_id,
+				   %[1]s AS is_prefix, similarity(title, $2) AS score
+			FROM movies
+			WHERE %[1]s OR %[2]s
+			UNION ALL
+			SELECT 'director', MIN(director), NULL,
+				   %[3]s, MAX(similarity(director, $2))
+			FROM movies
+			WHERE %[4]s OR %[5]s
+			GROUP BY LOWER(director)
+		) s
+		ORDER BY is_prefix DESC, score DESC, value
+		LIMIT $3`,
+		titlePrefix, fmt.Sprintf(similar, "title"),
+		fmt.Sprintf(anyPrefix, directorPrefix), directorPrefix, fmt.Sprintf(similar, "director"))
+
+	rows, err := m.db.QueryContext(ctx, sqlQuery, escapeLike(query)+"%", query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	var suggestions []*movies.Suggestion
+	for rows.Next() {
+		var (
+			kind, value string
+			movieID     sql.NullString
+		)
+		if err := rows.Scan(&kind, &value, &movieID); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion: %w", err)
+		}
+		suggestions = append(suggestions, &movies.Suggestion{
+			Kind:    movies.SuggestionKind(kind),
+			Text:    value,
+			MovieID: movies.MovieID(strings.TrimSpace(movieID.String)),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suggestions: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// maxDuplicateCandidates bounds the matches returned by FindPotentialDuplicates
+const maxDuplicateCandidates = 10
+
+// FindPotentialDuplicates matches by IMDb ID or by normalized title and
+// release year. The title expression mirrors movies.NormalizeTitle and is
+// served by idx_movies_normalized_title.
+func (m *movieRepository) FindPotentialDuplicates(ctx context.Context, movie *movies.Movie) ([]*movies.Movie, error) {
+	query := `
+		SELECT id, title, description, release_year, genre, director,
+			   duration_mins, rating, language, country, budget, revenue, currency,
+			   imdb_id, poster_url, created_at, updated_at
+		FROM movies
+		WHERE (imdb_id IS NOT NULL AND imdb_id = $1)
+		   OR ($2 <> '' AND release_year = $3
+			   AND regexp_replace(lower(title), '[^a-z0-9]+', '', 'g') = $2)
+		ORDER BY created_at
+		LIMIT $4`
+
+	return m.queryMovies(ctx, query, movie.IMDbID, movies.NormalizeTitle(movie.Title), movie.ReleaseYear, maxDuplicateCandidates)
+}
+
 func (m *movieRepository) Count(ctx context.Context) (int64, error) {
 	query := `SELECT COUNT(*) FROM movies`
 
@@ -206,7 +461,7 @@ func (m *movieRepository) ScanMovies(rows *sql.Rows) ([]*movies.Movie, error) {
 		err := rows.Scan(
 			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
 			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
-			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
 			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
 		)
 		if err != nil {
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/partners"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type partnerRepository struct {
+	db *sqlx.DB
+}
+
+func NewPartnerRepository(db *sqlx.DB) partners.Repository {
+	return &partnerRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, rate_limit, created_at, last_used_at, revoked_at`
+
+func (p *partnerRepository) CreateKey(ctx context.Context, key *partners.APIKey) error {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	query := `
+		INSERT INTO partner_api_keys (id, name, prefix, key_hash, scopes, rate_limit, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := p.db.ExecContext(ctx, query, key.ID, key.Name, key.Prefix, key.Hash, pq.Array(scopes), key.RateLimit, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+func (p *partnerRepository) FindKeyByHash(ctx context.Context, hash string) (*partners.APIKey, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM partner_api_keys WHERE key_hash = $1`, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, partners.ErrKeyNotFound
+	}
+	return keys[0], nil
+}
+
+func (p *partnerRepository) ListKeys(ctx context.Context) ([]*partners.APIKey, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM partner_api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return scanAPIKeys(rows)
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]*partners.APIKey, error) {
+	defer rows.Close()
+
+	keys := []*partners.APIKey{}
+	for rows.Next() {
+		var (
+			key    partners.APIKey
+			scopes pq.StringArray
+		)
+		err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.RateLimit,
+			&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		key.Hash = strings.TrimSpace(key.Hash)
+		for _, scope := range scopes {
+			key.Scopes = append(key.Scopes, partners.Scope(scope))
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (p *partnerRepository) RevokeKey(ctx context.Context, id partners.KeyID, at time.Time) error {
+	result, err := p.db.ExecContext(ctx, `UPDATE partner_api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if revoked == 0 {
+		return partners.ErrKeyNotFound
+	}
+	return nil
+}
+
+func (p *partnerRepository) RecordUsage(ctx context.Context, id partners.KeyID, endpoint string, at time.Time) error {
+	query := `
+		WITH used AS (
+			UPDATE partner_api_keys SET last_used_at = GREATEST(last_used_at, $3) WHERE id = $1
+		)
+		INSERT INTO partner_usage (key_id, day, endpoint, requests)
+		VALUES ($1, ($3::timestamptz AT TIME ZONE 'UTC')::date, $2, 1)
+		ON CONFLICT (key_id, day, endpoint) DO UPDATE SET requests = partner_usage.requests + 1`
+
+	if _, err := p.db.ExecContext(ctx, query, id, endpoint, at); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return partners.ErrKeyNotFound
+		}
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+func (p *partnerRepository) GetUsage(ctx context.Context, filter partners.UsageFilter) ([]*partners.Usage, error) {
+	query := `
+		SELECT key_id, day, endpoint, requests
+		FROM partner_usage
+		WHERE day BETWEEN $1::date AND $2::date AND ($3 = '' OR key_id = $3)
+		ORDER BY day, key_id, endpoint`
+
+	rows, err := p.db.QueryContext(ctx, query, filter.From.UTC().Format(time.DateOnly), filter.To.UTC().Format(time.DateOnly), filter.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []*partners.Usage{}
+	for rows.Next() {
+		var u partners.Usage
+		if err := rows.Scan(&u.KeyID, &u.Day, &u.Endpoint, &u.Requests); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		usage = append(usage, &u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/partners"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartnerRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`TRUNCATE TABLE partner_api_keys CASCADE`)
+	require.NoError(t, err)
+
+	repo := NewPartnerRepository(db)
+	ctx := context.Background()
+	now := time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	key, secret, err := partners.NewAPIKey("key-acme", "Acme", []partners.Scope{partners.ScopeScoresRead}, 60, now)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateKey(ctx, key))
+
+	found, err := repo.FindKeyByHash(ctx, partners.HashKey(secret))
+	require.NoError(t, err)
+	assert.Equal(t, key.Prefix, found.Prefix)
+	assert.Equal(t, []partners.Scope{partners.ScopeScoresRead}, found.Scopes)
+	assert.Equal(t, 60, found.RateLimit)
+	_, err = repo.FindKeyByHash(ctx, partners.HashKey("pk_unknown"))
+	assert.ErrorIs(t, err, partners.ErrKeyNotFound)
+
+	require.NoError(t, repo.RecordUsage(ctx, "key-acme", "/partner/v1/movies/{id}/score", now))
+	require.NoError(t, repo.RecordUsage(ctx, "key-acme", "/partner/v1/movies/{id}/score", now.Add(10*time.Minute)))
+	require.NoError(t, repo.RecordUsage(ctx, "key-acme", "/partner/v1/movies/{id}/score", now.Add(time.Hour)))
+	assert.ErrorIs(t, repo.RecordUsage(ctx, "key-missing", "/partner/v1/movies/{id}/score", now), partners.ErrKeyNotFound)
+
+	usage, err := repo.GetUsage(ctx, partners.UsageFilter{From: now.AddDate(0, 0, -1), To: now.AddDate(0, 0, 1), KeyID: "key-acme"})
+	require.NoError(t, err)
+	require.Len(t, usage, 2, "requests are counted by UTC day")
+	assert.Equal(t, int64(2), usage[0].Requests)
+	assert.Equal(t, "2024-03-10", usage[0].Day.Format(time.DateOnly))
+	assert.Equal(t, int64(1), usage[1].Requests)
+
+	usage, err = repo.GetUsage(ctx, partners.UsageFilter{From: now.AddDate(0, 0, 1), To: now.AddDate(0, 0, 1)})
+	require.NoError(t, err)
+	assert.Len(t, usage, 1)
+
+	found, err = repo.FindKeyByHash(ctx, partners.HashKey(secret))
+	require.NoError(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	assert.True(t, now.Add(time.Hour).Equal(*found.LastUsedAt))
+
+	require.NoError(t, repo.RevokeKey(ctx, "key-acme", now))
+	assert.ErrorIs(t, repo.RevokeKey(ctx, "key-acme", now), partners.ErrKeyNotFound)
+
+	keys, err := repo.ListKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.False(t, keys[0].Active())
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type peopleRepository struct {
+	db *sqlx.DB
+}
+
+func NewPeopleRepository(db *sqlx.DB) people.Repository {
+	return &peopleRepository{db: db}
+}
+
+func (p *peopleRepository) Save(ctx context.Context, person *people.Person) (*people.Person, error) {
+	query := `
+		INSERT INTO people (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at`
+
+	saved := *person
+	err := p.db.QueryRowContext(ctx, query, person.ID, person.Name, person.CreatedAt, person.UpdatedAt).
+		Scan(&saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("person with ID %s already exists", person.ID)
+		}
+		return nil, fmt.Errorf("failed to save person: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (p *peopleRepository) GetByID(ctx context.Context, id people.PersonID) (*people.Person, error) {
+	query := `SELECT id, name, created_at, updated_at FROM people WHERE id = $1`
+
+	return p.getPerson(ctx, query, id)
+}
+
+func (p *peopleRepository) GetByName(ctx context.Context, name string) (*people.Person, error) {
+	query := `
+		SELECT id, name, created_at, updated_at FROM people
+		WHERE LOWER(name) = LOWER($1)
+		ORDER BY created_at, id
+		LIMIT 1`
+
+	return p.getPerson(ctx, query, strings.TrimSpace(name))
+}
+
+func (p *peopleRepository) getPerson(ctx context.Context, query string, arg interface{}) (*people.Person, error) {
+	person := &people.Person{}
+	var id string
+	err := p.db.QueryRowContext(ctx, query, arg).Scan(&id, &person.Name, &person.CreatedAt, &person.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("person %v not found", arg)
+		}
+		return nil, fmt.Errorf("failed to get person: %w", err)
+	}
+
+	person.ID = people.PersonID(strings.TrimSpace(id))
+	return person, nil
+}
+
+func (p *peopleRepository) SaveCredit(ctx context.Context, credit *people.Credit) (*people.Credit, error) {
+	query := `
+		INSERT INTO movie_credits (movie_id, person_id, role, character_name, billing_order, created_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		ON CONFLICT (movie_id, person_id, role) DO UPDATE SET
+			character_name = EXCLUDED.character_name,
+			billing_order = EXCLUDED.billing_order
+		RETURNING created_at`
+
+	saved := *credit
+	err := p.db.QueryRowContext(
+		ctx, query,
+		credit.MovieID, credit.PersonID, credit.Role,
+		credit.Character, credit.BillingOrder, credit.CreatedAt,
+	).Scan(&saved.CreatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, fmt.Errorf("movie %s or person %s not found", credit.MovieID, credit.PersonID)
+		}
+		return nil, fmt.Errorf("failed to save credit: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (p *peopleRepository) DeleteCredit(ctx context.Context, movieID movies.MovieID, personID people.PersonID, role people.Role) error {
+	query := `DELETE FROM movie_credits WHERE movie_id = $1 AND person_id = $2 AND role = $3`
+
+	result, err := p.db.ExecContext(ctx, query, movieID, personID, role)
+	if err != nil {
+		return fmt.Errorf("failed to delete credit: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s credit for person %s on movie %s not found", role, personID, movieID)
+	}
+
+	return nil
+}
+
+func (p *peopleRepository) GetMovieCredits(ctx context.Context, movieID movies.MovieID) ([]*people.Credit, error) {
+	query := `
+		SELECT mc.movie_id, mc.person_id, p.name, mc.role,
+			   COALESCE(mc.character_name, ''), mc.billing_order, mc.created_at
+		FROM movie_credits mc
+		JOIN people p ON p.id = mc.person_id
+		WHERE mc.movie_id = $1
+		ORDER BY CASE mc.role
+				WHEN 'director' THEN 1 WHEN 'writer' THEN 2
+				WHEN 'actor' THEN 3 ELSE 4
+			END, mc.billing_order, p.name`
+
+	rows, err := p.db.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credits: %w", err)
+	}
+	defer rows.Close()
+
+	var credits []*people.Credit
+	for rows.Next() {
+		credit := &people.Credit{}
+		var mid, pid, role string
+		if err := rows.Scan(
+			&mid, &pid, &credit.PersonName, &role,
+			&credit.Character, &credit.BillingOrder, &credit.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan credit: %w", err)
+		}
+		credit.MovieID = movies.MovieID(strings.TrimSpace(mid))
+		credit.PersonID = people.PersonID(strings.TrimSpace(pid))
+		credit.Role = people.Role(role)
+		credits = append(credits, credit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating credits: %w", err)
+	}
+
+	return credits, nil
+}
+
+func (p *peopleRepository) GetPersonMovies(ctx context.Context, personID people.PersonID, role people.Role, options ...movies.SearchOption) ([]*people.CreditedMovie, int64, error) {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	query := `
+		SELECT m.id, m.title, m.description, m.release_year, m.genre, m.director,
+			   m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+			   m.imdb_id, m.poster_url, m.created_at, m.updated_at,
+			   mc.role, COALESCE(mc.character_name, ''),
+			   COUNT(*) OVER() AS total
+		FROM movie_credits mc
+		JOIN movies m ON m.id = mc.movie_id
+		WHERE mc.person_id = $1 AND ($2 = '' OR mc.role = $2)
+		ORDER BY m.release_year DESC, m.title, mc.role
+		LIMIT $3 OFFSET $4`
+
+	rows, err := p.db.QueryContext(ctx, query, personID, string(role), opts.Limit, opts.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query person movies: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		credited []*people.CreditedMovie
+		total    int64
+	)
+	for rows.Next() {
+		movie := &movies.Movie{}
+		entry := &people.CreditedMovie{Movie: movie}
+		var id, creditRole string
+		if err := rows.Scan(
+			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&creditRole, &entry.Character, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan person movie: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(id))
+		entry.Role = people.Role(creditRole)
+		credited = append(credited, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating person movies: %w", err)
+	}
+
+	return credited, total, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeopleRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewPeopleRepository(db)
+	movieRepo := NewMovieRepository(db)
+	ctx := context.Background()
+
+	for _, m := range []struct{ id, title string }{
+		{"test-id-credits-1", "Dune"},
+		{"test-id-credits-2", "Interstellar"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 2021, 'Sci-Fi', 'Director', 150, 'PG-13', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title)
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	person, err := repo.Save(ctx, &people.Person{ID: "test-id-person-1", Name: "Hans Zimmer", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+
+	found, err := repo.GetByName(ctx, "hans zimmer")
+	require.NoError(t, err)
+	assert.Equal(t, person.ID, found.ID)
+
+	_, err = repo.GetByID(ctx, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	for _, movieID := range []movies.MovieID{"test-id-credits-1", "test-id-credits-2"} {
+		_, err = repo.SaveCredit(ctx, &people.Credit{MovieID: movieID, PersonID: person.ID, Role: people.RoleComposer, CreatedAt: now})
+		require.NoError(t, err)
+	}
+	_, err = repo.SaveCredit(ctx, &people.Credit{
+		MovieID: "test-id-credits-1", PersonID: person.ID, Role: people.RoleActor, Character: "Cameo", BillingOrder: 9, CreatedAt: now,
+	})
+	require.NoError(t, err)
+
+	credits, err := repo.GetMovieCredits(ctx, "test-id-credits-1")
+	require.NoError(t, err)
+	require.Len(t, credits, 2)
+	assert.Equal(t, people.RoleActor, credits[0].Role)
+	assert.Equal(t, "Cameo", credits[0].Character)
+	assert.Equal(t, "Hans Zimmer", credits[0].PersonName)
+
+	credited, total, err := repo.GetPersonMovies(ctx, person.ID, people.RoleComposer, movies.WithLimit(1), movies.WithOffset(0))
+	require.NoError(t, err)
+	assert.Len(t, credited, 1)
+	assert.Equal(t, int64(2), total)
+
+	_, total, err = repo.GetPersonMovies(ctx, person.ID, "", movies.WithLimit(10), movies.WithOffset(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+
+	// Search can find movies by any credited person
+	results, total, err := movieRepo.Search(ctx, movies.SearchFilter{Featuring: "HANS ZIMMER"}, movies.WithLimit(10), movies.WithOffset(0))
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, int64(2), total)
+
+	require.NoError(t, repo.DeleteCredit(ctx, "test-id-credits-1", person.ID, people.RoleActor))
+	err = repo.DeleteCredit(ctx, "test-id-credits-1", person.ID, people.RoleActor)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.SaveCredit(ctx, &people.Credit{MovieID: "missing", PersonID: person.ID, Role: people.RoleWriter, CreatedAt: now})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
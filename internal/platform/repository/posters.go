@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type posterRepository struct {
+	db *sqlx.DB
+}
+
+func NewPosterRepository(db *sqlx.DB) movies.PosterRepository {
+	return &posterRepository{db: db}
+}
+
+const posterColumns = `movie_id, variant, storage_key, content_type, width, height, size_bytes, created_at`
+
+func (p *posterRepository) ReplacePosters(ctx context.Context, movieID movies.MovieID, posterURL string, posters []*movies.Poster, hash movies.PosterHash) ([]*movies.Poster, error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin poster transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the movie row serializes concurrent uploads for the same movie
+	result, err := tx.ExecContext(ctx, `UPDATE movies SET poster_url = $2, updated_at = NOW() WHERE id = $1`, movieID, posterURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update poster URL: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("movie with ID %s not found", movieID)
+	}
+
+	previous, err := p.queryPosters(ctx, tx, `
+		DELETE FROM movie_posters WHERE movie_id = $1
+		RETURNING `+posterColumns, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, poster := range posters {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO movie_posters (`+posterColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			movieID, poster.Variant, poster.StorageKey, poster.ContentType,
+			poster.Width, poster.Height, poster.SizeBytes, poster.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s poster: %w", poster.Variant, err)
+		}
+	}
+
+	// Stored as the signed BIGINT with the same bits
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO poster_hashes (movie_id, hash, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (movie_id) DO UPDATE SET
+			hash = EXCLUDED.hash,
+			created_at = EXCLUDED.created_at`,
+		movieID, int64(hash),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save poster hash: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit poster transaction: %w", err)
+	}
+
+	return previous, nil
+}
+
+func (p *posterRepository) GetPoster(ctx context.Context, movieID movies.MovieID, variant movies.PosterVariant) (*movies.Poster, error) {
+	posters, err := p.queryPosters(ctx, p.db, `
+		SELECT `+posterColumns+` FROM movie_posters
+		WHERE movie_id = $1 AND variant = $2`, movieID, variant)
+	if err != nil {
+		return nil, err
+	}
+	if len(posters) == 0 {
+		return nil, fmt.Errorf("%s poster for movie %s not found", variant, movieID)
+	}
+
+	return posters[0], nil
+}
+
+func (p *posterRepository) ListPosters(ctx context.Context, movieID movies.MovieID) ([]*movies.Poster, error) {
+	return p.queryPosters(ctx, p.db, `
+		SELECT `+posterColumns+` FROM movie_posters
+		WHERE movie_id = $1
+		ORDER BY width`, movieID)
+}
+
+func (p *posterRepository) ListPosterHashes(ctx context.Context) ([]*movies.PosterFingerprint, error) {
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT h.movie_id, m.title, m.release_year, h.hash, h.created_at
+		FROM poster_hashes h
+		JOIN movies m ON m.id = h.movie_id
+		ORDER BY h.created_at, h.movie_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query poster hashes: %w", err)
+	}
+	defer rows.Close()
+
+	var fingerprints []*movies.PosterFingerprint
+	for rows.Next() {
+		fingerprint := &movies.PosterFingerprint{}
+		var movieID string
+		var hash int64
+		if err := rows.Scan(&movieID, &fingerprint.Title, &fingerprint.ReleaseYear, &hash, &fingerprint.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan poster hash: %w", err)
+		}
+		fingerprint.MovieID = movies.MovieID(strings.TrimSpace(movieID))
+		fingerprint.Hash = movies.PosterHash(uint64(hash))
+		fingerprints = append(fingerprints, fingerprint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating poster hashes: %w", err)
+	}
+
+	return fingerprints, nil
+}
+
+func (p *posterRepository) queryPosters(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) ([]*movies.Poster, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posters: %w", err)
+	}
+	defer rows.Close()
+
+	var posters []*movies.Poster
+	for rows.Next() {
+		poster := &movies.Poster{}
+		var movieID string
+		if err := rows.Scan(
+			&movieID, &poster.Variant, &poster.StorageKey, &poster.ContentType,
+			&poster.Width, &poster.Height, &poster.SizeBytes, &poster.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan poster: %w", err)
+		}
+		poster.MovieID = movies.MovieID(strings.TrimSpace(movieID))
+		posters = append(posters, poster)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating posters: %w", err)
+	}
+
+	return posters, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPosterRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewPosterRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-poster', 'Alien', '', 1979, 'Horror', 'Ridley Scott', 117, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	upload := func(uploadID string) []*movies.Poster {
+		var posters []*movies.Poster
+		for _, variant := range movies.PosterVariants {
+			posters = append(posters, &movies.Poster{
+				MovieID:     "test-id-poster",
+				Variant:     variant,
+				StorageKey:  "posters/test-id-poster/" + uploadID + "/" + string(variant) + ".jpg",
+				ContentType: "image/jpeg",
+				Width:       variant.Width(),
+				Height:      variant.Width() * 3 / 2,
+				SizeBytes:   1024,
+				CreatedAt:   now,
+			})
+		}
+		return posters
+	}
+
+	previous, err := repo.ReplacePosters(ctx, "test-id-poster", "/api/v1/movies/test-id-poster/poster/large", upload("first"), 0x0f0f)
+	require.NoError(t, err)
+	assert.Empty(t, previous)
+
+	var posterURL string
+	require.NoError(t, db.Get(&posterURL, `SELECT poster_url FROM movies WHERE id = 'test-id-poster'`))
+	assert.Equal(t, "/api/v1/movies/test-id-poster/poster/large", posterURL)
+
+	previous, err = repo.ReplacePosters(ctx, "test-id-poster", "/api/v1/movies/test-id-poster/poster/large", upload("second"), 0xf0f0000000000001)
+	require.NoError(t, err)
+	require.Len(t, previous, 3)
+	assert.Contains(t, previous[0].StorageKey, "/first/")
+
+	poster, err := repo.GetPoster(ctx, "test-id-poster", movies.PosterMedium)
+	require.NoError(t, err)
+	assert.Equal(t, "posters/test-id-poster/second/medium.jpg", poster.StorageKey)
+	assert.Equal(t, movies.MovieID("test-id-poster"), poster.MovieID)
+	assert.Equal(t, 342, poster.Width)
+
+	posters, err := repo.ListPosters(ctx, "test-id-poster")
+	require.NoError(t, err)
+	require.Len(t, posters, 3)
+	assert.Equal(t, movies.PosterSmall, posters[0].Variant)
+
+	// The hash of the latest upload, with all 64 bits kept
+	hashes, err := repo.ListPosterHashes(ctx)
+	require.NoError(t, err)
+	require.Len(t, hashes, 1)
+	assert.Equal(t, movies.PosterHash(0xf0f0000000000001), hashes[0].Hash)
+	assert.Equal(t, "Alien", hashes[0].Title)
+	assert.Equal(t, 1979, hashes[0].ReleaseYear)
+
+	_, err = repo.ReplacePosters(ctx, "missing", "/x", nil, 0)
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = db.Exec(`DELETE FROM movies WHERE id = 'test-id-poster'`)
+	require.NoError(t, err)
+	_, err = repo.GetPoster(ctx, "test-id-poster", movies.PosterLarge)
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type providerRepository struct {
+	db *sqlx.DB
+}
+
+func NewProviderRepository(db *sqlx.DB) movies.ProviderRepository {
+	return &providerRepository{db: db}
+}
+
+func (r *providerRepository) ListProviders(ctx context.Context) ([]*movies.Provider, error) {
+	providers := []*movies.Provider{}
+	err := r.db.SelectContext(ctx, &providers, `
+		SELECT id, name, created_at, updated_at
+		FROM providers ORDER BY LOWER(name), id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query providers: %w", err)
+	}
+	return providers, nil
+}
+
+func (r *providerRepository) SaveProvider(ctx context.Context, provider *movies.Provider) (*movies.Provider, error) {
+	query := `
+		INSERT INTO providers (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at`
+
+	saved := *provider
+	err := r.db.QueryRowContext(ctx, query, provider.ID, provider.Name, provider.CreatedAt, provider.UpdatedAt).
+		Scan(&saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save provider: %w", err)
+	}
+	return &saved, nil
+}
+
+func (r *providerRepository) SaveOffer(ctx context.Context, offer *movies.Offer) (*movies.Offer, error) {
+	query := `
+		WITH saved AS (
+			INSERT INTO movie_providers (movie_id, region, provider_id, type, url, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (movie_id, region, provider_id, type) DO UPDATE SET
+				url = EXCLUDED.url,
+				updated_at = EXCLUDED.updated_at
+			RETURNING provider_id, created_at, updated_at
+		)
+		SELECT p.name, saved.created_at, saved.updated_at
+		FROM saved JOIN providers p ON p.id = saved.provider_id`
+
+	saved := *offer
+	err := r.db.QueryRowContext(
+		ctx, query,
+		offer.MovieID, offer.Region, offer.ProviderID, offer.Type,
+		offer.URL, offer.CreatedAt, offer.UpdatedAt,
+	).Scan(&saved.ProviderName, &saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			if pqErr.Constraint == "fk_movie_providers_provider" {
+				return nil, fmt.Errorf("provider %s not found", offer.ProviderID)
+			}
+			return nil, fmt.Errorf("movie with ID %s not found", offer.MovieID)
+		}
+		return nil, fmt.Errorf("failed to save offer: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (r *providerRepository) GetOffers(ctx context.Context, movieID movies.MovieID, region string) ([]*movies.Offer, error) {
+	conditions := []string{"o.movie_id = $1"}
+	args := []interface{}{movieID}
+	if region != "" {
+		args = append(args, region)
+		conditions = append(conditions, fmt.Sprintf("o.region = $%d", len(args)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT o.movie_id, o.region, o.provider_id, p.name AS provider_name, o.type, o.url, o.created_at, o.updated_at
+		FROM movie_providers o
+		JOIN providers p ON p.id = o.provider_id
+		WHERE %s
+		ORDER BY o.region, LOWER(p.name), o.provider_id, o.type`, strings.Join(conditions, " AND "))
+
+	offers := []*movies.Offer{}
+	if err := r.db.SelectContext(ctx, &offers, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to query offers: %w", err)
+	}
+	for _, offer := range offers {
+		offer.MovieID = movies.MovieID(strings.TrimSpace(string(offer.MovieID)))
+	}
+
+	return offers, nil
+}
+
+func (r *providerRepository) DeleteOffer(ctx context.Context, movieID movies.MovieID, region, providerID string, offerType movies.OfferType) error {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM movie_providers WHERE movie_id = $1 AND region = $2 AND provider_id = $3 AND type = $4`,
+		movieID, region, providerID, offerType)
+	if err != nil {
+		return fmt.Errorf("failed to delete offer: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s offer on %s in %s for movie %s not found", offerType, providerID, region, movieID)
+	}
+
+	return nil
+}
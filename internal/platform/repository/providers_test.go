@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewProviderRepository(db)
+	movieRepo := NewMovieRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-provider-dune', 'Dune', '', 2021, 'Sci-Fi', 'Denis Villeneuve', 155, 'PG13', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-provider-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	saved, err := repo.SaveProvider(ctx, &movies.Provider{ID: "arte", Name: "ARTE", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, "ARTE", saved.Name)
+	_, err = repo.SaveProvider(ctx, &movies.Provider{ID: "arte", Name: "ARTE Mediathek", CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+
+	providers, err := repo.ListProviders(ctx)
+	require.NoError(t, err)
+	names := make(map[string]string)
+	for _, p := range providers {
+		names[p.ID] = p.Name
+	}
+	assert.Equal(t, "ARTE Mediathek", names["arte"], "saving again renames")
+	assert.Equal(t, "Netflix", names["netflix"], "the common services are there from the start")
+
+	link := "https://www.netflix.com/title/81265477"
+	for _, offer := range []*movies.Offer{
+		{MovieID: "test-id-provider-dune", Region: "DE", ProviderID: "netflix", Type: movies.OfferSubscription, URL: &link},
+		{MovieID: "test-id-provider-dune", Region: "DE", ProviderID: "apple-tv", Type: movies.OfferRent},
+		{MovieID: "test-id-provider-dune", Region: "US", ProviderID: "max", Type: movies.OfferSubscription},
+		{MovieID: "test-id-provider-heat", Region: "US", ProviderID: "netflix", Type: movies.OfferSubscription},
+	} {
+		offer.CreatedAt, offer.UpdatedAt = now, now
+		_, err := repo.SaveOffer(ctx, offer)
+		require.NoError(t, err)
+	}
+
+	_, err = repo.SaveOffer(ctx, &movies.Offer{MovieID: "missing", Region: "DE", ProviderID: "netflix", Type: movies.OfferRent, CreatedAt: now, UpdatedAt: now})
+	assert.ErrorContains(t, err, "movie with ID missing not found")
+	_, err = repo.SaveOffer(ctx, &movies.Offer{MovieID: "test-id-provider-dune", Region: "DE", ProviderID: "myspace", Type: movies.OfferRent, CreatedAt: now, UpdatedAt: now})
+	assert.ErrorContains(t, err, "provider myspace not found")
+
+	offers, err := repo.GetOffers(ctx, "test-id-provider-dune", "")
+	require.NoError(t, err)
+	require.Len(t, offers, 3)
+	assert.Equal(t, "Apple TV", offers[0].ProviderName, "by region, then provider name")
+	assert.Equal(t, "Netflix", offers[1].ProviderName)
+	assert.Equal(t, link, *offers[1].URL)
+	assert.Equal(t, movies.MovieID("test-id-provider-dune"), offers[2].MovieID)
+
+	offers, err = repo.GetOffers(ctx, "test-id-provider-dune", "US")
+	require.NoError(t, err)
+	require.Len(t, offers, 1)
+	assert.Equal(t, "max", offers[0].ProviderID)
+
+	// Only movies on Netflix in Germany
+	results, total, err := movieRepo.Search(ctx, movies.SearchFilter{Provider: "netflix", ProviderRegion: "DE"}, movies.WithLimit(10), movies.WithOffset(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Dune", results[0].Title)
+	_, total, err = movieRepo.Search(ctx, movies.SearchFilter{Provider: "netflix"}, movies.WithLimit(10), movies.WithOffset(0))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total, "in any region")
+
+	require.NoError(t, repo.DeleteOffer(ctx, "test-id-provider-dune", "DE", "netflix", movies.OfferSubscription))
+	err = repo.DeleteOffer(ctx, "test-id-provider-dune", "DE", "netflix", movies.OfferSubscription)
+	assert.ErrorContains(t, err, "not found")
+	offers, err = repo.GetOffers(ctx, "test-id-provider-dune", "DE")
+	require.NoError(t, err)
+	assert.Len(t, offers, 1)
+}
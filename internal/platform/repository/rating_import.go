@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func NewRatingImporter(db *sqlx.DB) domainRating.Importer {
+	return &ratingRepository{db: db}
+}
+
+func (r *ratingRepository) ImportBatch(ctx context.Context, ratings []*domainRating.Rating) ([]domainRating.ImportStatus, error) {
+	statuses := make([]domainRating.ImportStatus, len(ratings))
+	if len(ratings) == 0 {
+		return statuses, nil
+	}
+
+	userIDs := make([]string, len(ratings))
+	movieIDs := make([]string, len(ratings))
+	for i, rating := range ratings {
+		userIDs[i] = string(rating.UserID)
+		movieIDs[i] = string(rating.MovieID)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// FOR KEY SHARE keeps the users and movies from being deleted before the
+	// insert, without blocking updates to them
+	existingUsers, err := lockExistingIDs(ctx, tx, "users", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	existingMovies, err := lockExistingIDs(ctx, tx, "movies", movieIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ids, insertUsers, insertMovies, reviews, createdAt, updatedAt []string
+		scores                                                        []int64
+		spoilers                                                      []bool
+	)
+	for i, rating := range ratings {
+		switch {
+		case !existingUsers[userIDs[i]]:
+			statuses[i] = domainRating.ImportUnknownUser
+		case !existingMovies[movieIDs[i]]:
+			statuses[i] = domainRating.ImportUnknownMovie
+		default:
+			ids = append(ids, string(rating.ID))
+			insertUsers = append(insertUsers, userIDs[i])
+			insertMovies = append(insertMovies, movieIDs[i])
+			scores = append(scores, int64(rating.Score))
+			reviews = append(reviews, rating.Review)
+			spoilers = append(spoilers, rating.ContainsSpoilers)
+			createdAt = append(createdAt, rating.CreatedAt.UTC().Format(time.RFC3339Nano))
+			updatedAt = append(updatedAt, rating.UpdatedAt.UTC().Format(time.RFC3339Nano))
+		}
+	}
+
+	type ratingKey struct {
+		userID  users.UserID
+		movieID movies.MovieID
+	}
+	inserted := make(map[ratingKey]bool)
+	if len(ids) > 0 {
+		rows, err := tx.QueryContext(ctx, `
+			INSERT INTO ratings (id, user_id, movie_id, score, review, contains_spoilers, created_at, updated_at)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::int[], $5::text[], $6::bool[], $7::timestamptz[], $8::timestamptz[])
+			ON CONFLICT (user_id, movie_id) WHERE deleted_at IS NULL DO NOTHING
+			RETURNING user_id, movie_id`,
+			pq.Array(ids), pq.Array(insertUsers), pq.Array(insertMovies), pq.Array(scores),
+			pq.Array(reviews), pq.Array(spoilers), pq.Array(createdAt), pq.Array(updatedAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert ratings: %w", err)
+		}
+		for rows.Next() {
+			var userID, movieID string
+			if err := rows.Scan(&userID, &movieID); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan imported rating: %w", err)
+			}
+			inserted[ratingKey{users.UserID(strings.TrimSpace(userID)), movies.MovieID(strings.TrimSpace(movieID))}] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating imported ratings: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	for i, rating := range ratings {
+		if statuses[i] != "" {
+			continue
+		}
+		// A pair repeated within the batch is inserted once; the first
+		// occurrence gets the credit
+		key := ratingKey{rating.UserID, rating.MovieID}
+		if inserted[key] {
+			statuses[i] = domainRating.ImportInserted
+			delete(inserted, key)
+		} else {
+			statuses[i] = domainRating.ImportDuplicate
+		}
+	}
+	return statuses, nil
+}
+
+func lockExistingIDs(ctx context.Context, tx *sqlx.Tx, table string, ids []string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx,
+		fmt.Sprintf(`SELECT id FROM %s WHERE id = ANY($1) ORDER BY id FOR KEY SHARE`, table),
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan %s id: %w", table, err)
+		}
+		existing[strings.TrimSpace(id)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", table, err)
+	}
+	return existing, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRatingImporter_ImportBatch(t *testing.T) {
+	db := setupRatingTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('movie-id-import', 'Test Movie', '', 2024, 'Action', 'Test Director', 120, 'PG-13', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at) VALUES
+			('user-id-import-1', 'import1@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW()),
+			('user-id-import-2', 'import2@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at)
+		VALUES ('rating-id-import-0', 'user-id-import-2', 'movie-id-import', 3, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	createdAt := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	newRating := func(id rating.RatingID, userID users.UserID, movieID movies.MovieID) *rating.Rating {
+		return &rating.Rating{
+			ID:        id,
+			UserID:    userID,
+			MovieID:   movieID,
+			Score:     4,
+			Review:    "Still holds up",
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}
+	}
+
+	importer := NewRatingImporter(db)
+	statuses, err := importer.ImportBatch(context.Background(), []*rating.Rating{
+		newRating("rating-id-import-1", "user-id-import-1", "movie-id-import"),
+		newRating("rating-id-import-2", "user-id-import-1", "movie-id-import"),  // repeated within the batch
+		newRating("rating-id-import-3", "user-id-import-2", "movie-id-import"),  // already rated
+		newRating("rating-id-import-4", "user-id-missing", "movie-id-import"),   // unknown user
+		newRating("rating-id-import-5", "user-id-import-1", "movie-id-missing"), // unknown movie
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []rating.ImportStatus{
+		rating.ImportInserted,
+		rating.ImportDuplicate,
+		rating.ImportDuplicate,
+		rating.ImportUnknownUser,
+		rating.ImportUnknownMovie,
+	}, statuses)
+
+	saved, err := NewRatingRepository(db).GetByID(context.Background(), "rating-id-import-1")
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(saved.CreatedAt), "the original timestamp is kept")
+	assert.Equal(t, "Still holds up", saved.Review)
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM ratings`))
+	assert.Equal(t, 2, count)
+}
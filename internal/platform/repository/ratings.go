@@ -8,35 +8,37 @@ import (
 	"thermondo/internal/domain/movies"
 	domainRating "thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/postgres"
+	"thermondo/internal/pkg/sorting"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
 type ratingRepository struct {
-	db *sqlx.DB
+	db      *sqlx.DB
+	dialect postgres.Dialect
 }
 
 func NewRatingRepository(db *sqlx.DB) domainRating.Repository {
-	return &ratingRepository{db: db}
+	return &ratingRepository{db: db, dialect: postgres.DialectOf(db)}
 }
 
 func (r *ratingRepository) Save(ctx context.Context, rating *domainRating.Rating) (*domainRating.Rating, error) {
 	query := `
-		INSERT INTO ratings (id, user_id, movie_id, score, review, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO ratings (id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at, version`
 
 	var savedRating = rating
 	err := r.db.QueryRowContext(
 		ctx, query,
 		rating.ID, rating.UserID, rating.MovieID, rating.Score,
-		rating.Review, rating.CreatedAt, rating.UpdatedAt,
-	).Scan(&savedRating.ID, &savedRating.CreatedAt, &savedRating.UpdatedAt)
+		rating.Review, rating.ContainsSpoilers, rating.ContainsAdultLanguage, rating.CreatedAt, rating.UpdatedAt,
+	).Scan(&savedRating.ID, &savedRating.CreatedAt, &savedRating.UpdatedAt, &savedRating.Version)
 
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		if r.dialect.IsUniqueViolation(err) {
 			return nil, fmt.Errorf("user has already rated this movie")
 		}
 		return nil, fmt.Errorf("failed to save rating: %w", err)
@@ -46,15 +48,24 @@ func (r *ratingRepository) Save(ctx context.Context, rating *domainRating.Rating
 }
 
 func (r *ratingRepository) GetByID(ctx context.Context, id domainRating.RatingID) (*domainRating.Rating, error) {
+	return r.getByID(ctx, id, "deleted_at IS NULL")
+}
+
+func (r *ratingRepository) GetByIDIncludingDeleted(ctx context.Context, id domainRating.RatingID) (*domainRating.Rating, error) {
+	return r.getByID(ctx, id, "TRUE")
+}
+
+// getByID reads rating id when it matches condition
+func (r *ratingRepository) getByID(ctx context.Context, id domainRating.RatingID, condition string) (*domainRating.Rating, error) {
 	query := `
-		SELECT id, user_id, movie_id, score, review, created_at, updated_at
-		FROM ratings WHERE id = $1`
+		SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version
+		FROM ratings WHERE id = $1 AND ` + condition
 
 	rating := &domainRating.Rating{}
 	var rid, userID, movieID string
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&rid, &userID, &movieID, &rating.Score,
-		&rating.Review, &rating.CreatedAt, &rating.UpdatedAt,
+		&rating.Review, &rating.ContainsSpoilers, &rating.ContainsAdultLanguage, &rating.CreatedAt, &rating.UpdatedAt, &rating.Version,
 	)
 
 	if err != nil {
@@ -73,13 +84,13 @@ func (r *ratingRepository) GetByID(ctx context.Context, id domainRating.RatingID
 
 func (r *ratingRepository) GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*domainRating.Rating, error) {
 	query := `
-		SELECT id, user_id, movie_id, score, review, created_at, updated_at
-		FROM ratings WHERE user_id = $1 AND movie_id = $2`
+		SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version
+		FROM ratings WHERE user_id = $1 AND movie_id = $2 AND deleted_at IS NULL`
 
 	rating := &domainRating.Rating{}
 	err := r.db.QueryRowContext(ctx, query, userID, movieID).Scan(
 		&rating.ID, &rating.UserID, &rating.MovieID, &rating.Score,
-		&rating.Review, &rating.CreatedAt, &rating.UpdatedAt,
+		&rating.Review, &rating.ContainsSpoilers, &rating.ContainsAdultLanguage, &rating.CreatedAt, &rating.UpdatedAt, &rating.Version,
 	)
 
 	if err != nil {
@@ -99,11 +110,11 @@ func (r *ratingRepository) GetByUser(ctx context.Context, userID users.UserID, o
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, user_id, movie_id, score, review, created_at, updated_at
+		SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version
 		FROM ratings 
-		WHERE user_id = $1
-		ORDER BY %s %s
-		LIMIT $2 OFFSET $3`, r.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY %s
+		LIMIT $2 OFFSET $3`, r.orderBy(opts))
 
 	return r.queryRatings(ctx, query, userID, opts.Limit, opts.Offset)
 }
@@ -114,32 +125,192 @@ func (r *ratingRepository) GetByMovie(ctx context.Context, movieID movies.MovieI
 		option(&opts)
 	}
 
+	conditions := []string{"movie_id = $1", visibleRating("ratings")}
+	switch opts.Reviewer {
+	case domainRating.ReviewerCritic:
+		conditions = append(conditions, criticRating("ratings"))
+	case domainRating.ReviewerAudience:
+		conditions = append(conditions, "NOT "+criticRating("ratings"))
+	}
+	switch opts.Spoilers {
+	case domainRating.SpoilersHide:
+		conditions = append(conditions, "NOT contains_spoilers")
+	case domainRating.SpoilersOnly:
+		conditions = append(conditions, "contains_spoilers")
+	}
+	if opts.HideAdultLanguage {
+		conditions = append(conditions, "NOT contains_adult_language")
+	}
+	if opts.ReviewsOnly {
+		conditions = append(conditions, "COALESCE(review, '') <> ''")
+	}
+
 	query := fmt.Sprintf(`
-		SELECT id, user_id, movie_id, score, review, created_at, updated_at
+		SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version
 		FROM ratings 
-		WHERE movie_id = $1
-		ORDER BY %s %s
-		LIMIT $2 OFFSET $3`, r.getSortColumn(opts.SortBy), strings.ToUpper(opts.Order))
+		WHERE %s
+		ORDER BY %s
+		LIMIT $2 OFFSET $3`, strings.Join(conditions, " AND "), r.orderBy(opts))
 
 	return r.queryRatings(ctx, query, movieID, opts.Limit, opts.Offset)
 }
 
+func (r *ratingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter domainRating.UserRatingFilter, options ...domainRating.SearchOption) ([]*domainRating.RatingWithMovie, int64, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	conditions := []string{"r.user_id = $1", liveRating("r")}
+	args := []interface{}{userID}
+
+	if filter.Genre != "" {
+		args = append(args, filter.Genre)
+		conditions = append(conditions, fmt.Sprintf("LOWER(m.genre) = LOWER($%d)", len(args)))
+	}
+	if filter.MinScore != nil {
+		args = append(args, *filter.MinScore)
+		conditions = append(conditions, fmt.Sprintf("r.score >= $%d", len(args)))
+	}
+	if filter.MaxScore != nil {
+		args = append(args, *filter.MaxScore)
+		conditions = append(conditions, fmt.Sprintf("r.score <= $%d", len(args)))
+	}
+	if filter.YearFrom != nil {
+		args = append(args, *filter.YearFrom)
+		conditions = append(conditions, fmt.Sprintf("m.release_year >= $%d", len(args)))
+	}
+	if filter.YearTo != nil {
+		args = append(args, *filter.YearTo)
+		conditions = append(conditions, fmt.Sprintf("m.release_year <= $%d", len(args)))
+	}
+	if filter.RatedFrom != nil {
+		args = append(args, *filter.RatedFrom)
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", len(args)))
+	}
+	if filter.RatedTo != nil {
+		args = append(args, *filter.RatedTo)
+		conditions = append(conditions, fmt.Sprintf("r.created_at < $%d", len(args)))
+	}
+
+	// Each rated movie's stats come from a lateral subquery; SQLite has no
+	// LATERAL, so there they are grouped by movie for the movies rated
+	statsJoin := fmt.Sprintf(`LEFT JOIN LATERAL (
+			SELECT ROUND(AVG(%s), 2) AS average_score, COUNT(*) AS total_ratings
+			FROM ratings mr
+			WHERE mr.movie_id = r.movie_id AND %s
+		) ms ON TRUE`, r.dialect.Decimal("mr.score"), visibleRating("mr"))
+	if r.dialect == postgres.DialectSQLite {
+		statsJoin = fmt.Sprintf(`LEFT JOIN (
+			SELECT mr.movie_id, ROUND(AVG(%s), 2) AS average_score, COUNT(*) AS total_ratings
+			FROM ratings mr
+			WHERE mr.movie_id IN (SELECT movie_id FROM ratings WHERE user_id = $1 AND deleted_at IS NULL) AND %s
+			GROUP BY mr.movie_id
+		) ms ON ms.movie_id = r.movie_id`, r.dialect.Decimal("mr.score"), visibleRating("mr"))
+	}
+
+	args = append(args, opts.Limit, opts.Offset)
+	query := fmt.Sprintf(`
+		SELECT r.id, r.user_id, r.movie_id, r.score, r.review, r.contains_spoilers, r.contains_adult_language, r.created_at, r.updated_at, r.version,
+			   m.id, m.title, m.description, m.release_year, m.genre, m.director,
+			   m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+			   m.imdb_id, m.poster_url, m.created_at, m.updated_at,
+			   COALESCE(ms.average_score, 0), COALESCE(ms.total_ratings, 0),
+			   COUNT(*) OVER() AS total_count
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		%s
+		WHERE %s
+		ORDER BY %s, r.id
+		LIMIT $%d OFFSET $%d`,
+		statsJoin,
+		strings.Join(conditions, " AND "),
+		r.joinedOrderBy(opts),
+		len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query user ratings with movies: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		results []*domainRating.RatingWithMovie
+		total   int64
+	)
+	for rows.Next() {
+		rating := &domainRating.Rating{}
+		movie := &movies.Movie{}
+		item := &domainRating.RatingWithMovie{Rating: rating, Movie: movie}
+		var rid, ruserID, rmovieID, mid string
+		var review sql.NullString
+
+		err := rows.Scan(
+			&rid, &ruserID, &rmovieID, &rating.Score, &review, &rating.ContainsSpoilers, &rating.ContainsAdultLanguage, &rating.CreatedAt, &rating.UpdatedAt, &rating.Version,
+			&mid, &movie.Title, &movie.Description, &movie.ReleaseYear, &movie.Genre, &movie.Director,
+			&movie.DurationMins, &movie.Rating, &movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&item.MovieAverage, &item.MovieTotalRatings,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user rating with movie: %w", err)
+		}
+
+		rating.ID = domainRating.RatingID(strings.TrimSpace(rid))
+		rating.UserID = users.UserID(strings.TrimSpace(ruserID))
+		rating.MovieID = movies.MovieID(strings.TrimSpace(rmovieID))
+		rating.Review = review.String
+		movie.ID = movies.MovieID(strings.TrimSpace(mid))
+		results = append(results, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating user ratings with movies: %w", err)
+	}
+
+	// COUNT(*) OVER() rides on the selected rows, so an offset past the
+	// user's last rating leaves total at zero; count the ratings on their own
+	if len(results) == 0 && opts.Offset > 0 {
+		countQuery := fmt.Sprintf(`
+			SELECT COUNT(*)
+			FROM ratings r
+			JOIN movies m ON m.id = r.movie_id
+			WHERE %s`, strings.Join(conditions, " AND "))
+		if err := r.db.GetContext(ctx, &total, countQuery, args[:len(args)-2]...); err != nil {
+			return nil, 0, fmt.Errorf("failed to count user ratings with movies: %w", err)
+		}
+	}
+
+	return results, total, nil
+}
+
+// Update applies the rating only if its stored version still equals
+// rating.Version, and bumps the version. A stale version yields
+// ErrVersionConflict so concurrent writers cannot overwrite each other.
 func (r *ratingRepository) Update(ctx context.Context, rating *domainRating.Rating) (*domainRating.Rating, error) {
 	query := `
 		UPDATE ratings SET
-			score = $2, review = $3, updated_at = $4
-		WHERE id = $1
-		RETURNING id, created_at, updated_at`
+			score = $2, review = $3, contains_spoilers = $4, contains_adult_language = $5, updated_at = $6, version = version + 1
+		WHERE id = $1 AND version = $7 AND deleted_at IS NULL
+		RETURNING id, created_at, updated_at, version`
 
 	rating.UpdatedAt = time.Now()
 
 	err := r.db.QueryRowContext(
 		ctx, query,
-		rating.ID, rating.Score, rating.Review, rating.UpdatedAt,
-	).Scan(&rating.ID, &rating.CreatedAt, &rating.UpdatedAt)
+		rating.ID, rating.Score, rating.Review, rating.ContainsSpoilers, rating.ContainsAdultLanguage, rating.UpdatedAt, rating.Version,
+	).Scan(&rating.ID, &rating.CreatedAt, &rating.UpdatedAt, &rating.Version)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
+			exists, existsErr := r.Exists(ctx, rating.ID)
+			if existsErr != nil {
+				return nil, existsErr
+			}
+			if exists {
+				return nil, domainRating.ErrVersionConflict
+			}
 			return nil, fmt.Errorf("rating with ID %s not found", rating.ID)
 		}
 		return nil, fmt.Errorf("failed to update rating: %w", err)
@@ -148,10 +319,12 @@ func (r *ratingRepository) Update(ctx context.Context, rating *domainRating.Rati
 	return rating, nil
 }
 
-func (r *ratingRepository) Delete(ctx context.Context, id domainRating.RatingID) error {
-	query := `DELETE FROM ratings WHERE id = $1`
+// Delete marks the rating deleted; the retention job removes the row once
+// it can no longer be restored
+func (r *ratingRepository) Delete(ctx context.Context, id domainRating.RatingID, deletedAt time.Time) error {
+	query := `UPDATE ratings SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := r.db.ExecContext(ctx, query, id, deletedAt)
 	if err != nil {
 		return fmt.Errorf("failed to delete rating: %w", err)
 	}
@@ -168,17 +341,68 @@ func (r *ratingRepository) Delete(ctx context.Context, id domainRating.RatingID)
 	return nil
 }
 
-func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*domainRating.MovieRatingStats, error) {
+// Restore clears deleted_at when it is not before deletedSince. When no row
+// changed it reads the rating again to tell why.
+func (r *ratingRepository) Restore(ctx context.Context, id domainRating.RatingID, deletedSince time.Time) (*domainRating.Rating, error) {
 	query := `
-		SELECT 
-			ROUND(AVG(score::decimal), 2) as average_score,
+		UPDATE ratings SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at >= $2
+		RETURNING id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, created_at, updated_at, version`
+
+	restored, err := r.queryRatings(ctx, query, id, deletedSince)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return nil, fmt.Errorf("user has already rated this movie")
+		}
+		return nil, fmt.Errorf("failed to restore rating: %w", err)
+	}
+	if len(restored) == 1 {
+		return restored[0], nil
+	}
+
+	var deletedAt sql.NullTime
+	err = r.db.QueryRowContext(ctx, `SELECT deleted_at FROM ratings WHERE id = $1`, id).Scan(&deletedAt)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, fmt.Errorf("rating with ID %s not found", id)
+	case err != nil:
+		return nil, fmt.Errorf("failed to restore rating: %w", err)
+	case !deletedAt.Valid:
+		return nil, domainRating.ErrNotDeleted
+	default:
+		return nil, domainRating.ErrRestoreExpired
+	}
+}
+
+// GetMovieStats reads the per-score counts and the overall totals, the row
+// whose score is NULL, in one ROLLUP. SQLite has no ROLLUP, so there the
+// totals row is a second select.
+func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*domainRating.MovieRatingStats, error) {
+	score := r.dialect.Decimal("score")
+	aggregates := func(scoreColumn string) string {
+		return `
+			ROUND(AVG(` + score + `), 2) as average_score,
 			COUNT(*) as total_ratings,
-			score,
-			COUNT(*) as score_count
+			` + scoreColumn + `,
+			COUNT(*) as score_count,
+			ROUND(AVG(` + score + `) FILTER (WHERE ` + criticRating("ratings") + `), 2) as critic_average,
+			COUNT(*) FILTER (WHERE ` + criticRating("ratings") + `) as critic_ratings,
+			ROUND(AVG(` + score + `) FILTER (WHERE NOT ` + criticRating("ratings") + `), 2) as audience_average,
+			COUNT(*) FILTER (WHERE NOT ` + criticRating("ratings") + `) as audience_ratings,
+			AVG(` + score + ` * ` + score + `) - AVG(` + score + `) * AVG(` + score + `) as score_variance
 		FROM ratings 
-		WHERE movie_id = $1
+		WHERE movie_id = $1 AND ` + visibleRating("ratings")
+	}
+	query := `SELECT ` + aggregates("score") + `
 		GROUP BY ROLLUP(score)
 		ORDER BY score`
+	if r.dialect == postgres.DialectSQLite {
+		query = `SELECT ` + aggregates("score") + `
+		GROUP BY score
+		UNION ALL
+		SELECT ` + aggregates("NULL") + `
+		ORDER BY 3`
+	}
 
 	rows, err := r.db.QueryContext(ctx, query, movieID)
 	if err != nil {
@@ -191,13 +415,15 @@ func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.Mov
 		ScoreCount: make(map[int]int64),
 	}
 
+	var totalVariance float64
 	for rows.Next() {
-		var avgScore sql.NullFloat64
-		var totalRatings int64
+		var avgScore, criticAvg, audienceAvg sql.NullFloat64
+		var totalRatings, criticRatings, audienceRatings int64
+		var variance sql.NullFloat64
 		var score sql.NullInt64
 		var scoreCount int64
 
-		err := rows.Scan(&avgScore, &totalRatings, &score, &scoreCount)
+		err := rows.Scan(&avgScore, &totalRatings, &score, &scoreCount, &criticAvg, &criticRatings, &audienceAvg, &audienceRatings, &variance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan movie stats: %w", err)
 		}
@@ -205,6 +431,9 @@ func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.Mov
 		if !score.Valid {
 			stats.AverageScore = avgScore.Float64
 			stats.TotalRatings = totalRatings
+			stats.Critics = domainRating.ScoreSummary{AverageScore: criticAvg.Float64, TotalRatings: criticRatings}
+			stats.Audience = domainRating.ScoreSummary{AverageScore: audienceAvg.Float64, TotalRatings: audienceRatings}
+			totalVariance = variance.Float64
 		} else {
 			stats.ScoreCount[int(score.Int64)] = scoreCount
 		}
@@ -213,12 +442,13 @@ func (r *ratingRepository) GetMovieStats(ctx context.Context, movieID movies.Mov
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating movie stats: %w", err)
 	}
+	stats.Describe(totalVariance)
 
 	return stats, nil
 }
 
 func (r *ratingRepository) Exists(ctx context.Context, id domainRating.RatingID) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM ratings WHERE id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM ratings WHERE id = $1 AND deleted_at IS NULL)`
 
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
@@ -230,7 +460,7 @@ func (r *ratingRepository) Exists(ctx context.Context, id domainRating.RatingID)
 }
 
 func (r *ratingRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM ratings`
+	query := `SELECT COUNT(*) FROM ratings WHERE deleted_at IS NULL`
 
 	var count int64
 	err := r.db.QueryRowContext(ctx, query).Scan(&count)
@@ -241,37 +471,221 @@ func (r *ratingRepository) Count(ctx context.Context) (int64, error) {
 	return count, nil
 }
 
-func (r *ratingRepository) GetGlobalAverageRating(ctx context.Context) (float64, error) {
+func (r *ratingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	query := fmt.Sprintf(`
+		SELECT r.movie_id FROM ratings r
+		WHERE %s
+		GROUP BY r.movie_id
+		ORDER BY COUNT(*) DESC, r.movie_id
+		LIMIT $1`, visibleRating("r"))
+
+	var ids []string
+	if err := r.db.SelectContext(ctx, &ids, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get most rated movies: %w", err)
+	}
+
+	movieIDs := make([]movies.MovieID, len(ids))
+	for i, id := range ids {
+		movieIDs[i] = movies.MovieID(strings.TrimSpace(id))
+	}
+	return movieIDs, nil
+}
+
+func (r *ratingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
 	query := `
-		SELECT ROUND(AVG(score::decimal), 2) as global_average
-		FROM ratings`
+		SELECT user_id FROM ratings
+		WHERE deleted_at IS NULL
+		GROUP BY user_id
+		ORDER BY COUNT(*) DESC, user_id
+		LIMIT $1`
+
+	var ids []string
+	if err := r.db.SelectContext(ctx, &ids, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get most active raters: %w", err)
+	}
+
+	userIDs := make([]users.UserID, len(ids))
+	for i, id := range ids {
+		userIDs[i] = users.UserID(strings.TrimSpace(id))
+	}
+	return userIDs, nil
+}
 
-	var globalAvg sql.NullFloat64
-	err := r.db.QueryRowContext(ctx, query).Scan(&globalAvg)
+// GetGlobalStats reads the rating_totals row that triggers on ratings and
+// users keep current
+func (r *ratingRepository) GetGlobalStats(ctx context.Context) (*domainRating.GlobalStats, error) {
+	query := `SELECT score_sum, rating_count, refreshed_at, updated_at FROM rating_totals`
+
+	var stats domainRating.GlobalStats
+	err := r.db.QueryRowContext(ctx, query).Scan(&stats.ScoreSum, &stats.TotalRatings, &stats.RefreshedAt, &stats.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global rating stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// RefreshGlobalStats locks the totals first, so writes that are in flight
+// finish before the ratings are summed and writes that follow wait and then
+// apply on top of the fresh totals
+func (r *ratingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*domainRating.GlobalStats, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to calculate global average rating: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Return default if no ratings exist yet
-	if !globalAvg.Valid {
-		return 3.0, nil // Default middle value for 1-5 scale
+	// A SQLite transaction holds the database's write lock from the start
+	if r.dialect == postgres.DialectPostgres {
+		if _, err := tx.ExecContext(ctx, `SELECT 1 FROM rating_totals FOR UPDATE`); err != nil {
+			return nil, fmt.Errorf("failed to lock global rating stats: %w", err)
+		}
 	}
 
-	return globalAvg.Float64, nil
+	query := `
+		UPDATE rating_totals SET
+			score_sum = d.visible_sum,
+			rating_count = d.visible_count,
+			refreshed_at = $1,
+			updated_at = $1
+		FROM (
+			SELECT COALESCE(SUM(score), 0) AS visible_sum, COUNT(*) AS visible_count
+			FROM ratings
+			WHERE ` + visibleRating("ratings") + `
+		) d
+		RETURNING score_sum, rating_count, refreshed_at, updated_at`
+
+	var stats domainRating.GlobalStats
+	err = tx.QueryRowContext(ctx, query, now).Scan(&stats.ScoreSum, &stats.TotalRatings, &stats.RefreshedAt, &stats.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh global rating stats: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit global rating stats: %w", err)
+	}
+	return &stats, nil
 }
 
-// Helper methods
-func (r *ratingRepository) getSortColumn(sortBy string) string {
-	switch sortBy {
-	case "score":
-		return "score"
-	case "created_at":
-		return "created_at"
-	case "updated_at":
-		return "updated_at"
-	default:
-		return "created_at"
+func (r *ratingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*domainRating.RatingActivity, error) {
+	query := `
+		SELECT score,
+			COUNT(*) FILTER (WHERE created_at >= $3) AS window_count,
+			COUNT(*) FILTER (WHERE created_at < $3) AS baseline_count
+		FROM ratings
+		WHERE movie_id = $1 AND created_at >= $2 AND ` + visibleRating("ratings") + `
+		GROUP BY score`
+
+	rows, err := r.db.QueryContext(ctx, query, movieID, baselineStart, windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rating activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := &domainRating.RatingActivity{
+		Window:   make(map[int]int64),
+		Baseline: make(map[int]int64),
+	}
+	for rows.Next() {
+		var score int
+		var windowCount, baselineCount int64
+		if err := rows.Scan(&score, &windowCount, &baselineCount); err != nil {
+			return nil, fmt.Errorf("failed to scan rating activity: %w", err)
+		}
+		activity.Window[score] = windowCount
+		activity.Baseline[score] = baselineCount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rating activity: %w", err)
+	}
+
+	return activity, nil
+}
+
+// GetRaterGroups counts the movie's visible ratings by score and by the
+// trust factors of their raters, with account age in whole days capped at
+// the age of full trust and rating counts capped at the count of full trust
+func (r *ratingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*domainRating.RaterGroup, error) {
+	maxAgeDays := int64(config.FullTrustAge.Hours() / 24)
+	query := `
+		SELECT r.score,
+			GREATEST(LEAST(FLOOR(EXTRACT(EPOCH FROM ($2 - u.created_at)) / 86400), $3), 0)::bigint AS age_days,
+			u.email_verified,
+			LEAST(rc.total, $4) AS rating_count,
+			u.report_count,
+			COUNT(*)
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS total FROM ratings ur WHERE ur.user_id = r.user_id AND ur.deleted_at IS NULL
+		) rc
+		WHERE r.movie_id = $1 AND ` + visibleRating("r") + `
+		GROUP BY 1, 2, 3, 4, 5`
+	if r.dialect == postgres.DialectSQLite {
+		query = `
+		SELECT r.score,
+			MAX(MIN(CAST(julianday($2) - julianday(u.created_at) AS INTEGER), $3), 0) AS age_days,
+			u.email_verified,
+			MIN((SELECT COUNT(*) FROM ratings ur WHERE ur.user_id = r.user_id AND ur.deleted_at IS NULL), $4) AS rating_count,
+			u.report_count,
+			COUNT(*)
+		FROM ratings r
+		JOIN users u ON u.id = r.user_id
+		WHERE r.movie_id = $1 AND ` + visibleRating("r") + `
+		GROUP BY 1, 2, 3, 4, 5`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, movieID, asOf, maxAgeDays, config.FullTrustRatings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rater groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*domainRating.RaterGroup
+	for rows.Next() {
+		group := &domainRating.RaterGroup{}
+		var ageDays int64
+		if err := rows.Scan(&group.Score, &ageDays, &group.Factors.EmailVerified,
+			&group.Factors.RatingCount, &group.Factors.ReportCount, &group.Ratings); err != nil {
+			return nil, fmt.Errorf("failed to scan rater group: %w", err)
+		}
+		group.Factors.AccountAge = time.Duration(ageDays) * 24 * time.Hour
+		groups = append(groups, group)
 	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rater groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ratingSortColumns maps the fields ratings can be sorted by onto their
+// columns
+var ratingSortColumns = map[string]string{
+	"created_at": "created_at",
+	"score":      "score",
+	"updated_at": "updated_at",
+}
+
+// joinedRatingSortColumns maps sort fields for queries joining ratings (r)
+// and movies (m)
+var joinedRatingSortColumns = map[string]string{
+	"created_at":   "r.created_at",
+	"release_year": "m.release_year",
+	"score":        "r.score",
+	"title":        "LOWER(m.title)",
+	"updated_at":   "r.updated_at",
+}
+
+// orderBy resolves the sort in opts to an ORDER BY list, created_at by
+// default
+func (r *ratingRepository) orderBy(opts domainRating.SearchOptions) string {
+	return sorting.OrderBy(sorting.Resolve(opts.SortBy, opts.Order, ratingSortColumns, "created_at"), ratingSortColumns)
+}
+
+// joinedOrderBy is orderBy for queries joining ratings and movies
+func (r *ratingRepository) joinedOrderBy(opts domainRating.SearchOptions) string {
+	return sorting.OrderBy(sorting.Resolve(opts.SortBy, opts.Order, joinedRatingSortColumns, "created_at"), joinedRatingSortColumns)
 }
 
 func (r *ratingRepository) queryRatings(ctx context.Context, query string, args ...interface{}) ([]*domainRating.Rating, error) {
@@ -285,13 +699,15 @@ func (r *ratingRepository) queryRatings(ctx context.Context, query string, args
 	for rows.Next() {
 		rating := &domainRating.Rating{}
 		var id, userID, movieID string
+		var review sql.NullString
 		err := rows.Scan(
 			&id, &userID, &movieID, &rating.Score,
-			&rating.Review, &rating.CreatedAt, &rating.UpdatedAt,
+			&review, &rating.ContainsSpoilers, &rating.ContainsAdultLanguage, &rating.CreatedAt, &rating.UpdatedAt, &rating.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan rating: %w", err)
 		}
+		rating.Review = review.String
 		// Trim any padding from the IDs
 		rating.ID = domainRating.RatingID(strings.TrimSpace(id))
 		rating.UserID = users.UserID(strings.TrimSpace(userID))
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type recommendationRepository struct {
+	movies *movieRepository
+}
+
+func NewRecommendationRepository(db *sqlx.DB) recommendations.Repository {
+	return &recommendationRepository{movies: &movieRepository{db: db}}
+}
+
+const recommendedMovieColumns = `
+	m.id, m.title, m.description, m.release_year, m.genre, m.director,
+	m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+	m.imdb_id, m.poster_url, m.created_at, m.updated_at`
+
+// unratedBy is a condition that drops movies the user bound to $1 rated;
+// a deleted rating leaves the movie unrated
+const unratedBy = `NOT EXISTS (SELECT 1 FROM ratings ur WHERE ur.movie_id = m.id AND ur.user_id = $1 AND ur.deleted_at IS NULL)`
+
+func (r *recommendationRepository) GetWatchlist(ctx context.Context, userID users.UserID, limit int) ([]*movies.Movie, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM movies m
+		JOIN (
+			SELECT lm.movie_id, MAX(lm.added_at) AS added_at
+			FROM list_movies lm
+			JOIN lists l ON l.id = lm.list_id
+			WHERE l.user_id = $1
+			GROUP BY lm.movie_id
+		) w ON w.movie_id = m.id
+		WHERE %s
+		ORDER BY w.added_at DESC, m.id
+		LIMIT $2`, recommendedMovieColumns, unratedBy)
+
+	return r.movies.queryMovies(ctx, query, userID, limit)
+}
+
+func (r *recommendationRepository) GetFavoriteGenre(ctx context.Context, userID users.UserID, minRatings int, asOf time.Time, halfLife time.Duration) (string, error) {
+	// The weight mirrors rating.DecayWeight: 2^(-age / half-life), and 1 for
+	// ratings from the future or without a half-life
+	query := `
+		SELECT genre
+		FROM (
+			SELECT m.genre, r.score,
+				   CASE WHEN $4::float8 > 0 AND r.created_at < $3::timestamptz
+						THEN POWER(2, -EXTRACT(EPOCH FROM ($3::timestamptz - r.created_at)) / $4::float8)
+						ELSE 1 END AS weight
+			FROM ratings r
+			JOIN movies m ON m.id = r.movie_id
+			WHERE r.user_id = $1 AND r.deleted_at IS NULL
+		) weighted
+		GROUP BY genre
+		HAVING COUNT(*) >= $2
+		ORDER BY SUM(weight * score) / SUM(weight) DESC, SUM(weight) DESC, genre
+		LIMIT 1`
+
+	var genre string
+	err := r.movies.db.QueryRowContext(ctx, query, userID, minRatings, asOf, halfLife.Seconds()).Scan(&genre)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get favorite genre: %w", err)
+	}
+	return genre, nil
+}
+
+func (r *recommendationRepository) GetTopInGenre(ctx context.Context, userID users.UserID, genre string, confidenceK float64, limit int) ([]*movies.Movie, error) {
+	query := fmt.Sprintf(`
+		WITH global AS (
+			SELECT COALESCE(AVG(score), 0) AS average FROM ratings WHERE %[2]s
+		)
+		SELECT %[1]s
+		FROM movies m
+		JOIN ratings r ON r.movie_id = m.id AND %[3]s
+		CROSS JOIN global g
+		WHERE LOWER(m.genre) = LOWER($2) AND %[4]s
+		GROUP BY m.id, g.average
+		ORDER BY %[5]s DESC, m.id
+		LIMIT $4`, recommendedMovieColumns, visibleRating("ratings"), visibleRating("r"), unratedBy,
+		bayesianAverage("AVG(r.score)", "COUNT(*)", "g.average", "$3::decimal"))
+
+	return r.movies.queryMovies(ctx, query, userID, genre, confidenceK, limit)
+}
+
+func (r *recommendationRepository) GetRecentFavorite(ctx context.Context, userID users.UserID, since time.Time, minScore int) (*movies.Movie, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.user_id = $1 AND r.created_at >= $2 AND r.score >= $3 AND r.deleted_at IS NULL
+		ORDER BY r.score DESC, r.created_at DESC, m.id
+		LIMIT 1`, recommendedMovieColumns)
+
+	found, err := r.movies.queryMovies(ctx, query, userID, since, minScore)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
+func (r *recommendationRepository) GetSimilar(ctx context.Context, userID users.UserID, movie *movies.Movie, limit int) ([]*movies.Movie, error) {
+	query := fmt.Sprintf(`
+		SELECT %[1]s
+		FROM movies m
+		LEFT JOIN ratings r ON r.movie_id = m.id AND %[2]s
+		WHERE m.id <> $2
+		  AND (LOWER(m.genre) = LOWER($3) OR LOWER(m.director) = LOWER($4))
+		  AND %[3]s
+		GROUP BY m.id
+		ORDER BY (LOWER(m.genre) = LOWER($3))::int + (LOWER(m.director) = LOWER($4))::int DESC,
+				 COALESCE(AVG(r.score), 0) DESC, COUNT(r.id) DESC, m.id
+		LIMIT $5`, recommendedMovieColumns, visibleRating("r"), unratedBy)
+
+	return r.movies.queryMovies(ctx, query, userID, movie.ID, movie.Genre, movie.Director, limit)
+}
+
+func (r *recommendationRepository) GetTrendingAmongSimilarUsers(ctx context.Context, userID users.UserID, since time.Time, neighbors, minScore, limit int) ([]*movies.Movie, error) {
+	query := fmt.Sprintf(`
+		WITH neighbors AS (
+			SELECT theirs.user_id
+			FROM ratings mine
+			JOIN ratings theirs ON theirs.movie_id = mine.movie_id AND theirs.user_id <> mine.user_id
+			WHERE mine.user_id = $1 AND mine.deleted_at IS NULL AND ABS(mine.score - theirs.score) <= 1 AND %[2]s
+			GROUP BY theirs.user_id
+			ORDER BY COUNT(*) DESC, theirs.user_id
+			LIMIT $3
+		)
+		SELECT %[1]s
+		FROM ratings r
+		JOIN neighbors n ON n.user_id = r.user_id
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.created_at >= $2 AND r.score >= $4 AND r.deleted_at IS NULL AND %[3]s
+		GROUP BY m.id
+		ORDER BY COUNT(*) DESC, AVG(r.score) DESC, m.id
+		LIMIT $5`, recommendedMovieColumns, visibleRating("theirs"), unratedBy)
+
+	return r.movies.queryMovies(ctx, query, userID, since, neighbors, minScore, limit)
+}
+
+func (r *recommendationRepository) GetPreferred(ctx context.Context, userID users.UserID, preferences *recommendations.Preferences, confidenceK float64, limit int) ([]*movies.Movie, error) {
+	genres := make([]string, len(preferences.Genres))
+	for i, genre := range preferences.Genres {
+		genres[i] = strings.ToLower(genre)
+	}
+	decades := make([]int64, len(preferences.Decades))
+	for i, decade := range preferences.Decades {
+		decades[i] = int64(decade)
+	}
+
+	query := fmt.Sprintf(`
+		WITH global AS (
+			SELECT COALESCE(AVG(score), 0) AS average FROM ratings WHERE %[2]s
+		)
+		SELECT %[1]s
+		FROM movies m
+		LEFT JOIN ratings r ON r.movie_id = m.id AND %[3]s
+		CROSS JOIN global g
+		WHERE (LOWER(m.genre) = ANY($2) OR m.release_year / 10 * 10 = ANY($3)) AND %[4]s
+		GROUP BY m.id, g.average
+		ORDER BY (LOWER(m.genre) = ANY($2))::int + (m.release_year / 10 * 10 = ANY($3))::int DESC,
+				 %[5]s DESC, m.id
+		LIMIT $5`, recommendedMovieColumns, visibleRating("ratings"), visibleRating("r"), unratedBy,
+		bayesianAverage("COALESCE(AVG(r.score), 0)", "COUNT(r.id)", "g.average", "$4::decimal"))
+
+	return r.movies.queryMovies(ctx, query, userID, pq.Array(genres), pq.Array(decades), confidenceK, limit)
+}
+
+func (r *recommendationRepository) CountRatings(ctx context.Context, userID users.UserID) (int, error) {
+	var count int
+	if err := r.movies.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ratings WHERE user_id = $1 AND deleted_at IS NULL`, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count ratings: %w", err)
+	}
+	return count, nil
+}
+
+func (r *recommendationRepository) SavePreferences(ctx context.Context, preferences *recommendations.Preferences) error {
+	decades := make([]int64, len(preferences.Decades))
+	for i, decade := range preferences.Decades {
+		decades[i] = int64(decade)
+	}
+
+	query := `
+		INSERT INTO user_preferences (user_id, genres, decades, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET genres = EXCLUDED.genres, decades = EXCLUDED.decades, updated_at = EXCLUDED.updated_at`
+
+	_, err := r.movies.db.ExecContext(ctx, query, preferences.UserID, pq.Array(preferences.Genres), pq.Array(decades), preferences.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return users.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return nil
+}
+
+func (r *recommendationRepository) GetPreferences(ctx context.Context, userID users.UserID) (*recommendations.Preferences, error) {
+	query := `SELECT user_id, genres, decades, updated_at FROM user_preferences WHERE user_id = $1`
+
+	preferences := &recommendations.Preferences{}
+	var id string
+	var genres pq.StringArray
+	var decades pq.Int64Array
+	err := r.movies.db.QueryRowContext(ctx, query, userID).Scan(&id, &genres, &decades, &preferences.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	preferences.UserID = users.UserID(strings.TrimSpace(id))
+	preferences.Genres = []string(genres)
+	preferences.Decades = make([]int, len(decades))
+	for i, decade := range decades {
+		preferences.Decades[i] = int(decade)
+	}
+	return preferences, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendationRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewRecommendationRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, id := range []string{"user-id-rec-me", "user-id-rec-alike", "user-id-rec-other"} {
+		_, err := db.Exec(`
+			INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+			VALUES ($1, $1 || '@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+		`, id)
+		require.NoError(t, err)
+	}
+	for _, m := range []struct{ id, title, genre, director string }{
+		{"test-id-rec-heat", "Heat", "Crime", "Michael Mann"},
+		{"test-id-rec-thief", "Thief", "Crime", "Michael Mann"},
+		{"test-id-rec-collateral", "Collateral", "Thriller", "Michael Mann"},
+		{"test-id-rec-ronin", "Ronin", "Crime", "John Frankenheimer"},
+		{"test-id-rec-alien", "Alien", "Horror", "Ridley Scott"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $2, '', 1995, $3, $4, 120, 'R', 'English', 'USA', NOW(), NOW())
+		`, m.id, m.title, m.genre, m.director)
+		require.NoError(t, err)
+	}
+	for _, r := range []struct {
+		id, userID, movieID string
+		score               int
+	}{
+		{"test-id-rec-r1", "user-id-rec-me", "test-id-rec-heat", 5},
+		{"test-id-rec-r2", "user-id-rec-me", "test-id-rec-alien", 2},
+		{"test-id-rec-r3", "user-id-rec-alike", "test-id-rec-heat", 4},
+		{"test-id-rec-r4", "user-id-rec-alike", "test-id-rec-ronin", 5},
+		{"test-id-rec-r5", "user-id-rec-other", "test-id-rec-heat", 1},
+		{"test-id-rec-r6", "user-id-rec-other", "test-id-rec-collateral", 5},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO ratings (id, user_id, movie_id, score, review, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, '', NOW(), NOW())
+		`, r.id, r.userID, r.movieID, r.score)
+		require.NoError(t, err)
+	}
+	_, err := db.Exec(`
+		INSERT INTO lists (id, user_id, name, visibility, share_slug) VALUES ('test-id-rec-list', 'user-id-rec-me', 'Watch later', 'private', 'recslug000000001');
+		INSERT INTO list_movies (list_id, movie_id, position, added_at) VALUES
+			('test-id-rec-list', 'test-id-rec-thief', 1, NOW() - INTERVAL '1 day'),
+			('test-id-rec-list', 'test-id-rec-ronin', 2, NOW()),
+			('test-id-rec-list', 'test-id-rec-heat', 3, NOW());
+	`)
+	require.NoError(t, err)
+
+	watchlist, err := repo.GetWatchlist(ctx, "user-id-rec-me", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-rec-ronin", "test-id-rec-thief"}, movieIDs(watchlist), "rated movies are left out")
+
+	genre, err := repo.GetFavoriteGenre(ctx, "user-id-rec-me", 1, now, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Crime", genre)
+	// A year later a new 1 outweighs the old 5, sinking Crime below Horror
+	later := now.AddDate(1, 0, 0)
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, review, created_at, updated_at)
+		VALUES ('test-id-rec-r7', 'user-id-rec-me', 'test-id-rec-thief', 1, '', $1, $1)
+	`, later)
+	require.NoError(t, err)
+	genre, err = repo.GetFavoriteGenre(ctx, "user-id-rec-me", 1, later, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "Horror", genre)
+	_, err = db.Exec(`DELETE FROM ratings WHERE id = 'test-id-rec-r7'`)
+	require.NoError(t, err)
+	genre, err = repo.GetFavoriteGenre(ctx, "user-id-rec-me", 2, now, 0)
+	require.NoError(t, err)
+	assert.Empty(t, genre)
+
+	top, err := repo.GetTopInGenre(ctx, "user-id-rec-me", "crime", 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-rec-ronin"}, movieIDs(top), "only rated movies rank")
+
+	favorite, err := repo.GetRecentFavorite(ctx, "user-id-rec-me", now.Add(-time.Hour), 4)
+	require.NoError(t, err)
+	require.NotNil(t, favorite)
+	assert.Equal(t, movies.MovieID("test-id-rec-heat"), favorite.ID)
+	favorite, err = repo.GetRecentFavorite(ctx, "user-id-rec-me", now.Add(time.Hour), 4)
+	require.NoError(t, err)
+	assert.Nil(t, favorite)
+
+	similar, err := repo.GetSimilar(ctx, "user-id-rec-me", &movies.Movie{ID: "test-id-rec-heat", Genre: "Crime", Director: "Michael Mann"}, 10)
+	require.NoError(t, err)
+	require.Len(t, similar, 3)
+	assert.Equal(t, movies.MovieID("test-id-rec-thief"), similar[0].ID, "genre and director both match")
+
+	trending, err := repo.GetTrendingAmongSimilarUsers(ctx, "user-id-rec-me", now.Add(-time.Hour), 5, 4, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-rec-ronin"}, movieIDs(trending), "only users who agreed on Heat count")
+}
+
+func TestRecommendationRepository_Preferences(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewRecommendationRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-pref', 'pref@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW());
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-pref-alien', 'Alien', '', 1979, 'Horror', 'Ridley Scott', 117, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-pref-halloween', 'Halloween', '', 1978, 'Horror', 'John Carpenter', 91, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-pref-scream', 'Scream', '', 1996, 'Horror', 'Wes Craven', 111, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-pref-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW());
+		INSERT INTO ratings (id, user_id, movie_id, score, review, created_at, updated_at)
+		VALUES ('test-id-pref-r1', 'user-id-pref', 'test-id-pref-halloween', 4, '', NOW(), NOW());
+	`)
+	require.NoError(t, err)
+
+	preferences, err := repo.GetPreferences(ctx, "user-id-pref")
+	require.NoError(t, err)
+	assert.Nil(t, preferences)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.SavePreferences(ctx, &recommendations.Preferences{UserID: "user-id-pref", Genres: []string{"Crime"}, Decades: []int{}, UpdatedAt: now}))
+	require.NoError(t, repo.SavePreferences(ctx, &recommendations.Preferences{UserID: "user-id-pref", Genres: []string{"Horror"}, Decades: []int{1970}, UpdatedAt: now}))
+	assert.ErrorIs(t, repo.SavePreferences(ctx, &recommendations.Preferences{UserID: "user-id-missing", Genres: []string{"Horror"}, UpdatedAt: now}), users.ErrUserNotFound)
+
+	preferences, err = repo.GetPreferences(ctx, "user-id-pref")
+	require.NoError(t, err)
+	require.NotNil(t, preferences)
+	assert.Equal(t, []string{"Horror"}, preferences.Genres, "saving replaces the preferences")
+	assert.Equal(t, []int{1970}, preferences.Decades)
+	assert.True(t, now.Equal(preferences.UpdatedAt))
+
+	preferred, err := repo.GetPreferred(ctx, "user-id-pref", preferences, 25, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []movies.MovieID{"test-id-pref-alien", "test-id-pref-scream"}, movieIDs(preferred), "matching genre and decade first, rated movies left out")
+
+	count, err := repo.CountRatings(ctx, "user-id-pref")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func movieIDs(list []*movies.Movie) []movies.MovieID {
+	ids := make([]movies.MovieID, len(list))
+	for i, movie := range list {
+		ids[i] = movie.ID
+	}
+	return ids
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// syncReleaseYear sets the release year of movie $1 to the year of its
+// earliest release; movies without releases keep theirs
+const syncReleaseYear = `
+	UPDATE movies m SET release_year = EXTRACT(YEAR FROM r.first_release)::INTEGER
+	FROM (SELECT MIN(release_date) AS first_release FROM movie_releases WHERE movie_id = $1) r
+	WHERE m.id = $1 AND r.first_release IS NOT NULL
+		AND m.release_year <> EXTRACT(YEAR FROM r.first_release)::INTEGER`
+
+type releaseRepository struct {
+	db *sqlx.DB
+}
+
+func NewReleaseRepository(db *sqlx.DB) movies.ReleaseRepository {
+	return &releaseRepository{db: db}
+}
+
+func (r *releaseRepository) SaveRelease(ctx context.Context, release *movies.Release) (*movies.Release, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin release transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO movie_releases (movie_id, region, type, release_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (movie_id, region, type) DO UPDATE SET
+			release_date = EXCLUDED.release_date,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at`
+
+	saved := *release
+	err = tx.QueryRowContext(
+		ctx, query,
+		release.MovieID, release.Region, release.Type,
+		release.ReleaseDate, release.CreatedAt, release.UpdatedAt,
+	).Scan(&saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, fmt.Errorf("movie with ID %s not found", release.MovieID)
+		}
+		return nil, fmt.Errorf("failed to save release: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, syncReleaseYear, release.MovieID); err != nil {
+		return nil, fmt.Errorf("failed to update release year: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit release: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (r *releaseRepository) GetReleases(ctx context.Context, movieID movies.MovieID) ([]*movies.Release, error) {
+	query := `
+		SELECT movie_id, region, type, release_date, created_at, updated_at
+		FROM movie_releases WHERE movie_id = $1
+		ORDER BY release_date, region, type`
+
+	rows, err := r.db.QueryContext(ctx, query, movieID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query releases: %w", err)
+	}
+	defer rows.Close()
+
+	releases := []*movies.Release{}
+	for rows.Next() {
+		release := &movies.Release{}
+		if err := rows.Scan(
+			&release.MovieID, &release.Region, &release.Type,
+			&release.ReleaseDate, &release.CreatedAt, &release.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan release: %w", err)
+		}
+		release.MovieID = movies.MovieID(strings.TrimSpace(string(release.MovieID)))
+		releases = append(releases, release)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+func (r *releaseRepository) DeleteRelease(ctx context.Context, movieID movies.MovieID, region string, releaseType movies.ReleaseType) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin release transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM movie_releases WHERE movie_id = $1 AND region = $2 AND type = $3`,
+		movieID, region, releaseType)
+	if err != nil {
+		return fmt.Errorf("failed to delete release: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s release in %s for movie %s not found", releaseType, region, movieID)
+	}
+
+	if _, err := tx.ExecContext(ctx, syncReleaseYear, movieID); err != nil {
+		return fmt.Errorf("failed to update release year: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit release: %w", err)
+	}
+
+	return nil
+}
+
+func (r *releaseRepository) ListUpcoming(ctx context.Context, filter movies.UpcomingFilter) ([]*movies.UpcomingRelease, int64, error) {
+	conditions := []string{"r.release_date BETWEEN $1 AND $2"}
+	args := []interface{}{filter.From, filter.To}
+	if filter.Region != "" {
+		args = append(args, filter.Region)
+		conditions = append(conditions, fmt.Sprintf("r.region = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("r.type = $%d", len(args)))
+	}
+	if filter.Policy != nil {
+		var condition string
+		condition, args = certifiedCondition("m", *filter.Policy, args)
+		conditions = append(conditions, condition)
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+		SELECT r.region, r.type, r.release_date, r.created_at, r.updated_at,
+			   m.id, m.title, m.description, m.release_year, m.genre, m.director,
+			   m.duration_mins, m.rating, m.language, m.country, m.budget, m.revenue, m.currency,
+			   m.imdb_id, m.poster_url, m.created_at, m.updated_at,
+			   COUNT(*) OVER()
+		FROM movie_releases r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE %s
+		ORDER BY r.release_date, m.title, r.region, r.type
+		LIMIT $%d OFFSET $%d`, strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query upcoming releases: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		upcoming = []*movies.UpcomingRelease{}
+		total    int64
+	)
+	for rows.Next() {
+		release := &movies.Release{}
+		movie := &movies.Movie{}
+		var id string
+		if err := rows.Scan(
+			&release.Region, &release.Type, &release.ReleaseDate, &release.CreatedAt, &release.UpdatedAt,
+			&id, &movie.Title, &movie.Description, &movie.ReleaseYear,
+			&movie.Genre, &movie.Director, &movie.DurationMins, &movie.Rating,
+			&movie.Language, &movie.Country, &movie.Budget, &movie.Revenue, &movie.Currency,
+			&movie.IMDbID, &movie.PosterURL, &movie.CreatedAt, &movie.UpdatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan upcoming release: %w", err)
+		}
+		movie.ID = movies.MovieID(strings.TrimSpace(id))
+		release.MovieID = movie.ID
+		upcoming = append(upcoming, &movies.UpcomingRelease{Release: release, Movie: movie})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating upcoming releases: %w", err)
+	}
+
+	return upcoming, total, nil
+}
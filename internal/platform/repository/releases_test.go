@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewReleaseRepository(db)
+	movieRepo := NewMovieRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-release-dune', 'Dune', '', 2021, 'Sci-Fi', 'Denis Villeneuve', 155, 'PG13', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-release-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	for _, release := range []*movies.Release{
+		{MovieID: "test-id-release-dune", Region: "US", Type: movies.ReleaseTheatrical, ReleaseDate: date(2021, 10, 22)},
+		{MovieID: "test-id-release-dune", Region: "DE", Type: movies.ReleaseTheatrical, ReleaseDate: date(2021, 9, 16)},
+		{MovieID: "test-id-release-dune", Region: "DE", Type: movies.ReleaseStreaming, ReleaseDate: date(2022, 2, 1)},
+		{MovieID: "test-id-release-heat", Region: "DE", Type: movies.ReleaseStreaming, ReleaseDate: date(2022, 1, 15)},
+	} {
+		release.CreatedAt, release.UpdatedAt = now, now
+		_, err := repo.SaveRelease(ctx, release)
+		require.NoError(t, err)
+	}
+	_, err = repo.SaveRelease(ctx, &movies.Release{MovieID: "missing", Region: "DE", Type: movies.ReleaseTheatrical, ReleaseDate: date(2021, 1, 1), CreatedAt: now, UpdatedAt: now})
+	assert.ErrorContains(t, err, "not found")
+
+	releases, err := repo.GetReleases(ctx, "test-id-release-dune")
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	assert.Equal(t, "DE", releases[0].Region, "earliest first")
+	assert.Equal(t, movies.MovieID("test-id-release-dune"), releases[0].MovieID)
+
+	heat, err := movieRepo.GetByID(ctx, "test-id-release-heat")
+	require.NoError(t, err)
+	assert.Equal(t, 2022, heat.ReleaseYear, "the year follows the earliest release")
+
+	// Moving the German premiere to an earlier year moves the release year
+	_, err = repo.SaveRelease(ctx, &movies.Release{MovieID: "test-id-release-dune", Region: "DE", Type: movies.ReleaseTheatrical, ReleaseDate: date(2020, 12, 18), CreatedAt: now, UpdatedAt: now})
+	require.NoError(t, err)
+	dune, err := movieRepo.GetByID(ctx, "test-id-release-dune")
+	require.NoError(t, err)
+	assert.Equal(t, 2020, dune.ReleaseYear)
+
+	require.NoError(t, repo.DeleteRelease(ctx, "test-id-release-dune", "DE", movies.ReleaseTheatrical))
+	dune, err = movieRepo.GetByID(ctx, "test-id-release-dune")
+	require.NoError(t, err)
+	assert.Equal(t, 2021, dune.ReleaseYear)
+	assert.ErrorContains(t, repo.DeleteRelease(ctx, "test-id-release-dune", "DE", movies.ReleaseTheatrical), "not found")
+
+	upcoming, total, err := repo.ListUpcoming(ctx, movies.UpcomingFilter{Region: "DE", From: date(2022, 1, 1), To: date(2022, 12, 31), Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, upcoming, 1)
+	assert.Equal(t, "Heat", upcoming[0].Movie.Title)
+	assert.Equal(t, movies.ReleaseStreaming, upcoming[0].Release.Type)
+
+	upcoming, total, err = repo.ListUpcoming(ctx, movies.UpcomingFilter{Type: movies.ReleaseTheatrical, From: date(2021, 1, 1), To: date(2021, 12, 31), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, upcoming, 1)
+	assert.Equal(t, "US", upcoming[0].Release.Region)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/retention"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type retentionRepository struct {
+	db *sqlx.DB
+}
+
+func NewRetentionRepository(db *sqlx.DB) retention.Repository {
+	return &retentionRepository{db: db}
+}
+
+// retentionTable says where the rows of a target live and when they became
+// eligible for deletion; $1 is the cut-off
+type retentionTable struct {
+	table    string
+	key      string
+	eligible string
+}
+
+var retentionTables = map[retention.Target]retentionTable{
+	retention.TargetSessions:       {table: "user_sessions", key: "id", eligible: "expires_at < $1 OR revoked_at < $1"},
+	retention.TargetMergeAudit:     {table: "movie_merges", key: "id", eligible: "merged_at < $1"},
+	retention.TargetJobs:           {table: "jobs", key: "id", eligible: "finished_at < $1"},
+	retention.TargetDeletedRatings: {table: "ratings", key: "id", eligible: "deleted_at < $1"},
+}
+
+// Purge deletes in batches, each in its own statement, so no lock is held
+// for long on tables the API writes to
+func (r *retentionRepository) Purge(ctx context.Context, target retention.Target, before time.Time, batchSize int, dryRun bool) (int64, error) {
+	t, ok := retentionTables[target]
+	if !ok {
+		return 0, fmt.Errorf("unknown retention target %q", target)
+	}
+
+	if dryRun {
+		var count int64
+		if err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM `+t.table+` WHERE `+t.eligible, before); err != nil {
+			return 0, fmt.Errorf("failed to count %s: %w", target, err)
+		}
+		return count, nil
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %[1]s WHERE %[2]s IN (SELECT %[2]s FROM %[1]s WHERE %[3]s LIMIT $2)`, t.table, t.key, t.eligible)
+	var deleted int64
+	for {
+		result, err := r.db.ExecContext(ctx, query, before, batchSize)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to purge %s: %w", target, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to purge %s: %w", target, err)
+		}
+		deleted += n
+		if n < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+func (r *retentionRepository) MediaReferences(ctx context.Context) (*retention.MediaReferences, error) {
+	refs := &retention.MediaReferences{Keys: make(map[string]bool), Prefixes: make(map[string]bool)}
+
+	var keys []string
+	if err := r.db.SelectContext(ctx, &keys, `SELECT storage_key FROM movie_posters`); err != nil {
+		return nil, fmt.Errorf("failed to list poster keys: %w", err)
+	}
+	// Uploads waiting for their job, e.g. rating imports
+	var uploads []string
+	if err := r.db.SelectContext(ctx, &uploads, `
+		SELECT payload->>'key' FROM jobs
+		WHERE status IN ('queued', 'running') AND payload->>'key' IS NOT NULL`,
+	); err != nil {
+		return nil, fmt.Errorf("failed to list job uploads: %w", err)
+	}
+	for _, key := range append(keys, uploads...) {
+		refs.Keys[key] = true
+	}
+
+	var avatars []string
+	if err := r.db.SelectContext(ctx, &avatars, `SELECT avatar_key FROM users WHERE avatar_key IS NOT NULL`); err != nil {
+		return nil, fmt.Errorf("failed to list avatar keys: %w", err)
+	}
+	for _, prefix := range avatars {
+		refs.Prefixes[prefix] = true
+	}
+	return refs, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/retention"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionRepository(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewRetentionRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	cutoff := now.Add(-24 * time.Hour)
+
+	_, err := db.Exec(`TRUNCATE TABLE movie_merges, jobs`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, avatar_key, created_at, updated_at)
+		VALUES ('user-id-retention', 'retention@example.com', 'password123', 'Test', 'User', 'user', true, 'avatars/user-id-retention/a1', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO user_sessions (id, user_id, created_at, last_used_at, expires_at, revoked_at) VALUES
+			('session-live', 'user-id-retention', $1, $1, $2, NULL),
+			('session-expired-recently', 'user-id-retention', $1, $1, $1, NULL),
+			('session-expired', 'user-id-retention', $3, $3, $3, NULL),
+			('session-revoked', 'user-id-retention', $3, $3, $2, $3)
+	`, now.Add(-time.Hour), now.Add(time.Hour), now.Add(-48*time.Hour))
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO movie_merges (source_movie_id, target_movie_id, merged_by, source_snapshot, merged_at) VALUES
+			('01HSOURCE00000000000000001', '01HTARGET00000000000000001', 'admin', '{}', $1),
+			('01HSOURCE00000000000000002', '01HTARGET00000000000000001', 'admin', '{}', $2)
+	`, now.Add(-48*time.Hour), now)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO jobs (id, type, status, payload, finished_at) VALUES
+			('job-old', 'ratings.import', 'succeeded', '{}', $1),
+			('job-recent', 'ratings.import', 'failed', '{}', $2),
+			('job-queued', 'ratings.import', 'queued', '{"key":"private/imports/ratings/a.ndjson"}', NULL)
+	`, now.Add(-48*time.Hour), now)
+	require.NoError(t, err)
+
+	// A dry run only counts
+	n, err := repo.Purge(ctx, retention.TargetSessions, cutoff, 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// Batches continue until nothing is left
+	n, err = repo.Purge(ctx, retention.TargetSessions, cutoff, 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	var sessions []string
+	require.NoError(t, db.Select(&sessions, `SELECT id FROM user_sessions WHERE user_id = 'user-id-retention' ORDER BY id`))
+	assert.Equal(t, []string{"session-expired-recently", "session-live"}, sessions)
+
+	n, err = repo.Purge(ctx, retention.TargetMergeAudit, cutoff, 100, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = repo.Purge(ctx, retention.TargetJobs, cutoff, 100, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = repo.Purge(ctx, retention.Target("unknown"), cutoff, 100, false)
+	assert.Error(t, err)
+
+	refs, err := repo.MediaReferences(ctx)
+	require.NoError(t, err)
+	assert.True(t, refs.Referenced("avatars/user-id-retention/a1/small.jpg"))
+	assert.True(t, refs.Referenced("private/imports/ratings/a.ndjson"))
+	assert.False(t, refs.Referenced("avatars/user-id-retention/a0/small.jpg"))
+}
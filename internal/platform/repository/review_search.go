@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"thermondo/internal/domain/movies"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/postgres"
+)
+
+// Matches in ts_headline output are delimited by control characters rather
+// than markup so the review can be escaped before <mark> tags are added
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var headlineOptions = fmt.Sprintf(
+	"StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \"",
+	headlineStart, headlineStop,
+)
+
+// SearchReviews matches websearch_to_tsquery syntax (words, "phrases", -not,
+// or) against the review_tsv index
+func (r *ratingRepository) SearchReviews(ctx context.Context, query string, filter domainRating.ReviewFilter, options ...domainRating.SearchOption) ([]*domainRating.ReviewMatch, int64, error) {
+	opts := domainRating.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if r.dialect == postgres.DialectSQLite {
+		return r.searchReviewsSQLite(ctx, query, filter, opts)
+	}
+
+	conditions, args := reviewFilterConditions([]string{"r.review_tsv @@ q.query"}, []interface{}{query}, filter, opts)
+
+	// ts_headline is costly, so it only runs on the page being returned
+	args = append(args, headlineOptions, opts.Limit, opts.Offset)
+	sqlQuery := fmt.Sprintf(`
+		SELECT p.id, p.user_id, p.movie_id, p.score, p.review, p.contains_spoilers, p.contains_adult_language, p.created_at, p.updated_at, p.version,
+			   p.rank, ts_headline('english', p.review, p.query, $%d), p.total_count
+		FROM (
+			SELECT r.id, r.user_id, r.movie_id, r.score, r.review, r.contains_spoilers, r.contains_adult_language, r.created_at, r.updated_at, r.version,
+				   ts_rank(r.review_tsv, q.query) AS rank, q.query,
+				   COUNT(*) OVER() AS total_count
+			FROM ratings r, websearch_to_tsquery('english', $1) AS q(query)
+			WHERE %s
+			ORDER BY rank DESC, r.created_at DESC, r.id
+			LIMIT $%d OFFSET $%d
+		) p
+		ORDER BY p.rank DESC, p.created_at DESC, p.id`,
+		len(args)-2, strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	return r.queryReviewMatches(ctx, sqlQuery, args, nil)
+}
+
+// searchReviewsSQLite stands in for full-text search on SQLite: every word
+// of query must occur in the review, case insensitively, and words prefixed
+// with - must not. Reviews rank by how often the words occur.
+func (r *ratingRepository) searchReviewsSQLite(ctx context.Context, query string, filter domainRating.ReviewFilter, opts domainRating.SearchOptions) ([]*domainRating.ReviewMatch, int64, error) {
+	var include, exclude []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, `"`)
+		if excluded, ok := strings.CutPrefix(word, "-"); ok {
+			if excluded != "" {
+				exclude = append(exclude, excluded)
+			}
+		} else if word != "" && word != "or" {
+			include = append(include, word)
+		}
+	}
+	if len(include) == 0 {
+		return nil, 0, nil
+	}
+
+	var (
+		args       []interface{}
+		conditions []string
+		ranks      []string
+	)
+	for _, word := range include {
+		args = append(args, word)
+		conditions = append(conditions, fmt.Sprintf("instr(lower(r.review), $%d) > 0", len(args)))
+		ranks = append(ranks, fmt.Sprintf("(length(lower(r.review)) - length(replace(lower(r.review), $%[1]d, ''))) / length($%[1]d)", len(args)))
+	}
+	for _, word := range exclude {
+		args = append(args, word)
+		conditions = append(conditions, fmt.Sprintf("instr(lower(r.review), $%d) = 0", len(args)))
+	}
+	conditions, args = reviewFilterConditions(conditions, args, filter, opts)
+
+	args = append(args, opts.Limit, opts.Offset)
+	sqlQuery := fmt.Sprintf(`
+		SELECT r.id, r.user_id, r.movie_id, r.score, r.review, r.contains_spoilers, r.contains_adult_language, r.created_at, r.updated_at, r.version,
+			   %s AS rank, r.review, COUNT(*) OVER() AS total_count
+		FROM ratings r
+		WHERE %s
+		ORDER BY rank DESC, r.created_at DESC, r.id
+		LIMIT $%d OFFSET $%d`,
+		strings.Join(ranks, " + "), strings.Join(conditions, " AND "), len(args)-1, len(args))
+
+	return r.queryReviewMatches(ctx, sqlQuery, args, include)
+}
+
+// reviewFilterConditions adds the conditions filter and opts put on the
+// ratings r searched to conditions, and their values to args
+func reviewFilterConditions(conditions []string, args []interface{}, filter domainRating.ReviewFilter, opts domainRating.SearchOptions) ([]string, []interface{}) {
+	conditions = append(conditions, visibleRating("r"))
+	if opts.HideAdultLanguage {
+		conditions = append(conditions, "NOT r.contains_adult_language")
+	}
+
+	if filter.MovieID != "" {
+		args = append(args, filter.MovieID)
+		conditions = append(conditions, fmt.Sprintf("r.movie_id = $%d", len(args)))
+	}
+	if filter.MinScore != nil {
+		args = append(args, *filter.MinScore)
+		conditions = append(conditions, fmt.Sprintf("r.score >= $%d", len(args)))
+	}
+	if filter.MaxScore != nil {
+		args = append(args, *filter.MaxScore)
+		conditions = append(conditions, fmt.Sprintf("r.score <= $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("r.created_at <= $%d", len(args)))
+	}
+	return conditions, args
+}
+
+// queryReviewMatches scans review matches whose last three columns are the
+// rank, the headline and a COUNT(*) OVER() total. Without words the
+// headline is a ts_headline result; with them it is the review, in which
+// the words are marked here.
+func (r *ratingRepository) queryReviewMatches(ctx context.Context, sqlQuery string, args []interface{}, words []string) ([]*domainRating.ReviewMatch, int64, error) {
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		matches []*domainRating.ReviewMatch
+		total   int64
+	)
+	for rows.Next() {
+		rating := &domainRating.Rating{}
+		match := &domainRating.ReviewMatch{Rating: rating}
+		var id, userID, movieID string
+		var review, headline sql.NullString
+
+		err := rows.Scan(
+			&id, &userID, &movieID, &rating.Score, &review, &rating.ContainsSpoilers, &rating.ContainsAdultLanguage,
+			&rating.CreatedAt, &rating.UpdatedAt, &rating.Version,
+			&match.Rank, &headline, &total,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan review match: %w", err)
+		}
+
+		rating.ID = domainRating.RatingID(strings.TrimSpace(id))
+		rating.UserID = users.UserID(strings.TrimSpace(userID))
+		rating.MovieID = movies.MovieID(strings.TrimSpace(movieID))
+		rating.Review = review.String
+		if words != nil {
+			headline.String = markWords(headline.String, words)
+		}
+		match.Snippet = highlightSnippet(headline.String)
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating review matches: %w", err)
+	}
+
+	return matches, total, nil
+}
+
+// highlightSnippet escapes a ts_headline result and turns its match
+// delimiters into <mark> tags
+func highlightSnippet(headline string) string {
+	return strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>").
+		Replace(html.EscapeString(headline))
+}
+
+// markWords delimits every case-insensitive occurrence of words in review
+// as ts_headline delimits its matches
+func markWords(review string, words []string) string {
+	lower := strings.ToLower(review)
+	if len(lower) != len(review) {
+		// Lower-casing changed byte offsets; leave the matches unmarked
+		return review
+	}
+	marked := make([]bool, len(review))
+	for _, word := range words {
+		for i := 0; ; {
+			j := strings.Index(lower[i:], word)
+			if j < 0 {
+				break
+			}
+			for k := i + j; k < i+j+len(word); k++ {
+				marked[k] = true
+			}
+			i += j + len(word)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(review); {
+		j := i
+		for j < len(review) && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString(headlineStart + review[i:j] + headlineStop)
+		} else {
+			b.WriteString(review[i:j])
+		}
+		i = j
+	}
+	return b.String()
+}
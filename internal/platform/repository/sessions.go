@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type sessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) users.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at`
+
+func (s *sessionRepository) Create(ctx context.Context, session *users.Session) error {
+	query := `
+		INSERT INTO user_sessions (id, user_id, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		session.ID, session.UserID, session.UserAgent, session.IP,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+func (s *sessionRepository) FindByID(ctx context.Context, id users.SessionID) (*users.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM user_sessions WHERE id = $1`
+
+	var session users.Session
+	if err := s.db.GetContext(ctx, &session, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *sessionRepository) ListActive(ctx context.Context, userID users.UserID, now time.Time) ([]*users.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_used_at DESC, id`
+
+	var sessions []*users.Session
+	if err := s.db.SelectContext(ctx, &sessions, query, userID, now); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *sessionRepository) Touch(ctx context.Context, id users.SessionID, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE user_sessions SET last_used_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+func (s *sessionRepository) Revoke(ctx context.Context, userID users.UserID, id users.SessionID, at time.Time) error {
+	query := `
+		UPDATE user_sessions SET revoked_at = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > $3`
+
+	result, err := s.db.ExecContext(ctx, query, id, userID, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if rows == 0 {
+		return users.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (s *sessionRepository) RevokeAll(ctx context.Context, userID users.UserID, at time.Time) (int64, error) {
+	query := `
+		UPDATE user_sessions SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2`
+
+	result, err := s.db.ExecContext(ctx, query, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewSessionRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-sessions', 'sessions@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, s := range []struct {
+		id      users.SessionID
+		expires time.Time
+	}{
+		{"session-laptop", now.Add(time.Hour)},
+		{"session-phone", now.Add(time.Hour)},
+		{"session-expired", now.Add(-time.Minute)},
+	} {
+		used := now.Add(time.Duration(-i) * time.Minute)
+		require.NoError(t, repo.Create(ctx, &users.Session{
+			ID: s.id, UserID: "user-id-sessions", UserAgent: "agent", IP: "203.0.113.7",
+			CreatedAt: used, LastUsedAt: used, ExpiresAt: s.expires,
+		}))
+	}
+
+	active, err := repo.ListActive(ctx, "user-id-sessions", now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, users.SessionID("session-laptop"), active[0].ID)
+
+	// Touching moves the session to the top of the list
+	require.NoError(t, repo.Touch(ctx, "session-phone", now.Add(time.Minute)))
+	active, err = repo.ListActive(ctx, "user-id-sessions", now)
+	require.NoError(t, err)
+	assert.Equal(t, users.SessionID("session-phone"), active[0].ID)
+
+	require.NoError(t, repo.Revoke(ctx, "user-id-sessions", "session-phone", now))
+	assert.ErrorIs(t, repo.Revoke(ctx, "user-id-sessions", "session-phone", now), users.ErrSessionNotFound)
+	assert.ErrorIs(t, repo.Revoke(ctx, "someone-else", "session-laptop", now), users.ErrSessionNotFound)
+
+	revoked, err := repo.FindByID(ctx, "session-phone")
+	require.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	assert.False(t, revoked.Active(now))
+
+	count, err := repo.RevokeAll(ctx, "user-id-sessions", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	active, err = repo.ListActive(ctx, "user-id-sessions", now)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(t, err, users.ErrSessionNotFound)
+}
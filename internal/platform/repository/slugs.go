@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type slugRepository struct {
+	db *sqlx.DB
+}
+
+func NewSlugRepository(db *sqlx.DB) movies.SlugRepository {
+	return &slugRepository{db: db}
+}
+
+func (s *slugRepository) AssignSlug(ctx context.Context, movieID movies.MovieID, base string, assignedAt time.Time) (string, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin slug transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Movies given the same base slug at once would pick the same candidate
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, base); err != nil {
+		return "", fmt.Errorf("failed to lock slug: %w", err)
+	}
+
+	var current sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT s.slug
+		FROM movies m
+		LEFT JOIN movie_slugs s ON s.movie_id = m.id AND s.is_current
+		WHERE m.id = $1
+		FOR UPDATE OF m`, movieID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("movie with ID %s not found", movieID)
+		}
+		return "", fmt.Errorf("failed to get current slug: %w", err)
+	}
+	if current.Valid && movies.IsSlugCandidate(current.String, base) {
+		return current.String, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT slug, movie_id FROM movie_slugs
+		WHERE slug = $1 OR slug LIKE $2`, base, escapeLike(base)+`-%`)
+	if err != nil {
+		return "", fmt.Errorf("failed to query slugs: %w", err)
+	}
+	owners := make(map[string]movies.MovieID)
+	for rows.Next() {
+		var slug, owner string
+		if err := rows.Scan(&slug, &owner); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("failed to scan slug: %w", err)
+		}
+		owners[slug] = movies.MovieID(strings.TrimSpace(owner))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error iterating slugs: %w", err)
+	}
+
+	// A slug the movie had before is its own to take back
+	slug := base
+	for n := 2; ; n++ {
+		owner, taken := owners[slug]
+		if !taken || owner == movieID {
+			break
+		}
+		slug = movies.SlugCandidate(base, n)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE movie_slugs SET is_current = FALSE WHERE movie_id = $1 AND is_current`, movieID); err != nil {
+		return "", fmt.Errorf("failed to retire slug: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO movie_slugs (slug, movie_id, is_current, created_at)
+		VALUES ($1, $2, TRUE, $3)
+		ON CONFLICT (slug) DO UPDATE SET is_current = TRUE
+		WHERE movie_slugs.movie_id = EXCLUDED.movie_id`,
+		slug, movieID, assignedAt)
+	if err != nil {
+		return "", fmt.Errorf("failed to save slug: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit slug transaction: %w", err)
+	}
+	return slug, nil
+}
+
+func (s *slugRepository) CurrentSlug(ctx context.Context, movieID movies.MovieID) (string, error) {
+	var slug string
+	err := s.db.QueryRowContext(ctx, `SELECT slug FROM movie_slugs WHERE movie_id = $1 AND is_current`, movieID).Scan(&slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("slug of movie %s not found", movieID)
+		}
+		return "", fmt.Errorf("failed to get slug: %w", err)
+	}
+	return slug, nil
+}
+
+func (s *slugRepository) ResolveSlug(ctx context.Context, slug string) (movies.MovieID, string, error) {
+	var movieID, current string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT s.movie_id, c.slug
+		FROM movie_slugs s
+		JOIN movie_slugs c ON c.movie_id = s.movie_id AND c.is_current
+		WHERE s.slug = $1`, slug).Scan(&movieID, &current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", "", fmt.Errorf("slug %s not found", slug)
+		}
+		return "", "", fmt.Errorf("failed to resolve slug: %w", err)
+	}
+	return movies.MovieID(strings.TrimSpace(movieID)), current, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewSlugRepository(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-slug-heat', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW()),
+			   ('test-id-slug-remake', 'Heat', '', 1995, 'Crime', 'Someone Else', 120, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	slug, err := repo.AssignSlug(ctx, "test-id-slug-heat", "heat-1995", now)
+	require.NoError(t, err)
+	assert.Equal(t, "heat-1995", slug)
+	slug, err = repo.AssignSlug(ctx, "test-id-slug-heat", "heat-1995", now)
+	require.NoError(t, err)
+	assert.Equal(t, "heat-1995", slug, "assigning again keeps the slug")
+
+	slug, err = repo.AssignSlug(ctx, "test-id-slug-remake", "heat-1995", now)
+	require.NoError(t, err)
+	assert.Equal(t, "heat-1995-2", slug, "the second movie is numbered")
+
+	// Renaming keeps the old slug pointing at the movie
+	slug, err = repo.AssignSlug(ctx, "test-id-slug-remake", "heat-2-1995", now)
+	require.NoError(t, err)
+	assert.Equal(t, "heat-2-1995", slug)
+	movieID, current, err := repo.ResolveSlug(ctx, "heat-1995-2")
+	require.NoError(t, err)
+	assert.Equal(t, movies.MovieID("test-id-slug-remake"), movieID)
+	assert.Equal(t, "heat-2-1995", current)
+
+	// Renaming back takes the old slug again
+	slug, err = repo.AssignSlug(ctx, "test-id-slug-remake", "heat-1995", now)
+	require.NoError(t, err)
+	assert.Equal(t, "heat-1995-2", slug)
+	current, err = repo.CurrentSlug(ctx, "test-id-slug-remake")
+	require.NoError(t, err)
+	assert.Equal(t, "heat-1995-2", current)
+
+	_, _, err = repo.ResolveSlug(ctx, "alien-1979")
+	assert.ErrorContains(t, err, "not found")
+	_, err = repo.AssignSlug(ctx, "missing", "missing-2000", now)
+	assert.ErrorContains(t, err, "movie with ID missing not found")
+
+	_, err = db.Exec(`DELETE FROM movies WHERE id = 'test-id-slug-heat'`)
+	require.NoError(t, err)
+	_, _, err = repo.ResolveSlug(ctx, "heat-1995")
+	assert.ErrorContains(t, err, "not found", "slugs go with their movie")
+}
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS rating_totals_banned_user_delete;
+DROP TRIGGER IF EXISTS rating_totals_shadow_bans;
+DROP TRIGGER IF EXISTS rating_totals_update;
+DROP TRIGGER IF EXISTS rating_totals_delete;
+DROP TRIGGER IF EXISTS rating_totals_insert;
+DROP TABLE IF EXISTS rating_totals;
+DROP TABLE IF EXISTS ratings;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS movies;
@@ -0,0 +1,137 @@
+-- Movies, users and ratings for STORAGE=sqlite, with the columns the
+-- Postgres migrations arrive at. Timestamps are stored as UTC text in a
+-- fixed-width format, so they order and compare as text.
+CREATE TABLE movies (
+    id CHAR(26) NOT NULL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL CHECK (TRIM(title) != ''),
+    description TEXT,
+    release_year INTEGER NOT NULL CHECK (release_year >= 1888),
+    genre VARCHAR(100) NOT NULL CHECK (TRIM(genre) != ''),
+    director VARCHAR(255) NOT NULL CHECK (TRIM(director) != ''),
+    duration_mins INTEGER NOT NULL CHECK (duration_mins > 0),
+    rating VARCHAR(10),
+    language VARCHAR(100) NOT NULL CHECK (TRIM(language) != ''),
+    country VARCHAR(100) NOT NULL CHECK (TRIM(country) != ''),
+    budget BIGINT CHECK (budget IS NULL OR budget >= 0),
+    revenue BIGINT CHECK (revenue IS NULL OR revenue >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    imdb_id VARCHAR(20),
+    poster_url TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00')
+);
+
+CREATE INDEX idx_movies_created_at ON movies (created_at DESC);
+CREATE INDEX idx_movies_title_lower ON movies (LOWER(title));
+CREATE INDEX idx_movies_genre ON movies (genre);
+CREATE INDEX idx_movies_director_lower ON movies (LOWER(director));
+CREATE INDEX idx_movies_release_year ON movies (release_year);
+CREATE INDEX idx_movies_imdb_id ON movies (imdb_id) WHERE imdb_id IS NOT NULL;
+
+CREATE TABLE users (
+    id VARCHAR(36) NOT NULL PRIMARY KEY,
+    first_name VARCHAR(100) NOT NULL,
+    last_name VARCHAR(100) NOT NULL,
+    email TEXT NOT NULL,
+    email_hash VARCHAR(64),
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    avatar_key VARCHAR(255),
+    shadow_banned BOOLEAN NOT NULL DEFAULT FALSE,
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    report_count INTEGER NOT NULL DEFAULT 0,
+    is_critic BOOLEAN NOT NULL DEFAULT FALSE,
+    content_mode VARCHAR(16) NOT NULL DEFAULT 'standard' CHECK (content_mode IN ('standard', 'kids')),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00')
+);
+
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+CREATE UNIQUE INDEX idx_users_email_hash ON users (email_hash);
+CREATE INDEX idx_users_created_at ON users (created_at);
+
+CREATE TABLE ratings (
+    id CHAR(26) NOT NULL PRIMARY KEY,
+    user_id CHAR(26) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id CHAR(26) NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score >= 1 AND score <= 5),
+    review TEXT,
+    contains_spoilers BOOLEAN NOT NULL DEFAULT FALSE,
+    contains_adult_language BOOLEAN NOT NULL DEFAULT FALSE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+
+    UNIQUE (user_id, movie_id)
+);
+
+CREATE INDEX idx_ratings_user_created ON ratings (user_id, created_at DESC);
+CREATE INDEX idx_ratings_movie_id ON ratings (movie_id);
+
+-- Running totals of visible ratings behind the global average, kept as in
+-- Postgres but by row-level triggers, which are all SQLite has
+CREATE TABLE rating_totals (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    score_sum BIGINT NOT NULL DEFAULT 0,
+    rating_count BIGINT NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00')
+);
+
+INSERT INTO rating_totals DEFAULT VALUES;
+
+CREATE TRIGGER rating_totals_insert
+    AFTER INSERT ON ratings
+    WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + NEW.score,
+        rating_count = rating_count + 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_delete
+    AFTER DELETE ON ratings
+    WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = OLD.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum - OLD.score,
+        rating_count = rating_count - 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_update
+    AFTER UPDATE OF score ON ratings
+    WHEN NEW.score <> OLD.score
+        AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + NEW.score - OLD.score,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+-- Banning or unbanning a user hides or shows all of their ratings
+CREATE TRIGGER rating_totals_shadow_bans
+    AFTER UPDATE OF shadow_banned ON users
+    WHEN NEW.shadow_banned <> OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = NEW.id)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = NEW.id)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+-- When a shadow-banned user is deleted, their ratings are deleted by the
+-- cascade after the user row is gone and would be subtracted as visible.
+-- Count them back in first so the cascade evens out.
+CREATE TRIGGER rating_totals_banned_user_delete
+    BEFORE DELETE ON users
+    WHEN OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = OLD.id),
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = OLD.id);
+END;
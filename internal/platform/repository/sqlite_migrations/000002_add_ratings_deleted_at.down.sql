@@ -0,0 +1,80 @@
+DROP TRIGGER IF EXISTS rating_totals_banned_user_delete;
+DROP TRIGGER IF EXISTS rating_totals_shadow_bans;
+
+CREATE TABLE ratings_old (
+    id CHAR(26) NOT NULL PRIMARY KEY,
+    user_id CHAR(26) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id CHAR(26) NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score >= 1 AND score <= 5),
+    review TEXT,
+    contains_spoilers BOOLEAN NOT NULL DEFAULT FALSE,
+    contains_adult_language BOOLEAN NOT NULL DEFAULT FALSE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+
+    UNIQUE (user_id, movie_id)
+);
+
+-- Deleted ratings are not in the totals and would come back
+INSERT INTO ratings_old (id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, version, created_at, updated_at)
+SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, version, created_at, updated_at
+FROM ratings
+WHERE deleted_at IS NULL;
+
+DROP TABLE ratings;
+ALTER TABLE ratings_old RENAME TO ratings;
+
+CREATE INDEX idx_ratings_user_created ON ratings (user_id, created_at DESC);
+CREATE INDEX idx_ratings_movie_id ON ratings (movie_id);
+
+CREATE TRIGGER rating_totals_insert
+    AFTER INSERT ON ratings
+    WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + NEW.score,
+        rating_count = rating_count + 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_delete
+    AFTER DELETE ON ratings
+    WHEN NOT EXISTS (SELECT 1 FROM users u WHERE u.id = OLD.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum - OLD.score,
+        rating_count = rating_count - 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_update
+    AFTER UPDATE OF score ON ratings
+    WHEN NEW.score <> OLD.score
+        AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + NEW.score - OLD.score,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_shadow_bans
+    AFTER UPDATE OF shadow_banned ON users
+    WHEN NEW.shadow_banned <> OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = NEW.id)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = NEW.id)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_banned_user_delete
+    BEFORE DELETE ON users
+    WHEN OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = OLD.id),
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = OLD.id);
+END;
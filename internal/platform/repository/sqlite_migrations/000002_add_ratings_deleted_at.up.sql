@@ -0,0 +1,91 @@
+-- Soft-deleted ratings, as in the Postgres migrations. Only live ratings
+-- are unique per user and movie, and SQLite cannot drop a table constraint,
+-- so the ratings table is rebuilt; the triggers that read it go with it.
+DROP TRIGGER IF EXISTS rating_totals_banned_user_delete;
+DROP TRIGGER IF EXISTS rating_totals_shadow_bans;
+
+CREATE TABLE ratings_new (
+    id CHAR(26) NOT NULL PRIMARY KEY,
+    user_id CHAR(26) NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    movie_id CHAR(26) NOT NULL REFERENCES movies (id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score >= 1 AND score <= 5),
+    review TEXT,
+    contains_spoilers BOOLEAN NOT NULL DEFAULT FALSE,
+    contains_adult_language BOOLEAN NOT NULL DEFAULT FALSE,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00'),
+    deleted_at TIMESTAMP
+);
+
+INSERT INTO ratings_new (id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, version, created_at, updated_at)
+SELECT id, user_id, movie_id, score, review, contains_spoilers, contains_adult_language, version, created_at, updated_at
+FROM ratings;
+
+DROP TABLE ratings;
+ALTER TABLE ratings_new RENAME TO ratings;
+
+CREATE INDEX idx_ratings_user_created ON ratings (user_id, created_at DESC);
+CREATE INDEX idx_ratings_movie_id ON ratings (movie_id);
+CREATE UNIQUE INDEX idx_ratings_user_movie_live ON ratings (user_id, movie_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ratings_deleted_at ON ratings (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- The totals count live ratings only; deleting and restoring a rating is
+-- an update that takes it out of or puts it back into the totals
+CREATE TRIGGER rating_totals_insert
+    AFTER INSERT ON ratings
+    WHEN NEW.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + NEW.score,
+        rating_count = rating_count + 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_delete
+    AFTER DELETE ON ratings
+    WHEN OLD.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = OLD.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum - OLD.score,
+        rating_count = rating_count - 1,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_update
+    AFTER UPDATE OF score, deleted_at ON ratings
+    WHEN (NEW.score <> OLD.score OR (NEW.deleted_at IS NULL) <> (OLD.deleted_at IS NULL))
+        AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = NEW.user_id AND u.shadow_banned)
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum
+            + CASE WHEN NEW.deleted_at IS NULL THEN NEW.score ELSE 0 END
+            - CASE WHEN OLD.deleted_at IS NULL THEN OLD.score ELSE 0 END,
+        rating_count = rating_count
+            + CASE WHEN NEW.deleted_at IS NULL THEN 1 ELSE 0 END
+            - CASE WHEN OLD.deleted_at IS NULL THEN 1 ELSE 0 END,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_shadow_bans
+    AFTER UPDATE OF shadow_banned ON users
+    WHEN NEW.shadow_banned <> OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = NEW.id AND deleted_at IS NULL)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = NEW.id AND deleted_at IS NULL)
+            * CASE WHEN NEW.shadow_banned THEN -1 ELSE 1 END,
+        updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') || '000000+00:00';
+END;
+
+CREATE TRIGGER rating_totals_banned_user_delete
+    BEFORE DELETE ON users
+    WHEN OLD.shadow_banned
+BEGIN
+    UPDATE rating_totals SET
+        score_sum = score_sum + (SELECT COALESCE(SUM(score), 0) FROM ratings WHERE user_id = OLD.id AND deleted_at IS NULL),
+        rating_count = rating_count + (SELECT COUNT(*) FROM ratings WHERE user_id = OLD.id AND deleted_at IS NULL);
+END;
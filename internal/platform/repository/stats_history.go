@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type statsHistoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewStatsHistoryRepository(db *sqlx.DB) rating.StatsHistoryRepository {
+	return &statsHistoryRepository{db: db}
+}
+
+func (r *statsHistoryRepository) SnapshotStats(ctx context.Context, date time.Time, globalAverage, confidenceK float64) (int64, error) {
+	query := `
+		INSERT INTO movie_stats_history (movie_id, snapshot_date, average_score, bayesian_average, total_ratings)
+		SELECT movie_id, $1::date,
+			ROUND(AVG(score::decimal), 2),
+			ROUND(` + bayesianAverage("AVG(score)", "COUNT(*)", "$2::decimal", "$3::decimal") + `, 2),
+			COUNT(*)
+		FROM ratings
+		WHERE ` + visibleRating("ratings") + `
+		GROUP BY movie_id
+		ON CONFLICT (movie_id, snapshot_date) DO UPDATE SET
+			average_score = EXCLUDED.average_score,
+			bayesian_average = EXCLUDED.bayesian_average,
+			total_ratings = EXCLUDED.total_ratings`
+
+	result, err := r.db.ExecContext(ctx, query, date.Format(rating.StatsHistoryDateLayout), globalAverage, confidenceK)
+	if err != nil {
+		return 0, fmt.Errorf("failed to snapshot movie stats: %w", err)
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return written, nil
+}
+
+func (r *statsHistoryRepository) GetStatsHistory(ctx context.Context, movieID movies.MovieID, from, to time.Time) ([]*rating.StatsSnapshot, error) {
+	query := `
+		SELECT movie_id, snapshot_date, average_score, bayesian_average, total_ratings
+		FROM movie_stats_history
+		WHERE movie_id = $1 AND snapshot_date BETWEEN $2::date AND $3::date
+		ORDER BY snapshot_date`
+
+	snapshots := []*rating.StatsSnapshot{}
+	err := r.db.SelectContext(ctx, &snapshots, query, movieID,
+		from.Format(rating.StatsHistoryDateLayout), to.Format(rating.StatsHistoryDateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats history: %w", err)
+	}
+	for _, s := range snapshots {
+		s.MovieID = movies.MovieID(strings.TrimSpace(string(s.MovieID)))
+	}
+	return snapshots, nil
+}
+
+func (r *statsHistoryRepository) DownsampleStatsHistory(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM movie_stats_history h
+		WHERE h.snapshot_date < $1::date AND EXISTS (
+			SELECT 1 FROM movie_stats_history later
+			WHERE later.movie_id = h.movie_id
+				AND later.snapshot_date > h.snapshot_date
+				AND later.snapshot_date < $1::date
+				AND date_trunc('week', later.snapshot_date) = date_trunc('week', h.snapshot_date)
+		)`
+
+	result, err := r.db.ExecContext(ctx, query, before.Format(rating.StatsHistoryDateLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to downsample stats history: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return removed, nil
+}
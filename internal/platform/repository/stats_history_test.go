@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsHistoryRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewStatsHistoryRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at) VALUES
+			('user-stats-history-1', 'statshistory1@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW()),
+			('user-stats-history-2', 'statshistory2@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-stats-history', 'Vertigo', '', 1958, 'Thriller', 'Alfred Hitchcock', 128, 'PG', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-stats-history-1', 'user-stats-history-1', 'test-id-stats-history', 5, NOW(), NOW()),
+			('rating-stats-history-2', 'user-stats-history-2', 'test-id-stats-history', 2, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	// Monday to Wednesday of one week, then the Monday after
+	for _, day := range []time.Time{date(2023, 1, 2), date(2023, 1, 3), date(2023, 1, 4), date(2023, 1, 9)} {
+		written, err := repo.SnapshotStats(ctx, day, 3, 2)
+		require.NoError(t, err)
+		assert.Positive(t, written)
+	}
+	// Taking the snapshot again replaces it
+	_, err = repo.SnapshotStats(ctx, date(2023, 1, 9), 3, 2)
+	require.NoError(t, err)
+
+	history, err := repo.GetStatsHistory(ctx, "test-id-stats-history", date(2023, 1, 1), date(2023, 12, 31))
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.True(t, date(2023, 1, 2).Equal(history[0].Date))
+	assert.Equal(t, 3.5, history[0].AverageScore)
+	assert.Equal(t, 3.25, history[0].BayesianAverage, "(2*3 + 7) / (2 + 2)")
+	assert.Equal(t, int64(2), history[0].TotalRatings)
+
+	_, err = repo.DownsampleStatsHistory(ctx, date(2023, 1, 10))
+	require.NoError(t, err)
+	history, err = repo.GetStatsHistory(ctx, "test-id-stats-history", date(2023, 1, 1), date(2023, 12, 31))
+	require.NoError(t, err)
+	require.Len(t, history, 2, "the last snapshot of each week is kept")
+	assert.True(t, date(2023, 1, 4).Equal(history[0].Date))
+	assert.True(t, date(2023, 1, 9).Equal(history[1].Date))
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/admin"
+	"thermondo/internal/domain/movies"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type summaryRepository struct {
+	db *sqlx.DB
+}
+
+func NewSummaryRepository(db *sqlx.DB) admin.SummaryRepository {
+	return &summaryRepository{db: db}
+}
+
+func (s *summaryRepository) Totals(ctx context.Context) (*admin.Totals, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE is_active),
+			(SELECT COUNT(*) FROM movies),
+			(SELECT COUNT(*) FROM ratings WHERE deleted_at IS NULL)`
+
+	totals := &admin.Totals{}
+	err := s.db.QueryRowContext(ctx, query).Scan(&totals.Users, &totals.ActiveUsers, &totals.Movies, &totals.Ratings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (s *summaryRepository) RatingsPerDay(ctx context.Context, since time.Time) ([]admin.DailyCount, error) {
+	return s.perDay(ctx, "ratings", liveRating("ratings"), since)
+}
+
+func (s *summaryRepository) SignupsPerDay(ctx context.Context, since time.Time) ([]admin.DailyCount, error) {
+	return s.perDay(ctx, "users", "TRUE", since)
+}
+
+// perDay counts the rows of table that meet condition by UTC creation day.
+// table and condition are never user input.
+func (s *summaryRepository) perDay(ctx context.Context, table, condition string, since time.Time) ([]admin.DailyCount, error) {
+	query := fmt.Sprintf(`
+		SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, COUNT(*)
+		FROM %s
+		WHERE created_at >= $1 AND %s
+		GROUP BY day
+		ORDER BY day`, table, condition)
+
+	rows, err := s.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count %s per day: %w", table, err)
+	}
+	defer rows.Close()
+
+	var counts []admin.DailyCount
+	for rows.Next() {
+		var count admin.DailyCount
+		if err := rows.Scan(&count.Date, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan daily count: %w", err)
+		}
+		count.Date = count.Date.UTC()
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (s *summaryRepository) TopMovies(ctx context.Context, since time.Time, limit int) ([]*admin.TopMovie, error) {
+	query := `
+		SELECT m.id, m.title, m.release_year, COUNT(*) AS ratings, AVG(r.score)::float8 AS average_score
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE r.created_at >= $1 AND ` + visibleRating("r") + `
+		GROUP BY m.id, m.title, m.release_year
+		ORDER BY ratings DESC, average_score DESC, m.title
+		LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top movies: %w", err)
+	}
+	defer rows.Close()
+
+	var top []*admin.TopMovie
+	for rows.Next() {
+		movie := &admin.TopMovie{}
+		var movieID string
+		if err := rows.Scan(&movieID, &movie.Title, &movie.ReleaseYear, &movie.Ratings, &movie.AverageScore); err != nil {
+			return nil, fmt.Errorf("failed to scan top movie: %w", err)
+		}
+		movie.MovieID = movies.MovieID(strings.TrimSpace(movieID))
+		top = append(top, movie)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top movies: %w", err)
+	}
+
+	return top, nil
+}
+
+func (s *summaryRepository) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewSummaryRepository(db)
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	lastMonth := today.AddDate(0, 0, -40)
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at) VALUES
+			('user-summary-1', 'summary1@example.com', 'password123', 'Test', 'User', 'user', true, $1, $1),
+			('user-summary-2', 'summary2@example.com', 'password123', 'Test', 'User', 'user', false, $2, $2)
+	`, today.Add(time.Hour), lastMonth)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at) VALUES
+			('test-id-summary-1', 'Alien', '', 1979, 'Horror', 'Ridley Scott', 117, 'R', 'English', 'USA', NOW(), NOW()),
+			('test-id-summary-2', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-summary-1', 'user-summary-1', 'test-id-summary-1', 5, $1, $1),
+			('rating-summary-2', 'user-summary-2', 'test-id-summary-1', 3, $1, $1),
+			('rating-summary-3', 'user-summary-1', 'test-id-summary-2', 4, $2, $2)
+	`, today.Add(2*time.Hour), lastMonth)
+	require.NoError(t, err)
+
+	totals, err := repo.Totals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), totals.Users)
+	assert.Equal(t, int64(1), totals.ActiveUsers)
+	assert.Equal(t, int64(2), totals.Movies)
+	assert.Equal(t, int64(3), totals.Ratings)
+
+	since := today.AddDate(0, 0, -29)
+	ratings, err := repo.RatingsPerDay(ctx, since)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	assert.True(t, today.Equal(ratings[0].Date))
+	assert.Equal(t, int64(2), ratings[0].Count)
+
+	signups, err := repo.SignupsPerDay(ctx, since)
+	require.NoError(t, err)
+	require.Len(t, signups, 1)
+	assert.Equal(t, int64(1), signups[0].Count)
+
+	top, err := repo.TopMovies(ctx, today.AddDate(0, 0, -6), 10)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, movies.MovieID("test-id-summary-1"), top[0].MovieID)
+	assert.Equal(t, int64(2), top[0].Ratings)
+	assert.InDelta(t, 4.0, top[0].AverageScore, 0.001)
+
+	require.NoError(t, repo.Ping(ctx))
+}
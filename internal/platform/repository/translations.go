@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/movies"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type translationRepository struct {
+	db *sqlx.DB
+}
+
+func NewTranslationRepository(db *sqlx.DB) movies.TranslationRepository {
+	return &translationRepository{db: db}
+}
+
+func (t *translationRepository) SaveTranslation(ctx context.Context, translation *movies.Translation) (*movies.Translation, error) {
+	query := `
+		INSERT INTO movie_translations (movie_id, locale, title, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (movie_id, locale) DO UPDATE SET
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at`
+
+	saved := *translation
+	err := t.db.QueryRowContext(
+		ctx, query,
+		translation.MovieID, translation.Locale, translation.Title,
+		translation.Description, translation.CreatedAt, translation.UpdatedAt,
+	).Scan(&saved.CreatedAt, &saved.UpdatedAt)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return nil, fmt.Errorf("movie with ID %s not found", translation.MovieID)
+		}
+		return nil, fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	return &saved, nil
+}
+
+func (t *translationRepository) GetTranslation(ctx context.Context, movieID movies.MovieID, locale string) (*movies.Translation, error) {
+	query := `
+		SELECT movie_id, locale, title, COALESCE(description, ''), created_at, updated_at
+		FROM movie_translations WHERE movie_id = $1 AND locale = $2`
+
+	translations, err := t.queryTranslations(ctx, query, movieID, locale)
+	if err != nil {
+		return nil, err
+	}
+	if len(translations) == 0 {
+		return nil, fmt.Errorf("translation %s for movie %s not found", locale, movieID)
+	}
+
+	return translations[0], nil
+}
+
+func (t *translationRepository) GetTranslations(ctx context.Context, movieID movies.MovieID) ([]*movies.Translation, error) {
+	query := `
+		SELECT movie_id, locale, title, COALESCE(description, ''), created_at, updated_at
+		FROM movie_translations WHERE movie_id = $1
+		ORDER BY locale`
+
+	return t.queryTranslations(ctx, query, movieID)
+}
+
+func (t *translationRepository) DeleteTranslation(ctx context.Context, movieID movies.MovieID, locale string) error {
+	query := `DELETE FROM movie_translations WHERE movie_id = $1 AND locale = $2`
+
+	result, err := t.db.ExecContext(ctx, query, movieID, locale)
+	if err != nil {
+		return fmt.Errorf("failed to delete translation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("translation %s for movie %s not found", locale, movieID)
+	}
+
+	return nil
+}
+
+func (t *translationRepository) FindTranslations(ctx context.Context, movieIDs []movies.MovieID, locales []string) ([]*movies.Translation, error) {
+	if len(movieIDs) == 0 || len(locales) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(movieIDs))
+	for i, id := range movieIDs {
+		ids[i] = string(id)
+	}
+
+	query := `
+		SELECT movie_id, locale, title, COALESCE(description, ''), created_at, updated_at
+		FROM movie_translations
+		WHERE movie_id = ANY($1) AND locale = ANY($2)`
+
+	return t.queryTranslations(ctx, query, pq.Array(ids), pq.Array(locales))
+}
+
+func (t *translationRepository) queryTranslations(ctx context.Context, query string, args ...interface{}) ([]*movies.Translation, error) {
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", err)
+	}
+	defer rows.Close()
+
+	var translations []*movies.Translation
+	for rows.Next() {
+		translation := &movies.Translation{}
+		var movieID string
+		if err := rows.Scan(
+			&movieID, &translation.Locale, &translation.Title,
+			&translation.Description, &translation.CreatedAt, &translation.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan translation: %w", err)
+		}
+		translation.MovieID = movies.MovieID(strings.TrimSpace(movieID))
+		translations = append(translations, translation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating translations: %w", err)
+	}
+
+	return translations, nil
+}
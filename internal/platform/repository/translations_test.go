@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslationRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewTranslationRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+		VALUES ('test-id-translation', 'The Godfather', '', 1972, 'Crime', 'Francis Ford Coppola', 175, 'R', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	saved, err := repo.SaveTranslation(ctx, &movies.Translation{
+		MovieID: "test-id-translation", Locale: "it", Title: "Il Padrino", CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Il Padrino", saved.Title)
+
+	// Saving the same locale again replaces the translation
+	_, err = repo.SaveTranslation(ctx, &movies.Translation{
+		MovieID: "test-id-translation", Locale: "it", Title: "Il padrino", Description: "Descrizione", CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	_, err = repo.SaveTranslation(ctx, &movies.Translation{
+		MovieID: "test-id-translation", Locale: "de", Title: "Der Pate", CreatedAt: now, UpdatedAt: now,
+	})
+	require.NoError(t, err)
+
+	translation, err := repo.GetTranslation(ctx, "test-id-translation", "it")
+	require.NoError(t, err)
+	assert.Equal(t, "Il padrino", translation.Title)
+	assert.Equal(t, "Descrizione", translation.Description)
+	assert.Equal(t, movies.MovieID("test-id-translation"), translation.MovieID)
+
+	all, err := repo.GetTranslations(ctx, "test-id-translation")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "de", all[0].Locale)
+
+	found, err := repo.FindTranslations(ctx, []movies.MovieID{"test-id-translation", "other"}, []string{"de", "fr"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "Der Pate", found[0].Title)
+
+	require.NoError(t, repo.DeleteTranslation(ctx, "test-id-translation", "de"))
+	err = repo.DeleteTranslation(ctx, "test-id-translation", "de")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.GetTranslation(ctx, "test-id-translation", "de")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = repo.SaveTranslation(ctx, &movies.Translation{
+		MovieID: "missing-movie", Locale: "de", Title: "Titel", CreatedAt: now, UpdatedAt: now,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
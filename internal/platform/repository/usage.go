@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/usage"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type usageRepository struct {
+	db *sqlx.DB
+}
+
+func NewUsageRepository(db *sqlx.DB) usage.Repository {
+	return &usageRepository{db: db}
+}
+
+func (u *usageRepository) Add(ctx context.Context, counters []*usage.Counter) error {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	kinds := make([]string, len(counters))
+	principalIDs := make([]string, len(counters))
+	days := make([]string, len(counters))
+	endpoints := make([]string, len(counters))
+	requests := make([]int64, len(counters))
+	bytes := make([]int64, len(counters))
+	for i, c := range counters {
+		kinds[i] = string(c.Kind)
+		principalIDs[i] = c.PrincipalID
+		days[i] = c.Day.UTC().Format(time.DateOnly)
+		endpoints[i] = c.Endpoint
+		requests[i] = c.Requests
+		bytes[i] = c.Bytes
+	}
+
+	query := `
+		INSERT INTO usage_counters (principal_kind, principal_id, day, endpoint, requests, bytes)
+		SELECT * FROM unnest($1::text[], $2::text[], $3::date[], $4::text[], $5::bigint[], $6::bigint[])
+		ON CONFLICT (principal_kind, principal_id, day, endpoint) DO UPDATE
+		SET requests = usage_counters.requests + EXCLUDED.requests,
+			bytes = usage_counters.bytes + EXCLUDED.bytes`
+
+	_, err := u.db.ExecContext(ctx, query,
+		pq.Array(kinds), pq.Array(principalIDs), pq.Array(days), pq.Array(endpoints), pq.Array(requests), pq.Array(bytes))
+	if err != nil {
+		return fmt.Errorf("failed to add usage counters: %w", err)
+	}
+	return nil
+}
+
+func (u *usageRepository) List(ctx context.Context, filter usage.Filter) ([]*usage.Counter, error) {
+	query := `
+		SELECT principal_kind, principal_id, day, endpoint, requests, bytes
+		FROM usage_counters
+		WHERE day BETWEEN $1::date AND $2::date
+			AND ($3 = '' OR principal_kind = $3)
+			AND ($4 = '' OR principal_id = $4)
+		ORDER BY day, principal_kind, principal_id, endpoint`
+
+	rows, err := u.db.QueryContext(ctx, query,
+		filter.From.UTC().Format(time.DateOnly), filter.To.UTC().Format(time.DateOnly), filter.Kind, filter.PrincipalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list usage counters: %w", err)
+	}
+	defer rows.Close()
+
+	counters := []*usage.Counter{}
+	for rows.Next() {
+		var c usage.Counter
+		if err := rows.Scan(&c.Kind, &c.PrincipalID, &c.Day, &c.Endpoint, &c.Requests, &c.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to scan usage counter: %w", err)
+		}
+		counters = append(counters, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage counters: %w", err)
+	}
+	return counters, nil
+}
+
+func (u *usageRepository) CountRequests(ctx context.Context, principal usage.Principal, from, to time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(requests), 0)
+		FROM usage_counters
+		WHERE principal_kind = $1 AND principal_id = $2 AND day >= $3::date AND day < $4::date`
+
+	var total int64
+	err := u.db.QueryRowContext(ctx, query, principal.Kind, principal.ID,
+		from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly)).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count requests: %w", err)
+	}
+	return total, nil
+}
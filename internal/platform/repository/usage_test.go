@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`TRUNCATE TABLE usage_counters`)
+	require.NoError(t, err)
+
+	repo := NewUsageRepository(db)
+	ctx := context.Background()
+	march := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	endpoint := "GET /api/v1/movies/{id}"
+
+	require.NoError(t, repo.Add(ctx, []*usage.Counter{
+		{Kind: usage.KindUser, PrincipalID: "user-usage", Day: march, Endpoint: endpoint, Requests: 3, Bytes: 300},
+		{Kind: usage.KindUser, PrincipalID: "user-usage", Day: april, Endpoint: endpoint, Requests: 1, Bytes: 100},
+		{Kind: usage.KindAPIKey, PrincipalID: "key-usage", Day: april, Endpoint: "GET /partner/v1/movies/{id}/score", Requests: 5, Bytes: 250},
+	}))
+	// A later flush adds to what is stored
+	require.NoError(t, repo.Add(ctx, []*usage.Counter{
+		{Kind: usage.KindUser, PrincipalID: "user-usage", Day: march, Endpoint: endpoint, Requests: 2, Bytes: 200},
+	}))
+	require.NoError(t, repo.Add(ctx, nil))
+
+	counters, err := repo.List(ctx, usage.Filter{From: march, To: april})
+	require.NoError(t, err)
+	require.Len(t, counters, 3)
+	assert.Equal(t, int64(5), counters[0].Requests)
+	assert.Equal(t, int64(500), counters[0].Bytes)
+	assert.True(t, march.Equal(counters[0].Day))
+
+	counters, err = repo.List(ctx, usage.Filter{From: march, To: april, Kind: usage.KindAPIKey})
+	require.NoError(t, err)
+	require.Len(t, counters, 1)
+	assert.Equal(t, "key-usage", counters[0].PrincipalID)
+
+	counters, err = repo.List(ctx, usage.Filter{From: april, To: april, PrincipalID: "user-usage"})
+	require.NoError(t, err)
+	require.Len(t, counters, 1)
+	assert.Equal(t, int64(1), counters[0].Requests)
+
+	principal := usage.Principal{Kind: usage.KindUser, ID: "user-usage"}
+	total, err := repo.CountRequests(ctx, principal, usage.MonthStart(march), usage.NextMonth(march))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	total, err = repo.CountRequests(ctx, usage.Principal{Kind: usage.KindUser, ID: "user-none"}, usage.MonthStart(april), usage.NextMonth(april))
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
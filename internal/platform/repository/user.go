@@ -5,8 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	domainUser "thermondo/internal/domain/users"
 	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/encryption"
+	"thermondo/internal/pkg/postgres"
+	"thermondo/internal/pkg/sorting"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -16,41 +22,75 @@ var (
 )
 
 type userRepository struct {
-	db    *sqlx.DB
-	cache cache.Cache
+	db      *sqlx.DB
+	dialect postgres.Dialect
+	cache   cache.Cache
+	logger  *slog.Logger
+	// keyring encrypts the email column when set; see user_encryption.go
+	keyring *encryption.Keyring
 }
 
-func NewUserRepository(db *sqlx.DB, cache cache.Cache) domainUser.UserRepository {
-	return &userRepository{
-		db:    db,
-		cache: cache,
+// UserRepositoryOption configures the user repository
+type UserRepositoryOption func(*userRepository)
+
+func NewUserRepository(db *sqlx.DB, cache cache.Cache, logger *slog.Logger, opts ...UserRepositoryOption) domainUser.UserRepository {
+	r := &userRepository{
+		db:      db,
+		dialect: postgres.DialectOf(db),
+		cache:   cache,
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *userRepository) FindByID(ctx context.Context, id domainUser.UserID) (*domainUser.User, error) {
-	query := `SELECT id, first_name, last_name, email, role, is_active, created_at FROM users WHERE id = $1`
+	query := `SELECT id, first_name, last_name, email, role, is_active, avatar_key, shadow_banned, is_critic, content_mode, created_at FROM users WHERE id = $1`
 	user := &domainUser.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &user.IsActive, &user.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &user.IsActive, &user.AvatarKey, &user.ShadowBanned, &user.IsCritic, &user.ContentMode, &user.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, err
 	}
-	return user, err
+	if err != nil {
+		return nil, err
+	}
+	if user.Email, err = r.openEmail(user.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (r *userRepository) FindByEmail(ctx context.Context, email string) (*domainUser.User, error) {
-	query := `SELECT id, first_name, last_name, email, role, is_active, created_at FROM users WHERE email = $1`
+	where, args, err := r.emailLookup(email)
+	if err != nil {
+		return nil, err
+	}
+	// The password hash is needed to log in
+	query := `SELECT id, first_name, last_name, email, password, role, is_active, avatar_key, shadow_banned, is_critic, content_mode, created_at FROM users WHERE ` + where
 	user := &domainUser.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &user.IsActive, &user.CreatedAt)
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Password, &user.Role, &user.IsActive, &user.AvatarKey, &user.ShadowBanned, &user.IsCritic, &user.ContentMode, &user.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return user, err
+	if err != nil {
+		return nil, err
+	}
+	if user.Email, err = r.openEmail(user.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func (r *userRepository) Create(ctx context.Context, user *domainUser.User) (*domainUser.User, error) {
-	query := `INSERT INTO users (id, first_name, last_name, email, password, role, is_active, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	email, emailHash, err := r.sealEmail(user.Email)
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO users (id, first_name, last_name, email, email_hash, password, role, is_active, content_mode, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
-	result, err := r.db.ExecContext(ctx, query, user.ID, user.FirstName, user.LastName, user.Email, user.Password, user.Role, user.IsActive, user.CreatedAt, user.UpdatedAt)
+	result, err := r.db.ExecContext(ctx, query, user.ID, user.FirstName, user.LastName, email, emailHash, user.Password, user.Role, user.IsActive, contentModeOrDefault(user.ContentMode), user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -65,57 +105,240 @@ func (r *userRepository) Create(ctx context.Context, user *domainUser.User) (*do
 	}
 
 	// Invalidate user cache
-	if err := r.invalidateUserCache(ctx, user.ID); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Failed to invalidate user cache: %v\n", err)
-	}
+	r.invalidateUserCache(ctx, user.ID)
 
 	return user, err
 }
 
-func (r *userRepository) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM users`
+func (r *userRepository) Update(ctx context.Context, user *domainUser.User) (*domainUser.User, error) {
+	email, emailHash, err := r.sealEmail(user.Email)
+	if err != nil {
+		return nil, err
+	}
+	query := `UPDATE users SET first_name = $2, last_name = $3, email = $4, email_hash = $5, is_active = $6, content_mode = $7, updated_at = $8 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, user.ID, user.FirstName, user.LastName, email, emailHash, user.IsActive, contentModeOrDefault(user.ContentMode), user.UpdatedAt)
+	if err != nil {
+		if r.dialect.IsUniqueViolation(err) {
+			return nil, domainUser.ErrUserAlreadyExists
+		}
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected == 0 {
+		return nil, domainUser.ErrUserNotFound
+	}
+
+	r.invalidateUserCache(ctx, user.ID)
+
+	return user, nil
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, id domainUser.UserID, avatarKey *string, updatedAt time.Time) (*string, error) {
+	// The subquery reads the old key under a row lock, so concurrent uploads
+	// each get back the key they actually replaced
+	query := `
+		UPDATE users u SET avatar_key = $2, updated_at = $3
+		FROM (SELECT id, avatar_key FROM users WHERE id = $1 FOR UPDATE) old
+		WHERE u.id = old.id
+		RETURNING old.avatar_key`
+
+	var previous *string
+	var err error
+	if r.dialect == postgres.DialectSQLite {
+		previous, err = r.updateAvatarSQLite(ctx, id, avatarKey, updatedAt)
+	} else {
+		err = r.db.QueryRowContext(ctx, query, id, avatarKey, updatedAt).Scan(&previous)
+	}
+	if err == sql.ErrNoRows {
+		return nil, domainUser.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.invalidateUserCache(ctx, id)
+
+	return previous, nil
+}
+
+// updateAvatarSQLite reads the old key and writes the new one in a
+// transaction, which holds SQLite's write lock throughout
+func (r *userRepository) updateAvatarSQLite(ctx context.Context, id domainUser.UserID, avatarKey *string, updatedAt time.Time) (*string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var previous *string
+	if err := tx.QueryRowContext(ctx, `SELECT avatar_key FROM users WHERE id = $1`, id).Scan(&previous); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET avatar_key = $2, updated_at = $3 WHERE id = $1`, id, avatarKey, updatedAt); err != nil {
+		return nil, err
+	}
+	return previous, tx.Commit()
+}
+
+func (r *userRepository) SetShadowBanned(ctx context.Context, id domainUser.UserID, banned bool, updatedAt time.Time) error {
+	return r.setFlag(ctx, id, "shadow_banned", banned, updatedAt)
+}
+
+func (r *userRepository) SetCritic(ctx context.Context, id domainUser.UserID, critic bool, updatedAt time.Time) error {
+	return r.setFlag(ctx, id, "is_critic", critic, updatedAt)
+}
+
+// setFlag updates one boolean column of a user. column is never user input.
+func (r *userRepository) setFlag(ctx context.Context, id domainUser.UserID, column string, value bool, updatedAt time.Time) error {
+	query := fmt.Sprintf(`UPDATE users SET %s = $2, updated_at = $3 WHERE id = $1`, column)
+
+	result, err := r.db.ExecContext(ctx, query, id, value, updatedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domainUser.ErrUserNotFound
+	}
+
+	r.invalidateUserCache(ctx, id)
+
+	return nil
+}
+
+// Count counts all users matching filter, ignoring pagination
+func (r *userRepository) Count(ctx context.Context, filter domainUser.ListFilter) (int, error) {
+	if err := r.checkEmailQuery(filter); err != nil {
+		return 0, err
+	}
+	where, args := userListWhere(r.dialect, filter)
+	query := `SELECT COUNT(*) FROM users ` + where
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
 	return count, err
 }
 
-func (r *userRepository) List(ctx context.Context, page, limit int) ([]*domainUser.User, error) {
+// List returns a page of users matching filter together with a COUNT(*)
+// OVER() total, so the page and the total come from the same snapshot
+func (r *userRepository) List(ctx context.Context, filter domainUser.ListFilter, page, limit int) ([]*domainUser.User, int, error) {
+	if err := r.checkEmailQuery(filter); err != nil {
+		return nil, 0, err
+	}
+
 	offset := 0
 	if page > 0 {
 		offset = (page - 1) * limit
 	}
-	query := `SELECT id, first_name, last_name, email, role, is_active, created_at FROM users ORDER BY id LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	where, args := userListWhere(r.dialect, filter)
+	query := fmt.Sprintf(`
+		SELECT id, first_name, last_name, email, role, is_active, avatar_key, shadow_banned, is_critic, content_mode, created_at,
+			   COUNT(*) OVER() AS total_count
+		FROM users
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`,
+		where, userListOrder(filter), len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []*domainUser.User
+	var (
+		users []*domainUser.User
+		total int
+	)
 	for rows.Next() {
 		user := &domainUser.User{}
-		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &user.IsActive, &user.CreatedAt); err != nil {
-			return nil, err
+		if err := rows.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.Role, &user.IsActive, &user.AvatarKey, &user.ShadowBanned, &user.IsCritic, &user.ContentMode, &user.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		if user.Email, err = r.openEmail(user.Email); err != nil {
+			return nil, 0, err
 		}
 		users = append(users, user)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	return users, nil
+	return users, total, nil
 }
 
-// invalidateUserCache deletes all cached data for a user
-func (r *userRepository) invalidateUserCache(ctx context.Context, userID domainUser.UserID) error {
-	profilePattern := fmt.Sprintf("user:%s:*", userID)
-	if err := r.cache.DeletePattern(ctx, profilePattern); err != nil {
-		return fmt.Errorf("failed to delete profile cache: %w", err)
+// userListWhere renders filter as a parameterised WHERE clause shared by
+// List and Count, or an empty string when nothing is filtered
+func userListWhere(dialect postgres.Dialect, filter domainUser.ListFilter) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if filter.EmailPrefix != "" {
+		args = append(args, strings.ToLower(escapeLike(filter.EmailPrefix))+"%")
+		conditions = append(conditions, fmt.Sprintf("LOWER(email) LIKE $%d%s", len(args), dialect.LikeEscape()))
 	}
 
-	statsKey := fmt.Sprintf("user:stats:%s", userID)
-	if err := r.cache.Delete(ctx, statsKey); err != nil {
-		return fmt.Errorf("failed to delete stats cache: %w", err)
+	if len(conditions) == 0 {
+		return "", nil
 	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
 
-	return nil
+// userSortColumns maps the fields users can be sorted by onto their columns
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"email":      "email",
+	"id":         "id",
+	"last_name":  "last_name, first_name",
+}
+
+// userListOrder maps the filter's sort options onto a whitelisted ORDER BY,
+// breaking ties on id so pages are stable
+func userListOrder(filter domainUser.ListFilter) string {
+	keys := sorting.Resolve(filter.SortBy, filter.Order, userSortColumns, "id")
+	order := sorting.OrderBy(keys, userSortColumns)
+	if keys[len(keys)-1].Field != "id" {
+		order += ", id"
+	}
+	return order
+}
+
+// invalidateUserCache deletes all cached data for a user
+func (r *userRepository) invalidateUserCache(ctx context.Context, userID domainUser.UserID) {
+	if err := r.cache.InvalidateTags(ctx, cache.UserTag(string(userID))); err != nil {
+		// The write stands; the entries expire with their TTL
+		r.logger.Warn("Failed to invalidate user cache", slog.String("error", err.Error()), slog.String("user_id", string(userID)))
+	}
+}
+
+// contentModeOrDefault stores users built without a content mode as standard
+func contentModeOrDefault(mode domainUser.ContentMode) domainUser.ContentMode {
+	if mode == "" {
+		return domainUser.ContentModeStandard
+	}
+	return mode
 }
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	domainRating "thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type userActivityRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserActivityRepository(db *sqlx.DB) domainRating.UserActivityRepository {
+	return &userActivityRepository{db: db}
+}
+
+// GetUserActivity runs one aggregate query per statistic. Days and months
+// are counted in UTC, so a streak doesn't depend on the server's time zone.
+func (r *userActivityRepository) GetUserActivity(ctx context.Context, userID users.UserID, from, to time.Time) (*domainRating.UserActivity, error) {
+	conditions := []string{"r.user_id = $1", liveRating("r")}
+	args := []interface{}{userID}
+	if !from.IsZero() {
+		args = append(args, from)
+		conditions = append(conditions, fmt.Sprintf("r.created_at >= $%d", len(args)))
+	}
+	if !to.IsZero() {
+		args = append(args, to)
+		conditions = append(conditions, fmt.Sprintf("r.created_at < $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	activity := &domainRating.UserActivity{
+		PerMonth: []domainRating.MonthlyCount{},
+		Decades:  []domainRating.DecadeCount{},
+	}
+
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*), COALESCE(ROUND(AVG(r.score::decimal), 2), 0)
+		FROM ratings r
+		WHERE %s`, where), args...).Scan(&activity.TotalRatings, &activity.AverageScore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count user ratings: %w", err)
+	}
+	if activity.TotalRatings == 0 {
+		return activity, nil
+	}
+
+	if err := r.db.SelectContext(ctx, &activity.PerMonth, fmt.Sprintf(`
+		SELECT to_char(date_trunc('month', r.created_at AT TIME ZONE 'UTC'), 'YYYY-MM') AS month, COUNT(*) AS count
+		FROM ratings r
+		WHERE %s
+		GROUP BY 1
+		ORDER BY 1`, where), args...); err != nil {
+		return nil, fmt.Errorf("failed to count user ratings per month: %w", err)
+	}
+
+	// Consecutive days minus their row number are constant within a run, so
+	// grouping by that difference yields one row per streak
+	streak := &domainRating.Streak{}
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(`
+		WITH days AS (
+			SELECT DISTINCT (r.created_at AT TIME ZONE 'UTC')::date AS day
+			FROM ratings r
+			WHERE %s
+		), runs AS (
+			SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day))::int AS run
+			FROM days
+		)
+		SELECT COUNT(*), MIN(day), MAX(day)
+		FROM runs
+		GROUP BY run
+		ORDER BY COUNT(*) DESC, MAX(day) DESC
+		LIMIT 1`, where), args...).Scan(&streak.Days, &streak.Start, &streak.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find longest rating streak: %w", err)
+	}
+	activity.LongestStreak = streak
+
+	if err := r.db.SelectContext(ctx, &activity.Decades, fmt.Sprintf(`
+		SELECT (m.release_year / 10) * 10 AS decade, COUNT(*) AS count
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE %s
+		GROUP BY 1
+		ORDER BY 2 DESC, 1 DESC`, where), args...); err != nil {
+		return nil, fmt.Errorf("failed to count user ratings per decade: %w", err)
+	}
+
+	if activity.TopGenre, err = r.mostRated(ctx, "m.genre", where, args); err != nil {
+		return nil, fmt.Errorf("failed to find top genre: %w", err)
+	}
+	if activity.TopDirector, err = r.mostRated(ctx, "m.director", where, args); err != nil {
+		return nil, fmt.Errorf("failed to find top director: %w", err)
+	}
+
+	return activity, nil
+}
+
+// mostRated returns the value of the movie column the user rated most
+// often, the alphabetically first among ties
+func (r *userActivityRepository) mostRated(ctx context.Context, column, where string, args []interface{}) (string, error) {
+	var value string
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %[1]s
+		FROM ratings r
+		JOIN movies m ON m.id = r.movie_id
+		WHERE %[2]s
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC, %[1]s
+		LIMIT 1`, column, where), args...).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainRating "thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserActivityRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	repo := NewUserActivityRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-activity-1', 'activity1@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at) VALUES
+			('test-id-activity-1', 'Vertigo', '', 1958, 'Thriller', 'Alfred Hitchcock', 128, 'PG', 'English', 'USA', NOW(), NOW()),
+			('test-id-activity-2', 'Psycho', '', 1960, 'Horror', 'Alfred Hitchcock', 109, 'PG', 'English', 'USA', NOW(), NOW()),
+			('test-id-activity-3', 'Rear Window', '', 1954, 'Thriller', 'Alfred Hitchcock', 112, 'PG', 'English', 'USA', NOW(), NOW()),
+			('test-id-activity-4', 'Heat', '', 1995, 'Crime', 'Michael Mann', 170, 'PG', 'English', 'USA', NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	// A two day streak in January, then two days apart from each other
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-activity-1', 'user-activity-1', 'test-id-activity-1', 5, '2023-01-10 09:00:00+00', NOW()),
+			('rating-activity-2', 'user-activity-1', 'test-id-activity-2', 4, '2023-01-11 23:30:00+00', NOW()),
+			('rating-activity-3', 'user-activity-1', 'test-id-activity-3', 3, '2023-01-31 12:00:00+00', NOW()),
+			('rating-activity-4', 'user-activity-1', 'test-id-activity-4', 4, '2023-02-02 08:00:00+00', NOW())
+	`)
+	require.NoError(t, err)
+
+	year := func(y int) time.Time { return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("aggregates the ratings of the period", func(t *testing.T) {
+		activity, err := repo.GetUserActivity(ctx, "user-activity-1", year(2023), year(2024))
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(4), activity.TotalRatings)
+		assert.Equal(t, 4.0, activity.AverageScore)
+		assert.Equal(t, []domainRating.MonthlyCount{{Month: "2023-01", Count: 3}, {Month: "2023-02", Count: 1}}, activity.PerMonth)
+		require.NotNil(t, activity.LongestStreak)
+		assert.Equal(t, 2, activity.LongestStreak.Days)
+		assert.True(t, time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC).Equal(activity.LongestStreak.Start))
+		assert.Equal(t, []domainRating.DecadeCount{{Decade: 1950, Count: 2}, {Decade: 1990, Count: 1}, {Decade: 1960, Count: 1}}, activity.Decades)
+		assert.Equal(t, "Thriller", activity.TopGenre)
+		assert.Equal(t, "Alfred Hitchcock", activity.TopDirector)
+	})
+
+	t.Run("is empty for a period without ratings", func(t *testing.T) {
+		activity, err := repo.GetUserActivity(ctx, "user-activity-1", year(2024), year(2025))
+		require.NoError(t, err)
+
+		assert.Zero(t, activity.TotalRatings)
+		assert.Nil(t, activity.LongestStreak)
+		assert.Empty(t, activity.PerMonth)
+		assert.Empty(t, activity.TopGenre)
+	})
+
+	t.Run("leaves zero bounds open", func(t *testing.T) {
+		activity, err := repo.GetUserActivity(ctx, "user-activity-1", time.Time{}, time.Time{})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(4), activity.TotalRatings)
+	})
+}
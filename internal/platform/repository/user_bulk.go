@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/postgres"
+)
+
+func (r *userRepository) BulkUpdate(ctx context.Context, update domainUser.BulkUpdate) ([]domainUser.BulkResult, error) {
+	ids := make([]string, len(update.UserIDs))
+	for i, id := range update.UserIDs {
+		ids[i] = string(id)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin bulk update transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the rows so the reported outcome is the one that gets committed.
+	// A SQLite transaction holds the database's write lock from the start.
+	inIDs, args := r.dialect.InList("id", ids, nil)
+	lock := " FOR UPDATE"
+	if r.dialect == postgres.DialectSQLite {
+		lock = ""
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, is_active, role FROM users WHERE `+inIDs+` ORDER BY id`+lock,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock users: %w", err)
+	}
+	type current struct {
+		active bool
+		role   domainUser.Role
+	}
+	existing := make(map[domainUser.UserID]current, len(ids))
+	for rows.Next() {
+		var (
+			id  domainUser.UserID
+			cur current
+		)
+		if err := rows.Scan(&id, &cur.active, &cur.role); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		existing[id] = cur
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	results := make([]domainUser.BulkResult, len(update.UserIDs))
+	var changed []string
+	for i, id := range update.UserIDs {
+		results[i].UserID = id
+		cur, ok := existing[id]
+		if !ok {
+			results[i].Status = domainUser.BulkNotFound
+			continue
+		}
+
+		var needsChange bool
+		switch update.Action {
+		case domainUser.BulkDeactivate:
+			needsChange = cur.active
+		case domainUser.BulkActivate:
+			needsChange = !cur.active
+		case domainUser.BulkChangeRole:
+			needsChange = cur.role != update.Role
+		default:
+			return nil, fmt.Errorf("unknown bulk action %q", update.Action)
+		}
+
+		if needsChange {
+			results[i].Status = domainUser.BulkUpdated
+			changed = append(changed, string(id))
+		} else {
+			results[i].Status = domainUser.BulkUnchanged
+		}
+	}
+
+	if len(changed) > 0 {
+		var (
+			column string
+			value  interface{}
+		)
+		switch update.Action {
+		case domainUser.BulkDeactivate, domainUser.BulkActivate:
+			column, value = "is_active", update.Action == domainUser.BulkActivate
+		case domainUser.BulkChangeRole:
+			column, value = "role", update.Role
+		}
+
+		inChanged, args := r.dialect.InList("id", changed, []interface{}{value, update.UpdatedAt})
+		query := fmt.Sprintf(`UPDATE users SET %s = $1, updated_at = $2 WHERE %s`, column, inChanged)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return nil, fmt.Errorf("failed to update users: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk update: %w", err)
+	}
+
+	for _, id := range changed {
+		r.invalidateUserCache(ctx, domainUser.UserID(id))
+	}
+
+	return results, nil
+}
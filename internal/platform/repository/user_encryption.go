@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	domainUser "thermondo/internal/domain/users"
+	"thermondo/internal/pkg/encryption"
+	"thermondo/internal/pkg/postgres"
+	"thermondo/internal/pkg/sorting"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errEmailKeyringMissing = errors.New("user email is encrypted but no keyring is configured")
+
+// WithEmailEncryption stores user emails encrypted with keyring, next to a
+// blind index for lookups by email. The keyring needs an index key.
+//
+// Rows written before encryption was enabled are still read and found by
+// email; EncryptUserEmails moves them over.
+func WithEmailEncryption(keyring *encryption.Keyring) UserRepositoryOption {
+	return func(r *userRepository) {
+		r.keyring = keyring
+	}
+}
+
+// sealEmail returns the values stored in the email and email_hash columns
+func (r *userRepository) sealEmail(email string) (string, *string, error) {
+	if r.keyring == nil {
+		return email, nil, nil
+	}
+
+	hash, err := r.keyring.BlindIndex(normalizeEmail(email))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to index email: %w", err)
+	}
+	sealed, err := r.keyring.EncryptString(email)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt email: %w", err)
+	}
+	return sealed, &hash, nil
+}
+
+// openEmail returns the plaintext of a stored email, which may predate
+// encryption
+func (r *userRepository) openEmail(stored string) (string, error) {
+	if !encryption.IsEncrypted(stored) {
+		return stored, nil
+	}
+	if r.keyring == nil {
+		return "", errEmailKeyringMissing
+	}
+
+	email, err := r.keyring.DecryptString(stored)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt email: %w", err)
+	}
+	return email, nil
+}
+
+// emailLookup returns the WHERE condition matching a user by email. With
+// encryption on it matches the blind index, and the plaintext column for
+// rows that have not been encrypted yet.
+func (r *userRepository) emailLookup(email string) (string, []interface{}, error) {
+	if r.keyring == nil {
+		return "email = $1", []interface{}{email}, nil
+	}
+
+	hash, err := r.keyring.BlindIndex(normalizeEmail(email))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to index email: %w", err)
+	}
+	return "(email_hash = $1 OR email = $2)", []interface{}{hash, email}, nil
+}
+
+// checkEmailQuery rejects list options that need the plaintext email column
+func (r *userRepository) checkEmailQuery(filter domainUser.ListFilter) error {
+	sortsByEmail := slices.ContainsFunc(sorting.Resolve(filter.SortBy, filter.Order, userSortColumns, "id"), func(key sorting.Key) bool {
+		return key.Field == "email"
+	})
+	if r.keyring != nil && (filter.EmailPrefix != "" || sortsByEmail) {
+		return domainUser.ErrEmailNotSearchable
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// EncryptUserEmails encrypts plaintext emails and rewraps emails sealed by
+// a retired key onto the keyring's active key, batchSize rows per
+// transaction. It returns the number of rows changed and is safe to run
+// while the service is serving traffic, or from several instances at once.
+func EncryptUserEmails(ctx context.Context, db *sqlx.DB, keyring *encryption.Keyring, batchSize int) (int, error) {
+	if batchSize < 1 {
+		batchSize = 500
+	}
+	current := escapeLike(encryption.KeyPrefix(keyring.ActiveKeyID())) + "%"
+
+	total := 0
+	for {
+		n, err := encryptUserEmailBatch(ctx, db, keyring, current, batchSize)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+func encryptUserEmailBatch(ctx context.Context, db *sqlx.DB, keyring *encryption.Keyring, current string, batchSize int) (int, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin email encryption transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED lets concurrent runs split the work instead of queueing.
+	// SQLite runs one write transaction at a time and has no row locks.
+	lock := " FOR UPDATE SKIP LOCKED"
+	if postgres.DialectOf(db) == postgres.DialectSQLite {
+		lock = ""
+	}
+	var rows []struct {
+		ID    string `db:"id"`
+		Email string `db:"email"`
+	}
+	err = tx.SelectContext(ctx, &rows,
+		`SELECT id, email FROM users WHERE email NOT LIKE $1 ORDER BY id LIMIT $2`+lock,
+		current, batchSize,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select users to encrypt: %w", err)
+	}
+
+	for _, row := range rows {
+		var (
+			sealed    string
+			plaintext = row.Email
+		)
+		if encryption.IsEncrypted(row.Email) {
+			if plaintext, err = keyring.DecryptString(row.Email); err != nil {
+				return 0, fmt.Errorf("failed to decrypt email of user %s: %w", row.ID, err)
+			}
+			sealed, err = keyring.Rewrap(row.Email)
+		} else {
+			sealed, err = keyring.EncryptString(row.Email)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt email of user %s: %w", row.ID, err)
+		}
+
+		hash, err := keyring.BlindIndex(normalizeEmail(plaintext))
+		if err != nil {
+			return 0, fmt.Errorf("failed to index email: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE users SET email = $2, email_hash = $3 WHERE id = $1`, row.ID, sealed, hash); err != nil {
+			return 0, fmt.Errorf("failed to store encrypted email of user %s: %w", row.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit email encryption: %w", err)
+	}
+	return len(rows), nil
+}
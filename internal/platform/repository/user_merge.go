@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type userMergeRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserMergeRepository(db *sqlx.DB) users.MergeRepository {
+	return &userMergeRepository{db: db}
+}
+
+func (m *userMergeRepository) Merge(ctx context.Context, sourceID, targetID users.UserID, mergedAt time.Time) (*users.MergeRecord, error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin merge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both users so concurrent merges of the same pair serialize
+	var locked int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM (SELECT id FROM users WHERE id IN ($1, $2) ORDER BY id FOR UPDATE) l`,
+		sourceID, targetID,
+	).Scan(&locked)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock users: %w", err)
+	}
+	if locked != 2 {
+		return nil, users.ErrUserNotFound
+	}
+
+	record := &users.MergeRecord{SourceID: sourceID, TargetID: targetID, MergedAt: mergedAt}
+
+	if record.RatingsDropped, err = resolveRatingConflicts(ctx, tx, "user_id", string(sourceID), string(targetID)); err != nil {
+		return nil, err
+	}
+	if record.RatingsMoved, err = moveRatings(ctx, tx, "user_id", string(sourceID), string(targetID)); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE user_identities SET user_id = $2 WHERE user_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move identities: %w", err)
+	}
+	if record.IdentitiesMoved, err = result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	// The target keeps its own profile; only what it is missing comes from
+	// the source
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users t SET avatar_key = s.avatar_key
+		FROM users s
+		WHERE t.id = $2 AND s.id = $1 AND t.avatar_key IS NULL`,
+		sourceID, targetID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge profile: %w", err)
+	}
+
+	moves := []struct{ name, query string }{
+		{"lists", `UPDATE lists SET user_id = $2 WHERE user_id = $1`},
+		{"preferences", `
+			INSERT INTO user_preferences (user_id, genres, decades, updated_at)
+			SELECT $2, genres, decades, updated_at
+			FROM user_preferences WHERE user_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"settings", `
+			INSERT INTO user_settings (user_id, settings, updated_at)
+			SELECT $2, settings, updated_at
+			FROM user_settings WHERE user_id = $1
+			ON CONFLICT DO NOTHING`},
+		{"anonymous claims", `UPDATE anonymous_principals SET claimed_by = $2 WHERE claimed_by = $1`},
+	}
+	for _, move := range moves {
+		if _, err := tx.ExecContext(ctx, move.query, sourceID, targetID); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", move.name, err)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_merges (source_user_id, target_user_id, ratings_moved, ratings_dropped, identities_moved, merged_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		sourceID, targetID, record.RatingsMoved, record.RatingsDropped, record.IdentitiesMoved, mergedAt,
+	).Scan(&record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record merge: %w", err)
+	}
+
+	// Sessions and the source's remaining preferences go with it
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to delete merged user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserMergeRepository(t *testing.T) {
+	db := setupTestDB(t, "ratings", "movies", "users", "user_merges")
+	defer db.Close()
+
+	repo := NewUserMergeRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, avatar_key, created_at, updated_at)
+		VALUES ('user-id-merge-source', 'source@example.com', 'password123', 'Ada', 'Lovelace', 'user', true, 'avatars/source', NOW(), NOW()),
+			   ('user-id-merge-target', 'target@example.com', 'password123', 'Ada', 'King', 'user', true, NULL, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+	for _, m := range []string{"test-id-umerge-1", "test-id-umerge-2", "test-id-umerge-3"} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, $1, '', 1995, 'Thriller', 'David Fincher', 127, 'R', 'English', 'USA', NOW(), NOW())
+		`, m)
+		require.NoError(t, err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	recent := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO ratings (id, user_id, movie_id, score, created_at, updated_at) VALUES
+			('rating-umerge-1', 'user-id-merge-source', 'test-id-umerge-1', 5, $1, $2),
+			('rating-umerge-2', 'user-id-merge-target', 'test-id-umerge-1', 2, $1, $1),
+			('rating-umerge-3', 'user-id-merge-source', 'test-id-umerge-2', 3, $1, $1),
+			('rating-umerge-4', 'user-id-merge-target', 'test-id-umerge-2', 4, $1, $2),
+			('rating-umerge-5', 'user-id-merge-source', 'test-id-umerge-3', 1, $1, $1)
+	`, old, recent)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		INSERT INTO user_identities (provider, subject, user_id, created_at) VALUES ('apple', '001234.abcd', 'user-id-merge-source', NOW());
+		INSERT INTO user_preferences (user_id, genres) VALUES ('user-id-merge-source', '{Thriller}');
+		INSERT INTO user_settings (user_id, settings, updated_at) VALUES ('user-id-merge-source', '{"locale":"de"}', NOW());
+		INSERT INTO lists (id, user_id, name, share_slug) VALUES ('list-id-umerge', 'user-id-merge-source', 'Watch later', 'umerge');
+	`)
+	require.NoError(t, err)
+
+	record, err := repo.Merge(ctx, "user-id-merge-source", "user-id-merge-target", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), record.RatingsMoved)
+	assert.Equal(t, int64(2), record.RatingsDropped)
+	assert.Equal(t, int64(1), record.IdentitiesMoved)
+	assert.NotZero(t, record.ID)
+
+	// The newer rating of each movie wins and moved ratings keep their timestamps
+	var scores []int
+	require.NoError(t, db.Select(&scores, `SELECT score FROM ratings WHERE user_id = 'user-id-merge-target' ORDER BY movie_id`))
+	assert.Equal(t, []int{5, 4, 1}, scores)
+	var updatedAt time.Time
+	require.NoError(t, db.Get(&updatedAt, `SELECT updated_at FROM ratings WHERE id = 'rating-umerge-5'`))
+	assert.WithinDuration(t, old, updatedAt, time.Second)
+
+	var owner string
+	require.NoError(t, db.Get(&owner, `SELECT user_id FROM user_identities WHERE provider = 'apple' AND subject = '001234.abcd'`))
+	assert.Equal(t, "user-id-merge-target", owner)
+	require.NoError(t, db.Get(&owner, `SELECT user_id FROM lists WHERE id = 'list-id-umerge'`))
+	assert.Equal(t, "user-id-merge-target", owner)
+	var genres int
+	require.NoError(t, db.Get(&genres, `SELECT cardinality(genres) FROM user_preferences WHERE user_id = 'user-id-merge-target'`))
+	assert.Equal(t, 1, genres)
+	var locale string
+	require.NoError(t, db.Get(&locale, `SELECT settings->>'locale' FROM user_settings WHERE user_id = 'user-id-merge-target'`))
+	assert.Equal(t, "de", locale)
+
+	var target struct {
+		LastName  string  `db:"last_name"`
+		AvatarKey *string `db:"avatar_key"`
+	}
+	require.NoError(t, db.Get(&target, `SELECT last_name, avatar_key FROM users WHERE id = 'user-id-merge-target'`))
+	assert.Equal(t, "King", target.LastName, "the target keeps its profile")
+	require.NotNil(t, target.AvatarKey)
+	assert.Equal(t, "avatars/source", *target.AvatarKey, "and gets what it was missing")
+
+	var sources int
+	require.NoError(t, db.Get(&sources, `SELECT COUNT(*) FROM users WHERE id = 'user-id-merge-source'`))
+	assert.Zero(t, sources)
+
+	_, err = repo.Merge(ctx, "user-id-merge-source", "user-id-merge-target", time.Now())
+	assert.ErrorIs(t, err, users.ErrUserNotFound)
+}
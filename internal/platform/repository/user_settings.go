@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type userSettingsRepository struct {
+	db *sqlx.DB
+}
+
+func NewUserSettingsRepository(db *sqlx.DB) users.SettingsRepository {
+	return &userSettingsRepository{db: db}
+}
+
+// settingsDocument is what is stored of the settings in the JSON document
+type settingsDocument struct {
+	Locale        string                     `json:"locale"`
+	Notifications users.NotificationSettings `json:"notifications"`
+	SortOrders    users.SortOrders           `json:"sort_orders"`
+}
+
+func (u *userSettingsRepository) Get(ctx context.Context, userID users.UserID) (*users.Settings, error) {
+	var document []byte
+	var updatedAt time.Time
+	err := u.db.QueryRowContext(ctx, `SELECT settings, updated_at FROM user_settings WHERE user_id = $1`, userID).Scan(&document, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+
+	// Settings missing from the document keep their defaults
+	settings := users.DefaultSettings(userID)
+	stored := settingsDocument{Locale: settings.Locale, Notifications: settings.Notifications, SortOrders: settings.SortOrders}
+	if err := json.Unmarshal(document, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	settings.Locale = stored.Locale
+	settings.Notifications = stored.Notifications
+	settings.SortOrders = stored.SortOrders
+	settings.UpdatedAt = updatedAt
+	return settings, nil
+}
+
+func (u *userSettingsRepository) Save(ctx context.Context, settings *users.Settings) error {
+	document, err := json.Marshal(settingsDocument{
+		Locale:        settings.Locale,
+		Notifications: settings.Notifications,
+		SortOrders:    settings.SortOrders,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	query := `
+		INSERT INTO user_settings (user_id, settings, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET settings = EXCLUDED.settings, updated_at = EXCLUDED.updated_at`
+
+	if _, err := u.db.ExecContext(ctx, query, settings.UserID, document, settings.UpdatedAt); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+			return users.ErrUserNotFound
+		}
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
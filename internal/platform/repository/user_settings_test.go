@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSettingsRepository(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	repo := NewUserSettingsRepository(db)
+	ctx := context.Background()
+
+	_, err := db.Exec(`
+		INSERT INTO users (id, email, password, first_name, last_name, role, is_active, created_at, updated_at)
+		VALUES ('user-id-settings', 'settings@example.com', 'password123', 'Test', 'User', 'user', true, NOW(), NOW())
+	`)
+	require.NoError(t, err)
+
+	settings, err := repo.Get(ctx, "user-id-settings")
+	require.NoError(t, err)
+	assert.Nil(t, settings, "never saved")
+
+	saved := users.DefaultSettings("user-id-settings")
+	saved.Locale = "de-DE"
+	saved.Notifications.NewReleases = true
+	saved.SortOrders.Movies = users.SortOrder{SortBy: "title", Order: "asc"}
+	saved.UpdatedAt = time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.Save(ctx, saved))
+
+	settings, err = repo.Get(ctx, "user-id-settings")
+	require.NoError(t, err)
+	assert.Equal(t, saved.Locale, settings.Locale)
+	assert.Equal(t, saved.Notifications, settings.Notifications)
+	assert.Equal(t, saved.SortOrders, settings.SortOrders)
+	assert.True(t, saved.UpdatedAt.Equal(settings.UpdatedAt))
+
+	// Settings added after the document was saved get their defaults
+	_, err = db.Exec(`UPDATE user_settings SET settings = '{"locale": "fr"}' WHERE user_id = 'user-id-settings'`)
+	require.NoError(t, err)
+	settings, err = repo.Get(ctx, "user-id-settings")
+	require.NoError(t, err)
+	assert.Equal(t, "fr", settings.Locale)
+	assert.Equal(t, users.DefaultSettings("").SortOrders, settings.SortOrders)
+
+	missing := users.DefaultSettings("user-id-missing")
+	assert.ErrorIs(t, repo.Save(ctx, missing), users.ErrUserNotFound)
+}
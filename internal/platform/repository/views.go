@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/views"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type viewRepository struct {
+	db *sqlx.DB
+}
+
+func NewViewRepository(db *sqlx.DB) views.Repository {
+	return &viewRepository{db: db}
+}
+
+// Add skips the counts of movies deleted since they were viewed, so they
+// don't fail the rest
+func (v *viewRepository) Add(ctx context.Context, counts []*views.DailyViews) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	movieIDs := make([]string, len(counts))
+	days := make([]string, len(counts))
+	viewCounts := make([]int64, len(counts))
+	for i, c := range counts {
+		movieIDs[i] = string(c.MovieID)
+		days[i] = c.Day.UTC().Format(time.DateOnly)
+		viewCounts[i] = c.Views
+	}
+
+	query := `
+		INSERT INTO movie_daily_views (movie_id, day, views)
+		SELECT c.movie_id, c.day, c.views
+		FROM unnest($1::text[], $2::date[], $3::bigint[]) AS c(movie_id, day, views)
+		JOIN movies m ON m.id = c.movie_id
+		ON CONFLICT (movie_id, day) DO UPDATE
+		SET views = movie_daily_views.views + EXCLUDED.views`
+
+	if _, err := v.db.ExecContext(ctx, query, pq.Array(movieIDs), pq.Array(days), pq.Array(viewCounts)); err != nil {
+		return fmt.Errorf("failed to add movie views: %w", err)
+	}
+	return nil
+}
+
+func (v *viewRepository) Top(ctx context.Context, day time.Time, limit int) ([]*views.DailyViews, error) {
+	query := `
+		SELECT movie_id, day, views
+		FROM movie_daily_views
+		WHERE day = $1::date
+		ORDER BY views DESC, movie_id
+		LIMIT $2`
+
+	rows, err := v.db.QueryContext(ctx, query, day.UTC().Format(time.DateOnly), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list most viewed movies: %w", err)
+	}
+	defer rows.Close()
+
+	top := []*views.DailyViews{}
+	for rows.Next() {
+		var c views.DailyViews
+		if err := rows.Scan(&c.MovieID, &c.Day, &c.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan movie views: %w", err)
+		}
+		top = append(top, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating movie views: %w", err)
+	}
+	return top, nil
+}
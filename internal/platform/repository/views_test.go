@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/views"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewRepository(t *testing.T) {
+	db := setupMovieTestDB(t)
+	defer db.Close()
+
+	for _, id := range []string{"test-id-views-1", "test-id-views-2"} {
+		_, err := db.Exec(`
+			INSERT INTO movies (id, title, description, release_year, genre, director, duration_mins, rating, language, country, created_at, updated_at)
+			VALUES ($1, 'Title', '', 1980, 'Drama', 'Director', 100, 'R', 'English', 'USA', NOW(), NOW())
+		`, id)
+		require.NoError(t, err)
+	}
+
+	repo := NewViewRepository(db)
+	ctx := context.Background()
+	today := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	require.NoError(t, repo.Add(ctx, []*views.DailyViews{
+		{MovieID: "test-id-views-1", Day: today, Views: 2},
+		{MovieID: "test-id-views-2", Day: today, Views: 3},
+		{MovieID: "test-id-views-1", Day: yesterday, Views: 10},
+		{MovieID: "test-id-views-deleted", Day: today, Views: 100},
+	}))
+	// A later flush adds to what is stored
+	require.NoError(t, repo.Add(ctx, []*views.DailyViews{
+		{MovieID: "test-id-views-1", Day: today, Views: 2},
+	}))
+	require.NoError(t, repo.Add(ctx, nil))
+
+	top, err := repo.Top(ctx, today, 10)
+	require.NoError(t, err)
+	require.Len(t, top, 2, "views of deleted movies are dropped")
+	assert.Equal(t, "test-id-views-1", string(top[0].MovieID))
+	assert.Equal(t, int64(4), top[0].Views)
+	assert.True(t, today.Equal(top[0].Day))
+	assert.Equal(t, int64(3), top[1].Views)
+
+	top, err = repo.Top(ctx, today, 1)
+	require.NoError(t, err)
+	assert.Len(t, top, 1)
+}
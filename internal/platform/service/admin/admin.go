@@ -0,0 +1,302 @@
+package admin
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/admin"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	// SummaryDays is the window for the per-day series
+	SummaryDays = 30
+	// TopMoviesDays is the window "this week" refers to
+	TopMoviesDays = 7
+	// DefaultTopMoviesLimit caps the top movies list
+	DefaultTopMoviesLimit = 10
+)
+
+type Service interface {
+	GetSummary(ctx context.Context) (*Summary, error)
+	// SetShadowBan hides or restores a user's ratings in public listings and
+	// rating aggregates. The user is not told and still sees their ratings.
+	SetShadowBan(ctx context.Context, userID string, banned bool, adminID string) error
+	// SetCritic grants or revokes verified critic status. Critics' ratings
+	// make up a movie's critic score instead of its audience score.
+	SetCritic(ctx context.Context, userID string, critic bool, adminID string) error
+	// BulkUpdateUsers deactivates, activates or changes the role of many
+	// users in one transaction. Admins cannot deactivate or demote
+	// themselves; their own ID is reported first, as skipped.
+	BulkUpdateUsers(ctx context.Context, req BulkUsersRequest, adminID string) ([]users.BulkResult, error)
+}
+
+type adminService struct {
+	summaryRepo    admin.SummaryRepository
+	userRepo       users.UserRepository
+	cache          cache.Cache
+	timeProvider   shared.TimeProvider
+	logger         *slog.Logger
+	topMoviesLimit int
+}
+
+// Option configures optional settings of the admin service
+type Option func(*adminService)
+
+// WithTopMoviesLimit sets how many movies the weekly top list holds
+func WithTopMoviesLimit(limit int) Option {
+	return func(s *adminService) {
+		if limit > 0 {
+			s.topMoviesLimit = limit
+		}
+	}
+}
+
+func NewAdminService(
+	summaryRepo admin.SummaryRepository,
+	userRepo users.UserRepository,
+	cache cache.Cache,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &adminService{
+		summaryRepo:    summaryRepo,
+		userRepo:       userRepo,
+		cache:          cache,
+		timeProvider:   timeProvider,
+		logger:         logger,
+		topMoviesLimit: DefaultTopMoviesLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetSummary returns the dashboard summary. The aggregates are expensive
+// full-table scans, so they are served from cache when possible; health is
+// always checked live so an outage shows up immediately.
+func (s *adminService) GetSummary(ctx context.Context) (*Summary, error) {
+	var summary Summary
+	if err := s.cache.Get(ctx, cache.AdminSummaryKey, &summary); err != nil {
+		aggregated, err := s.aggregate(ctx)
+		if err != nil {
+			s.logger.Error("Failed to aggregate admin summary", "error", err)
+			return nil, errors.NewInternalError("Failed to build admin summary")
+		}
+		if err := s.cache.Set(ctx, cache.AdminSummaryKey, aggregated, cache.AdminSummaryTTL); err != nil {
+			s.logger.Warn("Failed to cache admin summary", "error", err)
+		}
+		summary = *aggregated
+	}
+
+	summary.Health = s.health(ctx)
+	return &summary, nil
+}
+
+func (s *adminService) aggregate(ctx context.Context) (*Summary, error) {
+	now := s.timeProvider.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(SummaryDays - 1))
+	weekStart := today.AddDate(0, 0, -(TopMoviesDays - 1))
+
+	totals, err := s.summaryRepo.Totals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.summaryRepo.RatingsPerDay(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	signups, err := s.summaryRepo.SignupsPerDay(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	top, err := s.summaryRepo.TopMovies(ctx, weekStart, s.topMoviesLimit)
+	if err != nil {
+		return nil, err
+	}
+	if top == nil {
+		top = []*admin.TopMovie{}
+	}
+
+	signupsPerDay := fillDays(signups, since, SummaryDays)
+	return &Summary{
+		GeneratedAt:       now,
+		Totals:            *totals,
+		RatingsPerDay:     fillDays(ratings, since, SummaryDays),
+		TopMoviesThisWeek: top,
+		NewSignups: Signups{
+			Last7Days:  sumFrom(signupsPerDay, weekStart),
+			Last30Days: sumFrom(signupsPerDay, since),
+			PerDay:     signupsPerDay,
+		},
+	}, nil
+}
+
+func (s *adminService) SetShadowBan(ctx context.Context, userID string, banned bool, adminID string) error {
+	err := s.userRepo.SetShadowBanned(ctx, users.UserID(userID), banned, s.timeProvider.Now())
+	if err != nil {
+		if stdErrors.Is(err, users.ErrUserNotFound) {
+			return errors.NewNotFoundError("User not found")
+		}
+		s.logger.Error("Failed to update shadow ban", "error", err, "user_id", userID)
+		return errors.NewInternalError("Failed to update shadow ban")
+	}
+
+	s.logger.Info("Updated shadow ban", "user_id", userID, "shadow_banned", banned, "admin_id", adminID)
+
+	// Cached profiles embed movie averages the user's ratings counted towards
+	if err := s.cache.InvalidateTags(ctx, cache.ProfilesTag); err != nil {
+		s.logger.Warn("Failed to invalidate profile caches after shadow ban", "error", err)
+	}
+	if err := s.cache.Delete(ctx, cache.AdminSummaryKey); err != nil {
+		s.logger.Warn("Failed to invalidate admin summary after shadow ban", "error", err)
+	}
+
+	return nil
+}
+
+func (s *adminService) SetCritic(ctx context.Context, userID string, critic bool, adminID string) error {
+	err := s.userRepo.SetCritic(ctx, users.UserID(userID), critic, s.timeProvider.Now())
+	if err != nil {
+		if stdErrors.Is(err, users.ErrUserNotFound) {
+			return errors.NewNotFoundError("User not found")
+		}
+		s.logger.Error("Failed to update critic status", "error", err, "user_id", userID)
+		return errors.NewInternalError("Failed to update critic status")
+	}
+
+	s.logger.Info("Updated critic status", "user_id", userID, "is_critic", critic, "admin_id", adminID)
+	return nil
+}
+
+func (s *adminService) BulkUpdateUsers(ctx context.Context, req BulkUsersRequest, adminID string) ([]users.BulkResult, error) {
+	action, err := users.ParseBulkAction(req.Action)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	update := users.BulkUpdate{Action: action, UpdatedAt: s.timeProvider.Now()}
+	if action == users.BulkChangeRole {
+		switch role := users.Role(req.Role); role {
+		case users.RoleAdmin, users.RoleUser:
+			update.Role = role
+		default:
+			return nil, errors.NewBadRequestError("role must be one of: admin, user")
+		}
+	}
+
+	// Duplicates are collapsed so every user gets exactly one result
+	ids := make([]users.UserID, 0, len(req.UserIDs))
+	seen := make(map[users.UserID]bool, len(req.UserIDs))
+	for _, raw := range req.UserIDs {
+		id := users.UserID(strings.TrimSpace(raw))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, errors.NewBadRequestError("user_ids must not be empty")
+	}
+	if len(ids) > users.MaxBulkUsers {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("at most %d users can be updated at once", users.MaxBulkUsers))
+	}
+
+	// Activating yourself is harmless; locking yourself out is not
+	protectSelf := action != users.BulkActivate && seen[users.UserID(adminID)]
+	for _, id := range ids {
+		if !(protectSelf && id == users.UserID(adminID)) {
+			update.UserIDs = append(update.UserIDs, id)
+		}
+	}
+
+	results := make([]users.BulkResult, 0, len(ids))
+	if protectSelf {
+		results = append(results, users.BulkResult{UserID: users.UserID(adminID), Status: users.BulkSkipped, Reason: "cannot change your own account"})
+	}
+	if len(update.UserIDs) > 0 {
+		applied, err := s.userRepo.BulkUpdate(ctx, update)
+		if err != nil {
+			s.logger.Error("Failed to bulk update users", "error", err, "action", action, "users", len(update.UserIDs))
+			return nil, errors.NewInternalError("Failed to update users")
+		}
+		results = append(results, applied...)
+	}
+
+	updated := 0
+	for _, result := range results {
+		if result.Status == users.BulkUpdated {
+			updated++
+		}
+	}
+
+	s.logger.Info("Bulk updated users", "action", action, "role", update.Role, "requested", len(ids), "updated", updated, "admin_id", adminID)
+
+	if updated > 0 {
+		if err := s.cache.Delete(ctx, cache.AdminSummaryKey); err != nil {
+			s.logger.Warn("Failed to invalidate admin summary after bulk update", "error", err)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *adminService) health(ctx context.Context) Health {
+	return Health{
+		Database: checkComponent(ctx, s.summaryRepo.Ping),
+		Cache:    checkComponent(ctx, s.cache.Ping),
+	}
+}
+
+func checkComponent(ctx context.Context, ping func(context.Context) error) ComponentHealth {
+	start := time.Now()
+	err := ping(ctx)
+	health := ComponentHealth{
+		Status:    HealthUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		health.Status = HealthDown
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// fillDays expands sparse per-day counts into one entry per day starting at
+// since, so charts don't have to deal with gaps
+func fillDays(counts []admin.DailyCount, since time.Time, days int) []admin.DailyCount {
+	byDay := make(map[time.Time]int64, len(counts))
+	for _, c := range counts {
+		byDay[c.Date.UTC().Truncate(24*time.Hour)] += c.Count
+	}
+
+	filled := make([]admin.DailyCount, days)
+	for i := range filled {
+		day := since.AddDate(0, 0, i)
+		filled[i] = admin.DailyCount{Date: day, Count: byDay[day]}
+	}
+	return filled
+}
+
+func sumFrom(counts []admin.DailyCount, from time.Time) int64 {
+	var total int64
+	for _, c := range counts {
+		if !c.Date.Before(from) {
+			total += c.Count
+		}
+	}
+	return total
+}
@@ -0,0 +1,294 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/admin"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+var testNow = time.Date(2024, 3, 30, 15, 30, 0, 0, time.UTC)
+
+func setupTestService() (Service, *mockSummaryRepository, *cache.MockCache) {
+	service, repo, _, c := setupTestServiceWithUsers()
+	return service, repo, c
+}
+
+func setupTestServiceWithUsers() (Service, *mockSummaryRepository, *mockUserRepository, *cache.MockCache) {
+	repo := new(mockSummaryRepository)
+	userRepo := new(mockUserRepository)
+	c := new(cache.MockCache)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewAdminService(repo, userRepo, c, &mockTimeProvider{now: testNow}, logger, WithTopMoviesLimit(5))
+	return service, repo, userRepo, c
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGetSummary(t *testing.T) {
+	ctx := context.Background()
+	since := day(1)
+	weekStart := day(24)
+
+	t.Run("aggregates and caches on a cache miss", func(t *testing.T) {
+		service, repo, c := setupTestService()
+		c.On("Get", ctx, cache.AdminSummaryKey, mock.Anything).Return(cache.ErrCacheMiss)
+		repo.On("Totals", ctx).Return(&admin.Totals{Users: 10, ActiveUsers: 8, Movies: 50, Ratings: 200}, nil)
+		repo.On("RatingsPerDay", ctx, since).Return([]admin.DailyCount{
+			{Date: day(1), Count: 4},
+			{Date: day(30), Count: 6},
+		}, nil)
+		repo.On("SignupsPerDay", ctx, since).Return([]admin.DailyCount{
+			{Date: day(10), Count: 2},
+			{Date: day(25), Count: 3},
+		}, nil)
+		repo.On("TopMovies", ctx, weekStart, 5).Return([]*admin.TopMovie{{MovieID: "m1", Title: "Alien", Ratings: 9}}, nil)
+		repo.On("Ping", ctx).Return(nil)
+		c.On("Set", ctx, cache.AdminSummaryKey, mock.AnythingOfType("*admin.Summary"), cache.AdminSummaryTTL).Return(nil)
+		c.On("Ping", ctx).Return(nil)
+
+		summary, err := service.GetSummary(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, testNow, summary.GeneratedAt)
+		assert.Equal(t, int64(200), summary.Totals.Ratings)
+		require.Len(t, summary.RatingsPerDay, SummaryDays)
+		assert.Equal(t, admin.DailyCount{Date: day(1), Count: 4}, summary.RatingsPerDay[0])
+		assert.Equal(t, admin.DailyCount{Date: day(15), Count: 0}, summary.RatingsPerDay[14])
+		assert.Equal(t, admin.DailyCount{Date: day(30), Count: 6}, summary.RatingsPerDay[29])
+		assert.Equal(t, int64(3), summary.NewSignups.Last7Days)
+		assert.Equal(t, int64(5), summary.NewSignups.Last30Days)
+		assert.Len(t, summary.NewSignups.PerDay, SummaryDays)
+		assert.Len(t, summary.TopMoviesThisWeek, 1)
+		assert.Equal(t, HealthUp, summary.Health.Database.Status)
+		assert.Equal(t, HealthUp, summary.Health.Cache.Status)
+		c.AssertExpectations(t)
+	})
+
+	t.Run("serves aggregates from cache but checks health live", func(t *testing.T) {
+		service, repo, c := setupTestService()
+		c.On("Get", ctx, cache.AdminSummaryKey, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(2).(*Summary).Totals = admin.Totals{Users: 42}
+		})
+		repo.On("Ping", ctx).Return(errors.New("connection refused"))
+		c.On("Ping", ctx).Return(nil)
+
+		summary, err := service.GetSummary(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), summary.Totals.Users)
+		assert.Equal(t, HealthDown, summary.Health.Database.Status)
+		assert.Equal(t, "connection refused", summary.Health.Database.Error)
+		assert.Equal(t, HealthUp, summary.Health.Cache.Status)
+		repo.AssertNotCalled(t, "Totals", mock.Anything)
+	})
+
+	t.Run("returns an internal error when aggregation fails", func(t *testing.T) {
+		service, repo, c := setupTestService()
+		c.On("Get", ctx, cache.AdminSummaryKey, mock.Anything).Return(cache.ErrCacheMiss)
+		repo.On("Totals", ctx).Return(nil, errors.New("db down"))
+
+		_, err := service.GetSummary(ctx)
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeInternal), appErr.Code)
+		c.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetShadowBan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("bans the user and drops aggregate caches", func(t *testing.T) {
+		service, _, userRepo, c := setupTestServiceWithUsers()
+		userRepo.On("SetShadowBanned", ctx, users.UserID("user-1"), true, testNow).Return(nil)
+		c.On("InvalidateTags", ctx, []string{cache.ProfilesTag}).Return(nil)
+		c.On("Delete", ctx, []string{cache.AdminSummaryKey}).Return(nil)
+
+		err := service.SetShadowBan(ctx, "user-1", true, "admin-1")
+
+		require.NoError(t, err)
+		userRepo.AssertExpectations(t)
+		c.AssertExpectations(t)
+	})
+
+	t.Run("cache failures do not fail the ban", func(t *testing.T) {
+		service, _, userRepo, c := setupTestServiceWithUsers()
+		userRepo.On("SetShadowBanned", ctx, users.UserID("user-1"), false, testNow).Return(nil)
+		c.On("InvalidateTags", ctx, []string{cache.ProfilesTag}).Return(errors.New("redis down"))
+		c.On("Delete", ctx, []string{cache.AdminSummaryKey}).Return(errors.New("redis down"))
+
+		require.NoError(t, service.SetShadowBan(ctx, "user-1", false, "admin-1"))
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("SetShadowBanned", ctx, users.UserID("missing"), true, testNow).Return(users.ErrUserNotFound)
+
+		err := service.SetShadowBan(ctx, "missing", true, "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeNotFound), appErr.Code)
+	})
+}
+
+func TestSetCritic(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("grants critic status", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("SetCritic", ctx, users.UserID("user-1"), true, testNow).Return(nil)
+
+		require.NoError(t, service.SetCritic(ctx, "user-1", true, "admin-1"))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("SetCritic", ctx, users.UserID("missing"), false, testNow).Return(users.ErrUserNotFound)
+
+		err := service.SetCritic(ctx, "missing", false, "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeNotFound), appErr.Code)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("SetCritic", ctx, users.UserID("user-1"), true, testNow).Return(errors.New("connection reset"))
+
+		err := service.SetCritic(ctx, "user-1", true, "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeInternal), appErr.Code)
+	})
+}
+
+func TestBulkUpdateUsers(t *testing.T) {
+	ctx := context.Background()
+
+	assertBadRequest := func(t *testing.T, err error) {
+		t.Helper()
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeBadRequest), appErr.Code)
+	}
+
+	t.Run("deactivates users and skips the calling admin", func(t *testing.T) {
+		service, _, userRepo, c := setupTestServiceWithUsers()
+		userRepo.On("BulkUpdate", ctx, users.BulkUpdate{
+			Action:    users.BulkDeactivate,
+			UserIDs:   []users.UserID{"bot-1", "bot-2"},
+			UpdatedAt: testNow,
+		}).Return([]users.BulkResult{
+			{UserID: "bot-1", Status: users.BulkUpdated},
+			{UserID: "bot-2", Status: users.BulkNotFound},
+		}, nil)
+		c.On("Delete", ctx, []string{cache.AdminSummaryKey}).Return(nil)
+
+		results, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{
+			Action:  "deactivate",
+			UserIDs: []string{"bot-1", " bot-1 ", "admin-1", "", "bot-2"},
+		}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []users.BulkResult{
+			{UserID: "admin-1", Status: users.BulkSkipped, Reason: "cannot change your own account"},
+			{UserID: "bot-1", Status: users.BulkUpdated},
+			{UserID: "bot-2", Status: users.BulkNotFound},
+		}, results)
+		userRepo.AssertExpectations(t)
+		c.AssertExpectations(t)
+	})
+
+	t.Run("admins may activate themselves", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("BulkUpdate", ctx, users.BulkUpdate{
+			Action:    users.BulkActivate,
+			UserIDs:   []users.UserID{"admin-1"},
+			UpdatedAt: testNow,
+		}).Return([]users.BulkResult{{UserID: "admin-1", Status: users.BulkUnchanged}}, nil)
+
+		results, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "activate", UserIDs: []string{"admin-1"}}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, []users.BulkResult{{UserID: "admin-1", Status: users.BulkUnchanged}}, results)
+	})
+
+	t.Run("changes roles", func(t *testing.T) {
+		service, _, userRepo, c := setupTestServiceWithUsers()
+		userRepo.On("BulkUpdate", ctx, users.BulkUpdate{
+			Action:    users.BulkChangeRole,
+			Role:      users.RoleAdmin,
+			UserIDs:   []users.UserID{"user-1"},
+			UpdatedAt: testNow,
+		}).Return([]users.BulkResult{{UserID: "user-1", Status: users.BulkUpdated}}, nil)
+		c.On("Delete", ctx, []string{cache.AdminSummaryKey}).Return(nil)
+
+		_, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "change_role", Role: "admin", UserIDs: []string{"user-1"}}, "admin-1")
+
+		require.NoError(t, err)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("only the calling admin is a no-op", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+
+		results, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "deactivate", UserIDs: []string{"admin-1"}}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, users.BulkSkipped, results[0].Status)
+		userRepo.AssertNotCalled(t, "BulkUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+
+		_, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "delete", UserIDs: []string{"u"}}, "admin-1")
+		assertBadRequest(t, err)
+
+		_, err = service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "change_role", Role: "owner", UserIDs: []string{"u"}}, "admin-1")
+		assertBadRequest(t, err)
+
+		_, err = service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "activate", UserIDs: []string{" "}}, "admin-1")
+		assertBadRequest(t, err)
+
+		tooMany := make([]string, users.MaxBulkUsers+1)
+		for i := range tooMany {
+			tooMany[i] = fmt.Sprintf("user-%d", i)
+		}
+		_, err = service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "activate", UserIDs: tooMany}, "admin-1")
+		assertBadRequest(t, err)
+
+		userRepo.AssertNotCalled(t, "BulkUpdate", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		service, _, userRepo, _ := setupTestServiceWithUsers()
+		userRepo.On("BulkUpdate", ctx, mock.Anything).Return(nil, errors.New("deadlock detected"))
+
+		_, err := service.BulkUpdateUsers(ctx, BulkUsersRequest{Action: "activate", UserIDs: []string{"u"}}, "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeInternal), appErr.Code)
+	})
+}
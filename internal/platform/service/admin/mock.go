@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"context"
+	"thermondo/internal/domain/admin"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockSummaryRepository struct {
+	mock.Mock
+}
+
+func (m *mockSummaryRepository) Totals(ctx context.Context) (*admin.Totals, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*admin.Totals), args.Error(1)
+}
+
+func (m *mockSummaryRepository) RatingsPerDay(ctx context.Context, since time.Time) ([]admin.DailyCount, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]admin.DailyCount), args.Error(1)
+}
+
+func (m *mockSummaryRepository) SignupsPerDay(ctx context.Context, since time.Time) ([]admin.DailyCount, error) {
+	args := m.Called(ctx, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]admin.DailyCount), args.Error(1)
+}
+
+func (m *mockSummaryRepository) TopMovies(ctx context.Context, since time.Time, limit int) ([]*admin.TopMovie, error) {
+	args := m.Called(ctx, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*admin.TopMovie), args.Error(1)
+}
+
+func (m *mockSummaryRepository) Ping(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// mockUserRepository mocks the user operations the admin service uses; the
+// embedded interface panics for anything else
+type mockUserRepository struct {
+	users.UserRepository
+	mock.Mock
+}
+
+func (m *mockUserRepository) SetShadowBanned(ctx context.Context, id users.UserID, banned bool, updatedAt time.Time) error {
+	args := m.Called(ctx, id, banned, updatedAt)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) SetCritic(ctx context.Context, id users.UserID, critic bool, updatedAt time.Time) error {
+	args := m.Called(ctx, id, critic, updatedAt)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) BulkUpdate(ctx context.Context, update users.BulkUpdate) ([]users.BulkResult, error) {
+	args := m.Called(ctx, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.BulkResult), args.Error(1)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,49 @@
+package admin
+
+import (
+	"thermondo/internal/domain/admin"
+	"time"
+)
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// Summary is the ops dashboard payload. Everything except Health may be up
+// to AdminSummaryTTL old; GeneratedAt says when the aggregates were computed.
+type Summary struct {
+	GeneratedAt       time.Time          `json:"generated_at"`
+	Totals            admin.Totals       `json:"totals"`
+	RatingsPerDay     []admin.DailyCount `json:"ratings_per_day"`
+	TopMoviesThisWeek []*admin.TopMovie  `json:"top_movies_this_week"`
+	NewSignups        Signups            `json:"new_signups"`
+	Health            Health             `json:"health"`
+}
+
+// Signups counts new users over the summary window
+type Signups struct {
+	Last7Days  int64              `json:"last_7_days"`
+	Last30Days int64              `json:"last_30_days"`
+	PerDay     []admin.DailyCount `json:"per_day"`
+}
+
+// Health is checked live on every request and is never cached
+type Health struct {
+	Database ComponentHealth `json:"database"`
+	Cache    ComponentHealth `json:"cache"`
+}
+
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkUsersRequest applies one action to many users at once. Role is
+// required for the change_role action and ignored otherwise.
+type BulkUsersRequest struct {
+	Action  string   `json:"action"`
+	Role    string   `json:"role,omitempty"`
+	UserIDs []string `json:"user_ids"`
+}
@@ -0,0 +1,219 @@
+package anonymous
+
+import (
+	"context"
+	stdErrors "errors"
+	"log/slog"
+	"thermondo/internal/domain/anonymous"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/markdown"
+	"time"
+)
+
+// Service lets devices rate movies before their owner signs up, and moves
+// those ratings to the account once they do
+type Service interface {
+	// Start registers a new device. The device token is only ever returned
+	// here.
+	Start(ctx context.Context) (*StartResponse, error)
+	// Authenticate resolves a device token to its principal. Unknown tokens
+	// and those of claimed devices are rejected with a 401.
+	Authenticate(ctx context.Context, deviceToken string) (*anonymous.Principal, error)
+	// Rate stores the device's rating of a movie, replacing an earlier one
+	Rate(ctx context.Context, principalID anonymous.PrincipalID, req RateRequest) (*anonymous.Rating, error)
+	// ListRatings returns the device's ratings, most recently changed first
+	ListRatings(ctx context.Context, principalID anonymous.PrincipalID) ([]*anonymous.Rating, error)
+	// Claim moves the ratings of the device to the user's account
+	Claim(ctx context.Context, userID string, req ClaimRequest) (*anonymous.ClaimResult, error)
+}
+
+// StartResponse holds the token a device authenticates with from now on
+type StartResponse struct {
+	PrincipalID anonymous.PrincipalID `json:"principal_id"`
+	DeviceToken string                `json:"device_token"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+type RateRequest struct {
+	MovieID string `json:"-"`
+	Score   int    `json:"score"`
+	Review  string `json:"review"`
+}
+
+type ClaimRequest struct {
+	DeviceToken string `json:"device_token"`
+	// OnConflict is one of the anonymous.ConflictStrategy values and
+	// defaults to keep_account
+	OnConflict string `json:"on_conflict"`
+}
+
+type anonymousService struct {
+	repo         anonymous.Repository
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	reviewLimits rating.ReviewLimits
+}
+
+// Option configures optional settings of the anonymous service
+type Option func(*anonymousService)
+
+// WithReviewLimits holds anonymous reviews to the same limits as the
+// reviews they will become
+func WithReviewLimits(limits rating.ReviewLimits) Option {
+	return func(s *anonymousService) {
+		s.reviewLimits = limits
+	}
+}
+
+func NewAnonymousService(
+	repo anonymous.Repository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &anonymousService{
+		repo:         repo,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+		reviewLimits: rating.DefaultReviewLimits(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *anonymousService) Start(ctx context.Context) (*StartResponse, error) {
+	token, hash := anonymous.NewDeviceToken()
+	principal := &anonymous.Principal{
+		ID:        anonymous.PrincipalID(s.idGenerator.Generate()),
+		TokenHash: hash,
+		CreatedAt: s.timeProvider.Now(),
+	}
+	if err := s.repo.CreatePrincipal(ctx, principal); err != nil {
+		s.logger.Error("Failed to create anonymous principal", "error", err)
+		return nil, errors.NewInternalError("Failed to start anonymous session")
+	}
+
+	s.logger.Info("Started anonymous session", "principal_id", principal.ID)
+	return &StartResponse{PrincipalID: principal.ID, DeviceToken: token, CreatedAt: principal.CreatedAt}, nil
+}
+
+func (s *anonymousService) Authenticate(ctx context.Context, deviceToken string) (*anonymous.Principal, error) {
+	if deviceToken == "" {
+		return nil, errors.NewUnauthorizedError("Missing device token")
+	}
+	principal, err := s.repo.FindPrincipalByTokenHash(ctx, anonymous.HashDeviceToken(deviceToken))
+	if err != nil {
+		if stdErrors.Is(err, anonymous.ErrPrincipalNotFound) {
+			return nil, errors.NewUnauthorizedError("Invalid device token")
+		}
+		s.logger.Error("Failed to find anonymous principal", "error", err)
+		return nil, errors.NewInternalError("Failed to verify device token")
+	}
+	if principal.Claimed() {
+		return nil, errors.NewUnauthorizedError("Device ratings were claimed; sign in instead")
+	}
+	return principal, nil
+}
+
+func (s *anonymousService) Rate(ctx context.Context, principalID anonymous.PrincipalID, req RateRequest) (*anonymous.Rating, error) {
+	r, err := anonymous.NewRating(principalID, movies.MovieID(req.MovieID), req.Score, req.Review, s.timeProvider.Now())
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := s.reviewLimits.Check(r.Review); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	saved, err := s.repo.SaveRating(ctx, r)
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, anonymous.ErrMovieNotFound):
+			return nil, errors.NewNotFoundError("Movie not found")
+		case stdErrors.Is(err, anonymous.ErrAlreadyClaimed):
+			return nil, errors.NewConflictError("Device ratings were claimed; rate from the account instead")
+		}
+		s.logger.Error("Failed to save anonymous rating", "error", err, "principal_id", principalID, "movie_id", req.MovieID)
+		return nil, errors.NewInternalError("Failed to save rating")
+	}
+	return saved, nil
+}
+
+func (s *anonymousService) ListRatings(ctx context.Context, principalID anonymous.PrincipalID) ([]*anonymous.Rating, error) {
+	ratings, err := s.repo.ListRatings(ctx, principalID)
+	if err != nil {
+		s.logger.Error("Failed to list anonymous ratings", "error", err, "principal_id", principalID)
+		return nil, errors.NewInternalError("Failed to list ratings")
+	}
+	return ratings, nil
+}
+
+func (s *anonymousService) Claim(ctx context.Context, userID string, req ClaimRequest) (*anonymous.ClaimResult, error) {
+	strategy, err := anonymous.ParseConflictStrategy(req.OnConflict)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if req.DeviceToken == "" {
+		return nil, errors.NewBadRequestError("device_token is required")
+	}
+
+	principal, err := s.repo.FindPrincipalByTokenHash(ctx, anonymous.HashDeviceToken(req.DeviceToken))
+	if err != nil {
+		if stdErrors.Is(err, anonymous.ErrPrincipalNotFound) {
+			return nil, errors.NewNotFoundError("Device not found")
+		}
+		s.logger.Error("Failed to find anonymous principal", "error", err, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to claim ratings")
+	}
+	if principal.Claimed() {
+		return nil, errors.NewConflictError(anonymous.ErrAlreadyClaimed.Error())
+	}
+
+	deviceRatings, err := s.repo.ListRatings(ctx, principal.ID)
+	if err != nil {
+		s.logger.Error("Failed to list anonymous ratings", "error", err, "principal_id", principal.ID)
+		return nil, errors.NewInternalError("Failed to claim ratings")
+	}
+	candidates := make([]*rating.Rating, len(deviceRatings))
+	for i, r := range deviceRatings {
+		candidates[i] = &rating.Rating{
+			ID:               rating.RatingID(s.idGenerator.Generate()),
+			UserID:           users.UserID(userID),
+			MovieID:          r.MovieID,
+			Score:            r.Score,
+			Review:           r.Review,
+			ContainsSpoilers: markdown.HasSpoilers(r.Review),
+			CreatedAt:        r.CreatedAt,
+			UpdatedAt:        r.UpdatedAt,
+			Version:          1,
+		}
+	}
+
+	result, err := s.repo.Claim(ctx, principal.ID, users.UserID(userID), candidates, strategy, s.timeProvider.Now())
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, anonymous.ErrAlreadyClaimed):
+			return nil, errors.NewConflictError(err.Error())
+		case stdErrors.Is(err, users.ErrUserNotFound):
+			return nil, errors.NewNotFoundError("User not found")
+		}
+		s.logger.Error("Failed to claim anonymous ratings", "error", err, "principal_id", principal.ID, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to claim ratings")
+	}
+
+	s.logger.Info("Claimed anonymous ratings",
+		"principal_id", principal.ID,
+		"user_id", userID,
+		"imported", result.Imported,
+		"conflicts", len(result.Conflicts),
+		"on_conflict", strategy)
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package anonymous
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/anonymous"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupTestService() (Service, *mockAnonymousRepository) {
+	repo := new(mockAnonymousRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewAnonymousService(repo, &mockIDGenerator{}, &mockTimeProvider{now: testNow}, logger), repo
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestStart(t *testing.T) {
+	service, repo := setupTestService()
+	var stored *anonymous.Principal
+	repo.On("CreatePrincipal", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*anonymous.Principal)
+	}).Return(nil)
+
+	started, err := service.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, anonymous.PrincipalID("id-1"), started.PrincipalID)
+	assert.True(t, strings.HasPrefix(started.DeviceToken, anonymous.DeviceTokenPrefix))
+	assert.Equal(t, anonymous.HashDeviceToken(started.DeviceToken), stored.TokenHash, "only the hash is stored")
+	assert.NotContains(t, stored.TokenHash, started.DeviceToken)
+}
+
+func TestAuthenticate(t *testing.T) {
+	claimedAt := testNow
+	service, repo := setupTestService()
+	repo.On("FindPrincipalByTokenHash", mock.Anything, anonymous.HashDeviceToken("anon_phone")).Return(&anonymous.Principal{ID: "principal-1"}, nil)
+	repo.On("FindPrincipalByTokenHash", mock.Anything, anonymous.HashDeviceToken("anon_claimed")).Return(&anonymous.Principal{ID: "principal-2", ClaimedAt: &claimedAt}, nil)
+	repo.On("FindPrincipalByTokenHash", mock.Anything, anonymous.HashDeviceToken("anon_unknown")).Return(nil, anonymous.ErrPrincipalNotFound)
+
+	principal, err := service.Authenticate(context.Background(), "anon_phone")
+	require.NoError(t, err)
+	assert.Equal(t, anonymous.PrincipalID("principal-1"), principal.ID)
+
+	for _, token := range []string{"", "anon_claimed", "anon_unknown"} {
+		_, err := service.Authenticate(context.Background(), token)
+		assertAppErrorCode(t, err, appErrors.CodeUnauthorized)
+	}
+}
+
+func TestRate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves the rating", func(t *testing.T) {
+		service, repo := setupTestService()
+		want := &anonymous.Rating{PrincipalID: "principal-1", MovieID: "movie-1", Score: 4, Review: "Tense", CreatedAt: testNow, UpdatedAt: testNow}
+		repo.On("SaveRating", ctx, want).Return(want, nil)
+
+		saved, err := service.Rate(ctx, "principal-1", RateRequest{MovieID: "movie-1", Score: 4, Review: "  Tense "})
+		require.NoError(t, err)
+		assert.Equal(t, want, saved)
+	})
+
+	t.Run("rejects invalid scores", func(t *testing.T) {
+		service, repo := setupTestService()
+
+		_, err := service.Rate(ctx, "principal-1", RateRequest{MovieID: "movie-1", Score: 6})
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		repo.AssertNotCalled(t, "SaveRating", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown movie", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("SaveRating", ctx, mock.Anything).Return(nil, anonymous.ErrMovieNotFound)
+
+		_, err := service.Rate(ctx, "principal-1", RateRequest{MovieID: "movie-9", Score: 3})
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestClaim(t *testing.T) {
+	ctx := context.Background()
+	hash := anonymous.HashDeviceToken("anon_phone")
+
+	t.Run("moves the device ratings to the account", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindPrincipalByTokenHash", ctx, hash).Return(&anonymous.Principal{ID: "principal-1"}, nil)
+		repo.On("ListRatings", ctx, anonymous.PrincipalID("principal-1")).Return([]*anonymous.Rating{
+			{PrincipalID: "principal-1", MovieID: "movie-1", Score: 5, Review: "||The twist||", CreatedAt: testNow.Add(-time.Hour), UpdatedAt: testNow.Add(-time.Minute)},
+		}, nil)
+		result := &anonymous.ClaimResult{PrincipalID: "principal-1", UserID: "user-1", Imported: 1, Conflicts: []anonymous.Conflict{}, ClaimedAt: testNow}
+		repo.On("Claim", ctx, anonymous.PrincipalID("principal-1"), users.UserID("user-1"), []*rating.Rating{{
+			ID: "id-1", UserID: "user-1", MovieID: "movie-1", Score: 5, Review: "||The twist||", ContainsSpoilers: true,
+			CreatedAt: testNow.Add(-time.Hour), UpdatedAt: testNow.Add(-time.Minute), Version: 1,
+		}}, anonymous.KeepNewest, testNow).Return(result, nil)
+
+		claimed, err := service.Claim(ctx, "user-1", ClaimRequest{DeviceToken: "anon_phone", OnConflict: "keep_newest"})
+		require.NoError(t, err)
+		assert.Equal(t, result, claimed)
+	})
+
+	t.Run("unknown conflict strategy", func(t *testing.T) {
+		service, repo := setupTestService()
+
+		_, err := service.Claim(ctx, "user-1", ClaimRequest{DeviceToken: "anon_phone", OnConflict: "keep_both"})
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		repo.AssertNotCalled(t, "FindPrincipalByTokenHash", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown device", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindPrincipalByTokenHash", ctx, hash).Return(nil, anonymous.ErrPrincipalNotFound)
+
+		_, err := service.Claim(ctx, "user-1", ClaimRequest{DeviceToken: "anon_phone"})
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+
+	t.Run("device already claimed", func(t *testing.T) {
+		claimedAt := testNow
+		service, repo := setupTestService()
+		repo.On("FindPrincipalByTokenHash", ctx, hash).Return(&anonymous.Principal{ID: "principal-1", ClaimedAt: &claimedAt}, nil)
+
+		_, err := service.Claim(ctx, "user-1", ClaimRequest{DeviceToken: "anon_phone"})
+		assertAppErrorCode(t, err, appErrors.CodeConflict)
+		repo.AssertNotCalled(t, "Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("claimed concurrently", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindPrincipalByTokenHash", ctx, hash).Return(&anonymous.Principal{ID: "principal-1"}, nil)
+		repo.On("ListRatings", ctx, anonymous.PrincipalID("principal-1")).Return([]*anonymous.Rating{}, nil)
+		repo.On("Claim", ctx, anonymous.PrincipalID("principal-1"), users.UserID("user-1"), []*rating.Rating{}, anonymous.KeepAccount, testNow).
+			Return(nil, anonymous.ErrAlreadyClaimed)
+
+		_, err := service.Claim(ctx, "user-1", ClaimRequest{DeviceToken: "anon_phone"})
+		assertAppErrorCode(t, err, appErrors.CodeConflict)
+	})
+}
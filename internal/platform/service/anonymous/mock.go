@@ -0,0 +1,72 @@
+package anonymous
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/anonymous"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockAnonymousRepository struct {
+	mock.Mock
+}
+
+func (m *mockAnonymousRepository) CreatePrincipal(ctx context.Context, principal *anonymous.Principal) error {
+	args := m.Called(ctx, principal)
+	return args.Error(0)
+}
+
+func (m *mockAnonymousRepository) FindPrincipalByTokenHash(ctx context.Context, hash string) (*anonymous.Principal, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.Principal), args.Error(1)
+}
+
+func (m *mockAnonymousRepository) SaveRating(ctx context.Context, r *anonymous.Rating) (*anonymous.Rating, error) {
+	args := m.Called(ctx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.Rating), args.Error(1)
+}
+
+func (m *mockAnonymousRepository) ListRatings(ctx context.Context, principalID anonymous.PrincipalID) ([]*anonymous.Rating, error) {
+	args := m.Called(ctx, principalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*anonymous.Rating), args.Error(1)
+}
+
+func (m *mockAnonymousRepository) Claim(ctx context.Context, principalID anonymous.PrincipalID, userID users.UserID, candidates []*rating.Rating, strategy anonymous.ConflictStrategy, at time.Time) (*anonymous.ClaimResult, error) {
+	args := m.Called(ctx, principalID, userID, candidates, strategy, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*anonymous.ClaimResult), args.Error(1)
+}
+
+// mockIDGenerator hands out id-1, id-2, ...
+type mockIDGenerator struct {
+	issued int
+}
+
+func (m *mockIDGenerator) Generate() string {
+	m.issued++
+	return fmt.Sprintf("id-%d", m.issued)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
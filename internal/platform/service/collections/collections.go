@@ -0,0 +1,141 @@
+package collections
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/errors"
+)
+
+type Service interface {
+	CreateCollection(ctx context.Context, req CreateCollectionRequest) (*collections.Collection, error)
+	GetCollection(ctx context.Context, id string) (*CollectionDetails, error)
+	AddMovie(ctx context.Context, collectionID string, req AddMovieRequest) error
+	RemoveMovie(ctx context.Context, collectionID, movieID string) error
+}
+
+type collectionService struct {
+	collectionRepo      collections.Repository
+	idGenerator         shared.IDGenerator
+	timeProvider        shared.TimeProvider
+	logger              *slog.Logger
+	bayesianConfidenceK func() float64
+}
+
+// Option configures optional settings of the collection service
+type Option func(*collectionService)
+
+// WithBayesianConfidenceK reads the prior weight of the entries' Bayesian
+// averages from k, so it follows the rating service's configuration as that
+// is reloaded
+func WithBayesianConfidenceK(k func() float64) Option {
+	return func(s *collectionService) {
+		s.bayesianConfidenceK = k
+	}
+}
+
+func NewCollectionService(
+	collectionRepo collections.Repository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &collectionService{
+		collectionRepo:      collectionRepo,
+		idGenerator:         idGenerator,
+		timeProvider:        timeProvider,
+		logger:              logger,
+		bayesianConfidenceK: func() float64 { return rating.DefaultBayesianConfidenceK },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *collectionService) CreateCollection(ctx context.Context, req CreateCollectionRequest) (*collections.Collection, error) {
+	collection, err := collections.NewCollection(req.Name, req.Description, s.idGenerator, s.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	saved, err := s.collectionRepo.Save(ctx, collection)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, errors.NewConflictError("Collection with this ID already exists")
+		}
+		s.logger.Error("Failed to create collection", "error", err)
+		return nil, errors.NewInternalError("Failed to create collection")
+	}
+
+	return saved, nil
+}
+
+func (s *collectionService) GetCollection(ctx context.Context, id string) (*CollectionDetails, error) {
+	collection, err := s.collectionRepo.GetByID(ctx, collections.CollectionID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Collection not found")
+		}
+		s.logger.Error("Failed to get collection", "error", err, "collection_id", id)
+		return nil, errors.NewInternalError("Failed to get collection")
+	}
+
+	entries, err := s.collectionRepo.GetEntries(ctx, collection.ID, s.bayesianConfidenceK())
+	if err != nil {
+		s.logger.Error("Failed to get collection entries", "error", err, "collection_id", id)
+		return nil, errors.NewInternalError("Failed to get collection")
+	}
+	if entries == nil {
+		entries = []*collections.Entry{}
+	}
+
+	return &CollectionDetails{
+		Collection: collection,
+		Entries:    entries,
+		Stats:      collections.ComputeStats(entries),
+	}, nil
+}
+
+func (s *collectionService) AddMovie(ctx context.Context, collectionID string, req AddMovieRequest) error {
+	movieID := strings.TrimSpace(req.MovieID)
+	if movieID == "" {
+		return errors.NewBadRequestError(collections.ErrEmptyMovieID.Error())
+	}
+	if req.Position != nil && *req.Position < 1 {
+		return errors.NewBadRequestError(collections.ErrInvalidPosition.Error())
+	}
+
+	err := s.collectionRepo.AddMovie(ctx, collections.CollectionID(collectionID), movies.MovieID(movieID), req.Position)
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Collection or movie not found")
+		}
+		s.logger.Error("Failed to add movie to collection", "error", err, "collection_id", collectionID, "movie_id", movieID)
+		return errors.NewInternalError("Failed to add movie to collection")
+	}
+
+	return nil
+}
+
+func (s *collectionService) RemoveMovie(ctx context.Context, collectionID, movieID string) error {
+	err := s.collectionRepo.RemoveMovie(ctx, collections.CollectionID(collectionID), movies.MovieID(movieID))
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Movie not found in collection")
+		}
+		s.logger.Error("Failed to remove movie from collection", "error", err, "collection_id", collectionID, "movie_id", movieID)
+		return errors.NewInternalError("Failed to remove movie from collection")
+	}
+
+	return nil
+}
+
+func isNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
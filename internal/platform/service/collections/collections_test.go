@@ -0,0 +1,141 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+func setupTestService(opts ...Option) (Service, *mockCollectionRepository) {
+	repo := new(mockCollectionRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewCollectionService(
+		repo,
+		&mockIDGenerator{id: "collection-123"},
+		&mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		logger,
+		opts...,
+	)
+	return service, repo
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestCreateCollection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates a collection", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Save", ctx, mock.MatchedBy(func(c *collections.Collection) bool {
+			return c.ID == "collection-123" && c.Name == "Alien"
+		})).Return(&collections.Collection{ID: "collection-123", Name: "Alien"}, nil)
+
+		collection, err := service.CreateCollection(ctx, CreateCollectionRequest{Name: "Alien"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Alien", collection.Name)
+	})
+
+	t.Run("rejects an empty name", func(t *testing.T) {
+		service, repo := setupTestService()
+
+		_, err := service.CreateCollection(ctx, CreateCollectionRequest{})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetCollection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("embeds entries and aggregates stats", func(t *testing.T) {
+		service, repo := setupTestService(WithBayesianConfidenceK(func() float64 { return 10 }))
+		repo.On("GetByID", ctx, collections.CollectionID("collection-123")).Return(&collections.Collection{ID: "collection-123"}, nil)
+		repo.On("GetEntries", ctx, collections.CollectionID("collection-123"), 10.0).Return([]*collections.Entry{
+			{Movie: &movies.Movie{ID: "movie-1"}, Position: 1, BayesianAverage: 4.2, TotalRatings: 30},
+			{Movie: &movies.Movie{ID: "movie-2"}, Position: 2, BayesianAverage: 3.8, TotalRatings: 10},
+		}, nil)
+
+		details, err := service.GetCollection(ctx, "collection-123")
+
+		require.NoError(t, err)
+		assert.Len(t, details.Entries, 2)
+		assert.Equal(t, 2, details.Stats.MovieCount)
+		assert.Equal(t, int64(40), details.Stats.TotalRatings)
+		assert.InDelta(t, 4.0, details.Stats.AverageBayesianRating, 1e-9)
+	})
+
+	t.Run("follows a reloaded prior weight", func(t *testing.T) {
+		confidenceK := 10.0
+		service, repo := setupTestService(WithBayesianConfidenceK(func() float64 { return confidenceK }))
+		repo.On("GetByID", ctx, collections.CollectionID("collection-123")).Return(&collections.Collection{ID: "collection-123"}, nil)
+		repo.On("GetEntries", ctx, collections.CollectionID("collection-123"), 10.0).Return([]*collections.Entry{}, nil).Once()
+		repo.On("GetEntries", ctx, collections.CollectionID("collection-123"), 50.0).Return([]*collections.Entry{}, nil).Once()
+
+		_, err := service.GetCollection(ctx, "collection-123")
+		require.NoError(t, err)
+		confidenceK = 50
+		_, err = service.GetCollection(ctx, "collection-123")
+		require.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("returns not found for an unknown collection", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, collections.CollectionID("missing")).Return(nil, errors.New("collection with ID missing not found"))
+
+		_, err := service.GetCollection(ctx, "missing")
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestAddMovie(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("appends when no position is given", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("AddMovie", ctx, collections.CollectionID("collection-123"), movies.MovieID("movie-1"), (*int)(nil)).Return(nil)
+
+		err := service.AddMovie(ctx, "collection-123", AddMovieRequest{MovieID: "movie-1"})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a non-positive position", func(t *testing.T) {
+		service, _ := setupTestService()
+		position := 0
+
+		err := service.AddMovie(ctx, "collection-123", AddMovieRequest{MovieID: "movie-1", Position: &position})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+
+	t.Run("returns not found for an unknown movie", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("AddMovie", ctx, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("collection collection-123 or movie missing not found"))
+
+		err := service.AddMovie(ctx, "collection-123", AddMovieRequest{MovieID: "missing"})
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
@@ -0,0 +1,65 @@
+package collections
+
+import (
+	"context"
+	"thermondo/internal/domain/collections"
+	"thermondo/internal/domain/movies"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockCollectionRepository struct {
+	mock.Mock
+}
+
+func (m *mockCollectionRepository) Save(ctx context.Context, collection *collections.Collection) (*collections.Collection, error) {
+	args := m.Called(ctx, collection)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*collections.Collection), args.Error(1)
+}
+
+func (m *mockCollectionRepository) GetByID(ctx context.Context, id collections.CollectionID) (*collections.Collection, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*collections.Collection), args.Error(1)
+}
+
+func (m *mockCollectionRepository) AddMovie(ctx context.Context, id collections.CollectionID, movieID movies.MovieID, position *int) error {
+	args := m.Called(ctx, id, movieID, position)
+	return args.Error(0)
+}
+
+func (m *mockCollectionRepository) RemoveMovie(ctx context.Context, id collections.CollectionID, movieID movies.MovieID) error {
+	args := m.Called(ctx, id, movieID)
+	return args.Error(0)
+}
+
+func (m *mockCollectionRepository) GetEntries(ctx context.Context, id collections.CollectionID, confidenceK float64) ([]*collections.Entry, error) {
+	args := m.Called(ctx, id, confidenceK)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*collections.Entry), args.Error(1)
+}
+
+type mockIDGenerator struct {
+	id string
+}
+
+func (m *mockIDGenerator) Generate() string {
+	return m.id
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
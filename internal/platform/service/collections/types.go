@@ -0,0 +1,22 @@
+package collections
+
+import "thermondo/internal/domain/collections"
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type AddMovieRequest struct {
+	MovieID string `json:"movie_id"`
+	// Position is 1-based; the movie is appended when omitted
+	Position *int `json:"position,omitempty"`
+}
+
+// CollectionDetails is a collection with its ordered entries and their
+// aggregated rating stats
+type CollectionDetails struct {
+	Collection *collections.Collection
+	Entries    []*collections.Entry
+	Stats      collections.Stats
+}
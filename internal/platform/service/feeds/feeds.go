@@ -0,0 +1,260 @@
+package feeds
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/url"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/markdown"
+	"time"
+)
+
+// EntryLimit is how many of the newest entries a feed carries
+const EntryLimit = 20
+
+const (
+	newMoviesFeed    = "new_movies"
+	movieReviewsFeed = "movie_reviews"
+)
+
+// Service builds Atom feeds for feed readers: the movies newest to the
+// catalog and the reviews of a movie. Feeds are cached, as readers poll them
+// on a schedule whether anything changed or not.
+type Service interface {
+	// NewMovies returns the movies most recently added to the catalog
+	NewMovies(ctx context.Context) (*Feed, error)
+	// MovieReviews returns the newest reviews of a movie. Reviews flagged as
+	// spoilers or adult language are left out, as readers show them unasked.
+	MovieReviews(ctx context.Context, movieID string) (*Feed, error)
+}
+
+// Feed is an Atom feed. Updated is when its newest entry changed, or the
+// movie of a review feed without reviews; it is zero for an empty catalog.
+type Feed struct {
+	ID      string
+	Title   string
+	Link    string
+	Author  string
+	Updated time.Time
+	Entries []*Entry
+}
+
+// Entry is an item of a feed; Content is HTML
+type Entry struct {
+	ID        string
+	Title     string
+	Link      string
+	Content   string
+	Published time.Time
+	Updated   time.Time
+}
+
+// MovieFinder loads the movies of the catalog
+type MovieFinder interface {
+	GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error)
+	GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error)
+}
+
+// RatingFinder loads the ratings of a movie
+type RatingFinder interface {
+	GetByMovie(ctx context.Context, movieID movies.MovieID, options ...rating.SearchOption) ([]*rating.Rating, error)
+}
+
+type feedService struct {
+	movies  MovieFinder
+	ratings RatingFinder
+	cache   cache.Cache
+	siteURL string
+	logger  *slog.Logger
+}
+
+// Option configures optional settings of the feed service
+type Option func(*feedService)
+
+// WithCache caches the feeds
+func WithCache(c cache.Cache) Option {
+	return func(s *feedService) {
+		s.cache = c
+	}
+}
+
+// NewFeedService links the entries to the movie pages of the web frontend
+// at siteURL, which are at {siteURL}/movies/{id}
+func NewFeedService(movieFinder MovieFinder, ratingFinder RatingFinder, siteURL string, logger *slog.Logger, opts ...Option) Service {
+	s := &feedService{
+		movies:  movieFinder,
+		ratings: ratingFinder,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *feedService) NewMovies(ctx context.Context) (*Feed, error) {
+	cacheKey := cache.FeedKeyFunc(newMoviesFeed, "")
+	if feed, ok := s.cachedFeed(ctx, cacheKey); ok {
+		return feed, nil
+	}
+
+	moviesList, err := s.movies.GetAll(ctx,
+		movies.WithLimit(EntryLimit),
+		movies.WithOffset(0),
+		movies.WithSort("created_at", "desc"),
+	)
+	if err != nil {
+		s.logger.Error("Failed to list movies for the feed", "error", err)
+		return nil, errors.NewInternalError("Failed to build feed")
+	}
+
+	feed := &Feed{
+		ID:      s.siteURL + "/feeds/new-movies.atom",
+		Title:   "New movies",
+		Link:    s.siteURL + "/movies",
+		Author:  s.author(),
+		Entries: make([]*Entry, len(moviesList)),
+	}
+	for i, movie := range moviesList {
+		link := s.movieURL(movie.ID)
+		feed.Entries[i] = &Entry{
+			ID:        link,
+			Title:     movieTitle(movie),
+			Link:      link,
+			Content:   movieContent(movie),
+			Published: movie.CreatedAt,
+			Updated:   movie.UpdatedAt,
+		}
+		feed.Updated = latest(feed.Updated, movie.UpdatedAt)
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, cacheKey, feed, cache.FeedTTL); err != nil {
+			s.logger.Warn("Failed to cache feed", "error", err, "key", cacheKey)
+		}
+	}
+	return feed, nil
+}
+
+func (s *feedService) MovieReviews(ctx context.Context, movieID string) (*Feed, error) {
+	cacheKey := cache.FeedKeyFunc(movieReviewsFeed, movieID)
+	if feed, ok := s.cachedFeed(ctx, cacheKey); ok {
+		return feed, nil
+	}
+
+	movie, err := s.movies.GetByID(ctx, movies.MovieID(movieID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		s.logger.Error("Failed to get movie for the feed", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get movie")
+	}
+
+	reviews, err := s.ratings.GetByMovie(ctx, movie.ID,
+		rating.WithReviewsOnly(),
+		rating.WithSpoilers(rating.SpoilersHide),
+		rating.WithoutAdultLanguage(),
+		rating.WithSort("created_at", "desc"),
+		rating.WithLimit(EntryLimit),
+		rating.WithOffset(0),
+	)
+	if err != nil {
+		s.logger.Error("Failed to get reviews for the feed", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to build feed")
+	}
+
+	link := s.movieURL(movie.ID)
+	feed := &Feed{
+		ID:      s.siteURL + "/feeds/movie/" + url.PathEscape(string(movie.ID)) + "/reviews.atom",
+		Title:   "Reviews of " + movieTitle(movie),
+		Link:    link,
+		Author:  s.author(),
+		Updated: movie.UpdatedAt,
+		Entries: make([]*Entry, len(reviews)),
+	}
+	// Reviewers are not named: a feed is public and outlives their account
+	for i, review := range reviews {
+		reviewLink := link + "#review-" + url.PathEscape(string(review.ID))
+		feed.Entries[i] = &Entry{
+			ID:        reviewLink,
+			Title:     fmt.Sprintf("%d/5 for %s", review.Score, movie.Title),
+			Link:      reviewLink,
+			Content:   markdown.Render(review.Review),
+			Published: review.CreatedAt,
+			Updated:   review.UpdatedAt,
+		}
+		feed.Updated = latest(feed.Updated, review.UpdatedAt)
+	}
+
+	// Tagged with the movie, so a new or edited review clears it
+	if s.cache != nil {
+		if err := s.cache.SetWithTags(ctx, cacheKey, feed, cache.FeedTTL, cache.MovieTag(movieID)); err != nil {
+			s.logger.Warn("Failed to cache feed", "error", err, "key", cacheKey)
+		}
+	}
+	return feed, nil
+}
+
+func (s *feedService) movieURL(id movies.MovieID) string {
+	return s.siteURL + "/movies/" + url.PathEscape(string(id))
+}
+
+// author names the site, as Atom requires an author and entries have none
+func (s *feedService) author() string {
+	if u, err := url.Parse(s.siteURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return s.siteURL
+}
+
+func (s *feedService) cachedFeed(ctx context.Context, key string) (*Feed, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	var cached Feed
+	if err := s.cache.Get(ctx, key, &cached); err != nil {
+		return nil, false
+	}
+	return &cached, true
+}
+
+func movieTitle(movie *movies.Movie) string {
+	if movie.ReleaseYear > 0 {
+		return fmt.Sprintf("%s (%d)", movie.Title, movie.ReleaseYear)
+	}
+	return movie.Title
+}
+
+// movieContent describes a movie in HTML: its description and who made it
+func movieContent(movie *movies.Movie) string {
+	var details []string
+	for _, detail := range []string{movie.Genre, movie.Director} {
+		if detail != "" {
+			details = append(details, html.EscapeString(detail))
+		}
+	}
+
+	var b strings.Builder
+	if movie.Description != "" {
+		b.WriteString("<p>" + html.EscapeString(movie.Description) + "</p>")
+	}
+	if len(details) > 0 {
+		b.WriteString("<p>" + strings.Join(details, " · ") + "</p>")
+	}
+	return b.String()
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,152 @@
+package feeds
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSiteURL = "https://movies.example.com"
+
+func setupTestService(opts ...Option) (Service, *mockMovieFinder, *mockRatingFinder) {
+	movieFinder := new(mockMovieFinder)
+	ratingFinder := new(mockRatingFinder)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewFeedService(movieFinder, ratingFinder, testSiteURL+"/", logger, opts...), movieFinder, ratingFinder
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, status, appErr.StatusCode)
+}
+
+func TestNewMovies(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should list the newest movies", func(t *testing.T) {
+		service, movieFinder, _ := setupTestService()
+		movieFinder.On("GetAll", ctx, mock.MatchedBy(func(opts movies.SearchOptions) bool {
+			return opts.Limit == EntryLimit && opts.SortBy == "created_at" && opts.Order == "desc"
+		})).Return([]*movies.Movie{
+			{ID: "movie-2", Title: "Dune", ReleaseYear: 2021, Description: "Spice & sand", Genre: "Sci-Fi", Director: "Denis Villeneuve", CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+			{ID: "movie 1", Title: "Heat", CreatedAt: created.Add(-time.Hour), UpdatedAt: created.Add(2 * time.Hour)},
+		}, nil)
+
+		feed, err := service.NewMovies(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, testSiteURL+"/feeds/new-movies.atom", feed.ID)
+		assert.Equal(t, "movies.example.com", feed.Author)
+		assert.Equal(t, created.Add(2*time.Hour), feed.Updated, "the latest change of any entry")
+		require.Len(t, feed.Entries, 2)
+		assert.Equal(t, "Dune (2021)", feed.Entries[0].Title)
+		assert.Equal(t, testSiteURL+"/movies/movie-2", feed.Entries[0].Link)
+		assert.Equal(t, "<p>Spice &amp; sand</p><p>Sci-Fi · Denis Villeneuve</p>", feed.Entries[0].Content)
+		assert.Equal(t, testSiteURL+"/movies/movie%201", feed.Entries[1].Link)
+		assert.Empty(t, feed.Entries[1].Content)
+	})
+
+	t.Run("should serve a cached feed", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "feed:new_movies:", mock.Anything).Return(nil)
+		service, movieFinder, _ := setupTestService(WithCache(mockCache))
+
+		_, err := service.NewMovies(ctx)
+
+		require.NoError(t, err)
+		movieFinder.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should fail when the movies cannot be listed", func(t *testing.T) {
+		service, movieFinder, _ := setupTestService()
+		movieFinder.On("GetAll", ctx, mock.Anything).Return(nil, errors.New("connection refused"))
+
+		_, err := service.NewMovies(ctx)
+
+		assertStatus(t, err, http.StatusInternalServerError)
+	})
+}
+
+func TestMovieReviews(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	movie := &movies.Movie{ID: "movie-1", Title: "Heat", ReleaseYear: 1995, UpdatedAt: updated}
+
+	t.Run("should list the newest reviews that are safe to show", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "feed:movie_reviews:movie-1", mock.Anything).Return(errors.New("cache miss"))
+		mockCache.On("SetWithTags", ctx, "feed:movie_reviews:movie-1", mock.Anything, cache.FeedTTL, []string{"movie:movie-1"}).Return(nil)
+		service, movieFinder, ratingFinder := setupTestService(WithCache(mockCache))
+		movieFinder.On("GetByID", ctx, movies.MovieID("movie-1")).Return(movie, nil)
+		ratingFinder.On("GetByMovie", ctx, movies.MovieID("movie-1"), rating.SearchOptions{
+			Limit:             EntryLimit,
+			SortBy:            "created_at",
+			Order:             "desc",
+			Spoilers:          rating.SpoilersHide,
+			HideAdultLanguage: true,
+			ReviewsOnly:       true,
+		}).Return([]*rating.Rating{
+			{ID: "rating-1", UserID: "user-1", Score: 5, Review: "**Tense**", CreatedAt: updated, UpdatedAt: updated.Add(time.Hour)},
+		}, nil)
+
+		feed, err := service.MovieReviews(ctx, "movie-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, testSiteURL+"/feeds/movie/movie-1/reviews.atom", feed.ID)
+		assert.Equal(t, "Reviews of Heat (1995)", feed.Title)
+		assert.Equal(t, updated.Add(time.Hour), feed.Updated)
+		require.Len(t, feed.Entries, 1)
+		assert.Equal(t, "5/5 for Heat", feed.Entries[0].Title)
+		assert.Equal(t, testSiteURL+"/movies/movie-1#review-rating-1", feed.Entries[0].Link)
+		assert.Equal(t, "<p><strong>Tense</strong></p>", feed.Entries[0].Content)
+		assert.NotContains(t, feed.Entries[0].Content, "user-1")
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should date a feed without reviews by its movie", func(t *testing.T) {
+		service, movieFinder, ratingFinder := setupTestService()
+		movieFinder.On("GetByID", ctx, movies.MovieID("movie-1")).Return(movie, nil)
+		ratingFinder.On("GetByMovie", ctx, movies.MovieID("movie-1"), mock.Anything).Return([]*rating.Rating{}, nil)
+
+		feed, err := service.MovieReviews(ctx, "movie-1")
+
+		require.NoError(t, err)
+		assert.Empty(t, feed.Entries)
+		assert.Equal(t, updated, feed.Updated)
+	})
+
+	t.Run("should return not found for an unknown movie", func(t *testing.T) {
+		service, movieFinder, _ := setupTestService()
+		movieFinder.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("movie with ID missing not found"))
+
+		_, err := service.MovieReviews(ctx, "missing")
+
+		assertStatus(t, err, http.StatusNotFound)
+	})
+
+	t.Run("should fail when the reviews cannot be loaded", func(t *testing.T) {
+		service, movieFinder, ratingFinder := setupTestService()
+		movieFinder.On("GetByID", ctx, movies.MovieID("movie-1")).Return(movie, nil)
+		ratingFinder.On("GetByMovie", ctx, movies.MovieID("movie-1"), mock.Anything).Return(nil, errors.New("connection refused"))
+
+		_, err := service.MovieReviews(ctx, "movie-1")
+
+		assertStatus(t, err, http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,50 @@
+package feeds
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockMovieFinder struct {
+	mock.Mock
+}
+
+func (m *mockMovieFinder) GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockMovieFinder) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
+type mockRatingFinder struct {
+	mock.Mock
+}
+
+func (m *mockRatingFinder) GetByMovie(ctx context.Context, movieID movies.MovieID, options ...rating.SearchOption) ([]*rating.Rating, error) {
+	var opts rating.SearchOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	args := m.Called(ctx, movieID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.Rating), args.Error(1)
+}
@@ -0,0 +1,520 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/metrics"
+	"time"
+)
+
+const (
+	DefaultWorkers           = 2
+	DefaultPollInterval      = 5 * time.Second
+	DefaultHeartbeatInterval = 5 * time.Second
+	DefaultStaleAfter        = 2 * time.Minute
+
+	// finishTimeout bounds the writes that record a job's outcome, which
+	// still happen while the worker shuts down
+	finishTimeout = 10 * time.Second
+)
+
+var (
+	jobsFinished = metrics.NewCounterVec("jobs_finished_total", "Jobs that finished, by type and final status", "type", "status")
+	jobsRetried  = metrics.NewCounterVec("jobs_retried_total", "Failed job attempts queued for a retry, by type", "type")
+	jobsRunning  = metrics.NewGauge("jobs_running", "Jobs currently running in this process")
+)
+
+// Service queues background jobs and runs them on a pool of workers. Any
+// number of processes may run workers against the same database; each job
+// is claimed by exactly one of them.
+type Service interface {
+	// Register sets the handler and retry policy of a job type. Only
+	// registered types are enqueued and claimed, so register before Run.
+	Register(jobType string, handler jobs.Handler, policy jobs.RetryPolicy)
+	// Enqueue stores a job whose payload is marshalled to JSON
+	Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error)
+	GetJob(ctx context.Context, id string) (*jobs.Job, error)
+	// CancelJob cancels a queued job right away and stops a running one
+	// within a heartbeat
+	CancelJob(ctx context.Context, id string) (*jobs.Job, error)
+	// Schedule queues a job of a registered type once every interval,
+	// aligned to multiples of interval since the Unix epoch. However many
+	// processes schedule it, each interval queues one job.
+	Schedule(jobType string, interval time.Duration, payload any)
+	// Run works through due jobs and queues scheduled ones until ctx is
+	// done
+	Run(ctx context.Context)
+}
+
+type registration struct {
+	handler jobs.Handler
+	policy  jobs.RetryPolicy
+}
+
+type schedule struct {
+	jobType  string
+	interval time.Duration
+	payload  any
+}
+
+type jobService struct {
+	repo              jobs.Repository
+	idGenerator       shared.IDGenerator
+	timeProvider      shared.TimeProvider
+	logger            *slog.Logger
+	workers           int
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration
+	staleAfter        time.Duration
+
+	mu        sync.RWMutex
+	handlers  map[string]registration
+	schedules []schedule
+	// wake lets a worker pick up a job enqueued by this process without
+	// waiting for the next poll
+	wake    chan struct{}
+	running atomic.Int64
+}
+
+// Option configures optional settings of the job service
+type Option func(*jobService)
+
+// WithWorkers sets how many jobs this process runs at once
+func WithWorkers(n int) Option {
+	return func(s *jobService) {
+		s.workers = n
+	}
+}
+
+// WithPollInterval sets how often idle workers look for due jobs
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *jobService) {
+		s.pollInterval = interval
+	}
+}
+
+// WithHeartbeatInterval sets how often running jobs save their progress and
+// check for cancellation
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(s *jobService) {
+		s.heartbeatInterval = interval
+	}
+}
+
+// WithStaleAfter sets how long a running job may go without a heartbeat
+// before it is taken to have lost its worker
+func WithStaleAfter(d time.Duration) Option {
+	return func(s *jobService) {
+		s.staleAfter = d
+	}
+}
+
+func NewJobService(
+	repo jobs.Repository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &jobService{
+		repo:              repo,
+		idGenerator:       idGenerator,
+		timeProvider:      timeProvider,
+		logger:            logger,
+		workers:           DefaultWorkers,
+		pollInterval:      DefaultPollInterval,
+		heartbeatInterval: DefaultHeartbeatInterval,
+		staleAfter:        DefaultStaleAfter,
+		handlers:          make(map[string]registration),
+		wake:              make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *jobService) Register(jobType string, handler jobs.Handler, policy jobs.RetryPolicy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = registration{handler: handler, policy: policy}
+}
+
+func (s *jobService) registration(jobType string) (registration, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	reg, ok := s.handlers[jobType]
+	return reg, ok
+}
+
+func (s *jobService) types() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	types := make([]string, 0, len(s.handlers))
+	for jobType := range s.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (s *jobService) Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error) {
+	job, err := s.newJob(jobs.JobID(s.idGenerator.Generate()), jobType, payload, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		s.logger.Error("Failed to enqueue job", "error", err, "type", jobType)
+		return nil, errors.NewInternalError("Failed to queue job")
+	}
+
+	s.queued(job)
+	return job, nil
+}
+
+func (s *jobService) newJob(id jobs.JobID, jobType string, payload any, createdBy string) (*jobs.Job, error) {
+	reg, ok := s.registration(jobType)
+	if !ok {
+		return nil, fmt.Errorf("unknown job type %q", jobType)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", jobType, err)
+	}
+
+	now := s.timeProvider.Now()
+	return &jobs.Job{
+		ID:          id,
+		Type:        jobType,
+		Status:      jobs.StatusQueued,
+		Payload:     data,
+		MaxAttempts: reg.policy.MaxAttempts,
+		CreatedBy:   createdBy,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// queued wakes an idle worker for a job just created
+func (s *jobService) queued(job *jobs.Job) {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	s.logger.Info("Job queued", "job_id", job.ID, "type", job.Type, "created_by", job.CreatedBy)
+}
+
+func (s *jobService) Schedule(jobType string, interval time.Duration, payload any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules = append(s.schedules, schedule{jobType: jobType, interval: interval, payload: payload})
+}
+
+func (s *jobService) GetJob(ctx context.Context, id string) (*jobs.Job, error) {
+	job, err := s.repo.GetByID(ctx, jobs.JobID(id))
+	if err != nil {
+		if stdErrors.Is(err, jobs.ErrJobNotFound) {
+			return nil, errors.NewNotFoundError("Job not found")
+		}
+		s.logger.Error("Failed to get job", "error", err, "job_id", id)
+		return nil, errors.NewInternalError("Failed to get job")
+	}
+	return job, nil
+}
+
+func (s *jobService) CancelJob(ctx context.Context, id string) (*jobs.Job, error) {
+	job, err := s.repo.Cancel(ctx, jobs.JobID(id), s.timeProvider.Now())
+	switch {
+	case stdErrors.Is(err, jobs.ErrJobNotFound):
+		return nil, errors.NewNotFoundError("Job not found")
+	case stdErrors.Is(err, jobs.ErrJobFinished):
+		return nil, errors.NewConflictError("Job has already finished")
+	case err != nil:
+		s.logger.Error("Failed to cancel job", "error", err, "job_id", id)
+		return nil, errors.NewInternalError("Failed to cancel job")
+	}
+	if job.Status == jobs.StatusCancelled {
+		jobsFinished.With(job.Type, string(jobs.StatusCancelled)).Inc()
+	}
+	return job, nil
+}
+
+func (s *jobService) Run(ctx context.Context) {
+	s.logger.Info("Starting job workers", "workers", s.workers, "types", s.types())
+
+	s.mu.RLock()
+	schedules := append([]schedule(nil), s.schedules...)
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	wg.Add(s.workers + 1 + len(schedules))
+	go func() {
+		defer wg.Done()
+		s.recoverStale(ctx)
+	}()
+	for _, sched := range schedules {
+		go func(sched schedule) {
+			defer wg.Done()
+			s.runSchedule(ctx, sched)
+		}(sched)
+	}
+	for i := 0; i < s.workers; i++ {
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+	s.logger.Info("Stopped job workers")
+}
+
+func (s *jobService) work(ctx context.Context) {
+	types := s.types()
+	for ctx.Err() == nil {
+		job, err := s.repo.Claim(ctx, types, s.timeProvider.Now())
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to claim job", "error", err)
+		}
+		if job != nil {
+			s.execute(ctx, job)
+			continue
+		}
+
+		timer := time.NewTimer(s.pollInterval)
+		select {
+		case <-ctx.Done():
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// recoverStale requeues jobs whose worker stopped heartbeating, e.g. because
+// its process crashed
+func (s *jobService) recoverStale(ctx context.Context) {
+	ticker := time.NewTicker(s.staleAfter / 2)
+	defer ticker.Stop()
+
+	for {
+		now := s.timeProvider.Now()
+		if n, err := s.repo.RequeueStale(ctx, now.Add(-s.staleAfter), now); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to recover stale jobs", "error", err)
+		} else if n > 0 {
+			s.logger.Warn("Recovered jobs whose worker stopped", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSchedule queues the job of the current interval, then that of each
+// following one as it starts. A failed attempt is repeated after a poll
+// interval.
+func (s *jobService) runSchedule(ctx context.Context, sched schedule) {
+	for {
+		slot := s.timeProvider.Now().Truncate(sched.interval)
+		next := slot.Add(sched.interval)
+		if !s.enqueueScheduled(ctx, sched, slot) {
+			next = s.timeProvider.Now().Add(s.pollInterval)
+		}
+
+		timer := time.NewTimer(next.Sub(s.timeProvider.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// enqueueScheduled queues the job of the interval starting at slot. Its ID
+// is derived from the type and slot, so processes sharing the schedule
+// queue it only once, as does a restart within the interval.
+func (s *jobService) enqueueScheduled(ctx context.Context, sched schedule, slot time.Time) bool {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s@%d", sched.jobType, slot.Unix())))
+	job, err := s.newJob(jobs.JobID(hex.EncodeToString(sum[:16])), sched.jobType, sched.payload, "")
+	if err != nil {
+		s.logger.Error("Failed to schedule job", "error", err, "type", sched.jobType)
+		return false
+	}
+	switch err := s.repo.Create(ctx, job); {
+	case stdErrors.Is(err, jobs.ErrJobExists):
+		s.logger.Debug("Scheduled job already queued", "job_id", job.ID, "type", job.Type)
+	case err != nil:
+		if ctx.Err() == nil {
+			s.logger.Error("Failed to schedule job", "error", err, "type", sched.jobType)
+		}
+		return false
+	default:
+		s.queued(job)
+	}
+	return true
+}
+
+// runState is what the handler has reported, shared with the heartbeat
+type runState struct {
+	mu       sync.Mutex
+	progress float64
+	result   any
+}
+
+func (r *runState) report(progress float64, result any) {
+	switch {
+	case progress < 0:
+		progress = 0
+	case progress > 100:
+		progress = 100
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress, r.result = progress, result
+}
+
+func (r *runState) snapshot() (float64, json.RawMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.result == nil {
+		return r.progress, nil, nil
+	}
+	data, err := json.Marshal(r.result)
+	return r.progress, data, err
+}
+
+func (s *jobService) execute(ctx context.Context, job *jobs.Job) {
+	reg, ok := s.registration(job.Type)
+	if !ok {
+		// Claim only returns registered types
+		return
+	}
+
+	jobsRunning.Set(float64(s.running.Add(1)))
+	defer func() { jobsRunning.Set(float64(s.running.Add(-1))) }()
+
+	logger := s.logger.With("job_id", job.ID, "type", job.Type, "attempt", job.Attempts)
+	logger.Info("Job started")
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	state := &runState{progress: job.Progress}
+	var cancelled, lost atomic.Bool
+
+	heartbeatDone := make(chan struct{})
+	stopHeartbeat := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(s.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			progress, result, err := state.snapshot()
+			if err != nil {
+				logger.Error("Failed to encode job result", "error", err)
+				result = nil
+			}
+			cancelRequested, err := s.repo.Heartbeat(runCtx, job.ID, progress, result, s.timeProvider.Now())
+			switch {
+			case stdErrors.Is(err, jobs.ErrJobNotFound):
+				// The job was recovered as stale and may run elsewhere
+				lost.Store(true)
+				cancel()
+			case err != nil:
+				logger.Error("Failed to record job progress", "error", err)
+			case cancelRequested:
+				cancelled.Store(true)
+				cancel()
+			}
+		}
+	}()
+
+	err := s.call(runCtx, reg.handler, job, state.report)
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	if lost.Load() {
+		logger.Warn("Job stopped after losing its claim")
+		return
+	}
+
+	finishCtx, cancelFinish := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancelFinish()
+	_, result, encodeErr := state.snapshot()
+	if encodeErr != nil {
+		logger.Error("Failed to encode job result", "error", encodeErr)
+	}
+	now := s.timeProvider.Now()
+
+	var status jobs.Status
+	var errMsg string
+	switch {
+	case cancelled.Load():
+		status = jobs.StatusCancelled
+	case err == nil:
+		status = jobs.StatusSucceeded
+	case ctx.Err() != nil:
+		// Shutting down; another worker picks the job up again
+		if retryErr := s.repo.Retry(finishCtx, job.ID, "interrupted by shutdown", now, now); retryErr != nil {
+			logger.Error("Failed to requeue interrupted job", "error", retryErr)
+		}
+		logger.Info("Job interrupted by shutdown")
+		return
+	case jobs.IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		status, errMsg = jobs.StatusFailed, err.Error()
+	default:
+		delay := reg.policy.Delay(job.Attempts)
+		if retryErr := s.repo.Retry(finishCtx, job.ID, err.Error(), now.Add(delay), now); retryErr != nil {
+			logger.Error("Failed to queue job retry", "error", retryErr)
+			return
+		}
+		jobsRetried.With(job.Type).Inc()
+		logger.Warn("Job attempt failed, retrying", "error", err, "retry_in", delay)
+		return
+	}
+
+	if finishErr := s.repo.Finish(finishCtx, job.ID, status, result, errMsg, now); finishErr != nil {
+		logger.Error("Failed to record job outcome", "error", finishErr, "status", status)
+		return
+	}
+	jobsFinished.With(job.Type, string(status)).Inc()
+	if status == jobs.StatusFailed {
+		logger.Error("Job failed", "error", err)
+	} else {
+		logger.Info("Job finished", "status", status)
+	}
+}
+
+// call runs the handler, turning a panic into a permanent failure
+func (s *jobService) call(ctx context.Context, handler jobs.Handler, job *jobs.Job, report jobs.ReportFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = jobs.Permanent(fmt.Errorf("job panicked: %v", r))
+		}
+	}()
+	return handler(ctx, job, report)
+}
@@ -0,0 +1,321 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/shared"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+// fakeJobRepository keeps jobs in memory with the semantics of the Postgres
+// repository
+type fakeJobRepository struct {
+	mu   sync.Mutex
+	jobs map[jobs.JobID]*jobs.Job
+}
+
+func newFakeJobRepository() *fakeJobRepository {
+	return &fakeJobRepository{jobs: make(map[jobs.JobID]*jobs.Job)}
+}
+
+func (r *fakeJobRepository) Create(ctx context.Context, job *jobs.Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[job.ID]; ok {
+		return jobs.ErrJobExists
+	}
+	copied := *job
+	r.jobs[job.ID] = &copied
+	return nil
+}
+
+func (r *fakeJobRepository) GetByID(ctx context.Context, id jobs.JobID) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, jobs.ErrJobNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeJobRepository) Claim(ctx context.Context, types []string, now time.Time) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*jobs.Job
+	for _, job := range r.jobs {
+		if job.Status == jobs.StatusQueued && !job.RunAt.After(now) && contains(types, job.Type) {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	job := due[0]
+	job.Status = jobs.StatusRunning
+	job.Attempts++
+	job.UpdatedAt = now
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeJobRepository) Heartbeat(ctx context.Context, id jobs.JobID, progress float64, result json.RawMessage, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != jobs.StatusRunning {
+		return false, jobs.ErrJobNotFound
+	}
+	job.Progress = progress
+	if result != nil {
+		job.Result = result
+	}
+	job.UpdatedAt = now
+	return job.CancelRequested, nil
+}
+
+func (r *fakeJobRepository) Finish(ctx context.Context, id jobs.JobID, status jobs.Status, result json.RawMessage, errMsg string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != jobs.StatusRunning {
+		return jobs.ErrJobNotFound
+	}
+	job.Status, job.Error, job.FinishedAt = status, errMsg, &now
+	if result != nil {
+		job.Result = result
+	}
+	if status == jobs.StatusSucceeded {
+		job.Progress = 100
+	}
+	return nil
+}
+
+func (r *fakeJobRepository) Retry(ctx context.Context, id jobs.JobID, errMsg string, runAt, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != jobs.StatusRunning {
+		return jobs.ErrJobNotFound
+	}
+	job.Status, job.Error, job.RunAt = jobs.StatusQueued, errMsg, runAt
+	return nil
+}
+
+func (r *fakeJobRepository) Cancel(ctx context.Context, id jobs.JobID, now time.Time) (*jobs.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, jobs.ErrJobNotFound
+	}
+	if job.Status.Finished() {
+		return nil, jobs.ErrJobFinished
+	}
+	if job.Status == jobs.StatusQueued {
+		job.Status, job.FinishedAt = jobs.StatusCancelled, &now
+	}
+	job.CancelRequested = true
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeJobRepository) RequeueStale(ctx context.Context, staleBefore, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sequentialIDs hands out job-1, job-2, ...
+type sequentialIDs struct {
+	n atomic.Int64
+}
+
+func (g *sequentialIDs) Generate() string {
+	return "job-" + strconv.FormatInt(g.n.Add(1), 10)
+}
+
+func startJobService(t *testing.T, repo jobs.Repository, register func(Service)) Service {
+	t.Helper()
+	service := NewJobService(repo, &sequentialIDs{}, shared.NewTimeProvider(), slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithWorkers(2),
+		WithPollInterval(5*time.Millisecond),
+		WithHeartbeatInterval(5*time.Millisecond),
+	)
+	register(service)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return service
+}
+
+func waitForJob(t *testing.T, service Service, id jobs.JobID) *jobs.Job {
+	t.Helper()
+	var job *jobs.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = service.GetJob(context.Background(), string(id))
+		require.NoError(t, err)
+		return job.Status.Finished()
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestJobService_RunsJobs(t *testing.T) {
+	type payload struct {
+		Count int `json:"count"`
+	}
+	service := startJobService(t, newFakeJobRepository(), func(s Service) {
+		s.Register("count", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			var p payload
+			if err := json.Unmarshal(job.Payload, &p); err != nil {
+				return jobs.Permanent(err)
+			}
+			for i := 1; i <= p.Count; i++ {
+				report(float64(i)/float64(p.Count)*100, map[string]int{"counted": i})
+			}
+			return nil
+		}, jobs.DefaultRetryPolicy())
+	})
+
+	queued, err := service.Enqueue(context.Background(), "count", payload{Count: 3}, "admin-1")
+	require.NoError(t, err)
+	assert.Equal(t, jobs.StatusQueued, queued.Status)
+	assert.Equal(t, 3, queued.MaxAttempts)
+
+	job := waitForJob(t, service, queued.ID)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status)
+	assert.Equal(t, 100.0, job.Progress)
+	assert.JSONEq(t, `{"counted":3}`, string(job.Result))
+	assert.Equal(t, 1, job.Attempts)
+}
+
+func TestJobService_RetryPolicy(t *testing.T) {
+	var calls atomic.Int64
+	service := startJobService(t, newFakeJobRepository(), func(s Service) {
+		s.Register("flaky", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			if calls.Add(1) == 1 {
+				return errors.New("connection reset")
+			}
+			return nil
+		}, jobs.RetryPolicy{MaxAttempts: 3})
+		s.Register("broken", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			return jobs.Permanent(errors.New("payload is missing its key"))
+		}, jobs.RetryPolicy{MaxAttempts: 3})
+		s.Register("panics", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			panic("nil map")
+		}, jobs.RetryPolicy{MaxAttempts: 3})
+	})
+
+	flaky, err := service.Enqueue(context.Background(), "flaky", nil, "")
+	require.NoError(t, err)
+	job := waitForJob(t, service, flaky.ID)
+	assert.Equal(t, jobs.StatusSucceeded, job.Status)
+	assert.Equal(t, 2, job.Attempts)
+
+	broken, err := service.Enqueue(context.Background(), "broken", nil, "")
+	require.NoError(t, err)
+	job = waitForJob(t, service, broken.ID)
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Equal(t, 1, job.Attempts, "permanent failures are not retried")
+	assert.Equal(t, "payload is missing its key", job.Error)
+
+	panics, err := service.Enqueue(context.Background(), "panics", nil, "")
+	require.NoError(t, err)
+	job = waitForJob(t, service, panics.ID)
+	assert.Equal(t, jobs.StatusFailed, job.Status)
+	assert.Contains(t, job.Error, "nil map")
+
+	_, err = service.Enqueue(context.Background(), "unknown", nil, "")
+	assert.Error(t, err)
+}
+
+func TestJobService_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	service := startJobService(t, newFakeJobRepository(), func(s Service) {
+		s.Register("wait", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}, jobs.RetryPolicy{MaxAttempts: 3})
+	})
+
+	queued, err := service.Enqueue(context.Background(), "wait", nil, "")
+	require.NoError(t, err)
+	<-started
+
+	cancelling, err := service.CancelJob(context.Background(), string(queued.ID))
+	require.NoError(t, err)
+	assert.True(t, cancelling.CancelRequested)
+
+	job := waitForJob(t, service, queued.ID)
+	assert.Equal(t, jobs.StatusCancelled, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+
+	var appErr *appErrors.AppError
+	_, err = service.CancelJob(context.Background(), string(queued.ID))
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+
+	_, err = service.GetJob(context.Background(), "missing")
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestJobService_Schedule(t *testing.T) {
+	repo := newFakeJobRepository()
+	var runs atomic.Int64
+	register := func(s Service) {
+		s.Register("purge", func(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+			runs.Add(1)
+			return nil
+		}, jobs.DefaultRetryPolicy())
+		s.Schedule("purge", time.Hour, map[string]bool{"dry_run": true})
+	}
+	// Two processes share the schedule
+	startJobService(t, repo, register)
+	startJobService(t, repo, register)
+
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, 2*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(1), runs.Load(), "one job per interval")
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Len(t, repo.jobs, 1)
+	for _, job := range repo.jobs {
+		assert.JSONEq(t, `{"dry_run":true}`, string(job.Payload))
+	}
+}
@@ -0,0 +1,232 @@
+package lists
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+)
+
+// Service manages user-curated lists. requesterID identifies the caller;
+// only the owner may change a list, and private lists are hidden from
+// everyone else unless they are opened through the share slug.
+type Service interface {
+	CreateList(ctx context.Context, req CreateListRequest) (*lists.List, error)
+	// GetList looks the list up by ID first and then by share slug
+	GetList(ctx context.Context, idOrSlug, requesterID string) (*ListDetails, error)
+	GetUserLists(ctx context.Context, userID, requesterID string) ([]*lists.List, error)
+	UpdateList(ctx context.Context, id, requesterID string, req UpdateListRequest) (*lists.List, error)
+	DeleteList(ctx context.Context, id, requesterID string) error
+	AddMovie(ctx context.Context, id, requesterID string, req AddMovieRequest) error
+	RemoveMovie(ctx context.Context, id, requesterID, movieID string) error
+}
+
+type listService struct {
+	listRepo     lists.Repository
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+}
+
+func NewListService(
+	listRepo lists.Repository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+) Service {
+	return &listService{
+		listRepo:     listRepo,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+	}
+}
+
+func (s *listService) CreateList(ctx context.Context, req CreateListRequest) (*lists.List, error) {
+	list, err := lists.NewList(req.UserID, req.Name, req.Description, lists.Visibility(req.Visibility), s.idGenerator, s.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	saved, err := s.listRepo.Save(ctx, list)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return nil, errors.NewConflictError("List with this ID already exists")
+		}
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("User not found")
+		}
+		s.logger.Error("Failed to create list", "error", err)
+		return nil, errors.NewInternalError("Failed to create list")
+	}
+
+	return saved, nil
+}
+
+func (s *listService) GetList(ctx context.Context, idOrSlug, requesterID string) (*ListDetails, error) {
+	viaSlug := false
+	list, err := s.listRepo.GetByID(ctx, lists.ListID(idOrSlug))
+	if err != nil && isNotFoundError(err) {
+		viaSlug = true
+		list, err = s.listRepo.GetBySlug(ctx, idOrSlug)
+	}
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("List not found")
+		}
+		s.logger.Error("Failed to get list", "error", err, "list", idOrSlug)
+		return nil, errors.NewInternalError("Failed to get list")
+	}
+
+	// Private lists are reported as missing rather than forbidden so their
+	// IDs do not leak
+	if !list.CanView(requesterID, viaSlug) {
+		return nil, errors.NewNotFoundError("List not found")
+	}
+
+	entries, err := s.listRepo.GetEntries(ctx, list.ID)
+	if err != nil {
+		s.logger.Error("Failed to get list entries", "error", err, "list_id", list.ID)
+		return nil, errors.NewInternalError("Failed to get list")
+	}
+	if entries == nil {
+		entries = []*lists.Entry{}
+	}
+
+	return &ListDetails{List: list, Entries: entries}, nil
+}
+
+func (s *listService) GetUserLists(ctx context.Context, userID, requesterID string) ([]*lists.List, error) {
+	result, err := s.listRepo.GetByUser(ctx, users.UserID(userID), userID == requesterID)
+	if err != nil {
+		s.logger.Error("Failed to get user lists", "error", err, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to get user lists")
+	}
+	if result == nil {
+		result = []*lists.List{}
+	}
+
+	return result, nil
+}
+
+func (s *listService) UpdateList(ctx context.Context, id, requesterID string, req UpdateListRequest) (*lists.List, error) {
+	list, err := s.getOwnedList(ctx, id, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		list.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		list.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Visibility != nil {
+		list.Visibility = lists.Visibility(*req.Visibility)
+	}
+	if req.RegenerateShareSlug {
+		list.ShareSlug = lists.NewShareSlug()
+	}
+	if err := list.Validate(); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	list.UpdatedAt = s.timeProvider.Now()
+
+	updated, err := s.listRepo.Update(ctx, list)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("List not found")
+		}
+		s.logger.Error("Failed to update list", "error", err, "list_id", id)
+		return nil, errors.NewInternalError("Failed to update list")
+	}
+
+	return updated, nil
+}
+
+func (s *listService) DeleteList(ctx context.Context, id, requesterID string) error {
+	if _, err := s.getOwnedList(ctx, id, requesterID); err != nil {
+		return err
+	}
+
+	if err := s.listRepo.Delete(ctx, lists.ListID(id)); err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("List not found")
+		}
+		s.logger.Error("Failed to delete list", "error", err, "list_id", id)
+		return errors.NewInternalError("Failed to delete list")
+	}
+
+	return nil
+}
+
+func (s *listService) AddMovie(ctx context.Context, id, requesterID string, req AddMovieRequest) error {
+	movieID := strings.TrimSpace(req.MovieID)
+	if movieID == "" {
+		return errors.NewBadRequestError(lists.ErrEmptyMovieID.Error())
+	}
+	if req.Position != nil && *req.Position < 1 {
+		return errors.NewBadRequestError(lists.ErrInvalidPosition.Error())
+	}
+	if _, err := s.getOwnedList(ctx, id, requesterID); err != nil {
+		return err
+	}
+
+	err := s.listRepo.AddMovie(ctx, lists.ListID(id), movies.MovieID(movieID), req.Position)
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Movie not found")
+		}
+		s.logger.Error("Failed to add movie to list", "error", err, "list_id", id, "movie_id", movieID)
+		return errors.NewInternalError("Failed to add movie to list")
+	}
+
+	return nil
+}
+
+func (s *listService) RemoveMovie(ctx context.Context, id, requesterID, movieID string) error {
+	if _, err := s.getOwnedList(ctx, id, requesterID); err != nil {
+		return err
+	}
+
+	err := s.listRepo.RemoveMovie(ctx, lists.ListID(id), movies.MovieID(movieID))
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Movie not found in list")
+		}
+		s.logger.Error("Failed to remove movie from list", "error", err, "list_id", id, "movie_id", movieID)
+		return errors.NewInternalError("Failed to remove movie from list")
+	}
+
+	return nil
+}
+
+// getOwnedList loads a list for modification. Lists the requester cannot see
+// are reported as missing; visible lists owned by someone else are forbidden.
+func (s *listService) getOwnedList(ctx context.Context, id, requesterID string) (*lists.List, error) {
+	list, err := s.listRepo.GetByID(ctx, lists.ListID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("List not found")
+		}
+		s.logger.Error("Failed to get list", "error", err, "list_id", id)
+		return nil, errors.NewInternalError("Failed to get list")
+	}
+
+	if !list.IsOwnedBy(requesterID) {
+		if !list.CanView(requesterID, false) {
+			return nil, errors.NewNotFoundError("List not found")
+		}
+		return nil, errors.NewForbiddenError("Only the owner can modify this list")
+	}
+
+	return list, nil
+}
+
+func isNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
@@ -0,0 +1,225 @@
+package lists
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+func setupTestService() (Service, *mockListRepository) {
+	repo := new(mockListRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewListService(
+		repo,
+		&mockIDGenerator{id: "list-123"},
+		&mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		logger,
+	)
+	return service, repo
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func privateList() *lists.List {
+	return &lists.List{ID: "list-123", UserID: "owner", Name: "Watch later", Visibility: lists.VisibilityPrivate, ShareSlug: "abcdefghijklmnop"}
+}
+
+func TestCreateList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates a private list by default", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Save", ctx, mock.MatchedBy(func(l *lists.List) bool {
+			return l.ID == "list-123" && l.UserID == "owner" && l.Visibility == lists.VisibilityPrivate && l.ShareSlug != ""
+		})).Return(privateList(), nil)
+
+		list, err := service.CreateList(ctx, CreateListRequest{UserID: "owner", Name: "Watch later"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Watch later", list.Name)
+	})
+
+	t.Run("rejects an unknown visibility", func(t *testing.T) {
+		service, repo := setupTestService()
+
+		_, err := service.CreateList(ctx, CreateListRequest{UserID: "owner", Name: "Watch later", Visibility: "friends"})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns not found for an unknown user", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Save", ctx, mock.Anything).Return(nil, errors.New("user missing not found"))
+
+		_, err := service.CreateList(ctx, CreateListRequest{UserID: "missing", Name: "Watch later"})
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestGetList(t *testing.T) {
+	ctx := context.Background()
+	entries := []*lists.Entry{{Movie: &movies.Movie{ID: "movie-1"}, Position: 1}}
+
+	t.Run("owner sees a private list by ID", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+		repo.On("GetEntries", ctx, lists.ListID("list-123")).Return(entries, nil)
+
+		details, err := service.GetList(ctx, "list-123", "owner")
+
+		require.NoError(t, err)
+		assert.Len(t, details.Entries, 1)
+	})
+
+	t.Run("private list is hidden from other users", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+
+		_, err := service.GetList(ctx, "list-123", "someone-else")
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+		repo.AssertNotCalled(t, "GetEntries", mock.Anything, mock.Anything)
+	})
+
+	t.Run("share slug opens a private list", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("abcdefghijklmnop")).Return(nil, errors.New("list with ID abcdefghijklmnop not found"))
+		repo.On("GetBySlug", ctx, "abcdefghijklmnop").Return(privateList(), nil)
+		repo.On("GetEntries", ctx, lists.ListID("list-123")).Return(entries, nil)
+
+		details, err := service.GetList(ctx, "abcdefghijklmnop", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, lists.ListID("list-123"), details.List.ID)
+	})
+}
+
+func TestGetUserLists(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("owner sees private lists", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByUser", ctx, users.UserID("owner"), true).Return([]*lists.List{privateList()}, nil)
+
+		result, err := service.GetUserLists(ctx, "owner", "owner")
+
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+
+	t.Run("others only see public lists", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByUser", ctx, users.UserID("owner"), false).Return(nil, nil)
+
+		result, err := service.GetUserLists(ctx, "owner", "")
+
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestUpdateList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("owner changes visibility and rotates the slug", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+		repo.On("Update", ctx, mock.MatchedBy(func(l *lists.List) bool {
+			return l.Visibility == lists.VisibilityPublic && l.ShareSlug != "abcdefghijklmnop" && l.Name == "Watch later"
+		})).Return(&lists.List{ID: "list-123", Visibility: lists.VisibilityPublic}, nil)
+		public := "public"
+
+		list, err := service.UpdateList(ctx, "list-123", "owner", UpdateListRequest{Visibility: &public, RegenerateShareSlug: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, lists.VisibilityPublic, list.Visibility)
+	})
+
+	t.Run("forbids other users on a public list", func(t *testing.T) {
+		service, repo := setupTestService()
+		list := privateList()
+		list.Visibility = lists.VisibilityPublic
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(list, nil)
+		name := "Mine now"
+
+		_, err := service.UpdateList(ctx, "list-123", "someone-else", UpdateListRequest{Name: &name})
+
+		assertAppErrorCode(t, err, appErrors.CodeForbidden)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects an empty name", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+		empty := " "
+
+		_, err := service.UpdateList(ctx, "list-123", "owner", UpdateListRequest{Name: &empty})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+}
+
+func TestDeleteList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("hides private lists from other users", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+
+		err := service.DeleteList(ctx, "list-123", "someone-else")
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("owner deletes", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+		repo.On("Delete", ctx, lists.ListID("list-123")).Return(nil)
+
+		require.NoError(t, service.DeleteList(ctx, "list-123", "owner"))
+	})
+}
+
+func TestAddListMovie(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("appends when no position is given", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("GetByID", ctx, lists.ListID("list-123")).Return(privateList(), nil)
+		repo.On("AddMovie", ctx, lists.ListID("list-123"), movies.MovieID("movie-1"), (*int)(nil)).Return(nil)
+
+		err := service.AddMovie(ctx, "list-123", "owner", AddMovieRequest{MovieID: "movie-1"})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a non-positive position", func(t *testing.T) {
+		service, _ := setupTestService()
+		position := 0
+
+		err := service.AddMovie(ctx, "list-123", "owner", AddMovieRequest{MovieID: "movie-1", Position: &position})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+}
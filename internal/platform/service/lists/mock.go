@@ -0,0 +1,100 @@
+package lists
+
+import (
+	"context"
+	"thermondo/internal/domain/lists"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockListRepository struct {
+	mock.Mock
+}
+
+func (m *mockListRepository) Save(ctx context.Context, list *lists.List) (*lists.List, error) {
+	args := m.Called(ctx, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *mockListRepository) Update(ctx context.Context, list *lists.List) (*lists.List, error) {
+	args := m.Called(ctx, list)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *mockListRepository) Delete(ctx context.Context, id lists.ListID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockListRepository) GetByID(ctx context.Context, id lists.ListID) (*lists.List, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *mockListRepository) GetBySlug(ctx context.Context, slug string) (*lists.List, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*lists.List), args.Error(1)
+}
+
+func (m *mockListRepository) GetByUser(ctx context.Context, userID users.UserID, includePrivate bool) ([]*lists.List, error) {
+	args := m.Called(ctx, userID, includePrivate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*lists.List), args.Error(1)
+}
+
+func (m *mockListRepository) CountByUser(ctx context.Context, userID users.UserID) (lists.Counts, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(lists.Counts), args.Error(1)
+}
+
+func (m *mockListRepository) AddMovie(ctx context.Context, id lists.ListID, movieID movies.MovieID, position *int) error {
+	args := m.Called(ctx, id, movieID, position)
+	return args.Error(0)
+}
+
+func (m *mockListRepository) RemoveMovie(ctx context.Context, id lists.ListID, movieID movies.MovieID) error {
+	args := m.Called(ctx, id, movieID)
+	return args.Error(0)
+}
+
+func (m *mockListRepository) GetEntries(ctx context.Context, id lists.ListID) ([]*lists.Entry, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*lists.Entry), args.Error(1)
+}
+
+type mockIDGenerator struct {
+	id string
+}
+
+func (m *mockIDGenerator) Generate() string {
+	return m.id
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,32 @@
+package lists
+
+import "thermondo/internal/domain/lists"
+
+type CreateListRequest struct {
+	UserID      string `json:"user_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Visibility is "public" or "private"; lists are private when omitted
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// UpdateListRequest changes only the fields that are set
+type UpdateListRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Visibility  *string `json:"visibility,omitempty"`
+	// RegenerateShareSlug replaces the share slug, revoking old share links
+	RegenerateShareSlug bool `json:"regenerate_share_slug,omitempty"`
+}
+
+type AddMovieRequest struct {
+	MovieID string `json:"movie_id"`
+	// Position is 1-based; the movie is appended when omitted
+	Position *int `json:"position,omitempty"`
+}
+
+// ListDetails is a list with its ordered entries
+type ListDetails struct {
+	List    *lists.List
+	Entries []*lists.Entry
+}
@@ -0,0 +1,108 @@
+package movies
+
+import (
+	"context"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+)
+
+const (
+	// DefaultDecadeTop and MaxDecadeTop bound how many top movies are listed
+	// per decade
+	DefaultDecadeTop = 5
+	MaxDecadeTop     = 10
+)
+
+// WithAggregateRepository enables the director, genre and decade stats
+func WithAggregateRepository(aggregateRepo movies.AggregateRepository) Option {
+	return func(m *movieService) {
+		m.aggregateRepo = aggregateRepo
+	}
+}
+
+// WithRankingMetric sets the metric that picks the best and worst rated
+// movies of the aggregate stats and the top movies of each decade
+func WithRankingMetric(metric movies.RankingMetric) Option {
+	return func(m *movieService) {
+		m.rankingMetric = metric
+	}
+}
+
+// ranking is how the aggregate stats and decades rank movies
+func (m *movieService) ranking() movies.Ranking {
+	return movies.Ranking{Metric: m.rankingMetric, ConfidenceK: m.bayesianConfidenceK()}
+}
+
+// GetAggregateStats summarizes the movies of a director or genre. Stats are
+// cached for cache.AggregateStatsTTL, so new ratings show up late.
+func (m *movieService) GetAggregateStats(ctx context.Context, kind movies.AggregateKind, name string) (*movies.AggregateStats, error) {
+	name, err := movies.NormalizeAggregateName(kind, name)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if m.aggregateRepo == nil {
+		m.logger.Error("Aggregate stats requested but no aggregate repository is configured")
+		return nil, errors.NewInternalError("Aggregate stats are not available")
+	}
+	cacheKey := cache.AggregateStatsKeyFunc(string(kind), strings.ToLower(name), string(m.rankingMetric))
+
+	if m.cache != nil {
+		var cached movies.AggregateStats
+		if err := m.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	stats, err := m.aggregateRepo.GetAggregateStats(ctx, kind, name, m.ranking())
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("No movies found for " + string(kind) + " " + name)
+		}
+		m.logger.Error("Failed to get aggregate stats", "error", err, "kind", kind, "name", name)
+		return nil, errors.NewInternalError("Failed to get " + string(kind) + " stats")
+	}
+
+	if m.cache != nil {
+		if err := m.cache.Set(ctx, cacheKey, stats, cache.AggregateStatsTTL); err != nil {
+			m.logger.Warn("Failed to cache aggregate stats", "error", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// GetDecades lists every decade with movies, oldest first, with its top
+// movies. Like the aggregate stats they are cached, for cache.DecadesTTL.
+func (m *movieService) GetDecades(ctx context.Context, top int) ([]*movies.DecadeSummary, error) {
+	if top < 0 || top > MaxDecadeTop {
+		return nil, errors.NewBadRequestError("top must be between 0 and 10")
+	}
+	if m.aggregateRepo == nil {
+		m.logger.Error("Decades requested but no aggregate repository is configured")
+		return nil, errors.NewInternalError("Decades are not available")
+	}
+	cacheKey := cache.DecadesKeyFunc(top, string(m.rankingMetric))
+
+	if m.cache != nil {
+		var cached []*movies.DecadeSummary
+		if err := m.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return cached, nil
+		}
+	}
+
+	decades, err := m.aggregateRepo.GetDecades(ctx, m.ranking(), top)
+	if err != nil {
+		m.logger.Error("Failed to get decades", "error", err)
+		return nil, errors.NewInternalError("Failed to get decades")
+	}
+
+	if m.cache != nil {
+		if err := m.cache.Set(ctx, cacheKey, decades, cache.DecadesTTL); err != nil {
+			m.logger.Warn("Failed to cache decades", "error", err)
+		}
+	}
+
+	return decades, nil
+}
@@ -0,0 +1,148 @@
+package movies
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+)
+
+// WithCertificationRepository enables age certifications per territory
+func WithCertificationRepository(certificationRepo movies.CertificationRepository) Option {
+	return func(m *movieService) {
+		m.certificationRepo = certificationRepo
+	}
+}
+
+// WithKidsPolicy sets the movies listings, search and the release calendar
+// are limited to in kids mode. Title suggestions are filtered through the
+// certification repository, which must be configured as well.
+func WithKidsPolicy(policy *movies.ContentPolicy) Option {
+	return func(m *movieService) {
+		m.kidsPolicy = policy
+	}
+}
+
+func (m *movieService) ListCertifications(ctx context.Context, movieID string) ([]*movies.Certification, error) {
+	if err := m.requireCertifications(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	certifications, err := m.certificationRepo.GetCertifications(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to get certifications", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get certifications")
+	}
+
+	return certifications, nil
+}
+
+// PutCertification creates or replaces the movie's certification in a
+// territory. A US certification replaces the movie's rating.
+func (m *movieService) PutCertification(ctx context.Context, movieID, territory string, req movies.CertificationRequest) (*movies.Certification, error) {
+	certification, err := movies.NewCertification(movies.MovieID(movieID), territory, req.Certification, m.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireCertifications(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	saved, err := m.certificationRepo.SaveCertification(ctx, certification)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to save certification", "error", err, "movie_id", movieID, "territory", certification.Territory)
+		return nil, errors.NewInternalError("Failed to save certification")
+	}
+
+	m.logger.Info("Saved certification", "movie_id", movieID, "territory", saved.Territory, "certification", saved.Certification)
+	return saved, nil
+}
+
+func (m *movieService) DeleteCertification(ctx context.Context, movieID, territory string) error {
+	normalized, err := movies.NormalizeTerritory(territory)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireCertifications(ctx, movieID); err != nil {
+		return err
+	}
+
+	if err := m.certificationRepo.DeleteCertification(ctx, movies.MovieID(movieID), normalized); err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Certification not found")
+		}
+		m.logger.Error("Failed to delete certification", "error", err, "movie_id", movieID, "territory", normalized)
+		return errors.NewInternalError("Failed to delete certification")
+	}
+
+	return nil
+}
+
+// requireCertifications checks that certifications are configured and that
+// the movie exists
+func (m *movieService) requireCertifications(ctx context.Context, movieID string) error {
+	if m.certificationRepo == nil {
+		m.logger.Error("Certifications requested but no certification repository is configured")
+		return errors.NewInternalError("Certifications are not available")
+	}
+
+	exists, err := m.movieRepo.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+
+	return nil
+}
+
+// contentPolicy returns the policy the request's movies are limited to, or
+// nil when they aren't
+func (m *movieService) contentPolicy(ctx context.Context) *movies.ContentPolicy {
+	if !users.IsKidsMode(ctx) {
+		return nil
+	}
+	return m.kidsPolicy
+}
+
+// visibleSuggestions drops the title suggestions of movies the request's
+// content policy doesn't let through; director suggestions are kept
+func (m *movieService) visibleSuggestions(ctx context.Context, suggestions []*movies.Suggestion) ([]*movies.Suggestion, error) {
+	policy := m.contentPolicy(ctx)
+	if policy == nil {
+		return suggestions, nil
+	}
+	if m.certificationRepo == nil {
+		m.logger.Error("Kids mode suggestions requested but no certification repository is configured")
+		return nil, errors.NewInternalError("Failed to get suggestions")
+	}
+
+	var ids []movies.MovieID
+	for _, suggestion := range suggestions {
+		if suggestion.Kind == movies.SuggestionTitle {
+			ids = append(ids, suggestion.MovieID)
+		}
+	}
+	allowedIDs, err := m.certificationRepo.FilterAllowed(ctx, ids, *policy)
+	if err != nil {
+		m.logger.Error("Failed to filter suggestions by certification", "error", err)
+		return nil, errors.NewInternalError("Failed to get suggestions")
+	}
+	allowed := make(map[movies.MovieID]bool, len(allowedIDs))
+	for _, id := range allowedIDs {
+		allowed[id] = true
+	}
+
+	filtered := make([]*movies.Suggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if suggestion.Kind != movies.SuggestionTitle || allowed[suggestion.MovieID] {
+			filtered = append(filtered, suggestion)
+		}
+	}
+	return filtered, nil
+}
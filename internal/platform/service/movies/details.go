@@ -0,0 +1,146 @@
+package movies
+
+import (
+	"context"
+	"sync"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+)
+
+// MovieDetailsRequest selects which related data is embedded alongside the movie
+type MovieDetailsRequest struct {
+	MovieID      string
+	IncludeStats bool
+	// UserID embeds that user's rating of the movie when set
+	UserID string
+	// IncludeProviders embeds where to watch the movie, only in Region when
+	// it is set
+	IncludeProviders bool
+	Region           string
+}
+
+// MovieDetails is a movie together with the optional embedded data requested
+// in MovieDetailsRequest. Stats, UserRating and Offers are nil when not
+// requested; UserRating is also nil when the user has not rated the movie.
+type MovieDetails struct {
+	Movie      *movies.Movie
+	Stats      *rating.MovieRatingStats
+	UserRating *rating.Rating
+	Offers     []*movies.Offer
+}
+
+// StatsDisplay decides which embedded stats have too few ratings for their
+// averages to be shown; the rating service implements it
+type StatsDisplay interface {
+	WithholdStats(stats *rating.MovieRatingStats) bool
+}
+
+// WithStatsDisplay withholds the averages of embedded stats like the stats
+// endpoint does
+func WithStatsDisplay(display StatsDisplay) Option {
+	return func(m *movieService) {
+		m.statsDisplay = display
+	}
+}
+
+// GetMovieDetails loads the movie and the requested related data in one call.
+// The lookups are independent, so they run concurrently.
+func (m *movieService) GetMovieDetails(ctx context.Context, req MovieDetailsRequest) (*MovieDetails, error) {
+	needsRatings := req.IncludeStats || req.UserID != ""
+	if needsRatings && m.ratingRepo == nil {
+		m.logger.Error("Movie details requested ratings data but no rating repository is configured")
+		return nil, errors.NewInternalError("Failed to get movie details")
+	}
+	region, err := optionalRegion(req.Region)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if req.IncludeProviders && m.providerRepo == nil {
+		m.logger.Error("Movie details requested providers but no provider repository is configured")
+		return nil, errors.NewInternalError("Failed to get movie details")
+	}
+
+	var (
+		wg                                               sync.WaitGroup
+		details                                          = &MovieDetails{}
+		slug                                             string
+		movieErr, statsErr, ratingErr, offerErr, slugErr error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		details.Movie, movieErr = m.movieRepo.GetByID(ctx, movies.MovieID(req.MovieID))
+	}()
+
+	if m.slugRepo != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slug, slugErr = m.slugRepo.CurrentSlug(ctx, movies.MovieID(req.MovieID))
+		}()
+	}
+
+	if req.IncludeStats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details.Stats, statsErr = m.ratingRepo.GetMovieStats(ctx, movies.MovieID(req.MovieID))
+		}()
+	}
+
+	if req.UserID != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details.UserRating, ratingErr = m.ratingRepo.GetByUserAndMovie(ctx, users.UserID(req.UserID), movies.MovieID(req.MovieID))
+		}()
+	}
+
+	if req.IncludeProviders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details.Offers, offerErr = m.providerRepo.GetOffers(ctx, movies.MovieID(req.MovieID), region)
+		}()
+	}
+
+	wg.Wait()
+
+	if movieErr != nil {
+		if isNotFoundError(movieErr) {
+			m.logger.Error("Movie not found", "error", movieErr)
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to get movie", "error", movieErr, "movie_id", req.MovieID)
+		return nil, errors.NewInternalError("Failed to get movie")
+	}
+	// A movie without a slug is still served
+	if slugErr != nil && !isNotFoundError(slugErr) {
+		m.logger.Warn("Failed to get slug", "error", slugErr, "movie_id", req.MovieID)
+	}
+	details.Movie.Slug = slug
+
+	if statsErr != nil {
+		m.logger.Error("Failed to get movie stats", "error", statsErr, "movie_id", req.MovieID)
+		return nil, errors.NewInternalError("Failed to get movie stats")
+	}
+	if details.Stats != nil && m.statsDisplay != nil {
+		m.statsDisplay.WithholdStats(details.Stats)
+	}
+
+	// Not having rated the movie is a normal state, not an error
+	if ratingErr != nil && !isNotFoundError(ratingErr) {
+		m.logger.Error("Failed to get user rating", "error", ratingErr, "movie_id", req.MovieID, "user_id", req.UserID)
+		return nil, errors.NewInternalError("Failed to get user rating")
+	}
+
+	if offerErr != nil {
+		m.logger.Error("Failed to get offers", "error", offerErr, "movie_id", req.MovieID)
+		return nil, errors.NewInternalError("Failed to get offers")
+	}
+
+	return details, nil
+}
@@ -0,0 +1,61 @@
+package movies
+
+import (
+	"context"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+)
+
+// MergeMovies merges the duplicate sourceID into targetID on behalf of the
+// admin mergedBy. The source ID keeps resolving to the target afterwards.
+func (m *movieService) MergeMovies(ctx context.Context, sourceID, targetID, mergedBy string) (*movies.MergeRecord, error) {
+	if m.mergeRepo == nil {
+		return nil, errors.NewInternalError("Movie merging is not configured")
+	}
+
+	sourceID, targetID = strings.TrimSpace(sourceID), strings.TrimSpace(targetID)
+	if sourceID == "" || targetID == "" {
+		return nil, errors.NewBadRequestError(movies.ErrEmptyMovieID.Error())
+	}
+	if sourceID == targetID {
+		return nil, errors.NewBadRequestError(movies.ErrMergeIntoSelf.Error())
+	}
+
+	record, err := m.mergeRepo.Merge(ctx, movies.MovieID(sourceID), movies.MovieID(targetID), mergedBy, m.timeProvider.Now())
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to merge movies", "error", err, "source_id", sourceID, "target_id", targetID)
+		return nil, errors.NewInternalError("Failed to merge movies")
+	}
+
+	m.logger.Info("Merged movies",
+		"source_id", sourceID,
+		"target_id", targetID,
+		"merged_by", mergedBy,
+		"ratings_moved", record.RatingsMoved,
+		"ratings_dropped", record.RatingsDropped,
+	)
+
+	return record, nil
+}
+
+// ResolveMovieRedirect returns the ID a merged movie now lives under
+func (m *movieService) ResolveMovieRedirect(ctx context.Context, id string) (string, error) {
+	if m.mergeRepo == nil {
+		return "", errors.NewNotFoundError("Movie not found")
+	}
+
+	newID, err := m.mergeRepo.ResolveRedirect(ctx, movies.MovieID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to resolve movie redirect", "error", err, "movie_id", id)
+		return "", errors.NewInternalError("Failed to get movie")
+	}
+
+	return string(newID), nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -23,6 +26,14 @@ func (m *MockMovieRepository) Save(ctx context.Context, movie *movies.Movie) (*m
 	return args.Get(0).(*movies.Movie), args.Error(1)
 }
 
+func (m *MockMovieRepository) Update(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	args := m.Called(ctx, movie)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
 func (m *MockMovieRepository) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -44,6 +55,51 @@ func (m *MockMovieRepository) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockMovieRepository) Search(ctx context.Context, filter movies.SearchFilter, opts ...movies.SearchOption) ([]*movies.Movie, int64, error) {
+	args := m.Called(ctx, filter, opts)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*movies.Movie), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMovieRepository) CountSearch(ctx context.Context, filter movies.SearchFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMovieRepository) GetSearchFacets(ctx context.Context, filter movies.SearchFilter) (*movies.SearchFacets, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.SearchFacets), args.Error(1)
+}
+
+func (m *MockMovieRepository) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Suggestion), args.Error(1)
+}
+
+func (m *MockMovieRepository) FindPotentialDuplicates(ctx context.Context, movie *movies.Movie) ([]*movies.Movie, error) {
+	args := m.Called(ctx, movie)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *MockMovieRepository) Random(ctx context.Context, filter movies.SearchFilter, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
 func (m *MockMovieRepository) SearchByTitle(ctx context.Context, title string, opts ...movies.SearchOption) ([]*movies.Movie, error) {
 	args := m.Called(ctx, title, opts)
 	if args.Get(0) == nil {
@@ -97,6 +153,162 @@ func (m *MockMovieRepository) QueryContext(ctx context.Context, query string, ar
 	return callArgs.Get(0).(*sql.Rows), callArgs.Error(1)
 }
 
+// MockRatingRepository is a mock implementation of the rating.Repository interface
+type MockRatingRepository struct {
+	mock.Mock
+}
+
+func (m *MockRatingRepository) GetByUser(ctx context.Context, userID users.UserID, opts ...rating.SearchOption) ([]*rating.Rating, error) {
+	args := m.Called(ctx, userID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter rating.UserRatingFilter, opts ...rating.SearchOption) ([]*rating.RatingWithMovie, int64, error) {
+	args := m.Called(ctx, userID, filter, opts)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.RatingWithMovie), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRatingRepository) GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*rating.Rating, error) {
+	args := m.Called(ctx, userID, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetByID(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetByMovie(ctx context.Context, movieID movies.MovieID, opts ...rating.SearchOption) ([]*rating.Rating, error) {
+	args := m.Called(ctx, movieID, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRatingRepository) Delete(ctx context.Context, id rating.RatingID, deletedAt time.Time) error {
+	args := m.Called(ctx, id, deletedAt)
+	return args.Error(0)
+}
+
+func (m *MockRatingRepository) GetByIDIncludingDeleted(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) Restore(ctx context.Context, id rating.RatingID, deletedSince time.Time) (*rating.Rating, error) {
+	args := m.Called(ctx, id, deletedSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) Exists(ctx context.Context, id rating.RatingID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRatingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]movies.MovieID), args.Error(1)
+}
+
+func (m *MockRatingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.UserID), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetGlobalStats(ctx context.Context) (*rating.GlobalStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *MockRatingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*rating.GlobalStats, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*rating.RaterGroup, error) {
+	args := m.Called(ctx, movieID, asOf, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.RaterGroup), args.Error(1)
+}
+
+func (m *MockRatingRepository) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, options ...rating.SearchOption) ([]*rating.ReviewMatch, int64, error) {
+	args := m.Called(ctx, query, filter, options)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.ReviewMatch), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRatingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*rating.RatingActivity, error) {
+	args := m.Called(ctx, movieID, baselineStart, windowStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.RatingActivity), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*rating.MovieRatingStats, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.MovieRatingStats), args.Error(1)
+}
+
+func (m *MockRatingRepository) Save(ctx context.Context, r *rating.Rating) (*rating.Rating, error) {
+	args := m.Called(ctx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) Update(ctx context.Context, r *rating.Rating) (*rating.Rating, error) {
+	args := m.Called(ctx, r)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
 // Mock ID Generator
 type MockIDGenerator struct {
 	mock.Mock
@@ -116,3 +328,308 @@ func (m *MockTimeProvider) Now() time.Time {
 	args := m.Called()
 	return args.Get(0).(time.Time)
 }
+
+// MockTranslationRepository is a mock implementation of movies.TranslationRepository
+type MockTranslationRepository struct {
+	mock.Mock
+}
+
+func (m *MockTranslationRepository) SaveTranslation(ctx context.Context, translation *movies.Translation) (*movies.Translation, error) {
+	args := m.Called(ctx, translation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) GetTranslation(ctx context.Context, movieID movies.MovieID, locale string) (*movies.Translation, error) {
+	args := m.Called(ctx, movieID, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) GetTranslations(ctx context.Context, movieID movies.MovieID) ([]*movies.Translation, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Translation), args.Error(1)
+}
+
+func (m *MockTranslationRepository) DeleteTranslation(ctx context.Context, movieID movies.MovieID, locale string) error {
+	args := m.Called(ctx, movieID, locale)
+	return args.Error(0)
+}
+
+func (m *MockTranslationRepository) FindTranslations(ctx context.Context, movieIDs []movies.MovieID, locales []string) ([]*movies.Translation, error) {
+	args := m.Called(ctx, movieIDs, locales)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Translation), args.Error(1)
+}
+
+// MockReleaseRepository is a mock implementation of movies.ReleaseRepository
+type MockReleaseRepository struct {
+	mock.Mock
+}
+
+func (m *MockReleaseRepository) SaveRelease(ctx context.Context, release *movies.Release) (*movies.Release, error) {
+	args := m.Called(ctx, release)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Release), args.Error(1)
+}
+
+func (m *MockReleaseRepository) GetReleases(ctx context.Context, movieID movies.MovieID) ([]*movies.Release, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Release), args.Error(1)
+}
+
+func (m *MockReleaseRepository) DeleteRelease(ctx context.Context, movieID movies.MovieID, region string, releaseType movies.ReleaseType) error {
+	args := m.Called(ctx, movieID, region, releaseType)
+	return args.Error(0)
+}
+
+func (m *MockReleaseRepository) ListUpcoming(ctx context.Context, filter movies.UpcomingFilter) ([]*movies.UpcomingRelease, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*movies.UpcomingRelease), args.Get(1).(int64), args.Error(2)
+}
+
+// MockProviderRepository is a mock implementation of movies.ProviderRepository
+type MockProviderRepository struct {
+	mock.Mock
+}
+
+func (m *MockProviderRepository) ListProviders(ctx context.Context) ([]*movies.Provider, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Provider), args.Error(1)
+}
+
+func (m *MockProviderRepository) SaveProvider(ctx context.Context, provider *movies.Provider) (*movies.Provider, error) {
+	args := m.Called(ctx, provider)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Provider), args.Error(1)
+}
+
+func (m *MockProviderRepository) SaveOffer(ctx context.Context, offer *movies.Offer) (*movies.Offer, error) {
+	args := m.Called(ctx, offer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Offer), args.Error(1)
+}
+
+func (m *MockProviderRepository) GetOffers(ctx context.Context, movieID movies.MovieID, region string) ([]*movies.Offer, error) {
+	args := m.Called(ctx, movieID, region)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Offer), args.Error(1)
+}
+
+func (m *MockProviderRepository) DeleteOffer(ctx context.Context, movieID movies.MovieID, region, providerID string, offerType movies.OfferType) error {
+	args := m.Called(ctx, movieID, region, providerID, offerType)
+	return args.Error(0)
+}
+
+// MockCertificationRepository is a mock implementation of movies.CertificationRepository
+type MockCertificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockCertificationRepository) SaveCertification(ctx context.Context, certification *movies.Certification) (*movies.Certification, error) {
+	args := m.Called(ctx, certification)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Certification), args.Error(1)
+}
+
+func (m *MockCertificationRepository) GetCertifications(ctx context.Context, movieID movies.MovieID) ([]*movies.Certification, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Certification), args.Error(1)
+}
+
+func (m *MockCertificationRepository) DeleteCertification(ctx context.Context, movieID movies.MovieID, territory string) error {
+	args := m.Called(ctx, movieID, territory)
+	return args.Error(0)
+}
+
+func (m *MockCertificationRepository) FilterAllowed(ctx context.Context, ids []movies.MovieID, policy movies.ContentPolicy) ([]movies.MovieID, error) {
+	args := m.Called(ctx, ids, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]movies.MovieID), args.Error(1)
+}
+
+// MockPeopleRepository is a mock implementation of people.Repository
+type MockPeopleRepository struct {
+	mock.Mock
+}
+
+func (m *MockPeopleRepository) Save(ctx context.Context, person *people.Person) (*people.Person, error) {
+	args := m.Called(ctx, person)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *MockPeopleRepository) GetByID(ctx context.Context, id people.PersonID) (*people.Person, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *MockPeopleRepository) GetByName(ctx context.Context, name string) (*people.Person, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *MockPeopleRepository) SaveCredit(ctx context.Context, credit *people.Credit) (*people.Credit, error) {
+	args := m.Called(ctx, credit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Credit), args.Error(1)
+}
+
+func (m *MockPeopleRepository) DeleteCredit(ctx context.Context, movieID movies.MovieID, personID people.PersonID, role people.Role) error {
+	args := m.Called(ctx, movieID, personID, role)
+	return args.Error(0)
+}
+
+func (m *MockPeopleRepository) GetMovieCredits(ctx context.Context, movieID movies.MovieID) ([]*people.Credit, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*people.Credit), args.Error(1)
+}
+
+func (m *MockPeopleRepository) GetPersonMovies(ctx context.Context, personID people.PersonID, role people.Role, options ...movies.SearchOption) ([]*people.CreditedMovie, int64, error) {
+	args := m.Called(ctx, personID, role, options)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*people.CreditedMovie), args.Get(1).(int64), args.Error(2)
+}
+
+// MockMergeRepository is a mock implementation of the movies.MergeRepository interface
+type MockMergeRepository struct {
+	mock.Mock
+}
+
+func (m *MockMergeRepository) Merge(ctx context.Context, sourceID, targetID movies.MovieID, mergedBy string, mergedAt time.Time) (*movies.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID, mergedBy, mergedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.MergeRecord), args.Error(1)
+}
+
+func (m *MockMergeRepository) ResolveRedirect(ctx context.Context, id movies.MovieID) (movies.MovieID, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(movies.MovieID), args.Error(1)
+}
+
+// MockPosterRepository is a mock implementation of the movies.PosterRepository interface
+type MockPosterRepository struct {
+	mock.Mock
+}
+
+func (m *MockPosterRepository) ReplacePosters(ctx context.Context, movieID movies.MovieID, posterURL string, posters []*movies.Poster, hash movies.PosterHash) ([]*movies.Poster, error) {
+	args := m.Called(ctx, movieID, posterURL, posters, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Poster), args.Error(1)
+}
+
+func (m *MockPosterRepository) GetPoster(ctx context.Context, movieID movies.MovieID, variant movies.PosterVariant) (*movies.Poster, error) {
+	args := m.Called(ctx, movieID, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Poster), args.Error(1)
+}
+
+func (m *MockPosterRepository) ListPosters(ctx context.Context, movieID movies.MovieID) ([]*movies.Poster, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Poster), args.Error(1)
+}
+
+func (m *MockPosterRepository) ListPosterHashes(ctx context.Context) ([]*movies.PosterFingerprint, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.PosterFingerprint), args.Error(1)
+}
+
+// MockAggregateRepository is a mock implementation of the movies.AggregateRepository interface
+type MockAggregateRepository struct {
+	mock.Mock
+}
+
+func (m *MockAggregateRepository) GetAggregateStats(ctx context.Context, kind movies.AggregateKind, name string, ranking movies.Ranking) (*movies.AggregateStats, error) {
+	args := m.Called(ctx, kind, name, ranking)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.AggregateStats), args.Error(1)
+}
+
+func (m *MockAggregateRepository) GetDecades(ctx context.Context, ranking movies.Ranking, top int) ([]*movies.DecadeSummary, error) {
+	args := m.Called(ctx, ranking, top)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.DecadeSummary), args.Error(1)
+}
+
+type MockSlugRepository struct {
+	mock.Mock
+}
+
+func (m *MockSlugRepository) AssignSlug(ctx context.Context, movieID movies.MovieID, base string, assignedAt time.Time) (string, error) {
+	args := m.Called(ctx, movieID, base, assignedAt)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSlugRepository) CurrentSlug(ctx context.Context, movieID movies.MovieID) (string, error) {
+	args := m.Called(ctx, movieID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSlugRepository) ResolveSlug(ctx context.Context, slug string) (movies.MovieID, string, error) {
+	args := m.Called(ctx, slug)
+	return movies.MovieID(args.String(0)), args.String(1), args.Error(2)
+}
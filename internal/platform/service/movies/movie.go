@@ -4,26 +4,140 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/cache"
 	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/storage"
+	"time"
 )
 
 type Service interface {
 	CreateMovie(ctx context.Context, req movies.CreateMovieRequest) (*movies.Movie, error)
+	PatchMovie(ctx context.Context, id string, req PatchMovieRequest) (*movies.Movie, error)
 	GetAllMovies(ctx context.Context, limit, offset int, sortBy, order string) ([]*movies.Movie, int64, error)
 	GetMovieByID(ctx context.Context, id string) (*movies.Movie, error)
 	SearchMovies(ctx context.Context, req movies.SearchMoviesRequest) ([]*movies.Movie, int64, error)
+	GetSearchFacets(ctx context.Context, req movies.SearchMoviesRequest) (*movies.SearchFacets, error)
+	Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error)
+	RandomMovies(ctx context.Context, req RandomRequest) ([]*movies.Movie, error)
+	GetMovieDetails(ctx context.Context, req MovieDetailsRequest) (*MovieDetails, error)
+	LocalizeMovies(ctx context.Context, moviesList []*movies.Movie, locales []string)
+	ListTranslations(ctx context.Context, movieID string) ([]*movies.Translation, error)
+	GetTranslation(ctx context.Context, movieID, locale string) (*movies.Translation, error)
+	PutTranslation(ctx context.Context, movieID, locale string, req movies.TranslationRequest) (*movies.Translation, error)
+	DeleteTranslation(ctx context.Context, movieID, locale string) error
+	ListReleases(ctx context.Context, movieID string) ([]*movies.Release, error)
+	PutRelease(ctx context.Context, movieID, region, releaseType string, req movies.ReleaseRequest) (*movies.Release, error)
+	DeleteRelease(ctx context.Context, movieID, region, releaseType string) error
+	ListUpcoming(ctx context.Context, req UpcomingRequest) ([]*movies.UpcomingRelease, int64, error)
+	ListCertifications(ctx context.Context, movieID string) ([]*movies.Certification, error)
+	PutCertification(ctx context.Context, movieID, territory string, req movies.CertificationRequest) (*movies.Certification, error)
+	DeleteCertification(ctx context.Context, movieID, territory string) error
+	ListProviders(ctx context.Context) ([]*movies.Provider, error)
+	PutProvider(ctx context.Context, providerID string, req movies.ProviderRequest) (*movies.Provider, error)
+	ListOffers(ctx context.Context, movieID, region string) ([]*movies.Offer, error)
+	PutOffer(ctx context.Context, movieID, region, providerID, offerType string, req movies.OfferRequest) (*movies.Offer, error)
+	DeleteOffer(ctx context.Context, movieID, region, providerID, offerType string) error
+	MergeMovies(ctx context.Context, sourceID, targetID, mergedBy string) (*movies.MergeRecord, error)
+	ResolveMovieRedirect(ctx context.Context, id string) (string, error)
+	// ResolveMovieSlug returns the ID of the movie a current or old slug
+	// belongs to and the movie's current slug
+	ResolveMovieSlug(ctx context.Context, slug string) (string, string, error)
+	UploadPoster(ctx context.Context, id string, data []byte) ([]*PosterImage, error)
+	ListPosters(ctx context.Context, id string) ([]*PosterImage, error)
+	PosterURL(ctx context.Context, id, variant string) (string, error)
+	// FindDuplicatePosters groups movies with the same or nearly the same
+	// poster
+	FindDuplicatePosters(ctx context.Context, maxDistance int) ([]*PosterDuplicates, error)
+	// SearchByPoster finds the movies whose poster resembles an image
+	SearchByPoster(ctx context.Context, data []byte, maxDistance int) ([]*PosterMatch, error)
+	// GetAggregateStats summarizes the movies of a director or genre
+	GetAggregateStats(ctx context.Context, kind movies.AggregateKind, name string) (*movies.AggregateStats, error)
+	// GetDecades lists the decades with movies and the top movies of each
+	GetDecades(ctx context.Context, top int) ([]*movies.DecadeSummary, error)
 }
 
 type movieService struct {
-	movieRepo    movies.Repository
-	idGenerator  shared.IDGenerator
-	timeProvider shared.TimeProvider
-	logger       *slog.Logger
+	movieRepo           movies.Repository
+	ratingRepo          rating.Repository
+	statsDisplay        StatsDisplay
+	translationRepo     movies.TranslationRepository
+	releaseRepo         movies.ReleaseRepository
+	certificationRepo   movies.CertificationRepository
+	providerRepo        movies.ProviderRepository
+	kidsPolicy          *movies.ContentPolicy
+	peopleRepo          people.Repository
+	mergeRepo           movies.MergeRepository
+	aggregateRepo       movies.AggregateRepository
+	posterRepo          movies.PosterRepository
+	slugRepo            movies.SlugRepository
+	posterStorage       storage.Storage
+	posterURLTTL        time.Duration
+	idGenerator         shared.IDGenerator
+	timeProvider        shared.TimeProvider
+	logger              *slog.Logger
+	cache               cache.Cache
+	bayesianConfidenceK func() float64
+	rankingMetric       movies.RankingMetric
 }
 
-func (m *movieService) CreateMovie(ctx context.Context, req movies.CreateMovieRequest) (*movies.Movie, error) {
+// Option configures optional dependencies of the movie service
+type Option func(*movieService)
+
+// WithRatingRepository enables embedding rating data in movie details
+func WithRatingRepository(ratingRepo rating.Repository) Option {
+	return func(m *movieService) {
+		m.ratingRepo = ratingRepo
+	}
+}
+
+// WithTranslationRepository enables localized titles and the translation endpoints
+func WithTranslationRepository(translationRepo movies.TranslationRepository) Option {
+	return func(m *movieService) {
+		m.translationRepo = translationRepo
+	}
+}
+
+// WithPeopleRepository records the director of newly created movies as a credit
+func WithPeopleRepository(peopleRepo people.Repository) Option {
+	return func(m *movieService) {
+		m.peopleRepo = peopleRepo
+	}
+}
+
+// WithMergeRepository enables merging duplicate movies and resolving merged IDs
+func WithMergeRepository(mergeRepo movies.MergeRepository) Option {
+	return func(m *movieService) {
+		m.mergeRepo = mergeRepo
+	}
+}
+
+// WithCache enables caching of search facets and suggestions
+func WithCache(c cache.Cache) Option {
+	return func(m *movieService) {
+		m.cache = c
+	}
+}
+
+// WithBayesianConfidenceK reads the prior weight used by the min_rating
+// search filter and the rankings from k, whenever one is needed, so it
+// follows the rating service's configuration as that is reloaded
+func WithBayesianConfidenceK(k func() float64) Option {
+	return func(m *movieService) {
+		m.bayesianConfidenceK = k
+	}
+}
+
+func (m *movieService) CreateMovie(ctx context.Context, req movies.CreateMovieRequest) (_ *movies.Movie, err error) {
+	op := logging.StartOp(ctx, m.logger, "movie.create", "title", req.Title)
+	defer op.End(&err)
+
 	var options []movies.MovieOption
 
 	if req.Rating != nil {
@@ -35,6 +149,9 @@ func (m *movieService) CreateMovie(ctx context.Context, req movies.CreateMovieRe
 	if req.Revenue != nil {
 		options = append(options, movies.WithRevenue(*req.Revenue))
 	}
+	if req.Currency != "" {
+		options = append(options, movies.WithCurrency(req.Currency))
+	}
 	if req.IMDbID != nil {
 		options = append(options, movies.WithIMDbID(*req.IMDbID))
 	}
@@ -60,6 +177,12 @@ func (m *movieService) CreateMovie(ctx context.Context, req movies.CreateMovieRe
 		return nil, errors.NewBadRequestError(err.Error())
 	}
 
+	if !req.AllowDuplicate {
+		if err := m.checkDuplicates(ctx, movie); err != nil {
+			return nil, err
+		}
+	}
+
 	savedMovie, err := m.movieRepo.Save(ctx, movie)
 	if err != nil {
 		if isConflictError(err) {
@@ -70,16 +193,97 @@ func (m *movieService) CreateMovie(ctx context.Context, req movies.CreateMovieRe
 		return nil, errors.NewInternalError("Failed to create movie")
 	}
 
+	op.With("movie_id", savedMovie.ID)
+	m.creditDirector(ctx, savedMovie)
+	m.assignSlug(ctx, savedMovie)
+
 	return savedMovie, nil
 }
 
-func (m *movieService) GetAllMovies(ctx context.Context, limit int, offset int, sortBy string, order string) ([]*movies.Movie, int64, error) {
+// DuplicateMovieError rejects a new movie that looks like one already in the
+// catalogue. It unwraps to a conflict AppError.
+type DuplicateMovieError struct {
+	*errors.AppError
+	Candidates []*movies.DuplicateCandidate
+}
+
+func (e *DuplicateMovieError) Unwrap() error {
+	return e.AppError
+}
+
+// checkDuplicates returns a DuplicateMovieError when movie shares its IMDb ID,
+// or its normalized title and release year, with an existing movie
+func (m *movieService) checkDuplicates(ctx context.Context, movie *movies.Movie) error {
+	existing, err := m.movieRepo.FindPotentialDuplicates(ctx, movie)
+	if err != nil {
+		m.logger.Error("Failed to check for duplicate movies", "error", err)
+		return errors.NewInternalError("Failed to create movie")
+	}
+
+	candidates := movies.MatchDuplicates(movie, existing)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	m.logger.Info("Rejected potential duplicate movie", "title", movie.Title, "candidates", len(candidates))
+	return &DuplicateMovieError{
+		AppError:   errors.NewConflictError("Movie may already exist; set allow_duplicate to create it anyway"),
+		Candidates: candidates,
+	}
+}
+
+// creditDirector links the movie's director to a person, reusing an existing
+// person with the same name. The director string stays on the movie, so a
+// failure here only leaves the credit missing and is not returned.
+func (m *movieService) creditDirector(ctx context.Context, movie *movies.Movie) {
+	if m.peopleRepo == nil {
+		return
+	}
+
+	person, err := m.peopleRepo.GetByName(ctx, movie.Director)
+	if err != nil {
+		if !isNotFoundError(err) {
+			m.logger.Warn("Failed to look up director", "error", err, "movie_id", movie.ID)
+			return
+		}
+		person, err = people.NewPerson(movie.Director, m.idGenerator, m.timeProvider)
+		if err == nil {
+			person, err = m.peopleRepo.Save(ctx, person)
+		}
+		if err != nil {
+			m.logger.Warn("Failed to create director", "error", err, "movie_id", movie.ID)
+			return
+		}
+	}
+
+	credit, err := people.NewCredit(movie.ID, person.ID, people.RoleDirector, "", 0, m.timeProvider)
+	if err == nil {
+		_, err = m.peopleRepo.SaveCredit(ctx, credit)
+	}
+	if err != nil {
+		m.logger.Warn("Failed to credit director", "error", err, "movie_id", movie.ID)
+	}
+}
+
+func (m *movieService) GetAllMovies(ctx context.Context, limit int, offset int, sortBy string, order string) (_ []*movies.Movie, _ int64, err error) {
+	defer logging.StartOp(ctx, m.logger, "movie.list", "limit", limit, "offset", offset).End(&err)
+
 	searchOptions := []movies.SearchOption{
 		movies.WithLimit(limit),
 		movies.WithOffset(offset),
 		movies.WithSort(sortBy, order),
 	}
 
+	// Kids mode lists through search, which filters by certification
+	if policy := m.contentPolicy(ctx); policy != nil {
+		filter := movies.SearchFilter{
+			CertificationTerritory: policy.Territory,
+			Certifications:         policy.Certifications,
+			BayesianConfidenceK:    m.bayesianConfidenceK(),
+		}
+		return m.search(ctx, filter, offset, searchOptions)
+	}
+
 	moviesList, err := m.movieRepo.GetAll(ctx, searchOptions...)
 	if err != nil {
 		m.logger.Error("Failed to get movies", "error", err)
@@ -95,66 +299,201 @@ func (m *movieService) GetAllMovies(ctx context.Context, limit int, offset int,
 	return moviesList, totalCount, nil
 }
 
-func (m *movieService) GetMovieByID(ctx context.Context, id string) (*movies.Movie, error) {
+func (m *movieService) GetMovieByID(ctx context.Context, id string) (_ *movies.Movie, err error) {
+	defer logging.StartOp(ctx, m.logger, "movie.get", "movie_id", id).End(&err)
+
 	movie, err := m.movieRepo.GetByID(ctx, movies.MovieID(id))
 	if err != nil {
 		if isNotFoundError(err) {
-			m.logger.Error("Movie not found", "error", err)
 			return nil, errors.NewNotFoundError("Movie not found")
 		}
+		m.logger.Error("Failed to get movie", "error", err, "movie_id", id)
 		return nil, errors.NewInternalError("Failed to get movie")
 	}
+	m.loadSlug(ctx, movie)
 
 	return movie, nil
 }
 
-func (m *movieService) SearchMovies(ctx context.Context, req movies.SearchMoviesRequest) ([]*movies.Movie, int64, error) {
+func (m *movieService) SearchMovies(ctx context.Context, req movies.SearchMoviesRequest) (_ []*movies.Movie, _ int64, err error) {
+	defer logging.StartOp(ctx, m.logger, "movie.search", "query", req.Query, "limit", req.Limit, "offset", req.Offset).End(&err)
+
 	searchOptions := []movies.SearchOption{
 		movies.WithLimit(req.Limit),
 		movies.WithOffset(req.Offset),
 		movies.WithSort(req.SortBy, req.Order),
 	}
 
-	var (
-		moviesList []*movies.Movie
-		err        error
-	)
-
-	switch {
-	case req.Query != "":
-		moviesList, err = m.movieRepo.SearchByTitle(ctx, req.Query, searchOptions...)
-	case req.Genre != "":
-		moviesList, err = m.movieRepo.GetByGenre(ctx, req.Genre, searchOptions...)
-	case req.Director != "":
-		moviesList, err = m.movieRepo.GetByDirector(ctx, req.Director, searchOptions...)
-	case req.MinYear != nil || req.MaxYear != nil:
-		minYear := movies.FirstMovieYear
-		maxYear := m.timeProvider.Now().Year() + movies.MaxFutureYears
-		if req.MinYear != nil {
-			minYear = *req.MinYear
-		}
-		if req.MaxYear != nil {
-			maxYear = *req.MaxYear
-		}
-		moviesList, err = m.movieRepo.GetByYearRange(ctx, minYear, maxYear, searchOptions...)
-	default:
-		moviesList, err = m.movieRepo.GetAll(ctx, searchOptions...)
+	filter, err := m.searchFilter(ctx, req)
+	if err != nil {
+		return nil, 0, errors.NewBadRequestError(err.Error())
 	}
 
+	return m.search(ctx, filter, req.Offset, searchOptions)
+}
+
+func (m *movieService) search(ctx context.Context, filter movies.SearchFilter, offset int, searchOptions []movies.SearchOption) ([]*movies.Movie, int64, error) {
+	moviesList, totalCount, err := m.movieRepo.Search(ctx, filter, searchOptions...)
 	if err != nil {
 		m.logger.Error("Failed to search movies", "error", err)
 		return nil, 0, errors.NewInternalError("Failed to search movies")
 	}
 
-	totalCount, err := m.movieRepo.Count(ctx)
-	if err != nil {
-		m.logger.Error("Failed to get movie count", "error", err)
-		return nil, 0, errors.NewInternalError("Failed to get movie count")
+	// The window count is only available when the page has rows; a page past
+	// the end still needs the real total for pagination metadata
+	if len(moviesList) == 0 && offset > 0 {
+		totalCount, err = m.movieRepo.CountSearch(ctx, filter)
+		if err != nil {
+			m.logger.Error("Failed to get movie count", "error", err)
+			return nil, 0, errors.NewInternalError("Failed to get movie count")
+		}
 	}
 
 	return moviesList, totalCount, nil
 }
 
+// GetSearchFacets returns genre, decade, language and MPAA rating counts for
+// the movies matching req's filters. Pagination and sorting are ignored.
+func (m *movieService) GetSearchFacets(ctx context.Context, req movies.SearchMoviesRequest) (*movies.SearchFacets, error) {
+	filter, err := m.searchFilter(ctx, req)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	cacheKey := cache.MovieFacetsKeyFunc(searchFilterKey(filter))
+
+	if m.cache != nil {
+		var cached movies.SearchFacets
+		if err := m.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	facets, err := m.movieRepo.GetSearchFacets(ctx, filter)
+	if err != nil {
+		m.logger.Error("Failed to get search facets", "error", err)
+		return nil, errors.NewInternalError("Failed to get search facets")
+	}
+
+	if m.cache != nil {
+		if err := m.cache.Set(ctx, cacheKey, facets, cache.MovieFacetsTTL); err != nil {
+			m.logger.Warn("Failed to cache search facets", "error", err)
+		}
+	}
+
+	return facets, nil
+}
+
+// Suggest returns autocomplete matches on titles and directors. Results are
+// cached briefly since search-as-you-type repeats the same prefixes a lot.
+func (m *movieService) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	query = strings.TrimSpace(query)
+	cacheKey := cache.MovieSuggestKeyFunc(strings.ToLower(query), limit)
+
+	if m.cache != nil {
+		var cached []*movies.Suggestion
+		if err := m.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return m.visibleSuggestions(ctx, cached)
+		}
+	}
+
+	suggestions, err := m.movieRepo.Suggest(ctx, query, limit)
+	if err != nil {
+		m.logger.Error("Failed to get suggestions", "error", err, "query", query)
+		return nil, errors.NewInternalError("Failed to get suggestions")
+	}
+	if suggestions == nil {
+		suggestions = []*movies.Suggestion{}
+	}
+
+	if m.cache != nil {
+		if err := m.cache.Set(ctx, cacheKey, suggestions, cache.MovieSuggestTTL); err != nil {
+			m.logger.Warn("Failed to cache suggestions", "error", err)
+		}
+	}
+
+	return m.visibleSuggestions(ctx, suggestions)
+}
+
+// searchFilter turns req into a filter. In kids mode the kids policy
+// applies as well: a max_certification in its territory can only narrow it,
+// and one in another territory is replaced by it.
+func (m *movieService) searchFilter(ctx context.Context, req movies.SearchMoviesRequest) (movies.SearchFilter, error) {
+	filter := movies.SearchFilter{
+		Query:               req.Query,
+		Genre:               req.Genre,
+		Director:            req.Director,
+		MinYear:             req.MinYear,
+		MaxYear:             req.MaxYear,
+		Language:            req.Language,
+		Country:             req.Country,
+		MinDuration:         req.MinDuration,
+		MinBayesianRating:   req.MinRating,
+		Featuring:           req.Featuring,
+		BayesianConfidenceK: m.bayesianConfidenceK(),
+	}
+
+	if strings.TrimSpace(req.Provider) != "" {
+		provider, err := movies.NormalizeProviderID(req.Provider)
+		if err != nil {
+			return filter, err
+		}
+		region, err := optionalRegion(req.Region)
+		if err != nil {
+			return filter, err
+		}
+		filter.Provider, filter.ProviderRegion = provider, region
+	} else if strings.TrimSpace(req.Region) != "" {
+		return filter, fmt.Errorf("region can only be given with provider")
+	}
+
+	if strings.TrimSpace(req.MaxCertification) != "" {
+		territory := req.Territory
+		if strings.TrimSpace(territory) == "" {
+			territory = movies.DefaultTerritory
+		}
+		policy, err := movies.NewContentPolicy(territory, req.MaxCertification)
+		if err != nil {
+			return filter, err
+		}
+		filter.CertificationTerritory = policy.Territory
+		filter.Certifications = policy.Certifications
+	}
+
+	if policy := m.contentPolicy(ctx); policy != nil {
+		if filter.CertificationTerritory != policy.Territory {
+			filter.CertificationTerritory = policy.Territory
+			filter.Certifications = policy.Certifications
+		} else if len(policy.Certifications) < len(filter.Certifications) {
+			// Both lists are a prefix of the same rating board
+			filter.Certifications = policy.Certifications
+		}
+	}
+
+	return filter, nil
+}
+
+// searchFilterKey renders a filter as a stable cache key segment
+func searchFilterKey(filter movies.SearchFilter) string {
+	optInt := func(v *int) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.Itoa(*v)
+	}
+	minRating := ""
+	if filter.MinBayesianRating != nil {
+		minRating = strconv.FormatFloat(*filter.MinBayesianRating, 'f', -1, 64)
+	}
+
+	return strings.ToLower(strings.Join([]string{
+		filter.Query, filter.Genre, filter.Director,
+		optInt(filter.MinYear), optInt(filter.MaxYear),
+		filter.Language, filter.Country, optInt(filter.MinDuration), minRating,
+		filter.Featuring, filter.CertificationTerritory, strings.Join(filter.Certifications, ","),
+		filter.Provider, filter.ProviderRegion,
+	}, "|"))
+}
+
 func (m *movieService) getSortColumn(sortBy string) string {
 	switch sortBy {
 	case "title":
@@ -178,13 +517,23 @@ func NewMovieService(movieRepo movies.Repository,
 	idGenerator shared.IDGenerator,
 	timeProvider shared.TimeProvider,
 	logger *slog.Logger,
+	opts ...Option,
 ) Service {
-	return &movieService{
-		movieRepo:    movieRepo,
-		idGenerator:  idGenerator,
-		timeProvider: timeProvider,
-		logger:       logger,
+	service := &movieService{
+		movieRepo:           movieRepo,
+		idGenerator:         idGenerator,
+		timeProvider:        timeProvider,
+		logger:              logger,
+		bayesianConfidenceK: func() float64 { return rating.DefaultBayesianConfidenceK },
+		rankingMetric:       movies.RankByBayesian,
+		posterURLTTL:        DefaultPosterURLTTL,
 	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	return service
 }
 
 func isConflictError(err error) bool {
@@ -192,5 +541,5 @@ func isConflictError(err error) bool {
 }
 
 func isNotFoundError(err error) bool {
-	return fmt.Sprintf("%v", err) == "not found"
+	return strings.Contains(err.Error(), "not found")
 }
@@ -1,18 +1,33 @@
 package movies
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
 	"testing"
 	"time"
 
 	"log/slog"
+	"thermondo/internal/domain/money"
 	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
 	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/mergepatch"
+	"thermondo/internal/pkg/storage"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Test Helpers
@@ -30,6 +45,7 @@ func createTestMovie() *movies.Movie {
 		Rating:       movies.Rating("PG-13"),
 		Budget:       int64Ptr(100000000),
 		Revenue:      int64Ptr(500000000),
+		Currency:     "USD",
 		IMDbID:       stringPtr("tt1234567"),
 		PosterURL:    stringPtr("https://example.com/poster.jpg"),
 	}
@@ -90,6 +106,7 @@ func TestCreateMovie(t *testing.T) {
 			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
 				idGen.On("Generate").Return("test-id-123")
 				timeProv.On("Now").Return(now)
+				repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
 				repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
 			},
 			expectedMovie: createTestMovie(),
@@ -114,6 +131,7 @@ func TestCreateMovie(t *testing.T) {
 			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
 				idGen.On("Generate").Return("test-id-123")
 				timeProv.On("Now").Return(now)
+				repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
 				repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
 			},
 			expectedMovie: createTestMovie(),
@@ -355,6 +373,31 @@ func TestCreateMovie(t *testing.T) {
 				timeProv.AssertExpectations(t)
 			},
 		},
+		{
+			name: "should fail if unsupported currency",
+			req: movies.CreateMovieRequest{
+				Title:        "Test Movie",
+				Description:  "Test Description",
+				ReleaseYear:  2023,
+				Genre:        "Action",
+				Director:     "Test Director",
+				DurationMins: 120,
+				Language:     "English",
+				Country:      "USA",
+				Budget:       int64Ptr(1000000),
+				Currency:     "DOUBLOONS",
+			},
+			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
+				idGen.On("Generate").Return("test-id-123")
+				timeProv.On("Now").Return(now)
+			},
+			expectedError: &appErrors.AppError{},
+			expectedCalls: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
+				repo.AssertNotCalled(t, "Save")
+				idGen.AssertExpectations(t)
+				timeProv.AssertExpectations(t)
+			},
+		},
 		{
 			name: "should fail if invalid rating",
 			req: movies.CreateMovieRequest{
@@ -371,6 +414,7 @@ func TestCreateMovie(t *testing.T) {
 			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
 				idGen.On("Generate").Return("test-id-123")
 				timeProv.On("Now").Return(now)
+				repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
 				repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
 			},
 			expectedMovie: createTestMovie(),
@@ -395,6 +439,7 @@ func TestCreateMovie(t *testing.T) {
 			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
 				idGen.On("Generate").Return("test-id-123")
 				timeProv.On("Now").Return(now)
+				repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
 				repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, errors.New("movie with ID already exists"))
 			},
 			expectedError: &appErrors.AppError{},
@@ -419,6 +464,7 @@ func TestCreateMovie(t *testing.T) {
 			mockSetup: func(repo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
 				idGen.On("Generate").Return("test-id-123")
 				timeProv.On("Now").Return(now)
+				repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
 				repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, errors.New("database error"))
 			},
 			expectedError: &appErrors.AppError{},
@@ -619,14 +665,46 @@ func TestGetMovieByID(t *testing.T) {
 	}
 }
 
+func TestGetMovieByID_Logging(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := new(MockMovieRepository)
+	repo.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("not found"))
+	repo.On("GetByID", ctx, movies.MovieID("broken")).Return(nil, errors.New("database error"))
+	service := NewMovieService(repo, new(MockIDGenerator), new(MockTimeProvider), logger)
+
+	_, err := service.GetMovieByID(ctx, "missing")
+	require.Error(t, err)
+	_, err = service.GetMovieByID(ctx, "broken")
+	require.Error(t, err)
+
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 3)
+	assert.Equal(t, "INFO", records[0]["level"], "a missing movie is the client's error")
+	assert.Equal(t, "movie.get", records[0][logging.OperationKey])
+	assert.Equal(t, "missing", records[0]["movie_id"])
+	assert.Equal(t, "Movie not found", records[0][logging.ErrorKey])
+	assert.Equal(t, "Failed to get movie", records[1]["msg"], "the cause of an internal error is logged")
+	assert.Equal(t, "database error", records[1]["error"])
+	assert.Equal(t, "broken", records[1]["movie_id"])
+	assert.Equal(t, "ERROR", records[2]["level"])
+	assert.Equal(t, "movie.get", records[2][logging.OperationKey])
+	assert.Contains(t, records[2], logging.DurationKey)
+}
+
 func TestSearchMovies(t *testing.T) {
 	ctx := context.Background()
-	currentTime := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name           string
 		req            movies.SearchMoviesRequest
-		mockSetup      func(*MockMovieRepository, *MockTimeProvider)
+		mockSetup      func(*MockMovieRepository)
 		expectedMovies []*movies.Movie
 		expectedCount  int64
 		expectedError  error
@@ -640,119 +718,191 @@ func TestSearchMovies(t *testing.T) {
 				SortBy: "title",
 				Order:  "asc",
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				repo.On("SearchByTitle", ctx, "Test", mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{Query: "Test", BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
 			},
 			expectedMovies: []*movies.Movie{createTestMovie()},
 			expectedCount:  1,
 			expectedError:  nil,
 		},
 		{
-			name: "should search movies by genre",
+			name: "should combine all criteria in a single search",
+			req: movies.SearchMoviesRequest{
+				Query:       "Test",
+				Genre:       "Action",
+				Director:    "Test Director",
+				MinYear:     intPtr(2020),
+				MaxYear:     intPtr(2024),
+				Language:    "English",
+				Country:     "USA",
+				MinDuration: intPtr(90),
+				MinRating:   floatPtr(3.5),
+				Limit:       10,
+				Offset:      0,
+				SortBy:      "title",
+				Order:       "asc",
+			},
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{
+					Query:               "Test",
+					Genre:               "Action",
+					Director:            "Test Director",
+					MinYear:             intPtr(2020),
+					MaxYear:             intPtr(2024),
+					Language:            "English",
+					Country:             "USA",
+					MinDuration:         intPtr(90),
+					MinBayesianRating:   floatPtr(3.5),
+					BayesianConfidenceK: rating.DefaultBayesianConfidenceK,
+				}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+			},
+			expectedMovies: []*movies.Movie{createTestMovie()},
+			expectedCount:  1,
+			expectedError:  nil,
+		},
+		{
+			name: "should return the count matching the filters rather than all movies",
 			req: movies.SearchMoviesRequest{
 				Genre:  "Action",
-				Limit:  10,
+				Limit:  1,
 				Offset: 0,
 				SortBy: "title",
 				Order:  "asc",
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				repo.On("GetByGenre", ctx, "Action", mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{Genre: "Action", BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(7), nil)
 			},
 			expectedMovies: []*movies.Movie{createTestMovie()},
-			expectedCount:  1,
+			expectedCount:  7,
 			expectedError:  nil,
 		},
 		{
-			name: "should search movies by director",
+			name: "should return all movies when no search criteria provided",
 			req: movies.SearchMoviesRequest{
-				Director: "Test Director",
-				Limit:    10,
-				Offset:   0,
-				SortBy:   "title",
-				Order:    "asc",
+				Limit:  10,
+				Offset: 0,
+				SortBy: "title",
+				Order:  "asc",
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				repo.On("GetByDirector", ctx, "Test Director", mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
 			},
 			expectedMovies: []*movies.Movie{createTestMovie()},
 			expectedCount:  1,
 			expectedError:  nil,
 		},
 		{
-			name: "should search movies by year range",
+			name: "should keep movies certified at most the maximum in the territory",
 			req: movies.SearchMoviesRequest{
-				MinYear: intPtr(2020),
-				MaxYear: intPtr(2024),
-				Limit:   10,
-				Offset:  0,
-				SortBy:  "title",
-				Order:   "asc",
-			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				timeProv.On("Now").Return(currentTime)
-				repo.On("GetByYearRange", ctx, 2020, 2024, mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+				MaxCertification: "12a",
+				Territory:        "gb",
+				Limit:            10,
+				SortBy:           "title",
+				Order:            "asc",
+			},
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{
+					CertificationTerritory: "GB",
+					Certifications:         []string{"U", "PG", "12A"},
+					BayesianConfidenceK:    rating.DefaultBayesianConfidenceK,
+				}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
 			},
 			expectedMovies: []*movies.Movie{createTestMovie()},
 			expectedCount:  1,
 			expectedError:  nil,
 		},
 		{
-			name: "should search movies by year range with only min year",
+			name: "should keep movies offered on the provider in the region",
 			req: movies.SearchMoviesRequest{
-				MinYear: intPtr(2020),
-				Limit:   10,
-				Offset:  0,
-				SortBy:  "title",
-				Order:   "asc",
+				Provider: "Netflix",
+				Region:   "de",
+				Limit:    10,
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				timeProv.On("Now").Return(currentTime)
-				expectedMaxYear := 2024 + movies.MaxFutureYears
-				repo.On("GetByYearRange", ctx, 2020, expectedMaxYear, mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{
+					Provider:            "netflix",
+					ProviderRegion:      "DE",
+					BayesianConfidenceK: rating.DefaultBayesianConfidenceK,
+				}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
 			},
 			expectedMovies: []*movies.Movie{createTestMovie()},
 			expectedCount:  1,
 			expectedError:  nil,
 		},
 		{
-			name: "should return all movies when no search criteria provided",
+			name: "should reject a region without a provider",
 			req: movies.SearchMoviesRequest{
+				Region: "DE",
+				Limit:  10,
+			},
+			mockSetup:      func(repo *MockMovieRepository) {},
+			expectedMovies: nil,
+			expectedCount:  0,
+			expectedError:  &appErrors.AppError{},
+		},
+		{
+			name: "should reject a certification outside the territory's rating board",
+			req: movies.SearchMoviesRequest{
+				MaxCertification: "12A",
+				Limit:            10,
+			},
+			mockSetup:      func(repo *MockMovieRepository) {},
+			expectedMovies: nil,
+			expectedCount:  0,
+			expectedError:  &appErrors.AppError{},
+		},
+		{
+			name: "should return error if search fails",
+			req: movies.SearchMoviesRequest{
+				Query:  "Test",
 				Limit:  10,
 				Offset: 0,
 				SortBy: "title",
 				Order:  "asc",
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				expectedMovies := []*movies.Movie{createTestMovie()}
-				repo.On("GetAll", ctx, mock.Anything).Return(expectedMovies, nil)
-				repo.On("Count", ctx).Return(int64(1), nil)
+			mockSetup: func(repo *MockMovieRepository) {
+				repo.On("Search", ctx, mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("search error"))
 			},
-			expectedMovies: []*movies.Movie{createTestMovie()},
-			expectedCount:  1,
+			expectedMovies: nil,
+			expectedCount:  0,
+			expectedError:  &appErrors.AppError{},
+		},
+		{
+			name: "should count separately when the page is past the end of the results",
+			req: movies.SearchMoviesRequest{
+				Genre:  "Action",
+				Limit:  10,
+				Offset: 50,
+				SortBy: "title",
+				Order:  "asc",
+			},
+			mockSetup: func(repo *MockMovieRepository) {
+				filter := movies.SearchFilter{Genre: "Action", BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+				repo.On("Search", ctx, filter, mock.Anything).Return([]*movies.Movie{}, int64(0), nil)
+				repo.On("CountSearch", ctx, filter).Return(int64(12), nil)
+			},
+			expectedMovies: []*movies.Movie{},
+			expectedCount:  12,
 			expectedError:  nil,
 		},
 		{
-			name: "should return error if search fails",
+			name: "should return error if count fails",
 			req: movies.SearchMoviesRequest{
 				Query:  "Test",
 				Limit:  10,
-				Offset: 0,
+				Offset: 20,
 				SortBy: "title",
 				Order:  "asc",
 			},
-			mockSetup: func(repo *MockMovieRepository, timeProv *MockTimeProvider) {
-				repo.On("SearchByTitle", ctx, "Test", mock.Anything).Return(nil, errors.New("search error"))
+			mockSetup: func(repo *MockMovieRepository) {
+				repo.On("Search", ctx, mock.Anything, mock.Anything).Return([]*movies.Movie{}, int64(0), nil)
+				repo.On("CountSearch", ctx, mock.Anything).Return(int64(0), errors.New("count error"))
 			},
 			expectedMovies: nil,
 			expectedCount:  0,
@@ -768,7 +918,7 @@ func TestSearchMovies(t *testing.T) {
 			logger := slog.Default()
 
 			service := NewMovieService(mockRepo, mockIDGen, mockTimeProvider, logger)
-			tt.mockSetup(mockRepo, mockTimeProvider)
+			tt.mockSetup(mockRepo)
 
 			result, count, err := service.SearchMovies(ctx, tt.req)
 
@@ -785,7 +935,1415 @@ func TestSearchMovies(t *testing.T) {
 			}
 
 			mockRepo.AssertExpectations(t)
-			mockTimeProvider.AssertExpectations(t)
 		})
 	}
 }
+
+func TestGetMovieDetails(t *testing.T) {
+	ctx := context.Background()
+	userRating := &rating.Rating{
+		ID:      "rating-1",
+		UserID:  "user-1",
+		MovieID: "test-id-123",
+		Score:   4,
+	}
+	stats := &rating.MovieRatingStats{
+		MovieID:      "test-id-123",
+		AverageScore: 4.2,
+		TotalRatings: 10,
+		ScoreCount:   map[int]int64{4: 8, 5: 2},
+	}
+
+	tests := []struct {
+		name              string
+		req               MovieDetailsRequest
+		mockSetup         func(*MockMovieRepository, *MockRatingRepository)
+		expectedStats     *rating.MovieRatingStats
+		expectedRating    *rating.Rating
+		expectedErrorCode string
+	}{
+		{
+			name: "should embed stats and user rating",
+			req:  MovieDetailsRequest{MovieID: "test-id-123", IncludeStats: true, UserID: "user-1"},
+			mockSetup: func(movieRepo *MockMovieRepository, ratingRepo *MockRatingRepository) {
+				movieRepo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+				ratingRepo.On("GetMovieStats", ctx, movies.MovieID("test-id-123")).Return(stats, nil)
+				ratingRepo.On("GetByUserAndMovie", ctx, users.UserID("user-1"), movies.MovieID("test-id-123")).Return(userRating, nil)
+			},
+			expectedStats:  stats,
+			expectedRating: userRating,
+		},
+		{
+			name: "should leave user rating empty when the user has not rated the movie",
+			req:  MovieDetailsRequest{MovieID: "test-id-123", UserID: "user-1"},
+			mockSetup: func(movieRepo *MockMovieRepository, ratingRepo *MockRatingRepository) {
+				movieRepo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+				ratingRepo.On("GetByUserAndMovie", ctx, users.UserID("user-1"), movies.MovieID("test-id-123")).
+					Return(nil, errors.New("rating not found for user user-1 and movie test-id-123"))
+			},
+		},
+		{
+			name: "should return not found when the movie does not exist",
+			req:  MovieDetailsRequest{MovieID: "missing", IncludeStats: true},
+			mockSetup: func(movieRepo *MockMovieRepository, ratingRepo *MockRatingRepository) {
+				movieRepo.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("movie with ID missing not found"))
+				ratingRepo.On("GetMovieStats", ctx, movies.MovieID("missing")).Return(&rating.MovieRatingStats{}, nil)
+			},
+			expectedErrorCode: string(appErrors.CodeNotFound),
+		},
+		{
+			name: "should fail when stats cannot be loaded",
+			req:  MovieDetailsRequest{MovieID: "test-id-123", IncludeStats: true},
+			mockSetup: func(movieRepo *MockMovieRepository, ratingRepo *MockRatingRepository) {
+				movieRepo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+				ratingRepo.On("GetMovieStats", ctx, movies.MovieID("test-id-123")).Return(nil, errors.New("database error"))
+			},
+			expectedErrorCode: string(appErrors.CodeInternal),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockMovieRepository)
+			mockRatingRepo := new(MockRatingRepository)
+			tt.mockSetup(mockRepo, mockRatingRepo)
+
+			service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithRatingRepository(mockRatingRepo))
+			details, err := service.GetMovieDetails(ctx, tt.req)
+
+			if tt.expectedErrorCode != "" {
+				var appErr *appErrors.AppError
+				assert.ErrorAs(t, err, &appErr)
+				assert.Equal(t, tt.expectedErrorCode, appErr.Code)
+				assert.Nil(t, details)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, createTestMovie(), details.Movie)
+				assert.Equal(t, tt.expectedStats, details.Stats)
+				assert.Equal(t, tt.expectedRating, details.UserRating)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockRatingRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// minRatingsDisplay withholds the stats of movies with fewer ratings
+type minRatingsDisplay int64
+
+func (d minRatingsDisplay) WithholdStats(stats *rating.MovieRatingStats) bool {
+	return stats.Withhold(int64(d))
+}
+
+func TestGetMovieDetails_WithheldStats(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockMovieRepository)
+	mockRatingRepo := new(MockRatingRepository)
+	mockRepo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+	mockRatingRepo.On("GetMovieStats", ctx, movies.MovieID("test-id-123")).
+		Return(&rating.MovieRatingStats{MovieID: "test-id-123", AverageScore: 4.5, TotalRatings: 2, ScoreCount: map[int]int64{4: 1, 5: 1}}, nil)
+
+	service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+		WithRatingRepository(mockRatingRepo),
+		WithStatsDisplay(minRatingsDisplay(5)),
+	)
+	details, err := service.GetMovieDetails(ctx, MovieDetailsRequest{MovieID: "test-id-123", IncludeStats: true})
+
+	require.NoError(t, err)
+	assert.True(t, details.Stats.Withheld)
+	assert.Zero(t, details.Stats.AverageScore)
+	assert.Equal(t, int64(2), details.Stats.TotalRatings)
+}
+
+func TestGetMovieDetails_Providers(t *testing.T) {
+	ctx := context.Background()
+	offers := []*movies.Offer{{MovieID: "test-id-123", Region: "DE", ProviderID: "netflix", ProviderName: "Netflix", Type: movies.OfferSubscription}}
+
+	t.Run("should include the offers in the region", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockProviders := new(MockProviderRepository)
+		mockRepo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+		mockProviders.On("GetOffers", ctx, movies.MovieID("test-id-123"), "DE").Return(offers, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithProviderRepository(mockProviders))
+		details, err := service.GetMovieDetails(ctx, MovieDetailsRequest{MovieID: "test-id-123", IncludeProviders: true, Region: "de"})
+
+		require.NoError(t, err)
+		assert.Equal(t, offers, details.Offers)
+		mockProviders.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid region", func(t *testing.T) {
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithProviderRepository(new(MockProviderRepository)))
+		_, err := service.GetMovieDetails(ctx, MovieDetailsRequest{MovieID: "test-id-123", IncludeProviders: true, Region: "Germany"})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+}
+
+func TestGetSearchFacets(t *testing.T) {
+	ctx := context.Background()
+	req := movies.SearchMoviesRequest{Genre: "Horror", Limit: 10}
+	filter := movies.SearchFilter{Genre: "Horror", BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+	cacheKey := "movie_facets:|horror||||||||||||"
+	facets := &movies.SearchFacets{
+		Genres:  []movies.FacetBucket{{Value: "Horror", Count: 3}},
+		Decades: []movies.FacetBucket{{Value: "1980s", Count: 2}, {Value: "1970s", Count: 1}},
+	}
+
+	t.Run("should compute and cache facets on a cache miss", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(errors.New("cache miss"))
+		mockRepo.On("GetSearchFacets", ctx, filter).Return(facets, nil)
+		mockCache.On("Set", ctx, cacheKey, facets, cache.MovieFacetsTTL).Return(nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithCache(mockCache))
+		result, err := service.GetSearchFacets(ctx, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, facets, result)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should serve facets from cache", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithCache(mockCache))
+		_, err := service.GetSearchFacets(ctx, req)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "GetSearchFacets", mock.Anything, mock.Anything)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should return an internal error when the query fails", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockRepo.On("GetSearchFacets", ctx, filter).Return(nil, errors.New("database error"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		result, err := service.GetSearchFacets(ctx, req)
+
+		assert.Error(t, err)
+		assert.IsType(t, &appErrors.AppError{}, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestGetAggregateStats(t *testing.T) {
+	ctx := context.Background()
+	cacheKey := "aggregate_stats:director:greta gerwig:bayesian"
+	ranking := movies.Ranking{Metric: movies.RankByBayesian, ConfidenceK: rating.DefaultBayesianConfidenceK}
+	stats := &movies.AggregateStats{Kind: movies.AggregateDirector, Name: "Greta Gerwig", MovieCount: 3}
+
+	t.Run("should compute and cache stats on a cache miss", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(errors.New("cache miss"))
+		mockAggregates.On("GetAggregateStats", ctx, movies.AggregateDirector, "Greta Gerwig", ranking).Return(stats, nil)
+		mockCache.On("Set", ctx, cacheKey, stats, cache.AggregateStatsTTL).Return(nil)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates), WithCache(mockCache))
+		result, err := service.GetAggregateStats(ctx, movies.AggregateDirector, " Greta Gerwig ")
+
+		assert.NoError(t, err)
+		assert.Equal(t, stats, result)
+		mockAggregates.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should serve stats from cache", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(nil)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates), WithCache(mockCache))
+		_, err := service.GetAggregateStats(ctx, movies.AggregateDirector, "GRETA GERWIG")
+
+		assert.NoError(t, err)
+		mockAggregates.AssertNotCalled(t, "GetAggregateStats", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should map errors", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		mockAggregates.On("GetAggregateStats", ctx, movies.AggregateGenre, "Western", ranking).
+			Return(nil, errors.New("genre Western not found"))
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates))
+
+		_, err := service.GetAggregateStats(ctx, movies.AggregateGenre, "Western")
+		var appErr *appErrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+
+		_, err = service.GetAggregateStats(ctx, movies.AggregateGenre, "  ")
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+}
+
+func TestGetDecades(t *testing.T) {
+	ctx := context.Background()
+	decades := []*movies.DecadeSummary{
+		{Decade: 1990, MovieCount: 4, TopRated: []*movies.RatedTitle{{MovieID: "m1", Title: "Heat", ReleaseYear: 1995}}},
+	}
+
+	t.Run("should compute and cache decades on a cache miss", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "decades:3:bayesian", mock.Anything).Return(errors.New("cache miss"))
+		mockAggregates.On("GetDecades", ctx, movies.Ranking{Metric: movies.RankByBayesian, ConfidenceK: rating.DefaultBayesianConfidenceK}, 3).Return(decades, nil)
+		mockCache.On("Set", ctx, "decades:3:bayesian", decades, cache.DecadesTTL).Return(nil)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates), WithCache(mockCache))
+		result, err := service.GetDecades(ctx, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, decades, result)
+		mockAggregates.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should rank by the configured metric", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "decades:3:wilson", mock.Anything).Return(errors.New("cache miss"))
+		mockAggregates.On("GetDecades", ctx, movies.Ranking{Metric: movies.RankByWilson, ConfidenceK: rating.DefaultBayesianConfidenceK}, 3).Return(decades, nil)
+		mockCache.On("Set", ctx, "decades:3:wilson", decades, cache.DecadesTTL).Return(nil)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates), WithCache(mockCache), WithRankingMetric(movies.RankByWilson))
+		_, err := service.GetDecades(ctx, 3)
+
+		assert.NoError(t, err)
+		mockAggregates.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should reject too many top movies", func(t *testing.T) {
+		mockAggregates := new(MockAggregateRepository)
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithAggregateRepository(mockAggregates))
+
+		_, err := service.GetDecades(ctx, MaxDecadeTop+1)
+		var appErr *appErrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		mockAggregates.AssertNotCalled(t, "GetDecades", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	ctx := context.Background()
+	cacheKey := "movie_suggest:the godf:10"
+	suggestions := []*movies.Suggestion{
+		{Kind: movies.SuggestionTitle, Text: "The Godfather", MovieID: "movie-1"},
+		{Kind: movies.SuggestionDirector, Text: "Francis Ford Coppola"},
+	}
+
+	t.Run("should query and cache suggestions on a cache miss", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(errors.New("cache miss"))
+		mockRepo.On("Suggest", ctx, "The Godf", 10).Return(suggestions, nil)
+		mockCache.On("Set", ctx, cacheKey, suggestions, cache.MovieSuggestTTL).Return(nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithCache(mockCache))
+		result, err := service.Suggest(ctx, "  The Godf ", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, suggestions, result)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should serve suggestions from cache", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, cacheKey, mock.Anything).Return(nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithCache(mockCache))
+		_, err := service.Suggest(ctx, "The Godf", 10)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "Suggest", mock.Anything, mock.Anything, mock.Anything)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should return an internal error when the query fails", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockRepo.On("Suggest", ctx, "The Godf", 10).Return(nil, errors.New("database error"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		result, err := service.Suggest(ctx, "The Godf", 10)
+
+		assert.Error(t, err)
+		assert.IsType(t, &appErrors.AppError{}, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestRandomMovies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should pick from the decade the user has not rated", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		filter := movies.SearchFilter{
+			Genre:               "Horror",
+			MinYear:             intPtr(1980),
+			MaxYear:             intPtr(1989),
+			MinBayesianRating:   floatPtr(4),
+			NotRatedBy:          "user-1",
+			BayesianConfidenceK: rating.DefaultBayesianConfidenceK,
+		}
+		mockRepo.On("Random", ctx, filter, 3).Return([]*movies.Movie{createTestMovie()}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		result, err := service.RandomMovies(ctx, RandomRequest{Genre: "Horror", Decade: intPtr(1980), MinRating: floatPtr(4), NotRatedBy: "user-1", Limit: 3})
+
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should default and cap the limit", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockRepo.On("Random", ctx, mock.Anything, DefaultRandomLimit).Return(nil, nil)
+		mockRepo.On("Random", ctx, mock.Anything, MaxRandomLimit).Return(nil, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		result, err := service.RandomMovies(ctx, RandomRequest{})
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+
+		_, err = service.RandomMovies(ctx, RandomRequest{Limit: 50})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject a year that does not start a decade", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		_, err := service.RandomMovies(ctx, RandomRequest{Decade: intPtr(1984)})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeBadRequest), appErr.Code)
+		mockRepo.AssertNotCalled(t, "Random", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLocalizeMovies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should apply the most preferred available translation", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockTranslations := new(MockTranslationRepository)
+		first := &movies.Movie{ID: "movie-1", Title: "The Lives of Others", Description: "Canonical"}
+		second := &movies.Movie{ID: "movie-2", Title: "Metropolis", Description: "Canonical"}
+
+		mockTranslations.On("FindTranslations", ctx, []movies.MovieID{"movie-1", "movie-2"}, []string{"de-AT", "de", "en"}).
+			Return([]*movies.Translation{
+				{MovieID: "movie-1", Locale: "en", Title: "English title"},
+				{MovieID: "movie-1", Locale: "de", Title: "Das Leben der Anderen", Description: "Lokalisiert"},
+				{MovieID: "movie-2", Locale: "en", Title: "Metropolis (EN)"},
+			}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithTranslationRepository(mockTranslations))
+		service.LocalizeMovies(ctx, []*movies.Movie{first, second}, []string{"de-AT", "en"})
+
+		assert.Equal(t, "Das Leben der Anderen", first.Title)
+		assert.Equal(t, "Lokalisiert", first.Description)
+		assert.Equal(t, "de", first.Locale)
+		assert.Equal(t, "Metropolis (EN)", second.Title)
+		assert.Equal(t, "Canonical", second.Description, "an empty translated description keeps the canonical one")
+		assert.Equal(t, "en", second.Locale)
+		mockTranslations.AssertExpectations(t)
+	})
+
+	t.Run("should keep canonical text in the movie's own language", func(t *testing.T) {
+		mockTranslations := new(MockTranslationRepository)
+		english := &movies.Movie{ID: "movie-1", Title: "The Third Man", Language: "English"}
+		german := &movies.Movie{ID: "movie-2", Title: "Das Boot", Language: "German"}
+		american := &movies.Movie{ID: "movie-3", Title: "Harry Potter and the Philosopher's Stone", Language: "en"}
+
+		mockTranslations.On("FindTranslations", ctx, []movies.MovieID{"movie-1", "movie-2", "movie-3"}, []string{"en-US", "en", "de"}).
+			Return([]*movies.Translation{
+				{MovieID: "movie-1", Locale: "de", Title: "Der dritte Mann"},
+				{MovieID: "movie-2", Locale: "en", Title: "The Boat"},
+				{MovieID: "movie-3", Locale: "en-US", Title: "Harry Potter and the Sorcerer's Stone"},
+			}, nil)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithTranslationRepository(mockTranslations))
+		service.LocalizeMovies(ctx, []*movies.Movie{english, german, american}, []string{"en-US", "en", "de"})
+
+		assert.Equal(t, "The Third Man", english.Title, "en comes before de")
+		assert.Empty(t, english.Locale)
+		assert.Equal(t, "The Boat", german.Title)
+		assert.Equal(t, "en", german.Locale)
+		assert.Equal(t, "Harry Potter and the Sorcerer's Stone", american.Title, "a regional translation still comes first")
+		assert.Equal(t, "en-US", american.Locale)
+		mockTranslations.AssertExpectations(t)
+	})
+
+	t.Run("should keep canonical text when the lookup fails", func(t *testing.T) {
+		mockTranslations := new(MockTranslationRepository)
+		movie := &movies.Movie{ID: "movie-1", Title: "Canonical"}
+		mockTranslations.On("FindTranslations", ctx, mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithTranslationRepository(mockTranslations))
+		service.LocalizeMovies(ctx, []*movies.Movie{movie}, []string{"fr"})
+
+		assert.Equal(t, "Canonical", movie.Title)
+		assert.Empty(t, movie.Locale)
+	})
+
+	t.Run("should do nothing without a translation repository", func(t *testing.T) {
+		movie := &movies.Movie{ID: "movie-1", Title: "Canonical"}
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		service.LocalizeMovies(ctx, []*movies.Movie{movie}, []string{"fr"})
+
+		assert.Equal(t, "Canonical", movie.Title)
+	})
+}
+
+func TestPutTranslation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := movies.TranslationRequest{Title: " Le Parrain ", Description: "Description"}
+
+	t.Run("should normalize the locale and save", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockTranslations := new(MockTranslationRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockTranslations.On("SaveTranslation", ctx, mock.MatchedBy(func(tr *movies.Translation) bool {
+			return tr.Locale == "fr-CA" && tr.Title == "Le Parrain" && tr.MovieID == "movie-1"
+		})).Return(&movies.Translation{MovieID: "movie-1", Locale: "fr-CA", Title: "Le Parrain"}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithTranslationRepository(mockTranslations))
+		result, err := service.PutTranslation(ctx, "movie-1", "fr_ca", req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "fr-CA", result.Locale)
+		mockTranslations.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid locale", func(t *testing.T) {
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), mockTime, slog.Default(), WithTranslationRepository(new(MockTranslationRepository)))
+		_, err := service.PutTranslation(ctx, "movie-1", "french", req)
+
+		var appErr *appErrors.AppError
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeBadRequest), appErr.Code)
+	})
+
+	t.Run("should return not found for a missing movie", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("missing")).Return(false, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithTranslationRepository(new(MockTranslationRepository)))
+		_, err := service.PutTranslation(ctx, "missing", "fr", req)
+
+		var appErr *appErrors.AppError
+		assert.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(appErrors.CodeNotFound), appErr.Code)
+	})
+}
+
+func TestDeleteTranslation(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockMovieRepository)
+	mockTranslations := new(MockTranslationRepository)
+	mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+	mockTranslations.On("DeleteTranslation", ctx, movies.MovieID("movie-1"), "de").Return(errors.New("translation de for movie movie-1 not found"))
+
+	service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithTranslationRepository(mockTranslations))
+	err := service.DeleteTranslation(ctx, "movie-1", "DE")
+
+	var appErr *appErrors.AppError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, string(appErrors.CodeNotFound), appErr.Code)
+	mockTranslations.AssertExpectations(t)
+}
+
+func TestPutRelease(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should normalize region and type and save", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockReleases := new(MockReleaseRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockReleases.On("SaveRelease", ctx, mock.MatchedBy(func(r *movies.Release) bool {
+			return r.Region == "DE" && r.Type == movies.ReleaseTheatrical && r.ReleaseDate.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+		})).Return(&movies.Release{MovieID: "movie-1", Region: "DE", Type: movies.ReleaseTheatrical}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithReleaseRepository(mockReleases))
+		result, err := service.PutRelease(ctx, "movie-1", "de", "Theatrical", movies.ReleaseRequest{ReleaseDate: "2024-03-01"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "DE", result.Region)
+		mockReleases.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid release", func(t *testing.T) {
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), mockTime, slog.Default(), WithReleaseRepository(new(MockReleaseRepository)))
+		_, err := service.PutRelease(ctx, "movie-1", "DE", "dvd", movies.ReleaseRequest{ReleaseDate: "2024-03-01"})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, movies.ErrInvalidReleaseType.Error(), appErr.Message)
+	})
+
+	t.Run("should return not found for a missing release", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockReleases := new(MockReleaseRepository)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockReleases.On("DeleteRelease", ctx, movies.MovieID("movie-1"), "US", movies.ReleaseStreaming).
+			Return(errors.New("streaming release in US for movie movie-1 not found"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithReleaseRepository(mockReleases))
+		err := service.DeleteRelease(ctx, "movie-1", "us", "streaming")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestListUpcoming(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 10, 18, 30, 0, 0, time.UTC)
+
+	setup := func() (*MockReleaseRepository, Service) {
+		mockReleases := new(MockReleaseRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		return mockReleases, NewMovieService(new(MockMovieRepository), new(MockIDGenerator), mockTime, slog.Default(), WithReleaseRepository(mockReleases))
+	}
+
+	t.Run("should default to the next 90 days from today", func(t *testing.T) {
+		mockReleases, service := setup()
+		today := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+		mockReleases.On("ListUpcoming", ctx, movies.UpcomingFilter{
+			Region: "DE", From: today, To: today.AddDate(0, 0, DefaultUpcomingDays), Limit: 20,
+		}).Return([]*movies.UpcomingRelease{}, int64(0), nil)
+
+		_, _, err := service.ListUpcoming(ctx, UpcomingRequest{Region: "de", Limit: 20})
+
+		require.NoError(t, err)
+		mockReleases.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid windows", func(t *testing.T) {
+		for _, req := range []UpcomingRequest{
+			{Region: "DEU", Limit: 20},
+			{Type: "dvd", Limit: 20},
+			{From: "2024-06-01", To: "2024-05-01", Limit: 20},
+			{From: "2024-01-01", To: "2025-06-01", Limit: 20},
+			{From: "10.05.2024", Limit: 20},
+		} {
+			_, service := setup()
+			_, _, err := service.ListUpcoming(ctx, req)
+
+			var appErr *appErrors.AppError
+			require.True(t, errors.As(err, &appErr), req)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode, req)
+		}
+	})
+}
+
+func TestPutOffer(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should normalize region, provider and type and save", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockProviders := new(MockProviderRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockProviders.On("SaveOffer", ctx, mock.MatchedBy(func(o *movies.Offer) bool {
+			return o.Region == "DE" && o.ProviderID == "netflix" && o.Type == movies.OfferSubscription && o.URL == nil
+		})).Return(&movies.Offer{MovieID: "movie-1", Region: "DE", ProviderID: "netflix", ProviderName: "Netflix", Type: movies.OfferSubscription}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithProviderRepository(mockProviders))
+		result, err := service.PutOffer(ctx, "movie-1", "de", "Netflix", "subscription", movies.OfferRequest{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Netflix", result.ProviderName)
+		mockProviders.AssertExpectations(t)
+	})
+
+	t.Run("should reject an invalid offer", func(t *testing.T) {
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), mockTime, slog.Default(), WithProviderRepository(new(MockProviderRepository)))
+		_, err := service.PutOffer(ctx, "movie-1", "DE", "netflix", "stream", movies.OfferRequest{})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, movies.ErrInvalidOfferType.Error(), appErr.Message)
+	})
+
+	t.Run("should return not found for an unknown provider", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockProviders := new(MockProviderRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockProviders.On("SaveOffer", ctx, mock.Anything).Return(nil, errors.New("provider myspace not found"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithProviderRepository(mockProviders))
+		_, err := service.PutOffer(ctx, "movie-1", "DE", "myspace", "rent", movies.OfferRequest{})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, "Provider not found", appErr.Message)
+	})
+
+	t.Run("should return not found for a missing offer", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockProviders := new(MockProviderRepository)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockProviders.On("DeleteOffer", ctx, movies.MovieID("movie-1"), "US", "max", movies.OfferBuy).
+			Return(errors.New("buy offer on max in US for movie movie-1 not found"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithProviderRepository(mockProviders))
+		err := service.DeleteOffer(ctx, "movie-1", "us", "max", "buy")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+
+	t.Run("should fail when providers are not configured", func(t *testing.T) {
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+		_, err := service.ListProviders(ctx)
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+	})
+}
+
+func TestCreateMovie_CreditsDirector(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := movies.CreateMovieRequest{
+		Title:        "Test Movie",
+		ReleaseYear:  2023,
+		Genre:        "Action",
+		Director:     "Test Director",
+		DurationMins: 120,
+		Language:     "English",
+		Country:      "USA",
+	}
+
+	setup := func() (*MockMovieRepository, *MockPeopleRepository, *MockIDGenerator, *MockTimeProvider) {
+		repo := new(MockMovieRepository)
+		peopleRepo := new(MockPeopleRepository)
+		idGen := new(MockIDGenerator)
+		timeProv := new(MockTimeProvider)
+		idGen.On("Generate").Return("test-id-123")
+		timeProv.On("Now").Return(now)
+		repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
+		repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
+		return repo, peopleRepo, idGen, timeProv
+	}
+
+	t.Run("should reuse an existing person", func(t *testing.T) {
+		repo, peopleRepo, idGen, timeProv := setup()
+		peopleRepo.On("GetByName", ctx, "Test Director").Return(&people.Person{ID: "person-1", Name: "Test Director"}, nil)
+		peopleRepo.On("SaveCredit", ctx, mock.MatchedBy(func(c *people.Credit) bool {
+			return c.MovieID == "test-id-123" && c.PersonID == "person-1" && c.Role == people.RoleDirector
+		})).Return(&people.Credit{}, nil)
+
+		service := NewMovieService(repo, idGen, timeProv, slog.Default(), WithPeopleRepository(peopleRepo))
+		_, err := service.CreateMovie(ctx, req)
+
+		assert.NoError(t, err)
+		peopleRepo.AssertExpectations(t)
+		peopleRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should create the director when unknown", func(t *testing.T) {
+		repo, peopleRepo, idGen, timeProv := setup()
+		peopleRepo.On("GetByName", ctx, "Test Director").Return(nil, errors.New("person Test Director not found"))
+		peopleRepo.On("Save", ctx, mock.MatchedBy(func(p *people.Person) bool {
+			return p.Name == "Test Director"
+		})).Return(&people.Person{ID: "person-2", Name: "Test Director"}, nil)
+		peopleRepo.On("SaveCredit", ctx, mock.MatchedBy(func(c *people.Credit) bool {
+			return c.PersonID == "person-2"
+		})).Return(&people.Credit{}, nil)
+
+		service := NewMovieService(repo, idGen, timeProv, slog.Default(), WithPeopleRepository(peopleRepo))
+		_, err := service.CreateMovie(ctx, req)
+
+		assert.NoError(t, err)
+		peopleRepo.AssertExpectations(t)
+	})
+
+	t.Run("should still create the movie when crediting fails", func(t *testing.T) {
+		repo, peopleRepo, idGen, timeProv := setup()
+		peopleRepo.On("GetByName", ctx, "Test Director").Return(nil, errors.New("connection refused"))
+
+		service := NewMovieService(repo, idGen, timeProv, slog.Default(), WithPeopleRepository(peopleRepo))
+		movie, err := service.CreateMovie(ctx, req)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, movie)
+		peopleRepo.AssertNotCalled(t, "SaveCredit", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateMovie_DuplicateDetection(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := movies.CreateMovieRequest{
+		Title:        "Se7en",
+		ReleaseYear:  1995,
+		Genre:        "Thriller",
+		Director:     "David Fincher",
+		DurationMins: 127,
+		Language:     "English",
+		Country:      "USA",
+	}
+	existing := &movies.Movie{ID: "movie-1", Title: "SE7EN", ReleaseYear: 1995}
+
+	setup := func() (*MockMovieRepository, Service) {
+		repo := new(MockMovieRepository)
+		idGen := new(MockIDGenerator)
+		timeProv := new(MockTimeProvider)
+		idGen.On("Generate").Return("test-id-123")
+		timeProv.On("Now").Return(now)
+		return repo, NewMovieService(repo, idGen, timeProv, slog.Default())
+	}
+
+	t.Run("should reject a potential duplicate with its candidates", func(t *testing.T) {
+		repo, service := setup()
+		repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return([]*movies.Movie{existing}, nil)
+
+		_, err := service.CreateMovie(ctx, req)
+
+		var dupErr *DuplicateMovieError
+		require.True(t, errors.As(err, &dupErr))
+		require.Len(t, dupErr.Candidates, 1)
+		assert.Equal(t, movies.DuplicateTitleYear, dupErr.Candidates[0].Reason)
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip the check when duplicates are allowed", func(t *testing.T) {
+		repo, service := setup()
+		repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
+
+		override := req
+		override.AllowDuplicate = true
+		_, err := service.CreateMovie(ctx, override)
+
+		require.NoError(t, err)
+		repo.AssertNotCalled(t, "FindPotentialDuplicates", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMergeMovies(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func() (*MockMergeRepository, Service) {
+		mergeRepo := new(MockMergeRepository)
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+		return mergeRepo, NewMovieService(new(MockMovieRepository), new(MockIDGenerator), timeProv, slog.Default(), WithMergeRepository(mergeRepo))
+	}
+
+	t.Run("should merge and return the audit record", func(t *testing.T) {
+		mergeRepo, service := setup()
+		mergeRepo.On("Merge", ctx, movies.MovieID("source"), movies.MovieID("target"), "admin-1", now).
+			Return(&movies.MergeRecord{ID: 1, SourceID: "source", TargetID: "target", RatingsMoved: 3}, nil)
+
+		record, err := service.MergeMovies(ctx, "source", "target", "admin-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), record.RatingsMoved)
+	})
+
+	t.Run("should reject merging a movie into itself", func(t *testing.T) {
+		mergeRepo, service := setup()
+
+		_, err := service.MergeMovies(ctx, "source", "source", "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		mergeRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return not found for an unknown movie", func(t *testing.T) {
+		mergeRepo, service := setup()
+		mergeRepo.On("Merge", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, errors.New("movie source or target not found"))
+
+		_, err := service.MergeMovies(ctx, "source", "target", "admin-1")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestPatchMovie(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func() (*MockMovieRepository, Service) {
+		repo := new(MockMovieRepository)
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+		return repo, NewMovieService(repo, new(MockIDGenerator), timeProv, slog.Default())
+	}
+
+	t.Run("should change only the patched fields and clear nulled ones", func(t *testing.T) {
+		repo, service := setup()
+		existing := createTestMovie()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(existing, nil)
+		// The patch is applied to the loaded movie, so Update echoes it back
+		repo.On("Update", ctx, existing).Return(existing, nil)
+
+		movie, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{
+			Title:     mergepatch.Value("  Renamed Movie "),
+			PosterURL: mergepatch.Null[string](),
+			Budget:    mergepatch.Null[int64](),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed Movie", movie.Title)
+		assert.Nil(t, movie.PosterURL)
+		assert.Nil(t, movie.Budget)
+		assert.Equal(t, int64(500000000), *movie.Revenue)
+		assert.Equal(t, "Test Director", movie.Director)
+		assert.Equal(t, now, movie.UpdatedAt)
+	})
+
+	t.Run("should reject null for a required field", func(t *testing.T) {
+		repo, service := setup()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+
+		_, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{Title: mergepatch.Null[string]()})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		assert.Contains(t, appErr.Message, "title cannot be null")
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should relabel the currency without converting amounts", func(t *testing.T) {
+		repo, service := setup()
+		existing := createTestMovie()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(existing, nil)
+		repo.On("Update", ctx, existing).Return(existing, nil)
+
+		movie, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{Currency: mergepatch.Value(" eur")})
+
+		require.NoError(t, err)
+		assert.Equal(t, money.Currency("EUR"), movie.Currency)
+		assert.Equal(t, &money.Money{Amount: 100000000, Currency: "EUR"}, movie.BudgetMoney())
+	})
+
+	t.Run("should reject an unsupported or null currency", func(t *testing.T) {
+		for _, currency := range []mergepatch.Field[string]{mergepatch.Value("EURO"), mergepatch.Null[string]()} {
+			repo, service := setup()
+			repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+
+			_, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{Currency: currency})
+
+			var appErr *appErrors.AppError
+			require.True(t, errors.As(err, &appErr))
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+			repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should validate the patched movie", func(t *testing.T) {
+		repo, service := setup()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+
+		_, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{DurationMins: mergepatch.Value(0)})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, movies.ErrInvalidDuration.Error(), appErr.Message)
+	})
+
+	t.Run("should return not found for an unknown movie", func(t *testing.T) {
+		repo, service := setup()
+		repo.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("movie with ID missing not found"))
+
+		_, err := service.PatchMovie(ctx, "missing", PatchMovieRequest{Title: mergepatch.Value("x")})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestMovieSlugs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func() (*MockMovieRepository, *MockSlugRepository, Service) {
+		repo := new(MockMovieRepository)
+		slugRepo := new(MockSlugRepository)
+		idGen := new(MockIDGenerator)
+		idGen.On("Generate").Return("test-id-123")
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+		return repo, slugRepo, NewMovieService(repo, idGen, timeProv, slog.Default(), WithSlugRepository(slugRepo))
+	}
+
+	t.Run("should give a new movie a slug", func(t *testing.T) {
+		repo, slugRepo, service := setup()
+		repo.On("FindPotentialDuplicates", ctx, mock.AnythingOfType("*movies.Movie")).Return(nil, nil)
+		repo.On("Save", ctx, mock.AnythingOfType("*movies.Movie")).Return(createTestMovie(), nil)
+		slugRepo.On("AssignSlug", ctx, movies.MovieID("test-id-123"), "test-movie-2023", now).Return("test-movie-2023-2", nil)
+
+		movie, err := service.CreateMovie(ctx, movies.CreateMovieRequest{
+			Title: "Test Movie", ReleaseYear: 2023, Genre: "Action", Director: "Test Director",
+			DurationMins: 120, Language: "English", Country: "USA",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "test-movie-2023-2", movie.Slug)
+	})
+
+	t.Run("should reassign the slug when a movie is renamed", func(t *testing.T) {
+		repo, slugRepo, service := setup()
+		existing := createTestMovie()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(existing, nil)
+		repo.On("Update", ctx, existing).Return(existing, nil)
+		slugRepo.On("AssignSlug", ctx, movies.MovieID("test-id-123"), "the-sequel-2023", now).Return("the-sequel-2023", nil)
+
+		movie, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{Title: mergepatch.Value("The Sequel")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "the-sequel-2023", movie.Slug)
+	})
+
+	t.Run("should still save the movie when the slug fails", func(t *testing.T) {
+		repo, slugRepo, service := setup()
+		existing := createTestMovie()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(existing, nil)
+		repo.On("Update", ctx, existing).Return(existing, nil)
+		slugRepo.On("AssignSlug", ctx, mock.Anything, mock.Anything, mock.Anything).Return("", errors.New("connection refused"))
+
+		movie, err := service.PatchMovie(ctx, "test-id-123", PatchMovieRequest{Title: mergepatch.Value("The Sequel")})
+
+		require.NoError(t, err)
+		assert.Empty(t, movie.Slug)
+	})
+
+	t.Run("should return the slug with the movie", func(t *testing.T) {
+		repo, slugRepo, service := setup()
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+		slugRepo.On("CurrentSlug", ctx, movies.MovieID("test-id-123")).Return("test-movie-2023", nil)
+
+		movie, err := service.GetMovieByID(ctx, "test-id-123")
+
+		require.NoError(t, err)
+		assert.Equal(t, "test-movie-2023", movie.Slug)
+	})
+
+	t.Run("should resolve a slug to its movie", func(t *testing.T) {
+		_, slugRepo, service := setup()
+		slugRepo.On("ResolveSlug", ctx, "working-title-2023").Return("test-id-123", "test-movie-2023", nil)
+		slugRepo.On("ResolveSlug", ctx, "alien-1979").Return("", "", errors.New("slug alien-1979 not found"))
+
+		movieID, current, err := service.ResolveMovieSlug(ctx, "working-title-2023")
+		require.NoError(t, err)
+		assert.Equal(t, "test-id-123", movieID)
+		assert.Equal(t, "test-movie-2023", current)
+
+		_, _, err = service.ResolveMovieSlug(ctx, "alien-1979")
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestUploadPoster(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	pngPoster := func(t *testing.T, width, height int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+		return buf.Bytes()
+	}
+
+	setup := func(t *testing.T) (*MockMovieRepository, *MockPosterRepository, storage.Storage, Service) {
+		repo := new(MockMovieRepository)
+		posterRepo := new(MockPosterRepository)
+		idGen := new(MockIDGenerator)
+		idGen.On("Generate").Return("upload-1")
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+
+		store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+		require.NoError(t, err)
+
+		service := NewMovieService(repo, idGen, timeProv, slog.Default(), WithPosterStorage(posterRepo, store, time.Minute))
+		return repo, posterRepo, store, service
+	}
+
+	t.Run("should store every variant and remove the previous poster", func(t *testing.T) {
+		repo, posterRepo, store, service := setup(t)
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+
+		require.NoError(t, store.Put(ctx, "posters/test-id-123/old/large.jpg", []byte("old"), "image/jpeg"))
+		previous := []*movies.Poster{{MovieID: "test-id-123", Variant: movies.PosterLarge, StorageKey: "posters/test-id-123/old/large.jpg"}}
+		posterRepo.On("ReplacePosters", ctx, movies.MovieID("test-id-123"), "/api/v1/movies/test-id-123/poster/large", mock.Anything, mock.Anything).
+			Return(previous, nil)
+
+		images, err := service.UploadPoster(ctx, "test-id-123", pngPoster(t, 1000, 1500))
+
+		require.NoError(t, err)
+		require.Len(t, images, 3)
+		assert.Equal(t, movies.PosterSmall, images[0].Variant)
+		assert.Equal(t, 185, images[0].Width)
+		assert.Equal(t, 278, images[0].Height)
+		assert.Equal(t, "/api/v1/media/posters/test-id-123/upload-1/small.jpg", images[0].URL)
+		assert.Equal(t, 780, images[2].Width)
+
+		for _, poster := range images {
+			_, object, err := store.Get(ctx, poster.StorageKey)
+			require.NoError(t, err)
+			assert.Equal(t, poster.SizeBytes, object.Size)
+		}
+		_, _, err = store.Get(ctx, "posters/test-id-123/old/large.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should reject data that is not an image", func(t *testing.T) {
+		repo, posterRepo, _, service := setup(t)
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+
+		_, err := service.UploadPoster(ctx, "test-id-123", []byte("GIF89a..."))
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+		posterRepo.AssertNotCalled(t, "ReplacePosters", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return not found for an unknown movie", func(t *testing.T) {
+		repo, _, _, service := setup(t)
+		repo.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("movie with ID missing not found"))
+
+		_, err := service.UploadPoster(ctx, "missing", pngPoster(t, 10, 15))
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+
+	t.Run("should clean up stored variants when saving fails", func(t *testing.T) {
+		repo, posterRepo, store, service := setup(t)
+		repo.On("GetByID", ctx, movies.MovieID("test-id-123")).Return(createTestMovie(), nil)
+		posterRepo.On("ReplacePosters", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+		_, err := service.UploadPoster(ctx, "test-id-123", pngPoster(t, 10, 15))
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+		_, _, err = store.Get(ctx, "posters/test-id-123/upload-1/large.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should fail when poster storage is not configured", func(t *testing.T) {
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+
+		_, err := service.UploadPoster(ctx, "test-id-123", pngPoster(t, 10, 15))
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+	})
+}
+
+func TestFindDuplicatePosters(t *testing.T) {
+	ctx := context.Background()
+	posterRepo := new(MockPosterRepository)
+	service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+		WithPosterStorage(posterRepo, nil, 0))
+
+	// Oldest first, as the repository lists them
+	posterRepo.On("ListPosterHashes", ctx).Return([]*movies.PosterFingerprint{
+		{MovieID: "dune", Title: "Dune", Hash: 0xff00ff00ff00ff00},
+		{MovieID: "heat", Title: "Heat", Hash: 0x0123456789abcdef},
+		{MovieID: "dune-copy", Title: "Dune", Hash: 0xff00ff00ff00ff01},
+		{MovieID: "dune-crop", Title: "Dune: Part One", Hash: 0xff00ff00ff00ff07},
+		{MovieID: "alien", Title: "Alien", Hash: 0x00ff00ff00ff00ff},
+	}, nil)
+
+	t.Run("should group movies sharing a poster", func(t *testing.T) {
+		groups, err := service.FindDuplicatePosters(ctx, 2)
+
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		require.Len(t, groups[0].Movies, 3)
+		assert.Equal(t, movies.MovieID("dune"), groups[0].Movies[0].MovieID)
+		assert.Equal(t, 0, groups[0].Movies[0].Distance)
+		assert.Equal(t, 1, groups[0].Movies[1].Distance)
+		// Two bits from dune-copy, so in the group, though three from dune
+		assert.Equal(t, movies.MovieID("dune-crop"), groups[0].Movies[2].MovieID)
+		assert.Equal(t, 3, groups[0].Movies[2].Distance)
+	})
+
+	t.Run("should only group identical posters at distance 0", func(t *testing.T) {
+		groups, err := service.FindDuplicatePosters(ctx, 0)
+		require.NoError(t, err)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("should reject a distance out of range", func(t *testing.T) {
+		_, err := service.FindDuplicatePosters(ctx, MaxPosterMatchDistance+1)
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+
+	t.Run("should fail when posters are not configured", func(t *testing.T) {
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default())
+
+		_, err := service.FindDuplicatePosters(ctx, DefaultPosterMatchDistance)
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+	})
+}
+
+func TestSearchByPoster(t *testing.T) {
+	ctx := context.Background()
+
+	// A left to right gradient: every pixel is darker than its right
+	// neighbour, which hashes to all zeros
+	gradient := image.NewGray(image.Rect(0, 0, 90, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 90; x++ {
+			gradient.SetGray(x, y, color.Gray{Y: uint8(x * 2)})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gradient))
+
+	posterRepo := new(MockPosterRepository)
+	service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+		WithPosterStorage(posterRepo, nil, 0))
+	posterRepo.On("ListPosterHashes", ctx).Return([]*movies.PosterFingerprint{
+		{MovieID: "far", Hash: 0xffffffffffffffff},
+		{MovieID: "near", Hash: 0x3},
+		{MovieID: "same", Hash: 0x0},
+	}, nil)
+
+	matches, err := service.SearchByPoster(ctx, buf.Bytes(), DefaultPosterMatchDistance)
+
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, movies.MovieID("same"), matches[0].MovieID)
+	assert.Equal(t, 0, matches[0].Distance)
+	assert.Equal(t, movies.MovieID("near"), matches[1].MovieID)
+	assert.Equal(t, 2, matches[1].Distance)
+
+	_, err = service.SearchByPoster(ctx, []byte("GIF89a..."), DefaultPosterMatchDistance)
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+}
+
+func TestPosterURL(t *testing.T) {
+	ctx := context.Background()
+	posterRepo := new(MockPosterRepository)
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+	require.NoError(t, err)
+	service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+		WithPosterStorage(posterRepo, store, 0))
+
+	posterRepo.On("GetPoster", ctx, movies.MovieID("test-id-123"), movies.PosterMedium).
+		Return(&movies.Poster{StorageKey: "posters/test-id-123/u/medium.jpg"}, nil)
+	posterRepo.On("GetPoster", ctx, movies.MovieID("other"), movies.PosterLarge).
+		Return(nil, errors.New("large poster for movie other not found"))
+
+	url, err := service.PosterURL(ctx, "test-id-123", "medium")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/media/posters/test-id-123/u/medium.jpg", url)
+
+	var appErr *appErrors.AppError
+	_, err = service.PosterURL(ctx, "other", "large")
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+
+	_, err = service.PosterURL(ctx, "test-id-123", "huge")
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+}
+
+func TestPutCertification(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should normalize the territory and certification and save", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCertifications := new(MockCertificationRepository)
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockCertifications.On("SaveCertification", ctx, mock.MatchedBy(func(c *movies.Certification) bool {
+			return c.Territory == "US" && c.System == movies.SystemMPAA && c.Certification == "PG13"
+		})).Return(&movies.Certification{MovieID: "movie-1", Territory: "US", System: movies.SystemMPAA, Certification: "PG13"}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), mockTime, slog.Default(), WithCertificationRepository(mockCertifications))
+		result, err := service.PutCertification(ctx, "movie-1", "us", movies.CertificationRequest{Certification: "pg-13"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "PG13", result.Certification)
+		mockCertifications.AssertExpectations(t)
+	})
+
+	t.Run("should reject a certification of another rating board", func(t *testing.T) {
+		mockTime := new(MockTimeProvider)
+		mockTime.On("Now").Return(now)
+
+		service := NewMovieService(new(MockMovieRepository), new(MockIDGenerator), mockTime, slog.Default(), WithCertificationRepository(new(MockCertificationRepository)))
+		_, err := service.PutCertification(ctx, "movie-1", "DE", movies.CertificationRequest{Certification: "PG"})
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, movies.ErrInvalidCertification.Error(), appErr.Message)
+	})
+
+	t.Run("should return not found for a missing certification", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCertifications := new(MockCertificationRepository)
+		mockRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		mockCertifications.On("DeleteCertification", ctx, movies.MovieID("movie-1"), "DE").
+			Return(errors.New("certification in DE for movie movie-1 not found"))
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithCertificationRepository(mockCertifications))
+		err := service.DeleteCertification(ctx, "movie-1", "de")
+
+		var appErr *appErrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestKidsMode(t *testing.T) {
+	kidsCtx := users.WithContentMode(context.Background(), users.ContentModeKids)
+	policy := &movies.ContentPolicy{Territory: "US", Certifications: []string{"G", "PG"}}
+
+	t.Run("should list movies through the certification filter", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		filter := movies.SearchFilter{CertificationTerritory: "US", Certifications: []string{"G", "PG"}, BayesianConfidenceK: rating.DefaultBayesianConfidenceK}
+		mockRepo.On("Search", kidsCtx, filter, mock.Anything).Return([]*movies.Movie{createTestMovie()}, int64(1), nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithKidsPolicy(policy))
+		result, total, err := service.GetAllMovies(kidsCtx, 10, 0, "title", "asc")
+
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, int64(1), total)
+		mockRepo.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should leave standard mode unfiltered", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockRepo.On("Search", mock.Anything, movies.SearchFilter{Query: "Heat", BayesianConfidenceK: rating.DefaultBayesianConfidenceK}, mock.Anything).Return([]*movies.Movie{}, int64(0), nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithKidsPolicy(policy))
+		_, _, err := service.SearchMovies(context.Background(), movies.SearchMoviesRequest{Query: "Heat", Limit: 10})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not let a search widen the kids policy", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			req      movies.SearchMoviesRequest
+			expected []string
+		}{
+			{name: "less restrictive", req: movies.SearchMoviesRequest{MaxCertification: "NC17"}, expected: []string{"G", "PG"}},
+			{name: "more restrictive", req: movies.SearchMoviesRequest{MaxCertification: "G"}, expected: []string{"G"}},
+			{name: "another territory", req: movies.SearchMoviesRequest{MaxCertification: "18", Territory: "DE"}, expected: []string{"G", "PG"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockMovieRepository)
+				mockRepo.On("Search", kidsCtx, mock.MatchedBy(func(f movies.SearchFilter) bool {
+					return f.CertificationTerritory == "US" && assert.ObjectsAreEqual(tt.expected, f.Certifications)
+				}), mock.Anything).Return([]*movies.Movie{}, int64(0), nil)
+
+				service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(), WithKidsPolicy(policy))
+				tt.req.Limit = 10
+				_, _, err := service.SearchMovies(kidsCtx, tt.req)
+
+				require.NoError(t, err)
+				mockRepo.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("should drop the title suggestions of disallowed movies", func(t *testing.T) {
+		mockRepo := new(MockMovieRepository)
+		mockCertifications := new(MockCertificationRepository)
+		mockRepo.On("Suggest", kidsCtx, "the", 10).Return([]*movies.Suggestion{
+			{Kind: movies.SuggestionTitle, Text: "The Lion King", MovieID: "movie-1"},
+			{Kind: movies.SuggestionTitle, Text: "The Shining", MovieID: "movie-2"},
+			{Kind: movies.SuggestionDirector, Text: "Thea Sharrock"},
+		}, nil)
+		mockCertifications.On("FilterAllowed", kidsCtx, []movies.MovieID{"movie-1", "movie-2"}, *policy).
+			Return([]movies.MovieID{"movie-1"}, nil)
+
+		service := NewMovieService(mockRepo, new(MockIDGenerator), new(MockTimeProvider), slog.Default(),
+			WithCertificationRepository(mockCertifications), WithKidsPolicy(policy))
+		result, err := service.Suggest(kidsCtx, "the", 10)
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "The Lion King", result[0].Text)
+		assert.Equal(t, movies.SuggestionDirector, result[1].Kind)
+	})
+}
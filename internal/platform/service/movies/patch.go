@@ -0,0 +1,144 @@
+package movies
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/money"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/mergepatch"
+)
+
+// PatchMovieRequest is a JSON Merge Patch (RFC 7396) of a movie. Absent
+// members keep their value; null clears the optional attributes (rating,
+// budget, revenue, imdb_id, poster_url) and is rejected for required ones.
+type PatchMovieRequest struct {
+	Title        mergepatch.Field[string] `json:"title"`
+	Description  mergepatch.Field[string] `json:"description"`
+	ReleaseYear  mergepatch.Field[int]    `json:"release_year"`
+	Genre        mergepatch.Field[string] `json:"genre"`
+	Director     mergepatch.Field[string] `json:"director"`
+	DurationMins mergepatch.Field[int]    `json:"duration_mins"`
+	Rating       mergepatch.Field[string] `json:"rating"`
+	Language     mergepatch.Field[string] `json:"language"`
+	Country      mergepatch.Field[string] `json:"country"`
+	Budget       mergepatch.Field[int64]  `json:"budget"`
+	Revenue      mergepatch.Field[int64]  `json:"revenue"`
+	Currency     mergepatch.Field[string] `json:"currency"` // Relabels budget and revenue, without converting them
+	IMDbID       mergepatch.Field[string] `json:"imdb_id"`
+	PosterURL    mergepatch.Field[string] `json:"poster_url"`
+}
+
+// PatchMovie applies a merge patch to the movie and stores the result
+func (m *movieService) PatchMovie(ctx context.Context, id string, req PatchMovieRequest) (_ *movies.Movie, err error) {
+	defer logging.StartOp(ctx, m.logger, "movie.patch", "movie_id", id).End(&err)
+
+	movie, err := m.movieRepo.GetByID(ctx, movies.MovieID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to get movie for patch", "error", err, "movie_id", id)
+		return nil, errors.NewInternalError("Failed to update movie")
+	}
+
+	previousDirector := movie.Director
+	if err := applyMoviePatch(movie, req); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	if err := movie.Validate(m.timeProvider); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	movie.UpdatedAt = m.timeProvider.Now()
+
+	updated, err := m.movieRepo.Update(ctx, movie)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to update movie", "error", err, "movie_id", id)
+		return nil, errors.NewInternalError("Failed to update movie")
+	}
+
+	if updated.Director != previousDirector {
+		m.creditDirector(ctx, updated)
+	}
+	// Kept when the title and year still make the same slug
+	m.assignSlug(ctx, updated)
+
+	m.logger.Info("Patched movie", "movie_id", id)
+	return updated, nil
+}
+
+func applyMoviePatch(movie *movies.Movie, req PatchMovieRequest) error {
+	currency := string(movie.Currency)
+	required := []struct {
+		name string
+		null bool
+	}{
+		{"title", req.Title.ApplyTo(&movie.Title)},
+		{"description", req.Description.ApplyTo(&movie.Description)},
+		{"release_year", req.ReleaseYear.ApplyTo(&movie.ReleaseYear)},
+		{"genre", req.Genre.ApplyTo(&movie.Genre)},
+		{"director", req.Director.ApplyTo(&movie.Director)},
+		{"duration_mins", req.DurationMins.ApplyTo(&movie.DurationMins)},
+		{"language", req.Language.ApplyTo(&movie.Language)},
+		{"country", req.Country.ApplyTo(&movie.Country)},
+		{"currency", req.Currency.ApplyTo(&currency)},
+	}
+	for _, field := range required {
+		if field.null {
+			return fmt.Errorf("%s cannot be null", field.name)
+		}
+	}
+
+	movie.Title = strings.TrimSpace(movie.Title)
+	movie.Description = strings.TrimSpace(movie.Description)
+	movie.Genre = strings.TrimSpace(movie.Genre)
+	movie.Director = strings.TrimSpace(movie.Director)
+	movie.Language = strings.TrimSpace(movie.Language)
+	movie.Country = strings.TrimSpace(movie.Country)
+
+	if req.Rating.Set {
+		movie.Rating = ""
+		if !req.Rating.Null {
+			if !movies.IsValid(req.Rating.Value) {
+				return movies.ErrInvalidRating
+			}
+			movie.Rating = movies.Rating(req.Rating.Value)
+		}
+	}
+
+	if req.Currency.Set {
+		parsed, err := money.ParseCurrency(currency)
+		if err != nil {
+			return movies.ErrInvalidCurrency
+		}
+		movie.Currency = parsed
+	}
+
+	req.Budget.ApplyToOptional(&movie.Budget)
+	req.Revenue.ApplyToOptional(&movie.Revenue)
+	req.IMDbID.ApplyToOptional(&movie.IMDbID)
+	req.PosterURL.ApplyToOptional(&movie.PosterURL)
+	movie.IMDbID = trimOptional(movie.IMDbID)
+	movie.PosterURL = trimOptional(movie.PosterURL)
+
+	return nil
+}
+
+// trimOptional trims s and treats a blank value as unset, like the
+// WithIMDbID and WithPosterURL options do on create
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,324 @@
+package movies
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"sort"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/imaging"
+	"thermondo/internal/pkg/storage"
+	"time"
+)
+
+// DefaultPosterURLTTL is how long signed poster URLs stay valid
+const DefaultPosterURLTTL = 15 * time.Minute
+
+const (
+	// DefaultPosterMatchDistance is how many bits poster hashes may differ in
+	// to count as the same artwork: re-encoded, resized or lightly cropped
+	// copies stay within it
+	DefaultPosterMatchDistance = 4
+	// MaxPosterMatchDistance bounds the distance callers may ask for; past it
+	// unrelated posters start to match
+	MaxPosterMatchDistance = 16
+)
+
+// WithPosterStorage enables poster uploads. Variants are written to store and
+// their download URLs are valid for urlTTL (DefaultPosterURLTTL when zero)
+// if the backend signs them.
+func WithPosterStorage(posterRepo movies.PosterRepository, store storage.Storage, urlTTL time.Duration) Option {
+	return func(m *movieService) {
+		m.posterRepo = posterRepo
+		m.posterStorage = store
+		if urlTTL > 0 {
+			m.posterURLTTL = urlTTL
+		}
+	}
+}
+
+// PosterImage is a stored poster variant with a URL clients can fetch it from
+type PosterImage struct {
+	movies.Poster
+	URL string
+}
+
+// PosterPath is the stable address kept in a movie's poster_url. It redirects
+// to the current large variant, so it survives re-uploads and URL expiry.
+func PosterPath(id movies.MovieID, variant movies.PosterVariant) string {
+	return fmt.Sprintf("/api/v1/movies/%s/poster/%s", id, variant)
+}
+
+// UploadPoster decodes a JPEG or PNG image, stores it resized to every poster
+// variant and makes it the movie's poster. The previous variants are removed.
+func (m *movieService) UploadPoster(ctx context.Context, id string, data []byte) ([]*PosterImage, error) {
+	if m.posterRepo == nil || m.posterStorage == nil {
+		return nil, errors.NewInternalError("Poster uploads are not configured")
+	}
+
+	movieID := movies.MovieID(id)
+	if _, err := m.GetMovieByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		if stdErrors.Is(err, imaging.ErrImageTooLarge) {
+			return nil, errors.NewBadRequestError(err.Error())
+		}
+		return nil, errors.NewBadRequestError(movies.ErrInvalidPosterImage.Error())
+	}
+
+	variants := make([]imaging.Variant, len(movies.PosterVariants))
+	for i, variant := range movies.PosterVariants {
+		variants[i] = imaging.Variant{Name: string(variant), Width: variant.Width()}
+	}
+	renditions, err := imaging.Renditions(img, variants)
+	if err != nil {
+		m.logger.Error("Failed to resize poster", "error", err, "movie_id", id)
+		return nil, errors.NewInternalError("Failed to process poster")
+	}
+
+	// Every upload gets fresh keys so cached copies of the old poster are
+	// never served under the new one's URL
+	uploadID := m.idGenerator.Generate()
+	now := m.timeProvider.Now()
+
+	posters := make([]*movies.Poster, 0, len(renditions))
+	for _, rendition := range renditions {
+		poster := &movies.Poster{
+			MovieID:     movieID,
+			Variant:     movies.PosterVariant(rendition.Name),
+			StorageKey:  fmt.Sprintf("posters/%s/%s/%s.jpg", movieID, uploadID, rendition.Name),
+			ContentType: "image/jpeg",
+			Width:       rendition.Width,
+			Height:      rendition.Height,
+			SizeBytes:   int64(len(rendition.Data)),
+			CreatedAt:   now,
+		}
+		if err := m.posterStorage.Put(ctx, poster.StorageKey, rendition.Data, poster.ContentType); err != nil {
+			m.logger.Error("Failed to store poster", "error", err, "movie_id", id, "key", poster.StorageKey)
+			m.deletePosterObjects(ctx, posters)
+			return nil, errors.NewInternalError("Failed to store poster")
+		}
+		posters = append(posters, poster)
+	}
+
+	hash := movies.PosterHash(imaging.DifferenceHash(img))
+	previous, err := m.posterRepo.ReplacePosters(ctx, movieID, PosterPath(movieID, movies.PosterLarge), posters, hash)
+	if err != nil {
+		m.deletePosterObjects(ctx, posters)
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to save posters", "error", err, "movie_id", id)
+		return nil, errors.NewInternalError("Failed to store poster")
+	}
+	m.deletePosterObjects(ctx, previous)
+
+	m.logger.Info("Uploaded poster", "movie_id", id, "upload_id", uploadID)
+	return m.posterImages(ctx, posters)
+}
+
+// ListPosters returns the movie's current poster variants
+func (m *movieService) ListPosters(ctx context.Context, id string) ([]*PosterImage, error) {
+	if m.posterRepo == nil {
+		return nil, errors.NewNotFoundError("Poster not found")
+	}
+
+	posters, err := m.posterRepo.ListPosters(ctx, movies.MovieID(id))
+	if err != nil {
+		m.logger.Error("Failed to list posters", "error", err, "movie_id", id)
+		return nil, errors.NewInternalError("Failed to get poster")
+	}
+	if len(posters) == 0 {
+		return nil, errors.NewNotFoundError("Poster not found")
+	}
+
+	return m.posterImages(ctx, posters)
+}
+
+// PosterURL returns a download URL for one variant of the movie's poster
+func (m *movieService) PosterURL(ctx context.Context, id, variant string) (string, error) {
+	if m.posterRepo == nil || m.posterStorage == nil {
+		return "", errors.NewNotFoundError("Poster not found")
+	}
+
+	posterVariant, err := movies.ParsePosterVariant(variant)
+	if err != nil {
+		return "", errors.NewBadRequestError(err.Error())
+	}
+
+	poster, err := m.posterRepo.GetPoster(ctx, movies.MovieID(id), posterVariant)
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", errors.NewNotFoundError("Poster not found")
+		}
+		m.logger.Error("Failed to get poster", "error", err, "movie_id", id)
+		return "", errors.NewInternalError("Failed to get poster")
+	}
+
+	url, err := m.posterStorage.URL(ctx, poster.StorageKey, m.posterURLTTL)
+	if err != nil {
+		m.logger.Error("Failed to build poster URL", "error", err, "movie_id", id)
+		return "", errors.NewInternalError("Failed to get poster")
+	}
+
+	return url, nil
+}
+
+// PosterMatch is a movie whose poster resembles another, Distance bits of
+// its hash apart
+type PosterMatch struct {
+	movies.PosterFingerprint
+	Distance int
+}
+
+// PosterDuplicates is a group of movies sharing the same or nearly the same
+// poster. Distances are to the first movie, the one whose poster was
+// uploaded first.
+type PosterDuplicates struct {
+	Movies []*PosterMatch
+}
+
+// FindDuplicatePosters groups the movies whose posters are at most
+// maxDistance bits apart, catching duplicate catalog entries and artwork
+// uploaded to the wrong movie. Groups are transitive: a poster close to any
+// poster of a group joins it.
+func (m *movieService) FindDuplicatePosters(ctx context.Context, maxDistance int) ([]*PosterDuplicates, error) {
+	if err := validatePosterMatchDistance(maxDistance); err != nil {
+		return nil, err
+	}
+	fingerprints, err := m.posterFingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Union-find over the pairs close enough; the catalog holds few enough
+	// posters to compare every pair
+	parent := make([]int, len(fingerprints))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range fingerprints {
+		for j := i + 1; j < len(fingerprints); j++ {
+			if fingerprints[i].Hash.Distance(fingerprints[j].Hash) > maxDistance {
+				continue
+			}
+			// The root stays the earliest poster, as fingerprints are
+			// oldest first
+			if a, b := find(i), find(j); a != b {
+				if a < b {
+					parent[b] = a
+				} else {
+					parent[a] = b
+				}
+			}
+		}
+	}
+
+	groups := make(map[int]*PosterDuplicates)
+	var duplicates []*PosterDuplicates
+	for i, fingerprint := range fingerprints {
+		root := find(i)
+		group, ok := groups[root]
+		if !ok {
+			group = &PosterDuplicates{}
+			groups[root] = group
+			duplicates = append(duplicates, group)
+		}
+		group.Movies = append(group.Movies, &PosterMatch{
+			PosterFingerprint: *fingerprint,
+			Distance:          fingerprints[root].Hash.Distance(fingerprint.Hash),
+		})
+	}
+
+	result := make([]*PosterDuplicates, 0, len(duplicates))
+	for _, group := range duplicates {
+		if len(group.Movies) > 1 {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// SearchByPoster finds the movies whose posters are at most maxDistance bits
+// from the hash of a JPEG or PNG image, closest first
+func (m *movieService) SearchByPoster(ctx context.Context, data []byte, maxDistance int) ([]*PosterMatch, error) {
+	if err := validatePosterMatchDistance(maxDistance); err != nil {
+		return nil, err
+	}
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		if stdErrors.Is(err, imaging.ErrImageTooLarge) {
+			return nil, errors.NewBadRequestError(err.Error())
+		}
+		return nil, errors.NewBadRequestError(movies.ErrInvalidPosterImage.Error())
+	}
+	fingerprints, err := m.posterFingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := movies.PosterHash(imaging.DifferenceHash(img))
+	matches := []*PosterMatch{}
+	for _, fingerprint := range fingerprints {
+		if distance := hash.Distance(fingerprint.Hash); distance <= maxDistance {
+			matches = append(matches, &PosterMatch{PosterFingerprint: *fingerprint, Distance: distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Distance < matches[j].Distance
+	})
+	return matches, nil
+}
+
+func (m *movieService) posterFingerprints(ctx context.Context) ([]*movies.PosterFingerprint, error) {
+	if m.posterRepo == nil {
+		return nil, errors.NewInternalError("Poster uploads are not configured")
+	}
+	fingerprints, err := m.posterRepo.ListPosterHashes(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list poster hashes", "error", err)
+		return nil, errors.NewInternalError("Failed to compare posters")
+	}
+	return fingerprints, nil
+}
+
+func validatePosterMatchDistance(maxDistance int) error {
+	if maxDistance < 0 || maxDistance > MaxPosterMatchDistance {
+		return errors.NewBadRequestError(fmt.Sprintf("max_distance must be between 0 and %d", MaxPosterMatchDistance))
+	}
+	return nil
+}
+
+func (m *movieService) posterImages(ctx context.Context, posters []*movies.Poster) ([]*PosterImage, error) {
+	images := make([]*PosterImage, len(posters))
+	for i, poster := range posters {
+		url, err := m.posterStorage.URL(ctx, poster.StorageKey, m.posterURLTTL)
+		if err != nil {
+			m.logger.Error("Failed to build poster URL", "error", err, "movie_id", poster.MovieID)
+			return nil, errors.NewInternalError("Failed to get poster")
+		}
+		images[i] = &PosterImage{Poster: *poster, URL: url}
+	}
+	return images, nil
+}
+
+// deletePosterObjects removes stored variants on a best-effort basis; a
+// leftover object only costs storage
+func (m *movieService) deletePosterObjects(ctx context.Context, posters []*movies.Poster) {
+	for _, poster := range posters {
+		if err := m.posterStorage.Delete(ctx, poster.StorageKey); err != nil {
+			m.logger.Warn("Failed to delete poster object", "error", err, "key", poster.StorageKey)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package movies
+
+import (
+	"context"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+)
+
+// WithProviderRepository enables where to watch: the providers, the offers
+// of movies on them and searching by provider
+func WithProviderRepository(providerRepo movies.ProviderRepository) Option {
+	return func(m *movieService) {
+		m.providerRepo = providerRepo
+	}
+}
+
+func (m *movieService) ListProviders(ctx context.Context) ([]*movies.Provider, error) {
+	if err := m.requireProviders(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	providers, err := m.providerRepo.ListProviders(ctx)
+	if err != nil {
+		m.logger.Error("Failed to list providers", "error", err)
+		return nil, errors.NewInternalError("Failed to list providers")
+	}
+
+	return providers, nil
+}
+
+// PutProvider adds a provider or renames it
+func (m *movieService) PutProvider(ctx context.Context, providerID string, req movies.ProviderRequest) (*movies.Provider, error) {
+	provider, err := movies.NewProvider(providerID, req.Name, m.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireProviders(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	saved, err := m.providerRepo.SaveProvider(ctx, provider)
+	if err != nil {
+		m.logger.Error("Failed to save provider", "error", err, "provider", provider.ID)
+		return nil, errors.NewInternalError("Failed to save provider")
+	}
+
+	m.logger.Info("Saved provider", "provider", saved.ID)
+	return saved, nil
+}
+
+// ListOffers returns where the movie can be watched, only in region when it
+// is set
+func (m *movieService) ListOffers(ctx context.Context, movieID, region string) ([]*movies.Offer, error) {
+	normalized, err := optionalRegion(region)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireProviders(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	offers, err := m.providerRepo.GetOffers(ctx, movies.MovieID(movieID), normalized)
+	if err != nil {
+		m.logger.Error("Failed to get offers", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get offers")
+	}
+
+	return offers, nil
+}
+
+// PutOffer creates or replaces the movie's offer of a type on a provider in
+// region
+func (m *movieService) PutOffer(ctx context.Context, movieID, region, providerID, offerType string, req movies.OfferRequest) (*movies.Offer, error) {
+	offer, err := movies.NewOffer(movies.MovieID(movieID), region, providerID, offerType, req.URL, m.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireProviders(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	saved, err := m.providerRepo.SaveOffer(ctx, offer)
+	if err != nil {
+		if isNotFoundError(err) {
+			if strings.HasPrefix(err.Error(), "provider") {
+				return nil, errors.NewNotFoundError("Provider not found")
+			}
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to save offer", "error", err, "movie_id", movieID, "region", offer.Region, "provider", offer.ProviderID, "type", offer.Type)
+		return nil, errors.NewInternalError("Failed to save offer")
+	}
+
+	m.logger.Info("Saved offer", "movie_id", movieID, "region", saved.Region, "provider", saved.ProviderID, "type", saved.Type)
+	return saved, nil
+}
+
+func (m *movieService) DeleteOffer(ctx context.Context, movieID, region, providerID, offerType string) error {
+	normalizedRegion, err := movies.NormalizeRegion(region)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	normalizedProvider, err := movies.NormalizeProviderID(providerID)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	t, err := movies.ParseOfferType(offerType)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireProviders(ctx, movieID); err != nil {
+		return err
+	}
+
+	if err := m.providerRepo.DeleteOffer(ctx, movies.MovieID(movieID), normalizedRegion, normalizedProvider, t); err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Offer not found")
+		}
+		m.logger.Error("Failed to delete offer", "error", err, "movie_id", movieID, "region", normalizedRegion, "provider", normalizedProvider, "type", t)
+		return errors.NewInternalError("Failed to delete offer")
+	}
+
+	m.logger.Info("Deleted offer", "movie_id", movieID, "region", normalizedRegion, "provider", normalizedProvider, "type", t)
+	return nil
+}
+
+// optionalRegion normalizes a region that may be left out
+func optionalRegion(region string) (string, error) {
+	if strings.TrimSpace(region) == "" {
+		return "", nil
+	}
+	return movies.NormalizeRegion(region)
+}
+
+// requireProviders checks that providers are configured and, given a
+// movie, that it exists
+func (m *movieService) requireProviders(ctx context.Context, movieID string) error {
+	if m.providerRepo == nil {
+		m.logger.Error("Providers requested but no provider repository is configured")
+		return errors.NewInternalError("Providers are not available")
+	}
+	if movieID == "" {
+		return nil
+	}
+
+	exists, err := m.movieRepo.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package movies
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+)
+
+const (
+	DefaultRandomLimit = 1
+	MaxRandomLimit     = 10
+)
+
+// RandomRequest narrows the movies a "surprise me" pick is drawn from.
+// Decade is the decade's first year, e.g. 1990; NotRatedBy leaves out the
+// movies that user has rated.
+type RandomRequest struct {
+	Genre      string
+	Decade     *int
+	MinRating  *float64
+	NotRatedBy string
+	Limit      int
+}
+
+// RandomMovies picks up to req.Limit movies at random for discovery. Kids
+// mode applies as it does to search.
+func (m *movieService) RandomMovies(ctx context.Context, req RandomRequest) ([]*movies.Movie, error) {
+	search := movies.SearchMoviesRequest{Genre: req.Genre, MinRating: req.MinRating}
+	if req.Decade != nil {
+		if *req.Decade%10 != 0 {
+			return nil, errors.NewBadRequestError("decade must be the decade's first year, e.g. 1990")
+		}
+		lastYear := *req.Decade + 9
+		search.MinYear, search.MaxYear = req.Decade, &lastYear
+	}
+	filter, err := m.searchFilter(ctx, search)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	filter.NotRatedBy = req.NotRatedBy
+
+	limit := req.Limit
+	if limit < 1 {
+		limit = DefaultRandomLimit
+	}
+	picked, err := m.movieRepo.Random(ctx, filter, min(limit, MaxRandomLimit))
+	if err != nil {
+		m.logger.Error("Failed to pick random movies", "error", err)
+		return nil, errors.NewInternalError("Failed to pick random movies")
+	}
+	if picked == nil {
+		picked = []*movies.Movie{}
+	}
+	return picked, nil
+}
@@ -0,0 +1,194 @@
+package movies
+
+import (
+	"context"
+	stdErrors "errors"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	// DefaultUpcomingDays is how far ahead upcoming releases are listed
+	// when no end date is given
+	DefaultUpcomingDays = 90
+	// MaxUpcomingDays bounds the window of one upcoming releases query
+	MaxUpcomingDays  = 366
+	MaxUpcomingLimit = 100
+)
+
+// UpcomingRequest selects releases for the release calendar. Dates are
+// YYYY-MM-DD; From defaults to today and To to DefaultUpcomingDays later.
+type UpcomingRequest struct {
+	Region string
+	Type   string
+	From   string
+	To     string
+	Limit  int
+	Offset int
+}
+
+// WithReleaseRepository enables regional release dates and the upcoming
+// releases calendar
+func WithReleaseRepository(releaseRepo movies.ReleaseRepository) Option {
+	return func(m *movieService) {
+		m.releaseRepo = releaseRepo
+	}
+}
+
+func (m *movieService) ListReleases(ctx context.Context, movieID string) ([]*movies.Release, error) {
+	if err := m.requireReleases(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	releases, err := m.releaseRepo.GetReleases(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to get releases", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get releases")
+	}
+
+	return releases, nil
+}
+
+// PutRelease creates or replaces the movie's release of a type in region.
+// The movie's release year follows its earliest release.
+func (m *movieService) PutRelease(ctx context.Context, movieID, region, releaseType string, req movies.ReleaseRequest) (*movies.Release, error) {
+	release, err := movies.NewRelease(movies.MovieID(movieID), region, releaseType, req.ReleaseDate, m.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireReleases(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	saved, err := m.releaseRepo.SaveRelease(ctx, release)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to save release", "error", err, "movie_id", movieID, "region", release.Region, "type", release.Type)
+		return nil, errors.NewInternalError("Failed to save release")
+	}
+
+	m.logger.Info("Saved release", "movie_id", movieID, "region", saved.Region, "type", saved.Type)
+	return saved, nil
+}
+
+func (m *movieService) DeleteRelease(ctx context.Context, movieID, region, releaseType string) error {
+	normalized, err := movies.NormalizeRegion(region)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	t, err := movies.ParseReleaseType(releaseType)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireReleases(ctx, movieID); err != nil {
+		return err
+	}
+
+	if err := m.releaseRepo.DeleteRelease(ctx, movies.MovieID(movieID), normalized, t); err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Release not found")
+		}
+		m.logger.Error("Failed to delete release", "error", err, "movie_id", movieID, "region", normalized, "type", t)
+		return errors.NewInternalError("Failed to delete release")
+	}
+
+	return nil
+}
+
+// ListUpcoming returns a page of releases in the requested window, earliest
+// first, with the total number of matching releases
+func (m *movieService) ListUpcoming(ctx context.Context, req UpcomingRequest) ([]*movies.UpcomingRelease, int64, error) {
+	filter, err := m.upcomingFilter(req)
+	if err != nil {
+		return nil, 0, errors.NewBadRequestError(err.Error())
+	}
+	filter.Policy = m.contentPolicy(ctx)
+	if m.releaseRepo == nil {
+		m.logger.Error("Releases requested but no release repository is configured")
+		return nil, 0, errors.NewInternalError("Releases are not available")
+	}
+
+	upcoming, total, err := m.releaseRepo.ListUpcoming(ctx, *filter)
+	if err != nil {
+		m.logger.Error("Failed to list upcoming releases", "error", err, "region", filter.Region)
+		return nil, 0, errors.NewInternalError("Failed to list upcoming releases")
+	}
+
+	return upcoming, total, nil
+}
+
+func (m *movieService) upcomingFilter(req UpcomingRequest) (*movies.UpcomingFilter, error) {
+	filter := &movies.UpcomingFilter{Limit: req.Limit, Offset: req.Offset}
+
+	if strings.TrimSpace(req.Region) != "" {
+		region, err := movies.NormalizeRegion(req.Region)
+		if err != nil {
+			return nil, err
+		}
+		filter.Region = region
+	}
+	if strings.TrimSpace(req.Type) != "" {
+		t, err := movies.ParseReleaseType(req.Type)
+		if err != nil {
+			return nil, err
+		}
+		filter.Type = t
+	}
+
+	now := m.timeProvider.Now().UTC()
+	filter.From = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if req.From != "" {
+		from, err := time.Parse(movies.ReleaseDateLayout, req.From)
+		if err != nil {
+			return nil, stdErrors.New("from must be a YYYY-MM-DD date")
+		}
+		filter.From = from
+	}
+	filter.To = filter.From.AddDate(0, 0, DefaultUpcomingDays)
+	if req.To != "" {
+		to, err := time.Parse(movies.ReleaseDateLayout, req.To)
+		if err != nil {
+			return nil, stdErrors.New("to must be a YYYY-MM-DD date")
+		}
+		filter.To = to
+	}
+	if filter.To.Before(filter.From) {
+		return nil, stdErrors.New("to must not be before from")
+	}
+	if filter.To.Sub(filter.From) > MaxUpcomingDays*24*time.Hour {
+		return nil, stdErrors.New("to must be at most 366 days after from")
+	}
+
+	if filter.Limit <= 0 || filter.Limit > MaxUpcomingLimit {
+		return nil, stdErrors.New("limit must be between 1 and 100")
+	}
+	if filter.Offset < 0 {
+		return nil, stdErrors.New("offset must not be negative")
+	}
+
+	return filter, nil
+}
+
+// requireReleases checks that releases are configured and that the movie
+// exists
+func (m *movieService) requireReleases(ctx context.Context, movieID string) error {
+	if m.releaseRepo == nil {
+		m.logger.Error("Releases requested but no release repository is configured")
+		return errors.NewInternalError("Releases are not available")
+	}
+
+	exists, err := m.movieRepo.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+
+	return nil
+}
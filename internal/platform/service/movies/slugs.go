@@ -0,0 +1,66 @@
+package movies
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+)
+
+// WithSlugRepository gives movies slugs made of their title and year when
+// they are created or updated, and enables looking movies up by them
+func WithSlugRepository(slugRepo movies.SlugRepository) Option {
+	return func(m *movieService) {
+		m.slugRepo = slugRepo
+	}
+}
+
+// ResolveMovieSlug returns the movie a slug belongs to and the movie's
+// current slug, which differs from slug when the slug is an old one
+func (m *movieService) ResolveMovieSlug(ctx context.Context, slug string) (string, string, error) {
+	if m.slugRepo == nil {
+		return "", "", errors.NewNotFoundError("Movie not found")
+	}
+
+	movieID, current, err := m.slugRepo.ResolveSlug(ctx, slug)
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", "", errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to resolve movie slug", "error", err, "slug", slug)
+		return "", "", errors.NewInternalError("Failed to get movie")
+	}
+
+	return string(movieID), current, nil
+}
+
+// assignSlug gives the movie the slug of its current title and year. The
+// movie is saved by then, so a failure only leaves its slug as it was and
+// is not returned; the next update tries again.
+func (m *movieService) assignSlug(ctx context.Context, movie *movies.Movie) {
+	if m.slugRepo == nil {
+		return
+	}
+
+	slug, err := m.slugRepo.AssignSlug(ctx, movie.ID, movies.Slug(movie.Title, movie.ReleaseYear), m.timeProvider.Now())
+	if err != nil {
+		m.logger.Warn("Failed to assign slug", "error", err, "movie_id", movie.ID)
+		return
+	}
+	movie.Slug = slug
+}
+
+// loadSlug sets the movie's current slug, leaving it empty when it has none
+func (m *movieService) loadSlug(ctx context.Context, movie *movies.Movie) {
+	if m.slugRepo == nil {
+		return
+	}
+
+	slug, err := m.slugRepo.CurrentSlug(ctx, movie.ID)
+	if err != nil {
+		if !isNotFoundError(err) {
+			m.logger.Warn("Failed to get slug", "error", err, "movie_id", movie.ID)
+		}
+		return
+	}
+	movie.Slug = slug
+}
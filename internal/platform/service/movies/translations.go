@@ -0,0 +1,169 @@
+package movies
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/errors"
+)
+
+// LocalizeMovies replaces the title and description of each movie with its
+// best translation for the preferred locales, most preferred first. A region
+// tag falls back to its base language (pt-BR to pt). Movies keep their
+// canonical text when no translation matches, or when the movie's own
+// language comes before the translations found, so an English film is not
+// served in German to someone who prefers English. Localization is best
+// effort: lookup failures are logged and the canonical text is served.
+func (m *movieService) LocalizeMovies(ctx context.Context, moviesList []*movies.Movie, locales []string) {
+	if m.translationRepo == nil || len(moviesList) == 0 {
+		return
+	}
+
+	candidates := movies.LocaleFallbacks(locales)
+	if len(candidates) == 0 {
+		return
+	}
+
+	ids := make([]movies.MovieID, 0, len(moviesList))
+	for _, movie := range moviesList {
+		if movie != nil {
+			ids = append(ids, movie.ID)
+		}
+	}
+
+	translations, err := m.translationRepo.FindTranslations(ctx, ids, candidates)
+	if err != nil {
+		m.logger.Warn("Failed to load translations, serving canonical titles", "error", err, "locales", candidates)
+		return
+	}
+
+	byMovie := make(map[movies.MovieID]map[string]*movies.Translation)
+	for _, t := range translations {
+		if byMovie[t.MovieID] == nil {
+			byMovie[t.MovieID] = make(map[string]*movies.Translation)
+		}
+		byMovie[t.MovieID][t.Locale] = t
+	}
+
+	for _, movie := range moviesList {
+		if movie == nil {
+			continue
+		}
+		canonical := movies.LanguageCode(movie.Language)
+		for _, locale := range candidates {
+			if locale == canonical {
+				break
+			}
+			t, ok := byMovie[movie.ID][locale]
+			if !ok {
+				continue
+			}
+			movie.Title = t.Title
+			if t.Description != "" {
+				movie.Description = t.Description
+			}
+			movie.Locale = t.Locale
+			break
+		}
+	}
+}
+
+func (m *movieService) ListTranslations(ctx context.Context, movieID string) ([]*movies.Translation, error) {
+	if err := m.requireTranslations(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	translations, err := m.translationRepo.GetTranslations(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to get translations", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get translations")
+	}
+	if translations == nil {
+		translations = []*movies.Translation{}
+	}
+
+	return translations, nil
+}
+
+func (m *movieService) GetTranslation(ctx context.Context, movieID, locale string) (*movies.Translation, error) {
+	normalized, err := movies.NormalizeLocale(locale)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireTranslations(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	translation, err := m.translationRepo.GetTranslation(ctx, movies.MovieID(movieID), normalized)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Translation not found")
+		}
+		m.logger.Error("Failed to get translation", "error", err, "movie_id", movieID, "locale", normalized)
+		return nil, errors.NewInternalError("Failed to get translation")
+	}
+
+	return translation, nil
+}
+
+// PutTranslation creates or replaces the movie's translation for locale
+func (m *movieService) PutTranslation(ctx context.Context, movieID, locale string, req movies.TranslationRequest) (*movies.Translation, error) {
+	translation, err := movies.NewTranslation(movies.MovieID(movieID), locale, req.Title, req.Description, m.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireTranslations(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	saved, err := m.translationRepo.SaveTranslation(ctx, translation)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		m.logger.Error("Failed to save translation", "error", err, "movie_id", movieID, "locale", translation.Locale)
+		return nil, errors.NewInternalError("Failed to save translation")
+	}
+
+	return saved, nil
+}
+
+func (m *movieService) DeleteTranslation(ctx context.Context, movieID, locale string) error {
+	normalized, err := movies.NormalizeLocale(locale)
+	if err != nil {
+		return errors.NewBadRequestError(err.Error())
+	}
+	if err := m.requireTranslations(ctx, movieID); err != nil {
+		return err
+	}
+
+	if err := m.translationRepo.DeleteTranslation(ctx, movies.MovieID(movieID), normalized); err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Translation not found")
+		}
+		m.logger.Error("Failed to delete translation", "error", err, "movie_id", movieID, "locale", normalized)
+		return errors.NewInternalError("Failed to delete translation")
+	}
+
+	return nil
+}
+
+// requireTranslations checks that translations are configured and that the
+// movie exists, so a missing movie is reported as such rather than as an
+// empty translation list
+func (m *movieService) requireTranslations(ctx context.Context, movieID string) error {
+	if m.translationRepo == nil {
+		m.logger.Error("Translations requested but no translation repository is configured")
+		return errors.NewInternalError("Translations are not available")
+	}
+
+	exists, err := m.movieRepo.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		m.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+
+	return nil
+}
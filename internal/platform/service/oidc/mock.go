@@ -0,0 +1,84 @@
+package oidc
+
+import (
+	"context"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *mockIdentityRepository) Create(ctx context.Context, identity *users.Identity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
+
+func (m *mockIdentityRepository) Find(ctx context.Context, provider, subject string) (*users.Identity, error) {
+	args := m.Called(ctx, provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Identity), args.Error(1)
+}
+
+func (m *mockIdentityRepository) ListByUser(ctx context.Context, userID users.UserID) ([]*users.Identity, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*users.Identity), args.Error(1)
+}
+
+func (m *mockIdentityRepository) Delete(ctx context.Context, userID users.UserID, provider string) error {
+	args := m.Called(ctx, userID, provider)
+	return args.Error(0)
+}
+
+type mockUsers struct {
+	mock.Mock
+}
+
+func (m *mockUsers) FindUserByID(ctx context.Context, id string) (*users.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *mockUsers) FindUserByEmail(ctx context.Context, email string) (*users.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *mockUsers) CreateUser(ctx context.Context, user users.CreateUserRequest) (*users.User, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *mockUsers) MergeUsers(ctx context.Context, sourceID, targetID string) (*users.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.MergeRecord), args.Error(1)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,469 @@
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stdErrors "errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
+	oidcClient "thermondo/internal/pkg/oidc"
+	pwd "thermondo/internal/pkg/password"
+)
+
+// ProviderPlaceholder is replaced with the provider's name in the callback
+// URL
+const ProviderPlaceholder = "{provider}"
+
+// Service signs users in with OpenID Connect providers such as Google and
+// Apple. A user is known by the provider's subject once signed in; the
+// first time, the identity is linked to the account with the email the
+// provider verified, or a new account is made for it. Signed-in users can
+// also link and unlink providers themselves.
+type Service interface {
+	// Providers returns the names of the configured providers
+	Providers() []string
+	// Start begins signing in with provider and returns where to send the
+	// user
+	Start(ctx context.Context, provider string, req StartRequest) (*StartResponse, error)
+	// StartLink begins adding provider to the account of userID, who the
+	// caller must have recently authenticated as. Its callback links the
+	// provider's account, merging the account it already signs in to, if
+	// any, into userID's.
+	StartLink(ctx context.Context, provider, userID string) (*StartResponse, error)
+	// Callback ends the flow the provider sent the user back from and
+	// returns the user signed in
+	Callback(ctx context.Context, provider string, req CallbackRequest) (*CallbackResult, error)
+	// Identities returns the providers linked to the user, oldest first
+	Identities(ctx context.Context, userID string) ([]*users.Identity, error)
+	// Unlink removes provider from the user's ways to sign in. Unlinking
+	// the last provider takes the user's password, so the account stays
+	// reachable.
+	Unlink(ctx context.Context, userID, provider, password string) error
+}
+
+type StartRequest struct {
+	// ReturnTo is where the app wants the token delivered; it must be one
+	// of the allowed return URLs. Empty answers the callback with JSON.
+	ReturnTo string
+}
+
+type StartResponse struct {
+	AuthURL string
+	// Binding must be sent back with the callback, from a cookie of the
+	// browser that started the flow
+	Binding string
+}
+
+type CallbackRequest struct {
+	State   string
+	Code    string
+	Binding string
+	// Error is set instead of Code when the user or the provider refused
+	Error string
+	// User is the JSON Apple posts on the first sign-in only, holding the
+	// user's name, which its ID tokens never carry
+	User string
+}
+
+type CallbackResult struct {
+	User *users.User
+	// Provisioned is true when the account was made for this sign-in, and
+	// Linked when the identity was added to an existing account. Merged is
+	// true when the identity signed in to another account, which was
+	// merged into User.
+	Provisioned bool
+	Linked      bool
+	Merged      bool
+	ReturnTo    string
+}
+
+// Users are the user service methods the sign-in needs
+type Users interface {
+	FindUserByID(ctx context.Context, id string) (*users.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*users.User, error)
+	CreateUser(ctx context.Context, user users.CreateUserRequest) (*users.User, error)
+	MergeUsers(ctx context.Context, sourceID, targetID string) (*users.MergeRecord, error)
+}
+
+type oidcService struct {
+	providers    map[string]*oidcClient.Provider
+	identities   users.IdentityRepository
+	users        Users
+	state        stateSigner
+	callbackURL  string
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	defaultRole  users.Role
+	returnURLs   []string
+}
+
+// Option configures optional settings of the sign-in service
+type Option func(*oidcService)
+
+// WithDefaultRole sets the role of the accounts made at sign-in
+func WithDefaultRole(role users.Role) Option {
+	return func(s *oidcService) {
+		if role != "" {
+			s.defaultRole = role
+		}
+	}
+}
+
+// WithReturnURLs allows apps to have the token delivered to these URLs,
+// such as a mobile app's custom scheme
+func WithReturnURLs(urls ...string) Option {
+	return func(s *oidcService) {
+		s.returnURLs = append(s.returnURLs, urls...)
+	}
+}
+
+// NewOIDCService signs users in with providers. callbackURL is where the
+// providers send users back, with ProviderPlaceholder for the provider's
+// name; stateSecret signs the state of the flows under way.
+func NewOIDCService(
+	providers []*oidcClient.Provider,
+	identities users.IdentityRepository,
+	userService Users,
+	stateSecret string,
+	callbackURL string,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &oidcService{
+		providers:    make(map[string]*oidcClient.Provider, len(providers)),
+		identities:   identities,
+		users:        userService,
+		state:        stateSigner{secret: []byte(stateSecret)},
+		callbackURL:  callbackURL,
+		timeProvider: timeProvider,
+		logger:       logger,
+		defaultRole:  users.RoleUser,
+	}
+	for _, p := range providers {
+		s.providers[p.Name()] = p
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (s *oidcService) Start(ctx context.Context, provider string, req StartRequest) (*StartResponse, error) {
+	if req.ReturnTo != "" && !slices.Contains(s.returnURLs, req.ReturnTo) {
+		return nil, errors.NewBadRequestError("return_to is not an allowed return URL")
+	}
+	return s.start(ctx, provider, req.ReturnTo, "")
+}
+
+func (s *oidcService) StartLink(ctx context.Context, provider, userID string) (*StartResponse, error) {
+	return s.start(ctx, provider, "", userID)
+}
+
+func (s *oidcService) start(ctx context.Context, provider, returnTo, linkUserID string) (*StartResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.NewNotFoundError("Sign-in provider not found")
+	}
+
+	st, stateToken, binding, err := s.state.issue(provider, returnTo, linkUserID, s.timeProvider.Now())
+	if err != nil {
+		s.logger.Error("Failed to start sign-in", "provider", provider, "error", err)
+		return nil, errors.NewInternalError("Failed to start sign-in")
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, oidcClient.AuthRequest{
+		RedirectURI:  s.redirectURI(provider),
+		State:        stateToken,
+		Nonce:        s.state.nonce(st),
+		CodeVerifier: s.state.codeVerifier(st),
+	})
+	if err != nil {
+		s.logger.Error("Failed to reach sign-in provider", "provider", provider, "error", err)
+		return nil, errors.NewInternalError("Sign-in provider is unavailable")
+	}
+	return &StartResponse{AuthURL: authURL, Binding: binding}, nil
+}
+
+func (s *oidcService) Callback(ctx context.Context, provider string, req CallbackRequest) (_ *CallbackResult, err error) {
+	op := logging.StartOp(ctx, s.logger, "oidc.callback", "provider", provider)
+	defer op.End(&err)
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.NewNotFoundError("Sign-in provider not found")
+	}
+	if req.Error != "" {
+		return nil, errors.NewUnauthorizedError("Sign-in was not completed: " + req.Error)
+	}
+	st, err := s.state.verify(req.State, provider, req.Binding, s.timeProvider.Now())
+	if err != nil {
+		return nil, errors.NewBadRequestError("Sign-in state is invalid or expired; start again")
+	}
+	if req.Code == "" {
+		return nil, errors.NewBadRequestError("code is required")
+	}
+
+	token, err := p.Exchange(ctx, req.Code, s.redirectURI(provider), s.state.codeVerifier(st), s.state.nonce(st))
+	if err != nil {
+		if stdErrors.Is(err, oidcClient.ErrExchangeFailed) || stdErrors.Is(err, oidcClient.ErrInvalidIDToken) {
+			s.logger.Warn("Sign-in rejected", "provider", provider, "error", err)
+			return nil, errors.NewUnauthorizedError("Sign-in could not be verified")
+		}
+		return nil, err
+	}
+
+	var result *CallbackResult
+	if st.LinkUserID != "" {
+		result, err = s.link(ctx, provider, token, st.LinkUserID)
+	} else {
+		result, err = s.signIn(ctx, provider, token, req.User)
+	}
+	if err != nil {
+		return nil, err
+	}
+	op.With("user_id", result.User.ID, "provisioned", result.Provisioned, "linked", result.Linked, "merged", result.Merged)
+	result.ReturnTo = st.ReturnTo
+	return result, nil
+}
+
+// signIn finds the user the provider's subject is linked to, linking it
+// to the account with the verified email, or to a new account, the first
+// time
+func (s *oidcService) signIn(ctx context.Context, provider string, token *oidcClient.IDToken, appleUser string) (*CallbackResult, error) {
+	identity, err := s.identities.Find(ctx, provider, token.Subject)
+	if err == nil {
+		user, err := s.users.FindUserByID(ctx, string(identity.UserID))
+		if err != nil {
+			return nil, err
+		}
+		return s.active(&CallbackResult{User: user})
+	}
+	if !stdErrors.Is(err, users.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	// Linking and provisioning trust the email, so only one the provider
+	// verified will do
+	if token.Email == "" || !token.EmailVerified {
+		return nil, errors.NewForbiddenError("The provider did not verify your email address")
+	}
+
+	result := &CallbackResult{Linked: true}
+	result.User, err = s.users.FindUserByEmail(ctx, token.Email)
+	if err != nil {
+		return nil, err
+	}
+	if result.User == nil {
+		result.User, err = s.provision(ctx, token, appleUser)
+		if err != nil {
+			return nil, err
+		}
+		result.Provisioned, result.Linked = true, false
+	} else if _, err := s.active(result); err != nil {
+		return nil, err
+	}
+
+	// A user made above but not linked, say because this failed, is linked
+	// by email on their next sign-in
+	err = s.identities.Create(ctx, &users.Identity{
+		Provider:  provider,
+		Subject:   token.Subject,
+		UserID:    result.User.ID,
+		CreatedAt: s.timeProvider.Now(),
+	})
+	if err != nil && !stdErrors.Is(err, users.ErrIdentityExists) {
+		return nil, err
+	}
+	if err != nil {
+		// Linked by a sign-in that raced this one; it may have linked
+		// another account with the same email, so go by what was stored
+		return s.signIn(ctx, provider, token, appleUser)
+	}
+	return s.active(result)
+}
+
+// link adds the provider's subject to the account of userID, who started
+// the flow signed in. A subject that already signs in to another account
+// shows the user owns both, so that account is merged into this one.
+func (s *oidcService) link(ctx context.Context, provider string, token *oidcClient.IDToken, userID string) (*CallbackResult, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.active(&CallbackResult{User: user})
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identities.Find(ctx, provider, token.Subject)
+	switch {
+	case err == nil && identity.UserID == user.ID:
+		// Linked already; nothing to do
+		return result, nil
+	case err == nil:
+		if err := s.merge(ctx, string(identity.UserID), userID); err != nil {
+			return nil, err
+		}
+		result.Linked, result.Merged = true, true
+		return result, nil
+	case !stdErrors.Is(err, users.ErrIdentityNotFound):
+		return nil, err
+	}
+
+	err = s.identities.Create(ctx, &users.Identity{
+		Provider:  provider,
+		Subject:   token.Subject,
+		UserID:    user.ID,
+		CreatedAt: s.timeProvider.Now(),
+	})
+	if stdErrors.Is(err, users.ErrIdentityExists) {
+		return nil, errors.NewConflictError("The account was linked by another sign-in meanwhile; try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Linked = true
+	return result, nil
+}
+
+// merge merges the account sourceID into targetID. Accounts that could use
+// a merge to shed a restriction or take privileges along are left alone.
+func (s *oidcService) merge(ctx context.Context, sourceID, targetID string) error {
+	source, err := s.findUser(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if !source.IsActive || source.ShadowBanned || source.Role == users.RoleAdmin {
+		return errors.NewForbiddenError("The account this sign-in belongs to cannot be merged into yours")
+	}
+	if _, err := s.users.MergeUsers(ctx, sourceID, targetID); err != nil {
+		if stdErrors.Is(err, users.ErrUserNotFound) {
+			return errors.NewNotFoundError("User not found")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *oidcService) Identities(ctx context.Context, userID string) ([]*users.Identity, error) {
+	return s.identities.ListByUser(ctx, users.UserID(userID))
+}
+
+func (s *oidcService) Unlink(ctx context.Context, userID, provider, password string) (err error) {
+	op := logging.StartOp(ctx, s.logger, "oidc.unlink", "user_id", userID, "provider", provider)
+	defer op.End(&err)
+
+	identities, err := s.identities.ListByUser(ctx, users.UserID(userID))
+	if err != nil {
+		return err
+	}
+	linked := 0
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked++
+		}
+	}
+	if linked == 0 {
+		return errors.NewNotFoundError("Sign-in provider is not linked")
+	}
+
+	// Without a provider left, the password is the only way in, and
+	// accounts made at sign-in have one nobody knows
+	if linked == len(identities) {
+		user, err := s.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if password == "" || pwd.VerifyPassword(password, user.Password) != nil {
+			return errors.NewForbiddenError("Confirm your password to unlink your last sign-in provider")
+		}
+	}
+
+	if err := s.identities.Delete(ctx, users.UserID(userID), provider); err != nil {
+		if stdErrors.Is(err, users.ErrIdentityNotFound) {
+			return errors.NewNotFoundError("Sign-in provider is not linked")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *oidcService) findUser(ctx context.Context, id string) (*users.User, error) {
+	user, err := s.users.FindUserByID(ctx, id)
+	if stdErrors.Is(err, users.ErrUserNotFound) || stdErrors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return nil, errors.NewNotFoundError("User not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *oidcService) active(result *CallbackResult) (*CallbackResult, error) {
+	if !result.User.IsActive {
+		return nil, errors.NewForbiddenError("Account is deactivated")
+	}
+	return result, nil
+}
+
+// provision makes an account for a new user. It gets a random password
+// nobody knows, so it can only be signed into with the provider until the
+// user sets one.
+func (s *oidcService) provision(ctx context.Context, token *oidcClient.IDToken, appleUser string) (*users.User, error) {
+	firstName, lastName := names(token, appleUser)
+	return s.users.CreateUser(ctx, users.CreateUserRequest{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     token.Email,
+		Password:  randomString(),
+		Role:      string(s.defaultRole),
+	})
+}
+
+// names picks the user's names from the ID token, then from the user JSON
+// Apple posts, then from the full name. Users without any are named after
+// their email until they change it.
+func names(token *oidcClient.IDToken, appleUser string) (first, last string) {
+	first, last = token.GivenName, token.FamilyName
+	if first == "" && appleUser != "" {
+		var posted struct {
+			Name struct {
+				FirstName string `json:"firstName"`
+				LastName  string `json:"lastName"`
+			} `json:"name"`
+		}
+		if json.Unmarshal([]byte(appleUser), &posted) == nil {
+			first, last = posted.Name.FirstName, posted.Name.LastName
+		}
+	}
+	if first == "" && token.Name != "" {
+		first, last, _ = strings.Cut(strings.TrimSpace(token.Name), " ")
+	}
+	if first = strings.TrimSpace(first); first == "" {
+		first, _, _ = strings.Cut(token.Email, "@")
+	}
+	if last = strings.TrimSpace(last); last == "" {
+		last = "-"
+	}
+	return first, last
+}
+
+func (s *oidcService) redirectURI(provider string) string {
+	return strings.ReplaceAll(s.callbackURL, ProviderPlaceholder, provider)
+}
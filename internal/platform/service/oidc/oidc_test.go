@@ -0,0 +1,347 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	oidcClient "thermondo/internal/pkg/oidc"
+	"thermondo/internal/pkg/oidc/oidctest"
+	pwd "thermondo/internal/pkg/password"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+const testCallbackURL = "https://api.example.com/api/v1/auth/oidc/{provider}/callback"
+
+type testSetup struct {
+	service    Service
+	server     *oidctest.Server
+	identities *mockIdentityRepository
+	users      *mockUsers
+	clock      *mockTimeProvider
+}
+
+func setupTestService(t *testing.T, opts ...Option) *testSetup {
+	server := oidctest.NewServer(t, "client-1")
+	setup := &testSetup{
+		server:     server,
+		identities: new(mockIdentityRepository),
+		users:      new(mockUsers),
+		clock:      &mockTimeProvider{now: testNow},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	provider := oidcClient.NewProvider("google", server.URL, "client-1", oidcClient.WithClientSecret("s3cret"))
+	setup.service = NewOIDCService([]*oidcClient.Provider{provider}, setup.identities, setup.users, "state-secret", testCallbackURL, setup.clock, logger, opts...)
+	return setup
+}
+
+// signIn runs the flow through the fake provider, signing in as claims
+func (s *testSetup) signIn(t *testing.T, claims jwt.MapClaims) (*CallbackResult, error) {
+	t.Helper()
+	started, err := s.service.Start(context.Background(), "google", StartRequest{})
+	require.NoError(t, err)
+	code, state := s.server.Authorize(started.AuthURL, claims)
+	return s.service.Callback(context.Background(), "google", CallbackRequest{State: state, Code: code, Binding: started.Binding})
+}
+
+// link runs the flow userID started to link the fake provider
+func (s *testSetup) link(t *testing.T, userID string, claims jwt.MapClaims) (*CallbackResult, error) {
+	t.Helper()
+	started, err := s.service.StartLink(context.Background(), "google", userID)
+	require.NoError(t, err)
+	code, state := s.server.Authorize(started.AuthURL, claims)
+	return s.service.Callback(context.Background(), "google", CallbackRequest{State: state, Code: code, Binding: started.Binding})
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+var verified = jwt.MapClaims{
+	"sub": "google-1", "email": "ada@example.com", "email_verified": true,
+	"given_name": "Ada", "family_name": "Lovelace",
+}
+
+func TestStart(t *testing.T) {
+	setup := setupTestService(t, WithReturnURLs("thermondo://auth"))
+
+	started, err := setup.service.Start(context.Background(), "google", StartRequest{ReturnTo: "thermondo://auth"})
+	require.NoError(t, err)
+	assert.Contains(t, started.AuthURL, setup.server.URL+"/authorize?")
+	assert.Contains(t, started.AuthURL, "redirect_uri=https%3A%2F%2Fapi.example.com%2Fapi%2Fv1%2Fauth%2Foidc%2Fgoogle%2Fcallback")
+	assert.NotEmpty(t, started.Binding)
+	assert.Equal(t, []string{"google"}, setup.service.Providers())
+
+	_, err = setup.service.Start(context.Background(), "myspace", StartRequest{})
+	assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	_, err = setup.service.Start(context.Background(), "google", StartRequest{ReturnTo: "https://evil.example.com"})
+	assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+}
+
+func TestCallback_KnownIdentity(t *testing.T) {
+	setup := setupTestService(t)
+	user := &users.User{ID: "user-1", Email: "ada@example.com", IsActive: true}
+	setup.identities.On("Find", mock.Anything, "google", "google-1").Return(&users.Identity{Provider: "google", Subject: "google-1", UserID: "user-1"}, nil)
+	setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+
+	// The provider may have stopped vouching for the email; the subject is
+	// what counts
+	result, err := setup.signIn(t, jwt.MapClaims{"sub": "google-1", "email": "other@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, user, result.User)
+	assert.False(t, result.Provisioned)
+	assert.False(t, result.Linked)
+	setup.users.AssertNotCalled(t, "FindUserByEmail", mock.Anything, mock.Anything)
+}
+
+func TestCallback_LinksByVerifiedEmail(t *testing.T) {
+	setup := setupTestService(t)
+	user := &users.User{ID: "user-1", Email: "ada@example.com", Role: users.RoleAdmin, IsActive: true}
+	setup.identities.On("Find", mock.Anything, "google", "google-1").Return(nil, users.ErrIdentityNotFound)
+	setup.users.On("FindUserByEmail", mock.Anything, "ada@example.com").Return(user, nil)
+	setup.identities.On("Create", mock.Anything, &users.Identity{Provider: "google", Subject: "google-1", UserID: "user-1", CreatedAt: testNow}).Return(nil)
+
+	result, err := setup.signIn(t, verified)
+	require.NoError(t, err)
+	assert.Equal(t, user, result.User, "the account keeps its role")
+	assert.True(t, result.Linked)
+	assert.False(t, result.Provisioned)
+	setup.identities.AssertExpectations(t)
+}
+
+func TestCallback_Provisions(t *testing.T) {
+	setup := setupTestService(t, WithDefaultRole(users.RoleAdmin))
+	setup.identities.On("Find", mock.Anything, "google", "google-1").Return(nil, users.ErrIdentityNotFound)
+	setup.users.On("FindUserByEmail", mock.Anything, "ada@example.com").Return(nil, nil)
+	var created users.CreateUserRequest
+	setup.users.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(1).(users.CreateUserRequest)
+	}).Return(&users.User{ID: "user-2", Email: "ada@example.com", IsActive: true}, nil)
+	setup.identities.On("Create", mock.Anything, mock.MatchedBy(func(i *users.Identity) bool { return i.UserID == "user-2" })).Return(nil)
+
+	result, err := setup.signIn(t, verified)
+	require.NoError(t, err)
+	assert.True(t, result.Provisioned)
+	assert.False(t, result.Linked)
+	assert.Equal(t, "Ada", created.FirstName)
+	assert.Equal(t, "Lovelace", created.LastName)
+	assert.Equal(t, "ada@example.com", created.Email)
+	assert.Equal(t, "admin", created.Role, "the configured default role")
+	assert.Len(t, created.Password, 43, "a random password nobody knows")
+}
+
+func TestCallback_Rejections(t *testing.T) {
+	t.Run("unverified email", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.identities.On("Find", mock.Anything, "google", "google-1").Return(nil, users.ErrIdentityNotFound)
+
+		_, err := setup.signIn(t, jwt.MapClaims{"sub": "google-1", "email": "ada@example.com", "email_verified": false})
+		assertAppErrorCode(t, err, appErrors.CodeForbidden)
+		setup.users.AssertNotCalled(t, "FindUserByEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("deactivated account", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.identities.On("Find", mock.Anything, "google", "google-1").Return(nil, users.ErrIdentityNotFound)
+		setup.users.On("FindUserByEmail", mock.Anything, "ada@example.com").Return(&users.User{ID: "user-1", IsActive: false}, nil)
+
+		_, err := setup.signIn(t, verified)
+		assertAppErrorCode(t, err, appErrors.CodeForbidden)
+		setup.identities.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("state from another browser", func(t *testing.T) {
+		setup := setupTestService(t)
+		started, err := setup.service.Start(context.Background(), "google", StartRequest{})
+		require.NoError(t, err)
+		code, state := setup.server.Authorize(started.AuthURL, verified)
+
+		_, err = setup.service.Callback(context.Background(), "google", CallbackRequest{State: state, Code: code, Binding: "attacker"})
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		setup := setupTestService(t)
+		started, err := setup.service.Start(context.Background(), "google", StartRequest{})
+		require.NoError(t, err)
+		code, state := setup.server.Authorize(started.AuthURL, verified)
+		setup.clock.now = testNow.Add(StateLifetime + time.Second)
+
+		_, err = setup.service.Callback(context.Background(), "google", CallbackRequest{State: state, Code: code, Binding: started.Binding})
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+
+	t.Run("refused by the user", func(t *testing.T) {
+		setup := setupTestService(t)
+		_, err := setup.service.Callback(context.Background(), "google", CallbackRequest{Error: "access_denied"})
+		assertAppErrorCode(t, err, appErrors.CodeUnauthorized)
+	})
+
+	t.Run("code the provider rejects", func(t *testing.T) {
+		setup := setupTestService(t)
+		started, err := setup.service.Start(context.Background(), "google", StartRequest{})
+		require.NoError(t, err)
+		_, state := setup.server.Authorize(started.AuthURL, verified)
+
+		_, err = setup.service.Callback(context.Background(), "google", CallbackRequest{State: state, Code: "forged", Binding: started.Binding})
+		assertAppErrorCode(t, err, appErrors.CodeUnauthorized)
+	})
+}
+
+func TestNames(t *testing.T) {
+	for name, tc := range map[string]struct {
+		token       oidcClient.IDToken
+		appleUser   string
+		first, last string
+	}{
+		"claims":         {token: oidcClient.IDToken{GivenName: "Ada", FamilyName: "Lovelace"}, first: "Ada", last: "Lovelace"},
+		"apple user":     {appleUser: `{"name":{"firstName":"Ada","lastName":"Lovelace"},"email":"ada@example.com"}`, first: "Ada", last: "Lovelace"},
+		"full name":      {token: oidcClient.IDToken{Name: "Ada King Lovelace"}, first: "Ada", last: "King Lovelace"},
+		"email only":     {token: oidcClient.IDToken{Email: "ada@example.com"}, first: "ada", last: "-"},
+		"malformed user": {token: oidcClient.IDToken{Email: "ada@example.com"}, appleUser: "{", first: "ada", last: "-"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			first, last := names(&tc.token, tc.appleUser)
+			assert.Equal(t, tc.first, first)
+			assert.Equal(t, tc.last, last)
+		})
+	}
+}
+
+func TestCallback_Link(t *testing.T) {
+	user := &users.User{ID: "user-1", Email: "ada@example.com", IsActive: true}
+
+	t.Run("new identity", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+		setup.identities.On("Find", mock.Anything, "google", "google-1").Return(nil, users.ErrIdentityNotFound)
+		setup.identities.On("Create", mock.Anything, &users.Identity{Provider: "google", Subject: "google-1", UserID: "user-1", CreatedAt: testNow}).Return(nil)
+
+		// Whatever email the provider has, the user proved they own it
+		result, err := setup.link(t, "user-1", jwt.MapClaims{"sub": "google-1", "email": "other@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, user, result.User)
+		assert.True(t, result.Linked)
+		assert.False(t, result.Merged)
+		setup.identities.AssertExpectations(t)
+	})
+
+	t.Run("linked already", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+		setup.identities.On("Find", mock.Anything, "google", "google-1").Return(&users.Identity{UserID: "user-1"}, nil)
+
+		result, err := setup.link(t, "user-1", verified)
+		require.NoError(t, err)
+		assert.False(t, result.Linked)
+		setup.identities.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("merges the other account", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+		setup.users.On("FindUserByID", mock.Anything, "user-2").Return(&users.User{ID: "user-2", Role: users.RoleUser, IsActive: true}, nil)
+		setup.identities.On("Find", mock.Anything, "google", "google-1").Return(&users.Identity{UserID: "user-2"}, nil)
+		setup.users.On("MergeUsers", mock.Anything, "user-2", "user-1").Return(&users.MergeRecord{SourceID: "user-2", TargetID: "user-1"}, nil)
+
+		result, err := setup.link(t, "user-1", verified)
+		require.NoError(t, err)
+		assert.Equal(t, user, result.User)
+		assert.True(t, result.Linked)
+		assert.True(t, result.Merged)
+		setup.users.AssertExpectations(t)
+	})
+
+	for name, source := range map[string]*users.User{
+		"admin":         {ID: "user-2", Role: users.RoleAdmin, IsActive: true},
+		"shadow banned": {ID: "user-2", Role: users.RoleUser, IsActive: true, ShadowBanned: true},
+		"deactivated":   {ID: "user-2", Role: users.RoleUser},
+	} {
+		t.Run("refuses to merge "+name, func(t *testing.T) {
+			setup := setupTestService(t)
+			setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+			setup.users.On("FindUserByID", mock.Anything, "user-2").Return(source, nil)
+			setup.identities.On("Find", mock.Anything, "google", "google-1").Return(&users.Identity{UserID: "user-2"}, nil)
+
+			_, err := setup.link(t, "user-1", verified)
+			assertAppErrorCode(t, err, appErrors.CodeForbidden)
+			setup.users.AssertNotCalled(t, "MergeUsers", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("deactivated user", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(&users.User{ID: "user-1"}, nil)
+
+		_, err := setup.link(t, "user-1", verified)
+		assertAppErrorCode(t, err, appErrors.CodeForbidden)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(nil, users.ErrUserNotFound)
+
+		_, err := setup.link(t, "user-1", verified)
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestUnlink(t *testing.T) {
+	hash, err := pwd.HashPassword("correct horse")
+	require.NoError(t, err)
+	user := &users.User{ID: "user-1", Password: hash, IsActive: true}
+	both := []*users.Identity{{Provider: "google", UserID: "user-1"}, {Provider: "apple", UserID: "user-1"}}
+	googleOnly := both[:1]
+
+	t.Run("another provider remains", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.identities.On("ListByUser", mock.Anything, users.UserID("user-1")).Return(both, nil)
+		setup.identities.On("Delete", mock.Anything, users.UserID("user-1"), "google").Return(nil)
+
+		require.NoError(t, setup.service.Unlink(context.Background(), "user-1", "google", ""))
+		setup.users.AssertNotCalled(t, "FindUserByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("last provider with the password", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.identities.On("ListByUser", mock.Anything, users.UserID("user-1")).Return(googleOnly, nil)
+		setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+		setup.identities.On("Delete", mock.Anything, users.UserID("user-1"), "google").Return(nil)
+
+		require.NoError(t, setup.service.Unlink(context.Background(), "user-1", "google", "correct horse"))
+	})
+
+	t.Run("last provider without the password", func(t *testing.T) {
+		for _, password := range []string{"", "wrong"} {
+			setup := setupTestService(t)
+			setup.identities.On("ListByUser", mock.Anything, users.UserID("user-1")).Return(googleOnly, nil)
+			setup.users.On("FindUserByID", mock.Anything, "user-1").Return(user, nil)
+
+			err := setup.service.Unlink(context.Background(), "user-1", "google", password)
+			assertAppErrorCode(t, err, appErrors.CodeForbidden)
+			setup.identities.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("not linked", func(t *testing.T) {
+		setup := setupTestService(t)
+		setup.identities.On("ListByUser", mock.Anything, users.UserID("user-1")).Return(googleOnly, nil)
+
+		err := setup.service.Unlink(context.Background(), "user-1", "apple", "")
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
@@ -0,0 +1,106 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// StateLifetime is how long the user has to sign in with the provider
+const StateLifetime = 10 * time.Minute
+
+var errInvalidState = errors.New("invalid or expired sign-in state")
+
+// state is what the callback needs to know about the flow it ends. It
+// travels through the provider as the signed state parameter, so no flow
+// has to be stored; the nonce and PKCE verifier are derived from its ID
+// with the secret and never leave the service.
+type state struct {
+	Provider string `json:"prv"`
+	// BindingHash is the hash of the random value set as a cookie on the
+	// browser that started the flow, so a callback can't be finished on
+	// another browser
+	BindingHash string `json:"bnd"`
+	ReturnTo    string `json:"rt,omitempty"`
+	// LinkUserID is set when a signed-in user is adding the provider to
+	// their account rather than signing in
+	LinkUserID string `json:"lnk,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type stateSigner struct {
+	secret []byte
+}
+
+// issue starts a flow, returning its state, the state parameter and the
+// binding to set as a cookie
+func (s stateSigner) issue(provider, returnTo, linkUserID string, now time.Time) (st *state, token, binding string, err error) {
+	binding = randomString()
+	st = &state{
+		Provider:    provider,
+		BindingHash: hashBinding(binding),
+		ReturnTo:    returnTo,
+		LinkUserID:  linkUserID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomString(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(StateLifetime)),
+		},
+	}
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, st).SignedString(s.secret)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to sign state: %w", err)
+	}
+	return st, token, binding, nil
+}
+
+// verify checks token was issued for provider to the browser holding
+// binding, and has not expired
+func (s stateSigner) verify(token, provider, binding string, now time.Time) (*state, error) {
+	st := &state{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithTimeFunc(func() time.Time { return now }))
+	if _, err := parser.ParseWithClaims(token, st, func(*jwt.Token) (any, error) { return s.secret, nil }); err != nil {
+		return nil, errInvalidState
+	}
+	if st.Provider != provider || st.ID == "" || binding == "" ||
+		subtle.ConstantTimeCompare([]byte(st.BindingHash), []byte(hashBinding(binding))) != 1 {
+		return nil, errInvalidState
+	}
+	return st, nil
+}
+
+// nonce and codeVerifier are derived from the state's ID, so only the
+// service can compute them
+func (s stateSigner) nonce(st *state) string {
+	return s.derive("nonce", st.ID)
+}
+
+func (s stateSigner) codeVerifier(st *state) string {
+	return s.derive("pkce", st.ID)
+}
+
+func (s stateSigner) derive(purpose, id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose + ":" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashBinding(binding string) string {
+	sum := sha256.Sum256([]byte(binding))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("oidc: failed to read random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,95 @@
+package partners
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/partners"
+	ratingService "thermondo/internal/platform/service/rating"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockPartnerRepository struct {
+	mock.Mock
+}
+
+func (m *mockPartnerRepository) CreateKey(ctx context.Context, key *partners.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *mockPartnerRepository) FindKeyByHash(ctx context.Context, hash string) (*partners.APIKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*partners.APIKey), args.Error(1)
+}
+
+func (m *mockPartnerRepository) ListKeys(ctx context.Context) ([]*partners.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*partners.APIKey), args.Error(1)
+}
+
+func (m *mockPartnerRepository) RevokeKey(ctx context.Context, id partners.KeyID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *mockPartnerRepository) RecordUsage(ctx context.Context, id partners.KeyID, endpoint string, at time.Time) error {
+	args := m.Called(ctx, id, endpoint, at)
+	return args.Error(0)
+}
+
+func (m *mockPartnerRepository) GetUsage(ctx context.Context, filter partners.UsageFilter) ([]*partners.Usage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*partners.Usage), args.Error(1)
+}
+
+type mockScoreSource struct {
+	mock.Mock
+}
+
+func (m *mockScoreSource) GetEnhancedMovieStats(ctx context.Context, movieID string) (*ratingService.EnhancedMovieStats, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.EnhancedMovieStats), args.Error(1)
+}
+
+type mockMovieChecker struct {
+	mock.Mock
+}
+
+func (m *mockMovieChecker) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// mockIDGenerator hands out id-1, id-2, ...
+type mockIDGenerator struct {
+	issued int
+}
+
+func (m *mockIDGenerator) Generate() string {
+	m.issued++
+	return fmt.Sprintf("id-%d", m.issued)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,231 @@
+package partners
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/partners"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+	"time"
+)
+
+// MaxUsageDays bounds a usage report to about a year of days
+const MaxUsageDays = 366
+
+// Service manages partner API keys, meters their use and serves the
+// aggregate scores partners may read
+type Service interface {
+	// CreateKey issues a key. The secret is only ever returned here.
+	CreateKey(ctx context.Context, req CreateKeyRequest) (*CreatedKey, error)
+	ListKeys(ctx context.Context) ([]*partners.APIKey, error)
+	RevokeKey(ctx context.Context, id string) error
+	// Authenticate resolves a secret to its key. Unknown and revoked keys
+	// are rejected with a 401.
+	Authenticate(ctx context.Context, secret string) (*partners.APIKey, error)
+	// RecordUsage counts a request of the key to endpoint, a route pattern
+	RecordUsage(ctx context.Context, keyID partners.KeyID, endpoint string) error
+	// GetUsage reports the requests per key, endpoint and day in the range
+	GetUsage(ctx context.Context, req UsageRequest) (*UsageReport, error)
+	// GetScore returns a movie's Bayesian score and what it rests on
+	GetScore(ctx context.Context, movieID string) (*Score, error)
+}
+
+// ScoreSource computes the Bayesian score of a movie
+type ScoreSource interface {
+	GetEnhancedMovieStats(ctx context.Context, movieID string) (*ratingService.EnhancedMovieStats, error)
+}
+
+// MovieChecker tells whether a movie exists
+type MovieChecker interface {
+	Exists(ctx context.Context, id movies.MovieID) (bool, error)
+}
+
+type CreateKeyRequest struct {
+	Name      string           `json:"name"`
+	Scopes    []partners.Scope `json:"scopes"`
+	RateLimit int              `json:"rate_limit"`
+}
+
+// CreatedKey is a new key along with its secret
+type CreatedKey struct {
+	*partners.APIKey
+	Secret string `json:"secret"`
+}
+
+// UsageRequest holds the raw query of a usage report. From and To are
+// dates (YYYY-MM-DD), inclusive; they default to the current month so far.
+type UsageRequest struct {
+	From  string
+	To    string
+	KeyID string
+}
+
+type UsageReport struct {
+	From          string            `json:"from"`
+	To            string            `json:"to"`
+	KeyID         string            `json:"key_id,omitempty"`
+	TotalRequests int64             `json:"total_requests"`
+	Usage         []*partners.Usage `json:"usage"`
+}
+
+// Score is all the partner API reveals about a movie's ratings
+type Score struct {
+	MovieID string  `json:"movie_id"`
+	Score   float64 `json:"score"`
+	Count   int64   `json:"count"`
+	// Confidence grows from 0 to 1 as the movie gathers the minimum number
+	// of ratings for its score to be reliable
+	Confidence float64 `json:"confidence"`
+}
+
+type partnerService struct {
+	repo         partners.Repository
+	scores       ScoreSource
+	movies       MovieChecker
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+}
+
+func NewPartnerService(
+	repo partners.Repository,
+	scores ScoreSource,
+	movies MovieChecker,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+) Service {
+	return &partnerService{
+		repo:         repo,
+		scores:       scores,
+		movies:       movies,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+	}
+}
+
+func (s *partnerService) CreateKey(ctx context.Context, req CreateKeyRequest) (*CreatedKey, error) {
+	key, secret, err := partners.NewAPIKey(partners.KeyID(s.idGenerator.Generate()), req.Name, req.Scopes, req.RateLimit, s.timeProvider.Now())
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		s.logger.Error("Failed to create API key", "error", err, "name", key.Name)
+		return nil, errors.NewInternalError("Failed to create API key")
+	}
+
+	s.logger.Info("Created API key", "key_id", key.ID, "name", key.Name, "scopes", key.Scopes)
+	return &CreatedKey{APIKey: key, Secret: secret}, nil
+}
+
+func (s *partnerService) ListKeys(ctx context.Context) ([]*partners.APIKey, error) {
+	keys, err := s.repo.ListKeys(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list API keys", "error", err)
+		return nil, errors.NewInternalError("Failed to list API keys")
+	}
+	return keys, nil
+}
+
+func (s *partnerService) RevokeKey(ctx context.Context, id string) error {
+	if err := s.repo.RevokeKey(ctx, partners.KeyID(id), s.timeProvider.Now()); err != nil {
+		if stdErrors.Is(err, partners.ErrKeyNotFound) {
+			return errors.NewNotFoundError("API key not found")
+		}
+		s.logger.Error("Failed to revoke API key", "error", err, "key_id", id)
+		return errors.NewInternalError("Failed to revoke API key")
+	}
+
+	s.logger.Info("Revoked API key", "key_id", id)
+	return nil
+}
+
+func (s *partnerService) Authenticate(ctx context.Context, secret string) (*partners.APIKey, error) {
+	if secret == "" {
+		return nil, errors.NewUnauthorizedError("Missing API key")
+	}
+	key, err := s.repo.FindKeyByHash(ctx, partners.HashKey(secret))
+	if err != nil {
+		if stdErrors.Is(err, partners.ErrKeyNotFound) {
+			return nil, errors.NewUnauthorizedError("Invalid API key")
+		}
+		s.logger.Error("Failed to find API key", "error", err)
+		return nil, errors.NewInternalError("Failed to verify API key")
+	}
+	if !key.Active() {
+		return nil, errors.NewUnauthorizedError("API key has been revoked")
+	}
+	return key, nil
+}
+
+func (s *partnerService) RecordUsage(ctx context.Context, keyID partners.KeyID, endpoint string) error {
+	if err := s.repo.RecordUsage(ctx, keyID, endpoint, s.timeProvider.Now()); err != nil {
+		s.logger.Error("Failed to record API key usage", "error", err, "key_id", keyID, "endpoint", endpoint)
+		return errors.NewInternalError("Failed to record usage")
+	}
+	return nil
+}
+
+func (s *partnerService) GetUsage(ctx context.Context, req UsageRequest) (*UsageReport, error) {
+	now := s.timeProvider.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if req.From != "" {
+		if from, err = time.Parse(time.DateOnly, req.From); err != nil {
+			return nil, errors.NewBadRequestError("from must be a date like 2024-01-31")
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse(time.DateOnly, req.To); err != nil {
+			return nil, errors.NewBadRequestError("to must be a date like 2024-01-31")
+		}
+	}
+	if to.Before(from) {
+		return nil, errors.NewBadRequestError("from must not be after to")
+	}
+	if to.Sub(from) >= MaxUsageDays*24*time.Hour {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("a report covers at most %d days", MaxUsageDays))
+	}
+
+	usage, err := s.repo.GetUsage(ctx, partners.UsageFilter{From: from, To: to, KeyID: partners.KeyID(req.KeyID)})
+	if err != nil {
+		s.logger.Error("Failed to get API key usage", "error", err, "key_id", req.KeyID)
+		return nil, errors.NewInternalError("Failed to get usage")
+	}
+
+	report := &UsageReport{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), KeyID: req.KeyID, Usage: usage}
+	for _, u := range usage {
+		report.TotalRequests += u.Requests
+	}
+	return report, nil
+}
+
+func (s *partnerService) GetScore(ctx context.Context, movieID string) (*Score, error) {
+	exists, err := s.movies.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		s.logger.Error("Failed to check movie", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get score")
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError("Movie not found")
+	}
+
+	stats, err := s.scores.GetEnhancedMovieStats(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+	return &Score{
+		MovieID:    movieID,
+		Score:      math.Round(stats.BayesianAverage*100) / 100,
+		Count:      stats.TotalRatings,
+		Confidence: math.Round(stats.Confidence*100) / 100,
+	}, nil
+}
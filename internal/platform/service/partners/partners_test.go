@@ -0,0 +1,167 @@
+package partners
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/partners"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+)
+
+var testNow = time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+type testDeps struct {
+	repo   *mockPartnerRepository
+	scores *mockScoreSource
+	movies *mockMovieChecker
+}
+
+func setupTestService() (Service, *testDeps) {
+	deps := &testDeps{repo: new(mockPartnerRepository), scores: new(mockScoreSource), movies: new(mockMovieChecker)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewPartnerService(deps.repo, deps.scores, deps.movies, &mockIDGenerator{}, &mockTimeProvider{now: testNow}, logger), deps
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestCreateKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores only the hash", func(t *testing.T) {
+		service, deps := setupTestService()
+		var stored *partners.APIKey
+		deps.repo.On("CreateKey", ctx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*partners.APIKey)
+		}).Return(nil)
+
+		created, err := service.CreateKey(ctx, CreateKeyRequest{Name: "Acme", Scopes: []partners.Scope{partners.ScopeScoresRead}, RateLimit: 60})
+		require.NoError(t, err)
+		assert.Equal(t, partners.KeyID("id-1"), created.ID)
+		assert.True(t, strings.HasPrefix(created.Secret, partners.KeyPrefix))
+		assert.Equal(t, partners.HashKey(created.Secret), stored.Hash)
+		assert.Equal(t, 60, stored.RateLimit)
+	})
+
+	t.Run("rejects unknown scopes", func(t *testing.T) {
+		service, deps := setupTestService()
+		_, err := service.CreateKey(ctx, CreateKeyRequest{Name: "Acme", Scopes: []partners.Scope{"ratings:write"}})
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		deps.repo.AssertNotCalled(t, "CreateKey", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRevokeKey(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupTestService()
+	deps.repo.On("RevokeKey", ctx, partners.KeyID("key-1"), testNow).Return(nil)
+	deps.repo.On("RevokeKey", ctx, partners.KeyID("key-2"), testNow).Return(partners.ErrKeyNotFound)
+
+	require.NoError(t, service.RevokeKey(ctx, "key-1"))
+	assertAppErrorCode(t, service.RevokeKey(ctx, "key-2"), appErrors.CodeNotFound)
+}
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	revokedAt := testNow
+	service, deps := setupTestService()
+	deps.repo.On("FindKeyByHash", ctx, partners.HashKey("pk_live")).Return(&partners.APIKey{ID: "key-1"}, nil)
+	deps.repo.On("FindKeyByHash", ctx, partners.HashKey("pk_revoked")).Return(&partners.APIKey{ID: "key-2", RevokedAt: &revokedAt}, nil)
+	deps.repo.On("FindKeyByHash", ctx, partners.HashKey("pk_unknown")).Return(nil, partners.ErrKeyNotFound)
+
+	key, err := service.Authenticate(ctx, "pk_live")
+	require.NoError(t, err)
+	assert.Equal(t, partners.KeyID("key-1"), key.ID)
+
+	for _, secret := range []string{"", "pk_revoked", "pk_unknown"} {
+		_, err := service.Authenticate(ctx, secret)
+		assertAppErrorCode(t, err, appErrors.CodeUnauthorized)
+	}
+}
+
+func TestGetUsage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("defaults to the month so far", func(t *testing.T) {
+		service, deps := setupTestService()
+		filter := partners.UsageFilter{From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}
+		deps.repo.On("GetUsage", ctx, filter).Return([]*partners.Usage{
+			{KeyID: "key-1", Endpoint: "/movies/{id}/score", Requests: 40},
+			{KeyID: "key-2", Endpoint: "/movies/{id}/score", Requests: 2},
+		}, nil)
+
+		report, err := service.GetUsage(ctx, UsageRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-01", report.From)
+		assert.Equal(t, "2024-03-15", report.To)
+		assert.Equal(t, int64(42), report.TotalRequests)
+	})
+
+	t.Run("filters by key", func(t *testing.T) {
+		service, deps := setupTestService()
+		filter := partners.UsageFilter{From: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), KeyID: "key-1"}
+		deps.repo.On("GetUsage", ctx, filter).Return([]*partners.Usage{}, nil)
+
+		report, err := service.GetUsage(ctx, UsageRequest{From: "2024-02-01", To: "2024-02-29", KeyID: "key-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", report.KeyID)
+		assert.Zero(t, report.TotalRequests)
+	})
+
+	for name, req := range map[string]UsageRequest{
+		"malformed from": {From: "03/01/2024"},
+		"malformed to":   {To: "yesterday"},
+		"reversed":       {From: "2024-03-10", To: "2024-03-01"},
+		"too long":       {From: "2023-01-01", To: "2024-03-01"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, deps := setupTestService()
+			_, err := service.GetUsage(ctx, req)
+			assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+			deps.repo.AssertNotCalled(t, "GetUsage", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetScore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns only the aggregate", func(t *testing.T) {
+		service, deps := setupTestService()
+		deps.movies.On("Exists", ctx, mock.Anything).Return(true, nil)
+		deps.scores.On("GetEnhancedMovieStats", ctx, "movie-1").Return(&ratingService.EnhancedMovieStats{
+			MovieRatingStats: &rating.MovieRatingStats{TotalRatings: 12},
+			BayesianAverage:  3.8765,
+			Confidence:       0.404,
+			Percentile:       88,
+		}, nil)
+
+		score, err := service.GetScore(ctx, "movie-1")
+		require.NoError(t, err)
+		assert.Equal(t, &Score{MovieID: "movie-1", Score: 3.88, Count: 12, Confidence: 0.4}, score)
+	})
+
+	t.Run("unknown movie", func(t *testing.T) {
+		service, deps := setupTestService()
+		deps.movies.On("Exists", ctx, mock.Anything).Return(false, nil)
+
+		_, err := service.GetScore(ctx, "movie-x")
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+		deps.scores.AssertNotCalled(t, "GetEnhancedMovieStats", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,96 @@
+package people
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockPeopleRepository struct {
+	mock.Mock
+}
+
+func (m *mockPeopleRepository) Save(ctx context.Context, person *people.Person) (*people.Person, error) {
+	args := m.Called(ctx, person)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *mockPeopleRepository) GetByID(ctx context.Context, id people.PersonID) (*people.Person, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *mockPeopleRepository) GetByName(ctx context.Context, name string) (*people.Person, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Person), args.Error(1)
+}
+
+func (m *mockPeopleRepository) SaveCredit(ctx context.Context, credit *people.Credit) (*people.Credit, error) {
+	args := m.Called(ctx, credit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*people.Credit), args.Error(1)
+}
+
+func (m *mockPeopleRepository) DeleteCredit(ctx context.Context, movieID movies.MovieID, personID people.PersonID, role people.Role) error {
+	args := m.Called(ctx, movieID, personID, role)
+	return args.Error(0)
+}
+
+func (m *mockPeopleRepository) GetMovieCredits(ctx context.Context, movieID movies.MovieID) ([]*people.Credit, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*people.Credit), args.Error(1)
+}
+
+func (m *mockPeopleRepository) GetPersonMovies(ctx context.Context, personID people.PersonID, role people.Role, options ...movies.SearchOption) ([]*people.CreditedMovie, int64, error) {
+	args := m.Called(ctx, personID, role, options)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*people.CreditedMovie), args.Get(1).(int64), args.Error(2)
+}
+
+// mockMovieRepository only implements the movie lookups the people service
+// uses; calling any other movies.Repository method panics
+type mockMovieRepository struct {
+	movies.Repository
+	mock.Mock
+}
+
+func (m *mockMovieRepository) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockIDGenerator struct {
+	id string
+}
+
+func (m *mockIDGenerator) Generate() string {
+	return m.id
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
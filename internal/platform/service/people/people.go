@@ -0,0 +1,185 @@
+package people
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/errors"
+)
+
+type Service interface {
+	CreatePerson(ctx context.Context, req CreatePersonRequest) (*people.Person, error)
+	GetPerson(ctx context.Context, id string) (*people.Person, error)
+	// GetPersonMovies lists the movies a person is credited on; role narrows
+	// the list to one role and may be empty
+	GetPersonMovies(ctx context.Context, personID, role string, limit, offset int) ([]*people.CreditedMovie, int64, error)
+
+	GetMovieCredits(ctx context.Context, movieID string) ([]*people.Credit, error)
+	AddCredit(ctx context.Context, movieID string, req AddCreditRequest) (*people.Credit, error)
+	RemoveCredit(ctx context.Context, movieID, personID, role string) error
+}
+
+type peopleService struct {
+	peopleRepo   people.Repository
+	movieRepo    movies.Repository
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+}
+
+func NewPeopleService(
+	peopleRepo people.Repository,
+	movieRepo movies.Repository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+) Service {
+	return &peopleService{
+		peopleRepo:   peopleRepo,
+		movieRepo:    movieRepo,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+	}
+}
+
+func (s *peopleService) CreatePerson(ctx context.Context, req CreatePersonRequest) (*people.Person, error) {
+	person, err := people.NewPerson(req.Name, s.idGenerator, s.timeProvider)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	saved, err := s.peopleRepo.Save(ctx, person)
+	if err != nil {
+		if isConflictError(err) {
+			return nil, errors.NewConflictError("Person with this ID already exists")
+		}
+		s.logger.Error("Failed to create person", "error", err)
+		return nil, errors.NewInternalError("Failed to create person")
+	}
+
+	return saved, nil
+}
+
+func (s *peopleService) GetPerson(ctx context.Context, id string) (*people.Person, error) {
+	person, err := s.peopleRepo.GetByID(ctx, people.PersonID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Person not found")
+		}
+		s.logger.Error("Failed to get person", "error", err, "person_id", id)
+		return nil, errors.NewInternalError("Failed to get person")
+	}
+
+	return person, nil
+}
+
+func (s *peopleService) GetPersonMovies(ctx context.Context, personID, role string, limit, offset int) ([]*people.CreditedMovie, int64, error) {
+	roleFilter := people.Role(strings.ToLower(strings.TrimSpace(role)))
+	if roleFilter != "" && !roleFilter.IsValid() {
+		return nil, 0, errors.NewBadRequestError(people.ErrInvalidRole.Error())
+	}
+
+	// Distinguish an unknown person from one without credits
+	if _, err := s.GetPerson(ctx, personID); err != nil {
+		return nil, 0, err
+	}
+
+	credited, total, err := s.peopleRepo.GetPersonMovies(ctx, people.PersonID(personID), roleFilter,
+		movies.WithLimit(limit),
+		movies.WithOffset(offset),
+	)
+	if err != nil {
+		s.logger.Error("Failed to get person movies", "error", err, "person_id", personID)
+		return nil, 0, errors.NewInternalError("Failed to get person movies")
+	}
+
+	return credited, total, nil
+}
+
+func (s *peopleService) GetMovieCredits(ctx context.Context, movieID string) ([]*people.Credit, error) {
+	if err := s.requireMovie(ctx, movieID); err != nil {
+		return nil, err
+	}
+
+	credits, err := s.peopleRepo.GetMovieCredits(ctx, movies.MovieID(movieID))
+	if err != nil {
+		s.logger.Error("Failed to get movie credits", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get movie credits")
+	}
+	if credits == nil {
+		credits = []*people.Credit{}
+	}
+
+	return credits, nil
+}
+
+func (s *peopleService) AddCredit(ctx context.Context, movieID string, req AddCreditRequest) (*people.Credit, error) {
+	credit, err := people.NewCredit(
+		movies.MovieID(movieID),
+		people.PersonID(strings.TrimSpace(req.PersonID)),
+		people.Role(req.Role),
+		req.Character,
+		req.BillingOrder,
+		s.timeProvider,
+	)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	if err := s.requireMovie(ctx, movieID); err != nil {
+		return nil, err
+	}
+	person, err := s.GetPerson(ctx, string(credit.PersonID))
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.peopleRepo.SaveCredit(ctx, credit)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie or person not found")
+		}
+		s.logger.Error("Failed to save credit", "error", err, "movie_id", movieID, "person_id", credit.PersonID)
+		return nil, errors.NewInternalError("Failed to save credit")
+	}
+	saved.PersonName = person.Name
+
+	return saved, nil
+}
+
+func (s *peopleService) RemoveCredit(ctx context.Context, movieID, personID, role string) error {
+	err := s.peopleRepo.DeleteCredit(ctx, movies.MovieID(movieID), people.PersonID(personID), people.Role(strings.ToLower(role)))
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Credit not found")
+		}
+		s.logger.Error("Failed to delete credit", "error", err, "movie_id", movieID, "person_id", personID)
+		return errors.NewInternalError("Failed to delete credit")
+	}
+
+	return nil
+}
+
+func (s *peopleService) requireMovie(ctx context.Context, movieID string) error {
+	exists, err := s.movieRepo.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		s.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+	return nil
+}
+
+func isNotFoundError(err error) bool {
+	return strings.Contains(err.Error(), "not found")
+}
+
+func isConflictError(err error) bool {
+	return strings.Contains(err.Error(), "already exists")
+}
@@ -0,0 +1,149 @@
+package people
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/people"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+func setupTestService() (Service, *mockPeopleRepository, *mockMovieRepository) {
+	peopleRepo := new(mockPeopleRepository)
+	movieRepo := new(mockMovieRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	service := NewPeopleService(
+		peopleRepo,
+		movieRepo,
+		&mockIDGenerator{id: "person-123"},
+		&mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		logger,
+	)
+	return service, peopleRepo, movieRepo
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestCreatePerson(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates a person", func(t *testing.T) {
+		service, peopleRepo, _ := setupTestService()
+		peopleRepo.On("Save", ctx, mock.MatchedBy(func(p *people.Person) bool {
+			return p.ID == "person-123" && p.Name == "Hans Zimmer"
+		})).Return(&people.Person{ID: "person-123", Name: "Hans Zimmer"}, nil)
+
+		person, err := service.CreatePerson(ctx, CreatePersonRequest{Name: " Hans Zimmer "})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Hans Zimmer", person.Name)
+		peopleRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an empty name", func(t *testing.T) {
+		service, peopleRepo, _ := setupTestService()
+
+		_, err := service.CreatePerson(ctx, CreatePersonRequest{Name: ""})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+		peopleRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetPersonMovies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns credited movies filtered by role", func(t *testing.T) {
+		service, peopleRepo, _ := setupTestService()
+		credited := []*people.CreditedMovie{{Movie: &movies.Movie{ID: "movie-1", Title: "Dune"}, Role: people.RoleComposer}}
+		peopleRepo.On("GetByID", ctx, people.PersonID("person-123")).Return(&people.Person{ID: "person-123"}, nil)
+		peopleRepo.On("GetPersonMovies", ctx, people.PersonID("person-123"), people.RoleComposer, mock.Anything).Return(credited, int64(1), nil)
+
+		result, total, err := service.GetPersonMovies(ctx, "person-123", "Composer", 20, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, credited, result)
+	})
+
+	t.Run("rejects an unknown role", func(t *testing.T) {
+		service, _, _ := setupTestService()
+
+		_, _, err := service.GetPersonMovies(ctx, "person-123", "producer", 20, 0)
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+
+	t.Run("returns not found for an unknown person", func(t *testing.T) {
+		service, peopleRepo, _ := setupTestService()
+		peopleRepo.On("GetByID", ctx, people.PersonID("missing")).Return(nil, errors.New("person missing not found"))
+
+		_, _, err := service.GetPersonMovies(ctx, "missing", "", 20, 0)
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+	})
+}
+
+func TestAddCredit(t *testing.T) {
+	ctx := context.Background()
+	req := AddCreditRequest{PersonID: "person-123", Role: "actor", Character: "Paul Atreides", BillingOrder: 1}
+
+	t.Run("adds the credit and fills in the person name", func(t *testing.T) {
+		service, peopleRepo, movieRepo := setupTestService()
+		movieRepo.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+		peopleRepo.On("GetByID", ctx, people.PersonID("person-123")).Return(&people.Person{ID: "person-123", Name: "Timothée Chalamet"}, nil)
+		peopleRepo.On("SaveCredit", ctx, mock.MatchedBy(func(c *people.Credit) bool {
+			return c.Role == people.RoleActor && c.Character == "Paul Atreides"
+		})).Return(&people.Credit{MovieID: "movie-1", PersonID: "person-123", Role: people.RoleActor, Character: "Paul Atreides"}, nil)
+
+		credit, err := service.AddCredit(ctx, "movie-1", req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Timothée Chalamet", credit.PersonName)
+		peopleRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns not found for an unknown movie", func(t *testing.T) {
+		service, peopleRepo, movieRepo := setupTestService()
+		movieRepo.On("Exists", ctx, movies.MovieID("missing")).Return(false, nil)
+
+		_, err := service.AddCredit(ctx, "missing", req)
+
+		assertAppErrorCode(t, err, appErrors.CodeNotFound)
+		peopleRepo.AssertNotCalled(t, "SaveCredit", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a character on a non-actor credit", func(t *testing.T) {
+		service, _, _ := setupTestService()
+
+		_, err := service.AddCredit(ctx, "movie-1", AddCreditRequest{PersonID: "person-123", Role: "writer", Character: "Paul"})
+
+		assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+	})
+}
+
+func TestRemoveCredit(t *testing.T) {
+	ctx := context.Background()
+	service, peopleRepo, _ := setupTestService()
+	peopleRepo.On("DeleteCredit", ctx, movies.MovieID("movie-1"), people.PersonID("person-123"), people.RoleWriter).
+		Return(errors.New("writer credit for person person-123 on movie movie-1 not found"))
+
+	err := service.RemoveCredit(ctx, "movie-1", "person-123", "WRITER")
+
+	assertAppErrorCode(t, err, appErrors.CodeNotFound)
+}
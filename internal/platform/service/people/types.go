@@ -0,0 +1,12 @@
+package people
+
+type CreatePersonRequest struct {
+	Name string `json:"name"`
+}
+
+type AddCreditRequest struct {
+	PersonID     string `json:"person_id"`
+	Role         string `json:"role"`
+	Character    string `json:"character,omitempty"`
+	BillingOrder int    `json:"billing_order,omitempty"`
+}
@@ -0,0 +1,136 @@
+package rating
+
+import (
+	"context"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+)
+
+// ComparedMovieStats is one side of a comparison with its Bayesian average,
+// how much that average can be trusted and how divided its raters are
+type ComparedMovieStats struct {
+	*rating.ComparedMovie
+	BayesianAverage float64
+	Confidence      float64 // 0-1, as in EnhancedMovieStats
+	Controversy     float64 // 0-1, as in rating.MovieRatingStats
+	// Withheld is set when the averages are hidden because the movie has
+	// fewer than DisplayMinRatings ratings
+	Withheld bool
+}
+
+// MovieComparison is two movies side by side for the versus view
+type MovieComparison struct {
+	First      ComparedMovieStats
+	Second     ComparedMovieStats
+	HeadToHead rating.HeadToHead
+}
+
+// WithComparisonRepository enables CompareMovies and GetHeadToHead
+func WithComparisonRepository(repo rating.ComparisonRepository) Option {
+	return func(s *ratingService) {
+		s.comparisonRepo = repo
+	}
+}
+
+// WithCache caches the head-to-head of movie pairs and clears the cached
+// profile of a rater and stats of a movie when one of its ratings changes
+func WithCache(c cache.Cache) Option {
+	return func(s *ratingService) {
+		s.cache = c
+	}
+}
+
+func (s *ratingService) CompareMovies(ctx context.Context, firstID, secondID string) (*MovieComparison, error) {
+	firstID, secondID, err := s.moviePair(firstID, secondID)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := s.comparisonRepo.CompareMovies(ctx, movies.MovieID(firstID), movies.MovieID(secondID))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		s.logger.Error("Failed to compare movies", "error", err, "first", firstID, "second", secondID)
+		return nil, errors.NewInternalError("Failed to compare movies")
+	}
+	headToHead, err := s.GetHeadToHead(ctx, firstID, secondID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.reloadStaleGlobalAverage(ctx)
+	return &MovieComparison{
+		First:      s.comparedMovieStats(comparison.First),
+		Second:     s.comparedMovieStats(comparison.Second),
+		HeadToHead: *headToHead,
+	}, nil
+}
+
+func (s *ratingService) GetHeadToHead(ctx context.Context, firstID, secondID string) (*rating.HeadToHead, error) {
+	firstID, secondID, err := s.moviePair(firstID, secondID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey, swapped := cache.HeadToHeadKeyFunc(firstID, secondID)
+	if s.cache != nil {
+		var cached rating.HeadToHead
+		if err := s.cache.Get(ctx, cacheKey, &cached); err == nil {
+			if swapped {
+				cached = cached.Reversed()
+			}
+			return &cached, nil
+		}
+	}
+
+	headToHead, err := s.comparisonRepo.GetHeadToHead(ctx, movies.MovieID(firstID), movies.MovieID(secondID))
+	if err != nil {
+		s.logger.Error("Failed to get head-to-head", "error", err, "first", firstID, "second", secondID)
+		return nil, errors.NewInternalError("Failed to compare movies")
+	}
+
+	if s.cache != nil {
+		entry := *headToHead
+		if swapped {
+			entry = entry.Reversed()
+		}
+		if err := s.cache.Set(ctx, cacheKey, entry, cache.HeadToHeadTTL); err != nil {
+			s.logger.Warn("Failed to cache head-to-head", "error", err, "key", cacheKey)
+		}
+	}
+	return headToHead, nil
+}
+
+// moviePair checks the IDs of the two movies to compare
+func (s *ratingService) moviePair(firstID, secondID string) (string, string, error) {
+	firstID, secondID = strings.TrimSpace(firstID), strings.TrimSpace(secondID)
+	if firstID == "" || secondID == "" {
+		return "", "", errors.NewBadRequestError("Two movie IDs are required")
+	}
+	if firstID == secondID {
+		return "", "", errors.NewBadRequestError("Cannot compare a movie with itself")
+	}
+	if s.comparisonRepo == nil {
+		return "", "", errors.NewInternalError("Movie comparison is not available")
+	}
+	return firstID, secondID, nil
+}
+
+func (s *ratingService) comparedMovieStats(movie *rating.ComparedMovie) ComparedMovieStats {
+	stats := ComparedMovieStats{
+		ComparedMovie:   movie,
+		BayesianAverage: s.calculateBayesianAverage(movie.AverageScore, float64(movie.TotalRatings)),
+		Confidence:      s.calculateConfidence(movie.TotalRatings),
+		Controversy:     rating.Controversy(rating.ScoreVariance(movie.ScoreCount), movie.TotalRatings),
+	}
+	if movie.TotalRatings < s.GetBayesianConfig().DisplayMinRatings {
+		stats.Withheld = true
+		stats.AverageScore = 0
+		stats.BayesianAverage = 0
+	}
+	return stats
+}
@@ -0,0 +1,147 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+func setupCompareService(opts ...Option) (Service, *mockComparisonRepository) {
+	repo := new(mockComparisonRepository)
+	service := NewTestRatingService(new(mockRatingRepository), &mockIDGenerator{}, &mockTimeProvider{now: testNow},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), append(opts, WithComparisonRepository(repo))...)
+	return service, repo
+}
+
+func TestCompareMovies(t *testing.T) {
+	t.Run("adds Bayesian averages and confidence", func(t *testing.T) {
+		service, repo := setupCompareService()
+		repo.On("CompareMovies", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).Return(&rating.MovieComparison{
+			First:  &rating.ComparedMovie{MovieID: "movie-1", AverageScore: 4.0, TotalRatings: 15, ScoreCount: map[int]int64{4: 15}},
+			Second: &rating.ComparedMovie{MovieID: "movie-2", ScoreCount: map[int]int64{}},
+		}, nil)
+		repo.On("GetHeadToHead", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).
+			Return(&rating.HeadToHead{SharedRaters: 3, PreferFirst: 2, Ties: 1, AverageDifference: 1.33}, nil)
+
+		comparison, err := service.CompareMovies(context.Background(), "movie-1", " movie-2 ")
+
+		require.NoError(t, err)
+		// (25 * 3.0 + 15 * 4.0) / (25 + 15)
+		assert.InDelta(t, 3.375, comparison.First.BayesianAverage, 0.0001)
+		assert.Equal(t, 1.0, comparison.First.Confidence)
+		assert.Equal(t, 3.0, comparison.Second.BayesianAverage, "no ratings fall back to the global average")
+		assert.Equal(t, 0.0, comparison.Second.Confidence)
+		assert.Equal(t, 0.0, comparison.First.Controversy, "every rater agrees")
+		assert.Equal(t, int64(2), comparison.HeadToHead.PreferFirst)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("rates how divided the raters are", func(t *testing.T) {
+		service, repo := setupCompareService()
+		repo.On("CompareMovies", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).Return(&rating.MovieComparison{
+			First:  &rating.ComparedMovie{MovieID: "movie-1", AverageScore: 3.0, TotalRatings: 30, ScoreCount: map[int]int64{1: 15, 5: 15}},
+			Second: &rating.ComparedMovie{MovieID: "movie-2", AverageScore: 3.0, TotalRatings: 30, ScoreCount: map[int]int64{2: 15, 4: 15}},
+		}, nil)
+		repo.On("GetHeadToHead", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).Return(&rating.HeadToHead{}, nil)
+
+		comparison, err := service.CompareMovies(context.Background(), "movie-1", "movie-2")
+
+		require.NoError(t, err)
+		// 4 / 4 * 30 / 40 and 1 / 4 * 30 / 40
+		assert.Equal(t, 0.75, comparison.First.Controversy)
+		assert.Equal(t, 0.188, comparison.Second.Controversy)
+	})
+
+	t.Run("withholds the averages of movies with too few ratings", func(t *testing.T) {
+		service, repo := setupCompareService(WithBayesianConfig(BayesianConfig{MinVotes: 10, GlobalAverage: 3.0, ConfidenceK: 25, DisplayMinRatings: 20}))
+		repo.On("CompareMovies", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).Return(&rating.MovieComparison{
+			First:  &rating.ComparedMovie{MovieID: "movie-1", AverageScore: 4.0, TotalRatings: 15, ScoreCount: map[int]int64{4: 15}},
+			Second: &rating.ComparedMovie{MovieID: "movie-2", AverageScore: 3.5, TotalRatings: 20, ScoreCount: map[int]int64{3: 10, 4: 10}},
+		}, nil)
+		repo.On("GetHeadToHead", mock.Anything, movies.MovieID("movie-1"), movies.MovieID("movie-2")).Return(&rating.HeadToHead{}, nil)
+
+		comparison, err := service.CompareMovies(context.Background(), "movie-1", "movie-2")
+
+		require.NoError(t, err)
+		assert.True(t, comparison.First.Withheld)
+		assert.Zero(t, comparison.First.AverageScore)
+		assert.Zero(t, comparison.First.BayesianAverage)
+		assert.Equal(t, int64(15), comparison.First.ScoreCount[4])
+		assert.False(t, comparison.Second.Withheld)
+		assert.Equal(t, 3.5, comparison.Second.AverageScore)
+	})
+
+	tests := []struct {
+		name           string
+		first, second  string
+		repoErr        error
+		expectedStatus int
+	}{
+		{name: "missing id", first: "movie-1", second: "", expectedStatus: http.StatusBadRequest},
+		{name: "same movie", first: "movie-1", second: "movie-1", expectedStatus: http.StatusBadRequest},
+		{name: "unknown movie", first: "movie-1", second: "movie-2", repoErr: errors.New("movie with ID movie-2 not found"), expectedStatus: http.StatusNotFound},
+		{name: "repository error", first: "movie-1", second: "movie-2", repoErr: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := setupCompareService()
+			repo.On("CompareMovies", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.repoErr)
+
+			_, err := service.CompareMovies(context.Background(), tt.first, tt.second)
+
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.expectedStatus, appErr.StatusCode)
+		})
+	}
+}
+
+func TestGetHeadToHead(t *testing.T) {
+	ctx := context.Background()
+	// Cached in the order of the IDs, so of movie-a against movie-b
+	cached := rating.HeadToHead{SharedRaters: 4, PreferFirst: 3, PreferSecond: 1, AverageDifference: 1.25}
+
+	t.Run("caches a pair in ID order", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "head_to_head:movie-a:movie-b", mock.Anything).Return(errors.New("cache miss"))
+		mockCache.On("Set", ctx, "head_to_head:movie-a:movie-b", cached, cache.HeadToHeadTTL).Return(nil)
+		service, repo := setupCompareService(WithCache(mockCache))
+		repo.On("GetHeadToHead", ctx, movies.MovieID("movie-b"), movies.MovieID("movie-a")).Return(&rating.HeadToHead{
+			SharedRaters: 4, PreferFirst: 1, PreferSecond: 3, AverageDifference: -1.25,
+		}, nil)
+
+		headToHead, err := service.GetHeadToHead(ctx, "movie-b", "movie-a")
+
+		require.NoError(t, err)
+		assert.Equal(t, cached.Reversed(), *headToHead)
+		assert.Equal(t, 75, headToHead.PreferSecondPercent())
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("reverses a cached pair asked in the other order", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "head_to_head:movie-a:movie-b", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			*args.Get(2).(*rating.HeadToHead) = cached
+		})
+		service, repo := setupCompareService(WithCache(mockCache))
+
+		headToHead, err := service.GetHeadToHead(ctx, "movie-b", "movie-a")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), headToHead.PreferSecond)
+		assert.Equal(t, -1.25, headToHead.AverageDifference)
+		repo.AssertNotCalled(t, "GetHeadToHead", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,615 @@
+package rating
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/markdown"
+	"time"
+)
+
+const (
+	DefaultHistoryMaxBytes   = 10 << 20
+	DefaultHistoryMaxEntries = 20000
+
+	// historyPageSize is how many ratings an export reads and an import
+	// writes at a time
+	historyPageSize = 500
+	// maxMatchCandidates caps the movies offered for an entry matched by a
+	// similar title
+	maxMatchCandidates = 5
+	// AcceptFuzzySimilarity is how similar the best candidate's title must be
+	// for an import that accepts fuzzy matches to take it
+	AcceptFuzzySimilarity = 0.6
+)
+
+// HistoryColumns are the CSV columns of an export, in order. An import
+// needs a header naming score and at least one of movie_id, imdb_id and
+// title; other columns are optional and unknown ones are ignored.
+var HistoryColumns = []string{
+	"movie_id", "imdb_id", "title", "year", "score", "review", "contains_spoilers", "rated_at", "updated_at",
+}
+
+// HistoryEntry is one rating of a user's history as exported, or as read
+// from a file being imported. An import identifies the movie by MovieID,
+// IMDbID or Title and Year, in that order of preference.
+type HistoryEntry struct {
+	MovieID          string     `json:"movie_id,omitempty"`
+	IMDbID           string     `json:"imdb_id,omitempty"`
+	Title            string     `json:"title,omitempty"`
+	Year             int        `json:"year,omitempty"`
+	Score            int        `json:"score"`
+	Review           string     `json:"review,omitempty"`
+	ContainsSpoilers bool       `json:"contains_spoilers,omitempty"`
+	RatedAt          time.Time  `json:"rated_at"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
+
+	// Line is the line of a CSV file or the position in a JSON array
+	Line int `json:"-"`
+	// invalid explains why the entry could not be read
+	invalid string
+}
+
+// MatchStatus says how an entry of an import was matched to a movie
+type MatchStatus string
+
+const (
+	MatchExact   MatchStatus = "matched"   // By ID, IMDb ID or a title that names one movie
+	MatchFuzzy   MatchStatus = "fuzzy"     // Only by a similar or ambiguous title; see the candidates
+	MatchNone    MatchStatus = "unmatched" // No movie resembles the entry
+	MatchInvalid MatchStatus = "invalid"   // The entry can't be imported as it is
+)
+
+// MovieCandidate is a movie an entry may refer to
+type MovieCandidate struct {
+	MovieID    string  `json:"movie_id"`
+	Title      string  `json:"title"`
+	Year       int     `json:"year"`
+	IMDbID     *string `json:"imdb_id,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// HistoryMatch is how an entry of an import was matched. Movie is what an
+// import rates; entries without one are skipped.
+type HistoryMatch struct {
+	Line       int              `json:"line"`
+	Entry      *HistoryEntry    `json:"entry"`
+	Status     MatchStatus      `json:"status"`
+	Movie      *MovieCandidate  `json:"movie,omitempty"`
+	Candidates []MovieCandidate `json:"candidates,omitempty"`
+	Reason     string           `json:"reason,omitempty"`
+}
+
+// HistoryPreview is what an import would do, without doing it
+type HistoryPreview struct {
+	Entries   int             `json:"entries"`
+	Matched   int             `json:"matched"`
+	Fuzzy     int             `json:"fuzzy"`
+	Unmatched int             `json:"unmatched"`
+	Invalid   int             `json:"invalid"`
+	Matches   []*HistoryMatch `json:"matches"`
+	// Unresolved are the titles whose entries matched no movie, or only
+	// fuzzily, for the user to resolve with TitleResolutions
+	Unresolved []*UnresolvedTitle `json:"unresolved"`
+}
+
+// UnresolvedTitle is a title of an import that matched no movie or several,
+// with the lines it appears on
+type UnresolvedTitle struct {
+	Title      string           `json:"title"`
+	Year       int              `json:"year,omitempty"`
+	Lines      []int            `json:"lines"`
+	Candidates []MovieCandidate `json:"candidates"`
+}
+
+// TitleResolution picks the movie that every entry with a title and year
+// refers to, as listed in HistoryPreview.Unresolved
+type TitleResolution struct {
+	Title   string `json:"title"`
+	Year    int    `json:"year,omitempty"`
+	MovieID string `json:"movie_id"`
+}
+
+// HistoryImportResult is what an import did
+type HistoryImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"` // The user had already rated the movie
+	Skipped    int `json:"skipped"`
+	// NotImported are the skipped entries with why they were skipped
+	NotImported []*HistoryMatch `json:"not_imported"`
+}
+
+// HistoryImportOptions says how to read an import
+type HistoryImportOptions struct {
+	Format string // One of HistoryFormats
+	// AcceptFuzzy imports entries matched by a similar title when the best
+	// candidate is at least AcceptFuzzySimilarity alike
+	AcceptFuzzy bool
+	// Resolutions match the entries of titles to movies, taking precedence
+	// over their title but not over a movie ID or IMDb ID
+	Resolutions []TitleResolution
+}
+
+// HistoryService moves a user's ratings between this service and others
+type HistoryService interface {
+	// ExportHistory returns all of the user's ratings, oldest first
+	ExportHistory(ctx context.Context, userID string) ([]*HistoryEntry, error)
+	// PreviewHistoryImport matches the entries of an import to movies so
+	// the user can resolve the titles that matched none or several of them
+	PreviewHistoryImport(ctx context.Context, userID string, body io.Reader, options HistoryImportOptions) (*HistoryPreview, error)
+	// ImportHistory rates the movies the entries were matched to, keeping
+	// their original dates. Movies the user has already rated are left
+	// alone, as is every movie rated again further down the file.
+	ImportHistory(ctx context.Context, userID string, body io.Reader, options HistoryImportOptions) (*HistoryImportResult, error)
+}
+
+type historyService struct {
+	ratingRepo   rating.Repository
+	matcher      movies.Matcher
+	importer     rating.Importer
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	maxBytes     int64
+	maxEntries   int
+	reviewLimits rating.ReviewLimits
+}
+
+// HistoryOption configures optional settings of the history service
+type HistoryOption func(*historyService)
+
+// WithHistoryMaxBytes caps the size of an import
+func WithHistoryMaxBytes(maxBytes int64) HistoryOption {
+	return func(s *historyService) {
+		s.maxBytes = maxBytes
+	}
+}
+
+// WithHistoryMaxEntries caps the ratings of an import
+func WithHistoryMaxEntries(maxEntries int) HistoryOption {
+	return func(s *historyService) {
+		s.maxEntries = maxEntries
+	}
+}
+
+// WithHistoryReviewLimits replaces the maximum review length of
+// rating.DefaultReviewLimits. Only the maximum applies, as reviews written
+// elsewhere didn't have to meet our minimum.
+func WithHistoryReviewLimits(limits rating.ReviewLimits) HistoryOption {
+	return func(s *historyService) {
+		s.reviewLimits = rating.ReviewLimits{MaxLength: limits.MaxLength}
+	}
+}
+
+func NewHistoryService(
+	ratingRepo rating.Repository,
+	matcher movies.Matcher,
+	importer rating.Importer,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...HistoryOption,
+) HistoryService {
+	s := &historyService{
+		ratingRepo:   ratingRepo,
+		matcher:      matcher,
+		importer:     importer,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+		maxBytes:     DefaultHistoryMaxBytes,
+		maxEntries:   DefaultHistoryMaxEntries,
+		reviewLimits: rating.ReviewLimits{MaxLength: rating.DefaultReviewLimits().MaxLength},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *historyService) ExportHistory(ctx context.Context, userID string) ([]*HistoryEntry, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.NewBadRequestError("User ID is required")
+	}
+
+	entries := []*HistoryEntry{}
+	for offset := 0; ; offset += historyPageSize {
+		page, total, err := s.ratingRepo.GetUserRatingsWithMovies(ctx, users.UserID(userID), rating.UserRatingFilter{},
+			rating.WithSort("created_at", "asc"),
+			rating.WithLimit(historyPageSize),
+			rating.WithOffset(offset),
+		)
+		if err != nil {
+			s.logger.Error("Failed to export ratings", "error", err, "user_id", userID)
+			return nil, errors.NewInternalError("Failed to export ratings")
+		}
+		for _, rated := range page {
+			entries = append(entries, historyEntry(rated))
+		}
+		if len(page) < historyPageSize || int64(offset+len(page)) >= total {
+			return entries, nil
+		}
+	}
+}
+
+func historyEntry(rated *rating.RatingWithMovie) *HistoryEntry {
+	entry := &HistoryEntry{
+		MovieID:          string(rated.Rating.MovieID),
+		Title:            rated.Movie.Title,
+		Year:             rated.Movie.ReleaseYear,
+		Score:            rated.Rating.Score,
+		Review:           rated.Rating.Review,
+		ContainsSpoilers: rated.Rating.ContainsSpoilers,
+		RatedAt:          rated.Rating.CreatedAt.UTC(),
+	}
+	if rated.Movie.IMDbID != nil {
+		entry.IMDbID = *rated.Movie.IMDbID
+	}
+	if !rated.Rating.UpdatedAt.Equal(rated.Rating.CreatedAt) {
+		updatedAt := rated.Rating.UpdatedAt.UTC()
+		entry.UpdatedAt = &updatedAt
+	}
+	return entry
+}
+
+// WriteHistoryCSV writes entries as CSV with a header of HistoryColumns
+func WriteHistoryCSV(w io.Writer, entries []*HistoryEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(HistoryColumns); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		year, updatedAt := "", ""
+		if entry.Year != 0 {
+			year = strconv.Itoa(entry.Year)
+		}
+		if entry.UpdatedAt != nil {
+			updatedAt = entry.UpdatedAt.Format(time.RFC3339)
+		}
+		record := []string{
+			entry.MovieID, entry.IMDbID, entry.Title, year, strconv.Itoa(entry.Score), entry.Review,
+			strconv.FormatBool(entry.ContainsSpoilers), entry.RatedAt.Format(time.RFC3339), updatedAt,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *historyService) PreviewHistoryImport(ctx context.Context, userID string, body io.Reader, options HistoryImportOptions) (*HistoryPreview, error) {
+	matches, err := s.matchImport(ctx, userID, body, options)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &HistoryPreview{Entries: len(matches), Matches: matches}
+	for _, match := range matches {
+		switch match.Status {
+		case MatchExact:
+			preview.Matched++
+		case MatchFuzzy:
+			preview.Fuzzy++
+		case MatchNone:
+			preview.Unmatched++
+		case MatchInvalid:
+			preview.Invalid++
+		}
+	}
+	preview.Unresolved = unresolvedTitles(matches)
+	return preview, nil
+}
+
+// unresolvedTitles groups the entries left without a movie by title
+func unresolvedTitles(matches []*HistoryMatch) []*UnresolvedTitle {
+	unresolved := []*UnresolvedTitle{}
+	byKey := make(map[titleKey]*UnresolvedTitle)
+	for _, match := range matches {
+		if match.Movie != nil || (match.Status != MatchFuzzy && match.Status != MatchNone) || match.Entry.Title == "" {
+			continue
+		}
+		key := newTitleKey(match.Entry.Title, match.Entry.Year)
+		title, ok := byKey[key]
+		if !ok {
+			title = &UnresolvedTitle{
+				Title:      match.Entry.Title,
+				Year:       match.Entry.Year,
+				Candidates: append([]MovieCandidate{}, match.Candidates...),
+			}
+			byKey[key] = title
+			unresolved = append(unresolved, title)
+		}
+		title.Lines = append(title.Lines, match.Line)
+	}
+	return unresolved
+}
+
+func (s *historyService) ImportHistory(ctx context.Context, userID string, body io.Reader, options HistoryImportOptions) (*HistoryImportResult, error) {
+	matches, err := s.matchImport(ctx, userID, body, options)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryImportResult{NotImported: []*HistoryMatch{}}
+	var batch []*rating.Rating
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		statuses, err := s.importer.ImportBatch(ctx, batch)
+		if err != nil {
+			s.logger.Error("Failed to import ratings", "error", err, "user_id", userID)
+			return errors.NewInternalError("Failed to import ratings")
+		}
+		for _, status := range statuses {
+			switch status {
+			case rating.ImportInserted:
+				result.Imported++
+			case rating.ImportDuplicate:
+				result.Duplicates++
+			case rating.ImportUnknownUser:
+				return errors.NewNotFoundError("User not found")
+			case rating.ImportUnknownMovie:
+				// The movie was deleted since it was matched
+				result.Skipped++
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for _, match := range matches {
+		if match.Movie == nil {
+			result.Skipped++
+			result.NotImported = append(result.NotImported, match)
+			continue
+		}
+		batch = append(batch, s.newImportedRating(userID, match))
+		if len(batch) >= historyPageSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rating history imported",
+		"user_id", userID,
+		"imported", result.Imported,
+		"duplicates", result.Duplicates,
+		"skipped", result.Skipped,
+	)
+	return result, nil
+}
+
+func (s *historyService) newImportedRating(userID string, match *HistoryMatch) *rating.Rating {
+	entry := match.Entry
+	updatedAt := entry.RatedAt
+	if entry.UpdatedAt != nil {
+		updatedAt = *entry.UpdatedAt
+	}
+	return &rating.Rating{
+		ID:               rating.RatingID(s.idGenerator.Generate()),
+		UserID:           users.UserID(userID),
+		MovieID:          movies.MovieID(match.Movie.MovieID),
+		Score:            entry.Score,
+		Review:           entry.Review,
+		ContainsSpoilers: entry.ContainsSpoilers || markdown.HasSpoilers(entry.Review),
+		CreatedAt:        entry.RatedAt,
+		UpdatedAt:        updatedAt,
+		Version:          1,
+	}
+}
+
+// matchImport reads an import and matches its entries to movies
+func (s *historyService) matchImport(ctx context.Context, userID string, body io.Reader, options HistoryImportOptions) ([]*HistoryMatch, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.NewBadRequestError("User ID is required")
+	}
+	parse, ok := historyFormats[options.Format]
+	if !ok {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("format must be one of: %s", strings.Join(HistoryFormats(), ", ")))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, s.maxBytes+1))
+	switch {
+	case err != nil:
+		s.logger.Error("Failed to read rating history", "error", err)
+		return nil, errors.NewBadRequestError("Failed to read import")
+	case int64(len(data)) > s.maxBytes:
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("Import must not exceed %d bytes", s.maxBytes))
+	case len(bytes.TrimSpace(data)) == 0:
+		return nil, errors.NewBadRequestError("Import is empty")
+	}
+
+	entries, err := parse(data)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	if len(entries) > s.maxEntries {
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("Import must not exceed %d ratings", s.maxEntries))
+	}
+	resolutions := make(map[titleKey]string, len(options.Resolutions))
+	for _, resolution := range options.Resolutions {
+		movieID := strings.TrimSpace(resolution.MovieID)
+		if movieID == "" || movies.NormalizeTitle(resolution.Title) == "" {
+			return nil, errors.NewBadRequestError("Every resolution needs a title and a movie_id")
+		}
+		resolutions[newTitleKey(resolution.Title, resolution.Year)] = movieID
+	}
+
+	matches := make([]*HistoryMatch, len(entries))
+	for i, entry := range entries {
+		matches[i] = &HistoryMatch{Line: entry.Line, Entry: entry}
+		if reason := s.validateEntry(entry); reason != "" {
+			matches[i].Status, matches[i].Reason = MatchInvalid, reason
+			continue
+		}
+		if movieID, ok := resolutions[newTitleKey(entry.Title, entry.Year)]; ok && entry.MovieID == "" {
+			entry.MovieID = movieID
+		}
+	}
+	if err := s.matchMovies(ctx, matches, options.AcceptFuzzy); err != nil {
+		s.logger.Error("Failed to match rating history", "error", err)
+		return nil, errors.NewInternalError("Failed to match movies")
+	}
+	return matches, nil
+}
+
+// validateEntry explains why an entry can't be imported, whatever movie it
+// matches
+func (s *historyService) validateEntry(entry *HistoryEntry) string {
+	if entry.invalid != "" {
+		return entry.invalid
+	}
+	entry.MovieID = strings.TrimSpace(entry.MovieID)
+	entry.IMDbID = strings.TrimSpace(entry.IMDbID)
+	entry.Title = strings.TrimSpace(entry.Title)
+	entry.Review = strings.TrimSpace(entry.Review)
+
+	now := s.timeProvider.Now()
+	switch {
+	case entry.MovieID == "" && entry.IMDbID == "" && entry.Title == "":
+		return "movie_id, imdb_id or title is required"
+	case entry.Score < 1 || entry.Score > 5:
+		return rating.ErrInvalidScore.Error()
+	case entry.RatedAt.After(now):
+		return "rated_at must not be in the future"
+	case entry.UpdatedAt != nil && !entry.RatedAt.IsZero() && entry.UpdatedAt.Before(entry.RatedAt):
+		return "updated_at must not be before rated_at"
+	}
+	if err := s.reviewLimits.Check(entry.Review); err != nil {
+		return err.Error()
+	}
+	// Entries without a date were rated no later than now
+	if entry.RatedAt.IsZero() {
+		entry.RatedAt = now
+	}
+	return ""
+}
+
+// matchMovies sets the movie of every valid entry it can match: by movie
+// ID, then by IMDb ID, then by a title that names exactly one movie.
+// Entries that name a movie ID or IMDb ID that doesn't exist fall back to
+// their title.
+func (s *historyService) matchMovies(ctx context.Context, matches []*HistoryMatch, acceptFuzzy bool) error {
+	var ids []movies.MovieID
+	var imdbIDs []string
+	for _, match := range matches {
+		if match.Status == MatchInvalid {
+			continue
+		}
+		if match.Entry.MovieID != "" {
+			ids = append(ids, movies.MovieID(match.Entry.MovieID))
+		}
+		if match.Entry.IMDbID != "" {
+			imdbIDs = append(imdbIDs, match.Entry.IMDbID)
+		}
+	}
+	byID, err := s.matcher.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byIMDbID, err := s.matcher.GetByIMDbIDs(ctx, imdbIDs)
+	if err != nil {
+		return err
+	}
+
+	// Exports list a title once per rating, rewatches included
+	titles := make(map[titleKey][]*movies.TitleMatch)
+	for _, match := range matches {
+		if match.Status == MatchInvalid {
+			continue
+		}
+		entry := match.Entry
+		if movie, ok := byID[movies.MovieID(entry.MovieID)]; ok {
+			match.Status, match.Movie = MatchExact, candidate(movie, 1)
+			continue
+		}
+		if movie, ok := byIMDbID[entry.IMDbID]; ok {
+			match.Status, match.Movie = MatchExact, candidate(movie, 1)
+			continue
+		}
+
+		key := newTitleKey(entry.Title, entry.Year)
+		if key.title == "" {
+			match.Status, match.Reason = MatchNone, "movie not found"
+			continue
+		}
+		found, ok := titles[key]
+		if !ok {
+			if found, err = s.matcher.MatchTitle(ctx, entry.Title, entry.Year, maxMatchCandidates); err != nil {
+				return err
+			}
+			titles[key] = found
+		}
+		classifyTitleMatch(match, found, acceptFuzzy)
+	}
+	return nil
+}
+
+// titleKey identifies the entries of an import that name the same title
+type titleKey struct {
+	title string
+	year  int
+}
+
+func newTitleKey(title string, year int) titleKey {
+	return titleKey{movies.NormalizeTitle(title), year}
+}
+
+// classifyTitleMatch matches an entry by the movies whose title resembles
+// its own. Only a single movie with an equal title is a match; anything
+// else is left to the user to resolve unless fuzzy matches are accepted.
+func classifyTitleMatch(match *HistoryMatch, found []*movies.TitleMatch, acceptFuzzy bool) {
+	if len(found) == 0 {
+		match.Status, match.Reason = MatchNone, "no movie with a similar title"
+		return
+	}
+
+	match.Candidates = make([]MovieCandidate, len(found))
+	equal := 0
+	for i, f := range found {
+		match.Candidates[i] = *candidate(f.Movie, f.Similarity)
+		if f.Similarity >= 1 {
+			equal++
+		}
+	}
+
+	switch {
+	case equal == 1:
+		match.Status, match.Movie, match.Candidates = MatchExact, &match.Candidates[0], nil
+		return
+	case equal > 1:
+		match.Reason = "several movies have this title"
+	default:
+		match.Reason = "no movie has this exact title"
+	}
+	match.Status = MatchFuzzy
+	if acceptFuzzy && found[0].Similarity >= AcceptFuzzySimilarity {
+		match.Movie = &match.Candidates[0]
+	}
+}
+
+func candidate(movie *movies.Movie, similarity float64) *MovieCandidate {
+	return &MovieCandidate{
+		MovieID:    string(movie.ID),
+		Title:      movie.Title,
+		Year:       movie.ReleaseYear,
+		IMDbID:     movie.IMDbID,
+		Similarity: similarity,
+	}
+}
@@ -0,0 +1,271 @@
+package rating
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyParser reads the entries of an import. Entries that can't be read
+// are returned with invalid set; an error means the whole file is unusable.
+type historyParser func(body []byte) ([]*HistoryEntry, error)
+
+// historyFormats are the file formats an import reads, by name
+var historyFormats = map[string]historyParser{
+	"csv":        parseHistoryCSV,
+	"json":       parseHistoryJSON,
+	"letterboxd": parseLetterboxdCSV,
+	"imdb":       parseIMDbCSV,
+}
+
+// HistoryFormats lists the formats an import reads
+func HistoryFormats() []string {
+	formats := make([]string, 0, len(historyFormats))
+	for name := range historyFormats {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// csvRow reads a field of a CSV row by its lowercased header name; missing
+// columns read as empty
+type csvRow func(column string) string
+
+// readHistoryCSV reads a CSV file with a header row, turning every row into
+// an entry with toEntry. The header must name at least one column of each
+// of the required groups. Rows that can't be split into fields are returned
+// as invalid entries.
+func readHistoryCSV(body []byte, required [][]string, toEntry func(row csvRow) *HistoryEntry) ([]*HistoryEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, group := range required {
+		found := false
+		for _, name := range group {
+			if _, ok := columns[name]; ok {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("CSV header must name a %s column", orList(group))
+		}
+	}
+
+	var entries []*HistoryEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !stdErrors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid CSV: %w", err)
+			}
+			entries = append(entries, &HistoryEntry{Line: parseErr.StartLine, invalid: "invalid CSV: " + parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		entry := toEntry(func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		})
+		entry.Line = line
+		entries = append(entries, entry)
+	}
+}
+
+// orList joins names as "a, b or c"
+func orList(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// parseHistoryCSV reads the CSV layout of an export
+func parseHistoryCSV(body []byte) ([]*HistoryEntry, error) {
+	required := [][]string{{"score"}, {"movie_id", "imdb_id", "title"}}
+	return readHistoryCSV(body, required, func(row csvRow) *HistoryEntry {
+		entry := &HistoryEntry{
+			MovieID: row("movie_id"),
+			IMDbID:  row("imdb_id"),
+			Title:   row("title"),
+			Review:  row("review"),
+		}
+		entry.invalid = parseHistoryFields(entry, row)
+		return entry
+	})
+}
+
+// parseHistoryFields sets the typed fields of an entry in the export
+// layout, or explains why one can't be read
+func parseHistoryFields(entry *HistoryEntry, row csvRow) string {
+	var err error
+	if entry.Score, err = strconv.Atoi(row("score")); err != nil {
+		return "score must be a whole number"
+	}
+	if entry.Year, err = parseHistoryYear(row("year")); err != nil {
+		return err.Error()
+	}
+	if spoilers := row("contains_spoilers"); spoilers != "" {
+		if entry.ContainsSpoilers, err = strconv.ParseBool(spoilers); err != nil {
+			return "contains_spoilers must be true or false"
+		}
+	}
+	ratedAt := row("rated_at")
+	if ratedAt == "" {
+		// Ratings copied from our own API listings
+		ratedAt = row("created_at")
+	}
+	if ratedAt != "" {
+		if entry.RatedAt, err = parseHistoryTime(ratedAt); err != nil {
+			return "rated_at must be a date or an RFC 3339 time"
+		}
+	}
+	if updatedAt := row("updated_at"); updatedAt != "" {
+		t, err := parseHistoryTime(updatedAt)
+		if err != nil {
+			return "updated_at must be a date or an RFC 3339 time"
+		}
+		entry.UpdatedAt = &t
+	}
+	return ""
+}
+
+// parseLetterboxdCSV reads ratings.csv, reviews.csv or diary.csv of a
+// Letterboxd data export. Films are matched by name and year, as the
+// Letterboxd URI is a short link we can't resolve. Ratings of half stars
+// are rounded up to whole ones, and diary entries are dated by when the
+// film was watched rather than logged.
+func parseLetterboxdCSV(body []byte) ([]*HistoryEntry, error) {
+	required := [][]string{{"name"}, {"rating"}}
+	return readHistoryCSV(body, required, func(row csvRow) *HistoryEntry {
+		entry := &HistoryEntry{Title: row("name"), Review: row("review")}
+		entry.invalid = parseLetterboxdFields(entry, row)
+		return entry
+	})
+}
+
+func parseLetterboxdFields(entry *HistoryEntry, row csvRow) string {
+	raw := row("rating")
+	if raw == "" {
+		return "the film was logged without a rating"
+	}
+	stars, err := strconv.ParseFloat(raw, 64)
+	if err != nil || stars < 0.5 || stars > 5 {
+		return "rating must be between 0.5 and 5 stars"
+	}
+	entry.Score = int(math.Round(stars))
+
+	if entry.Year, err = parseHistoryYear(row("year")); err != nil {
+		return err.Error()
+	}
+	date := row("watched date")
+	if date == "" {
+		date = row("date")
+	}
+	if date != "" {
+		if entry.RatedAt, err = parseHistoryTime(date); err != nil {
+			return "date must be formatted as YYYY-MM-DD"
+		}
+	}
+	return ""
+}
+
+// parseIMDbCSV reads the ratings.csv of an IMDb ratings export. Titles are
+// matched by their IMDb ID, falling back to title and year, and scores of
+// 1 to 10 are halved, rounding up. Series and episodes are rejected.
+func parseIMDbCSV(body []byte) ([]*HistoryEntry, error) {
+	required := [][]string{{"const", "title"}, {"your rating"}}
+	return readHistoryCSV(body, required, func(row csvRow) *HistoryEntry {
+		entry := &HistoryEntry{IMDbID: row("const"), Title: row("title")}
+		entry.invalid = parseIMDbFields(entry, row)
+		return entry
+	})
+}
+
+func parseIMDbFields(entry *HistoryEntry, row csvRow) string {
+	titleType := row("title type")
+	if lower := strings.ToLower(titleType); strings.Contains(lower, "series") || strings.Contains(lower, "episode") {
+		return fmt.Sprintf("%s is not a movie", titleType)
+	}
+
+	score, err := strconv.Atoi(row("your rating"))
+	if err != nil || score < 1 || score > 10 {
+		return "your rating must be between 1 and 10"
+	}
+	entry.Score = int(math.Round(float64(score) / 2))
+
+	if entry.Year, err = parseHistoryYear(row("year")); err != nil {
+		return err.Error()
+	}
+	if date := row("date rated"); date != "" {
+		if entry.RatedAt, err = parseHistoryTime(date); err != nil {
+			return "date rated must be formatted as YYYY-MM-DD"
+		}
+	}
+	return ""
+}
+
+func parseHistoryYear(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, stdErrors.New("year must be a number")
+	}
+	return year, nil
+}
+
+// parseHistoryTime accepts RFC 3339 times and plain dates, which are taken
+// as midnight UTC
+func parseHistoryTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseHistoryJSON reads a JSON array of entries as written by an export
+func parseHistoryJSON(body []byte) ([]*HistoryEntry, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, stdErrors.New("import must be a JSON array of ratings")
+	}
+
+	entries := make([]*HistoryEntry, len(raw))
+	for i, item := range raw {
+		entry := &HistoryEntry{}
+		if err := json.Unmarshal(item, entry); err != nil {
+			entry = &HistoryEntry{invalid: "invalid rating: " + err.Error()}
+		}
+		entry.Line = i + 1
+		entries[i] = entry
+	}
+	return entries, nil
+}
@@ -0,0 +1,114 @@
+package rating
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLetterboxdCSV(t *testing.T) {
+	body := strings.Join([]string{
+		"\ufeffDate,Name,Year,Letterboxd URI,Rating,Rewatch,Review,Tags,Watched Date",
+		"2021-02-03,Alien,1979,https://boxd.it/abc,4.5,,\"Perfect, still.\",,2021-02-01",
+		"2021-03-04,Heat,1995,https://boxd.it/def,0.5,Yes,,,",
+		"2021-03-05,Zardoz,1974,https://boxd.it/ghi,,,,,",
+		"2021-03-06,Heat,1995,https://boxd.it/def,6,,,,",
+		"2021-03-07,Heat,199x,https://boxd.it/def,3,,,,",
+	}, "\n")
+
+	entries, err := parseLetterboxdCSV([]byte(body))
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	assert.Equal(t, 2, entries[0].Line)
+	assert.Equal(t, "Alien", entries[0].Title)
+	assert.Equal(t, 1979, entries[0].Year)
+	assert.Equal(t, 5, entries[0].Score, "half stars round up")
+	assert.Equal(t, "Perfect, still.", entries[0].Review)
+	assert.Equal(t, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), entries[0].RatedAt, "dated by the watched date")
+	assert.Empty(t, entries[0].invalid)
+
+	assert.Equal(t, 1, entries[1].Score)
+	assert.Equal(t, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), entries[1].RatedAt)
+
+	assert.Equal(t, "the film was logged without a rating", entries[2].invalid)
+	assert.Equal(t, "rating must be between 0.5 and 5 stars", entries[3].invalid)
+	assert.Equal(t, "year must be a number", entries[4].invalid)
+
+	_, err = parseLetterboxdCSV([]byte("Date,Title,Rating\n"))
+	assert.EqualError(t, err, "CSV header must name a name column")
+}
+
+func TestParseIMDbCSV(t *testing.T) {
+	body := strings.Join([]string{
+		"Const,Your Rating,Date Rated,Title,URL,Title Type,IMDb Rating,Runtime (mins),Year,Genres,Num Votes,Release Date,Directors",
+		"tt0078748,9,2019-05-06,Alien,https://www.imdb.com/title/tt0078748/,Movie,8.5,117,1979,\"Horror, Sci-Fi\",900000,1979-05-25,Ridley Scott",
+		"tt0113277,1,2019-05-07,Heat,https://www.imdb.com/title/tt0113277/,movie,8.3,170,1995,Crime,700000,1995-12-15,Michael Mann",
+		"tt0903747,10,2019-05-08,Breaking Bad,https://www.imdb.com/title/tt0903747/,TV Series,9.5,49,2008,Drama,2000000,2008-01-20,",
+		"tt0000001,11,2019-05-09,Carmencita,https://www.imdb.com/title/tt0000001/,Short,5.7,1,1894,Documentary,2000,1894-03-10,",
+	}, "\n")
+
+	entries, err := parseIMDbCSV([]byte(body))
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "tt0078748", entries[0].IMDbID)
+	assert.Equal(t, "Alien", entries[0].Title)
+	assert.Equal(t, 1979, entries[0].Year)
+	assert.Equal(t, 5, entries[0].Score, "9 of 10 rounds up to 5 of 5")
+	assert.Equal(t, time.Date(2019, 5, 6, 0, 0, 0, 0, time.UTC), entries[0].RatedAt)
+	assert.Empty(t, entries[0].invalid)
+
+	assert.Equal(t, 1, entries[1].Score)
+	assert.Equal(t, "TV Series is not a movie", entries[2].invalid)
+	assert.Equal(t, "your rating must be between 1 and 10", entries[3].invalid)
+
+	_, err = parseIMDbCSV([]byte("Const,Title\n"))
+	assert.EqualError(t, err, "CSV header must name a your rating column")
+}
+
+func TestPreviewHistoryImport_Resolutions(t *testing.T) {
+	importer := &fakeImporter{}
+	service, _ := setupHistoryService(importer)
+
+	// The remake and the original share a title, and nothing is like Zardoz
+	body := strings.Join([]string{
+		"Date,Name,Year,Letterboxd URI,Rating",
+		"2021-01-01,The Thing,,https://boxd.it/a,5",
+		"2021-01-02,Zardoz,1974,https://boxd.it/b,2",
+		"2021-01-03,The Thing,,https://boxd.it/a,4",
+		"2021-01-04,Heat,1995,https://boxd.it/c,4",
+	}, "\n")
+
+	preview, err := service.PreviewHistoryImport(context.Background(), "user-1", strings.NewReader(body), HistoryImportOptions{Format: "letterboxd"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, preview.Matched)
+	require.Len(t, preview.Unresolved, 2)
+	assert.Equal(t, "The Thing", preview.Unresolved[0].Title)
+	assert.Equal(t, []int{2, 4}, preview.Unresolved[0].Lines)
+	assert.Len(t, preview.Unresolved[0].Candidates, 2)
+	assert.Equal(t, "Zardoz", preview.Unresolved[1].Title)
+	assert.Equal(t, 1974, preview.Unresolved[1].Year)
+	assert.Empty(t, preview.Unresolved[1].Candidates)
+
+	result, err := service.ImportHistory(context.Background(), "user-1", strings.NewReader(body), HistoryImportOptions{
+		Format:      "letterboxd",
+		Resolutions: []TitleResolution{{Title: "the thing", MovieID: "movie-thing"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Imported)
+	assert.Equal(t, 1, result.Skipped)
+	require.Len(t, importer.batches, 1)
+	assert.Equal(t, "movie-thing", string(importer.batches[0][0].MovieID))
+	assert.Equal(t, "movie-thing", string(importer.batches[0][1].MovieID))
+
+	_, err = service.ImportHistory(context.Background(), "user-1", strings.NewReader(body), HistoryImportOptions{
+		Format:      "letterboxd",
+		Resolutions: []TitleResolution{{Title: "The Thing"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package rating
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+// fakeMatcher matches titles by normalized prefix, rating equal titles 1
+type fakeMatcher struct {
+	movies []*movies.Movie
+}
+
+func (f *fakeMatcher) GetByIDs(ctx context.Context, ids []movies.MovieID) (map[movies.MovieID]*movies.Movie, error) {
+	found := make(map[movies.MovieID]*movies.Movie)
+	for _, id := range ids {
+		for _, movie := range f.movies {
+			if movie.ID == id {
+				found[id] = movie
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeMatcher) GetByIMDbIDs(ctx context.Context, imdbIDs []string) (map[string]*movies.Movie, error) {
+	found := make(map[string]*movies.Movie)
+	for _, imdbID := range imdbIDs {
+		for _, movie := range f.movies {
+			if movie.IMDbID != nil && *movie.IMDbID == imdbID {
+				found[imdbID] = movie
+			}
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeMatcher) MatchTitle(ctx context.Context, title string, year int, limit int) ([]*movies.TitleMatch, error) {
+	normalized := movies.NormalizeTitle(title)
+	var matches []*movies.TitleMatch
+	for _, movie := range f.movies {
+		if year != 0 && (movie.ReleaseYear < year-1 || movie.ReleaseYear > year+1) {
+			continue
+		}
+		switch movieTitle := movies.NormalizeTitle(movie.Title); {
+		case movieTitle == normalized:
+			matches = append(matches, &movies.TitleMatch{Movie: movie, Similarity: 1})
+		case strings.HasPrefix(movieTitle, normalized):
+			matches = append(matches, &movies.TitleMatch{Movie: movie, Similarity: 0.7})
+		}
+	}
+	return matches, nil
+}
+
+func historyMovies() []*movies.Movie {
+	imdbID := "tt0084787"
+	return []*movies.Movie{
+		{ID: "movie-thing", Title: "The Thing", ReleaseYear: 1982, IMDbID: &imdbID},
+		{ID: "movie-thing-2011", Title: "The Thing", ReleaseYear: 2011},
+		{ID: "movie-alien", Title: "Alien", ReleaseYear: 1979},
+		{ID: "movie-aliens", Title: "Aliens", ReleaseYear: 1986},
+		{ID: "movie-heat", Title: "Heat", ReleaseYear: 1995},
+	}
+}
+
+func setupHistoryService(importer rating.Importer) (HistoryService, *mockRatingRepository) {
+	repo := new(mockRatingRepository)
+	timeProvider := &mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewHistoryService(repo, &fakeMatcher{movies: historyMovies()}, importer,
+		&mockIDGenerator{id: "rating-imported"}, timeProvider, logger)
+	return service, repo
+}
+
+func TestExportHistory(t *testing.T) {
+	service, repo := setupHistoryService(&fakeImporter{})
+
+	imdbID := "tt0084787"
+	createdAt := time.Date(2012, 3, 4, 5, 6, 7, 0, time.UTC)
+	updatedAt := createdAt.Add(24 * time.Hour)
+	repo.On("GetUserRatingsWithMovies", mock.Anything, mock.Anything, rating.UserRatingFilter{}, mock.Anything).Return([]*rating.RatingWithMovie{
+		{
+			Rating: &rating.Rating{MovieID: "movie-thing", Score: 5, Review: "A, \"classic\"", CreatedAt: createdAt, UpdatedAt: createdAt},
+			Movie:  &movies.Movie{ID: "movie-thing", Title: "The Thing", ReleaseYear: 1982, IMDbID: &imdbID},
+		},
+		{
+			Rating: &rating.Rating{MovieID: "movie-heat", Score: 4, ContainsSpoilers: true, CreatedAt: createdAt, UpdatedAt: updatedAt},
+			Movie:  &movies.Movie{ID: "movie-heat", Title: "Heat", ReleaseYear: 1995},
+		},
+	}, int64(2), nil)
+
+	entries, err := service.ExportHistory(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "tt0084787", entries[0].IMDbID)
+	assert.Nil(t, entries[0].UpdatedAt)
+	require.NotNil(t, entries[1].UpdatedAt)
+	assert.Equal(t, updatedAt, *entries[1].UpdatedAt)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHistoryCSV(&buf, entries))
+	assert.Equal(t, strings.Join([]string{
+		"movie_id,imdb_id,title,year,score,review,contains_spoilers,rated_at,updated_at",
+		`movie-thing,tt0084787,The Thing,1982,5,"A, ""classic""",false,2012-03-04T05:06:07Z,`,
+		"movie-heat,,Heat,1995,4,,true,2012-03-04T05:06:07Z,2012-03-05T05:06:07Z",
+		"",
+	}, "\n"), buf.String())
+
+	// An export reads back as it was written
+	importer := &fakeImporter{}
+	service, _ = setupHistoryService(importer)
+	result, err := service.ImportHistory(context.Background(), "user-1", &buf, HistoryImportOptions{Format: "csv"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, importer.batches, 1)
+	assert.Equal(t, `A, "classic"`, importer.batches[0][0].Review)
+	assert.Equal(t, createdAt, importer.batches[0][0].CreatedAt)
+	assert.Equal(t, updatedAt, importer.batches[0][1].UpdatedAt)
+}
+
+func TestPreviewHistoryImport(t *testing.T) {
+	service, _ := setupHistoryService(&fakeImporter{})
+
+	body := strings.Join([]string{
+		"Title,Year,Score,IMDb_ID,Rated_At,Notes",
+		"Alien,1979,5,,2015-06-01,watched twice", // Equal title
+		"Whatever,,4,tt0084787,,",                // IMDb ID wins over the title
+		"The Thing,,4,,,",                        // Two movies have the title
+		"Alie,,3,,,",                             // Only similar titles
+		"Zardoz,1974,2,,,",                       // Nothing similar
+		"Heat,1995,9,,,",                         // Out of range
+		"Heat,1995,4,,2999-01-01,",               // In the future
+		"Heat,1995,four,,,",
+	}, "\n")
+
+	preview, err := service.PreviewHistoryImport(context.Background(), "user-1", strings.NewReader(body), HistoryImportOptions{Format: "csv"})
+	require.NoError(t, err)
+	assert.Equal(t, 8, preview.Entries)
+	assert.Equal(t, 2, preview.Matched)
+	assert.Equal(t, 2, preview.Fuzzy)
+	assert.Equal(t, 1, preview.Unmatched)
+	assert.Equal(t, 3, preview.Invalid)
+
+	matches := preview.Matches
+	assert.Equal(t, 2, matches[0].Line)
+	assert.Equal(t, MatchExact, matches[0].Status)
+	assert.Equal(t, "movie-alien", matches[0].Movie.MovieID)
+	assert.Equal(t, time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC), matches[0].Entry.RatedAt)
+
+	assert.Equal(t, "movie-thing", matches[1].Movie.MovieID)
+
+	assert.Equal(t, MatchFuzzy, matches[2].Status)
+	assert.Nil(t, matches[2].Movie)
+	assert.Len(t, matches[2].Candidates, 2)
+	assert.Equal(t, "several movies have this title", matches[2].Reason)
+
+	assert.Equal(t, MatchFuzzy, matches[3].Status)
+	assert.Len(t, matches[3].Candidates, 2)
+
+	assert.Equal(t, MatchNone, matches[4].Status)
+	assert.Equal(t, rating.ErrInvalidScore.Error(), matches[5].Reason)
+	assert.Equal(t, "rated_at must not be in the future", matches[6].Reason)
+	assert.Equal(t, "score must be a whole number", matches[7].Reason)
+}
+
+func TestImportHistory(t *testing.T) {
+	importer := &fakeImporter{}
+	service, _ := setupHistoryService(importer)
+
+	body := `[
+		{"title": "Alie", "score": 3, "rated_at": "2015-06-01T10:00:00Z"},
+		{"title": "Zardoz", "score": 2},
+		{"movie_id": "movie-heat", "score": 4, "review": "The ||diner scene||"},
+		{"movie_id": "movie-heat", "score": "five"}
+	]`
+
+	result, err := service.ImportHistory(context.Background(), "user-1", strings.NewReader(body), HistoryImportOptions{Format: "json", AcceptFuzzy: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, 2, result.Skipped)
+	require.Len(t, result.NotImported, 2)
+	assert.Equal(t, 2, result.NotImported[0].Line)
+	assert.Equal(t, MatchInvalid, result.NotImported[1].Status)
+
+	require.Len(t, importer.batches, 1)
+	imported := importer.batches[0]
+	assert.Equal(t, movies.MovieID("movie-alien"), imported[0].MovieID, "the best fuzzy candidate was accepted")
+	assert.Equal(t, time.Date(2015, 6, 1, 10, 0, 0, 0, time.UTC), imported[0].CreatedAt)
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), imported[1].CreatedAt, "undated ratings are dated now")
+	assert.True(t, imported[1].ContainsSpoilers)
+	assert.Equal(t, 1, imported[1].Version)
+}
+
+func TestImportHistory_Errors(t *testing.T) {
+	var appErr *appErrors.AppError
+
+	service, _ := setupHistoryService(&fakeImporter{})
+	_, err := service.ImportHistory(context.Background(), "user-1", strings.NewReader("{}"), HistoryImportOptions{Format: "xml"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	_, err = service.PreviewHistoryImport(context.Background(), "user-1", strings.NewReader("title,year\nAlien,1979"), HistoryImportOptions{Format: "csv"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	_, err = service.ImportHistory(context.Background(), "user-1", strings.NewReader(`{"title":"Alien"}`), HistoryImportOptions{Format: "json"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	entries, _ := json.Marshal([]HistoryEntry{{MovieID: "movie-heat", Score: 4}, {MovieID: "movie-alien", Score: 4}})
+	service = NewHistoryService(new(mockRatingRepository), &fakeMatcher{movies: historyMovies()}, &fakeImporter{},
+		&mockIDGenerator{id: "rating-imported"}, &mockTimeProvider{now: time.Now()}, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithHistoryMaxEntries(1))
+	_, err = service.ImportHistory(context.Background(), "user-1", bytes.NewReader(entries), HistoryImportOptions{Format: "json"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.StatusCode)
+
+	// The importer doesn't know the user
+	service, _ = setupHistoryService(&fakeImporter{})
+	_, err = service.ImportHistory(context.Background(), "user-missing", strings.NewReader(`[{"movie_id":"movie-heat","score":4}]`), HistoryImportOptions{Format: "json"})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
@@ -0,0 +1,365 @@
+package rating
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/storage"
+	"time"
+)
+
+const (
+	// ImportJobType is the job that imports an uploaded NDJSON file
+	ImportJobType = "ratings.import"
+
+	DefaultImportBatchSize = 500
+	DefaultImportMaxBytes  = 100 << 20
+	// maxImportErrors caps the rejected lines a job reports; the rest are
+	// only counted
+	maxImportErrors = 100
+)
+
+// ImportRetryPolicy retries imports that failed on a database error. A retry
+// reads the upload from the start; ratings written by an earlier attempt
+// then count as duplicates.
+func ImportRetryPolicy() jobs.RetryPolicy {
+	return jobs.RetryPolicy{MaxAttempts: 3, Backoff: time.Minute, MaxBackoff: 10 * time.Minute}
+}
+
+// ImportLineError explains why a line of an import was not imported
+type ImportLineError struct {
+	Line   int64  `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult is the result an import job reports as it goes
+type ImportResult struct {
+	TotalBytes     int64             `json:"total_bytes"`
+	ProcessedBytes int64             `json:"processed_bytes"`
+	Lines          int64             `json:"lines"` // Non-empty lines read
+	Imported       int64             `json:"imported"`
+	Duplicates     int64             `json:"duplicates"` // The user had already rated the movie
+	Rejected       int64             `json:"rejected"`   // Invalid lines and lines referring to unknown users or movies
+	Errors         []ImportLineError `json:"errors"`     // The first rejected lines
+}
+
+// JobQueue queues background jobs
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error)
+}
+
+// ImportService ingests historical ratings from legacy systems
+type ImportService interface {
+	// StartImport stores an NDJSON stream of ratings, one object per line,
+	// and queues a job to import it
+	StartImport(ctx context.Context, body io.Reader, startedBy string) (*jobs.Job, error)
+	// RunImport is the jobs.Handler of ImportJobType
+	RunImport(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error
+}
+
+// importPayload points an import job at its upload
+type importPayload struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// importLine is one line of an import. CreatedAt is the original time of the
+// rating and UpdatedAt defaults to it.
+type importLine struct {
+	UserID           string     `json:"user_id"`
+	MovieID          string     `json:"movie_id"`
+	Score            int        `json:"score"`
+	Review           string     `json:"review"`
+	ContainsSpoilers bool       `json:"contains_spoilers"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+type importService struct {
+	importer     rating.Importer
+	queue        JobQueue
+	store        storage.Storage
+	idGenerator  shared.IDGenerator
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	batchSize    int
+	maxBytes     int64
+	reviewLimits rating.ReviewLimits
+}
+
+// ImportOption configures optional settings of the import service
+type ImportOption func(*importService)
+
+// WithImportBatchSize sets how many ratings are written per statement
+func WithImportBatchSize(size int) ImportOption {
+	return func(s *importService) {
+		s.batchSize = size
+	}
+}
+
+// WithImportMaxBytes caps the size of an upload
+func WithImportMaxBytes(maxBytes int64) ImportOption {
+	return func(s *importService) {
+		s.maxBytes = maxBytes
+	}
+}
+
+// WithImportReviewLimits replaces the maximum review length of
+// rating.DefaultReviewLimits. Only the maximum applies, as legacy reviews
+// predate any minimum.
+func WithImportReviewLimits(limits rating.ReviewLimits) ImportOption {
+	return func(s *importService) {
+		s.reviewLimits = rating.ReviewLimits{MaxLength: limits.MaxLength}
+	}
+}
+
+// NewImportService keeps uploads in store under storage.PrivatePrefix until
+// their job has imported them
+func NewImportService(
+	importer rating.Importer,
+	queue JobQueue,
+	store storage.Storage,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...ImportOption,
+) ImportService {
+	s := &importService{
+		importer:     importer,
+		queue:        queue,
+		store:        store,
+		idGenerator:  idGenerator,
+		timeProvider: timeProvider,
+		logger:       logger,
+		batchSize:    DefaultImportBatchSize,
+		maxBytes:     DefaultImportMaxBytes,
+		reviewLimits: rating.ReviewLimits{MaxLength: rating.DefaultReviewLimits().MaxLength},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *importService) StartImport(ctx context.Context, body io.Reader, startedBy string) (*jobs.Job, error) {
+	data, err := io.ReadAll(io.LimitReader(body, s.maxBytes+1))
+	switch {
+	case err != nil:
+		s.logger.Error("Failed to read import", "error", err)
+		return nil, errors.NewBadRequestError("Failed to read import")
+	case int64(len(data)) > s.maxBytes:
+		return nil, errors.NewPayloadTooLargeError(fmt.Sprintf("Import must not exceed %d bytes", s.maxBytes))
+	case len(bytes.TrimSpace(data)) == 0:
+		return nil, errors.NewBadRequestError("Import is empty")
+	}
+
+	// The upload is stored so any worker can run the job, after the request
+	// is gone
+	key := storage.PrivatePrefix + "imports/ratings/" + s.idGenerator.Generate() + ".ndjson"
+	if err := s.store.Put(ctx, key, data, "application/x-ndjson"); err != nil {
+		s.logger.Error("Failed to store import", "error", err)
+		return nil, errors.NewInternalError("Failed to start import")
+	}
+
+	job, err := s.queue.Enqueue(ctx, ImportJobType, importPayload{Key: key, Size: int64(len(data))}, startedBy)
+	if err != nil {
+		if deleteErr := s.store.Delete(ctx, key); deleteErr != nil {
+			s.logger.Error("Failed to delete import", "error", deleteErr, "key", key)
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *importService) RunImport(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	var payload importPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil || payload.Key == "" {
+		return jobs.Permanent(fmt.Errorf("invalid import payload"))
+	}
+	body, _, err := s.store.Get(ctx, payload.Key)
+	if err != nil {
+		if stdErrors.Is(err, storage.ErrNotFound) {
+			return jobs.Permanent(fmt.Errorf("upload %s no longer exists", payload.Key))
+		}
+		return fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer body.Close()
+
+	result := &ImportResult{TotalBytes: payload.Size, Errors: []ImportLineError{}}
+	if err := s.importLines(ctx, body, result, report); err != nil {
+		return err
+	}
+
+	if err := s.store.Delete(context.WithoutCancel(ctx), payload.Key); err != nil {
+		s.logger.Error("Failed to delete imported upload", "error", err, "key", payload.Key)
+	}
+	s.logger.Info("Rating import completed",
+		"job_id", job.ID,
+		"lines", result.Lines,
+		"imported", result.Imported,
+		"duplicates", result.Duplicates,
+		"rejected", result.Rejected,
+	)
+	return nil
+}
+
+// importProgress collects what happened since the last report
+type importProgress struct {
+	bytes, lines, imported, duplicates, rejected int64
+	errors                                       []ImportLineError
+}
+
+func (p *importProgress) reject(line int64, reason string) {
+	p.rejected++
+	if len(p.errors) < maxImportErrors {
+		p.errors = append(p.errors, ImportLineError{Line: line, Reason: reason})
+	}
+}
+
+func (s *importService) importLines(ctx context.Context, r io.Reader, result *ImportResult, report jobs.ReportFunc) error {
+	var (
+		reader   = bufio.NewReader(r)
+		progress importProgress
+		batch    []*rating.Rating
+		lines    []int64 // Line number of each rating in batch
+		lineNo   int64
+	)
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			statuses, err := s.importer.ImportBatch(ctx, batch)
+			if err != nil {
+				return err
+			}
+			for i, status := range statuses {
+				switch status {
+				case rating.ImportInserted:
+					progress.imported++
+				case rating.ImportDuplicate:
+					progress.duplicates++
+				case rating.ImportUnknownUser:
+					progress.reject(lines[i], "user not found")
+				case rating.ImportUnknownMovie:
+					progress.reject(lines[i], "movie not found")
+				}
+			}
+			batch, lines = batch[:0], lines[:0]
+		}
+		record(result, &progress)
+		// The worker encodes reports concurrently, so it gets its own copy
+		snapshot := *result
+		snapshot.Errors = append([]ImportLineError(nil), result.Errors...)
+		report(result.progress(), snapshot)
+		return nil
+	}
+
+	for {
+		raw, err := reader.ReadBytes('\n')
+		if len(raw) > 0 {
+			lineNo++
+			progress.bytes += int64(len(raw))
+			if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+				progress.lines++
+				if imported, reason := s.parseLine(raw); reason != "" {
+					progress.reject(lineNo, reason)
+				} else {
+					batch = append(batch, imported)
+					lines = append(lines, lineNo)
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read upload: %w", err)
+		}
+		// Rejected lines are reported in the same rhythm as written ones
+		if len(batch) >= s.batchSize || progress.lines >= int64(s.batchSize) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// progress is the share of the upload processed, in percent
+func (r *ImportResult) progress() float64 {
+	if r.TotalBytes == 0 {
+		return 100
+	}
+	return float64(r.ProcessedBytes) / float64(r.TotalBytes) * 100
+}
+
+// record moves the progress since the last call into the result
+func record(result *ImportResult, progress *importProgress) {
+	result.ProcessedBytes += progress.bytes
+	result.Lines += progress.lines
+	result.Imported += progress.imported
+	result.Duplicates += progress.duplicates
+	result.Rejected += progress.rejected
+	// Lines rejected by the database come after those rejected while parsing
+	sort.Slice(progress.errors, func(i, j int) bool { return progress.errors[i].Line < progress.errors[j].Line })
+	for _, lineErr := range progress.errors {
+		if len(result.Errors) < maxImportErrors {
+			result.Errors = append(result.Errors, lineErr)
+		}
+	}
+	*progress = importProgress{errors: progress.errors[:0]}
+}
+
+// parseLine turns a line into a rating, or explains why it can't be imported
+func (s *importService) parseLine(raw []byte) (*rating.Rating, string) {
+	var line importLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return nil, "invalid JSON: " + err.Error()
+	}
+	if line.CreatedAt.IsZero() {
+		return nil, "created_at is required"
+	}
+	if line.CreatedAt.After(s.timeProvider.Now()) {
+		return nil, "created_at must not be in the future"
+	}
+	updatedAt := line.CreatedAt
+	if line.UpdatedAt != nil {
+		if line.UpdatedAt.Before(line.CreatedAt) {
+			return nil, "updated_at must not be before created_at"
+		}
+		updatedAt = *line.UpdatedAt
+	}
+
+	imported := &rating.Rating{
+		ID:               rating.RatingID(s.idGenerator.Generate()),
+		UserID:           users.UserID(strings.TrimSpace(line.UserID)),
+		MovieID:          movies.MovieID(strings.TrimSpace(line.MovieID)),
+		Score:            line.Score,
+		Review:           strings.TrimSpace(line.Review),
+		ContainsSpoilers: line.ContainsSpoilers,
+		CreatedAt:        line.CreatedAt,
+		UpdatedAt:        updatedAt,
+		Version:          1,
+	}
+	if err := imported.Validate(); err != nil {
+		return nil, err.Error()
+	}
+	if err := s.reviewLimits.Check(imported.Review); err != nil {
+		return nil, err.Error()
+	}
+	return imported, ""
+}
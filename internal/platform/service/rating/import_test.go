@@ -0,0 +1,185 @@
+package rating
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/storage"
+)
+
+// fakeImporter treats user-dup as having rated everything and user-missing
+// and movie-missing as unknown
+type fakeImporter struct {
+	mu      sync.Mutex
+	batches [][]*rating.Rating
+	err     error
+}
+
+func (f *fakeImporter) ImportBatch(ctx context.Context, ratings []*rating.Rating) ([]rating.ImportStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.batches = append(f.batches, append([]*rating.Rating(nil), ratings...))
+
+	statuses := make([]rating.ImportStatus, len(ratings))
+	for i, r := range ratings {
+		switch {
+		case r.UserID == "user-dup":
+			statuses[i] = rating.ImportDuplicate
+		case r.UserID == "user-missing":
+			statuses[i] = rating.ImportUnknownUser
+		case r.MovieID == "movie-missing":
+			statuses[i] = rating.ImportUnknownMovie
+		default:
+			statuses[i] = rating.ImportInserted
+		}
+	}
+	return statuses, nil
+}
+
+// fakeQueue records the jobs it is given instead of running them
+type fakeQueue struct {
+	jobs []*jobs.Job
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &jobs.Job{ID: "job-1", Type: jobType, Status: jobs.StatusQueued, Payload: data, CreatedBy: createdBy}
+	q.jobs = append(q.jobs, job)
+	return job, nil
+}
+
+func setupImportService(t *testing.T, importer rating.Importer, opts ...ImportOption) (ImportService, *fakeQueue, storage.Storage) {
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/media"})
+	require.NoError(t, err)
+	queue := &fakeQueue{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	timeProvider := &mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	service := NewImportService(importer, queue, store, &mockIDGenerator{id: "import-123"}, timeProvider, logger, opts...)
+	return service, queue, store
+}
+
+// runImport runs the queued job and returns the last result it reported
+func runImport(t *testing.T, service ImportService, job *jobs.Job) (ImportResult, float64, error) {
+	t.Helper()
+	var (
+		result   ImportResult
+		progress float64
+	)
+	err := service.RunImport(context.Background(), job, func(p float64, r any) {
+		progress, result = p, r.(ImportResult)
+	})
+	return result, progress, err
+}
+
+func TestImportRatings(t *testing.T) {
+	importer := &fakeImporter{}
+	service, queue, store := setupImportService(t, importer, WithImportBatchSize(2))
+
+	body := strings.Join([]string{
+		`{"user_id":"user-1","movie_id":"movie-1","score":4,"review":"  Classic ","created_at":"2012-03-04T05:06:07Z"}`,
+		``,
+		`{"user_id":"user-2",`,
+		`{"user_id":"user-dup","movie_id":"movie-1","score":3,"created_at":"2013-01-01T00:00:00Z"}`,
+		`{"user_id":"user-3","movie_id":"movie-1","score":7,"created_at":"2013-01-01T00:00:00Z"}`,
+		`{"user_id":"user-3","movie_id":"movie-missing","score":2,"created_at":"2013-01-01T00:00:00Z"}`,
+		`{"user_id":"user-4","movie_id":"movie-1","score":5,"created_at":"2030-01-01T00:00:00Z"}`,
+		`{"user_id":"user-5","movie_id":"movie-1","score":1,"created_at":"2014-01-01T00:00:00Z","updated_at":"2015-01-01T00:00:00Z"}`,
+	}, "\n")
+
+	job, err := service.StartImport(context.Background(), strings.NewReader(body), "admin-1")
+	require.NoError(t, err)
+	require.Len(t, queue.jobs, 1)
+	assert.Equal(t, ImportJobType, job.Type)
+	assert.Equal(t, "admin-1", job.CreatedBy)
+	assert.JSONEq(t, `{"key":"private/imports/ratings/import-123.ndjson","size":`+strconv.Itoa(len(body))+`}`, string(job.Payload))
+
+	result, progress, err := runImport(t, service, job)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, progress)
+	assert.Equal(t, int64(len(body)), result.ProcessedBytes)
+	assert.Equal(t, int64(7), result.Lines)
+	assert.Equal(t, int64(2), result.Imported)
+	assert.Equal(t, int64(1), result.Duplicates)
+	assert.Equal(t, int64(4), result.Rejected)
+	require.Len(t, result.Errors, 4)
+	assert.Equal(t, int64(3), result.Errors[0].Line)
+	assert.Contains(t, result.Errors[0].Reason, "invalid JSON")
+	assert.Equal(t, ImportLineError{Line: 5, Reason: rating.ErrInvalidScore.Error()}, result.Errors[1])
+	assert.Equal(t, ImportLineError{Line: 6, Reason: "movie not found"}, result.Errors[2])
+	assert.Equal(t, ImportLineError{Line: 7, Reason: "created_at must not be in the future"}, result.Errors[3])
+
+	var imported []*rating.Rating
+	for _, batch := range importer.batches {
+		imported = append(imported, batch...)
+	}
+	require.Len(t, imported, 4)
+	assert.Equal(t, "Classic", imported[0].Review)
+	assert.Equal(t, time.Date(2012, 3, 4, 5, 6, 7, 0, time.UTC), imported[0].CreatedAt)
+	assert.Equal(t, imported[0].CreatedAt, imported[0].UpdatedAt)
+	assert.Equal(t, time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), imported[3].UpdatedAt)
+
+	// The upload is removed once imported
+	_, _, err = store.Get(context.Background(), "private/imports/ratings/import-123.ndjson")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestImportRatings_WriteFailure(t *testing.T) {
+	service, _, store := setupImportService(t, &fakeImporter{err: errors.New("connection refused")})
+
+	job, err := service.StartImport(context.Background(),
+		strings.NewReader(`{"user_id":"user-1","movie_id":"movie-1","score":4,"created_at":"2012-03-04T05:06:07Z"}`), "admin-1")
+	require.NoError(t, err)
+
+	_, _, err = runImport(t, service, job)
+	require.Error(t, err)
+	assert.False(t, jobs.IsPermanent(err), "database errors are retried")
+
+	// A retry needs the upload
+	_, _, err = store.Get(context.Background(), "private/imports/ratings/import-123.ndjson")
+	assert.NoError(t, err)
+}
+
+func TestImportRatings_InvalidJobs(t *testing.T) {
+	service, _, _ := setupImportService(t, &fakeImporter{})
+
+	_, _, err := runImport(t, service, &jobs.Job{Payload: json.RawMessage(`{}`)})
+	assert.True(t, jobs.IsPermanent(err))
+
+	_, _, err = runImport(t, service, &jobs.Job{Payload: json.RawMessage(`{"key":"private/imports/ratings/gone.ndjson"}`)})
+	assert.True(t, jobs.IsPermanent(err))
+}
+
+func TestImportRatings_InvalidUpload(t *testing.T) {
+	service, queue, _ := setupImportService(t, &fakeImporter{}, WithImportMaxBytes(10))
+
+	_, err := service.StartImport(context.Background(), strings.NewReader(strings.Repeat("x", 11)), "admin-1")
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.StatusCode)
+
+	_, err = service.StartImport(context.Background(), strings.NewReader("\n\n"), "admin-1")
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	assert.Empty(t, queue.jobs)
+}
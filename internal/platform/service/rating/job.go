@@ -9,7 +9,11 @@ import (
 )
 
 const (
+	// DefaultGlobalAverage is used until there are ratings
 	DefaultGlobalAverage = 3.0
+	// DefaultGlobalAverageTTL is how long the service may use a global
+	// average before reading the running totals again
+	DefaultGlobalAverageTTL = time.Minute
 )
 
 func NewRatingServiceWithStartup(
@@ -25,6 +29,7 @@ func NewRatingServiceWithStartup(
 		logger:         logger,
 		bayesianConfig: DefaultBayesianConfig(),
 		globalAverage:  DefaultGlobalAverage,
+		globalTTL:      DefaultGlobalAverageTTL,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -37,6 +42,8 @@ func NewRatingServiceWithStartup(
 	return service, nil
 }
 
+// StartGlobalAverageUpdater recomputes the running rating totals from
+// scratch every updateInterval, correcting any drift
 func (s *ratingService) StartGlobalAverageUpdater(ctx context.Context, updateInterval time.Duration) {
 	ticker := time.NewTicker(updateInterval)
 	defer ticker.Stop()
@@ -49,8 +56,8 @@ func (s *ratingService) StartGlobalAverageUpdater(ctx context.Context, updateInt
 			s.logger.Info("Stopping global average updater")
 			return
 		case <-ticker.C:
-			if err := s.UpdateGlobalAverage(ctx); err != nil {
-				s.logger.Error("Periodic global average update failed", "error", err)
+			if _, err := s.RefreshGlobalAverage(ctx); err != nil {
+				s.logger.Error("Periodic global average refresh failed", "error", err)
 			}
 		}
 	}
@@ -55,6 +55,14 @@ func (m *mockRatingRepository) GetByMovie(ctx context.Context, movieID movies.Mo
 	return args.Get(0).([]*rating.Rating), args.Error(1)
 }
 
+func (m *mockRatingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter rating.UserRatingFilter, options ...rating.SearchOption) ([]*rating.RatingWithMovie, int64, error) {
+	args := m.Called(ctx, userID, filter, options)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.RatingWithMovie), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *mockRatingRepository) Update(ctx context.Context, r *rating.Rating) (*rating.Rating, error) {
 	args := m.Called(ctx, r)
 	if args.Get(0) == nil {
@@ -63,11 +71,27 @@ func (m *mockRatingRepository) Update(ctx context.Context, r *rating.Rating) (*r
 	return args.Get(0).(*rating.Rating), args.Error(1)
 }
 
-func (m *mockRatingRepository) Delete(ctx context.Context, id rating.RatingID) error {
-	args := m.Called(ctx, id)
+func (m *mockRatingRepository) Delete(ctx context.Context, id rating.RatingID, deletedAt time.Time) error {
+	args := m.Called(ctx, id, deletedAt)
 	return args.Error(0)
 }
 
+func (m *mockRatingRepository) GetByIDIncludingDeleted(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *mockRatingRepository) Restore(ctx context.Context, id rating.RatingID, deletedSince time.Time) (*rating.Rating, error) {
+	args := m.Called(ctx, id, deletedSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
 func (m *mockRatingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*rating.MovieRatingStats, error) {
 	args := m.Called(ctx, movieID)
 	if args.Get(0) == nil {
@@ -86,9 +110,60 @@ func (m *mockRatingRepository) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *mockRatingRepository) GetGlobalAverageRating(ctx context.Context) (float64, error) {
+func (m *mockRatingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]movies.MovieID), args.Error(1)
+}
+
+func (m *mockRatingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.UserID), args.Error(1)
+}
+
+func (m *mockRatingRepository) GetGlobalStats(ctx context.Context) (*rating.GlobalStats, error) {
 	args := m.Called(ctx)
-	return args.Get(0).(float64), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *mockRatingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*rating.GlobalStats, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *mockRatingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*rating.RaterGroup, error) {
+	args := m.Called(ctx, movieID, asOf, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.RaterGroup), args.Error(1)
+}
+
+func (m *mockRatingRepository) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, options ...rating.SearchOption) ([]*rating.ReviewMatch, int64, error) {
+	args := m.Called(ctx, query, filter, options)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.ReviewMatch), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *mockRatingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*rating.RatingActivity, error) {
+	args := m.Called(ctx, movieID, baselineStart, windowStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.RatingActivity), args.Error(1)
 }
 
 type mockIDGenerator struct {
@@ -106,3 +181,32 @@ type mockTimeProvider struct {
 func (m *mockTimeProvider) Now() time.Time {
 	return m.now
 }
+
+type mockAnomalyNotifier struct {
+	mock.Mock
+}
+
+func (m *mockAnomalyNotifier) NotifyVolatility(ctx context.Context, movieID string, volatility *rating.Volatility) error {
+	args := m.Called(ctx, movieID, volatility)
+	return args.Error(0)
+}
+
+type mockComparisonRepository struct {
+	mock.Mock
+}
+
+func (m *mockComparisonRepository) CompareMovies(ctx context.Context, first, second movies.MovieID) (*rating.MovieComparison, error) {
+	args := m.Called(ctx, first, second)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.MovieComparison), args.Error(1)
+}
+
+func (m *mockComparisonRepository) GetHeadToHead(ctx context.Context, first, second movies.MovieID) (*rating.HeadToHead, error) {
+	args := m.Called(ctx, first, second)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.HeadToHead), args.Error(1)
+}
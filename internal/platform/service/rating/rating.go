@@ -2,13 +2,21 @@ package rating
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/shared"
 	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
 	"thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/i18n"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/markdown"
+	"time"
 )
 
 // Bayesian rating configuration
@@ -16,14 +24,17 @@ type BayesianConfig struct {
 	MinVotes      int64   // Minimum votes to consider reliable (e.g., 10)
 	GlobalAverage float64 // Global average rating across all movies
 	ConfidenceK   float64 // Confidence parameter - higher = more conservative
+	// DisplayMinRatings is how many ratings a movie needs before its
+	// averages are shown; 0 always shows them
+	DisplayMinRatings int64
 }
 
 // Default Bayesian configuration
 func DefaultBayesianConfig() BayesianConfig {
 	return BayesianConfig{
-		MinVotes:      10,   // Require at least 10 votes for full confidence
-		GlobalAverage: 3.0,  // Assume global average of 3.0 (middle of 1-5 scale)
-		ConfidenceK:   25.0, // Confidence factor (similar to IMDb)
+		MinVotes:      10,                                // Require at least 10 votes for full confidence
+		GlobalAverage: 3.0,                               // Assume global average of 3.0 (middle of 1-5 scale)
+		ConfidenceK:   rating.DefaultBayesianConfidenceK, // Confidence factor (similar to IMDb)
 	}
 }
 
@@ -34,23 +45,87 @@ type EnhancedMovieStats struct {
 	Confidence      float64 `json:"confidence"`  // How confident we are (0-1)
 	Percentile      float64 `json:"percentile"`  // Percentile rank among all movies
 	Explanation     string  `json:"explanation"` // Human-readable explanation
+	// WilsonLowerBound is the alternative to the Bayesian average: the lower
+	// bound of the Wilson score interval of the same votes, on the 1-5 scale
+	WilsonLowerBound float64 `json:"wilson_lower_bound"`
+	// Breakdown is every number the Bayesian average was computed from
+	Breakdown ScoreBreakdown `json:"breakdown"`
+}
+
+// ScoreBreakdown is every number a movie's Bayesian average is computed
+// from, (C*m + R*v) / (C + v), so the score can be shown and checked step
+// by step
+type ScoreBreakdown struct {
+	// ConfidenceK (C) is how many votes at the global average every movie
+	// starts with
+	ConfidenceK float64 `json:"confidence_k"`
+	// GlobalAverage (m) is the average of all ratings as loaded at
+	// GlobalAverageLoadedAt, which is zero while the configured one is used
+	GlobalAverage         float64   `json:"global_average"`
+	GlobalAverageLoadedAt time.Time `json:"global_average_loaded_at"`
+	// MovieAverage (R) and Votes (v) are the movie's average and votes the
+	// score is computed from: trust-weighted when TrustWeighted, without
+	// the ExcludedVotes of a flagged rating spike
+	MovieAverage  float64 `json:"movie_average"`
+	Votes         float64 `json:"votes"`
+	TrustWeighted bool    `json:"trust_weighted"`
+	ExcludedVotes float64 `json:"excluded_votes"`
+	// RawAverage and RawRatings are the movie's plain average and count
+	RawAverage float64 `json:"raw_average"`
+	RawRatings int64   `json:"raw_ratings"`
+	// PriorWeight, C / (C + v), and MovieWeight, v / (C + v), are the shares
+	// of the global and the movie's average in the score
+	PriorWeight     float64 `json:"prior_weight"`
+	MovieWeight     float64 `json:"movie_weight"`
+	BayesianAverage float64 `json:"bayesian_average"`
+	// MinVotes is the number of ratings for full confidence; confidence is
+	// RawRatings / MinVotes up to 1
+	MinVotes int64 `json:"min_votes"`
 }
 
 type Service interface {
 	CreateRating(ctx context.Context, req CreateRatingRequest) (*rating.Rating, error)
 	GetRatingByID(ctx context.Context, id string) (*rating.Rating, error)
 	GetUserRating(ctx context.Context, userID, movieID string) (*rating.Rating, error)
-	UpdateRating(ctx context.Context, id string, req UpdateRatingRequest) (*rating.Rating, error)
-	DeleteRating(ctx context.Context, id string) error
+	// UpdateRating, DeleteRating and RestoreRating change the rating only
+	// when the requester rated it or is an admin
+	UpdateRating(ctx context.Context, id string, requester Requester, req UpdateRatingRequest) (*rating.Rating, error)
+	// DeleteRating hides the rating; it can be restored for the restore
+	// window
+	DeleteRating(ctx context.Context, id string, requester Requester) error
+	// RestoreRating brings back a rating deleted within the restore window
+	RestoreRating(ctx context.Context, id string, requester Requester) (*rating.Rating, error)
 	GetUserRatings(ctx context.Context, userID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error)
-	GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error)
+	GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string, filter MovieRatingsFilter) ([]*rating.Rating, int64, error)
 	GetMovieStats(ctx context.Context, movieID string) (*rating.MovieRatingStats, error)
+	// SearchReviews finds ratings whose review text matches query, best
+	// matches first
+	SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, limit, offset int) ([]*rating.ReviewMatch, int64, error)
 
 	// Enhanced methods with Bayesian calculation
 	GetEnhancedMovieStats(ctx context.Context, movieID string) (*EnhancedMovieStats, error)
+	// CompareMovies puts two movies' ratings side by side, with how the
+	// users who rated both scored them
+	CompareMovies(ctx context.Context, firstID, secondID string) (*MovieComparison, error)
+	// GetHeadToHead tells how the users who rated both movies scored them
+	GetHeadToHead(ctx context.Context, firstID, secondID string) (*rating.HeadToHead, error)
+	// UpdateGlobalAverage reloads the global average from the running
+	// rating totals
 	UpdateGlobalAverage(ctx context.Context) error
+	// GlobalAverage returns the cached global average and the totals behind
+	// it, reloading them first once they are older than the TTL
+	GlobalAverage(ctx context.Context) (*GlobalAverage, error)
+	// RefreshGlobalAverage recomputes the running totals from the ratings
+	RefreshGlobalAverage(ctx context.Context) (*GlobalAverage, error)
+	// StartGlobalAverageUpdater runs RefreshGlobalAverage every interval
+	// until ctx is done
+	StartGlobalAverageUpdater(ctx context.Context, interval time.Duration)
 	GetBayesianConfig() BayesianConfig
 	SetBayesianConfig(config BayesianConfig)
+	// WithholdStats hides the averages of stats with fewer ratings than
+	// DisplayMinRatings, and reports whether it did. Every stats response
+	// goes through it.
+	WithholdStats(stats *rating.MovieRatingStats) bool
 }
 
 type ratingService struct {
@@ -58,9 +133,58 @@ type ratingService struct {
 	idGenerator    shared.IDGenerator
 	timeProvider   shared.TimeProvider
 	logger         *slog.Logger
+	bayesianMu     sync.RWMutex // Guards bayesianConfig and the global average fields, which change at runtime
 	bayesianConfig BayesianConfig
 	globalAverage  float64 // Cached global average
-	testMode       bool    // If true, run background updates synchronously (for tests)
+	globalStats    rating.GlobalStats
+	globalLoadedAt time.Time     // When globalStats were last read; zero until then
+	globalTTL      time.Duration // Reload the global average when older; 0 never reloads on its own
+	testMode       bool          // If true, run background updates synchronously (for tests)
+	volatility     *volatilityDetector
+	trust          *users.TrustConfig
+	reviewLimits   rating.ReviewLimits
+	restoreWindow  time.Duration // How long a deleted rating can be restored
+	comparisonRepo rating.ComparisonRepository
+	cache          cache.Cache
+}
+
+// DefaultRestoreWindow is how long a deleted rating can be restored unless
+// WithRestoreWindow says otherwise
+const DefaultRestoreWindow = 72 * time.Hour
+
+// Option configures optional settings of the rating service
+type Option func(*ratingService)
+
+// WithBayesianConfig replaces DefaultBayesianConfig. The global average is
+// still refreshed from the ratings by UpdateGlobalAverage.
+func WithBayesianConfig(config BayesianConfig) Option {
+	return func(s *ratingService) {
+		s.bayesianConfig = config
+		s.globalAverage = config.GlobalAverage
+	}
+}
+
+// WithGlobalAverageTTL makes the service reload the global average from the
+// running rating totals when the cached value is older than ttl
+func WithGlobalAverageTTL(ttl time.Duration) Option {
+	return func(s *ratingService) {
+		s.globalTTL = ttl
+	}
+}
+
+// WithReviewLimits replaces rating.DefaultReviewLimits
+func WithReviewLimits(limits rating.ReviewLimits) Option {
+	return func(s *ratingService) {
+		s.reviewLimits = limits
+	}
+}
+
+// WithRestoreWindow replaces DefaultRestoreWindow; 0 makes deletes final
+// for clients, though the rows are kept until the retention job purges them
+func WithRestoreWindow(window time.Duration) Option {
+	return func(s *ratingService) {
+		s.restoreWindow = window
+	}
 }
 
 func NewRatingService(
@@ -68,8 +192,9 @@ func NewRatingService(
 	idGenerator shared.IDGenerator,
 	timeProvider shared.TimeProvider,
 	logger *slog.Logger,
+	opts ...Option,
 ) Service {
-	return &ratingService{
+	s := &ratingService{
 		ratingRepo:     ratingRepo,
 		idGenerator:    idGenerator,
 		timeProvider:   timeProvider,
@@ -77,7 +202,13 @@ func NewRatingService(
 		bayesianConfig: DefaultBayesianConfig(),
 		globalAverage:  3.0, // Default until first calculation
 		testMode:       false,
+		reviewLimits:   rating.DefaultReviewLimits(),
+		restoreWindow:  DefaultRestoreWindow,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // NewTestRatingService is used for tests to enable synchronous background updates
@@ -86,8 +217,9 @@ func NewTestRatingService(
 	idGenerator shared.IDGenerator,
 	timeProvider shared.TimeProvider,
 	logger *slog.Logger,
+	opts ...Option,
 ) Service {
-	return &ratingService{
+	s := &ratingService{
 		ratingRepo:     ratingRepo,
 		idGenerator:    idGenerator,
 		timeProvider:   timeProvider,
@@ -95,7 +227,13 @@ func NewTestRatingService(
 		bayesianConfig: DefaultBayesianConfig(),
 		globalAverage:  3.0,
 		testMode:       true,
+		reviewLimits:   rating.DefaultReviewLimits(),
+		restoreWindow:  DefaultRestoreWindow,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func NewRatingServiceWithConfig(
@@ -113,6 +251,8 @@ func NewRatingServiceWithConfig(
 		bayesianConfig: config,
 		globalAverage:  config.GlobalAverage,
 		testMode:       false,
+		reviewLimits:   rating.DefaultReviewLimits(),
+		restoreWindow:  DefaultRestoreWindow,
 	}
 }
 
@@ -123,34 +263,56 @@ func NewRatingServiceWithConfig(
 // - m = global average rating
 // - R = average rating for this movie
 // - v = number of votes for this movie
-func (s *ratingService) calculateBayesianAverage(movieAverage float64, movieVotes int64) float64 {
+func (s *ratingService) calculateBayesianAverage(movieAverage float64, movieVotes float64) float64 {
+	return s.bayesianBreakdown(movieAverage, movieVotes).BayesianAverage
+}
+
+// bayesianBreakdown computes the Bayesian average of calculateBayesianAverage,
+// keeping the inputs and intermediate numbers
+func (s *ratingService) bayesianBreakdown(movieAverage float64, movieVotes float64) ScoreBreakdown {
+	s.bayesianMu.RLock()
 	C := s.bayesianConfig.ConfidenceK
 	m := s.globalAverage
+	loadedAt := s.globalLoadedAt
+	s.bayesianMu.RUnlock()
 	R := movieAverage
-	v := float64(movieVotes)
+	v := movieVotes
 
+	breakdown := ScoreBreakdown{
+		ConfidenceK:           C,
+		GlobalAverage:         m,
+		GlobalAverageLoadedAt: loadedAt,
+		MovieAverage:          R,
+		Votes:                 v,
+	}
 	if v == 0 {
-		return m // Return global average if no votes
+		// Return global average if no votes
+		breakdown.PriorWeight = 1
+		breakdown.BayesianAverage = m
+		return breakdown
 	}
 
-	bayesianAvg := (C*m + R*v) / (C + v)
+	breakdown.PriorWeight = C / (C + v)
+	breakdown.MovieWeight = v / (C + v)
+	breakdown.BayesianAverage = rating.BayesianAverage(R, v, m, C)
 	s.logger.Debug("Calculated Bayesian average",
 		"movie_avg", R,
 		"movie_votes", v,
 		"global_avg", m,
 		"confidence_k", C,
-		"bayesian_avg", bayesianAvg,
+		"bayesian_avg", breakdown.BayesianAverage,
 	)
 
-	return bayesianAvg
+	return breakdown
 }
 
 // Calculate confidence score (0-1) based on number of ratings
 func (s *ratingService) calculateConfidence(totalRatings int64) float64 {
-	if totalRatings >= s.bayesianConfig.MinVotes {
+	minVotes := s.GetBayesianConfig().MinVotes
+	if totalRatings >= minVotes {
 		return 1.0
 	}
-	confidence := float64(totalRatings) / float64(s.bayesianConfig.MinVotes)
+	confidence := float64(totalRatings) / float64(minVotes)
 	return confidence
 }
 
@@ -181,31 +343,34 @@ func (s *ratingService) estimatePercentile(bayesianAverage float64) float64 {
 	}
 }
 
-// Generate human-readable explanation
-func (s *ratingService) generateExplanation(stats *EnhancedMovieStats) string {
+// Generate human-readable explanation in the request's language
+func (s *ratingService) generateExplanation(ctx context.Context, stats *EnhancedMovieStats) string {
 	totalRatings := stats.TotalRatings
 	confidence := stats.Confidence
 
 	if totalRatings == 0 {
-		return "No ratings yet. Score shows global average."
+		return i18n.T(ctx, "No ratings yet. Score shows global average.")
 	}
 
-	if totalRatings < s.bayesianConfig.MinVotes {
-		return fmt.Sprintf("Rating adjusted for small sample size (%d ratings). Bayesian average considers global trends.", totalRatings)
+	if totalRatings < s.GetBayesianConfig().MinVotes {
+		return i18n.Sprintf(ctx, "Rating adjusted for small sample size (%d ratings). Bayesian average considers global trends.", totalRatings)
 	}
 
 	if confidence >= 0.95 {
-		return fmt.Sprintf("High confidence rating based on %d user ratings.", totalRatings)
+		return i18n.Sprintf(ctx, "High confidence rating based on %d user ratings.", totalRatings)
 	}
 
 	if confidence >= 0.8 {
-		return fmt.Sprintf("Reliable rating based on %d user ratings.", totalRatings)
+		return i18n.Sprintf(ctx, "Reliable rating based on %d user ratings.", totalRatings)
 	}
 
-	return fmt.Sprintf("Rating based on %d user ratings with %.0f%% confidence.", totalRatings, confidence*100)
+	return i18n.Sprintf(ctx, "Rating based on %d user ratings with %.0f%% confidence.", totalRatings, confidence*100)
 }
 
-func (s *ratingService) CreateRating(ctx context.Context, req CreateRatingRequest) (*rating.Rating, error) {
+func (s *ratingService) CreateRating(ctx context.Context, req CreateRatingRequest) (_ *rating.Rating, err error) {
+	op := logging.StartOp(ctx, s.logger, "rating.create", "user_id", req.UserID, "movie_id", req.MovieID)
+	defer op.End(&err)
+
 	existingRating, err := s.ratingRepo.GetByUserAndMovie(ctx, users.UserID(req.UserID), movies.MovieID(req.MovieID))
 	if err == nil && existingRating != nil {
 		s.logger.Warn("User attempted to rate movie twice",
@@ -226,6 +391,11 @@ func (s *ratingService) CreateRating(ctx context.Context, req CreateRatingReques
 		s.logger.Error("Failed to create rating domain object", "error", err)
 		return nil, errors.NewBadRequestError(err.Error())
 	}
+	if err := s.reviewLimits.Check(newRating.Review); err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	newRating.ContainsSpoilers = req.ContainsSpoilers || markdown.HasSpoilers(newRating.Review)
+	newRating.ContainsAdultLanguage = req.ContainsAdultLanguage
 
 	s.logger.Info("Creating rating",
 		"user_id", req.UserID,
@@ -242,15 +412,15 @@ func (s *ratingService) CreateRating(ctx context.Context, req CreateRatingReques
 		return nil, errors.NewInternalError("Failed to create rating")
 	}
 
-	// Update global average in background after new rating
+	op.With("rating_id", savedRating.ID)
+	s.invalidateCaches(ctx, savedRating)
+
+	// Checking for a rating spike here alerts admins even if nobody looks at
+	// the stats. The global average follows from the running totals.
 	if s.testMode {
-		_ = s.UpdateGlobalAverage(context.Background())
+		s.detectVolatility(context.Background(), req.MovieID)
 	} else {
-		go func() {
-			if err := s.UpdateGlobalAverage(context.Background()); err != nil {
-				s.logger.Error("Failed to update global average", "error", err)
-			}
-		}()
+		go s.detectVolatility(context.Background(), req.MovieID)
 	}
 
 	return savedRating, nil
@@ -284,7 +454,9 @@ func (s *ratingService) GetUserRating(ctx context.Context, userID, movieID strin
 	return ratingObj, nil
 }
 
-func (s *ratingService) UpdateRating(ctx context.Context, id string, req UpdateRatingRequest) (*rating.Rating, error) {
+func (s *ratingService) UpdateRating(ctx context.Context, id string, requester Requester, req UpdateRatingRequest) (_ *rating.Rating, err error) {
+	defer logging.StartOp(ctx, s.logger, "rating.update", "rating_id", id).End(&err)
+
 	existingRating, err := s.ratingRepo.GetByID(ctx, rating.RatingID(id))
 	if err != nil {
 		if isNotFoundError(err) {
@@ -294,6 +466,18 @@ func (s *ratingService) UpdateRating(ctx context.Context, id string, req UpdateR
 		s.logger.Error("Failed to get rating for update", "error", err, "rating_id", id)
 		return nil, errors.NewInternalError("Failed to get rating for update")
 	}
+	if !canChange(requester, existingRating) {
+		return nil, errors.NewForbiddenError("You can only change your own ratings")
+	}
+
+	if req.ExpectedVersion != nil && *req.ExpectedVersion != existingRating.Version {
+		s.logger.Info("Rating update precondition failed", "rating_id", id,
+			"expected_version", *req.ExpectedVersion, "current_version", existingRating.Version)
+		return nil, &VersionConflictError{
+			AppError: errors.NewPreconditionFailedError("Rating has been modified since it was read"),
+			Current:  existingRating,
+		}
+	}
 
 	updatedRating := *existingRating
 
@@ -310,31 +494,88 @@ func (s *ratingService) UpdateRating(ctx context.Context, id string, req UpdateR
 			s.logger.Error("Failed to update review", "error", err)
 			return nil, errors.NewBadRequestError(err.Error())
 		}
+		if err := s.reviewLimits.Check(updatedRating.Review); err != nil {
+			return nil, errors.NewBadRequestError(err.Error())
+		}
 		s.logger.Info("Updated rating review", "rating_id", id)
 	}
 
+	// Spoiler tags in the review keep it flagged whatever the client says
+	if req.ContainsSpoilers != nil || req.Review != nil {
+		containsSpoilers := updatedRating.ContainsSpoilers
+		if req.ContainsSpoilers != nil {
+			containsSpoilers = *req.ContainsSpoilers
+		}
+		updatedRating.MarkSpoilers(containsSpoilers || markdown.HasSpoilers(updatedRating.Review), s.timeProvider)
+	}
+	if req.ContainsAdultLanguage != nil {
+		updatedRating.MarkAdultLanguage(*req.ContainsAdultLanguage, s.timeProvider)
+	}
+
 	savedRating, err := s.ratingRepo.Update(ctx, &updatedRating)
 	if err != nil {
+		if stdErrors.Is(err, rating.ErrVersionConflict) {
+			return nil, s.versionConflict(ctx, id, req.ExpectedVersion != nil)
+		}
 		s.logger.Error("Failed to save updated rating", "error", err, "rating_id", id)
 		return nil, errors.NewInternalError("Failed to update rating")
 	}
+	s.invalidateCaches(ctx, savedRating)
 
-	// Update global average in background after rating update
-	if s.testMode {
-		_ = s.UpdateGlobalAverage(context.Background())
-	} else {
-		go func() {
-			if err := s.UpdateGlobalAverage(context.Background()); err != nil {
-				s.logger.Error("Failed to update global average after rating update", "error", err)
-			}
-		}()
+	return savedRating, nil
+}
+
+// VersionConflictError rejects an update made against a stale version of a
+// rating. Current holds the rating as it is now stored so clients can merge
+// and retry. It unwraps to a 412 AppError when the client sent If-Match and
+// to a 409 when the race was only detected on write.
+type VersionConflictError struct {
+	*errors.AppError
+	Current *rating.Rating
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return e.AppError
+}
+
+// versionConflict reloads the rating after a concurrent write won the race
+func (s *ratingService) versionConflict(ctx context.Context, id string, hadPrecondition bool) error {
+	s.logger.Info("Rating was modified concurrently", "rating_id", id)
+
+	current, err := s.ratingRepo.GetByID(ctx, rating.RatingID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Rating not found")
+		}
+		s.logger.Error("Failed to reload rating after version conflict", "error", err, "rating_id", id)
+		return errors.NewInternalError("Failed to update rating")
 	}
 
-	return savedRating, nil
+	appErr := errors.NewConflictError("Rating was modified concurrently")
+	if hadPrecondition {
+		appErr = errors.NewPreconditionFailedError("Rating has been modified since it was read")
+	}
+	return &VersionConflictError{AppError: appErr, Current: current}
 }
 
-func (s *ratingService) DeleteRating(ctx context.Context, id string) error {
-	err := s.ratingRepo.Delete(ctx, rating.RatingID(id))
+func (s *ratingService) DeleteRating(ctx context.Context, id string, requester Requester) (err error) {
+	defer logging.StartOp(ctx, s.logger, "rating.delete", "rating_id", id).End(&err)
+
+	// The rating is read first for its rater, who may delete it, and for
+	// the user and movie whose caches it clears
+	deleted, err := s.ratingRepo.GetByID(ctx, rating.RatingID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return errors.NewNotFoundError("Rating not found")
+		}
+		s.logger.Error("Failed to get rating for deletion", "error", err, "rating_id", id)
+		return errors.NewInternalError("Failed to delete rating")
+	}
+	if !canChange(requester, deleted) {
+		return errors.NewForbiddenError("You can only change your own ratings")
+	}
+
+	err = s.ratingRepo.Delete(ctx, rating.RatingID(id), s.timeProvider.Now())
 	if err != nil {
 		if isNotFoundError(err) {
 			s.logger.Debug("Rating not found for deletion", "rating_id", id)
@@ -345,19 +586,69 @@ func (s *ratingService) DeleteRating(ctx context.Context, id string) error {
 	}
 
 	s.logger.Info("Deleted rating", "rating_id", id)
+	s.invalidateCaches(ctx, deleted)
 
-	// Update global average in background after deletion
-	if s.testMode {
-		_ = s.UpdateGlobalAverage(context.Background())
-	} else {
-		go func() {
-			if err := s.UpdateGlobalAverage(context.Background()); err != nil {
-				s.logger.Error("Failed to update global average after rating deletion", "error", err)
-			}
-		}()
+	return nil
+}
+
+func (s *ratingService) RestoreRating(ctx context.Context, id string, requester Requester) (_ *rating.Rating, err error) {
+	defer logging.StartOp(ctx, s.logger, "rating.restore", "rating_id", id).End(&err)
+
+	// GetByID leaves deleted ratings out, so the rater is read from the
+	// rating whether or not it is deleted
+	existing, err := s.ratingRepo.GetByIDIncludingDeleted(ctx, rating.RatingID(id))
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil, errors.NewNotFoundError("Rating not found")
+		}
+		s.logger.Error("Failed to get rating for restore", "error", err, "rating_id", id)
+		return nil, errors.NewInternalError("Failed to restore rating")
+	}
+	if !canChange(requester, existing) {
+		return nil, errors.NewForbiddenError("You can only change your own ratings")
 	}
 
-	return nil
+	deletedSince := s.timeProvider.Now().Add(-s.restoreWindow)
+	restored, err := s.ratingRepo.Restore(ctx, rating.RatingID(id), deletedSince)
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, rating.ErrRestoreExpired):
+			return nil, errors.NewGoneError("Rating was deleted too long ago to be restored")
+		case stdErrors.Is(err, rating.ErrNotDeleted):
+			return nil, errors.NewConflictError("Rating is not deleted")
+		case isConflictError(err):
+			// The user rated the movie again after deleting this rating
+			return nil, errors.NewConflictError("User has already rated this movie")
+		case isNotFoundError(err):
+			return nil, errors.NewNotFoundError("Rating not found")
+		}
+		s.logger.Error("Failed to restore rating", "error", err, "rating_id", id)
+		return nil, errors.NewInternalError("Failed to restore rating")
+	}
+
+	s.logger.Info("Restored rating", "rating_id", id)
+	s.invalidateCaches(ctx, restored)
+
+	return restored, nil
+}
+
+// canChange reports whether requester may update, delete or restore r: its
+// rater and admins may
+func canChange(requester Requester, r *rating.Rating) bool {
+	return requester != nil && (requester.Is(string(r.UserID)) || requester.IsAdmin())
+}
+
+// invalidateCaches clears what a change to r makes stale: the profile and
+// stats of its rater and the cached stats of its movie. It runs once the
+// change is stored and before it is reported, so the rater's next read
+// misses the cache; a failure leaves the entries to expire with their TTL.
+func (s *ratingService) invalidateCaches(ctx context.Context, r *rating.Rating) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.InvalidateTags(ctx, cache.UserTag(string(r.UserID)), cache.MovieTag(string(r.MovieID))); err != nil {
+		s.logger.Warn("Failed to invalidate rating caches", "error", err, "user_id", r.UserID, "movie_id", r.MovieID)
+	}
 }
 
 func (s *ratingService) GetUserRatings(ctx context.Context, userID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error) {
@@ -379,11 +670,16 @@ func (s *ratingService) GetUserRatings(ctx context.Context, userID string, limit
 	return ratingsList, totalCount, nil
 }
 
-func (s *ratingService) GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string) ([]*rating.Rating, int64, error) {
+func (s *ratingService) GetMovieRatings(ctx context.Context, movieID string, limit, offset int, sortBy, order string, filter MovieRatingsFilter) ([]*rating.Rating, int64, error) {
 	searchOptions := []rating.SearchOption{
 		rating.WithLimit(limit),
 		rating.WithOffset(offset),
 		rating.WithSort(sortBy, order),
+		rating.WithReviewer(filter.Reviewer),
+		rating.WithSpoilers(filter.Spoilers),
+	}
+	if users.IsKidsMode(ctx) {
+		searchOptions = append(searchOptions, rating.WithoutAdultLanguage())
 	}
 
 	ratingsList, err := s.ratingRepo.GetByMovie(ctx, movies.MovieID(movieID), searchOptions...)
@@ -398,6 +694,27 @@ func (s *ratingService) GetMovieRatings(ctx context.Context, movieID string, lim
 	return ratingsList, totalCount, nil
 }
 
+func (s *ratingService) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, limit, offset int) ([]*rating.ReviewMatch, int64, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, 0, errors.NewBadRequestError("Search query is required")
+	}
+
+	searchOptions := []rating.SearchOption{rating.WithLimit(limit), rating.WithOffset(offset)}
+	if users.IsKidsMode(ctx) {
+		searchOptions = append(searchOptions, rating.WithoutAdultLanguage())
+	}
+
+	matches, total, err := s.ratingRepo.SearchReviews(ctx, query, filter, searchOptions...)
+	if err != nil {
+		s.logger.Error("Failed to search reviews", "error", err, "query", query)
+		return nil, 0, errors.NewInternalError("Failed to search reviews")
+	}
+
+	s.logger.Debug("Searched reviews", "query", query, "total", total)
+	return matches, total, nil
+}
+
 func (s *ratingService) GetMovieStats(ctx context.Context, movieID string) (*rating.MovieRatingStats, error) {
 	stats, err := s.ratingRepo.GetMovieStats(ctx, movies.MovieID(movieID))
 	if err != nil {
@@ -405,6 +722,9 @@ func (s *ratingService) GetMovieStats(ctx context.Context, movieID string) (*rat
 		return nil, errors.NewInternalError("Failed to get movie stats")
 	}
 
+	s.weighRatings(ctx, stats)
+	stats.Volatility = s.detectVolatility(ctx, movieID)
+	s.WithholdStats(stats)
 	return stats, nil
 }
 
@@ -417,20 +737,39 @@ func (s *ratingService) GetEnhancedMovieStats(ctx context.Context, movieID strin
 		return nil, errors.NewInternalError("Failed to get movie stats")
 	}
 
+	s.weighRatings(ctx, stats)
+	stats.Volatility = s.detectVolatility(ctx, movieID)
+	average, votes := stats.Votes()
+	weightedVotes := votes
+	if stats.Volatility != nil && stats.Volatility.Flagged && s.volatility.config.ExcludeFlagged {
+		average, votes = stats.WithoutSpike(stats.Volatility)
+		stats.Volatility.Excluded = true
+	}
+
 	// Calculate Bayesian metrics
-	bayesianAvg := s.calculateBayesianAverage(stats.AverageScore, stats.TotalRatings)
+	s.reloadStaleGlobalAverage(ctx)
+	breakdown := s.bayesianBreakdown(average, votes)
+	bayesianAvg := breakdown.BayesianAverage
 	confidence := s.calculateConfidence(stats.TotalRatings)
 	percentile := s.estimatePercentile(bayesianAvg)
 
+	breakdown.RawAverage = stats.AverageScore
+	breakdown.RawRatings = stats.TotalRatings
+	breakdown.TrustWeighted = stats.Trust != nil
+	breakdown.ExcludedVotes = weightedVotes - votes
+	breakdown.MinVotes = s.GetBayesianConfig().MinVotes
+
 	enhancedStats := &EnhancedMovieStats{
 		MovieRatingStats: stats,
 		BayesianAverage:  bayesianAvg,
 		Confidence:       confidence,
 		Percentile:       percentile,
+		WilsonLowerBound: rating.WilsonLowerBound(average, votes),
+		Breakdown:        breakdown,
 	}
 
 	// Add explanation
-	enhancedStats.Explanation = s.generateExplanation(enhancedStats)
+	enhancedStats.Explanation = s.generateExplanation(ctx, enhancedStats)
 
 	s.logger.Debug("Calculated enhanced movie stats",
 		"movie_id", movieID,
@@ -439,55 +778,161 @@ func (s *ratingService) GetEnhancedMovieStats(ctx context.Context, movieID strin
 		"confidence", confidence,
 		"total_ratings", stats.TotalRatings)
 
+	if s.WithholdStats(stats) {
+		enhancedStats.withhold(ctx)
+	}
 	return enhancedStats, nil
 }
 
-// Update global average rating across all movies
-func (s *ratingService) UpdateGlobalAverage(ctx context.Context) error {
-	s.logger.Info("Updating global average rating")
+// withhold hides the scores of stats whose averages were withheld, leaving
+// the breakdown only the numbers that are not the movie's
+func (e *EnhancedMovieStats) withhold(ctx context.Context) {
+	e.BayesianAverage = 0
+	e.Percentile = 0
+	e.WilsonLowerBound = 0
+	e.Explanation = i18n.T(ctx, rating.NotEnoughRatings)
+	e.Breakdown = ScoreBreakdown{
+		ConfidenceK:           e.Breakdown.ConfidenceK,
+		GlobalAverage:         e.Breakdown.GlobalAverage,
+		GlobalAverageLoadedAt: e.Breakdown.GlobalAverageLoadedAt,
+		RawRatings:            e.Breakdown.RawRatings,
+		MinVotes:              e.Breakdown.MinVotes,
+	}
+}
+
+// GlobalAverage is the cached global average rating and the running totals
+// it was computed from
+type GlobalAverage struct {
+	Average float64
+	rating.GlobalStats
+	// LoadedAt is when the service last read the totals
+	LoadedAt time.Time
+}
 
-	newGlobalAverage, err := s.ratingRepo.GetGlobalAverageRating(ctx)
+// UpdateGlobalAverage reads the running totals, which is a single-row
+// lookup; the totals are only recomputed by RefreshGlobalAverage
+func (s *ratingService) UpdateGlobalAverage(ctx context.Context) error {
+	stats, err := s.ratingRepo.GetGlobalStats(ctx)
 	if err != nil {
-		s.logger.Error("Failed to calculate global average from repository", "error", err)
+		s.logger.Error("Failed to read global rating totals", "error", err)
 		return fmt.Errorf("failed to update global average: %w", err)
 	}
 
-	oldAverage := s.globalAverage
-	s.globalAverage = newGlobalAverage
+	oldAverage, newAverage := s.setGlobalStats(stats)
+	s.logger.Debug("Updated global average",
+		"old_average", oldAverage,
+		"new_average", newAverage,
+		"total_ratings", stats.TotalRatings)
+	return nil
+}
 
-	s.bayesianConfig.GlobalAverage = newGlobalAverage
+func (s *ratingService) RefreshGlobalAverage(ctx context.Context) (*GlobalAverage, error) {
+	stats, err := s.ratingRepo.RefreshGlobalStats(ctx, s.timeProvider.Now())
+	if err != nil {
+		s.logger.Error("Failed to recompute global rating totals", "error", err)
+		return nil, errors.NewInternalError("Failed to refresh global average")
+	}
 
-	s.logger.Info("Successfully updated global average",
+	oldAverage, newAverage := s.setGlobalStats(stats)
+	s.logger.Info("Recomputed global average",
 		"old_average", oldAverage,
-		"new_average", newGlobalAverage,
-		"change", newGlobalAverage-oldAverage)
+		"new_average", newAverage,
+		"total_ratings", stats.TotalRatings)
+	return s.globalAverageSnapshot(), nil
+}
 
-	return nil
+func (s *ratingService) GlobalAverage(ctx context.Context) (*GlobalAverage, error) {
+	s.bayesianMu.RLock()
+	loaded := !s.globalLoadedAt.IsZero()
+	s.bayesianMu.RUnlock()
+
+	if !loaded {
+		if err := s.UpdateGlobalAverage(ctx); err != nil {
+			return nil, errors.NewInternalError("Failed to get global average")
+		}
+	} else {
+		s.reloadStaleGlobalAverage(ctx)
+	}
+	return s.globalAverageSnapshot(), nil
+}
+
+// reloadStaleGlobalAverage reloads the global average once it is older than
+// the TTL. On failure the stale value stays in use.
+func (s *ratingService) reloadStaleGlobalAverage(ctx context.Context) {
+	if s.globalTTL <= 0 {
+		return
+	}
+	s.bayesianMu.RLock()
+	stale := s.globalLoadedAt.IsZero() || s.timeProvider.Now().Sub(s.globalLoadedAt) >= s.globalTTL
+	s.bayesianMu.RUnlock()
+
+	if stale {
+		if err := s.UpdateGlobalAverage(ctx); err != nil {
+			s.logger.Warn("Using stale global average", "error", err)
+		}
+	}
+}
+
+func (s *ratingService) setGlobalStats(stats *rating.GlobalStats) (oldAverage, newAverage float64) {
+	newAverage, ok := stats.Average()
+	if !ok {
+		newAverage = DefaultGlobalAverage
+	}
+
+	s.bayesianMu.Lock()
+	defer s.bayesianMu.Unlock()
+	oldAverage = s.globalAverage
+	s.globalAverage = newAverage
+	s.bayesianConfig.GlobalAverage = newAverage
+	s.globalStats = *stats
+	s.globalLoadedAt = s.timeProvider.Now()
+	return oldAverage, newAverage
+}
+
+func (s *ratingService) globalAverageSnapshot() *GlobalAverage {
+	s.bayesianMu.RLock()
+	defer s.bayesianMu.RUnlock()
+	return &GlobalAverage{
+		Average:     s.globalAverage,
+		GlobalStats: s.globalStats,
+		LoadedAt:    s.globalLoadedAt,
+	}
 }
 
 // Configuration methods
 func (s *ratingService) GetBayesianConfig() BayesianConfig {
+	s.bayesianMu.RLock()
+	defer s.bayesianMu.RUnlock()
 	return s.bayesianConfig
 }
 
 func (s *ratingService) SetBayesianConfig(config BayesianConfig) {
+	s.bayesianMu.Lock()
+	defer s.bayesianMu.Unlock()
+
 	s.logger.Info("Updating Bayesian configuration",
 		"old_min_votes", s.bayesianConfig.MinVotes,
 		"new_min_votes", config.MinVotes,
 		"old_global_avg", s.bayesianConfig.GlobalAverage,
 		"new_global_avg", config.GlobalAverage,
 		"old_confidence_k", s.bayesianConfig.ConfidenceK,
-		"new_confidence_k", config.ConfidenceK)
+		"new_confidence_k", config.ConfidenceK,
+		"old_display_min_ratings", s.bayesianConfig.DisplayMinRatings,
+		"new_display_min_ratings", config.DisplayMinRatings)
 
 	s.bayesianConfig = config
 	s.globalAverage = config.GlobalAverage
 }
 
+func (s *ratingService) WithholdStats(stats *rating.MovieRatingStats) bool {
+	return stats.Withhold(s.GetBayesianConfig().DisplayMinRatings)
+}
+
 // Helper functions
 func isConflictError(err error) bool {
 	return fmt.Sprintf("%v", err) == "user has already rated this movie"
 }
 
 func isNotFoundError(err error) bool {
-	return fmt.Sprintf("%v", err) == "not found"
+	return strings.Contains(err.Error(), "not found")
 }
@@ -1,10 +1,13 @@
 package rating
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"log/slog"
+	"net/http"
 	"testing"
 	"time"
 
@@ -15,9 +18,25 @@ import (
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/i18n"
+	"thermondo/internal/pkg/logging"
 )
 
 // Test helpers
+// testRequester stands in for the authenticated caller changing a rating
+type testRequester struct {
+	userID string
+	admin  bool
+}
+
+func (r testRequester) Is(id string) bool { return r.userID == id }
+func (r testRequester) IsAdmin() bool     { return r.admin }
+
+// rater is the author of createTestRating
+var rater = testRequester{userID: "user-123"}
+
 func createTestRating() *rating.Rating {
 	return &rating.Rating{
 		ID:        rating.RatingID("test-rating-123"),
@@ -27,6 +46,7 @@ func createTestRating() *rating.Rating {
 		Review:    "Great movie!",
 		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
 		UpdatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Version:   1,
 	}
 }
 
@@ -45,10 +65,12 @@ func createTestMovieStats() *rating.MovieRatingStats {
 	}
 }
 
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
 func setupTestService() (Service, *mockRatingRepository, *mockIDGenerator, *mockTimeProvider) {
 	mockRepo := new(mockRatingRepository)
 	mockIDGen := &mockIDGenerator{id: "test-rating-123"}
-	mockTimeProvider := &mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	mockTimeProvider := &mockTimeProvider{now: testNow}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	service := NewTestRatingService(mockRepo, mockIDGen, mockTimeProvider, logger)
@@ -79,10 +101,6 @@ func TestCreateRating(t *testing.T) {
 				expectedRating := createTestRating()
 				mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 					Return(expectedRating, nil)
-
-				// Global average update - expect one call for deterministic unit test
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil).Once()
 			},
 			expectedError: "",
 			expectSuccess: true,
@@ -220,7 +238,7 @@ func TestGetRatingByID(t *testing.T) {
 			ratingID: "nonexistent-rating",
 			setupMocks: func(mockRepo *mockRatingRepository) {
 				mockRepo.On("GetByID", mock.Anything, rating.RatingID("nonexistent-rating")).
-					Return(nil, errors.New("not found"))
+					Return(nil, errors.New("rating with ID nonexistent-rating not found"))
 			},
 			expectedError: "Rating not found",
 			expectSuccess: false,
@@ -287,10 +305,6 @@ func TestUpdateRating(t *testing.T) {
 				updatedRating.UpdatedAt = mockTimeProvider.Now()
 				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 					Return(&updatedRating, nil)
-
-				// Global average update
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil)
 			},
 			expectSuccess: true,
 			validateResult: func(t *testing.T, result *rating.Rating) {
@@ -314,10 +328,6 @@ func TestUpdateRating(t *testing.T) {
 				updatedRating.UpdatedAt = mockTimeProvider.Now()
 				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 					Return(&updatedRating, nil)
-
-				// Global average update
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil)
 			},
 			expectSuccess: true,
 			validateResult: func(t *testing.T, result *rating.Rating) {
@@ -343,10 +353,6 @@ func TestUpdateRating(t *testing.T) {
 				updatedRating.UpdatedAt = mockTimeProvider.Now()
 				mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 					Return(&updatedRating, nil)
-
-				// Global average update
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil)
 			},
 			expectSuccess: true,
 			validateResult: func(t *testing.T, result *rating.Rating) {
@@ -388,7 +394,7 @@ func TestUpdateRating(t *testing.T) {
 			service, mockRepo, _, mockTimeProvider := setupTestService()
 			tt.setupMocks(mockRepo, mockTimeProvider)
 
-			result, err := service.UpdateRating(context.Background(), tt.ratingID, tt.request)
+			result, err := service.UpdateRating(context.Background(), tt.ratingID, rater, tt.request)
 
 			if tt.expectSuccess {
 				assert.NoError(t, err)
@@ -407,6 +413,182 @@ func TestUpdateRating(t *testing.T) {
 	}
 }
 
+func TestUpdateRatingVersionConflict(t *testing.T) {
+	t.Run("stale If-Match is rejected before writing", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).
+			Return(createTestRating(), nil)
+
+		_, err := service.UpdateRating(context.Background(), "test-rating-123", rater, UpdateRatingRequest{
+			Score:           intPtr(5),
+			ExpectedVersion: intPtr(3),
+		})
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, http.StatusPreconditionFailed, conflict.StatusCode)
+		assert.Equal(t, 1, conflict.Current.Version)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent write returns the current rating", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		current := createTestRating()
+		current.Score = 1
+		current.Version = 2
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).
+			Return(createTestRating(), nil).Once()
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).
+			Return(nil, rating.ErrVersionConflict)
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).
+			Return(current, nil).Once()
+
+		_, err := service.UpdateRating(context.Background(), "test-rating-123", rater, UpdateRatingRequest{Score: intPtr(5)})
+
+		var conflict *VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, http.StatusConflict, conflict.StatusCode)
+		assert.Equal(t, current, conflict.Current)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRatingChangesRequireTheRater(t *testing.T) {
+	stranger := testRequester{userID: "user-456"}
+	admin := testRequester{userID: "admin-1", admin: true}
+
+	t.Run("others cannot change a rating", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("GetByIDIncludingDeleted", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+
+		_, updateErr := service.UpdateRating(context.Background(), "test-rating-123", stranger, UpdateRatingRequest{Score: intPtr(5)})
+		deleteErr := service.DeleteRating(context.Background(), "test-rating-123", stranger)
+		_, restoreErr := service.RestoreRating(context.Background(), "test-rating-123", stranger)
+
+		for _, err := range []error{updateErr, deleteErr, restoreErr} {
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, http.StatusForbidden, appErr.StatusCode)
+		}
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("admins can", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).Return(nil)
+
+		require.NoError(t, service.DeleteRating(context.Background(), "test-rating-123", admin))
+	})
+}
+
+func TestReviewRules(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	newService := func(repo *mockRatingRepository) Service {
+		return NewTestRatingService(repo, &mockIDGenerator{id: "test-rating-123"}, &mockTimeProvider{now: time.Now()}, logger,
+			WithReviewLimits(rating.ReviewLimits{MinLength: 10, MaxLength: 50}))
+	}
+	saved := func(spoilers bool) interface{} {
+		return mock.MatchedBy(func(r *rating.Rating) bool { return r.ContainsSpoilers == spoilers })
+	}
+
+	t.Run("spoiler tags flag the review", func(t *testing.T) {
+		repo := new(mockRatingRepository)
+		repo.On("GetByUserAndMovie", mock.Anything, users.UserID("user-123"), movies.MovieID("movie-123")).Return(nil, errors.New("not found"))
+		repo.On("Save", mock.Anything, saved(true)).Return(createTestRating(), nil)
+
+		_, err := newService(repo).CreateRating(ctx, CreateRatingRequest{
+			UserID: "user-123", MovieID: "movie-123", Score: 4, Review: "The twist: ||he was dead||",
+		})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("review outside the length limits", func(t *testing.T) {
+		repo := new(mockRatingRepository)
+		repo.On("GetByUserAndMovie", mock.Anything, users.UserID("user-123"), movies.MovieID("movie-123")).Return(nil, errors.New("not found"))
+
+		_, err := newService(repo).CreateRating(ctx, CreateRatingRequest{
+			UserID: "user-123", MovieID: "movie-123", Score: 4, Review: "Meh",
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 10 characters")
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("update cannot clear the flag while tags remain", func(t *testing.T) {
+		repo := new(mockRatingRepository)
+		existing := createTestRating()
+		existing.Review = "Loved it, ||the dog survives||"
+		existing.ContainsSpoilers = true
+		repo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(existing, nil)
+		repo.On("Update", mock.Anything, saved(true)).Return(existing, nil)
+
+		noSpoilers := false
+		_, err := newService(repo).UpdateRating(ctx, "test-rating-123", rater, UpdateRatingRequest{ContainsSpoilers: &noSpoilers})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("update clears the flag", func(t *testing.T) {
+		repo := new(mockRatingRepository)
+		existing := createTestRating()
+		existing.ContainsSpoilers = true
+		repo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(existing, nil)
+		repo.On("Update", mock.Anything, saved(false)).Return(existing, nil)
+
+		noSpoilers := false
+		_, err := newService(repo).UpdateRating(ctx, "test-rating-123", rater, UpdateRatingRequest{ContainsSpoilers: &noSpoilers})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestSearchReviews(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("trims the query and pages the search", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		filter := rating.ReviewFilter{MovieID: "movie-123"}
+		matches := []*rating.ReviewMatch{{Rating: createTestRating(), Snippet: "<mark>twist</mark>"}}
+		mockRepo.On("SearchReviews", ctx, "twist", filter, mock.Anything).Return(matches, int64(1), nil)
+
+		result, total, err := service.SearchReviews(ctx, "  twist ", filter, 20, 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, matches, result)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("blank query", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+
+		_, _, err := service.SearchReviews(ctx, "   ", rating.ReviewFilter{}, 20, 0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Search query is required")
+		mockRepo.AssertNotCalled(t, "SearchReviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("SearchReviews", ctx, "twist", rating.ReviewFilter{}, mock.Anything).Return(nil, int64(0), errors.New("db down"))
+
+		_, _, err := service.SearchReviews(ctx, "twist", rating.ReviewFilter{}, 20, 0)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Failed to search reviews")
+	})
+}
+
 func TestDeleteRating(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -419,12 +601,9 @@ func TestDeleteRating(t *testing.T) {
 			name:     "successful deletion",
 			ratingID: "test-rating-123",
 			setupMocks: func(mockRepo *mockRatingRepository) {
-				mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123")).
+				mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+				mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).
 					Return(nil)
-
-				// Global average update
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil)
 			},
 			expectSuccess: true,
 		},
@@ -432,17 +611,39 @@ func TestDeleteRating(t *testing.T) {
 			name:     "rating not found",
 			ratingID: "nonexistent-rating",
 			setupMocks: func(mockRepo *mockRatingRepository) {
-				mockRepo.On("Delete", mock.Anything, rating.RatingID("nonexistent-rating")).
+				mockRepo.On("GetByID", mock.Anything, rating.RatingID("nonexistent-rating")).Return(nil, errors.New("not found"))
+			},
+			expectedError: "Rating not found",
+			expectSuccess: false,
+		},
+		{
+			name:     "rating deleted before it is removed",
+			ratingID: "test-rating-123",
+			setupMocks: func(mockRepo *mockRatingRepository) {
+				mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+				mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).
 					Return(errors.New("not found"))
 			},
 			expectedError: "Rating not found",
 			expectSuccess: false,
 		},
+		{
+			name:     "someone else's rating",
+			ratingID: "test-rating-123",
+			setupMocks: func(mockRepo *mockRatingRepository) {
+				other := createTestRating()
+				other.UserID = "user-456"
+				mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(other, nil)
+			},
+			expectedError: "You can only change your own ratings",
+			expectSuccess: false,
+		},
 		{
 			name:     "repository error",
 			ratingID: "test-rating-123",
 			setupMocks: func(mockRepo *mockRatingRepository) {
-				mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123")).
+				mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+				mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).
 					Return(errors.New("database error"))
 			},
 			expectedError: "Failed to delete rating",
@@ -455,7 +656,7 @@ func TestDeleteRating(t *testing.T) {
 			service, mockRepo, _, _ := setupTestService()
 			tt.setupMocks(mockRepo)
 
-			err := service.DeleteRating(context.Background(), tt.ratingID)
+			err := service.DeleteRating(context.Background(), tt.ratingID, rater)
 
 			if tt.expectSuccess {
 				assert.NoError(t, err)
@@ -469,6 +670,162 @@ func TestDeleteRating(t *testing.T) {
 	}
 }
 
+func TestDeleteRating_Logging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(mockRatingRepository)
+	mockRepo.On("GetByID", mock.Anything, mock.Anything).Return(createTestRating(), nil)
+	mockRepo.On("Delete", mock.Anything, rating.RatingID("rating-1"), testNow).Return(nil)
+	mockRepo.On("Delete", mock.Anything, rating.RatingID("rating-2"), testNow).Return(errors.New("database error"))
+	service := NewTestRatingService(mockRepo, &mockIDGenerator{}, &mockTimeProvider{now: testNow}, logger)
+
+	require.NoError(t, service.DeleteRating(context.Background(), "rating-1", rater))
+	require.Error(t, service.DeleteRating(context.Background(), "rating-2", rater))
+
+	var operations []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		if record[logging.OperationKey] == "rating.delete" {
+			operations = append(operations, record)
+		}
+	}
+	require.Len(t, operations, 2)
+	assert.Equal(t, "DEBUG", operations[0]["level"])
+	assert.Equal(t, "rating-1", operations[0]["rating_id"])
+	assert.NotContains(t, operations[0], logging.ErrorKey)
+	assert.Equal(t, "ERROR", operations[1]["level"])
+	assert.Equal(t, "rating-2", operations[1]["rating_id"])
+	assert.Equal(t, "Failed to delete rating", operations[1][logging.ErrorKey])
+}
+
+func TestRestoreRating(t *testing.T) {
+	deletedSince := testNow.Add(-DefaultRestoreWindow)
+	tests := []struct {
+		name           string
+		restoreErr     error
+		expectedStatus int
+	}{
+		{name: "expired", restoreErr: rating.ErrRestoreExpired, expectedStatus: http.StatusGone},
+		{name: "not deleted", restoreErr: rating.ErrNotDeleted, expectedStatus: http.StatusConflict},
+		{name: "rated again", restoreErr: errors.New("user has already rated this movie"), expectedStatus: http.StatusConflict},
+		{name: "not found", restoreErr: errors.New("rating with ID test-rating-123 not found"), expectedStatus: http.StatusNotFound},
+		{name: "repository error", restoreErr: errors.New("database error"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo, _, _ := setupTestService()
+			mockRepo.On("GetByIDIncludingDeleted", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+			mockRepo.On("Restore", mock.Anything, rating.RatingID("test-rating-123"), deletedSince).Return(nil, tt.restoreErr)
+
+			_, err := service.RestoreRating(context.Background(), "test-rating-123", rater)
+
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.expectedStatus, appErr.StatusCode)
+		})
+	}
+
+	t.Run("restores within the window", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		restored := &rating.Rating{ID: "test-rating-123", UserID: "user-123", Score: 4}
+		mockRepo.On("GetByIDIncludingDeleted", mock.Anything, rating.RatingID("test-rating-123")).Return(restored, nil)
+		mockRepo.On("Restore", mock.Anything, rating.RatingID("test-rating-123"), deletedSince).Return(restored, nil)
+
+		result, err := service.RestoreRating(context.Background(), "test-rating-123", rater)
+
+		require.NoError(t, err)
+		assert.Equal(t, restored, result)
+	})
+
+	t.Run("unknown rating", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("GetByIDIncludingDeleted", mock.Anything, rating.RatingID("test-rating-123")).
+			Return(nil, errors.New("rating with ID test-rating-123 not found"))
+
+		_, err := service.RestoreRating(context.Background(), "test-rating-123", rater)
+
+		var appErr *appErrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRatingChangesInvalidateCaches(t *testing.T) {
+	tags := []string{cache.UserTag("user-123"), cache.MovieTag("movie-123")}
+	setup := func() (Service, *mockRatingRepository, *cache.MockCache) {
+		mockRepo := new(mockRatingRepository)
+		mockCache := new(cache.MockCache)
+		service := NewTestRatingService(mockRepo, &mockIDGenerator{id: "test-rating-123"}, &mockTimeProvider{now: testNow},
+			slog.New(slog.NewTextHandler(io.Discard, nil)), WithCache(mockCache))
+		return service, mockRepo, mockCache
+	}
+
+	t.Run("create", func(t *testing.T) {
+		service, mockRepo, mockCache := setup()
+		mockRepo.On("GetByUserAndMovie", mock.Anything, users.UserID("user-123"), movies.MovieID("movie-123")).
+			Return(nil, errors.New("not found"))
+		mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*rating.Rating")).Return(createTestRating(), nil)
+		mockCache.On("InvalidateTags", mock.Anything, tags).Return(nil)
+
+		_, err := service.CreateRating(context.Background(), CreateRatingRequest{UserID: "user-123", MovieID: "movie-123", Score: 4})
+
+		require.NoError(t, err)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		service, mockRepo, mockCache := setup()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).Return(createTestRating(), nil)
+		mockCache.On("InvalidateTags", mock.Anything, tags).Return(nil)
+
+		score := 5
+		_, err := service.UpdateRating(context.Background(), "test-rating-123", rater, UpdateRatingRequest{Score: &score})
+
+		require.NoError(t, err)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		service, mockRepo, mockCache := setup()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).Return(nil)
+		mockCache.On("InvalidateTags", mock.Anything, tags).Return(errors.New("cache unavailable"))
+
+		err := service.DeleteRating(context.Background(), "test-rating-123", rater)
+
+		require.NoError(t, err, "a failed invalidation does not fail the delete")
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		service, mockRepo, mockCache := setup()
+		mockRepo.On("GetByIDIncludingDeleted", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("Restore", mock.Anything, rating.RatingID("test-rating-123"), testNow.Add(-DefaultRestoreWindow)).
+			Return(createTestRating(), nil)
+		mockCache.On("InvalidateTags", mock.Anything, tags).Return(nil)
+
+		_, err := service.RestoreRating(context.Background(), "test-rating-123", rater)
+
+		require.NoError(t, err)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("failed changes leave the caches alone", func(t *testing.T) {
+		service, mockRepo, mockCache := setup()
+		mockRepo.On("GetByID", mock.Anything, rating.RatingID("test-rating-123")).Return(createTestRating(), nil)
+		mockRepo.On("Delete", mock.Anything, rating.RatingID("test-rating-123"), testNow).Return(errors.New("database error"))
+
+		err := service.DeleteRating(context.Background(), "test-rating-123", rater)
+
+		require.Error(t, err)
+		mockCache.AssertNotCalled(t, "InvalidateTags", mock.Anything, mock.Anything)
+	})
+}
+
 func TestGetEnhancedMovieStats(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -589,6 +946,76 @@ func TestGetEnhancedMovieStats(t *testing.T) {
 	}
 }
 
+func TestGetEnhancedMovieStats_Breakdown(t *testing.T) {
+	service, mockRepo, _, _ := setupTestService()
+	mockRepo.On("GetMovieStats", mock.Anything, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil)
+
+	result, err := service.GetEnhancedMovieStats(context.Background(), "movie-123")
+
+	require.NoError(t, err)
+	b := result.Breakdown
+	assert.Equal(t, ScoreBreakdown{
+		ConfidenceK:     25,
+		GlobalAverage:   3.0,
+		MovieAverage:    4.2,
+		Votes:           10,
+		RawAverage:      4.2,
+		RawRatings:      10,
+		PriorWeight:     25.0 / 35.0,
+		MovieWeight:     10.0 / 35.0,
+		BayesianAverage: result.BayesianAverage,
+		MinVotes:        10,
+	}, b)
+	assert.InDelta(t, b.PriorWeight*b.GlobalAverage+b.MovieWeight*b.MovieAverage, b.BayesianAverage, 1e-9)
+	assert.InDelta(t, 1.0, b.PriorWeight+b.MovieWeight, 1e-9)
+	assert.Equal(t, rating.WilsonLowerBound(4.2, 10), result.WilsonLowerBound)
+	assert.Less(t, result.WilsonLowerBound, b.MovieAverage)
+}
+
+func TestGetEnhancedMovieStats_Translated(t *testing.T) {
+	service, mockRepo, _, _ := setupTestService()
+	mockRepo.On("GetMovieStats", mock.Anything, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil)
+
+	result, err := service.GetEnhancedMovieStats(i18n.WithLanguage(context.Background(), "de"), "movie-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Sehr verlässliche Bewertung auf Basis von 10 Nutzerbewertungen.", result.Explanation)
+}
+
+func TestGetMovieStats_Withheld(t *testing.T) {
+	service, mockRepo, _, _ := setupTestService()
+	for i := 0; i < 3; i++ {
+		mockRepo.On("GetMovieStats", mock.Anything, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil).Once()
+	}
+	config := service.GetBayesianConfig()
+	config.DisplayMinRatings = 11
+	service.SetBayesianConfig(config)
+
+	stats, err := service.GetMovieStats(context.Background(), "movie-123")
+
+	require.NoError(t, err)
+	assert.True(t, stats.Withheld)
+	assert.Zero(t, stats.AverageScore)
+	assert.Equal(t, int64(10), stats.TotalRatings, "counts are still shown")
+
+	enhanced, err := service.GetEnhancedMovieStats(i18n.WithLanguage(context.Background(), "de"), "movie-123")
+
+	require.NoError(t, err)
+	assert.True(t, enhanced.Withheld)
+	assert.Zero(t, enhanced.BayesianAverage)
+	assert.Zero(t, enhanced.WilsonLowerBound)
+	assert.Zero(t, enhanced.Breakdown.MovieAverage)
+	assert.Equal(t, "Nicht genügend Bewertungen", enhanced.Explanation)
+
+	config.DisplayMinRatings = 10
+	service.SetBayesianConfig(config)
+	stats, err = service.GetMovieStats(context.Background(), "movie-123")
+
+	require.NoError(t, err)
+	assert.False(t, stats.Withheld, "a movie with exactly the minimum is shown")
+	assert.Equal(t, 4.2, stats.AverageScore)
+}
+
 func TestUpdateGlobalAverage(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -600,8 +1027,8 @@ func TestUpdateGlobalAverage(t *testing.T) {
 		{
 			name: "successful global average update",
 			setupMocks: func(mockRepo *mockRatingRepository) {
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.47, nil)
+				mockRepo.On("GetGlobalStats", mock.Anything).
+					Return(&rating.GlobalStats{ScoreSum: 347, TotalRatings: 100}, nil)
 			},
 			expectSuccess: true,
 			validateResult: func(t *testing.T, service Service) {
@@ -612,8 +1039,8 @@ func TestUpdateGlobalAverage(t *testing.T) {
 		{
 			name: "repository error",
 			setupMocks: func(mockRepo *mockRatingRepository) {
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(0.0, errors.New("database error"))
+				mockRepo.On("GetGlobalStats", mock.Anything).
+					Return(nil, errors.New("database error"))
 			},
 			expectedError: "failed to update global average",
 			expectSuccess: false,
@@ -642,6 +1069,52 @@ func TestUpdateGlobalAverage(t *testing.T) {
 	}
 }
 
+func TestGlobalAverageCaching(t *testing.T) {
+	mockRepo := new(mockRatingRepository)
+	clock := &mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewTestRatingService(mockRepo, &mockIDGenerator{id: "test-rating-123"}, clock, logger,
+		WithGlobalAverageTTL(time.Minute))
+	ctx := context.Background()
+
+	mockRepo.On("GetGlobalStats", mock.Anything).
+		Return(&rating.GlobalStats{ScoreSum: 40, TotalRatings: 10}, nil).Once()
+	mockRepo.On("GetMovieStats", mock.Anything, movies.MovieID("movie-123")).
+		Return(createTestMovieStats(), nil)
+
+	// The first use loads the totals, later uses within the TTL reuse them
+	_, err := service.GetEnhancedMovieStats(ctx, "movie-123")
+	require.NoError(t, err)
+	clock.now = clock.now.Add(30 * time.Second)
+	_, err = service.GetEnhancedMovieStats(ctx, "movie-123")
+	require.NoError(t, err)
+
+	global, err := service.GlobalAverage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, global.Average)
+	assert.Equal(t, int64(10), global.TotalRatings)
+	assert.Equal(t, clock.now.Add(-30*time.Second), global.LoadedAt)
+
+	// Once stale, a failed reload keeps the cached average
+	clock.now = clock.now.Add(time.Minute)
+	mockRepo.On("GetGlobalStats", mock.Anything).
+		Return(nil, errors.New("database error")).Once()
+	global, err = service.GlobalAverage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4.0, global.Average)
+
+	// A refresh recomputes the totals
+	mockRepo.On("RefreshGlobalStats", mock.Anything, clock.now).
+		Return(&rating.GlobalStats{ScoreSum: 35, TotalRatings: 10, RefreshedAt: clock.now}, nil).Once()
+	global, err = service.RefreshGlobalAverage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3.5, global.Average)
+	assert.Equal(t, clock.now, global.RefreshedAt)
+	assert.Equal(t, 3.5, service.GetBayesianConfig().GlobalAverage)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestBayesianConfiguration(t *testing.T) {
 	service, _, _, _ := setupTestService()
 
@@ -681,8 +1154,6 @@ func BenchmarkCreateRating(b *testing.B) {
 		Return(nil, errors.New("not found"))
 	mockRepo.On("Save", mock.Anything, mock.Anything).
 		Return(createTestRating(), nil)
-	mockRepo.On("GetGlobalAverageRating", mock.Anything).
-		Return(3.2, nil)
 
 	request := CreateRatingRequest{
 		UserID:  "user-123",
@@ -732,8 +1203,6 @@ func TestRatingWorkflow(t *testing.T) {
 						Return(nil, errors.New("not found")).Once()
 					mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 						Return(createTestRating(), nil).Once()
-					mockRepo.On("GetGlobalAverageRating", mock.Anything).
-						Return(3.2, nil).Once()
 
 					req := CreateRatingRequest{
 						UserID:  "user-123",
@@ -755,11 +1224,9 @@ func TestRatingWorkflow(t *testing.T) {
 					updatedRating.Score = 5
 					mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 						Return(&updatedRating, nil).Once()
-					mockRepo.On("GetGlobalAverageRating", mock.Anything).
-						Return(3.3, nil).Once()
 
 					updateReq := UpdateRatingRequest{Score: intPtr(5)}
-					result, err := service.UpdateRating(context.Background(), "test-rating-123", updateReq)
+					result, err := service.UpdateRating(context.Background(), "test-rating-123", rater, updateReq)
 					require.NoError(t, err)
 					assert.Equal(t, 5, result.Score)
 				},
@@ -777,7 +1244,7 @@ func TestRatingWorkflow(t *testing.T) {
 			finalAssertion: func(t *testing.T, service Service) {
 				// Verify final state
 				config := service.GetBayesianConfig()
-				assert.Equal(t, 3.3, config.GlobalAverage) // Should be updated from operations
+				assert.Equal(t, 3.0, config.GlobalAverage) // Writes leave the global average to the running totals
 			},
 		},
 	}
@@ -863,8 +1330,6 @@ func TestRatingServiceEdgeCases(t *testing.T) {
 				// First call succeeds
 				mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*rating.Rating")).
 					Return(createTestRating(), nil).Once()
-				mockRepo.On("GetGlobalAverageRating", mock.Anything).
-					Return(3.2, nil).Once()
 
 				// Second call fails due to race condition
 				mockRepo.On("Save", mock.Anything, mock.AnythingOfType("*rating.Rating")).
@@ -971,7 +1436,7 @@ func TestBayesianConfigurationScenarios(t *testing.T) {
 func TestServiceWithCustomConfig(t *testing.T) {
 	mockRepo := new(mockRatingRepository)
 	mockIDGen := &mockIDGenerator{id: "custom-test-123"}
-	mockTimeProvider := &mockTimeProvider{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	mockTimeProvider := &mockTimeProvider{now: testNow}
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
 
 	customConfig := BayesianConfig{
@@ -0,0 +1,180 @@
+package rating
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	// StatsSnapshotJobType is the job that snapshots the stats of every
+	// rated movie
+	StatsSnapshotJobType = "ratings.stats_snapshot"
+
+	// DefaultStatsDownsampleAfter is how old snapshots get before only one
+	// per week is kept
+	DefaultStatsDownsampleAfter = 365 * 24 * time.Hour
+)
+
+// StatsAverager supplies the global average and confidence the Bayesian
+// average of a snapshot is computed with
+type StatsAverager interface {
+	GlobalAverage(ctx context.Context) (*GlobalAverage, error)
+	GetBayesianConfig() BayesianConfig
+}
+
+// MovieChecker tells whether a movie exists
+type MovieChecker interface {
+	Exists(ctx context.Context, id movies.MovieID) (bool, error)
+}
+
+// StatsSnapshotResult is what a snapshot job reports
+type StatsSnapshotResult struct {
+	Date        string `json:"date"`
+	Movies      int64  `json:"movies"`      // Snapshots written
+	Downsampled int64  `json:"downsampled"` // Old snapshots removed
+}
+
+// StatsHistoryService keeps a daily record of every movie's rating numbers
+// for charting long-term reception
+type StatsHistoryService interface {
+	// GetStatsHistory returns the movie's snapshots between from and to,
+	// YYYY-MM-DD dates that default to the first snapshot and today
+	GetStatsHistory(ctx context.Context, movieID, from, to string) ([]*rating.StatsSnapshot, error)
+	// RunSnapshot is the jobs.Handler of StatsSnapshotJobType
+	RunSnapshot(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error
+}
+
+type statsHistoryService struct {
+	repo            rating.StatsHistoryRepository
+	movies          MovieChecker
+	averager        StatsAverager
+	timeProvider    shared.TimeProvider
+	logger          *slog.Logger
+	downsampleAfter time.Duration
+}
+
+// StatsHistoryOption configures optional settings of the stats history
+// service
+type StatsHistoryOption func(*statsHistoryService)
+
+// WithStatsDownsampleAfter sets how old snapshots get before only the last
+// one of each week is kept; 0 keeps them all
+func WithStatsDownsampleAfter(d time.Duration) StatsHistoryOption {
+	return func(s *statsHistoryService) {
+		s.downsampleAfter = d
+	}
+}
+
+func NewStatsHistoryService(
+	repo rating.StatsHistoryRepository,
+	movies MovieChecker,
+	averager StatsAverager,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...StatsHistoryOption,
+) StatsHistoryService {
+	s := &statsHistoryService{
+		repo:            repo,
+		movies:          movies,
+		averager:        averager,
+		timeProvider:    timeProvider,
+		logger:          logger,
+		downsampleAfter: DefaultStatsDownsampleAfter,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *statsHistoryService) GetStatsHistory(ctx context.Context, movieID, from, to string) ([]*rating.StatsSnapshot, error) {
+	fromDate, toDate, err := s.historyRange(from, to)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+
+	exists, err := s.movies.Exists(ctx, movies.MovieID(movieID))
+	if err != nil {
+		s.logger.Error("Failed to check movie existence", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get movie")
+	}
+	if !exists {
+		return nil, errors.NewNotFoundError("Movie not found")
+	}
+
+	snapshots, err := s.repo.GetStatsHistory(ctx, movies.MovieID(movieID), fromDate, toDate)
+	if err != nil {
+		s.logger.Error("Failed to get stats history", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get stats history")
+	}
+
+	minRatings := s.averager.GetBayesianConfig().DisplayMinRatings
+	for _, snapshot := range snapshots {
+		if snapshot.TotalRatings < minRatings {
+			snapshot.Withheld = true
+			snapshot.AverageScore = 0
+			snapshot.BayesianAverage = 0
+		}
+	}
+	return snapshots, nil
+}
+
+func (s *statsHistoryService) historyRange(from, to string) (time.Time, time.Time, error) {
+	var fromDate time.Time
+	if strings.TrimSpace(from) != "" {
+		parsed, err := time.Parse(rating.StatsHistoryDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, stdErrors.New("from must be a YYYY-MM-DD date")
+		}
+		fromDate = parsed
+	}
+	toDate := s.timeProvider.Now().UTC()
+	if strings.TrimSpace(to) != "" {
+		parsed, err := time.Parse(rating.StatsHistoryDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, stdErrors.New("to must be a YYYY-MM-DD date")
+		}
+		toDate = parsed
+	}
+	if toDate.Before(fromDate) {
+		return time.Time{}, time.Time{}, stdErrors.New("to must not be before from")
+	}
+	return fromDate, toDate, nil
+}
+
+// RunSnapshot writes today's snapshot, then downsamples the old ones. A
+// retried or repeated run on the same day replaces that day's snapshot.
+func (s *statsHistoryService) RunSnapshot(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	global, err := s.averager.GlobalAverage(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get global average: %w", err)
+	}
+
+	now := s.timeProvider.Now().UTC()
+	result := StatsSnapshotResult{Date: now.Format(rating.StatsHistoryDateLayout)}
+	result.Movies, err = s.repo.SnapshotStats(ctx, now, global.Average, s.averager.GetBayesianConfig().ConfidenceK)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot movie stats: %w", err)
+	}
+	report(50, result)
+
+	if s.downsampleAfter > 0 {
+		result.Downsampled, err = s.repo.DownsampleStatsHistory(ctx, now.Add(-s.downsampleAfter))
+		if err != nil {
+			return fmt.Errorf("failed to downsample stats history: %w", err)
+		}
+	}
+	report(100, result)
+
+	s.logger.Info("Snapshotted movie stats", "job_id", job.ID, "date", result.Date, "movies", result.Movies, "downsampled", result.Downsampled)
+	return nil
+}
@@ -0,0 +1,127 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStatsHistory records the arguments it is called with
+type fakeStatsHistory struct {
+	snapshotDate      time.Time
+	globalAverage     float64
+	confidenceK       float64
+	downsampledBefore time.Time
+	from, to          time.Time
+	err               error
+}
+
+func (f *fakeStatsHistory) SnapshotStats(ctx context.Context, date time.Time, globalAverage, confidenceK float64) (int64, error) {
+	f.snapshotDate, f.globalAverage, f.confidenceK = date, globalAverage, confidenceK
+	return 42, f.err
+}
+
+func (f *fakeStatsHistory) GetStatsHistory(ctx context.Context, movieID movies.MovieID, from, to time.Time) ([]*rating.StatsSnapshot, error) {
+	f.from, f.to = from, to
+	return []*rating.StatsSnapshot{{MovieID: movieID, Date: from, AverageScore: 4, BayesianAverage: 3.6, TotalRatings: 10}}, f.err
+}
+
+func (f *fakeStatsHistory) DownsampleStatsHistory(ctx context.Context, before time.Time) (int64, error) {
+	f.downsampledBefore = before
+	return 6, nil
+}
+
+type fakeAverager struct{}
+
+func (fakeAverager) GlobalAverage(ctx context.Context) (*GlobalAverage, error) {
+	return &GlobalAverage{Average: 3.4}, nil
+}
+
+func (fakeAverager) GetBayesianConfig() BayesianConfig {
+	return BayesianConfig{ConfidenceK: 25}
+}
+
+type fakeMovieChecker map[movies.MovieID]bool
+
+func (f fakeMovieChecker) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	return f[id], nil
+}
+
+func TestStatsHistory(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 5, 0, 0, time.UTC)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := func(repo *fakeStatsHistory, opts ...StatsHistoryOption) StatsHistoryService {
+		return NewStatsHistoryService(repo, fakeMovieChecker{"movie-1": true}, fakeAverager{}, &mockTimeProvider{now: now}, logger, opts...)
+	}
+
+	t.Run("snapshots today's stats and downsamples the old ones", func(t *testing.T) {
+		repo := &fakeStatsHistory{}
+		var reported []StatsSnapshotResult
+		err := setup(repo).RunSnapshot(context.Background(), &jobs.Job{ID: "job-1"}, func(progress float64, result any) {
+			reported = append(reported, result.(StatsSnapshotResult))
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, now, repo.snapshotDate)
+		assert.Equal(t, 3.4, repo.globalAverage)
+		assert.Equal(t, 25.0, repo.confidenceK)
+		assert.Equal(t, now.Add(-DefaultStatsDownsampleAfter), repo.downsampledBefore)
+		require.Len(t, reported, 2)
+		assert.Equal(t, StatsSnapshotResult{Date: "2024-06-01", Movies: 42, Downsampled: 6}, reported[1])
+	})
+
+	t.Run("keeps every snapshot when downsampling is disabled", func(t *testing.T) {
+		repo := &fakeStatsHistory{}
+		err := setup(repo, WithStatsDownsampleAfter(0)).RunSnapshot(context.Background(), &jobs.Job{ID: "job-1"}, func(float64, any) {})
+
+		require.NoError(t, err)
+		assert.True(t, repo.downsampledBefore.IsZero())
+	})
+
+	t.Run("fails the job when the snapshot fails", func(t *testing.T) {
+		repo := &fakeStatsHistory{err: errors.New("connection reset")}
+		err := setup(repo).RunSnapshot(context.Background(), &jobs.Job{ID: "job-1"}, func(float64, any) {})
+
+		assert.ErrorContains(t, err, "connection reset")
+	})
+
+	t.Run("lists the history up to today by default", func(t *testing.T) {
+		repo := &fakeStatsHistory{}
+		snapshots, err := setup(repo).GetStatsHistory(context.Background(), "movie-1", "2023-01-01", "")
+
+		require.NoError(t, err)
+		require.Len(t, snapshots, 1)
+		assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), repo.from)
+		assert.Equal(t, now, repo.to)
+	})
+
+	t.Run("rejects bad dates and unknown movies", func(t *testing.T) {
+		tests := []struct {
+			movieID, from, to string
+			status            int
+		}{
+			{"movie-1", "01/01/2023", "", http.StatusBadRequest},
+			{"movie-1", "2024-02-01", "2024-01-01", http.StatusBadRequest},
+			{"missing", "", "", http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			_, err := setup(&fakeStatsHistory{}).GetStatsHistory(context.Background(), tt.movieID, tt.from, tt.to)
+
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.status, appErr.StatusCode)
+		}
+	})
+}
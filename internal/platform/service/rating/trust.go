@@ -0,0 +1,31 @@
+package rating
+
+import (
+	"context"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+)
+
+// WithTrustWeighting weighs every rating by its rater's trust score in movie
+// stats and Bayesian averages, so brand-new accounts move scores less
+func WithTrustWeighting(config users.TrustConfig) Option {
+	return func(s *ratingService) {
+		s.trust = &config
+	}
+}
+
+// weighRatings sets stats.Trust. Like volatility detection it degrades to
+// plain stats when it fails.
+func (s *ratingService) weighRatings(ctx context.Context, stats *rating.MovieRatingStats) {
+	if s.trust == nil {
+		return
+	}
+
+	groups, err := s.ratingRepo.GetRaterGroups(ctx, stats.MovieID, s.timeProvider.Now(), *s.trust)
+	if err != nil {
+		s.logger.Warn("Failed to get rater trust factors, using unweighted stats", "error", err, "movie_id", stats.MovieID)
+		return
+	}
+
+	stats.Trust = rating.WeighRatings(groups, *s.trust)
+}
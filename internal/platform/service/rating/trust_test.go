@@ -0,0 +1,79 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+)
+
+func setupTrustService() (Service, *mockRatingRepository, time.Time) {
+	mockRepo := new(mockRatingRepository)
+	now := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewTestRatingService(mockRepo, &mockIDGenerator{id: "test-rating-123"}, &mockTimeProvider{now: now}, logger,
+		WithTrustWeighting(users.DefaultTrustConfig()),
+	)
+	return service, mockRepo, now
+}
+
+func TestTrustWeightedStats(t *testing.T) {
+	ctx := context.Background()
+	veteran := users.TrustFactors{AccountAge: 30 * 24 * time.Hour, EmailVerified: true, RatingCount: 20}
+	groups := []*rating.RaterGroup{
+		{Score: 5, Factors: veteran, Ratings: 10},
+		{Score: 1, Factors: users.TrustFactors{}, Ratings: 10},
+	}
+	stats := func() *rating.MovieRatingStats {
+		return &rating.MovieRatingStats{MovieID: "movie-123", AverageScore: 3.0, TotalRatings: 20, ScoreCount: map[int]int64{1: 10, 5: 10}}
+	}
+
+	t.Run("stats carry the weighted average", func(t *testing.T) {
+		service, mockRepo, now := setupTrustService()
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(stats(), nil)
+		mockRepo.On("GetRaterGroups", ctx, movies.MovieID("movie-123"), now, users.DefaultTrustConfig()).Return(groups, nil)
+
+		result, err := service.GetMovieStats(ctx, "movie-123")
+
+		require.NoError(t, err)
+		require.NotNil(t, result.Trust)
+		// 10 ratings worth 1 and 10 worth 0.1: (10*5 + 1*1) / 11
+		assert.InDelta(t, 11.0, result.Trust.EffectiveRatings, 0.0001)
+		assert.InDelta(t, 51.0/11.0, result.Trust.WeightedAverage, 0.0001)
+		assert.Equal(t, 3.0, result.AverageScore)
+	})
+
+	t.Run("Bayesian average uses the weighted votes", func(t *testing.T) {
+		service, mockRepo, now := setupTrustService()
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(stats(), nil)
+		mockRepo.On("GetRaterGroups", ctx, movies.MovieID("movie-123"), now, users.DefaultTrustConfig()).Return(groups, nil)
+
+		result, err := service.GetEnhancedMovieStats(ctx, "movie-123")
+
+		require.NoError(t, err)
+		// (25*3.0 + 51) / (25 + 11)
+		assert.InDelta(t, 126.0/36.0, result.BayesianAverage, 0.0001)
+	})
+
+	t.Run("falls back to plain stats when trust factors fail", func(t *testing.T) {
+		service, mockRepo, now := setupTrustService()
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(stats(), nil)
+		mockRepo.On("GetRaterGroups", ctx, movies.MovieID("movie-123"), now, users.DefaultTrustConfig()).Return(nil, errors.New("db down"))
+
+		result, err := service.GetEnhancedMovieStats(ctx, "movie-123")
+
+		require.NoError(t, err)
+		assert.Nil(t, result.Trust)
+		// (25*3.0 + 20*3.0) / (25 + 20)
+		assert.InDelta(t, 3.0, result.BayesianAverage, 0.0001)
+	})
+}
@@ -1,13 +1,39 @@
 package rating
 
+import "thermondo/internal/domain/rating"
+
 type CreateRatingRequest struct {
-	UserID  string `json:"user_id"`
-	MovieID string `json:"movie_id"`
-	Score   int    `json:"score"`
-	Review  string `json:"review,omitempty"`
+	UserID           string `json:"user_id"`
+	MovieID          string `json:"movie_id"`
+	Score            int    `json:"score"`
+	Review           string `json:"review,omitempty"`            // Markdown
+	ContainsSpoilers bool   `json:"contains_spoilers,omitempty"` // Implied by ||spoiler|| tags in the review
+	// ContainsAdultLanguage keeps the review out of listings in kids mode
+	ContainsAdultLanguage bool `json:"contains_adult_language,omitempty"`
+}
+
+// Requester is the caller a rating is changed for, as the HTTP layer's
+// authenticated principal
+type Requester interface {
+	// Is reports whether the requester is user id
+	Is(id string) bool
+	// IsAdmin reports whether the requester may act for other users
+	IsAdmin() bool
 }
 
 type UpdateRatingRequest struct {
-	Score  *int    `json:"score,omitempty"`
-	Review *string `json:"review,omitempty"`
+	Score            *int    `json:"score,omitempty"`
+	Review           *string `json:"review,omitempty"`
+	ContainsSpoilers *bool   `json:"contains_spoilers,omitempty"`
+	// ContainsAdultLanguage keeps the review out of listings in kids mode
+	ContainsAdultLanguage *bool `json:"contains_adult_language,omitempty"`
+	// ExpectedVersion carries the client's If-Match precondition. When set,
+	// the update is rejected unless the rating is still at this version.
+	ExpectedVersion *int `json:"-"`
+}
+
+// MovieRatingsFilter narrows a movie's ratings; zero values keep them all
+type MovieRatingsFilter struct {
+	Reviewer rating.ReviewerType
+	Spoilers rating.SpoilerFilter
 }
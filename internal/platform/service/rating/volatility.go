@@ -0,0 +1,96 @@
+package rating
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"time"
+)
+
+// AnomalyNotifier tells admins about movies flagged for review bombing
+type AnomalyNotifier interface {
+	NotifyVolatility(ctx context.Context, movieID string, volatility *rating.Volatility) error
+}
+
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogNotifier reports flagged movies as warnings in the service log
+func NewLogNotifier(logger *slog.Logger) AnomalyNotifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) NotifyVolatility(_ context.Context, movieID string, volatility *rating.Volatility) error {
+	n.logger.Warn("Possible review bombing detected",
+		"movie_id", movieID,
+		"direction", volatility.Direction,
+		"score", volatility.Score,
+		"spike_ratings", volatility.SpikeRatings,
+		"expected_ratings", volatility.ExpectedRatings,
+		"window_start", volatility.WindowStart)
+	return nil
+}
+
+// volatilityDetector holds the review bombing settings and remembers which
+// movies admins were already told about
+type volatilityDetector struct {
+	config   rating.VolatilityConfig
+	notifier AnomalyNotifier
+
+	mu       sync.Mutex
+	notified map[string]time.Time
+}
+
+// WithVolatilityDetection enables review bombing detection on movie stats.
+// Admins are notified through notifier at most once per movie per window.
+func WithVolatilityDetection(config rating.VolatilityConfig, notifier AnomalyNotifier) Option {
+	return func(s *ratingService) {
+		s.volatility = &volatilityDetector{
+			config:   config,
+			notifier: notifier,
+			notified: make(map[string]time.Time),
+		}
+	}
+}
+
+// detectVolatility returns nil when detection is disabled or fails; a broken
+// detector must never break the stats it decorates
+func (s *ratingService) detectVolatility(ctx context.Context, movieID string) *rating.Volatility {
+	if s.volatility == nil {
+		return nil
+	}
+
+	config := s.volatility.config
+	now := s.timeProvider.Now()
+	windowStart := now.Add(-config.Window)
+	baselineStart := windowStart.Add(-config.Baseline)
+
+	activity, err := s.ratingRepo.GetRatingActivity(ctx, movies.MovieID(movieID), baselineStart, windowStart)
+	if err != nil {
+		s.logger.Warn("Failed to get rating activity for volatility detection", "error", err, "movie_id", movieID)
+		return nil
+	}
+
+	volatility := rating.DetectVolatility(*activity, windowStart, config)
+	if volatility.Flagged && s.volatility.shouldNotify(movieID, now) {
+		if err := s.volatility.notifier.NotifyVolatility(ctx, movieID, volatility); err != nil {
+			s.logger.Error("Failed to notify admins about rating volatility", "error", err, "movie_id", movieID)
+		}
+	}
+
+	return volatility
+}
+
+func (d *volatilityDetector) shouldNotify(movieID string, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.notified[movieID]; ok && now.Sub(last) < d.config.Window {
+		return false
+	}
+	d.notified[movieID] = now
+	return true
+}
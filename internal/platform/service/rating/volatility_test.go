@@ -0,0 +1,109 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+)
+
+func setupVolatilityService(config rating.VolatilityConfig) (Service, *mockRatingRepository, *mockAnomalyNotifier) {
+	mockRepo := new(mockRatingRepository)
+	notifier := new(mockAnomalyNotifier)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewTestRatingService(mockRepo, &mockIDGenerator{id: "test-rating-123"},
+		&mockTimeProvider{now: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)}, logger,
+		WithVolatilityDetection(config, notifier),
+	)
+	return service, mockRepo, notifier
+}
+
+func bombedActivity() *rating.RatingActivity {
+	return &rating.RatingActivity{
+		Window:   map[int]int64{1: 20},
+		Baseline: map[int]int64{1: 3, 4: 30, 5: 7},
+	}
+}
+
+func TestGetMovieStatsVolatility(t *testing.T) {
+	ctx := context.Background()
+	windowStart := time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC)
+	baselineStart := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+
+	t.Run("flags a spike and notifies admins once", func(t *testing.T) {
+		service, mockRepo, notifier := setupVolatilityService(rating.DefaultVolatilityConfig())
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil)
+		mockRepo.On("GetRatingActivity", ctx, movies.MovieID("movie-123"), baselineStart, windowStart).Return(bombedActivity(), nil)
+		notifier.On("NotifyVolatility", ctx, "movie-123", mock.MatchedBy(func(v *rating.Volatility) bool {
+			return v.Flagged && v.Direction == rating.VolatilityNegative && v.SpikeRatings == 20
+		})).Return(nil).Once()
+
+		for i := 0; i < 2; i++ {
+			stats, err := service.GetMovieStats(ctx, "movie-123")
+
+			require.NoError(t, err)
+			require.NotNil(t, stats.Volatility)
+			assert.True(t, stats.Volatility.Flagged)
+			assert.False(t, stats.Volatility.Excluded)
+		}
+		notifier.AssertExpectations(t)
+	})
+
+	t.Run("stats survive a failing detector", func(t *testing.T) {
+		service, mockRepo, notifier := setupVolatilityService(rating.DefaultVolatilityConfig())
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil)
+		mockRepo.On("GetRatingActivity", ctx, movies.MovieID("movie-123"), baselineStart, windowStart).Return(nil, errors.New("db down"))
+
+		stats, err := service.GetMovieStats(ctx, "movie-123")
+
+		require.NoError(t, err)
+		assert.Nil(t, stats.Volatility)
+		notifier.AssertNotCalled(t, "NotifyVolatility", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("detection is off by default", func(t *testing.T) {
+		service, mockRepo, _, _ := setupTestService()
+		mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(createTestMovieStats(), nil)
+
+		stats, err := service.GetMovieStats(ctx, "movie-123")
+
+		require.NoError(t, err)
+		assert.Nil(t, stats.Volatility)
+		mockRepo.AssertNotCalled(t, "GetRatingActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetEnhancedMovieStatsExcludesSpike(t *testing.T) {
+	ctx := context.Background()
+	config := rating.DefaultVolatilityConfig()
+	config.ExcludeFlagged = true
+
+	bombed := &rating.MovieRatingStats{MovieID: "movie-123", AverageScore: 2.5, TotalRatings: 40}
+
+	service, mockRepo, notifier := setupVolatilityService(config)
+	mockRepo.On("GetMovieStats", ctx, movies.MovieID("movie-123")).Return(bombed, nil)
+	mockRepo.On("GetRatingActivity", ctx, movies.MovieID("movie-123"), mock.Anything, mock.Anything).Return(bombedActivity(), nil)
+	notifier.On("NotifyVolatility", ctx, "movie-123", mock.Anything).Return(nil)
+
+	stats, err := service.GetEnhancedMovieStats(ctx, "movie-123")
+	require.NoError(t, err)
+
+	// The 20 one-star ratings are left out, leaving 20 ratings averaging 4.0:
+	// (25*3.0 + 20*4.0) / (25 + 20)
+	assert.True(t, stats.Volatility.Excluded)
+	assert.InDelta(t, 155.0/45.0, stats.BayesianAverage, 0.0001)
+	assert.Equal(t, 2.5, stats.AverageScore)
+	assert.Equal(t, 20.0, stats.Breakdown.ExcludedVotes)
+	assert.Equal(t, 20.0, stats.Breakdown.Votes)
+	assert.InDelta(t, 4.0, stats.Breakdown.MovieAverage, 0.0001)
+	assert.Equal(t, 2.5, stats.Breakdown.RawAverage)
+}
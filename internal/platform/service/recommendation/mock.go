@@ -0,0 +1,132 @@
+package recommendation
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockRecommendationRepository struct {
+	mock.Mock
+}
+
+func (m *mockRecommendationRepository) GetWatchlist(ctx context.Context, userID users.UserID, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetFavoriteGenre(ctx context.Context, userID users.UserID, minRatings int, asOf time.Time, halfLife time.Duration) (string, error) {
+	args := m.Called(ctx, userID, minRatings, asOf, halfLife)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetTopInGenre(ctx context.Context, userID users.UserID, genre string, confidenceK float64, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, userID, genre, confidenceK, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetRecentFavorite(ctx context.Context, userID users.UserID, since time.Time, minScore int) (*movies.Movie, error) {
+	args := m.Called(ctx, userID, since, minScore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetSimilar(ctx context.Context, userID users.UserID, movie *movies.Movie, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, userID, movie, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetTrendingAmongSimilarUsers(ctx context.Context, userID users.UserID, since time.Time, neighbors, minScore, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, userID, since, neighbors, minScore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) GetPreferred(ctx context.Context, userID users.UserID, preferences *recommendations.Preferences, confidenceK float64, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, userID, preferences, confidenceK, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) CountRatings(ctx context.Context, userID users.UserID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockRecommendationRepository) SavePreferences(ctx context.Context, preferences *recommendations.Preferences) error {
+	args := m.Called(ctx, preferences)
+	return args.Error(0)
+}
+
+func (m *mockRecommendationRepository) GetPreferences(ctx context.Context, userID users.UserID) (*recommendations.Preferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*recommendations.Preferences), args.Error(1)
+}
+
+type mockUserFinder struct {
+	mock.Mock
+}
+
+func (m *mockUserFinder) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+type mockContentFilter struct {
+	mock.Mock
+}
+
+func (m *mockContentFilter) FilterAllowed(ctx context.Context, ids []movies.MovieID, policy movies.ContentPolicy) ([]movies.MovieID, error) {
+	args := m.Called(ctx, ids, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]movies.MovieID), args.Error(1)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
+
+type mockHeadToHeadFinder struct {
+	mock.Mock
+}
+
+func (m *mockHeadToHeadFinder) GetHeadToHead(ctx context.Context, firstID, secondID string) (*rating.HeadToHead, error) {
+	args := m.Called(ctx, firstID, secondID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.HeadToHead), args.Error(1)
+}
@@ -0,0 +1,103 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+	"time"
+)
+
+// PreferencesRequest is what a user picks during onboarding
+type PreferencesRequest struct {
+	Genres  []string `json:"genres"`
+	Decades []int    `json:"decades"`
+}
+
+func (s *recommendationService) SavePreferences(ctx context.Context, userID string, req PreferencesRequest) (*recommendations.Preferences, error) {
+	preferences, err := recommendations.NewPreferences(users.UserID(userID), req.Genres, req.Decades, s.timeProvider.Now())
+	if err != nil {
+		return nil, appErrors.NewBadRequestError(err.Error())
+	}
+
+	if err := s.repo.SavePreferences(ctx, preferences); err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			return nil, appErrors.NewNotFoundError("User not found")
+		}
+		s.logger.Error("Failed to save preferences", "error", err, "user_id", userID)
+		return nil, appErrors.NewInternalError("Failed to save preferences")
+	}
+
+	// The picks are seeded from the preferences; show the new ones right away
+	if s.cache != nil {
+		if err := s.cache.Delete(ctx, cache.HomeShelfKeyFunc(userID, string(ShelfTopPicks))); err != nil {
+			s.logger.Warn("Failed to invalidate home shelf", "error", err, "user_id", userID, "shelf", ShelfTopPicks)
+		}
+	}
+	return preferences, nil
+}
+
+// coldStartPicks blends the movies of the user's onboarding preferences
+// with those liked by users who rate like them. The fewer ratings the user
+// has, the more of the shelf the preferences fill. It returns nil when the
+// user has rated enough or never picked any preferences.
+func (s *recommendationService) coldStartPicks(ctx context.Context, userID users.UserID) ([]*movies.Movie, error) {
+	if s.coldStartRatings == 0 {
+		return nil, nil
+	}
+	rated, err := s.repo.CountRatings(ctx, userID)
+	if err != nil || rated >= s.coldStartRatings {
+		return nil, err
+	}
+	preferences, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil || preferences == nil {
+		return nil, err
+	}
+
+	preferred, err := s.repo.GetPreferred(ctx, userID, preferences, s.confidenceK(), s.shelfSize)
+	if err != nil {
+		return nil, err
+	}
+	var collaborative []*movies.Movie
+	if rated > 0 {
+		// Neighbors are few this early, so everything they liked counts
+		collaborative, err = s.repo.GetTrendingAmongSimilarUsers(ctx, userID, time.Time{}, trendingNeighbors, trendingMinScore, s.shelfSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	share := float64(s.coldStartRatings-rated) / float64(s.coldStartRatings)
+	return blend(preferred, collaborative, share, s.shelfSize), nil
+}
+
+// blend picks up to size movies, taking from preferred while it makes up
+// less than share of the picks and from collaborative otherwise, so both
+// show up early. Once a list runs out the other fills the remaining places.
+func blend(preferred, collaborative []*movies.Movie, share float64, size int) []*movies.Movie {
+	seen := make(map[movies.MovieID]bool)
+	blended := make([]*movies.Movie, 0, size)
+	fromPreferred := 0
+	for len(blended) < size && (len(preferred) > 0 || len(collaborative) > 0) {
+		var next *movies.Movie
+		takePreferred := len(collaborative) == 0 ||
+			(len(preferred) > 0 && float64(fromPreferred) < share*float64(len(blended)+1))
+		if takePreferred {
+			next, preferred = preferred[0], preferred[1:]
+		} else {
+			next, collaborative = collaborative[0], collaborative[1:]
+		}
+		if seen[next.ID] {
+			continue
+		}
+		seen[next.ID] = true
+		if takePreferred {
+			fromPreferred++
+		}
+		blended = append(blended, next)
+	}
+	return blended
+}
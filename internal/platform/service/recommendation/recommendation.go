@@ -0,0 +1,449 @@
+package recommendation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	DefaultShelfSize        = 12
+	DefaultColdStartRatings = 10
+
+	// favoriteGenreMinRatings is how many ratings a genre needs before it
+	// can be the user's favorite
+	favoriteGenreMinRatings = 3
+	// recentFavoriteWindow and recentFavoriteMinScore pick the movie the
+	// "because you rated" shelf is about
+	recentFavoriteWindow   = 90 * 24 * time.Hour
+	recentFavoriteMinScore = 4
+	// trendingWindow, trendingNeighbors and trendingMinScore shape the
+	// shelf of what users who rate like the user liked lately
+	trendingWindow    = 30 * 24 * time.Hour
+	trendingNeighbors = 50
+	trendingMinScore  = 4
+)
+
+// ShelfID names a kind of home shelf
+type ShelfID string
+
+const (
+	ShelfWatchlist       ShelfID = "watchlist"
+	ShelfTopPicks        ShelfID = "top_picks"
+	ShelfBecauseYouRated ShelfID = "because_you_rated"
+	ShelfTrending        ShelfID = "trending_among_similar_users"
+)
+
+// ShelfMovie is a movie on a shelf
+type ShelfMovie struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseYear int     `json:"release_year"`
+	Genre       string  `json:"genre"`
+	Director    string  `json:"director"`
+	PosterURL   *string `json:"poster_url,omitempty"`
+	// Preference is set on the cards of the because-you-rated shelf when
+	// users rated both the card's movie and the one the shelf is about
+	Preference *Preference `json:"preference,omitempty"`
+}
+
+// Preference is how the users who rated both a card's movie and the movie
+// its shelf is about scored them
+type Preference struct {
+	SharedRaters int64 `json:"shared_raters"`
+	// PreferPercent is the share of them who scored the card's movie higher
+	PreferPercent int `json:"prefer_percent"`
+}
+
+// Shelf is a row of movies picked for one reason
+type Shelf struct {
+	ID    ShelfID `json:"id"`
+	Title string  `json:"title"`
+	// Genre is the favorite genre the top picks come from
+	Genre string `json:"genre,omitempty"`
+	// BecauseOf is the movie the user rated that similar movies are picked by
+	BecauseOf *ShelfMovie   `json:"because_of,omitempty"`
+	Movies    []*ShelfMovie `json:"movies"`
+}
+
+// Home is a user's personalized homepage
+type Home struct {
+	UserID  string   `json:"user_id"`
+	Shelves []*Shelf `json:"shelves"`
+}
+
+// UserFinder looks users up by ID
+type UserFinder interface {
+	FindByID(ctx context.Context, id users.UserID) (*users.User, error)
+}
+
+// HeadToHeadFinder tells how the users who rated both movies of a pair
+// scored them
+type HeadToHeadFinder interface {
+	GetHeadToHead(ctx context.Context, firstID, secondID string) (*rating.HeadToHead, error)
+}
+
+// ContentFilter tells which movies a content policy lets through
+type ContentFilter interface {
+	FilterAllowed(ctx context.Context, ids []movies.MovieID, policy movies.ContentPolicy) ([]movies.MovieID, error)
+}
+
+// Service assembles personalized recommendations
+type Service interface {
+	// GetHome returns the user's home shelves in a fixed order, leaving out
+	// those with no movies. A shelf that fails to load is left out as well
+	// rather than failing the whole page.
+	GetHome(ctx context.Context, userID string) (*Home, error)
+	// SavePreferences replaces the genres and decades the user picked during
+	// onboarding, which seed the top picks until they have rated enough
+	SavePreferences(ctx context.Context, userID string, req PreferencesRequest) (*recommendations.Preferences, error)
+}
+
+type recommendationService struct {
+	repo         recommendations.Repository
+	users        UserFinder
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	cache        cache.Cache
+	shelfSize    int
+	confidenceK  func() float64
+	// coldStartRatings is how many ratings a user needs before the top
+	// picks ignore their onboarding preferences
+	coldStartRatings int
+	// genreHalfLife is how long it takes for a rating to count half as
+	// much towards the favorite genre of the top picks
+	genreHalfLife time.Duration
+	// kidsPolicy limits the shelves in kids mode, checked through
+	// contentFilter
+	kidsPolicy    *movies.ContentPolicy
+	contentFilter ContentFilter
+	headToHead    HeadToHeadFinder
+}
+
+// Option configures optional settings of the recommendation service
+type Option func(*recommendationService)
+
+// WithCache caches every shelf of every user for its own TTL
+func WithCache(c cache.Cache) Option {
+	return func(s *recommendationService) {
+		s.cache = c
+	}
+}
+
+// WithShelfSize sets how many movies a shelf holds at most
+func WithShelfSize(size int) Option {
+	return func(s *recommendationService) {
+		if size > 0 {
+			s.shelfSize = size
+		}
+	}
+}
+
+// WithBayesianConfidenceK reads the prior weight the top picks are ranked
+// with from k, so it follows the rating service's configuration as that is
+// reloaded
+func WithBayesianConfidenceK(k func() float64) Option {
+	return func(s *recommendationService) {
+		if k != nil {
+			s.confidenceK = k
+		}
+	}
+}
+
+// WithColdStartRatings sets how many ratings a user needs before the top
+// picks rely on ratings alone; 0 ignores onboarding preferences
+func WithColdStartRatings(n int) Option {
+	return func(s *recommendationService) {
+		if n >= 0 {
+			s.coldStartRatings = n
+		}
+	}
+}
+
+// WithGenreHalfLife sets how long it takes for a rating to count half as
+// much towards the genre of the top picks; 0 counts every rating alike
+func WithGenreHalfLife(d time.Duration) Option {
+	return func(s *recommendationService) {
+		if d >= 0 {
+			s.genreHalfLife = d
+		}
+	}
+}
+
+// WithKidsPolicy limits the shelves to the movies policy lets through in
+// kids mode. Shelves are cached unfiltered and filtered on every read.
+func WithKidsPolicy(policy *movies.ContentPolicy, filter ContentFilter) Option {
+	return func(s *recommendationService) {
+		s.kidsPolicy = policy
+		s.contentFilter = filter
+	}
+}
+
+// WithHeadToHead adds to the cards of the because-you-rated shelf how many
+// of the users who rated both movies prefer the card's
+func WithHeadToHead(finder HeadToHeadFinder) Option {
+	return func(s *recommendationService) {
+		s.headToHead = finder
+	}
+}
+
+func NewRecommendationService(
+	repo recommendations.Repository,
+	userFinder UserFinder,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &recommendationService{
+		repo:         repo,
+		users:        userFinder,
+		timeProvider: timeProvider,
+		logger:       logger,
+		shelfSize:    DefaultShelfSize,
+		confidenceK:  func() float64 { return rating.DefaultBayesianConfidenceK },
+
+		coldStartRatings: DefaultColdStartRatings,
+		genreHalfLife:    rating.DefaultGenreHalfLife,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// shelfBuilder builds one shelf of a user's home
+type shelfBuilder struct {
+	id    ShelfID
+	ttl   time.Duration
+	build func(ctx context.Context, userID users.UserID) (*Shelf, error)
+}
+
+func (s *recommendationService) GetHome(ctx context.Context, userID string) (*Home, error) {
+	user, err := s.users.FindByID(ctx, users.UserID(userID))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return nil, appErrors.NewNotFoundError("User not found")
+	}
+	if err != nil {
+		s.logger.Error("Failed to find user", "error", err, "user_id", userID)
+		return nil, appErrors.NewInternalError("Failed to load home")
+	}
+
+	builders := []shelfBuilder{
+		{ShelfWatchlist, cache.HomeWatchlistTTL, s.watchlistShelf},
+		{ShelfTopPicks, cache.HomeTopPicksTTL, s.topPicksShelf},
+		{ShelfBecauseYouRated, cache.HomeBecauseYouRatedTTL, s.becauseYouRatedShelf},
+		{ShelfTrending, cache.HomeTrendingTTL, s.trendingShelf},
+	}
+
+	// The shelves don't depend on each other, so they load side by side
+	shelves := make([]*Shelf, len(builders))
+	var wg sync.WaitGroup
+	for i, builder := range builders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shelves[i] = s.shelf(ctx, user.ID, builder)
+		}()
+	}
+	wg.Wait()
+
+	if users.IsKidsMode(ctx) && s.kidsPolicy != nil {
+		if err := s.filterShelves(ctx, shelves); err != nil {
+			s.logger.Error("Failed to filter home shelves for kids mode", "error", err, "user_id", userID)
+			return nil, appErrors.NewInternalError("Failed to load home")
+		}
+	}
+
+	home := &Home{UserID: userID, Shelves: []*Shelf{}}
+	for _, shelf := range shelves {
+		if shelf != nil && len(shelf.Movies) > 0 {
+			home.Shelves = append(home.Shelves, shelf)
+		}
+	}
+	return home, nil
+}
+
+// shelf returns the cached shelf or builds and caches it. Empty shelves
+// are cached too, so users with little history don't rebuild them on every
+// visit. It returns nil when the shelf failed to build.
+func (s *recommendationService) shelf(ctx context.Context, userID users.UserID, builder shelfBuilder) *Shelf {
+	cacheKey := cache.HomeShelfKeyFunc(string(userID), string(builder.id))
+	if s.cache != nil {
+		var cached Shelf
+		if err := s.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached
+		}
+	}
+
+	shelf, err := builder.build(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to build home shelf", "error", err, "user_id", userID, "shelf", builder.id)
+		return nil
+	}
+	if shelf.Movies == nil {
+		shelf.Movies = []*ShelfMovie{}
+	}
+
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, cacheKey, shelf, builder.ttl); err != nil {
+			s.logger.Warn("Failed to cache home shelf", "error", err, "user_id", userID, "shelf", builder.id)
+		}
+	}
+	return shelf
+}
+
+// filterShelves drops the movies the kids policy doesn't let through from
+// every shelf, checking them all in one go. A shelf picked because of a
+// movie that isn't let through is emptied.
+func (s *recommendationService) filterShelves(ctx context.Context, shelves []*Shelf) error {
+	var ids []movies.MovieID
+	for _, shelf := range shelves {
+		if shelf == nil {
+			continue
+		}
+		for _, movie := range shelf.Movies {
+			ids = append(ids, movies.MovieID(movie.ID))
+		}
+		if shelf.BecauseOf != nil {
+			ids = append(ids, movies.MovieID(shelf.BecauseOf.ID))
+		}
+	}
+
+	allowedIDs, err := s.contentFilter.FilterAllowed(ctx, ids, *s.kidsPolicy)
+	if err != nil {
+		return err
+	}
+	allowed := make(map[string]bool, len(allowedIDs))
+	for _, id := range allowedIDs {
+		allowed[string(id)] = true
+	}
+
+	for _, shelf := range shelves {
+		if shelf == nil {
+			continue
+		}
+		kept := make([]*ShelfMovie, 0, len(shelf.Movies))
+		for _, movie := range shelf.Movies {
+			if allowed[movie.ID] && (shelf.BecauseOf == nil || allowed[shelf.BecauseOf.ID]) {
+				kept = append(kept, movie)
+			}
+		}
+		shelf.Movies = kept
+	}
+	return nil
+}
+
+func (s *recommendationService) watchlistShelf(ctx context.Context, userID users.UserID) (*Shelf, error) {
+	found, err := s.repo.GetWatchlist(ctx, userID, s.shelfSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Shelf{ID: ShelfWatchlist, Title: "Continue from your watchlist", Movies: toShelfMovies(found)}, nil
+}
+
+func (s *recommendationService) topPicksShelf(ctx context.Context, userID users.UserID) (*Shelf, error) {
+	picks, err := s.coldStartPicks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if picks != nil {
+		return &Shelf{ID: ShelfTopPicks, Title: "Picked for you", Movies: toShelfMovies(picks)}, nil
+	}
+
+	genre, err := s.repo.GetFavoriteGenre(ctx, userID, favoriteGenreMinRatings, s.timeProvider.Now(), s.genreHalfLife)
+	if err != nil {
+		return nil, err
+	}
+	shelf := &Shelf{ID: ShelfTopPicks, Genre: genre}
+	if genre == "" {
+		return shelf, nil
+	}
+
+	found, err := s.repo.GetTopInGenre(ctx, userID, genre, s.confidenceK(), s.shelfSize)
+	if err != nil {
+		return nil, err
+	}
+	shelf.Title = fmt.Sprintf("Top picks in %s", genre)
+	shelf.Movies = toShelfMovies(found)
+	return shelf, nil
+}
+
+func (s *recommendationService) becauseYouRatedShelf(ctx context.Context, userID users.UserID) (*Shelf, error) {
+	since := s.timeProvider.Now().Add(-recentFavoriteWindow)
+	favorite, err := s.repo.GetRecentFavorite(ctx, userID, since, recentFavoriteMinScore)
+	if err != nil {
+		return nil, err
+	}
+	shelf := &Shelf{ID: ShelfBecauseYouRated}
+	if favorite == nil {
+		return shelf, nil
+	}
+
+	found, err := s.repo.GetSimilar(ctx, userID, favorite, s.shelfSize)
+	if err != nil {
+		return nil, err
+	}
+	shelf.Title = fmt.Sprintf("Because you rated %s", favorite.Title)
+	shelf.BecauseOf = toShelfMovie(favorite)
+	shelf.Movies = toShelfMovies(found)
+	s.addPreferences(ctx, shelf)
+	return shelf, nil
+}
+
+// addPreferences sets the preference of every card of the shelf against
+// the movie the shelf is about. A card whose preference fails to load goes
+// without rather than failing the shelf.
+func (s *recommendationService) addPreferences(ctx context.Context, shelf *Shelf) {
+	if s.headToHead == nil {
+		return
+	}
+	for _, movie := range shelf.Movies {
+		headToHead, err := s.headToHead.GetHeadToHead(ctx, movie.ID, shelf.BecauseOf.ID)
+		if err != nil {
+			s.logger.Warn("Failed to get head-to-head", "error", err, "movie_id", movie.ID, "because_of", shelf.BecauseOf.ID)
+			continue
+		}
+		if headToHead.SharedRaters > 0 {
+			movie.Preference = &Preference{SharedRaters: headToHead.SharedRaters, PreferPercent: headToHead.PreferFirstPercent()}
+		}
+	}
+}
+
+func (s *recommendationService) trendingShelf(ctx context.Context, userID users.UserID) (*Shelf, error) {
+	since := s.timeProvider.Now().Add(-trendingWindow)
+	found, err := s.repo.GetTrendingAmongSimilarUsers(ctx, userID, since, trendingNeighbors, trendingMinScore, s.shelfSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Shelf{ID: ShelfTrending, Title: "Trending among people who rate like you", Movies: toShelfMovies(found)}, nil
+}
+
+func toShelfMovie(movie *movies.Movie) *ShelfMovie {
+	return &ShelfMovie{
+		ID:          string(movie.ID),
+		Title:       movie.Title,
+		ReleaseYear: movie.ReleaseYear,
+		Genre:       movie.Genre,
+		Director:    movie.Director,
+		PosterURL:   movie.PosterURL,
+	}
+}
+
+func toShelfMovies(found []*movies.Movie) []*ShelfMovie {
+	shelfMovies := make([]*ShelfMovie, len(found))
+	for i, movie := range found {
+		shelfMovies[i] = toShelfMovie(movie)
+	}
+	return shelfMovies
+}
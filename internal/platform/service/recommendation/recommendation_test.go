@@ -0,0 +1,308 @@
+package recommendation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/recommendations"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+// mapCache keeps values in memory and records the TTL of every key
+type mapCache struct {
+	cache.NoOpCache
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *mapCache) Get(ctx context.Context, key string, dest interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.values[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *mapCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] = data
+	c.ttls[key] = ttl
+	return nil
+}
+
+var now = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func setupRecommendationService(repo *mockRecommendationRepository, c cache.Cache) Service {
+	finder := new(mockUserFinder)
+	finder.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	finder.On("FindByID", mock.Anything, users.UserID("user-missing")).Return(nil, sql.ErrNoRows)
+
+	return NewRecommendationService(repo, finder, &mockTimeProvider{now: now},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), WithCache(c), WithShelfSize(5))
+}
+
+func TestGetHome(t *testing.T) {
+	heat := &movies.Movie{ID: "movie-heat", Title: "Heat", Genre: "Crime", Director: "Michael Mann"}
+	thief := &movies.Movie{ID: "movie-thief", Title: "Thief", Genre: "Crime", Director: "Michael Mann"}
+	ronin := &movies.Movie{ID: "movie-ronin", Title: "Ronin", Genre: "Crime"}
+
+	repo := new(mockRecommendationRepository)
+	repo.On("GetWatchlist", mock.Anything, users.UserID("user-1"), 5).Return([]*movies.Movie{}, nil).Once()
+	repo.On("CountRatings", mock.Anything, users.UserID("user-1")).Return(40, nil).Once()
+	repo.On("GetFavoriteGenre", mock.Anything, users.UserID("user-1"), favoriteGenreMinRatings, now, rating.DefaultGenreHalfLife).Return("Crime", nil).Once()
+	repo.On("GetTopInGenre", mock.Anything, users.UserID("user-1"), "Crime", rating.DefaultBayesianConfidenceK, 5).Return([]*movies.Movie{ronin}, nil).Once()
+	repo.On("GetRecentFavorite", mock.Anything, users.UserID("user-1"), now.Add(-recentFavoriteWindow), recentFavoriteMinScore).Return(heat, nil).Once()
+	repo.On("GetSimilar", mock.Anything, users.UserID("user-1"), heat, 5).Return([]*movies.Movie{thief, ronin}, nil).Once()
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), now.Add(-trendingWindow), trendingNeighbors, trendingMinScore, 5).
+		Return(nil, errors.New("connection reset")).Once()
+
+	c := newMapCache()
+	service := setupRecommendationService(repo, c)
+
+	home, err := service.GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, home.Shelves, 2, "the empty watchlist and the failed trending shelf are left out")
+
+	assert.Equal(t, ShelfTopPicks, home.Shelves[0].ID)
+	assert.Equal(t, "Top picks in Crime", home.Shelves[0].Title)
+	assert.Equal(t, "movie-ronin", home.Shelves[0].Movies[0].ID)
+
+	assert.Equal(t, ShelfBecauseYouRated, home.Shelves[1].ID)
+	assert.Equal(t, "Because you rated Heat", home.Shelves[1].Title)
+	assert.Equal(t, "movie-heat", home.Shelves[1].BecauseOf.ID)
+	assert.Len(t, home.Shelves[1].Movies, 2)
+
+	// Every shelf but the failed one is cached for its own TTL
+	assert.Equal(t, map[string]time.Duration{
+		"home_shelf:user-1:watchlist":         cache.HomeWatchlistTTL,
+		"home_shelf:user-1:top_picks":         cache.HomeTopPicksTTL,
+		"home_shelf:user-1:because_you_rated": cache.HomeBecauseYouRatedTTL,
+	}, c.ttls)
+
+	// Cached shelves are served without querying again
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), now.Add(-trendingWindow), trendingNeighbors, trendingMinScore, 5).
+		Return([]*movies.Movie{thief}, nil).Once()
+
+	home, err = service.GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, home.Shelves, 3)
+	assert.Equal(t, ShelfTrending, home.Shelves[2].ID)
+	repo.AssertExpectations(t)
+}
+
+func TestGetHome_NewUser(t *testing.T) {
+	repo := new(mockRecommendationRepository)
+	repo.On("GetWatchlist", mock.Anything, users.UserID("user-1"), 5).Return([]*movies.Movie{}, nil)
+	repo.On("CountRatings", mock.Anything, users.UserID("user-1")).Return(0, nil)
+	repo.On("GetPreferences", mock.Anything, users.UserID("user-1")).Return(nil, nil)
+	repo.On("GetFavoriteGenre", mock.Anything, users.UserID("user-1"), favoriteGenreMinRatings, now, rating.DefaultGenreHalfLife).Return("", nil)
+	repo.On("GetRecentFavorite", mock.Anything, users.UserID("user-1"), mock.Anything, recentFavoriteMinScore).Return(nil, nil)
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), mock.Anything, trendingNeighbors, trendingMinScore, 5).Return([]*movies.Movie{}, nil)
+
+	home, err := setupRecommendationService(repo, cache.NewNoOpCache()).GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, home.Shelves)
+	assert.NotNil(t, home.Shelves)
+	repo.AssertNotCalled(t, "GetTopInGenre", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "GetSimilar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetHome_UnknownUser(t *testing.T) {
+	_, err := setupRecommendationService(new(mockRecommendationRepository), cache.NewNoOpCache()).GetHome(context.Background(), "user-missing")
+
+	var appErr *appErrors.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestGetHome_ColdStart(t *testing.T) {
+	preferences := &recommendations.Preferences{UserID: "user-1", Genres: []string{"Horror"}}
+	alien := &movies.Movie{ID: "movie-alien", Title: "Alien"}
+	scream := &movies.Movie{ID: "movie-scream", Title: "Scream"}
+	heat := &movies.Movie{ID: "movie-heat", Title: "Heat"}
+
+	repo := new(mockRecommendationRepository)
+	repo.On("GetWatchlist", mock.Anything, users.UserID("user-1"), 5).Return([]*movies.Movie{}, nil)
+	repo.On("CountRatings", mock.Anything, users.UserID("user-1")).Return(5, nil)
+	repo.On("GetPreferences", mock.Anything, users.UserID("user-1")).Return(preferences, nil)
+	repo.On("GetPreferred", mock.Anything, users.UserID("user-1"), preferences, rating.DefaultBayesianConfidenceK, 5).Return([]*movies.Movie{alien, scream}, nil)
+	// Liked by neighbors ever, and lately
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), time.Time{}, trendingNeighbors, trendingMinScore, 5).Return([]*movies.Movie{heat, alien}, nil)
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), now.Add(-trendingWindow), trendingNeighbors, trendingMinScore, 5).Return([]*movies.Movie{}, nil)
+	repo.On("GetRecentFavorite", mock.Anything, users.UserID("user-1"), mock.Anything, recentFavoriteMinScore).Return(nil, nil)
+
+	home, err := setupRecommendationService(repo, cache.NewNoOpCache()).GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, home.Shelves, 1)
+	assert.Equal(t, ShelfTopPicks, home.Shelves[0].ID)
+	assert.Equal(t, "Picked for you", home.Shelves[0].Title)
+
+	ids := make([]string, len(home.Shelves[0].Movies))
+	for i, movie := range home.Shelves[0].Movies {
+		ids[i] = movie.ID
+	}
+	assert.Equal(t, []string{"movie-alien", "movie-heat", "movie-scream"}, ids)
+	repo.AssertNotCalled(t, "GetFavoriteGenre", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetHome_KidsMode(t *testing.T) {
+	heat := &movies.Movie{ID: "movie-heat", Title: "Heat"}
+	thief := &movies.Movie{ID: "movie-thief", Title: "Thief"}
+	up := &movies.Movie{ID: "movie-up", Title: "Up"}
+	coco := &movies.Movie{ID: "movie-coco", Title: "Coco"}
+	policy := &movies.ContentPolicy{Territory: "US", Certifications: []string{"G", "PG"}}
+
+	repo := new(mockRecommendationRepository)
+	repo.On("GetWatchlist", mock.Anything, users.UserID("user-1"), 5).Return([]*movies.Movie{up, heat}, nil)
+	repo.On("CountRatings", mock.Anything, users.UserID("user-1")).Return(40, nil)
+	repo.On("GetFavoriteGenre", mock.Anything, users.UserID("user-1"), favoriteGenreMinRatings, now, rating.DefaultGenreHalfLife).Return("", nil)
+	repo.On("GetRecentFavorite", mock.Anything, users.UserID("user-1"), mock.Anything, recentFavoriteMinScore).Return(heat, nil)
+	repo.On("GetSimilar", mock.Anything, users.UserID("user-1"), heat, 5).Return([]*movies.Movie{coco, thief}, nil)
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), mock.Anything, trendingNeighbors, trendingMinScore, 5).Return([]*movies.Movie{thief}, nil)
+
+	filter := new(mockContentFilter)
+	filter.On("FilterAllowed", mock.Anything, mock.Anything, *policy).Return([]movies.MovieID{"movie-up", "movie-coco"}, nil)
+
+	finder := new(mockUserFinder)
+	finder.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	service := NewRecommendationService(repo, finder, &mockTimeProvider{now: now},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), WithShelfSize(5), WithKidsPolicy(policy, filter))
+
+	home, err := service.GetHome(users.WithContentMode(context.Background(), users.ContentModeKids), "user-1")
+	require.NoError(t, err)
+	require.Len(t, home.Shelves, 1, "the trending shelf is emptied and the one picked because of Heat is dropped")
+	assert.Equal(t, ShelfWatchlist, home.Shelves[0].ID)
+	require.Len(t, home.Shelves[0].Movies, 1)
+	assert.Equal(t, "movie-up", home.Shelves[0].Movies[0].ID)
+
+	// Standard mode isn't filtered
+	home, err = service.GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Len(t, home.Shelves, 3)
+	filter.AssertNumberOfCalls(t, "FilterAllowed", 1)
+}
+
+func TestGetHome_Preferences(t *testing.T) {
+	heat := &movies.Movie{ID: "movie-heat", Title: "Heat"}
+	thief := &movies.Movie{ID: "movie-thief", Title: "Thief"}
+	ronin := &movies.Movie{ID: "movie-ronin", Title: "Ronin"}
+	collateral := &movies.Movie{ID: "movie-collateral", Title: "Collateral"}
+
+	repo := new(mockRecommendationRepository)
+	repo.On("GetWatchlist", mock.Anything, users.UserID("user-1"), 5).Return([]*movies.Movie{}, nil)
+	repo.On("CountRatings", mock.Anything, users.UserID("user-1")).Return(40, nil)
+	repo.On("GetFavoriteGenre", mock.Anything, users.UserID("user-1"), favoriteGenreMinRatings, now, rating.DefaultGenreHalfLife).Return("", nil)
+	repo.On("GetRecentFavorite", mock.Anything, users.UserID("user-1"), mock.Anything, recentFavoriteMinScore).Return(heat, nil)
+	repo.On("GetSimilar", mock.Anything, users.UserID("user-1"), heat, 5).Return([]*movies.Movie{thief, ronin, collateral}, nil)
+	repo.On("GetTrendingAmongSimilarUsers", mock.Anything, users.UserID("user-1"), mock.Anything, trendingNeighbors, trendingMinScore, 5).Return([]*movies.Movie{}, nil)
+
+	headToHead := new(mockHeadToHeadFinder)
+	headToHead.On("GetHeadToHead", mock.Anything, "movie-thief", "movie-heat").Return(&rating.HeadToHead{SharedRaters: 4, PreferFirst: 3, PreferSecond: 1}, nil)
+	headToHead.On("GetHeadToHead", mock.Anything, "movie-ronin", "movie-heat").Return(&rating.HeadToHead{}, nil)
+	headToHead.On("GetHeadToHead", mock.Anything, "movie-collateral", "movie-heat").Return(nil, errors.New("connection reset"))
+
+	finder := new(mockUserFinder)
+	finder.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	service := NewRecommendationService(repo, finder, &mockTimeProvider{now: now},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), WithShelfSize(5), WithHeadToHead(headToHead))
+
+	home, err := service.GetHome(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, home.Shelves, 1)
+	cards := home.Shelves[0].Movies
+	require.Len(t, cards, 3)
+	assert.Equal(t, &Preference{SharedRaters: 4, PreferPercent: 75}, cards[0].Preference)
+	assert.Nil(t, cards[1].Preference, "no shared raters")
+	assert.Nil(t, cards[2].Preference, "a failed lookup leaves the card without")
+}
+
+func TestBlend(t *testing.T) {
+	movie := func(id string) *movies.Movie { return &movies.Movie{ID: movies.MovieID(id)} }
+	preferred := []*movies.Movie{movie("p1"), movie("p2"), movie("p3"), movie("p4")}
+	collaborative := []*movies.Movie{movie("c1"), movie("p2"), movie("c2"), movie("c3")}
+
+	tests := []struct {
+		name  string
+		share float64
+		size  int
+		want  []movies.MovieID
+	}{
+		{"new users get their preferences", 1, 3, []movies.MovieID{"p1", "p2", "p3"}},
+		{"half and half", 0.5, 6, []movies.MovieID{"p1", "c1", "p2", "c2", "p3", "c3"}},
+		{"mostly ratings", 0.2, 5, []movies.MovieID{"p1", "c1", "p2", "c2", "c3"}},
+		{"a list running out leaves its places", 0.5, 10, []movies.MovieID{"p1", "c1", "p2", "c2", "p3", "c3", "p4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []movies.MovieID
+			for _, m := range blend(preferred, collaborative, tt.share, tt.size) {
+				ids = append(ids, m.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSavePreferences(t *testing.T) {
+	repo := new(mockRecommendationRepository)
+	repo.On("SavePreferences", mock.Anything, &recommendations.Preferences{
+		UserID: "user-1", Genres: []string{"Horror"}, Decades: []int{1970, 1980}, UpdatedAt: now,
+	}).Return(nil)
+	repo.On("SavePreferences", mock.Anything, mock.MatchedBy(func(p *recommendations.Preferences) bool { return p.UserID == "user-missing" })).
+		Return(users.ErrUserNotFound)
+
+	c := newMapCache()
+	require.NoError(t, c.Set(context.Background(), "home_shelf:user-1:top_picks", Shelf{ID: ShelfTopPicks}, time.Hour))
+	service := setupRecommendationService(repo, c)
+
+	preferences, err := service.SavePreferences(context.Background(), "user-1", PreferencesRequest{Genres: []string{"Horror"}, Decades: []int{1980, 1970}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1970, 1980}, preferences.Decades)
+	assert.NotContains(t, c.values, "home_shelf:user-1:top_picks", "the picks are rebuilt from the new preferences")
+
+	var appErr *appErrors.AppError
+	_, err = service.SavePreferences(context.Background(), "user-1", PreferencesRequest{})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	_, err = service.SavePreferences(context.Background(), "user-missing", PreferencesRequest{Genres: []string{"Horror"}})
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
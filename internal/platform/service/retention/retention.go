@@ -0,0 +1,225 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/retention"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/pkg/metrics"
+	"thermondo/internal/pkg/storage"
+	"time"
+)
+
+const (
+	// JobType is the job that applies the retention policy
+	JobType = "retention.purge"
+
+	DefaultBatchSize = 1000
+
+	// targetOrphanedMedia counts stored objects no row points at
+	targetOrphanedMedia = "orphaned_media"
+)
+
+// mediaPrefixes are where the service stores objects. Objects elsewhere in
+// the bucket are never touched.
+var mediaPrefixes = []string{"posters/", "avatars/", storage.PrivatePrefix}
+
+var removed = metrics.NewCounterVec("retention_removed_total",
+	"Rows and media objects removed by retention, by target; dry runs count what they would have removed", "target", "dry_run")
+
+// Policy sets how long data is kept once it is no longer used; 0 keeps it
+// forever
+type Policy struct {
+	Sessions   time.Duration // After the session expired or was revoked
+	MergeAudit time.Duration // After the merge
+	Jobs       time.Duration // After the job finished
+	// DeletedRatings must be longer than the period in which a deleted
+	// rating can be restored
+	DeletedRatings time.Duration // After the rating was deleted
+	// OrphanedMedia is how old an object no row points at must be before it
+	// is deleted. It must cover the time between storing an upload and
+	// saving the row that points at it.
+	OrphanedMedia time.Duration
+}
+
+// RunOptions is the payload of a retention job
+type RunOptions struct {
+	DryRun bool `json:"dry_run"` // Count what would be removed without removing it
+}
+
+// Result is what a retention job reports. Removed counts by target what
+// was removed, or in a dry run what would have been.
+type Result struct {
+	DryRun  bool             `json:"dry_run"`
+	Removed map[string]int64 `json:"removed"`
+}
+
+// JobQueue queues background jobs
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error)
+}
+
+// Service deletes data whose retention period is over
+type Service interface {
+	// StartRun queues a retention job outside the schedule
+	StartRun(ctx context.Context, dryRun bool, startedBy string) (*jobs.Job, error)
+	// Run is the jobs.Handler of JobType
+	Run(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error
+}
+
+type retentionService struct {
+	repo         retention.Repository
+	store        storage.Storage
+	queue        JobQueue
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	policy       Policy
+	batchSize    int
+}
+
+// Option configures optional settings of the retention service
+type Option func(*retentionService)
+
+// WithPolicy sets the retention periods; without it nothing is removed
+func WithPolicy(policy Policy) Option {
+	return func(s *retentionService) {
+		s.policy = policy
+	}
+}
+
+// WithBatchSize sets how many rows a delete statement removes at most
+func WithBatchSize(size int) Option {
+	return func(s *retentionService) {
+		s.batchSize = size
+	}
+}
+
+func NewRetentionService(
+	repo retention.Repository,
+	store storage.Storage,
+	queue JobQueue,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &retentionService{
+		repo:         repo,
+		store:        store,
+		queue:        queue,
+		timeProvider: timeProvider,
+		logger:       logger,
+		batchSize:    DefaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *retentionService) StartRun(ctx context.Context, dryRun bool, startedBy string) (*jobs.Job, error) {
+	return s.queue.Enqueue(ctx, JobType, RunOptions{DryRun: dryRun}, startedBy)
+}
+
+func (s *retentionService) Run(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	var options RunOptions
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal(job.Payload, &options); err != nil {
+			return jobs.Permanent(fmt.Errorf("invalid retention payload: %w", err))
+		}
+	}
+
+	now := s.timeProvider.Now()
+	steps := []struct {
+		target string
+		keep   time.Duration
+		purge  func(ctx context.Context, before time.Time, dryRun bool) (int64, error)
+	}{
+		{string(retention.TargetSessions), s.policy.Sessions, s.purgeRows(retention.TargetSessions)},
+		{string(retention.TargetMergeAudit), s.policy.MergeAudit, s.purgeRows(retention.TargetMergeAudit)},
+		{string(retention.TargetJobs), s.policy.Jobs, s.purgeRows(retention.TargetJobs)},
+		{string(retention.TargetDeletedRatings), s.policy.DeletedRatings, s.purgeRows(retention.TargetDeletedRatings)},
+		{targetOrphanedMedia, s.policy.OrphanedMedia, s.purgeOrphanedMedia},
+	}
+
+	// A retried run starts over; what an earlier attempt removed is gone
+	// and not counted again
+	result := Result{DryRun: options.DryRun, Removed: make(map[string]int64)}
+	for i, step := range steps {
+		if step.keep <= 0 {
+			continue
+		}
+		n, err := step.purge(ctx, now.Add(-step.keep), options.DryRun)
+		// Rows a step removed before failing are counted too
+		if n > 0 {
+			removed.With(step.target, strconv.FormatBool(options.DryRun)).Add(n)
+		}
+		result.Removed[step.target] = n
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", step.target, err)
+		}
+
+		snapshot := Result{DryRun: result.DryRun, Removed: make(map[string]int64, len(result.Removed))}
+		for target, count := range result.Removed {
+			snapshot.Removed[target] = count
+		}
+		report(float64(i+1)/float64(len(steps))*100, snapshot)
+	}
+
+	s.logger.Info("Retention run completed", "job_id", job.ID, "dry_run", options.DryRun, "removed", result.Removed)
+	return nil
+}
+
+func (s *retentionService) purgeRows(target retention.Target) func(context.Context, time.Time, bool) (int64, error) {
+	return func(ctx context.Context, before time.Time, dryRun bool) (int64, error) {
+		return s.repo.Purge(ctx, target, before, s.batchSize, dryRun)
+	}
+}
+
+// purgeOrphanedMedia deletes objects written before before that no row
+// points at. Objects are listed before the references are read, so an
+// object saved in between is seen as referenced.
+func (s *retentionService) purgeOrphanedMedia(ctx context.Context, before time.Time, dryRun bool) (int64, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	var candidates []storage.Object
+	for _, prefix := range mediaPrefixes {
+		objects, err := s.store.List(ctx, prefix)
+		if err != nil {
+			return 0, err
+		}
+		for _, object := range objects {
+			if object.ModTime.Before(before) {
+				candidates = append(candidates, object)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	refs, err := s.repo.MediaReferences(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, object := range candidates {
+		if refs.Referenced(object.Key) {
+			continue
+		}
+		if !dryRun {
+			if err := s.store.Delete(ctx, object.Key); err != nil {
+				return n, err
+			}
+		}
+		s.logger.Debug("Orphaned media object", "key", object.Key, "dry_run", dryRun)
+		n++
+	}
+	return n, nil
+}
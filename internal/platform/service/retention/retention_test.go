@@ -0,0 +1,136 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/retention"
+	"thermondo/internal/pkg/storage"
+)
+
+var testNow = time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)
+
+type fixedTime struct{}
+
+func (fixedTime) Now() time.Time { return testNow }
+
+// fakeRepository reports the cut-offs it is given
+type fakeRepository struct {
+	counts  map[retention.Target]int64
+	err     error
+	purged  map[retention.Target]time.Time
+	dryRuns int
+	refs    *retention.MediaReferences
+}
+
+func (r *fakeRepository) Purge(ctx context.Context, target retention.Target, before time.Time, batchSize int, dryRun bool) (int64, error) {
+	if r.purged == nil {
+		r.purged = make(map[retention.Target]time.Time)
+	}
+	r.purged[target] = before
+	if dryRun {
+		r.dryRuns++
+	}
+	return r.counts[target], r.err
+}
+
+func (r *fakeRepository) MediaReferences(ctx context.Context) (*retention.MediaReferences, error) {
+	return r.refs, nil
+}
+
+// putMedia stores objects in a local store and backdates them by age
+func putMedia(t *testing.T, objects map[string]time.Duration) (storage.Storage, string) {
+	root := t.TempDir()
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: root, BaseURL: "/media"})
+	require.NoError(t, err)
+	for key, age := range objects {
+		require.NoError(t, store.Put(context.Background(), key, []byte("data"), "image/jpeg"))
+		modTime := testNow.Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), modTime, modTime))
+	}
+	return store, root
+}
+
+func runJob(t *testing.T, service Service, payload string) (Result, error) {
+	t.Helper()
+	var result Result
+	err := service.Run(context.Background(), &jobs.Job{ID: "job-1", Type: JobType, Payload: json.RawMessage(payload)},
+		func(progress float64, r any) { result = r.(Result) })
+	return result, err
+}
+
+func newService(repo retention.Repository, store storage.Storage, policy Policy) Service {
+	return NewRetentionService(repo, store, nil, fixedTime{}, slog.New(slog.NewTextHandler(io.Discard, nil)), WithPolicy(policy))
+}
+
+func TestRetentionRun(t *testing.T) {
+	store, root := putMedia(t, map[string]time.Duration{
+		"posters/m1/u1/large.jpg":          48 * time.Hour, // Referenced
+		"posters/m1/u0/large.jpg":          48 * time.Hour, // Orphaned
+		"posters/m1/u2/large.jpg":          time.Hour,      // Orphaned, but may still be saved
+		"avatars/user-1/a1/small.jpg":      48 * time.Hour, // Referenced through its prefix
+		"avatars/user-1/a0/small.jpg":      48 * time.Hour, // Orphaned
+		"private/imports/ratings/x.ndjson": 48 * time.Hour, // Orphaned
+		"other/file.txt":                   48 * time.Hour, // Not ours
+	})
+	repo := &fakeRepository{
+		counts: map[retention.Target]int64{retention.TargetSessions: 12, retention.TargetJobs: 3, retention.TargetDeletedRatings: 4},
+		refs: &retention.MediaReferences{
+			Keys:     map[string]bool{"posters/m1/u1/large.jpg": true},
+			Prefixes: map[string]bool{"avatars/user-1/a1": true},
+		},
+	}
+	service := newService(repo, store, Policy{Sessions: 720 * time.Hour, Jobs: 24 * time.Hour, DeletedRatings: 720 * time.Hour, OrphanedMedia: 24 * time.Hour})
+
+	// A dry run removes nothing
+	result, err := runJob(t, service, `{"dry_run":true}`)
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, map[string]int64{"sessions": 12, "jobs": 3, "deleted_ratings": 4, "orphaned_media": 3}, result.Removed)
+	assert.Equal(t, 3, repo.dryRuns)
+	assert.FileExists(t, filepath.Join(root, "posters/m1/u0/large.jpg"))
+
+	result, err = runJob(t, service, `{}`)
+	require.NoError(t, err)
+	assert.False(t, result.DryRun)
+	assert.Equal(t, int64(3), result.Removed["orphaned_media"])
+	assert.Equal(t, testNow.Add(-720*time.Hour), repo.purged[retention.TargetSessions])
+	assert.Equal(t, testNow.Add(-24*time.Hour), repo.purged[retention.TargetJobs])
+	assert.Equal(t, testNow.Add(-720*time.Hour), repo.purged[retention.TargetDeletedRatings])
+	_, purgedAudit := repo.purged[retention.TargetMergeAudit]
+	assert.False(t, purgedAudit, "a period of 0 keeps the rows")
+
+	for key, kept := range map[string]bool{
+		"posters/m1/u1/large.jpg":          true,
+		"posters/m1/u0/large.jpg":          false,
+		"posters/m1/u2/large.jpg":          true,
+		"avatars/user-1/a1/small.jpg":      true,
+		"avatars/user-1/a0/small.jpg":      false,
+		"private/imports/ratings/x.ndjson": false,
+		"other/file.txt":                   true,
+	} {
+		_, err := os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+		assert.Equal(t, kept, err == nil, key)
+	}
+}
+
+func TestRetentionRun_Failures(t *testing.T) {
+	service := newService(&fakeRepository{err: errors.New("connection reset")}, nil, Policy{Sessions: time.Hour})
+	_, err := runJob(t, service, `{}`)
+	require.Error(t, err)
+	assert.False(t, jobs.IsPermanent(err))
+
+	_, err = runJob(t, service, `{"dry_run":"yes"}`)
+	assert.True(t, jobs.IsPermanent(err))
+}
@@ -0,0 +1,51 @@
+package seo
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockMovieFinder struct {
+	mock.Mock
+}
+
+func (m *mockMovieFinder) GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error) {
+	opts := movies.DefaultSearchOptions()
+	for _, option := range options {
+		option(&opts)
+	}
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *mockMovieFinder) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
+func (m *mockMovieFinder) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockStatsProvider struct {
+	mock.Mock
+}
+
+func (m *mockStatsProvider) GetEnhancedMovieStats(ctx context.Context, movieID string) (*ratingService.EnhancedMovieStats, error) {
+	args := m.Called(ctx, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ratingService.EnhancedMovieStats), args.Error(1)
+}
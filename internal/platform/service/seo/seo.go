@@ -0,0 +1,228 @@
+package seo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+	"time"
+)
+
+const (
+	// DefaultPageSize is how many movies a sitemap page lists unless
+	// WithPageSize says otherwise
+	DefaultPageSize = 10000
+	// MaxPageSize is the most URLs the sitemap protocol allows per file
+	MaxPageSize = 50000
+)
+
+// Service publishes the catalog for search engines: a sitemap of the movie
+// pages of the web frontend and the schema.org data to embed in each page.
+// Both are cached, as crawlers read them in bursts.
+type Service interface {
+	// SitemapIndex links every sitemap page
+	SitemapIndex(ctx context.Context) ([]*SitemapEntry, error)
+	// SitemapPage lists the movie pages of the 1-based page, oldest movie
+	// first, so a page keeps its movies as the catalog grows
+	SitemapPage(ctx context.Context, page int) ([]*SitemapEntry, error)
+	// MovieData returns what search engines are told about a movie
+	MovieData(ctx context.Context, movieID string) (*MovieData, error)
+}
+
+// SitemapEntry is a URL of the sitemap and when its content last changed;
+// LastModified is zero when unknown
+type SitemapEntry struct {
+	URL          string
+	LastModified time.Time
+}
+
+// MovieData is a movie, the URL of its page and its rating as shown to
+// search engines: the Bayesian average, so a few enthusiastic votes do not
+// make a movie look better than it is. The rating is left out, with
+// RatingCount 0, while the movie has no ratings or too few to show.
+type MovieData struct {
+	URL         string
+	Movie       *movies.Movie
+	RatingValue float64
+	RatingCount int64
+}
+
+// MovieFinder loads the movies of the catalog
+type MovieFinder interface {
+	GetAll(ctx context.Context, options ...movies.SearchOption) ([]*movies.Movie, error)
+	GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+// StatsProvider computes the Bayesian stats of a movie
+type StatsProvider interface {
+	GetEnhancedMovieStats(ctx context.Context, movieID string) (*ratingService.EnhancedMovieStats, error)
+}
+
+type seoService struct {
+	movies   MovieFinder
+	stats    StatsProvider
+	cache    cache.Cache
+	siteURL  string
+	pageSize int
+	logger   *slog.Logger
+}
+
+// Option configures optional settings of the SEO service
+type Option func(*seoService)
+
+// WithPageSize replaces DefaultPageSize
+func WithPageSize(size int) Option {
+	return func(s *seoService) {
+		if size > 0 && size <= MaxPageSize {
+			s.pageSize = size
+		}
+	}
+}
+
+// WithCache caches the sitemap pages and the movie data
+func WithCache(c cache.Cache) Option {
+	return func(s *seoService) {
+		s.cache = c
+	}
+}
+
+// NewSEOService publishes the movie pages of the web frontend at siteURL,
+// which are at {siteURL}/movies/{id}
+func NewSEOService(movieFinder MovieFinder, stats StatsProvider, siteURL string, logger *slog.Logger, opts ...Option) Service {
+	s := &seoService{
+		movies:   movieFinder,
+		stats:    stats,
+		siteURL:  strings.TrimRight(siteURL, "/"),
+		pageSize: DefaultPageSize,
+		logger:   logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *seoService) SitemapIndex(ctx context.Context) ([]*SitemapEntry, error) {
+	cacheKey := cache.SitemapKeyFunc(0)
+	if entries, ok := s.cachedSitemap(ctx, cacheKey); ok {
+		return entries, nil
+	}
+
+	total, err := s.movies.Count(ctx)
+	if err != nil {
+		s.logger.Error("Failed to count movies for the sitemap", "error", err)
+		return nil, errors.NewInternalError("Failed to build sitemap")
+	}
+
+	// An empty catalog still has an empty first page
+	pages := max(1, int(math.Ceil(float64(total)/float64(s.pageSize))))
+	entries := make([]*SitemapEntry, pages)
+	for i := range entries {
+		entries[i] = &SitemapEntry{URL: fmt.Sprintf("%s/sitemap.xml?page=%d", s.siteURL, i+1)}
+	}
+
+	s.cacheSitemap(ctx, cacheKey, entries)
+	return entries, nil
+}
+
+func (s *seoService) SitemapPage(ctx context.Context, page int) ([]*SitemapEntry, error) {
+	if page < 1 {
+		return nil, errors.NewBadRequestError("page must be a positive number")
+	}
+	cacheKey := cache.SitemapKeyFunc(page)
+	if entries, ok := s.cachedSitemap(ctx, cacheKey); ok {
+		return entries, nil
+	}
+
+	moviesList, err := s.movies.GetAll(ctx,
+		movies.WithLimit(s.pageSize),
+		movies.WithOffset((page-1)*s.pageSize),
+		movies.WithSort("created_at", "asc"),
+	)
+	if err != nil {
+		s.logger.Error("Failed to list movies for the sitemap", "error", err, "page", page)
+		return nil, errors.NewInternalError("Failed to build sitemap")
+	}
+	// Only the first page may be empty, for an empty catalog
+	if len(moviesList) == 0 && page > 1 {
+		return nil, errors.NewNotFoundError("Sitemap page not found")
+	}
+
+	entries := make([]*SitemapEntry, len(moviesList))
+	for i, movie := range moviesList {
+		entries[i] = &SitemapEntry{URL: s.movieURL(movie.ID), LastModified: movie.UpdatedAt}
+	}
+
+	s.cacheSitemap(ctx, cacheKey, entries)
+	return entries, nil
+}
+
+func (s *seoService) MovieData(ctx context.Context, movieID string) (*MovieData, error) {
+	cacheKey := cache.StructuredDataKeyFunc(movieID)
+	if s.cache != nil {
+		var cached MovieData
+		if err := s.cache.Get(ctx, cacheKey, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	movie, err := s.movies.GetByID(ctx, movies.MovieID(movieID))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, errors.NewNotFoundError("Movie not found")
+		}
+		s.logger.Error("Failed to get movie for structured data", "error", err, "movie_id", movieID)
+		return nil, errors.NewInternalError("Failed to get movie")
+	}
+	stats, err := s.stats.GetEnhancedMovieStats(ctx, movieID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &MovieData{URL: s.movieURL(movie.ID), Movie: movie}
+	if stats.TotalRatings > 0 && !stats.Withheld {
+		data.RatingValue = math.Round(stats.BayesianAverage*10) / 10
+		data.RatingCount = stats.TotalRatings
+	}
+
+	// Tagged with the movie, so a new rating clears it along with the
+	// movie's other cached responses
+	if s.cache != nil {
+		if err := s.cache.SetWithTags(ctx, cacheKey, data, cache.StructuredDataTTL, cache.MovieTag(movieID)); err != nil {
+			s.logger.Warn("Failed to cache structured data", "error", err, "movie_id", movieID)
+		}
+	}
+
+	return data, nil
+}
+
+func (s *seoService) movieURL(id movies.MovieID) string {
+	return s.siteURL + "/movies/" + url.PathEscape(string(id))
+}
+
+func (s *seoService) cachedSitemap(ctx context.Context, key string) ([]*SitemapEntry, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	var cached []*SitemapEntry
+	if err := s.cache.Get(ctx, key, &cached); err != nil {
+		return nil, false
+	}
+	return cached, true
+}
+
+func (s *seoService) cacheSitemap(ctx context.Context, key string, entries []*SitemapEntry) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Set(ctx, key, entries, cache.SitemapTTL); err != nil {
+		s.logger.Warn("Failed to cache sitemap", "error", err, "key", key)
+	}
+}
@@ -0,0 +1,156 @@
+package seo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+	ratingService "thermondo/internal/platform/service/rating"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testSiteURL = "https://movies.example.com"
+
+func setupTestService(opts ...Option) (Service, *mockMovieFinder, *mockStatsProvider) {
+	finder := new(mockMovieFinder)
+	stats := new(mockStatsProvider)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewSEOService(finder, stats, testSiteURL+"/", logger, opts...), finder, stats
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, status, appErr.StatusCode)
+}
+
+func TestSitemapIndex(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		total int64
+		pages int
+	}{
+		{"an empty catalog has one page", 0, 1},
+		{"a full last page", 4, 2},
+		{"a partial last page", 5, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, finder, _ := setupTestService(WithPageSize(2))
+			finder.On("Count", ctx).Return(tt.total, nil)
+
+			entries, err := service.SitemapIndex(ctx)
+
+			require.NoError(t, err)
+			require.Len(t, entries, tt.pages)
+			assert.Equal(t, testSiteURL+"/sitemap.xml?page=1", entries[0].URL)
+		})
+	}
+}
+
+func TestSitemapPage(t *testing.T) {
+	ctx := context.Background()
+	updated := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should list the movie pages oldest first", func(t *testing.T) {
+		service, finder, _ := setupTestService(WithPageSize(2))
+		finder.On("GetAll", ctx, movies.SearchOptions{Limit: 2, Offset: 2, SortBy: "created_at", Order: "asc"}).
+			Return([]*movies.Movie{{ID: "movie 3", UpdatedAt: updated}}, nil)
+
+		entries, err := service.SitemapPage(ctx, 2)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, testSiteURL+"/movies/movie%203", entries[0].URL)
+		assert.Equal(t, updated, entries[0].LastModified)
+	})
+
+	t.Run("should not find a page past the end", func(t *testing.T) {
+		service, finder, _ := setupTestService()
+		finder.On("GetAll", ctx, mock.Anything).Return([]*movies.Movie{}, nil)
+
+		_, err := service.SitemapPage(ctx, 3)
+
+		assertStatus(t, err, http.StatusNotFound)
+	})
+
+	t.Run("should reject a page below 1", func(t *testing.T) {
+		service, _, _ := setupTestService()
+
+		_, err := service.SitemapPage(ctx, 0)
+
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("should serve a cached page", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "sitemap:1", mock.Anything).Return(nil)
+		service, finder, _ := setupTestService(WithCache(mockCache))
+
+		_, err := service.SitemapPage(ctx, 1)
+
+		require.NoError(t, err)
+		finder.AssertNotCalled(t, "GetAll", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMovieData(t *testing.T) {
+	ctx := context.Background()
+	movie := &movies.Movie{ID: "movie-1", Title: "Dune"}
+
+	t.Run("should rate the movie with its Bayesian average", func(t *testing.T) {
+		mockCache := new(cache.MockCache)
+		mockCache.On("Get", ctx, "structured_data:movie-1", mock.Anything).Return(errors.New("cache miss"))
+		mockCache.On("SetWithTags", ctx, "structured_data:movie-1", mock.Anything, cache.StructuredDataTTL, []string{"movie:movie-1"}).Return(nil)
+		service, finder, stats := setupTestService(WithCache(mockCache))
+		finder.On("GetByID", ctx, movies.MovieID("movie-1")).Return(movie, nil)
+		stats.On("GetEnhancedMovieStats", ctx, "movie-1").Return(&ratingService.EnhancedMovieStats{
+			MovieRatingStats: &rating.MovieRatingStats{AverageScore: 4.8, TotalRatings: 12},
+			BayesianAverage:  3.5649,
+		}, nil)
+
+		data, err := service.MovieData(ctx, "movie-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, testSiteURL+"/movies/movie-1", data.URL)
+		assert.Equal(t, 3.6, data.RatingValue)
+		assert.Equal(t, int64(12), data.RatingCount)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("should leave out a withheld rating", func(t *testing.T) {
+		service, finder, stats := setupTestService()
+		finder.On("GetByID", ctx, movies.MovieID("movie-1")).Return(movie, nil)
+		stats.On("GetEnhancedMovieStats", ctx, "movie-1").Return(&ratingService.EnhancedMovieStats{
+			MovieRatingStats: &rating.MovieRatingStats{TotalRatings: 2, Withheld: true},
+		}, nil)
+
+		data, err := service.MovieData(ctx, "movie-1")
+
+		require.NoError(t, err)
+		assert.Zero(t, data.RatingCount)
+	})
+
+	t.Run("should not find a missing movie", func(t *testing.T) {
+		service, finder, _ := setupTestService()
+		finder.On("GetByID", ctx, movies.MovieID("missing")).Return(nil, errors.New("movie with ID missing not found"))
+
+		_, err := service.MovieData(ctx, "missing")
+
+		assertStatus(t, err, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"context"
+	"thermondo/internal/domain/users"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *mockSessionRepository) Create(ctx context.Context, session *users.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) FindByID(ctx context.Context, id users.SessionID) (*users.Session, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Session), args.Error(1)
+}
+
+func (m *mockSessionRepository) ListActive(ctx context.Context, userID users.UserID, now time.Time) ([]*users.Session, error) {
+	args := m.Called(ctx, userID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*users.Session), args.Error(1)
+}
+
+func (m *mockSessionRepository) Touch(ctx context.Context, id users.SessionID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) Revoke(ctx context.Context, userID users.UserID, id users.SessionID, at time.Time) error {
+	args := m.Called(ctx, userID, id, at)
+	return args.Error(0)
+}
+
+func (m *mockSessionRepository) RevokeAll(ctx context.Context, userID users.UserID, at time.Time) (int64, error) {
+	args := m.Called(ctx, userID, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockIDGenerator struct {
+	id string
+}
+
+func (m *mockIDGenerator) Generate() string {
+	return m.id
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,156 @@
+package session
+
+import (
+	"context"
+	stdErrors "errors"
+	"log/slog"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+// DefaultTouchInterval is how stale last_used_at may get before an
+// authenticated request updates it, so busy clients don't write on every call
+const DefaultTouchInterval = time.Minute
+
+// Service tracks the devices a user is signed in on
+type Service interface {
+	// Start opens a session for a successful login that lasts until expiresAt
+	Start(ctx context.Context, userID, userAgent, ip string, expiresAt time.Time) (*users.Session, error)
+	// List returns the user's active sessions, most recently used first
+	List(ctx context.Context, userID string) ([]*users.Session, error)
+	// Revoke signs one device out
+	Revoke(ctx context.Context, userID, sessionID string) error
+	// RevokeAll signs the user out everywhere and returns how many sessions
+	// were ended
+	RevokeAll(ctx context.Context, userID string) (int64, error)
+	// Validate checks that a token's session is still active and records
+	// that it was used. It returns users.ErrSessionRevoked for revoked,
+	// expired and unknown sessions.
+	Validate(ctx context.Context, sessionID string) error
+}
+
+type sessionService struct {
+	repo          users.SessionRepository
+	idGenerator   shared.IDGenerator
+	timeProvider  shared.TimeProvider
+	logger        *slog.Logger
+	touchInterval time.Duration
+}
+
+// Option configures optional settings of the session service
+type Option func(*sessionService)
+
+// WithTouchInterval sets how often last_used_at is refreshed
+func WithTouchInterval(interval time.Duration) Option {
+	return func(s *sessionService) {
+		if interval >= 0 {
+			s.touchInterval = interval
+		}
+	}
+}
+
+func NewSessionService(
+	repo users.SessionRepository,
+	idGenerator shared.IDGenerator,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &sessionService{
+		repo:          repo,
+		idGenerator:   idGenerator,
+		timeProvider:  timeProvider,
+		logger:        logger,
+		touchInterval: DefaultTouchInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// maxUserAgentLength keeps arbitrary client headers from bloating the table
+const maxUserAgentLength = 512
+
+func (s *sessionService) Start(ctx context.Context, userID, userAgent, ip string, expiresAt time.Time) (*users.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := s.timeProvider.Now()
+	session := &users.Session{
+		ID:         users.SessionID(s.idGenerator.Generate()),
+		UserID:     users.UserID(userID),
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.repo.Create(ctx, session); err != nil {
+		s.logger.Error("Failed to start session", "error", err, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to start session")
+	}
+	return session, nil
+}
+
+func (s *sessionService) List(ctx context.Context, userID string) ([]*users.Session, error) {
+	sessions, err := s.repo.ListActive(ctx, users.UserID(userID), s.timeProvider.Now())
+	if err != nil {
+		s.logger.Error("Failed to list sessions", "error", err, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to list sessions")
+	}
+	if sessions == nil {
+		sessions = []*users.Session{}
+	}
+	return sessions, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	err := s.repo.Revoke(ctx, users.UserID(userID), users.SessionID(sessionID), s.timeProvider.Now())
+	if err != nil {
+		if stdErrors.Is(err, users.ErrSessionNotFound) {
+			return errors.NewNotFoundError("Session not found")
+		}
+		s.logger.Error("Failed to revoke session", "error", err, "user_id", userID, "session_id", sessionID)
+		return errors.NewInternalError("Failed to revoke session")
+	}
+
+	s.logger.Info("Revoked session", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID string) (int64, error) {
+	revoked, err := s.repo.RevokeAll(ctx, users.UserID(userID), s.timeProvider.Now())
+	if err != nil {
+		s.logger.Error("Failed to revoke sessions", "error", err, "user_id", userID)
+		return 0, errors.NewInternalError("Failed to revoke sessions")
+	}
+
+	s.logger.Info("Revoked all sessions", "user_id", userID, "revoked", revoked)
+	return revoked, nil
+}
+
+func (s *sessionService) Validate(ctx context.Context, sessionID string) error {
+	session, err := s.repo.FindByID(ctx, users.SessionID(sessionID))
+	if err != nil {
+		if stdErrors.Is(err, users.ErrSessionNotFound) {
+			return users.ErrSessionRevoked
+		}
+		return err
+	}
+
+	now := s.timeProvider.Now()
+	if !session.Active(now) {
+		return users.ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastUsedAt) >= s.touchInterval {
+		if err := s.repo.Touch(ctx, session.ID, now); err != nil {
+			s.logger.Warn("Failed to record session use", "error", err, "session_id", sessionID)
+		}
+	}
+	return nil
+}
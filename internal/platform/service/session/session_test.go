@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func setupTestService() (Service, *mockSessionRepository) {
+	repo := new(mockSessionRepository)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewSessionService(repo, &mockIDGenerator{id: "session-1"}, &mockTimeProvider{now: testNow}, logger)
+	return service, repo
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := testNow.Add(24 * time.Hour)
+
+	t.Run("records the device", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Create", ctx, &users.Session{
+			ID: "session-1", UserID: "user-1", UserAgent: "curl/8.0", IP: "203.0.113.7",
+			CreatedAt: testNow, LastUsedAt: testNow, ExpiresAt: expiresAt,
+		}).Return(nil)
+
+		session, err := service.Start(ctx, "user-1", "curl/8.0", "203.0.113.7", expiresAt)
+
+		require.NoError(t, err)
+		assert.Equal(t, users.SessionID("session-1"), session.ID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("truncates long user agents", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Create", ctx, mock.MatchedBy(func(s *users.Session) bool {
+			return len(s.UserAgent) == maxUserAgentLength
+		})).Return(nil)
+
+		_, err := service.Start(ctx, "user-1", strings.Repeat("a", 2000), "203.0.113.7", expiresAt)
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Create", ctx, mock.Anything).Return(errors.New("connection reset"))
+
+		_, err := service.Start(ctx, "user-1", "curl/8.0", "203.0.113.7", expiresAt)
+
+		assertAppErrorCode(t, err, appErrors.CodeInternal)
+	})
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns an empty list rather than nil", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("ListActive", ctx, users.UserID("user-1"), testNow).Return(nil, nil)
+
+		sessions, err := service.List(ctx, "user-1")
+
+		require.NoError(t, err)
+		assert.NotNil(t, sessions)
+		assert.Empty(t, sessions)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revokes the session", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Revoke", ctx, users.UserID("user-1"), users.SessionID("session-1"), testNow).Return(nil)
+
+		require.NoError(t, service.Revoke(ctx, "user-1", "session-1"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("Revoke", ctx, users.UserID("user-1"), users.SessionID("other"), testNow).Return(users.ErrSessionNotFound)
+
+		assertAppErrorCode(t, service.Revoke(ctx, "user-1", "other"), appErrors.CodeNotFound)
+	})
+
+	t.Run("revokes everything", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("RevokeAll", ctx, users.UserID("user-1"), testNow).Return(int64(3), nil)
+
+		revoked, err := service.RevokeAll(ctx, "user-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), revoked)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	active := func(lastUsed time.Time) *users.Session {
+		return &users.Session{ID: "session-1", UserID: "user-1", LastUsedAt: lastUsed, ExpiresAt: testNow.Add(time.Hour)}
+	}
+
+	t.Run("active session used a while ago is touched", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindByID", ctx, users.SessionID("session-1")).Return(active(testNow.Add(-5*time.Minute)), nil)
+		repo.On("Touch", ctx, users.SessionID("session-1"), testNow).Return(nil)
+
+		require.NoError(t, service.Validate(ctx, "session-1"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("recently used session is not touched again", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindByID", ctx, users.SessionID("session-1")).Return(active(testNow.Add(-10*time.Second)), nil)
+
+		require.NoError(t, service.Validate(ctx, "session-1"))
+		repo.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		service, repo := setupTestService()
+		session := active(testNow)
+		revokedAt := testNow.Add(-time.Minute)
+		session.RevokedAt = &revokedAt
+		repo.On("FindByID", ctx, users.SessionID("session-1")).Return(session, nil)
+
+		assert.ErrorIs(t, service.Validate(ctx, "session-1"), users.ErrSessionRevoked)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		service, repo := setupTestService()
+		session := active(testNow)
+		session.ExpiresAt = testNow
+		repo.On("FindByID", ctx, users.SessionID("session-1")).Return(session, nil)
+
+		assert.ErrorIs(t, service.Validate(ctx, "session-1"), users.ErrSessionRevoked)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindByID", ctx, users.SessionID("forged")).Return(nil, users.ErrSessionNotFound)
+
+		assert.ErrorIs(t, service.Validate(ctx, "forged"), users.ErrSessionRevoked)
+	})
+
+	t.Run("repository failure is not a revocation", func(t *testing.T) {
+		service, repo := setupTestService()
+		repo.On("FindByID", ctx, users.SessionID("session-1")).Return(nil, errors.New("connection reset"))
+
+		err := service.Validate(ctx, "session-1")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, users.ErrSessionRevoked)
+	})
+}
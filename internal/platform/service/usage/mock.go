@@ -0,0 +1,40 @@
+package usage
+
+import (
+	"context"
+	"thermondo/internal/domain/usage"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockUsageRepository struct {
+	mock.Mock
+}
+
+func (m *mockUsageRepository) Add(ctx context.Context, counters []*usage.Counter) error {
+	args := m.Called(ctx, counters)
+	return args.Error(0)
+}
+
+func (m *mockUsageRepository) List(ctx context.Context, filter usage.Filter) ([]*usage.Counter, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*usage.Counter), args.Error(1)
+}
+
+func (m *mockUsageRepository) CountRequests(ctx context.Context, principal usage.Principal, from, to time.Time) (int64, error) {
+	args := m.Called(ctx, principal, from, to)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
@@ -0,0 +1,396 @@
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/usage"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	// MaxReportDays bounds a usage report to about a year of days
+	MaxReportDays = 366
+	// DefaultTopEndpoints is how many endpoints a report lists per principal
+	DefaultTopEndpoints = 5
+
+	// pendingKey holds counts not yet flushed to Postgres, one field per
+	// principal, day, endpoint and measure
+	pendingKey = "usage:pending"
+	// monthKeyFormat counts a principal's requests in a month for quotas:
+	// usage:month:{kind}:{id}:{yyyy-mm}
+	monthKeyFormat = "usage:month:%s:%s:%s"
+	// monthKeyTTL keeps a month's counter past the month's end
+	monthKeyTTL = 32 * 24 * time.Hour
+
+	fieldSeparator = "|"
+	measureReqs    = "requests"
+	measureBytes   = "bytes"
+)
+
+// Service meters requests per principal. Counts are buffered in Counters
+// and flushed to the repository periodically, so metering costs no
+// database write per request.
+type Service interface {
+	// Allow counts a request against the principal's monthly quota. The
+	// quota is nil when the principal's kind has none. Rejected requests
+	// are not counted.
+	Allow(ctx context.Context, principal usage.Principal) (*usage.Quota, error)
+	// Record adds a request to endpoint, a route pattern, and the bytes
+	// sent in response to the principal's usage
+	Record(ctx context.Context, principal usage.Principal, endpoint string, bytes int64) error
+	// Flush moves the buffered counts to the repository and returns how
+	// many counters it wrote
+	Flush(ctx context.Context) (int, error)
+	// StartFlusher flushes every interval until ctx is done, then once more
+	StartFlusher(ctx context.Context, interval time.Duration)
+	// GetUsage reports the stored usage in a range of days
+	GetUsage(ctx context.Context, req ReportRequest) (*Report, error)
+}
+
+// ReportRequest holds the raw query of a usage report. From and To are
+// dates (YYYY-MM-DD), inclusive; they default to the current month so far.
+type ReportRequest struct {
+	From        string
+	To          string
+	Kind        string
+	PrincipalID string
+	// Top is how many endpoints to list per principal
+	Top int
+}
+
+type Report struct {
+	From          string            `json:"from"`
+	To            string            `json:"to"`
+	TotalRequests int64             `json:"total_requests"`
+	TotalBytes    int64             `json:"total_bytes"`
+	Principals    []*PrincipalUsage `json:"principals"`
+	Counters      []*usage.Counter  `json:"counters"`
+}
+
+// PrincipalUsage sums a principal's usage over a report's range
+type PrincipalUsage struct {
+	usage.Principal
+	Requests     int64            `json:"requests"`
+	Bytes        int64            `json:"bytes"`
+	TopEndpoints []*EndpointUsage `json:"top_endpoints"`
+}
+
+type EndpointUsage struct {
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+}
+
+type usageService struct {
+	repo         usage.Repository
+	counters     cache.Counters
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+	quotas       map[usage.PrincipalKind]int64
+}
+
+// Option configures optional settings of the usage service
+type Option func(*usageService)
+
+// WithMonthlyQuota caps the requests each principal of kind may make per
+// calendar month (UTC). A limit of 0 or less removes the cap.
+func WithMonthlyQuota(kind usage.PrincipalKind, limit int64) Option {
+	return func(s *usageService) {
+		if limit <= 0 {
+			delete(s.quotas, kind)
+			return
+		}
+		s.quotas[kind] = limit
+	}
+}
+
+func NewUsageService(
+	repo usage.Repository,
+	counters cache.Counters,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+	opts ...Option,
+) Service {
+	s := &usageService{
+		repo:         repo,
+		counters:     counters,
+		timeProvider: timeProvider,
+		logger:       logger,
+		quotas:       make(map[usage.PrincipalKind]int64),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *usageService) Allow(ctx context.Context, principal usage.Principal) (*usage.Quota, error) {
+	limit, ok := s.quotas[principal.Kind]
+	if !ok {
+		return nil, nil
+	}
+
+	now := s.timeProvider.Now()
+	key := fmt.Sprintf(monthKeyFormat, principal.Kind, principal.ID, now.UTC().Format("2006-01"))
+	used, err := s.counters.IncrBy(ctx, key, 1, monthKeyTTL)
+	if err != nil {
+		s.logger.Error("Failed to count request against quota", "error", err, "kind", principal.Kind, "principal_id", principal.ID)
+		return nil, errors.NewInternalError("Failed to check quota")
+	}
+	if used == 1 {
+		// A new counter, at the start of the month or after Redis lost it,
+		// picks up what was already flushed for the month
+		stored, err := s.repo.CountRequests(ctx, principal, usage.MonthStart(now), usage.NextMonth(now))
+		if err != nil {
+			s.logger.Error("Failed to count stored requests", "error", err, "kind", principal.Kind, "principal_id", principal.ID)
+		} else if stored > 0 {
+			if used, err = s.counters.IncrBy(ctx, key, stored, monthKeyTTL); err != nil {
+				s.logger.Error("Failed to seed quota counter", "error", err, "kind", principal.Kind, "principal_id", principal.ID)
+				used = stored + 1
+			}
+		}
+	}
+
+	quota := &usage.Quota{Limit: limit, Used: used, ResetAt: usage.NextMonth(now)}
+	if quota.Exceeded() {
+		if _, err := s.counters.IncrBy(ctx, key, -1, monthKeyTTL); err != nil {
+			s.logger.Error("Failed to uncount rejected request", "error", err, "kind", principal.Kind, "principal_id", principal.ID)
+		}
+	}
+	return quota, nil
+}
+
+func (s *usageService) Record(ctx context.Context, principal usage.Principal, endpoint string, bytes int64) error {
+	field := strings.Join([]string{string(principal.Kind), principal.ID, s.timeProvider.Now().UTC().Format(time.DateOnly), endpoint}, fieldSeparator)
+	err := s.counters.HIncrBy(ctx, pendingKey, map[string]int64{
+		field + fieldSeparator + measureReqs:  1,
+		field + fieldSeparator + measureBytes: bytes,
+	})
+	if err != nil {
+		s.logger.Error("Failed to record usage", "error", err, "kind", principal.Kind, "principal_id", principal.ID, "endpoint", endpoint)
+		return errors.NewInternalError("Failed to record usage")
+	}
+	return nil
+}
+
+func (s *usageService) Flush(ctx context.Context) (int, error) {
+	pending, err := s.counters.HTake(ctx, pendingKey)
+	if err != nil {
+		s.logger.Error("Failed to take buffered usage", "error", err)
+		return 0, errors.NewInternalError("Failed to flush usage")
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	byField := make(map[string]*usage.Counter)
+	for field, n := range pending {
+		counter, measure, err := parseField(field)
+		if err != nil {
+			s.logger.Warn("Dropping malformed usage field", "field", field, "error", err)
+			continue
+		}
+		key := strings.TrimSuffix(field, fieldSeparator+measure)
+		if existing, ok := byField[key]; ok {
+			counter = existing
+		} else {
+			byField[key] = counter
+		}
+		if measure == measureReqs {
+			counter.Requests += n
+		} else {
+			counter.Bytes += n
+		}
+	}
+	counters := make([]*usage.Counter, 0, len(byField))
+	for _, counter := range byField {
+		counters = append(counters, counter)
+	}
+
+	if err := s.repo.Add(ctx, counters); err != nil {
+		// Put the counts back so the next flush retries them
+		if restoreErr := s.counters.HIncrBy(context.WithoutCancel(ctx), pendingKey, pending); restoreErr != nil {
+			s.logger.Error("Lost buffered usage", "error", restoreErr, "counters", len(counters))
+		}
+		s.logger.Error("Failed to store usage", "error", err, "counters", len(counters))
+		return 0, errors.NewInternalError("Failed to flush usage")
+	}
+	return len(counters), nil
+}
+
+// parseField splits a pending field into its counter and measure
+func parseField(field string) (*usage.Counter, string, error) {
+	parts := strings.Split(field, fieldSeparator)
+	if len(parts) != 5 {
+		return nil, "", fmt.Errorf("expected 5 parts, got %d", len(parts))
+	}
+	day, err := time.Parse(time.DateOnly, parts[2])
+	if err != nil {
+		return nil, "", err
+	}
+	if measure := parts[4]; measure != measureReqs && measure != measureBytes {
+		return nil, "", fmt.Errorf("unknown measure %q", measure)
+	}
+	return &usage.Counter{Kind: usage.PrincipalKind(parts[0]), PrincipalID: parts[1], Day: day, Endpoint: parts[3]}, parts[4], nil
+}
+
+func (s *usageService) StartFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("Starting usage flusher", "interval", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Counts buffered in memory would be lost with the process
+			if _, err := s.Flush(context.WithoutCancel(ctx)); err != nil {
+				s.logger.Error("Final usage flush failed", "error", err)
+			}
+			s.logger.Info("Stopping usage flusher")
+			return
+		case <-ticker.C:
+			if n, err := s.Flush(ctx); err != nil {
+				s.logger.Error("Periodic usage flush failed", "error", err)
+			} else if n > 0 {
+				s.logger.Debug("Flushed usage", "counters", n)
+			}
+		}
+	}
+}
+
+func (s *usageService) GetUsage(ctx context.Context, req ReportRequest) (*Report, error) {
+	now := s.timeProvider.Now().UTC()
+	from := usage.MonthStart(now)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if req.From != "" {
+		if from, err = time.Parse(time.DateOnly, req.From); err != nil {
+			return nil, errors.NewBadRequestError("from must be a date like 2024-01-31")
+		}
+	}
+	if req.To != "" {
+		if to, err = time.Parse(time.DateOnly, req.To); err != nil {
+			return nil, errors.NewBadRequestError("to must be a date like 2024-01-31")
+		}
+	}
+	if to.Before(from) {
+		return nil, errors.NewBadRequestError("from must not be after to")
+	}
+	if to.Sub(from) >= MaxReportDays*24*time.Hour {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("a report covers at most %d days", MaxReportDays))
+	}
+	kind, err := usage.ParseKind(req.Kind)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err.Error())
+	}
+	top := req.Top
+	if top <= 0 {
+		top = DefaultTopEndpoints
+	}
+
+	counters, err := s.repo.List(ctx, usage.Filter{From: from, To: to, Kind: kind, PrincipalID: req.PrincipalID})
+	if err != nil {
+		s.logger.Error("Failed to list usage", "error", err)
+		return nil, errors.NewInternalError("Failed to get usage")
+	}
+
+	report := &Report{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), Counters: counters}
+	report.Principals = summarize(counters, top)
+	for _, p := range report.Principals {
+		report.TotalRequests += p.Requests
+		report.TotalBytes += p.Bytes
+	}
+	return report, nil
+}
+
+// summarize sums counters per principal, busiest principal first, listing
+// each one's top endpoints by requests
+func summarize(counters []*usage.Counter, top int) []*PrincipalUsage {
+	principals := make(map[usage.Principal]*PrincipalUsage)
+	endpoints := make(map[usage.Principal]map[string]*EndpointUsage)
+	for _, c := range counters {
+		principal := c.Principal()
+		p, ok := principals[principal]
+		if !ok {
+			p = &PrincipalUsage{Principal: principal}
+			principals[principal] = p
+			endpoints[principal] = make(map[string]*EndpointUsage)
+		}
+		p.Requests += c.Requests
+		p.Bytes += c.Bytes
+
+		e, ok := endpoints[principal][c.Endpoint]
+		if !ok {
+			e = &EndpointUsage{Endpoint: c.Endpoint}
+			endpoints[principal][c.Endpoint] = e
+		}
+		e.Requests += c.Requests
+		e.Bytes += c.Bytes
+	}
+
+	summary := make([]*PrincipalUsage, 0, len(principals))
+	for principal, p := range principals {
+		for _, e := range endpoints[principal] {
+			p.TopEndpoints = append(p.TopEndpoints, e)
+		}
+		sort.Slice(p.TopEndpoints, func(i, j int) bool {
+			a, b := p.TopEndpoints[i], p.TopEndpoints[j]
+			if a.Requests != b.Requests {
+				return a.Requests > b.Requests
+			}
+			return a.Endpoint < b.Endpoint
+		})
+		if len(p.TopEndpoints) > top {
+			p.TopEndpoints = p.TopEndpoints[:top]
+		}
+		summary = append(summary, p)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		a, b := summary[i], summary[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.ID < b.ID
+	})
+	return summary
+}
+
+// CSVColumns is the header row of WriteCSV
+var CSVColumns = []string{"day", "kind", "principal_id", "endpoint", "requests", "bytes"}
+
+// WriteCSV writes the counters one per row, for spreadsheets and billing
+func WriteCSV(w io.Writer, counters []*usage.Counter) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVColumns); err != nil {
+		return err
+	}
+	for _, c := range counters {
+		record := []string{
+			c.Day.UTC().Format(time.DateOnly),
+			string(c.Kind),
+			c.PrincipalID,
+			c.Endpoint,
+			strconv.FormatInt(c.Requests, 10),
+			strconv.FormatInt(c.Bytes, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
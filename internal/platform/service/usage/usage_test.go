@@ -0,0 +1,171 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/usage"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+var testNow = time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+var (
+	alice = usage.Principal{Kind: usage.KindUser, ID: "user-alice"}
+	acme  = usage.Principal{Kind: usage.KindAPIKey, ID: "key-acme"}
+)
+
+func setupTestService(opts ...Option) (Service, *mockUsageRepository, *cache.MemoryCounters) {
+	repo := new(mockUsageRepository)
+	counters := cache.NewMemoryCounters()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewUsageService(repo, counters, &mockTimeProvider{now: testNow}, logger, opts...), repo, counters
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	march, april := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no quota for the kind", func(t *testing.T) {
+		service, repo, _ := setupTestService(WithMonthlyQuota(usage.KindAPIKey, 10))
+		quota, err := service.Allow(ctx, alice)
+		require.NoError(t, err)
+		assert.Nil(t, quota)
+		repo.AssertNotCalled(t, "CountRequests", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("picks up flushed requests and stops at the limit", func(t *testing.T) {
+		service, repo, _ := setupTestService(WithMonthlyQuota(usage.KindAPIKey, 10))
+		repo.On("CountRequests", ctx, acme, march, april).Return(int64(8), nil).Once()
+
+		quota, err := service.Allow(ctx, acme)
+		require.NoError(t, err)
+		assert.Equal(t, &usage.Quota{Limit: 10, Used: 9, ResetAt: april}, quota)
+
+		quota, _ = service.Allow(ctx, acme)
+		assert.False(t, quota.Exceeded())
+		assert.Zero(t, quota.Remaining())
+
+		for i := 0; i < 2; i++ {
+			quota, _ = service.Allow(ctx, acme)
+			assert.True(t, quota.Exceeded())
+			assert.Equal(t, int64(11), quota.Used, "rejected requests are not counted")
+		}
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestRecordAndFlush(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	service, repo, counters := setupTestService()
+	require.NoError(t, service.Record(ctx, alice, "GET /api/v1/movies/{id}", 120))
+	require.NoError(t, service.Record(ctx, alice, "GET /api/v1/movies/{id}", 80))
+	require.NoError(t, service.Record(ctx, acme, "GET /partner/v1/movies/{id}/score", 60))
+
+	var stored []*usage.Counter
+	repo.On("Add", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).([]*usage.Counter)
+	}).Return(nil).Once()
+
+	n, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	sort.Slice(stored, func(i, j int) bool { return stored[i].Kind < stored[j].Kind })
+	assert.Equal(t, []*usage.Counter{
+		{Kind: usage.KindAPIKey, PrincipalID: "key-acme", Day: day, Endpoint: "GET /partner/v1/movies/{id}/score", Requests: 1, Bytes: 60},
+		{Kind: usage.KindUser, PrincipalID: "user-alice", Day: day, Endpoint: "GET /api/v1/movies/{id}", Requests: 2, Bytes: 200},
+	}, stored)
+
+	n, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing left to flush")
+
+	t.Run("keeps the counts when storing fails", func(t *testing.T) {
+		require.NoError(t, service.Record(ctx, alice, "GET /api/v1/movies", 10))
+		repo.On("Add", ctx, mock.Anything).Return(errors.New("connection refused")).Once()
+
+		_, err := service.Flush(ctx)
+		assertAppErrorCode(t, err, appErrors.CodeInternal)
+
+		pending, err := counters.HTake(ctx, pendingKey)
+		require.NoError(t, err)
+		assert.Len(t, pending, 2, "requests and bytes are buffered again")
+	})
+}
+
+func TestGetUsage(t *testing.T) {
+	ctx := context.Background()
+	march1, march15 := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	t.Run("summarizes the month so far", func(t *testing.T) {
+		service, repo, _ := setupTestService()
+		repo.On("List", ctx, usage.Filter{From: march1, To: march15}).Return([]*usage.Counter{
+			{Kind: usage.KindUser, PrincipalID: "user-alice", Day: march1, Endpoint: "GET /api/v1/movies", Requests: 2, Bytes: 20},
+			{Kind: usage.KindUser, PrincipalID: "user-alice", Day: march15, Endpoint: "GET /api/v1/movies/{id}", Requests: 3, Bytes: 30},
+			{Kind: usage.KindUser, PrincipalID: "user-alice", Day: march15, Endpoint: "GET /api/v1/movies", Requests: 2, Bytes: 20},
+			{Kind: usage.KindAPIKey, PrincipalID: "key-acme", Day: march15, Endpoint: "GET /partner/v1/movies/{id}/score", Requests: 9, Bytes: 90},
+		}, nil)
+
+		report, err := service.GetUsage(ctx, ReportRequest{Top: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-03-01", report.From)
+		assert.Equal(t, "2024-03-15", report.To)
+		assert.Equal(t, int64(16), report.TotalRequests)
+		assert.Equal(t, int64(160), report.TotalBytes)
+		require.Len(t, report.Principals, 2)
+		assert.Equal(t, acme, report.Principals[0].Principal, "busiest first")
+		assert.Equal(t, []*EndpointUsage{{Endpoint: "GET /api/v1/movies", Requests: 4, Bytes: 40}}, report.Principals[1].TopEndpoints)
+	})
+
+	t.Run("filters by principal", func(t *testing.T) {
+		service, repo, _ := setupTestService()
+		filter := usage.Filter{From: march1, To: march1, Kind: usage.KindAPIKey, PrincipalID: "key-acme"}
+		repo.On("List", ctx, filter).Return([]*usage.Counter{}, nil)
+
+		report, err := service.GetUsage(ctx, ReportRequest{From: "2024-03-01", To: "2024-03-01", Kind: "api_key", PrincipalID: "key-acme"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Principals)
+	})
+
+	for name, req := range map[string]ReportRequest{
+		"malformed from": {From: "03/01/2024"},
+		"reversed":       {From: "2024-03-10", To: "2024-03-01"},
+		"too long":       {From: "2023-01-01", To: "2024-03-01"},
+		"unknown kind":   {Kind: "device"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, repo, _ := setupTestService()
+			_, err := service.GetUsage(ctx, req)
+			assertAppErrorCode(t, err, appErrors.CodeBadRequest)
+			repo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, []*usage.Counter{
+		{Kind: usage.KindUser, PrincipalID: "user-alice", Day: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Endpoint: "GET /api/v1/movies", Requests: 2, Bytes: 20},
+	}))
+	assert.Equal(t, "day,kind,principal_id,endpoint,requests,bytes\n2024-03-01,user,user-alice,GET /api/v1/movies,2,20\n", buf.String())
+}
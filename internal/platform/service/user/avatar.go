@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/imaging"
+	"thermondo/internal/pkg/storage"
+	"time"
+)
+
+// DefaultAvatarURLTTL is how long signed avatar URLs stay valid
+const DefaultAvatarURLTTL = 15 * time.Minute
+
+// ErrAvatarsDisabled is returned by the avatar methods when the service was
+// built without WithAvatarStorage
+var ErrAvatarsDisabled = errors.New("avatar uploads are not configured")
+
+// WithAvatarStorage enables avatar uploads. Avatars are written to store and
+// their download URLs are valid for urlTTL (DefaultAvatarURLTTL when zero) if
+// the backend signs them.
+func WithAvatarStorage(store storage.Storage, urlTTL time.Duration) Option {
+	return func(s *userService) {
+		s.avatarStorage = store
+		if urlTTL > 0 {
+			s.avatarURLTTL = urlTTL
+		}
+	}
+}
+
+// AvatarPath is the stable address of one size of a user's avatar. It
+// redirects to the current upload, so it survives re-uploads and URL expiry.
+func AvatarPath(id users.UserID, size users.AvatarSize) string {
+	if size == users.AvatarStandard {
+		return fmt.Sprintf("/api/v1/users/%s/avatar", id)
+	}
+	return fmt.Sprintf("/api/v1/users/%s/avatar/%s", id, size)
+}
+
+// UploadAvatar decodes a JPEG or PNG image, stores a square crop of it in
+// every avatar size and makes it the user's avatar, removing the previous
+// one. It returns users.ErrUserNotFound, users.ErrInvalidAvatar or
+// imaging.ErrImageTooLarge for bad input.
+func (s *userService) UploadAvatar(ctx context.Context, id string, data []byte) (*users.User, error) {
+	if s.avatarStorage == nil {
+		return nil, ErrAvatarsDisabled
+	}
+
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrImageTooLarge) {
+			return nil, err
+		}
+		return nil, users.ErrInvalidAvatar
+	}
+
+	variants := make([]imaging.Variant, len(users.AvatarSizes))
+	for i, size := range users.AvatarSizes {
+		variants[i] = imaging.Variant{Name: string(size), Width: size.Width(), Square: true}
+	}
+	renditions, err := imaging.Renditions(img, variants)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resize avatar: %w", err)
+	}
+
+	// Every upload gets a fresh prefix so cached copies of the old avatar
+	// are never served under the new one's URL
+	prefix := fmt.Sprintf("avatars/%s/%s", user.ID, s.idGenerator.Generate())
+	for _, rendition := range renditions {
+		key := users.AvatarObjectKey(prefix, users.AvatarSize(rendition.Name))
+		if err := s.avatarStorage.Put(ctx, key, rendition.Data, "image/jpeg"); err != nil {
+			s.deleteAvatarObjects(ctx, &prefix)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	now := s.timeProvider.Now()
+	previous, err := s.userRepository.UpdateAvatar(ctx, user.ID, &prefix, now)
+	if err != nil {
+		s.deleteAvatarObjects(ctx, &prefix)
+		return nil, err
+	}
+	s.deleteAvatarObjects(ctx, previous)
+
+	user.AvatarKey = &prefix
+	user.UpdatedAt = now
+	return user, nil
+}
+
+// DeleteAvatar removes the user's avatar. It returns users.ErrUserNotFound or
+// users.ErrNoAvatar.
+func (s *userService) DeleteAvatar(ctx context.Context, id string) error {
+	if s.avatarStorage == nil {
+		return ErrAvatarsDisabled
+	}
+
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.AvatarKey == nil {
+		return users.ErrNoAvatar
+	}
+
+	previous, err := s.userRepository.UpdateAvatar(ctx, user.ID, nil, s.timeProvider.Now())
+	if err != nil {
+		return err
+	}
+	s.deleteAvatarObjects(ctx, previous)
+	return nil
+}
+
+// AvatarURL returns a download URL for one size of the user's avatar. It
+// returns users.ErrUserNotFound, users.ErrNoAvatar or
+// users.ErrInvalidAvatarSize.
+func (s *userService) AvatarURL(ctx context.Context, id, size string) (string, error) {
+	avatarSize, err := users.ParseAvatarSize(size)
+	if err != nil {
+		return "", err
+	}
+	if s.avatarStorage == nil {
+		return "", users.ErrNoAvatar
+	}
+
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if user.AvatarKey == nil {
+		return "", users.ErrNoAvatar
+	}
+
+	return s.avatarStorage.URL(ctx, users.AvatarObjectKey(*user.AvatarKey, avatarSize), s.avatarURLTTL)
+}
+
+func (s *userService) findUser(ctx context.Context, id string) (*users.User, error) {
+	user, err := s.userRepository.FindByID(ctx, users.UserID(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return nil, users.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// deleteAvatarObjects removes every size stored under prefix on a best-effort
+// basis; a leftover object only costs storage
+func (s *userService) deleteAvatarObjects(ctx context.Context, prefix *string) {
+	if prefix == nil {
+		return
+	}
+	for _, size := range users.AvatarSizes {
+		key := users.AvatarObjectKey(*prefix, size)
+		if err := s.avatarStorage.Delete(ctx, key); err != nil {
+			slog.Warn("Failed to delete avatar object", "error", err, "key", key)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAvatar(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var avatar bytes.Buffer
+	require.NoError(t, png.Encode(&avatar, image.NewRGBA(image.Rect(0, 0, 400, 300))))
+
+	setup := func(t *testing.T) (*MockUserRepository, storage.Storage, UserService) {
+		repo := new(MockUserRepository)
+		idGen := new(MockIDGenerator)
+		idGen.On("Generate").Return("upload-1")
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+
+		store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+		require.NoError(t, err)
+
+		service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), idGen, timeProv, new(mockCache), testLogger,
+			WithAvatarStorage(store, time.Minute))
+		return repo, store, service
+	}
+
+	t.Run("stores square sizes and removes the previous avatar", func(t *testing.T) {
+		repo, store, service := setup(t)
+		repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id"}, nil)
+
+		oldPrefix := "avatars/test-id/old"
+		for _, size := range users.AvatarSizes {
+			require.NoError(t, store.Put(ctx, users.AvatarObjectKey(oldPrefix, size), []byte("old"), "image/jpeg"))
+		}
+		repo.On("UpdateAvatar", mock.Anything, users.UserID("test-id"), mock.MatchedBy(func(key *string) bool {
+			return key != nil && *key == "avatars/test-id/upload-1"
+		}), now).Return(&oldPrefix, nil)
+
+		user, err := service.UploadAvatar(ctx, "test-id", avatar.Bytes())
+
+		require.NoError(t, err)
+		require.NotNil(t, user.AvatarKey)
+		assert.Equal(t, "avatars/test-id/upload-1", *user.AvatarKey)
+
+		body, _, err := store.Get(ctx, "avatars/test-id/upload-1/thumbnail.jpg")
+		require.NoError(t, err)
+		thumbnail, _, err := image.Decode(body)
+		body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 64, 64), thumbnail.Bounds())
+
+		_, _, err = store.Get(ctx, users.AvatarObjectKey(oldPrefix, users.AvatarStandard))
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("rejects data that is not an image", func(t *testing.T) {
+		repo, _, service := setup(t)
+		repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id"}, nil)
+
+		_, err := service.UploadAvatar(ctx, "test-id", []byte("<svg/>"))
+
+		assert.ErrorIs(t, err, users.ErrInvalidAvatar)
+		repo.AssertNotCalled(t, "UpdateAvatar", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo, _, service := setup(t)
+		repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(nil, sql.ErrNoRows)
+
+		_, err := service.UploadAvatar(ctx, "test-id", avatar.Bytes())
+
+		assert.ErrorIs(t, err, users.ErrUserNotFound)
+	})
+
+	t.Run("without storage", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository), new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger)
+
+		_, err := service.UploadAvatar(ctx, "test-id", avatar.Bytes())
+
+		assert.ErrorIs(t, err, ErrAvatarsDisabled)
+	})
+}
+
+func TestDeleteAvatar(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	prefix := "avatars/test-id/upload-1"
+
+	repo := new(MockUserRepository)
+	timeProv := new(MockTimeProvider)
+	timeProv.On("Now").Return(now)
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+	require.NoError(t, err)
+	require.NoError(t, store.Put(ctx, users.AvatarObjectKey(prefix, users.AvatarStandard), []byte("jpeg"), "image/jpeg"))
+
+	service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), timeProv, new(mockCache), testLogger,
+		WithAvatarStorage(store, 0))
+
+	repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id", AvatarKey: &prefix}, nil)
+	repo.On("FindByID", mock.Anything, users.UserID("no-avatar")).Return(&users.User{ID: "no-avatar"}, nil)
+	repo.On("UpdateAvatar", mock.Anything, users.UserID("test-id"), (*string)(nil), now).Return(&prefix, nil)
+
+	require.NoError(t, service.DeleteAvatar(ctx, "test-id"))
+	_, _, err = store.Get(ctx, users.AvatarObjectKey(prefix, users.AvatarStandard))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	assert.ErrorIs(t, service.DeleteAvatar(ctx, "no-avatar"), users.ErrNoAvatar)
+}
+
+func TestAvatarURL(t *testing.T) {
+	ctx := context.Background()
+	prefix := "avatars/test-id/upload-1"
+
+	repo := new(MockUserRepository)
+	store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+	require.NoError(t, err)
+	service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger,
+		WithAvatarStorage(store, 0))
+
+	repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id", AvatarKey: &prefix}, nil)
+	repo.On("FindByID", mock.Anything, users.UserID("no-avatar")).Return(&users.User{ID: "no-avatar"}, nil)
+
+	url, err := service.AvatarURL(ctx, "test-id", "thumbnail")
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/media/avatars/test-id/upload-1/thumbnail.jpg", url)
+
+	_, err = service.AvatarURL(ctx, "no-avatar", "standard")
+	assert.ErrorIs(t, err, users.ErrNoAvatar)
+
+	_, err = service.AvatarURL(ctx, "test-id", "huge")
+	assert.ErrorIs(t, err, users.ErrInvalidAvatarSize)
+
+	assert.Equal(t, "/api/v1/users/test-id/avatar", AvatarPath("test-id", users.AvatarStandard))
+	assert.Equal(t, "/api/v1/users/test-id/avatar/thumbnail", AvatarPath("test-id", users.AvatarThumbnail))
+}
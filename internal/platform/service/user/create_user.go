@@ -2,7 +2,10 @@ package user
 
 import (
 	"context"
+	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/users"
+	pkgerrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
 	"thermondo/internal/pkg/password"
 )
 
@@ -18,15 +21,42 @@ type UserService interface {
 	CreateUser(ctx context.Context, user users.CreateUserRequest) (*users.User, error)
 	FindUserByID(ctx context.Context, id string) (*users.User, error)
 	FindUserByEmail(ctx context.Context, email string) (*users.User, error)
-	ListUsers(ctx context.Context, page, limit int) ([]*users.User, int, error)
+	ListUsers(ctx context.Context, filter users.ListFilter, page, limit int) ([]*users.User, int, error)
+	PatchUser(ctx context.Context, id string, req PatchUserRequest) (*users.User, error)
+
+	// Avatars
+	UploadAvatar(ctx context.Context, id string, data []byte) (*users.User, error)
+	DeleteAvatar(ctx context.Context, id string) error
+	AvatarURL(ctx context.Context, id, size string) (string, error)
 
 	// User profile
-	GetUserProfile(ctx context.Context, req UserProfileRequest) ([]*UserRatingWithMovie, *UserProfileStats, error)
+	GetUserProfile(ctx context.Context, req UserProfileRequest) ([]*UserRatingWithMovie, *UserProfileStats, int64, error)
 	GetUserStats(ctx context.Context, userID string) (*UserProfileStats, error)
+	GetYearInReview(ctx context.Context, userID string, year int) (*YearInReview, error)
 	InvalidateUserCache(ctx context.Context, userID string) error
+	// WarmUserCache precomputes the user's stats and first profile page
+	WarmUserCache(ctx context.Context, userID string) error
+
+	// MergeUsers merges another account of the same person into targetID
+	MergeUsers(ctx context.Context, sourceID, targetID string) (*users.MergeRecord, error)
+
+	// Settings
+	GetSettings(ctx context.Context, id string) (*users.Settings, error)
+	SaveSettings(ctx context.Context, id string, settings users.Settings) (*users.Settings, error)
+}
+
+// clientErrors are the errors of the user service a client causes; they are
+// logged as such rather than as failures
+var clientErrors = []error{
+	users.ErrInvalidEmail, users.ErrEmptyEmail, users.ErrEmptyFirstName, users.ErrEmptyLastName,
+	users.ErrEmptyPassword, users.ErrUserAlreadyExists, users.ErrUserNotFound, ErrNullField,
+	users.ErrMergeIntoSelf, users.ErrInvalidContentMode, users.ErrInvalidSortOrder, movies.ErrInvalidLocale,
 }
 
-func (s *userService) CreateUser(ctx context.Context, user users.CreateUserRequest) (*users.User, error) {
+func (s *userService) CreateUser(ctx context.Context, user users.CreateUserRequest) (_ *users.User, err error) {
+	op := logging.StartOp(ctx, s.logger, "user.create").Expect(clientErrors...)
+	defer op.End(&err)
+
 	existingUser, err := s.userRepository.FindByEmail(ctx, user.Email)
 	if err != nil {
 		return nil, err
@@ -56,10 +86,15 @@ func (s *userService) CreateUser(ctx context.Context, user users.CreateUserReque
 		return nil, err
 	}
 
+	if !s.emailPolicy.Permits(u.Email) {
+		return nil, pkgerrors.NewBadRequestError("Email domain is not allowed")
+	}
+
 	savedUser, err := s.userRepository.Create(ctx, u)
 	if err != nil {
 		return nil, err
 	}
+	op.With("user_id", savedUser.ID)
 
 	return savedUser, nil
 }
@@ -72,7 +107,7 @@ func (s *userService) FindUserByEmail(ctx context.Context, email string) (*users
 	return s.userRepository.FindByEmail(ctx, email)
 }
 
-func (s *userService) ListUsers(ctx context.Context, page, limit int) ([]*users.User, int, error) {
+func (s *userService) ListUsers(ctx context.Context, filter users.ListFilter, page, limit int) ([]*users.User, int, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -80,17 +115,20 @@ func (s *userService) ListUsers(ctx context.Context, page, limit int) ([]*users.
 		limit = 20
 	}
 
-	// Get total count for pagination info
-	total, err := s.userRepository.Count(ctx)
+	list, total, err := s.userRepository.List(ctx, filter, page, limit)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Get paginated users
-	users, err := s.userRepository.List(ctx, page, limit)
-	if err != nil {
-		return nil, 0, err
+	// The repository reads the total off the listed users, so a page beyond
+	// the last one comes back with a zero total; the admin listing still
+	// needs it to show how many pages there are
+	if len(list) == 0 && page > 1 {
+		total, err = s.userRepository.Count(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 
-	return users, total, nil
+	return list, total, nil
 }
@@ -1,15 +1,21 @@
 package user
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"testing"
 	"time"
 
 	"thermondo/internal/domain/users"
+	pkgerrors "thermondo/internal/pkg/errors"
+	"thermondo/internal/pkg/logging"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -139,7 +145,7 @@ func TestCreateUser(t *testing.T) {
 			mockTimeProvider := new(MockTimeProvider)
 			tt.setupMocks(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProvider)
 
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProvider, nil)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProvider, nil, testLogger)
 			user, err := service.CreateUser(context.Background(), tt.req)
 
 			if tt.expectedErrorFunc != nil {
@@ -172,3 +178,76 @@ func TestCreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateUserEmailDomainPolicy(t *testing.T) {
+	policy := users.EmailDomainPolicy{Blocked: users.DisposableEmailDomains}
+
+	t.Run("rejects blocked domains before saving", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockIDGen := new(MockIDGenerator)
+		mockTimeProvider := new(MockTimeProvider)
+		mockRepo.On("FindByEmail", mock.Anything, "bot@mailinator.com").Return(nil, nil)
+		mockIDGen.On("Generate").Return("test-id")
+		mockTimeProvider.On("Now").Return(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		service := NewUserService(mockRepo, new(MockRatingRepository), new(MockMovieRepository), mockIDGen, mockTimeProvider, nil, testLogger,
+			WithEmailDomainPolicy(policy))
+		user, err := service.CreateUser(context.Background(), users.CreateUserRequest{
+			FirstName: "Bot", LastName: "Farm", Email: "bot@mailinator.com", Password: "password123", Role: "user",
+		})
+
+		assert.Nil(t, user)
+		var appErr *pkgerrors.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, string(pkgerrors.CodeBadRequest), appErr.Code)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("admits other domains", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockIDGen := new(MockIDGenerator)
+		mockTimeProvider := new(MockTimeProvider)
+		mockRepo.On("FindByEmail", mock.Anything, "jane@example.com").Return(nil, nil)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(&users.User{ID: "test-id"}, nil)
+		mockIDGen.On("Generate").Return("test-id")
+		mockTimeProvider.On("Now").Return(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+
+		service := NewUserService(mockRepo, new(MockRatingRepository), new(MockMovieRepository), mockIDGen, mockTimeProvider, nil, testLogger,
+			WithEmailDomainPolicy(policy))
+		_, err := service.CreateUser(context.Background(), users.CreateUserRequest{
+			FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Password: "password123", Role: "user",
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateUser_Logging(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("FindByEmail", mock.Anything, "taken@example.com").Return(&users.User{ID: "existing"}, nil)
+	mockRepo.On("FindByEmail", mock.Anything, "down@example.com").Return(nil, errors.New("connection refused"))
+	service := NewUserService(mockRepo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), nil, logger)
+
+	for _, email := range []string{"taken@example.com", "down@example.com"} {
+		_, err := service.CreateUser(context.Background(), users.CreateUserRequest{
+			FirstName: "Jane", LastName: "Doe", Email: email, Password: "password123", Role: "user",
+		})
+		require.Error(t, err)
+	}
+
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(line, &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, "INFO", records[0]["level"], "a taken email is the client's error")
+	assert.Equal(t, "user.create", records[0][logging.OperationKey])
+	assert.Equal(t, users.ErrUserAlreadyExists.Error(), records[0][logging.ErrorKey])
+	assert.Equal(t, "ERROR", records[1]["level"])
+	assert.Equal(t, "connection refused", records[1][logging.ErrorKey])
+}
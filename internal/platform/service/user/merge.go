@@ -0,0 +1,63 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/logging"
+)
+
+// ErrMergeDisabled is returned by MergeUsers when the service was built
+// without WithMergeRepository
+var ErrMergeDisabled = errors.New("account merging is not configured")
+
+// WithMergeRepository enables merging a person's accounts
+func WithMergeRepository(repo users.MergeRepository) Option {
+	return func(s *userService) {
+		s.mergeRepo = repo
+	}
+}
+
+// MergeUsers merges sourceID, another account of the person who owns
+// targetID, into targetID and deletes it; callers must have made sure the
+// same person owns both. It returns users.ErrMergeIntoSelf or
+// users.ErrUserNotFound.
+func (s *userService) MergeUsers(ctx context.Context, sourceID, targetID string) (_ *users.MergeRecord, err error) {
+	op := logging.StartOp(ctx, s.logger, "user.merge", "source_id", sourceID, "target_id", targetID).Expect(clientErrors...)
+	defer op.End(&err)
+
+	if s.mergeRepo == nil {
+		return nil, ErrMergeDisabled
+	}
+	if sourceID == targetID {
+		return nil, users.ErrMergeIntoSelf
+	}
+
+	source, err := s.findUser(ctx, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.findUser(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.mergeRepo.Merge(ctx, source.ID, target.ID, s.timeProvider.Now())
+	if err != nil {
+		return nil, err
+	}
+	op.With("ratings_moved", record.RatingsMoved, "ratings_dropped", record.RatingsDropped, "identities_moved", record.IdentitiesMoved)
+
+	// The merge went around the repository that keeps the cache current
+	for _, id := range []string{sourceID, targetID} {
+		if err := s.InvalidateUserCache(ctx, id); err != nil {
+			s.logger.Warn("Failed to invalidate merged user cache", "error", err, "user_id", id)
+		}
+	}
+	// The target kept its own avatar, so the source's is not used anymore
+	if s.avatarStorage != nil && target.AvatarKey != nil {
+		s.deleteAvatarObjects(ctx, source.AvatarKey)
+	}
+
+	return record, nil
+}
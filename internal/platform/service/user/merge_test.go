@@ -0,0 +1,84 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeUsers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T) (*MockUserRepository, *MockMergeRepository, *mockCache, storage.Storage, UserService) {
+		repo := new(MockUserRepository)
+		mergeRepo := new(MockMergeRepository)
+		c := new(mockCache)
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+		store, err := storage.NewLocalStorage(storage.LocalConfig{Root: t.TempDir(), BaseURL: "/api/v1/media"})
+		require.NoError(t, err)
+
+		service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), timeProv, c, testLogger,
+			WithAvatarStorage(store, time.Minute),
+			WithMergeRepository(mergeRepo),
+		)
+		return repo, mergeRepo, c, store, service
+	}
+
+	t.Run("merges and clears both users' caches", func(t *testing.T) {
+		repo, mergeRepo, c, store, service := setup(t)
+		sourceAvatar, targetAvatar := "avatars/source/1", "avatars/target/1"
+		require.NoError(t, store.Put(ctx, users.AvatarObjectKey(sourceAvatar, users.AvatarStandard), []byte("source"), "image/jpeg"))
+		repo.On("FindByID", mock.Anything, users.UserID("source")).Return(&users.User{ID: "source", AvatarKey: &sourceAvatar}, nil)
+		repo.On("FindByID", mock.Anything, users.UserID("target")).Return(&users.User{ID: "target", AvatarKey: &targetAvatar}, nil)
+		record := &users.MergeRecord{ID: 1, SourceID: "source", TargetID: "target", RatingsMoved: 3}
+		mergeRepo.On("Merge", mock.Anything, users.UserID("source"), users.UserID("target"), now).Return(record, nil)
+		c.On("InvalidateTags", mock.Anything, []string{cache.UserTag("source")}).Return(nil)
+		c.On("InvalidateTags", mock.Anything, []string{cache.UserTag("target")}).Return(nil)
+
+		merged, err := service.MergeUsers(ctx, "source", "target")
+
+		require.NoError(t, err)
+		assert.Equal(t, record, merged)
+		c.AssertExpectations(t)
+		_, _, err = store.Get(ctx, users.AvatarObjectKey(sourceAvatar, users.AvatarStandard))
+		assert.ErrorIs(t, err, storage.ErrNotFound, "the target kept its own avatar")
+	})
+
+	t.Run("rejects merging an account into itself", func(t *testing.T) {
+		_, mergeRepo, _, _, service := setup(t)
+
+		_, err := service.MergeUsers(ctx, "target", "target")
+
+		assert.ErrorIs(t, err, users.ErrMergeIntoSelf)
+		mergeRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo, mergeRepo, _, _, service := setup(t)
+		repo.On("FindByID", mock.Anything, users.UserID("source")).Return(nil, sql.ErrNoRows)
+
+		_, err := service.MergeUsers(ctx, "source", "target")
+
+		assert.ErrorIs(t, err, users.ErrUserNotFound)
+		mergeRepo.AssertNotCalled(t, "Merge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository), new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger)
+
+		_, err := service.MergeUsers(ctx, "source", "target")
+
+		assert.ErrorIs(t, err, ErrMergeDisabled)
+	})
+}
@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"database/sql"
+	"thermondo/internal/domain/lists"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
@@ -26,6 +27,40 @@ func (m *MockUserRepository) Create(ctx context.Context, user *users.User) (*use
 	return u, args.Error(1)
 }
 
+func (m *MockUserRepository) Update(ctx context.Context, user *users.User) (*users.User, error) {
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateAvatar(ctx context.Context, id users.UserID, avatarKey *string, updatedAt time.Time) (*string, error) {
+	args := m.Called(ctx, id, avatarKey, updatedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*string), args.Error(1)
+}
+
+func (m *MockUserRepository) SetShadowBanned(ctx context.Context, id users.UserID, banned bool, updatedAt time.Time) error {
+	args := m.Called(ctx, id, banned, updatedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetCritic(ctx context.Context, id users.UserID, critic bool, updatedAt time.Time) error {
+	args := m.Called(ctx, id, critic, updatedAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) BulkUpdate(ctx context.Context, update users.BulkUpdate) ([]users.BulkResult, error) {
+	args := m.Called(ctx, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.BulkResult), args.Error(1)
+}
+
 func (m *MockUserRepository) FindByID(ctx context.Context, id users.UserID) (*users.User, error) {
 	args := m.Called(ctx, id)
 	var u *users.User
@@ -44,16 +79,16 @@ func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*us
 	return u, args.Error(1)
 }
 
-func (m *MockUserRepository) List(ctx context.Context, page, limit int) ([]*users.User, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockUserRepository) List(ctx context.Context, filter users.ListFilter, page, limit int) ([]*users.User, int, error) {
+	args := m.Called(ctx, filter, page, limit)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.Int(1), args.Error(2)
 	}
-	return args.Get(0).([]*users.User), args.Error(1)
+	return args.Get(0).([]*users.User), args.Int(1), args.Error(2)
 }
 
-func (m *MockUserRepository) Count(ctx context.Context) (int, error) {
-	args := m.Called(ctx)
+func (m *MockUserRepository) Count(ctx context.Context, filter users.ListFilter) (int, error) {
+	args := m.Called(ctx, filter)
 	return args.Int(0), args.Error(1)
 }
 
@@ -90,6 +125,14 @@ func (m *MockRatingRepository) GetByUser(ctx context.Context, userID users.UserI
 	return args.Get(0).([]*rating.Rating), args.Error(1)
 }
 
+func (m *MockRatingRepository) GetUserRatingsWithMovies(ctx context.Context, userID users.UserID, filter rating.UserRatingFilter, opts ...rating.SearchOption) ([]*rating.RatingWithMovie, int64, error) {
+	args := m.Called(ctx, userID, filter, opts)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.RatingWithMovie), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockRatingRepository) GetByUserAndMovie(ctx context.Context, userID users.UserID, movieID movies.MovieID) (*rating.Rating, error) {
 	args := m.Called(ctx, userID, movieID)
 	if args.Get(0) == nil {
@@ -119,19 +162,86 @@ func (m *MockRatingRepository) Count(ctx context.Context) (int64, error) {
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockRatingRepository) Delete(ctx context.Context, id rating.RatingID) error {
-	args := m.Called(ctx, id)
+func (m *MockRatingRepository) Delete(ctx context.Context, id rating.RatingID, deletedAt time.Time) error {
+	args := m.Called(ctx, id, deletedAt)
 	return args.Error(0)
 }
 
+func (m *MockRatingRepository) GetByIDIncludingDeleted(ctx context.Context, id rating.RatingID) (*rating.Rating, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
+func (m *MockRatingRepository) Restore(ctx context.Context, id rating.RatingID, deletedSince time.Time) (*rating.Rating, error) {
+	args := m.Called(ctx, id, deletedSince)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.Rating), args.Error(1)
+}
+
 func (m *MockRatingRepository) Exists(ctx context.Context, id rating.RatingID) (bool, error) {
 	args := m.Called(ctx, id)
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRatingRepository) GetGlobalAverageRating(ctx context.Context) (float64, error) {
+func (m *MockRatingRepository) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]movies.MovieID), args.Error(1)
+}
+
+func (m *MockRatingRepository) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]users.UserID), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetGlobalStats(ctx context.Context) (*rating.GlobalStats, error) {
 	args := m.Called(ctx)
-	return args.Get(0).(float64), args.Error(1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *MockRatingRepository) RefreshGlobalStats(ctx context.Context, now time.Time) (*rating.GlobalStats, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.GlobalStats), args.Error(1)
+}
+
+func (m *MockRatingRepository) GetRaterGroups(ctx context.Context, movieID movies.MovieID, asOf time.Time, config users.TrustConfig) ([]*rating.RaterGroup, error) {
+	args := m.Called(ctx, movieID, asOf, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*rating.RaterGroup), args.Error(1)
+}
+
+func (m *MockRatingRepository) SearchReviews(ctx context.Context, query string, filter rating.ReviewFilter, options ...rating.SearchOption) ([]*rating.ReviewMatch, int64, error) {
+	args := m.Called(ctx, query, filter, options)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*rating.ReviewMatch), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRatingRepository) GetRatingActivity(ctx context.Context, movieID movies.MovieID, baselineStart, windowStart time.Time) (*rating.RatingActivity, error) {
+	args := m.Called(ctx, movieID, baselineStart, windowStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*rating.RatingActivity), args.Error(1)
 }
 
 func (m *MockRatingRepository) GetMovieStats(ctx context.Context, movieID movies.MovieID) (*rating.MovieRatingStats, error) {
@@ -237,6 +347,14 @@ func (m *MockMovieRepository) Save(ctx context.Context, movie *movies.Movie) (*m
 	return args.Get(0).(*movies.Movie), args.Error(1)
 }
 
+func (m *MockMovieRepository) Update(ctx context.Context, movie *movies.Movie) (*movies.Movie, error) {
+	args := m.Called(ctx, movie)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
 func (m *MockMovieRepository) ScanMovies(rows *sql.Rows) ([]*movies.Movie, error) {
 	args := m.Called(rows)
 	if args.Get(0) == nil {
@@ -245,6 +363,51 @@ func (m *MockMovieRepository) ScanMovies(rows *sql.Rows) ([]*movies.Movie, error
 	return args.Get(0).([]*movies.Movie), args.Error(1)
 }
 
+func (m *MockMovieRepository) Search(ctx context.Context, filter movies.SearchFilter, opts ...movies.SearchOption) ([]*movies.Movie, int64, error) {
+	args := m.Called(ctx, filter, opts)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*movies.Movie), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMovieRepository) CountSearch(ctx context.Context, filter movies.SearchFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMovieRepository) GetSearchFacets(ctx context.Context, filter movies.SearchFilter) (*movies.SearchFacets, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.SearchFacets), args.Error(1)
+}
+
+func (m *MockMovieRepository) Suggest(ctx context.Context, query string, limit int) ([]*movies.Suggestion, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Suggestion), args.Error(1)
+}
+
+func (m *MockMovieRepository) FindPotentialDuplicates(ctx context.Context, movie *movies.Movie) ([]*movies.Movie, error) {
+	args := m.Called(ctx, movie)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
+func (m *MockMovieRepository) Random(ctx context.Context, filter movies.SearchFilter, limit int) ([]*movies.Movie, error) {
+	args := m.Called(ctx, filter, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*movies.Movie), args.Error(1)
+}
+
 func (m *MockMovieRepository) SearchByTitle(ctx context.Context, title string, opts ...movies.SearchOption) ([]*movies.Movie, error) {
 	args := m.Called(ctx, title, opts)
 	if args.Get(0) == nil {
@@ -282,17 +445,17 @@ func (m *MockUserService) FindUserByEmail(ctx context.Context, email string) (*u
 	return args.Get(0).(*users.User), args.Error(1)
 }
 
-func (m *MockUserService) ListUsers(ctx context.Context, page, limit int) ([]*users.User, int, error) {
-	args := m.Called(ctx, page, limit)
+func (m *MockUserService) ListUsers(ctx context.Context, filter users.ListFilter, page, limit int) ([]*users.User, int, error) {
+	args := m.Called(ctx, filter, page, limit)
 	return args.Get(0).([]*users.User), args.Int(1), args.Error(2)
 }
 
-func (m *MockUserService) GetUserProfile(ctx context.Context, req UserProfileRequest) ([]*UserRatingWithMovie, *UserProfileStats, error) {
+func (m *MockUserService) GetUserProfile(ctx context.Context, req UserProfileRequest) ([]*UserRatingWithMovie, *UserProfileStats, int64, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
-		return nil, nil, args.Error(2)
+		return nil, nil, 0, args.Error(3)
 	}
-	return args.Get(0).([]*UserRatingWithMovie), args.Get(1).(*UserProfileStats), args.Error(2)
+	return args.Get(0).([]*UserRatingWithMovie), args.Get(1).(*UserProfileStats), args.Get(2).(int64), args.Error(3)
 }
 
 func (m *MockUserService) GetUserStats(ctx context.Context, userID string) (*UserProfileStats, error) {
@@ -303,6 +466,14 @@ func (m *MockUserService) GetUserStats(ctx context.Context, userID string) (*Use
 	return args.Get(0).(*UserProfileStats), args.Error(1)
 }
 
+func (m *MockUserService) GetYearInReview(ctx context.Context, userID string, year int) (*YearInReview, error) {
+	args := m.Called(ctx, userID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*YearInReview), args.Error(1)
+}
+
 func (m *MockUserService) InvalidateUserCache(ctx context.Context, userID string) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
@@ -312,3 +483,70 @@ func (m *MockUserService) WarmUserCache(ctx context.Context, userID string) erro
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
+
+func (m *MockUserService) MergeUsers(ctx context.Context, sourceID, targetID string) (*users.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.MergeRecord), args.Error(1)
+}
+
+func (m *MockUserService) GetSettings(ctx context.Context, id string) (*users.Settings, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Settings), args.Error(1)
+}
+
+func (m *MockUserService) SaveSettings(ctx context.Context, id string, settings users.Settings) (*users.Settings, error) {
+	args := m.Called(ctx, id, settings)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Settings), args.Error(1)
+}
+
+// MockListRepository implements the list counts used by the user profile; the
+// embedded interface panics for anything else
+type MockListRepository struct {
+	lists.Repository
+	mock.Mock
+}
+
+func (m *MockListRepository) CountByUser(ctx context.Context, userID users.UserID) (lists.Counts, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(lists.Counts), args.Error(1)
+}
+
+// MockMergeRepository is a mock implementation of users.MergeRepository
+type MockMergeRepository struct {
+	mock.Mock
+}
+
+func (m *MockMergeRepository) Merge(ctx context.Context, sourceID, targetID users.UserID, mergedAt time.Time) (*users.MergeRecord, error) {
+	args := m.Called(ctx, sourceID, targetID, mergedAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.MergeRecord), args.Error(1)
+}
+
+// MockSettingsRepository is a mock implementation of users.SettingsRepository
+type MockSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingsRepository) Get(ctx context.Context, userID users.UserID) (*users.Settings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*users.Settings), args.Error(1)
+}
+
+func (m *MockSettingsRepository) Save(ctx context.Context, settings *users.Settings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
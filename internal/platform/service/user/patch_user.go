@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/mergepatch"
+)
+
+// PatchUserRequest is a JSON Merge Patch (RFC 7396) of a user's profile. All
+// patchable attributes are required, so null is rejected for each of them.
+// The password, role and active flag cannot be patched.
+type PatchUserRequest struct {
+	FirstName mergepatch.Field[string] `json:"first_name"`
+	LastName  mergepatch.Field[string] `json:"last_name"`
+	Email     mergepatch.Field[string] `json:"email"`
+	// ContentMode is "standard" or "kids"
+	ContentMode mergepatch.Field[string] `json:"content_mode"`
+}
+
+// ErrNullField wraps a null sent for an attribute that cannot be cleared
+var ErrNullField = errors.New("cannot be null")
+
+// PatchUser applies a merge patch to the user's profile. It returns
+// users.ErrUserNotFound, users.ErrUserAlreadyExists when the new email is
+// taken, ErrNullField, or a users validation error.
+func (s *userService) PatchUser(ctx context.Context, id string, req PatchUserRequest) (_ *users.User, err error) {
+	defer logging.StartOp(ctx, s.logger, "user.patch", "user_id", id).Expect(clientErrors...).End(&err)
+
+	user, err := s.userRepository.FindByID(ctx, users.UserID(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return nil, users.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previousEmail := user.Email
+	contentMode := string(user.ContentMode)
+	for _, field := range []struct {
+		name string
+		null bool
+	}{
+		{"first_name", req.FirstName.ApplyTo(&user.FirstName)},
+		{"last_name", req.LastName.ApplyTo(&user.LastName)},
+		{"email", req.Email.ApplyTo(&user.Email)},
+		{"content_mode", req.ContentMode.ApplyTo(&contentMode)},
+	} {
+		if field.null {
+			return nil, fmt.Errorf("%s %w", field.name, ErrNullField)
+		}
+	}
+
+	user.FirstName = strings.TrimSpace(user.FirstName)
+	user.LastName = strings.TrimSpace(user.LastName)
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+
+	if err := user.ValidateProfile(); err != nil {
+		return nil, err
+	}
+	if req.ContentMode.Set {
+		if user.ContentMode, err = users.ParseContentMode(contentMode); err != nil {
+			return nil, err
+		}
+	}
+	if user.Email != previousEmail {
+		existing, err := s.userRepository.FindByEmail(ctx, user.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != user.ID {
+			return nil, users.ErrUserAlreadyExists
+		}
+	}
+
+	user.UpdatedAt = s.timeProvider.Now()
+	return s.userRepository.Update(ctx, user)
+}
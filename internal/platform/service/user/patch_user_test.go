@@ -0,0 +1,120 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/mergepatch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchUser(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	existingUser := func() *users.User {
+		return &users.User{ID: "test-id", FirstName: "John", LastName: "Doe", Email: "john@example.com", Role: users.RoleUser, IsActive: true}
+	}
+
+	tests := []struct {
+		name          string
+		req           PatchUserRequest
+		setupMocks    func(*MockUserRepository)
+		expectedError error
+		validate      func(*testing.T, *users.User)
+	}{
+		{
+			name: "changes only the patched fields",
+			req:  PatchUserRequest{LastName: mergepatch.Value(" Smith ")},
+			setupMocks: func(repo *MockUserRepository) {
+				user := existingUser()
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(user, nil)
+				// The patch is applied to the loaded user, so Update echoes it back
+				repo.On("Update", mock.Anything, user).Return(user, nil)
+			},
+			validate: func(t *testing.T, u *users.User) {
+				assert.Equal(t, "John", u.FirstName)
+				assert.Equal(t, "Smith", u.LastName)
+				assert.Equal(t, now, u.UpdatedAt)
+			},
+		},
+		{
+			name: "normalizes and checks a new email",
+			req:  PatchUserRequest{Email: mergepatch.Value("Jane@Example.com")},
+			setupMocks: func(repo *MockUserRepository) {
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(existingUser(), nil)
+				repo.On("FindByEmail", mock.Anything, "jane@example.com").Return(&users.User{ID: "other-id"}, nil)
+			},
+			expectedError: users.ErrUserAlreadyExists,
+		},
+		{
+			name: "rejects null",
+			req:  PatchUserRequest{FirstName: mergepatch.Null[string]()},
+			setupMocks: func(repo *MockUserRepository) {
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(existingUser(), nil)
+			},
+			expectedError: ErrNullField,
+		},
+		{
+			name: "rejects an invalid email",
+			req:  PatchUserRequest{Email: mergepatch.Value("not-an-email")},
+			setupMocks: func(repo *MockUserRepository) {
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(existingUser(), nil)
+			},
+			expectedError: users.ErrInvalidEmail,
+		},
+		{
+			name: "switches the profile to kids mode",
+			req:  PatchUserRequest{ContentMode: mergepatch.Value("Kids")},
+			setupMocks: func(repo *MockUserRepository) {
+				user := existingUser()
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(user, nil)
+				repo.On("Update", mock.Anything, user).Return(user, nil)
+			},
+			validate: func(t *testing.T, u *users.User) {
+				assert.Equal(t, users.ContentModeKids, u.ContentMode)
+			},
+		},
+		{
+			name: "rejects an unknown content mode",
+			req:  PatchUserRequest{ContentMode: mergepatch.Value("adult")},
+			setupMocks: func(repo *MockUserRepository) {
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(existingUser(), nil)
+			},
+			expectedError: users.ErrInvalidContentMode,
+		},
+		{
+			name: "unknown user",
+			req:  PatchUserRequest{LastName: mergepatch.Value("Smith")},
+			setupMocks: func(repo *MockUserRepository) {
+				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(nil, sql.ErrNoRows)
+			},
+			expectedError: users.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockUserRepository)
+			timeProv := new(MockTimeProvider)
+			timeProv.On("Now").Return(now)
+			tt.setupMocks(repo)
+
+			service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), timeProv, new(mockCache), testLogger)
+			user, err := service.PatchUser(context.Background(), "test-id", tt.req)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			tt.validate(t, user)
+			repo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/logging"
+)
+
+// ErrSettingsDisabled is returned by the settings methods when the service
+// was built without WithSettingsRepository
+var ErrSettingsDisabled = errors.New("user settings are not configured")
+
+// WithSettingsRepository enables user settings
+func WithSettingsRepository(repo users.SettingsRepository) Option {
+	return func(s *userService) {
+		s.settingsRepo = repo
+	}
+}
+
+// GetSettings returns the user's settings, with the defaults for those they
+// never saved. Other services read a user's locale, notification opt-ins
+// and sort orders from here. It returns users.ErrUserNotFound.
+func (s *userService) GetSettings(ctx context.Context, id string) (*users.Settings, error) {
+	if s.settingsRepo == nil {
+		return nil, ErrSettingsDisabled
+	}
+
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.settingsRepo.Get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = users.DefaultSettings(user.ID)
+	}
+	if user.ContentMode != "" {
+		settings.ContentMode = user.ContentMode
+	}
+	return settings, nil
+}
+
+// SaveSettings replaces the user's settings. It returns
+// users.ErrUserNotFound, or the validation errors of Settings.Normalize.
+func (s *userService) SaveSettings(ctx context.Context, id string, settings users.Settings) (_ *users.Settings, err error) {
+	op := logging.StartOp(ctx, s.logger, "user.settings.save", "user_id", id).Expect(clientErrors...)
+	defer op.End(&err)
+
+	if s.settingsRepo == nil {
+		return nil, ErrSettingsDisabled
+	}
+
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	settings.UserID = user.ID
+	if err := settings.Normalize(); err != nil {
+		return nil, err
+	}
+
+	now := s.timeProvider.Now()
+	// The content mode is the user's; updating it through the repository
+	// keeps the cached user current
+	if settings.ContentMode != user.ContentMode {
+		user.ContentMode = settings.ContentMode
+		user.UpdatedAt = now
+		if _, err := s.userRepository.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	settings.UpdatedAt = now
+	if err := s.settingsRepo.Save(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
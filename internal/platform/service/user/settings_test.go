@@ -0,0 +1,148 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSettings(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*MockUserRepository, *MockSettingsRepository, UserService) {
+		repo := new(MockUserRepository)
+		settingsRepo := new(MockSettingsRepository)
+		service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger,
+			WithSettingsRepository(settingsRepo),
+		)
+		return repo, settingsRepo, service
+	}
+
+	t.Run("defaults with the user's content mode", func(t *testing.T) {
+		repo, settingsRepo, service := setup()
+		repo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1", ContentMode: users.ContentModeKids}, nil)
+		settingsRepo.On("Get", mock.Anything, users.UserID("user-1")).Return(nil, nil)
+
+		settings, err := service.GetSettings(ctx, "user-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, users.DefaultLocale, settings.Locale)
+		assert.Equal(t, users.ContentModeKids, settings.ContentMode)
+		assert.Equal(t, "created_at", settings.SortOrders.Ratings.SortBy)
+	})
+
+	t.Run("stored", func(t *testing.T) {
+		repo, settingsRepo, service := setup()
+		repo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1", ContentMode: users.ContentModeStandard}, nil)
+		stored := users.DefaultSettings("user-1")
+		stored.Locale = "de"
+		settingsRepo.On("Get", mock.Anything, users.UserID("user-1")).Return(stored, nil)
+
+		settings, err := service.GetSettings(ctx, "user-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "de", settings.Locale)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo, settingsRepo, service := setup()
+		repo.On("FindByID", mock.Anything, users.UserID("missing")).Return(nil, sql.ErrNoRows)
+
+		_, err := service.GetSettings(ctx, "missing")
+
+		assert.ErrorIs(t, err, users.ErrUserNotFound)
+		settingsRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository), new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger)
+
+		_, err := service.GetSettings(ctx, "user-1")
+
+		assert.ErrorIs(t, err, ErrSettingsDisabled)
+	})
+}
+
+func TestSaveSettings(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	setup := func() (*MockUserRepository, *MockSettingsRepository, UserService) {
+		repo := new(MockUserRepository)
+		settingsRepo := new(MockSettingsRepository)
+		timeProv := new(MockTimeProvider)
+		timeProv.On("Now").Return(now)
+		service := NewUserService(repo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), timeProv, new(mockCache), testLogger,
+			WithSettingsRepository(settingsRepo),
+		)
+		return repo, settingsRepo, service
+	}
+
+	t.Run("normalizes and stores", func(t *testing.T) {
+		repo, settingsRepo, service := setup()
+		repo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1", ContentMode: users.ContentModeStandard}, nil)
+		settingsRepo.On("Save", mock.Anything, mock.AnythingOfType("*users.Settings")).Return(nil)
+
+		input := *users.DefaultSettings("")
+		input.Locale = "PT_br"
+		input.SortOrders.Movies = users.SortOrder{SortBy: "title", Order: "ASC"}
+		settings, err := service.SaveSettings(ctx, "user-1", input)
+
+		require.NoError(t, err)
+		assert.Equal(t, users.UserID("user-1"), settings.UserID)
+		assert.Equal(t, "pt-BR", settings.Locale)
+		assert.Equal(t, "asc", settings.SortOrders.Movies.Order)
+		assert.Equal(t, now, settings.UpdatedAt)
+		repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("updates the user's content mode", func(t *testing.T) {
+		repo, settingsRepo, service := setup()
+		repo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1", ContentMode: users.ContentModeStandard}, nil)
+		repo.On("Update", mock.Anything, mock.MatchedBy(func(u *users.User) bool {
+			return u.ContentMode == users.ContentModeKids && u.UpdatedAt.Equal(now)
+		})).Return(&users.User{ID: "user-1", ContentMode: users.ContentModeKids}, nil)
+		settingsRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+
+		input := *users.DefaultSettings("")
+		input.ContentMode = "Kids"
+		settings, err := service.SaveSettings(ctx, "user-1", input)
+
+		require.NoError(t, err)
+		assert.Equal(t, users.ContentModeKids, settings.ContentMode)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(*users.Settings)
+			want   error
+		}{
+			{"locale", func(s *users.Settings) { s.Locale = "not a locale" }, movies.ErrInvalidLocale},
+			{"content mode", func(s *users.Settings) { s.ContentMode = "adult" }, users.ErrInvalidContentMode},
+			{"sort field", func(s *users.Settings) { s.SortOrders.Ratings.SortBy = "title" }, users.ErrInvalidSortOrder},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo, settingsRepo, service := setup()
+				repo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+
+				input := *users.DefaultSettings("")
+				tt.modify(&input)
+				_, err := service.SaveSettings(ctx, "user-1", input)
+
+				assert.ErrorIs(t, err, tt.want)
+				settingsRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
@@ -4,20 +4,77 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"thermondo/internal/domain/lists"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
 	"thermondo/internal/pkg/cache"
 	pkgerrors "thermondo/internal/pkg/errors"
 	"thermondo/internal/pkg/interfaces"
+	"thermondo/internal/pkg/logging"
+	"thermondo/internal/pkg/metrics"
+	"thermondo/internal/pkg/storage"
+	"time"
 )
 
+// The profile page the profile endpoints serve without query parameters
+const (
+	DefaultProfileLimit  = 20
+	DefaultProfileSortBy = "created_at"
+	DefaultProfileOrder  = "desc"
+)
+
+// cacheFailures counts user cache reads and writes that failed, by cached
+// entry (profile, stats, year_in_review) and operation (get, set). A read
+// that misses is not a failure.
+var cacheFailures = metrics.NewCounterVec("user_cache_failures_total",
+	"Failed user cache reads and writes, by cached entry and operation", "entry", "operation")
+
 type UserProfileRequest struct {
-	UserID string `json:"user_id"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
-	SortBy string `json:"sort_by"` // "created_at", "score", "title"
-	Order  string `json:"order"`   // "asc", "desc"
+	UserID   string `json:"user_id"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+	SortBy   string `json:"sort_by"` // "created_at", "score", "title"
+	Order    string `json:"order"`   // "asc", "desc"
+	Genre    string `json:"genre,omitempty"`
+	MinScore *int   `json:"min_score,omitempty"`
+	MaxScore *int   `json:"max_score,omitempty"`
+	YearFrom *int   `json:"year_from,omitempty"`
+	YearTo   *int   `json:"year_to,omitempty"`
+}
+
+func (r UserProfileRequest) toFilter() rating.UserRatingFilter {
+	return rating.UserRatingFilter{
+		Genre:    r.Genre,
+		MinScore: r.MinScore,
+		MaxScore: r.MaxScore,
+		YearFrom: r.YearFrom,
+		YearTo:   r.YearTo,
+	}
+}
+
+// filterKey renders the request filters as a stable cache key segment
+func (r UserProfileRequest) filterKey() string {
+	return fmt.Sprintf("genre=%s,score=%s-%s,year=%s-%s",
+		strings.ToLower(r.Genre),
+		intPtrKey(r.MinScore), intPtrKey(r.MaxScore),
+		intPtrKey(r.YearFrom), intPtrKey(r.YearTo))
+}
+
+func intPtrKey(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// userProfilePage is the cached shape of one page of a user's profile ratings
+type userProfilePage struct {
+	Ratings []*UserRatingWithMovie `json:"ratings"`
+	Total   int64                  `json:"total"`
 }
 
 type UserRatingWithMovie struct {
@@ -29,11 +86,22 @@ type UserRatingWithMovie struct {
 }
 
 type UserProfileStats struct {
-	TotalRatings      int64            `json:"total_ratings"`
-	AverageScore      float64          `json:"average_score"`
-	ScoreDistribution map[int]int64    `json:"score_distribution"` // User's rating distribution
-	FavoriteGenre     string           `json:"favorite_genre"`
-	GenreBreakdown    map[string]int64 `json:"genre_breakdown"`
+	TotalRatings      int64         `json:"total_ratings"`
+	AverageScore      float64       `json:"average_score"`
+	ScoreDistribution map[int]int64 `json:"score_distribution"` // User's rating distribution
+	FavoriteGenre     string        `json:"favorite_genre"`     // Rated most often of all time
+	// RecentFavoriteGenre is rated most often with every rating counting
+	// less the older it is, so it follows the user's current taste
+	RecentFavoriteGenre string           `json:"recent_favorite_genre"`
+	GenreBreakdown      map[string]int64 `json:"genre_breakdown"`
+	// RatingsPerMonth, LongestStreak and FavoriteDecade are only filled
+	// when the service has an activity repository
+	RatingsPerMonth []rating.MonthlyCount `json:"ratings_per_month,omitempty"`
+	LongestStreak   *rating.Streak        `json:"longest_streak,omitempty"`
+	FavoriteDecade  int                   `json:"favorite_decade,omitempty"`
+	// Lists is filled on every profile request and never cached, so new
+	// lists show up immediately
+	Lists *lists.Counts `json:"lists,omitempty"`
 }
 
 type userService struct {
@@ -43,6 +111,42 @@ type userService struct {
 	idGenerator    interfaces.IDGenerator
 	timeProvider   interfaces.TimeProvider
 	cache          cache.Cache
+	logger         *slog.Logger
+	listRepo       lists.Repository
+	avatarStorage  storage.Storage
+	avatarURLTTL   time.Duration
+	emailPolicy    users.EmailDomainPolicy
+	activityRepo   rating.UserActivityRepository
+	genreHalfLife  time.Duration
+	mergeRepo      users.MergeRepository
+	settingsRepo   users.SettingsRepository
+}
+
+// Option configures optional dependencies of the user service
+type Option func(*userService)
+
+// WithListRepository enables list counts on the user profile
+func WithListRepository(repo lists.Repository) Option {
+	return func(s *userService) {
+		s.listRepo = repo
+	}
+}
+
+// WithGenreHalfLife sets how long it takes for a rating to count half as
+// much towards the recent favorite genre; 0 counts every rating alike
+func WithGenreHalfLife(d time.Duration) Option {
+	return func(s *userService) {
+		if d >= 0 {
+			s.genreHalfLife = d
+		}
+	}
+}
+
+// WithEmailDomainPolicy restricts which email domains may register
+func WithEmailDomainPolicy(policy users.EmailDomainPolicy) Option {
+	return func(s *userService) {
+		s.emailPolicy = policy
+	}
 }
 
 func NewUserService(
@@ -52,105 +156,110 @@ func NewUserService(
 	idGenerator interfaces.IDGenerator,
 	timeProvider interfaces.TimeProvider,
 	cache cache.Cache,
+	logger *slog.Logger,
+	opts ...Option,
 ) UserService {
-	return &userService{
+	s := &userService{
 		userRepository: userRepository,
 		ratingRepo:     ratingRepo,
 		movieRepo:      movieRepo,
 		idGenerator:    idGenerator,
 		timeProvider:   timeProvider,
 		cache:          cache,
+		logger:         logger,
+		avatarURLTTL:   DefaultAvatarURLTTL,
+		genreHalfLife:  rating.DefaultGenreHalfLife,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *userService) GetUserProfile(ctx context.Context, req UserProfileRequest) ([]*UserRatingWithMovie, *UserProfileStats, error) {
+func (s *userService) GetUserProfile(ctx context.Context, req UserProfileRequest) (_ []*UserRatingWithMovie, _ *UserProfileStats, _ int64, err error) {
+	defer logging.StartOp(ctx, s.logger, "user.profile", "user_id", req.UserID).Expect(clientErrors...).End(&err)
+
 	// Check if user exists
 	user, err := s.userRepository.FindByID(ctx, users.UserID(req.UserID))
 	if err != nil {
-		return nil, nil, pkgerrors.NewInternalError("Failed to check user existence")
+		return nil, nil, 0, pkgerrors.NewInternalError("Failed to check user existence")
 	}
 	if user == nil {
-		return nil, nil, errors.New("user not found")
+		return nil, nil, 0, users.ErrUserNotFound
 	}
 
 	// Try to get profile from cache
-	cacheKey := cache.UserProfileKeyFunc(req.UserID, req.Limit, req.Offset, req.SortBy)
-	var cachedProfile []*UserRatingWithMovie
-	if err := s.cache.Get(ctx, cacheKey, &cachedProfile); err == nil {
+	cacheKey := cache.UserProfileKeyFunc(req.UserID, req.Limit, req.Offset, req.SortBy, req.Order, req.filterKey())
+	var cachedPage userProfilePage
+	if s.cacheGet(ctx, "profile", req.UserID, cacheKey, &cachedPage) {
 		// If we have cached profile, get stats from cache as well
 		statsKey := cache.UserStatsKeyFunc(req.UserID)
 		var cachedStats *UserProfileStats
-		if err := s.cache.Get(ctx, statsKey, &cachedStats); err == nil {
-			return cachedProfile, cachedStats, nil
+		if s.cacheGet(ctx, "stats", req.UserID, statsKey, &cachedStats) {
+			return cachedPage.Ratings, s.withListCounts(ctx, req.UserID, cachedStats), cachedPage.Total, nil
 		}
 	}
 
-	// Get user's ratings with pagination
+	// Sorting, filtering and pagination are all applied in SQL so the order
+	// is consistent across pages
 	searchOptions := []rating.SearchOption{
 		rating.WithLimit(req.Limit),
 		rating.WithOffset(req.Offset),
 		rating.WithSort(req.SortBy, req.Order),
 	}
 
-	userRatings, err := s.ratingRepo.GetByUser(ctx, users.UserID(req.UserID), searchOptions...)
+	userRatings, total, err := s.ratingRepo.GetUserRatingsWithMovies(ctx, users.UserID(req.UserID), req.toFilter(), searchOptions...)
 	if err != nil {
-		return nil, nil, pkgerrors.NewInternalError("Failed to get user ratings")
+		return nil, nil, 0, pkgerrors.NewInternalError("Failed to get user ratings")
 	}
 
-	// Get movie details and stats for each rating
 	userRatingsWithMovies := make([]*UserRatingWithMovie, len(userRatings))
 	for i, userRating := range userRatings {
-		movie, err := s.movieRepo.GetByID(ctx, userRating.MovieID)
-		if err != nil {
-			continue
-		}
-
-		// Get movie rating stats
-		movieStats, err := s.ratingRepo.GetMovieStats(ctx, userRating.MovieID)
-		if err != nil {
-			movieStats = &rating.MovieRatingStats{
-				AverageScore: 0,
-				TotalRatings: 0,
-			}
-		}
-
-		// Compare user rating vs movie average
-		userVsAvg := s.compareUserRatingToAverage(userRating.Score, movieStats.AverageScore)
-
 		userRatingsWithMovies[i] = &UserRatingWithMovie{
-			Rating:       userRating,
-			Movie:        movie,
-			MovieAverage: movieStats.AverageScore,
-			TotalRatings: movieStats.TotalRatings,
-			UserVsAvg:    userVsAvg,
+			Rating:       userRating.Rating,
+			Movie:        userRating.Movie,
+			MovieAverage: userRating.MovieAverage,
+			TotalRatings: userRating.MovieTotalRatings,
+			UserVsAvg:    s.compareUserRatingToAverage(userRating.Rating.Score, userRating.MovieAverage),
 		}
 	}
 
 	// Get user stats
 	userStats, err := s.GetUserStats(ctx, req.UserID)
 	if err != nil {
-		return nil, nil, pkgerrors.NewInternalError("Failed to get user statistics")
+		return nil, nil, 0, pkgerrors.NewInternalError("Failed to get user statistics")
 	}
 
 	// Cache the results
-	if err := s.cache.Set(ctx, cacheKey, userRatingsWithMovies, cache.UserProfileTTL); err != nil {
-		// Log cache error but don't fail the request
-		fmt.Printf("Failed to cache user profile: %v\n", err)
+	page := userProfilePage{Ratings: userRatingsWithMovies, Total: total}
+	s.cacheSet(ctx, "profile", req.UserID, cacheKey, page, cache.UserProfileTTL, cache.ProfilesTag)
+	s.cacheSet(ctx, "stats", req.UserID, cache.UserStatsKeyFunc(req.UserID), userStats, cache.UserStatsTTL)
+
+	return userRatingsWithMovies, s.withListCounts(ctx, req.UserID, userStats), total, nil
+}
+
+// withListCounts returns a copy of stats with the user's list counts. Counts
+// are best effort; a failure leaves them out of the profile.
+func (s *userService) withListCounts(ctx context.Context, userID string, stats *UserProfileStats) *UserProfileStats {
+	if s.listRepo == nil || stats == nil {
+		return stats
 	}
 
-	statsKey := cache.UserStatsKeyFunc(req.UserID)
-	if err := s.cache.Set(ctx, statsKey, userStats, cache.UserStatsTTL); err != nil {
-		// Log cache error but don't fail the request
-		fmt.Printf("Failed to cache user stats: %v\n", err)
+	counts, err := s.listRepo.CountByUser(ctx, users.UserID(userID))
+	if err != nil {
+		s.logger.Warn("Failed to count user lists", "error", err, "user_id", userID)
+		return stats
 	}
 
-	return userRatingsWithMovies, userStats, nil
+	withCounts := *stats
+	withCounts.Lists = &counts
+	return &withCounts
 }
 
 func (s *userService) GetUserStats(ctx context.Context, userID string) (*UserProfileStats, error) {
 	cacheKey := cache.UserStatsKeyFunc(userID)
 	var cachedStats *UserProfileStats
-	if err := s.cache.Get(ctx, cacheKey, &cachedStats); err == nil {
+	if s.cacheGet(ctx, "stats", userID, cacheKey, &cachedStats) {
 		return cachedStats, nil
 	}
 
@@ -159,7 +268,7 @@ func (s *userService) GetUserStats(ctx context.Context, userID string) (*UserPro
 		return nil, pkgerrors.NewInternalError("Failed to check user existence")
 	}
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, users.ErrUserNotFound
 	}
 
 	allRatings, err := s.ratingRepo.GetByUser(ctx, users.UserID(userID))
@@ -176,9 +285,7 @@ func (s *userService) GetUserStats(ctx context.Context, userID string) (*UserPro
 			GenreBreakdown:    make(map[string]int64),
 		}
 
-		if err := s.cache.Set(ctx, cacheKey, emptyStats, cache.UserStatsTTL); err != nil {
-			fmt.Printf("Failed to cache empty user stats: %v\n", err)
-		}
+		s.cacheSet(ctx, "stats", userID, cacheKey, emptyStats, cache.UserStatsTTL)
 
 		return emptyStats, nil
 	}
@@ -187,41 +294,45 @@ func (s *userService) GetUserStats(ctx context.Context, userID string) (*UserPro
 	totalScore := 0
 	scoreDistribution := make(map[int]int64)
 	genreBreakdown := make(map[string]int64)
+	recentGenres := make(map[string]float64)
+	now := s.timeProvider.Now()
 
-	for _, rating := range allRatings {
-		totalScore += rating.Score
-		scoreDistribution[rating.Score]++
+	for _, r := range allRatings {
+		totalScore += r.Score
+		scoreDistribution[r.Score]++
 
 		// Get movie for genre info
-		if movie, err := s.movieRepo.GetByID(ctx, rating.MovieID); err == nil {
+		if movie, err := s.movieRepo.GetByID(ctx, r.MovieID); err == nil {
 			genreBreakdown[movie.Genre]++
+			recentGenres[movie.Genre] += rating.DecayWeight(now.Sub(r.CreatedAt), s.genreHalfLife)
 		}
 	}
 
 	averageScore := float64(totalScore) / float64(len(allRatings))
 
-	// Find favorite genre
-	favoriteGenre := ""
-	maxGenreCount := int64(0)
-	for genre, count := range genreBreakdown {
-		if count > maxGenreCount {
-			maxGenreCount = count
-			favoriteGenre = genre
-		}
+	stats := &UserProfileStats{
+		TotalRatings:        int64(len(allRatings)),
+		AverageScore:        averageScore,
+		ScoreDistribution:   scoreDistribution,
+		FavoriteGenre:       rating.FavoriteGenre(genreBreakdown),
+		RecentFavoriteGenre: rating.FavoriteGenre(recentGenres),
+		GenreBreakdown:      genreBreakdown,
 	}
 
-	stats := &UserProfileStats{
-		TotalRatings:      int64(len(allRatings)),
-		AverageScore:      averageScore,
-		ScoreDistribution: scoreDistribution,
-		FavoriteGenre:     favoriteGenre,
-		GenreBreakdown:    genreBreakdown,
+	if s.activityRepo != nil {
+		activity, err := s.activityRepo.GetUserActivity(ctx, users.UserID(userID), time.Time{}, time.Time{})
+		if err != nil {
+			return nil, pkgerrors.NewInternalError("Failed to get user activity for stats")
+		}
+		stats.RatingsPerMonth = activity.PerMonth
+		stats.LongestStreak = activity.LongestStreak
+		if len(activity.Decades) > 0 {
+			stats.FavoriteDecade = activity.Decades[0].Decade
+		}
 	}
 
 	// Cache the stats
-	if err := s.cache.Set(ctx, cacheKey, stats, cache.UserStatsTTL); err != nil {
-		fmt.Printf("Failed to cache user stats: %v\n", err)
-	}
+	s.cacheSet(ctx, "stats", userID, cacheKey, stats, cache.UserStatsTTL)
 
 	return stats, nil
 }
@@ -248,19 +359,51 @@ func (s *userService) compareUserRatingToAverage(userScore int, movieAverage flo
 	}
 }
 
-// InvalidateUserCache invalidates all cached data for a user
-func (s *userService) InvalidateUserCache(ctx context.Context, userID string) error {
-	// Delete all user profile cache entries
-	profilePattern := "user_profile:" + userID + ":*:*:*"
-	if err := s.cache.DeletePattern(ctx, profilePattern); err != nil {
-		return fmt.Errorf("failed to delete profile cache: %w", err)
+// WarmUserCache precomputes the user's stats and the default first page of
+// their profile, where most profile visits land. Data that is already
+// cached is left as it is.
+func (s *userService) WarmUserCache(ctx context.Context, userID string) error {
+	_, _, _, err := s.GetUserProfile(ctx, UserProfileRequest{
+		UserID: userID,
+		Limit:  DefaultProfileLimit,
+		SortBy: DefaultProfileSortBy,
+		Order:  DefaultProfileOrder,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to warm user cache: %w", err)
+	}
+	return nil
+}
+
+// cacheGet reads the cached entry of a user into dest and reports whether it
+// was there. A failure other than a miss is logged and counted, and taken
+// for a miss so the entry is computed again.
+func (s *userService) cacheGet(ctx context.Context, entry, userID, key string, dest any) bool {
+	err := s.cache.Get(ctx, key, dest)
+	if err == nil {
+		return true
+	}
+	if !errors.Is(err, cache.ErrCacheMiss) {
+		cacheFailures.With(entry, "get").Inc()
+		s.logger.Warn("Failed to read user cache", "error", err, "entry", entry, "user_id", userID, "cache_key", key)
 	}
+	return false
+}
 
-	// Delete user stats cache
-	statsKey := cache.UserStatsKeyFunc(userID)
-	if err := s.cache.Delete(ctx, statsKey); err != nil {
-		return fmt.Errorf("failed to delete stats cache: %w", err)
+// cacheSet caches an entry of a user, tagged with the user and tags. A
+// failure is logged and counted but does not fail the request.
+func (s *userService) cacheSet(ctx context.Context, entry, userID, key string, value any, ttl time.Duration, tags ...string) {
+	tags = append([]string{cache.UserTag(userID)}, tags...)
+	if err := s.cache.SetWithTags(ctx, key, value, ttl, tags...); err != nil {
+		cacheFailures.With(entry, "set").Inc()
+		s.logger.Warn("Failed to write user cache", "error", err, "entry", entry, "user_id", userID, "cache_key", key)
 	}
+}
 
+// InvalidateUserCache invalidates all cached data for a user
+func (s *userService) InvalidateUserCache(ctx context.Context, userID string) error {
+	if err := s.cache.InvalidateTags(ctx, cache.UserTag(userID)); err != nil {
+		return fmt.Errorf("failed to invalidate user cache: %w", err)
+	}
 	return nil
 }
@@ -3,9 +3,12 @@ package user
 import (
 	"context"
 	"errors"
+	"io"
+	"log/slog"
 	"testing"
 	"time"
 
+	"thermondo/internal/domain/lists"
 	"thermondo/internal/domain/movies"
 	"thermondo/internal/domain/rating"
 	"thermondo/internal/domain/users"
@@ -14,6 +17,8 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
 // Add a mock cache struct
 type mockCache struct{ mock.Mock }
 
@@ -37,6 +42,19 @@ func (m *mockCache) DeletePattern(ctx context.Context, pattern string) error {
 	args := m.Called(ctx, pattern)
 	return args.Error(0)
 }
+func (m *mockCache) SetWithTags(ctx context.Context, key string, value interface{}, ttl time.Duration, tags ...string) error {
+	args := m.Called(ctx, key, value, ttl, tags)
+	return args.Error(0)
+}
+func (m *mockCache) TaggedKeys(ctx context.Context, tags ...string) ([]string, error) {
+	args := m.Called(ctx, tags)
+	keys, _ := args.Get(0).([]string)
+	return keys, args.Error(1)
+}
+func (m *mockCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	args := m.Called(ctx, tags)
+	return args.Error(0)
+}
 func (m *mockCache) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -124,7 +142,7 @@ func TestFindUserByID(t *testing.T) {
 			tt.mockSetup(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv)
 
 			mockCache := new(mockCache)
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache, testLogger)
 			user, err := service.FindUserByID(context.Background(), tt.userID)
 
 			if tt.expectedError != nil {
@@ -218,7 +236,7 @@ func TestFindUserByEmail(t *testing.T) {
 			tt.mockSetup(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv)
 
 			mockCache := new(mockCache)
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache, testLogger)
 			user, err := service.FindUserByEmail(context.Background(), tt.email)
 
 			if tt.expectedError != nil {
@@ -250,6 +268,7 @@ func TestFindUserByEmail(t *testing.T) {
 func TestListUsers(t *testing.T) {
 	tests := []struct {
 		name          string
+		filter        users.ListFilter
 		page          int
 		limit         int
 		mockSetup     func(*MockUserRepository, *MockRatingRepository, *MockMovieRepository, *MockIDGenerator, *MockTimeProvider)
@@ -262,7 +281,7 @@ func TestListUsers(t *testing.T) {
 			page:  1,
 			limit: 10,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("List", mock.Anything, 1, 10).Return([]*users.User{
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 10).Return([]*users.User{
 					{
 						ID:        "test-id-1",
 						FirstName: "John",
@@ -283,8 +302,7 @@ func TestListUsers(t *testing.T) {
 						CreatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 						UpdatedAt: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
 					},
-				}, nil)
-				repo.On("Count", mock.Anything).Return(2, nil)
+				}, 2, nil)
 			},
 			expectedUsers: []*users.User{
 				{
@@ -316,8 +334,7 @@ func TestListUsers(t *testing.T) {
 			page:  1,
 			limit: 10,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("List", mock.Anything, 1, 10).Return([]*users.User{}, nil)
-				repo.On("Count", mock.Anything).Return(0, nil)
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 10).Return([]*users.User{}, 0, nil)
 			},
 			expectedUsers: []*users.User{},
 			expectedTotal: 0,
@@ -328,8 +345,7 @@ func TestListUsers(t *testing.T) {
 			page:  1,
 			limit: 10,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("Count", mock.Anything).Return(0, nil)
-				repo.On("List", mock.Anything, 1, 10).Return(nil, errors.New("database error"))
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 10).Return(nil, 0, errors.New("database error"))
 			},
 			expectedUsers: nil,
 			expectedTotal: 0,
@@ -337,22 +353,47 @@ func TestListUsers(t *testing.T) {
 		},
 		{
 			name:  "repository error on count",
-			page:  1,
+			page:  5,
 			limit: 10,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("Count", mock.Anything).Return(0, errors.New("database error"))
+				repo.On("List", mock.Anything, users.ListFilter{}, 5, 10).Return([]*users.User{}, 0, nil)
+				repo.On("Count", mock.Anything, users.ListFilter{}).Return(0, errors.New("database error"))
 			},
 			expectedUsers: nil,
 			expectedTotal: 0,
 			expectedError: errors.New("database error"),
 		},
+		{
+			name:   "filters are passed to the repository",
+			filter: users.ListFilter{Role: users.RoleAdmin, EmailPrefix: "jane", SortBy: "created_at", Order: "desc"},
+			page:   1,
+			limit:  10,
+			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
+				filter := users.ListFilter{Role: users.RoleAdmin, EmailPrefix: "jane", SortBy: "created_at", Order: "desc"}
+				repo.On("List", mock.Anything, filter, 1, 10).Return([]*users.User{{ID: "test-id-2", Role: users.RoleAdmin}}, 1, nil)
+			},
+			expectedUsers: []*users.User{{ID: "test-id-2", Role: users.RoleAdmin}},
+			expectedTotal: 1,
+			expectedError: nil,
+		},
+		{
+			name:  "page past the end falls back to count",
+			page:  3,
+			limit: 10,
+			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
+				repo.On("List", mock.Anything, users.ListFilter{}, 3, 10).Return([]*users.User{}, 0, nil)
+				repo.On("Count", mock.Anything, users.ListFilter{}).Return(12, nil)
+			},
+			expectedUsers: []*users.User{},
+			expectedTotal: 12,
+			expectedError: nil,
+		},
 		{
 			name:  "invalid page number",
 			page:  0,
 			limit: 10,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("List", mock.Anything, 1, 10).Return([]*users.User{}, nil)
-				repo.On("Count", mock.Anything).Return(0, nil)
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 10).Return([]*users.User{}, 0, nil)
 			},
 			expectedUsers: []*users.User{},
 			expectedTotal: 0,
@@ -363,8 +404,7 @@ func TestListUsers(t *testing.T) {
 			page:  1,
 			limit: 0,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("List", mock.Anything, 1, 20).Return([]*users.User{}, nil)
-				repo.On("Count", mock.Anything).Return(0, nil)
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 20).Return([]*users.User{}, 0, nil)
 			},
 			expectedUsers: []*users.User{},
 			expectedTotal: 0,
@@ -375,8 +415,7 @@ func TestListUsers(t *testing.T) {
 			page:  1,
 			limit: 101,
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider) {
-				repo.On("List", mock.Anything, 1, 20).Return([]*users.User{}, nil)
-				repo.On("Count", mock.Anything).Return(0, nil)
+				repo.On("List", mock.Anything, users.ListFilter{}, 1, 20).Return([]*users.User{}, 0, nil)
 			},
 			expectedUsers: []*users.User{},
 			expectedTotal: 0,
@@ -394,8 +433,8 @@ func TestListUsers(t *testing.T) {
 			tt.mockSetup(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv)
 
 			mockCache := new(mockCache)
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
-			users, total, err := service.ListUsers(context.Background(), tt.page, tt.limit)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache, testLogger)
+			users, total, err := service.ListUsers(context.Background(), tt.filter, tt.page, tt.limit)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -433,20 +472,23 @@ func TestGetUserProfile(t *testing.T) {
 		mockSetup       func(*MockUserRepository, *MockRatingRepository, *MockMovieRepository, *MockIDGenerator, *MockTimeProvider, *mockCache)
 		expectedRatings []*UserRatingWithMovie
 		expectedStats   *UserProfileStats
+		expectedTotal   int64
 		expectedError   error
 	}{
 		{
 			name: "successful profile retrieval",
 			req: UserProfileRequest{
-				UserID: "test-id",
-				Limit:  10,
-				Offset: 0,
-				SortBy: "created_at",
-				Order:  "desc",
+				UserID:   "test-id",
+				Limit:    10,
+				Offset:   0,
+				SortBy:   "created_at",
+				Order:    "desc",
+				Genre:    "Action",
+				MinScore: intPtr(4),
 			},
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider, cache *mockCache) {
 				// Mock cache miss
-				cache.On("Get", mock.Anything, "user_profile:test-id:10:0:created_at", mock.Anything).Return(errors.New("cache miss"))
+				cache.On("Get", mock.Anything, "user_profile:test-id:10:0:created_at:desc:genre=action,score=4-,year=-", mock.Anything).Return(errors.New("cache miss"))
 				cache.On("Get", mock.Anything, "user_stats:test-id", mock.Anything).Return(errors.New("cache miss"))
 
 				// Mock user existence check
@@ -461,7 +503,7 @@ func TestGetUserProfile(t *testing.T) {
 					UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 				}, nil)
 
-				// Mock ratings
+				// Mock ratings used to compute the user stats
 				ratings := []*rating.Rating{
 					{
 						ID:        "rating-1",
@@ -482,16 +524,19 @@ func TestGetUserProfile(t *testing.T) {
 				}
 				movieRepo.On("GetByID", mock.Anything, movies.MovieID("movie-1")).Return(movie, nil)
 
-				// Mock movie stats
-				movieStats := &rating.MovieRatingStats{
-					AverageScore: 4.5,
-					TotalRatings: 100,
-				}
-				ratingRepo.On("GetMovieStats", mock.Anything, movies.MovieID("movie-1")).Return(movieStats, nil)
+				// Mock the joined profile page
+				ratingRepo.On("GetUserRatingsWithMovies", mock.Anything, users.UserID("test-id"), rating.UserRatingFilter{Genre: "Action", MinScore: intPtr(4)}, mock.Anything).Return([]*rating.RatingWithMovie{
+					{
+						Rating:            ratings[0],
+						Movie:             movie,
+						MovieAverage:      4.5,
+						MovieTotalRatings: 100,
+					},
+				}, int64(1), nil)
 
 				// Mock cache set
-				cache.On("Set", mock.Anything, "user_profile:test-id:10:0:created_at", mock.Anything, mock.Anything).Return(nil)
-				cache.On("Set", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything).Return(nil)
+				cache.On("SetWithTags", mock.Anything, "user_profile:test-id:10:0:created_at:desc:genre=action,score=4-,year=-", mock.Anything, mock.Anything, []string{"user:test-id", "user_profiles"}).Return(nil)
+				cache.On("SetWithTags", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 			},
 			expectedRatings: []*UserRatingWithMovie{
 				{
@@ -524,6 +569,7 @@ func TestGetUserProfile(t *testing.T) {
 					"Action": 1,
 				},
 			},
+			expectedTotal: 1,
 			expectedError: nil,
 		},
 		{
@@ -550,7 +596,7 @@ func TestGetUserProfile(t *testing.T) {
 			},
 			mockSetup: func(repo *MockUserRepository, ratingRepo *MockRatingRepository, movieRepo *MockMovieRepository, idGen *MockIDGenerator, timeProv *MockTimeProvider, cache *mockCache) {
 				// Mock cache miss
-				cache.On("Get", mock.Anything, "user_profile:test-id:10:0:created_at", mock.Anything).Return(errors.New("cache miss"))
+				cache.On("Get", mock.Anything, "user_profile:test-id:10:0:created_at::genre=,score=-,year=-", mock.Anything).Return(errors.New("cache miss"))
 				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{
 					ID:        "test-id",
 					FirstName: "John",
@@ -561,7 +607,7 @@ func TestGetUserProfile(t *testing.T) {
 					CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 					UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 				}, nil)
-				ratingRepo.On("GetByUser", mock.Anything, users.UserID("test-id"), mock.Anything).Return(nil, errors.New("Failed to get user ratings"))
+				ratingRepo.On("GetUserRatingsWithMovies", mock.Anything, users.UserID("test-id"), rating.UserRatingFilter{}, mock.Anything).Return(nil, int64(0), errors.New("database error"))
 			},
 			expectedRatings: nil,
 			expectedStats:   nil,
@@ -576,11 +622,12 @@ func TestGetUserProfile(t *testing.T) {
 			mockMovieRepo := new(MockMovieRepository)
 			mockIDGen := new(MockIDGenerator)
 			mockTimeProv := new(MockTimeProvider)
+			mockTimeProv.On("Now").Return(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)).Maybe()
 			mockCache := new(mockCache)
 			tt.mockSetup(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
 
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
-			ratings, stats, err := service.GetUserProfile(context.Background(), tt.req)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache, testLogger)
+			ratings, stats, total, err := service.GetUserProfile(context.Background(), tt.req)
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
@@ -592,6 +639,7 @@ func TestGetUserProfile(t *testing.T) {
 				assert.NotNil(t, ratings)
 				assert.NotNil(t, stats)
 				assert.Equal(t, len(tt.expectedRatings), len(ratings))
+				assert.Equal(t, tt.expectedTotal, total)
 				assert.Equal(t, tt.expectedStats.TotalRatings, stats.TotalRatings)
 				assert.Equal(t, tt.expectedStats.AverageScore, stats.AverageScore)
 				assert.Equal(t, tt.expectedStats.FavoriteGenre, stats.FavoriteGenre)
@@ -638,7 +686,7 @@ func TestGetUserStats(t *testing.T) {
 				// Mock cache miss
 				cache.On("Get", mock.Anything, "user_stats:test-id", mock.Anything).Return(errors.New("cache miss"))
 				// Mock cache set
-				cache.On("Set", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything).Return(nil)
+				cache.On("SetWithTags", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{
 					ID:        "test-id",
 					FirstName: "John",
@@ -739,7 +787,7 @@ func TestGetUserStats(t *testing.T) {
 				// Mock cache miss
 				cache.On("Get", mock.Anything, "user_stats:test-id", mock.Anything).Return(errors.New("cache miss"))
 				// Mock cache set
-				cache.On("Set", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything).Return(nil)
+				cache.On("SetWithTags", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 				repo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{
 					ID:        "test-id",
 					FirstName: "John",
@@ -770,10 +818,11 @@ func TestGetUserStats(t *testing.T) {
 			mockMovieRepo := new(MockMovieRepository)
 			mockIDGen := new(MockIDGenerator)
 			mockTimeProv := new(MockTimeProvider)
+			mockTimeProv.On("Now").Return(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)).Maybe()
 			mockCache := new(mockCache)
 			tt.mockSetup(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
 
-			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache)
+			service := NewUserService(mockRepo, mockRatingRepo, mockMovieRepo, mockIDGen, mockTimeProv, mockCache, testLogger)
 			stats, err := service.GetUserStats(context.Background(), tt.userID)
 
 			if tt.expectedError != nil {
@@ -802,3 +851,126 @@ func TestGetUserStats(t *testing.T) {
 		})
 	}
 }
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestGetUserProfileIncludesListCounts(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	mockCache := new(mockCache)
+	mockListRepo := new(MockListRepository)
+
+	mockRepo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id"}, nil)
+	mockCache.On("Get", mock.Anything, mock.MatchedBy(func(key string) bool { return key != "user_stats:test-id" }), mock.Anything).Return(nil)
+	mockCache.On("Get", mock.Anything, "user_stats:test-id", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		*(args.Get(2).(**UserProfileStats)) = &UserProfileStats{TotalRatings: 3}
+	})
+	mockListRepo.On("CountByUser", mock.Anything, users.UserID("test-id")).Return(lists.Counts{Total: 2, Public: 1, Private: 1}, nil)
+
+	service := NewUserService(mockRepo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), mockCache, testLogger, WithListRepository(mockListRepo))
+	_, stats, _, err := service.GetUserProfile(context.Background(), UserProfileRequest{UserID: "test-id", Limit: 10, SortBy: "created_at", Order: "desc"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalRatings)
+	assert.Equal(t, &lists.Counts{Total: 2, Public: 1, Private: 1}, stats.Lists)
+}
+
+func TestWarmUserCache(t *testing.T) {
+	profileKey := "user_profile:test-id:20:0:created_at:desc:genre=,score=-,year=-"
+
+	t.Run("caches the default profile page and stats", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRatingRepo := new(MockRatingRepository)
+		mockCache := new(mockCache)
+		mockRepo.On("FindByID", mock.Anything, users.UserID("test-id")).Return(&users.User{ID: "test-id"}, nil)
+		mockCache.On("Get", mock.Anything, profileKey, mock.Anything).Return(errors.New("cache miss"))
+		mockCache.On("Get", mock.Anything, "user_stats:test-id", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			*(args.Get(2).(**UserProfileStats)) = &UserProfileStats{TotalRatings: 0}
+		})
+		mockRatingRepo.On("GetUserRatingsWithMovies", mock.Anything, users.UserID("test-id"), mock.Anything, mock.Anything).
+			Return([]*rating.RatingWithMovie{}, int64(0), nil)
+		mockCache.On("SetWithTags", mock.Anything, profileKey, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("SetWithTags", mock.Anything, "user_stats:test-id", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		service := NewUserService(mockRepo, mockRatingRepo, new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), mockCache, testLogger)
+		err := service.WarmUserCache(context.Background(), "test-id")
+
+		assert.NoError(t, err)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("fails for an unknown user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockRepo.On("FindByID", mock.Anything, users.UserID("missing")).Return(nil, nil)
+
+		service := NewUserService(mockRepo, new(MockRatingRepository), new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), new(mockCache), testLogger)
+		err := service.WarmUserCache(context.Background(), "missing")
+
+		assert.ErrorContains(t, err, "user not found")
+	})
+}
+
+func TestGetUserStatsRecentFavoriteGenre(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	userRepo := new(MockUserRepository)
+	ratingRepo := new(MockRatingRepository)
+	movieRepo := new(MockMovieRepository)
+	timeProvider := new(MockTimeProvider)
+	cache := new(mockCache)
+	timeProvider.On("Now").Return(now)
+	cache.On("Get", mock.Anything, "user_stats:user-1", mock.Anything).Return(errors.New("cache miss"))
+	cache.On("SetWithTags", mock.Anything, "user_stats:user-1", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	userRepo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	// Three westerns two years ago, two horror movies last week
+	ratingRepo.On("GetByUser", mock.Anything, users.UserID("user-1"), mock.Anything).Return([]*rating.Rating{
+		{MovieID: "western-1", Score: 4, CreatedAt: now.AddDate(-2, 0, 0)},
+		{MovieID: "western-2", Score: 4, CreatedAt: now.AddDate(-2, 0, 0)},
+		{MovieID: "western-3", Score: 4, CreatedAt: now.AddDate(-2, 0, 0)},
+		{MovieID: "horror-1", Score: 5, CreatedAt: now.AddDate(0, 0, -7)},
+		{MovieID: "horror-2", Score: 5, CreatedAt: now.AddDate(0, 0, -7)},
+	}, nil)
+	for _, id := range []movies.MovieID{"western-1", "western-2", "western-3"} {
+		movieRepo.On("GetByID", mock.Anything, id).Return(&movies.Movie{ID: id, Genre: "Western"}, nil)
+	}
+	for _, id := range []movies.MovieID{"horror-1", "horror-2"} {
+		movieRepo.On("GetByID", mock.Anything, id).Return(&movies.Movie{ID: id, Genre: "Horror"}, nil)
+	}
+
+	t.Run("recent ratings count more", func(t *testing.T) {
+		service := NewUserService(userRepo, ratingRepo, movieRepo, new(MockIDGenerator), timeProvider, cache, testLogger)
+		stats, err := service.GetUserStats(context.Background(), "user-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Western", stats.FavoriteGenre)
+		assert.Equal(t, "Horror", stats.RecentFavoriteGenre)
+	})
+
+	t.Run("without decay both agree", func(t *testing.T) {
+		service := NewUserService(userRepo, ratingRepo, movieRepo, new(MockIDGenerator), timeProvider, cache, testLogger, WithGenreHalfLife(0))
+		stats, err := service.GetUserStats(context.Background(), "user-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Western", stats.RecentFavoriteGenre)
+	})
+}
+
+func TestUserCacheFailures(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	ratingRepo := new(MockRatingRepository)
+	mockCache := new(mockCache)
+	userRepo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	ratingRepo.On("GetByUser", mock.Anything, users.UserID("user-1"), mock.Anything).Return([]*rating.Rating{}, nil)
+	mockCache.On("Get", mock.Anything, "user_stats:user-1", mock.Anything).Return(errors.New("connection refused"))
+	mockCache.On("SetWithTags", mock.Anything, "user_stats:user-1", mock.Anything, mock.Anything, []string{"user:user-1"}).Return(errors.New("connection refused"))
+
+	gets, sets := cacheFailures.With("stats", "get").Value(), cacheFailures.With("stats", "set").Value()
+	service := NewUserService(userRepo, ratingRepo, new(MockMovieRepository), new(MockIDGenerator), new(MockTimeProvider), mockCache, testLogger)
+	stats, err := service.GetUserStats(context.Background(), "user-1")
+
+	assert.NoError(t, err, "a failing cache does not fail the request")
+	assert.Equal(t, int64(0), stats.TotalRatings)
+	assert.Equal(t, gets+1, cacheFailures.With("stats", "get").Value())
+	assert.Equal(t, sets+1, cacheFailures.With("stats", "set").Value())
+	mockCache.AssertExpectations(t)
+}
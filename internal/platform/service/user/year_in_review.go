@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/pkg/cache"
+	pkgerrors "thermondo/internal/pkg/errors"
+	"time"
+)
+
+// YearInReviewTopRated is how many of the year's best rated movies the year
+// in review lists
+const YearInReviewTopRated = 5
+
+// YearInReview sums up the ratings a user gave in one calendar year (UTC)
+type YearInReview struct {
+	UserID          string                 `json:"user_id"`
+	Year            int                    `json:"year"`
+	TotalRatings    int64                  `json:"total_ratings"`
+	AverageScore    float64                `json:"average_score"`
+	RatingsPerMonth []rating.MonthlyCount  `json:"ratings_per_month"`
+	LongestStreak   *rating.Streak         `json:"longest_streak,omitempty"`
+	TopDecade       int                    `json:"top_decade,omitempty"`
+	TopGenre        string                 `json:"top_genre"`
+	TopDirector     string                 `json:"top_director"`
+	TopRated        []*UserRatingWithMovie `json:"top_rated"`
+}
+
+// WithActivityRepository enables rating cadence, streaks and decades in the
+// user statistics, and the year in review
+func WithActivityRepository(repo rating.UserActivityRepository) Option {
+	return func(s *userService) {
+		s.activityRepo = repo
+	}
+}
+
+// GetYearInReview returns the user's year in review; year 0 means the
+// current year
+func (s *userService) GetYearInReview(ctx context.Context, userID string, year int) (*YearInReview, error) {
+	if s.activityRepo == nil {
+		return nil, pkgerrors.NewInternalError("Year in review is not available")
+	}
+
+	currentYear := s.timeProvider.Now().UTC().Year()
+	if year == 0 {
+		year = currentYear
+	}
+	if year < 1 || year > currentYear {
+		return nil, pkgerrors.NewBadRequestError(fmt.Sprintf("year must be between 1 and %d", currentYear))
+	}
+
+	cacheKey := cache.UserWrappedKeyFunc(userID, year)
+	var cached *YearInReview
+	if s.cacheGet(ctx, "year_in_review", userID, cacheKey, &cached) {
+		return cached, nil
+	}
+
+	user, err := s.userRepository.FindByID(ctx, users.UserID(userID))
+	if err != nil {
+		return nil, pkgerrors.NewInternalError("Failed to check user existence")
+	}
+	if user == nil {
+		return nil, pkgerrors.NewNotFoundError("User not found")
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	activity, err := s.activityRepo.GetUserActivity(ctx, user.ID, from, to)
+	if err != nil {
+		return nil, pkgerrors.NewInternalError("Failed to get user activity")
+	}
+
+	topRated, _, err := s.ratingRepo.GetUserRatingsWithMovies(ctx, user.ID,
+		rating.UserRatingFilter{RatedFrom: &from, RatedTo: &to},
+		rating.WithSort("score", "desc"), rating.WithLimit(YearInReviewTopRated))
+	if err != nil {
+		return nil, pkgerrors.NewInternalError("Failed to get top rated movies")
+	}
+
+	review := &YearInReview{
+		UserID:          userID,
+		Year:            year,
+		TotalRatings:    activity.TotalRatings,
+		AverageScore:    activity.AverageScore,
+		RatingsPerMonth: activity.PerMonth,
+		LongestStreak:   activity.LongestStreak,
+		TopGenre:        activity.TopGenre,
+		TopDirector:     activity.TopDirector,
+		TopRated:        make([]*UserRatingWithMovie, len(topRated)),
+	}
+	if len(activity.Decades) > 0 {
+		review.TopDecade = activity.Decades[0].Decade
+	}
+	for i, rated := range topRated {
+		review.TopRated[i] = &UserRatingWithMovie{
+			Rating:       rated.Rating,
+			Movie:        rated.Movie,
+			MovieAverage: rated.MovieAverage,
+			TotalRatings: rated.MovieTotalRatings,
+			UserVsAvg:    s.compareUserRatingToAverage(rated.Rating.Score, rated.MovieAverage),
+		}
+	}
+
+	s.cacheSet(ctx, "year_in_review", userID, cacheKey, review, cache.UserWrappedTTL)
+
+	return review, nil
+}
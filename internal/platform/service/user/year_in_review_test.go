@@ -0,0 +1,129 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/rating"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeActivityRepository returns activity and records the period asked for
+type fakeActivityRepository struct {
+	activity *rating.UserActivity
+	from, to time.Time
+}
+
+func (f *fakeActivityRepository) GetUserActivity(ctx context.Context, userID users.UserID, from, to time.Time) (*rating.UserActivity, error) {
+	f.from, f.to = from, to
+	return f.activity, nil
+}
+
+func TestGetYearInReview(t *testing.T) {
+	now := time.Date(2024, 12, 20, 10, 0, 0, 0, time.UTC)
+	activity := &rating.UserActivity{
+		TotalRatings:  2,
+		AverageScore:  4.5,
+		PerMonth:      []rating.MonthlyCount{{Month: "2024-03", Count: 2}},
+		LongestStreak: &rating.Streak{Days: 2, Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		Decades:       []rating.DecadeCount{{Decade: 1990, Count: 2}},
+		TopGenre:      "Crime",
+		TopDirector:   "Michael Mann",
+	}
+	setup := func() (*MockUserRepository, *MockRatingRepository, *mockCache, *fakeActivityRepository, UserService) {
+		userRepo := new(MockUserRepository)
+		ratingRepo := new(MockRatingRepository)
+		cache := new(mockCache)
+		timeProvider := new(MockTimeProvider)
+		timeProvider.On("Now").Return(now)
+		activityRepo := &fakeActivityRepository{activity: activity}
+		service := NewUserService(userRepo, ratingRepo, new(MockMovieRepository), new(MockIDGenerator), timeProvider, cache, testLogger, WithActivityRepository(activityRepo))
+		return userRepo, ratingRepo, cache, activityRepo, service
+	}
+
+	t.Run("sums up the current year by default", func(t *testing.T) {
+		userRepo, ratingRepo, cache, activityRepo, service := setup()
+		cache.On("Get", mock.Anything, "user_wrapped:user-1:2024", mock.Anything).Return(errors.New("cache miss"))
+		cache.On("SetWithTags", mock.Anything, "user_wrapped:user-1:2024", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		userRepo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		ratingRepo.On("GetUserRatingsWithMovies", mock.Anything, users.UserID("user-1"), rating.UserRatingFilter{RatedFrom: &from, RatedTo: &to}, mock.Anything).Return([]*rating.RatingWithMovie{
+			{Rating: &rating.Rating{ID: "rating-1", Score: 5}, Movie: &movies.Movie{ID: "movie-1", Title: "Heat"}, MovieAverage: 4.2, MovieTotalRatings: 10},
+		}, int64(2), nil)
+
+		review, err := service.GetYearInReview(context.Background(), "user-1", 0)
+
+		require.NoError(t, err)
+		assert.Equal(t, from, activityRepo.from)
+		assert.Equal(t, to, activityRepo.to)
+		assert.Equal(t, 2024, review.Year)
+		assert.Equal(t, int64(2), review.TotalRatings)
+		assert.Equal(t, 1990, review.TopDecade)
+		assert.Equal(t, "Michael Mann", review.TopDirector)
+		require.Len(t, review.TopRated, 1)
+		assert.Equal(t, "much_above", review.TopRated[0].UserVsAvg)
+		cache.AssertExpectations(t)
+	})
+
+	t.Run("rejects future years", func(t *testing.T) {
+		_, _, _, _, service := setup()
+
+		_, err := service.GetYearInReview(context.Background(), "user-1", 2025)
+
+		var appErr *appErrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	})
+
+	t.Run("fails for unknown users", func(t *testing.T) {
+		userRepo, _, cache, _, service := setup()
+		cache.On("Get", mock.Anything, "user_wrapped:missing:2023", mock.Anything).Return(errors.New("cache miss"))
+		userRepo.On("FindByID", mock.Anything, users.UserID("missing")).Return(nil, nil)
+
+		_, err := service.GetYearInReview(context.Background(), "missing", 2023)
+
+		var appErr *appErrors.AppError
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+}
+
+func TestGetUserStatsIncludesActivity(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	ratingRepo := new(MockRatingRepository)
+	movieRepo := new(MockMovieRepository)
+	cache := new(mockCache)
+	cache.On("Get", mock.Anything, "user_stats:user-1", mock.Anything).Return(errors.New("cache miss"))
+	cache.On("SetWithTags", mock.Anything, "user_stats:user-1", mock.Anything, mock.Anything, []string{"user:user-1"}).Return(nil)
+	userRepo.On("FindByID", mock.Anything, users.UserID("user-1")).Return(&users.User{ID: "user-1"}, nil)
+	ratingRepo.On("GetByUser", mock.Anything, users.UserID("user-1"), mock.Anything).Return([]*rating.Rating{{ID: "rating-1", MovieID: "movie-1", Score: 4}}, nil)
+	movieRepo.On("GetByID", mock.Anything, movies.MovieID("movie-1")).Return(&movies.Movie{ID: "movie-1", Genre: "Crime"}, nil)
+	activityRepo := &fakeActivityRepository{activity: &rating.UserActivity{
+		TotalRatings:  1,
+		PerMonth:      []rating.MonthlyCount{{Month: "2024-03", Count: 1}},
+		LongestStreak: &rating.Streak{Days: 1},
+		Decades:       []rating.DecadeCount{{Decade: 1990, Count: 1}},
+	}}
+
+	timeProvider := new(MockTimeProvider)
+	timeProvider.On("Now").Return(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	service := NewUserService(userRepo, ratingRepo, movieRepo, new(MockIDGenerator), timeProvider, cache, testLogger, WithActivityRepository(activityRepo))
+	stats, err := service.GetUserStats(context.Background(), "user-1")
+
+	require.NoError(t, err)
+	assert.True(t, activityRepo.from.IsZero() && activityRepo.to.IsZero(), "over all time")
+	assert.Equal(t, []rating.MonthlyCount{{Month: "2024-03", Count: 1}}, stats.RatingsPerMonth)
+	assert.Equal(t, 1, stats.LongestStreak.Days)
+	assert.Equal(t, 1990, stats.FavoriteDecade)
+}
@@ -0,0 +1,53 @@
+package views
+
+import (
+	"context"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/views"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock dependencies
+type mockViewRepository struct {
+	mock.Mock
+}
+
+func (m *mockViewRepository) Add(ctx context.Context, counts []*views.DailyViews) error {
+	args := m.Called(ctx, counts)
+	return args.Error(0)
+}
+
+func (m *mockViewRepository) Top(ctx context.Context, day time.Time, limit int) ([]*views.DailyViews, error) {
+	args := m.Called(ctx, day, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*views.DailyViews), args.Error(1)
+}
+
+type mockMovieFinder struct {
+	mock.Mock
+}
+
+func (m *mockMovieFinder) GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*movies.Movie), args.Error(1)
+}
+
+func (m *mockMovieFinder) Exists(ctx context.Context, id movies.MovieID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockTimeProvider struct {
+	now time.Time
+}
+
+func (m *mockTimeProvider) Now() time.Time {
+	return m.now
+}
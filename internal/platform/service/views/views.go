@@ -0,0 +1,257 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/shared"
+	"thermondo/internal/domain/users"
+	"thermondo/internal/domain/views"
+	"thermondo/internal/pkg/cache"
+	"thermondo/internal/pkg/errors"
+	"time"
+)
+
+const (
+	DefaultRecentLimit  = 20
+	DefaultPopularLimit = 10
+	// MaxRecentLimit is also how many movies a user's recently viewed list
+	// keeps
+	MaxRecentLimit  = 50
+	MaxPopularLimit = 50
+
+	// pendingKey holds views not yet flushed to Postgres, one field per
+	// day and movie
+	pendingKey = "views:pending"
+	// seenKeyFormat marks that a user viewed a movie on a day, so they
+	// count once: views:seen:{yyyy-mm-dd}:{user id}:{movie id}
+	seenKeyFormat = "views:seen:%s:%s:%s"
+	// seenKeyTTL keeps a mark until its day is over everywhere
+	seenKeyTTL = 26 * time.Hour
+	// recentKeyFormat holds a user's recently viewed movies:
+	// views:recent:{user id}
+	recentKeyFormat = "views:recent:%s"
+	// recentKeyTTL forgets what users viewed once they stop viewing
+	recentKeyTTL = 90 * 24 * time.Hour
+
+	fieldSeparator = "|"
+)
+
+// Service tracks which movies users view. Views are buffered in Counters
+// and rolled up per movie and day into the repository periodically, so
+// tracking costs no database write per view.
+type Service interface {
+	// Record notes that the user viewed the movie: it moves the movie to
+	// the front of the user's recently viewed and counts towards the
+	// movie's views of the day, once per user
+	Record(ctx context.Context, userID users.UserID, movieID movies.MovieID) error
+	// RecentlyViewed returns up to limit movies the user viewed, most
+	// recent first
+	RecentlyViewed(ctx context.Context, userID users.UserID, limit int) ([]*ViewedMovie, error)
+	// PopularToday returns up to limit of the movies viewed by the most
+	// users today (UTC), as of the last flush
+	PopularToday(ctx context.Context, limit int) ([]*PopularMovie, error)
+	// Flush moves the buffered views to the repository and returns how
+	// many daily counts it wrote
+	Flush(ctx context.Context) (int, error)
+	// StartFlusher flushes every interval until ctx is done, then once more
+	StartFlusher(ctx context.Context, interval time.Duration)
+}
+
+// ViewedMovie is a movie of a user's recently viewed
+type ViewedMovie struct {
+	Movie    *movies.Movie
+	ViewedAt time.Time
+}
+
+// PopularMovie is a movie and how many users viewed it today
+type PopularMovie struct {
+	Movie *movies.Movie
+	Views int64
+}
+
+// MovieFinder loads the movies views refer to
+type MovieFinder interface {
+	GetByID(ctx context.Context, id movies.MovieID) (*movies.Movie, error)
+	Exists(ctx context.Context, id movies.MovieID) (bool, error)
+}
+
+type viewService struct {
+	repo         views.Repository
+	movies       MovieFinder
+	counters     cache.Counters
+	recents      cache.Recents
+	timeProvider shared.TimeProvider
+	logger       *slog.Logger
+}
+
+func NewViewService(
+	repo views.Repository,
+	movies MovieFinder,
+	counters cache.Counters,
+	recents cache.Recents,
+	timeProvider shared.TimeProvider,
+	logger *slog.Logger,
+) Service {
+	return &viewService{
+		repo:         repo,
+		movies:       movies,
+		counters:     counters,
+		recents:      recents,
+		timeProvider: timeProvider,
+		logger:       logger,
+	}
+}
+
+func (s *viewService) Record(ctx context.Context, userID users.UserID, movieID movies.MovieID) error {
+	exists, err := s.movies.Exists(ctx, movieID)
+	if err != nil {
+		s.logger.Error("Failed to check movie exists", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to record view")
+	}
+	if !exists {
+		return errors.NewNotFoundError("Movie not found")
+	}
+
+	now := s.timeProvider.Now().UTC()
+	if err := s.recents.Add(ctx, fmt.Sprintf(recentKeyFormat, userID), string(movieID), now, MaxRecentLimit, recentKeyTTL); err != nil {
+		s.logger.Error("Failed to record recently viewed", "error", err, "user_id", userID, "movie_id", movieID)
+		return errors.NewInternalError("Failed to record view")
+	}
+
+	day := now.Format(time.DateOnly)
+	seen, err := s.counters.IncrBy(ctx, fmt.Sprintf(seenKeyFormat, day, userID, movieID), 1, seenKeyTTL)
+	if err != nil {
+		s.logger.Error("Failed to mark view", "error", err, "user_id", userID, "movie_id", movieID)
+		return errors.NewInternalError("Failed to record view")
+	}
+	if seen > 1 {
+		return nil
+	}
+	if err := s.counters.HIncrBy(ctx, pendingKey, map[string]int64{day + fieldSeparator + string(movieID): 1}); err != nil {
+		s.logger.Error("Failed to count view", "error", err, "movie_id", movieID)
+		return errors.NewInternalError("Failed to record view")
+	}
+	return nil
+}
+
+func (s *viewService) RecentlyViewed(ctx context.Context, userID users.UserID, limit int) ([]*ViewedMovie, error) {
+	if limit <= 0 {
+		limit = DefaultRecentLimit
+	}
+	recent, err := s.recents.Latest(ctx, fmt.Sprintf(recentKeyFormat, userID), min(limit, MaxRecentLimit))
+	if err != nil {
+		s.logger.Error("Failed to list recently viewed", "error", err, "user_id", userID)
+		return nil, errors.NewInternalError("Failed to get recently viewed movies")
+	}
+
+	viewed := make([]*ViewedMovie, 0, len(recent))
+	for _, r := range recent {
+		movie, err := s.findMovie(ctx, movies.MovieID(r.Member))
+		if err != nil {
+			return nil, errors.NewInternalError("Failed to get recently viewed movies")
+		}
+		if movie != nil {
+			viewed = append(viewed, &ViewedMovie{Movie: movie, ViewedAt: r.At})
+		}
+	}
+	return viewed, nil
+}
+
+func (s *viewService) PopularToday(ctx context.Context, limit int) ([]*PopularMovie, error) {
+	if limit <= 0 {
+		limit = DefaultPopularLimit
+	}
+	top, err := s.repo.Top(ctx, s.timeProvider.Now().UTC(), min(limit, MaxPopularLimit))
+	if err != nil {
+		s.logger.Error("Failed to list most viewed movies", "error", err)
+		return nil, errors.NewInternalError("Failed to get popular movies")
+	}
+
+	popular := make([]*PopularMovie, 0, len(top))
+	for _, t := range top {
+		movie, err := s.findMovie(ctx, t.MovieID)
+		if err != nil {
+			return nil, errors.NewInternalError("Failed to get popular movies")
+		}
+		if movie != nil {
+			popular = append(popular, &PopularMovie{Movie: movie, Views: t.Views})
+		}
+	}
+	return popular, nil
+}
+
+// findMovie returns the movie with id, or nil if it has been deleted since
+// it was viewed
+func (s *viewService) findMovie(ctx context.Context, id movies.MovieID) (*movies.Movie, error) {
+	movie, err := s.movies.GetByID(ctx, id)
+	if err == nil {
+		return movie, nil
+	}
+	// The repositories don't tell a missing movie from a failed lookup
+	exists, existsErr := s.movies.Exists(ctx, id)
+	if existsErr == nil && !exists {
+		return nil, nil
+	}
+	s.logger.Error("Failed to get viewed movie", "error", err, "movie_id", id)
+	return nil, err
+}
+
+func (s *viewService) Flush(ctx context.Context) (int, error) {
+	pending, err := s.counters.HTake(ctx, pendingKey)
+	if err != nil {
+		s.logger.Error("Failed to take buffered views", "error", err)
+		return 0, errors.NewInternalError("Failed to flush views")
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	counts := make([]*views.DailyViews, 0, len(pending))
+	for field, n := range pending {
+		day, movieID, ok := strings.Cut(field, fieldSeparator)
+		parsed, err := time.Parse(time.DateOnly, day)
+		if !ok || err != nil || movieID == "" {
+			s.logger.Warn("Dropping malformed views field", "field", field)
+			continue
+		}
+		counts = append(counts, &views.DailyViews{MovieID: movies.MovieID(movieID), Day: parsed, Views: n})
+	}
+
+	if err := s.repo.Add(ctx, counts); err != nil {
+		// Put the views back so the next flush retries them
+		if restoreErr := s.counters.HIncrBy(context.WithoutCancel(ctx), pendingKey, pending); restoreErr != nil {
+			s.logger.Error("Lost buffered views", "error", restoreErr, "counts", len(counts))
+		}
+		s.logger.Error("Failed to store views", "error", err, "counts", len(counts))
+		return 0, errors.NewInternalError("Failed to flush views")
+	}
+	return len(counts), nil
+}
+
+func (s *viewService) StartFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.logger.Info("Starting views flusher", "interval", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Views buffered in memory would be lost with the process
+			if _, err := s.Flush(context.WithoutCancel(ctx)); err != nil {
+				s.logger.Error("Final views flush failed", "error", err)
+			}
+			s.logger.Info("Stopping views flusher")
+			return
+		case <-ticker.C:
+			if n, err := s.Flush(ctx); err != nil {
+				s.logger.Error("Periodic views flush failed", "error", err)
+			} else if n > 0 {
+				s.logger.Debug("Flushed views", "counts", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/views"
+	"thermondo/internal/pkg/cache"
+	appErrors "thermondo/internal/pkg/errors"
+)
+
+var testNow = time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+type testDeps struct {
+	repo     *mockViewRepository
+	movies   *mockMovieFinder
+	counters *cache.MemoryCounters
+	clock    *mockTimeProvider
+}
+
+func setupTestService() (Service, *testDeps) {
+	deps := &testDeps{
+		repo:     new(mockViewRepository),
+		movies:   new(mockMovieFinder),
+		counters: cache.NewMemoryCounters(),
+		clock:    &mockTimeProvider{now: testNow},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewViewService(deps.repo, deps.movies, deps.counters, cache.NewMemoryRecents(), deps.clock, logger), deps
+}
+
+func assertAppErrorCode(t *testing.T, err error, code appErrors.ErrorCode) {
+	t.Helper()
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, string(code), appErr.Code)
+}
+
+func TestRecordAndFlush(t *testing.T) {
+	ctx := context.Background()
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	service, deps := setupTestService()
+	deps.movies.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+	deps.movies.On("Exists", ctx, movies.MovieID("movie-2")).Return(true, nil)
+
+	require.NoError(t, service.Record(ctx, "user-1", "movie-1"))
+	require.NoError(t, service.Record(ctx, "user-1", "movie-1"))
+	require.NoError(t, service.Record(ctx, "user-2", "movie-1"))
+	require.NoError(t, service.Record(ctx, "user-1", "movie-2"))
+
+	deps.repo.On("Add", ctx, mock.MatchedBy(func(counts []*views.DailyViews) bool {
+		return assert.ElementsMatch(t, []*views.DailyViews{
+			{MovieID: "movie-1", Day: day, Views: 2},
+			{MovieID: "movie-2", Day: day, Views: 1},
+		}, counts)
+	})).Return(nil).Once()
+
+	n, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "a user counts once per movie and day")
+
+	n, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	deps.repo.AssertExpectations(t)
+}
+
+func TestRecord_UnknownMovie(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupTestService()
+	deps.movies.On("Exists", ctx, movies.MovieID("missing")).Return(false, nil)
+
+	err := service.Record(ctx, "user-1", "missing")
+	assertAppErrorCode(t, err, appErrors.CodeNotFound)
+
+	recent, err := service.RecentlyViewed(ctx, "user-1", 10)
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+}
+
+func TestFlush_KeepsViewsWhenStoringFails(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupTestService()
+	deps.movies.On("Exists", ctx, movies.MovieID("movie-1")).Return(true, nil)
+	require.NoError(t, service.Record(ctx, "user-1", "movie-1"))
+
+	deps.repo.On("Add", ctx, mock.Anything).Return(errors.New("connection refused")).Once()
+	_, err := service.Flush(ctx)
+	assertAppErrorCode(t, err, appErrors.CodeInternal)
+
+	deps.repo.On("Add", ctx, mock.Anything).Return(nil).Once()
+	n, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "the views are retried")
+}
+
+func TestRecentlyViewed(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupTestService()
+	for _, id := range []movies.MovieID{"movie-1", "movie-2", "movie-3"} {
+		deps.movies.On("Exists", ctx, id).Return(true, nil).Once()
+		require.NoError(t, service.Record(ctx, "user-1", id))
+		deps.clock.now = deps.clock.now.Add(time.Minute)
+	}
+
+	deps.movies.On("GetByID", ctx, movies.MovieID("movie-3")).Return(&movies.Movie{ID: "movie-3"}, nil)
+	deps.movies.On("GetByID", ctx, movies.MovieID("movie-2")).Return(nil, errors.New("movie with ID movie-2 not found"))
+	deps.movies.On("Exists", ctx, movies.MovieID("movie-2")).Return(false, nil)
+	deps.movies.On("GetByID", ctx, movies.MovieID("movie-1")).Return(&movies.Movie{ID: "movie-1"}, nil)
+
+	recent, err := service.RecentlyViewed(ctx, "user-1", 0)
+	require.NoError(t, err)
+	require.Len(t, recent, 2, "deleted movies are skipped")
+	assert.Equal(t, movies.MovieID("movie-3"), recent[0].Movie.ID)
+	assert.Equal(t, testNow.Add(2*time.Minute), recent[0].ViewedAt)
+	assert.Equal(t, movies.MovieID("movie-1"), recent[1].Movie.ID)
+
+	recent, err = service.RecentlyViewed(ctx, "user-2", 10)
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+}
+
+func TestPopularToday(t *testing.T) {
+	ctx := context.Background()
+	service, deps := setupTestService()
+	deps.repo.On("Top", ctx, testNow, MaxPopularLimit).Return([]*views.DailyViews{
+		{MovieID: "movie-1", Day: testNow, Views: 7},
+	}, nil)
+	deps.movies.On("GetByID", ctx, movies.MovieID("movie-1")).Return(&movies.Movie{ID: "movie-1"}, nil)
+
+	popular, err := service.PopularToday(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, popular, 1)
+	assert.Equal(t, int64(7), popular[0].Views)
+
+	deps.repo.On("Top", ctx, testNow, DefaultPopularLimit).Return(nil, errors.New("connection refused"))
+	_, err = service.PopularToday(ctx, 0)
+	assertAppErrorCode(t, err, appErrors.CodeInternal)
+}
@@ -0,0 +1,359 @@
+package warmup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// JobType is the job that runs warmups too large to run inline
+	JobType = "cache.warm"
+
+	DefaultTopMovies = 100
+	// MaxTargets bounds the movies and users one warmup asks for
+	MaxTargets = 5000
+	// DefaultInlineLimit is how many movies and users a warmup may cover
+	// before it is queued as a job instead of run during the request
+	DefaultInlineLimit = 50
+
+	// statsPathFormat is the stats endpoint as clients call it, so the
+	// warmed responses land under the keys their requests look up
+	statsPathFormat = "/api/v1/movies/%s/stats"
+	// concurrency bounds the requests in flight, leaving the database pool
+	// to the traffic already arriving
+	concurrency = 4
+)
+
+// Ranker finds the movies and users most likely to be asked for
+type Ranker interface {
+	MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error)
+	MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error)
+}
+
+// GlobalAverageLoader loads the global average rating the Bayesian averages
+// are computed against
+type GlobalAverageLoader interface {
+	UpdateGlobalAverage(ctx context.Context) error
+}
+
+// UserCacheWarmer precomputes the cached profile and stats of a user
+type UserCacheWarmer interface {
+	WarmUserCache(ctx context.Context, userID string) error
+}
+
+// JobQueue queues background jobs
+type JobQueue interface {
+	Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error)
+}
+
+// Request picks what to warm: the given movies and users, and the top
+// movies and users by number of ratings. It is also the payload of a
+// JobType job.
+type Request struct {
+	MovieIDs  []string `json:"movie_ids,omitempty"`
+	UserIDs   []string `json:"user_ids,omitempty"`
+	TopMovies int      `json:"top_movies,omitempty"`
+	TopUsers  int      `json:"top_users,omitempty"`
+}
+
+// size is how many movies and users the request covers at most
+func (r Request) size() int {
+	return len(r.MovieIDs) + len(r.UserIDs) + r.TopMovies + r.TopUsers
+}
+
+// Result counts the movies and users a warmup warmed and those it failed
+// to
+type Result struct {
+	Movies int `json:"movies"`
+	Users  int `json:"users"`
+	Failed int `json:"failed"`
+}
+
+// Warmer fills the caches behind the busiest reads, at startup before the
+// server reports ready and on demand through the admin API, so the first
+// requests for popular data do not all reach the database. Movie stats are
+// requested through the API handler, which stores them in the response
+// cache just as a client request would; users are warmed by the user
+// service.
+type Warmer struct {
+	ranker      Ranker
+	averages    GlobalAverageLoader
+	handler     http.Handler
+	users       UserCacheWarmer
+	queue       JobQueue
+	logger      *slog.Logger
+	topMovies   int
+	inlineLimit int
+}
+
+// Option configures optional settings of the warmer
+type Option func(*Warmer)
+
+// WithTopMovies sets how many of the most rated movies have their stats
+// warmed at startup; 0 only loads the global average
+func WithTopMovies(n int) Option {
+	return func(w *Warmer) {
+		if n >= 0 {
+			w.topMovies = n
+		}
+	}
+}
+
+// WithUsers enables warming users
+func WithUsers(users UserCacheWarmer) Option {
+	return func(w *Warmer) {
+		w.users = users
+	}
+}
+
+// WithJobQueue queues warmups larger than inlineLimit as JobType jobs;
+// without a queue every warmup runs inline
+func WithJobQueue(queue JobQueue, inlineLimit int) Option {
+	return func(w *Warmer) {
+		w.queue = queue
+		if inlineLimit > 0 {
+			w.inlineLimit = inlineLimit
+		}
+	}
+}
+
+func NewWarmer(ranker Ranker, averages GlobalAverageLoader, handler http.Handler, logger *slog.Logger, opts ...Option) *Warmer {
+	w := &Warmer{
+		ranker:      ranker,
+		averages:    averages,
+		handler:     handler,
+		logger:      logger,
+		topMovies:   DefaultTopMovies,
+		inlineLimit: DefaultInlineLimit,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run is the startup warmup: it loads the global average and warms the
+// stats of the most rated movies. It carries on past failures, which it
+// returns together; a cache left cold only costs the first request a
+// database lookup.
+func (w *Warmer) Run(ctx context.Context) error {
+	start := time.Now()
+	var errs []error
+
+	if err := w.averages.UpdateGlobalAverage(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to load global average: %w", err))
+	}
+
+	result, err := w.Warm(ctx, Request{TopMovies: w.topMovies}, nil)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	w.logger.Info("Warmed caches", "movies", result.Movies, "duration", time.Since(start))
+	return errors.Join(errs...)
+}
+
+// Start warms what req asks for. Small warmups run right away and return
+// their result; larger ones are queued and return the job.
+func (w *Warmer) Start(ctx context.Context, req Request, startedBy string) (*Result, *jobs.Job, error) {
+	req, err := w.validate(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if w.queue != nil && req.size() > w.inlineLimit {
+		job, err := w.queue.Enqueue(ctx, JobType, req, startedBy)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, job, nil
+	}
+
+	result, err := w.Warm(ctx, req, nil)
+	if err != nil {
+		w.logger.Warn("Cache warmup incomplete", "error", err, "started_by", startedBy)
+	}
+	return result, nil, nil
+}
+
+// RunJob is the jobs.Handler of JobType
+func (w *Warmer) RunJob(ctx context.Context, job *jobs.Job, report jobs.ReportFunc) error {
+	var req Request
+	if err := json.Unmarshal(job.Payload, &req); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid warmup payload: %w", err))
+	}
+
+	result, err := w.Warm(ctx, req, func(done, total int, progress Result) {
+		report(float64(done)/float64(total)*100, progress)
+	})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	// Nothing was tried, so the ranking itself failed; that is worth a retry
+	if err != nil && result.Movies+result.Users+result.Failed == 0 {
+		return err
+	}
+	// Movies and users that failed are reported, not retried; their next
+	// request warms them
+	report(100, result)
+	w.logger.Info("Cache warmup job completed", "job_id", job.ID, "movies", result.Movies, "users", result.Users, "failed", result.Failed)
+	return nil
+}
+
+// Warm warms the movies and users req asks for, calling progress, if set,
+// as they are done. The result is complete even when it returns an error,
+// which joins the failures.
+func (w *Warmer) Warm(ctx context.Context, req Request, progress func(done, total int, result Result)) (*Result, error) {
+	var errs []error
+
+	movieIDs := req.MovieIDs
+	if req.TopMovies > 0 {
+		top, err := w.ranker.MostRatedMovies(ctx, req.TopMovies)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get most rated movies: %w", err))
+		}
+		for _, id := range top {
+			movieIDs = append(movieIDs, string(id))
+		}
+	}
+	userIDs := req.UserIDs
+	if req.TopUsers > 0 && w.users != nil {
+		top, err := w.ranker.MostActiveRaters(ctx, req.TopUsers)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get most active raters: %w", err))
+		}
+		for _, id := range top {
+			userIDs = append(userIDs, string(id))
+		}
+	}
+	movieIDs, userIDs = dedupe(movieIDs), dedupe(userIDs)
+	if w.users == nil {
+		userIDs = nil
+	}
+
+	var warmedMovies, warmedUsers, failed, done atomic.Int64
+	total := len(movieIDs) + len(userIDs)
+	finished := func() {
+		n := done.Add(1)
+		if progress != nil {
+			progress(int(n), total, Result{
+				Movies: int(warmedMovies.Load()),
+				Users:  int(warmedUsers.Load()),
+				Failed: int(failed.Load()),
+			})
+		}
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for _, movieID := range movieIDs {
+		group.Go(func() error {
+			defer finished()
+			if status := w.get(groupCtx, fmt.Sprintf(statsPathFormat, url.PathEscape(movieID))); status != http.StatusOK {
+				w.logger.Warn("Failed to warm movie stats", "movie_id", movieID, "status", status)
+				failed.Add(1)
+				return nil
+			}
+			warmedMovies.Add(1)
+			return nil
+		})
+	}
+	for _, userID := range userIDs {
+		group.Go(func() error {
+			defer finished()
+			if err := w.users.WarmUserCache(groupCtx, userID); err != nil {
+				w.logger.Warn("Failed to warm user cache", "user_id", userID, "error", err)
+				failed.Add(1)
+				return nil
+			}
+			warmedUsers.Add(1)
+			return nil
+		})
+	}
+	group.Wait()
+
+	result := &Result{
+		Movies: int(warmedMovies.Load()),
+		Users:  int(warmedUsers.Load()),
+		Failed: int(failed.Load()),
+	}
+	if result.Failed > 0 {
+		errs = append(errs, fmt.Errorf("failed to warm %d of %d movies and users", result.Failed, total))
+	}
+	return result, errors.Join(errs...)
+}
+
+// validate trims the IDs of req and checks its size
+func (w *Warmer) validate(req Request) (Request, error) {
+	if req.TopMovies < 0 || req.TopUsers < 0 {
+		return req, appErrors.NewBadRequestError("top_movies and top_users must not be negative")
+	}
+	if (len(req.UserIDs) > 0 || req.TopUsers > 0) && w.users == nil {
+		return req, appErrors.NewBadRequestError("Warming users is not available")
+	}
+	req.MovieIDs, req.UserIDs = dedupe(req.MovieIDs), dedupe(req.UserIDs)
+	switch size := req.size(); {
+	case size == 0:
+		return req, appErrors.NewBadRequestError("Nothing to warm; give movie_ids, user_ids, top_movies or top_users")
+	case size > MaxTargets:
+		return req, appErrors.NewBadRequestError(fmt.Sprintf("A warmup covers at most %d movies and users", MaxTargets))
+	}
+	return req, nil
+}
+
+// dedupe trims ids and drops blank and repeated ones, keeping the order
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// get serves a GET of path through the handler and returns the status
+func (w *Warmer) get(ctx context.Context, path string) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+	rec := &statusRecorder{header: make(http.Header), status: http.StatusOK}
+	w.handler.ServeHTTP(rec, req)
+	return rec.status
+}
+
+// statusRecorder discards a response but its status
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *statusRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+}
@@ -0,0 +1,237 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"thermondo/internal/domain/jobs"
+	"thermondo/internal/domain/movies"
+	"thermondo/internal/domain/users"
+	appErrors "thermondo/internal/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRanker struct {
+	ids       []movies.MovieID
+	userIDs   []users.UserID
+	err       error
+	limit     int
+	userLimit int
+}
+
+func (f *fakeRanker) MostRatedMovies(ctx context.Context, limit int) ([]movies.MovieID, error) {
+	f.limit = limit
+	return f.ids, f.err
+}
+
+func (f *fakeRanker) MostActiveRaters(ctx context.Context, limit int) ([]users.UserID, error) {
+	f.userLimit = limit
+	return f.userIDs, f.err
+}
+
+type fakeUsers struct {
+	mu      sync.Mutex
+	warmed  []string
+	failing map[string]bool
+}
+
+func (f *fakeUsers) WarmUserCache(ctx context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failing[userID] {
+		return errors.New("user not found")
+	}
+	f.warmed = append(f.warmed, userID)
+	return nil
+}
+
+type fakeQueue struct {
+	jobType string
+	payload any
+	by      string
+}
+
+func (f *fakeQueue) Enqueue(ctx context.Context, jobType string, payload any, createdBy string) (*jobs.Job, error) {
+	f.jobType, f.payload, f.by = jobType, payload, createdBy
+	return &jobs.Job{ID: "job-1", Type: jobType, Status: jobs.StatusQueued}, nil
+}
+
+type fakeAverages struct {
+	err    error
+	loaded bool
+}
+
+func (f *fakeAverages) UpdateGlobalAverage(ctx context.Context) error {
+	f.loaded = true
+	return f.err
+}
+
+// recordingHandler answers 200 to every path but those in failing
+type recordingHandler struct {
+	mu      sync.Mutex
+	paths   []string
+	failing map[string]bool
+}
+
+func (h *recordingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.paths = append(h.paths, r.URL.RequestURI())
+	h.mu.Unlock()
+	if h.failing[r.URL.RequestURI()] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte(`{}`))
+}
+
+func TestWarmer_Run(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("loads the global average and requests the stats of the most rated movies", func(t *testing.T) {
+		ranker := &fakeRanker{ids: []movies.MovieID{"movie-1", "movie 2"}}
+		averages := &fakeAverages{}
+		handler := &recordingHandler{}
+
+		err := NewWarmer(ranker, averages, handler, logger, WithTopMovies(2)).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, averages.loaded)
+		assert.Equal(t, 2, ranker.limit)
+		sort.Strings(handler.paths)
+		assert.Equal(t, []string{"/api/v1/movies/movie%202/stats", "/api/v1/movies/movie-1/stats"}, handler.paths)
+	})
+
+	t.Run("carries on past failures and reports them", func(t *testing.T) {
+		ranker := &fakeRanker{ids: []movies.MovieID{"movie-1", "movie-2"}}
+		averages := &fakeAverages{err: errors.New("database down")}
+		handler := &recordingHandler{failing: map[string]bool{"/api/v1/movies/movie-1/stats": true}}
+
+		err := NewWarmer(ranker, averages, handler, logger).Run(context.Background())
+
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "failed to load global average")
+		assert.ErrorContains(t, err, "failed to warm 1 of 2 movies and users")
+		assert.Len(t, handler.paths, 2)
+	})
+
+	t.Run("only loads the global average without top movies", func(t *testing.T) {
+		ranker := &fakeRanker{}
+		averages := &fakeAverages{}
+		handler := &recordingHandler{}
+
+		err := NewWarmer(ranker, averages, handler, logger, WithTopMovies(0)).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, averages.loaded)
+		assert.Zero(t, ranker.limit)
+		assert.Empty(t, handler.paths)
+	})
+}
+
+func TestWarmer_Start(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("warms small requests inline", func(t *testing.T) {
+		ranker := &fakeRanker{userIDs: []users.UserID{"user-2", "user-1"}}
+		handler := &recordingHandler{}
+		userWarmer := &fakeUsers{failing: map[string]bool{"user-2": true}}
+		queue := &fakeQueue{}
+		warmer := NewWarmer(ranker, &fakeAverages{}, handler, logger, WithUsers(userWarmer), WithJobQueue(queue, 10))
+
+		result, job, err := warmer.Start(context.Background(), Request{
+			MovieIDs: []string{" movie-1 ", "movie-1", ""},
+			UserIDs:  []string{"user-1"},
+			TopUsers: 2,
+		}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Nil(t, job)
+		assert.Equal(t, &Result{Movies: 1, Users: 1, Failed: 1}, result)
+		assert.Equal(t, []string{"/api/v1/movies/movie-1/stats"}, handler.paths)
+		assert.Equal(t, []string{"user-1"}, userWarmer.warmed)
+		assert.Equal(t, 2, ranker.userLimit)
+		assert.Empty(t, queue.jobType)
+	})
+
+	t.Run("queues large requests", func(t *testing.T) {
+		handler := &recordingHandler{}
+		queue := &fakeQueue{}
+		warmer := NewWarmer(&fakeRanker{}, &fakeAverages{}, handler, logger, WithJobQueue(queue, 10))
+
+		result, job, err := warmer.Start(context.Background(), Request{TopMovies: 11}, "admin-1")
+
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		require.NotNil(t, job)
+		assert.Equal(t, JobType, queue.jobType)
+		assert.Equal(t, Request{TopMovies: 11, MovieIDs: []string{}, UserIDs: []string{}}, queue.payload)
+		assert.Equal(t, "admin-1", queue.by)
+		assert.Empty(t, handler.paths)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		warmer := NewWarmer(&fakeRanker{}, &fakeAverages{}, &recordingHandler{}, logger)
+
+		for name, req := range map[string]Request{
+			"empty":          {MovieIDs: []string{" "}},
+			"negative":       {TopMovies: -1},
+			"too large":      {TopMovies: MaxTargets + 1},
+			"users disabled": {UserIDs: []string{"user-1"}},
+		} {
+			_, _, err := warmer.Start(context.Background(), req, "admin-1")
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr, name)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode, name)
+		}
+	})
+}
+
+func TestWarmer_RunJob(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("warms the payload and reports progress", func(t *testing.T) {
+		ranker := &fakeRanker{ids: []movies.MovieID{"movie-1", "movie-2"}}
+		warmer := NewWarmer(ranker, &fakeAverages{}, &recordingHandler{}, logger)
+		var mu sync.Mutex
+		var reports []float64
+		var last any
+
+		err := warmer.RunJob(context.Background(), &jobs.Job{ID: "job-1", Payload: []byte(`{"top_movies":2}`)}, func(progress float64, result any) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, progress)
+			last = result
+		})
+
+		require.NoError(t, err)
+		sort.Float64s(reports)
+		assert.Equal(t, []float64{50, 100, 100}, reports)
+		assert.Equal(t, &Result{Movies: 2}, last)
+	})
+
+	t.Run("fails permanently on an invalid payload", func(t *testing.T) {
+		warmer := NewWarmer(&fakeRanker{}, &fakeAverages{}, &recordingHandler{}, logger)
+
+		err := warmer.RunJob(context.Background(), &jobs.Job{Payload: []byte(`{`)}, func(float64, any) {})
+
+		require.Error(t, err)
+		assert.True(t, jobs.IsPermanent(err))
+	})
+
+	t.Run("retries when the ranking fails", func(t *testing.T) {
+		warmer := NewWarmer(&fakeRanker{err: errors.New("database down")}, &fakeAverages{}, &recordingHandler{}, logger)
+
+		err := warmer.RunJob(context.Background(), &jobs.Job{Payload: []byte(`{"top_movies":5}`)}, func(float64, any) {})
+
+		require.Error(t, err)
+		assert.False(t, jobs.IsPermanent(err))
+	})
+}